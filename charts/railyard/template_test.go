@@ -63,6 +63,22 @@ func TestConfigmap_TelegraphAllowedChannels_OmittedWhenNotSet(t *testing.T) {
 	}
 }
 
+func TestConfigmap_TelegraphMultiPlatform_Rendered(t *testing.T) {
+	out := helmTemplate(t, "ci/test-values-telegraph-multi-platform.yaml")
+
+	for _, want := range []string{
+		`platform: "slack"`,
+		`- "discord"`,
+		"app_token: ${SLACK_APP_TOKEN}",
+		"bot_token: ${DISCORD_BOT_TOKEN}",
+		`channel_id: "1487921215430594571"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in configmap output", want)
+		}
+	}
+}
+
 func TestEngineDeployment_LogLevel_Set(t *testing.T) {
 	out := helmTemplateShow(t, "ci/test-values-loglevel.yaml", "templates/engine-deployment.yaml")
 
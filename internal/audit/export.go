@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// ExportFormat identifies the export layout in its header line.
+const ExportFormat = "railyard-audit-export/1"
+
+// ExportHeader is the first line of an export. Its hash seeds the chain.
+type ExportHeader struct {
+	Format      string    `json:"format"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	GeneratedAt time.Time `json:"generated_at"`
+	GeneratedBy string    `json:"generated_by,omitempty"`
+}
+
+// ExportRecord is one audit event or engine journal entry in an export.
+// Hash is the SHA-256 of the previous line's hash and this record (with
+// Hash empty), so changing, dropping, or reordering any line breaks every
+// hash after it.
+type ExportRecord struct {
+	Seq      int       `json:"seq"`
+	Source   string    `json:"source"` // "audit" or "journal"
+	ID       uint      `json:"id"`
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`               // audit event type or journal kind
+	Actor    string    `json:"actor"`              // audit actor or engine ID
+	Resource string    `json:"resource,omitempty"` // audit resource or car ID
+	Detail   string    `json:"detail,omitempty"`
+	Outcome  string    `json:"outcome,omitempty"` // journal entries only
+	PrevHash string    `json:"prev_hash"`
+	Hash     string    `json:"hash"`
+}
+
+// ExportTrailer is the last line of an export: the record count and the
+// chain's final hash, which is what a detached signature vouches for.
+type ExportTrailer struct {
+	Records   int    `json:"records"`
+	FinalHash string `json:"final_hash"`
+}
+
+// Export writes the audit events and engine journal entries created in
+// [since, until) to w as JSON lines — a header, the records in time order,
+// and a trailer — hash-chained so the file is tamper-evident on its own. It
+// returns the trailer.
+func Export(db *gorm.DB, w io.Writer, header ExportHeader) (ExportTrailer, error) {
+	if db == nil {
+		return ExportTrailer{}, fmt.Errorf("audit: db is required")
+	}
+	header.Format = ExportFormat
+
+	var events []AuditEvent
+	if err := db.Where("created_at >= ? AND created_at < ?", header.Since, header.Until).
+		Order("created_at, id").Find(&events).Error; err != nil {
+		return ExportTrailer{}, fmt.Errorf("audit: read audit events: %w", err)
+	}
+	var entries []models.JournalEntry
+	if err := db.Where("created_at >= ? AND created_at < ?", header.Since, header.Until).
+		Order("created_at, id").Find(&entries).Error; err != nil {
+		return ExportTrailer{}, fmt.Errorf("audit: read journal: %w", err)
+	}
+
+	records := make([]ExportRecord, 0, len(events)+len(entries))
+	for _, e := range events {
+		records = append(records, ExportRecord{Source: "audit", ID: e.ID, Time: e.CreatedAt.UTC(),
+			Type: e.EventType, Actor: e.Actor, Resource: e.Resource, Detail: e.Detail})
+	}
+	for _, e := range entries {
+		records = append(records, ExportRecord{Source: "journal", ID: e.ID, Time: e.CreatedAt.UTC(),
+			Type: e.Kind, Actor: e.EngineID, Resource: e.CarID, Detail: e.Detail, Outcome: e.Outcome})
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return ExportTrailer{}, fmt.Errorf("audit: write export: %w", err)
+	}
+	prev, err := hashJSON("", header)
+	if err != nil {
+		return ExportTrailer{}, err
+	}
+	for i := range records {
+		r := &records[i]
+		r.Seq = i + 1
+		r.PrevHash = prev
+		if r.Hash, err = hashJSON(prev, r); err != nil {
+			return ExportTrailer{}, err
+		}
+		if err := enc.Encode(r); err != nil {
+			return ExportTrailer{}, fmt.Errorf("audit: write export: %w", err)
+		}
+		prev = r.Hash
+	}
+	trailer := ExportTrailer{Records: len(records), FinalHash: prev}
+	if err := enc.Encode(trailer); err != nil {
+		return ExportTrailer{}, fmt.Errorf("audit: write export: %w", err)
+	}
+	return trailer, nil
+}
+
+// VerifyExport re-walks an export's hash chain and returns its trailer, or
+// an error naming the first line that does not match.
+func VerifyExport(r io.Reader) (ExportTrailer, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	if !sc.Scan() {
+		return ExportTrailer{}, fmt.Errorf("audit: empty export")
+	}
+	var header ExportHeader
+	if err := json.Unmarshal(sc.Bytes(), &header); err != nil || header.Format != ExportFormat {
+		return ExportTrailer{}, fmt.Errorf("audit: line 1: not a %s header", ExportFormat)
+	}
+	prev, err := hashJSON("", header)
+	if err != nil {
+		return ExportTrailer{}, err
+	}
+
+	var lines [][]byte
+	for sc.Scan() {
+		lines = append(lines, append([]byte(nil), sc.Bytes()...))
+	}
+	if err := sc.Err(); err != nil {
+		return ExportTrailer{}, fmt.Errorf("audit: read export: %w", err)
+	}
+	if len(lines) == 0 {
+		return ExportTrailer{}, fmt.Errorf("audit: export has no trailer")
+	}
+
+	for i, line := range lines[:len(lines)-1] {
+		var rec ExportRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return ExportTrailer{}, fmt.Errorf("audit: line %d: %w", i+2, err)
+		}
+		want := rec.Hash
+		rec.Hash = ""
+		got, err := hashJSON(prev, rec)
+		if err != nil {
+			return ExportTrailer{}, err
+		}
+		if rec.Seq != i+1 || rec.PrevHash != prev || got != want {
+			return ExportTrailer{}, fmt.Errorf("audit: line %d (seq %d): hash chain broken", i+2, rec.Seq)
+		}
+		prev = want
+	}
+
+	var trailer ExportTrailer
+	if err := json.Unmarshal(lines[len(lines)-1], &trailer); err != nil {
+		return ExportTrailer{}, fmt.Errorf("audit: trailer: %w", err)
+	}
+	if trailer.Records != len(lines)-1 || trailer.FinalHash != prev {
+		return ExportTrailer{}, fmt.Errorf("audit: trailer does not match the records (export truncated or altered)")
+	}
+	return trailer, nil
+}
+
+// hashJSON returns hex(SHA-256(prev || JSON(v))).
+func hashJSON(prev string, v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("audit: marshal for hash: %w", err)
+	}
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
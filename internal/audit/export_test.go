@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func exportTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&AuditEvent{}, &models.JournalEntry{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestExport_HashChainVerifies(t *testing.T) {
+	db := exportTestDB(t)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	db.Create(&AuditEvent{EventType: "car.created", Actor: "alice", Resource: "car-1", CreatedAt: base.Add(time.Minute)})
+	db.Create(&models.JournalEntry{CarID: "car-1", EngineID: "eng-1", Kind: "command", Detail: "go test ./...", Outcome: "ok", CreatedAt: base.Add(2 * time.Minute)})
+	db.Create(&AuditEvent{EventType: "car.merged", Actor: "yardmaster", Resource: "car-1", CreatedAt: base.Add(3 * time.Minute)})
+	db.Create(&AuditEvent{EventType: "car.created", Actor: "alice", Resource: "car-2", CreatedAt: base.Add(-time.Hour)}) // before the window
+
+	var buf bytes.Buffer
+	trailer, err := Export(db, &buf, ExportHeader{Since: base, Until: base.Add(time.Hour), GeneratedAt: base.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if trailer.Records != 3 {
+		t.Errorf("Records = %d, want 3", trailer.Records)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("lines = %d, want header + 3 records + trailer:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[2], `"source":"journal"`) {
+		t.Errorf("records not in time order: %s", lines[2])
+	}
+
+	got, err := VerifyExport(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("VerifyExport: %v", err)
+	}
+	if got != trailer {
+		t.Errorf("VerifyExport = %+v, want %+v", got, trailer)
+	}
+
+	for name, tampered := range map[string]string{
+		"edited":    strings.Replace(buf.String(), "go test ./...", "rm -rf /", 1),
+		"dropped":   strings.Join(append(append([]string{}, lines[:2]...), lines[3:]...), "\n"),
+		"truncated": strings.Join(lines[:4], "\n"),
+		"reordered": strings.Join([]string{lines[0], lines[2], lines[1], lines[3], lines[4]}, "\n"),
+	} {
+		if _, err := VerifyExport(strings.NewReader(tampered)); err == nil {
+			t.Errorf("%s export verified, want error", name)
+		}
+	}
+}
+
+func TestExport_Empty(t *testing.T) {
+	db := exportTestDB(t)
+	now := time.Now()
+	var buf bytes.Buffer
+	trailer, err := Export(db, &buf, ExportHeader{Since: now.Add(-time.Hour), Until: now})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if trailer.Records != 0 || trailer.FinalHash == "" {
+		t.Errorf("trailer = %+v, want 0 records and the header hash", trailer)
+	}
+	if _, err := VerifyExport(&buf); err != nil {
+		t.Errorf("VerifyExport: %v", err)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/google/go-github/v68/github"
+	"github.com/zulandar/railyard/internal/car"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
@@ -54,7 +56,7 @@ func Start(ctx context.Context, opts StartOpts) error {
 	if err != nil {
 		return fmt.Errorf("bull: %w", err)
 	}
-	store := NewStore(opts.DB, opts.Config.BranchPrefix)
+	store := NewStore(opts.DB, opts.Config.BranchPrefix, car.IDFormatFromConfig(opts.Config))
 
 	tracks := buildTrackInfos(opts.Config.Tracks)
 
@@ -156,6 +158,12 @@ func (d *daemonDeps) CreateCar(ctx context.Context, opts CarCreateOpts) (string,
 func (d *daemonDeps) RecordTriagedIssue(ctx context.Context, issue models.BullIssue) error {
 	return d.store.RecordTriagedIssue(ctx, issue)
 }
+func (d *daemonDeps) YardPause(ctx context.Context) (yard.PauseState, error) {
+	if p, ok := d.store.(yardPauser); ok {
+		return p.YardPause(ctx)
+	}
+	return yard.PauseState{}, nil
+}
 func (d *daemonDeps) CreateCarAndRecord(ctx context.Context, opts CarCreateOpts, issue models.BullIssue) (string, error) {
 	return d.store.CreateCarAndRecord(ctx, opts, issue)
 }
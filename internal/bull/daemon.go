@@ -12,6 +12,7 @@ import (
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/logutil"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 )
 
 const defaultBullPollInterval = 60 * time.Second
@@ -52,6 +53,12 @@ type DaemonStore interface {
 	CreateCarAndRecord(ctx context.Context, opts CarCreateOpts, issue models.BullIssue) (string, error)
 }
 
+// yardPauser is implemented by stores that can report the yard's pause
+// switch. Stores without it are treated as never paused.
+type yardPauser interface {
+	YardPause(ctx context.Context) (yard.PauseState, error)
+}
+
 // DaemonOpts bundles all configuration for RunDaemon.
 type DaemonOpts struct {
 	Config         config.BullConfig
@@ -111,6 +118,19 @@ func RunDaemon(ctx context.Context, deps interface {
 		}
 		opts.RateLimitUntil = time.Time{} // reset after backoff
 
+		// A paused yard creates no cars. Hold off the whole cycle without
+		// advancing the poll boundary, so issues opened meanwhile are
+		// triaged after the yard resumes.
+		if p, ok := deps.(yardPauser); ok {
+			if st, err := p.YardPause(ctx); err != nil {
+				log.Printf("bull: read yard pause state: %v", err)
+			} else if st.Paused {
+				fmt.Fprintf(out, "Bull: yard %s; not triaging\n", st)
+				sleepCtx(ctx, opts.PollInterval)
+				continue
+			}
+		}
+
 		// Collect tracked issues once per cycle for filtering. On failure,
 		// SKIP the whole cycle: proceeding with an empty tracked list would
 		// defeat the "already tracked" skip for every previously-triaged
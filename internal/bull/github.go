@@ -10,6 +10,7 @@ import (
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v68/github"
 	"github.com/zulandar/railyard/internal/config"
+	ghapi "github.com/zulandar/railyard/internal/github"
 	"golang.org/x/oauth2"
 )
 
@@ -24,7 +25,7 @@ type GitHubClient struct {
 // NewClient constructs a GitHubClient authenticated using credentials from cfg.
 // If cfg.AppID is non-zero, it authenticates as a GitHub App installation using
 // the private key at cfg.PrivateKeyPath. Otherwise it falls back to PAT auth
-// using cfg.GitHubToken.
+// using cfg.GitHubToken. Requests go through the shared rate-limited client.
 func NewClient(owner, repo string, cfg config.BullConfig) (*GitHubClient, error) {
 	var tc *http.Client
 	if cfg.AppID != 0 {
@@ -39,6 +40,7 @@ func NewClient(owner, repo string, cfg config.BullConfig) (*GitHubClient, error)
 		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.GitHubToken})
 		tc = oauth2.NewClient(context.Background(), ts)
 	}
+	tc = ghapi.New(ghapi.Options{Base: tc.Transport}).HTTPClient()
 	return &GitHubClient{
 		client:             github.NewClient(tc),
 		owner:              owner,
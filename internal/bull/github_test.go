@@ -65,13 +65,24 @@ func TestNewClient_PAT(t *testing.T) {
 	if gc.client == nil {
 		t.Error("expected non-nil underlying github.Client")
 	}
-	// Verify the underlying transport is oauth2.
-	transport := gc.client.Client().Transport
+	// Verify requests go through the shared client, then oauth2.
+	transport := baseTransport(t, gc)
 	if _, ok := transport.(*oauth2.Transport); !ok {
 		t.Errorf("expected oauth2.Transport, got %s", reflect.TypeOf(transport))
 	}
 }
 
+// baseTransport returns the authenticating transport below the shared
+// rate-limited one that NewClient wraps every client in.
+func baseTransport(t *testing.T, gc *GitHubClient) http.RoundTripper {
+	t.Helper()
+	shared, ok := gc.client.Client().Transport.(interface{ Unwrap() http.RoundTripper })
+	if !ok {
+		t.Fatalf("expected the shared rate-limited transport, got %s", reflect.TypeOf(gc.client.Client().Transport))
+	}
+	return shared.Unwrap()
+}
+
 func TestNewClient_AppCredentials(t *testing.T) {
 	// Generate a real RSA key so ghinstallation can parse it.
 	keyFile := generateRSAKeyFile(t)
@@ -94,8 +105,8 @@ func TestNewClient_AppCredentials(t *testing.T) {
 	if gc.repo != "repo" {
 		t.Errorf("repo = %q, want %q", gc.repo, "repo")
 	}
-	// Verify the underlying transport is ghinstallation.
-	transport := gc.client.Client().Transport
+	// Verify requests go through the shared client, then ghinstallation.
+	transport := baseTransport(t, gc)
 	if _, ok := transport.(*ghinstallation.Transport); !ok {
 		t.Errorf("expected *ghinstallation.Transport, got %s", reflect.TypeOf(transport))
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/zulandar/railyard/internal/car"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
@@ -14,11 +15,18 @@ import (
 type Store struct {
 	db           *gorm.DB
 	branchPrefix string
+	idFormat     car.IDFormat
 }
 
-// NewStore creates a new Store.
-func NewStore(db *gorm.DB, branchPrefix string) *Store {
-	return &Store{db: db, branchPrefix: branchPrefix}
+// NewStore creates a new Store. Cars it creates get IDs in idFormat.
+func NewStore(db *gorm.DB, branchPrefix string, idFormat car.IDFormat) *Store {
+	return &Store{db: db, branchPrefix: branchPrefix, idFormat: idFormat}
+}
+
+// YardPause reports whether the yard is paused. A paused yard creates no
+// cars, so the daemon holds off triage until it resumes.
+func (s *Store) YardPause(_ context.Context) (yard.PauseState, error) {
+	return yard.GetPause(s.db)
 }
 
 func (s *Store) GetTrackedIssues(_ context.Context) ([]models.BullIssue, error) {
@@ -92,6 +100,7 @@ func (s *Store) CreateCar(_ context.Context, opts CarCreateOpts) (string, error)
 		DesignNotes:  opts.DesignNotes,
 		Acceptance:   opts.Acceptance,
 		BranchPrefix: opts.BranchPrefix,
+		IDFormat:     s.idFormat,
 		RequestedBy:  opts.RequestedBy,
 	})
 	if err != nil {
@@ -130,6 +139,7 @@ func (s *Store) CreateCarAndRecord(_ context.Context, opts CarCreateOpts, issue
 			DesignNotes:  opts.DesignNotes,
 			Acceptance:   opts.Acceptance,
 			BranchPrefix: opts.BranchPrefix,
+			IDFormat:     s.idFormat,
 			RequestedBy:  opts.RequestedBy,
 		})
 		if err != nil {
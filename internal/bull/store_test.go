@@ -2,8 +2,10 @@ package bull
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"github.com/zulandar/railyard/internal/car"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -35,7 +37,7 @@ func storeTestDB(t *testing.T) *gorm.DB {
 // issue (railyard-p9t).
 func TestCreateCarAndRecord_RollsBackCarOnRecordFailure(t *testing.T) {
 	db := storeTestDB(t)
-	store := NewStore(db, "ry/test")
+	store := NewStore(db, "ry/test", car.IDFormat{})
 
 	// A prior tracking row already exists for issue #10. The unique index on
 	// bull_issues.issue_number makes the record step fail, which must roll the
@@ -60,10 +62,11 @@ func TestCreateCarAndRecord_RollsBackCarOnRecordFailure(t *testing.T) {
 }
 
 // TestCreateCarAndRecord_HappyPath: on success the car exists, the tracking
-// row exists, and the row points at the new car.
+// row exists, and the row points at the new car, whose ID uses the store's
+// ID format.
 func TestCreateCarAndRecord_HappyPath(t *testing.T) {
 	db := storeTestDB(t)
-	store := NewStore(db, "ry/test")
+	store := NewStore(db, "ry/test", car.IDFormat{TrackPrefixes: map[string]string{"backend": "be-"}})
 
 	carID, err := store.CreateCarAndRecord(context.Background(),
 		CarCreateOpts{Title: "real", Track: "backend", Type: "bug", SourceIssue: 11, BranchPrefix: "ry/test"},
@@ -72,8 +75,8 @@ func TestCreateCarAndRecord_HappyPath(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreateCarAndRecord: %v", err)
 	}
-	if carID == "" {
-		t.Fatal("expected non-empty car ID")
+	if !strings.HasPrefix(carID, "be-") {
+		t.Fatalf("car ID = %q, want be- prefix", carID)
 	}
 
 	var car models.Car
@@ -34,7 +34,7 @@ func SyncCarStatuses(ctx context.Context, client SyncClient, store SyncStore, cf
 	}
 
 	for _, issue := range issues {
-		if issue.CarID == "" {
+		if issue.CarID == "" || issue.TriageMode == models.TriageModeImport {
 			continue
 		}
 
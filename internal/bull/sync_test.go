@@ -410,6 +410,27 @@ func TestSyncCarStatuses_SkipsIssueWithNoCarID(t *testing.T) {
 	}
 }
 
+func TestSyncCarStatuses_SkipsImportedIssues(t *testing.T) {
+	client := &mockSyncClient{}
+	store := &mockSyncStore{
+		issues: []models.BullIssue{
+			{ID: 1, IssueNumber: 10, CarID: "car-1", LastKnownStatus: "open", TriageMode: models.TriageModeImport},
+		},
+		carStatuses: map[string]string{"car-1": "merged"},
+	}
+	cfg := testBullConfig(true)
+
+	if err := SyncCarStatuses(context.Background(), client, store, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.addedLabels) != 0 || len(client.comments) != 0 {
+		t.Errorf("imported issue was synced: labels=%v comments=%v", client.addedLabels, client.comments)
+	}
+	if len(store.updatedIssues) != 0 {
+		t.Errorf("expected 0 UpdateIssueStatus calls, got %d", len(store.updatedIssues))
+	}
+}
+
 func TestSyncCarStatuses_InProgressStatusKeepsLabel(t *testing.T) {
 	client := &mockSyncClient{}
 	store := &mockSyncStore{
@@ -0,0 +1,123 @@
+package car
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// attachmentsHeading introduces the attachment list Attach appends to a car
+// description.
+const attachmentsHeading = "## Attachments"
+
+// Attach binds the given uploaded attachments to carID and lists them in the
+// car's description, so engines know to read them. An attachment already
+// bound to another car is copied, letting one uploaded spec serve several
+// cars.
+func Attach(db *gorm.DB, carID string, ids []uint) ([]models.Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var c models.Car
+	if err := db.Select("id", "description").Where("id = ?", carID).First(&c).Error; err != nil {
+		return nil, fmt.Errorf("car: attach to %s: %w", carID, err)
+	}
+
+	var attached []models.Attachment
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			var a models.Attachment
+			if err := tx.First(&a, id).Error; err != nil {
+				return fmt.Errorf("attachment %d: %w", id, err)
+			}
+			switch a.CarID {
+			case carID:
+				continue
+			case "":
+				if err := tx.Model(&a).Update("car_id", carID).Error; err != nil {
+					return fmt.Errorf("attachment %d: %w", id, err)
+				}
+				a.CarID = carID
+			default:
+				a.ID = 0
+				a.CarID = carID
+				if err := tx.Create(&a).Error; err != nil {
+					return fmt.Errorf("copy attachment %d: %w", id, err)
+				}
+			}
+			attached = append(attached, a)
+		}
+		if len(attached) == 0 {
+			return nil
+		}
+		desc := appendAttachmentList(c.Description, attached)
+		return tx.Model(&models.Car{}).Where("id = ?", carID).Update("description", desc).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("car: attach to %s: %w", carID, err)
+	}
+	return attached, nil
+}
+
+// Attachments returns carID's attachments in upload order, without their
+// contents.
+func Attachments(db *gorm.DB, carID string) ([]models.Attachment, error) {
+	var atts []models.Attachment
+	err := db.Omit("data").Where("car_id = ?", carID).Order("id").Find(&atts).Error
+	if err != nil {
+		return nil, fmt.Errorf("car: attachments for %s: %w", carID, err)
+	}
+	return atts, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// AttachmentPath returns the worktree-relative path engines find a under.
+// The ID prefix keeps two uploads with the same name apart.
+func AttachmentPath(a models.Attachment) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(path.Base(a.Name), "_"), "._")
+	if name == "" {
+		name = "file"
+	}
+	return path.Join(models.AttachmentsDir, fmt.Sprintf("%d-%s", a.ID, name))
+}
+
+// appendAttachmentList adds one line per attachment under the attachments
+// heading, creating the heading on first use.
+func appendAttachmentList(desc string, atts []models.Attachment) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(desc, "\n"))
+	if !strings.Contains(desc, attachmentsHeading) {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(attachmentsHeading + "\n")
+		b.WriteString("Uploaded in the dispatch thread; read them from the worktree.\n")
+	} else {
+		b.WriteString("\n")
+	}
+	for _, a := range atts {
+		fmt.Fprintf(&b, "- `%s` (%s", AttachmentPath(a), a.Name)
+		if a.ContentType != "" {
+			fmt.Fprintf(&b, ", %s", a.ContentType)
+		}
+		fmt.Fprintf(&b, ", %s)\n", FormatBytes(a.Size))
+	}
+	return b.String()
+}
+
+// FormatBytes renders n as a short human-readable size (e.g. "3.1 KB").
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,93 @@
+package car
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testAttachDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.Attachment{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestAttach(t *testing.T) {
+	db := testAttachDB(t)
+	db.Create(&models.Car{ID: "car-a", Title: "A", Description: "Build the thing."})
+	db.Create(&models.Car{ID: "car-b", Title: "B"})
+	spec := models.Attachment{Name: "spec.md", ContentType: "text/markdown", Size: 3200, Data: []byte("# spec")}
+	shot := models.Attachment{Name: "my screen shot.png", Size: 12, Data: []byte("png")}
+	db.Create(&spec)
+	db.Create(&shot)
+
+	got, err := Attach(db, "car-a", []uint{spec.ID, shot.ID})
+	if err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("attached %d, want 2", len(got))
+	}
+	var c models.Car
+	db.First(&c, "id = ?", "car-a")
+	for _, want := range []string{
+		"Build the thing.\n\n## Attachments\n",
+		"- `.railyard-attachments/1-spec.md` (spec.md, text/markdown, 3.1 KB)",
+		"- `.railyard-attachments/2-my_screen_shot.png` (my screen shot.png, 12 B)",
+	} {
+		if !strings.Contains(c.Description, want) {
+			t.Errorf("description missing %q:\n%s", want, c.Description)
+		}
+	}
+
+	// Re-attaching is a no-op; attaching to another car copies the row.
+	if got, err := Attach(db, "car-a", []uint{spec.ID}); err != nil || len(got) != 0 {
+		t.Errorf("re-attach: got %d, err %v; want 0, nil", len(got), err)
+	}
+	got, err = Attach(db, "car-b", []uint{spec.ID})
+	if err != nil || len(got) != 1 || got[0].ID == spec.ID {
+		t.Fatalf("attach to second car: %+v, %v", got, err)
+	}
+	atts, _ := Attachments(db, "car-a")
+	if len(atts) != 2 || atts[0].Data != nil {
+		t.Errorf("Attachments(car-a) = %+v, want 2 without data", atts)
+	}
+	if atts, _ := Attachments(db, "car-b"); len(atts) != 1 {
+		t.Errorf("Attachments(car-b) = %d, want 1", len(atts))
+	}
+
+	if _, err := Attach(db, "car-a", []uint{999}); err == nil {
+		t.Error("missing attachment: want error")
+	}
+	if _, err := Attach(db, "car-x", []uint{spec.ID}); err == nil {
+		t.Error("missing car: want error")
+	}
+}
+
+func TestAttachmentPath(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"spec.md", ".railyard-attachments/7-spec.md"},
+		{"../../etc/passwd", ".railyard-attachments/7-passwd"},
+		{"...", ".railyard-attachments/7-file"},
+		{"résumé v2.pdf", ".railyard-attachments/7-r_sum_v2.pdf"},
+	}
+	for _, tt := range tests {
+		if got := AttachmentPath(models.Attachment{ID: 7, Name: tt.name}); got != tt.want {
+			t.Errorf("AttachmentPath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
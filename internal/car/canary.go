@@ -0,0 +1,140 @@
+package car
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// CanaryGroup holds merge and cycle-time figures for one side of a canary
+// comparison.
+type CanaryGroup struct {
+	Cars         int           // cars claimed since the canary started
+	Merged       int           // of those, cars merged
+	GateFailures int           // cars the merge gate rejected at least once
+	FirstPass    int           // merged cars the merge gate never rejected
+	MedianCycle  time.Duration // median claim-to-completion time; 0 without completed cars
+	Completed    int           // cars with a completion time
+}
+
+// MergeRate is the share of cars that reached the merge gate and passed it
+// on the first attempt, or -1 when none has reached it yet.
+func (g CanaryGroup) MergeRate() float64 {
+	reached := g.FirstPass + g.GateFailures
+	if reached == 0 {
+		return -1
+	}
+	return float64(g.FirstPass) / float64(reached)
+}
+
+// CanaryComparison compares a track's canary group with its control group:
+// the track's other cars claimed over the same period.
+type CanaryComparison struct {
+	Track   string
+	Since   time.Time // first claim of a canary car; zero when none yet
+	Canary  CanaryGroup
+	Control CanaryGroup
+}
+
+// GateFailureNote prefixes the progress note the yardmaster writes when a
+// car fails the merge gate ("switch:test-failed: ...").
+const GateFailureNote = "switch:"
+
+// CompareCanary measures a track's canary cars against the control cars
+// claimed since the first canary car was.
+func CompareCanary(db *gorm.DB, track string) (*CanaryComparison, error) {
+	cmp := &CanaryComparison{Track: track}
+	var first models.Car
+	err := db.Where("track = ? AND canary = ? AND claimed_at IS NOT NULL", track, true).
+		Order("claimed_at").Limit(1).Find(&first).Error
+	if err != nil {
+		return nil, fmt.Errorf("car: canary start for %s: %w", track, err)
+	}
+	if first.ClaimedAt == nil {
+		return cmp, nil
+	}
+	cmp.Since = *first.ClaimedAt
+
+	var cars []models.Car
+	if err := db.Select("id", "status", "canary", "claimed_at", "completed_at").
+		Where("track = ? AND claimed_at >= ?", track, cmp.Since).Find(&cars).Error; err != nil {
+		return nil, fmt.Errorf("car: canary cars for %s: %w", track, err)
+	}
+	ids := make([]string, len(cars))
+	for i, c := range cars {
+		ids[i] = c.ID
+	}
+	failed := make(map[string]bool)
+	if len(ids) > 0 {
+		var failedIDs []string
+		if err := db.Model(&models.CarProgress{}).Distinct("car_id").
+			Where("car_id IN ? AND note LIKE ?", ids, GateFailureNote+"%").
+			Pluck("car_id", &failedIDs).Error; err != nil {
+			return nil, fmt.Errorf("car: canary gate failures for %s: %w", track, err)
+		}
+		for _, id := range failedIDs {
+			failed[id] = true
+		}
+	}
+
+	var canaryCycles, controlCycles []time.Duration
+	for _, c := range cars {
+		g, cycles := &cmp.Control, &controlCycles
+		if c.Canary {
+			g, cycles = &cmp.Canary, &canaryCycles
+		}
+		g.Cars++
+		if c.Status == "merged" {
+			g.Merged++
+			if !failed[c.ID] {
+				g.FirstPass++
+			}
+		}
+		if failed[c.ID] {
+			g.GateFailures++
+		}
+		if c.ClaimedAt != nil && c.CompletedAt != nil {
+			if d := c.CompletedAt.Sub(*c.ClaimedAt); d > 0 {
+				*cycles = append(*cycles, d)
+			}
+		}
+	}
+	cmp.Canary.Completed, cmp.Canary.MedianCycle = len(canaryCycles), medianDuration(canaryCycles)
+	cmp.Control.Completed, cmp.Control.MedianCycle = len(controlCycles), medianDuration(controlCycles)
+	return cmp, nil
+}
+
+func medianDuration(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	return ds[len(ds)/2]
+}
+
+// Verdict summarizes the comparison in one line: whether the canary is
+// merging and finishing cars at least as well as the control group.
+func (c *CanaryComparison) Verdict() string {
+	if c.Canary.Cars == 0 {
+		return "no canary cars claimed yet"
+	}
+	cr, kr := c.Canary.MergeRate(), c.Control.MergeRate()
+	if cr < 0 || kr < 0 {
+		return "not enough merges yet to compare"
+	}
+	var worse []string
+	if cr < kr {
+		worse = append(worse, "merge rate")
+	}
+	if c.Canary.MedianCycle > 0 && c.Control.MedianCycle > 0 && c.Canary.MedianCycle > c.Control.MedianCycle {
+		worse = append(worse, "cycle time")
+	}
+	if len(worse) > 0 {
+		return "canary is behind on " + strings.Join(worse, " and ")
+	}
+	return "canary is on par with or ahead of control"
+}
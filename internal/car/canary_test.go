@@ -0,0 +1,66 @@
+package car
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestCompareCanary(t *testing.T) {
+	db := testDB(t)
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	at := func(h float64) *time.Time {
+		ts := base.Add(time.Duration(h * float64(time.Hour)))
+		return &ts
+	}
+	cars := []models.Car{
+		// Claimed before the canary started: not counted.
+		{ID: "car-old", Title: "old", Track: "backend", Status: "merged", ClaimedAt: at(-5), CompletedAt: at(-4)},
+		{ID: "car-c1", Title: "c1", Track: "backend", Status: "merged", Canary: true, ClaimedAt: at(0), CompletedAt: at(1)},
+		{ID: "car-c2", Title: "c2", Track: "backend", Status: "merged", Canary: true, ClaimedAt: at(1), CompletedAt: at(2)},
+		{ID: "car-k1", Title: "k1", Track: "backend", Status: "merged", ClaimedAt: at(0.5), CompletedAt: at(3.5)},
+		{ID: "car-k2", Title: "k2", Track: "backend", Status: "blocked", ClaimedAt: at(1), CompletedAt: at(4)},
+		{ID: "car-k3", Title: "k3", Track: "backend", Status: "in_progress", ClaimedAt: at(2)},
+		{ID: "car-other", Title: "other", Track: "frontend", Status: "merged", Canary: true, ClaimedAt: at(0), CompletedAt: at(9)},
+	}
+	if err := db.Create(&cars).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&models.CarProgress{CarID: "car-k2", EngineID: "yardmaster", Note: "switch:test-failed: tests failed: exit 1"})
+	db.Create(&models.CarProgress{CarID: "car-c1", EngineID: "eng-1", Note: "switched approach"})
+
+	cmp, err := CompareCanary(db, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Since.Equal(base) {
+		t.Errorf("Since = %v, want %v", cmp.Since, base)
+	}
+	if c := cmp.Canary; c.Cars != 2 || c.Merged != 2 || c.FirstPass != 2 || c.GateFailures != 0 || c.MedianCycle != time.Hour {
+		t.Errorf("canary = %+v", c)
+	}
+	if k := cmp.Control; k.Cars != 3 || k.Merged != 1 || k.GateFailures != 1 || k.MergeRate() != 0.5 || k.MedianCycle != 3*time.Hour {
+		t.Errorf("control = %+v", k)
+	}
+	if got := cmp.Verdict(); got != "canary is on par with or ahead of control" {
+		t.Errorf("Verdict = %q", got)
+	}
+
+	cmp.Canary.MedianCycle = 5 * time.Hour
+	cmp.Canary.FirstPass, cmp.Canary.GateFailures = 1, 2
+	if got := cmp.Verdict(); got != "canary is behind on merge rate and cycle time" {
+		t.Errorf("Verdict = %q", got)
+	}
+}
+
+func TestCompareCanary_NoCanaryCars(t *testing.T) {
+	db := testDB(t)
+	cmp, err := CompareCanary(db, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Since.IsZero() || cmp.Verdict() != "no canary cars claimed yet" {
+		t.Errorf("cmp = %+v, verdict %q", cmp, cmp.Verdict())
+	}
+}
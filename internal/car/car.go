@@ -12,6 +12,7 @@ import (
 
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"github.com/zulandar/railyard/pkg/plugin"
 	"gorm.io/gorm"
 )
@@ -31,8 +32,9 @@ func publish(bus events.Bus, topic plugin.EventType, payload any) {
 type CreateOpts struct {
 	Title        string
 	Description  string
-	Type         string // task, epic, bug, spike
+	Type         string // task, epic, bug, spike, conflict
 	Priority     int    // 0=critical → 4=backlog
+	Estimate     int    // relative size for smallest_first claiming; 0 = unestimated
 	Track        string
 	ParentID     string
 	DesignNotes  string
@@ -41,6 +43,12 @@ type CreateOpts struct {
 	BranchPrefix string // e.g., "ry/alice"
 	BaseBranch   string // base branch for merging (empty = "main")
 	RequestedBy  string // who requested this car (username or owner)
+	Owner        string // human owner/reviewer; a leading "@" is stripped
+	RevertOf     string // car whose merge this car reverts
+	ConflictOf   string // car whose merge conflict this car resolves
+	ClonedFrom   string // car this car was cloned from (ry car clone --link)
+	IDFormat     IDFormat
+	IgnorePause  bool // create even while the yard is paused (operator override)
 }
 
 // ListFilters holds optional filters for listing cars.
@@ -49,6 +57,7 @@ type ListFilters struct {
 	Status   string
 	Type     string
 	Assignee string
+	Owner    string
 	ParentID string
 }
 
@@ -75,17 +84,29 @@ type StatusCount struct {
 //     branch merged externally; open → merged/done also covers epic auto-close.
 //   - blocked → done: UnblockDeps test-failed retry and the retry-merge action.
 //   - done → pr_open → pr_review: PR mode + inspect review claims.
+//   - merged → reverted: the car's revert car (RevertOf) merged.
+//   - blocked → merged: the car's conflict car (ConflictOf) merged, carrying
+//     the car's branch with it.
+//   - done → needs-attention: Switch found the car's branch deleted or
+//     rewritten on origin; a human moves it back to done (branch restored)
+//     or open (redo the work).
+//
+// Deliberately absent: merge-failed/cancelled → open. Both stay terminal;
+// car.Rerun (ry car rerun) reopens them itself on a fresh branch, which a
+// plain status update would skip.
 var ValidTransitions = map[string][]string{
-	"draft":        {"open"},
-	"open":         {"ready", "cancelled", "blocked", "done", "merged"},
-	"ready":        {"claimed", "blocked", "merged"},
-	"claimed":      {"in_progress", "done", "open", "blocked", "merged"},
-	"in_progress":  {"done", "open", "blocked", "merged"},
-	"done":         {"merged", "merge-failed", "pr_open"},
-	"blocked":      {"open", "ready", "done"},
-	"merge-failed": {"done", "cancelled"},
-	"pr_open":      {"open", "merged", "cancelled", "pr_review"},
-	"pr_review":    {"pr_open", "merged", "cancelled"},
+	"draft":           {"open"},
+	"open":            {"ready", "cancelled", "blocked", "done", "merged"},
+	"ready":           {"claimed", "blocked", "merged"},
+	"claimed":         {"in_progress", "done", "open", "blocked", "merged"},
+	"in_progress":     {"done", "open", "blocked", "merged"},
+	"done":            {"merged", "merge-failed", "pr_open", "needs-attention"},
+	"blocked":         {"open", "ready", "done", "merged"},
+	"merge-failed":    {"done", "cancelled"},
+	"needs-attention": {"done", "open", "cancelled"},
+	"pr_open":         {"open", "merged", "cancelled", "pr_review"},
+	"pr_review":       {"pr_open", "merged", "cancelled"},
+	"merged":          {"reverted"},
 }
 
 // GenerateID creates a random car ID in car-xxxxxxxx format (8-char hex).
@@ -124,6 +145,9 @@ var validCarTypes = map[string]bool{
 	"epic":  true,
 	"bug":   true,
 	"spike": true,
+	// conflict cars are spawned by the yardmaster to resolve another car's
+	// merge conflict; they are a distinct type so reports can count them.
+	"conflict": true,
 }
 
 // Create creates a new car with an auto-generated ID.
@@ -142,13 +166,18 @@ func CreateWithBus(db *gorm.DB, bus events.Bus, opts CreateOpts) (*models.Car, e
 	if opts.Title == "" {
 		return nil, fmt.Errorf("car: title is required")
 	}
+	if !opts.IgnorePause {
+		if err := yard.CheckRunning(db); err != nil {
+			return nil, fmt.Errorf("car: %w", err)
+		}
+	}
 
 	// Validate parent and inherit track if needed (before track check).
 	if opts.ParentID != "" {
 		var parent models.Car
 		if err := db.Where("id = ?", opts.ParentID).First(&parent).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("car: parent not found: %s", opts.ParentID)
+				return nil, fmt.Errorf("car: parent %w: %s", ErrNotFound, opts.ParentID)
 			}
 			return nil, fmt.Errorf("car: check parent %s: %w", opts.ParentID, err)
 		}
@@ -168,7 +197,10 @@ func CreateWithBus(db *gorm.DB, bus events.Bus, opts CreateOpts) (*models.Car, e
 		opts.Type = "task"
 	}
 	if !validCarTypes[opts.Type] {
-		return nil, fmt.Errorf("car: invalid type %q (valid: task, epic, bug, spike)", opts.Type)
+		return nil, fmt.Errorf("car: invalid type %q (valid: task, epic, bug, spike, conflict)", opts.Type)
+	}
+	if opts.Estimate < 0 {
+		return nil, fmt.Errorf("car: estimate must not be negative")
 	}
 
 	// Insert with retry on duplicate-key: the old COUNT-then-INSERT check was
@@ -177,7 +209,7 @@ func CreateWithBus(db *gorm.DB, bus events.Bus, opts CreateOpts) (*models.Car, e
 	var car models.Car
 	const maxIDAttempts = 5
 	for attempt := 0; ; attempt++ {
-		id, err := generateID()
+		id, err := opts.IDFormat.Generate(opts.Track, opts.Title)
 		if err != nil {
 			return nil, err
 		}
@@ -189,12 +221,17 @@ func CreateWithBus(db *gorm.DB, bus events.Bus, opts CreateOpts) (*models.Car, e
 			Type:        opts.Type,
 			Status:      "draft",
 			Priority:    opts.Priority,
+			Estimate:    opts.Estimate,
 			Track:       opts.Track,
 			BaseBranch:  opts.BaseBranch,
 			DesignNotes: opts.DesignNotes,
 			Acceptance:  opts.Acceptance,
 			SkipTests:   opts.SkipTests,
 			RequestedBy: opts.RequestedBy,
+			Owner:       NormalizeOwner(opts.Owner),
+			RevertOf:    opts.RevertOf,
+			ConflictOf:  opts.ConflictOf,
+			ClonedFrom:  opts.ClonedFrom,
 			Branch:      ComputeBranch(opts.BranchPrefix, opts.Track, id),
 		}
 		if opts.ParentID != "" {
@@ -211,6 +248,15 @@ func CreateWithBus(db *gorm.DB, bus events.Bus, opts CreateOpts) (*models.Car, e
 		return nil, fmt.Errorf("car: create: %w", err)
 	}
 
+	// Priority carries a column default, so gorm omits a zero (P0) value on
+	// insert and the row lands at the default. Write P0 explicitly.
+	if opts.Priority == 0 {
+		if err := db.Model(&models.Car{}).Where("id = ?", car.ID).Update("priority", 0).Error; err != nil {
+			return nil, fmt.Errorf("car: create: set priority: %w", err)
+		}
+		car.Priority = 0
+	}
+
 	publish(bus, plugin.CarCreated, plugin.CarCreatedEvent{
 		CarID:       car.ID,
 		Track:       car.Track,
@@ -227,7 +273,7 @@ func Get(db *gorm.DB, id string) (*models.Car, error) {
 	var car models.Car
 	if err := db.Preload("Deps").Preload("Progress").Where("id = ?", id).First(&car).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("car: not found: %s", id)
+			return nil, fmt.Errorf("car: %w: %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("car: get %s: %w", id, err)
 	}
@@ -250,6 +296,9 @@ func List(db *gorm.DB, filters ListFilters) ([]models.Car, error) {
 	if filters.Assignee != "" {
 		q = q.Where("assignee = ?", filters.Assignee)
 	}
+	if filters.Owner != "" {
+		q = q.Where("owner = ?", NormalizeOwner(filters.Owner))
+	}
 	if filters.ParentID != "" {
 		q = q.Where("parent_id = ?", filters.ParentID)
 	}
@@ -290,6 +339,9 @@ func Search(db *gorm.DB, query string, filters ListFilters, limit int) ([]models
 	if filters.Assignee != "" {
 		q = q.Where("assignee = ?", filters.Assignee)
 	}
+	if filters.Owner != "" {
+		q = q.Where("owner = ?", NormalizeOwner(filters.Owner))
+	}
 	if filters.ParentID != "" {
 		q = q.Where("parent_id = ?", filters.ParentID)
 	}
@@ -311,6 +363,15 @@ func Search(db *gorm.DB, query string, filters ListFilters, limit int) ([]models
 // re-read and retry (railyard-5df).
 var ErrConcurrentModification = errors.New("car: concurrent modification")
 
+// ErrNotFound is wrapped by the errors returned when a car ID (or a parent
+// or dependency) does not exist, so callers can tell a missing car from a
+// database failure with errors.Is.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidTransition is returned by Update/UpdateWithBus when the requested
+// status is not reachable from the car's current status (see ValidTransitions).
+var ErrInvalidTransition = errors.New("car: invalid status transition")
+
 // Update modifies car fields. Status transitions are validated against ValidTransitions.
 // Equivalent to UpdateWithBus(db, nil, id, updates) — no events are published.
 func Update(db *gorm.DB, id string, updates map[string]interface{}) error {
@@ -333,7 +394,7 @@ func UpdateWithBus(db *gorm.DB, bus events.Bus, id string, updates map[string]in
 	var car models.Car
 	if err := db.Where("id = ?", id).First(&car).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("car: not found: %s", id)
+			return fmt.Errorf("car: %w: %s", ErrNotFound, id)
 		}
 		return fmt.Errorf("car: get %s for update: %w", id, err)
 	}
@@ -343,7 +404,7 @@ func UpdateWithBus(db *gorm.DB, bus events.Bus, id string, updates map[string]in
 	if newStatus, ok := updates["status"].(string); ok {
 		if !isValidTransition(car.Status, newStatus) {
 			valid := ValidTransitions[car.Status]
-			return fmt.Errorf("car: invalid status transition from %q to %q; valid transitions: %v", car.Status, newStatus, valid)
+			return fmt.Errorf("%w from %q to %q; valid transitions: %v", ErrInvalidTransition, car.Status, newStatus, valid)
 		}
 
 		now := time.Now()
@@ -353,6 +414,13 @@ func UpdateWithBus(db *gorm.DB, bus events.Bus, id string, updates map[string]in
 		if newStatus == "done" {
 			updates["completed_at"] = now
 		}
+		// Leaving blocked clears the reason, so a reopened car is not
+		// later mistaken for one still waiting on the original block.
+		if car.Status == "blocked" && newStatus != "blocked" {
+			if _, ok := updates["blocked_reason"]; !ok {
+				updates["blocked_reason"] = ""
+			}
+		}
 	}
 
 	// For status changes, the UPDATE is conditional on the status the
@@ -457,7 +525,7 @@ func GetChildren(db *gorm.DB, parentID string) ([]models.Car, error) {
 		return nil, fmt.Errorf("car: check parent %s: %w", parentID, err)
 	}
 	if count == 0 {
-		return nil, fmt.Errorf("car: parent not found: %s", parentID)
+		return nil, fmt.Errorf("car: parent %w: %s", ErrNotFound, parentID)
 	}
 
 	var children []models.Car
@@ -496,7 +564,7 @@ func PublishWithBus(db *gorm.DB, bus events.Bus, id string, recursive bool) (int
 	var c models.Car
 	if err := db.Where("id = ?", id).First(&c).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, fmt.Errorf("car: not found: %s", id)
+			return 0, fmt.Errorf("car: %w: %s", ErrNotFound, id)
 		}
 		return 0, fmt.Errorf("car: get %s for publish: %w", id, err)
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"github.com/zulandar/railyard/pkg/plugin"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -27,6 +28,7 @@ func testDB(t *testing.T) *gorm.DB {
 		&models.Car{},
 		&models.CarDep{},
 		&models.CarProgress{},
+		&models.RailyardConfig{},
 	); err != nil {
 		t.Fatalf("migrate test db: %v", err)
 	}
@@ -105,6 +107,22 @@ func TestCreate_ExplicitType(t *testing.T) {
 	}
 }
 
+func TestCreate_CriticalPriorityPersisted(t *testing.T) {
+	db := testDB(t)
+
+	car := createCar(t, db, CreateOpts{Title: "Urgent", Track: "backend", Priority: 0})
+	if car.Priority != 0 {
+		t.Errorf("returned Priority = %d, want 0", car.Priority)
+	}
+	got, err := Get(db, car.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Priority != 0 {
+		t.Errorf("stored Priority = %d, want 0", got.Priority)
+	}
+}
+
 func TestCreate_MissingTitle(t *testing.T) {
 	db := testDB(t)
 
@@ -167,8 +185,24 @@ func TestCreate_InvalidParent(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent parent")
 	}
-	if !strings.Contains(err.Error(), "parent not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "parent not found")
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "parent not found") {
+		t.Errorf("error = %q, want ErrNotFound containing %q", err.Error(), "parent not found")
+	}
+}
+
+func TestCreate_PausedYard(t *testing.T) {
+	db := testDB(t)
+	if _, _, err := yard.Pause(db, "incident", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Create(db, CreateOpts{Title: "blocked", Track: "backend", BranchPrefix: "ry/test"})
+	if !errors.Is(err, yard.ErrPaused) {
+		t.Fatalf("Create while paused: err = %v, want ErrPaused", err)
+	}
+	c, err := Create(db, CreateOpts{Title: "hotfix", Track: "backend", BranchPrefix: "ry/test", IgnorePause: true})
+	if err != nil || c.Title != "hotfix" {
+		t.Errorf("Create with IgnorePause = %v, %v", c, err)
 	}
 }
 
@@ -260,8 +294,8 @@ func TestGet_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent ID")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 }
 
@@ -535,6 +569,27 @@ func TestUpdate_AnyToBlocked(t *testing.T) {
 	}
 }
 
+func TestUpdate_UnblockClearsBlockedReason(t *testing.T) {
+	db := testDB(t)
+
+	car := createCar(t, db, CreateOpts{Title: "Policy block", Track: "backend"})
+	db.Model(&models.Car{}).Where("id = ?", car.ID).Updates(map[string]interface{}{
+		"status":         "blocked",
+		"blocked_reason": models.BlockedReasonCommandPolicy,
+	})
+
+	if err := Update(db, car.ID, map[string]interface{}{"status": "open"}); err != nil {
+		t.Fatalf("blocked→open: %v", err)
+	}
+	got, err := Get(db, car.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.BlockedReason != "" {
+		t.Errorf("BlockedReason = %q, want cleared", got.BlockedReason)
+	}
+}
+
 func TestUpdate_NotFound(t *testing.T) {
 	db := testDB(t)
 
@@ -542,8 +597,8 @@ func TestUpdate_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent ID")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 }
 
@@ -611,8 +666,8 @@ func TestGetChildren_NonexistentParent(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent parent")
 	}
-	if !strings.Contains(err.Error(), "parent not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "parent not found")
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "parent not found") {
+		t.Errorf("error = %q, want ErrNotFound containing %q", err.Error(), "parent not found")
 	}
 }
 
@@ -731,8 +786,8 @@ func TestPublish_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent ID")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 }
 
@@ -1033,7 +1088,7 @@ func TestCreateWithBus_InvalidType(t *testing.T) {
 func TestCreateWithBus_ValidTypes(t *testing.T) {
 	db := testDB(t)
 
-	for _, typ := range []string{"task", "epic", "bug", "spike", ""} {
+	for _, typ := range []string{"task", "epic", "bug", "spike", "conflict", ""} {
 		if _, err := Create(db, CreateOpts{Title: "ok " + typ, Track: "backend", Type: typ}); err != nil {
 			t.Errorf("Create(type=%q) error: %v", typ, err)
 		}
@@ -1074,6 +1129,27 @@ func TestCreateWithBus_RetriesOnDuplicateID(t *testing.T) {
 	}
 }
 
+// TestCreate_IDFormat: the track prefix applies to the track the car ends up
+// on, including one inherited from its parent epic, and the branch embeds the
+// generated ID.
+func TestCreate_IDFormat(t *testing.T) {
+	db := testDB(t)
+	format := IDFormat{Slug: true, TrackPrefixes: map[string]string{"backend": "be-"}}
+
+	epic := createCar(t, db, CreateOpts{Title: "Auth epic", Track: "backend", Type: "epic", IDFormat: format})
+	child := createCar(t, db, CreateOpts{Title: "Add login form", ParentID: epic.ID, BranchPrefix: "ry/alice", IDFormat: format})
+
+	if !strings.HasPrefix(epic.ID, "be-auth-epic-") {
+		t.Errorf("epic ID = %q, want be-auth-epic-<hex>", epic.ID)
+	}
+	if !strings.HasPrefix(child.ID, "be-add-login-form-") {
+		t.Errorf("child ID = %q, want be-add-login-form-<hex>", child.ID)
+	}
+	if child.Branch != "ry/alice/backend/"+child.ID {
+		t.Errorf("branch = %q, want it to end in the car ID", child.Branch)
+	}
+}
+
 // --- Search tests ---
 
 func TestSearch_TitleMatch(t *testing.T) {
@@ -1,6 +1,7 @@
 package car
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -50,6 +51,57 @@ func TestGenerateID_HexChars(t *testing.T) {
 	}
 }
 
+func TestIDFormat_Generate(t *testing.T) {
+	tests := []struct {
+		name   string
+		format IDFormat
+		track  string
+		title  string
+		want   *regexp.Regexp
+	}{
+		{"zero value", IDFormat{}, "backend", "Anything", regexp.MustCompile(`^car-[0-9a-f]{8}$`)},
+		{"prefix and length", IDFormat{Prefix: "app-", Length: 5}, "backend", "x", regexp.MustCompile(`^app-[0-9a-f]{5}$`)},
+		{"track prefix", IDFormat{Prefix: "app-", TrackPrefixes: map[string]string{"backend": "be-"}}, "backend", "x", regexp.MustCompile(`^be-[0-9a-f]{8}$`)},
+		{"other track", IDFormat{TrackPrefixes: map[string]string{"backend": "be-"}}, "frontend", "x", regexp.MustCompile(`^car-[0-9a-f]{8}$`)},
+		{"slug", IDFormat{Prefix: "be-", Length: 4, Slug: true}, "backend", "Fix login timeout!", regexp.MustCompile(`^be-fix-login-timeout-[0-9a-f]{4}$`)},
+		{"slug trimmed to fit", IDFormat{Slug: true}, "backend", "Refactor the configuration loader and validation", regexp.MustCompile(`^car-refactor-the-[0-9a-f]{8}$`)},
+		{"empty slug", IDFormat{Slug: true}, "backend", "!!!", regexp.MustCompile(`^car-[0-9a-f]{8}$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.format.Generate(tt.track, tt.title)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if !tt.want.MatchString(id) {
+				t.Errorf("ID %q does not match %s", id, tt.want)
+			}
+			if len(id) > 32 {
+				t.Errorf("ID %q longer than the 32-char column", id)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in     string
+		maxLen int
+		want   string
+	}{
+		{"Add OAuth2 login", 20, "add-oauth2-login"},
+		{"Add OAuth2 login", 10, "add-oauth2"},
+		{"Internationalization", 8, "internat"},
+		{"  --  ", 10, ""},
+		{"anything", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in, tt.maxLen); got != tt.want {
+			t.Errorf("Slugify(%q, %d) = %q, want %q", tt.in, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
 func TestComputeBranch(t *testing.T) {
 	tests := []struct {
 		prefix string
@@ -189,6 +241,8 @@ func TestValidTransitions_ProductionEdges(t *testing.T) {
 		{"pr_review", "pr_open", "inspect Store.ReleaseReview / stale pr_review cleanup"},
 		{"pr_review", "merged", "operator recovery (PR merged externally mid-review)"},
 		{"pr_review", "cancelled", "operator recovery"},
+		{"merged", "reverted", "yardmaster markRevertedOriginal"},
+		{"blocked", "merged", "yardmaster markConflictResolvedOriginal"},
 	}
 	for _, e := range edges {
 		if !IsValidTransition(e.from, e.to) {
@@ -0,0 +1,153 @@
+package car
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// CloneOpts holds parameters for cloning a car.
+type CloneOpts struct {
+	Track        string // track for the clone; empty keeps the original's
+	Link         bool   // record the original in the clone's ClonedFrom
+	BranchPrefix string
+	BaseBranch   string // base branch for the clone (empty = "main")
+	RequestedBy  string
+	IDFormat     IDFormat
+}
+
+// Clone creates a draft car with the original's title, description,
+// acceptance criteria, design notes, type, priority, and estimate, for
+// re-running similar work. Status, assignee, branch, progress, and deps are
+// not copied. The clone stays under the original's epic unless it moves to
+// another track.
+func Clone(db *gorm.DB, id string, opts CloneOpts) (*models.Car, error) {
+	orig, err := Get(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if orig.Type == "conflict" {
+		return nil, fmt.Errorf("car: %s is a conflict car; its work is tied to the original merge and cannot be cloned", id)
+	}
+
+	create := CreateOpts{
+		Title:        orig.Title,
+		Description:  orig.Description,
+		Type:         orig.Type,
+		Priority:     orig.Priority,
+		Estimate:     orig.Estimate,
+		Track:        orig.Track,
+		DesignNotes:  orig.DesignNotes,
+		Acceptance:   orig.Acceptance,
+		SkipTests:    orig.SkipTests,
+		BranchPrefix: opts.BranchPrefix,
+		BaseBranch:   opts.BaseBranch,
+		RequestedBy:  opts.RequestedBy,
+		Owner:        orig.Owner,
+		IDFormat:     opts.IDFormat,
+	}
+	if opts.Track != "" && opts.Track != orig.Track {
+		create.Track = opts.Track
+	} else if orig.ParentID != nil {
+		create.ParentID = *orig.ParentID
+	}
+
+	if opts.Link {
+		create.ClonedFrom = orig.ID
+	}
+	return Create(db, create)
+}
+
+// rerunStatuses are the statuses Rerun accepts: the car's attempt ended
+// without merging and nothing else will pick it up again.
+var rerunStatuses = map[string]bool{"merge-failed": true, "cancelled": true}
+
+// rerunNotePrefix starts the progress note Rerun writes; Rerun counts these
+// notes to number the fresh branch.
+const rerunNotePrefix = "Re-run #"
+
+// Rerun resets a merge-failed or cancelled car to open on a fresh branch so
+// an engine redoes the work from the current base branch. The car keeps its
+// ID, progress notes, and deps; a progress note records the previous status
+// and branch. It returns the old branch, which is left as it was.
+// Equivalent to RerunWithBus(db, nil, id).
+func Rerun(db *gorm.DB, id string) (oldBranch string, err error) {
+	return RerunWithBus(db, nil, id)
+}
+
+// RerunWithBus is Rerun, publishing [plugin.CarStatusChanged] to bus once the
+// reset commits. Both statuses are terminal in ValidTransitions, so this is
+// the only path that reopens such a car.
+func RerunWithBus(db *gorm.DB, bus events.Bus, id string) (oldBranch string, err error) {
+	c, err := Get(db, id)
+	if err != nil {
+		return "", err
+	}
+	if !rerunStatuses[c.Status] {
+		return "", fmt.Errorf("car: %s is %s; only merge-failed or cancelled cars can be re-run", id, c.Status)
+	}
+	if c.Type == "epic" || c.Type == "conflict" {
+		return "", fmt.Errorf("car: %s is a %s car and cannot be re-run", id, c.Type)
+	}
+
+	var runs int64
+	if err := db.Model(&models.CarProgress{}).
+		Where("car_id = ? AND note LIKE ?", id, rerunNotePrefix+"%").
+		Count(&runs).Error; err != nil {
+		return "", fmt.Errorf("car: count re-runs of %s: %w", id, err)
+	}
+	n := int(runs) + 1
+	branch := fmt.Sprintf("%s-rerun%d", rerunBaseBranch(c.Branch), n)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Car{}).Where("id = ? AND status = ?", id, c.Status).Updates(map[string]interface{}{
+			"status":                "open",
+			"branch":                branch,
+			"assignee":              "",
+			"blocked_reason":        "",
+			"claimed_at":            nil,
+			"completed_at":          nil,
+			"preempted_at":          nil,
+			"draft_pr_at":           nil,
+			"pushed_head":           "",
+			"last_rebase_base_head": "",
+			"canary":                false,
+		})
+		if res.Error != nil {
+			return fmt.Errorf("car: re-run %s: %w", id, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("car: re-run %s: status changed concurrently", id)
+		}
+		return tx.Create(&models.CarProgress{
+			CarID:        id,
+			EngineID:     "dispatch",
+			Note:         fmt.Sprintf("%s%d: reset from %s to open on branch %s (previous branch %s)", rerunNotePrefix, n, c.Status, branch, c.Branch),
+			FilesChanged: "[]",
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	slog.Info("car: status transition", "car", id, "from", c.Status, "to", "open", "rerun", n)
+	publish(bus, plugin.CarStatusChanged, plugin.CarStatusChangedEvent{
+		CarID:     id,
+		OldStatus: c.Status,
+		NewStatus: "open",
+	})
+	return c.Branch, nil
+}
+
+// rerunBaseBranch strips an earlier re-run suffix so repeated re-runs
+// number a single branch name instead of nesting suffixes.
+func rerunBaseBranch(branch string) string {
+	if i := strings.LastIndex(branch, "-rerun"); i > 0 {
+		return branch[:i]
+	}
+	return branch
+}
@@ -0,0 +1,105 @@
+package car
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/pkg/plugin"
+)
+
+func TestClone(t *testing.T) {
+	db := testDB(t)
+	epic := createCar(t, db, CreateOpts{Title: "Payments", Track: "backend", Type: "epic"})
+	orig := createCar(t, db, CreateOpts{
+		Title: "Add refunds", Track: "backend", ParentID: epic.ID, Priority: 1, Estimate: 3,
+		Description: "desc", Acceptance: "acc", DesignNotes: "design", Owner: "alice",
+	})
+	db.Model(&models.Car{}).Where("id = ?", orig.ID).Updates(map[string]interface{}{"status": "merged", "assignee": "eng-1"})
+
+	c, err := Clone(db, orig.ID, CloneOpts{BranchPrefix: "ry", RequestedBy: "bob"})
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if c.ID == orig.ID || c.Status != "draft" || c.Assignee != "" || c.ClonedFrom != "" {
+		t.Errorf("clone = %+v", c)
+	}
+	if c.Title != orig.Title || c.Description != "desc" || c.Acceptance != "acc" || c.DesignNotes != "design" ||
+		c.Priority != 1 || c.Estimate != 3 || c.Owner != "alice" || c.RequestedBy != "bob" {
+		t.Errorf("clone did not copy the original's fields: %+v", c)
+	}
+	if c.ParentID == nil || *c.ParentID != epic.ID || c.Branch != "ry/backend/"+c.ID {
+		t.Errorf("clone parent/branch = %v %q", c.ParentID, c.Branch)
+	}
+
+	// Another track leaves the epic; --link records the original.
+	other, err := Clone(db, orig.ID, CloneOpts{Track: "frontend", Link: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got models.Car
+	db.First(&got, "id = ?", other.ID)
+	if got.Track != "frontend" || got.ParentID != nil || got.ClonedFrom != orig.ID {
+		t.Errorf("linked clone = %+v", got)
+	}
+
+	if _, err := Clone(db, "car-missing", CloneOpts{}); err == nil {
+		t.Error("cloned a missing car")
+	}
+}
+
+func TestRerun(t *testing.T) {
+	db := testDB(t)
+	c := createCar(t, db, CreateOpts{Title: "Flaky", Track: "backend", BranchPrefix: "ry"})
+	if _, err := Rerun(db, c.ID); err == nil || !strings.Contains(err.Error(), "only merge-failed or cancelled") {
+		t.Fatalf("Rerun of a draft car = %v", err)
+	}
+
+	db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+		"status": "merge-failed", "assignee": "eng-1", "pushed_head": "abc123",
+	})
+	db.Create(&models.CarProgress{CarID: c.ID, Note: "first attempt", FilesChanged: "[]"})
+
+	old, err := Rerun(db, c.ID)
+	if err != nil {
+		t.Fatalf("Rerun: %v", err)
+	}
+	if old != c.Branch {
+		t.Errorf("old branch = %q, want %q", old, c.Branch)
+	}
+	got, _ := Get(db, c.ID)
+	if got.Status != "open" || got.Assignee != "" || got.PushedHead != "" || got.Branch != c.Branch+"-rerun1" {
+		t.Errorf("after rerun = %+v", got)
+	}
+	if len(got.Progress) != 2 || !strings.Contains(got.Progress[1].Note, "reset from merge-failed to open on branch "+c.Branch+"-rerun1") {
+		t.Errorf("progress = %+v", got.Progress)
+	}
+
+	// A second re-run numbers the same base branch.
+	db.Model(&models.Car{}).Where("id = ?", c.ID).Update("status", "cancelled")
+	if _, err := Rerun(db, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = Get(db, c.ID)
+	if got.Branch != c.Branch+"-rerun2" {
+		t.Errorf("second rerun branch = %q", got.Branch)
+	}
+}
+
+func TestRerunWithBus_PublishesCarStatusChanged(t *testing.T) {
+	db := testDB(t)
+	bus := &captureBus{}
+	c := createCar(t, db, CreateOpts{Title: "Flaky", Track: "backend", BranchPrefix: "ry"})
+	db.Model(&models.Car{}).Where("id = ?", c.ID).Update("status", "cancelled")
+
+	if _, err := RerunWithBus(db, bus, c.ID); err != nil {
+		t.Fatalf("RerunWithBus: %v", err)
+	}
+	got, ok := bus.firstOf(plugin.CarStatusChanged).(plugin.CarStatusChangedEvent)
+	if !ok {
+		t.Fatalf("no CarStatusChanged event; topics = %v", bus.topics())
+	}
+	if got.CarID != c.ID || got.OldStatus != "cancelled" || got.NewStatus != "open" {
+		t.Errorf("event = %+v, want %s cancelled→open", got, c.ID)
+	}
+}
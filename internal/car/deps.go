@@ -39,7 +39,7 @@ func AddDep(db *gorm.DB, carID, blockedBy, depType string) error {
 				return fmt.Errorf("dep: check car %s: %w", id, err)
 			}
 			if count == 0 {
-				return fmt.Errorf("dep: car not found: %s", id)
+				return fmt.Errorf("dep: car %w: %s", ErrNotFound, id)
 			}
 		}
 
@@ -85,7 +85,7 @@ func RemoveDep(db *gorm.DB, carID, blockedBy string) error {
 		return fmt.Errorf("dep: remove %s → %s: %w", carID, blockedBy, result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("dep: dependency %s → %s not found", carID, blockedBy)
+		return fmt.Errorf("dep: dependency %s → %s %w", carID, blockedBy, ErrNotFound)
 	}
 	return nil
 }
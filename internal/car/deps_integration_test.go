@@ -3,6 +3,7 @@
 package car
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -91,16 +92,16 @@ func TestIntegration_AddDep_CarNotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent blocker")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 
 	err = AddDep(gormDB, "car-zzzzz", a, "blocks")
 	if err == nil {
 		t.Fatal("expected error for non-existent car")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 }
 
@@ -227,8 +228,8 @@ func TestIntegration_RemoveDep_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent dependency")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 }
 
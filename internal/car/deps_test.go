@@ -1,6 +1,7 @@
 package car
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -73,8 +74,8 @@ func TestAddDep_CarNotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent car")
 	}
-	if !strings.Contains(err.Error(), "car not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "car not found")
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "car not found") {
+		t.Errorf("error = %q, want ErrNotFound containing %q", err.Error(), "car not found")
 	}
 
 	err = AddDep(db, "car-zzzzz", a.ID, "blocks")
@@ -182,8 +183,8 @@ func TestRemoveDep_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent dependency")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error = %q, want to contain %q", err.Error(), "not found")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
 	}
 }
 
@@ -0,0 +1,255 @@
+package car
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// epicActiveStatuses are the child statuses that still need or hold an
+// engine; an epic's reservation on a track is sized by them.
+var epicActiveStatuses = []string{"open", "ready", "claimed", "in_progress"}
+
+// DispatchEpicOpts configures DispatchEpicWithBus.
+type DispatchEpicOpts struct {
+	// Engines caps the engines reserved for the epic on each track; 0
+	// reserves one per active child on the track.
+	Engines int
+	// TrackSlots maps each track to its engine_slots, the WIP limit the
+	// epic's reservation and other dispatched epics' reservations on the
+	// track may not exceed together.
+	TrackSlots map[string]int
+}
+
+// EpicDispatch reports what DispatchEpicWithBus did.
+type EpicDispatch struct {
+	Published int // draft cars published, the epic included
+	// Waves groups the epic's open children by dependency depth: Waves[0]
+	// can be claimed now, Waves[1] once Waves[0] merges, and so on.
+	Waves [][]models.Car
+	// External lists open children waiting on an unresolved car outside
+	// the epic.
+	External     []models.Car
+	Reservations []models.EpicReservation
+}
+
+// DispatchEpic is DispatchEpicWithBus without an event bus.
+func DispatchEpic(db *gorm.DB, epicID string, opts DispatchEpicOpts) (*EpicDispatch, error) {
+	return DispatchEpicWithBus(db, nil, epicID, opts)
+}
+
+// DispatchEpicWithBus sends an epic to the engines as one batch: it
+// publishes the epic and its draft children so every child whose blockers
+// have merged is ready at once (the rest become ready as their blockers
+// merge), and reserves engine capacity for the epic on each of its
+// children's tracks. Running it again re-sizes the reservations.
+func DispatchEpicWithBus(db *gorm.DB, bus events.Bus, epicID string, opts DispatchEpicOpts) (*EpicDispatch, error) {
+	epic, err := Get(db, epicID)
+	if err != nil {
+		return nil, err
+	}
+	if epic.Type != "epic" {
+		return nil, fmt.Errorf("car: dispatch %s: not an epic (type %s)", epicID, epic.Type)
+	}
+	if slices.Contains(models.EpicClosedStatuses, epic.Status) {
+		return nil, fmt.Errorf("car: dispatch %s: epic is %s", epicID, epic.Status)
+	}
+
+	published, err := PublishWithBus(db, bus, epicID, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &EpicDispatch{Published: published}
+
+	var children []models.Car
+	if err := db.Where("parent_id = ? AND type != ? AND status IN ?", epicID, "epic", epicActiveStatuses).
+		Order("priority ASC, created_at ASC").Find(&children).Error; err != nil {
+		return nil, fmt.Errorf("car: dispatch %s: list children: %w", epicID, err)
+	}
+	if result.Waves, result.External, err = epicWaves(db, children); err != nil {
+		return nil, fmt.Errorf("car: dispatch %s: %w", epicID, err)
+	}
+	if result.Reservations, err = reserveEpicEngines(db, epicID, children, opts); err != nil {
+		return nil, fmt.Errorf("car: dispatch %s: %w", epicID, err)
+	}
+	return result, nil
+}
+
+// epicWaves orders an epic's open children into dependency waves. A child
+// joins the wave after its deepest unresolved blocker inside the epic; a
+// child with an unresolved blocker outside the epic is external. Children
+// already claimed are left out.
+func epicWaves(db *gorm.DB, children []models.Car) ([][]models.Car, []models.Car, error) {
+	ids := make([]string, len(children))
+	for i, c := range children {
+		ids[i] = c.ID
+	}
+	var deps []struct {
+		CarID     string
+		BlockedBy string
+	}
+	if len(ids) > 0 {
+		if err := db.Table("car_deps").
+			Select("car_deps.car_id, car_deps.blocked_by").
+			Joins("JOIN cars blocker ON car_deps.blocked_by = blocker.id").
+			Where("car_deps.car_id IN ? AND blocker.status NOT IN ?", ids, models.ResolvedBlockerStatuses).
+			Scan(&deps).Error; err != nil {
+			return nil, nil, fmt.Errorf("list blockers: %w", err)
+		}
+	}
+	inEpic := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		inEpic[id] = true
+	}
+	blockers := make(map[string][]string)
+	external := make(map[string]bool)
+	for _, d := range deps {
+		if inEpic[d.BlockedBy] {
+			blockers[d.CarID] = append(blockers[d.CarID], d.BlockedBy)
+		} else {
+			external[d.CarID] = true
+		}
+	}
+
+	// depth memoizes each child's wave; -1 marks a child waiting outside
+	// the epic, directly or through a blocker.
+	depth := make(map[string]int, len(ids))
+	var waveOf func(id string) int
+	waveOf = func(id string) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		depth[id] = 0 // AddDep rejects cycles; this only guards the recursion
+		d := 0
+		if external[id] {
+			d = -1
+		}
+		for _, b := range blockers[id] {
+			bd := waveOf(b)
+			if bd < 0 {
+				d = -1
+				break
+			}
+			if d >= 0 && bd+1 > d {
+				d = bd + 1
+			}
+		}
+		depth[id] = d
+		return d
+	}
+
+	var waves [][]models.Car
+	var waiting []models.Car
+	for _, c := range children {
+		if c.Status != "open" || c.Assignee != "" {
+			continue
+		}
+		d := waveOf(c.ID)
+		if d < 0 {
+			waiting = append(waiting, c)
+			continue
+		}
+		for len(waves) <= d {
+			waves = append(waves, nil)
+		}
+		waves[d] = append(waves[d], c)
+	}
+	return waves, waiting, nil
+}
+
+// reserveEpicEngines replaces an epic's reservations: on each track with
+// active children it reserves one engine per child (at most opts.Engines),
+// within what the track's engine_slots leave after other open epics'
+// reservations.
+func reserveEpicEngines(db *gorm.DB, epicID string, children []models.Car, opts DispatchEpicOpts) ([]models.EpicReservation, error) {
+	want := make(map[string]int)
+	for _, c := range children {
+		want[c.Track]++
+	}
+	tracks := make([]string, 0, len(want))
+	for t := range want {
+		tracks = append(tracks, t)
+	}
+	sort.Strings(tracks)
+
+	var reservations []models.EpicReservation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("epic_id = ?", epicID).Delete(&models.EpicReservation{}).Error; err != nil {
+			return fmt.Errorf("clear reservations: %w", err)
+		}
+		for _, track := range tracks {
+			var held int64
+			if err := tx.Table("epic_reservations").
+				Select("COALESCE(SUM(epic_reservations.engines), 0)").
+				Joins("JOIN cars epic ON epic_reservations.epic_id = epic.id").
+				Where("epic_reservations.track = ? AND epic.status NOT IN ?", track, models.EpicClosedStatuses).
+				Scan(&held).Error; err != nil {
+				return fmt.Errorf("reservations on track %s: %w", track, err)
+			}
+			n := want[track]
+			if opts.Engines > 0 {
+				n = min(n, opts.Engines)
+			}
+			n = max(min(n, opts.TrackSlots[track]-int(held)), 0)
+			r := models.EpicReservation{EpicID: epicID, Track: track, Engines: n}
+			if err := tx.Create(&r).Error; err != nil {
+				return fmt.Errorf("reserve track %s: %w", track, err)
+			}
+			reservations = append(reservations, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// ReservedEpics returns the open epics whose reservation on track is not
+// yet filled: fewer of the track's engines hold their children than the
+// epic reserved. Engines claim these epics' children first.
+func ReservedEpics(db *gorm.DB, track string) ([]string, error) {
+	var reservations []models.EpicReservation
+	if err := db.Table("epic_reservations").
+		Select("epic_reservations.epic_id, epic_reservations.engines").
+		Joins("JOIN cars epic ON epic_reservations.epic_id = epic.id").
+		Where("epic_reservations.track = ? AND epic_reservations.engines > 0 AND epic.status NOT IN ?", track, models.EpicClosedStatuses).
+		Order("epic_reservations.created_at ASC").
+		Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("car: reserved epics on %s: %w", track, err)
+	}
+	var epics []string
+	for _, r := range reservations {
+		var busy int64
+		if err := db.Model(&models.Car{}).
+			Where("parent_id = ? AND track = ? AND status IN ?", r.EpicID, track, []string{"claimed", "in_progress"}).
+			Count(&busy).Error; err != nil {
+			return nil, fmt.Errorf("car: engines on epic %s: %w", r.EpicID, err)
+		}
+		if int(busy) < r.Engines {
+			epics = append(epics, r.EpicID)
+		}
+	}
+	return epics, nil
+}
+
+// DispatchedEpics returns the epics sent out by ry epic dispatch, open or
+// closed, oldest first.
+func DispatchedEpics(db *gorm.DB) ([]models.Car, error) {
+	var ids []string
+	if err := db.Model(&models.EpicReservation{}).Distinct("epic_id").Pluck("epic_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("car: dispatched epics: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var epics []models.Car
+	if err := db.Where("id IN ?", ids).Order("created_at ASC").Find(&epics).Error; err != nil {
+		return nil, fmt.Errorf("car: dispatched epics: %w", err)
+	}
+	return epics, nil
+}
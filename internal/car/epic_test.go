@@ -0,0 +1,127 @@
+package car
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+func epicTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.EpicReservation{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func createEpicChild(t *testing.T, db *gorm.DB, epicID, id, track, status string) {
+	t.Helper()
+	if err := db.Create(&models.Car{ID: id, Title: id, Type: "task", Track: track, Status: status, ParentID: &epicID}).Error; err != nil {
+		t.Fatalf("create %s: %v", id, err)
+	}
+}
+
+func TestDispatchEpic(t *testing.T) {
+	db := epicTestDB(t)
+	db.Create(&models.Car{ID: "car-epic", Title: "Checkout", Type: "epic", Track: "backend", Status: "draft"})
+	createEpicChild(t, db, "car-epic", "car-api", "backend", "draft")
+	createEpicChild(t, db, "car-epic", "car-db", "backend", "draft")
+	createEpicChild(t, db, "car-epic", "car-ui", "frontend", "draft")
+	createEpicChild(t, db, "car-epic", "car-e2e", "frontend", "draft")
+	createEpicChild(t, db, "car-epic", "car-docs", "backend", "draft")
+	db.Create(&models.Car{ID: "car-outside", Title: "Other", Track: "backend", Status: "open"})
+	// car-ui waits on car-api, car-e2e on car-ui: three waves.
+	db.Create(&models.CarDep{CarID: "car-ui", BlockedBy: "car-api", DepType: "blocks"})
+	db.Create(&models.CarDep{CarID: "car-e2e", BlockedBy: "car-ui", DepType: "blocks"})
+	db.Create(&models.CarDep{CarID: "car-docs", BlockedBy: "car-outside", DepType: "blocks"})
+
+	// Another open epic already holds two of frontend's three slots.
+	db.Create(&models.Car{ID: "car-other", Title: "Other epic", Type: "epic", Status: "open"})
+	db.Create(&models.EpicReservation{EpicID: "car-other", Track: "frontend", Engines: 2})
+	// A closed epic's reservation is ignored.
+	db.Create(&models.Car{ID: "car-closed", Title: "Closed epic", Type: "epic", Status: "merged"})
+	db.Create(&models.EpicReservation{EpicID: "car-closed", Track: "backend", Engines: 3})
+
+	d, err := DispatchEpic(db, "car-epic", DispatchEpicOpts{TrackSlots: map[string]int{"backend": 3, "frontend": 3}})
+	if err != nil {
+		t.Fatalf("DispatchEpic: %v", err)
+	}
+	if d.Published != 6 {
+		t.Errorf("published %d cars, want 6", d.Published)
+	}
+	var waves []string
+	for _, w := range d.Waves {
+		waves = append(waves, epicIDs(w))
+	}
+	if got := strings.Join(waves, " | "); got != "car-api,car-db | car-ui | car-e2e" {
+		t.Errorf("waves = %s", got)
+	}
+	if got := epicIDs(d.External); got != "car-docs" {
+		t.Errorf("external = %s, want car-docs", got)
+	}
+
+	got := map[string]int{}
+	for _, r := range d.Reservations {
+		got[r.Track] = r.Engines
+	}
+	if got["backend"] != 3 || got["frontend"] != 1 {
+		t.Errorf("reservations = %v, want backend 3 (one per child), frontend 1 (slots left)", got)
+	}
+
+	// Re-dispatching with a cap replaces the reservations.
+	d, err = DispatchEpic(db, "car-epic", DispatchEpicOpts{Engines: 1, TrackSlots: map[string]int{"backend": 3, "frontend": 3}})
+	if err != nil {
+		t.Fatalf("re-dispatch: %v", err)
+	}
+	if d.Published != 0 || len(d.Reservations) != 2 || d.Reservations[0].Engines != 1 {
+		t.Errorf("re-dispatch = %+v, want nothing published and 1 engine per track", d)
+	}
+	var n int64
+	db.Model(&models.EpicReservation{}).Where("epic_id = ?", "car-epic").Count(&n)
+	if n != 2 {
+		t.Errorf("epic has %d reservation rows, want 2", n)
+	}
+}
+
+func TestDispatchEpic_Rejects(t *testing.T) {
+	db := epicTestDB(t)
+	db.Create(&models.Car{ID: "car-task", Title: "Task", Type: "task", Status: "open"})
+	db.Create(&models.Car{ID: "car-done", Title: "Done", Type: "epic", Status: "done"})
+
+	if _, err := DispatchEpic(db, "car-task", DispatchEpicOpts{}); err == nil || !strings.Contains(err.Error(), "not an epic") {
+		t.Errorf("task: err = %v", err)
+	}
+	if _, err := DispatchEpic(db, "car-done", DispatchEpicOpts{}); err == nil || !strings.Contains(err.Error(), "epic is done") {
+		t.Errorf("closed epic: err = %v", err)
+	}
+}
+
+func TestReservedEpics(t *testing.T) {
+	db := epicTestDB(t)
+	db.Create(&models.Car{ID: "car-e1", Title: "E1", Type: "epic", Status: "open"})
+	db.Create(&models.Car{ID: "car-e2", Title: "E2", Type: "epic", Status: "open"})
+	createEpicChild(t, db, "car-e1", "car-e1a", "backend", "in_progress")
+	createEpicChild(t, db, "car-e1", "car-e1b", "backend", "open")
+	createEpicChild(t, db, "car-e2", "car-e2a", "backend", "open")
+	db.Create(&models.EpicReservation{EpicID: "car-e1", Track: "backend", Engines: 1})
+	db.Create(&models.EpicReservation{EpicID: "car-e2", Track: "backend", Engines: 2})
+
+	epics, err := ReservedEpics(db, "backend")
+	if err != nil {
+		t.Fatalf("ReservedEpics: %v", err)
+	}
+	if len(epics) != 1 || epics[0] != "car-e2" {
+		t.Errorf("reserved epics = %v, want [car-e2] (car-e1's one engine is busy)", epics)
+	}
+}
+
+func epicIDs(cars []models.Car) string {
+	ids := make([]string, len(cars))
+	for i, c := range cars {
+		ids[i] = c.ID
+	}
+	return strings.Join(ids, ",")
+}
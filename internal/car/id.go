@@ -0,0 +1,106 @@
+package car
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// IDFormat configures the IDs Create generates. The zero value is the
+// default car-xxxxxxxx format.
+type IDFormat struct {
+	Prefix        string            // leading text of every ID; default "car-"
+	Length        int               // random hex characters; default 8
+	Slug          bool              // put a slug of the title before the random suffix
+	TrackPrefixes map[string]string // per-track Prefix overrides, keyed by track name
+}
+
+// IDFormatFromConfig returns the ID format configured by car_ids and each
+// track's id_prefix.
+func IDFormatFromConfig(cfg *config.Config) IDFormat {
+	f := IDFormat{
+		Prefix: cfg.CarIDs.Prefix,
+		Length: cfg.CarIDs.Length,
+		Slug:   cfg.CarIDs.Slug,
+	}
+	for _, t := range cfg.Tracks {
+		if t.IDPrefix == "" {
+			continue
+		}
+		if f.TrackPrefixes == nil {
+			f.TrackPrefixes = make(map[string]string)
+		}
+		f.TrackPrefixes[t.Name] = t.IDPrefix
+	}
+	return f
+}
+
+// isDefault reports whether f produces GenerateID's car-xxxxxxxx IDs.
+func (f IDFormat) isDefault(track string) bool {
+	return f.prefix(track) == "car-" && (f.Length == 0 || f.Length == config.DefaultCarIDLength) && !f.Slug
+}
+
+func (f IDFormat) prefix(track string) string {
+	if p := f.TrackPrefixes[track]; p != "" {
+		return p
+	}
+	if f.Prefix != "" {
+		return f.Prefix
+	}
+	return "car-"
+}
+
+// Generate returns a new ID for a car on track titled title, e.g.
+// be-4f1c09a2 or be-fix-login-timeout-4f1c09a2 with Slug set. The random
+// suffix is always present, so identically titled cars get distinct IDs;
+// Create retries on the rare collision. The slug is shortened to keep the ID
+// within config.MaxCarIDSize.
+func (f IDFormat) Generate(track, title string) (string, error) {
+	if f.isDefault(track) {
+		return generateID()
+	}
+	n := f.Length
+	if n == 0 {
+		n = config.DefaultCarIDLength
+	}
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("car: generate ID: %w", err)
+	}
+	prefix, suffix := f.prefix(track), hex.EncodeToString(b)[:n]
+	if f.Slug {
+		if slug := Slugify(title, config.MaxCarIDSize-len(prefix)-n-1); slug != "" {
+			return prefix + slug + "-" + suffix, nil
+		}
+	}
+	return prefix + suffix, nil
+}
+
+// Slugify lowercases s and joins its letters and digits with single dashes,
+// cut at a word boundary to at most maxLen characters where possible.
+func Slugify(s string, maxLen int) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	var b strings.Builder
+	for _, w := range words {
+		sep := 0
+		if b.Len() > 0 {
+			sep = 1
+		}
+		if b.Len()+sep+len(w) > maxLen {
+			if b.Len() == 0 && maxLen > 0 {
+				b.WriteString(w[:maxLen]) // a single long word: truncate it
+			}
+			break
+		}
+		if sep == 1 {
+			b.WriteByte('-')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package car
+
+import (
+	"fmt"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Journal returns a car's work journal in chronological order, across all
+// engines and sessions that worked on it.
+func Journal(db *gorm.DB, carID string) ([]models.JournalEntry, error) {
+	if carID == "" {
+		return nil, fmt.Errorf("car: journal: car ID is required")
+	}
+	var c models.Car
+	if err := db.Select("id").Where("id = ?", carID).First(&c).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("car: journal: car %w: %s", ErrNotFound, carID)
+		}
+		return nil, fmt.Errorf("car: journal: get car %s: %w", carID, err)
+	}
+
+	var entries []models.JournalEntry
+	if err := db.Where("car_id = ?", carID).Order("created_at ASC, id ASC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("car: journal %s: %w", carID, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package car
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testJournalDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.JournalEntry{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestJournal_ChronologicalOrder(t *testing.T) {
+	db := testJournalDB(t)
+	createMemCar(t, db, "car-j1", "Journal car", "backend")
+
+	base := time.Now().Add(-time.Hour)
+	db.Create(&models.JournalEntry{CarID: "car-j1", Kind: "test", Detail: "go test ./...", CreatedAt: base.Add(2 * time.Minute)})
+	db.Create(&models.JournalEntry{CarID: "car-j1", Kind: "session", Detail: "started", CreatedAt: base})
+	db.Create(&models.JournalEntry{CarID: "car-other", Kind: "session", Detail: "started", CreatedAt: base})
+
+	entries, err := Journal(db, "car-j1")
+	if err != nil {
+		t.Fatalf("Journal: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Kind != "session" || entries[1].Kind != "test" {
+		t.Errorf("entries out of order: %q, %q", entries[0].Kind, entries[1].Kind)
+	}
+}
+
+func TestJournal_CarNotFound(t *testing.T) {
+	db := testJournalDB(t)
+	_, err := Journal(db, "missing")
+	if err == nil || !strings.Contains(err.Error(), "car not found") {
+		t.Errorf("err = %v, want car not found", err)
+	}
+}
+
+func TestJournal_EmptyCarID(t *testing.T) {
+	db := testJournalDB(t)
+	if _, err := Journal(db, ""); err == nil {
+		t.Error("expected error for empty car ID")
+	}
+}
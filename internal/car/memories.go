@@ -117,7 +117,7 @@ func carTrack(db *gorm.DB, carID string) (string, error) {
 	var c models.Car
 	if err := db.Select("track").Where("id = ?", carID).First(&c).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return "", fmt.Errorf("memories: car not found: %s", carID)
+			return "", fmt.Errorf("memories: car %w: %s", ErrNotFound, carID)
 		}
 		return "", fmt.Errorf("memories: get car %s: %w", carID, err)
 	}
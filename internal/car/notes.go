@@ -0,0 +1,85 @@
+package car
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// MaxNoteLength caps a track note's length in bytes. Notes go into every
+// engine prompt on the track, so they are meant to be one or two sentences.
+const MaxNoteLength = 500
+
+// MaxPromptNotes is how many of a track's most recent notes an engine
+// prompt includes.
+const MaxPromptNotes = 20
+
+// AddNote records a note for a track. author is the engine ID, or "human"
+// for notes added by hand; carID may be empty.
+func AddNote(db *gorm.DB, track, content, author, carID string) (*models.TrackNote, error) {
+	content = strings.TrimSpace(content)
+	if track == "" {
+		return nil, fmt.Errorf("notes: track is required")
+	}
+	if content == "" {
+		return nil, fmt.Errorf("notes: content is required")
+	}
+	if len(content) > MaxNoteLength {
+		return nil, fmt.Errorf("notes: note is %d bytes, the limit is %d", len(content), MaxNoteLength)
+	}
+	note := models.TrackNote{Track: track, Content: content, Author: author, CarID: carID}
+	if err := db.Create(&note).Error; err != nil {
+		return nil, fmt.Errorf("notes: add for %s: %w", track, err)
+	}
+	return &note, nil
+}
+
+// TrackNotes returns a track's most recent notes, oldest first. limit <= 0
+// returns them all.
+func TrackNotes(db *gorm.DB, track string, limit int) ([]models.TrackNote, error) {
+	if track == "" {
+		return nil, fmt.Errorf("notes: track is required")
+	}
+	q := db.Where("track = ?", track).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var notes []models.TrackNote
+	if err := q.Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("notes: list %s: %w", track, err)
+	}
+	for i, j := 0, len(notes)-1; i < j; i, j = i+1, j-1 {
+		notes[i], notes[j] = notes[j], notes[i]
+	}
+	return notes, nil
+}
+
+// RemoveNote deletes a note by ID. Returns an error if no matching row was
+// found.
+func RemoveNote(db *gorm.DB, id uint) error {
+	result := db.Where("id = ?", id).Delete(&models.TrackNote{})
+	if result.Error != nil {
+		return fmt.Errorf("notes: remove %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("notes: no note %d found", id)
+	}
+	return nil
+}
+
+// PruneNotes deletes a track's notes created before the cutoff and returns
+// how many were removed. An empty track prunes every track.
+func PruneNotes(db *gorm.DB, track string, before time.Time) (int64, error) {
+	q := db.Where("created_at < ?", before)
+	if track != "" {
+		q = q.Where("track = ?", track)
+	}
+	result := q.Delete(&models.TrackNote{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("notes: prune: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
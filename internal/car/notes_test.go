@@ -0,0 +1,92 @@
+package car
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testNoteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.TrackNote{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestAddNote(t *testing.T) {
+	db := testNoteDB(t)
+	note, err := AddNote(db, "backend", "  tests require FOO_ENV=1\n", "eng-1", "car-1")
+	if err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+	if note.ID == 0 || note.Content != "tests require FOO_ENV=1" || note.Author != "eng-1" || note.CarID != "car-1" {
+		t.Errorf("note = %+v", note)
+	}
+
+	for _, tc := range []struct{ track, content, want string }{
+		{"", "x", "track is required"},
+		{"backend", " ", "content is required"},
+		{"backend", strings.Repeat("x", MaxNoteLength+1), "the limit is 500"},
+	} {
+		if _, err := AddNote(db, tc.track, tc.content, "human", ""); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("AddNote(%q, %d bytes) err = %v, want %q", tc.track, len(tc.content), err, tc.want)
+		}
+	}
+}
+
+func TestTrackNotes(t *testing.T) {
+	db := testNoteDB(t)
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i, content := range []string{"first", "second", "third"} {
+		db.Create(&models.TrackNote{Track: "backend", Content: content, CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	db.Create(&models.TrackNote{Track: "frontend", Content: "other", CreatedAt: base})
+
+	notes, err := TrackNotes(db, "backend", 2)
+	if err != nil {
+		t.Fatalf("TrackNotes: %v", err)
+	}
+	if len(notes) != 2 || notes[0].Content != "second" || notes[1].Content != "third" {
+		t.Errorf("notes = %+v, want the two newest, oldest first", notes)
+	}
+	if all, _ := TrackNotes(db, "backend", 0); len(all) != 3 {
+		t.Errorf("unlimited notes = %d, want 3", len(all))
+	}
+}
+
+func TestRemoveAndPruneNotes(t *testing.T) {
+	db := testNoteDB(t)
+	now := time.Now()
+	old := models.TrackNote{Track: "backend", Content: "old", CreatedAt: now.Add(-48 * time.Hour)}
+	db.Create(&old)
+	db.Create(&models.TrackNote{Track: "frontend", Content: "old too", CreatedAt: now.Add(-48 * time.Hour)})
+	fresh := models.TrackNote{Track: "backend", Content: "fresh", CreatedAt: now}
+	db.Create(&fresh)
+
+	n, err := PruneNotes(db, "backend", now.Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("PruneNotes = %d, %v; want 1", n, err)
+	}
+	if n, _ := PruneNotes(db, "", now.Add(-24*time.Hour)); n != 1 {
+		t.Errorf("PruneNotes across tracks = %d, want 1", n)
+	}
+
+	if err := RemoveNote(db, fresh.ID); err != nil {
+		t.Fatalf("RemoveNote: %v", err)
+	}
+	if err := RemoveNote(db, fresh.ID); err == nil || !strings.Contains(err.Error(), "no note") {
+		t.Errorf("second RemoveNote err = %v", err)
+	}
+}
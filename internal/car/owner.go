@@ -0,0 +1,30 @@
+package car
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// NormalizeOwner trims whitespace and a leading "@" from an owner handle,
+// so "@alice" and "alice" name the same owner.
+func NormalizeOwner(owner string) string {
+	return strings.TrimPrefix(strings.TrimSpace(owner), "@")
+}
+
+// SetOwner sets the human owner of a car. An empty owner clears it.
+func SetOwner(db *gorm.DB, id, owner string) error {
+	if id == "" {
+		return fmt.Errorf("car: set owner: car ID is required")
+	}
+	res := db.Model(&models.Car{}).Where("id = ?", id).Update("owner", NormalizeOwner(owner))
+	if res.Error != nil {
+		return fmt.Errorf("car: set owner %s: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("car: %w: %s", ErrNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package car
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeOwner(t *testing.T) {
+	tests := map[string]string{
+		"@alice":   "alice",
+		"alice":    "alice",
+		" @bob  ":  "bob",
+		"":         "",
+		"@@weird":  "@weird",
+		"carol@x ": "carol@x",
+	}
+	for in, want := range tests {
+		if got := NormalizeOwner(in); got != want {
+			t.Errorf("NormalizeOwner(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCreate_Owner(t *testing.T) {
+	db := testDB(t)
+	c := createCar(t, db, CreateOpts{Title: "Owned", Track: "backend", Owner: "@alice"})
+	if c.Owner != "alice" {
+		t.Errorf("Owner = %q, want alice", c.Owner)
+	}
+}
+
+func TestSetOwner(t *testing.T) {
+	db := testDB(t)
+	c := createCar(t, db, CreateOpts{Title: "Owned", Track: "backend"})
+
+	if err := SetOwner(db, c.ID, "@bob"); err != nil {
+		t.Fatalf("SetOwner: %v", err)
+	}
+	got, _ := Get(db, c.ID)
+	if got.Owner != "bob" {
+		t.Errorf("Owner = %q, want bob", got.Owner)
+	}
+
+	if err := SetOwner(db, c.ID, ""); err != nil {
+		t.Fatalf("SetOwner clear: %v", err)
+	}
+	got, _ = Get(db, c.ID)
+	if got.Owner != "" {
+		t.Errorf("Owner = %q, want empty", got.Owner)
+	}
+}
+
+func TestSetOwner_NotFound(t *testing.T) {
+	db := testDB(t)
+	err := SetOwner(db, "car-nope", "alice")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestList_FilterByOwner(t *testing.T) {
+	db := testDB(t)
+	createCar(t, db, CreateOpts{Title: "A", Track: "backend", Owner: "alice"})
+	createCar(t, db, CreateOpts{Title: "B", Track: "backend", Owner: "bob"})
+	createCar(t, db, CreateOpts{Title: "C", Track: "backend"})
+
+	cars, err := List(db, ListFilters{Owner: "@alice"})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(cars) != 1 || cars[0].Title != "A" {
+		t.Errorf("cars = %+v, want only A", cars)
+	}
+}
@@ -0,0 +1,179 @@
+package car
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// SplitChild describes one car to create when splitting a car.
+type SplitChild struct {
+	Title       string
+	Description string
+	Acceptance  string
+	Type        string // empty = task
+	Priority    *int   // nil keeps the original's priority
+	Estimate    int
+}
+
+// SplitOpts holds parameters for splitting a car.
+type SplitOpts struct {
+	Children     []SplitChild
+	BranchPrefix string // used when the original's branch does not show its prefix
+	RequestedBy  string // empty keeps the original's requester
+	IDFormat     IDFormat
+}
+
+// splitStatuses are the statuses Split accepts: the car's work has not been
+// handed to the merge gate yet.
+var splitStatuses = map[string]bool{
+	"draft": true, "open": true, "ready": true, "claimed": true, "in_progress": true, "blocked": true,
+}
+
+// Split turns a car that proved too big into an epic with the given children.
+// Children inherit the original's track, branch prefix, base branch, owner,
+// design notes, and blockers, and start as drafts when the original was a
+// draft and open otherwise. Cars that were blocked by the original are
+// re-pointed to wait on every child instead, since an epic closes as done,
+// which does not release its dependents. The original is released from its
+// engine and reopened as an epic; work already on its branch is not carried
+// over. It returns the children in the order given.
+func Split(db *gorm.DB, id string, opts SplitOpts) ([]models.Car, error) {
+	if len(opts.Children) == 0 {
+		return nil, fmt.Errorf("car: split %s: at least one child is required", id)
+	}
+	for i, ch := range opts.Children {
+		if strings.TrimSpace(ch.Title) == "" {
+			return nil, fmt.Errorf("car: split %s: child %d has no title", id, i+1)
+		}
+	}
+	orig, err := Get(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if orig.Type == "epic" {
+		return nil, fmt.Errorf("car: %s is already an epic; add children with ry car create --parent %s", id, id)
+	}
+	if orig.Type == "conflict" {
+		return nil, fmt.Errorf("car: %s is a conflict car; its work is tied to the original merge and cannot be split", id)
+	}
+	if !splitStatuses[orig.Status] {
+		return nil, fmt.Errorf("car: %s is %s; only cars not yet completed can be split", id, orig.Status)
+	}
+
+	status := "open"
+	if orig.Status == "draft" {
+		status = "draft"
+	}
+	requestedBy := opts.RequestedBy
+	if requestedBy == "" {
+		requestedBy = orig.RequestedBy
+	}
+	blockers, dependents, err := ListDeps(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ok := branchPrefixOf(orig)
+	if !ok {
+		prefix = opts.BranchPrefix
+	}
+
+	var children []models.Car
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Car{}).Where("id = ? AND status = ?", id, orig.Status).Updates(map[string]interface{}{
+			"type":           "epic",
+			"status":         status,
+			"assignee":       "",
+			"blocked_reason": "",
+			"claimed_at":     nil,
+		})
+		if res.Error != nil {
+			return fmt.Errorf("car: split %s: %w", id, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("car: split %s: status changed concurrently", id)
+		}
+
+		for _, ch := range opts.Children {
+			priority := orig.Priority
+			if ch.Priority != nil {
+				priority = *ch.Priority
+			}
+			// Splitting reshapes work already in the yard, so it goes ahead
+			// while the yard is paused.
+			c, err := Create(tx, CreateOpts{
+				Title:        ch.Title,
+				Description:  ch.Description,
+				Type:         ch.Type,
+				Priority:     priority,
+				Estimate:     ch.Estimate,
+				Track:        orig.Track,
+				ParentID:     orig.ID,
+				DesignNotes:  orig.DesignNotes,
+				Acceptance:   ch.Acceptance,
+				SkipTests:    orig.SkipTests,
+				BranchPrefix: prefix,
+				BaseBranch:   orig.BaseBranch,
+				RequestedBy:  requestedBy,
+				Owner:        orig.Owner,
+				IDFormat:     opts.IDFormat,
+				IgnorePause:  true,
+			})
+			if err != nil {
+				return fmt.Errorf("car: split %s: %w", id, err)
+			}
+			if status != c.Status {
+				if err := tx.Model(&models.Car{}).Where("id = ?", c.ID).Update("status", status).Error; err != nil {
+					return fmt.Errorf("car: split %s: open %s: %w", id, c.ID, err)
+				}
+				c.Status = status
+			}
+			for _, b := range blockers {
+				if err := AddDep(tx, c.ID, b.BlockedBy, b.DepType); err != nil {
+					return fmt.Errorf("car: split %s: %w", id, err)
+				}
+			}
+			for _, d := range dependents {
+				if err := AddDep(tx, d.CarID, c.ID, d.DepType); err != nil {
+					return fmt.Errorf("car: split %s: %w", id, err)
+				}
+			}
+			children = append(children, *c)
+		}
+		for _, d := range dependents {
+			if err := RemoveDep(tx, d.CarID, id); err != nil {
+				return fmt.Errorf("car: split %s: %w", id, err)
+			}
+		}
+
+		ids := make([]string, len(children))
+		for i, c := range children {
+			ids[i] = c.ID
+		}
+		return tx.Create(&models.CarProgress{
+			CarID:        id,
+			EngineID:     "dispatch",
+			Note:         fmt.Sprintf("Split from %s %s into %s", orig.Status, orig.Type, strings.Join(ids, ", ")),
+			FilesChanged: "[]",
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// branchPrefixOf recovers the branch prefix a car was created with from its
+// branch, "prefix/track/id" (see ComputeBranch), ignoring a re-run suffix.
+// ok is false when the branch was named some other way, as adopted branches
+// are.
+func branchPrefixOf(c *models.Car) (prefix string, ok bool) {
+	branch := rerunBaseBranch(c.Branch)
+	if branch == c.Track+"/"+c.ID {
+		return "", true
+	}
+	return strings.CutSuffix(branch, "/"+c.Track+"/"+c.ID)
+}
@@ -0,0 +1,98 @@
+package car
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestSplit(t *testing.T) {
+	db := testDB(t)
+	schema := createCar(t, db, CreateOpts{Title: "Schema", Track: "backend"})
+	orig := createCar(t, db, CreateOpts{Title: "Payments rewrite", Track: "backend", BranchPrefix: "ry/alice", Priority: 1, Owner: "alice"})
+	docs := createCar(t, db, CreateOpts{Title: "Payments docs", Track: "backend"})
+	if err := AddDep(db, orig.ID, schema.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddDep(db, docs.ID, orig.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&models.Car{}).Where("id = ?", orig.ID).Updates(map[string]interface{}{"status": "in_progress", "assignee": "eng-1"})
+
+	p0 := 0
+	children, err := Split(db, orig.ID, SplitOpts{Children: []SplitChild{
+		{Title: "Refund model", Acceptance: "migrations run"},
+		{Title: "Refund API", Priority: &p0, Type: "bug"},
+	}})
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("children = %d, want 2", len(children))
+	}
+
+	epic, _ := Get(db, orig.ID)
+	if epic.Type != "epic" || epic.Status != "open" || epic.Assignee != "" {
+		t.Errorf("original = type %q status %q assignee %q, want an open unassigned epic", epic.Type, epic.Status, epic.Assignee)
+	}
+	for i, c := range children {
+		if c.ParentID == nil || *c.ParentID != orig.ID || c.Track != "backend" || c.Status != "open" || c.Owner != "alice" {
+			t.Errorf("child %d = %+v", i, c)
+		}
+		if c.Branch != "ry/alice/backend/"+c.ID {
+			t.Errorf("child %d branch = %q, want the original's prefix", i, c.Branch)
+		}
+		blockers, _, _ := ListDeps(db, c.ID)
+		if len(blockers) != 1 || blockers[0].BlockedBy != schema.ID {
+			t.Errorf("child %d blockers = %+v, want the original's", i, blockers)
+		}
+	}
+	if children[0].Priority != 1 || children[0].Acceptance != "migrations run" || children[1].Priority != 0 || children[1].Type != "bug" {
+		t.Errorf("children = %+v", children)
+	}
+
+	docBlockers, _, _ := ListDeps(db, docs.ID)
+	got := map[string]bool{}
+	for _, d := range docBlockers {
+		got[d.BlockedBy] = true
+	}
+	if len(got) != 2 || !got[children[0].ID] || !got[children[1].ID] {
+		t.Errorf("dependent blockers = %+v, want both children and not the epic", docBlockers)
+	}
+	if !strings.Contains(epic.Progress[len(epic.Progress)-1].Note, children[1].ID) {
+		t.Errorf("progress = %+v", epic.Progress)
+	}
+}
+
+func TestSplit_Rejects(t *testing.T) {
+	db := testDB(t)
+	epic := createCar(t, db, CreateOpts{Title: "Epic", Track: "backend", Type: "epic"})
+	merged := createCar(t, db, CreateOpts{Title: "Done", Track: "backend"})
+	db.Model(&models.Car{}).Where("id = ?", merged.ID).Update("status", "merged")
+	draft := createCar(t, db, CreateOpts{Title: "Draft", Track: "backend"})
+	one := []SplitChild{{Title: "Part"}}
+
+	for _, tc := range []struct {
+		id       string
+		children []SplitChild
+		want     string
+	}{
+		{epic.ID, one, "already an epic"},
+		{merged.ID, one, "is merged"},
+		{draft.ID, nil, "at least one child"},
+		{draft.ID, []SplitChild{{Title: " "}}, "child 1 has no title"},
+	} {
+		if _, err := Split(db, tc.id, SplitOpts{Children: tc.children}); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Split(%s) err = %v, want %q", tc.id, err, tc.want)
+		}
+	}
+
+	children, err := Split(db, draft.ID, SplitOpts{Children: one})
+	if err != nil {
+		t.Fatalf("Split draft: %v", err)
+	}
+	if children[0].Status != "draft" {
+		t.Errorf("child of a draft = %q, want draft", children[0].Status)
+	}
+}
@@ -0,0 +1,139 @@
+package car
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Watch event kinds.
+const (
+	WatchStatus   = "status"   // the car's status changed
+	WatchProgress = "progress" // an engine wrote a progress note
+	WatchMessage  = "message"  // a message about the car (merge attempts, escalations)
+)
+
+// WatchEvent is one change observed on a car by a Watcher.
+type WatchEvent struct {
+	Kind      string
+	At        time.Time
+	OldStatus string // WatchStatus only; "" on the first poll
+	Status    string // WatchStatus only
+	From      string // author: engine ID for progress, sender for messages
+	Detail    string // progress note or message subject/body
+}
+
+// Watcher follows a single car by polling its status, progress notes, and
+// messages. It keeps no connection state, so callers drive it with Poll on
+// whatever interval suits them.
+type Watcher struct {
+	db           *gorm.DB
+	carID        string
+	status       string
+	lastProgress uint
+	lastMessage  uint
+	seeded       bool
+}
+
+// NewWatcher returns a Watcher for carID. The car must exist.
+func NewWatcher(db *gorm.DB, carID string) (*Watcher, error) {
+	if carID == "" {
+		return nil, fmt.Errorf("car: watch: car ID is required")
+	}
+	var c models.Car
+	if err := db.Select("id").Where("id = ?", carID).First(&c).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("car: watch: car %w: %s", ErrNotFound, carID)
+		}
+		return nil, fmt.Errorf("car: watch: get car %s: %w", carID, err)
+	}
+	return &Watcher{db: db, carID: carID}, nil
+}
+
+// Status returns the car status seen by the most recent Poll.
+func (w *Watcher) Status() string { return w.status }
+
+// Poll returns what changed since the previous call, oldest first. The first
+// call reports the current status and skips earlier progress and messages.
+func (w *Watcher) Poll() ([]WatchEvent, error) {
+	var c models.Car
+	if err := w.db.Select("id, status, updated_at").Where("id = ?", w.carID).First(&c).Error; err != nil {
+		return nil, fmt.Errorf("car: watch %s: %w", w.carID, err)
+	}
+
+	if !w.seeded {
+		w.seeded = true
+		w.status = c.Status
+		if err := w.seedCursors(); err != nil {
+			return nil, err
+		}
+		return []WatchEvent{{Kind: WatchStatus, At: c.UpdatedAt, Status: c.Status}}, nil
+	}
+
+	var events []WatchEvent
+
+	var progress []models.CarProgress
+	if err := w.db.Where("car_id = ? AND id > ?", w.carID, w.lastProgress).Order("id ASC").Find(&progress).Error; err != nil {
+		return nil, fmt.Errorf("car: watch %s: progress: %w", w.carID, err)
+	}
+	for _, p := range progress {
+		events = append(events, WatchEvent{Kind: WatchProgress, At: p.CreatedAt, From: p.EngineID, Detail: p.Note})
+		w.lastProgress = p.ID
+	}
+
+	var msgs []models.Message
+	if err := w.db.Where("car_id = ? AND id > ?", w.carID, w.lastMessage).Order("id ASC").Find(&msgs).Error; err != nil {
+		return nil, fmt.Errorf("car: watch %s: messages: %w", w.carID, err)
+	}
+	for _, m := range msgs {
+		detail := m.Subject
+		if m.Body != "" {
+			detail += ": " + m.Body
+		}
+		events = append(events, WatchEvent{Kind: WatchMessage, At: m.CreatedAt, From: m.FromAgent, Detail: detail})
+		w.lastMessage = m.ID
+	}
+
+	// Status last, so a note explaining a transition prints before it.
+	if c.Status != w.status {
+		events = append(events, WatchEvent{Kind: WatchStatus, At: c.UpdatedAt, OldStatus: w.status, Status: c.Status})
+		w.status = c.Status
+	}
+	return events, nil
+}
+
+// seedCursors moves the progress and message cursors past existing rows.
+func (w *Watcher) seedCursors() error {
+	var maxIDs struct{ ID uint }
+	if err := w.db.Model(&models.CarProgress{}).Where("car_id = ?", w.carID).
+		Select("COALESCE(MAX(id), 0) AS id").Scan(&maxIDs).Error; err != nil {
+		return fmt.Errorf("car: watch %s: progress: %w", w.carID, err)
+	}
+	w.lastProgress = maxIDs.ID
+	maxIDs.ID = 0
+	if err := w.db.Model(&models.Message{}).Where("car_id = ?", w.carID).
+		Select("COALESCE(MAX(id), 0) AS id").Scan(&maxIDs).Error; err != nil {
+		return fmt.Errorf("car: watch %s: messages: %w", w.carID, err)
+	}
+	w.lastMessage = maxIDs.ID
+	return nil
+}
+
+// UntilReached reports whether status satisfies a `watch --until` condition,
+// and whether the car has instead reached a state from which it never will.
+// "merged" waits for the merge; "done" accepts done or anything after it.
+func UntilReached(until, status string) (reached, unreachable bool) {
+	switch until {
+	case "merged":
+		return status == "merged" || status == "reverted", status == "cancelled"
+	case "done":
+		switch status {
+		case "done", "merge-failed", "needs-attention", "pr_open", "pr_review", "merged", "reverted":
+			return true, false
+		}
+		return false, status == "cancelled"
+	}
+	return false, false
+}
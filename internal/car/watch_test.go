@@ -0,0 +1,107 @@
+package car
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testWatchDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.CarProgress{}, &models.Message{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestWatcher_ReportsChangesSinceLastPoll(t *testing.T) {
+	db := testWatchDB(t)
+	createMemCar(t, db, "car-w1", "Watched car", "backend")
+	db.Model(&models.Car{}).Where("id = ?", "car-w1").Update("status", "in_progress")
+	db.Create(&models.CarProgress{CarID: "car-w1", EngineID: "eng-1", Note: "old note"})
+
+	w, err := NewWatcher(db, "car-w1")
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Kind != WatchStatus || events[0].Status != "in_progress" {
+		t.Fatalf("first poll = %+v, want only the current status", events)
+	}
+
+	db.Create(&models.CarProgress{CarID: "car-w1", EngineID: "eng-1", Note: "tests passing"})
+	db.Create(&models.Message{FromAgent: "yardmaster", ToAgent: "human", CarID: "car-w1", Subject: "merge-failed", Body: "conflict"})
+	db.Create(&models.Message{FromAgent: "yardmaster", ToAgent: "human", CarID: "car-other", Subject: "unrelated"})
+	db.Model(&models.Car{}).Where("id = ?", "car-w1").Update("status", "done")
+
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	if strings.Join(kinds, ",") != "progress,message,status" {
+		t.Fatalf("kinds = %v, want progress,message,status", kinds)
+	}
+	if events[0].Detail != "tests passing" || events[0].From != "eng-1" {
+		t.Errorf("progress event = %+v", events[0])
+	}
+	if events[1].Detail != "merge-failed: conflict" {
+		t.Errorf("message detail = %q", events[1].Detail)
+	}
+	if events[2].OldStatus != "in_progress" || events[2].Status != "done" || w.Status() != "done" {
+		t.Errorf("status event = %+v", events[2])
+	}
+
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("idle poll = %+v, want none", events)
+	}
+}
+
+func TestNewWatcher_CarNotFound(t *testing.T) {
+	db := testWatchDB(t)
+	if _, err := NewWatcher(db, "missing"); err == nil || !strings.Contains(err.Error(), "car not found") {
+		t.Errorf("err = %v, want car not found", err)
+	}
+}
+
+func TestUntilReached(t *testing.T) {
+	tests := []struct {
+		until, status        string
+		reached, unreachable bool
+	}{
+		{"merged", "done", false, false},
+		{"merged", "merged", true, false},
+		{"merged", "cancelled", false, true},
+		{"done", "in_progress", false, false},
+		{"done", "done", true, false},
+		{"done", "merge-failed", true, false},
+		{"done", "cancelled", false, true},
+	}
+	for _, tt := range tests {
+		reached, unreachable := UntilReached(tt.until, tt.status)
+		if reached != tt.reached || unreachable != tt.unreachable {
+			t.Errorf("UntilReached(%q, %q) = %v, %v; want %v, %v",
+				tt.until, tt.status, reached, unreachable, tt.reached, tt.unreachable)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package car
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// AddWebhook registers a webhook that receives carID's status changes and
+// merge results. rawURL must be an http(s) URL; secret, when set, signs
+// each delivery.
+func AddWebhook(db *gorm.DB, carID, rawURL, secret, createdBy string) (*models.CarWebhook, error) {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook: %q is not an http(s) URL", rawURL)
+	}
+	if _, err := Get(db, carID); err != nil {
+		return nil, err
+	}
+	hook := models.CarWebhook{CarID: carID, URL: rawURL, Secret: secret, CreatedBy: createdBy}
+	if err := db.Create(&hook).Error; err != nil {
+		return nil, fmt.Errorf("webhook: add to %s: %w", carID, err)
+	}
+	return &hook, nil
+}
+
+// Webhooks returns carID's webhooks, oldest first.
+func Webhooks(db *gorm.DB, carID string) ([]models.CarWebhook, error) {
+	var hooks []models.CarWebhook
+	if err := db.Where("car_id = ?", carID).Order("id ASC").Find(&hooks).Error; err != nil {
+		return nil, fmt.Errorf("webhook: list %s: %w", carID, err)
+	}
+	return hooks, nil
+}
+
+// RemoveWebhook deletes carID's webhook with the given ID.
+func RemoveWebhook(db *gorm.DB, carID string, id uint) error {
+	res := db.Where("id = ? AND car_id = ?", id, carID).Delete(&models.CarWebhook{})
+	if res.Error != nil {
+		return fmt.Errorf("webhook: remove %d from %s: %w", id, carID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("webhook: %d on %s %w", id, carID, ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package car
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestWebhooks(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.CarWebhook{}); err != nil {
+		t.Fatal(err)
+	}
+	c := createCar(t, db, CreateOpts{Title: "Deployable", Track: "backend"})
+
+	hook, err := AddWebhook(db, c.ID, "https://ci.example.com/hooks/railyard", "s3cret", "alice")
+	if err != nil {
+		t.Fatalf("AddWebhook: %v", err)
+	}
+	if _, err := AddWebhook(db, c.ID, "ftp://example.com", "", "alice"); err == nil {
+		t.Error("accepted a non-http URL")
+	}
+	if _, err := AddWebhook(db, "car-missing", "https://example.com", "", "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing car: err = %v, want ErrNotFound", err)
+	}
+
+	hooks, err := Webhooks(db, c.ID)
+	if err != nil || len(hooks) != 1 || hooks[0].URL != hook.URL || hooks[0].Secret != "s3cret" {
+		t.Fatalf("Webhooks = %+v, %v", hooks, err)
+	}
+
+	if err := RemoveWebhook(db, "car-other", hook.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("remove from another car: err = %v, want ErrNotFound", err)
+	}
+	if err := RemoveWebhook(db, c.ID, hook.ID); err != nil {
+		t.Fatalf("RemoveWebhook: %v", err)
+	}
+	if hooks, _ := Webhooks(db, c.ID); len(hooks) != 0 {
+		t.Errorf("hooks after remove = %+v", hooks)
+	}
+}
@@ -0,0 +1,54 @@
+// Package clock abstracts time so heartbeats, stall timers, digests, and
+// backoffs can be driven by a controllable fake in tests instead of real
+// sleeps. Production code uses Real; tests use NewFake and Advance.
+package clock
+
+import "time"
+
+// Clock is the subset of the time package that time-based logic depends on.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// After waits for d to elapse and then sends the current time on the
+	// returned channel.
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the calling goroutine for at least d.
+	Sleep(d time.Duration)
+	// NewTicker returns a Ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the clock-agnostic equivalent of *time.Ticker.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker. It does not close the channel.
+	Stop()
+}
+
+// Real is the wall clock, backed by the time package.
+var Real Clock = realClock{}
+
+// OrReal returns c, or Real when c is nil. Constructors use it to default an
+// optional Clock field.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
@@ -0,0 +1,146 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestOrReal(t *testing.T) {
+	if OrReal(nil) != Real {
+		t.Error("OrReal(nil) should return Real")
+	}
+	f := NewFake(start)
+	if OrReal(f) != f {
+		t.Error("OrReal should return a non-nil clock unchanged")
+	}
+}
+
+func TestReal_Now(t *testing.T) {
+	before := time.Now()
+	got := Real.Now()
+	if got.Before(before) {
+		t.Errorf("Real.Now() = %v, before %v", got, before)
+	}
+	tk := Real.NewTicker(time.Millisecond)
+	defer tk.Stop()
+	select {
+	case <-tk.C():
+	case <-time.After(time.Second):
+		t.Fatal("real ticker did not fire")
+	}
+}
+
+func TestFake_AfterFiresOnAdvance(t *testing.T) {
+	f := NewFake(start)
+	ch := f.After(time.Minute)
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("fired before deadline")
+	default:
+	}
+
+	f.Advance(time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(start.Add(time.Minute)) {
+			t.Errorf("fired at %v, want %v", got, start.Add(time.Minute))
+		}
+	default:
+		t.Fatal("did not fire at deadline")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("waiters = %d, want 0", f.Waiters())
+	}
+}
+
+func TestFake_AfterNonPositive(t *testing.T) {
+	f := NewFake(start)
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("After(0) should fire immediately")
+	}
+}
+
+func TestFake_SleepAndBlockUntil(t *testing.T) {
+	f := NewFake(start)
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Hour)
+		close(done)
+	}()
+	if !f.BlockUntil(1, time.Second) {
+		t.Fatal("sleeper never registered")
+	}
+	f.Advance(time.Hour)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep did not return after Advance")
+	}
+	if f.Since(start) != time.Hour {
+		t.Errorf("Since = %v, want 1h", f.Since(start))
+	}
+}
+
+func TestFake_Ticker(t *testing.T) {
+	f := NewFake(start)
+	tk := f.NewTicker(10 * time.Second)
+
+	f.Advance(10 * time.Second)
+	select {
+	case <-tk.C():
+	default:
+		t.Fatal("ticker did not fire after one period")
+	}
+
+	// Skipping several periods delivers a single tick (dropped like time.Ticker).
+	f.Advance(35 * time.Second)
+	select {
+	case <-tk.C():
+	default:
+		t.Fatal("ticker did not fire after multiple periods")
+	}
+	select {
+	case <-tk.C():
+		t.Fatal("ticker should not queue missed ticks")
+	default:
+	}
+
+	// Re-armed on the next boundary (50s), not 35s after the last advance.
+	f.Advance(5 * time.Second)
+	select {
+	case <-tk.C():
+	default:
+		t.Fatal("ticker did not fire at the next period boundary")
+	}
+
+	tk.Stop()
+	if f.Waiters() != 0 {
+		t.Errorf("waiters after Stop = %d, want 0", f.Waiters())
+	}
+	f.Advance(time.Minute)
+	select {
+	case <-tk.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
+
+func TestFake_Set(t *testing.T) {
+	f := NewFake(start)
+	ch := f.After(time.Hour)
+	f.Set(start.Add(2 * time.Hour))
+	select {
+	case <-ch:
+	default:
+		t.Fatal("Set past deadline should fire timer")
+	}
+	if !f.Now().Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("Now = %v after Set", f.Now())
+	}
+}
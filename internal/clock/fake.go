@@ -0,0 +1,168 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a manually advanced Clock. Timers created with After, Sleep, or
+// NewTicker fire only when Advance moves the clock past their deadline.
+// Fake is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	at     time.Time
+	ch     chan time.Time
+	period time.Duration // >0 for tickers: re-arm after firing
+	stop   bool
+}
+
+// NewFake returns a Fake set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by at least d. A non-positive d fires immediately.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, &fakeWaiter{at: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Sleep blocks until the clock has been advanced by at least d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTicker returns a Ticker that fires each time the clock advances past
+// another multiple of d. Like time.Ticker, ticks are dropped rather than
+// queued when the receiver falls behind. It panics if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{at: f.now.Add(d), ch: make(chan time.Time, 1), period: d}
+	f.waiters = append(f.waiters, w)
+	return &fakeTicker{f: f, w: w}
+}
+
+// Set moves the clock to t (which may be in the past) and fires any timers
+// that are now due.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	f.now = t
+	f.mu.Unlock()
+	f.fire()
+}
+
+// Advance moves the clock forward by d and fires every timer whose deadline
+// has been reached, in deadline order.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	f.mu.Unlock()
+	f.fire()
+}
+
+func (f *Fake) fire() {
+	f.mu.Lock()
+	now := f.now
+	type firing struct {
+		at time.Time
+		ch chan time.Time
+	}
+	var due []firing
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		switch {
+		case w.stop:
+		case !w.at.After(now):
+			due = append(due, firing{at: w.at, ch: w.ch})
+			if w.period > 0 {
+				// Re-arm at the first period boundary after now.
+				for !w.at.After(now) {
+					w.at = w.at.Add(w.period)
+				}
+				pending = append(pending, w)
+			}
+		default:
+			pending = append(pending, w)
+		}
+	}
+	f.waiters = pending
+	f.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, w := range due {
+		select {
+		case w.ch <- now:
+		default: // ticker receiver is behind; drop like time.Ticker
+		}
+	}
+}
+
+// Waiters returns the number of pending timers and tickers. Tests use it to
+// wait until the code under test has started sleeping before calling Advance.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil polls (in real time) until at least n timers are pending or
+// timeout elapses, and reports whether the condition was met.
+func (f *Fake) BlockUntil(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for f.Waiters() < n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	t.w.stop = true
+	for i, w := range t.f.waiters {
+		if w == t.w {
+			t.f.waiters = append(t.f.waiters[:i], t.f.waiters[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables BootstrapYAML reads to write a config for a yard
+// running in a container (ry container run).
+const (
+	EnvConfig        = "RAILYARD_CONFIG"         // a complete railyard.yaml; the other variables are ignored
+	EnvOwner         = "RAILYARD_OWNER"          // owner (required)
+	EnvRepo          = "RAILYARD_REPO"           // repo (required)
+	EnvTracks        = "RAILYARD_TRACKS"         // name:language[:engine_slots],... (required)
+	EnvAgentProvider = "RAILYARD_AGENT_PROVIDER" // agent_provider; set by the engine image
+	EnvDBHost        = "RAILYARD_DB_HOST"
+	EnvDBPort        = "RAILYARD_DB_PORT"
+	EnvDBName        = "RAILYARD_DB_NAME"
+	EnvDBUser        = "RAILYARD_DB_USER"
+	EnvDBPassword    = "RAILYARD_DB_PASSWORD" // referenced as ${RAILYARD_DB_PASSWORD}, never written to the file
+)
+
+// bootstrapConfig is the subset of Config BootstrapYAML writes.
+type bootstrapConfig struct {
+	Owner         string `yaml:"owner"`
+	Repo          string `yaml:"repo"`
+	AgentProvider string `yaml:"agent_provider,omitempty"`
+	Database      struct {
+		Host     string `yaml:"host,omitempty"`
+		Port     int    `yaml:"port,omitempty"`
+		Database string `yaml:"database,omitempty"`
+		Username string `yaml:"username,omitempty"`
+		Password string `yaml:"password,omitempty"`
+	} `yaml:"database"`
+	Tracks []bootstrapTrack `yaml:"tracks"`
+}
+
+type bootstrapTrack struct {
+	Name        string `yaml:"name"`
+	Language    string `yaml:"language"`
+	EngineSlots int    `yaml:"engine_slots,omitempty"`
+}
+
+// BootstrapYAML builds a railyard.yaml from the RAILYARD_* environment
+// variables read through getenv: RAILYARD_CONFIG verbatim when set,
+// otherwise a minimal config from the owner, repo, tracks, and database
+// variables. The result is validated before it is returned.
+func BootstrapYAML(getenv func(string) string) ([]byte, error) {
+	data := []byte(getenv(EnvConfig))
+	if len(data) == 0 {
+		var bc bootstrapConfig
+		bc.Owner = getenv(EnvOwner)
+		bc.Repo = getenv(EnvRepo)
+		if bc.Owner == "" || bc.Repo == "" || getenv(EnvTracks) == "" {
+			return nil, fmt.Errorf("config: bootstrap: set %s, or %s, %s, and %s", EnvConfig, EnvOwner, EnvRepo, EnvTracks)
+		}
+		bc.AgentProvider = getenv(EnvAgentProvider)
+		bc.Database.Host = getenv(EnvDBHost)
+		bc.Database.Database = getenv(EnvDBName)
+		bc.Database.Username = getenv(EnvDBUser)
+		if getenv(EnvDBPassword) != "" {
+			bc.Database.Password = "${" + EnvDBPassword + "}"
+		}
+		if p := getenv(EnvDBPort); p != "" {
+			port, err := strconv.Atoi(p)
+			if err != nil || port <= 0 {
+				return nil, fmt.Errorf("config: bootstrap: invalid %s %q", EnvDBPort, p)
+			}
+			bc.Database.Port = port
+		}
+		tracks, err := parseBootstrapTracks(getenv(EnvTracks))
+		if err != nil {
+			return nil, err
+		}
+		bc.Tracks = tracks
+		if data, err = yaml.Marshal(&bc); err != nil {
+			return nil, fmt.Errorf("config: bootstrap: %w", err)
+		}
+	}
+	if _, err := Parse(data); err != nil {
+		return nil, fmt.Errorf("config: bootstrap: %w", err)
+	}
+	return data, nil
+}
+
+// parseBootstrapTracks parses RAILYARD_TRACKS, e.g. "backend:go:3,web:typescript".
+func parseBootstrapTracks(s string) ([]bootstrapTrack, error) {
+	var tracks []bootstrapTrack
+	for _, spec := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("config: bootstrap: invalid %s entry %q (want name:language[:engine_slots])", EnvTracks, spec)
+		}
+		t := bootstrapTrack{Name: parts[0], Language: parts[1]}
+		if len(parts) == 3 {
+			n, err := strconv.Atoi(parts[2])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("config: bootstrap: invalid engine_slots in %s entry %q", EnvTracks, spec)
+			}
+			t.EngineSlots = n
+		}
+		tracks = append(tracks, t)
+	}
+	return tracks, nil
+}
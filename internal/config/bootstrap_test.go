@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBootstrapYAML(t *testing.T) {
+	env := map[string]string{
+		EnvOwner:         "alice",
+		EnvRepo:          "git@github.com:org/app.git",
+		EnvTracks:        "backend:go:3, web:typescript",
+		EnvAgentProvider: "codex",
+		EnvDBHost:        "db.internal",
+		EnvDBPort:        "3307",
+		EnvDBPassword:    "s3cret",
+	}
+	t.Setenv(EnvDBPassword, "s3cret")
+	data, err := BootstrapYAML(func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatalf("BootstrapYAML: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") || !strings.Contains(string(data), "${RAILYARD_DB_PASSWORD}") {
+		t.Errorf("password written to the config:\n%s", data)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Owner != "alice" || cfg.AgentProvider != "codex" || cfg.Database.Host != "db.internal" || cfg.Database.Port != 3307 || cfg.Database.Password != "s3cret" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if len(cfg.Tracks) != 2 || cfg.Tracks[0].EngineSlots != 3 || cfg.Tracks[1].Name != "web" || cfg.Tracks[1].Language != "typescript" {
+		t.Errorf("tracks = %+v", cfg.Tracks)
+	}
+}
+
+func TestBootstrapYAML_FullConfig(t *testing.T) {
+	full := "owner: bob\nrepo: org/app\ntracks:\n  - name: backend\n    language: go\n"
+	data, err := BootstrapYAML(func(k string) string {
+		if k == EnvConfig {
+			return full
+		}
+		return "ignored"
+	})
+	if err != nil || string(data) != full {
+		t.Errorf("BootstrapYAML = %q, %v; want RAILYARD_CONFIG verbatim", data, err)
+	}
+}
+
+func TestBootstrapYAML_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{EnvOwner: "alice"}, "set RAILYARD_CONFIG, or RAILYARD_OWNER"},
+		{map[string]string{EnvOwner: "alice", EnvRepo: "org/app", EnvTracks: "backend"}, `invalid RAILYARD_TRACKS entry "backend"`},
+		{map[string]string{EnvOwner: "alice", EnvRepo: "org/app", EnvTracks: "backend:go:0"}, "invalid engine_slots"},
+		{map[string]string{EnvOwner: "alice", EnvRepo: "org/app", EnvTracks: "backend:go", EnvDBPort: "x"}, "invalid RAILYARD_DB_PORT"},
+		{map[string]string{EnvConfig: "owner: alice\n"}, "config: bootstrap:"},
+	} {
+		_, err := BootstrapYAML(func(k string) string { return tc.env[k] })
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("env %v: err = %v, want %q", tc.env, err, tc.want)
+		}
+	}
+}
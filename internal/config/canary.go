@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCanaryPercent is the share of a track's cars put in the canary
+// group when canary.percent is unset.
+const DefaultCanaryPercent = 20
+
+// CanaryConfig trials changed track settings on a share of the track's cars
+// before they apply to all of them. A car joins the canary group when an
+// engine first claims it; its engine prompt and its merge gate then use the
+// settings here in place of the track's. `ry config canary` compares the
+// group with the rest of the track and `ry config promote` moves the
+// settings into the track.
+type CanaryConfig struct {
+	Percent        int                    `yaml:"percent"`          // share of newly claimed cars in the canary group, 1-100; default 20
+	PreTestCommand string                 `yaml:"pre_test_command"` // replaces the track's pre_test_command at the merge gate
+	TestCommand    string                 `yaml:"test_command"`     // replaces the track's test_command at the merge gate
+	AgentModel     string                 `yaml:"agent_model"`      // replaces the track's agent_model
+	Conventions    map[string]interface{} `yaml:"conventions"`      // replaces the track's conventions in the engine prompt
+}
+
+// canaryKeys are the CanaryConfig fields that override track settings, by
+// YAML key.
+var canaryKeys = []string{"pre_test_command", "test_command", "agent_model", "conventions"}
+
+// InCanary reports whether carID falls in a canary group covering percent
+// of cars. The split hashes the ID, so it is stable for a car.
+func InCanary(carID string, percent int) bool {
+	h := fnv.New32a()
+	h.Write([]byte(carID))
+	return int(h.Sum32()%100) < percent
+}
+
+// WithCanary returns the track with its canary settings applied, for a car
+// in the canary group. A track without a canary is returned unchanged.
+func (t TrackConfig) WithCanary() TrackConfig {
+	cn := t.Canary
+	if cn == nil {
+		return t
+	}
+	if cn.PreTestCommand != "" {
+		t.PreTestCommand = cn.PreTestCommand
+	}
+	if cn.TestCommand != "" {
+		t.TestCommand = cn.TestCommand
+	}
+	if cn.AgentModel != "" {
+		t.AgentModel = cn.AgentModel
+	}
+	if cn.Conventions != nil {
+		t.Conventions = cn.Conventions
+	}
+	return t
+}
+
+// PromoteCanary rewrites a railyard.yaml document so the named track's
+// canary settings replace the track's own and the canary block is removed.
+// Comments elsewhere in the file are kept.
+func PromoteCanary(data []byte, track string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("config: parse: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("config: track %q not found", track)
+	}
+	tracks := mappingValue(doc.Content[0], "tracks")
+	if tracks == nil || tracks.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("config: track %q not found", track)
+	}
+	var t *yaml.Node
+	for _, item := range tracks.Content {
+		if name := mappingValue(item, "name"); name != nil && name.Value == track {
+			t = item
+			break
+		}
+	}
+	if t == nil {
+		return nil, fmt.Errorf("config: track %q not found", track)
+	}
+	idx := mappingIndex(t, "canary")
+	if idx < 0 || t.Content[idx+1].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config: track %q has no canary to promote", track)
+	}
+	canary := t.Content[idx+1]
+	t.Content = append(t.Content[:idx], t.Content[idx+2:]...)
+
+	for _, key := range canaryKeys {
+		ci := mappingIndex(canary, key)
+		if ci < 0 {
+			continue
+		}
+		if ti := mappingIndex(t, key); ti >= 0 {
+			t.Content[ti+1] = canary.Content[ci+1]
+		} else {
+			t.Content = append(t.Content, canary.Content[ci], canary.Content[ci+1])
+		}
+	}
+	return encodeYAML(&doc)
+}
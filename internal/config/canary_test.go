@@ -0,0 +1,126 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const canaryYAML = `owner: alice
+repo: git@github.com:org/app.git
+tracks:
+  - name: backend
+    language: go
+    test_command: go test ./...
+    conventions:
+      style: standard
+    canary:
+      percent: 25
+      test_command: go test -race ./... # trial the race detector
+      conventions:
+        style: strict
+  - name: frontend
+    language: typescript
+    canary:
+      agent_model: sonnet
+`
+
+func TestParse_Canary(t *testing.T) {
+	cfg, err := Parse([]byte(canaryYAML))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	be := cfg.Tracks[0]
+	if be.Canary == nil || be.Canary.Percent != 25 || be.Canary.TestCommand != "go test -race ./..." {
+		t.Fatalf("canary = %+v", be.Canary)
+	}
+	if got := cfg.Tracks[1].Canary.Percent; got != DefaultCanaryPercent {
+		t.Errorf("default percent = %d, want %d", got, DefaultCanaryPercent)
+	}
+
+	c := be.WithCanary()
+	if c.TestCommand != "go test -race ./..." || c.Conventions["style"] != "strict" || c.Language != "go" {
+		t.Errorf("WithCanary = %+v", c)
+	}
+	if be.TestCommand != "go test ./..." {
+		t.Errorf("WithCanary modified the track: %q", be.TestCommand)
+	}
+	if fe := cfg.Tracks[1].WithCanary(); fe.AgentModel != "sonnet" {
+		t.Errorf("frontend canary model = %q", fe.AgentModel)
+	}
+}
+
+func TestParse_CanaryValidation(t *testing.T) {
+	yaml := `
+owner: alice
+repo: git@github.com:org/app.git
+tracks:
+  - name: backend
+    language: go
+    canary:
+      percent: 150
+      test_command: go test -race ./...
+  - name: frontend
+    language: typescript
+    canary:
+      percent: 10
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		`track "backend": canary.percent must be between 1 and 100`,
+		`track "frontend": canary needs at least one of test_command, pre_test_command, agent_model, or conventions`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q: %v", want, err)
+		}
+	}
+}
+
+func TestInCanary(t *testing.T) {
+	in := 0
+	for i := 0; i < 1000; i++ {
+		id := "car-" + strings.Repeat("x", i%7) + string(rune('a'+i%26)) + string(rune('a'+i/26%26))
+		if InCanary(id, 20) {
+			in++
+		}
+		if InCanary(id, 20) != InCanary(id, 20) {
+			t.Fatalf("InCanary(%q) is not stable", id)
+		}
+		if InCanary(id, 0) || !InCanary(id, 100) {
+			t.Fatalf("InCanary(%q) ignores 0/100 bounds", id)
+		}
+	}
+	if in < 100 || in > 300 {
+		t.Errorf("%d of 1000 cars in a 20%% canary", in)
+	}
+}
+
+func TestPromoteCanary(t *testing.T) {
+	out, err := PromoteCanary([]byte(canaryYAML), "backend")
+	if err != nil {
+		t.Fatalf("PromoteCanary: %v", err)
+	}
+	cfg, err := Parse(out)
+	if err != nil {
+		t.Fatalf("promoted config does not parse: %v\n%s", err, out)
+	}
+	be := cfg.Tracks[0]
+	if be.Canary != nil || be.TestCommand != "go test -race ./..." || be.Conventions["style"] != "strict" {
+		t.Errorf("promoted backend = %+v", be)
+	}
+	if cfg.Tracks[1].Canary == nil {
+		t.Error("frontend canary was removed")
+	}
+	if !strings.Contains(string(out), "# trial the race detector") {
+		t.Errorf("comment lost:\n%s", out)
+	}
+
+	if _, err := PromoteCanary(out, "backend"); err == nil || !strings.Contains(err.Error(), `track "backend" has no canary`) {
+		t.Errorf("second promote err = %v", err)
+	}
+	if _, err := PromoteCanary(out, "nope"); err == nil || !strings.Contains(err.Error(), `track "nope" not found`) {
+		t.Errorf("unknown track err = %v", err)
+	}
+}
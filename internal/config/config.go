@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -34,23 +38,39 @@ type Config struct {
 	BranchPrefix      string              `yaml:"branch_prefix"`
 	DefaultBranch     string              `yaml:"default_branch"`
 	DefaultAcceptance string              `yaml:"default_acceptance"`
+	CarIDs            CarIDConfig         `yaml:"car_ids"`
 	RequirePR         bool                `yaml:"require_pr"`
+	PRTemplate        PRTemplateConfig    `yaml:"pr_template"`
 	DashboardURL      string              `yaml:"dashboard_url"`
+	Dashboard         DashboardConfig     `yaml:"dashboard"`
 	Database          DatabaseConfig      `yaml:"database"`
 	Stall             StallConfig         `yaml:"stall"`
+	Autoscale         AutoscaleConfig     `yaml:"autoscale"`
+	Retention         RetentionConfig     `yaml:"retention"`
+	Dispatch          DispatchConfig      `yaml:"dispatch"`
 	Tracks            []TrackConfig       `yaml:"tracks"`
 	Notifications     NotificationsConfig `yaml:"notifications"`
+	Webhooks          []WebhookConfig     `yaml:"webhooks"`
 	CocoIndex         CocoIndexConfig     `yaml:"cocoindex"`
 	Bull              BullConfig          `yaml:"bull"`
 	Inspect           InspectConfig       `yaml:"inspect"`
 	Telegraph         TelegraphConfig     `yaml:"telegraph"`
+	Network           NetworkConfig       `yaml:"network"`
 	Kubernetes        KubernetesConfig    `yaml:"kubernetes"`
+	Tmux              TmuxConfig          `yaml:"tmux"`
+	// Multiplexer selects the terminal multiplexer that hosts the yardmaster,
+	// engine, and daemon sessions: "tmux" (default) or "zellij".
+	Multiplexer string       `yaml:"multiplexer"`
+	Zellij      ZellijConfig `yaml:"zellij"`
 	// MCPServers declares additional MCP servers (keyed by server name) to
 	// merge into the .mcp.json written to dispatch/engine worktrees. The
 	// name "railyard_cocoindex" is reserved for the built-in codesearch
 	// server.
-	MCPServers    map[string]MCPServerConfig `yaml:"mcp_servers"`
-	AgentProvider string                     `yaml:"agent_provider"`
+	MCPServers map[string]MCPServerConfig `yaml:"mcp_servers"`
+	// Templates pre-fill `ry car create --template <name>`, keyed by name.
+	// Templates can also live in .railyard/templates/<name>.yaml.
+	Templates     map[string]CarTemplate `yaml:"templates"`
+	AgentProvider string                 `yaml:"agent_provider"`
 	// AgentModel selects a specific model for the configured agent provider.
 	// Unlike AgentProvider (which defaults to "claude"), AgentModel has no
 	// default — empty means "let the provider's CLI choose". The value
@@ -89,6 +109,11 @@ type Config struct {
 	// populated by the loader from leftover top-level keys, never directly
 	// unmarshaled.
 	PluginConfigs map[string]yaml.Node `yaml:"-"`
+
+	// Overrides are the "path=value" overrides (ry --set) applied over the
+	// file by LoadWithOverrides. Daemons launched from this config are
+	// passed them too, so the whole yard runs with the same values.
+	Overrides []string `yaml:"-"`
 }
 
 // CodexConfig holds settings specific to the Codex CLI provider.
@@ -380,6 +405,24 @@ type YardmasterConfig struct {
 	AutoMergeOnApproval bool   `yaml:"auto_merge_on_approval"`
 	ReworkLabel         string `yaml:"rework_label"`
 	RevisedLabel        string `yaml:"revised_label"`
+	// DisablePreemption stops the yardmaster from parking low-priority work
+	// when a P0 car is waiting and every engine on its track is busy.
+	DisablePreemption bool `yaml:"disable_preemption"`
+	// DraftPROnPush (with require_pr) opens a draft PR as soon as an
+	// engine pushes its first commit and keeps its body current with
+	// progress notes and check status, instead of waiting for the car to
+	// finish. The PR is marked ready for review when the car is done.
+	DraftPROnPush bool `yaml:"draft_pr_on_push"`
+	// ConflictAssist spawns a conflict car when a car's merge conflicts
+	// with its base branch: the merge, conflict markers and all, is
+	// committed on the conflict car's branch for an engine to resolve, and
+	// the resolved branch re-enters the merge gate in the original's place.
+	ConflictAssist bool `yaml:"conflict_assist"`
+	// FailurePatternsFile holds the output patterns ry merge relabel learns
+	// when an operator corrects a merge-gate failure's classification
+	// (infrastructure vs. test). The merge gate reads it on every failed
+	// run. Default: .railyard/failure-patterns.yaml.
+	FailurePatternsFile string `yaml:"failure_patterns_file"`
 }
 
 // IsKubernetesMode returns true when the config targets a Kubernetes deployment.
@@ -426,6 +469,37 @@ type NotificationsConfig struct {
 	Command string `yaml:"command"` // shell command template, e.g. "notify-send 'Railyard' '{{.Subject}}'"
 }
 
+// Webhook event names for webhooks[].events.
+const (
+	WebhookCarStatusChanged = "car.status_changed"
+	WebhookEngineStalled    = "engine.stalled"
+	WebhookSwitchFailed     = "switch.failed"
+	WebhookYardPaused       = "yard.paused"
+	WebhookYardResumed      = "yard.resumed"
+)
+
+// ValidWebhookEvents lists the accepted webhooks[].events values.
+var ValidWebhookEvents = []string{
+	WebhookCarStatusChanged,
+	WebhookEngineStalled,
+	WebhookSwitchFailed,
+	WebhookYardPaused,
+	WebhookYardResumed,
+}
+
+// WebhookConfig is one outbound webhook: the yardmaster POSTs a JSON payload
+// to URL for each subscribed lifecycle event.
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`
+	Events []string `yaml:"events"` // event names to send; empty sends all
+	Secret string   `yaml:"secret"` // HMAC-SHA256 key for X-Railyard-Signature; supports ${ENV_VAR}
+}
+
+// Wants reports whether the webhook subscribes to event.
+func (w WebhookConfig) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
 // StallConfig holds thresholds for engine stall detection.
 type StallConfig struct {
 	StdoutTimeoutSec         int `yaml:"stdout_timeout_sec"`         // no stdout for N seconds = stall (default 120)
@@ -433,13 +507,50 @@ type StallConfig struct {
 	MaxClearCycles           int `yaml:"max_clear_cycles"`           // more than N cycles = stall (default 5)
 	MaxSwitchFailures        int `yaml:"max_switch_failures"`        // repeated switch failures before escalation (default 3)
 	SwitchTimeoutSec         int `yaml:"switch_timeout_sec"`         // max seconds for switch/runTests (default 600)
+	SwitchNoticeSec          int `yaml:"switch_notice_sec"`          // post a telegraph progress notice each time merge-gate tests run another N seconds (default 300; -1 disables)
+	AbortRequeueSec          int `yaml:"abort_requeue_sec"`          // seconds a car whose test run was aborted from chat waits before re-entering the merge gate (default 900)
+	SwitchRetryMax           int `yaml:"switch_retry_max"`           // automatic merge-gate retries after an infra or push failure before paging (default 4; -1 disables)
+	SwitchRetryBaseSec       int `yaml:"switch_retry_base_sec"`      // wait before the first automatic retry; doubles on each further attempt (default 60)
+	SwitchRetryMaxSec        int `yaml:"switch_retry_max_sec"`       // cap on the wait between automatic retries (default 1800)
 	EscalationCooldownSec    int `yaml:"escalation_cooldown_sec"`    // per-car cooldown between escalations (default 600)
 	MaxConcurrentEscalations int `yaml:"max_concurrent_escalations"` // limit concurrent escalation goroutines (default 3)
 	StaleEngineThresholdSec  int `yaml:"stale_engine_threshold_sec"` // seconds before an engine is considered stale (default 60)
+	StaleClaimSec            int `yaml:"stale_claim_sec"`            // seconds a claimed car may go without progress before it is released (default 900; -1 disables)
 	RateLimitMaxRetries      int `yaml:"rate_limit_max_retries"`     // max consecutive rate-limit retries before stalling (default 3)
 	RateLimitMaxWaitSec      int `yaml:"rate_limit_max_wait_sec"`    // max seconds to wait between retries (default 300)
 }
 
+// WorktreeResetConfig tunes how engines reset their worktree before each
+// car, for repositories where a full clean and checkout is slow.
+type WorktreeResetConfig struct {
+	PreservePaths   []string `yaml:"preserve_paths"`   // untracked paths git clean keeps, e.g. node_modules/, .cache/
+	CheckoutWorkers int      `yaml:"checkout_workers"` // parallel checkout workers for the reset (git checkout.workers); serial when <= 1
+}
+
+// AutoscaleConfig drives the yardmaster's engine autoscaler, which adds
+// engines to a track with a sustained backlog of ready cars (up to the
+// track's engine_slots) and drains engines that sit idle. Local
+// (tmux/zellij) mode only.
+type AutoscaleConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	ReadyThreshold  int  `yaml:"ready_threshold"`    // scale up while a track has more than N ready cars (default 3)
+	ScaleUpAfterMin int  `yaml:"scale_up_after_min"` // ...for N minutes straight; one engine per period (default 5)
+	IdleAfterMin    int  `yaml:"idle_after_min"`     // drain an engine idle for N minutes on a track with no ready cars (default 15)
+	MinEngines      int  `yaml:"min_engines"`        // never scale a track below N engines (default 1)
+}
+
+// RetentionConfig has the yardmaster delete old operational data once a
+// day so the database does not grow without bound on a busy yard. Windows
+// are in days.
+type RetentionConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	DryRun            bool `yaml:"dry_run"`             // log what would be removed without removing it
+	EventsDays        int  `yaml:"events_days"`         // events behind ry events list (default 90)
+	ConversationsDays int  `yaml:"conversations_days"`  // turns of finished dispatch sessions (default 30)
+	AgentLogsDays     int  `yaml:"agent_logs_days"`     // agent pane output behind ry logs; token counts are kept (default 7)
+	MergeAttemptsDays int  `yaml:"merge_attempts_days"` // the yardmaster's failed merge-gate notes on cars (default 180)
+}
+
 // TLSConfig holds TLS settings for encrypted database connections.
 type TLSConfig struct {
 	Enabled    bool   `yaml:"enabled"`
@@ -451,12 +562,52 @@ type TLSConfig struct {
 
 // DatabaseConfig holds connection settings for the MySQL database server.
 type DatabaseConfig struct {
-	Host     string    `yaml:"host"`
-	Port     int       `yaml:"port"`
-	Database string    `yaml:"database"`
-	Username string    `yaml:"username"`
-	Password string    `yaml:"password"`
-	TLS      TLSConfig `yaml:"tls"`
+	Host     string         `yaml:"host"`
+	Port     int            `yaml:"port"`
+	Database string         `yaml:"database"`
+	Username string         `yaml:"username"`
+	Password string         `yaml:"password"`
+	TLS      TLSConfig      `yaml:"tls"`
+	QueryLog QueryLogConfig `yaml:"query_log"`
+}
+
+// DefaultQueryLogPath is where slow queries are logged when query_log.path
+// is unset.
+const DefaultQueryLogPath = ".railyard/logs/slow-queries.jsonl"
+
+// QueryLogConfig enables the slow-query log: every query that takes at least
+// SlowMs is appended to Path with the subsystem that issued it, for
+// ry debug queries to summarize.
+type QueryLogConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	SlowMs  int    `yaml:"slow_ms"` // threshold in milliseconds; default 200
+	Path    string `yaml:"path"`    // JSONL file; default .railyard/logs/slow-queries.jsonl
+}
+
+// Multiplexers accepted by the multiplexer setting.
+const (
+	MultiplexerTmux   = "tmux"
+	MultiplexerZellij = "zellij"
+)
+
+// ValidMultiplexers lists the accepted multiplexer values.
+var ValidMultiplexers = []string{MultiplexerTmux, MultiplexerZellij}
+
+// ZellijConfig selects the zellij installation used when multiplexer is
+// "zellij".
+type ZellijConfig struct {
+	Binary string `yaml:"binary"` // zellij executable name or path; default "zellij"
+}
+
+// TmuxConfig selects the tmux installation Railyard drives. The zero value
+// runs "tmux" from PATH on the default server.
+type TmuxConfig struct {
+	Binary string `yaml:"binary"` // tmux executable name or path; default "tmux"
+	Socket string `yaml:"socket"` // server socket name, passed as -L (e.g. "railyard")
+	// BaseIndex is the first window index on that server. When set, commands
+	// target the session's first window explicitly (session:N) instead of
+	// the active one; set it to match base-index in your tmux.conf.
+	BaseIndex *int `yaml:"base_index"`
 }
 
 // KubernetesConfig holds settings for Kubernetes deployment mode.
@@ -476,19 +627,273 @@ type ScalingConfig struct {
 	ScaleDownIdleMinutes int `yaml:"scale_down_idle_minutes"`
 }
 
+// Claim strategies control the order in which a track's engines pick up
+// ready cars (tracks[].claim_strategy).
+const (
+	ClaimStrategyFIFO          = "fifo"           // oldest car first, ignoring priority
+	ClaimStrategyPriority      = "priority"       // strict priority, oldest first within a level
+	ClaimStrategyPriorityAging = "priority_aging" // priority, boosted one level per claim_aging_hours waited
+	ClaimStrategySmallestFirst = "smallest_first" // smallest estimate first; unestimated cars last
+)
+
+// DefaultClaimAgingHours is the priority_aging boost interval when
+// claim_aging_hours is unset.
+const DefaultClaimAgingHours = 24
+
+// ValidClaimStrategies lists the accepted tracks[].claim_strategy values.
+var ValidClaimStrategies = []string{
+	ClaimStrategyFIFO,
+	ClaimStrategyPriority,
+	ClaimStrategyPriorityAging,
+	ClaimStrategySmallestFirst,
+}
+
+// ClaimPolicyWeighted is the dispatch.claim_policy name for
+// ClaimStrategyPriorityAging: priority first, weighted by time waited.
+const ClaimPolicyWeighted = "weighted"
+
+// DispatchConfig holds yard-wide defaults for how ready cars are handed out.
+type DispatchConfig struct {
+	// ClaimPolicy is the claim order for tracks that set no claim_strategy:
+	// priority (default), fifo, or weighted. Any claim_strategy value is
+	// also accepted.
+	ClaimPolicy string `yaml:"claim_policy"`
+}
+
+// ClaimStrategyForPolicy returns the claim strategy a dispatch.claim_policy
+// value selects, or "" if the policy is not recognised.
+func ClaimStrategyForPolicy(policy string) string {
+	switch {
+	case policy == "":
+		return ClaimStrategyPriority
+	case policy == ClaimPolicyWeighted:
+		return ClaimStrategyPriorityAging
+	case slices.Contains(ValidClaimStrategies, policy):
+		return policy
+	}
+	return ""
+}
+
 // TrackConfig defines an area of concern within the repo.
 type TrackConfig struct {
 	Name                  string                   `yaml:"name"`
+	Type                  string                   `yaml:"type"` // code (default), docs, or config; see TrackTypeFor
 	Language              string                   `yaml:"language"`
+	Repo                  string                   `yaml:"repo"`           // git URL of the track's own repository; the top-level repo when unset
+	DefaultBranch         string                   `yaml:"default_branch"` // base branch for the track's cars; the top-level default_branch when unset
 	FilePatterns          []string                 `yaml:"file_patterns"`
 	EngineSlots           int                      `yaml:"engine_slots"`
 	StallStdoutTimeoutSec int                      `yaml:"stall_stdout_timeout_sec"`
 	PreTestCommand        string                   `yaml:"pre_test_command"`
 	TestCommand           string                   `yaml:"test_command"`
+	TestTimeoutSec        int                      `yaml:"test_timeout_sec"`      // max seconds for the track's merge-gate tests; stall.switch_timeout_sec when unset
+	TestMatrix            []TestMatrixCell         `yaml:"test_matrix"`           // named test cells run at the merge gate instead of test_command
+	TestMatrixParallel    bool                     `yaml:"test_matrix_parallel"`  // run test_matrix cells concurrently
+	TestRunner            *TestRunnerConfig        `yaml:"test_runner,omitempty"` // where merge-gate tests run; local when unset
+	Coverage              *CoverageConfig          `yaml:"coverage,omitempty"`    // coverage tracking and regression gate; off when unset
+	DiffLimit             *DiffLimitConfig         `yaml:"diff_limit,omitempty"`  // max files/lines a car may change before its merge is deferred; off when unset
+	SwitchConcurrency     int                      `yaml:"switch_concurrency"`    // done cars whose merge-gate tests run at once; merges stay serial; defaults to 1
+	ClaimStrategy         string                   `yaml:"claim_strategy"`        // order engines claim ready cars; defaults to "priority"
+	ClaimAgingHours       int                      `yaml:"claim_aging_hours"`     // priority_aging: hours waited per one-level boost; defaults to 24
 	Conventions           map[string]interface{}   `yaml:"conventions"`
 	AgentProvider         string                   `yaml:"agent_provider"`
 	AgentModel            string                   `yaml:"agent_model"`
 	Playwright            *models.PlaywrightConfig `yaml:"playwright,omitempty"`
+	PRTemplate            *PRTemplateConfig        `yaml:"pr_template,omitempty"`    // per-track override of the top-level pr_template
+	IDPrefix              string                   `yaml:"id_prefix"`                // per-track override of car_ids.prefix, e.g. "be-"
+	Sandbox               *SandboxConfig           `yaml:"sandbox,omitempty"`        // run engine agents in a sandbox; off when unset
+	Resources             *ResourcesConfig         `yaml:"resources,omitempty"`      // CPU/memory limits for engine agents; unlimited when unset
+	CommandPolicy         *CommandPolicyConfig     `yaml:"command_policy,omitempty"` // shell commands engine agents may run; unrestricted when unset
+	Canary                *CanaryConfig            `yaml:"canary,omitempty"`         // settings trialled on a share of the track's cars; see ry config canary
+	WorktreeReset         *WorktreeResetConfig     `yaml:"worktree_reset,omitempty"` // how engines reset their worktree between cars; full clean when unset
+	RequirePR             *bool                    `yaml:"require_pr,omitempty"`     // per-track override of require_pr; see RequirePRFor
+}
+
+// PRTemplateConfig customizes the pull requests the yardmaster opens when
+// require_pr is set. Title and Body are Go text/template strings rendered
+// with yardmaster.PRTemplateData; an empty field keeps the built-in format.
+// Checklist items are exposed to the body template as .Checklist.
+type PRTemplateConfig struct {
+	Title     string   `yaml:"title"`
+	Body      string   `yaml:"body"`
+	Checklist []string `yaml:"checklist"`
+}
+
+// ResolvePRTemplate returns the PR template for a track: each non-empty
+// field of the track's pr_template overrides the top-level one.
+func (c *Config) ResolvePRTemplate(track string) PRTemplateConfig {
+	tmpl := c.PRTemplate
+	for _, t := range c.Tracks {
+		if t.Name != track || t.PRTemplate == nil {
+			continue
+		}
+		if t.PRTemplate.Title != "" {
+			tmpl.Title = t.PRTemplate.Title
+		}
+		if t.PRTemplate.Body != "" {
+			tmpl.Body = t.PRTemplate.Body
+		}
+		if t.PRTemplate.Checklist != nil {
+			tmpl.Checklist = t.PRTemplate.Checklist
+		}
+		break
+	}
+	return tmpl
+}
+
+// CarIDConfig sets the format of generated car IDs; the zero value keeps the
+// default car-xxxxxxxx. A car's ID is fixed when it is created, so its
+// branch, PR, and merge trailer keep one stable reference even if the format
+// or the car's title changes later.
+type CarIDConfig struct {
+	Prefix string `yaml:"prefix"` // leading text of every ID; default "car-"
+	Length int    `yaml:"length"` // random hex characters, 4-16; default 8
+	Slug   bool   `yaml:"slug"`   // add a slug of the title: car-fix-login-timeout-3f2a91c0
+}
+
+// Car ID limits. IDs are stored in 32-character columns; a slugged ID keeps
+// room for at least MinCarIDSlug characters of title.
+const (
+	DefaultCarIDLength = 8
+	MinCarIDLength     = 4
+	MaxCarIDLength     = 16
+	MaxCarIDSize       = 32
+	MinCarIDSlug       = 4
+)
+
+var carIDPrefixRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// validateCarIDPrefix checks an ID prefix for use in branch names and chat
+// commands. "eng-" is reserved for engine IDs.
+func validateCarIDPrefix(field, prefix string, length int, slug bool) []string {
+	var errs []string
+	if !carIDPrefixRe.MatchString(prefix) {
+		errs = append(errs, fmt.Sprintf("%s %q must be lowercase letters, digits, and dashes", field, prefix))
+	}
+	if strings.HasPrefix(prefix, "eng-") {
+		errs = append(errs, fmt.Sprintf("%s %q is reserved for engine IDs", field, prefix))
+	}
+	size := len(prefix) + length
+	if slug {
+		size += 1 + MinCarIDSlug
+	}
+	if size > MaxCarIDSize {
+		errs = append(errs, fmt.Sprintf("%s %q is too long: IDs would exceed %d characters", field, prefix, MaxCarIDSize))
+	}
+	return errs
+}
+
+// TestMatrixCell is one named entry of a track's test matrix: a test command
+// run with extra environment variables, e.g. the same suite under another Go
+// or Node version.
+type TestMatrixCell struct {
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"`
+	Env     map[string]string `yaml:"env"`
+}
+
+// CoverageConfig tracks a track's test coverage at the merge gate. Coverage
+// is read from Profile (a Go cover profile the test command writes, relative
+// to the repo root) when set, otherwise from the last match of Regex in the
+// test output, whose first capture group is the percentage. Profile is only
+// readable with a local test runner.
+type CoverageConfig struct {
+	Regex   string  `yaml:"regex"`    // e.g. `total:\s+\(statements\)\s+([\d.]+)%`
+	Profile string  `yaml:"profile"`  // e.g. coverage.out
+	MaxDrop float64 `yaml:"max_drop"` // block merges losing more than this many percentage points; 0 records only
+}
+
+// DiffLimitConfig caps how much a single car may change, keeping merges
+// reviewable. The yardmaster measures the branch against its base before
+// running tests; a car over either limit is blocked and dispatch is asked to
+// split it. Files matching an Exclude glob (e.g. go.sum, *.lock, or
+// gen/*.pb.go) are not counted; a glob without a slash matches the base name.
+type DiffLimitConfig struct {
+	MaxFiles int      `yaml:"max_files"` // files changed per car; 0 means no limit
+	MaxLines int      `yaml:"max_lines"` // lines added plus deleted per car; 0 means no limit
+	Exclude  []string `yaml:"exclude"`   // path globs left out of both counts
+}
+
+// Test runner types for tracks[].test_runner.type.
+const (
+	TestRunnerLocal = "local" // yardmaster worktree (default)
+	TestRunnerSSH   = "ssh"   // a dedicated clone on a remote host, over ssh
+	TestRunnerCI    = "ci"    // a CI job triggered and polled over HTTP
+)
+
+// DefaultTestRunnerPollSec is the CI runner poll interval when
+// poll_interval_sec is unset.
+const DefaultTestRunnerPollSec = 15
+
+// TestRunnerConfig offloads a track's merge-gate tests from the coordinator.
+// The ssh runner runs the pre-test command and test cells in WorkDir, a
+// dedicated clone on Host; the ci runner POSTs the job to TriggerURL and
+// polls the returned status URL for the result and log.
+type TestRunnerConfig struct {
+	Type string `yaml:"type"` // local, ssh, or ci
+
+	// ssh
+	Host         string `yaml:"host"` // [user@]host
+	Port         int    `yaml:"port"`
+	IdentityFile string `yaml:"identity_file"`
+	WorkDir      string `yaml:"work_dir"` // remote clone with an "origin" remote
+
+	// ci
+	TriggerURL      string `yaml:"trigger_url"`
+	Token           string `yaml:"token"` // sent as a bearer token; supports ${VAR}
+	PollIntervalSec int    `yaml:"poll_interval_sec"`
+}
+
+// Engine sandbox tools.
+const (
+	SandboxBubblewrap = "bubblewrap"
+	SandboxFirejail   = "firejail"
+)
+
+// SandboxConfig runs a track's engine agents inside a Linux sandbox: the
+// filesystem is read-only except the car's worktree, Writable, and a private
+// /tmp; privilege escalation (sudo) is blocked; and network egress goes
+// through an allow-listing proxy that only reaches AllowHosts.
+type SandboxConfig struct {
+	Tool       string   `yaml:"tool"`        // bubblewrap or firejail
+	AllowHosts []string `yaml:"allow_hosts"` // host or *.domain patterns agents may reach; empty blocks all egress
+	Writable   []string `yaml:"writable"`    // extra writable paths, e.g. ~/.cache/go-build; ~ expands to $HOME
+}
+
+// ResourcesConfig limits the CPU and memory a track's engine agents and the
+// builds they run may use, so a yard of busy engines cannot starve the host.
+// Nice and CPUs work anywhere; MemoryMax and CPUQuota put each agent in its
+// own cgroup v2 scope through systemd-run and need Linux with a user systemd.
+type ResourcesConfig struct {
+	Nice      int    `yaml:"nice"`       // scheduling niceness 1-19 for the agent and its children; 0 leaves it unchanged
+	CPUs      int    `yaml:"cpus"`       // parallelism hint: sets GOMAXPROCS, MAKEFLAGS -j, CARGO_BUILD_JOBS and friends
+	MemoryMax string `yaml:"memory_max"` // cgroup memory.max per agent, e.g. 4G; the agent is OOM-killed past it
+	CPUQuota  string `yaml:"cpu_quota"`  // cgroup CPU quota per agent, e.g. 200% for two cores
+}
+
+// validMemoryMax and validCPUQuota match the systemd MemoryMax= and
+// CPUQuota= forms resources accepts.
+var (
+	validMemoryMax = regexp.MustCompile(`^[1-9][0-9]*[KMGT]?$`)
+	validCPUQuota  = regexp.MustCompile(`^[1-9][0-9]*%$`)
+)
+
+// Cgroup reports whether r needs a cgroup scope per agent.
+func (r *ResourcesConfig) Cgroup() bool {
+	return r != nil && (r.MemoryMax != "" || r.CPUQuota != "")
+}
+
+// CommandPolicyConfig restricts the shell commands a track's engine agents
+// may run. Each rule is a command prefix matched word by word, e.g. "rm -rf"
+// or "git push"; the program name also matches by base name, so "rm" covers
+// /bin/rm. Deny rules win over allow rules, and a non-empty Allow list
+// refuses everything it does not match except ry itself. Refused commands
+// never run and are journaled; with BlockCar the session also stops and the
+// car is blocked until a human reopens it.
+type CommandPolicyConfig struct {
+	Allow    []string `yaml:"allow"`     // command prefixes agents may run; empty allows anything not denied
+	Deny     []string `yaml:"deny"`      // command prefixes agents may never run
+	BlockCar bool     `yaml:"block_car"` // stop the session and block the car on the first violation
 }
 
 // ReservedMCPServerName is the .mcp.json server key Railyard owns for its
@@ -573,32 +978,161 @@ type InspectConfig struct {
 	MaxReviewIterations int `yaml:"max_review_iterations"`
 }
 
+// DashboardConfig secures the dashboard server (web UI and /api routes) for
+// access from other machines. The zero value serves plain HTTP with no
+// authentication, which is only appropriate on localhost.
+type DashboardConfig struct {
+	TLS DashboardTLSConfig `yaml:"tls"`
+	// Tokens, when non-empty, are required on every request except static
+	// assets, as "Authorization: Bearer <token>" (or, for browsers, once as
+	// ?token=<token>, which sets a session cookie).
+	Tokens []APITokenConfig `yaml:"tokens"`
+}
+
+// DashboardTLSConfig serves the dashboard over HTTPS, from a certificate on
+// disk or one obtained through ACME, optionally requiring client
+// certificates.
+type DashboardTLSConfig struct {
+	Cert string `yaml:"cert"` // PEM certificate path
+	Key  string `yaml:"key"`  // PEM private key path
+	// ClientCA, when set, enables mutual TLS: clients must present a
+	// certificate signed by a CA in this PEM bundle.
+	ClientCA string     `yaml:"client_ca"`
+	ACME     ACMEConfig `yaml:"acme"`
+}
+
+// ACMEConfig obtains and renews the dashboard certificate from an ACME CA
+// (Let's Encrypt by default) via the TLS-ALPN-01 challenge, so the
+// dashboard must be reachable on port 443 for each domain.
+type ACMEConfig struct {
+	Domains      []string `yaml:"domains"`
+	Email        string   `yaml:"email"`         // contact for expiry notices (optional)
+	CacheDir     string   `yaml:"cache_dir"`     // certificate cache; default .railyard/acme
+	DirectoryURL string   `yaml:"directory_url"` // ACME directory; default Let's Encrypt production
+}
+
+// Enabled reports whether the dashboard serves HTTPS.
+func (t DashboardTLSConfig) Enabled() bool {
+	return t.Cert != "" || len(t.ACME.Domains) > 0
+}
+
+// API token scopes. A read token may use GET routes; an admin token may
+// also call mutating routes such as /api/yard/pause.
+const (
+	TokenScopeRead  = "read"
+	TokenScopeAdmin = "admin"
+)
+
+// APITokenConfig is one dashboard API token.
+type APITokenConfig struct {
+	Name  string `yaml:"name"`  // label for logs
+	Token string `yaml:"token"` // secret; use ${ENV_VAR}
+	Scope string `yaml:"scope"` // "read" (default) or "admin"
+}
+
+// NetworkConfig routes outbound HTTP and websocket traffic (Slack, Discord,
+// GitHub, agent APIs) through a proxy and trusts an extra CA bundle. Unset
+// proxy fields fall back to the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment
+// variables. See internal/outbound.
+type NetworkConfig struct {
+	HTTPProxy  string `yaml:"http_proxy"`  // proxy for http:// URLs
+	HTTPSProxy string `yaml:"https_proxy"` // proxy for https:// and wss:// URLs
+	NoProxy    string `yaml:"no_proxy"`    // comma-separated hosts/CIDRs that bypass the proxy
+	// CABundle is a PEM file of CA certificates trusted in addition to the
+	// system pool, e.g. a corporate TLS-inspection root.
+	CABundle string `yaml:"ca_bundle"`
+}
+
+// HasProxy reports whether a proxy URL is configured.
+func (n NetworkConfig) HasProxy() bool {
+	return n.HTTPProxy != "" || n.HTTPSProxy != ""
+}
+
 // TelegraphConfig holds settings for the Telegraph chat bridge.
 type TelegraphConfig struct {
-	Platform          string              `yaml:"platform"`            // "slack" or "discord"
-	Channel           string              `yaml:"channel"`             // default channel ID
-	AllowedChannels   []string            `yaml:"allowed_channels"`    // channel IDs the bot may respond in; empty = all
-	ProcessTimeoutSec int                 `yaml:"process_timeout_sec"` // max seconds a dispatch subprocess may run; default 900
-	HealthPort        int                 `yaml:"health_port"`         // HTTP health check port; default 8086
-	Slack             SlackConfig         `yaml:"slack"`
-	Discord           DiscordConfig       `yaml:"discord"`
-	DispatchLock      DispatchLockConfig  `yaml:"dispatch_lock"`
-	Events            EventsConfig        `yaml:"events"`
-	Digest            DigestConfig        `yaml:"digest"`
-	Conversations     ConversationsConfig `yaml:"conversations"`
+	Platform           string              `yaml:"platform"`             // "slack" or "discord"; defaults to the first of Platforms
+	Platforms          []string            `yaml:"platforms"`            // run several platforms at once; defaults to [Platform]
+	Channel            string              `yaml:"channel"`              // default channel ID
+	AllowedChannels    []string            `yaml:"allowed_channels"`     // channel IDs the bot may respond in; empty = all
+	ProcessTimeoutSec  int                 `yaml:"process_timeout_sec"`  // max seconds a dispatch subprocess may run; default 900
+	ShutdownTimeoutSec int                 `yaml:"shutdown_timeout_sec"` // max seconds to drain in-flight dispatch turns on shutdown; default 60
+	HealthPort         int                 `yaml:"health_port"`          // HTTP health check port; default 8086
+	StatusCacheTTLSec  int                 `yaml:"status_cache_ttl_sec"` // seconds chat commands and digests reuse one status read; default 5, negative disables
+	Slack              SlackConfig         `yaml:"slack"`
+	Discord            DiscordConfig       `yaml:"discord"`
+	DispatchLock       DispatchLockConfig  `yaml:"dispatch_lock"`
+	Events             EventsConfig        `yaml:"events"`
+	Digest             DigestConfig        `yaml:"digest"`
+	Conversations      ConversationsConfig `yaml:"conversations"`
+	Inbound            InboundConfig       `yaml:"inbound"`
+	// HTTPAgent, when Endpoint is set, runs dispatch turns on a hosted agent
+	// API instead of a local claude CLI or native loop.
+	HTTPAgent HTTPAgentConfig `yaml:"http_agent"`
+	// Users maps car owner handles (without "@") to platform user IDs so
+	// owners can be DM'd when their car merges, fails, or escalates. With
+	// several platforms it applies to the primary one (Platform); the others
+	// take their own slack.users / discord.users.
+	Users map[string]string `yaml:"users"`
+}
+
+// EnabledPlatforms returns the platforms Telegraph connects to, primary
+// first.
+func (t TelegraphConfig) EnabledPlatforms() []string {
+	if len(t.Platforms) > 0 {
+		return t.Platforms
+	}
+	if t.Platform != "" {
+		return []string{t.Platform}
+	}
+	return nil
+}
+
+// PlatformChannel returns the default channel ID on platform: its own
+// channel_id when set, otherwise Channel.
+func (t TelegraphConfig) PlatformChannel(platform string) string {
+	var id string
+	switch platform {
+	case "slack":
+		id = t.Slack.ChannelID
+	case "discord":
+		id = t.Discord.ChannelID
+	}
+	if id == "" {
+		id = t.Channel
+	}
+	return id
+}
+
+// PlatformUsers returns the owner-handle to user-ID map for platform: its
+// own users map when set, otherwise Users for the primary platform.
+func (t TelegraphConfig) PlatformUsers(platform string) map[string]string {
+	var users map[string]string
+	switch platform {
+	case "slack":
+		users = t.Slack.Users
+	case "discord":
+		users = t.Discord.Users
+	}
+	if len(users) == 0 && platform == t.Platform {
+		users = t.Users
+	}
+	return users
 }
 
 // SlackConfig holds Slack-specific credentials.
 type SlackConfig struct {
-	BotToken string `yaml:"bot_token"` // xoxb-...
-	AppToken string `yaml:"app_token"` // xapp-...
+	BotToken  string            `yaml:"bot_token"`  // xoxb-...
+	AppToken  string            `yaml:"app_token"`  // xapp-...
+	ChannelID string            `yaml:"channel_id"` // overrides telegraph.channel on Slack
+	Users     map[string]string `yaml:"users"`      // owner handle → Slack user ID
 }
 
 // DiscordConfig holds Discord-specific credentials.
 type DiscordConfig struct {
-	BotToken  string `yaml:"bot_token"`
-	GuildID   string `yaml:"guild_id"`
-	ChannelID string `yaml:"channel_id"`
+	BotToken  string            `yaml:"bot_token"`
+	GuildID   string            `yaml:"guild_id"`
+	ChannelID string            `yaml:"channel_id"` // overrides telegraph.channel on Discord
+	Users     map[string]string `yaml:"users"`      // owner handle → Discord user ID
 }
 
 // DispatchLockConfig controls the dispatch lock heartbeat and queue.
@@ -618,9 +1152,10 @@ type EventsConfig struct {
 
 // DigestConfig controls periodic summary messages.
 type DigestConfig struct {
-	Pulse  DigestSchedule `yaml:"pulse"`
-	Daily  DigestSchedule `yaml:"daily"`
-	Weekly DigestSchedule `yaml:"weekly"`
+	Pulse        DigestSchedule `yaml:"pulse"`
+	Daily        DigestSchedule `yaml:"daily"`
+	Weekly       DigestSchedule `yaml:"weekly"`
+	GroupByOwner bool           `yaml:"group_by_owner"` // add a per-owner section to daily/weekly digests
 }
 
 // DigestSchedule configures a single digest schedule.
@@ -629,14 +1164,52 @@ type DigestSchedule struct {
 	Cron    string `yaml:"cron"`
 }
 
+// InboundConfig limits what chat users can send Telegraph, protecting
+// dispatch sessions from giant pastes and copy-paste loops. Only messages
+// addressed to the bot count: commands, mentions, and session thread
+// replies.
+type InboundConfig struct {
+	MaxMessageChars int    `yaml:"max_message_chars"` // default 8000
+	Oversize        string `yaml:"oversize"`          // "trim" (default) or "reject" longer messages
+	MaxPerMinute    int    `yaml:"max_per_minute"`    // messages per user per minute; default 10
+}
+
 // ConversationsConfig controls dispatch conversation behavior.
 type ConversationsConfig struct {
-	MaxTurns             int `yaml:"max_turns"`              // default 20
-	RecoveryLookbackDays int `yaml:"recovery_lookback_days"` // default 7
+	MaxTurns             int                       `yaml:"max_turns"`              // default 20
+	RecoveryLookbackDays int                       `yaml:"recovery_lookback_days"` // default 7
+	SummarizeAfterTurns  int                       `yaml:"summarize_after_turns"`  // on resume, older turns beyond the latest N are summarized (default 40; -1 disables)
+	Summarizer           string                    `yaml:"summarizer"`             // "truncate" (default) or "agent" (one-shot read-only agent call)
+	Archive              ConversationArchiveConfig `yaml:"archive"`
+}
+
+// ConversationArchiveConfig moves the turns of old dispatch sessions out of
+// the database. Archived turns are still read back on resume and export.
+type ConversationArchiveConfig struct {
+	KeepDays int    `yaml:"keep_days"` // finished sessions keep their turns in the DB this long; 0 = never archive
+	Store    string `yaml:"store"`     // "file" (default) or "http"
+	Dir      string `yaml:"dir"`       // file store directory; default .railyard/history
+	URL      string `yaml:"url"`       // http store base URL; objects are PUT/GET/DELETE at <url>/<key>
+	Token    string `yaml:"token"`     // sent as "Authorization: Bearer <token>" by the http store; supports ${ENV_VAR}
+}
+
+// HTTPAgentConfig configures a hosted agent API for dispatch. Each turn is a
+// POST of the prompt to Endpoint; the response is streamed back as
+// server-sent events (or plain text lines).
+type HTTPAgentConfig struct {
+	Endpoint string `yaml:"endpoint"` // e.g. https://agents.example.com/v1/dispatch
+	Token    string `yaml:"token"`    // sent as "Authorization: Bearer <token>"; supports ${ENV_VAR}
 }
 
 // Load reads a YAML config file from path and returns a validated Config.
 func Load(path string) (*Config, error) {
+	return LoadWithOverrides(path, nil)
+}
+
+// LoadWithOverrides is Load with overrides applied over the file before it
+// is validated, so they take precedence over railyard.yaml without editing
+// it. Each override is "path=value" (see ApplyOverrides).
+func LoadWithOverrides(path string, overrides []string) (*Config, error) {
 	// Warn if the config file is world-readable (may contain credentials).
 	// Skip in Kubernetes — ConfigMap volumes are always mounted 0644.
 	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
@@ -649,11 +1222,32 @@ func Load(path string) (*Config, error) {
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("config: read %s: %w", path, err)
+		return nil, &LoadError{Err: fmt.Errorf("config: read %s: %w", path, err)}
 	}
-	return Parse(data)
+	if len(overrides) == 0 {
+		return Parse(data)
+	}
+	if data, err = ApplyOverrides(data, overrides); err != nil {
+		return nil, &LoadError{Err: err}
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Overrides = append([]string(nil), overrides...)
+	return cfg, nil
+}
+
+// LoadError wraps every error returned by Load and Parse, so callers can
+// tell a missing or invalid config apart from other failures with
+// errors.As. Its message is the wrapped error's.
+type LoadError struct {
+	Err error
 }
 
+func (e *LoadError) Error() string { return e.Err.Error() }
+func (e *LoadError) Unwrap() error { return e.Err }
+
 // Parse unmarshals YAML bytes into a validated Config.
 //
 // Top-level keys that are not part of the typed Config schema (i.e. keys
@@ -661,7 +1255,15 @@ func Load(path string) (*Config, error) {
 // by the plugin host. Unknown keys are logged at DEBUG and do not fail the
 // load.
 func Parse(data []byte) (*Config, error) {
-	// Detect deprecated 'dolt:' key from pre-rename configs.
+	cfg, err := parse(data)
+	if err != nil {
+		return nil, &LoadError{Err: err}
+	}
+	return cfg, nil
+}
+
+func parse(data []byte) (*Config, error) {
+	// Refuse older layouts (e.g. 'dolt:' from pre-rename configs).
 	if err := checkDeprecatedKeys(data); err != nil {
 		return nil, err
 	}
@@ -750,17 +1352,19 @@ func knownTopLevelKeys() map[string]bool {
 	return out
 }
 
-// checkDeprecatedKeys inspects raw YAML for renamed top-level keys and
-// returns a helpful error if any are found.
+// checkDeprecatedKeys inspects raw YAML for keys renamed or moved by a
+// Migration and returns an error pointing at ry upgrade-config if any are
+// found.
 func checkDeprecatedKeys(data []byte) error {
-	var raw map[string]interface{}
-	if err := yaml.Unmarshal(data, &raw); err != nil {
-		return nil // let the main unmarshal report the parse error
+	pending, err := PendingMigrations(data)
+	if err != nil || len(pending) == 0 {
+		return err
 	}
-	if _, ok := raw["dolt"]; ok {
-		return fmt.Errorf("config: the 'dolt' key has been renamed to 'database' — update your config file:\n\n  # Before\n  dolt:\n    host: ...\n\n  # After\n  database:\n    host: ...")
+	reasons := make([]string, len(pending))
+	for i, m := range pending {
+		reasons[i] = m.Description
 	}
-	return nil
+	return fmt.Errorf("config: %s — run `ry upgrade-config` to update your config file (the original is backed up)", strings.Join(reasons, "; "))
 }
 
 // applyDefaults fills in derived and default values.
@@ -789,6 +1393,20 @@ func (c *Config) applyDefaults() {
 	c.Database.TLS.CACert = resolveEnvVars(c.Database.TLS.CACert)
 	c.Database.TLS.ClientCert = resolveEnvVars(c.Database.TLS.ClientCert)
 	c.Database.TLS.ClientKey = resolveEnvVars(c.Database.TLS.ClientKey)
+	if c.Database.QueryLog.SlowMs == 0 {
+		c.Database.QueryLog.SlowMs = 200
+	}
+	if c.Database.QueryLog.Path == "" {
+		c.Database.QueryLog.Path = DefaultQueryLogPath
+	}
+	for i := range c.Tracks {
+		if r := c.Tracks[i].TestRunner; r != nil {
+			r.Token = resolveEnvVars(r.Token)
+			if r.Type == TestRunnerCI && r.PollIntervalSec <= 0 {
+				r.PollIntervalSec = DefaultTestRunnerPollSec
+			}
+		}
+	}
 	// MCP server env blocks typically carry tokens — resolve ${VAR} there
 	// like the other credential fields above.
 	for _, srv := range c.MCPServers {
@@ -811,6 +1429,21 @@ func (c *Config) applyDefaults() {
 	if c.Stall.SwitchTimeoutSec == 0 {
 		c.Stall.SwitchTimeoutSec = 600
 	}
+	if c.Stall.SwitchNoticeSec == 0 {
+		c.Stall.SwitchNoticeSec = 300
+	}
+	if c.Stall.AbortRequeueSec == 0 {
+		c.Stall.AbortRequeueSec = 900
+	}
+	if c.Stall.SwitchRetryMax == 0 {
+		c.Stall.SwitchRetryMax = 4
+	}
+	if c.Stall.SwitchRetryBaseSec == 0 {
+		c.Stall.SwitchRetryBaseSec = 60
+	}
+	if c.Stall.SwitchRetryMaxSec == 0 {
+		c.Stall.SwitchRetryMaxSec = 1800
+	}
 	if c.Stall.EscalationCooldownSec == 0 {
 		c.Stall.EscalationCooldownSec = 600
 	}
@@ -823,6 +1456,33 @@ func (c *Config) applyDefaults() {
 	if c.Stall.RateLimitMaxWaitSec == 0 {
 		c.Stall.RateLimitMaxWaitSec = 300
 	}
+	if c.Stall.StaleClaimSec == 0 {
+		c.Stall.StaleClaimSec = 900
+	}
+	if c.Autoscale.ReadyThreshold == 0 {
+		c.Autoscale.ReadyThreshold = 3
+	}
+	if c.Autoscale.ScaleUpAfterMin == 0 {
+		c.Autoscale.ScaleUpAfterMin = 5
+	}
+	if c.Autoscale.IdleAfterMin == 0 {
+		c.Autoscale.IdleAfterMin = 15
+	}
+	if c.Autoscale.MinEngines == 0 {
+		c.Autoscale.MinEngines = 1
+	}
+	if c.Retention.EventsDays == 0 {
+		c.Retention.EventsDays = 90
+	}
+	if c.Retention.ConversationsDays == 0 {
+		c.Retention.ConversationsDays = 30
+	}
+	if c.Retention.AgentLogsDays == 0 {
+		c.Retention.AgentLogsDays = 7
+	}
+	if c.Retention.MergeAttemptsDays == 0 {
+		c.Retention.MergeAttemptsDays = 180
+	}
 	if c.Yardmaster.HealthPort == 0 {
 		c.Yardmaster.HealthPort = 8081
 	}
@@ -832,6 +1492,9 @@ func (c *Config) applyDefaults() {
 	if c.Yardmaster.RevisedLabel == "" {
 		c.Yardmaster.RevisedLabel = "railyard: revised"
 	}
+	if c.Yardmaster.FailurePatternsFile == "" {
+		c.Yardmaster.FailurePatternsFile = ".railyard/failure-patterns.yaml"
+	}
 	if c.AgentProvider == "" {
 		c.AgentProvider = "claude"
 	}
@@ -861,6 +1524,22 @@ func (c *Config) applyDefaults() {
 		if c.Tracks[i].AgentModel == "" {
 			c.Tracks[i].AgentModel = c.AgentModel
 		}
+		if c.Tracks[i].ClaimStrategy == "" {
+			c.Tracks[i].ClaimStrategy = ClaimStrategyForPolicy(c.Dispatch.ClaimPolicy)
+			if c.Tracks[i].ClaimStrategy == "" {
+				// Invalid policy: validate reports it once, not per track.
+				c.Tracks[i].ClaimStrategy = ClaimStrategyPriority
+			}
+		}
+		if c.Tracks[i].ClaimAgingHours == 0 {
+			c.Tracks[i].ClaimAgingHours = DefaultClaimAgingHours
+		}
+		if c.Tracks[i].SwitchConcurrency == 0 {
+			c.Tracks[i].SwitchConcurrency = 1
+		}
+		if cn := c.Tracks[i].Canary; cn != nil && cn.Percent == 0 {
+			cn.Percent = DefaultCanaryPercent
+		}
 		// Playwright defaults — only apply when the block is present and enabled.
 		if pw := c.Tracks[i].Playwright; pw != nil && pw.Enabled {
 			if pw.Filename == "" {
@@ -887,6 +1566,22 @@ func (c *Config) applyDefaults() {
 		}
 	}
 	c.CocoIndex.DatabaseURL = resolveEnvVars(c.CocoIndex.DatabaseURL)
+	c.Dashboard.TLS.Cert = resolveEnvVars(c.Dashboard.TLS.Cert)
+	c.Dashboard.TLS.Key = resolveEnvVars(c.Dashboard.TLS.Key)
+	c.Dashboard.TLS.ClientCA = resolveEnvVars(c.Dashboard.TLS.ClientCA)
+	if len(c.Dashboard.TLS.ACME.Domains) > 0 && c.Dashboard.TLS.ACME.CacheDir == "" {
+		c.Dashboard.TLS.ACME.CacheDir = ".railyard/acme"
+	}
+	for i := range c.Dashboard.Tokens {
+		tok := &c.Dashboard.Tokens[i]
+		tok.Token = resolveEnvVars(tok.Token)
+		if tok.Scope == "" {
+			tok.Scope = TokenScopeRead
+		}
+	}
+	c.Network.HTTPProxy = resolveEnvVars(c.Network.HTTPProxy)
+	c.Network.HTTPSProxy = resolveEnvVars(c.Network.HTTPSProxy)
+	c.Network.CABundle = resolveEnvVars(c.Network.CABundle)
 	if c.CocoIndex.VenvPath == "" {
 		c.CocoIndex.VenvPath = "cocoindex/.venv"
 	}
@@ -960,6 +1655,10 @@ func (c *Config) applyDefaults() {
 			c.Inspect.Labels.ReReview = "inspect: re-review"
 		}
 	}
+	// The primary platform is the first listed one.
+	if c.Telegraph.Platform == "" && len(c.Telegraph.Platforms) > 0 {
+		c.Telegraph.Platform = c.Telegraph.Platforms[0]
+	}
 	// Telegraph defaults — only apply when telegraph section is present (platform set).
 	if c.Telegraph.Platform != "" {
 		if c.Telegraph.DispatchLock.HeartbeatIntervalSec == 0 {
@@ -989,9 +1688,33 @@ func (c *Config) applyDefaults() {
 		if c.Telegraph.Conversations.RecoveryLookbackDays == 0 {
 			c.Telegraph.Conversations.RecoveryLookbackDays = 7
 		}
+		if c.Telegraph.Conversations.SummarizeAfterTurns == 0 {
+			c.Telegraph.Conversations.SummarizeAfterTurns = 40
+		}
+		if c.Telegraph.Conversations.Summarizer == "" {
+			c.Telegraph.Conversations.Summarizer = "truncate"
+		}
+		if c.Telegraph.Conversations.Archive.Dir == "" {
+			c.Telegraph.Conversations.Archive.Dir = ".railyard/history"
+		}
 		if c.Telegraph.ProcessTimeoutSec == 0 {
 			c.Telegraph.ProcessTimeoutSec = 900
 		}
+		if c.Telegraph.ShutdownTimeoutSec == 0 {
+			c.Telegraph.ShutdownTimeoutSec = 60
+		}
+		if c.Telegraph.StatusCacheTTLSec == 0 {
+			c.Telegraph.StatusCacheTTLSec = 5
+		}
+		if c.Telegraph.Inbound.MaxMessageChars == 0 {
+			c.Telegraph.Inbound.MaxMessageChars = 8000
+		}
+		if c.Telegraph.Inbound.Oversize == "" {
+			c.Telegraph.Inbound.Oversize = "trim"
+		}
+		if c.Telegraph.Inbound.MaxPerMinute == 0 {
+			c.Telegraph.Inbound.MaxPerMinute = 10
+		}
 		if c.Telegraph.HealthPort == 0 {
 			c.Telegraph.HealthPort = 8086
 		}
@@ -999,6 +1722,8 @@ func (c *Config) applyDefaults() {
 		c.Telegraph.Slack.BotToken = resolveEnvVars(c.Telegraph.Slack.BotToken)
 		c.Telegraph.Slack.AppToken = resolveEnvVars(c.Telegraph.Slack.AppToken)
 		c.Telegraph.Discord.BotToken = resolveEnvVars(c.Telegraph.Discord.BotToken)
+		c.Telegraph.HTTPAgent.Token = resolveEnvVars(c.Telegraph.HTTPAgent.Token)
+		c.Telegraph.Conversations.Archive.Token = resolveEnvVars(c.Telegraph.Conversations.Archive.Token)
 	}
 	// Plugin health-poll interval default (railyard-77h.12). Applied
 	// unconditionally so the host always sees a positive value; a
@@ -1020,6 +1745,37 @@ func (c *Config) validate() error {
 	if len(c.Tracks) == 0 {
 		errs = append(errs, "at least one track is required")
 	}
+	if c.Database.QueryLog.SlowMs < 0 {
+		errs = append(errs, "database.query_log.slow_ms must not be negative")
+	}
+	if ClaimStrategyForPolicy(c.Dispatch.ClaimPolicy) == "" {
+		errs = append(errs, fmt.Sprintf("invalid dispatch.claim_policy %q (valid: priority, fifo, weighted, or any claim_strategy)", c.Dispatch.ClaimPolicy))
+	}
+	for i, w := range c.Webhooks {
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("webhooks[%d].url must be an http(s) URL", i))
+		}
+		for _, ev := range w.Events {
+			if !slices.Contains(ValidWebhookEvents, ev) {
+				errs = append(errs, fmt.Sprintf("webhooks[%d]: unknown event %q (valid: %s)", i, ev, strings.Join(ValidWebhookEvents, ", ")))
+			}
+		}
+	}
+	if a := c.Autoscale; a.ReadyThreshold < 0 || a.ScaleUpAfterMin < 0 || a.IdleAfterMin < 0 || a.MinEngines < 0 {
+		errs = append(errs, "autoscale thresholds must not be negative")
+	}
+	if r := c.Retention; r.EventsDays < 0 || r.ConversationsDays < 0 || r.AgentLogsDays < 0 || r.MergeAttemptsDays < 0 {
+		errs = append(errs, "retention windows must not be negative")
+	}
+	carIDLength := c.CarIDs.Length
+	if carIDLength == 0 {
+		carIDLength = DefaultCarIDLength
+	} else if carIDLength < MinCarIDLength || carIDLength > MaxCarIDLength {
+		errs = append(errs, fmt.Sprintf("car_ids.length must be between %d and %d", MinCarIDLength, MaxCarIDLength))
+	}
+	if c.CarIDs.Prefix != "" {
+		errs = append(errs, validateCarIDPrefix("car_ids.prefix", c.CarIDs.Prefix, carIDLength, c.CarIDs.Slug)...)
+	}
 	for i, t := range c.Tracks {
 		if t.Name == "" {
 			errs = append(errs, fmt.Sprintf("tracks[%d].name is required", i))
@@ -1027,6 +1783,138 @@ func (c *Config) validate() error {
 		if t.Language == "" {
 			errs = append(errs, fmt.Sprintf("tracks[%d].language is required", i))
 		}
+		if t.Repo != "" && (strings.ContainsAny(t.Name, `/\`) || t.Name == "." || t.Name == "..") {
+			errs = append(errs, fmt.Sprintf("track %q: a track with its own repo needs a name usable as a directory (no / or \\)", t.Name))
+		}
+		if t.Type != "" && !slices.Contains(ValidTrackTypes, t.Type) {
+			errs = append(errs, fmt.Sprintf("track %q: invalid type %q (valid: %s)", t.Name, t.Type, strings.Join(ValidTrackTypes, ", ")))
+		}
+		if t.ClaimStrategy != "" && !slices.Contains(ValidClaimStrategies, t.ClaimStrategy) {
+			errs = append(errs, fmt.Sprintf("track %q: invalid claim_strategy %q (valid: %s)", t.Name, t.ClaimStrategy, strings.Join(ValidClaimStrategies, ", ")))
+		}
+		if t.ClaimAgingHours < 0 {
+			errs = append(errs, fmt.Sprintf("track %q: claim_aging_hours must not be negative", t.Name))
+		}
+		if t.SwitchConcurrency < 0 {
+			errs = append(errs, fmt.Sprintf("track %q: switch_concurrency must not be negative", t.Name))
+		}
+		if t.TestTimeoutSec < 0 {
+			errs = append(errs, fmt.Sprintf("track %q: test_timeout_sec must not be negative", t.Name))
+		}
+		cellNames := make(map[string]bool, len(t.TestMatrix))
+		for j, cell := range t.TestMatrix {
+			if cell.Name == "" {
+				errs = append(errs, fmt.Sprintf("track %q: test_matrix[%d].name is required", t.Name, j))
+			} else if cellNames[cell.Name] {
+				errs = append(errs, fmt.Sprintf("track %q: duplicate test_matrix cell %q", t.Name, cell.Name))
+			}
+			cellNames[cell.Name] = true
+			if cell.Command == "" {
+				errs = append(errs, fmt.Sprintf("track %q: test_matrix[%d].command is required", t.Name, j))
+			}
+		}
+		if r := t.TestRunner; r != nil {
+			switch r.Type {
+			case "", TestRunnerLocal:
+			case TestRunnerSSH:
+				if r.Host == "" || r.WorkDir == "" {
+					errs = append(errs, fmt.Sprintf("track %q: ssh test_runner requires host and work_dir", t.Name))
+				}
+			case TestRunnerCI:
+				if r.TriggerURL == "" {
+					errs = append(errs, fmt.Sprintf("track %q: ci test_runner requires trigger_url", t.Name))
+				}
+			default:
+				errs = append(errs, fmt.Sprintf("track %q: invalid test_runner.type %q (valid: local, ssh, ci)", t.Name, r.Type))
+			}
+		}
+		if c := t.Coverage; c != nil {
+			if c.Regex == "" && c.Profile == "" {
+				errs = append(errs, fmt.Sprintf("track %q: coverage requires regex or profile", t.Name))
+			}
+			if c.Regex != "" {
+				if re, err := regexp.Compile(c.Regex); err != nil {
+					errs = append(errs, fmt.Sprintf("track %q: invalid coverage.regex: %v", t.Name, err))
+				} else if re.NumSubexp() < 1 {
+					errs = append(errs, fmt.Sprintf("track %q: coverage.regex needs a capture group for the percentage", t.Name))
+				}
+			}
+			if c.MaxDrop < 0 {
+				errs = append(errs, fmt.Sprintf("track %q: coverage.max_drop must not be negative", t.Name))
+			}
+		}
+		if dl := t.DiffLimit; dl != nil {
+			if dl.MaxFiles < 0 || dl.MaxLines < 0 {
+				errs = append(errs, fmt.Sprintf("track %q: diff_limit.max_files and diff_limit.max_lines must not be negative", t.Name))
+			} else if dl.MaxFiles == 0 && dl.MaxLines == 0 {
+				errs = append(errs, fmt.Sprintf("track %q: diff_limit needs max_files or max_lines", t.Name))
+			}
+			for _, g := range dl.Exclude {
+				if _, err := path.Match(g, ""); err != nil || g == "" {
+					errs = append(errs, fmt.Sprintf("track %q: invalid diff_limit.exclude pattern %q", t.Name, g))
+				}
+			}
+		}
+		if wr := t.WorktreeReset; wr != nil {
+			if wr.CheckoutWorkers < 0 {
+				errs = append(errs, fmt.Sprintf("track %q: worktree_reset.checkout_workers must not be negative", t.Name))
+			}
+			for _, p := range wr.PreservePaths {
+				if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "..") {
+					errs = append(errs, fmt.Sprintf("track %q: worktree_reset.preserve_paths entry %q must be a relative path inside the repo", t.Name, p))
+				}
+			}
+		}
+		if sb := t.Sandbox; sb != nil {
+			if sb.Tool != SandboxBubblewrap && sb.Tool != SandboxFirejail {
+				errs = append(errs, fmt.Sprintf("track %q: invalid sandbox.tool %q (valid: %s, %s)", t.Name, sb.Tool, SandboxBubblewrap, SandboxFirejail))
+			}
+			for _, h := range sb.AllowHosts {
+				if h == "" || strings.ContainsAny(h, "/: ") {
+					errs = append(errs, fmt.Sprintf("track %q: invalid sandbox.allow_hosts entry %q (want a host name or *.domain)", t.Name, h))
+				}
+			}
+			for _, w := range sb.Writable {
+				if !filepath.IsAbs(w) && !strings.HasPrefix(w, "~/") {
+					errs = append(errs, fmt.Sprintf("track %q: sandbox.writable path %q must be absolute or start with ~/", t.Name, w))
+				}
+			}
+		}
+		if rc := t.Resources; rc != nil {
+			if rc.Nice < 0 || rc.Nice > 19 {
+				errs = append(errs, fmt.Sprintf("track %q: resources.nice must be between 0 and 19, got %d", t.Name, rc.Nice))
+			}
+			if rc.CPUs < 0 {
+				errs = append(errs, fmt.Sprintf("track %q: resources.cpus must not be negative", t.Name))
+			}
+			if rc.MemoryMax != "" && !validMemoryMax.MatchString(rc.MemoryMax) {
+				errs = append(errs, fmt.Sprintf("track %q: invalid resources.memory_max %q (want bytes with an optional K, M, G or T suffix, e.g. 4G)", t.Name, rc.MemoryMax))
+			}
+			if rc.CPUQuota != "" && !validCPUQuota.MatchString(rc.CPUQuota) {
+				errs = append(errs, fmt.Sprintf("track %q: invalid resources.cpu_quota %q (want a percentage of one core, e.g. 200%%)", t.Name, rc.CPUQuota))
+			}
+		}
+		if cp := t.CommandPolicy; cp != nil {
+			if len(cp.Allow) == 0 && len(cp.Deny) == 0 {
+				errs = append(errs, fmt.Sprintf("track %q: command_policy needs at least one allow or deny rule", t.Name))
+			}
+			for _, r := range append(append([]string(nil), cp.Allow...), cp.Deny...) {
+				if strings.TrimSpace(r) == "" {
+					errs = append(errs, fmt.Sprintf("track %q: command_policy has an empty rule", t.Name))
+				}
+			}
+		}
+		if cn := t.Canary; cn != nil {
+			if cn.Percent < 0 || cn.Percent > 100 {
+				errs = append(errs, fmt.Sprintf("track %q: canary.percent must be between 1 and 100", t.Name))
+			}
+			if cn.TestCommand == "" && cn.PreTestCommand == "" && cn.AgentModel == "" && cn.Conventions == nil {
+				errs = append(errs, fmt.Sprintf("track %q: canary needs at least one of test_command, pre_test_command, agent_model, or conventions", t.Name))
+			}
+		}
+		if t.IDPrefix != "" {
+			errs = append(errs, validateCarIDPrefix(fmt.Sprintf("track %q: id_prefix", t.Name), t.IDPrefix, carIDLength, c.CarIDs.Slug)...)
+		}
 		// Playwright validation — only when the block is present and enabled.
 		// Template is preserved as-written and not validated for existence here
 		// (the file may not yet exist at config-load time).
@@ -1051,6 +1939,23 @@ func (c *Config) validate() error {
 			errs = append(errs, fmt.Sprintf("mcp_servers[%q]: command is required", name))
 		}
 	}
+	templateNames := make([]string, 0, len(c.Templates))
+	for name := range c.Templates {
+		templateNames = append(templateNames, name)
+	}
+	sort.Strings(templateNames)
+	for _, name := range templateNames {
+		errs = append(errs, c.validateCarTemplate(name, c.Templates[name])...)
+	}
+	if c.Multiplexer != "" && !slices.Contains(ValidMultiplexers, c.Multiplexer) {
+		errs = append(errs, fmt.Sprintf("invalid multiplexer %q (valid: %s)", c.Multiplexer, strings.Join(ValidMultiplexers, ", ")))
+	}
+	if strings.ContainsAny(c.Tmux.Socket, "/ \t") {
+		errs = append(errs, fmt.Sprintf("tmux.socket %q must be a socket name (tmux -L), not a path", c.Tmux.Socket))
+	}
+	if c.Tmux.BaseIndex != nil && *c.Tmux.BaseIndex < 0 {
+		errs = append(errs, "tmux.base_index must not be negative")
+	}
 	// Kubernetes validation (only when namespace or image is set).
 	if c.Kubernetes.Namespace != "" || c.Kubernetes.Image != "" {
 		if c.Kubernetes.Image == "" {
@@ -1136,23 +2041,109 @@ func (c *Config) validate() error {
 	}
 	// Telegraph validation (only when platform is configured).
 	if c.Telegraph.Platform != "" {
-		switch c.Telegraph.Platform {
-		case "slack":
-			if c.Telegraph.Slack.BotToken == "" {
-				errs = append(errs, "telegraph.slack.bot_token is required when platform is slack")
+		if len(c.Telegraph.Platforms) > 0 && !slices.Contains(c.Telegraph.Platforms, c.Telegraph.Platform) {
+			errs = append(errs, fmt.Sprintf("telegraph.platform %q must be one of telegraph.platforms", c.Telegraph.Platform))
+		}
+		seen := make(map[string]bool)
+		for _, p := range c.Telegraph.EnabledPlatforms() {
+			if seen[p] {
+				errs = append(errs, fmt.Sprintf("telegraph.platforms lists %q more than once", p))
+				continue
+			}
+			seen[p] = true
+			switch p {
+			case "slack":
+				if c.Telegraph.Slack.BotToken == "" {
+					errs = append(errs, "telegraph.slack.bot_token is required when platform is slack")
+				}
+				if c.Telegraph.Slack.AppToken == "" {
+					errs = append(errs, "telegraph.slack.app_token is required when platform is slack")
+				}
+			case "discord":
+				if c.Telegraph.Discord.BotToken == "" {
+					errs = append(errs, "telegraph.discord.bot_token is required when platform is discord")
+				}
+			default:
+				errs = append(errs, fmt.Sprintf("telegraph.platform %q is not supported (use slack or discord)", p))
+				continue
 			}
-			if c.Telegraph.Slack.AppToken == "" {
-				errs = append(errs, "telegraph.slack.app_token is required when platform is slack")
+			switch {
+			case c.Telegraph.PlatformChannel(p) != "":
+			case len(c.Telegraph.EnabledPlatforms()) > 1:
+				errs = append(errs, fmt.Sprintf("telegraph.channel or telegraph.%s.channel_id is required", p))
+			default:
+				errs = append(errs, "telegraph.channel is required")
 			}
-		case "discord":
-			if c.Telegraph.Discord.BotToken == "" {
-				errs = append(errs, "telegraph.discord.bot_token is required when platform is discord")
+		}
+		if ep := c.Telegraph.HTTPAgent.Endpoint; ep != "" && !strings.HasPrefix(ep, "http://") && !strings.HasPrefix(ep, "https://") {
+			errs = append(errs, fmt.Sprintf("telegraph.http_agent.endpoint %q must be an http(s) URL", ep))
+		}
+		in := c.Telegraph.Inbound
+		if in.MaxMessageChars < 0 {
+			errs = append(errs, "telegraph.inbound.max_message_chars must not be negative")
+		}
+		if in.MaxPerMinute < 0 {
+			errs = append(errs, "telegraph.inbound.max_per_minute must not be negative")
+		}
+		if in.Oversize != "trim" && in.Oversize != "reject" {
+			errs = append(errs, fmt.Sprintf("telegraph.inbound.oversize %q is not supported (use trim or reject)", in.Oversize))
+		}
+		conv := c.Telegraph.Conversations
+		if conv.SummarizeAfterTurns < -1 {
+			errs = append(errs, "telegraph.conversations.summarize_after_turns must be positive, or -1 to disable")
+		}
+		if conv.Summarizer != "truncate" && conv.Summarizer != "agent" {
+			errs = append(errs, fmt.Sprintf("telegraph.conversations.summarizer %q is not supported (use truncate or agent)", conv.Summarizer))
+		}
+		arc := conv.Archive
+		if arc.KeepDays < 0 {
+			errs = append(errs, "telegraph.conversations.archive.keep_days must not be negative")
+		}
+		switch arc.Store {
+		case "", "file":
+		case "http":
+			if !strings.HasPrefix(arc.URL, "http://") && !strings.HasPrefix(arc.URL, "https://") {
+				errs = append(errs, fmt.Sprintf("telegraph.conversations.archive.url %q must be an http(s) URL when store is http", arc.URL))
 			}
 		default:
-			errs = append(errs, fmt.Sprintf("telegraph.platform %q is not supported (use slack or discord)", c.Telegraph.Platform))
+			errs = append(errs, fmt.Sprintf("telegraph.conversations.archive.store %q is not supported (use file or http)", arc.Store))
+		}
+	}
+	dtls := c.Dashboard.TLS
+	if (dtls.Cert == "") != (dtls.Key == "") {
+		errs = append(errs, "dashboard.tls: cert and key must be set together")
+	}
+	if dtls.Cert != "" && len(dtls.ACME.Domains) > 0 {
+		errs = append(errs, "dashboard.tls: set either cert/key or acme.domains, not both")
+	}
+	if dtls.ClientCA != "" && !dtls.Enabled() {
+		errs = append(errs, "dashboard.tls.client_ca requires cert/key or acme.domains")
+	}
+	seenTokens := make(map[string]bool)
+	for i, tok := range c.Dashboard.Tokens {
+		label := tok.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		if tok.Token == "" {
+			errs = append(errs, fmt.Sprintf("dashboard.tokens %s: token is required", label))
+		} else if seenTokens[tok.Token] {
+			errs = append(errs, fmt.Sprintf("dashboard.tokens %s: token duplicates another entry", label))
+		}
+		seenTokens[tok.Token] = true
+		if tok.Scope != TokenScopeRead && tok.Scope != TokenScopeAdmin {
+			errs = append(errs, fmt.Sprintf("dashboard.tokens %s: scope %q is not supported (use read or admin)", label, tok.Scope))
+		}
+	}
+	for _, p := range []struct{ field, raw string }{
+		{"network.http_proxy", c.Network.HTTPProxy},
+		{"network.https_proxy", c.Network.HTTPSProxy},
+	} {
+		if p.raw == "" {
+			continue
 		}
-		if c.Telegraph.Channel == "" {
-			errs = append(errs, "telegraph.channel is required")
+		if u, err := url.Parse(p.raw); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			errs = append(errs, fmt.Sprintf("%s %q must be an http(s):// or socks5:// URL", p.field, p.raw))
 		}
 	}
 	if len(errs) > 0 {
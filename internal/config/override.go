@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ApplyOverrides sets each "path=value" override in the YAML document data
+// and returns the edited document. The path is dotted YAML keys; a list
+// element is picked by its name field (tracks.backend.engine_slots) or by
+// index (tracks.0.engine_slots). The value is parsed as YAML, so 8, true,
+// and [a, b] keep their types. Missing keys are created; keys the config
+// does not declare are rejected so a typo cannot be silently ignored.
+func ApplyOverrides(data []byte, overrides []string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("config: parse: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	for _, o := range overrides {
+		path, value, err := ParseOverride(o)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkOverridePath(path); err != nil {
+			return nil, err
+		}
+		var v yaml.Node
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil || len(v.Content) == 0 {
+			v = yaml.Node{Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}}}
+			if value == "" {
+				v.Content[0].Tag = "!!null"
+			}
+		}
+		if err := setNode(doc.Content[0], strings.Split(path, "."), v.Content[0]); err != nil {
+			return nil, fmt.Errorf("config: --set %s: %w", path, err)
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// ParseOverride splits a "path=value" override.
+func ParseOverride(o string) (path, value string, err error) {
+	path, value, ok := strings.Cut(o, "=")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" || strings.Contains(path, "..") || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") {
+		return "", "", fmt.Errorf("config: invalid override %q (want path=value, e.g. tracks.backend.engine_slots=8)", o)
+	}
+	return path, value, nil
+}
+
+// OverrideValue returns the effective value at an override path in cfg,
+// after defaults, rendered as YAML on one line.
+func OverrideValue(cfg *Config, path string) (string, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("config: marshal: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("config: parse: %w", err)
+	}
+	node := doc.Content[0]
+	for _, seg := range strings.Split(path, ".") {
+		if node = childNode(node, seg); node == nil {
+			return "", fmt.Errorf("config: %s is not set", path)
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		node.Style = yaml.FlowStyle
+	}
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return "", fmt.Errorf("config: marshal %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// setNode sets the value at path below node, creating missing mapping keys.
+func setNode(node *yaml.Node, path []string, value *yaml.Node) error {
+	seg := path[0]
+	last := len(path) == 1
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != seg {
+				continue
+			}
+			if last {
+				node.Content[i+1] = value
+				return nil
+			}
+			return setNode(node.Content[i+1], path[1:], value)
+		}
+		child := value
+		if !last {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			if err := setNode(child, path[1:], value); err != nil {
+				return err
+			}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: seg}, child)
+		return nil
+	case yaml.SequenceNode:
+		i := elementIndex(node, seg)
+		if i < 0 {
+			return fmt.Errorf("no list element named or numbered %q", seg)
+		}
+		if last {
+			node.Content[i] = value
+			return nil
+		}
+		return setNode(node.Content[i], path[1:], value)
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			*node = yaml.Node{Kind: yaml.MappingNode}
+			return setNode(node, path, value)
+		}
+	}
+	return fmt.Errorf("%q is not a mapping or list", seg)
+}
+
+// childNode returns the value under seg in a mapping or list node, or nil.
+func childNode(node *yaml.Node, seg string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == seg {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i := elementIndex(node, seg); i >= 0 {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// elementIndex finds the list element whose name field is seg, falling
+// back to seg as an index.
+func elementIndex(seq *yaml.Node, seg string) int {
+	for i, el := range seq.Content {
+		if name := childNode(el, "name"); name != nil && name.Value == seg {
+			return i
+		}
+	}
+	if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(seq.Content) {
+		return i
+	}
+	return -1
+}
+
+// checkOverridePath rejects paths through keys the Config struct does not
+// declare. Top-level keys it does not declare belong to plugins, and blocks
+// with their own YAML decoding or free-form values are not checked below.
+func checkOverridePath(path string) error {
+	unmarshaler := reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	t := reflect.TypeOf(Config{})
+	for i, seg := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if reflect.PointerTo(t).Implements(unmarshaler) {
+			return nil
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			f, ok := yamlField(t, seg)
+			if !ok {
+				if i == 0 {
+					return nil
+				}
+				return fmt.Errorf("config: --set %s: unknown key %q", path, seg)
+			}
+			t = f.Type
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// yamlField returns the field of struct type t whose yaml key is key.
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if opts == "inline" {
+			if sf, ok := yamlField(f.Type, key); ok {
+				return sf, true
+			}
+			continue
+		}
+		if name == "" && f.IsExported() {
+			name = strings.ToLower(f.Name)
+		}
+		if name == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const overrideTestYAML = `
+owner: alice
+repo: git@github.com:org/app.git
+tracks:
+  - name: backend
+    language: go
+    engine_slots: 3
+  - name: frontend
+    language: typescript
+`
+
+func TestApplyOverrides(t *testing.T) {
+	data, err := ApplyOverrides([]byte(overrideTestYAML), []string{
+		"tracks.backend.engine_slots=8",
+		"tracks.1.test_command=npm test",
+		"tracks.frontend.resources.nice=10",
+		"stall.stdout_timeout_sec=300",
+	})
+	if err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v\n%s", err, data)
+	}
+	if got := cfg.Tracks[0].EngineSlots; got != 8 {
+		t.Errorf("backend engine_slots = %d, want 8", got)
+	}
+	fe := cfg.Tracks[1]
+	if fe.TestCommand != "npm test" || fe.Resources == nil || fe.Resources.Nice != 10 {
+		t.Errorf("frontend = %+v", fe)
+	}
+	if cfg.Stall.StdoutTimeoutSec != 300 {
+		t.Errorf("stall.stdout_timeout_sec = %d, want 300", cfg.Stall.StdoutTimeoutSec)
+	}
+	if cfg.Owner != "alice" || cfg.Tracks[0].Language != "go" {
+		t.Errorf("untouched values changed: %+v", cfg)
+	}
+}
+
+func TestApplyOverrides_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		override string
+		want     string
+	}{
+		{"tracks.backend.engine_slots", "invalid override"},
+		{"=8", "invalid override"},
+		{"tracks..engine_slots=8", "invalid override"},
+		{"tracks.backend.engine_slot=8", `unknown key "engine_slot"`},
+		{"tracks.mobile.engine_slots=8", `no list element named or numbered "mobile"`},
+		{"owner.name=bob", `"name" is not a mapping or list`},
+	} {
+		_, err := ApplyOverrides([]byte(overrideTestYAML), []string{tc.override})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ApplyOverrides(%q) error = %v, want %q", tc.override, err, tc.want)
+		}
+	}
+}
+
+func TestLoadWithOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "railyard.yaml")
+	if err := os.WriteFile(path, []byte(overrideTestYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sets := []string{"tracks.backend.engine_slots=8", "tracks.frontend.claim_strategy="}
+	cfg, err := LoadWithOverrides(path, sets)
+	if err != nil {
+		t.Fatalf("LoadWithOverrides: %v", err)
+	}
+	if cfg.Tracks[0].EngineSlots != 8 || len(cfg.Overrides) != 2 {
+		t.Errorf("engine_slots = %d, overrides = %q", cfg.Tracks[0].EngineSlots, cfg.Overrides)
+	}
+	// The effective value is read back after defaults are applied.
+	if v, err := OverrideValue(cfg, "tracks.frontend.claim_strategy"); err != nil || v != "priority" {
+		t.Errorf("OverrideValue(claim_strategy) = %q, %v; want priority", v, err)
+	}
+	if v, err := OverrideValue(cfg, "tracks.backend.engine_slots"); err != nil || v != "8" {
+		t.Errorf("OverrideValue(engine_slots) = %q, %v; want 8", v, err)
+	}
+
+	// Overrides are validated like the file.
+	if _, err := LoadWithOverrides(path, []string{"tracks.backend.resources.nice=25"}); err == nil {
+		t.Error("expected validation error for resources.nice 25")
+	}
+	if cfg, err := Load(path); err != nil || cfg.Tracks[0].EngineSlots != 3 || cfg.Overrides != nil {
+		t.Errorf("Load without overrides: %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CarTemplatesDir is where per-repo car templates live, one <name>.yaml
+// file per template, relative to the repository root.
+const CarTemplatesDir = ".railyard/templates"
+
+// CarTemplate pre-fills `ry car create --template <name>`. Flags given on
+// the command line override the template's fields; Deps are added as
+// blockers of every car created from it.
+type CarTemplate struct {
+	Type        string   `yaml:"type"`
+	Track       string   `yaml:"track"`
+	Priority    *int     `yaml:"priority"` // nil keeps the create default
+	Description string   `yaml:"description"`
+	Acceptance  string   `yaml:"acceptance"`
+	DesignNotes string   `yaml:"design"`
+	Deps        []string `yaml:"deps"` // car IDs the new car is blocked by
+}
+
+// ErrTemplateNotFound is returned by CarTemplate for an unknown name.
+var ErrTemplateNotFound = errors.New("car template not found")
+
+// CarTemplate returns the named template from the templates section, or
+// else from <repoDir>/.railyard/templates/<name>.yaml. A name defined in
+// both places uses the config's.
+func (c *Config) CarTemplate(repoDir, name string) (CarTemplate, error) {
+	if t, ok := c.Templates[name]; ok {
+		return t, nil
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return CarTemplate{}, fmt.Errorf("config: invalid template name %q", name)
+	}
+	path := filepath.Join(repoDir, CarTemplatesDir, name+".yaml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		known := c.CarTemplateNames(repoDir)
+		if len(known) == 0 {
+			return CarTemplate{}, fmt.Errorf("config: %w: %q (no templates defined in railyard.yaml or %s)", ErrTemplateNotFound, name, CarTemplatesDir)
+		}
+		return CarTemplate{}, fmt.Errorf("config: %w: %q (available: %s)", ErrTemplateNotFound, name, strings.Join(known, ", "))
+	}
+	if err != nil {
+		return CarTemplate{}, fmt.Errorf("config: read template %s: %w", path, err)
+	}
+	var t CarTemplate
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return CarTemplate{}, fmt.Errorf("config: parse template %s: %w", path, err)
+	}
+	if errs := c.validateCarTemplate(name, t); len(errs) > 0 {
+		return CarTemplate{}, fmt.Errorf("config: template %s: %s", path, strings.Join(errs, "; "))
+	}
+	return t, nil
+}
+
+// CarTemplateNames lists the templates available in repoDir, sorted.
+func (c *Config) CarTemplateNames(repoDir string) []string {
+	seen := make(map[string]bool, len(c.Templates))
+	for name := range c.Templates {
+		seen[name] = true
+	}
+	files, _ := filepath.Glob(filepath.Join(repoDir, CarTemplatesDir, "*.yaml"))
+	for _, f := range files {
+		seen[strings.TrimSuffix(filepath.Base(f), ".yaml")] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateCarTemplate checks a template's priority and track against the
+// config. Types are left to car.Create.
+func (c *Config) validateCarTemplate(name string, t CarTemplate) []string {
+	var errs []string
+	if t.Priority != nil && (*t.Priority < 0 || *t.Priority > 4) {
+		errs = append(errs, fmt.Sprintf("templates[%q].priority must be between 0 and 4", name))
+	}
+	if t.Track != "" && !slices.ContainsFunc(c.Tracks, func(tr TrackConfig) bool { return tr.Name == t.Track }) {
+		errs = append(errs, fmt.Sprintf("templates[%q]: unknown track %q", name, t.Track))
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const templatesYAML = `
+owner: alice
+repo: git@github.com:org/app.git
+tracks:
+  - name: backend
+    language: go
+templates:
+  bugfix:
+    type: bug
+    track: backend
+    priority: 1
+    acceptance: A regression test fails before the fix and passes after.
+    deps: [car-infra]
+`
+
+func TestCarTemplate_FromConfigAndDir(t *testing.T) {
+	cfg, err := Parse([]byte(templatesYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repoDir := t.TempDir()
+	dir := filepath.Join(repoDir, CarTemplatesDir)
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, "chore.yaml"), []byte("type: task\npriority: 3\ndescription: Housekeeping.\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "bugfix.yaml"), []byte("type: spike\n"), 0o644)
+
+	bug, err := cfg.CarTemplate(repoDir, "bugfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bug.Type != "bug" || bug.Track != "backend" || bug.Priority == nil || *bug.Priority != 1 || len(bug.Deps) != 1 || bug.Deps[0] != "car-infra" {
+		t.Errorf("bugfix = %+v, want the railyard.yaml template", bug)
+	}
+
+	chore, err := cfg.CarTemplate(repoDir, "chore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chore.Type != "task" || *chore.Priority != 3 || chore.Description != "Housekeeping." {
+		t.Errorf("chore = %+v", chore)
+	}
+
+	if got := strings.Join(cfg.CarTemplateNames(repoDir), ","); got != "bugfix,chore" {
+		t.Errorf("names = %q", got)
+	}
+
+	_, err = cfg.CarTemplate(repoDir, "feature")
+	if !errors.Is(err, ErrTemplateNotFound) || !strings.Contains(err.Error(), "available: bugfix, chore") {
+		t.Errorf("err = %v, want not found listing the templates", err)
+	}
+	if _, err := cfg.CarTemplate(repoDir, "../secrets"); err == nil {
+		t.Error("path-like template name accepted")
+	}
+}
+
+func TestCarTemplate_Validation(t *testing.T) {
+	_, err := Parse([]byte(strings.Replace(templatesYAML, "track: backend\n    priority: 1", "track: bakend\n    priority: 7", 1)))
+	if err == nil || !strings.Contains(err.Error(), `templates["bugfix"].priority`) || !strings.Contains(err.Error(), `unknown track "bakend"`) {
+		t.Errorf("err = %v, want priority and track errors", err)
+	}
+
+	cfg, _ := Parse([]byte(templatesYAML))
+	repoDir := t.TempDir()
+	os.MkdirAll(filepath.Join(repoDir, CarTemplatesDir), 0o755)
+	os.WriteFile(filepath.Join(repoDir, CarTemplatesDir, "web.yaml"), []byte("track: frontend\n"), 0o644)
+	if _, err := cfg.CarTemplate(repoDir, "web"); err == nil || !strings.Contains(err.Error(), "frontend") {
+		t.Errorf("err = %v, want unknown track error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import "path/filepath"
+
+// TrackReposDir is where tracks with their own repo are cloned, relative to
+// the main repository. Each such track gets <TrackReposDir>/<track>, which
+// holds its engine, yardmaster, and switch worktrees the same way the main
+// repository does.
+const TrackReposDir = ".railyard/repos"
+
+func (c *Config) track(name string) *TrackConfig {
+	for i := range c.Tracks {
+		if c.Tracks[i].Name == name {
+			return &c.Tracks[i]
+		}
+	}
+	return nil
+}
+
+// RepoFor returns the repository a track's cars are worked in: the track's
+// own repo, or the top-level repo when the track does not set one.
+func (c *Config) RepoFor(track string) string {
+	if t := c.track(track); t != nil && t.Repo != "" {
+		return t.Repo
+	}
+	return c.Repo
+}
+
+// DefaultBranchFor returns the configured base branch for a track's cars:
+// the track's own default_branch, or the top-level one. It may be empty, in
+// which case callers detect the branch from the repository.
+func (c *Config) DefaultBranchFor(track string) string {
+	if t := c.track(track); t != nil && t.DefaultBranch != "" {
+		return t.DefaultBranch
+	}
+	return c.DefaultBranch
+}
+
+// HasOwnRepo reports whether a track's cars live in a repository other than
+// the top-level one.
+func (c *Config) HasOwnRepo(track string) bool {
+	t := c.track(track)
+	return t != nil && t.Repo != "" && t.Repo != c.Repo
+}
+
+// RepoDirFor returns the local checkout a track works in, given the main
+// repository's directory: the track's clone under TrackReposDir when it has
+// its own repo, otherwise repoDir itself. The clone may not exist yet; see
+// engine.EnsureTrackRepo.
+func (c *Config) RepoDirFor(repoDir, track string) string {
+	if !c.HasOwnRepo(track) {
+		return repoDir
+	}
+	return filepath.Join(repoDir, filepath.FromSlash(TrackReposDir), track)
+}
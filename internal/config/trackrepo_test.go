@@ -0,0 +1,69 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse_TrackRepo(t *testing.T) {
+	yaml := `
+owner: carol
+repo: git@github.com:org/app.git
+default_branch: main
+tracks:
+  - name: backend
+    language: go
+  - name: ml
+    language: python
+    repo: git@github.com:org/models.git
+    default_branch: develop
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.RepoFor("ml"); got != "git@github.com:org/models.git" {
+		t.Errorf("RepoFor(ml) = %q", got)
+	}
+	if got := cfg.RepoFor("backend"); got != "git@github.com:org/app.git" {
+		t.Errorf("RepoFor(backend) = %q, want the top-level repo", got)
+	}
+	if got := cfg.DefaultBranchFor("ml"); got != "develop" {
+		t.Errorf("DefaultBranchFor(ml) = %q, want develop", got)
+	}
+	if got := cfg.DefaultBranchFor("backend"); got != "main" {
+		t.Errorf("DefaultBranchFor(backend) = %q, want main", got)
+	}
+	if !cfg.HasOwnRepo("ml") || cfg.HasOwnRepo("backend") || cfg.HasOwnRepo("nope") {
+		t.Errorf("HasOwnRepo: ml=%v backend=%v nope=%v", cfg.HasOwnRepo("ml"), cfg.HasOwnRepo("backend"), cfg.HasOwnRepo("nope"))
+	}
+	if got, want := cfg.RepoDirFor("/src/app", "ml"), filepath.Join("/src/app", ".railyard", "repos", "ml"); got != want {
+		t.Errorf("RepoDirFor(ml) = %q, want %q", got, want)
+	}
+	if got := cfg.RepoDirFor("/src/app", "backend"); got != "/src/app" {
+		t.Errorf("RepoDirFor(backend) = %q, want the main repo", got)
+	}
+}
+
+func TestHasOwnRepo_SameAsTopLevel(t *testing.T) {
+	cfg := &Config{Repo: "org/app", Tracks: []TrackConfig{{Name: "api", Repo: "org/app"}}}
+	if cfg.HasOwnRepo("api") {
+		t.Error("a track repeating the top-level repo should share the main checkout")
+	}
+}
+
+func TestParse_TrackRepo_NameMustBeDirectory(t *testing.T) {
+	yaml := `
+owner: carol
+repo: git@github.com:org/app.git
+tracks:
+  - name: ml/models
+    language: python
+    repo: git@github.com:org/models.git
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil || !strings.Contains(err.Error(), "usable as a directory") {
+		t.Fatalf("err = %v, want a track name error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+// Track types (tracks[].type) adjust a track's merge-gate defaults to the
+// kind of work it produces.
+const (
+	TrackTypeCode   = "code"   // tests gate the merge (default)
+	TrackTypeDocs   = "docs"   // relative links in changed Markdown must resolve; merges without a PR
+	TrackTypeConfig = "config" // changed YAML and JSON files must parse
+)
+
+// ValidTrackTypes lists the accepted tracks[].type values.
+var ValidTrackTypes = []string{TrackTypeCode, TrackTypeDocs, TrackTypeConfig}
+
+// TrackTypeFor returns a track's type, TrackTypeCode when it is unset or
+// the track is unknown.
+func (c *Config) TrackTypeFor(track string) string {
+	if t := c.track(track); t != nil && t.Type != "" {
+		return t.Type
+	}
+	return TrackTypeCode
+}
+
+// RequirePRFor reports whether a track's cars merge through a pull
+// request: the track's own require_pr when set, otherwise the top-level
+// require_pr, except that docs tracks merge directly.
+func (c *Config) RequirePRFor(track string) bool {
+	if t := c.track(track); t != nil && t.RequirePR != nil {
+		return *t.RequirePR
+	}
+	if c.TrackTypeFor(track) == TrackTypeDocs {
+		return false
+	}
+	return c.RequirePR
+}
+
+// SwitchTimeoutFor returns the seconds a track's merge-gate tests may run:
+// the track's test_timeout_sec when set, otherwise stall.switch_timeout_sec.
+func (c *Config) SwitchTimeoutFor(track string) int {
+	if t := c.track(track); t != nil && t.TestTimeoutSec > 0 {
+		return t.TestTimeoutSec
+	}
+	return c.Stall.SwitchTimeoutSec
+}
+
+// UsesPRs reports whether any track's cars merge through a pull request,
+// i.e. whether the yardmaster needs to watch PRs at all.
+func (c *Config) UsesPRs() bool {
+	if c.RequirePR && len(c.Tracks) == 0 {
+		return true
+	}
+	for _, t := range c.Tracks {
+		if c.RequirePRFor(t.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_TrackType(t *testing.T) {
+	yaml := `
+owner: carol
+repo: git@github.com:org/app.git
+require_pr: true
+tracks:
+  - name: backend
+    language: go
+  - name: handbook
+    type: docs
+    language: markdown
+  - name: infra
+    type: config
+    language: yaml
+  - name: api-docs
+    type: docs
+    language: markdown
+    require_pr: true
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for track, want := range map[string]string{"backend": TrackTypeCode, "handbook": TrackTypeDocs, "infra": TrackTypeConfig, "missing": TrackTypeCode} {
+		if got := cfg.TrackTypeFor(track); got != want {
+			t.Errorf("TrackTypeFor(%s) = %q, want %q", track, got, want)
+		}
+	}
+	for track, want := range map[string]bool{"backend": true, "handbook": false, "infra": true, "api-docs": true} {
+		if got := cfg.RequirePRFor(track); got != want {
+			t.Errorf("RequirePRFor(%s) = %v, want %v", track, got, want)
+		}
+	}
+	if !cfg.UsesPRs() {
+		t.Error("UsesPRs = false, want true")
+	}
+
+	cfg.RequirePR = false
+	cfg.Tracks[3].RequirePR = nil
+	if cfg.UsesPRs() {
+		t.Error("UsesPRs = true with require_pr off everywhere")
+	}
+}
+
+func TestParse_TrackTypeInvalid(t *testing.T) {
+	yaml := `
+owner: carol
+repo: git@github.com:org/app.git
+tracks:
+  - name: handbook
+    type: prose
+    language: markdown
+`
+	_, err := Parse([]byte(yaml))
+	if err == nil || !strings.Contains(err.Error(), `invalid type "prose"`) {
+		t.Fatalf("err = %v, want invalid type", err)
+	}
+}
+
+func TestParse_TrackTestCommands(t *testing.T) {
+	yaml := `
+owner: carol
+repo: git@github.com:org/app.git
+tracks:
+  - name: backend
+    language: go
+    test_command: "go test ./..."
+  - name: frontend
+    language: typescript
+    pre_test_command: "npm ci"
+    test_command: "npx vitest run"
+    test_timeout_sec: 1800
+`
+	cfg, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fe := cfg.Tracks[1]; fe.PreTestCommand != "npm ci" || fe.TestCommand != "npx vitest run" {
+		t.Errorf("frontend commands = %q, %q", fe.PreTestCommand, fe.TestCommand)
+	}
+	for track, want := range map[string]int{"backend": 600, "frontend": 1800, "missing": 600} {
+		if got := cfg.SwitchTimeoutFor(track); got != want {
+			t.Errorf("SwitchTimeoutFor(%s) = %d, want %d", track, got, want)
+		}
+	}
+
+	_, err = Parse([]byte(strings.Replace(yaml, "test_timeout_sec: 1800", "test_timeout_sec: -5", 1)))
+	if err == nil || !strings.Contains(err.Error(), "test_timeout_sec must not be negative") {
+		t.Errorf("negative test_timeout_sec: err = %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Migration rewrites one older railyard.yaml layout to the current schema.
+// Migrations only ever rename or move keys; values are carried over as
+// written, comments included.
+type Migration struct {
+	From        string // dotted key path in the old layout; "tracks[]" matches every track
+	To          string // dotted key path in the current layout, same shape as From
+	Description string // why the key moved, shown when loading an old config
+}
+
+// Migrations lists every layout change since the first release, oldest
+// first. Add an entry here whenever a key is renamed or moved; Parse refuses
+// configs that still use a From path and ry upgrade-config rewrites them.
+var Migrations = []Migration{
+	{From: "dolt", To: "database", Description: "the 'dolt' key has been renamed to 'database'"},
+}
+
+// UpgradeResult is the outcome of UpgradeConfig.
+type UpgradeResult struct {
+	Data    []byte      // the rewritten config; the input unchanged when nothing applied
+	Applied []Migration // migrations that changed the config, in order
+}
+
+// UpgradeConfig applies every pending migration to a railyard.yaml document.
+// Comments attached to moved keys travel with them. When no migration
+// applies the input is returned byte for byte, so formatting is only
+// normalized for configs that actually change.
+func UpgradeConfig(data []byte) (*UpgradeResult, error) {
+	return upgradeConfig(data, Migrations)
+}
+
+func upgradeConfig(data []byte, migrations []Migration) (*UpgradeResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("config: parse: %w", err)
+	}
+	applied, err := migrate(&doc, migrations)
+	if err != nil {
+		return nil, err
+	}
+	res := &UpgradeResult{Data: data, Applied: applied}
+	if len(applied) == 0 {
+		return res, nil
+	}
+
+	if res.Data, err = encodeYAML(&doc); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// encodeYAML writes a parsed document back out with railyard.yaml's
+// two-space indent.
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("config: encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("config: encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PendingMigrations returns the migrations whose old layout data still
+// uses. Unparseable YAML has none; the main unmarshal reports it.
+func PendingMigrations(data []byte) ([]Migration, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil
+	}
+	return migrate(&doc, Migrations)
+}
+
+// migrate applies migrations to a parsed document in order and returns the
+// ones that changed it.
+func migrate(doc *yaml.Node, migrations []Migration) ([]Migration, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var applied []Migration
+	for _, m := range migrations {
+		moved, err := m.apply(doc.Content[0])
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if moved {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+// apply moves m.From to m.To under root, reporting whether anything moved.
+// A config that sets both paths is refused: the two values need merging by
+// hand.
+func (m Migration) apply(root *yaml.Node) (bool, error) {
+	from, to := strings.Split(m.From, "."), strings.Split(m.To, ".")
+	listed := strings.HasSuffix(from[0], "[]") || strings.HasSuffix(to[0], "[]")
+	if m.From == "" || m.To == "" || listed && from[0] != to[0] {
+		return false, fmt.Errorf("migration %q -> %q: paths must be non-empty and share their list prefix", m.From, m.To)
+	}
+	// Descend through shared "[]" segments so each list element migrates on
+	// its own.
+	if strings.HasSuffix(from[0], "[]") {
+		seq := mappingValue(root, strings.TrimSuffix(from[0], "[]"))
+		if seq == nil || seq.Kind != yaml.SequenceNode {
+			return false, nil
+		}
+		sub := Migration{From: strings.Join(from[1:], "."), To: strings.Join(to[1:], ".")}
+		moved := false
+		for i, item := range seq.Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+			ok, err := sub.apply(item)
+			if err != nil {
+				return false, fmt.Errorf("%s[%d]: %w", strings.TrimSuffix(from[0], "[]"), i, err)
+			}
+			moved = moved || ok
+		}
+		return moved, nil
+	}
+
+	parent := root
+	for _, k := range from[:len(from)-1] {
+		if parent = mappingValue(parent, k); parent == nil || parent.Kind != yaml.MappingNode {
+			return false, nil
+		}
+	}
+	idx := mappingIndex(parent, from[len(from)-1])
+	if idx < 0 {
+		return false, nil
+	}
+	key, val := parent.Content[idx], parent.Content[idx+1]
+
+	dest := root
+	for _, k := range to[:len(to)-1] {
+		next := mappingValue(dest, k)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			dest.Content = append(dest.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, next)
+		} else if next.Kind != yaml.MappingNode {
+			return false, fmt.Errorf("cannot move %s to %s: %s is not a mapping", m.From, m.To, k)
+		}
+		dest = next
+	}
+	name := to[len(to)-1]
+	if mappingIndex(dest, name) >= 0 {
+		return false, fmt.Errorf("both %s and %s are set; merge them into %s by hand", m.From, m.To, m.To)
+	}
+
+	parent.Content = append(parent.Content[:idx], parent.Content[idx+2:]...)
+	key.Value = name
+	if dest == parent {
+		// A rename in place keeps the key where it was.
+		dest.Content = append(dest.Content[:idx], append([]*yaml.Node{key, val}, dest.Content[idx:]...)...)
+	} else {
+		dest.Content = append(dest.Content, key, val)
+	}
+	return true, nil
+}
+
+// mappingIndex returns the index of key's key node in a mapping node's
+// Content, or -1.
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Kind == yaml.ScalarNode && m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	if i := mappingIndex(m, key); i >= 0 {
+		return m.Content[i+1]
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpgradeConfig_RenamesDolt(t *testing.T) {
+	old := `# Railyard config
+owner: alice
+repo: git@github.com:org/app.git
+# Shared MySQL for the yard
+dolt:
+  host: 10.0.0.5 # primary
+  port: 3307
+tracks:
+  - name: backend
+    language: go
+`
+	res, err := UpgradeConfig([]byte(old))
+	if err != nil {
+		t.Fatalf("UpgradeConfig: %v", err)
+	}
+	if len(res.Applied) != 1 || res.Applied[0].From != "dolt" {
+		t.Fatalf("applied = %+v", res.Applied)
+	}
+	out := string(res.Data)
+	for _, want := range []string{"# Railyard config", "# Shared MySQL for the yard\ndatabase:\n", "host: 10.0.0.5 # primary"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("upgraded config missing %q:\n%s", want, out)
+		}
+	}
+	// The key stays where it was.
+	if strings.Index(out, "database:") > strings.Index(out, "tracks:") {
+		t.Errorf("database moved below tracks:\n%s", out)
+	}
+
+	cfg, err := Parse(res.Data)
+	if err != nil {
+		t.Fatalf("upgraded config does not parse: %v", err)
+	}
+	if cfg.Database.Host != "10.0.0.5" || cfg.Database.Port != 3307 {
+		t.Errorf("database = %+v", cfg.Database)
+	}
+
+	// Upgrading again is a no-op that leaves the bytes alone.
+	again, err := UpgradeConfig(res.Data)
+	if err != nil || len(again.Applied) != 0 || string(again.Data) != out {
+		t.Errorf("second upgrade = %+v, %v", again, err)
+	}
+}
+
+func TestUpgradeConfig_CurrentConfigUntouched(t *testing.T) {
+	src := "owner: alice\nrepo:   git@github.com:org/app.git   # odd spacing\n"
+	res, err := UpgradeConfig([]byte(src))
+	if err != nil {
+		t.Fatalf("UpgradeConfig: %v", err)
+	}
+	if len(res.Applied) != 0 || string(res.Data) != src {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestUpgradeConfig_BothKeysSet(t *testing.T) {
+	_, err := UpgradeConfig([]byte("dolt:\n  host: a\ndatabase:\n  host: b\n"))
+	if err == nil || !strings.Contains(err.Error(), "both dolt and database are set") {
+		t.Errorf("err = %v", err)
+	}
+	// Loading reports the conflict rather than silently ignoring dolt.
+	if _, err := Parse([]byte("dolt:\n  host: a\ndatabase:\n  host: b\n")); err == nil {
+		t.Error("Parse accepted a config with both dolt and database")
+	}
+}
+
+func TestUpgradeConfig_MovesNestedAndTrackKeys(t *testing.T) {
+	src := `stall_timeout: 30
+tracks:
+  - name: backend
+    tests: go test ./...
+  - name: frontend
+`
+	res, err := upgradeConfig([]byte(src), []Migration{
+		{From: "stall_timeout", To: "stall.timeout_sec"},
+		{From: "tracks[].tests", To: "tracks[].test_command"},
+	})
+	if err != nil {
+		t.Fatalf("upgradeConfig: %v", err)
+	}
+	want := `tracks:
+  - name: backend
+    test_command: go test ./...
+  - name: frontend
+stall:
+  timeout_sec: 30
+`
+	if string(res.Data) != want || len(res.Applied) != 2 {
+		t.Errorf("upgraded config =\n%s\nwant\n%s", res.Data, want)
+	}
+
+	if _, err := upgradeConfig([]byte(src), []Migration{{From: "tracks[].tests", To: "test_command"}}); err == nil {
+		t.Error("expected an error for a migration leaving its list")
+	}
+}
@@ -0,0 +1,128 @@
+package dashboard
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zulandar/railyard/internal/yard"
+	"gorm.io/gorm"
+)
+
+// APIStatus is the body of GET /api/status: the data behind the index page,
+// for scripts and remote monitors that cannot attach to tmux.
+type APIStatus struct {
+	GeneratedAt       time.Time        `json:"generated_at"`
+	Paused            bool             `json:"paused"`
+	PausedBy          string           `json:"paused_by,omitempty"`
+	PauseReason       string           `json:"pause_reason,omitempty"`
+	Engines           []APIEngine      `json:"engines"`
+	Tracks            []APITrack       `json:"tracks"`
+	Cars              map[string]int   `json:"cars"` // non-epic cars by status
+	MessageQueueDepth int64            `json:"message_queue_depth"`
+	Yardmaster        *APIEngine       `json:"yardmaster"`
+	Stats             APIStatusSummary `json:"stats"`
+}
+
+// APIEngine is one engine in APIStatus.
+type APIEngine struct {
+	ID           string    `json:"id"`
+	Track        string    `json:"track,omitempty"`
+	Status       string    `json:"status"`
+	CurrentCar   string    `json:"current_car,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	LastActivity time.Time `json:"last_activity"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// APITrack is one track's car counts in APIStatus.
+type APITrack struct {
+	Track      string `json:"track"`
+	Draft      int    `json:"draft"`
+	Open       int    `json:"open"`
+	Claimed    int    `json:"claimed"`
+	InProgress int    `json:"in_progress"`
+	Done       int    `json:"done"`
+	Merged     int    `json:"merged"`
+	Blocked    int    `json:"blocked"`
+	Total      int    `json:"total"`
+}
+
+// APIStatusSummary mirrors the stat cards on the index page.
+type APIStatusSummary struct {
+	ActiveEngines  int   `json:"active_engines"`
+	OpenCars       int   `json:"open_cars"`
+	InProgressCars int   `json:"in_progress_cars"`
+	BlockedCars    int   `json:"blocked_cars"`
+	CompletedToday int64 `json:"completed_today"`
+	TotalTokens    int64 `json:"total_tokens"`
+}
+
+// BuildAPIStatus gathers APIStatus from the database. Unlike the HTML
+// pages, which render whatever they could load, it fails on the first query
+// error so monitors never mistake a partial read for an idle yard.
+func BuildAPIStatus(db *gorm.DB) (*APIStatus, error) {
+	engines, err := EngineSummary(db)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := TrackSummary(db)
+	if err != nil {
+		return nil, err
+	}
+	cars, err := CarStatusCounts(db)
+	if err != nil {
+		return nil, err
+	}
+	depth, err := MessageQueueDepth(db)
+	if err != nil {
+		return nil, err
+	}
+	pause, err := yard.GetPause(db)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &APIStatus{
+		GeneratedAt:       time.Now().UTC(),
+		Paused:            pause.Paused,
+		PausedBy:          pause.By,
+		PauseReason:       pause.Reason,
+		Engines:           make([]APIEngine, 0, len(engines)),
+		Tracks:            make([]APITrack, 0, len(tracks)),
+		Cars:              cars,
+		MessageQueueDepth: depth,
+	}
+	for _, e := range engines {
+		st.Engines = append(st.Engines, APIEngine{
+			ID: e.ID, Track: e.Track, Status: e.Status, CurrentCar: e.CurrentCar,
+			Provider: e.Provider, LastActivity: e.LastActivity, StartedAt: e.StartedAt,
+		})
+	}
+	for _, t := range tracks {
+		st.Tracks = append(st.Tracks, APITrack(t))
+	}
+	if ym := YardmasterStatus(db); ym != nil {
+		st.Yardmaster = &APIEngine{ID: ym.ID, Status: ym.Status, CurrentCar: ym.CurrentCar,
+			LastActivity: ym.LastActivity, StartedAt: ym.StartedAt}
+	}
+	stats := ComputeStats(engines, tracks, db)
+	st.Stats = APIStatusSummary(stats)
+	return st, nil
+}
+
+// handleAPIStatus serves BuildAPIStatus as JSON.
+func handleAPIStatus(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if db == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "error", "error": "database unavailable"})
+			return
+		}
+		st, err := BuildAPIStatus(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, st)
+	}
+}
@@ -0,0 +1,115 @@
+package dashboard
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Token scopes. A read token may use GET and HEAD routes; an admin token may
+// also call the mutating /api routes.
+const (
+	ScopeRead  = "read"
+	ScopeAdmin = "admin"
+)
+
+// tokenCookie carries a browser's token after it logs in with ?token=.
+const tokenCookie = "ry_token"
+
+// Token is an API token accepted by the dashboard.
+type Token struct {
+	Name  string // label for logs
+	Value string // the secret
+	Scope string // ScopeRead or ScopeAdmin
+}
+
+// tokenAuth returns middleware that requires one of tokens on every request
+// except static assets. It is a no-op when tokens is empty, which keeps the
+// localhost-only default unauthenticated.
+//
+// API clients send "Authorization: Bearer <token>". A browser opens any page
+// once with ?token=<token>; the token moves into an HttpOnly, SameSite=Strict
+// cookie and the query parameter is stripped by a redirect, so it does not
+// linger in history or the Referer header. SameSite=Strict also keeps other
+// sites from riding the cookie into the POST routes.
+func tokenAuth(tokens []Token) gin.HandlerFunc {
+	if len(tokens) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/static/") {
+			c.Next()
+			return
+		}
+
+		if q := c.Query("token"); q != "" && c.Request.Method == http.MethodGet {
+			if matchToken(tokens, q) == nil {
+				unauthorized(c)
+				return
+			}
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     tokenCookie,
+				Value:    q,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   c.Request.TLS != nil,
+				SameSite: http.SameSiteStrictMode,
+			})
+			u := *c.Request.URL
+			query := u.Query()
+			query.Del("token")
+			u.RawQuery = query.Encode()
+			c.Redirect(http.StatusSeeOther, u.RequestURI())
+			c.Abort()
+			return
+		}
+
+		tok := matchToken(tokens, requestToken(c.Request))
+		if tok == nil {
+			unauthorized(c)
+			return
+		}
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead && tok.Scope != ScopeAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token " + tok.Name + " is read-only; this route requires an admin token"})
+			return
+		}
+		c.Set("APIToken", tok.Name)
+		c.Next()
+	}
+}
+
+// requestToken returns the bearer token or session cookie on r, or "".
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if v, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(v)
+		}
+		return ""
+	}
+	if ck, err := r.Cookie(tokenCookie); err == nil {
+		return ck.Value
+	}
+	return ""
+}
+
+// matchToken returns the token whose value is got, comparing in constant
+// time, or nil.
+func matchToken(tokens []Token, got string) *Token {
+	if got == "" {
+		return nil
+	}
+	var match *Token
+	for i := range tokens {
+		if subtle.ConstantTimeCompare([]byte(tokens[i].Value), []byte(got)) == 1 {
+			match = &tokens[i]
+		}
+	}
+	return match
+}
+
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer realm="railyard"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "a valid API token is required"})
+}
@@ -14,10 +14,10 @@ import (
 
 // Auth security regression tests for the dashboard.
 //
-// These tests document the current state of authentication boundaries.
-// Currently the dashboard has NO authentication — all routes are publicly
-// accessible. These tests serve as a compliance gate: once authentication
-// middleware is added, the skipped tests should be unskipped and enforced.
+// These tests document the authentication boundaries of the default
+// configuration. With no API tokens configured the dashboard has NO
+// authentication — all routes are publicly accessible, which is only safe on
+// localhost. Token enforcement is covered in auth_test.go.
 
 func init() {
 	gin.SetMode(gin.TestMode)
@@ -0,0 +1,233 @@
+package dashboard
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/acme"
+)
+
+func tokenRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(tokenAuth([]Token{
+		{Name: "grafana", Value: "read-tok", Scope: ScopeRead},
+		{Name: "ops", Value: "admin-tok", Scope: ScopeAdmin},
+	}))
+	router.SetHTMLTemplate(mustParseTemplates())
+	registerRoutes(router, nil, "")
+	return router
+}
+
+func serve(router *gin.Engine, method, path string, mutate func(*http.Request)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	if mutate != nil {
+		mutate(req)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func bearer(tok string) func(*http.Request) {
+	return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+tok) }
+}
+
+func TestTokenAuth_RequiresToken(t *testing.T) {
+	router := tokenRouter()
+
+	for _, path := range []string{"/", "/cars", "/logs", "/api/events"} {
+		w := serve(router, "GET", path, nil)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Header().Get("WWW-Authenticate"), "Bearer") {
+			t.Errorf("GET %s without token = %d, want 401 with WWW-Authenticate", path, w.Code)
+		}
+	}
+	if w := serve(router, "GET", "/cars", bearer("wrong")); w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /cars with a bad token = %d, want 401", w.Code)
+	}
+	if w := serve(router, "GET", "/cars", bearer("read-tok")); w.Code != http.StatusOK {
+		t.Errorf("GET /cars with a read token = %d, want 200", w.Code)
+	}
+	if w := serve(router, "GET", "/static/", nil); w.Code == http.StatusUnauthorized {
+		t.Error("static assets require a token")
+	}
+}
+
+func TestTokenAuth_Scopes(t *testing.T) {
+	router := tokenRouter()
+
+	w := serve(router, "POST", "/api/yard/pause", bearer("read-tok"))
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "grafana is read-only") {
+		t.Errorf("pause with a read token = %d %s, want 403", w.Code, w.Body.String())
+	}
+	// The admin token passes auth; the nil DB then fails the handler itself.
+	w = serve(router, "POST", "/api/yard/pause", bearer("admin-tok"))
+	if w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+		t.Errorf("pause with an admin token = %d, want past auth", w.Code)
+	}
+}
+
+func TestTokenAuth_BrowserLogin(t *testing.T) {
+	router := tokenRouter()
+
+	w := serve(router, "GET", "/cars?status=open&token=read-tok", nil)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/cars?status=open" {
+		t.Fatalf("login = %d Location=%q, want 303 to /cars?status=open", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != tokenCookie || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+		t.Fatalf("cookies = %+v, want an HttpOnly SameSite=Strict %s cookie", cookies, tokenCookie)
+	}
+
+	w = serve(router, "GET", "/cars", func(r *http.Request) { r.AddCookie(cookies[0]) })
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /cars with session cookie = %d, want 200", w.Code)
+	}
+	if w := serve(router, "GET", "/cars?token=nope", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("login with a bad token = %d, want 401", w.Code)
+	}
+}
+
+// testPKI is a throwaway CA with a server certificate for 127.0.0.1 (PEM
+// files on disk) and a client certificate it signed.
+type testPKI struct {
+	caFile, certFile, keyFile string
+	client                    tls.Certificate
+	roots                     *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "railyard test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "railyard test"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		if usage == x509.ExtKeyUsageServerAuth {
+			tmpl.DNSNames = []string{"localhost"}
+			tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	p := testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "server.pem"),
+		keyFile:  filepath.Join(dir, "server-key.pem"),
+		roots:    x509.NewCertPool(),
+	}
+	p.roots.AddCert(caCert)
+	os.WriteFile(p.caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600)
+	serverCert, serverKey := issue(2, x509.ExtKeyUsageServerAuth)
+	os.WriteFile(p.certFile, serverCert, 0o600)
+	os.WriteFile(p.keyFile, serverKey, 0o600)
+	clientCert, clientKey := issue(3, x509.ExtKeyUsageClientAuth)
+	if p.client, err = tls.X509KeyPair(clientCert, clientKey); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestServerTLSConfig_MutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	cfg, err := serverTLSConfig(StartOpts{TLSCert: pki.certFile, TLSKey: pki.keyFile, ClientCA: pki.caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ClientAuth = %v, want RequireAndVerifyClientCert", cfg.ClientAuth)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = cfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.roots, Certificates: certs}}}
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(); err == nil {
+		t.Error("connection without a client certificate succeeded")
+	}
+	if err := get(pki.client); err != nil {
+		t.Errorf("connection with a client certificate: %v", err)
+	}
+}
+
+func TestServerTLSConfig_Modes(t *testing.T) {
+	if cfg, err := serverTLSConfig(StartOpts{}); cfg != nil || err != nil {
+		t.Errorf("plain HTTP: cfg = %v, err = %v; want nil, nil", cfg, err)
+	}
+	if _, err := serverTLSConfig(StartOpts{ClientCA: "ca.pem"}); err == nil {
+		t.Error("client CA without TLS: want error")
+	}
+	if _, err := serverTLSConfig(StartOpts{TLSCert: "missing.pem", TLSKey: "missing-key.pem"}); err == nil {
+		t.Error("missing key pair: want error")
+	}
+
+	pki := newTestPKI(t)
+	cfg, err := serverTLSConfig(StartOpts{
+		ACME:     &ACMEOpts{Domains: []string{"yard.example.com"}, CacheDir: t.TempDir()},
+		ClientCA: pki.caFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GetCertificate == nil || !strings.Contains(strings.Join(cfg.NextProtos, ","), acme.ALPNProto) {
+		t.Errorf("ACME config: GetCertificate set=%v NextProtos=%v", cfg.GetCertificate != nil, cfg.NextProtos)
+	}
+	challenge, _ := cfg.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{acme.ALPNProto}})
+	if challenge == nil || challenge.ClientAuth != tls.NoClientCert {
+		t.Error("ACME challenge handshake must not require a client certificate")
+	}
+	if normal, _ := cfg.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"h2"}}); normal != nil {
+		t.Error("ordinary handshakes must use the mutual-TLS config")
+	}
+}
@@ -2,14 +2,15 @@ package dashboard
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
@@ -125,6 +126,26 @@ func MessageQueueDepth(db *gorm.DB) (int64, error) {
 	return count, nil
 }
 
+// CarStatusCounts returns the number of non-epic cars in each status.
+func CarStatusCounts(db *gorm.DB) (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	if err := db.Model(&models.Car{}).
+		Select("status, count(*) as count").
+		Where("type != ?", "epic").
+		Group("status").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
+
 // CarRow holds car data for display in the list view.
 type CarRow struct {
 	ID          string
@@ -420,7 +441,7 @@ func GetCarDetail(db *gorm.DB, id string) (*CarDetail, error) {
 				Priority: ch.Priority,
 				Assignee: ch.Assignee,
 			}
-			if ch.Status == "done" || ch.Status == "merged" || ch.Status == "cancelled" {
+			if slices.Contains(models.ClosedStatuses, ch.Status) {
 				detail.ChildrenDone++
 			}
 		}
@@ -1485,105 +1506,37 @@ func estimateTokenCost(model string, inputTokens, outputTokens int64) float64 {
 
 // --- Yard pause/resume state ---
 //
-// The paused flag is persisted into the existing RailyardConfig.Settings JSON
-// blob so we avoid adding new tables / columns. The row may be missing in
-// dev/test environments — the helpers below treat a missing row as
-// "not paused" and create a minimal row on first SetYardPaused write.
-
-// yardSettings is the shape we serialize into RailyardConfig.Settings. Unknown
-// fields from other writers are preserved by round-tripping through a
-// map[string]any (see SetYardPaused).
-type yardSettings struct {
-	Paused       bool   `json:"paused"`
-	PausedReason string `json:"paused_reason,omitempty"`
-}
+// The pause switch itself lives in internal/yard, where engine claims, the
+// yardmaster's merges, and car creation consult it. These wrappers keep the
+// dashboard's flag-and-reason view of it.
 
 // GetYardPaused returns the current paused flag and reason. A nil db returns
 // the zero value. A missing or unparseable row is treated as not paused.
-// Transient DB errors (anything other than gorm.ErrRecordNotFound) are logged
-// at WARN so operators can spot a degraded database that would otherwise
-// silently render the yard as "running".
+// Transient DB errors are logged at WARN so operators can spot a degraded
+// database that would otherwise silently render the yard as "running".
 func GetYardPaused(db *gorm.DB) (paused bool, reason string) {
 	if db == nil {
 		return false, ""
 	}
-	var rc models.RailyardConfig
-	if err := db.First(&rc).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			slog.Default().Warn("dashboard: GetYardPaused query failed", "error", err)
-		}
-		return false, ""
-	}
-	if rc.Settings == "" {
-		return false, ""
-	}
-	var s yardSettings
-	if err := json.Unmarshal([]byte(rc.Settings), &s); err != nil {
+	st, err := yard.GetPause(db)
+	if err != nil {
+		slog.Default().Warn("dashboard: GetYardPaused query failed", "error", err)
 		return false, ""
 	}
-	return s.Paused, s.PausedReason
+	return st.Paused, st.Reason
 }
 
-// SetYardPaused persists the paused flag and reason into the
-// RailyardConfig.Settings JSON. Other keys already present in Settings are
-// preserved.
-//
-// If no RailyardConfig row exists yet, one is created with Owner="dashboard"
-// and an empty repo URL so the dashboard can manage yard state independently
-// of the db init seed path. This is a safe fallback for unit tests that only
-// run AutoMigrate.
+// SetYardPaused pauses the yard on behalf of the dashboard, or resumes it.
+// Pausing an already paused yard keeps the existing pause and its reason.
 func SetYardPaused(db *gorm.DB, paused bool, reason string) error {
-	if db == nil {
-		return fmt.Errorf("dashboard: db is required")
-	}
-
-	var rc models.RailyardConfig
-	err := db.First(&rc).Error
-	if err != nil {
-		// Only treat a genuine "no row yet" as the fallback-create case;
-		// any other error (context cancellation, connection blip, schema
-		// mismatch, etc.) must propagate so we don't insert a spurious
-		// Owner="dashboard" row and mask the underlying failure.
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("dashboard: read config row: %w", err)
-		}
-		rc = models.RailyardConfig{
-			Owner:    "dashboard",
-			RepoURL:  "",
-			Mode:     "local",
-			Settings: "{}",
-		}
-		if createErr := db.Create(&rc).Error; createErr != nil {
-			return fmt.Errorf("dashboard: create config row: %w", createErr)
-		}
-	}
-
-	// Round-trip through map[string]any to preserve other Settings keys.
-	settings := map[string]any{}
-	if rc.Settings != "" {
-		if err := json.Unmarshal([]byte(rc.Settings), &settings); err != nil {
-			// Corrupted JSON — reset to a fresh map; safer than refusing to
-			// update yard state.
-			settings = map[string]any{}
-		}
-	}
-	settings["paused"] = paused
+	var err error
 	if paused {
-		// Only persist a reason when actively pausing — clearing on resume
-		// avoids stale "paused_reason" lingering after a resume.
-		settings["paused_reason"] = reason
+		_, _, err = yard.Pause(db, reason, "dashboard")
 	} else {
-		delete(settings, "paused_reason")
+		_, _, err = yard.Resume(db)
 	}
-
-	data, err := json.Marshal(settings)
 	if err != nil {
-		return fmt.Errorf("dashboard: marshal settings: %w", err)
-	}
-	if err := db.Model(&models.RailyardConfig{}).
-		Where("id = ?", rc.ID).
-		Update("settings", string(data)).Error; err != nil {
-		return fmt.Errorf("dashboard: update settings: %w", err)
+		return fmt.Errorf("dashboard: %w", err)
 	}
 	return nil
 }
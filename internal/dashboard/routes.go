@@ -69,6 +69,9 @@ func registerRoutesWithBus(router *gin.Engine, db *gorm.DB, projectName string,
 	router.GET("/partials/yardmaster", handlePartialsYardmaster(db))
 	router.GET("/partials/ready-cars", handlePartialsReadyCars(db))
 
+	// JSON API for remote monitoring.
+	router.GET("/api/status", handleAPIStatus(db))
+
 	// SSE endpoint for real-time escalation alerts.
 	router.GET("/api/events", handleSSE(db))
 
@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -83,6 +84,50 @@ func TestRouteIndex_WithData(t *testing.T) {
 	}
 }
 
+func TestRouteAPIStatus(t *testing.T) {
+	db, baseURL, cleanup := setupDBRouter(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.Create(&models.Engine{ID: "eng-api-1", Track: "backend", Status: "working", CurrentCar: "car-api-1", LastActivity: now})
+	db.Create(&models.Car{ID: "car-api-1", Title: "A", Track: "backend", Status: "in_progress", Type: "task"})
+	db.Create(&models.Car{ID: "car-api-2", Title: "B", Track: "backend", Status: "open", Type: "task"})
+	db.Create(&models.Car{ID: "car-api-3", Title: "C", Track: "frontend", Status: "merge_failed", Type: "task"})
+	db.Create(&models.Message{FromAgent: "eng-api-1", ToAgent: "yardmaster", Subject: "help"})
+	if err := SetYardPaused(db, true, "deploy"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(baseURL + "/api/status")
+	if err != nil {
+		t.Fatalf("GET /api/status: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("content type = %q", ct)
+	}
+
+	var st APIStatus
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(st.Engines) != 1 || st.Engines[0].ID != "eng-api-1" || st.Engines[0].CurrentCar != "car-api-1" {
+		t.Errorf("engines = %+v", st.Engines)
+	}
+	if len(st.Tracks) != 2 || st.Tracks[0].Track != "backend" || st.Tracks[0].InProgress != 1 || st.Tracks[0].Open != 1 {
+		t.Errorf("tracks = %+v", st.Tracks)
+	}
+	if st.Cars["merge_failed"] != 1 || st.Cars["open"] != 1 {
+		t.Errorf("cars = %v", st.Cars)
+	}
+	if st.MessageQueueDepth != 1 || !st.Paused || st.PauseReason != "deploy" || st.PausedBy != "dashboard" {
+		t.Errorf("status = %+v", st)
+	}
+}
+
 func TestRouteCarDetail_WithData(t *testing.T) {
 	db, baseURL, cleanup := setupDBRouter(t)
 	defer cleanup()
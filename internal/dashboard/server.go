@@ -22,6 +22,9 @@ type StartOpts struct {
 	Out         io.Writer
 	TLSCert     string          // path to TLS certificate file (optional)
 	TLSKey      string          // path to TLS private key file (optional)
+	ACME        *ACMEOpts       // obtain the certificate via ACME instead of TLSCert/TLSKey (optional)
+	ClientCA    string          // PEM CA bundle; when set, clients must present a certificate it signed (optional)
+	Tokens      []Token         // API tokens; when non-empty, every non-static request needs one (optional)
 	RateLimit   RateLimitConfig // per-IP rate limiting (optional)
 	ProjectName string          // project name displayed in the nav bar badge (optional)
 	// Bus is the optional plugin event bus. When non-nil, pause/resume routes
@@ -46,6 +49,7 @@ func Start(ctx context.Context, opts StartOpts) error {
 	router.Use(gin.Recovery())
 	router.Use(securityHeaders())
 	router.Use(rateLimiter(ctx, opts.RateLimit))
+	router.Use(tokenAuth(opts.Tokens))
 
 	// Parse embedded templates.
 	tmpl, err := parseTemplates()
@@ -57,10 +61,16 @@ func Start(ctx context.Context, opts StartOpts) error {
 	// Register routes.
 	registerRoutesWithBus(router, opts.DB, opts.ProjectName, opts.Bus)
 
+	tlsCfg, err := serverTLSConfig(opts)
+	if err != nil {
+		return fmt.Errorf("dashboard: %w", err)
+	}
+
 	addr := fmt.Sprintf(":%d", opts.Port)
 	srv := &http.Server{
 		Addr:              addr,
 		Handler:           router,
+		TLSConfig:         tlsCfg,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      120 * time.Second, // generous for SSE long-poll
@@ -77,7 +87,7 @@ func Start(ctx context.Context, opts StartOpts) error {
 		srv.Shutdown(shutdownCtx)
 	}()
 
-	useTLS := opts.TLSCert != "" && opts.TLSKey != ""
+	useTLS := tlsCfg != nil
 	if opts.Out != nil {
 		scheme := "http"
 		if useTLS {
@@ -88,7 +98,7 @@ func Start(ctx context.Context, opts StartOpts) error {
 
 	var listenErr error
 	if useTLS {
-		listenErr = srv.ListenAndServeTLS(opts.TLSCert, opts.TLSKey)
+		listenErr = srv.ListenAndServeTLS("", "")
 	} else {
 		listenErr = srv.ListenAndServe()
 	}
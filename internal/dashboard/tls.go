@@ -0,0 +1,81 @@
+package dashboard
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACMEOpts obtains the server certificate from an ACME CA.
+type ACMEOpts struct {
+	Domains      []string
+	Email        string
+	CacheDir     string
+	DirectoryURL string // default Let's Encrypt production
+}
+
+// serverTLSConfig builds the server's TLS config from opts, or returns nil
+// when the dashboard should serve plain HTTP.
+func serverTLSConfig(opts StartOpts) (*tls.Config, error) {
+	useACME := opts.ACME != nil && len(opts.ACME.Domains) > 0
+	useFiles := opts.TLSCert != "" && opts.TLSKey != ""
+	if !useACME && !useFiles {
+		if opts.ClientCA != "" {
+			return nil, fmt.Errorf("client CA requires a TLS certificate or ACME")
+		}
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if useFiles {
+		cert, err := tls.LoadX509KeyPair(opts.TLSCert, opts.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	} else {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(opts.ACME.Domains...),
+			Cache:      autocert.DirCache(opts.ACME.CacheDir),
+			Email:      opts.ACME.Email,
+		}
+		if opts.ACME.DirectoryURL != "" {
+			m.Client = &acme.Client{DirectoryURL: opts.ACME.DirectoryURL}
+		}
+		cfg.GetCertificate = m.GetCertificate
+		cfg.NextProtos = []string{"h2", "http/1.1", acme.ALPNProto}
+	}
+
+	if opts.ClientCA != "" {
+		pem, err := os.ReadFile(opts.ClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA %s contains no PEM certificates", opts.ClientCA)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		if useACME {
+			// The CA's TLS-ALPN-01 validation handshake carries no client
+			// certificate; let it through so renewals keep working.
+			open := cfg.Clone()
+			open.ClientAuth = tls.NoClientCert
+			open.ClientCAs = nil
+			cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+				if slices.Equal(hello.SupportedProtos, []string{acme.ALPNProto}) {
+					return open, nil
+				}
+				return nil, nil
+			}
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"gorm.io/gorm"
+)
+
+// ErrNotDolt is returned by the time-travel helpers when the database
+// server is not Dolt: plain MySQL keeps no history to read.
+var ErrNotDolt = errors.New("db: the database server is not Dolt, which keeps no history to replay")
+
+// DoltCommit is one commit in a Dolt database's history.
+type DoltCommit struct {
+	Hash      string    `gorm:"column:commit_hash"`
+	Committer string    `gorm:"column:committer"`
+	Date      time.Time `gorm:"column:date"`
+	Message   string    `gorm:"column:message"`
+}
+
+// asOfLayouts are the timestamp layouts ParseAsOf accepts, in local time
+// unless the layout carries a zone.
+var asOfLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// validRevision matches Dolt revision specs: commit hashes, branches,
+// tags, and ancestry suffixes such as main~3.
+var validRevision = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./~^-]*$`)
+
+// ParseAsOf interprets an --as-of value. A timestamp or a duration (read as
+// that long before now, e.g. 8h) returns the time; anything else must be a
+// Dolt revision (commit hash, branch, or tag) and is returned as rev.
+func ParseAsOf(s string, now time.Time) (at time.Time, rev string, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, "", fmt.Errorf("db: as-of is empty")
+	}
+	for _, layout := range asOfLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, "", nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, "", fmt.Errorf("db: as-of duration %s must be positive", s)
+		}
+		return now.Add(-d), "", nil
+	}
+	if !validRevision.MatchString(s) {
+		return time.Time{}, "", fmt.Errorf("db: as-of %q is not a timestamp, duration, or Dolt revision", s)
+	}
+	return time.Time{}, s, nil
+}
+
+// DoltVersion returns the version of the Dolt server db is connected to,
+// or ErrNotDolt.
+func DoltVersion(db *gorm.DB) (string, error) {
+	var version string
+	if err := db.Raw("SELECT dolt_version()").Scan(&version).Error; err != nil || version == "" {
+		return "", ErrNotDolt
+	}
+	return version, nil
+}
+
+// ResolveAsOf returns the Dolt commit the database was at for an --as-of
+// value (see ParseAsOf): the last commit at or before a time, or the commit
+// a revision names.
+func ResolveAsOf(db *gorm.DB, asOf string, now time.Time) (*DoltCommit, error) {
+	at, rev, err := ParseAsOf(asOf, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := DoltVersion(db); err != nil {
+		return nil, err
+	}
+
+	var commits []DoltCommit
+	q := db.Raw("SELECT commit_hash, committer, date, message FROM dolt_log WHERE date <= ? ORDER BY date DESC LIMIT 1", at)
+	if rev != "" {
+		// Table function arguments cannot be bound; rev is validated above.
+		q = db.Raw(fmt.Sprintf("SELECT commit_hash, committer, date, message FROM dolt_log('%s') LIMIT 1", rev))
+	}
+	if err := q.Scan(&commits).Error; err != nil {
+		return nil, fmt.Errorf("db: resolve as-of %q: %w", asOf, err)
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("db: no Dolt commit at or before %q", asOf)
+	}
+	return &commits[0], nil
+}
+
+// ConnectAsOf opens a connection to the database as it was at a Dolt
+// commit, through Dolt's read-only revision database "<database>/<commit>":
+// every query reads that commit, as with AS OF.
+func ConnectAsOf(cfg config.DatabaseConfig, commit string) (*gorm.DB, error) {
+	if !validRevision.MatchString(commit) {
+		return nil, fmt.Errorf("db: invalid Dolt commit %q", commit)
+	}
+	cfg.Database = url.PathEscape(cfg.Database + "/" + commit)
+	return ConnectWithConfig(cfg)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/zulandar/railyard/internal/config"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestParseAsOf(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		in     string
+		wantAt time.Time
+		rev    string
+	}{
+		{"2024-01-01 10:00", time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local), ""},
+		{"2024-01-01 10:00:30", time.Date(2024, 1, 1, 10, 0, 30, 0, time.Local), ""},
+		{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), ""},
+		{"2024-01-01T10:00:00Z", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), ""},
+		{"8h", now.Add(-8 * time.Hour), ""},
+		{"3v2q9k1mhtbg1p7ccm4gq2l8ufn5ie0d", time.Time{}, "3v2q9k1mhtbg1p7ccm4gq2l8ufn5ie0d"},
+		{"main~3", time.Time{}, "main~3"},
+	}
+	for _, tt := range tests {
+		at, rev, err := ParseAsOf(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseAsOf(%q): %v", tt.in, err)
+			continue
+		}
+		if !at.Equal(tt.wantAt) || rev != tt.rev {
+			t.Errorf("ParseAsOf(%q) = %v, %q; want %v, %q", tt.in, at, rev, tt.wantAt, tt.rev)
+		}
+	}
+
+	for _, bad := range []string{"", "-2h", "main'; DROP TABLE cars; --", "two words"} {
+		if _, _, err := ParseAsOf(bad, now); err == nil {
+			t.Errorf("ParseAsOf(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestConnectAsOf_RevisionDatabase(t *testing.T) {
+	var captured string
+	orig := openDB
+	openDB = func(dsn string) (*gorm.DB, error) {
+		captured = dsn
+		return nil, errors.New("stub")
+	}
+	defer func() { openDB = orig }()
+
+	cfg := config.DatabaseConfig{Host: "127.0.0.1", Port: 3306, Database: "railyard_alice", Username: "root"}
+	ConnectAsOf(cfg, "3v2q9k1mhtbg1p7ccm4gq2l8ufn5ie0d")
+	parsed, err := gomysql.ParseDSN(captured)
+	if err != nil {
+		t.Fatalf("ParseDSN(%q): %v", captured, err)
+	}
+	if parsed.DBName != "railyard_alice/3v2q9k1mhtbg1p7ccm4gq2l8ufn5ie0d" {
+		t.Errorf("database = %q, want the revision database", parsed.DBName)
+	}
+
+	if _, err := ConnectAsOf(cfg, "x'; --"); err == nil {
+		t.Error("ConnectAsOf accepted an invalid commit")
+	}
+}
+
+func TestResolveAsOf_NotDolt(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := ResolveAsOf(gormDB, "8h", time.Now()); !errors.Is(err, ErrNotDolt) {
+		t.Errorf("err = %v, want ErrNotDolt", err)
+	}
+}
@@ -24,12 +24,24 @@ func AllModels() []interface{} {
 		&models.Message{},
 		&models.BroadcastAck{},
 		&models.AgentLog{},
+		&models.JournalEntry{},
+		&models.Event{},
 		&models.RailyardConfig{},
 		&models.DispatchSession{},
 		&models.TelegraphConversation{},
+		&models.ThreadHistoryCache{},
+		&models.NotifySubscription{},
 		&models.BullIssue{},
 		&models.BullMeta{},
 		&models.PluginKV{},
+		&models.CoverageRecord{},
+		&models.UndoEntry{},
+		&models.Attachment{},
+		&models.CarThread{},
+		&models.TrackNote{},
+		&models.CarWebhook{},
+		&models.FailureLabel{},
+		&models.EpicReservation{},
 		&audit.AuditEvent{},
 	}
 }
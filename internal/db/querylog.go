@@ -0,0 +1,256 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// modulePath prefixes every Railyard function name in a stack trace.
+const modulePath = "github.com/zulandar/railyard/"
+
+// QueryRecord is one line of the slow-query log.
+type QueryRecord struct {
+	Time       time.Time `json:"time"`
+	DurationMs float64   `json:"duration_ms"`
+	SQL        string    `json:"sql"` // normalized: literals replaced with ?
+	Rows       int64     `json:"rows"`
+	Subsystem  string    `json:"subsystem"` // package that issued the query, e.g. "yardmaster"
+	Caller     string    `json:"caller"`    // first Railyard frame, "func (file:line)"
+	Stack      []string  `json:"stack,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// maxStackFrames bounds the Railyard frames kept per record.
+const maxStackFrames = 6
+
+// slowQueryLogger is a GORM logger that stays silent except for queries
+// slower than threshold, which it appends to w as JSON lines.
+type slowQueryLogger struct {
+	logger.Interface
+	threshold time.Duration
+	now       func() time.Time
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newSlowQueryLogger(w io.Writer, threshold time.Duration) *slowQueryLogger {
+	return &slowQueryLogger{
+		Interface: logger.Default.LogMode(logger.Silent),
+		threshold: threshold,
+		now:       time.Now,
+		w:         w,
+	}
+}
+
+// LogMode keeps the slow-query log in place when callers such as db.Debug()
+// ask for a different level.
+func (l *slowQueryLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+// Trace records the query if it ran for at least the threshold.
+func (l *slowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := l.now().Sub(begin)
+	if elapsed < l.threshold {
+		return
+	}
+	sql, rows := fc()
+	rec := QueryRecord{
+		Time:       begin.UTC(),
+		DurationMs: float64(elapsed.Microseconds()) / 1000,
+		SQL:        NormalizeSQL(sql),
+		Rows:       rows,
+	}
+	rec.Subsystem, rec.Caller, rec.Stack = queryCaller()
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	line, mErr := json.Marshal(rec)
+	if mErr != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// One write per record, so processes sharing the file with O_APPEND do
+	// not interleave lines.
+	l.w.Write(append(line, '\n'))
+}
+
+// queryCaller walks the stack above GORM to the Railyard code that issued
+// the query, returning its subsystem, the calling frame, and the Railyard
+// frames above it.
+func queryCaller() (subsystem, caller string, stack []string) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if strings.HasPrefix(f.Function, modulePath) && !strings.Contains(f.Function, "/internal/db.(*slowQueryLogger)") {
+			if caller == "" {
+				subsystem = subsystemOf(f.Function)
+				caller = fmt.Sprintf("%s (%s:%d)", shortFunc(f.Function), filepath.Base(f.File), f.Line)
+			}
+			if len(stack) < maxStackFrames {
+				stack = append(stack, fmt.Sprintf("%s (%s:%d)", shortFunc(f.Function), filepath.Base(f.File), f.Line))
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	if caller == "" {
+		subsystem = "unknown"
+	}
+	return subsystem, caller, stack
+}
+
+// subsystemOf names the Railyard package a function belongs to:
+// ".../internal/yardmaster.(*Daemon).poll" is "yardmaster".
+func subsystemOf(function string) string {
+	pkg := strings.TrimPrefix(function, modulePath)
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if i := strings.Index(pkg, "."); i >= 0 {
+		pkg = pkg[:i]
+	}
+	return pkg
+}
+
+// shortFunc drops the import path from a function name.
+func shortFunc(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		return function[i+1:]
+	}
+	return function
+}
+
+var (
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+	sqlNumber        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlValueList     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)(?:\s*,\s*\(\s*\?(?:\s*,\s*\?)*\s*\))*`)
+	sqlSpace         = regexp.MustCompile(`\s+`)
+)
+
+// NormalizeSQL replaces literals with ? and collapses value lists, so
+// queries that differ only in their arguments group together and no row
+// data reaches the log.
+func NormalizeSQL(sql string) string {
+	sql = sqlStringLiteral.ReplaceAllString(sql, "?")
+	sql = sqlNumber.ReplaceAllString(sql, "?")
+	sql = sqlValueList.ReplaceAllString(sql, "(?)")
+	return strings.TrimSpace(sqlSpace.ReplaceAllString(sql, " "))
+}
+
+// InstrumentQueries installs the slow-query log on gormDB when cfg enables
+// it. The log file is opened for append, so every process in a yard can
+// share it.
+func InstrumentQueries(gormDB *gorm.DB, cfg config.QueryLogConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("db: query log: %w", err)
+	}
+	f, err := os.OpenFile(cfg.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("db: query log: %w", err)
+	}
+	gormDB.Logger = newSlowQueryLogger(f, time.Duration(cfg.SlowMs)*time.Millisecond)
+	return nil
+}
+
+// ReadQueryLog parses a slow-query log, skipping records before since.
+// Lines that do not parse (a torn final write, say) are skipped.
+func ReadQueryLog(r io.Reader, since time.Time) ([]QueryRecord, error) {
+	var records []QueryRecord
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var rec QueryRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Time.Before(since) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("db: read query log: %w", err)
+	}
+	return records, nil
+}
+
+// QuerySummary aggregates the slow-query records for one normalized query.
+type QuerySummary struct {
+	SQL        string
+	Count      int
+	TotalMs    float64
+	MaxMs      float64
+	Subsystems map[string]int // records per issuing subsystem
+	TopCaller  string         // the caller with the most records
+}
+
+// AvgMs is the mean duration of the query.
+func (s QuerySummary) AvgMs() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalMs / float64(s.Count)
+}
+
+// SummarizeQueries groups records by normalized SQL and returns the top
+// groups by total time spent. top <= 0 returns every group.
+func SummarizeQueries(records []QueryRecord, top int) []QuerySummary {
+	groups := make(map[string]*QuerySummary)
+	callers := make(map[string]map[string]int)
+	for _, r := range records {
+		s, ok := groups[r.SQL]
+		if !ok {
+			s = &QuerySummary{SQL: r.SQL, Subsystems: make(map[string]int)}
+			groups[r.SQL] = s
+			callers[r.SQL] = make(map[string]int)
+		}
+		s.Count++
+		s.TotalMs += r.DurationMs
+		s.MaxMs = max(s.MaxMs, r.DurationMs)
+		s.Subsystems[r.Subsystem]++
+		callers[r.SQL][r.Caller]++
+	}
+
+	out := make([]QuerySummary, 0, len(groups))
+	for sql, s := range groups {
+		best := -1
+		for c, n := range callers[sql] {
+			if n > best || n == best && c < s.TopCaller {
+				s.TopCaller, best = c, n
+			}
+		}
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TotalMs != out[j].TotalMs {
+			return out[i].TotalMs > out[j].TotalMs
+		}
+		return out[i].SQL < out[j].SQL
+	})
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM `cars` WHERE id = 'car-abc12' AND priority > 3":  "SELECT * FROM `cars` WHERE id = ? AND priority > ?",
+		"SELECT * FROM cars WHERE status IN ('open','ready', 'done')":   "SELECT * FROM cars WHERE status IN (?)",
+		"INSERT INTO t (a,b) VALUES (1,'x'),(2,'it''s')":                "INSERT INTO t (a,b) VALUES (?)",
+		"UPDATE engines\n\tSET last_activity = '2026-01-02 03:04:05.6'": "UPDATE engines SET last_activity = ?",
+		"SELECT count(*) FROM table2":                                   "SELECT count(*) FROM table2",
+		`SELECT * FROM cars WHERE title = "say \"hi\""`:                 "SELECT * FROM cars WHERE title = ?",
+	}
+	for in, want := range cases {
+		if got := NormalizeSQL(in); got != want {
+			t.Errorf("NormalizeSQL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSlowQueryLogger_RecordsSlowQueries(t *testing.T) {
+	db := testDB(t)
+	var buf bytes.Buffer
+	l := newSlowQueryLogger(&buf, 50*time.Millisecond)
+	db.Logger = l
+
+	// Fast queries stay out of the log.
+	var n int64
+	db.Model(&models.Car{}).Where("id = ?", "car-fast").Count(&n)
+	if buf.Len() != 0 {
+		t.Fatalf("fast query logged: %s", buf.String())
+	}
+
+	l.now = func() time.Time { return time.Now().Add(time.Second) }
+	db.Model(&models.Car{}).Where("id = ?", "car-secret").Count(&n)
+	recs, err := ReadQueryLog(&buf, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("got %d records, want 1: %s", len(recs), buf.String())
+	}
+	r := recs[0]
+	if r.DurationMs < 1000 || strings.Contains(r.SQL, "car-secret") || !strings.Contains(r.SQL, "`cars`") {
+		t.Errorf("record = %+v", r)
+	}
+	if r.Subsystem != "db" || !strings.Contains(r.Caller, "TestSlowQueryLogger_RecordsSlowQueries") || len(r.Stack) == 0 {
+		t.Errorf("caller = %q/%q, stack %v", r.Subsystem, r.Caller, r.Stack)
+	}
+}
+
+func TestInstrumentQueries(t *testing.T) {
+	db := testDB(t)
+	silent := db.Logger
+	if err := InstrumentQueries(db, config.QueryLogConfig{}); err != nil || db.Logger != silent {
+		t.Fatalf("disabled query log changed the logger (err %v)", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "logs", "slow.jsonl")
+	if err := InstrumentQueries(db, config.QueryLogConfig{Enabled: true, Path: path}); err != nil {
+		t.Fatalf("InstrumentQueries: %v", err)
+	}
+	var n int64
+	db.Model(&models.Car{}).Count(&n)
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"subsystem":"db"`) {
+		t.Errorf("log = %q, %v", data, err)
+	}
+}
+
+func TestSummarizeQueries(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	log := strings.Join([]string{
+		`{"time":"2026-05-01T11:00:00Z","duration_ms":300,"sql":"SELECT a","subsystem":"cli","caller":"cli.runStatus (status.go:10)"}`,
+		`{"time":"2026-05-01T11:01:00Z","duration_ms":500,"sql":"SELECT a","subsystem":"yardmaster","caller":"yardmaster.poll (daemon.go:5)"}`,
+		`{"time":"2026-05-01T11:02:00Z","duration_ms":250,"sql":"SELECT a","subsystem":"cli","caller":"cli.runStatus (status.go:10)"}`,
+		`{"time":"2026-05-01T11:03:00Z","duration_ms":900,"sql":"SELECT b","subsystem":"engine","caller":"engine.claim (claim.go:1)"}`,
+		`{"time":"2026-04-01T00:00:00Z","duration_ms":9000,"sql":"SELECT old","subsystem":"engine"}`,
+		`{"time":`,
+	}, "\n")
+	recs, err := ReadQueryLog(strings.NewReader(log), now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("got %d records, want 4 (old and torn lines skipped)", len(recs))
+	}
+
+	sums := SummarizeQueries(recs, 0)
+	if len(sums) != 2 || sums[0].SQL != "SELECT a" {
+		t.Fatalf("summaries = %+v", sums)
+	}
+	a := sums[0]
+	if a.Count != 3 || a.TotalMs != 1050 || a.MaxMs != 500 || a.AvgMs() != 350 {
+		t.Errorf("SELECT a = %+v", a)
+	}
+	if a.Subsystems["cli"] != 2 || a.Subsystems["yardmaster"] != 1 || a.TopCaller != "cli.runStatus (status.go:10)" {
+		t.Errorf("SELECT a callers = %+v / %q", a.Subsystems, a.TopCaller)
+	}
+	if got := SummarizeQueries(recs, 1); len(got) != 1 {
+		t.Errorf("top 1 returned %d", len(got))
+	}
+}
@@ -13,6 +13,10 @@ import (
 	"github.com/zulandar/railyard/internal/engine"
 )
 
+// SessionEnv carries the dispatch session ID to the agent's subprocesses,
+// so `ry car create` run by the agent records each car on the session.
+const SessionEnv = "RAILYARD_DISPATCH_SESSION"
+
 // StartOpts holds parameters for starting the Dispatch agent.
 type StartOpts struct {
 	ConfigPath string
@@ -214,6 +214,29 @@ func TestRenderPrompt_ContainsConventions(t *testing.T) {
 	}
 }
 
+func TestRenderPrompt_ContainsDiffLimit(t *testing.T) {
+	cfg := testConfig(config.TrackConfig{
+		Name:      "backend",
+		Language:  "go",
+		DiffLimit: &config.DiffLimitConfig{MaxFiles: 20, MaxLines: 800},
+	})
+
+	prompt, err := RenderPrompt(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"- Diff limit per car: 20 files, 800 lines",
+		"### Split Requests",
+		"ry inbox --agent dispatch",
+		`ry car update <car-id> --description "..." --acceptance "..."`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
 func TestRenderPrompt_ContainsNewSections(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{
 		Name:     "api",
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// WriteAttachments writes carID's chat attachments into workDir at the paths
+// the car description lists. The directory is recreated each time so files
+// from the worktree's previous car never leak into this one. It is excluded
+// from git via EnsureRailyardIgnore.
+func WriteAttachments(db *gorm.DB, workDir, carID string) (int, error) {
+	dir := filepath.Join(workDir, models.AttachmentsDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("engine: clear attachments: %w", err)
+	}
+
+	var atts []models.Attachment
+	if err := db.Where("car_id = ?", carID).Order("id").Find(&atts).Error; err != nil {
+		return 0, fmt.Errorf("engine: load attachments for %s: %w", carID, err)
+	}
+	if len(atts) == 0 {
+		return 0, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("engine: create attachments dir: %w", err)
+	}
+	for _, a := range atts {
+		path := filepath.Join(workDir, filepath.FromSlash(car.AttachmentPath(a)))
+		if err := os.WriteFile(path, a.Data, 0644); err != nil {
+			return 0, fmt.Errorf("engine: write attachment %d: %w", a.ID, err)
+		}
+	}
+	return len(atts), nil
+}
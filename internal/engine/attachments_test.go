@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestWriteAttachments(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&models.Attachment{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	gormDB.Create(&models.Attachment{CarID: "car-a", Name: "spec.md", Data: []byte("# spec")})
+	gormDB.Create(&models.Attachment{CarID: "car-b", Name: "other.txt", Data: []byte("other")})
+
+	workDir := t.TempDir()
+	stale := filepath.Join(workDir, models.AttachmentsDir, "9-stale.txt")
+	os.MkdirAll(filepath.Dir(stale), 0755)
+	os.WriteFile(stale, []byte("old car"), 0644)
+
+	n, err := WriteAttachments(gormDB, workDir, "car-a")
+	if err != nil || n != 1 {
+		t.Fatalf("WriteAttachments = %d, %v; want 1, nil", n, err)
+	}
+	got, err := os.ReadFile(filepath.Join(workDir, models.AttachmentsDir, "1-spec.md"))
+	if err != nil || string(got) != "# spec" {
+		t.Errorf("spec.md = %q, %v", got, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("attachment from a previous car was left behind")
+	}
+
+	if n, err := WriteAttachments(gormDB, workDir, "car-none"); err != nil || n != 0 {
+		t.Errorf("car without attachments = %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, models.AttachmentsDir)); !os.IsNotExist(err) {
+		t.Error("attachments dir should be removed when the car has none")
+	}
+}
@@ -4,60 +4,160 @@ import (
 	"fmt"
 	"log/slog"
 	"math/rand/v2"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 const claimMaxRetries = 3
 
+// ClaimOpts selects the order in which ClaimCarWithOpts picks ready cars.
+type ClaimOpts struct {
+	Strategy      string        // a config.ClaimStrategy* value; defaults to priority
+	AgingInterval time.Duration // priority_aging boost interval; defaults to config.DefaultClaimAgingHours
+	CarID         string        // if set, claim only this car (e.g. the urgent car after a preemption)
+	Clock         clock.Clock   // time source for aging and claimed_at; nil uses clock.Real
+}
+
+// ClaimOptsForTrack builds ClaimOpts from a track's configuration.
+func ClaimOptsForTrack(tc *config.TrackConfig) ClaimOpts {
+	if tc == nil {
+		return ClaimOpts{}
+	}
+	return ClaimOpts{
+		Strategy:      tc.ClaimStrategy,
+		AgingInterval: time.Duration(tc.ClaimAgingHours) * time.Hour,
+	}
+}
+
 // ClaimCar atomically finds the highest-priority ready car on the given track
-// and assigns it to the engine. It uses SELECT ... FOR UPDATE SKIP LOCKED for
-// concurrency safety.
+// and assigns it to the engine. It is ClaimCarWithOpts with the default
+// (strict priority) strategy.
+func ClaimCar(db *gorm.DB, engineID, track string) (*models.Car, error) {
+	return ClaimCarWithOpts(db, engineID, track, ClaimOpts{})
+}
+
+// ClaimCarWithOpts atomically finds the next ready car on the given track,
+// ordered by opts.Strategy, and assigns it to the engine. It uses
+// SELECT ... FOR UPDATE SKIP LOCKED for concurrency safety.
 //
 // MySQL does not fully support row-level SKIP LOCKED and falls back to
 // transaction serialization. When two engines race for the same car, the loser
 // gets Error 1213 (serialization failure). We retry with jittered backoff.
-func ClaimCar(db *gorm.DB, engineID, track string) (*models.Car, error) {
+func ClaimCarWithOpts(db *gorm.DB, engineID, track string, opts ClaimOpts) (*models.Car, error) {
 	if engineID == "" {
 		return nil, fmt.Errorf("engine: engineID is required")
 	}
 	if track == "" {
 		return nil, fmt.Errorf("engine: track is required")
 	}
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = config.ClaimStrategyPriority
+	}
+	if !slices.Contains(config.ValidClaimStrategies, strategy) {
+		return nil, fmt.Errorf("engine: unknown claim strategy %q", strategy)
+	}
+	clk := clock.OrReal(opts.Clock)
+	// A paused yard hands out no new work; engines finish the car they hold.
+	if err := yard.CheckRunning(db); err != nil {
+		return nil, err
+	}
 
 	var claimed models.Car
 	var lastErr error
 
 	for attempt := range claimMaxRetries {
 		lastErr = db.Transaction(func(tx *gorm.DB) error {
-			// Subquery: car IDs that have at least one unresolved blocker.
-			blockedSub := tx.Table("car_deps").
-				Select("car_deps.car_id").
-				Joins("JOIN cars blocker ON car_deps.blocked_by = blocker.id").
-				Where("blocker.status NOT IN ?", models.ResolvedBlockerStatuses)
-
-			// Find the highest-priority ready car, locking the row.
-			// Exclude epics — they are container cars, not implementable work.
-			result := tx.Where("status = ? AND (assignee = ? OR assignee IS NULL) AND track = ? AND type != ?", "open", "", track, "epic").
-				Where("id NOT IN (?)", blockedSub).
-				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
-				Order("priority ASC, created_at ASC").
-				Limit(1).
-				Find(&claimed)
-
-			if result.Error != nil {
-				return fmt.Errorf("engine: find ready car: %w", result.Error)
+			// Ready cars: open, unassigned, on this track, not epics (container
+			// cars, not implementable work), and with no unresolved blocker.
+			// A non-empty epics restricts them to those epics' children.
+			ready := func(epics []string) *gorm.DB {
+				blockedSub := tx.Table("car_deps").
+					Select("car_deps.car_id").
+					Joins("JOIN cars blocker ON car_deps.blocked_by = blocker.id").
+					Where("blocker.status NOT IN ?", models.ResolvedBlockerStatuses)
+				q := tx.Where("status = ? AND (assignee = ? OR assignee IS NULL) AND track = ? AND type != ?", "open", "", track, "epic").
+					Where("id NOT IN (?)", blockedSub)
+				if opts.CarID != "" {
+					q = q.Where("id = ?", opts.CarID)
+				}
+				if len(epics) > 0 {
+					q = q.Where("parent_id IN ?", epics)
+				}
+				return q
+			}
+
+			pick := func(epics []string) (bool, error) {
+				if strategy == config.ClaimStrategyPriorityAging {
+					// Aging depends on wall-clock wait time, so rank candidates in
+					// Go and lock the first one no other engine holds.
+					var candidates []models.Car
+					if err := ready(epics).Select("id", "priority", "created_at").Find(&candidates).Error; err != nil {
+						return false, fmt.Errorf("engine: find ready car: %w", err)
+					}
+					for _, id := range rankByAgedPriority(candidates, opts.AgingInterval, clk.Now()) {
+						result := ready(epics).Where("id = ?", id).
+							Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+							Limit(1).
+							Find(&claimed)
+						if result.Error != nil {
+							return false, fmt.Errorf("engine: find ready car: %w", result.Error)
+						}
+						if result.RowsAffected > 0 {
+							return true, nil
+						}
+					}
+					return false, nil
+				}
+				result := ready(epics).
+					Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+					Order(claimOrder(strategy)).
+					Limit(1).
+					Find(&claimed)
+				if result.Error != nil {
+					return false, fmt.Errorf("engine: find ready car: %w", result.Error)
+				}
+				return result.RowsAffected > 0, nil
+			}
+
+			// Epics sent out by ry epic dispatch whose engine reservation on
+			// this track is not filled get their children claimed first. The
+			// reservation is soft: with none of their children ready, any
+			// ready car is claimed.
+			var found bool
+			if opts.CarID == "" {
+				epics, err := car.ReservedEpics(tx, track)
+				if err != nil {
+					return err
+				}
+				if len(epics) > 0 {
+					if found, err = pick(epics); err != nil {
+						return err
+					}
+				}
+			}
+			if !found {
+				var err error
+				if found, err = pick(nil); err != nil {
+					return err
+				}
 			}
-			if result.RowsAffected == 0 {
+			if !found {
 				return fmt.Errorf("engine: no ready cars: %w", gorm.ErrRecordNotFound)
 			}
 
 			// Update the car: status=claimed, assignee=engineID, claimed_at=now.
-			now := time.Now()
+			now := clk.Now()
 			if err := tx.Model(&models.Car{}).Where("id = ?", claimed.ID).Updates(map[string]interface{}{
 				"status":     "claimed",
 				"assignee":   engineID,
@@ -86,6 +186,7 @@ func ClaimCar(db *gorm.DB, engineID, track string) (*models.Car, error) {
 				"car", claimed.ID,
 				"track", track,
 				"priority", claimed.Priority,
+				"strategy", strategy,
 			)
 			return &claimed, nil
 		}
@@ -121,6 +222,48 @@ func ClaimCar(db *gorm.DB, engineID, track string) (*models.Car, error) {
 	return nil, fmt.Errorf("engine: claim failed after %d retries: %w", claimMaxRetries, lastErr)
 }
 
+// claimOrder returns the ORDER BY clause for a SQL-orderable claim strategy.
+func claimOrder(strategy string) string {
+	switch strategy {
+	case config.ClaimStrategyFIFO:
+		return "created_at ASC, id ASC"
+	case config.ClaimStrategySmallestFirst:
+		// Unestimated (0) cars sort after every estimated car.
+		return "CASE WHEN estimate > 0 THEN 0 ELSE 1 END ASC, estimate ASC, priority ASC, created_at ASC"
+	default:
+		return "priority ASC, created_at ASC"
+	}
+}
+
+// rankByAgedPriority orders candidate cars by effective priority, where each
+// full interval a car has waited since creation raises it one level (lower
+// number = more urgent). Ties go to the older car. Returns the ordered IDs.
+func rankByAgedPriority(cars []models.Car, interval time.Duration, now time.Time) []string {
+	if interval <= 0 {
+		interval = config.DefaultClaimAgingHours * time.Hour
+	}
+	effective := func(c models.Car) int {
+		waited := now.Sub(c.CreatedAt)
+		if waited < 0 {
+			waited = 0
+		}
+		return c.Priority - int(waited/interval)
+	}
+	sorted := slices.Clone(cars)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ei, ej := effective(sorted[i]), effective(sorted[j])
+		if ei != ej {
+			return ei < ej
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+	ids := make([]string, len(sorted))
+	for i, c := range sorted {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
 // MarkInProgress transitions a car from claimed to in_progress as the engine
 // spawns the agent subprocess, so reporting surfaces (ry status, dashboard,
 // telegraph digest) show the car as actively worked and ry complete's
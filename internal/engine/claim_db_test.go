@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/db"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -137,3 +139,168 @@ func TestClaimCar_NoReadyCars_CleanError(t *testing.T) {
 		t.Errorf("idle error should name the track, got: %v", err)
 	}
 }
+
+// seedStrategyCars creates three ready cars on "backend" whose claim order
+// differs under every strategy:
+//   - car-old: P3, created 3 days ago, unestimated
+//   - car-hot: P1, created 1 hour ago, estimate 8
+//   - car-small: P2, created 2 days ago, estimate 1
+func seedStrategyCars(t *testing.T, gormDB *gorm.DB) {
+	t.Helper()
+	now := time.Now()
+	for _, c := range []models.Car{
+		{ID: "car-old", Priority: 3, CreatedAt: now.Add(-72 * time.Hour)},
+		{ID: "car-hot", Priority: 1, Estimate: 8, CreatedAt: now.Add(-time.Hour)},
+		{ID: "car-small", Priority: 2, Estimate: 1, CreatedAt: now.Add(-48 * time.Hour)},
+	} {
+		c.Title = "test car " + c.ID
+		c.Status = "open"
+		c.Track = "backend"
+		c.UpdatedAt = now
+		if err := gormDB.Create(&c).Error; err != nil {
+			t.Fatalf("create car: %v", err)
+		}
+	}
+}
+
+func TestClaimCarWithOpts_Strategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		aging    time.Duration
+		want     string
+	}{
+		{"", 0, "car-hot"},
+		{"priority", 0, "car-hot"},
+		{"fifo", 0, "car-old"},
+		{"smallest_first", 0, "car-small"},
+		// 24h aging: old 3-3=0, small 2-2=0, hot 1-0=1; oldest of the tie wins.
+		{"priority_aging", 24 * time.Hour, "car-old"},
+		// Slow aging barely moves anything: strict priority order remains.
+		{"priority_aging", 1000 * time.Hour, "car-hot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.aging.String(), func(t *testing.T) {
+			gormDB := claimTestDB(t)
+			seedStrategyCars(t, gormDB)
+
+			c, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{Strategy: tt.strategy, AgingInterval: tt.aging})
+			if err != nil {
+				t.Fatalf("ClaimCarWithOpts: %v", err)
+			}
+			if c.ID != tt.want {
+				t.Errorf("claimed %s, want %s", c.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimCarWithOpts_AgingSkipsAssigned(t *testing.T) {
+	gormDB := claimTestDB(t)
+	seedStrategyCars(t, gormDB)
+
+	first, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{Strategy: "priority_aging", AgingInterval: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	second, err := ClaimCarWithOpts(gormDB, "eng-2", "backend", ClaimOpts{Strategy: "priority_aging", AgingInterval: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("second claim: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("both engines claimed %s", first.ID)
+	}
+}
+
+func TestClaimCarWithOpts_AgingUsesClock(t *testing.T) {
+	gormDB := claimTestDB(t)
+	seedStrategyCars(t, gormDB)
+
+	// With 48h aging car-small wins today, but a day later car-old has
+	// waited 96h and ties the other two at P1; the oldest of the tie wins.
+	later := time.Now().Add(24 * time.Hour)
+	c, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{
+		Strategy:      "priority_aging",
+		AgingInterval: 48 * time.Hour,
+		Clock:         clock.NewFake(later),
+	})
+	if err != nil {
+		t.Fatalf("ClaimCarWithOpts: %v", err)
+	}
+	if c.ID != "car-old" {
+		t.Errorf("claimed %s, want car-old", c.ID)
+	}
+	if c.ClaimedAt == nil || !c.ClaimedAt.Equal(later) {
+		t.Errorf("claimed_at = %v, want %v", c.ClaimedAt, later)
+	}
+}
+
+func TestClaimCarWithOpts_UnknownStrategy(t *testing.T) {
+	gormDB := claimTestDB(t)
+	_, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{Strategy: "random"})
+	if err == nil || !strings.Contains(err.Error(), "unknown claim strategy") {
+		t.Errorf("err = %v, want unknown claim strategy", err)
+	}
+}
+
+func TestClaimCarWithOpts_CarID(t *testing.T) {
+	gormDB := claimTestDB(t)
+	seedStrategyCars(t, gormDB)
+
+	c, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{CarID: "car-old"})
+	if err != nil {
+		t.Fatalf("ClaimCarWithOpts: %v", err)
+	}
+	if c.ID != "car-old" {
+		t.Errorf("claimed %s, want car-old", c.ID)
+	}
+
+	if _, err := ClaimCarWithOpts(gormDB, "eng-2", "backend", ClaimOpts{CarID: "car-old"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("second claim of car-old: err = %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestClaimCarWithOpts_PausedYard(t *testing.T) {
+	gormDB := claimTestDB(t)
+	createClaimTestCar(t, gormDB, "car-p1", "open", "")
+	if _, _, err := yard.Pause(gormDB, "incident", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{}); !errors.Is(err, yard.ErrPaused) {
+		t.Fatalf("claim while paused: err = %v, want ErrPaused", err)
+	}
+
+	if _, _, err := yard.Resume(gormDB); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := ClaimCarWithOpts(gormDB, "eng-1", "backend", ClaimOpts{}); err != nil || c.ID != "car-p1" {
+		t.Errorf("claim after resume = %v, %v", c, err)
+	}
+}
+
+func TestClaimCarWithOpts_ReservedEpicFirst(t *testing.T) {
+	gormDB := claimTestDB(t)
+	seedStrategyCars(t, gormDB)
+	epicID := "car-epic"
+	gormDB.Create(&models.Car{ID: epicID, Title: "Epic", Type: "epic", Track: "backend", Status: "open"})
+	for _, id := range []string{"car-child1", "car-child2"} {
+		gormDB.Create(&models.Car{ID: id, Title: id, Track: "backend", Status: "open", Priority: 4, ParentID: &epicID})
+	}
+	gormDB.Create(&models.EpicReservation{EpicID: epicID, Track: "backend", Engines: 1})
+
+	first, err := ClaimCar(gormDB, "eng-1", "backend")
+	if err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if first.ParentID == nil || *first.ParentID != epicID {
+		t.Fatalf("first claim = %s, want a child of the reserved epic", first.ID)
+	}
+	// The reservation is filled: the next engine claims by priority.
+	second, err := ClaimCar(gormDB, "eng-2", "backend")
+	if err != nil {
+		t.Fatalf("second claim: %v", err)
+	}
+	if second.ID != "car-hot" {
+		t.Errorf("second claim = %s, want car-hot", second.ID)
+	}
+}
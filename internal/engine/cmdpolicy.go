@@ -0,0 +1,599 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// CommandPolicyEnv carries a track's command policy, JSON-encoded, from the
+// engine to the PreToolUse hook the agent runs before each shell command.
+const CommandPolicyEnv = "RAILYARD_COMMAND_POLICY"
+
+// policyMarker prefixes every refusal the hook reports. The agent sees it as
+// the tool error; the engine finds it in the session output.
+const policyMarker = "railyard command policy: blocked "
+
+// CommandPolicy decides which shell commands an agent may run. See
+// config.CommandPolicyConfig for the rule syntax.
+type CommandPolicy struct {
+	Allow    []string `json:"allow,omitempty"`
+	Deny     []string `json:"deny,omitempty"`
+	BlockCar bool     `json:"-"` // engine-side only; the hook refuses commands either way
+}
+
+// NewCommandPolicy returns the policy for a track's config, or nil when the
+// track does not restrict commands.
+func NewCommandPolicy(cp *config.CommandPolicyConfig) *CommandPolicy {
+	if cp == nil {
+		return nil
+	}
+	return &CommandPolicy{Allow: cp.Allow, Deny: cp.Deny, BlockCar: cp.BlockCar}
+}
+
+// CommandPolicyFromEnv decodes the policy the engine passed in
+// CommandPolicyEnv. It returns nil when the variable is unset.
+func CommandPolicyFromEnv() (*CommandPolicy, error) {
+	raw := os.Getenv(CommandPolicyEnv)
+	if raw == "" {
+		return nil, nil
+	}
+	var p CommandPolicy
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil, fmt.Errorf("engine: decode %s: %w", CommandPolicyEnv, err)
+	}
+	return &p, nil
+}
+
+// env renders the policy as a CommandPolicyEnv assignment.
+func (p *CommandPolicy) env() string {
+	data, _ := json.Marshal(p)
+	return CommandPolicyEnv + "=" + string(data)
+}
+
+// PolicyViolation is a shell command the policy refused.
+type PolicyViolation struct {
+	Command string    // the full command line the agent tried to run
+	Reason  string    // e.g. `denied by "git push"` or "not in the allow list"
+	At      time.Time // when it was refused; zero when not known
+}
+
+// Error renders the refusal the agent sees. FindPolicyViolations parses it
+// back out of the session output.
+func (v *PolicyViolation) Error() string {
+	return policyMarker + strconv.Quote(v.Command) + " (" + v.Reason + ")"
+}
+
+// Check returns a *PolicyViolation if line, or any command within it, is
+// not allowed. Pipelines, lists, subshells, command substitutions and
+// sh -c scripts are split and each command is checked.
+func (p *CommandPolicy) Check(line string) error {
+	for _, words := range splitCommands(line) {
+		if reason := p.refuse(words); reason != "" {
+			return &PolicyViolation{Command: line, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// refuse returns why one simple command is not allowed, or "".
+func (p *CommandPolicy) refuse(words []string) string {
+	unwrapped := unwrapCommand(words)
+	for _, rule := range p.Deny {
+		if ruleMatches(rule, words) || ruleMatches(rule, unwrapped) {
+			return fmt.Sprintf("denied by %q", rule)
+		}
+	}
+	// Agents report progress and completion through ry, so it is always
+	// allowed unless explicitly denied.
+	if len(p.Allow) == 0 || path.Base(words[0]) == "ry" {
+		return ""
+	}
+	for _, rule := range p.Allow {
+		if ruleMatches(rule, words) {
+			return ""
+		}
+	}
+	return "not in the allow list"
+}
+
+// ruleMatches reports whether words start with the words of rule. The
+// program name also matches by base name.
+func ruleMatches(rule string, words []string) bool {
+	want := strings.Fields(rule)
+	if len(want) == 0 || len(words) < len(want) {
+		return false
+	}
+	if words[0] != want[0] && (strings.Contains(want[0], "/") || path.Base(words[0]) != want[0]) {
+		return false
+	}
+	for i := 1; i < len(want); i++ {
+		if words[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// commandWrappers run their arguments as a command. Deny rules see through
+// them, so denying "rm" also refuses "sudo rm".
+var commandWrappers = map[string]bool{
+	"sudo": true, "env": true, "command": true, "exec": true, "nohup": true,
+	"nice": true, "time": true, "timeout": true, "xargs": true, "doas": true,
+}
+
+// unwrapCommand strips leading wrapper programs and their options.
+func unwrapCommand(words []string) []string {
+	for len(words) > 0 && commandWrappers[path.Base(words[0])] {
+		wrapper := path.Base(words[0])
+		words = words[1:]
+		for len(words) > 0 && (strings.HasPrefix(words[0], "-") || isAssignment(words[0])) {
+			words = words[1:]
+		}
+		if wrapper == "timeout" && len(words) > 0 {
+			words = words[1:] // the duration
+		}
+	}
+	return words
+}
+
+// shellKeywords may precede a command without being its program.
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "do": true,
+	"while": true, "until": true, "!": true, "{": true, "}": true,
+	"fi": true, "done": true,
+}
+
+// splitCommands splits a shell command line into its simple commands, each
+// as a list of words with quotes removed. Leading variable assignments and
+// shell keywords are dropped. The bodies of $(...) and `...` are split
+// recursively and returned as commands of their own, and an sh -c or
+// bash -c command is replaced by the commands of its script.
+func splitCommands(line string) [][]string {
+	var (
+		cmds   [][]string
+		words  []string
+		word   strings.Builder
+		inWord bool
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		for len(words) > 0 && (isAssignment(words[0]) || shellKeywords[words[0]]) {
+			words = words[1:]
+		}
+		if script, ok := shellScript(words); ok {
+			cmds = append(cmds, splitCommands(script)...)
+		} else if len(words) > 0 {
+			cmds = append(cmds, words)
+		}
+		words = nil
+	}
+
+	rs := []rune(line)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '\\' && i+1 < len(rs):
+			i++
+			if rs[i] != '\n' {
+				word.WriteRune(rs[i])
+				inWord = true
+			}
+		case r == '\'':
+			j := i + 1
+			for j < len(rs) && rs[j] != '\'' {
+				j++
+			}
+			word.WriteString(string(rs[i+1 : min(j, len(rs))]))
+			inWord = true
+			i = j
+		case r == '"':
+			inWord = true
+			for i++; i < len(rs) && rs[i] != '"'; i++ {
+				switch {
+				case rs[i] == '\\' && i+1 < len(rs):
+					i++
+					word.WriteRune(rs[i])
+				case rs[i] == '`' || (rs[i] == '$' && i+1 < len(rs) && rs[i+1] == '('):
+					body, end := substitution(rs, i)
+					cmds = append(cmds, splitCommands(body)...)
+					word.WriteString("$()")
+					i = end
+				default:
+					word.WriteRune(rs[i])
+				}
+			}
+		case r == '`' || (r == '$' && i+1 < len(rs) && rs[i+1] == '('):
+			body, end := substitution(rs, i)
+			cmds = append(cmds, splitCommands(body)...)
+			word.WriteString("$()")
+			inWord = true
+			i = end
+		case r == '&' && i > 0 && (rs[i-1] == '>' || rs[i-1] == '<'):
+			word.WriteRune(r) // 2>&1
+			inWord = true
+		case r == ';' || r == '&' || r == '|' || r == '\n' || r == '(' || r == ')':
+			endCommand()
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endCommand()
+	return cmds
+}
+
+// substitution returns the body of the command substitution starting at
+// rs[i] — `...` or $(...) — and the index of its last rune.
+func substitution(rs []rune, i int) (string, int) {
+	if rs[i] == '`' {
+		j := i + 1
+		for j < len(rs) && rs[j] != '`' {
+			if rs[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		return string(rs[i+1 : min(j, len(rs))]), j
+	}
+	depth := 0
+	for j := i + 1; j < len(rs); j++ {
+		switch rs[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(rs[i+2 : j]), j
+			}
+		}
+	}
+	return string(rs[i+2:]), len(rs)
+}
+
+// shellScript returns the script of a `sh -c script` style command.
+func shellScript(words []string) (string, bool) {
+	if len(words) == 0 {
+		return "", false
+	}
+	switch path.Base(words[0]) {
+	case "sh", "bash", "zsh", "dash":
+	default:
+		return "", false
+	}
+	for i := 1; i+1 < len(words); i++ {
+		if words[i] == "-c" || (strings.HasPrefix(words[i], "-") && strings.HasSuffix(words[i], "c")) {
+			return words[i+1], true
+		}
+	}
+	return "", false
+}
+
+// isAssignment reports whether w is a VAR=value prefix.
+func isAssignment(w string) bool {
+	name, _, ok := strings.Cut(w, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// CommandPolicyHookName identifies the hook WriteCommandPolicyHook installs,
+// so a rewrite replaces it rather than adding another.
+const CommandPolicyHookName = "engine check-command"
+
+// WriteCommandPolicyHook installs a claude PreToolUse hook in the worktree's
+// .claude/settings.local.json that runs hookCommand before every Bash tool
+// call. Other settings and hooks in the file are preserved. The .claude
+// directory is already excluded from git (see railyardIgnoreEntries).
+func WriteCommandPolicyHook(workDir, hookCommand string) error {
+	settingsPath := filepath.Join(workDir, ".claude", "settings.local.json")
+	settings := map[string]any{}
+	if data, err := os.ReadFile(settingsPath); err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			settings = map[string]any{} // malformed JSON — start fresh
+		}
+	}
+
+	hooks, _ := settings["hooks"].(map[string]any)
+	if hooks == nil {
+		hooks = map[string]any{}
+	}
+	var pre []any
+	if existing, ok := hooks["PreToolUse"].([]any); ok {
+		for _, h := range existing {
+			if !isCommandPolicyHook(h) {
+				pre = append(pre, h)
+			}
+		}
+	}
+	pre = append(pre, map[string]any{
+		"matcher": "Bash",
+		"hooks": []any{map[string]any{
+			"type":    "command",
+			"command": hookCommand,
+		}},
+	})
+	hooks["PreToolUse"] = pre
+	settings["hooks"] = hooks
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("engine: marshal %s: %w", settingsPath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0o755); err != nil {
+		return fmt.Errorf("engine: create %s: %w", filepath.Dir(settingsPath), err)
+	}
+	if err := os.WriteFile(settingsPath, data, 0600); err != nil {
+		return fmt.Errorf("engine: write %s: %w", settingsPath, err)
+	}
+	return nil
+}
+
+// isCommandPolicyHook reports whether a PreToolUse entry is ours.
+func isCommandPolicyHook(entry any) bool {
+	m, _ := entry.(map[string]any)
+	hooks, _ := m["hooks"].([]any)
+	for _, h := range hooks {
+		hm, _ := h.(map[string]any)
+		if cmd, _ := hm["command"].(string); strings.HasSuffix(cmd, CommandPolicyHookName) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyViolationRe parses a refusal rendered by PolicyViolation.Error.
+var policyViolationRe = regexp.MustCompile(regexp.QuoteMeta(policyMarker) + `("(?:[^"\\]|\\.)*") \(((?:denied by "(?:[^"\\]|\\.)*")|not in the allow list)\)`)
+
+// FindPolicyViolations returns the commands the policy hook refused during a
+// session, in order, from the agent's logged output. The refusal usually
+// arrives JSON-encoded inside a stream-json tool result, so JSON lines are
+// decoded before matching.
+func FindPolicyViolations(logs []models.AgentLog) []PolicyViolation {
+	var out []PolicyViolation
+	seen := map[string]bool{}
+	scanText := func(text string, at time.Time) {
+		for _, m := range policyViolationRe.FindAllStringSubmatch(text, -1) {
+			cmd, err := strconv.Unquote(m[1])
+			if err != nil {
+				continue
+			}
+			// A refusal is echoed in more than one place (tool result,
+			// hook stderr); report each distinct one once.
+			key := cmd + "\x00" + m[2]
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, PolicyViolation{Command: cmd, Reason: m[2], At: at})
+		}
+	}
+
+	var carry string
+	scan := func(line string, at time.Time) {
+		if !strings.Contains(line, strings.TrimSpace(policyMarker)) {
+			return
+		}
+		var v any
+		if json.Unmarshal([]byte(line), &v) == nil {
+			walkStrings(v, func(s string) { scanText(s, at) })
+			return
+		}
+		scanText(line, at)
+	}
+	for _, l := range logs {
+		lines := strings.Split(carry+l.Content, "\n")
+		carry = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			scan(line, l.CreatedAt)
+		}
+	}
+	if carry != "" && len(logs) > 0 {
+		scan(carry, logs[len(logs)-1].CreatedAt)
+	}
+	return out
+}
+
+// walkStrings calls fn for every string in a decoded JSON value.
+func walkStrings(v any, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case []any:
+		for _, e := range v {
+			walkStrings(e, fn)
+		}
+	case map[string]any:
+		for _, e := range v {
+			walkStrings(e, fn)
+		}
+	}
+}
+
+// PolicyDetector watches a session's output for the policy hook refusing a
+// command. It fires once on Signaled(); the engine then stops the session
+// when the track blocks cars on violations.
+type PolicyDetector struct {
+	mu       sync.Mutex
+	tail     []byte // end of the previous write, for markers split across writes
+	fired    bool
+	signalCh chan struct{}
+}
+
+// NewPolicyDetector constructs a detector with a size-1 buffered signal
+// channel.
+func NewPolicyDetector() *PolicyDetector {
+	return &PolicyDetector{signalCh: make(chan struct{}, 1)}
+}
+
+// Signaled returns a channel that receives once, on the first refusal.
+func (d *PolicyDetector) Signaled() <-chan struct{} {
+	return d.signalCh
+}
+
+// Fired reports whether a refusal has been seen.
+func (d *PolicyDetector) Fired() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.fired
+}
+
+// AttachToSession feeds the session's stdout and stderr to the detector,
+// chaining on any existing onWrite callbacks like the rate-limit detector.
+func (d *PolicyDetector) AttachToSession(sess *Session) {
+	if sess == nil {
+		return
+	}
+	if sess.stdout != nil {
+		sess.stdout.chainOnWrite(d.observeOutput)
+	}
+	if sess.stderr != nil {
+		sess.stderr.chainOnWrite(d.observeOutput)
+	}
+}
+
+// observeOutput scans p for the refusal marker. Safe for concurrent use.
+func (d *PolicyDetector) observeOutput(p []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.fired || len(p) == 0 {
+		return
+	}
+	marker := []byte(strings.TrimSpace(policyMarker))
+	buf := append(d.tail, p...)
+	if !bytes.Contains(buf, marker) {
+		d.tail = append([]byte(nil), buf[max(0, len(buf)-len(marker)+1):]...)
+		return
+	}
+	d.fired = true
+	select {
+	case d.signalCh <- struct{}{}:
+	default:
+	}
+}
+
+// SessionPolicyViolations returns the commands the policy hook refused
+// during a session, from its stdout and stderr logs.
+func SessionPolicyViolations(db *gorm.DB, sessionID string) ([]PolicyViolation, error) {
+	var logs []models.AgentLog
+	if err := db.Where("session_id = ?", sessionID).Order("id").Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("engine: load agent logs: %w", err)
+	}
+	return FindPolicyViolations(logs), nil
+}
+
+// CommandBlockedTopic is the bus topic for a command the policy refused. It
+// is not a core plugin event, so plugins receive it as a custom event whose
+// payload carries engine_id, car_id, command, and reason.
+const CommandBlockedTopic = "railyard.command_blocked"
+
+// ReportPolicyViolations logs each refused command and publishes it on bus.
+func ReportPolicyViolations(bus events.Bus, engineID, carID string, violations []PolicyViolation) {
+	for _, v := range violations {
+		slog.Warn("engine: command blocked by policy",
+			"engine", engineID, "car", carID, "command", v.Command, "reason", v.Reason)
+		publish(bus, CommandBlockedTopic, map[string]any{
+			"engine_id": engineID,
+			"car_id":    carID,
+			"command":   v.Command,
+			"reason":    v.Reason,
+		})
+	}
+}
+
+// CommandPolicyBlockOpts holds parameters for blocking a car on a command
+// policy violation.
+type CommandPolicyBlockOpts struct {
+	RepoDir    string
+	Branch     string
+	SessionID  string
+	Violations []PolicyViolation
+}
+
+// HandleCommandPolicyBlock blocks a car whose agent tried a command the
+// track's policy forbids, for tracks with command_policy.block_car. The
+// branch is pushed so the work survives, a progress note records the
+// commands, and a human is asked to review them; the car stays blocked
+// until they reopen it.
+func HandleCommandPolicyBlock(db *gorm.DB, engineID, carID string, opts CommandPolicyBlockOpts) error {
+	if opts.Branch != "" && opts.RepoDir != "" {
+		if err := PushBranch(opts.RepoDir, opts.Branch); err != nil {
+			slog.Warn("engine: command policy push warning (non-fatal)", "car", carID, "error", err)
+		}
+	}
+
+	var lines []string
+	for _, v := range opts.Violations {
+		lines = append(lines, "  "+v.Command+" ("+v.Reason+")")
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  (the command was not captured in the session log)")
+	}
+	commands := strings.Join(lines, "\n")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Car{}).Where("id = ?", carID).
+			Updates(map[string]interface{}{
+				"status":         "blocked",
+				"blocked_reason": models.BlockedReasonCommandPolicy,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("engine: mark car blocked %s: %w", carID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("engine: car %s not found", carID)
+		}
+
+		if err := tx.Create(&models.CarProgress{
+			CarID:        carID,
+			EngineID:     engineID,
+			SessionID:    opts.SessionID,
+			Note:         "Stopped by the track's command policy:\n" + commands,
+			FilesChanged: "[]",
+			CreatedAt:    time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("engine: write progress note: %w", err)
+		}
+
+		body := fmt.Sprintf("Car %s was stopped: its agent tried to run commands the track's command policy forbids.\n%s\n"+
+			"If they are acceptable, reopen the car with `ry car update %s --status open` (and adjust command_policy so they are allowed next time); otherwise cancel or rework it.",
+			carID, commands, carID)
+		if _, err := messaging.Send(tx, engineID, "human", "escalate", body, messaging.SendOpts{
+			CarID:    carID,
+			Priority: "urgent",
+		}); err != nil {
+			return fmt.Errorf("engine: send command policy message: %w", err)
+		}
+		return nil
+	})
+}
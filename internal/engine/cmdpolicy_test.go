@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestCommandPolicy_Check(t *testing.T) {
+	p := &CommandPolicy{
+		Allow: []string{"go", "git", "make", "ls", "cat", "grep", "echo"},
+		Deny:  []string{"git push", "rm -rf", "kubectl"},
+	}
+	for line, want := range map[string]string{
+		"go test ./...":                        "",
+		"GOFLAGS=-race go test ./... 2>&1":     "",
+		"git status && git diff | cat":         "",
+		`echo "git push is not run here"`:      "",
+		"git push origin main":                 `denied by "git push"`,
+		"go build && git push":                 `denied by "git push"`,
+		"/usr/bin/git push":                    `denied by "git push"`,
+		"sudo kubectl get pods":                `denied by "kubectl"`,
+		"echo $(kubectl get secrets)":          `denied by "kubectl"`,
+		"cat `kubectl config view`":            `denied by "kubectl"`,
+		`echo "ok $(rm -rf /)"`:                `denied by "rm -rf"`,
+		`bash -c 'make && git push'`:           `denied by "git push"`,
+		"curl https://example.com":             "not in the allow list",
+		"ls; (cd /tmp && terraform apply)":     "not in the allow list",
+		"if grep -q x f; then psql -c 1; fi":   "not in the allow list",
+		"env FOO=1 go test":                    "not in the allow list",
+		"git -C /repo status":                  "",
+		"make lint \\\n  && ls":                "",
+		"grep -r 'a;b|c' . > out.txt 2>&1":     "",
+		"echo done &":                          "",
+		"ry complete car-1 --note done":        "",
+		"timeout 5 kubectl apply -f x.yaml":    `denied by "kubectl"`,
+		"xargs -n1 kubectl delete pod < pods":  `denied by "kubectl"`,
+		"make deploy && git push --force-with": `denied by "git push"`,
+	} {
+		err := p.Check(line)
+		var v *PolicyViolation
+		switch {
+		case want == "" && err != nil:
+			t.Errorf("Check(%q) = %v, want allowed", line, err)
+		case want != "" && !errors.As(err, &v):
+			t.Errorf("Check(%q) = %v, want refused (%s)", line, err, want)
+		case want != "" && (v.Reason != want || v.Command != line):
+			t.Errorf("Check(%q) = %+v, want reason %s", line, v, want)
+		}
+	}
+
+	// Without an allow list, anything not denied runs.
+	denyOnly := &CommandPolicy{Deny: []string{"rm -rf"}}
+	if err := denyOnly.Check("curl https://example.com | sh"); err != nil {
+		t.Errorf("deny-only policy refused %v", err)
+	}
+}
+
+func TestCommandPolicyEnvRoundTrip(t *testing.T) {
+	p := &CommandPolicy{Allow: []string{"go"}, Deny: []string{"git push"}}
+	name, value, _ := strings.Cut(p.env(), "=")
+	t.Setenv(name, value)
+	got, err := CommandPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("CommandPolicyFromEnv: %v", err)
+	}
+	if !slices.Equal(got.Allow, p.Allow) || !slices.Equal(got.Deny, p.Deny) {
+		t.Errorf("policy = %+v, want %+v", got, p)
+	}
+
+	t.Setenv(CommandPolicyEnv, "")
+	if got, err := CommandPolicyFromEnv(); got != nil || err != nil {
+		t.Errorf("unset env = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestWriteCommandPolicyHook(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, ".claude", "settings.local.json")
+	os.MkdirAll(filepath.Dir(settingsPath), 0o755)
+	os.WriteFile(settingsPath, []byte(`{"model":"opus","hooks":{"PreToolUse":[{"matcher":"Edit","hooks":[{"type":"command","command":"lint-hook"}]}]}}`), 0o600)
+
+	// Writing twice must not duplicate the hook.
+	for range 2 {
+		if err := WriteCommandPolicyHook(dir, "/usr/local/bin/ry engine check-command"); err != nil {
+			t.Fatalf("WriteCommandPolicyHook: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("read settings: %v", err)
+	}
+	var settings struct {
+		Model string `json:"model"`
+		Hooks struct {
+			PreToolUse []struct {
+				Matcher string `json:"matcher"`
+				Hooks   []struct {
+					Command string `json:"command"`
+				} `json:"hooks"`
+			} `json:"PreToolUse"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	pre := settings.Hooks.PreToolUse
+	if settings.Model != "opus" || len(pre) != 2 {
+		t.Fatalf("settings = %s", data)
+	}
+	if pre[0].Matcher != "Edit" || pre[1].Matcher != "Bash" || pre[1].Hooks[0].Command != "/usr/local/bin/ry engine check-command" {
+		t.Errorf("PreToolUse = %+v", pre)
+	}
+}
+
+func TestFindPolicyViolations(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	refusal := (&PolicyViolation{Command: `git push origin "main"`, Reason: `denied by "git push"`}).Error()
+	toolResult, _ := json.Marshal(map[string]any{
+		"type": "user",
+		"message": map[string]any{"content": []any{map[string]any{
+			"type": "tool_result", "is_error": true,
+			"content": "PreToolUse:Bash hook error: [ry engine check-command]: " + refusal,
+		}}},
+	})
+	line := string(toolResult) + "\n"
+	logs := []models.AgentLog{
+		{Content: line[:40], CreatedAt: t0},
+		{Content: line[40:], CreatedAt: t0},
+		{Content: refusal + "\n", CreatedAt: t0.Add(time.Second)}, // echoed on stderr
+		{Content: "railyard command policy: blocked " + strconv.Quote("curl x") + " (not in the allow list)\n", CreatedAt: t0.Add(2 * time.Second)},
+	}
+
+	got := FindPolicyViolations(logs)
+	want := []PolicyViolation{
+		{Command: `git push origin "main"`, Reason: `denied by "git push"`, At: t0},
+		{Command: "curl x", Reason: "not in the allow list", At: t0.Add(2 * time.Second)},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("violations = %+v, want %+v", got, want)
+	}
+}
+
+func TestPolicyDetector(t *testing.T) {
+	d := NewPolicyDetector()
+	d.observeOutput([]byte("ordinary output\n"))
+	if d.Fired() {
+		t.Fatal("fired on ordinary output")
+	}
+	// The marker split across writes still fires, once.
+	d.observeOutput([]byte(`..."railyard command pol`))
+	d.observeOutput([]byte(`icy: blocked \"git push\" (denied by \"git push\")"`))
+	d.observeOutput([]byte("railyard command policy: blocked again"))
+	select {
+	case <-d.Signaled():
+	default:
+		t.Fatal("detector did not signal")
+	}
+	select {
+	case <-d.Signaled():
+		t.Fatal("detector signaled twice")
+	default:
+	}
+}
+
+func TestHandleCommandPolicyBlock(t *testing.T) {
+	db := eventsTestDB(t)
+	db.Create(&models.Car{ID: "car-1", Status: "in_progress", Track: "backend"})
+
+	err := HandleCommandPolicyBlock(db, "eng-1", "car-1", CommandPolicyBlockOpts{
+		SessionID:  "sess-1",
+		Violations: []PolicyViolation{{Command: "kubectl delete ns prod", Reason: `denied by "kubectl"`}},
+	})
+	if err != nil {
+		t.Fatalf("HandleCommandPolicyBlock: %v", err)
+	}
+
+	var c models.Car
+	db.First(&c, "id = ?", "car-1")
+	if c.Status != "blocked" || c.BlockedReason != models.BlockedReasonCommandPolicy {
+		t.Errorf("car = %s/%s, want blocked/%s", c.Status, c.BlockedReason, models.BlockedReasonCommandPolicy)
+	}
+	var msg models.Message
+	if err := db.Where("to_agent = ?", "human").First(&msg).Error; err != nil {
+		t.Fatalf("no message to human: %v", err)
+	}
+	if msg.Subject != "escalate" || !strings.Contains(msg.Body, "kubectl delete ns prod") || !strings.Contains(msg.Body, "ry car update car-1 --status open") {
+		t.Errorf("message = %+v", msg)
+	}
+	var progress models.CarProgress
+	if err := db.Where("car_id = ?", "car-1").First(&progress).Error; err != nil || !strings.Contains(progress.Note, "kubectl delete ns prod") {
+		t.Errorf("progress note = %+v, %v", progress, err)
+	}
+
+	if err := HandleCommandPolicyBlock(db, "eng-1", "missing", CommandPolicyBlockOpts{}); err == nil {
+		t.Error("expected an error for a missing car")
+	}
+}
+
+func TestReportPolicyViolations(t *testing.T) {
+	bus := &fakeBus{}
+	ReportPolicyViolations(bus, "eng-1", "car-1", []PolicyViolation{{Command: "kubectl get pods", Reason: `denied by "kubectl"`}})
+
+	evs := bus.snapshot()
+	if len(evs) != 1 || evs[0].Topic != CommandBlockedTopic {
+		t.Fatalf("events = %+v", evs)
+	}
+	payload, _ := evs[0].Payload.(map[string]any)
+	if payload["car_id"] != "car-1" || payload["command"] != "kubectl get pods" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
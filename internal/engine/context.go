@@ -19,9 +19,12 @@ type ContextInput struct {
 	Config        *config.Config
 	Progress      []models.CarProgress
 	Messages      []models.Message
-	RecentCommits []string // pre-fetched "git log --oneline" lines
-	EngineID      string   // engine identifier, used for co-author trailer
-	RepoDir       string   // path to the engine's workdir/repo, used to check
+	Notes         []models.TrackNote // the track's shared notes, oldest first
+	PriorCars     []PriorCar         // related recently merged cars (RelatedPriorCars)
+	TrackContext  bool               // TrackContextFile was written to the worktree
+	RecentCommits []string           // pre-fetched "git log --oneline" lines
+	EngineID      string             // engine identifier, used for co-author trailer
+	RepoDir       string             // path to the engine's workdir/repo, used to check
 	// for the existence of a Playwright template file.
 }
 
@@ -40,14 +43,19 @@ func RenderContext(input ContextInput) (string, error) {
 	var w strings.Builder
 	writeHeader(&w, input.Track, input.Config)
 	writeConventions(&w, input.Track)
+	writeNotes(&w, input.Notes)
+	writeTrackContextPointer(&w, input.TrackContext)
 	writeCurrentCar(&w, input.Car)
+	writeConflictGuide(&w, input.Car)
+	writePriorCars(&w, input.PriorCars)
 	writeProgress(&w, input.Progress)
 	writeMessages(&w, input.Messages)
 	writeRecentCommits(&w, input.RecentCommits)
+	writeDiffLimit(&w, resolveDiffLimit(input.Track, input.Config))
 	if section := playwrightSection(resolvePlaywrightConfig(input.Track, input.Config), input.Car.ID, input.RepoDir); section != "" {
 		w.WriteString(section)
 	}
-	writeInstructions(&w, input.EngineID, input.Car.BaseBranch)
+	writeInstructions(&w, input.EngineID, input.Car.BaseBranch, input.Track.Name)
 	return w.String(), nil
 }
 
@@ -67,6 +75,39 @@ func resolvePlaywrightConfig(track *models.Track, cfg *config.Config) *models.Pl
 	return nil
 }
 
+// resolveDiffLimit returns the diff_limit of the given track from the
+// supplied Config (matched by Name), or nil when the track has none.
+func resolveDiffLimit(track *models.Track, cfg *config.Config) *config.DiffLimitConfig {
+	for i := range cfg.Tracks {
+		if cfg.Tracks[i].Name == track.Name {
+			return cfg.Tracks[i].DiffLimit
+		}
+	}
+	return nil
+}
+
+// writeDiffLimit tells the engine how much one car may change before the
+// yardmaster defers its merge and has the car split.
+func writeDiffLimit(w *strings.Builder, dl *config.DiffLimitConfig) {
+	if dl == nil {
+		return
+	}
+	var limits []string
+	if dl.MaxFiles > 0 {
+		limits = append(limits, fmt.Sprintf("%d files", dl.MaxFiles))
+	}
+	if dl.MaxLines > 0 {
+		limits = append(limits, fmt.Sprintf("%d lines added plus deleted", dl.MaxLines))
+	}
+	w.WriteString("## Diff Size Limit\n")
+	fmt.Fprintf(w, "This track keeps each car reviewable: your branch may change at most %s against its base", strings.Join(limits, " and "))
+	if len(dl.Exclude) > 0 {
+		fmt.Fprintf(w, " (not counting %s)", strings.Join(dl.Exclude, ", "))
+	}
+	w.WriteString(".\n")
+	w.WriteString("A larger branch is not merged; the car is blocked until it is split. Stay within the car's scope, and if the work cannot fit, create child cars for the rest (see \"If You Need to Split Work\").\n\n")
+}
+
 // playwrightSection renders the "Required: Playwright PR Demo" markdown section
 // for engines working on a Playwright-enabled track. Returns "" when pw is nil
 // or disabled so callers can omit the section without trailing blank lines.
@@ -124,6 +165,27 @@ func writeConventions(w *strings.Builder, track *models.Track) {
 	w.WriteString("or frameworks from other projects. Follow the conventions above exactly.\n\n")
 }
 
+func writeNotes(w *strings.Builder, notes []models.TrackNote) {
+	if len(notes) == 0 {
+		return
+	}
+	w.WriteString("## Track Notes\n")
+	w.WriteString("Facts earlier engines and operators recorded for this track. Treat them as hints and verify before relying on them.\n\n")
+	for _, n := range notes {
+		fmt.Fprintf(w, "### Note %d (%s, %s)\n", n.ID, n.Author, n.CreatedAt.Format("2006-01-02"))
+		writeUserContent(w, n.Content)
+	}
+	w.WriteString("\n")
+}
+
+func writeTrackContextPointer(w *strings.Builder, written bool) {
+	if !written {
+		return
+	}
+	w.WriteString("## Track Context\n")
+	fmt.Fprintf(w, "%s in your worktree collects this track's conventions, notes, epics in progress, and recently merged cars. Read it before you start; it is regenerated for each car, so do not edit or commit it.\n\n", TrackContextFile)
+}
+
 func writeCurrentCar(w *strings.Builder, car *models.Car) {
 	w.WriteString("## Your Current Car\n")
 	fmt.Fprintf(w, "Car: %s\n", car.ID)
@@ -139,6 +201,25 @@ func writeCurrentCar(w *strings.Builder, car *models.Car) {
 	w.WriteString("\n")
 }
 
+// writeConflictGuide tells the engine on a conflict car (spawned by the
+// yardmaster when another car's merge conflicted) how to finish the merge
+// already committed on its branch.
+func writeConflictGuide(w *strings.Builder, car *models.Car) {
+	if car.Type != "conflict" {
+		return
+	}
+	baseBranch := car.BaseBranch
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+	w.WriteString("## Resolving a Merge Conflict\n")
+	fmt.Fprintf(w, "This car resolves the merge conflict of car %s. The last commit on your branch merges `%s` into that car's work with the conflict markers (`<<<<<<<`, `=======`, `>>>>>>>`) still in the files.\n\n", car.ConflictOf, baseBranch)
+	w.WriteString("1. List the conflicted files: `git grep -l -e '^<<<<<<< ' -e '^>>>>>>> '`\n")
+	fmt.Fprintf(w, "2. Resolve each conflict so both sides' changes survive: the car's work and what landed on `%s`. Do not take one side wholesale.\n", baseBranch)
+	w.WriteString("3. Remove every marker, then build and run the tests.\n")
+	w.WriteString("4. Commit the resolution as a new commit. Do NOT rebase, reset, or amend the existing history.\n\n")
+}
+
 func writeProgress(w *strings.Builder, progress []models.CarProgress) {
 	if len(progress) == 0 {
 		return
@@ -182,7 +263,7 @@ func writeRecentCommits(w *strings.Builder, commits []string) {
 	w.WriteString("\n")
 }
 
-func writeInstructions(w *strings.Builder, engineID, baseBranch string) {
+func writeInstructions(w *strings.Builder, engineID, baseBranch, track string) {
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
@@ -225,6 +306,12 @@ func writeInstructions(w *strings.Builder, engineID, baseBranch string) {
 	w.WriteString("1. Create child cars: `ry car create --title \"sub-task\" --track <track> --parent <car-id> --type task`\n")
 	w.WriteString("2. Continue on the current car, children will be picked up by other engines\n")
 
+	w.WriteString("\n## If You Learn Something Reusable\n")
+	w.WriteString("If you discover a fact that would save the next engine on this track time (a required env var, a flaky test, a non-obvious build step), record it as a track note:\n")
+	w.WriteString("```\n")
+	fmt.Fprintf(w, "ry note add --track %s --from <engine-id> --car <car-id> \"tests require FOO_ENV=1\"\n", track)
+	w.WriteString("```\n")
+	w.WriteString("Keep notes to a sentence or two about the project, not about this car. Notes appear in every future prompt on the track.\n")
 	w.WriteString("\n## If You Discover a Bug\n")
 	w.WriteString("If you find a bug or issue **outside** your car's scope (code you didn't write, ")
 	w.WriteString("a different module, a broken dependency, a security issue, or a previously completed car ")
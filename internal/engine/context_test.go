@@ -91,6 +91,56 @@ func TestRenderContext_Header(t *testing.T) {
 	}
 }
 
+func TestRenderContext_ConflictGuide(t *testing.T) {
+	out, err := RenderContext(makeInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "## Resolving a Merge Conflict") {
+		t.Error("ordinary car got the conflict guide")
+	}
+
+	in := makeInput()
+	in.Car.Type = "conflict"
+	in.Car.ConflictOf = "car-orig"
+	in.Car.BaseBranch = "develop"
+	out, err = RenderContext(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Resolving a Merge Conflict",
+		"merge conflict of car car-orig",
+		"merges `develop` into",
+		"Do NOT rebase",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("conflict guide missing %q", want)
+		}
+	}
+}
+
+func TestRenderContext_DiffLimit(t *testing.T) {
+	out, err := RenderContext(makeInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "## Diff Size Limit") {
+		t.Error("track without diff_limit got the diff limit section")
+	}
+
+	in := makeInput()
+	in.Config.Tracks = []config.TrackConfig{{Name: "backend", DiffLimit: &config.DiffLimitConfig{MaxLines: 800, Exclude: []string{"go.sum"}}}}
+	out, err = RenderContext(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "at most 800 lines added plus deleted against its base (not counting go.sum)."
+	if !strings.Contains(out, "## Diff Size Limit") || !strings.Contains(out, want) {
+		t.Errorf("diff limit section missing %q:\n%s", want, out)
+	}
+}
+
 func TestRenderContext_Conventions(t *testing.T) {
 	out, err := RenderContext(makeInput())
 	if err != nil {
@@ -229,6 +279,34 @@ func TestRenderContext_MessagesEmpty(t *testing.T) {
 	}
 }
 
+func TestRenderContext_Notes(t *testing.T) {
+	input := makeInput()
+	input.Notes = []models.TrackNote{
+		{ID: 7, Track: "backend", Author: "eng-abc", Content: "tests require FOO_ENV=1", CreatedAt: time.Date(2026, 2, 14, 10, 30, 0, 0, time.UTC)},
+	}
+	out, err := RenderContext(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Track Notes",
+		"### Note 7 (eng-abc, 2026-02-14)",
+		"<user-content>\ntests require FOO_ENV=1\n</user-content>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("notes missing %q", want)
+		}
+	}
+	if strings.Index(out, "## Track Notes") > strings.Index(out, "## Your Current Car") {
+		t.Error("notes should come before the current car")
+	}
+
+	out, _ = RenderContext(makeInput())
+	if strings.Contains(out, "## Track Notes") {
+		t.Error("notes section should be omitted when empty")
+	}
+}
+
 func TestRenderContext_RecentCommits(t *testing.T) {
 	input := makeInput()
 	input.RecentCommits = []string{
@@ -738,3 +816,16 @@ func TestRenderContext_PlaywrightNoMatchingTrack(t *testing.T) {
 		t.Error("expected no Playwright section when configured track does not match input.Track.Name")
 	}
 }
+
+func TestRenderContext_TrackContextPointer(t *testing.T) {
+	input := makeInput()
+	out, _ := RenderContext(input)
+	if strings.Contains(out, TrackContextFile) {
+		t.Error("pointer rendered without a track context file")
+	}
+	input.TrackContext = true
+	out, _ = RenderContext(input)
+	if !strings.Contains(out, "## Track Context") || !strings.Contains(out, TrackContextFile) {
+		t.Errorf("context missing the TRACK.md pointer:\n%s", out)
+	}
+}
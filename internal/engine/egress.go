@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// egressDialTimeout bounds how long the proxy waits to reach an allowed host.
+const egressDialTimeout = 10 * time.Second
+
+// EgressDenial is one request the egress proxy refused.
+type EgressDenial struct {
+	Host string
+	At   time.Time
+}
+
+// EgressProxy is an HTTP proxy that only lets sandboxed agents reach
+// allow-listed hosts. HTTPS goes through CONNECT tunnels, so the proxy sees
+// the host name but never the traffic. Refused hosts are recorded for the
+// session's violation report.
+type EgressProxy struct {
+	allow     []string
+	ln        net.Listener
+	srv       *http.Server
+	transport *http.Transport
+
+	mu     sync.Mutex
+	denied []EgressDenial
+}
+
+// StartEgressProxy listens on a loopback port and serves until Close.
+func StartEgressProxy(allow []string) (*EgressProxy, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("engine: egress proxy: %w", err)
+	}
+	p := &EgressProxy{
+		allow:     allow,
+		ln:        ln,
+		transport: &http.Transport{Proxy: nil, DialContext: (&net.Dialer{Timeout: egressDialTimeout}).DialContext},
+	}
+	p.srv = &http.Server{Handler: p, ReadHeaderTimeout: egressDialTimeout}
+	go func() {
+		if err := p.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("engine: egress proxy stopped", "error", err)
+		}
+	}()
+	return p, nil
+}
+
+// URL is the proxy address for HTTP_PROXY and HTTPS_PROXY.
+func (p *EgressProxy) URL() string {
+	return "http://" + p.ln.Addr().String()
+}
+
+// Denials returns the requests refused so far.
+func (p *EgressProxy) Denials() []EgressDenial {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]EgressDenial(nil), p.denied...)
+}
+
+// Close stops the proxy. Open tunnels end when the agent's side closes.
+func (p *EgressProxy) Close() error {
+	p.transport.CloseIdleConnections()
+	return p.srv.Close()
+}
+
+// ServeHTTP implements http.Handler.
+func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+	if r.Method == http.MethodConnect {
+		host, _, _ = net.SplitHostPort(r.Host)
+	}
+	if !HostAllowed(p.allow, host) {
+		p.mu.Lock()
+		p.denied = append(p.denied, EgressDenial{Host: host, At: time.Now()})
+		p.mu.Unlock()
+		http.Error(w, "railyard sandbox: egress to "+host+" is not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+// tunnel splices the client connection to the target of a CONNECT request.
+func (p *EgressProxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	dst, err := net.DialTimeout("tcp", r.Host, egressDialTimeout)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		dst.Close()
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	src, buf, err := hj.Hijack()
+	if err != nil {
+		dst.Close()
+		return
+	}
+	if _, err := src.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		src.Close()
+		dst.Close()
+		return
+	}
+	go func() {
+		defer dst.Close()
+		io.Copy(dst, buf)
+	}()
+	go func() {
+		defer src.Close()
+		io.Copy(src, dst)
+	}()
+}
+
+// forward relays a plain-HTTP proxy request.
+func (p *EgressProxy) forward(w http.ResponseWriter, r *http.Request) {
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Connection")
+	out.Header.Del("Proxy-Authorization")
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// HostAllowed reports whether host matches one of the allow patterns: an
+// exact host name, or "*.domain" for any subdomain of domain.
+func HostAllowed(allow []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, a := range allow {
+		a = strings.ToLower(a)
+		if suffix, ok := strings.CutPrefix(a, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == a {
+			return true
+		}
+	}
+	return false
+}
@@ -28,6 +28,9 @@ type RegisterOpts struct {
 	PodName   string
 	SessionID string
 	Provider  string // agent provider name (e.g., "claude", "codex")
+	// AgentBinary is the agent CLI the engine runs when it overrides the
+	// provider default; recorded so a rollout can roll the engine back.
+	AgentBinary string
 }
 
 // GenerateID creates a unique engine ID in eng-xxxxxxxx format (8-char hex).
@@ -81,6 +84,7 @@ func Register(db *gorm.DB, opts RegisterOpts) (*models.Engine, error) {
 		Status:       StatusIdle,
 		SessionID:    opts.SessionID,
 		Provider:     opts.Provider,
+		AgentBinary:  opts.AgentBinary,
 		StartedAt:    now,
 		LastActivity: now,
 	}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -9,6 +10,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/zulandar/railyard/internal/config"
 )
 
 // cleanExcludes lists untracked files that git clean should preserve in worktrees.
@@ -65,6 +68,74 @@ func DetectBaseBranch(repoDir, defaultBranch string) string {
 	return "main"
 }
 
+// TrackBaseBranch returns the base branch for new cars on a track. A
+// track's own default_branch wins; a track with its own repo otherwise uses
+// the branch its clone checked out (the remote's default), since the main
+// repository's current branch says nothing about it. Other tracks use
+// DetectBaseBranch on repoDir with the top-level default_branch.
+func TrackBaseBranch(cfg *config.Config, repoDir, track string) string {
+	for _, t := range cfg.Tracks {
+		if t.Name == track && t.DefaultBranch != "" {
+			return t.DefaultBranch
+		}
+	}
+	if cfg.HasOwnRepo(track) {
+		return DetectBaseBranch(cfg.RepoDirFor(repoDir, track), "")
+	}
+	return DetectBaseBranch(repoDir, cfg.DefaultBranch)
+}
+
+// EnsureTrackRepo returns the local checkout a track's worktrees are made
+// from, cloning the track's repo under .railyard/repos/<track> the first
+// time it is needed. Tracks without their own repo use repoDir. The clone is
+// made in a temporary directory and renamed into place, so engines starting
+// together on the same track do not clone over each other.
+func EnsureTrackRepo(cfg *config.Config, repoDir, track string) (string, error) {
+	if !cfg.HasOwnRepo(track) {
+		return repoDir, nil
+	}
+	dir := cfg.RepoDirFor(repoDir, track)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return dir, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", fmt.Errorf("engine: create track repos dir: %w", err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+track+"-clone-")
+	if err != nil {
+		return "", fmt.Errorf("engine: clone track %q: %w", track, err)
+	}
+	defer os.RemoveAll(tmp)
+
+	cmd := exec.Command("git", "clone", "--quiet", CloneURL(cfg.RepoFor(track)), tmp)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("engine: clone repo for track %q: %s", track, strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		// Another engine finished cloning first; use its clone.
+		if _, statErr := os.Stat(filepath.Join(dir, ".git")); statErr == nil {
+			return dir, nil
+		}
+		return "", fmt.Errorf("engine: clone repo for track %q: %w", track, err)
+	}
+	return dir, nil
+}
+
+// CloneURL turns a repo setting into something git clone accepts: URLs and
+// local paths pass through, an owner/repo slug becomes a GitHub HTTPS URL.
+func CloneURL(repo string) string {
+	if strings.Contains(repo, "://") || strings.Contains(repo, "@") || filepath.IsAbs(repo) {
+		return repo
+	}
+	if _, err := os.Stat(repo); err == nil {
+		return repo
+	}
+	if owner, name, err := config.ParseGitHubRepo(repo); err == nil {
+		return fmt.Sprintf("https://github.com/%s/%s.git", owner, name)
+	}
+	return repo
+}
+
 // EnsureWorktree creates a git worktree at .railyard/engines/<engineID> if it doesn't exist.
 // Returns the absolute path to the worktree directory.
 func EnsureWorktree(repoDir, engineID string) (string, error) {
@@ -146,22 +217,55 @@ func CleanupWorktrees(repoDir string) error {
 	return nil
 }
 
+// ResetOpts tunes ResetWorktreeWithOpts for large repositories.
+type ResetOpts struct {
+	// PreservePaths are untracked paths (git clean -e patterns, e.g.
+	// "node_modules/" or ".cache/") left in place so dependency and build
+	// caches survive between cars.
+	PreservePaths []string
+	// CheckoutWorkers, when > 1, enables git's parallel checkout
+	// (checkout.workers) for the hard reset.
+	CheckoutWorkers int
+}
+
+// ResetOptsForTrack builds ResetOpts from a track's configuration.
+func ResetOptsForTrack(tc *config.TrackConfig) ResetOpts {
+	if tc == nil || tc.WorktreeReset == nil {
+		return ResetOpts{}
+	}
+	return ResetOpts{
+		PreservePaths:   tc.WorktreeReset.PreservePaths,
+		CheckoutWorkers: tc.WorktreeReset.CheckoutWorkers,
+	}
+}
+
 // ResetWorktree resets an engine's worktree to a clean state at origin/{baseBranch}
 // (or local {baseBranch} if no remote). This must be called before CreateBranch when
 // starting a new car to avoid merge conflicts from stale state.
 // If baseBranch is empty, defaults to "main".
 func ResetWorktree(wtDir, baseBranch string) error {
+	return ResetWorktreeWithOpts(wtDir, baseBranch, ResetOpts{})
+}
+
+// ResetWorktreeWithOpts is ResetWorktree with cache paths to preserve and
+// parallel checkout. Only the base branch is fetched, and the hard reset
+// rewrites only the files that differ from the target, so on a worktree
+// already near the base the cost is dominated by git clean walking untracked
+// directories — which PreservePaths skips. Each step's duration is logged.
+func ResetWorktreeWithOpts(wtDir, baseBranch string, opts ResetOpts) error {
 	if wtDir == "" {
 		return fmt.Errorf("engine: worktree directory is required")
 	}
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
+	start := time.Now()
 
-	// Step 1: Fetch origin to get latest refs. Non-fatal if no remote.
-	fetch := exec.Command("git", "fetch", "origin")
+	// Step 1: Fetch the base branch from origin. Non-fatal if no remote.
+	fetch := exec.Command("git", "fetch", "origin", baseBranch)
 	fetch.Dir = wtDir
 	fetch.CombinedOutput() // ignore error — local-only repos have no remote
+	fetched := time.Now()
 
 	// Step 2: Detach HEAD so we're not on any branch.
 	detach := exec.Command("git", "checkout", "--detach", "HEAD")
@@ -170,12 +274,18 @@ func ResetWorktree(wtDir, baseBranch string) error {
 		return fmt.Errorf("engine: detach HEAD: %s: %w", strings.TrimSpace(string(out)), err)
 	}
 
-	// Step 3: Remove untracked files and directories (preserving railyard files).
-	clean := exec.Command("git", gitCleanArgs()...)
+	// Step 3: Remove untracked files and directories (preserving railyard
+	// files and the configured cache paths).
+	cleanArgs := gitCleanArgs()
+	for _, p := range opts.PreservePaths {
+		cleanArgs = append(cleanArgs, "-e", p)
+	}
+	clean := exec.Command("git", cleanArgs...)
 	clean.Dir = wtDir
 	if out, err := clean.CombinedOutput(); err != nil {
 		return fmt.Errorf("engine: git clean: %s: %w", strings.TrimSpace(string(out)), err)
 	}
+	cleaned := time.Now()
 
 	// Step 4: Hard reset to origin/{baseBranch} (fall back to local {baseBranch}).
 	target := "origin/" + baseBranch
@@ -185,13 +295,24 @@ func ResetWorktree(wtDir, baseBranch string) error {
 		target = baseBranch
 	}
 
-	reset := exec.Command("git", "reset", "--hard", target)
+	var resetArgs []string
+	if opts.CheckoutWorkers > 1 {
+		resetArgs = append(resetArgs, "-c", fmt.Sprintf("checkout.workers=%d", opts.CheckoutWorkers))
+	}
+	resetArgs = append(resetArgs, "reset", "--hard", target)
+	reset := exec.Command("git", resetArgs...)
 	reset.Dir = wtDir
 	if out, err := reset.CombinedOutput(); err != nil {
 		return fmt.Errorf("engine: reset to %s: %s: %w", target, strings.TrimSpace(string(out)), err)
 	}
+	done := time.Now()
 
 	writeClaudeIgnore(wtDir)
+	slog.Info("engine: worktree reset", "dir", wtDir, "target", target,
+		"fetch", fetched.Sub(start).Round(time.Millisecond),
+		"clean", cleaned.Sub(fetched).Round(time.Millisecond),
+		"reset", done.Sub(cleaned).Round(time.Millisecond),
+		"total", done.Sub(start).Round(time.Millisecond))
 	return nil
 }
 
@@ -230,7 +351,16 @@ func CreateBranch(repoDir, branchName, baseBranch string) error {
 	return fmt.Errorf("engine: create branch %q: %s", branchName, strings.TrimSpace(string(out)))
 }
 
-// PushBranch pushes a branch to origin, retrying once on failure.
+// ErrPushRejected is matched (via errors.Is) by the error PushBranch returns
+// when origin refused the push because its branch has commits the local
+// branch lacks, i.e. it was rewritten or pushed to from elsewhere.
+var ErrPushRejected = errors.New("push rejected: remote branch has diverged")
+
+// PushBranch pushes a branch to origin, retrying once on failure. It never
+// force-pushes: the explicit refspec carries no "+", so neither the caller
+// nor a remote.origin.push setting can overwrite commits on origin. A
+// rejected push is not retried and returns an error wrapping
+// ErrPushRejected.
 func PushBranch(repoDir, branchName string) error {
 	if branchName == "" {
 		return fmt.Errorf("engine: branch name is required")
@@ -239,14 +369,19 @@ func PushBranch(repoDir, branchName string) error {
 		return fmt.Errorf("engine: repo directory is required")
 	}
 
+	refspec := "refs/heads/" + branchName + ":refs/heads/" + branchName
 	var lastErr error
 	for attempt := range 2 {
-		cmd := exec.Command("git", "push", "origin", branchName)
+		cmd := exec.Command("git", "push", "origin", refspec)
 		cmd.Dir = repoDir
 		out, err := cmd.CombinedOutput()
 		if err == nil {
 			return nil
 		}
+		if pushRejected(string(out)) {
+			return fmt.Errorf("engine: push branch %q: %w (refusing to force-push; the branch was changed on origin): %s",
+				branchName, ErrPushRejected, strings.TrimSpace(string(out)))
+		}
 		lastErr = fmt.Errorf("engine: push branch %q (attempt %d): %s", branchName, attempt+1, strings.TrimSpace(string(out)))
 
 		if attempt == 0 {
@@ -256,6 +391,24 @@ func PushBranch(repoDir, branchName string) error {
 	return lastErr
 }
 
+// pushRejected reports whether git push output shows a non-fast-forward
+// rejection.
+func pushRejected(out string) bool {
+	return strings.Contains(out, "[rejected]") &&
+		(strings.Contains(out, "non-fast-forward") || strings.Contains(out, "fetch first"))
+}
+
+// HeadCommit returns the commit SHA checked out in repoDir.
+func HeadCommit(repoDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("engine: rev-parse HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // RecentCommits returns the last n commits on the given branch as one-line strings.
 func RecentCommits(repoDir, branchName string, n int) ([]string, error) {
 	if branchName == "" {
@@ -453,6 +606,39 @@ func EnsureYardmasterWorktree(repoDir string) (string, error) {
 	return wtDir, nil
 }
 
+// EnsureSwitchWorktree creates a persistent git worktree at
+// .railyard/switch/<slot>/ where the yardmaster runs one car's merge-gate
+// tests while other cars are tested or merged elsewhere. Returns the
+// absolute path to the worktree directory.
+func EnsureSwitchWorktree(repoDir, slot string) (string, error) {
+	if repoDir == "" {
+		return "", fmt.Errorf("engine: repo directory is required")
+	}
+	if slot == "" {
+		return "", fmt.Errorf("engine: switch worktree slot is required")
+	}
+
+	wtDir := filepath.Join(repoDir, ".railyard", "switch", slot)
+
+	// Reuse existing worktree.
+	if _, err := os.Stat(wtDir); err == nil {
+		return wtDir, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(repoDir, ".railyard", "switch"), 0755); err != nil {
+		return "", fmt.Errorf("engine: create switch dir: %w", err)
+	}
+
+	cmd := exec.Command("git", "worktree", "add", "--detach", wtDir)
+	cmd.Dir = repoDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("engine: create switch worktree %q: %s", slot, strings.TrimSpace(string(out)))
+	}
+
+	return wtDir, nil
+}
+
 // RemoveYardmasterWorktree removes the yardmaster's git worktree.
 func RemoveYardmasterWorktree(repoDir string) error {
 	wtPath := filepath.Join(".railyard", "yardmaster")
@@ -579,6 +765,7 @@ var railyardIgnoreEntries = []string{
 	".mcp.json",
 	".claude",
 	".railyard/",
+	".railyard-attachments/",
 	".claudeignore",
 	".beads/",
 }
@@ -1,11 +1,14 @@
 package engine
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
 )
 
 // initTestRepo creates a bare git repo with one commit, returns the working directory.
@@ -283,6 +286,31 @@ func TestResetWorktree_CleansUpDirtyState(t *testing.T) {
 	}
 }
 
+func TestResetWorktreeWithOpts_PreservesPaths(t *testing.T) {
+	dir := initTestRepo(t)
+	wtDir, err := EnsureWorktree(dir, "eng-reset-keep")
+	if err != nil {
+		t.Fatalf("EnsureWorktree: %v", err)
+	}
+	for _, p := range []string{"node_modules/left-pad/index.js", ".cache/build.bin", "scratch/junk.txt"} {
+		os.MkdirAll(filepath.Join(wtDir, filepath.Dir(p)), 0755)
+		os.WriteFile(filepath.Join(wtDir, p), []byte("x"), 0644)
+	}
+
+	opts := ResetOpts{PreservePaths: []string{"node_modules/", ".cache/"}, CheckoutWorkers: 4}
+	if err := ResetWorktreeWithOpts(wtDir, "", opts); err != nil {
+		t.Fatalf("ResetWorktreeWithOpts: %v", err)
+	}
+	for _, p := range []string{"node_modules/left-pad/index.js", ".cache/build.bin"} {
+		if _, err := os.Stat(filepath.Join(wtDir, p)); err != nil {
+			t.Errorf("%s should be preserved: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "scratch")); !os.IsNotExist(err) {
+		t.Error("expected scratch/ to be removed")
+	}
+}
+
 func TestResetWorktree_UpdatesToLatestMain(t *testing.T) {
 	dir := initTestRepo(t)
 
@@ -568,6 +596,43 @@ func TestPushBranch_WithRemote(t *testing.T) {
 	}
 }
 
+func TestPushBranch_RefusesForcePush(t *testing.T) {
+	bareDir := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare", "-b", "main")
+	cmd.Dir = bareDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %s\n%s", err, out)
+	}
+	dir := initTestRepo(t)
+	run := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s\n%s", args, err, out)
+		}
+	}
+	run("remote", "add", "origin", bareDir)
+	addCommit(t, dir, "pushed")
+	if err := PushBranch(dir, "main"); err != nil {
+		t.Fatalf("PushBranch: %v", err)
+	}
+
+	// Rewrite local history so the push is no longer a fast-forward. A
+	// configured forcing refspec must not turn it into a force push.
+	run("reset", "--hard", "HEAD~1")
+	addCommit(t, dir, "rewritten")
+	run("config", "remote.origin.push", "+refs/heads/*:refs/heads/*")
+
+	err := PushBranch(dir, "main")
+	if !errors.Is(err, ErrPushRejected) {
+		t.Fatalf("PushBranch error = %v, want ErrPushRejected", err)
+	}
+	if strings.Contains(err.Error(), "attempt") {
+		t.Errorf("rejected push was retried: %v", err)
+	}
+}
+
 // --- RecentCommits tests ---
 
 func TestRecentCommits(t *testing.T) {
@@ -1046,6 +1111,28 @@ func TestEnsureYardmasterWorktree_EmptyDir(t *testing.T) {
 	}
 }
 
+func TestEnsureSwitchWorktree(t *testing.T) {
+	dir := initTestRepo(t)
+
+	wtDir, err := EnsureSwitchWorktree(dir, "backend-1")
+	if err != nil {
+		t.Fatalf("EnsureSwitchWorktree: %v", err)
+	}
+	if expected := filepath.Join(dir, ".railyard", "switch", "backend-1"); wtDir != expected {
+		t.Errorf("wtDir = %q, want %q", wtDir, expected)
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, ".git")); err != nil {
+		t.Errorf("switch worktree not created: %v", err)
+	}
+
+	if wtDir2, err := EnsureSwitchWorktree(dir, "backend-1"); err != nil || wtDir2 != wtDir {
+		t.Errorf("reuse = %q, %v; want %q", wtDir2, err, wtDir)
+	}
+	if _, err := EnsureSwitchWorktree(dir, ""); err == nil {
+		t.Error("expected error for empty slot")
+	}
+}
+
 // --- SyncWorktreeToBranch tests ---
 
 func TestSyncWorktreeToBranch(t *testing.T) {
@@ -1467,3 +1554,78 @@ func TestEnsureRailyardIgnore_PreservesExisting(t *testing.T) {
 		t.Error("missing .claude entry")
 	}
 }
+
+func TestEnsureTrackRepo_ClonesOwnRepo(t *testing.T) {
+	mainRepo := initTestRepo(t)
+	trackRemote := initTestRepo(t)
+	cmd := exec.Command("git", "checkout", "-b", "develop")
+	cmd.Dir = trackRemote
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("checkout: %s\n%s", err, out)
+	}
+
+	cfg := &config.Config{
+		Repo:          "org/app",
+		DefaultBranch: "trunk",
+		Tracks: []config.TrackConfig{
+			{Name: "backend"},
+			{Name: "ml", Repo: trackRemote},
+		},
+	}
+
+	dir, err := EnsureTrackRepo(cfg, mainRepo, "ml")
+	if err != nil {
+		t.Fatalf("EnsureTrackRepo: %v", err)
+	}
+	if want := filepath.Join(mainRepo, ".railyard", "repos", "ml"); dir != want {
+		t.Errorf("dir = %q, want %q", dir, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+		t.Errorf("clone missing checkout: %v", err)
+	}
+	if again, err := EnsureTrackRepo(cfg, mainRepo, "ml"); err != nil || again != dir {
+		t.Errorf("second EnsureTrackRepo = %q, %v; want reuse of %q", again, err, dir)
+	}
+	if got := TrackBaseBranch(cfg, mainRepo, "ml"); got != "develop" {
+		t.Errorf("TrackBaseBranch(ml) = %q, want the clone's branch develop", got)
+	}
+
+	if dir, err := EnsureTrackRepo(cfg, mainRepo, "backend"); err != nil || dir != mainRepo {
+		t.Errorf("EnsureTrackRepo(backend) = %q, %v; want the main repo", dir, err)
+	}
+	if got := TrackBaseBranch(cfg, mainRepo, "backend"); got != "main" {
+		t.Errorf("TrackBaseBranch(backend) = %q, want main", got)
+	}
+
+	cfg.Tracks[1].DefaultBranch = "release"
+	if got := TrackBaseBranch(cfg, mainRepo, "ml"); got != "release" {
+		t.Errorf("TrackBaseBranch(ml) with default_branch = %q, want release", got)
+	}
+}
+
+func TestEnsureTrackRepo_CloneFailure(t *testing.T) {
+	cfg := &config.Config{
+		Repo:   "org/app",
+		Tracks: []config.TrackConfig{{Name: "ml", Repo: filepath.Join(t.TempDir(), "missing")}},
+	}
+	mainRepo := t.TempDir()
+	if _, err := EnsureTrackRepo(cfg, mainRepo, "ml"); err == nil {
+		t.Fatal("expected clone error")
+	}
+	if _, err := os.Stat(filepath.Join(mainRepo, ".railyard", "repos", "ml")); !os.IsNotExist(err) {
+		t.Errorf("failed clone left a track dir behind: %v", err)
+	}
+}
+
+func TestCloneURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"zulandar/blog":                     "https://github.com/zulandar/blog.git",
+		"git@github.com:zulandar/blog.git":  "git@github.com:zulandar/blog.git",
+		"https://github.com/zulandar/b.git": "https://github.com/zulandar/b.git",
+		"/srv/git/blog.git":                 "/srv/git/blog.git",
+	} {
+		if got := CloneURL(in); got != want {
+			t.Errorf("CloneURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
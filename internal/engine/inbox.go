@@ -2,6 +2,9 @@ package engine
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
@@ -17,6 +20,7 @@ const (
 	InstructionSwitchTrack InstructionType = "switch-track"
 	InstructionGuidance    InstructionType = "guidance"
 	InstructionDrain       InstructionType = "drain"
+	InstructionPreempt     InstructionType = "preempt"
 	InstructionUnknown     InstructionType = "unknown"
 )
 
@@ -27,6 +31,9 @@ type Instruction struct {
 	Subject   string
 	Body      string
 	CarID     string
+	From      string
+	Priority  string
+	CreatedAt time.Time
 }
 
 // ClassifyMessage determines the instruction type from a message subject.
@@ -47,6 +54,8 @@ func ClassifyMessage(msg *models.Message) InstructionType {
 		return InstructionGuidance
 	case "drain":
 		return InstructionDrain
+	case "preempt":
+		return InstructionPreempt
 	default:
 		return InstructionUnknown
 	}
@@ -72,6 +81,9 @@ func ProcessInbox(db *gorm.DB, engineID string) ([]Instruction, error) {
 			Subject:   msgs[i].Subject,
 			Body:      msgs[i].Body,
 			CarID:     msgs[i].CarID,
+			From:      msgs[i].FromAgent,
+			Priority:  msgs[i].Priority,
+			CreatedAt: msgs[i].CreatedAt,
 		}
 		instructions = append(instructions, inst)
 
@@ -118,6 +130,39 @@ func ShouldDrain(instructions []Instruction) bool {
 	return false
 }
 
+// PreemptionFor checks if any instruction preempts the given car. It returns
+// the urgent car the engine should claim instead (carried in the body).
+func PreemptionFor(instructions []Instruction, carID string) (string, bool) {
+	for _, inst := range instructions {
+		if inst.Type == InstructionPreempt && inst.CarID == carID {
+			return strings.TrimSpace(inst.Body), true
+		}
+	}
+	return "", false
+}
+
+// Guidance returns the guidance instructions as messages for the engine's
+// next prompt. ProcessInbox has already acknowledged them, so the engine
+// must carry them until it renders that prompt.
+func Guidance(instructions []Instruction) []models.Message {
+	var msgs []models.Message
+	for _, inst := range instructions {
+		if inst.Type != InstructionGuidance {
+			continue
+		}
+		msgs = append(msgs, models.Message{
+			ID:        inst.MessageID,
+			FromAgent: inst.From,
+			CarID:     inst.CarID,
+			Subject:   inst.Subject,
+			Body:      inst.Body,
+			Priority:  inst.Priority,
+			CreatedAt: inst.CreatedAt,
+		})
+	}
+	return msgs
+}
+
 // HasResume checks if any instruction is a resume.
 func HasResume(instructions []Instruction) bool {
 	for _, inst := range instructions {
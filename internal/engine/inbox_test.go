@@ -22,6 +22,7 @@ func TestClassifyMessage_AllTypes(t *testing.T) {
 		{"switch-track", InstructionSwitchTrack},
 		{"guidance", InstructionGuidance},
 		{"drain", InstructionDrain},
+		{"preempt", InstructionPreempt},
 		{"something-else", InstructionUnknown},
 		{"", InstructionUnknown},
 	}
@@ -103,6 +104,37 @@ func TestShouldDrain(t *testing.T) {
 	}
 }
 
+func TestPreemptionFor(t *testing.T) {
+	instructions := []Instruction{
+		{Type: InstructionGuidance, CarID: "car-1"},
+		{Type: InstructionPreempt, CarID: "car-2", Body: " car-urgent\n"},
+	}
+	if _, ok := PreemptionFor(instructions, "car-1"); ok {
+		t.Error("expected no preemption for car-1")
+	}
+	urgent, ok := PreemptionFor(instructions, "car-2")
+	if !ok || urgent != "car-urgent" {
+		t.Errorf("PreemptionFor(car-2) = %q, %v; want car-urgent, true", urgent, ok)
+	}
+}
+
+func TestGuidance(t *testing.T) {
+	instructions := []Instruction{
+		{Type: InstructionDrain},
+		{Type: InstructionGuidance, MessageID: 7, Subject: "guidance", Body: "pause refactors", From: "alice", Priority: "urgent"},
+	}
+	msgs := Guidance(instructions)
+	if len(msgs) != 1 {
+		t.Fatalf("Guidance returned %d messages, want 1", len(msgs))
+	}
+	if m := msgs[0]; m.ID != 7 || m.FromAgent != "alice" || m.Body != "pause refactors" || m.Priority != "urgent" {
+		t.Errorf("message = %+v", m)
+	}
+	if Guidance(nil) != nil {
+		t.Error("expected no messages for no instructions")
+	}
+}
+
 func TestInstructionTypeConstants(t *testing.T) {
 	if InstructionAbort != "abort" {
 		t.Errorf("InstructionAbort = %q", InstructionAbort)
@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Journal entry kinds.
+const (
+	JournalSession = "session"
+	JournalCommand = "command"
+	JournalTest    = "test"
+	JournalFiles   = "files"
+	JournalSandbox = "sandbox"
+	JournalPolicy  = "policy"
+)
+
+// Journal entry outcomes. An empty outcome means the result was not observed
+// (e.g. the session ended mid-command).
+const (
+	JournalOK      = "ok"
+	JournalFailed  = "failed"
+	JournalBlocked = "blocked" // a sandbox or command policy violation
+)
+
+// testCommandPattern matches common test runner invocations so they can be
+// journaled as test runs rather than plain commands.
+var testCommandPattern = regexp.MustCompile(`\b(go test|npm (run )?test|yarn test|pnpm test|pytest|cargo test|make test|jest|vitest|mvn test|gradle test|rspec|phpunit)\b`)
+
+// JournalStep is a single parsed step from an agent session transcript.
+type JournalStep struct {
+	Kind    string
+	Detail  string
+	Outcome string
+	At      time.Time
+}
+
+// JournalOpts holds parameters for recording a session's work journal.
+type JournalOpts struct {
+	CarID       string
+	EngineID    string
+	SessionID   string
+	RepoDir     string // worktree to snapshot with ChangedFiles; skipped if empty
+	TestCommand string // track test_command; commands containing it count as test runs
+	Outcome     string // how the session ended (e.g. "completed", "clear", "stall: ...")
+
+	// Sandboxed sessions also journal each sandbox violation, from the
+	// agent's output and the egress proxy's denials.
+	Sandboxed     bool
+	EgressDenials []EgressDenial
+
+	// PolicyViolations are the commands the track's command policy refused
+	// (see SessionPolicyViolations).
+	PolicyViolations []PolicyViolation
+}
+
+// RecordSessionJournal reconstructs what the agent did during a session from
+// its agent_logs output and appends it to the car's journal: a session-start
+// entry, every shell command (test runs flagged separately) with its outcome,
+// a snapshot of uncommitted files, any sandbox and command policy
+// violations, and a session-end
+// entry. Call it before completion/clear handling auto-commits the worktree
+// so the snapshot still reflects the agent's changes.
+func RecordSessionJournal(db *gorm.DB, opts JournalOpts) error {
+	if opts.CarID == "" {
+		return fmt.Errorf("engine: journal: carID is required")
+	}
+	if opts.SessionID == "" {
+		return fmt.Errorf("engine: journal: sessionID is required")
+	}
+
+	var logs []models.AgentLog
+	if err := db.Where("session_id = ? AND direction = ?", opts.SessionID, "out").
+		Order("id").Find(&logs).Error; err != nil {
+		return fmt.Errorf("engine: journal: load agent logs: %w", err)
+	}
+
+	now := time.Now()
+	started := now
+	if len(logs) > 0 {
+		started = logs[0].CreatedAt
+	}
+
+	steps := []JournalStep{{Kind: JournalSession, Detail: "started", At: started}}
+	steps = append(steps, ParseJournalSteps(logs, opts.TestCommand)...)
+
+	if opts.Sandboxed {
+		var errLogs []models.AgentLog
+		if err := db.Where("session_id = ? AND direction = ?", opts.SessionID, "err").
+			Order("id").Find(&errLogs).Error; err != nil {
+			return fmt.Errorf("engine: journal: load agent logs: %w", err)
+		}
+		violations := FindSandboxViolations(append(logs, errLogs...), opts.EgressDenials)
+		for _, v := range violations {
+			steps = append(steps, JournalStep{Kind: JournalSandbox, Detail: v.String(), Outcome: JournalBlocked, At: v.At})
+		}
+		if len(violations) > 0 {
+			slog.Warn("engine: sandbox violations", "car", opts.CarID, "session", opts.SessionID, "count", len(violations))
+		}
+	}
+
+	for _, v := range opts.PolicyViolations {
+		steps = append(steps, JournalStep{Kind: JournalPolicy, Detail: v.Command + " (" + v.Reason + ")", Outcome: JournalBlocked, At: v.At})
+	}
+
+	if opts.RepoDir != "" {
+		if files, err := ChangedFiles(opts.RepoDir); err == nil && len(files) > 0 {
+			data, _ := json.Marshal(files)
+			steps = append(steps, JournalStep{Kind: JournalFiles, Detail: string(data), At: now})
+		}
+	}
+
+	end := JournalStep{Kind: JournalSession, Detail: "ended", At: now}
+	if opts.Outcome != "" {
+		end.Detail = "ended: " + opts.Outcome
+	}
+	steps = append(steps, end)
+
+	entries := make([]models.JournalEntry, 0, len(steps))
+	for _, s := range steps {
+		entries = append(entries, models.JournalEntry{
+			CarID:     opts.CarID,
+			EngineID:  opts.EngineID,
+			SessionID: opts.SessionID,
+			Kind:      s.Kind,
+			Detail:    s.Detail,
+			Outcome:   s.Outcome,
+			CreatedAt: s.At,
+		})
+	}
+	if err := db.Create(&entries).Error; err != nil {
+		return fmt.Errorf("engine: journal: write entries: %w", err)
+	}
+	return nil
+}
+
+// journalToolUse and journalToolResult extract shell tool calls and their
+// results from claude stream-json assistant and user events.
+type journalToolUse struct {
+	Message struct {
+		Content []struct {
+			Type  string `json:"type"`
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			Input struct {
+				Command string `json:"command"`
+			} `json:"input"`
+			ToolUseID string `json:"tool_use_id"`
+			IsError   bool   `json:"is_error"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+// ParseJournalSteps extracts shell commands and their outcomes from a
+// session's agent_logs rows. Rows are flushed on a timer, so a line may span
+// rows; each line is stamped with the time of the row that completed it.
+// Both claude stream-json and native-loop transcripts are understood.
+func ParseJournalSteps(logs []models.AgentLog, testCommand string) []JournalStep {
+	p := &journalParser{testCommand: strings.TrimSpace(testCommand), byToolID: map[string]int{}, pendingNative: -1}
+	var carry string
+	for _, l := range logs {
+		lines := strings.Split(carry+l.Content, "\n")
+		carry = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			p.line(line, l.CreatedAt)
+		}
+	}
+	if carry != "" && len(logs) > 0 {
+		p.line(carry, logs[len(logs)-1].CreatedAt)
+	}
+	return p.steps
+}
+
+type journalParser struct {
+	testCommand   string
+	steps         []JournalStep
+	byToolID      map[string]int // stream-json tool_use id -> index in steps
+	pendingNative int            // index of the native-loop command awaiting a result; -1 if none
+}
+
+func (p *journalParser) add(command string, at time.Time) int {
+	kind := JournalCommand
+	if testCommandPattern.MatchString(command) || (p.testCommand != "" && strings.Contains(command, p.testCommand)) {
+		kind = JournalTest
+	}
+	p.steps = append(p.steps, JournalStep{Kind: kind, Detail: command, At: at})
+	return len(p.steps) - 1
+}
+
+func (p *journalParser) line(line string, at time.Time) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	if line[0] == '{' {
+		p.streamLine(line, at)
+		return
+	}
+	p.nativeLine(line, at)
+}
+
+// streamLine handles one claude stream-json event.
+func (p *journalParser) streamLine(line string, at time.Time) {
+	var evt streamEvent
+	if err := json.Unmarshal([]byte(line), &evt); err != nil {
+		return
+	}
+	if evt.Type != "assistant" && evt.Type != "user" {
+		return
+	}
+	var e journalToolUse
+	if err := json.Unmarshal([]byte(line), &e); err != nil {
+		return
+	}
+	for _, block := range e.Message.Content {
+		switch block.Type {
+		case "tool_use":
+			if block.Name != "Bash" || block.Input.Command == "" {
+				continue
+			}
+			p.byToolID[block.ID] = p.add(block.Input.Command, at)
+		case "tool_result":
+			idx, ok := p.byToolID[block.ToolUseID]
+			if !ok {
+				continue
+			}
+			delete(p.byToolID, block.ToolUseID)
+			p.steps[idx].Outcome = JournalOK
+			if block.IsError {
+				p.steps[idx].Outcome = JournalFailed
+			}
+		}
+	}
+}
+
+// nativeLine handles one line of a native-loop transcript (see
+// writeTranscriptEvent in pkg/cli): "🔧 bash {args}" starts a command and the
+// following "→ ..." line carries its result.
+func (p *journalParser) nativeLine(line string, at time.Time) {
+	switch {
+	case strings.HasPrefix(line, "🔧 "):
+		p.pendingNative = -1
+		name, args, _ := strings.Cut(strings.TrimPrefix(line, "🔧 "), " ")
+		if name != "bash" {
+			return
+		}
+		var a struct {
+			Command string `json:"command"`
+		}
+		command := args
+		if err := json.Unmarshal([]byte(args), &a); err == nil && a.Command != "" {
+			command = a.Command
+		}
+		p.pendingNative = p.add(command, at)
+	case p.pendingNative < 0:
+	case strings.HasPrefix(line, "→ error:") || strings.Contains(line, "[command failed"):
+		p.steps[p.pendingNative].Outcome = JournalFailed
+		p.pendingNative = -1
+	case strings.HasPrefix(line, "→ "):
+		p.steps[p.pendingNative].Outcome = JournalOK
+	}
+}
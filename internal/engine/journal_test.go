@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func journalTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&models.AgentLog{}, &models.JournalEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return gormDB
+}
+
+const (
+	streamBashUse  = `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}`
+	streamBashFail = `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","is_error":true,"content":"FAIL"}]}}`
+	streamReadUse  = `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"a.go"}}]}}`
+	streamLsUse    = `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"ls"}}]}}`
+	streamLsOK     = `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t3","content":"a.go"}]}}`
+)
+
+func TestParseJournalSteps_StreamJSON(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	logs := []models.AgentLog{
+		{Content: streamBashUse + "\n" + streamBashFail + "\n" + streamReadUse + "\n", CreatedAt: t0},
+		{Content: streamLsUse + "\n" + streamLsOK + "\n", CreatedAt: t0.Add(5 * time.Second)},
+	}
+
+	steps := ParseJournalSteps(logs, "")
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want 2: %+v", len(steps), steps)
+	}
+	if steps[0].Kind != JournalTest || steps[0].Outcome != JournalFailed || steps[0].Detail != "go test ./..." {
+		t.Errorf("step 0 = %+v, want failed test run", steps[0])
+	}
+	if steps[1].Kind != JournalCommand || steps[1].Outcome != JournalOK || !steps[1].At.Equal(t0.Add(5*time.Second)) {
+		t.Errorf("step 1 = %+v, want ok command at second flush", steps[1])
+	}
+}
+
+func TestParseJournalSteps_LineSplitAcrossRows(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	half := len(streamLsUse) / 2
+	logs := []models.AgentLog{
+		{Content: streamLsUse[:half], CreatedAt: t0},
+		{Content: streamLsUse[half:] + "\n" + streamLsOK, CreatedAt: t0.Add(5 * time.Second)},
+	}
+
+	steps := ParseJournalSteps(logs, "")
+	if len(steps) != 1 || steps[0].Detail != "ls" || steps[0].Outcome != JournalOK {
+		t.Fatalf("steps = %+v, want one ok ls", steps)
+	}
+}
+
+func TestParseJournalSteps_NativeTranscript(t *testing.T) {
+	logs := []models.AgentLog{{Content: "Looking around.\n" +
+		"🔧 bash {\"command\":\"make check\"}\n→ ok\n" +
+		"🔧 read_file {\"path\":\"a.go\"}\n→ package a\n" +
+		"🔧 bash {\"command\":\"false\"}\n→ \n[command failed: exit status 1]\n" +
+		"🔧 bash {\"command\":\"sleep 100\"}\n"}}
+
+	steps := ParseJournalSteps(logs, "make check")
+	if len(steps) != 3 {
+		t.Fatalf("got %d steps, want 3: %+v", len(steps), steps)
+	}
+	if steps[0].Kind != JournalTest || steps[0].Outcome != JournalOK {
+		t.Errorf("step 0 = %+v, want ok test (track test_command)", steps[0])
+	}
+	if steps[1].Detail != "false" || steps[1].Outcome != JournalFailed {
+		t.Errorf("step 1 = %+v, want failed command", steps[1])
+	}
+	if steps[2].Outcome != "" {
+		t.Errorf("step 2 outcome = %q, want unknown", steps[2].Outcome)
+	}
+}
+
+func TestRecordSessionJournal(t *testing.T) {
+	gormDB := journalTestDB(t)
+	t0 := time.Now().Add(-time.Minute)
+	gormDB.Create(&models.AgentLog{SessionID: "sess-1", CarID: "car-1", Direction: "out", Content: streamLsUse + "\n" + streamLsOK + "\n", CreatedAt: t0})
+	gormDB.Create(&models.AgentLog{SessionID: "sess-1", CarID: "car-1", Direction: "err", Content: streamBashUse + "\n", CreatedAt: t0})
+	gormDB.Create(&models.AgentLog{SessionID: "sess-2", CarID: "car-1", Direction: "out", Content: streamBashUse + "\n", CreatedAt: t0})
+
+	if err := RecordSessionJournal(gormDB, JournalOpts{CarID: "car-1", EngineID: "eng-1", SessionID: "sess-1", Outcome: "completed"}); err != nil {
+		t.Fatalf("RecordSessionJournal: %v", err)
+	}
+
+	var entries []models.JournalEntry
+	gormDB.Where("car_id = ?", "car-1").Order("id").Find(&entries)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3 (start, ls, end): %+v", len(entries), entries)
+	}
+	if entries[0].Kind != JournalSession || entries[0].Detail != "started" || !entries[0].CreatedAt.Equal(t0) {
+		t.Errorf("entry 0 = %+v, want session start at first log time", entries[0])
+	}
+	if entries[1].Detail != "ls" || entries[1].EngineID != "eng-1" || entries[1].SessionID != "sess-1" {
+		t.Errorf("entry 1 = %+v, want ls command for eng-1/sess-1", entries[1])
+	}
+	if entries[2].Detail != "ended: completed" {
+		t.Errorf("entry 2 detail = %q, want %q", entries[2].Detail, "ended: completed")
+	}
+}
+
+func TestRecordSessionJournal_SandboxViolations(t *testing.T) {
+	gormDB := journalTestDB(t)
+	t0 := time.Now().Add(-time.Minute)
+	gormDB.Create(&models.AgentLog{SessionID: "sess-1", CarID: "car-1", Direction: "err", Content: "sudo: effective uid is not 0\n", CreatedAt: t0})
+
+	if err := RecordSessionJournal(gormDB, JournalOpts{
+		CarID:         "car-1",
+		SessionID:     "sess-1",
+		Sandboxed:     true,
+		EgressDenials: []EgressDenial{{Host: "evil.example", At: t0}},
+	}); err != nil {
+		t.Fatalf("RecordSessionJournal: %v", err)
+	}
+
+	var entries []models.JournalEntry
+	gormDB.Where("kind = ?", JournalSandbox).Order("id").Find(&entries)
+	if len(entries) != 2 || entries[0].Detail != "sudo: privilege escalation blocked" || entries[1].Outcome != JournalBlocked {
+		t.Errorf("sandbox entries = %+v", entries)
+	}
+}
+
+func TestRecordSessionJournal_PolicyViolations(t *testing.T) {
+	gormDB := journalTestDB(t)
+	if err := RecordSessionJournal(gormDB, JournalOpts{
+		CarID:            "car-1",
+		SessionID:        "sess-1",
+		PolicyViolations: []PolicyViolation{{Command: "git push", Reason: `denied by "git push"`, At: time.Now()}},
+	}); err != nil {
+		t.Fatalf("RecordSessionJournal: %v", err)
+	}
+
+	var entries []models.JournalEntry
+	gormDB.Where("kind = ?", JournalPolicy).Find(&entries)
+	if len(entries) != 1 || entries[0].Detail != `git push (denied by "git push")` || entries[0].Outcome != JournalBlocked {
+		t.Errorf("policy entries = %+v", entries)
+	}
+}
+
+func TestRecordSessionJournal_Validation(t *testing.T) {
+	gormDB := journalTestDB(t)
+	if err := RecordSessionJournal(gormDB, JournalOpts{SessionID: "s"}); err == nil {
+		t.Error("expected error for missing car ID")
+	}
+	if err := RecordSessionJournal(gormDB, JournalOpts{CarID: "c"}); err == nil {
+		t.Error("expected error for missing session ID")
+	}
+}
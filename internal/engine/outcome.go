@@ -158,3 +158,80 @@ func HandleClearCycle(db *gorm.DB, car *models.Car, engine *models.Engine, opts
 
 	return nil
 }
+
+// PreemptionOpts holds parameters for parking a preempted car.
+type PreemptionOpts struct {
+	RepoDir     string
+	SessionID   string // empty when preempted between sessions
+	UrgentCarID string // car the preemption made way for
+}
+
+// HandlePreemption checkpoints and parks a car whose engine was preempted for
+// urgent work. Uncommitted changes are auto-committed and the branch pushed so
+// the next engine to claim the car resumes from it; the car goes back to open
+// and unassigned with PreemptedAt set, and the engine returns to idle. Parking
+// is conditional on the car still being assigned to this engine.
+func HandlePreemption(db *gorm.DB, car *models.Car, engine *models.Engine, opts PreemptionOpts) error {
+	if car == nil {
+		return fmt.Errorf("engine: car is required")
+	}
+	if engine == nil {
+		return fmt.Errorf("engine: engine is required")
+	}
+
+	filesJSON := "[]"
+	if opts.RepoDir != "" {
+		if files, err := ChangedFiles(opts.RepoDir); err == nil && len(files) > 0 {
+			data, _ := json.Marshal(files)
+			filesJSON = string(data)
+		}
+	}
+
+	if car.Branch != "" && opts.RepoDir != "" {
+		msg := fmt.Sprintf("railyard: checkpoint before preemption (%s)", car.ID)
+		if committed, acErr := AutoCommitIfDirty(opts.RepoDir, msg); acErr != nil {
+			slog.Warn("engine: preemption checkpoint commit warning (non-fatal)", "car", car.ID, "error", acErr)
+		} else if committed {
+			slog.Info("engine: checkpointed uncommitted changes before preemption", "car", car.ID, "branch", car.Branch)
+		}
+		if err := PushBranch(opts.RepoDir, car.Branch); err != nil {
+			slog.Warn("engine: preemption push failed (non-fatal)", "car", car.ID, "branch", car.Branch, "error", err)
+		}
+	}
+
+	note := "Preempted for urgent work; progress checkpointed on the car branch."
+	if opts.UrgentCarID != "" {
+		note = fmt.Sprintf("Preempted for urgent car %s; progress checkpointed on the car branch.", opts.UrgentCarID)
+	}
+	if err := db.Create(&models.CarProgress{
+		CarID:        car.ID,
+		EngineID:     engine.ID,
+		SessionID:    opts.SessionID,
+		Note:         note,
+		FilesChanged: filesJSON,
+		CreatedAt:    time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("engine: write preemption progress: %w", err)
+	}
+
+	now := time.Now()
+	if err := db.Model(&models.Car{}).Where("id = ? AND assignee = ?", car.ID, engine.ID).Updates(map[string]interface{}{
+		"status":       "open",
+		"assignee":     "",
+		"claimed_at":   nil,
+		"preempted_at": now,
+	}).Error; err != nil {
+		return fmt.Errorf("engine: park preempted car %s: %w", car.ID, err)
+	}
+
+	if err := db.Model(&models.Engine{}).Where("id = ?", engine.ID).Updates(map[string]interface{}{
+		"status":      StatusIdle,
+		"current_car": "",
+		"session_id":  "",
+	}).Error; err != nil {
+		return fmt.Errorf("engine: reset engine to idle: %w", err)
+	}
+	slog.Info("engine: car parked after preemption", "car", car.ID, "engine", engine.ID, "urgent_car", opts.UrgentCarID)
+
+	return nil
+}
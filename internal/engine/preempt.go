@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// UrgentPriority is the priority class that may preempt lower-priority work
+// when every engine on its track is busy.
+const UrgentPriority = 0
+
+// DefaultPreemptPollInterval is how often a running session checks for a
+// preempt instruction.
+const DefaultPreemptPollInterval = 10 * time.Second
+
+// PendingPreemption looks for an unacknowledged preempt instruction addressed
+// to engineID for carID. If one exists it is acknowledged and the urgent car
+// ID from its body is returned with ok=true.
+func PendingPreemption(db *gorm.DB, engineID, carID string) (urgentCarID string, ok bool, err error) {
+	if engineID == "" {
+		return "", false, fmt.Errorf("engine: engineID is required")
+	}
+	if carID == "" {
+		return "", false, fmt.Errorf("engine: carID is required")
+	}
+
+	var msg models.Message
+	err = db.Where("to_agent = ? AND subject = ? AND car_id = ? AND acknowledged = ?", engineID, "preempt", carID, false).
+		Order("id").First(&msg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("engine: pending preemption: %w", err)
+	}
+	if err := messaging.Acknowledge(db, msg.ID); err != nil {
+		return "", false, fmt.Errorf("engine: pending preemption: %w", err)
+	}
+	return strings.TrimSpace(msg.Body), true, nil
+}
+
+// PreemptWatcher polls for a preempt instruction while an agent session runs
+// and cancels the session context when one arrives.
+type PreemptWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	preempted bool
+	urgentCar string
+}
+
+// WatchPreemption starts a PreemptWatcher for the engine's current car. The
+// returned watcher's Context is derived from parent; run the session under it
+// so a preemption terminates the agent. Call Stop when the session ends.
+func WatchPreemption(parent context.Context, db *gorm.DB, engineID, carID string, interval time.Duration) *PreemptWatcher {
+	if interval <= 0 {
+		interval = DefaultPreemptPollInterval
+	}
+	ctx, cancel := context.WithCancel(parent)
+	w := &PreemptWatcher{ctx: ctx, cancel: cancel}
+	go w.run(db, engineID, carID, interval)
+	return w
+}
+
+func (w *PreemptWatcher) run(db *gorm.DB, engineID, carID string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		urgent, ok, err := PendingPreemption(db, engineID, carID)
+		if err != nil {
+			slog.Warn("engine: preemption poll error", "engine", engineID, "car", carID, "error", err)
+			continue
+		}
+		if ok {
+			slog.Info("engine: preempted for urgent car", "engine", engineID, "car", carID, "urgent_car", urgent)
+			w.mu.Lock()
+			w.preempted = true
+			w.urgentCar = urgent
+			w.mu.Unlock()
+			w.cancel()
+			return
+		}
+	}
+}
+
+// Context returns the session context, cancelled on preemption or when the
+// parent context is done.
+func (w *PreemptWatcher) Context() context.Context {
+	return w.ctx
+}
+
+// Stop ends polling and releases the session context.
+func (w *PreemptWatcher) Stop() {
+	w.cancel()
+}
+
+// Preempted reports whether a preempt instruction was received, and the
+// urgent car it named.
+func (w *PreemptWatcher) Preempted() (urgentCarID string, ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.urgentCar, w.preempted
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestPendingPreemption(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	if _, ok, err := PendingPreemption(gormDB, "eng-1", "car-1"); err != nil || ok {
+		t.Fatalf("empty inbox: ok=%v err=%v, want false/nil", ok, err)
+	}
+
+	messaging.Send(gormDB, "yardmaster", "eng-1", "preempt", "car-other", messaging.SendOpts{CarID: "car-2"})
+	messaging.Send(gormDB, "yardmaster", "eng-1", "preempt", "car-urgent", messaging.SendOpts{CarID: "car-1"})
+
+	urgent, ok, err := PendingPreemption(gormDB, "eng-1", "car-1")
+	if err != nil || !ok || urgent != "car-urgent" {
+		t.Fatalf("PendingPreemption = %q, %v, %v; want car-urgent, true, nil", urgent, ok, err)
+	}
+	if _, ok, _ := PendingPreemption(gormDB, "eng-1", "car-1"); ok {
+		t.Error("preemption should be acknowledged after the first read")
+	}
+}
+
+func TestWatchPreemption_CancelsSession(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	w := WatchPreemption(context.Background(), gormDB, "eng-1", "car-1", 10*time.Millisecond)
+	defer w.Stop()
+
+	messaging.Send(gormDB, "yardmaster", "eng-1", "preempt", "car-urgent", messaging.SendOpts{CarID: "car-1"})
+
+	select {
+	case <-w.Context().Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("session context not cancelled after preempt instruction")
+	}
+	if urgent, ok := w.Preempted(); !ok || urgent != "car-urgent" {
+		t.Errorf("Preempted() = %q, %v; want car-urgent, true", urgent, ok)
+	}
+}
+
+func TestWatchPreemption_StopIsNotPreemption(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	w := WatchPreemption(context.Background(), gormDB, "eng-1", "car-1", time.Hour)
+	w.Stop()
+	if _, ok := w.Preempted(); ok {
+		t.Error("Stop should not report a preemption")
+	}
+}
+
+func TestHandlePreemption_ParksCar(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	claimed := time.Now()
+	gormDB.Create(&models.Car{ID: "car-1", Title: "low", Track: "backend", Status: "in_progress", Assignee: "eng-1", ClaimedAt: &claimed})
+	gormDB.Create(&models.Engine{ID: "eng-1", Track: "backend", Status: StatusWorking, CurrentCar: "car-1"})
+
+	c := &models.Car{ID: "car-1"}
+	if err := HandlePreemption(gormDB, c, &models.Engine{ID: "eng-1"}, PreemptionOpts{UrgentCarID: "car-urgent"}); err != nil {
+		t.Fatalf("HandlePreemption: %v", err)
+	}
+
+	var got models.Car
+	gormDB.First(&got, "id = ?", "car-1")
+	if got.Status != "open" || got.Assignee != "" || got.ClaimedAt != nil || got.PreemptedAt == nil {
+		t.Errorf("car = status %q assignee %q claimed_at %v preempted_at %v; want parked", got.Status, got.Assignee, got.ClaimedAt, got.PreemptedAt)
+	}
+	var eng models.Engine
+	gormDB.First(&eng, "id = ?", "eng-1")
+	if eng.Status != StatusIdle || eng.CurrentCar != "" {
+		t.Errorf("engine = status %q current_car %q; want idle with no car", eng.Status, eng.CurrentCar)
+	}
+	var progress models.CarProgress
+	gormDB.Where("car_id = ?", "car-1").First(&progress)
+	if progress.Note == "" {
+		t.Error("expected a preemption progress note")
+	}
+}
+
+func TestHandlePreemption_ReassignedCarUntouched(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	gormDB.Create(&models.Car{ID: "car-1", Title: "low", Track: "backend", Status: "in_progress", Assignee: "eng-2"})
+
+	if err := HandlePreemption(gormDB, &models.Car{ID: "car-1"}, &models.Engine{ID: "eng-1"}, PreemptionOpts{}); err != nil {
+		t.Fatalf("HandlePreemption: %v", err)
+	}
+	var got models.Car
+	gormDB.First(&got, "id = ?", "car-1")
+	if got.Status != "in_progress" || got.Assignee != "eng-2" {
+		t.Errorf("car reassigned to another engine was modified: %+v", got)
+	}
+}
@@ -24,7 +24,10 @@ func (p *ClaudeProvider) Name() string { return "claude" }
 func (p *ClaudeProvider) BuildCommand(ctx context.Context, opts engine.SpawnOpts) (*exec.Cmd, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(ctx)
 
-	binary := p.Binary
+	binary := opts.AgentBinary
+	if binary == "" {
+		binary = p.Binary
+	}
 	if binary == "" {
 		binary = opts.ClaudeBinary
 	}
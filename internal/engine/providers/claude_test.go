@@ -52,6 +52,20 @@ func TestClaudeProvider_BuildCommand_FallbackToOptsBinary(t *testing.T) {
 	}
 }
 
+func TestClaudeProvider_BuildCommand_AgentBinaryOverrides(t *testing.T) {
+	p := &ClaudeProvider{Binary: "/usr/local/bin/claude"}
+	cmd, cancel := p.BuildCommand(context.Background(), engine.SpawnOpts{
+		AgentBinary:    "/opt/claude-2/bin/claude",
+		ClaudeBinary:   "/opt/claude",
+		ContextPayload: "test",
+	})
+	defer cancel()
+
+	if cmd.Args[0] != "/opt/claude-2/bin/claude" {
+		t.Errorf("binary = %q, want %q", cmd.Args[0], "/opt/claude-2/bin/claude")
+	}
+}
+
 func TestClaudeProvider_BuildCommand_RequiredFlags(t *testing.T) {
 	p := &ClaudeProvider{}
 	cmd, cancel := p.BuildCommand(context.Background(), engine.SpawnOpts{
@@ -32,7 +32,10 @@ func (p *CodexProvider) Name() string { return "codex" }
 func (p *CodexProvider) BuildCommand(ctx context.Context, opts engine.SpawnOpts) (*exec.Cmd, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(ctx)
 
-	binary := p.Binary
+	binary := opts.AgentBinary
+	if binary == "" {
+		binary = p.Binary
+	}
 	if binary == "" {
 		binary = "codex"
 	}
@@ -70,7 +70,10 @@ func copilotEnv() []string {
 func (p *CopilotProvider) BuildCommand(ctx context.Context, opts engine.SpawnOpts) (*exec.Cmd, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(ctx)
 
-	binary := p.Binary
+	binary := opts.AgentBinary
+	if binary == "" {
+		binary = p.Binary
+	}
 	if binary == "" {
 		binary = "copilot"
 	}
@@ -34,7 +34,10 @@ func (p *GeminiProvider) Name() string { return "gemini" }
 func (p *GeminiProvider) BuildCommand(ctx context.Context, opts engine.SpawnOpts) (*exec.Cmd, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(ctx)
 
-	binary := p.Binary
+	binary := opts.AgentBinary
+	if binary == "" {
+		binary = p.Binary
+	}
 	if binary == "" {
 		binary = "gemini"
 	}
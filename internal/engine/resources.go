@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// cgroupControllersPath exists only on hosts with the unified cgroup v2
+// hierarchy. A var so tests can point it elsewhere.
+var cgroupControllersPath = "/sys/fs/cgroup/cgroup.controllers"
+
+// CheckResources reports whether rc can be applied on this host: a nice
+// level needs nice on PATH, and cgroup limits need Linux with cgroup v2 and
+// systemd-run. Engines call it at startup so a misconfigured track fails
+// fast instead of on every spawn.
+func CheckResources(rc *config.ResourcesConfig) error {
+	if rc == nil {
+		return nil
+	}
+	if rc.Nice > 0 {
+		if _, err := exec.LookPath("nice"); err != nil {
+			return fmt.Errorf("engine: resources: nice not found on PATH: %w", err)
+		}
+	}
+	if !rc.Cgroup() {
+		return nil
+	}
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("engine: resources: memory_max and cpu_quota require linux (running on %s)", runtime.GOOS)
+	}
+	if _, err := os.Stat(cgroupControllersPath); err != nil {
+		return fmt.Errorf("engine: resources: memory_max and cpu_quota require cgroup v2: %w", err)
+	}
+	if _, err := exec.LookPath("systemd-run"); err != nil {
+		return fmt.Errorf("engine: resources: systemd-run not found on PATH: %w", err)
+	}
+	return nil
+}
+
+// resourceEnv returns the variables that cap the parallelism of the
+// toolchains agents commonly drive to cpus. Go sizes its build and test
+// parallelism from GOMAXPROCS.
+func resourceEnv(cpus int) []string {
+	n := strconv.Itoa(cpus)
+	return []string{
+		"GOMAXPROCS=" + n,
+		"MAKEFLAGS=-j" + n,
+		"CARGO_BUILD_JOBS=" + n,
+		"CMAKE_BUILD_PARALLEL_LEVEL=" + n,
+		"OMP_NUM_THREADS=" + n,
+		"UV_THREADPOOL_SIZE=" + n,
+	}
+}
+
+// limitResources rewrites cmd to run under rc: the parallelism variables
+// are added to its environment, nice lowers its priority, and systemd-run
+// places it in a transient user scope carrying the cgroup limits. It wraps
+// outside the sandbox so the limits cover the sandbox tool too.
+func limitResources(cmd *exec.Cmd, rc *config.ResourcesConfig) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	if rc.CPUs > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, resourceEnv(rc.CPUs)...)
+	}
+	if rc.Nice > 0 {
+		if err := prefixCommand(cmd, "nice", "-n", strconv.Itoa(rc.Nice)); err != nil {
+			return err
+		}
+	}
+	if rc.Cgroup() {
+		args := []string{"--user", "--scope", "--quiet", "--collect"}
+		if rc.MemoryMax != "" {
+			args = append(args, "-p", "MemoryMax="+rc.MemoryMax)
+		}
+		if rc.CPUQuota != "" {
+			args = append(args, "-p", "CPUQuota="+rc.CPUQuota)
+		}
+		if err := prefixCommand(cmd, "systemd-run", append(args, "--")...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefixCommand rewrites cmd to run through bin with args before the
+// original command line.
+func prefixCommand(cmd *exec.Cmd, bin string, args ...string) error {
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("engine: resources: %s not found on PATH: %w", bin, err)
+	}
+	wrapped := append(append([]string{bin}, args...), cmd.Path)
+	cmd.Args = append(wrapped, cmd.Args[1:]...)
+	cmd.Path = path
+	return nil
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func TestLimitResources_NiceAndCPUs(t *testing.T) {
+	nice, err := exec.LookPath("nice")
+	if err != nil {
+		t.Skip("nice not installed")
+	}
+	cmd := exec.Command("true", "--flag")
+	cmd.Env = []string{"HOME=/home/x"}
+	if err := limitResources(cmd, &config.ResourcesConfig{Nice: 10, CPUs: 2}); err != nil {
+		t.Fatalf("limitResources: %v", err)
+	}
+	if cmd.Path != nice || strings.Join(cmd.Args[:3], " ") != "nice -n 10" || cmd.Args[len(cmd.Args)-1] != "--flag" {
+		t.Errorf("wrapped command = %s %q", cmd.Path, cmd.Args)
+	}
+	for _, want := range []string{"HOME=/home/x", "GOMAXPROCS=2", "MAKEFLAGS=-j2", "CARGO_BUILD_JOBS=2"} {
+		if !slices.Contains(cmd.Env, want) {
+			t.Errorf("env %q missing %q", cmd.Env, want)
+		}
+	}
+}
+
+func TestLimitResources_Cgroup(t *testing.T) {
+	if _, err := exec.LookPath("systemd-run"); err != nil {
+		t.Skip("systemd-run not installed")
+	}
+	cmd := exec.Command("true")
+	if err := limitResources(cmd, &config.ResourcesConfig{MemoryMax: "4G", CPUQuota: "200%"}); err != nil {
+		t.Fatalf("limitResources: %v", err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, want := range []string{"systemd-run --user --scope", "-p MemoryMax=4G", "-p CPUQuota=200%", "-- "} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+	if cmd.Env != nil {
+		t.Errorf("env set without cpus: %q", cmd.Env)
+	}
+}
+
+func TestCheckResources(t *testing.T) {
+	if err := CheckResources(nil); err != nil {
+		t.Errorf("nil resources: %v", err)
+	}
+	if err := CheckResources(&config.ResourcesConfig{CPUs: 4}); err != nil {
+		t.Errorf("cpus only: %v", err)
+	}
+
+	orig := cgroupControllersPath
+	t.Cleanup(func() { cgroupControllersPath = orig })
+	cgroupControllersPath = filepath.Join(t.TempDir(), "missing")
+	err := CheckResources(&config.ResourcesConfig{MemoryMax: "4G"})
+	if err == nil || !strings.Contains(err.Error(), "require") {
+		t.Errorf("cgroup limits without cgroup v2: err = %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// sandboxBinaries maps a sandbox tool to the binary that runs it.
+var sandboxBinaries = map[string]string{
+	config.SandboxBubblewrap: "bwrap",
+	config.SandboxFirejail:   "firejail",
+}
+
+// providerStatePaths are the per-user files each agent CLI writes while it
+// runs (session state, credentials refresh). They stay writable inside the
+// sandbox so the agent itself keeps working.
+var providerStatePaths = map[string][]string{
+	"claude":  {"~/.claude", "~/.claude.json"},
+	"codex":   {"~/.codex"},
+	"gemini":  {"~/.gemini"},
+	"copilot": {"~/.copilot"},
+}
+
+// CheckSandbox reports whether sb can run on this host: sandboxing needs
+// Linux and the tool's binary on PATH. Engines call it at startup so a
+// misconfigured track fails fast instead of on every spawn.
+func CheckSandbox(sb *config.SandboxConfig) error {
+	if sb == nil {
+		return nil
+	}
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("engine: sandbox: %s requires linux (running on %s)", sb.Tool, runtime.GOOS)
+	}
+	bin, ok := sandboxBinaries[sb.Tool]
+	if !ok {
+		return fmt.Errorf("engine: sandbox: unknown tool %q", sb.Tool)
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return fmt.Errorf("engine: sandbox: %s not found on PATH: %w", bin, err)
+	}
+	return nil
+}
+
+// sandboxSpec is everything needed to wrap one agent command.
+type sandboxSpec struct {
+	Tool     string
+	WorkDir  string   // the car's worktree; writable and the working directory
+	Writable []string // other writable paths, already expanded; missing ones are skipped
+	Offline  bool     // block all network egress (no hosts are allowed)
+	ProxyURL string   // egress proxy the agent's HTTP clients are pointed at
+}
+
+// newSandboxSpec builds the spec for an agent of provider working in
+// workDir. The worktree's git directory is writable so the agent can commit.
+func newSandboxSpec(sb *config.SandboxConfig, provider, workDir, proxyURL string) sandboxSpec {
+	spec := sandboxSpec{
+		Tool:     sb.Tool,
+		WorkDir:  workDir,
+		Offline:  len(sb.AllowHosts) == 0,
+		ProxyURL: proxyURL,
+	}
+	if dir := gitCommonDir(workDir); dir != "" && !isWithin(dir, workDir) {
+		spec.Writable = append(spec.Writable, dir)
+	}
+	for _, p := range append(providerStatePaths[provider], sb.Writable...) {
+		spec.Writable = append(spec.Writable, expandHome(p))
+	}
+	return spec
+}
+
+// wrap rewrites cmd to run inside the sandbox. The command's working
+// directory and environment carry over; proxy variables are added so HTTP
+// clients send egress through the allow-listing proxy.
+func (s sandboxSpec) wrap(cmd *exec.Cmd) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	bin := sandboxBinaries[s.Tool]
+	toolPath, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("engine: sandbox: %s not found on PATH: %w", bin, err)
+	}
+
+	var args []string
+	switch s.Tool {
+	case config.SandboxBubblewrap:
+		args = s.bwrapArgs()
+	case config.SandboxFirejail:
+		args = s.firejailArgs()
+	default:
+		return fmt.Errorf("engine: sandbox: unknown tool %q", s.Tool)
+	}
+	args = append(append([]string{bin}, args...), "--", cmd.Path)
+	cmd.Args = append(args, cmd.Args[1:]...)
+	cmd.Path = toolPath
+
+	if cmd.Dir == "" {
+		cmd.Dir = s.WorkDir
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	if s.ProxyURL != "" {
+		for _, k := range []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy"} {
+			cmd.Env = append(cmd.Env, k+"="+s.ProxyURL)
+		}
+		cmd.Env = append(cmd.Env, "NO_PROXY=localhost,127.0.0.1", "no_proxy=localhost,127.0.0.1")
+	}
+	return nil
+}
+
+// bwrapArgs mounts the host read-only with a private /tmp and the worktree
+// and writable paths bound read-write. bwrap always sets no_new_privs, so
+// setuid binaries like sudo cannot gain root.
+func (s sandboxSpec) bwrapArgs() []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-pid", "--unshare-ipc", "--unshare-uts",
+		"--die-with-parent",
+		"--new-session",
+	}
+	if s.Offline {
+		args = append(args, "--unshare-net")
+	}
+	for _, p := range s.writablePaths() {
+		args = append(args, "--bind", p, p)
+	}
+	if s.WorkDir != "" {
+		args = append(args, "--chdir", s.WorkDir)
+	}
+	return args
+}
+
+// firejailArgs is the firejail equivalent of bwrapArgs.
+func (s sandboxSpec) firejailArgs() []string {
+	args := []string{
+		"--quiet",
+		"--noprofile",
+		"--read-only=/",
+		"--private-tmp",
+		"--nonewprivs",
+		"--noroot",
+		"--caps.drop=all",
+	}
+	if s.Offline {
+		args = append(args, "--net=none")
+	}
+	for _, p := range s.writablePaths() {
+		args = append(args, "--read-write="+p)
+	}
+	return args
+}
+
+// writablePaths returns the worktree and the writable paths that exist.
+func (s sandboxSpec) writablePaths() []string {
+	var paths []string
+	for _, p := range append([]string{s.WorkDir}, s.Writable...) {
+		if p == "" {
+			continue
+		}
+		if _, err := os.Stat(p); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// gitCommonDir returns the absolute path of the git directory shared by the
+// worktree at dir, or "" if dir is not in a git repository.
+func gitCommonDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// isWithin reports whether path is dir or inside it.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// expandHome replaces a leading ~/ with the user's home directory.
+func expandHome(p string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return p
+}
+
+// Sandbox violation kinds.
+const (
+	ViolationFilesystem = "filesystem"
+	ViolationNetwork    = "network"
+	ViolationSudo       = "sudo"
+)
+
+// SandboxViolation is something an agent tried that the sandbox blocked.
+// Repeats of the same violation in a session are counted, not listed.
+type SandboxViolation struct {
+	Kind   string
+	Detail string
+	Count  int
+	At     time.Time // first occurrence
+}
+
+// String renders the violation for the journal and logs.
+func (v SandboxViolation) String() string {
+	s := v.Kind + ": " + v.Detail
+	if v.Count > 1 {
+		s += fmt.Sprintf(" (%d times)", v.Count)
+	}
+	return s
+}
+
+var (
+	// readOnlyRe matches the error a write outside the writable paths fails
+	// with, capturing the path. Paths may be quoted, or JSON-escaped inside
+	// stream-json tool results.
+	readOnlyRe = regexp.MustCompile(`(/[^\s'"\\:‘’]+)(?:\\?['"’])?: Read-only file system`)
+	// sudoBlockedRe matches sudo refusing to run under no_new_privs
+	// (bubblewrap) or in a user namespace without root (firejail).
+	sudoBlockedRe = regexp.MustCompile(`sudo: (?:The \\?"no new privileges\\?" flag is set|effective uid is not 0)`)
+)
+
+// FindSandboxViolations reports what the sandbox blocked during a session:
+// filesystem writes and sudo attempts found in the agent's output, and
+// hosts the egress proxy refused.
+func FindSandboxViolations(logs []models.AgentLog, denials []EgressDenial) []SandboxViolation {
+	var out []SandboxViolation
+	index := map[string]int{}
+	add := func(kind, detail string, at time.Time) {
+		key := kind + "\x00" + detail
+		if i, ok := index[key]; ok {
+			out[i].Count++
+			return
+		}
+		index[key] = len(out)
+		out = append(out, SandboxViolation{Kind: kind, Detail: detail, Count: 1, At: at})
+	}
+
+	var carry string
+	scan := func(line string, at time.Time) {
+		for _, m := range readOnlyRe.FindAllStringSubmatch(line, -1) {
+			add(ViolationFilesystem, "write to "+m[1]+" blocked", at)
+		}
+		if sudoBlockedRe.MatchString(line) {
+			add(ViolationSudo, "privilege escalation blocked", at)
+		}
+	}
+	for _, l := range logs {
+		lines := strings.Split(carry+l.Content, "\n")
+		carry = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			scan(line, l.CreatedAt)
+		}
+	}
+	if carry != "" && len(logs) > 0 {
+		scan(carry, logs[len(logs)-1].CreatedAt)
+	}
+
+	for _, d := range denials {
+		add(ViolationNetwork, "egress to "+d.Host+" blocked", d.At)
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestSandboxSpec_BubblewrapArgs(t *testing.T) {
+	wt, cache := t.TempDir(), t.TempDir()
+	spec := sandboxSpec{Tool: config.SandboxBubblewrap, WorkDir: wt, Writable: []string{cache, "/no/such/dir"}, Offline: true}
+	args := strings.Join(spec.bwrapArgs(), " ")
+	for _, want := range []string{"--ro-bind / /", "--tmpfs /tmp", "--unshare-net", "--bind " + wt + " " + wt, "--bind " + cache + " " + cache, "--chdir " + wt} {
+		if !strings.Contains(args, want) {
+			t.Errorf("bwrap args %q missing %q", args, want)
+		}
+	}
+	if strings.Contains(args, "/no/such/dir") {
+		t.Errorf("bwrap args bind a missing path: %q", args)
+	}
+
+	spec.Offline = false
+	if args := spec.bwrapArgs(); slices.Contains(args, "--unshare-net") {
+		t.Error("network unshared although hosts are allowed")
+	}
+}
+
+func TestSandboxSpec_FirejailArgs(t *testing.T) {
+	wt := t.TempDir()
+	args := (sandboxSpec{Tool: config.SandboxFirejail, WorkDir: wt, Offline: true}).firejailArgs()
+	for _, want := range []string{"--read-only=/", "--read-write=" + wt, "--nonewprivs", "--noroot", "--net=none"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("firejail args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestSandboxSpec_Wrap(t *testing.T) {
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		t.Skip("bwrap not installed")
+	}
+	wt := t.TempDir()
+	cmd := exec.Command("true", "--flag")
+	spec := sandboxSpec{Tool: config.SandboxBubblewrap, WorkDir: wt, ProxyURL: "http://127.0.0.1:3128"}
+	if err := spec.wrap(cmd); err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	if cmd.Path != bwrap || cmd.Args[0] != "bwrap" || cmd.Args[len(cmd.Args)-1] != "--flag" {
+		t.Errorf("wrapped command = %s %q", cmd.Path, cmd.Args)
+	}
+	if cmd.Dir != wt || !slices.Contains(cmd.Env, "HTTPS_PROXY=http://127.0.0.1:3128") {
+		t.Errorf("dir %q, env missing the proxy", cmd.Dir)
+	}
+}
+
+func TestFindSandboxViolations(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	logs := []models.AgentLog{
+		{Content: `{"type":"user","message":{"content":[{"type":"tool_result","is_error":true,"content":"touch: cannot touch '/etc/hosts': Read-only file system"}]}}` + "\n", CreatedAt: t0},
+		{Content: "sudo: The \"no new privileges\" flag is set, which prevents sudo from running as root.\n", CreatedAt: t0.Add(time.Second)},
+		{Content: "mkdir: cannot create directory ‘/etc/hosts’: Read-only file system\n", CreatedAt: t0.Add(2 * time.Second)},
+	}
+	denials := []EgressDenial{{Host: "evil.example", At: t0}, {Host: "evil.example", At: t0}}
+
+	got := FindSandboxViolations(logs, denials)
+	var rendered []string
+	for _, v := range got {
+		rendered = append(rendered, v.String())
+	}
+	want := []string{
+		"filesystem: write to /etc/hosts blocked (2 times)",
+		"sudo: privilege escalation blocked",
+		"network: egress to evil.example blocked (2 times)",
+	}
+	if !slices.Equal(rendered, want) {
+		t.Errorf("violations = %q, want %q", rendered, want)
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	allow := []string{"api.anthropic.com", "*.golang.org"}
+	for host, want := range map[string]bool{
+		"api.anthropic.com":  true,
+		"API.Anthropic.com.": true,
+		"proxy.golang.org":   true,
+		"golang.org":         false,
+		"evilgolang.org":     false,
+		"example.com":        false,
+	} {
+		if got := HostAllowed(allow, host); got != want {
+			t.Errorf("HostAllowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestEgressProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer upstream.Close()
+
+	p, err := StartEgressProxy([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("StartEgressProxy: %v", err)
+	}
+	defer p.Close()
+	proxyURL, _ := url.Parse(p.URL())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("GET allowed host: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("body = %q", body)
+	}
+
+	resp, err = client.Get("http://blocked.invalid/")
+	if err != nil {
+		t.Fatalf("GET blocked host: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", resp.StatusCode)
+	}
+	if d := p.Denials(); len(d) != 1 || d[0].Host != "blocked.invalid" {
+		t.Errorf("denials = %+v", d)
+	}
+}
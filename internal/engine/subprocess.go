@@ -14,6 +14,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
 )
@@ -23,10 +24,14 @@ type SpawnOpts struct {
 	EngineID       string
 	CarID          string
 	ContextPayload string
-	WorkDir        string // working directory for the agent
-	ClaudeBinary   string // path to claude binary, default "claude" (legacy; prefer ProviderName)
-	ProviderName   string // agent provider name (e.g., "claude", "codex"); defaults to "claude"
-	Model          string // optional model identifier; consumed per-provider (env var or flag). Empty preserves CLI default.
+	WorkDir        string                  // working directory for the agent
+	ClaudeBinary   string                  // path to claude binary, default "claude" (legacy; prefer ProviderName)
+	ProviderName   string                  // agent provider name (e.g., "claude", "codex"); defaults to "claude"
+	AgentBinary    string                  // overrides the provider's CLI binary (ry engine start --agent-binary)
+	Model          string                  // optional model identifier; consumed per-provider (env var or flag). Empty preserves CLI default.
+	Sandbox        *config.SandboxConfig   // optional; runs the agent in the track's sandbox
+	Resources      *config.ResourcesConfig // optional; the track's CPU/memory limits for the agent
+	CommandPolicy  *CommandPolicy          // optional; passed to the agent's command policy hook
 }
 
 // Session represents a running claude subprocess.
@@ -41,6 +46,7 @@ type Session struct {
 	waitCh chan error // buffered(1), receives exit result
 	stdout *logWriter
 	stderr *logWriter
+	egress *EgressProxy // sandboxed sessions only
 }
 
 // logWriter implements io.Writer, buffering output and periodically flushing
@@ -100,6 +106,42 @@ func SpawnAgent(ctx context.Context, db *gorm.DB, opts SpawnOpts) (*Session, err
 	}
 
 	cmd, cancel := provider.BuildCommand(ctx, opts)
+	if opts.CommandPolicy != nil {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, opts.CommandPolicy.env())
+	}
+
+	var egress *EgressProxy
+	if opts.Sandbox != nil {
+		var proxyURL string
+		if len(opts.Sandbox.AllowHosts) > 0 {
+			if egress, err = StartEgressProxy(opts.Sandbox.AllowHosts); err != nil {
+				cancel()
+				return nil, err
+			}
+			proxyURL = egress.URL()
+		}
+		spec := newSandboxSpec(opts.Sandbox, providerName, opts.WorkDir, proxyURL)
+		if err := spec.wrap(cmd); err != nil {
+			cancel()
+			if egress != nil {
+				egress.Close()
+			}
+			return nil, err
+		}
+	}
+
+	if opts.Resources != nil {
+		if err := limitResources(cmd, opts.Resources); err != nil {
+			cancel()
+			if egress != nil {
+				egress.Close()
+			}
+			return nil, err
+		}
+	}
 
 	parseFn := provider.ParseOutput
 	stdoutWriter := newLogWriter(db, opts.EngineID, sessionID, opts.CarID, "out", parseFn)
@@ -110,6 +152,9 @@ func SpawnAgent(ctx context.Context, db *gorm.DB, opts SpawnOpts) (*Session, err
 
 	if err := cmd.Start(); err != nil {
 		cancel()
+		if egress != nil {
+			egress.Close()
+		}
 		return nil, fmt.Errorf("engine: start claude: %w", err)
 	}
 
@@ -124,6 +169,9 @@ func SpawnAgent(ctx context.Context, db *gorm.DB, opts SpawnOpts) (*Session, err
 	// Wait goroutine: waits for process, final flushes, sends result.
 	go func() {
 		waitErr := cmd.Wait()
+		if egress != nil {
+			egress.Close()
+		}
 		flushCancel()
 		stdoutWriter.Close()
 		stderrWriter.Close()
@@ -149,9 +197,19 @@ func SpawnAgent(ctx context.Context, db *gorm.DB, opts SpawnOpts) (*Session, err
 		waitCh:   waitCh,
 		stdout:   stdoutWriter,
 		stderr:   stderrWriter,
+		egress:   egress,
 	}, nil
 }
 
+// EgressDenials returns the hosts the sandbox's egress proxy refused. It is
+// nil for sessions without a proxy.
+func (s *Session) EgressDenials() []EgressDenial {
+	if s.egress == nil {
+		return nil
+	}
+	return s.egress.Denials()
+}
+
 // Wait blocks until the subprocess exits and returns its error (if any).
 func (s *Session) Wait() error {
 	return <-s.waitCh
@@ -176,7 +234,10 @@ func (s *Session) Cancel() {
 func buildCommand(ctx context.Context, opts SpawnOpts) (*exec.Cmd, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(ctx)
 
-	binary := opts.ClaudeBinary
+	binary := opts.AgentBinary
+	if binary == "" {
+		binary = opts.ClaudeBinary
+	}
 	if binary == "" {
 		binary = "claude"
 	}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// TrackContextFile is the worktree-relative path of the generated track
+// context file. It lives under .railyard/, which EnsureRailyardIgnore keeps
+// out of git.
+const TrackContextFile = ".railyard/TRACK.md"
+
+const (
+	// maxTrackFileMerged caps the recently merged cars in TRACK.md.
+	maxTrackFileMerged = 15
+	// maxTrackFileEpics caps the epics in TRACK.md.
+	maxTrackFileEpics = 8
+	// maxTrackFileText truncates each epic's description and design notes
+	// and each merged car's summary.
+	maxTrackFileText = 600
+)
+
+// TrackContext is what TRACK.md is assembled from.
+type TrackContext struct {
+	Track  *models.Track
+	Notes  []models.TrackNote // oldest first
+	Epics  []models.Car       // the track's unfinished epics, most recently updated first
+	Merged []MergedSummary    // most recently merged first
+}
+
+// MergedSummary is one recently merged car in TRACK.md.
+type MergedSummary struct {
+	ID       string
+	Title    string
+	MergedAt time.Time
+	Summary  string // the car's last engine progress note
+}
+
+// LoadTrackContext gathers the sources of a track's TRACK.md: its notes,
+// its unfinished epics, and the cars it merged most recently.
+func LoadTrackContext(db *gorm.DB, track *models.Track) (*TrackContext, error) {
+	if track == nil {
+		return nil, fmt.Errorf("engine: track is required")
+	}
+	notes, err := car.TrackNotes(db, track.Name, car.MaxPromptNotes)
+	if err != nil {
+		return nil, fmt.Errorf("engine: track context: %w", err)
+	}
+
+	var epics []models.Car
+	if err := db.Where("track = ? AND type = ? AND status NOT IN ?", track.Name, "epic", models.ClosedStatuses).
+		Order("updated_at DESC, id").Limit(maxTrackFileEpics).Find(&epics).Error; err != nil {
+		return nil, fmt.Errorf("engine: track context: load epics: %w", err)
+	}
+
+	var merged []models.Car
+	if err := db.Where("track = ? AND status = ? AND type <> ?", track.Name, "merged", "epic").
+		Order("completed_at DESC, id").Limit(maxTrackFileMerged).Find(&merged).Error; err != nil {
+		return nil, fmt.Errorf("engine: track context: load merged cars: %w", err)
+	}
+	summaries := make([]MergedSummary, len(merged))
+	for i, c := range merged {
+		summaries[i] = MergedSummary{ID: c.ID, Title: c.Title}
+		if c.CompletedAt != nil {
+			summaries[i].MergedAt = *c.CompletedAt
+		}
+		if notes := priorNotes(db, c.ID); len(notes) > 0 {
+			summaries[i].Summary = notes[len(notes)-1]
+		}
+	}
+	return &TrackContext{Track: track, Notes: notes, Epics: epics, Merged: summaries}, nil
+}
+
+// Render produces TRACK.md. The output depends only on the sources, so an
+// unchanged track renders byte-for-byte the same file.
+func (tc *TrackContext) Render() string {
+	var w strings.Builder
+	fmt.Fprintf(&w, "# Track: %s\n\n", tc.Track.Name)
+	w.WriteString("Generated by Railyard from the track's configuration, notes, epics, and merged cars. It is rewritten when those change; add lasting facts with `ry note add` instead of editing it.\n\n")
+
+	w.WriteString("## Conventions\n")
+	if tc.Track.Language != "" {
+		fmt.Fprintf(&w, "Language: %s\n", tc.Track.Language)
+	}
+	var patterns []string
+	if tc.Track.FilePatterns != "" && json.Unmarshal([]byte(tc.Track.FilePatterns), &patterns) == nil && len(patterns) > 0 {
+		fmt.Fprintf(&w, "Files: %s\n", strings.Join(patterns, ", "))
+	}
+	if conv := formatConventions(tc.Track.Conventions); conv != "" {
+		w.WriteString(conv)
+		w.WriteString("\n")
+	}
+	if tc.Track.SystemPrompt != "" {
+		w.WriteString("\n")
+		w.WriteString(strings.TrimSpace(tc.Track.SystemPrompt))
+		w.WriteString("\n")
+	}
+	w.WriteString("\n")
+
+	if len(tc.Notes) > 0 {
+		w.WriteString("## Track Notes\n")
+		for _, n := range tc.Notes {
+			fmt.Fprintf(&w, "- %s (%s, %s)\n", oneLine(n.Content, maxTrackFileText), n.Author, n.CreatedAt.Format("2006-01-02"))
+		}
+		w.WriteString("\n")
+	}
+
+	if len(tc.Epics) > 0 {
+		w.WriteString("## Epics in Progress\n")
+		w.WriteString("The larger pieces of work on this track. Keep new code consistent with their design.\n\n")
+		for _, e := range tc.Epics {
+			fmt.Fprintf(&w, "### %s: %s (%s)\n", e.ID, e.Title, e.Status)
+			if e.Description != "" {
+				w.WriteString(oneLine(e.Description, maxTrackFileText))
+				w.WriteString("\n")
+			}
+			if e.DesignNotes != "" {
+				fmt.Fprintf(&w, "Design: %s\n", oneLine(e.DesignNotes, maxTrackFileText))
+			}
+			w.WriteString("\n")
+		}
+	}
+
+	if len(tc.Merged) > 0 {
+		w.WriteString("## Recently Merged\n")
+		for _, m := range tc.Merged {
+			fmt.Fprintf(&w, "- %s: %s", m.ID, m.Title)
+			if !m.MergedAt.IsZero() {
+				fmt.Fprintf(&w, " (%s)", m.MergedAt.Format("2006-01-02"))
+			}
+			w.WriteString("\n")
+			if m.Summary != "" {
+				fmt.Fprintf(&w, "  %s\n", oneLine(m.Summary, maxTrackFileText))
+			}
+		}
+		w.WriteString("\n")
+	}
+	return w.String()
+}
+
+// Write renders TRACK.md into workDir, leaving the file alone when its
+// content has not changed. It reports whether the file was written.
+func (tc *TrackContext) Write(workDir string) (bool, error) {
+	content := []byte(tc.Render())
+	path := filepath.Join(workDir, filepath.FromSlash(TrackContextFile))
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("engine: create track context dir: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return false, fmt.Errorf("engine: write track context: %w", err)
+	}
+	return true, nil
+}
+
+// oneLine flattens s to a single line of at most n bytes.
+func oneLine(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
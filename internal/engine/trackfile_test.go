@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestTrackContext_Write(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	track := &models.Track{Name: "backend", Language: "go", FilePatterns: `["internal/**"]`,
+		Conventions: `{"style":"gofmt"}`, SystemPrompt: "Prefer table-driven tests."}
+	merged := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	gormDB.Create(&models.Car{ID: "car-m1", Title: "Add login", Track: "backend", Status: "merged", CompletedAt: &merged})
+	gormDB.Create(&models.CarProgress{CarID: "car-m1", EngineID: "eng-1", Note: "Login uses the session store\nin internal/auth."})
+	gormDB.Create(&models.Car{ID: "car-e1", Title: "Auth rework", Track: "backend", Type: "epic", Status: "open", DesignNotes: "Sessions move to Redis."})
+	gormDB.Create(&models.Car{ID: "car-e2", Title: "Old epic", Track: "backend", Type: "epic", Status: "done"})
+	gormDB.Create(&models.Car{ID: "car-f1", Title: "Other track", Track: "frontend", Status: "merged", CompletedAt: &merged})
+	gormDB.Create(&models.TrackNote{Track: "backend", Content: "CI needs REDIS_URL", Author: "human"})
+
+	tc, err := LoadTrackContext(gormDB, track)
+	if err != nil {
+		t.Fatalf("LoadTrackContext: %v", err)
+	}
+	dir := t.TempDir()
+	written, err := tc.Write(dir)
+	if err != nil || !written {
+		t.Fatalf("Write = %v, %v; want the file written", written, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, TrackContextFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"# Track: backend",
+		"Language: go",
+		"Files: internal/**",
+		"Prefer table-driven tests.",
+		"- CI needs REDIS_URL (human,",
+		"### car-e1: Auth rework (open)",
+		"Design: Sessions move to Redis.",
+		"- car-m1: Add login (2026-10-01)",
+		"  Login uses the session store in internal/auth.",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("TRACK.md missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Old epic") || strings.Contains(md, "Other track") {
+		t.Errorf("TRACK.md includes finished epics or other tracks:\n%s", md)
+	}
+
+	if written, err := tc.Write(dir); err != nil || written {
+		t.Errorf("rewrite of unchanged sources = %v, %v; want the file left alone", written, err)
+	}
+	gormDB.Create(&models.TrackNote{Track: "backend", Content: "Use sqlc for queries", Author: "eng-2"})
+	tc, _ = LoadTrackContext(gormDB, track)
+	if written, _ := tc.Write(dir); !written {
+		t.Error("a new note should refresh TRACK.md")
+	}
+}
@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// warmStartWindow is how recently a car must have merged to be offered
+	// as prior work.
+	warmStartWindow = 14 * 24 * time.Hour
+	// maxPriorCars caps the related cars summarized in one prompt.
+	maxPriorCars = 3
+	// maxPriorFiles caps the files listed per prior car.
+	maxPriorFiles = 10
+	// maxPriorNotes is how many of a prior car's progress notes are shown.
+	maxPriorNotes = 2
+	// maxPriorNoteLen truncates each prior progress note.
+	maxPriorNoteLen = 500
+	// priorMergeScan is how many merge commits on the base branch are
+	// searched for the prior cars' merges.
+	priorMergeScan = 200
+)
+
+// PriorCar summarizes a recently merged car related to the one an engine is
+// starting, so the engine can build on that work instead of rediscovering
+// it.
+type PriorCar struct {
+	ID       string
+	Title    string
+	MergedAt time.Time
+	Reason   string   // why it is related, e.g. "same epic car-ab12"
+	DiffStat string   // git --shortstat of its merge; empty when the merge was not found
+	Files    []string // files its merge changed, capped at maxPriorFiles
+	Notes    []string // its last progress notes, oldest first
+}
+
+// RelatedPriorCars returns up to maxPriorCars cars merged in the last two
+// weeks that share c's epic, or whose merge changed a file c's description,
+// design notes, or acceptance criteria name. Merges are looked up on the
+// base branch in repoDir by their Car-ID trailer; when git history is not
+// available only epic siblings are found. Most recently merged first.
+func RelatedPriorCars(db *gorm.DB, repoDir string, c *models.Car) ([]PriorCar, error) {
+	if c == nil {
+		return nil, fmt.Errorf("engine: car is required")
+	}
+	var candidates []models.Car
+	if err := db.Where("status = ? AND type <> ? AND id <> ? AND completed_at > ?", "merged", "epic", c.ID, time.Now().Add(-warmStartWindow)).
+		Order("completed_at DESC").Limit(50).Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("engine: load prior cars: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	merges := mergesByCar(repoDir, c.BaseBranch)
+	text := c.Description + "\n" + c.DesignNotes + "\n" + c.Acceptance
+
+	var related []PriorCar
+	for _, cand := range candidates {
+		merge := merges[cand.ID]
+		var files []string
+		if merge != "" {
+			files = mergeFiles(repoDir, merge)
+		}
+
+		var reason string
+		if c.ParentID != nil && *c.ParentID != "" && cand.ParentID != nil && *cand.ParentID == *c.ParentID {
+			reason = "same epic " + *c.ParentID
+		} else if shared := filesNamedIn(files, text); len(shared) > 0 {
+			reason = "also changed " + strings.Join(shared, ", ")
+		} else {
+			continue
+		}
+
+		p := PriorCar{ID: cand.ID, Title: cand.Title, Reason: reason, Files: files}
+		if cand.CompletedAt != nil {
+			p.MergedAt = *cand.CompletedAt
+		}
+		if len(p.Files) > maxPriorFiles {
+			p.Files = append(p.Files[:maxPriorFiles:maxPriorFiles], fmt.Sprintf("(+%d more)", len(files)-maxPriorFiles))
+		}
+		if merge != "" {
+			p.DiffStat = gitText(repoDir, "diff", "--shortstat", merge+"^1", merge)
+		}
+		p.Notes = priorNotes(db, cand.ID)
+		related = append(related, p)
+		if len(related) == maxPriorCars {
+			break
+		}
+	}
+	return related, nil
+}
+
+// mergesByCar maps car IDs to the merge commits that landed them on the
+// base branch, read from the Car-ID trailer the yardmaster writes. Failures
+// yield an empty map.
+func mergesByCar(repoDir, baseBranch string) map[string]string {
+	merges := make(map[string]string)
+	if repoDir == "" {
+		return merges
+	}
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+	ref := baseBranch
+	if gitText(repoDir, "rev-parse", "--verify", "--quiet", "origin/"+baseBranch) != "" {
+		ref = "origin/" + baseBranch
+	}
+	out := gitText(repoDir, "log", "--merges", fmt.Sprintf("-%d", priorMergeScan),
+		"--format=%H %(trailers:key=Car-ID,valueonly,separator=%x20)", ref)
+	for _, line := range strings.Split(out, "\n") {
+		hash, carID, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || carID == "" {
+			continue
+		}
+		// The newest merge of a car wins (revisions merge again).
+		if _, seen := merges[carID]; !seen {
+			merges[carID] = hash
+		}
+	}
+	return merges
+}
+
+// mergeFiles lists the files a merge commit changed against its first parent.
+func mergeFiles(repoDir, merge string) []string {
+	out := gitText(repoDir, "diff", "--name-only", merge+"^1", merge)
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// filesNamedIn returns the files whose path appears in text.
+func filesNamedIn(files []string, text string) []string {
+	var named []string
+	for _, f := range files {
+		if strings.Contains(text, f) {
+			named = append(named, f)
+		}
+	}
+	return named
+}
+
+// priorNotes returns a car's last engine progress notes, oldest first,
+// leaving out the yardmaster's switch notes.
+func priorNotes(db *gorm.DB, carID string) []string {
+	var progress []models.CarProgress
+	if err := db.Where("car_id = ? AND engine_id <> ? AND note <> ?", carID, "yardmaster", "").
+		Order("created_at DESC, id DESC").Limit(maxPriorNotes).Find(&progress).Error; err != nil {
+		return nil
+	}
+	notes := make([]string, 0, len(progress))
+	for i := len(progress) - 1; i >= 0; i-- {
+		note := strings.TrimSpace(progress[i].Note)
+		if len(note) > maxPriorNoteLen {
+			note = note[:maxPriorNoteLen] + "..."
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// gitText runs git in repoDir and returns its trimmed output, or "" on error.
+func gitText(repoDir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writePriorCars summarizes related recently merged cars.
+func writePriorCars(w *strings.Builder, prior []PriorCar) {
+	if len(prior) == 0 {
+		return
+	}
+	w.WriteString("## Related Prior Work\n")
+	w.WriteString("These recently merged cars share this car's epic or files. Build on their approach and decisions instead of rediscovering them, but verify against the current code.\n\n")
+	for _, p := range prior {
+		fmt.Fprintf(w, "### %s: %s\n", p.ID, p.Title)
+		if !p.MergedAt.IsZero() {
+			fmt.Fprintf(w, "Merged %s (%s)\n", p.MergedAt.Format("2006-01-02"), p.Reason)
+		} else {
+			fmt.Fprintf(w, "Related: %s\n", p.Reason)
+		}
+		if p.DiffStat != "" {
+			fmt.Fprintf(w, "Diff: %s\n", p.DiffStat)
+		}
+		if len(p.Files) > 0 {
+			fmt.Fprintf(w, "Files: %s\n", strings.Join(p.Files, ", "))
+		}
+		for _, n := range p.Notes {
+			writeUserContent(w, n)
+		}
+		w.WriteString("\n")
+	}
+}
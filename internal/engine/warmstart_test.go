@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// commitMerge lands a branch changing file on main with a Car-ID trailer,
+// the way the yardmaster's switch does.
+func commitMerge(t *testing.T, dir, carID, file string) {
+	t.Helper()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s\n%s", args, err, out)
+		}
+	}
+	run("checkout", "-b", carID)
+	os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0o755)
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(carID+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-m", "work on "+carID)
+	run("checkout", "main")
+	run("merge", "--no-ff", carID, "-m", "Switch: merge "+carID+" to main\n\nCar-ID: "+carID)
+}
+
+func TestRelatedPriorCars(t *testing.T) {
+	gormDB := outcomeTestDB(t)
+	dir := initTestRepo(t)
+	commitMerge(t, dir, "car-p1", "internal/auth/login.go")
+	commitMerge(t, dir, "car-p3", "web/app.ts")
+	commitMerge(t, dir, "car-old", "internal/auth/login.go")
+
+	epic := "car-epic"
+	now := time.Now()
+	merged := func(id string, parent *string, ago time.Duration) {
+		at := now.Add(-ago)
+		gormDB.Create(&models.Car{ID: id, Title: "Title " + id, Status: "merged", Type: "task", ParentID: parent, CompletedAt: &at})
+	}
+	merged("car-p1", nil, time.Hour)
+	merged("car-p2", &epic, 2*time.Hour)
+	merged("car-p3", nil, 3*time.Hour)
+	merged("car-old", &epic, 30*24*time.Hour)
+	gormDB.Create(&models.CarProgress{CarID: "car-p1", EngineID: "eng-1", Note: "Chose bcrypt over scrypt for the login hash", FilesChanged: "[]"})
+	gormDB.Create(&models.CarProgress{CarID: "car-p1", EngineID: "yardmaster", Note: "switch:test-failed: boom", FilesChanged: "[]"})
+
+	current := &models.Car{ID: "car-new", ParentID: &epic, Description: "Add rate limiting to internal/auth/login.go"}
+	prior, err := RelatedPriorCars(gormDB, dir, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prior) != 2 {
+		t.Fatalf("prior = %+v, want car-p1 and car-p2", prior)
+	}
+
+	p1, p2 := prior[0], prior[1]
+	if p1.ID != "car-p1" || p1.Reason != "also changed internal/auth/login.go" || !strings.Contains(p1.DiffStat, "1 file changed") {
+		t.Errorf("car-p1 = %+v", p1)
+	}
+	if len(p1.Notes) != 1 || !strings.Contains(p1.Notes[0], "bcrypt") {
+		t.Errorf("car-p1 notes = %q, want the engine note only", p1.Notes)
+	}
+	if p2.ID != "car-p2" || p2.Reason != "same epic car-epic" || p2.DiffStat != "" {
+		t.Errorf("car-p2 = %+v", p2)
+	}
+
+	input := makeInput()
+	input.PriorCars = prior
+	out, err := RenderContext(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Related Prior Work",
+		"### car-p1: Title car-p1",
+		"(also changed internal/auth/login.go)",
+		"Files: internal/auth/login.go",
+		"<user-content>\nChose bcrypt over scrypt for the login hash\n</user-content>",
+		"### car-p2: Title car-p2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestRenderContext_NoPriorCars(t *testing.T) {
+	out, err := RenderContext(makeInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "## Related Prior Work") {
+		t.Error("prior work section rendered without prior cars")
+	}
+}
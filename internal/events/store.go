@@ -0,0 +1,123 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Record writes one event to the events table. The payload is stored as
+// JSON; its CarID and EngineID fields (or a TargetID naming either) are
+// copied into indexed columns so `ry events list --car` can find it.
+func Record(db *gorm.DB, kind, actor string, payload any) error {
+	if db == nil {
+		return fmt.Errorf("events: db is required")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("events: marshal %s payload: %w", kind, err)
+	}
+	ev := models.Event{Kind: kind, Actor: actor, Payload: string(data), CreatedAt: time.Now()}
+	ev.CarID, ev.EngineID = payloadIDs(data)
+	if ev.EngineID != "" && actor == "" {
+		ev.Actor = ev.EngineID
+	}
+	if err := db.Create(&ev).Error; err != nil {
+		return fmt.Errorf("events: record %s: %w", kind, err)
+	}
+	return nil
+}
+
+// payloadIDs pulls the car and engine IDs out of a JSON payload object.
+func payloadIDs(data []byte) (carID, engineID string) {
+	var fields struct {
+		CarID    string
+		EngineID string
+		TargetID string
+	}
+	if json.Unmarshal(data, &fields) != nil {
+		return "", ""
+	}
+	carID, engineID = fields.CarID, fields.EngineID
+	switch {
+	case fields.TargetID == "":
+	case strings.HasPrefix(fields.TargetID, "eng-"):
+		if engineID == "" {
+			engineID = fields.TargetID
+		}
+	case carID == "":
+		carID = fields.TargetID
+	}
+	return carID, engineID
+}
+
+// Persist subscribes to each topic on bus and records every event published
+// there, so the in-process events a yardmaster or engine already emits
+// survive the process. actor is stored on each row; when empty, the
+// payload's EngineID is used. Failed writes are logged, never retried.
+// The returned Unsubscribe stops recording.
+func Persist(bus Bus, db *gorm.DB, actor string, logger *slog.Logger, topics ...string) Unsubscribe {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	unsubs := make([]Unsubscribe, 0, len(topics))
+	for _, topic := range topics {
+		topic := topic
+		unsubs = append(unsubs, bus.Subscribe(topic, func(payload any) {
+			if err := Record(db, topic, actor, payload); err != nil {
+				logger.Warn("Event log write failed", "topic", topic, "error", err)
+			}
+		}))
+	}
+	return func() {
+		for _, u := range unsubs {
+			u()
+		}
+	}
+}
+
+// ListFilter narrows List. Zero fields match everything.
+type ListFilter struct {
+	Since    time.Time
+	CarID    string
+	EngineID string
+	Kind     string
+	Limit    int // newest Limit events; 0 means no limit
+}
+
+// List returns recorded events matching f, oldest first.
+func List(db *gorm.DB, f ListFilter) ([]models.Event, error) {
+	if db == nil {
+		return nil, fmt.Errorf("events: db is required")
+	}
+	q := db.Model(&models.Event{})
+	if !f.Since.IsZero() {
+		q = q.Where("created_at >= ?", f.Since)
+	}
+	if f.CarID != "" {
+		q = q.Where("car_id = ?", f.CarID)
+	}
+	if f.EngineID != "" {
+		q = q.Where("engine_id = ?", f.EngineID)
+	}
+	if f.Kind != "" {
+		q = q.Where("kind = ?", f.Kind)
+	}
+	q = q.Order("created_at DESC, id DESC")
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	var evs []models.Event
+	if err := q.Find(&evs).Error; err != nil {
+		return nil, fmt.Errorf("events: list: %w", err)
+	}
+	for i, j := 0, len(evs)-1; i < j; i, j = i+1, j-1 {
+		evs[i], evs[j] = evs[j], evs[i]
+	}
+	return evs, nil
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func storeTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Event{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+type statusPayload struct {
+	CarID     string
+	OldStatus string
+	NewStatus string
+}
+
+type actionPayload struct {
+	TargetID   string
+	ActionType string
+}
+
+func TestRecordAndList(t *testing.T) {
+	db := storeTestDB(t)
+	records := []struct {
+		kind, actor string
+		payload     any
+	}{
+		{"CarStatusChanged", "eng-1", statusPayload{CarID: "car-1", OldStatus: "open", NewStatus: "claimed"}},
+		{"YardmasterAction", "yardmaster", actionPayload{TargetID: "eng-2", ActionType: "restart"}},
+		{"YardmasterAction", "yardmaster", actionPayload{TargetID: "car-1", ActionType: "reassign"}},
+		{"EngineStarted", "", struct{ EngineID, Track string }{"eng-3", "backend"}},
+	}
+	for _, r := range records {
+		if err := Record(db, r.kind, r.actor, r.payload); err != nil {
+			t.Fatalf("Record(%s): %v", r.kind, err)
+		}
+	}
+
+	car, err := List(db, ListFilter{CarID: "car-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(car) != 2 || car[0].Kind != "CarStatusChanged" || car[1].Kind != "YardmasterAction" {
+		t.Errorf("car-1 events = %+v, want status change then reassign, oldest first", car)
+	}
+
+	eng, _ := List(db, ListFilter{EngineID: "eng-2"})
+	if len(eng) != 1 || eng[0].Actor != "yardmaster" {
+		t.Errorf("eng-2 events = %+v, want the restart", eng)
+	}
+	started, _ := List(db, ListFilter{Kind: "EngineStarted"})
+	if len(started) != 1 || started[0].Actor != "eng-3" || started[0].EngineID != "eng-3" {
+		t.Errorf("EngineStarted = %+v, want actor and engine eng-3", started)
+	}
+
+	if evs, _ := List(db, ListFilter{Since: time.Now().Add(time.Hour)}); len(evs) != 0 {
+		t.Errorf("future since = %d events, want 0", len(evs))
+	}
+	if evs, _ := List(db, ListFilter{Limit: 2}); len(evs) != 2 || evs[1].Kind != "EngineStarted" {
+		t.Errorf("limit 2 = %+v, want the newest two", evs)
+	}
+}
+
+func TestPersist(t *testing.T) {
+	db := storeTestDB(t)
+	bus := NewBus()
+	defer closeBus(t, bus)
+	unsub := Persist(bus, db, "yardmaster", nil, "CarMerged")
+
+	bus.Publish("CarMerged", struct{ CarID, Branch string }{"car-9", "ry/car-9"})
+	bus.Publish("NotPersisted", struct{ CarID string }{"car-9"})
+
+	count := func() int64 {
+		var n int64
+		db.Model(&models.Event{}).Count(&n)
+		return n
+	}
+	if !waitFor(t, func() bool { return count() == 1 }, time.Second) {
+		t.Fatalf("events recorded = %d, want 1", count())
+	}
+	unsub()
+	bus.Publish("CarMerged", struct{ CarID string }{"car-10"})
+	time.Sleep(20 * time.Millisecond)
+	if n := count(); n != 1 {
+		t.Errorf("events after unsubscribe = %d, want 1", n)
+	}
+}
@@ -0,0 +1,266 @@
+// Package find searches the yard's records — cars, epics, progress notes,
+// engine journals, dispatch conversations, and track notes — for a term, so
+// an operator can look something up without knowing which command owns it.
+package find
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Kind names a group of results.
+type Kind string
+
+const (
+	KindCar       Kind = "car"
+	KindEpic      Kind = "epic"
+	KindProgress  Kind = "progress"
+	KindJournal   Kind = "journal"
+	KindSession   Kind = "session"
+	KindTrackNote Kind = "note"
+)
+
+// DefaultLimit is how many hits each group shows when the caller passes no
+// limit.
+const DefaultLimit = 10
+
+// snippetRadius is how much text around the match a snippet keeps.
+const snippetRadius = 40
+
+// Hit is one matching record.
+type Hit struct {
+	ID      string    // car ID, or the record's row ID
+	CarID   string    // car the record belongs to; empty when none
+	Title   string    // short label, e.g. the car title or journal entry kind
+	Snippet string    // the matched text with some context
+	At      time.Time // when the record was created
+	Link    string    // command that shows more, e.g. "ry car show car-1"
+}
+
+// Group holds the hits of one kind, newest or highest priority first.
+type Group struct {
+	Kind  Kind
+	Label string
+	Hits  []Hit
+	More  bool // more hits matched than the limit allowed
+}
+
+// Search returns the groups with at least one hit for query, in a fixed
+// order: epics, cars, progress notes, journals, sessions, track notes.
+// Matching is a case-insensitive substring match. limit caps each group;
+// zero or less uses DefaultLimit.
+func Search(db *gorm.DB, query string, limit int) ([]Group, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("find: query is required")
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	pattern := "%" + strings.ToLower(query) + "%"
+
+	searches := []struct {
+		kind  Kind
+		label string
+		run   func(db *gorm.DB, query, pattern string, n int) ([]Hit, error)
+	}{
+		{KindEpic, "Epics", func(db *gorm.DB, q, p string, n int) ([]Hit, error) { return findCars(db, q, p, n, true) }},
+		{KindCar, "Cars", func(db *gorm.DB, q, p string, n int) ([]Hit, error) { return findCars(db, q, p, n, false) }},
+		{KindProgress, "Progress notes", findProgress},
+		{KindJournal, "Engine journals", findJournal},
+		{KindSession, "Dispatch sessions", findSessions},
+		{KindTrackNote, "Track notes", findTrackNotes},
+	}
+
+	var groups []Group
+	for _, s := range searches {
+		hits, err := s.run(db, query, pattern, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		g := Group{Kind: s.kind, Label: s.label, Hits: hits}
+		if len(hits) > limit {
+			g.Hits, g.More = hits[:limit], true
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func findCars(db *gorm.DB, query, pattern string, n int, epics bool) ([]Hit, error) {
+	q := db.Model(&models.Car{}).Where(
+		"(LOWER(id) LIKE ? OR LOWER(title) LIKE ? OR LOWER(description) LIKE ? OR LOWER(design_notes) LIKE ? OR LOWER(acceptance) LIKE ?)",
+		pattern, pattern, pattern, pattern, pattern)
+	if epics {
+		q = q.Where("type = ?", "epic")
+	} else {
+		q = q.Where("type <> ?", "epic")
+	}
+	var cars []models.Car
+	if err := q.Order("priority ASC, created_at DESC").Limit(n).Find(&cars).Error; err != nil {
+		return nil, fmt.Errorf("find: cars: %w", err)
+	}
+	hits := make([]Hit, 0, len(cars))
+	for _, c := range cars {
+		h := Hit{
+			ID:      c.ID,
+			CarID:   c.ID,
+			Title:   fmt.Sprintf("%s [%s, %s]", c.Title, c.Status, c.Track),
+			Snippet: snippet(query, c.Title, c.Description, c.DesignNotes, c.Acceptance),
+			At:      c.CreatedAt,
+			Link:    "ry car show " + c.ID,
+		}
+		if epics {
+			h.Link = "ry car children " + c.ID
+		}
+		hits = append(hits, h)
+	}
+	return hits, nil
+}
+
+func findProgress(db *gorm.DB, query, pattern string, n int) ([]Hit, error) {
+	var rows []models.CarProgress
+	if err := db.Where("LOWER(note) LIKE ?", pattern).
+		Order("created_at DESC, id DESC").Limit(n).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("find: progress notes: %w", err)
+	}
+	hits := make([]Hit, 0, len(rows))
+	for _, p := range rows {
+		hits = append(hits, Hit{
+			ID:      fmt.Sprint(p.ID),
+			CarID:   p.CarID,
+			Title:   p.EngineID,
+			Snippet: snippet(query, p.Note),
+			At:      p.CreatedAt,
+			Link:    "ry car show " + p.CarID,
+		})
+	}
+	return hits, nil
+}
+
+func findJournal(db *gorm.DB, query, pattern string, n int) ([]Hit, error) {
+	var rows []models.JournalEntry
+	if err := db.Where("LOWER(detail) LIKE ?", pattern).
+		Order("created_at DESC, id DESC").Limit(n).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("find: journals: %w", err)
+	}
+	hits := make([]Hit, 0, len(rows))
+	for _, e := range rows {
+		title := e.Kind
+		if e.EngineID != "" {
+			title += " by " + e.EngineID
+		}
+		hits = append(hits, Hit{
+			ID:      fmt.Sprint(e.ID),
+			CarID:   e.CarID,
+			Title:   title,
+			Snippet: snippet(query, e.Detail),
+			At:      e.CreatedAt,
+			Link:    "ry car journal " + e.CarID,
+		})
+	}
+	return hits, nil
+}
+
+// findSessions matches dispatch conversation turns, one hit per session.
+func findSessions(db *gorm.DB, query, pattern string, n int) ([]Hit, error) {
+	var turns []models.TelegraphConversation
+	if err := db.Where("LOWER(content) LIKE ?", pattern).
+		Order("created_at DESC, id DESC").Limit(n * 5).Find(&turns).Error; err != nil {
+		return nil, fmt.Errorf("find: dispatch sessions: %w", err)
+	}
+	var hits []Hit
+	seen := make(map[uint]bool)
+	for _, t := range turns {
+		if seen[t.SessionID] {
+			continue
+		}
+		seen[t.SessionID] = true
+		title := t.Role
+		if t.UserName != "" {
+			title += " " + t.UserName
+		}
+		hits = append(hits, Hit{
+			ID:      fmt.Sprint(t.SessionID),
+			Title:   title,
+			Snippet: snippet(query, t.Content),
+			At:      t.CreatedAt,
+			Link:    fmt.Sprintf("ry telegraph sessions export %d", t.SessionID),
+		})
+		if len(hits) == n {
+			break
+		}
+	}
+	return hits, nil
+}
+
+func findTrackNotes(db *gorm.DB, query, pattern string, n int) ([]Hit, error) {
+	var notes []models.TrackNote
+	if err := db.Where("LOWER(content) LIKE ?", pattern).
+		Order("created_at DESC, id DESC").Limit(n).Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("find: track notes: %w", err)
+	}
+	hits := make([]Hit, 0, len(notes))
+	for _, note := range notes {
+		hits = append(hits, Hit{
+			ID:      fmt.Sprint(note.ID),
+			CarID:   note.CarID,
+			Title:   note.Track,
+			Snippet: snippet(query, note.Content),
+			At:      note.CreatedAt,
+			Link:    "ry note list --track " + note.Track,
+		})
+	}
+	return hits, nil
+}
+
+// snippet returns the text around the first case-insensitive match of query
+// in the first field that contains it, on one line. When no field matches
+// (e.g. a car matched on its ID) the first non-empty field is shortened.
+func snippet(query string, fields ...string) string {
+	lq := strings.ToLower(query)
+	for _, f := range fields {
+		f = strings.Join(strings.Fields(f), " ")
+		lf := strings.ToLower(f)
+		i := strings.Index(lf, lq)
+		if i < 0 || len(lf) != len(f) {
+			continue
+		}
+		start, end := max(i-snippetRadius, 0), min(i+len(query)+snippetRadius, len(f))
+		for start > 0 && !utf8.RuneStart(f[start]) {
+			start--
+		}
+		for end < len(f) && !utf8.RuneStart(f[end]) {
+			end++
+		}
+		s := f[start:end]
+		if start > 0 {
+			s = "..." + s
+		}
+		if end < len(f) {
+			s += "..."
+		}
+		return s
+	}
+	for _, f := range fields {
+		if f = strings.Join(strings.Fields(f), " "); f != "" {
+			if len(f) > 2*snippetRadius {
+				cut := 2 * snippetRadius
+				for cut > 0 && !utf8.RuneStart(f[cut]) {
+					cut--
+				}
+				f = f[:cut] + "..."
+			}
+			return f
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package find
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.CarProgress{}, &models.JournalEntry{},
+		&models.DispatchSession{}, &models.TelegraphConversation{}, &models.TrackNote{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestSearch_GroupsEveryKind(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-epic", Title: "Payments revamp", Type: "epic", Track: "backend", Status: "open"})
+	db.Create(&models.Car{ID: "car-1", Title: "Refund endpoint", Description: "Add refunds to the PAYMENTS service.", Type: "task", Track: "backend", Status: "open"})
+	db.Create(&models.Car{ID: "car-2", Title: "Unrelated", Type: "task", Track: "backend"})
+	db.Create(&models.CarProgress{CarID: "car-1", EngineID: "eng-1", Note: "Wired payments client"})
+	db.Create(&models.JournalEntry{CarID: "car-1", EngineID: "eng-1", Kind: "command", Detail: "go test ./payments/..."})
+	db.Create(&models.DispatchSession{ID: 7, Source: "telegraph", UserName: "alice"})
+	db.Create(&models.TelegraphConversation{SessionID: 7, Sequence: 1, Role: "user", UserName: "alice", Content: "split the payments work"})
+	db.Create(&models.TelegraphConversation{SessionID: 7, Sequence: 2, Role: "assistant", Content: "payments epic created"})
+	db.Create(&models.TrackNote{Track: "backend", Content: "payments tests need STRIPE_KEY", Author: "eng-1"})
+
+	groups, err := Search(db, "Payments", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, g := range groups {
+		kinds = append(kinds, fmt.Sprintf("%s:%d", g.Kind, len(g.Hits)))
+	}
+	if got := strings.Join(kinds, " "); got != "epic:1 car:1 progress:1 journal:1 session:1 note:1" {
+		t.Fatalf("groups = %s", got)
+	}
+	if h := groups[0].Hits[0]; h.Link != "ry car children car-epic" {
+		t.Errorf("epic link = %q", h.Link)
+	}
+	car := groups[1].Hits[0]
+	if car.ID != "car-1" || car.Link != "ry car show car-1" || car.Snippet != "Add refunds to the PAYMENTS service." {
+		t.Errorf("car hit = %+v", car)
+	}
+	if s := groups[4].Hits[0]; s.ID != "7" || s.Link != "ry telegraph sessions export 7" {
+		t.Errorf("session hit = %+v", s)
+	}
+	if n := groups[5].Hits[0]; n.Link != "ry note list --track backend" {
+		t.Errorf("note hit = %+v", n)
+	}
+}
+
+func TestSearch_LimitAndEmptyQuery(t *testing.T) {
+	db := testDB(t)
+	for i := 0; i < 3; i++ {
+		db.Create(&models.Car{ID: fmt.Sprintf("car-%d", i), Title: "ledger fix", Track: "backend"})
+	}
+	groups, err := Search(db, "ledger", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || len(groups[0].Hits) != 2 || !groups[0].More {
+		t.Errorf("groups = %+v", groups)
+	}
+	if _, err := Search(db, "  ", 0); err == nil {
+		t.Error("empty query accepted")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", 60) + " needle " + strings.Repeat("b", 60)
+	got := snippet("NEEDLE", "", long)
+	if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") || !strings.Contains(got, "needle") {
+		t.Errorf("snippet = %q", got)
+	}
+	if got := snippet("car-1", "Title\nwith  spaces"); got != "Title with spaces" {
+		t.Errorf("no-match snippet = %q", got)
+	}
+}
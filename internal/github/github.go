@@ -0,0 +1,189 @@
+// Package github is the GitHub REST client shared by the yardmaster, bull,
+// and inspect. It adds, underneath any caller's own API wrapper, what every
+// caller needs once several daemons poll the same repository: token
+// handling, waiting out primary and secondary rate limits instead of failing,
+// ETag caching of GETs (a 304 does not count against the rate limit), and
+// request metrics.
+//
+// Callers that use google/go-github pass HTTPClient() to github.NewClient;
+// callers that only need a few endpoints use GetJSON and SendJSON directly.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// DefaultBaseURL is the public GitHub REST API.
+const DefaultBaseURL = "https://api.github.com/"
+
+// Options configures New. The zero value is usable: unauthenticated requests
+// to the public API over http.DefaultTransport.
+type Options struct {
+	// Token is sent as a bearer token on requests that carry no
+	// Authorization header of their own. Leave it empty when Base already
+	// authenticates (e.g. a GitHub App installation transport).
+	Token string
+	// Base performs the actual requests (default http.DefaultTransport).
+	Base http.RoundTripper
+	// BaseURL is the API root for GetJSON (default DefaultBaseURL); set it
+	// for GitHub Enterprise.
+	BaseURL string
+	// MinRemaining makes requests wait for the rate limit to reset once
+	// fewer than this many calls remain in the window (default 100).
+	MinRemaining int
+	// MaxRetries bounds retries of a request that hit a rate limit
+	// (default 3).
+	MaxRetries int
+	// CacheSize bounds the number of ETag-cached GET responses
+	// (default 256; negative disables caching).
+	CacheSize int
+	// Clock drives rate-limit waits (default clock.Real).
+	Clock clock.Clock
+	// Logger receives rate-limit waits (default slog.Default()).
+	Logger *slog.Logger
+}
+
+// Client is a rate-limit-aware GitHub client. It is safe for concurrent use;
+// share one per token so all callers see the same rate-limit state.
+type Client struct {
+	http    *http.Client
+	baseURL *url.URL
+	t       *transport
+}
+
+// New returns a Client configured by opts.
+func New(opts Options) *Client {
+	if opts.Base == nil {
+		opts.Base = http.DefaultTransport
+	}
+	if opts.MinRemaining == 0 {
+		opts.MinRemaining = 100
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.CacheSize == 0 {
+		opts.CacheSize = 256
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	base, err := url.Parse(opts.BaseURL)
+	if opts.BaseURL == "" || err != nil {
+		base, _ = url.Parse(DefaultBaseURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	t := newTransport(opts)
+	return &Client{
+		http:    &http.Client{Transport: t, Timeout: 60 * time.Second},
+		baseURL: base,
+		t:       t,
+	}
+}
+
+// HTTPClient returns an *http.Client that routes through the shared
+// transport, for wrapping by google/go-github.
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Metrics returns a snapshot of the client's request counters.
+func (c *Client) Metrics() Stats {
+	return c.t.metrics.snapshot()
+}
+
+// GetJSON fetches path (relative to the API root, e.g.
+// "repos/o/r/commits/main/check-runs") and decodes the JSON body into v.
+func (c *Client) GetJSON(ctx context.Context, path string, v any) error {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return fmt.Errorf("github: bad path %q: %w", path, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("github: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("github: GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{StatusCode: resp.StatusCode, Path: path, Message: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("github: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// SendJSON sends body, encoded as JSON, to path with method (e.g. POST or
+// PATCH) and decodes a JSON response into v unless v is nil. Any 2xx status
+// is success.
+func (c *Client) SendJSON(ctx context.Context, method, path string, body, v any) error {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return fmt.Errorf("github: bad path %q: %w", path, err)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("github: encode %s %s: %w", method, path, err)
+	}
+	// A bytes.Reader body gets GetBody set, so rate-limit retries can resend it.
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("github: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("github: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{StatusCode: resp.StatusCode, Path: path, Message: strings.TrimSpace(string(msg))}
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("github: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// APIError is a non-2xx response from GetJSON or SendJSON.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github: GET %s: %d %s", e.Path, e.StatusCode, e.Message)
+}
+
+// TokenFromEnv returns the token from GITHUB_TOKEN or, failing that,
+// GH_TOKEN (the variable the gh CLI reads), or "".
+func TokenFromEnv() string {
+	if t := os.Getenv("GITHUB_TOKEN"); t != "" {
+		return t
+	}
+	return os.Getenv("GH_TOKEN")
+}
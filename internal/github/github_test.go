@@ -0,0 +1,281 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// instantClock records requested waits and returns from them immediately.
+type instantClock struct {
+	clock.Clock
+	now time.Time
+
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func newInstantClock() *instantClock {
+	return &instantClock{Clock: clock.Real, now: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *instantClock) Now() time.Time { return c.now }
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *instantClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+func newTestClient(t *testing.T, h http.HandlerFunc, opts Options) (*Client, *instantClock) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	clk := newInstantClock()
+	opts.BaseURL = srv.URL
+	opts.Clock = clk
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(opts), clk
+}
+
+func TestGetJSON_TokenAndHeaders(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.Header.Get("Accept") != "application/vnd.github+json" || r.Header.Get("X-GitHub-Api-Version") == "" {
+			t.Errorf("missing GitHub headers: %v", r.Header)
+		}
+		if r.URL.Path != "/repos/o/r" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"full_name":"o/r"}`)
+	}, Options{Token: "tok"})
+
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if err := c.GetJSON(context.Background(), "repos/o/r", &repo); err != nil {
+		t.Fatal(err)
+	}
+	if repo.FullName != "o/r" {
+		t.Errorf("full_name = %q", repo.FullName)
+	}
+}
+
+func TestGetJSON_APIError(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	}, Options{})
+
+	err := c.GetJSON(context.Background(), "repos/o/missing", &struct{}{})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusNotFound || !strings.Contains(apiErr.Message, "Not Found") {
+		t.Errorf("err = %v, want 404 APIError", err)
+	}
+}
+
+func TestSendJSON(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/repos/o/r/issues/comments/7" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"body":"hi"}` {
+			t.Errorf("body = %s", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":7}`)
+	}, Options{})
+
+	var got struct {
+		ID int `json:"id"`
+	}
+	if err := c.SendJSON(context.Background(), http.MethodPatch, "repos/o/r/issues/comments/7", map[string]string{"body": "hi"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 7 {
+		t.Errorf("id = %d", got.ID)
+	}
+}
+
+func TestTransport_ETagCache(t *testing.T) {
+	var hits atomic.Int32
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"n":1}`)
+	}, Options{})
+
+	for i := 0; i < 3; i++ {
+		var v struct{ N int }
+		if err := c.GetJSON(context.Background(), "x", &v); err != nil {
+			t.Fatalf("get %d: %v", i, err)
+		}
+		if v.N != 1 {
+			t.Errorf("get %d: n = %d, want the cached body", i, v.N)
+		}
+	}
+	m := c.Metrics()
+	if hits.Load() != 3 || m.Requests != 3 || m.CacheHits != 2 {
+		t.Errorf("hits = %d, metrics = %+v; want 3 requests, 2 served from cache", hits.Load(), m)
+	}
+}
+
+func TestTransport_SecondaryLimitRetryAfter(t *testing.T) {
+	var hits atomic.Int32
+	c, clk := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}, Options{})
+
+	if err := c.GetJSON(context.Background(), "x", &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if waits := clk.Waits(); len(waits) != 1 || waits[0] != 7*time.Second {
+		t.Errorf("waits = %v, want [7s]", waits)
+	}
+	if m := c.Metrics(); m.SecondaryLimits != 1 || m.Requests != 2 {
+		t.Errorf("metrics = %+v", m)
+	}
+}
+
+func TestTransport_SecondaryLimitFromMessage(t *testing.T) {
+	var hits atomic.Int32
+	c, clk := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit. Please wait."}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}, Options{})
+
+	if err := c.GetJSON(context.Background(), "x", &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if waits := clk.Waits(); len(waits) != 1 || waits[0] != defaultSecondaryWait {
+		t.Errorf("waits = %v, want [%v]", waits, defaultSecondaryWait)
+	}
+}
+
+func TestTransport_ForbiddenIsNotRetried(t *testing.T) {
+	var hits atomic.Int32
+	c, clk := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+	}, Options{})
+
+	err := c.GetJSON(context.Background(), "x", &struct{}{})
+	if apiErr, ok := err.(*APIError); !ok || !strings.Contains(apiErr.Message, "not accessible") {
+		t.Errorf("err = %v, want the 403 body", err)
+	}
+	if hits.Load() != 1 || len(clk.Waits()) != 0 {
+		t.Errorf("hits = %d, waits = %v; want one request, no waits", hits.Load(), clk.Waits())
+	}
+}
+
+func TestTransport_PrimaryLimitWaitsForReset(t *testing.T) {
+	clkNow := time.Unix(1_700_000_000, 0)
+	reset := clkNow.Add(90 * time.Second)
+	var hits atomic.Int32
+	c, clk := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		if n == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		fmt.Fprint(w, `{}`)
+	}, Options{MaxRetries: 1})
+
+	if err := c.GetJSON(context.Background(), "x", &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if waits := clk.Waits(); len(waits) == 0 || waits[0] != 90*time.Second {
+		t.Errorf("waits = %v, want to wait 90s for the reset", waits)
+	}
+	if m := c.Metrics(); m.Remaining != 4999 || m.PrimaryWaits == 0 {
+		t.Errorf("metrics = %+v", m)
+	}
+}
+
+func TestTransport_WaitsWhenRemainingLow(t *testing.T) {
+	clkNow := time.Unix(1_700_000_000, 0)
+	c, clk := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "5")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(clkNow.Add(30*time.Second).Unix()))
+		fmt.Fprint(w, `{}`)
+	}, Options{MinRemaining: 10})
+
+	for i := 0; i < 2; i++ {
+		if err := c.GetJSON(context.Background(), "x", &struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if waits := clk.Waits(); len(waits) != 1 || waits[0] != 30*time.Second {
+		t.Errorf("waits = %v, want one 30s wait before the second request", waits)
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	var hits atomic.Int32
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, Options{MaxRetries: 2})
+
+	err := c.GetJSON(context.Background(), "x", &struct{}{})
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("err = %v, want 429", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("hits = %d, want 1 + 2 retries", hits.Load())
+	}
+}
+
+func TestTokenFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh")
+	if got := TokenFromEnv(); got != "gh" {
+		t.Errorf("TokenFromEnv() = %q, want GH_TOKEN", got)
+	}
+	t.Setenv("GITHUB_TOKEN", "github")
+	if got := TokenFromEnv(); got != "github" {
+		t.Errorf("TokenFromEnv() = %q, want GITHUB_TOKEN", got)
+	}
+}
@@ -0,0 +1,45 @@
+package github
+
+import "sync/atomic"
+
+// Stats is a snapshot of a Client's request counters.
+type Stats struct {
+	Requests        int64 // requests sent to GitHub, retries included
+	CacheHits       int64 // GETs answered 304 and served from the ETag cache
+	PrimaryWaits    int64 // waits for the primary rate-limit window to reset
+	SecondaryLimits int64 // secondary (abuse) rate-limit rejections
+	Errors          int64 // transport errors and 5xx responses
+	Remaining       int64 // calls left in the window per the last response; -1 if unknown
+}
+
+type metrics struct {
+	requests        atomic.Int64
+	cacheHits       atomic.Int64
+	primaryWaits    atomic.Int64
+	secondaryLimits atomic.Int64
+	errors          atomic.Int64
+	remaining       atomic.Int64
+	seen            atomic.Bool // remaining has been set
+}
+
+func (m *metrics) add(c *atomic.Int64) { c.Add(1) }
+
+func (m *metrics) setRemaining(n int) {
+	m.remaining.Store(int64(n))
+	m.seen.Store(true)
+}
+
+func (m *metrics) snapshot() Stats {
+	s := Stats{
+		Requests:        m.requests.Load(),
+		CacheHits:       m.cacheHits.Load(),
+		PrimaryWaits:    m.primaryWaits.Load(),
+		SecondaryLimits: m.secondaryLimits.Load(),
+		Errors:          m.errors.Load(),
+		Remaining:       -1,
+	}
+	if m.seen.Load() {
+		s.Remaining = m.remaining.Load()
+	}
+	return s
+}
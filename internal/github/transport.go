@@ -0,0 +1,273 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// defaultSecondaryWait is how long to back off from a secondary rate limit
+// that did not say when to retry. GitHub documents "at least one minute".
+const defaultSecondaryWait = time.Minute
+
+// transport is the http.RoundTripper behind Client.
+type transport struct {
+	base         http.RoundTripper
+	token        string
+	minRemaining int
+	maxRetries   int
+	clock        clock.Clock
+	logger       *slog.Logger
+	metrics      *metrics
+	cache        *etagCache // nil when caching is disabled
+
+	mu        sync.Mutex
+	remaining int       // calls left in the current window; -1 until known
+	reset     time.Time // when the window resets
+}
+
+func newTransport(opts Options) *transport {
+	t := &transport{
+		base:         opts.Base,
+		token:        opts.Token,
+		minRemaining: opts.MinRemaining,
+		maxRetries:   opts.MaxRetries,
+		clock:        clock.OrReal(opts.Clock),
+		logger:       opts.Logger,
+		metrics:      &metrics{},
+		remaining:    -1,
+	}
+	if opts.CacheSize > 0 {
+		t.cache = newETagCache(opts.CacheSize)
+	}
+	return t
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.token != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	if req.Header.Get("X-GitHub-Api-Version") == "" {
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	}
+
+	var cached *cacheEntry
+	if t.cache != nil && req.Method == http.MethodGet && req.Header.Get("If-None-Match") == "" {
+		if cached = t.cache.get(cacheKey(req)); cached != nil {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForWindow(req.Context()); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		t.metrics.add(&t.metrics.requests)
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			t.metrics.add(&t.metrics.errors)
+			return nil, err
+		}
+		t.observe(resp)
+
+		wait, limited := t.rateLimitWait(resp)
+		if !limited {
+			if resp.StatusCode >= 500 {
+				t.metrics.add(&t.metrics.errors)
+			}
+			return t.finish(req, resp, cached)
+		}
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if attempt >= t.maxRetries || !replayable {
+			return resp, nil
+		}
+		resp.Body.Close()
+		t.logger.Warn("GitHub rate limited, waiting", "method", req.Method, "path", req.URL.Path, "wait", wait, "attempt", attempt+1)
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// finish serves a 304 from the cache and records cacheable 200s.
+// Unwrap returns the transport requests are sent through, e.g. the
+// authenticating transport a caller passed as Options.Base.
+func (t *transport) Unwrap() http.RoundTripper { return t.base }
+
+func (t *transport) finish(req *http.Request, resp *http.Response, cached *cacheEntry) (*http.Response, error) {
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		t.metrics.add(&t.metrics.cacheHits)
+		resp.Body.Close()
+		header := cached.header.Clone()
+		for k, v := range resp.Header {
+			if strings.HasPrefix(k, "X-Ratelimit-") {
+				header[k] = v
+			}
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       req,
+		}, nil
+	}
+	etag := resp.Header.Get("ETag")
+	if t.cache == nil || req.Method != http.MethodGet || resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.cache.put(cacheKey(req), &cacheEntry{etag: etag, header: resp.Header.Clone(), body: body})
+	return resp, nil
+}
+
+// observe records the rate-limit window reported by resp.
+func (t *transport) observe(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	t.mu.Lock()
+	t.remaining = remaining
+	if reset > 0 {
+		t.reset = time.Unix(reset, 0)
+	}
+	t.mu.Unlock()
+	t.metrics.setRemaining(remaining)
+}
+
+// waitForWindow blocks until the rate-limit window resets when the last
+// response left fewer than minRemaining calls.
+func (t *transport) waitForWindow(ctx context.Context) error {
+	t.mu.Lock()
+	remaining := t.remaining
+	wait := t.reset.Sub(t.clock.Now())
+	t.mu.Unlock()
+	if remaining < 0 || remaining >= t.minRemaining || wait <= 0 {
+		return nil
+	}
+	t.metrics.add(&t.metrics.primaryWaits)
+	t.logger.Warn("GitHub rate limit low, waiting for reset", "remaining", remaining, "wait", wait)
+	if err := t.sleep(ctx, wait); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.remaining = -1 // unknown until the next response
+	t.mu.Unlock()
+	return nil
+}
+
+// rateLimitWait reports whether resp is a rate-limit rejection and how long
+// to wait before retrying. Primary limits (no calls left) wait for the
+// window reset; secondary limits honor Retry-After.
+func (t *transport) rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		t.metrics.add(&t.metrics.secondaryLimits)
+		return time.Duration(secs) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		t.metrics.add(&t.metrics.primaryWaits)
+		t.mu.Lock()
+		wait := t.reset.Sub(t.clock.Now())
+		t.mu.Unlock()
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return wait, true
+	}
+	// A 403 is also how GitHub reports missing permissions; only the
+	// message tells a secondary limit apart.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		t.metrics.add(&t.metrics.secondaryLimits)
+		return defaultSecondaryWait, true
+	}
+	return 0, false
+}
+
+func (t *transport) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.clock.After(d):
+		return nil
+	}
+}
+
+// cacheKey identifies a GET for ETag caching. The Authorization header is
+// part of the key so responses are never served across identities.
+func cacheKey(req *http.Request) string {
+	return req.Header.Get("Authorization") + " " + req.URL.String()
+}
+
+type cacheEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// etagCache is a bounded map of GET responses, evicting the oldest entry.
+type etagCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*cacheEntry
+	order   []string
+}
+
+func newETagCache(max int) *etagCache {
+	return &etagCache{max: max, entries: make(map[string]*cacheEntry)}
+}
+
+func (c *etagCache) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key]
+}
+
+func (c *etagCache) put(key string, e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+		if len(c.order) > c.max {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.entries[key] = e
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v68/github"
 	"github.com/zulandar/railyard/internal/config"
+	ghapi "github.com/zulandar/railyard/internal/github"
 )
 
 // InlineComment represents a single inline review comment on a PR diff.
@@ -31,7 +32,8 @@ type GitHubClient struct {
 }
 
 // NewGitHubClient constructs a GitHubClient authenticated as a GitHub App
-// installation using the credentials in cfg.
+// installation using the credentials in cfg. Requests go through the shared
+// rate-limited client.
 func NewGitHubClient(owner, repo string, cfg config.InspectConfig) (*GitHubClient, error) {
 	itr, err := ghinstallation.NewKeyFromFile(
 		http.DefaultTransport, cfg.AppID, cfg.InstallationID, cfg.PrivateKeyPath,
@@ -39,7 +41,7 @@ func NewGitHubClient(owner, repo string, cfg config.InspectConfig) (*GitHubClien
 	if err != nil {
 		return nil, fmt.Errorf("inspect: github app auth: %w", err)
 	}
-	tc := &http.Client{Transport: itr}
+	tc := ghapi.New(ghapi.Options{Base: itr}).HTTPClient()
 	return &GitHubClient{
 		client:             github.NewClient(tc),
 		owner:              owner,
@@ -0,0 +1,423 @@
+// Package issueimport creates cars from open GitHub issues (ry car import
+// github) and reports each car's progress back to its issue as a comment.
+//
+// Imported issues are recorded in bull_issues with triage mode "import", so
+// Bull does not triage them a second time and the merge trailers link each
+// car's commits to its issue.
+package issueimport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/car"
+	ghapi "github.com/zulandar/railyard/internal/github"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Issue is an open GitHub issue as returned by the issues API.
+type Issue struct {
+	Number    int             `json:"number"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	HTMLURL   string          `json:"html_url"`
+	Labels    []Label         `json:"labels"`
+	Milestone *Milestone      `json:"milestone"`
+	PR        json.RawMessage `json:"pull_request"` // set on pull requests, which the API lists as issues
+}
+
+// Label is a GitHub issue label.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Milestone is a GitHub milestone.
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// Client is the GitHub access Import and SyncStatus need.
+type Client interface {
+	// ListIssues returns the repository's open issues carrying label
+	// (all open issues when label is empty), pull requests excluded.
+	ListIssues(ctx context.Context, label string) ([]Issue, error)
+	// Comment posts body as a comment on issue number.
+	Comment(ctx context.Context, number int, body string) error
+}
+
+// GitHub is a Client for one repository over the REST API.
+type GitHub struct {
+	api         *ghapi.Client
+	owner, name string
+}
+
+// NewGitHub returns a Client for repo ("owner/name").
+func NewGitHub(api *ghapi.Client, repo string) (*GitHub, error) {
+	owner, name, ok := strings.Cut(strings.Trim(repo, "/"), "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("issueimport: repo %q is not owner/name", repo)
+	}
+	return &GitHub{api: api, owner: owner, name: name}, nil
+}
+
+func (g *GitHub) ListIssues(ctx context.Context, label string) ([]Issue, error) {
+	const perPage = 100
+	var all []Issue
+	for page := 1; ; page++ {
+		q := url.Values{"state": {"open"}, "per_page": {strconv.Itoa(perPage)}, "page": {strconv.Itoa(page)}}
+		if label != "" {
+			q.Set("labels", label)
+		}
+		var issues []Issue
+		path := fmt.Sprintf("repos/%s/%s/issues?%s", url.PathEscape(g.owner), url.PathEscape(g.name), q.Encode())
+		if err := g.api.GetJSON(ctx, path, &issues); err != nil {
+			return nil, fmt.Errorf("issueimport: list issues: %w", err)
+		}
+		for _, is := range issues {
+			if len(is.PR) == 0 || string(is.PR) == "null" {
+				all = append(all, is)
+			}
+		}
+		if len(issues) < perPage {
+			return all, nil
+		}
+	}
+}
+
+func (g *GitHub) Comment(ctx context.Context, number int, body string) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/comments", url.PathEscape(g.owner), url.PathEscape(g.name), number)
+	if err := g.api.SendJSON(ctx, http.MethodPost, path, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("issueimport: comment on #%d: %w", number, err)
+	}
+	return nil
+}
+
+// Options controls Import.
+type Options struct {
+	Label        string   // only issues with this label; "" for all open issues
+	Tracks       []string // configured track names, for mapping labels to tracks
+	DefaultTrack string   // track for issues without a track label; "" skips them
+	Publish      bool     // open the cars for engines instead of leaving them draft
+	DryRun       bool     // report what would be created without writing
+
+	BranchPrefix string
+	IDFormat     car.IDFormat
+	RequestedBy  string
+	BaseBranch   string
+}
+
+// Imported is one car created from an issue.
+type Imported struct {
+	Issue int
+	Title string
+	CarID string // empty on a dry run
+	Track string
+	Epic  string // epic car ID (or milestone title on a dry run); "" without a milestone
+}
+
+// Skipped is an issue Import did not create a car for.
+type Skipped struct {
+	Issue  int
+	Reason string
+}
+
+// Result reports what Import did.
+type Result struct {
+	Imported []Imported
+	Epics    []string // epic cars created for milestones
+	Skipped  []Skipped
+}
+
+// Import creates a car for each open issue that has not been imported (or
+// triaged by Bull) before. An issue's track is the first label naming a
+// configured track (either "backend" or "track:backend"), else
+// DefaultTrack; its type is bug or spike when labelled so; its priority
+// comes from a P0–P4 or "priority:N" label (default 2). Issues in a
+// milestone become children of an epic car titled after the milestone,
+// created on first use.
+func Import(ctx context.Context, db *gorm.DB, client Client, opts Options) (*Result, error) {
+	issues, err := client.ListIssues(ctx, opts.Label)
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := trackedIssues(db)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	epics := map[string]string{} // milestone title → epic car ID
+	for _, is := range issues {
+		if carID, ok := tracked[is.Number]; ok {
+			reason := "already tracked"
+			if carID != "" {
+				reason = "already imported as " + carID
+			}
+			res.Skipped = append(res.Skipped, Skipped{Issue: is.Number, Reason: reason})
+			continue
+		}
+		track := issueTrack(is, opts.Tracks, opts.DefaultTrack)
+		if track == "" {
+			res.Skipped = append(res.Skipped, Skipped{Issue: is.Number, Reason: "no label names a track (use --track for a default)"})
+			continue
+		}
+
+		imp := Imported{Issue: is.Number, Title: is.Title, Track: track}
+		if opts.DryRun {
+			if is.Milestone != nil {
+				imp.Epic = is.Milestone.Title
+			}
+			res.Imported = append(res.Imported, imp)
+			continue
+		}
+
+		if is.Milestone != nil && is.Milestone.Title != "" {
+			epicID, created, err := milestoneEpic(db, epics, is.Milestone.Title, track, opts)
+			if err != nil {
+				return res, err
+			}
+			if created {
+				res.Epics = append(res.Epics, epicID)
+			}
+			imp.Epic = epicID
+		}
+		carID, err := createCar(db, is, imp, opts)
+		if err != nil {
+			return res, fmt.Errorf("issueimport: issue #%d: %w", is.Number, err)
+		}
+		imp.CarID = carID
+		res.Imported = append(res.Imported, imp)
+	}
+	return res, nil
+}
+
+// trackedIssues returns the issue numbers already linked to cars, mapped
+// to their car ID ("" when Bull tracks the issue without a car).
+func trackedIssues(db *gorm.DB) (map[int]string, error) {
+	tracked := map[int]string{}
+	var rows []models.BullIssue
+	if err := db.Select("issue_number", "car_id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("issueimport: load tracked issues: %w", err)
+	}
+	for _, r := range rows {
+		tracked[r.IssueNumber] = r.CarID
+	}
+	var cars []models.Car
+	if err := db.Select("id", "source_issue").Where("source_issue > 0").Find(&cars).Error; err != nil {
+		return nil, fmt.Errorf("issueimport: load imported cars: %w", err)
+	}
+	for _, c := range cars {
+		if tracked[c.SourceIssue] == "" {
+			tracked[c.SourceIssue] = c.ID
+		}
+	}
+	return tracked, nil
+}
+
+// issueTrack picks the track for an issue from its labels.
+func issueTrack(is Issue, tracks []string, fallback string) string {
+	for _, l := range is.Labels {
+		name := strings.TrimPrefix(strings.ToLower(l.Name), "track:")
+		for _, t := range tracks {
+			if strings.EqualFold(name, t) {
+				return t
+			}
+		}
+	}
+	return fallback
+}
+
+// issueType maps bug and spike labels to car types.
+func issueType(is Issue) string {
+	for _, l := range is.Labels {
+		switch strings.ToLower(l.Name) {
+		case "bug", "type:bug":
+			return "bug"
+		case "spike", "type:spike":
+			return "spike"
+		}
+	}
+	return "task"
+}
+
+// issuePriority reads a P0–P4 or priority:N label.
+func issuePriority(is Issue) int {
+	for _, l := range is.Labels {
+		name := strings.ToLower(strings.TrimSpace(l.Name))
+		var num string
+		switch {
+		case strings.HasPrefix(name, "priority:"):
+			num = strings.TrimSpace(strings.TrimPrefix(name, "priority:"))
+		case len(name) == 2 && name[0] == 'p':
+			num = name[1:]
+		default:
+			continue
+		}
+		if n, err := strconv.Atoi(num); err == nil && n >= 0 && n <= 4 {
+			return n
+		}
+	}
+	return 2
+}
+
+// milestoneEpic returns the epic car for a milestone, reusing an open epic
+// with the same title or creating one on track.
+func milestoneEpic(db *gorm.DB, cache map[string]string, title, track string, opts Options) (id string, created bool, err error) {
+	if id, ok := cache[title]; ok {
+		return id, false, nil
+	}
+	var epic models.Car
+	err = db.Where("type = ? AND title = ? AND status NOT IN ?", "epic", title, models.ResolvedBlockerStatuses).
+		Order("created_at").First(&epic).Error
+	if err == nil {
+		cache[title] = epic.ID
+		return epic.ID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, fmt.Errorf("issueimport: find epic %q: %w", title, err)
+	}
+	c, err := car.Create(db, car.CreateOpts{
+		Title:        title,
+		Description:  "GitHub milestone " + title,
+		Type:         "epic",
+		Track:        track,
+		BranchPrefix: opts.BranchPrefix,
+		BaseBranch:   opts.BaseBranch,
+		RequestedBy:  opts.RequestedBy,
+		IDFormat:     opts.IDFormat,
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("issueimport: create epic %q: %w", title, err)
+	}
+	if opts.Publish {
+		if _, err := car.Publish(db, c.ID, false); err != nil {
+			return "", false, err
+		}
+	}
+	cache[title] = c.ID
+	return c.ID, true, nil
+}
+
+// createCar creates the car for one issue and records the link, in one
+// transaction.
+func createCar(db *gorm.DB, is Issue, imp Imported, opts Options) (string, error) {
+	var carID string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		description := strings.TrimSpace(is.Body)
+		if is.HTMLURL != "" {
+			description = strings.TrimSpace(description + "\n\nImported from " + is.HTMLURL)
+		}
+		c, err := car.Create(tx, car.CreateOpts{
+			Title:        is.Title,
+			Description:  description,
+			Type:         issueType(is),
+			Priority:     issuePriority(is),
+			Track:        imp.Track,
+			ParentID:     imp.Epic,
+			BranchPrefix: opts.BranchPrefix,
+			BaseBranch:   opts.BaseBranch,
+			RequestedBy:  opts.RequestedBy,
+			IDFormat:     opts.IDFormat,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Car{}).Where("id = ?", c.ID).Update("source_issue", is.Number).Error; err != nil {
+			return fmt.Errorf("set source issue: %w", err)
+		}
+		status := c.Status
+		if opts.Publish {
+			if _, err := car.Publish(tx, c.ID, false); err != nil {
+				return err
+			}
+			status = "open"
+		}
+		now := time.Now()
+		if err := tx.Create(&models.BullIssue{
+			IssueNumber:     is.Number,
+			CarID:           c.ID,
+			LastKnownStatus: status,
+			TriageMode:      models.TriageModeImport,
+			LastSyncedAt:    &now,
+		}).Error; err != nil {
+			return fmt.Errorf("record issue: %w", err)
+		}
+		carID = c.ID
+		return nil
+	})
+	return carID, err
+}
+
+// Synced is one status comment SyncStatus posted.
+type Synced struct {
+	Issue     int
+	CarID     string
+	OldStatus string
+	Status    string
+}
+
+// SyncStatus comments on each imported issue whose car changed status since
+// the last sync. Issues whose car is cancelled or merged stop being synced
+// after their final comment.
+func SyncStatus(ctx context.Context, db *gorm.DB, client Client) ([]Synced, error) {
+	var rows []models.BullIssue
+	if err := db.Where("triage_mode = ? AND car_id <> '' AND last_known_status NOT IN ?",
+		models.TriageModeImport, models.ResolvedBlockerStatuses).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("issueimport: load imported issues: %w", err)
+	}
+	var synced []Synced
+	for _, row := range rows {
+		var c models.Car
+		if err := db.Select("id", "status", "blocked_reason").Where("id = ?", row.CarID).First(&c).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return synced, fmt.Errorf("issueimport: load car %s: %w", row.CarID, err)
+		}
+		if c.Status == row.LastKnownStatus {
+			continue
+		}
+		if err := client.Comment(ctx, row.IssueNumber, StatusComment(c)); err != nil {
+			return synced, err
+		}
+		now := time.Now()
+		if err := db.Model(&models.BullIssue{}).Where("id = ?", row.ID).
+			Updates(map[string]any{"last_known_status": c.Status, "last_synced_at": now}).Error; err != nil {
+			return synced, fmt.Errorf("issueimport: record sync of #%d: %w", row.IssueNumber, err)
+		}
+		synced = append(synced, Synced{Issue: row.IssueNumber, CarID: c.ID, OldStatus: row.LastKnownStatus, Status: c.Status})
+	}
+	return synced, nil
+}
+
+// StatusComment is the issue comment describing a car's current status.
+func StatusComment(c models.Car) string {
+	what := map[string]string{
+		"open":      "is queued for an engine",
+		"ready":     "is queued for an engine",
+		"claimed":   "is being worked on",
+		"done":      "is finished and waiting for the merge gate",
+		"pr_open":   "has a pull request open for review",
+		"merged":    "has been merged",
+		"cancelled": "was cancelled",
+	}[c.Status]
+	if c.Status == "blocked" {
+		what = "is blocked"
+		if c.BlockedReason != "" {
+			what += " (" + c.BlockedReason + ")"
+		}
+	}
+	if what == "" {
+		what = "is now " + c.Status
+	}
+	return fmt.Sprintf("Railyard: car `%s` %s.", c.ID, what)
+}
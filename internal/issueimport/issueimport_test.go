@@ -0,0 +1,229 @@
+package issueimport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghapi "github.com/zulandar/railyard/internal/github"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/models/testfactory"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.BullIssue{}, &models.RailyardConfig{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+type fakeClient struct {
+	issues   []Issue
+	comments map[int][]string
+}
+
+func (f *fakeClient) ListIssues(_ context.Context, label string) ([]Issue, error) {
+	return f.issues, nil
+}
+
+func (f *fakeClient) Comment(_ context.Context, number int, body string) error {
+	if f.comments == nil {
+		f.comments = map[int][]string{}
+	}
+	f.comments[number] = append(f.comments[number], body)
+	return nil
+}
+
+func labels(names ...string) []Label {
+	var ls []Label
+	for _, n := range names {
+		ls = append(ls, Label{Name: n})
+	}
+	return ls
+}
+
+func TestImport_MapsLabelsAndMilestones(t *testing.T) {
+	db := testDB(t)
+	client := &fakeClient{issues: []Issue{
+		{Number: 1, Title: "Login times out", Body: "Steps...", HTMLURL: "https://github.com/org/app/issues/1",
+			Labels: labels("railyard", "bug", "backend", "P1"), Milestone: &Milestone{Title: "v2.0"}},
+		{Number: 2, Title: "Dark mode", Labels: labels("railyard", "track:Frontend", "priority:0"), Milestone: &Milestone{Title: "v2.0"}},
+		{Number: 3, Title: "No track label", Labels: labels("railyard")},
+	}}
+
+	res, err := Import(context.Background(), db, client, Options{
+		Label:  "railyard",
+		Tracks: []string{"backend", "frontend"},
+	})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(res.Imported) != 2 || len(res.Epics) != 1 || len(res.Skipped) != 1 || res.Skipped[0].Issue != 3 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	var bug models.Car
+	db.First(&bug, "id = ?", res.Imported[0].CarID)
+	if bug.Type != "bug" || bug.Priority != 1 || bug.Track != "backend" || bug.SourceIssue != 1 || bug.Status != "draft" {
+		t.Errorf("car for #1 = %+v", bug)
+	}
+	if !strings.Contains(bug.Description, "Imported from https://github.com/org/app/issues/1") {
+		t.Errorf("description = %q", bug.Description)
+	}
+	var dark models.Car
+	db.First(&dark, "id = ?", res.Imported[1].CarID)
+	if dark.Track != "frontend" || dark.Priority != 0 || dark.Type != "task" {
+		t.Errorf("car for #2 = %+v", dark)
+	}
+	epic := res.Epics[0]
+	if bug.ParentID == nil || *bug.ParentID != epic || dark.ParentID == nil || *dark.ParentID != epic {
+		t.Errorf("both cars should be children of epic %s", epic)
+	}
+
+	var tracked []models.BullIssue
+	db.Order("issue_number").Find(&tracked)
+	if len(tracked) != 2 || tracked[0].TriageMode != models.TriageModeImport || tracked[0].CarID != bug.ID {
+		t.Errorf("bull_issues = %+v", tracked)
+	}
+
+	// A second run imports nothing new and reuses the epic.
+	client.issues = append(client.issues, Issue{Number: 4, Title: "More", Labels: labels("backend"), Milestone: &Milestone{Title: "v2.0"}})
+	res, err = Import(context.Background(), db, client, Options{Tracks: []string{"backend", "frontend"}, Publish: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 1 || res.Imported[0].Issue != 4 || len(res.Epics) != 0 || res.Imported[0].Epic != epic {
+		t.Fatalf("second run = %+v", res)
+	}
+	var more models.Car
+	db.First(&more, "id = ?", res.Imported[0].CarID)
+	if more.Status != "open" {
+		t.Errorf("published car status = %q, want open", more.Status)
+	}
+	if !strings.HasPrefix(res.Skipped[0].Reason, "already imported as ") {
+		t.Errorf("skip reason = %q", res.Skipped[0].Reason)
+	}
+}
+
+func TestImport_DryRunWritesNothing(t *testing.T) {
+	db := testDB(t)
+	client := &fakeClient{issues: []Issue{{Number: 7, Title: "x", Milestone: &Milestone{Title: "m"}}}}
+	res, err := Import(context.Background(), db, client, Options{DefaultTrack: "backend", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 1 || res.Imported[0].CarID != "" || res.Imported[0].Epic != "m" {
+		t.Errorf("result = %+v", res)
+	}
+	var n int64
+	db.Model(&models.Car{}).Count(&n)
+	if n != 0 {
+		t.Errorf("dry run created %d cars", n)
+	}
+}
+
+func TestImport_SkipsIssuesBullTracks(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.BullIssue{IssueNumber: 5, LastKnownStatus: "triaged"})
+	testfactory.Car().With(func(c *models.Car) { c.SourceIssue = 6 }).Create(t, db)
+	client := &fakeClient{issues: []Issue{{Number: 5, Title: "a"}, {Number: 6, Title: "b"}}}
+	res, err := Import(context.Background(), db, client, Options{DefaultTrack: "backend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Imported) != 0 || len(res.Skipped) != 2 {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestSyncStatus_CommentsOnChanges(t *testing.T) {
+	db := testDB(t)
+	client := &fakeClient{issues: []Issue{{Number: 1, Title: "a"}, {Number: 2, Title: "b"}}}
+	res, err := Import(context.Background(), db, client, Options{DefaultTrack: "backend", Publish: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := res.Imported[0].CarID
+	db.Model(&models.Car{}).Where("id = ?", first).Updates(map[string]any{"status": "blocked", "blocked_reason": models.BlockedReasonTestFailed})
+
+	synced, err := SyncStatus(context.Background(), db, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(synced) != 1 || synced[0].Issue != 1 || synced[0].OldStatus != "open" || synced[0].Status != "blocked" {
+		t.Fatalf("synced = %+v", synced)
+	}
+	want := "Railyard: car `" + first + "` is blocked (test-failed)."
+	if got := client.comments[1]; len(got) != 1 || got[0] != want {
+		t.Errorf("comments = %q, want %q", got, want)
+	}
+
+	// Nothing changed: no second comment.
+	if synced, _ = SyncStatus(context.Background(), db, client); len(synced) != 0 {
+		t.Errorf("second sync = %+v", synced)
+	}
+
+	// Merged is reported once, then the issue is no longer synced.
+	db.Model(&models.Car{}).Where("id = ?", first).Update("status", "merged")
+	SyncStatus(context.Background(), db, client)
+	db.Model(&models.Car{}).Where("id = ?", first).Update("status", "open")
+	SyncStatus(context.Background(), db, client)
+	if got := client.comments[1]; len(got) != 2 || !strings.HasSuffix(got[1], "has been merged.") {
+		t.Errorf("comments after merge = %q", got)
+	}
+}
+
+func TestGitHub_ListIssuesSkipsPullRequests(t *testing.T) {
+	var gotQuery, gotComment string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/org/app/issues":
+			gotQuery = r.URL.RawQuery
+			w.Write([]byte(`[{"number":1,"title":"issue","labels":[{"name":"backend"}],"milestone":{"number":3,"title":"v2"}},
+				{"number":2,"title":"a PR","pull_request":{"url":"x"}}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/org/app/issues/1/comments":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			gotComment = body["body"]
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	gh, err := NewGitHub(ghapi.New(ghapi.Options{BaseURL: srv.URL, CacheSize: -1}), "org/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := gh.ListIssues(context.Background(), "railyard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Number != 1 || issues[0].Milestone.Title != "v2" || issues[0].Labels[0].Name != "backend" {
+		t.Errorf("issues = %+v", issues)
+	}
+	if !strings.Contains(gotQuery, "labels=railyard") || !strings.Contains(gotQuery, "state=open") {
+		t.Errorf("query = %q", gotQuery)
+	}
+	if err := gh.Comment(context.Background(), 1, "hello"); err != nil || gotComment != "hello" {
+		t.Errorf("comment = %q, err = %v", gotComment, err)
+	}
+
+	if _, err := NewGitHub(nil, "just-a-name"); err == nil {
+		t.Error("NewGitHub accepted a repo without an owner")
+	}
+}
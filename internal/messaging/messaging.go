@@ -56,6 +56,54 @@ func Send(db *gorm.DB, from, to, subject, body string, opts SendOpts) (*models.M
 	return &msg, nil
 }
 
+// SendToTrack sends the same message to every active engine on a track, as
+// one direct message per engine so each engine's acknowledgement is recorded
+// on its own row. The messages share a thread (the first message's ID), so
+// GetThread lists who has picked it up. It returns the messages sent, one
+// per engine, and an error if the track has no active engines.
+func SendToTrack(db *gorm.DB, from, track, subject, body string, opts SendOpts) ([]models.Message, error) {
+	if track == "" {
+		return nil, fmt.Errorf("messaging: track is required")
+	}
+
+	var engines []models.Engine
+	if err := db.Where("track = ? AND status != ?", track, "dead").
+		Order("id").Find(&engines).Error; err != nil {
+		return nil, fmt.Errorf("messaging: list engines on track %s: %w", track, err)
+	}
+	if len(engines) == 0 {
+		return nil, fmt.Errorf("messaging: no active engines on track %s", track)
+	}
+
+	var sent []models.Message
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sent = sent[:0]
+		for _, eng := range engines {
+			o := opts
+			if len(sent) > 0 {
+				o.ThreadID = sent[0].ThreadID
+			}
+			msg, err := Send(tx, from, eng.ID, subject, body, o)
+			if err != nil {
+				return err
+			}
+			if msg.ThreadID == nil {
+				// The first message starts the thread.
+				msg.ThreadID = &msg.ID
+				if err := tx.Model(msg).Update("thread_id", msg.ID).Error; err != nil {
+					return fmt.Errorf("messaging: start thread: %w", err)
+				}
+			}
+			sent = append(sent, *msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sent, nil
+}
+
 // Inbox returns unacknowledged messages for an agent, ordered by priority then
 // creation time. It includes both direct messages and broadcast messages that
 // the agent has not yet acknowledged.
@@ -449,3 +449,49 @@ func TestReply_DBError(t *testing.T) {
 		t.Fatal("expected error from Reply with closed DB")
 	}
 }
+
+// --- SendToTrack DB tests ---
+
+func TestSendToTrack(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.Engine{}); err != nil {
+		t.Fatalf("migrate engines: %v", err)
+	}
+	for _, e := range []models.Engine{
+		{ID: "eng-b", Track: "backend", Status: "working"},
+		{ID: "eng-a", Track: "backend", Status: "idle"},
+		{ID: "eng-dead", Track: "backend", Status: "dead"},
+		{ID: "eng-f", Track: "frontend", Status: "idle"},
+	} {
+		if err := db.Create(&e).Error; err != nil {
+			t.Fatalf("create engine: %v", err)
+		}
+	}
+
+	msgs, err := SendToTrack(db, "alice", "backend", "guidance", "pause refactors", SendOpts{})
+	if err != nil {
+		t.Fatalf("SendToTrack: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].ToAgent != "eng-a" || msgs[1].ToAgent != "eng-b" {
+		t.Fatalf("sent = %+v, want eng-a and eng-b", msgs)
+	}
+	thread, err := GetThread(db, *msgs[0].ThreadID)
+	if err != nil {
+		t.Fatalf("GetThread: %v", err)
+	}
+	if len(thread) != 2 {
+		t.Errorf("thread has %d messages, want 2", len(thread))
+	}
+
+	inbox, _ := Inbox(db, "eng-b")
+	if len(inbox) != 1 || inbox[0].Body != "pause refactors" {
+		t.Errorf("eng-b inbox = %+v", inbox)
+	}
+	if inbox, _ := Inbox(db, "eng-f"); len(inbox) != 0 {
+		t.Errorf("frontend engine got %d messages", len(inbox))
+	}
+
+	if _, err := SendToTrack(db, "alice", "docs", "guidance", "x", SendOpts{}); err == nil || !strings.Contains(err.Error(), "no active engines") {
+		t.Errorf("empty track error = %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Attachment is a file uploaded in a dispatch chat thread (a spec, a
+// screenshot). It is recorded against the thread it was posted in until
+// `ry car create --attach` binds it to a car; engines then find it in their
+// worktree under AttachmentsDir.
+type Attachment struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	CarID       string `gorm:"size:32;index"` // empty until attached to a car
+	Platform    string `gorm:"size:32"`
+	ChannelID   string `gorm:"size:64"`
+	ThreadID    string `gorm:"size:64;index"`
+	UploadedBy  string `gorm:"size:128"`
+	Name        string `gorm:"size:255"`
+	ContentType string `gorm:"size:128"`
+	Size        int64
+	Data        []byte
+	CreatedAt   time.Time
+}
+
+// AttachmentsDir is the worktree-relative directory engines find a car's
+// attachments in. It is excluded from git so attachments are never
+// committed.
+const AttachmentsDir = ".railyard-attachments"
@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+// TriageModeImport marks a BullIssue created by `ry car import github`
+// rather than by Bull's triage. Bull skips these when syncing labels; the
+// import's --update posts status comments for them instead.
+const TriageModeImport = "import"
+
 // BullIssue tracks a GitHub issue that Bull has triaged or is monitoring.
 type BullIssue struct {
 	ID              uint   `gorm:"primaryKey;autoIncrement"`
@@ -10,7 +15,7 @@ type BullIssue struct {
 	LastKnownStatus string `gorm:"size:32"`
 	TriageSummary   string `gorm:"type:text"`
 	TriageResponse  string `gorm:"type:text"`
-	TriageMode      string `gorm:"size:16"`
+	TriageMode      string `gorm:"size:16"` // bull.triage_mode used, or TriageModeImport
 	LastSyncedAt    *time.Time
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
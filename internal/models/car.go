@@ -2,12 +2,22 @@ package models
 
 import "time"
 
+// TerminalStatuses are the statuses a car never leaves on its own: its work
+// merged, was cancelled, or merged and was then reverted. Every "is this car
+// still live" check uses this list or ClosedStatuses, so adding a terminal
+// status here is enough to retire cars in it everywhere.
+var TerminalStatuses = []string{"merged", "cancelled", "reverted"}
+
+// ClosedStatuses are TerminalStatuses plus done: the car's work is over,
+// though a done car may still be waiting on the merge gate.
+var ClosedStatuses = []string{"done", "merged", "cancelled", "reverted"}
+
 // ResolvedBlockerStatuses lists car statuses that count as "resolved" when
 // evaluating whether a blocker still prevents its dependent from being worked.
 // Every query that checks for unresolved blockers must use this list to stay
 // consistent. Forgetting a status here causes dependent cars to appear
 // permanently blocked.
-var ResolvedBlockerStatuses = []string{"cancelled", "merged"}
+var ResolvedBlockerStatuses = TerminalStatuses
 
 // BlockedReason values record why a car was set to "blocked" status.
 // UnblockDeps uses this to decide whether to transition to "done" (retry
@@ -16,6 +26,10 @@ const (
 	BlockedReasonTestFailed       = "test-failed"
 	BlockedReasonStalled          = "stalled"
 	BlockedReasonCompletionFailed = "completion-failed"
+	BlockedReasonCoverageDropped  = "coverage-dropped"
+	BlockedReasonMergeConflict    = "merge-conflict"
+	BlockedReasonCommandPolicy    = "command-policy" // waits for a human; never unblocked automatically
+	BlockedReasonDiffTooLarge     = "diff-too-large" // waits for dispatch to split the car
 )
 
 // Car is the core work item in Railyard.
@@ -26,23 +40,36 @@ type Car struct {
 	Type               string  `gorm:"size:16;default:task"`
 	Status             string  `gorm:"size:16;default:draft;index"`
 	Priority           int     `gorm:"default:2"`
+	Estimate           int     `gorm:"default:0"` // relative size (e.g. story points); 0 = unestimated
 	Track              string  `gorm:"size:64;index"`
 	Assignee           string  `gorm:"size:64"`
+	Owner              string  `gorm:"size:64;index"` // human responsible for the car; distinct from the engine Assignee
 	ParentID           *string `gorm:"size:32"`
 	Branch             string  `gorm:"size:128"`
 	BaseBranch         string  `gorm:"size:64" json:"base_branch"`
 	DesignNotes        string  `gorm:"type:text"`
 	Acceptance         string  `gorm:"type:text"`
 	SkipTests          bool    `gorm:"default:false"`
-	BlockedReason      string  `gorm:"size:32"` // why blocked: "test-failed", "stalled", "completion-failed", or "" for dependency
+	BlockedReason      string  `gorm:"size:32"` // why blocked: "test-failed", "stalled", "completion-failed", "coverage-dropped", "merge-conflict", "command-policy", "diff-too-large", or "" for dependency
 	RequestedBy        string  `gorm:"size:64"`
 	SourceIssue        int
-	LastRebaseBaseHead string `gorm:"size:40"`   // SHA of base branch HEAD when rebase was last attempted
-	LastPRCommentCount int    `gorm:"default:0"` // non-author inline comment count when car entered pr_open
+	RevertOf           string `gorm:"size:32;index"` // car whose merge this car reverts; "" for ordinary cars
+	ConflictOf         string `gorm:"size:32;index"` // car whose merge conflict this car resolves; "" for ordinary cars
+	ClonedFrom         string `gorm:"size:32;index"` // car this car was cloned from (ry car clone --link); "" when not linked
+	ImportedFrom       string `gorm:"size:40;index"` // pre-Railyard merge commit this archived car was made from (ry import git-history); "" otherwise
+	LastRebaseBaseHead string `gorm:"size:40"`       // SHA of base branch HEAD when rebase was last attempted
+	PushedHead         string `gorm:"size:40"`       // branch commit pushed at ry complete (or by a yardmaster rebase); Switch checks origin still has it
+	LastPRCommentCount int    `gorm:"default:0"`     // non-author inline comment count when car entered pr_open
+	Canary             bool   `gorm:"default:false"` // claimed into its track's canary group (tracks[].canary)
+	SwitchFailure      string `gorm:"size:32"`       // category of the last transient merge-gate failure (infra-failed, push-failed); "" when none
+	SwitchAttempts     int    `gorm:"default:0"`     // consecutive transient merge-gate failures retried automatically
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 	ClaimedAt          *time.Time
 	CompletedAt        *time.Time
+	PreemptedAt        *time.Time // last time the car was parked to make way for urgent work
+	DraftPRAt          *time.Time // when the yardmaster opened a draft PR for the car's in-progress work
+	SwitchRetryAt      *time.Time // when a car retried after a transient merge-gate failure may re-enter the merge gate
 
 	Parent   *Car          `gorm:"foreignKey:ParentID"`
 	Children []Car         `gorm:"foreignKey:ParentID"`
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CarThread maps a car to the chat thread its lifecycle events are posted
+// in on one platform. When Telegraph runs several platforms at once, each
+// car has one row per platform, so its lifecycle is mirrored in a thread on
+// every platform.
+type CarThread struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	CarID         string `gorm:"size:32;not null;uniqueIndex:idx_car_thread_platform"`
+	Platform      string `gorm:"size:16;not null;uniqueIndex:idx_car_thread_platform"` // "slack" or "discord"
+	ChannelID     string `gorm:"size:64"`
+	RootMessageID string `gorm:"size:64"` // first event message; the thread is started from it
+	ThreadID      string `gorm:"size:64"` // empty until the second event starts the thread
+	CreatedAt     time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// CarWebhook is a webhook registered on one car with ry car webhook add. The
+// yardmaster POSTs that car's status changes and merge results to URL, with
+// the same payloads as the webhooks: section of railyard.yaml.
+type CarWebhook struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	CarID     string `gorm:"size:32;not null;index"`
+	URL       string `gorm:"size:1024;not null"`
+	Secret    string `gorm:"size:255"` // HMAC-SHA256 key for X-Railyard-Signature; may be empty
+	CreatedBy string `gorm:"size:128"`
+	CreatedAt time.Time
+}
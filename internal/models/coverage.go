@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// CoverageRecord is the test coverage measured for a car at the merge gate,
+// with its delta against the track's coverage at the last merge. The latest
+// record of a merged car is the baseline for later cars on its track.
+type CoverageRecord struct {
+	ID        uint     `gorm:"primaryKey;autoIncrement"`
+	CarID     string   `gorm:"size:32;index"`
+	Track     string   `gorm:"size:64;index"`
+	Coverage  float64  // percent of statements covered on the car's branch
+	Baseline  *float64 // track coverage at the last merge; nil for the track's first record
+	Delta     float64  // Coverage - Baseline in percentage points; 0 without a baseline
+	CreatedAt time.Time
+}
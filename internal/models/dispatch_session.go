@@ -6,16 +6,21 @@ import "time"
 // from Telegraph (chat) or local CLI. The dispatch lock system uses this
 // model to prevent concurrent sessions on the same thread/channel.
 type DispatchSession struct {
-	ID               uint      `gorm:"primaryKey;autoIncrement"`
-	Source           string    `gorm:"size:16;not null;index"` // "telegraph" or "local"
-	UserName         string    `gorm:"size:64;not null"`
-	PlatformThreadID string    `gorm:"size:128;index:idx_thread_channel"`
-	ChannelID        string    `gorm:"size:128;index:idx_thread_channel"`
-	Status           string    `gorm:"size:16;default:active;index"` // active, completed, expired
-	CarsCreated      string    `gorm:"type:json"`                    // JSON array of car IDs
-	LastHeartbeat    time.Time `gorm:"index"`
+	ID               uint       `gorm:"primaryKey;autoIncrement"`
+	Source           string     `gorm:"size:16;not null;index"` // "telegraph" or "local"
+	UserName         string     `gorm:"size:64;not null"`
+	PlatformThreadID string     `gorm:"size:128;index:idx_thread_channel"`
+	ChannelID        string     `gorm:"size:128;index:idx_thread_channel"`
+	Status           string     `gorm:"size:16;default:active;index"`       // active, completed, expired
+	CarsCreated      string     `gorm:"type:json"`                          // JSON array of car IDs
+	LastSequence     int        `gorm:"not null;default:0"`                 // last conversation Sequence allocated
+	ProcessPID       int        `gorm:"column:process_pid;default:0;index"` // dispatch subprocess PID while it runs; 0 once it exits or is reaped
+	ProcessHost      string     `gorm:"size:255"`                           // host the subprocess runs on
+	ProcessStartedAt *time.Time // subprocess start; guards against reaping a reused PID
+	LastHeartbeat    time.Time  `gorm:"index"`
 	CreatedAt        time.Time
 	CompletedAt      *time.Time
+	ArchivedAt       *time.Time // conversation turns moved to the history archive
 
 	Conversations []TelegraphConversation `gorm:"foreignKey:SessionID"`
 }
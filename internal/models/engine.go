@@ -12,7 +12,14 @@ type Engine struct {
 	CurrentCar   string `gorm:"size:32"`
 	SessionID    string `gorm:"size:64"`
 	Provider     string `gorm:"size:32"`  // agent provider name (e.g., "claude", "codex")
+	AgentBinary  string `gorm:"size:255"` // agent CLI override from --agent-binary; empty runs the provider default
 	OverlayTable string `gorm:"size:128"` // pgvector overlay table name (e.g., ovl_eng_a1b2c3d4)
 	StartedAt    time.Time
 	LastActivity time.Time `gorm:"index"`
+
+	// StaleReleases counts cars the yardmaster took back from this engine
+	// because its claim made no progress; LastReleaseReason says why the
+	// latest one was released. Both feed the yard health score.
+	StaleReleases     int
+	LastReleaseReason string `gorm:"size:255"`
 }
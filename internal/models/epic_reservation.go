@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// EpicClosedStatuses are the statuses of an epic whose work is over; its
+// reservations no longer hold engine capacity.
+var EpicClosedStatuses = ClosedStatuses
+
+// EpicReservation holds engine capacity on one track for an epic sent out
+// by ry epic dispatch. The reservation is soft: while fewer than Engines of
+// the track's engines work on the epic's children, a claiming engine takes
+// one of those children ahead of other ready cars, but no engine waits idle
+// for them. Reservations of epics that have closed are ignored.
+type EpicReservation struct {
+	EpicID    string `gorm:"primaryKey;size:32"`
+	Track     string `gorm:"primaryKey;size:64"`
+	Engines   int    // engines preferred for the epic's children; 0 when the track's engine_slots were already reserved
+	CreatedAt time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Event is one significant state change in the yard — a car transition, an
+// engine start or stall, a merge, a scale or other yardmaster action — with
+// who did it and the event's payload as JSON. Rows are append-only and back
+// `ry events list`.
+type Event struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Kind      string    `gorm:"size:64;index"` // the bus topic, e.g. CarStatusChanged
+	Actor     string    `gorm:"size:64"`       // yardmaster, an engine ID, or a CLI user
+	CarID     string    `gorm:"size:32;index"`
+	EngineID  string    `gorm:"size:64;index"`
+	Payload   string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
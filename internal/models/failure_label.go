@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// FailureLabel records an operator's correction of a merge attempt's
+// failure category with ry merge relabel. ProgressID is the attempt's
+// "switch:" progress note; Pattern is the output substring added to the
+// yardmaster's failure patterns so later attempts are classified as Label.
+type FailureLabel struct {
+	ID               uint   `gorm:"primaryKey;autoIncrement"`
+	ProgressID       uint   `gorm:"not null;uniqueIndex"`
+	CarID            string `gorm:"size:32;not null;index"`
+	OriginalCategory string `gorm:"size:32;not null"`
+	Label            string `gorm:"size:32;not null"`
+	Pattern          string `gorm:"size:255"`
+	Output           string `gorm:"type:text"` // the labeled test output tail
+	LabeledBy        string `gorm:"size:128"`
+	CreatedAt        time.Time
+}
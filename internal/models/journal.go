@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// JournalEntry is one step in an engine's work journal for a car: a session
+// boundary, a shell command the agent ran, a test run, a snapshot of the
+// files touched, or something the engine sandbox or command policy blocked.
+// Entries are append-only and back `ry car journal`.
+type JournalEntry struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	CarID     string `gorm:"size:32;index"`
+	EngineID  string `gorm:"size:64"`
+	SessionID string `gorm:"size:64;index"`
+	Kind      string `gorm:"size:16"` // session, command, test, files, sandbox, policy
+	Detail    string `gorm:"type:text"`
+	Outcome   string `gorm:"size:16"` // ok, failed, blocked, or empty when unknown
+	CreatedAt time.Time
+}
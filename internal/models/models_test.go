@@ -44,7 +44,7 @@ func assertFieldType(t *testing.T, typ reflect.Type, fieldName, expectedType str
 }
 
 func TestResolvedBlockerStatuses(t *testing.T) {
-	expected := map[string]bool{"cancelled": true, "merged": true}
+	expected := map[string]bool{"cancelled": true, "merged": true, "reverted": true}
 	if len(ResolvedBlockerStatuses) != len(expected) {
 		t.Fatalf("ResolvedBlockerStatuses has %d entries, want %d", len(ResolvedBlockerStatuses), len(expected))
 	}
@@ -136,6 +136,20 @@ func TestCarProgress_Fields(t *testing.T) {
 	assertFieldType(t, typ, "CreatedAt", "time.Time")
 }
 
+func TestJournalEntry_Fields(t *testing.T) {
+	typ := reflect.TypeOf(JournalEntry{})
+
+	assertGormTag(t, typ, "ID", "primaryKey")
+	assertGormTag(t, typ, "CarID", "size:32")
+	assertGormTag(t, typ, "CarID", "index")
+	assertGormTag(t, typ, "SessionID", "index")
+	assertGormTag(t, typ, "Kind", "size:16")
+	assertGormTag(t, typ, "Detail", "type:text")
+	assertGormTag(t, typ, "Outcome", "size:16")
+
+	assertFieldType(t, typ, "CreatedAt", "time.Time")
+}
+
 func TestTrack_Fields(t *testing.T) {
 	typ := reflect.TypeOf(Track{})
 
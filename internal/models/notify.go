@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// NotifySubscription is one chat user's opt-in to direct-message pings from
+// Telegraph for a single target: a car ID, an engine ID, or an event kind
+// ("cars", "engine-stalls", "escalations"). Managed with `!ry notify me`.
+type NotifySubscription struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Platform  string `gorm:"size:16;not null;uniqueIndex:idx_notify_user_target"`  // "slack" or "discord"
+	UserID    string `gorm:"size:128;not null;uniqueIndex:idx_notify_user_target"` // platform-specific user ID
+	UserName  string `gorm:"size:64"`
+	Target    string `gorm:"size:64;not null;uniqueIndex:idx_notify_user_target;index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,264 @@
+// Package testfactory builds models rows with sensible defaults for tests,
+// so a test states only the fields it cares about:
+//
+//	c := testfactory.Car().WithStatus("claimed").WithAssignee("eng-1").Create(t, db)
+//	testfactory.Engine().WithID("eng-1").Idle(time.Hour).Create(t, db)
+//
+// Every builder fills a unique ID (or leaves autoincrement IDs to the
+// database), a track of "backend", and timestamps of now. Build returns the
+// value without touching the database; Create inserts it and fails the test
+// on error. For fields without a With method, use With(func).
+package testfactory
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultTrack is the track builders use unless told otherwise.
+const DefaultTrack = "backend"
+
+var seq atomic.Int64
+
+// nextID returns prefix-NNNNN, unique within the test binary.
+func nextID(prefix string) string {
+	return fmt.Sprintf("%s-%05d", prefix, seq.Add(1))
+}
+
+func create[T any](t testing.TB, db *gorm.DB, v *T) {
+	t.Helper()
+	if err := db.Create(v).Error; err != nil {
+		t.Fatalf("testfactory: create %T: %v", *v, err)
+	}
+}
+
+// CarBuilder builds a models.Car.
+type CarBuilder struct{ c models.Car }
+
+// Car returns a builder for an open, unassigned task on DefaultTrack.
+func Car() *CarBuilder {
+	id := nextID("car")
+	now := time.Now()
+	return &CarBuilder{c: models.Car{
+		ID:        id,
+		Title:     "Test car " + id,
+		Type:      "task",
+		Status:    "open",
+		Priority:  2,
+		Track:     DefaultTrack,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+}
+
+func (b *CarBuilder) WithID(id string) *CarBuilder         { b.c.ID = id; return b }
+func (b *CarBuilder) WithTitle(title string) *CarBuilder   { b.c.Title = title; return b }
+func (b *CarBuilder) WithType(typ string) *CarBuilder      { b.c.Type = typ; return b }
+func (b *CarBuilder) WithStatus(status string) *CarBuilder { b.c.Status = status; return b }
+func (b *CarBuilder) WithPriority(p int) *CarBuilder       { b.c.Priority = p; return b }
+func (b *CarBuilder) WithTrack(track string) *CarBuilder   { b.c.Track = track; return b }
+func (b *CarBuilder) WithBranch(branch string) *CarBuilder { b.c.Branch = branch; return b }
+func (b *CarBuilder) WithOwner(owner string) *CarBuilder   { b.c.Owner = owner; return b }
+
+// WithParent makes the car a child of parentID.
+func (b *CarBuilder) WithParent(parentID string) *CarBuilder { b.c.ParentID = &parentID; return b }
+
+// WithAssignee assigns the car to an engine. It does not change the status.
+func (b *CarBuilder) WithAssignee(engineID string) *CarBuilder { b.c.Assignee = engineID; return b }
+
+// ClaimedBy marks the car claimed by engineID at the given time.
+func (b *CarBuilder) ClaimedBy(engineID string, at time.Time) *CarBuilder {
+	b.c.Status, b.c.Assignee, b.c.ClaimedAt = "claimed", engineID, &at
+	return b
+}
+
+// Blocked marks the car blocked for reason (one of the models.BlockedReason
+// values, or "" for a dependency block).
+func (b *CarBuilder) Blocked(reason string) *CarBuilder {
+	b.c.Status, b.c.BlockedReason = "blocked", reason
+	return b
+}
+
+// With applies fn to the car being built, for fields without a With method.
+func (b *CarBuilder) With(fn func(*models.Car)) *CarBuilder { fn(&b.c); return b }
+
+// Build returns the car without saving it.
+func (b *CarBuilder) Build() models.Car { return b.c }
+
+// Create inserts the car and returns it.
+func (b *CarBuilder) Create(t testing.TB, db *gorm.DB) models.Car {
+	t.Helper()
+	c := b.c
+	create(t, db, &c)
+	// GORM skips zero values for columns with a default, so an explicit
+	// priority 0 would be stored as the column default of 2.
+	if b.c.Priority == 0 {
+		if err := db.Model(&c).Update("priority", 0).Error; err != nil {
+			t.Fatalf("testfactory: set car %s priority: %v", c.ID, err)
+		}
+		c.Priority = 0
+	}
+	return c
+}
+
+// Dep records that carID is blocked by blockedBy.
+func Dep(t testing.TB, db *gorm.DB, carID, blockedBy string) models.CarDep {
+	t.Helper()
+	d := models.CarDep{CarID: carID, BlockedBy: blockedBy, DepType: "blocks"}
+	create(t, db, &d)
+	return d
+}
+
+// EngineBuilder builds a models.Engine.
+type EngineBuilder struct{ e models.Engine }
+
+// Engine returns a builder for an idle engine on DefaultTrack that started
+// and was last active now.
+func Engine() *EngineBuilder {
+	now := time.Now()
+	return &EngineBuilder{e: models.Engine{
+		ID:           nextID("eng"),
+		Track:        DefaultTrack,
+		Status:       "idle",
+		Provider:     "claude",
+		StartedAt:    now,
+		LastActivity: now,
+	}}
+}
+
+func (b *EngineBuilder) WithID(id string) *EngineBuilder         { b.e.ID = id; return b }
+func (b *EngineBuilder) WithTrack(track string) *EngineBuilder   { b.e.Track = track; return b }
+func (b *EngineBuilder) WithStatus(status string) *EngineBuilder { b.e.Status = status; return b }
+func (b *EngineBuilder) WithProvider(p string) *EngineBuilder    { b.e.Provider = p; return b }
+
+// Working sets the engine working on carID.
+func (b *EngineBuilder) Working(carID string) *EngineBuilder {
+	b.e.Status, b.e.CurrentCar = "working", carID
+	return b
+}
+
+// Idle sets the engine idle with no car, last active d ago.
+func (b *EngineBuilder) Idle(d time.Duration) *EngineBuilder {
+	b.e.Status, b.e.CurrentCar, b.e.LastActivity = "idle", "", time.Now().Add(-d)
+	return b
+}
+
+// LastActive sets the engine's last heartbeat.
+func (b *EngineBuilder) LastActive(at time.Time) *EngineBuilder { b.e.LastActivity = at; return b }
+
+// With applies fn to the engine being built.
+func (b *EngineBuilder) With(fn func(*models.Engine)) *EngineBuilder { fn(&b.e); return b }
+
+// Build returns the engine without saving it.
+func (b *EngineBuilder) Build() models.Engine { return b.e }
+
+// Create inserts the engine and returns it.
+func (b *EngineBuilder) Create(t testing.TB, db *gorm.DB) models.Engine {
+	t.Helper()
+	e := b.e
+	create(t, db, &e)
+	return e
+}
+
+// MessageBuilder builds a models.Message.
+type MessageBuilder struct{ m models.Message }
+
+// Message returns a builder for an unacknowledged normal-priority message
+// from the yardmaster to human.
+func Message() *MessageBuilder {
+	return &MessageBuilder{m: models.Message{
+		FromAgent: "yardmaster",
+		ToAgent:   "human",
+		Subject:   "test",
+		Body:      "test message",
+		Priority:  "normal",
+		CreatedAt: time.Now(),
+	}}
+}
+
+func (b *MessageBuilder) From(agent string) *MessageBuilder             { b.m.FromAgent = agent; return b }
+func (b *MessageBuilder) To(agent string) *MessageBuilder               { b.m.ToAgent = agent; return b }
+func (b *MessageBuilder) WithSubject(s string) *MessageBuilder          { b.m.Subject = s; return b }
+func (b *MessageBuilder) WithBody(body string) *MessageBuilder          { b.m.Body = body; return b }
+func (b *MessageBuilder) WithCar(carID string) *MessageBuilder          { b.m.CarID = carID; return b }
+func (b *MessageBuilder) WithPriority(p string) *MessageBuilder         { b.m.Priority = p; return b }
+func (b *MessageBuilder) Acknowledged() *MessageBuilder                 { b.m.Acknowledged = true; return b }
+func (b *MessageBuilder) At(at time.Time) *MessageBuilder               { b.m.CreatedAt = at; return b }
+func (b *MessageBuilder) With(fn func(*models.Message)) *MessageBuilder { fn(&b.m); return b }
+
+// Build returns the message without saving it.
+func (b *MessageBuilder) Build() models.Message { return b.m }
+
+// Create inserts the message and returns it with its ID.
+func (b *MessageBuilder) Create(t testing.TB, db *gorm.DB) models.Message {
+	t.Helper()
+	m := b.m
+	create(t, db, &m)
+	return m
+}
+
+// DispatchSessionBuilder builds a models.DispatchSession.
+type DispatchSessionBuilder struct{ s models.DispatchSession }
+
+// DispatchSession returns a builder for an active local session with a
+// heartbeat of now and no cars created.
+func DispatchSession() *DispatchSessionBuilder {
+	now := time.Now()
+	return &DispatchSessionBuilder{s: models.DispatchSession{
+		Source:        "local",
+		UserName:      "tester",
+		Status:        "active",
+		CarsCreated:   "[]",
+		LastHeartbeat: now,
+		CreatedAt:     now,
+	}}
+}
+
+// FromTelegraph makes it a chat session on the given channel and thread.
+func (b *DispatchSessionBuilder) FromTelegraph(channelID, threadID string) *DispatchSessionBuilder {
+	b.s.Source, b.s.ChannelID, b.s.PlatformThreadID = "telegraph", channelID, threadID
+	return b
+}
+
+func (b *DispatchSessionBuilder) WithUser(name string) *DispatchSessionBuilder {
+	b.s.UserName = name
+	return b
+}
+func (b *DispatchSessionBuilder) WithStatus(status string) *DispatchSessionBuilder {
+	b.s.Status = status
+	return b
+}
+
+// LastHeartbeat sets when the session last reported in.
+func (b *DispatchSessionBuilder) LastHeartbeat(at time.Time) *DispatchSessionBuilder {
+	b.s.LastHeartbeat = at
+	return b
+}
+
+// Completed marks the session completed at the given time.
+func (b *DispatchSessionBuilder) Completed(at time.Time) *DispatchSessionBuilder {
+	b.s.Status, b.s.CompletedAt = "completed", &at
+	return b
+}
+
+// With applies fn to the session being built.
+func (b *DispatchSessionBuilder) With(fn func(*models.DispatchSession)) *DispatchSessionBuilder {
+	fn(&b.s)
+	return b
+}
+
+// Build returns the session without saving it.
+func (b *DispatchSessionBuilder) Build() models.DispatchSession { return b.s }
+
+// Create inserts the session and returns it with its ID.
+func (b *DispatchSessionBuilder) Create(t testing.TB, db *gorm.DB) models.DispatchSession {
+	t.Helper()
+	s := b.s
+	create(t, db, &s)
+	return s
+}
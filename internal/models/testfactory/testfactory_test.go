@@ -0,0 +1,78 @@
+package testfactory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.CarDep{}, &models.Engine{}, &models.Message{}, &models.DispatchSession{}, &models.TelegraphConversation{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestCar_DefaultsAndOverrides(t *testing.T) {
+	db := testDB(t)
+	a := Car().Create(t, db)
+	b := Car().WithStatus("done").WithTrack("frontend").WithPriority(0).Create(t, db)
+	if a.ID == b.ID {
+		t.Fatalf("two cars share ID %q", a.ID)
+	}
+
+	var got models.Car
+	db.First(&got, "id = ?", a.ID)
+	if got.Status != "open" || got.Track != DefaultTrack || got.Type != "task" || got.Priority != 2 || got.Title == "" {
+		t.Errorf("default car = %+v", got)
+	}
+	got = models.Car{}
+	db.First(&got, "id = ?", b.ID)
+	if got.Status != "done" || got.Track != "frontend" || got.Priority != 0 {
+		t.Errorf("overridden car = status %q, track %q, priority %d", got.Status, got.Track, got.Priority)
+	}
+
+	at := time.Now().Add(-time.Hour)
+	c := Car().ClaimedBy("eng-1", at).Build()
+	if c.Status != "claimed" || c.Assignee != "eng-1" || c.ClaimedAt == nil || !c.ClaimedAt.Equal(at) {
+		t.Errorf("ClaimedBy = %+v", c)
+	}
+	Dep(t, db, b.ID, a.ID)
+	var n int64
+	db.Model(&models.CarDep{}).Where("car_id = ? AND blocked_by = ?", b.ID, a.ID).Count(&n)
+	if n != 1 {
+		t.Errorf("deps = %d, want 1", n)
+	}
+}
+
+func TestEngineMessageDispatchSession(t *testing.T) {
+	db := testDB(t)
+	e := Engine().Idle(time.Hour).Create(t, db)
+	if e.Status != "idle" || time.Since(e.LastActivity) < time.Hour {
+		t.Errorf("idle engine = %+v", e)
+	}
+	if w := Engine().Working("car-1").Build(); w.Status != "working" || w.CurrentCar != "car-1" {
+		t.Errorf("working engine = %+v", w)
+	}
+
+	m := Message().To(e.ID).WithCar("car-1").Create(t, db)
+	if m.ID == 0 || m.ToAgent != e.ID || m.Acknowledged {
+		t.Errorf("message = %+v", m)
+	}
+
+	s := DispatchSession().FromTelegraph("C1", "T1").Create(t, db)
+	if s.ID == 0 || s.Source != "telegraph" || s.Status != "active" {
+		t.Errorf("session = %+v", s)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ThreadHistoryCache holds the chat messages of one thread as last fetched
+// from the platform, so resuming a dispatch session reads them from the
+// database and asks the platform only for messages newer than
+// LastMessageID.
+type ThreadHistoryCache struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement"`
+	ChannelID     string    `gorm:"size:128;not null;uniqueIndex:idx_thread_history_channel_thread"`
+	ThreadID      string    `gorm:"size:128;not null;uniqueIndex:idx_thread_history_channel_thread"`
+	LastMessageID string    `gorm:"size:128"`                 // platform ID of the newest cached message
+	Messages      string    `gorm:"type:mediumtext;not null"` // JSON array of messages, oldest first
+	FetchedAt     time.Time // last full fetch; older caches are refetched in full
+	UpdatedAt     time.Time
+}
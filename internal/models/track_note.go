@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TrackNote is a reusable fact an engine or operator recorded for a track
+// (e.g. "tests require FOO_ENV=1"). Notes are included in the prompt of
+// every engine working on the track until they are pruned.
+type TrackNote struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Track     string `gorm:"size:64;not null;index"`
+	Content   string `gorm:"type:text"`
+	Author    string `gorm:"size:64"` // engine ID, or "human" for notes added by hand
+	CarID     string `gorm:"size:32"` // car being worked on when the note was added; may be empty
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UndoEntry records one reversible CLI mutation so `ry undo` can restore the
+// state before it. Entries are kept per actor as a bounded stack.
+type UndoEntry struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Actor     string `gorm:"size:64;index"` // user who ran the command
+	Kind      string `gorm:"size:32"`       // "car" or "engine-scale"
+	Target    string `gorm:"size:64"`       // car ID or track name
+	Summary   string `gorm:"size:255"`      // human-readable description for --list
+	Data      string `gorm:"type:text"`     // JSON state needed to reverse the change
+	CreatedAt time.Time
+}
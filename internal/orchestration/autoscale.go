@@ -0,0 +1,165 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// AutoscaleAction is one change the autoscaler made to a track.
+type AutoscaleAction struct {
+	Track    string
+	Previous int
+	Current  int
+	Engine   string // drained engine (scale down) or created session (scale up)
+	Reason   string
+}
+
+// Autoscaler sizes each track's engine pool from its ready-car backlog, per
+// the config's autoscale section. Call Tick on each yardmaster cycle; the
+// Autoscaler keeps the backlog timers between calls.
+type Autoscaler struct {
+	DB         *gorm.DB
+	Config     *config.Config
+	ConfigPath string
+	Tmux       Tmux // defaults to TmuxFor(Config) if nil
+
+	// backlogSince is when each track's ready count last rose above the
+	// threshold, or since its last scale-up if that was later.
+	backlogSince map[string]time.Time
+}
+
+// NewAutoscaler returns an Autoscaler for cfg.
+func NewAutoscaler(db *gorm.DB, cfg *config.Config, configPath string) *Autoscaler {
+	return &Autoscaler{DB: db, Config: cfg, ConfigPath: configPath, backlogSince: make(map[string]time.Time)}
+}
+
+// Tick evaluates every track once and returns what it changed:
+//
+//   - a track whose ready cars have exceeded autoscale.ready_threshold for
+//     scale_up_after_min gets one more engine via Scale, up to its
+//     engine_slots; the timer then restarts, so a lasting backlog adds one
+//     engine per period.
+//   - on a track with no ready cars, one engine that has been idle with no
+//     car for idle_after_min is drained, down to min_engines.
+//
+// Errors on one track are collected and do not stop the others. Tick does
+// nothing when autoscale is disabled or in Kubernetes mode, where replicas
+// are managed by the cluster.
+func (a *Autoscaler) Tick(now time.Time) ([]AutoscaleAction, error) {
+	cfg := a.Config
+	if cfg == nil || !cfg.Autoscale.Enabled || cfg.IsKubernetesMode() {
+		return nil, nil
+	}
+	if a.DB == nil {
+		return nil, fmt.Errorf("orchestration: database connection is required")
+	}
+	if a.backlogSince == nil {
+		a.backlogSince = make(map[string]time.Time)
+	}
+	if a.Tmux == nil {
+		a.Tmux = TmuxFor(cfg)
+	}
+	as := cfg.Autoscale
+	upAfter := time.Duration(as.ScaleUpAfterMin) * time.Minute
+	idleAfter := time.Duration(as.IdleAfterMin) * time.Minute
+
+	var actions []AutoscaleAction
+	var errs []error
+	for _, t := range cfg.Tracks {
+		ready, err := readyCars(a.DB, t.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("track %s: count ready cars: %w", t.Name, err))
+			continue
+		}
+		var live []models.Engine
+		if err := a.DB.Where("track = ? AND status != ?", t.Name, "dead").Find(&live).Error; err != nil {
+			errs = append(errs, fmt.Errorf("track %s: list engines: %w", t.Name, err))
+			continue
+		}
+
+		if ready <= int64(as.ReadyThreshold) {
+			delete(a.backlogSince, t.Name)
+		} else if _, ok := a.backlogSince[t.Name]; !ok {
+			a.backlogSince[t.Name] = now
+		}
+
+		switch {
+		case ready > int64(as.ReadyThreshold):
+			if now.Sub(a.backlogSince[t.Name]) < upAfter || len(live) >= t.EngineSlots {
+				continue
+			}
+			res, err := Scale(ScaleOpts{
+				DB:         a.DB,
+				Config:     cfg,
+				ConfigPath: a.ConfigPath,
+				Track:      t.Name,
+				Count:      len(live) + 1,
+				Tmux:       a.Tmux,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("track %s: scale up: %w", t.Name, err))
+				continue
+			}
+			a.backlogSince[t.Name] = now
+			act := AutoscaleAction{Track: t.Name, Previous: res.Previous, Current: res.Current,
+				Reason: fmt.Sprintf("%d ready cars for %s", ready, upAfter)}
+			if len(res.SessionsCreated) > 0 {
+				act.Engine = res.SessionsCreated[0]
+			}
+			actions = append(actions, act)
+
+		case ready == 0 && len(live) > as.MinEngines:
+			idle := longestIdle(live, now, idleAfter)
+			if idle == nil {
+				continue
+			}
+			reason := fmt.Sprintf("idle since %s with no ready cars", idle.LastActivity.Format("15:04"))
+			if err := drainEngine(a.DB, idle.ID, fmt.Sprintf("Autoscaler: track %s has had no ready cars while this engine sat idle. Exit gracefully.", t.Name)); err != nil {
+				errs = append(errs, fmt.Errorf("track %s: scale down: %w", t.Name, err))
+				continue
+			}
+			actions = append(actions, AutoscaleAction{Track: t.Name, Previous: len(live), Current: len(live) - 1,
+				Engine: idle.ID, Reason: reason})
+		}
+	}
+	if len(errs) > 0 {
+		return actions, fmt.Errorf("orchestration: autoscale: %w", errors.Join(errs...))
+	}
+	return actions, nil
+}
+
+// longestIdle returns the engine that has been idle with no car the
+// longest, if that is at least d, or nil.
+func longestIdle(engines []models.Engine, now time.Time, d time.Duration) *models.Engine {
+	var best *models.Engine
+	for i := range engines {
+		e := &engines[i]
+		if e.Status != "idle" || e.CurrentCar != "" || now.Sub(e.LastActivity) < d {
+			continue
+		}
+		if best == nil || e.LastActivity.Before(best.LastActivity) {
+			best = e
+		}
+	}
+	return best
+}
+
+// readyCars counts a track's claimable cars: open, unassigned, not an epic,
+// and with no unresolved blockers.
+func readyCars(db *gorm.DB, track string) (int64, error) {
+	var n int64
+	err := db.Model(&models.Car{}).
+		Where("track = ? AND status = ? AND (assignee = ? OR assignee IS NULL) AND type != ?", track, "open", "", "epic").
+		Where("id NOT IN (?)",
+			db.Model(&models.CarDep{}).
+				Select("car_id").
+				Joins("JOIN cars ON cars.id = car_deps.blocked_by").
+				Where("cars.status NOT IN ?", models.ResolvedBlockerStatuses),
+		).Count(&n).Error
+	return n, err
+}
@@ -0,0 +1,107 @@
+package orchestration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/models/testfactory"
+)
+
+func autoscaleConfig() *config.Config {
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 3})
+	cfg.Autoscale = config.AutoscaleConfig{Enabled: true, ReadyThreshold: 2, ScaleUpAfterMin: 5, IdleAfterMin: 15, MinEngines: 1}
+	return cfg
+}
+
+func TestAutoscaler_ScalesUpOnSustainedBacklog(t *testing.T) {
+	db := testDB(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	testfactory.Engine().WithID("eng-1").Working("car-0").Create(t, db)
+	for i := 0; i < 4; i++ {
+		testfactory.Car().Create(t, db)
+	}
+	m := &mockTmux{sessionExistsFunc: func(name string) bool { return name == YardmasterSession("test") }}
+	a := NewAutoscaler(db, autoscaleConfig(), "/tmp/test.yaml")
+	a.Tmux = m
+
+	for _, step := range []struct {
+		at    time.Duration
+		wantN int
+	}{
+		{0, 0},                // backlog first seen
+		{4 * time.Minute, 0},  // not sustained long enough
+		{5 * time.Minute, 1},  // five minutes over the threshold
+		{6 * time.Minute, 0},  // timer restarted by the scale-up
+		{10 * time.Minute, 1}, // still backlogged a period later
+	} {
+		actions, err := a.Tick(now.Add(step.at))
+		if err != nil {
+			t.Fatalf("Tick at +%s: %v", step.at, err)
+		}
+		if len(actions) != step.wantN {
+			t.Fatalf("Tick at +%s: actions = %+v, want %d", step.at, actions, step.wantN)
+		}
+	}
+	if len(m.createdSessions) != 2 {
+		t.Errorf("sessions created = %v, want 2", m.createdSessions)
+	}
+
+	// At engine_slots the backlog no longer adds engines.
+	testfactory.Engine().WithStatus("working").Create(t, db)
+	testfactory.Engine().WithStatus("working").Create(t, db)
+	if actions, _ := a.Tick(now.Add(time.Hour)); len(actions) != 0 {
+		t.Errorf("actions at engine_slots = %+v, want none", actions)
+	}
+}
+
+func TestAutoscaler_DrainsIdleEngines(t *testing.T) {
+	db := testDB(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	testfactory.Engine().WithID("eng-old").LastActive(now.Add(-40*time.Minute)).Create(t, db)
+	testfactory.Engine().WithID("eng-new").LastActive(now.Add(-20*time.Minute)).Create(t, db)
+	testfactory.Engine().WithID("eng-busy").Working("car-1").LastActive(now.Add(-time.Hour)).Create(t, db)
+	a := NewAutoscaler(db, autoscaleConfig(), "/tmp/test.yaml")
+	a.Tmux = &mockTmux{}
+
+	actions, err := a.Tick(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Engine != "eng-old" || actions[0].Current != 2 {
+		t.Fatalf("actions = %+v, want eng-old drained", actions)
+	}
+	var eng models.Engine
+	db.First(&eng, "id = ?", "eng-old")
+	if eng.Status != "dead" {
+		t.Errorf("eng-old status = %q, want dead", eng.Status)
+	}
+
+	// One engine per tick; the next drains eng-new, then min_engines holds.
+	if actions, _ := a.Tick(now); len(actions) != 1 || actions[0].Engine != "eng-new" {
+		t.Errorf("second tick = %+v, want eng-new drained", actions)
+	}
+	if actions, _ := a.Tick(now); len(actions) != 0 {
+		t.Errorf("third tick = %+v, want none at min_engines", actions)
+	}
+
+	// Ready work keeps idle engines.
+	testfactory.Engine().LastActive(now.Add(-time.Hour)).Create(t, db)
+	testfactory.Car().Create(t, db)
+	if actions, _ := a.Tick(now); len(actions) != 0 {
+		t.Errorf("tick with ready cars = %+v, want none", actions)
+	}
+}
+
+func TestAutoscaler_Disabled(t *testing.T) {
+	db := testDB(t)
+	cfg := autoscaleConfig()
+	cfg.Autoscale.Enabled = false
+	testfactory.Engine().Idle(time.Hour).Create(t, db)
+	testfactory.Engine().Idle(time.Hour).Create(t, db)
+	actions, err := NewAutoscaler(db, cfg, "").Tick(time.Now())
+	if err != nil || len(actions) != 0 {
+		t.Errorf("Tick = %+v, %v; want nothing when disabled", actions, err)
+	}
+}
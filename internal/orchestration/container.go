@@ -0,0 +1,85 @@
+package orchestration
+
+import (
+	"fmt"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// LaunchDispatch starts the Dispatch planner in its own session, for a
+// yard whose sessions are not started from a terminal (ry container run).
+// Operators attach to it to plan work.
+func LaunchDispatch(tmux Tmux, cfg *config.Config, configPath string) (string, error) {
+	if tmux == nil {
+		tmux = TmuxFor(cfg)
+	}
+	session := DispatchSession(cfg.Owner)
+	if err := tmux.CreateSession(session); err != nil {
+		return "", fmt.Errorf("orchestration: create dispatch session: %w", err)
+	}
+	args := daemonArgs(cfg, configPath, "dispatch")
+	if err := tmux.SendKeys(session, shellCommand(args...)); err != nil {
+		return session, fmt.Errorf("orchestration: start dispatch: %w", err)
+	}
+	return session, verifyLaunched(clock.Real, tmux, []paneLaunch{{session, args}})
+}
+
+// Keeper relaunches the yard's daemon sessions when their ry process
+// exits: the yardmaster, telegraph, bull, inspect, and dispatch sessions
+// of a started yard. Engines are not its concern; the yardmaster's
+// Supervisor restarts them. Together they let ry container run be the
+// single supervisor of a containerized yard.
+type Keeper struct {
+	Tmux    Tmux
+	daemons []paneLaunch
+}
+
+// NewKeeper returns a Keeper for the daemon sessions in started and for
+// dispatchSession (skipped when empty).
+func NewKeeper(tmux Tmux, cfg *config.Config, configPath string, started *StartResult, dispatchSession string) *Keeper {
+	if tmux == nil {
+		tmux = TmuxFor(cfg)
+	}
+	k := &Keeper{Tmux: tmux}
+	for _, d := range []struct {
+		session string
+		sub     []string
+	}{
+		{started.YardmasterSession, []string{"yardmaster"}},
+		{started.TelegraphSession, []string{"telegraph", "start"}},
+		{started.BullSession, []string{"bull"}},
+		{started.InspectSession, []string{"inspect"}},
+		{dispatchSession, []string{"dispatch"}},
+	} {
+		if d.session != "" {
+			k.daemons = append(k.daemons, paneLaunch{d.session, daemonArgs(cfg, configPath, d.sub...)})
+		}
+	}
+	return k
+}
+
+// Tick relaunches every daemon whose session is gone or whose pane is back
+// at a shell, and returns the sessions it relaunched. Multiplexers that
+// cannot report a pane's process only get missing sessions recreated.
+func (k *Keeper) Tick() ([]string, error) {
+	pi, inspect := k.Tmux.(paneInspector)
+	var relaunched []string
+	for _, d := range k.daemons {
+		if k.Tmux.SessionExists(d.session) {
+			if !inspect {
+				continue
+			}
+			if current, err := pi.PaneCommand(d.session); err != nil || current == d.args[0] {
+				continue
+			}
+		} else if err := k.Tmux.CreateSession(d.session); err != nil {
+			return relaunched, fmt.Errorf("orchestration: recreate %s: %w", d.session, err)
+		}
+		if err := k.Tmux.SendKeys(d.session, shellCommand(d.args...)); err != nil {
+			return relaunched, fmt.Errorf("orchestration: relaunch %s: %w", d.session, err)
+		}
+		relaunched = append(relaunched, d.session)
+	}
+	return relaunched, nil
+}
@@ -0,0 +1,52 @@
+package orchestration
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func TestLaunchDispatch(t *testing.T) {
+	fastLaunchChecks(t)
+	m := newInspectingTmux()
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 1})
+	cfg.Overrides = []string{"tracks.backend.engine_slots=1"}
+
+	session, err := LaunchDispatch(m, cfg, "/tmp/test.yaml")
+	if err != nil {
+		t.Fatalf("LaunchDispatch: %v", err)
+	}
+	want := "ry dispatch --config /tmp/test.yaml --set tracks.backend.engine_slots=1"
+	if session != DispatchSession("test") || len(m.sentKeys) != 1 || m.sentKeys[0] != want {
+		t.Errorf("session = %s, sent keys = %q, want %q", session, m.sentKeys, want)
+	}
+}
+
+func TestKeeper_RelaunchesExitedDaemons(t *testing.T) {
+	m := newInspectingTmux()
+	ym, tg, disp := YardmasterSession("test"), TelegraphSession("test"), DispatchSession("test")
+	m.sessionExistsFunc = func(name string) bool { return name != disp }
+	m.dead[tg] = true
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 1})
+
+	k := NewKeeper(m, cfg, "/tmp/test.yaml", &StartResult{
+		YardmasterSession: ym,
+		TelegraphSession:  tg,
+		EngineSessions:    []EngineSessionInfo{{Session: EngineSession("test", 0), Track: "backend"}},
+	}, disp)
+	relaunched, err := k.Tick()
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if !slices.Equal(relaunched, []string{tg, disp}) {
+		t.Errorf("relaunched = %v, want the exited telegraph and the missing dispatch", relaunched)
+	}
+	if !slices.Equal(m.createdSessions, []string{disp}) {
+		t.Errorf("created sessions = %v, want only the missing one", m.createdSessions)
+	}
+	want := []string{"ry telegraph start --config /tmp/test.yaml", "ry dispatch --config /tmp/test.yaml"}
+	if !slices.Equal(m.sentKeys, want) {
+		t.Errorf("sent keys = %q, want %q", m.sentKeys, want)
+	}
+}
@@ -0,0 +1,257 @@
+package orchestration
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultEngineStatsWindow is the rolling window engine stats cover unless
+// told otherwise.
+const DefaultEngineStatsWindow = 7 * 24 * time.Hour
+
+// Engine flag kinds: what an underperforming engine should get.
+const (
+	// FlagRestart marks a live engine whose process looks broken.
+	FlagRestart = "restart"
+	// FlagReview marks an engine whose output suggests its track's prompt
+	// or agent profile (agent_provider, agent_model) needs review.
+	FlagReview = "review"
+)
+
+// Engine flag thresholds.
+const (
+	// minFlagCars is the fewest completed cars an engine needs before its
+	// merge failure rate or cycle time is judged.
+	minFlagCars = 3
+	// flagGateFailureRate is the share of an engine's completed cars
+	// rejected by the merge gate at which it is flagged for review.
+	flagGateFailureRate = 0.5
+	// flagSlowCycle is how many times its track's average cycle time an
+	// engine may take before it is flagged for review.
+	flagSlowCycle = 2
+	// flagStaleReleases is how many claims a live engine may have released
+	// for lack of progress before it is flagged for restart.
+	flagStaleReleases = 2
+)
+
+// restartDrainReason is the drain instruction RestartEngine sends; engine
+// stats count these messages as restarts.
+const restartDrainReason = "Engine restarting. Complete current work and exit gracefully."
+
+// EngineStats is one engine's performance over a stats window.
+type EngineStats struct {
+	EngineID      string
+	Track         string
+	Provider      string
+	Status        string
+	Completed     int           // cars the engine completed in the window
+	GateFailures  int           // of those, cars the merge gate rejected at least once
+	AvgCycle      time.Duration // mean claim-to-completion time of the completed cars
+	Restarts      int           // times the engine was restarted in the window (stalls, ry engine restart, supervisor)
+	StaleReleases int           // claims the yardmaster took back for lack of progress
+	Flags         []EngineFlag
+}
+
+// EngineFlag is a reason to act on an underperforming engine.
+type EngineFlag struct {
+	Kind   string // FlagRestart or FlagReview
+	Reason string
+	Action string
+}
+
+// MergeFailureRate is the share of completed cars the merge gate rejected
+// at least once, or -1 when the engine completed none.
+func (s EngineStats) MergeFailureRate() float64 {
+	if s.Completed == 0 {
+		return -1
+	}
+	return float64(s.GateFailures) / float64(s.Completed)
+}
+
+// ComputeEngineStats returns the stats of every engine that was active in
+// [since, until) or is still live, best first: most cars completed, then
+// lowest merge failure rate. Engines that underperform their track's
+// other engines carry flags.
+func ComputeEngineStats(db *gorm.DB, since, until time.Time) ([]EngineStats, error) {
+	var engines []models.Engine
+	if err := db.Where("status != ? OR last_activity >= ?", "dead", since).
+		Order("id").Find(&engines).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: engine stats: %w", err)
+	}
+	byID := make(map[string]*EngineStats, len(engines))
+	stats := make([]EngineStats, len(engines))
+	for i, e := range engines {
+		provider := e.Provider
+		if provider == "" {
+			provider = "claude"
+		}
+		stats[i] = EngineStats{EngineID: e.ID, Track: e.Track, Provider: provider, Status: e.Status, StaleReleases: e.StaleReleases}
+		byID[e.ID] = &stats[i]
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(engines))
+	for i, e := range engines {
+		ids[i] = e.ID
+	}
+
+	var cars []models.Car
+	if err := db.Select("id", "assignee", "claimed_at", "completed_at").
+		Where("assignee IN ? AND completed_at >= ? AND completed_at < ?", ids, since, until).
+		Find(&cars).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: engine stats: completed cars: %w", err)
+	}
+	failed := make(map[string]bool)
+	if len(cars) > 0 {
+		carIDs := make([]string, len(cars))
+		for i, c := range cars {
+			carIDs[i] = c.ID
+		}
+		var failedIDs []string
+		if err := db.Model(&models.CarProgress{}).Distinct("car_id").
+			Where("car_id IN ? AND note LIKE ?", carIDs, car.GateFailureNote+"%").
+			Pluck("car_id", &failedIDs).Error; err != nil {
+			return nil, fmt.Errorf("orchestration: engine stats: gate failures: %w", err)
+		}
+		for _, id := range failedIDs {
+			failed[id] = true
+		}
+	}
+
+	// Cycle totals per engine and per track, for the track averages the
+	// slow-cycle flag compares against.
+	type cycleTotal struct {
+		sum time.Duration
+		n   int
+	}
+	engineCycles := make(map[string]*cycleTotal)
+	trackCycles := make(map[string]*cycleTotal)
+	addCycle := func(totals map[string]*cycleTotal, key string, d time.Duration) {
+		if totals[key] == nil {
+			totals[key] = &cycleTotal{}
+		}
+		totals[key].sum += d
+		totals[key].n++
+	}
+	for _, c := range cars {
+		s := byID[c.Assignee]
+		s.Completed++
+		if failed[c.ID] {
+			s.GateFailures++
+		}
+		if c.ClaimedAt == nil || c.CompletedAt == nil {
+			continue
+		}
+		d := c.CompletedAt.Sub(*c.ClaimedAt)
+		if d <= 0 {
+			continue
+		}
+		addCycle(engineCycles, c.Assignee, d)
+		addCycle(trackCycles, s.Track, d)
+	}
+	for id, t := range engineCycles {
+		byID[id].AvgCycle = t.sum / time.Duration(t.n)
+	}
+
+	var restarts []models.Message
+	if err := db.Select("to_agent").
+		Where("to_agent IN ? AND subject = ? AND body = ? AND created_at >= ? AND created_at < ?",
+			ids, "drain", restartDrainReason, since, until).
+		Find(&restarts).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: engine stats: restarts: %w", err)
+	}
+	for _, m := range restarts {
+		byID[m.ToAgent].Restarts++
+	}
+
+	// Engines per track with a cycle average, so an engine is only called
+	// slow next to peers.
+	peers := make(map[string]int)
+	for _, s := range stats {
+		if s.AvgCycle > 0 {
+			peers[s.Track]++
+		}
+	}
+	for i := range stats {
+		s := &stats[i]
+		var trackAvg time.Duration
+		if t := trackCycles[s.Track]; t != nil && peers[s.Track] > 1 {
+			trackAvg = t.sum / time.Duration(t.n)
+		}
+		s.Flags = engineFlags(*s, trackAvg)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Completed != stats[j].Completed {
+			return stats[i].Completed > stats[j].Completed
+		}
+		return stats[i].MergeFailureRate() < stats[j].MergeFailureRate()
+	})
+	return stats, nil
+}
+
+// engineFlags decides whether an engine underperforms. trackAvg is the
+// average cycle time of its track's completed cars, or 0 when the engine
+// has no peers to compare with.
+func engineFlags(s EngineStats, trackAvg time.Duration) []EngineFlag {
+	var flags []EngineFlag
+	if s.Status != "dead" && s.StaleReleases >= flagStaleReleases {
+		flags = append(flags, EngineFlag{
+			Kind:   FlagRestart,
+			Reason: fmt.Sprintf("%d claims released for lack of progress", s.StaleReleases),
+			Action: fmt.Sprintf("check setup errors with `ry logs --engine %s`, then `ry engine restart %s`", s.EngineID, s.EngineID),
+		})
+	}
+	if s.Completed < minFlagCars {
+		return flags
+	}
+	review := fmt.Sprintf("review the %s track's system_prompt and conventions, or its agent_provider/agent_model (engine runs %s)", s.Track, s.Provider)
+	if rate := s.MergeFailureRate(); rate >= flagGateFailureRate {
+		flags = append(flags, EngineFlag{
+			Kind:   FlagReview,
+			Reason: fmt.Sprintf("%d of %d completed cars failed the merge gate (%.0f%%)", s.GateFailures, s.Completed, rate*100),
+			Action: review,
+		})
+	}
+	if trackAvg > 0 && s.AvgCycle > flagSlowCycle*trackAvg {
+		flags = append(flags, EngineFlag{
+			Kind:   FlagReview,
+			Reason: fmt.Sprintf("average cycle %s against %s for the track", formatDuration(s.AvgCycle), formatDuration(trackAvg)),
+			Action: review,
+		})
+	}
+	return flags
+}
+
+// FlaggedEngines returns the stats that carry flags.
+func FlaggedEngines(stats []EngineStats) []EngineStats {
+	var flagged []EngineStats
+	for _, s := range stats {
+		if len(s.Flags) > 0 {
+			flagged = append(flagged, s)
+		}
+	}
+	return flagged
+}
+
+// FlagKinds lists an engine's distinct flag kinds, comma-separated, or "-".
+func (s EngineStats) FlagKinds() string {
+	var kinds []string
+	for _, f := range s.Flags {
+		if !slices.Contains(kinds, f.Kind) {
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return "-"
+	}
+	return strings.Join(kinds, ",")
+}
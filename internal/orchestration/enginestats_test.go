@@ -0,0 +1,93 @@
+package orchestration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestComputeEngineStats(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.CarProgress{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	now := time.Now()
+	since := now.Add(-DefaultEngineStatsWindow)
+
+	db.Create(&models.Engine{ID: "eng-fast", Track: "backend", Status: "idle", LastActivity: now})
+	db.Create(&models.Engine{ID: "eng-slow", Track: "backend", Status: "working", Provider: "codex", LastActivity: now})
+	db.Create(&models.Engine{ID: "eng-stuck", Track: "frontend", Status: "idle", StaleReleases: 2, LastActivity: now})
+	db.Create(&models.Engine{ID: "eng-old", Track: "backend", Status: "dead", LastActivity: now.Add(-30 * 24 * time.Hour)})
+
+	car := func(id, engine string, cycle time.Duration, gateFailed bool) {
+		completed := now.Add(-time.Hour)
+		claimed := completed.Add(-cycle)
+		db.Create(&models.Car{ID: id, Title: id, Track: "backend", Status: "merged", Assignee: engine, ClaimedAt: &claimed, CompletedAt: &completed})
+		if gateFailed {
+			db.Create(&models.CarProgress{CarID: id, EngineID: "yardmaster", Note: "switch:test-failed: tests failed"})
+		}
+	}
+	for i, id := range []string{"car-f1", "car-f2", "car-f3", "car-f4"} {
+		car(id, "eng-fast", 30*time.Minute, i == 0)
+	}
+	car("car-s1", "eng-slow", 10*time.Hour, true)
+	car("car-s2", "eng-slow", 10*time.Hour, true)
+	car("car-s3", "eng-slow", 10*time.Hour, false)
+	// Completed before the window: not counted.
+	old := now.Add(-30 * 24 * time.Hour)
+	db.Create(&models.Car{ID: "car-old", Track: "backend", Status: "merged", Assignee: "eng-fast", ClaimedAt: &old, CompletedAt: &old})
+
+	db.Create(&models.Message{FromAgent: "orchestrator", ToAgent: "eng-slow", Subject: "drain", Body: restartDrainReason, CreatedAt: now.Add(-time.Hour)})
+	db.Create(&models.Message{FromAgent: "orchestrator", ToAgent: "eng-slow", Subject: "drain", Body: "Scaling down.", CreatedAt: now.Add(-time.Hour)})
+
+	stats, err := ComputeEngineStats(db, since, now)
+	if err != nil {
+		t.Fatalf("ComputeEngineStats: %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("got %d engines, want 3 (the dead engine was inactive): %+v", len(stats), stats)
+	}
+	fast, slow, stuck := stats[0], stats[1], stats[2]
+	if fast.EngineID != "eng-fast" || slow.EngineID != "eng-slow" || stuck.EngineID != "eng-stuck" {
+		t.Fatalf("ranking = %s, %s, %s; want eng-fast, eng-slow, eng-stuck", fast.EngineID, slow.EngineID, stuck.EngineID)
+	}
+
+	if fast.Completed != 4 || fast.GateFailures != 1 || fast.AvgCycle != 30*time.Minute || len(fast.Flags) != 0 {
+		t.Errorf("eng-fast = %+v, want 4 completed, 1 gate failure, 30m cycle, no flags", fast)
+	}
+	if slow.Completed != 3 || slow.Restarts != 1 || slow.Provider != "codex" {
+		t.Errorf("eng-slow = %+v, want 3 completed and 1 restart", slow)
+	}
+	if slow.FlagKinds() != FlagReview || len(slow.Flags) != 2 {
+		t.Fatalf("eng-slow flags = %+v, want review for gate failures and cycle time", slow.Flags)
+	}
+	if !strings.Contains(slow.Flags[0].Reason, "2 of 3") || !strings.Contains(slow.Flags[1].Reason, "average cycle") {
+		t.Errorf("eng-slow flag reasons = %q, %q", slow.Flags[0].Reason, slow.Flags[1].Reason)
+	}
+	if !strings.Contains(slow.Flags[0].Action, "backend") || !strings.Contains(slow.Flags[0].Action, "codex") {
+		t.Errorf("review action = %q, want the track and agent", slow.Flags[0].Action)
+	}
+	if stuck.FlagKinds() != FlagRestart || stuck.MergeFailureRate() != -1 {
+		t.Errorf("eng-stuck = %+v, want a restart flag and no failure rate", stuck)
+	}
+	if flagged := FlaggedEngines(stats); len(flagged) != 2 {
+		t.Errorf("FlaggedEngines = %d engines, want 2", len(flagged))
+	}
+}
+
+func TestEngineFlags_NeedsSamplesAndPeers(t *testing.T) {
+	few := EngineStats{EngineID: "eng-1", Track: "backend", Status: "idle", Completed: 2, GateFailures: 2}
+	if flags := engineFlags(few, 0); len(flags) != 0 {
+		t.Errorf("flags with 2 cars = %+v, want none", flags)
+	}
+	alone := EngineStats{EngineID: "eng-1", Track: "backend", Status: "idle", Completed: 5, AvgCycle: 10 * time.Hour}
+	if flags := engineFlags(alone, 0); len(flags) != 0 {
+		t.Errorf("flags without peers = %+v, want none", flags)
+	}
+	dead := EngineStats{EngineID: "eng-1", Status: "dead", StaleReleases: 5}
+	if flags := engineFlags(dead, 0); len(flags) != 0 {
+		t.Errorf("flags for a dead engine = %+v, want no restart", flags)
+	}
+}
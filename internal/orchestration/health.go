@@ -0,0 +1,337 @@
+package orchestration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Health signal kinds.
+const (
+	HealthIdleEngines    = "idle-engines"
+	HealthSlowCar        = "slow-car"
+	HealthMessageBacklog = "message-backlog"
+	HealthMergeFailures  = "merge-failures"
+	HealthStaleClaims    = "stale-claims"
+	HealthEngineFlagged  = "engine-flagged"
+)
+
+// Health signal severities.
+const (
+	SeverityWarn = "warn"
+	SeverityCrit = "crit"
+)
+
+// Health thresholds.
+const (
+	// idleEngineGrace is how long an engine may sit idle next to ready work
+	// before it is flagged (engines poll, so brief idleness is normal).
+	idleEngineGrace = 2 * time.Minute
+	// defaultCycleTime is the expected claim-to-done time for a track with
+	// too little history to measure.
+	defaultCycleTime = time.Hour
+	// cycleTimeSamples is how many recent completions set a track's expected
+	// cycle time, and minCycleTimeSamples the fewest worth trusting.
+	cycleTimeSamples    = 20
+	minCycleTimeSamples = 3
+	// backlogWindow is the window over which message inflow is compared.
+	backlogWindow = 15 * time.Minute
+	// backlogMinDepth and backlogCritDepth gate the message-backlog signal.
+	backlogMinDepth  = 10
+	backlogCritDepth = 50
+	// mergeStreakWindow is how many recent merge outcomes per track are
+	// inspected for a failure streak.
+	mergeStreakWindow = 5
+	// staleClaimsCrit is how many claims an engine may have released for
+	// lack of progress before that is critical.
+	staleClaimsCrit = 3
+)
+
+// YardHealth is a derived health score for the yard with the signals that
+// lowered it.
+type YardHealth struct {
+	Score   int // 0–100
+	Signals []HealthSignal
+}
+
+// HealthSignal is one anomaly with a suggested next action.
+type HealthSignal struct {
+	Kind     string // HealthIdleEngines, HealthSlowCar, ...
+	Severity string // SeverityWarn or SeverityCrit
+	Subject  string // track, car, or "messages"
+	Message  string
+	Action   string
+}
+
+// Label summarizes the score: healthy, degraded, or unhealthy.
+func (h YardHealth) Label() string {
+	switch {
+	case h.Score >= 90:
+		return "healthy"
+	case h.Score >= 60:
+		return "degraded"
+	default:
+		return "unhealthy"
+	}
+}
+
+// severityPenalty is the score deduction per signal.
+func severityPenalty(severity string) int {
+	if severity == SeverityCrit {
+		return 25
+	}
+	return 10
+}
+
+// assessHealth derives health signals from gathered status info plus a few
+// extra queries, and scores the yard.
+func assessHealth(db *gorm.DB, info *StatusInfo, now time.Time) YardHealth {
+	var signals []HealthSignal
+	signals = append(signals, idleEngineSignals(info, now)...)
+	signals = append(signals, slowCarSignals(db, now)...)
+	signals = append(signals, messageBacklogSignals(db, info.MessageDepth, now)...)
+	signals = append(signals, mergeFailureSignals(db, info.TrackSummary)...)
+	signals = append(signals, staleClaimSignals(db)...)
+	signals = append(signals, engineFlagSignals(db, now)...)
+
+	sort.SliceStable(signals, func(i, j int) bool {
+		return signals[i].Severity == SeverityCrit && signals[j].Severity != SeverityCrit
+	})
+
+	score := 100
+	for _, s := range signals {
+		score -= severityPenalty(s.Severity)
+	}
+	if score < 0 {
+		score = 0
+	}
+	return YardHealth{Score: score, Signals: signals}
+}
+
+// idleEngineSignals flags tracks with ready cars whose engines have been idle
+// past idleEngineGrace. All engines idle is critical.
+func idleEngineSignals(info *StatusInfo, now time.Time) []HealthSignal {
+	var signals []HealthSignal
+	for _, t := range info.TrackSummary {
+		if t.Ready == 0 {
+			continue
+		}
+		var live int
+		var idle []string
+		for _, e := range info.Engines {
+			if e.Track != t.Track {
+				continue
+			}
+			live++
+			if e.Status == "idle" && e.CurrentCar == "" && now.Sub(e.LastActivity) >= idleEngineGrace {
+				idle = append(idle, e.ID)
+			}
+		}
+		if len(idle) == 0 {
+			continue
+		}
+		severity := SeverityWarn
+		if len(idle) == live {
+			severity = SeverityCrit
+		}
+		signals = append(signals, HealthSignal{
+			Kind:     HealthIdleEngines,
+			Severity: severity,
+			Subject:  t.Track,
+			Message:  fmt.Sprintf("%d engine(s) idle while %d car(s) are ready", len(idle), t.Ready),
+			Action:   fmt.Sprintf("check claim errors with `ry logs --engine %s`, or `ry engine restart %s`", idle[0], idle[0]),
+		})
+	}
+	return signals
+}
+
+// slowCarSignals flags in_progress cars running past twice their track's
+// expected cycle time (critical past four times).
+func slowCarSignals(db *gorm.DB, now time.Time) []HealthSignal {
+	var cars []models.Car
+	db.Select("id", "track", "claimed_at").
+		Where("status = ? AND claimed_at IS NOT NULL", "in_progress").
+		Order("claimed_at ASC").Find(&cars)
+
+	expected := map[string]time.Duration{}
+	var signals []HealthSignal
+	for _, c := range cars {
+		want, ok := expected[c.Track]
+		if !ok {
+			want = expectedCycleTime(db, c.Track)
+			expected[c.Track] = want
+		}
+		elapsed := now.Sub(*c.ClaimedAt)
+		if elapsed <= 2*want {
+			continue
+		}
+		severity := SeverityWarn
+		if elapsed > 4*want {
+			severity = SeverityCrit
+		}
+		signals = append(signals, HealthSignal{
+			Kind:     HealthSlowCar,
+			Severity: severity,
+			Subject:  c.ID,
+			Message:  fmt.Sprintf("in progress %s on %s (expected ~%s)", formatDuration(elapsed), c.Track, formatDuration(want)),
+			Action:   fmt.Sprintf("review progress with `ry car journal %s` and `ry logs --car %s`", c.ID, c.ID),
+		})
+	}
+	return signals
+}
+
+// expectedCycleTime returns the median claim-to-completion time of the
+// track's recent completed cars, or defaultCycleTime without enough history.
+func expectedCycleTime(db *gorm.DB, track string) time.Duration {
+	var done []models.Car
+	db.Select("claimed_at", "completed_at").
+		Where("track = ? AND claimed_at IS NOT NULL AND completed_at IS NOT NULL", track).
+		Order("completed_at DESC").Limit(cycleTimeSamples).Find(&done)
+
+	var durations []time.Duration
+	for _, c := range done {
+		if d := c.CompletedAt.Sub(*c.ClaimedAt); d > 0 {
+			durations = append(durations, d)
+		}
+	}
+	if len(durations) < minCycleTimeSamples {
+		return defaultCycleTime
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durations[len(durations)/2]
+}
+
+// messageBacklogSignals flags an unacknowledged message queue that is both
+// deep and receiving more messages than in the preceding window.
+func messageBacklogSignals(db *gorm.DB, depth int64, now time.Time) []HealthSignal {
+	if depth < backlogMinDepth {
+		return nil
+	}
+	var recent, earlier int64
+	unacked := func() *gorm.DB {
+		return db.Model(&models.Message{}).Where("acknowledged = ? AND to_agent != ?", false, "broadcast")
+	}
+	unacked().Where("created_at >= ?", now.Add(-backlogWindow)).Count(&recent)
+	unacked().Where("created_at >= ? AND created_at < ?", now.Add(-2*backlogWindow), now.Add(-backlogWindow)).Count(&earlier)
+	if recent <= earlier {
+		return nil
+	}
+	severity := SeverityWarn
+	if depth >= backlogCritDepth {
+		severity = SeverityCrit
+	}
+	return []HealthSignal{{
+		Kind:     HealthMessageBacklog,
+		Severity: severity,
+		Subject:  "messages",
+		Message:  fmt.Sprintf("%d unacknowledged and growing (%d in the last %s vs %d before)", depth, recent, formatDuration(backlogWindow), earlier),
+		Action:   "check the yardmaster is running (`ry status` SESSIONS) and triage `ry message inbox`",
+	}}
+}
+
+// mergeFailureSignals flags tracks whose most recent merge outcomes are
+// consecutive failures.
+func mergeFailureSignals(db *gorm.DB, tracks []TrackSummary) []HealthSignal {
+	var signals []HealthSignal
+	for _, t := range tracks {
+		if t.MergeFailed == 0 {
+			continue
+		}
+		var outcomes []models.Car
+		db.Select("id", "status").
+			Where("track = ? AND status IN ?", t.Track, []string{"merged", "merge-failed"}).
+			Order("updated_at DESC").Limit(mergeStreakWindow).Find(&outcomes)
+
+		var streak []string
+		for _, c := range outcomes {
+			if c.Status != "merge-failed" {
+				break
+			}
+			streak = append(streak, c.ID)
+		}
+		if len(streak) < 2 {
+			continue
+		}
+		severity := SeverityWarn
+		if len(streak) >= 3 {
+			severity = SeverityCrit
+		}
+		signals = append(signals, HealthSignal{
+			Kind:     HealthMergeFailures,
+			Severity: severity,
+			Subject:  t.Track,
+			Message:  fmt.Sprintf("%d merges failed in a row (%s)", len(streak), strings.Join(streak, ", ")),
+			Action:   fmt.Sprintf("check the base branch and test command, then retry with `ry yardmaster switch %s`", streak[0]),
+		})
+	}
+	return signals
+}
+
+// staleClaimSignals flags live engines the yardmaster has taken claimed
+// cars back from because they never started on them.
+func staleClaimSignals(db *gorm.DB) []HealthSignal {
+	var engines []models.Engine
+	db.Select("id", "stale_releases", "last_release_reason").
+		Where("status != ? AND stale_releases > 0", "dead").
+		Order("stale_releases DESC, id").Find(&engines)
+
+	var signals []HealthSignal
+	for _, e := range engines {
+		severity := SeverityWarn
+		if e.StaleReleases >= staleClaimsCrit {
+			severity = SeverityCrit
+		}
+		signals = append(signals, HealthSignal{
+			Kind:     HealthStaleClaims,
+			Severity: severity,
+			Subject:  e.ID,
+			Message:  fmt.Sprintf("%d claim(s) released for lack of progress (last: %s)", e.StaleReleases, e.LastReleaseReason),
+			Action:   fmt.Sprintf("check setup errors with `ry logs --engine %s`, or `ry engine restart %s`", e.ID, e.ID),
+		})
+	}
+	return signals
+}
+
+// engineFlagSignals flags live engines that underperform their track's
+// other engines over the default engine stats window. Restart flags are
+// left to staleClaimSignals, which already reports the released claims.
+func engineFlagSignals(db *gorm.DB, now time.Time) []HealthSignal {
+	stats, err := ComputeEngineStats(db, now.Add(-DefaultEngineStatsWindow), now)
+	if err != nil {
+		return nil
+	}
+	var signals []HealthSignal
+	for _, s := range stats {
+		if s.Status == "dead" {
+			continue
+		}
+		for _, f := range s.Flags {
+			if f.Kind != FlagReview {
+				continue
+			}
+			signals = append(signals, HealthSignal{
+				Kind:     HealthEngineFlagged,
+				Severity: SeverityWarn,
+				Subject:  s.EngineID,
+				Message:  f.Reason,
+				Action:   f.Action,
+			})
+		}
+	}
+	return signals
+}
+
+// formatHealth renders the YARD HEALTH section.
+func formatHealth(h YardHealth) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("YARD HEALTH: %d/100 (%s)\n", h.Score, h.Label()))
+	for _, s := range h.Signals {
+		b.WriteString(fmt.Sprintf("  [%s] %s: %s\n", s.Severity, s.Subject, s.Message))
+		b.WriteString(fmt.Sprintf("         → %s\n", s.Action))
+	}
+	return b.String()
+}
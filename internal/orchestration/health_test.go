@@ -0,0 +1,191 @@
+package orchestration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestYardHealth_Label(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{100, "healthy"},
+		{90, "healthy"},
+		{75, "degraded"},
+		{59, "unhealthy"},
+	}
+	for _, tt := range tests {
+		if got := (YardHealth{Score: tt.score}).Label(); got != tt.want {
+			t.Errorf("Label(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestIdleEngineSignals(t *testing.T) {
+	now := time.Now()
+	info := &StatusInfo{
+		Engines: []EngineInfo{
+			{ID: "eng-idle", Track: "backend", Status: "idle", LastActivity: now.Add(-10 * time.Minute)},
+			{ID: "eng-busy", Track: "backend", Status: "working", CurrentCar: "car-1", LastActivity: now},
+			{ID: "eng-fresh", Track: "frontend", Status: "idle", LastActivity: now.Add(-30 * time.Second)},
+			{ID: "eng-fe", Track: "frontend", Status: "idle", LastActivity: now.Add(-10 * time.Minute)},
+			{ID: "eng-docs", Track: "docs", Status: "idle", LastActivity: now.Add(-10 * time.Minute)},
+		},
+		TrackSummary: []TrackSummary{
+			{Track: "backend", Ready: 2},
+			{Track: "frontend", Ready: 1},
+			{Track: "docs", Ready: 0},
+		},
+	}
+
+	signals := idleEngineSignals(info, now)
+	if len(signals) != 2 {
+		t.Fatalf("got %d signals, want 2 (backend, frontend): %+v", len(signals), signals)
+	}
+	if signals[0].Subject != "backend" || signals[0].Severity != SeverityWarn {
+		t.Errorf("backend signal = %+v, want warn (one of two engines idle)", signals[0])
+	}
+	if !strings.Contains(signals[0].Action, "eng-idle") {
+		t.Errorf("action = %q, want it to name the idle engine", signals[0].Action)
+	}
+	if signals[1].Subject != "frontend" || signals[1].Severity != SeverityWarn {
+		t.Errorf("frontend signal = %+v, want warn (fresh idle engine within grace)", signals[1])
+	}
+}
+
+func TestSlowCarSignals_UsesTrackHistory(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	// Three completed backend cars took 10 minutes each.
+	for i, id := range []string{"done-1", "done-2", "done-3"} {
+		claimed := now.Add(-time.Duration(i+1) * 24 * time.Hour)
+		completed := claimed.Add(10 * time.Minute)
+		db.Create(&models.Car{ID: id, Title: id, Track: "backend", Status: "merged", ClaimedAt: &claimed, CompletedAt: &completed})
+	}
+	slow := now.Add(-30 * time.Minute)
+	ok := now.Add(-15 * time.Minute)
+	db.Create(&models.Car{ID: "car-slow", Title: "slow", Track: "backend", Status: "in_progress", ClaimedAt: &slow})
+	db.Create(&models.Car{ID: "car-ok", Title: "ok", Track: "backend", Status: "in_progress", ClaimedAt: &ok})
+	// No history on frontend: the 1h default applies.
+	db.Create(&models.Car{ID: "car-fe", Title: "fe", Track: "frontend", Status: "in_progress", ClaimedAt: &slow})
+
+	signals := slowCarSignals(db, now)
+	if len(signals) != 1 {
+		t.Fatalf("got %d signals, want 1: %+v", len(signals), signals)
+	}
+	if signals[0].Subject != "car-slow" || signals[0].Severity != SeverityWarn {
+		t.Errorf("signal = %+v, want warn for car-slow (3x expected)", signals[0])
+	}
+}
+
+func TestMessageBacklogSignals(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	if got := messageBacklogSignals(db, 3, now); got != nil {
+		t.Errorf("shallow queue flagged: %+v", got)
+	}
+
+	for i := 0; i < 12; i++ {
+		db.Create(&models.Message{FromAgent: "eng-1", ToAgent: "human", Subject: "help", CreatedAt: now.Add(-time.Minute)})
+	}
+	db.Create(&models.Message{FromAgent: "eng-1", ToAgent: "human", Subject: "help", CreatedAt: now.Add(-20 * time.Minute)})
+
+	signals := messageBacklogSignals(db, 13, now)
+	if len(signals) != 1 || signals[0].Kind != HealthMessageBacklog || signals[0].Severity != SeverityWarn {
+		t.Fatalf("signals = %+v, want one warn backlog signal", signals)
+	}
+}
+
+func TestMessageBacklogSignals_NotGrowing(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	for i := 0; i < 12; i++ {
+		db.Create(&models.Message{FromAgent: "eng-1", ToAgent: "human", Subject: "help", CreatedAt: now.Add(-20 * time.Minute)})
+	}
+	if got := messageBacklogSignals(db, 12, now); got != nil {
+		t.Errorf("deep but static queue flagged: %+v", got)
+	}
+}
+
+func TestMergeFailureSignals_Streak(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	db.Create(&models.Car{ID: "car-m1", Title: "m1", Track: "backend", Status: "merged", UpdatedAt: now.Add(-time.Hour)})
+	db.Create(&models.Car{ID: "car-f1", Title: "f1", Track: "backend", Status: "merge-failed", UpdatedAt: now.Add(-2 * time.Minute)})
+	db.Create(&models.Car{ID: "car-f2", Title: "f2", Track: "backend", Status: "merge-failed", UpdatedAt: now.Add(-time.Minute)})
+	db.Create(&models.Car{ID: "car-f3", Title: "f3", Track: "frontend", Status: "merge-failed", UpdatedAt: now.Add(-time.Minute)})
+	db.Create(&models.Car{ID: "car-m2", Title: "m2", Track: "frontend", Status: "merged", UpdatedAt: now})
+
+	signals := mergeFailureSignals(db, []TrackSummary{
+		{Track: "backend", MergeFailed: 2},
+		{Track: "frontend", MergeFailed: 1},
+	})
+	if len(signals) != 1 {
+		t.Fatalf("got %d signals, want 1 (backend streak): %+v", len(signals), signals)
+	}
+	if signals[0].Subject != "backend" || !strings.Contains(signals[0].Message, "2 merges failed in a row") {
+		t.Errorf("signal = %+v", signals[0])
+	}
+	if !strings.Contains(signals[0].Action, "ry yardmaster switch car-f2") {
+		t.Errorf("action = %q, want retry of most recent failure", signals[0].Action)
+	}
+}
+
+func TestStaleClaimSignals(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Engine{ID: "eng-ok", Track: "backend", Status: "idle"})
+	db.Create(&models.Engine{ID: "eng-flaky", Track: "backend", Status: "idle", StaleReleases: 1, LastReleaseReason: "car-1: claimed 20m ago"})
+	db.Create(&models.Engine{ID: "eng-broken", Track: "backend", Status: "working", StaleReleases: 3, LastReleaseReason: "car-2: claimed 16m ago"})
+	db.Create(&models.Engine{ID: "eng-gone", Track: "backend", Status: "dead", StaleReleases: 5})
+
+	signals := staleClaimSignals(db)
+	if len(signals) != 2 {
+		t.Fatalf("got %d signals, want 2 (live engines with releases): %+v", len(signals), signals)
+	}
+	if signals[0].Subject != "eng-broken" || signals[0].Severity != SeverityCrit {
+		t.Errorf("first signal = %+v, want crit for eng-broken", signals[0])
+	}
+	if signals[1].Subject != "eng-flaky" || signals[1].Severity != SeverityWarn || !strings.Contains(signals[1].Message, "car-1") {
+		t.Errorf("second signal = %+v, want warn for eng-flaky naming car-1", signals[1])
+	}
+}
+
+func TestAssessHealth_Score(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	info := &StatusInfo{
+		Engines:      []EngineInfo{{ID: "eng-1", Track: "backend", Status: "idle", LastActivity: now.Add(-time.Hour)}},
+		TrackSummary: []TrackSummary{{Track: "backend", Ready: 1}},
+	}
+	h := assessHealth(db, info, now)
+	if len(h.Signals) != 1 || h.Signals[0].Severity != SeverityCrit {
+		t.Fatalf("signals = %+v, want one crit (all engines idle)", h.Signals)
+	}
+	if h.Score != 75 {
+		t.Errorf("Score = %d, want 75", h.Score)
+	}
+
+	if h := assessHealth(db, &StatusInfo{}, now); h.Score != 100 || len(h.Signals) != 0 {
+		t.Errorf("empty yard health = %+v, want 100 with no signals", h)
+	}
+}
+
+func TestFormatStatus_Health(t *testing.T) {
+	info := &StatusInfo{Health: &YardHealth{Score: 90, Signals: []HealthSignal{{
+		Kind: HealthSlowCar, Severity: SeverityWarn, Subject: "car-1", Message: "in progress 2h 0m", Action: "review it",
+	}}}}
+	out := FormatStatus(info)
+	for _, want := range []string{"YARD HEALTH: 90/100 (healthy)", "[warn] car-1: in progress 2h 0m", "→ review it"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	if out := FormatStatus(&StatusInfo{}); strings.Contains(out, "YARD HEALTH") {
+		t.Errorf("health section rendered without an assessment:\n%s", out)
+	}
+}
@@ -0,0 +1,156 @@
+package orchestration
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// shellCommand joins args into a command line for SendKeys, quoting each
+// argument for a POSIX shell so config paths and track names containing
+// spaces or metacharacters reach ry as a single argument.
+func shellCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// daemonArgs is the command line that runs the ry subcommand sub against
+// configPath. The config's --set overrides are passed on so the daemon runs
+// with the same values as the command that launched it.
+func daemonArgs(cfg *config.Config, configPath string, sub ...string) []string {
+	args := append(append([]string{"ry"}, sub...), "--config", configPath)
+	if cfg != nil {
+		for _, o := range cfg.Overrides {
+			args = append(args, "--set", o)
+		}
+	}
+	return args
+}
+
+// engineArgs is the command line that starts an engine daemon on track.
+// A non-empty agentBinary pins the agent CLI the engine runs.
+func engineArgs(cfg *config.Config, configPath, track, agentBinary string) []string {
+	args := append(daemonArgs(cfg, configPath, "engine", "start"), "--track", track)
+	if agentBinary != "" {
+		args = append(args, "--agent-binary", agentBinary)
+	}
+	return args
+}
+
+// shellQuote returns s unchanged when it only contains characters the shell
+// treats literally, and single-quoted otherwise.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool { return !isShellSafe(r) }) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellSafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("@%+=:,./-_", r)
+}
+
+// paneInspector is implemented by multiplexers that can report what a
+// session's pane is running. Start and Scale use it to confirm each daemon
+// actually launched; backends without it (zellij, test mocks, the unittest
+// stub) are not verified.
+type paneInspector interface {
+	// PaneCommand returns the name of the pane's foreground process.
+	PaneCommand(session string) (string, error)
+	// CapturePane returns the pane's visible output.
+	CapturePane(session string) (string, error)
+}
+
+// launchCheckDelays are the waits before each check that a pane's process
+// started. A shell still reading its rc files has not run the command yet,
+// so the checks back off to about six seconds in total. Package var so
+// tests can shorten it.
+var launchCheckDelays = []time.Duration{
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	400 * time.Millisecond,
+	800 * time.Millisecond,
+	1600 * time.Millisecond,
+	3200 * time.Millisecond,
+}
+
+// launchTailLines bounds the pane output quoted in a failed launch error.
+const launchTailLines = 10
+
+// paneLaunch is a command sent to a session, to be verified by
+// verifyLaunched.
+type paneLaunch struct {
+	session string
+	args    []string
+}
+
+// verifyLaunched checks that each launch's pane is running the launched
+// program, re-checking with backoff while the shell gets there. A pane that
+// never does (the command failed, or the program is not on PATH) is
+// reported with the tail of its output, since the error is otherwise only
+// visible by attaching to the session.
+func verifyLaunched(clk clock.Clock, tmux Tmux, launches []paneLaunch) error {
+	pi, ok := tmux.(paneInspector)
+	if !ok {
+		return nil
+	}
+	var failed []string
+	for _, l := range launches {
+		if err := waitForLaunch(clk, pi, l); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("orchestration: %s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+func waitForLaunch(clk clock.Clock, pi paneInspector, l paneLaunch) error {
+	want := filepath.Base(l.args[0])
+	var current string
+	var err error
+	for _, d := range launchCheckDelays {
+		clk.Sleep(d)
+		current, err = pi.PaneCommand(l.session)
+		if err == nil && current == want {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: check %s launched: %w", l.session, want, err)
+	}
+	msg := fmt.Sprintf("%s: %s did not start (pane is running %q)", l.session, shellCommand(l.args...), current)
+	if out, cErr := pi.CapturePane(l.session); cErr == nil {
+		if tail := paneTail(out, launchTailLines); tail != "" {
+			msg += ":\n" + tail
+		}
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// paneTail returns the last n non-blank lines of pane output, indented.
+func paneTail(out string, n int) string {
+	lines := strings.Split(strings.TrimRight(out, "\n "), "\n")
+	var kept []string
+	for i := len(lines) - 1; i >= 0 && len(kept) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			kept = append([]string{"    " + strings.TrimRight(lines[i], " ")}, kept...)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
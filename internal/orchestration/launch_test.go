@@ -0,0 +1,161 @@
+package orchestration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// inspectingTmux is a mockTmux whose panes report a foreground process:
+// "ry" once keys have been sent, unless the session is listed in dead.
+type inspectingTmux struct {
+	*mockTmux
+	dead     map[string]bool
+	checks   map[string]int
+	inspectE error
+}
+
+func newInspectingTmux() *inspectingTmux {
+	return &inspectingTmux{mockTmux: &mockTmux{}, dead: map[string]bool{}, checks: map[string]int{}}
+}
+
+func (m *inspectingTmux) PaneCommand(session string) (string, error) {
+	m.checks[session]++
+	if m.inspectE != nil {
+		return "", m.inspectE
+	}
+	if m.dead[session] {
+		return "bash", nil
+	}
+	return "ry", nil
+}
+
+func (m *inspectingTmux) CapturePane(session string) (string, error) {
+	return "$ ry engine start --config x\nError: load config: open x: no such file or directory\n$ \n\n", nil
+}
+
+func fastLaunchChecks(t *testing.T) {
+	t.Helper()
+	saved := launchCheckDelays
+	launchCheckDelays = []time.Duration{0, 0, 0}
+	t.Cleanup(func() { launchCheckDelays = saved })
+}
+
+func TestShellCommand(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"ry", "engine", "start", "--config", "/srv/yard/railyard.yaml"}, "ry engine start --config /srv/yard/railyard.yaml"},
+		{[]string{"ry", "yardmaster", "--config", "/home/me/My Yard/railyard.yaml"}, "ry yardmaster --config '/home/me/My Yard/railyard.yaml'"},
+		{[]string{"ry", "--track", "it's;$(rm -rf)"}, `ry --track 'it'\''s;$(rm -rf)'`},
+		{[]string{"ry", "--track", ""}, "ry --track ''"},
+	}
+	for _, c := range cases {
+		if got := shellCommand(c.args...); got != c.want {
+			t.Errorf("shellCommand(%q) = %s, want %s", c.args, got, c.want)
+		}
+	}
+}
+
+func TestVerifyLaunched_SkipsBackendsWithoutInspection(t *testing.T) {
+	if err := verifyLaunched(clock.Real, &mockTmux{}, []paneLaunch{{"s", []string{"ry"}}}); err != nil {
+		t.Errorf("verifyLaunched = %v, want nil", err)
+	}
+}
+
+func TestVerifyLaunched_ReportsDeadPanes(t *testing.T) {
+	fastLaunchChecks(t)
+	m := newInspectingTmux()
+	m.dead["railyard_me_eng001"] = true
+
+	err := verifyLaunched(clock.Real, m, []paneLaunch{
+		{"railyard_me_eng000", []string{"ry", "engine", "start"}},
+		{"railyard_me_eng001", []string{"ry", "engine", "start", "--track", "web app"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for the dead pane")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "eng000") || !strings.Contains(msg, `railyard_me_eng001: ry engine start --track 'web app' did not start (pane is running "bash")`) {
+		t.Errorf("err = %v", err)
+	}
+	if !strings.Contains(msg, "    Error: load config") {
+		t.Errorf("err does not quote the pane output: %v", err)
+	}
+	if m.checks["railyard_me_eng000"] != 1 || m.checks["railyard_me_eng001"] != len(launchCheckDelays) {
+		t.Errorf("checks = %v, want 1 for the live pane and every retry for the dead one", m.checks)
+	}
+}
+
+func TestVerifyLaunched_InspectError(t *testing.T) {
+	fastLaunchChecks(t)
+	m := newInspectingTmux()
+	m.inspectE = fmt.Errorf("no such session")
+	err := verifyLaunched(clock.Real, m, []paneLaunch{{"s", []string{"ry"}}})
+	if err == nil || !strings.Contains(err.Error(), "s: check ry launched: no such session") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestStart_QuotesConfigPathAndVerifiesLaunch(t *testing.T) {
+	fastLaunchChecks(t)
+	dir := filepath.Join(t.TempDir(), "my yard")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(dir, "railyard.yaml")
+	m := newInspectingTmux()
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 1})
+
+	if _, err := Start(StartOpts{Config: cfg, ConfigPath: cfgPath, DB: testDB(t), Tmux: m}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	want := "ry engine start --config '" + cfgPath + "' --track backend"
+	if len(m.sentKeys) != 2 || m.sentKeys[1] != want {
+		t.Errorf("sent keys = %q, want engine command %q", m.sentKeys, want)
+	}
+	if m.checks[YardmasterSession("test")] != 1 || m.checks[EngineSession("test", 0)] != 1 {
+		t.Errorf("checks = %v, want one per session", m.checks)
+	}
+}
+
+func TestStart_PassesConfigOverrides(t *testing.T) {
+	fastLaunchChecks(t)
+	m := newInspectingTmux()
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 1})
+	cfg.Overrides = []string{"tracks.backend.engine_slots=1", "stall.stdout_timeout_sec=300"}
+
+	if _, err := Start(StartOpts{Config: cfg, ConfigPath: "/tmp/test.yaml", DB: testDB(t), Tmux: m}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	sets := " --set tracks.backend.engine_slots=1 --set stall.stdout_timeout_sec=300"
+	want := []string{
+		"ry yardmaster --config /tmp/test.yaml" + sets,
+		"ry engine start --config /tmp/test.yaml" + sets + " --track backend",
+	}
+	if len(m.sentKeys) != 2 || m.sentKeys[0] != want[0] || m.sentKeys[1] != want[1] {
+		t.Errorf("sent keys = %q, want %q", m.sentKeys, want)
+	}
+}
+
+func TestStart_FailedLaunchCleansUp(t *testing.T) {
+	fastLaunchChecks(t)
+	m := newInspectingTmux()
+	m.dead[EngineSession("test", 0)] = true
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 1})
+
+	_, err := Start(StartOpts{Config: cfg, ConfigPath: "/tmp/test.yaml", DB: testDB(t), Tmux: m})
+	if err == nil || !strings.Contains(err.Error(), "did not start") {
+		t.Fatalf("err = %v, want launch failure", err)
+	}
+	if len(m.killedSessions) != 2 {
+		t.Errorf("killed sessions = %v, want both sessions cleaned up", m.killedSessions)
+	}
+}
@@ -0,0 +1,107 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// ErrLocked is matched (via errors.Is) by the error AcquireLock returns
+// while another ry operation holds the owner's lock.
+var ErrLocked = errors.New("another ry operation in progress")
+
+// LockHolder describes the process holding an orchestration lock.
+type LockHolder struct {
+	Op    string    `json:"op"`
+	PID   int       `json:"pid"`
+	Since time.Time `json:"since"`
+}
+
+// LockedError is returned by AcquireLock when the lock is already held.
+type LockedError struct {
+	Path   string
+	Holder LockHolder // zero if the holder's details could not be read
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder.PID == 0 {
+		return fmt.Sprintf("another ry operation in progress (lock %s held); try again when it finishes", e.Path)
+	}
+	return fmt.Sprintf("another ry operation in progress (ry %s, pid %d, since %s); try again when it finishes",
+		e.Holder.Op, e.Holder.PID, e.Holder.Since.Local().Format("15:04:05"))
+}
+
+// Is makes errors.Is(err, ErrLocked) match.
+func (e *LockedError) Is(target error) bool { return target == ErrLocked }
+
+// lockDir is where lock files live. Package var so tests can use a temp dir.
+var lockDir = os.TempDir()
+
+// LockPath returns the lock file for an owner. Sessions are named per owner
+// on a host, so the lock is too: two checkouts run by the same owner share
+// one set of sessions and must share one lock.
+func LockPath(owner string) string {
+	return filepath.Join(lockDir, fmt.Sprintf("railyard_%s.lock", owner))
+}
+
+// Lock is a held orchestration lock. Release it when the operation ends.
+type Lock struct {
+	f *os.File
+}
+
+// AcquireLock takes the owner's advisory orchestration lock for op (e.g.
+// "start", "engine scale"), so two ry commands cannot both pass the
+// "sessions already running?" checks and then launch or kill the same
+// sessions. It does not wait: if another process holds the lock it returns
+// a *LockedError naming that process.
+//
+// The lock is a flock(2) on the file, so it is released by the kernel if
+// the holder dies; a crashed ry never leaves the yard locked.
+func AcquireLock(owner, op string) (*Lock, error) {
+	path := LockPath(owner)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("orchestration: open lock %s: %w", path, err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, &LockedError{Path: path, Holder: readLockHolder(path)}
+		}
+		return nil, fmt.Errorf("orchestration: lock %s: %w", path, err)
+	}
+
+	// Record who holds it so a second ry can say what it is waiting on.
+	// Failing to write this only degrades that message.
+	data, _ := json.Marshal(LockHolder{Op: op, PID: os.Getpid(), Since: time.Now().UTC().Truncate(time.Second)})
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt(data, 0) //nolint:errcheck
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release drops the lock. It is safe to call on a nil Lock and more than once.
+func (l *Lock) Release() {
+	if l == nil || l.f == nil {
+		return
+	}
+	// Clear the holder before unlocking so a stale record is never read as
+	// a live one. The file itself stays: removing it would let a waiter
+	// lock an unlinked inode while a newcomer locks a fresh file.
+	l.f.Truncate(0)                               //nolint:errcheck
+	syscall.Flock(int(l.f.Fd()), syscall.LOCK_UN) //nolint:errcheck
+	l.f.Close()
+	l.f = nil
+}
+
+func readLockHolder(path string) LockHolder {
+	var h LockHolder
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &h) //nolint:errcheck
+	}
+	return h
+}
@@ -0,0 +1,48 @@
+package orchestration
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	saved := lockDir
+	lockDir = t.TempDir()
+	t.Cleanup(func() { lockDir = saved })
+
+	first, err := AcquireLock("alice", "start")
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+
+	// flock locks belong to the open file, so a second open in this process
+	// conflicts just as another ry process would.
+	_, err = AcquireLock("alice", "engine scale")
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("second AcquireLock error = %v, want ErrLocked", err)
+	}
+	var le *LockedError
+	if !errors.As(err, &le) || le.Holder.Op != "start" || le.Holder.PID == 0 {
+		t.Fatalf("holder = %+v, want op start with a pid", le)
+	}
+	if !strings.Contains(err.Error(), "another ry operation in progress (ry start, pid") {
+		t.Errorf("error = %q", err)
+	}
+
+	// Other owners have their own sessions and their own lock.
+	other, err := AcquireLock("bob", "start")
+	if err != nil {
+		t.Fatalf("AcquireLock for another owner: %v", err)
+	}
+	other.Release()
+
+	first.Release()
+	first.Release() // idempotent
+
+	again, err := AcquireLock("alice", "stop")
+	if err != nil {
+		t.Fatalf("AcquireLock after release: %v", err)
+	}
+	again.Release()
+}
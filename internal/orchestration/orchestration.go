@@ -1,24 +1,41 @@
 package orchestration
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/progress"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
+// ErrNotFound is wrapped by the errors returned for a track or engine that
+// does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrNoSession is wrapped by the errors returned when an operation needs the
+// railyard's tmux sessions and they are not running.
+var ErrNoSession = errors.New("no railyard session running")
+
 // StartOpts configures the ry start command.
 type StartOpts struct {
 	Config     *config.Config
 	ConfigPath string
 	DB         *gorm.DB
-	Engines    int  // 0 = sum of track engine_slots
-	Telegraph  bool // include telegraph session
-	Tmux       Tmux // defaults to DefaultTmux if nil
+	Engines    int         // 0 = sum of track engine_slots
+	Telegraph  bool        // include telegraph session
+	Tmux       Tmux        // defaults to TmuxFor(Config) if nil
+	Clock      clock.Clock // time source for launch checks; nil means clock.Real
+
+	// Progress, when non-nil, receives a "session" step as each tmux
+	// session is launched.
+	Progress progress.Func
 }
 
 // StartResult holds the result of starting the railyard.
@@ -53,7 +70,10 @@ func Start(opts StartOpts) (*StartResult, error) {
 		return nil, fmt.Errorf("orchestration: at least one track must be configured")
 	}
 	if opts.Tmux == nil {
-		opts.Tmux = DefaultTmux
+		opts.Tmux = TmuxFor(opts.Config)
+	}
+	if err := validateTmux(opts.Tmux); err != nil {
+		return nil, err
 	}
 
 	owner := opts.Config.Owner
@@ -95,6 +115,20 @@ func Start(opts StartOpts) (*StartResult, error) {
 	}
 
 	result := &StartResult{}
+	var launches []paneLaunch
+
+	// Report each launched session against the total to be launched.
+	totalSessions := 1 + totalEngines
+	for _, on := range []bool{opts.Telegraph, opts.Config.Bull.Enabled, opts.Config.Inspect.Enabled} {
+		if on {
+			totalSessions++
+		}
+	}
+	launched := 0
+	reportLaunched := func(session string) {
+		launched++
+		opts.Progress.Report(progress.Event{Step: "session", Detail: session, Current: launched, Total: totalSessions})
+	}
 
 	// Create yardmaster session.
 	if err := opts.Tmux.CreateSession(ymSession); err != nil {
@@ -102,12 +136,14 @@ func Start(opts StartOpts) (*StartResult, error) {
 	}
 	createdSessions = append(createdSessions, ymSession)
 
-	ymCmd := fmt.Sprintf("ry yardmaster --config %s", opts.ConfigPath)
-	if err := opts.Tmux.SendKeys(ymSession, ymCmd); err != nil {
+	ymArgs := daemonArgs(opts.Config, opts.ConfigPath, "yardmaster")
+	if err := opts.Tmux.SendKeys(ymSession, shellCommand(ymArgs...)); err != nil {
 		cleanup()
 		return nil, fmt.Errorf("orchestration: start yardmaster: %w", err)
 	}
 	result.YardmasterSession = ymSession
+	launches = append(launches, paneLaunch{ymSession, ymArgs})
+	reportLaunched(ymSession)
 
 	// Optional telegraph session.
 	if opts.Telegraph {
@@ -118,12 +154,14 @@ func Start(opts StartOpts) (*StartResult, error) {
 		}
 		createdSessions = append(createdSessions, tgSession)
 
-		tgCmd := fmt.Sprintf("ry telegraph start --config %s", opts.ConfigPath)
-		if err := opts.Tmux.SendKeys(tgSession, tgCmd); err != nil {
+		tgArgs := daemonArgs(opts.Config, opts.ConfigPath, "telegraph", "start")
+		if err := opts.Tmux.SendKeys(tgSession, shellCommand(tgArgs...)); err != nil {
 			cleanup()
 			return nil, fmt.Errorf("orchestration: start telegraph: %w", err)
 		}
 		result.TelegraphSession = tgSession
+		launches = append(launches, paneLaunch{tgSession, tgArgs})
+		reportLaunched(tgSession)
 	}
 
 	// Optional bull daemon session.
@@ -135,12 +173,14 @@ func Start(opts StartOpts) (*StartResult, error) {
 		}
 		createdSessions = append(createdSessions, bullSess)
 
-		bullCmd := fmt.Sprintf("ry bull --config %s", opts.ConfigPath)
-		if err := opts.Tmux.SendKeys(bullSess, bullCmd); err != nil {
+		bullArgs := daemonArgs(opts.Config, opts.ConfigPath, "bull")
+		if err := opts.Tmux.SendKeys(bullSess, shellCommand(bullArgs...)); err != nil {
 			cleanup()
 			return nil, fmt.Errorf("orchestration: start bull: %w", err)
 		}
 		result.BullSession = bullSess
+		launches = append(launches, paneLaunch{bullSess, bullArgs})
+		reportLaunched(bullSess)
 	}
 
 	// Optional inspect (PR review) daemon session.
@@ -152,12 +192,14 @@ func Start(opts StartOpts) (*StartResult, error) {
 		}
 		createdSessions = append(createdSessions, inspSess)
 
-		inspCmd := fmt.Sprintf("ry inspect --config %s", opts.ConfigPath)
-		if err := opts.Tmux.SendKeys(inspSess, inspCmd); err != nil {
+		inspArgs := daemonArgs(opts.Config, opts.ConfigPath, "inspect")
+		if err := opts.Tmux.SendKeys(inspSess, shellCommand(inspArgs...)); err != nil {
 			cleanup()
 			return nil, fmt.Errorf("orchestration: start inspect: %w", err)
 		}
 		result.InspectSession = inspSess
+		launches = append(launches, paneLaunch{inspSess, inspArgs})
+		reportLaunched(inspSess)
 	}
 
 	// Engine sessions — one per engine.
@@ -173,15 +215,24 @@ func Start(opts StartOpts) (*StartResult, error) {
 			}
 			createdSessions = append(createdSessions, engSession)
 
-			engineCmd := fmt.Sprintf("ry engine start --config %s --track %s", opts.ConfigPath, trackName)
-			if err := opts.Tmux.SendKeys(engSession, engineCmd); err != nil {
+			engineArgs := engineArgs(opts.Config, opts.ConfigPath, trackName, "")
+			if err := opts.Tmux.SendKeys(engSession, shellCommand(engineArgs...)); err != nil {
 				cleanup()
 				return nil, fmt.Errorf("orchestration: start engine on %s: %w", trackName, err)
 			}
 			result.EngineSessions = append(result.EngineSessions, EngineSessionInfo{Session: engSession, Track: trackName})
+			launches = append(launches, paneLaunch{engSession, engineArgs})
+			reportLaunched(engSession)
 		}
 	}
 
+	// Keys typed into a pane fail silently when the command does not run,
+	// so confirm every daemon is actually up before reporting success.
+	if err := verifyLaunched(clock.OrReal(opts.Clock), opts.Tmux, launches); err != nil {
+		cleanup()
+		return nil, err
+	}
+
 	return result, nil
 }
 
@@ -190,7 +241,8 @@ type StopOpts struct {
 	DB      *gorm.DB
 	Config  *config.Config // needed for owner-based session prefix
 	Timeout time.Duration  // max wait for graceful drain (default 60s)
-	Tmux    Tmux           // defaults to DefaultTmux if nil
+	Tmux    Tmux           // defaults to TmuxFor(Config) if nil
+	Clock   clock.Clock    // time source for the drain wait; nil means clock.Real
 }
 
 // Stop gracefully shuts down the railyard.
@@ -202,8 +254,9 @@ func Stop(opts StopOpts) error {
 		opts.Timeout = 60 * time.Second
 	}
 	if opts.Tmux == nil {
-		opts.Tmux = DefaultTmux
+		opts.Tmux = TmuxFor(opts.Config)
 	}
+	clk := clock.OrReal(opts.Clock)
 
 	// Discover all running railyard sessions.
 	var sessions []string
@@ -224,7 +277,7 @@ func Stop(opts StopOpts) error {
 	}
 
 	if len(sessions) == 0 {
-		return fmt.Errorf("orchestration: no railyard session running")
+		return fmt.Errorf("orchestration: %w", ErrNoSession)
 	}
 
 	// Step 1: Send drain broadcast.
@@ -234,14 +287,14 @@ func Stop(opts StopOpts) error {
 	}
 
 	// Step 2: Wait for working engines to finish (up to timeout).
-	deadline := time.Now().Add(opts.Timeout)
-	for time.Now().Before(deadline) {
+	deadline := clk.Now().Add(opts.Timeout)
+	for clk.Now().Before(deadline) {
 		var working int64
 		opts.DB.Model(&models.Engine{}).Where("status = ?", "working").Count(&working)
 		if working == 0 {
 			break
 		}
-		time.Sleep(2 * time.Second)
+		clk.Sleep(2 * time.Second)
 	}
 
 	// Step 3: Send C-c to all sessions.
@@ -249,7 +302,7 @@ func Stop(opts StopOpts) error {
 		_ = opts.Tmux.SendSignal(s, "C-c")
 	}
 	// Brief pause for processes to exit.
-	time.Sleep(2 * time.Second)
+	clk.Sleep(2 * time.Second)
 
 	// Step 4: Kill all sessions.
 	for _, s := range sessions {
@@ -270,7 +323,8 @@ func Stop(opts StopOpts) error {
 type StatusOpts struct {
 	DB     *gorm.DB
 	Config *config.Config // needed for owner-based session prefix
-	Tmux   Tmux
+	Tmux   Tmux           // defaults to TmuxFor(Config) if nil
+	Clock  clock.Clock    // time source for engine uptimes; nil means clock.Real
 }
 
 // StatusInfo holds dashboard information.
@@ -283,6 +337,9 @@ type StatusInfo struct {
 	TotalInputTokens  int64
 	TotalOutputTokens int64
 	TotalTokens       int64
+	Health            *YardHealth // derived health signals; nil when not assessed
+	Pause             yard.PauseState
+	MergeHold         yard.MergeHold
 }
 
 // EngineInfo holds per-engine dashboard data.
@@ -298,26 +355,34 @@ type EngineInfo struct {
 
 // TrackSummary holds per-track car counts.
 type TrackSummary struct {
-	Track        string
-	Open         int64
-	Ready        int64
-	InProgress   int64
-	Done         int64
-	Blocked      int64
-	MergeFailed  int64
-	BaseBranches []string // unique base branches for active cars on this track
+	Track         string
+	Open          int64
+	Ready         int64
+	InProgress    int64
+	Done          int64
+	Blocked       int64
+	MergeFailed   int64
+	BaseBranches  []string // unique base branches for active cars on this track
+	ClaimStrategy string   // configured claim_strategy; empty when config is unavailable
 }
 
 // Status gathers dashboard information.
-func Status(db *gorm.DB, tmux Tmux, cfg *config.Config) (*StatusInfo, error) {
+func Status(opts StatusOpts) (*StatusInfo, error) {
+	db, tmux, cfg := opts.DB, opts.Tmux, opts.Config
 	if db == nil {
 		return nil, fmt.Errorf("orchestration: database connection is required")
 	}
 	if tmux == nil {
-		tmux = DefaultTmux
+		tmux = TmuxFor(cfg)
 	}
 
 	info := &StatusInfo{}
+	if st, err := yard.GetPause(db); err == nil {
+		info.Pause = st
+	}
+	if h, err := yard.GetMergeHold(db); err == nil {
+		info.MergeHold = h
+	}
 
 	// Discover component sessions.
 	if cfg != nil {
@@ -336,7 +401,7 @@ func Status(db *gorm.DB, tmux Tmux, cfg *config.Config) (*StatusInfo, error) {
 	var engines []models.Engine
 	db.Where("status != ?", "dead").Order("track, id").Find(&engines)
 
-	now := time.Now()
+	now := clock.OrReal(opts.Clock).Now()
 	for _, e := range engines {
 		info.Engines = append(info.Engines, EngineInfo{
 			ID:           e.ID,
@@ -355,15 +420,23 @@ func Status(db *gorm.DB, tmux Tmux, cfg *config.Config) (*StatusInfo, error) {
 
 	for _, t := range tracks {
 		ts := TrackSummary{Track: t.Name}
+		if cfg != nil {
+			for _, tc := range cfg.Tracks {
+				if tc.Name == t.Name {
+					ts.ClaimStrategy = tc.ClaimStrategy
+				}
+			}
+		}
 		db.Model(&models.Car{}).Where("track = ? AND status = ?", t.Name, "open").Count(&ts.Open)
 		db.Model(&models.Car{}).Where("track = ? AND status = ?", t.Name, "in_progress").Count(&ts.InProgress)
 		db.Model(&models.Car{}).Where("track = ? AND status = ?", t.Name, "done").Count(&ts.Done)
 		db.Model(&models.Car{}).Where("track = ? AND status = ?", t.Name, "blocked").Count(&ts.Blocked)
 		db.Model(&models.Car{}).Where("track = ? AND status = ?", t.Name, "merge-failed").Count(&ts.MergeFailed)
-		// Ready = open with no unresolved blockers.
+		// Ready = the claimable queue: open, unassigned, not an epic, with
+		// no unresolved blockers (the same cars car.ReadyCars returns).
 		var ready int64
 		db.Model(&models.Car{}).
-			Where("track = ? AND status = ? AND (assignee = ? OR assignee IS NULL)", t.Name, "open", "").
+			Where("track = ? AND status = ? AND (assignee = ? OR assignee IS NULL) AND type != ?", t.Name, "open", "", "epic").
 			Where("id NOT IN (?)",
 				db.Model(&models.CarDep{}).
 					Select("car_id").
@@ -375,7 +448,7 @@ func Status(db *gorm.DB, tmux Tmux, cfg *config.Config) (*StatusInfo, error) {
 		// Collect unique base branches for active (non-done/merged/cancelled) cars.
 		var bases []string
 		db.Model(&models.Car{}).
-			Where("track = ? AND status NOT IN ?", t.Name, []string{"done", "merged", "cancelled"}).
+			Where("track = ? AND status NOT IN ?", t.Name, models.ClosedStatuses).
 			Distinct("base_branch").Pluck("base_branch", &bases)
 		seen := map[string]bool{}
 		for _, b := range bases {
@@ -410,11 +483,20 @@ func Status(db *gorm.DB, tmux Tmux, cfg *config.Config) (*StatusInfo, error) {
 	info.TotalOutputTokens = tokenRow.OutputTokens
 	info.TotalTokens = tokenRow.TotalTokens
 
+	health := assessHealth(db, info, now)
+	info.Health = &health
+
 	return info, nil
 }
 
 // FormatStatus renders StatusInfo as a human-readable dashboard string.
 func FormatStatus(info *StatusInfo) string {
+	return formatStatus(info, nil)
+}
+
+// formatStatus renders the dashboard, wrapping engine rows whose ID is in
+// highlight with the mapped ANSI color.
+func formatStatus(info *StatusInfo, highlight map[string]string) string {
 	var b strings.Builder
 
 	if info.SessionRunning {
@@ -422,6 +504,12 @@ func FormatStatus(info *StatusInfo) string {
 	} else {
 		b.WriteString("Railyard: STOPPED\n")
 	}
+	if info.Pause.Paused {
+		fmt.Fprintf(&b, "Yard %s (no new claims, merges, or car creation; ry resume to continue)\n", info.Pause)
+	}
+	if info.MergeHold.Held {
+		fmt.Fprintf(&b, "Infra failure: %s (!ry infra resolved to resume)\n", info.MergeHold)
+	}
 	b.WriteString("\n")
 
 	// Component sessions.
@@ -446,10 +534,14 @@ func FormatStatus(info *StatusInfo) string {
 		if provider == "" {
 			provider = "claude"
 		}
-		b.WriteString(fmt.Sprintf("%-14s %-12s %-10s %-10s %-14s %-20s %s\n",
+		row := fmt.Sprintf("%-14s %-12s %-10s %-10s %-14s %-20s %s",
 			e.ID, e.Track, e.Status, provider, car,
 			e.LastActivity.Format("15:04:05"),
-			formatDuration(e.Uptime)))
+			formatDuration(e.Uptime))
+		if color, ok := highlight[e.ID]; ok {
+			row = color + row + ansiReset
+		}
+		b.WriteString(row + "\n")
 	}
 	if len(info.Engines) == 0 {
 		b.WriteString("  (no active engines)\n")
@@ -460,22 +552,22 @@ func FormatStatus(info *StatusInfo) string {
 	b.WriteString("TRACKS\n")
 	multiBase := hasMultipleBases(info.TrackSummary)
 	if multiBase {
-		b.WriteString(fmt.Sprintf("%-12s %-12s %6s %6s %6s %6s %6s %8s\n",
-			"TRACK", "BASE", "OPEN", "READY", "ACTIVE", "DONE", "BLOCKED", "MRG-FAIL"))
+		b.WriteString(fmt.Sprintf("%-12s %-12s %6s %6s %6s %6s %6s %8s  %s\n",
+			"TRACK", "BASE", "OPEN", "READY", "ACTIVE", "DONE", "BLOCKED", "MRG-FAIL", "STRATEGY"))
 		for _, t := range info.TrackSummary {
 			base := strings.Join(t.BaseBranches, ",")
 			if base == "" {
 				base = "main"
 			}
-			b.WriteString(fmt.Sprintf("%-12s %-12s %6d %6d %6d %6d %6d %8d\n",
-				t.Track, base, t.Open, t.Ready, t.InProgress, t.Done, t.Blocked, t.MergeFailed))
+			b.WriteString(fmt.Sprintf("%-12s %-12s %6d %6d %6d %6d %6d %8d  %s\n",
+				t.Track, base, t.Open, t.Ready, t.InProgress, t.Done, t.Blocked, t.MergeFailed, claimStrategyLabel(t.ClaimStrategy)))
 		}
 	} else {
-		b.WriteString(fmt.Sprintf("%-12s %6s %6s %6s %6s %6s %8s\n",
-			"TRACK", "OPEN", "READY", "ACTIVE", "DONE", "BLOCKED", "MRG-FAIL"))
+		b.WriteString(fmt.Sprintf("%-12s %6s %6s %6s %6s %6s %8s  %s\n",
+			"TRACK", "OPEN", "READY", "ACTIVE", "DONE", "BLOCKED", "MRG-FAIL", "STRATEGY"))
 		for _, t := range info.TrackSummary {
-			b.WriteString(fmt.Sprintf("%-12s %6d %6d %6d %6d %6d %8d\n",
-				t.Track, t.Open, t.Ready, t.InProgress, t.Done, t.Blocked, t.MergeFailed))
+			b.WriteString(fmt.Sprintf("%-12s %6d %6d %6d %6d %6d %8d  %s\n",
+				t.Track, t.Open, t.Ready, t.InProgress, t.Done, t.Blocked, t.MergeFailed, claimStrategyLabel(t.ClaimStrategy)))
 		}
 	}
 	if len(info.TrackSummary) == 0 {
@@ -486,6 +578,12 @@ func FormatStatus(info *StatusInfo) string {
 	// Message depth.
 	b.WriteString(fmt.Sprintf("Message queue: %d unacknowledged\n", info.MessageDepth))
 
+	// Yard health.
+	if info.Health != nil {
+		b.WriteString("\n")
+		b.WriteString(formatHealth(*info.Health))
+	}
+
 	// Token usage.
 	if info.TotalTokens > 0 {
 		b.WriteString("\nTOKENS\n")
@@ -497,6 +595,14 @@ func FormatStatus(info *StatusInfo) string {
 	return b.String()
 }
 
+// claimStrategyLabel renders a track's claim strategy for status output.
+func claimStrategyLabel(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 // hasMultipleBases returns true when any track has more than one base branch,
 // or different tracks target different base branches.
 func hasMultipleBases(tracks []TrackSummary) bool {
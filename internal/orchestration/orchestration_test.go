@@ -1,13 +1,17 @@
 package orchestration
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/progress"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -473,6 +477,35 @@ func TestStart_Success(t *testing.T) {
 	}
 }
 
+func TestStart_ReportsProgress(t *testing.T) {
+	db := testDB(t)
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 2})
+	var events []progress.Event
+	_, err := Start(StartOpts{
+		Config:     cfg,
+		ConfigPath: "/tmp/test.yaml",
+		DB:         db,
+		Telegraph:  true,
+		Tmux:       &mockTmux{},
+		Progress:   func(e progress.Event) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 1 yardmaster + 1 telegraph + 2 engines.
+	if len(events) != 4 {
+		t.Fatalf("events = %+v, want 4", events)
+	}
+	if events[0].Detail != YardmasterSession("test") || events[1].Detail != TelegraphSession("test") {
+		t.Errorf("first events = %+v, want yardmaster then telegraph", events[:2])
+	}
+	for i, e := range events {
+		if e.Step != "session" || e.Current != i+1 || e.Total != 4 {
+			t.Errorf("event %d = %+v, want session %d/4", i, e, i+1)
+		}
+	}
+}
+
 func TestStart_WithTelegraph(t *testing.T) {
 	db := testDB(t)
 	m := &mockTmux{}
@@ -929,7 +962,7 @@ func TestStatus_ReportsInspectSession(t *testing.T) {
 			}, nil
 		},
 	}
-	info, err := Status(db, m, cfg)
+	info, err := Status(StatusOpts{DB: db, Tmux: m, Config: cfg})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -971,8 +1004,8 @@ func TestStop_NoSession(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for no session")
 	}
-	if !strings.Contains(err.Error(), "no railyard session running") {
-		t.Errorf("error = %q, want to contain 'no railyard session running'", err.Error())
+	if !errors.Is(err, ErrNoSession) {
+		t.Errorf("error = %v, want ErrNoSession", err)
 	}
 }
 
@@ -1074,7 +1107,7 @@ func TestStop_DefaultTimeout(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestStatus_NilDB(t *testing.T) {
-	_, err := Status(nil, nil, nil)
+	_, err := Status(StatusOpts{})
 	if err == nil {
 		t.Fatal("expected error for nil DB")
 	}
@@ -1083,7 +1116,7 @@ func TestStatus_NilDB(t *testing.T) {
 func TestStatus_EmptyDB(t *testing.T) {
 	db := testDB(t)
 	m := &mockTmux{sessionExists: false}
-	info, err := Status(db, m, nil)
+	info, err := Status(StatusOpts{DB: db, Tmux: m})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1128,6 +1161,9 @@ func TestStatus_WithEnginesAndTracks(t *testing.T) {
 	db.Create(&models.Car{ID: "b-1", Track: "backend", Status: "open"})
 	db.Create(&models.Car{ID: "b-2", Track: "backend", Status: "in_progress", Assignee: "eng-1"})
 	db.Create(&models.Car{ID: "b-3", Track: "backend", Status: "done"})
+	db.Create(&models.Car{ID: "b-4", Track: "backend", Status: "open", Type: "epic"})
+	db.Create(&models.Car{ID: "b-5", Track: "backend", Status: "open"})
+	db.Create(&models.CarDep{CarID: "b-5", BlockedBy: "b-2"})
 
 	// Create messages.
 	db.Create(&models.Message{FromAgent: "a", ToAgent: "eng-1", Acknowledged: false})
@@ -1140,7 +1176,7 @@ func TestStatus_WithEnginesAndTracks(t *testing.T) {
 			return []string{"railyard_test_yardmaster"}, nil
 		},
 	}
-	info, err := Status(db, m, cfg)
+	info, err := Status(StatusOpts{DB: db, Tmux: m, Config: cfg})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1153,6 +1189,12 @@ func TestStatus_WithEnginesAndTracks(t *testing.T) {
 	if len(info.TrackSummary) != 2 {
 		t.Errorf("track summary = %d, want 2 (backend + frontend)", len(info.TrackSummary))
 	}
+	// Only b-1 is claimable: b-4 is an epic and b-5 waits on b-2.
+	for _, ts := range info.TrackSummary {
+		if ts.Track == "backend" && (ts.Open != 3 || ts.Ready != 1) {
+			t.Errorf("backend open/ready = %d/%d, want 3/1", ts.Open, ts.Ready)
+		}
+	}
 	// 2 non-broadcast unacknowledged messages.
 	if info.MessageDepth != 2 {
 		t.Errorf("message depth = %d, want 2", info.MessageDepth)
@@ -1171,7 +1213,7 @@ func TestStatus_LegacyFallback(t *testing.T) {
 		},
 	}
 	// No config — falls back to legacy session name check.
-	info, err := Status(db, m, nil)
+	info, err := Status(StatusOpts{DB: db, Tmux: m})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1229,7 +1271,7 @@ func TestScale_TrackNotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for track not found")
 	}
-	if !strings.Contains(err.Error(), "not found in config") {
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "not found in config") {
 		t.Errorf("error = %q, want to contain 'not found in config'", err.Error())
 	}
 }
@@ -1264,8 +1306,8 @@ func TestScale_NoSession(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for no session")
 	}
-	if !strings.Contains(err.Error(), "no railyard session running") {
-		t.Errorf("error = %q, want to contain 'no railyard session running'", err.Error())
+	if !errors.Is(err, ErrNoSession) {
+		t.Errorf("error = %v, want ErrNoSession", err)
 	}
 }
 
@@ -1342,6 +1384,37 @@ func TestScale_ScaleUp(t *testing.T) {
 	}
 }
 
+func TestScale_ReportsProgress(t *testing.T) {
+	db := testDB(t)
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 5})
+	m := &mockTmux{
+		sessionExistsFunc: func(name string) bool {
+			return name == YardmasterSession("test")
+		},
+	}
+	var events []progress.Event
+	_, err := Scale(ScaleOpts{
+		DB:         db,
+		Config:     cfg,
+		ConfigPath: "/tmp/test.yaml",
+		Track:      "backend",
+		Count:      2,
+		Tmux:       m,
+		Progress:   func(e progress.Event) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2", events)
+	}
+	for i, e := range events {
+		if e.Step != "engine" || e.Detail != m.createdSessions[i] || e.Current != i+1 || e.Total != 2 {
+			t.Errorf("event %d = %+v", i, e)
+		}
+	}
+}
+
 func TestScale_ScaleDown(t *testing.T) {
 	db := testDB(t)
 	now := time.Now()
@@ -1557,6 +1630,23 @@ func TestListEngines_FilterByStatus(t *testing.T) {
 	}
 }
 
+func TestListEngines_UptimeUsesClock(t *testing.T) {
+	db := testDB(t)
+	started := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	db.Create(&models.Engine{ID: "eng-1", Track: "backend", Status: "idle", StartedAt: started})
+
+	engines, err := ListEngines(EngineListOpts{DB: db, Clock: clock.NewFake(started.Add(90 * time.Minute))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(engines) != 1 {
+		t.Fatalf("engines = %d, want 1", len(engines))
+	}
+	if engines[0].Uptime != 90*time.Minute {
+		t.Errorf("uptime = %v, want 1h30m", engines[0].Uptime)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // RestartEngine tests
 // ---------------------------------------------------------------------------
@@ -1594,8 +1684,8 @@ func TestRestartEngine_NoSession(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for no session")
 	}
-	if !strings.Contains(err.Error(), "no railyard session running") {
-		t.Errorf("error = %q, want to contain 'no railyard session running'", err.Error())
+	if !errors.Is(err, ErrNoSession) {
+		t.Errorf("error = %v, want ErrNoSession", err)
 	}
 }
 
@@ -1759,6 +1849,17 @@ func TestFormatStatus_Stopped(t *testing.T) {
 	}
 }
 
+func TestFormatStatus_Paused(t *testing.T) {
+	info := &StatusInfo{Pause: yard.PauseState{Paused: true, By: "alice", Reason: "bad deploy"}}
+	out := FormatStatus(info)
+	if !strings.Contains(out, "Yard paused by alice: bad deploy (no new claims") {
+		t.Errorf("expected pause line, got: %s", out)
+	}
+	if strings.Contains(FormatStatus(&StatusInfo{}), "paused") {
+		t.Error("running yard should not mention a pause")
+	}
+}
+
 func TestFormatStatus_EmptyCar(t *testing.T) {
 	info := &StatusInfo{
 		SessionRunning: true,
@@ -1818,6 +1919,23 @@ func TestFormatStatus_SingleBaseBranch(t *testing.T) {
 	}
 }
 
+func TestFormatStatus_ClaimStrategy(t *testing.T) {
+	info := &StatusInfo{
+		SessionRunning: true,
+		TrackSummary: []TrackSummary{
+			{Track: "backend", Open: 3, ClaimStrategy: "priority_aging"},
+			{Track: "frontend", Open: 2},
+		},
+	}
+	out := FormatStatus(info)
+	if !strings.Contains(out, "STRATEGY") {
+		t.Errorf("expected STRATEGY column header, got: %s", out)
+	}
+	if !strings.Contains(out, "priority_aging") {
+		t.Errorf("expected 'priority_aging' for backend track, got: %s", out)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // hasMultipleBases tests
 // ---------------------------------------------------------------------------
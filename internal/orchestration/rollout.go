@@ -0,0 +1,266 @@
+package orchestration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/progress"
+	"gorm.io/gorm"
+)
+
+// Rollout defaults.
+const (
+	DefaultRolloutIdleTimeout  = 30 * time.Minute
+	DefaultRolloutStartTimeout = 2 * time.Minute
+)
+
+// rolloutPollInterval is how often Rollout re-reads engine rows while
+// waiting for an engine to go idle or a replacement to register. Package var
+// so tests can shorten it.
+var rolloutPollInterval = 2 * time.Second
+
+// RolloutOpts configures the ry engine rollout command.
+type RolloutOpts struct {
+	DB         *gorm.DB
+	Config     *config.Config
+	ConfigPath string
+	Track      string
+	Tmux       Tmux // defaults to TmuxFor(Config) if nil
+
+	// AgentBinary is the agent CLI replacements run; "" runs the provider
+	// default from PATH, which picks up a CLI upgraded in place.
+	AgentBinary string
+	// MaxFailures is how many replacements may fail before the rollout
+	// stops and rolls the upgraded engines back.
+	MaxFailures int
+	// IdleTimeout bounds the wait for each engine to finish its current
+	// car; engines still busy after it are skipped. Zero means
+	// DefaultRolloutIdleTimeout.
+	IdleTimeout time.Duration
+	// StartTimeout bounds the wait for a replacement to register. Zero
+	// means DefaultRolloutStartTimeout.
+	StartTimeout time.Duration
+	// Clock is the time source for those waits; nil means clock.Real.
+	Clock clock.Clock
+
+	// Progress, when non-nil, receives an "engine" step as each engine is
+	// replaced.
+	Progress progress.Func
+}
+
+// RolloutStep records one engine replacement.
+type RolloutStep struct {
+	OldEngine string
+	NewEngine string // empty when the replacement failed
+	Session   string
+	Error     string
+}
+
+// RolloutResult holds the outcome of a rollout.
+type RolloutResult struct {
+	Track    string
+	Upgraded []RolloutStep
+	Failed   []RolloutStep
+	// Skipped lists engines that were still working when IdleTimeout ran
+	// out, or that stopped on their own during the rollout.
+	Skipped []string
+	// RolledBack lists the replacements made to undo the rollout, each
+	// running the agent binary of the engine it restores.
+	RolledBack []RolloutStep
+}
+
+// Rollout restarts a track's engines one at a time so they pick up a new
+// agent CLI. Each engine is left to finish its current car, drained, and
+// replaced by an engine running opts.AgentBinary; the next engine is not
+// touched until the replacement has registered. A failed replacement is
+// relaunched on the engine's previous binary. Once more than
+// opts.MaxFailures replacements fail, the rollout stops and every engine it
+// upgraded is rolled back the same way.
+func Rollout(opts RolloutOpts) (*RolloutResult, error) {
+	if opts.DB == nil {
+		return nil, fmt.Errorf("orchestration: database connection is required")
+	}
+	if opts.Config == nil {
+		return nil, fmt.Errorf("orchestration: config is required")
+	}
+	if opts.Track == "" {
+		return nil, fmt.Errorf("orchestration: track is required")
+	}
+	if opts.MaxFailures < 0 {
+		return nil, fmt.Errorf("orchestration: max failures must be non-negative")
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultRolloutIdleTimeout
+	}
+	if opts.StartTimeout <= 0 {
+		opts.StartTimeout = DefaultRolloutStartTimeout
+	}
+	if opts.Tmux == nil {
+		opts.Tmux = TmuxFor(opts.Config)
+	}
+	opts.Clock = clock.OrReal(opts.Clock)
+
+	found := false
+	for _, t := range opts.Config.Tracks {
+		if t.Name == opts.Track {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("orchestration: track %q %w in config", opts.Track, ErrNotFound)
+	}
+	if !opts.Tmux.SessionExists(YardmasterSession(opts.Config.Owner)) {
+		return nil, fmt.Errorf("orchestration: %w", ErrNoSession)
+	}
+
+	// Oldest first: the longest-running engines are the likeliest to be on
+	// the previous agent.
+	var engines []models.Engine
+	if err := opts.DB.Where("track = ? AND status != ?", opts.Track, "dead").
+		Order("started_at").Find(&engines).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: list engines for track %q: %w", opts.Track, err)
+	}
+
+	result := &RolloutResult{Track: opts.Track}
+	for i, eng := range engines {
+		opts.Progress.Report(progress.Event{Step: "engine", Detail: eng.ID, Current: i + 1, Total: len(engines)})
+
+		if !waitEngineIdle(opts.DB, opts.Clock, eng.ID, opts.IdleTimeout) {
+			result.Skipped = append(result.Skipped, eng.ID)
+			continue
+		}
+		step := replaceEngine(opts, eng, opts.AgentBinary)
+		if step.Error == "" {
+			result.Upgraded = append(result.Upgraded, step)
+			continue
+		}
+		result.Failed = append(result.Failed, step)
+
+		// Keep the track's capacity: the engine is already drained.
+		if eng.AgentBinary != opts.AgentBinary {
+			result.RolledBack = append(result.RolledBack, relaunchEngine(opts, eng))
+		}
+
+		if len(result.Failed) > opts.MaxFailures {
+			for j := len(result.Upgraded) - 1; j >= 0; j-- {
+				up := result.Upgraded[j]
+				prev := engineByID(engines, up.OldEngine)
+				if !waitEngineIdle(opts.DB, opts.Clock, up.NewEngine, opts.IdleTimeout) {
+					result.Skipped = append(result.Skipped, up.NewEngine)
+					continue
+				}
+				newEng := models.Engine{ID: up.NewEngine, Track: opts.Track}
+				back := replaceEngine(opts, newEng, prev.AgentBinary)
+				back.OldEngine = up.NewEngine
+				result.RolledBack = append(result.RolledBack, back)
+			}
+			return result, fmt.Errorf("orchestration: rollout on %s stopped after %d failed replacement(s): %s",
+				opts.Track, len(result.Failed), step.Error)
+		}
+	}
+	return result, nil
+}
+
+// replaceEngine drains eng and starts a replacement on agentBinary, waiting
+// for the replacement to register.
+func replaceEngine(opts RolloutOpts, eng models.Engine, agentBinary string) RolloutStep {
+	step := RolloutStep{OldEngine: eng.ID}
+	if err := drainEngine(opts.DB, eng.ID, "Engine replaced by rollout. Complete current work and exit gracefully."); err != nil {
+		step.Error = err.Error()
+		return step
+	}
+	return startReplacement(opts, step, agentBinary)
+}
+
+// relaunchEngine starts a fresh engine on eng's own agent binary, after its
+// rollout replacement failed.
+func relaunchEngine(opts RolloutOpts, eng models.Engine) RolloutStep {
+	return startReplacement(opts, RolloutStep{OldEngine: eng.ID}, eng.AgentBinary)
+}
+
+func startReplacement(opts RolloutOpts, step RolloutStep, agentBinary string) RolloutStep {
+	known, err := trackEngineIDs(opts.DB, opts.Track)
+	if err != nil {
+		step.Error = err.Error()
+		return step
+	}
+	session, err := launchEngine(opts.Clock, opts.Tmux, opts.Config, opts.ConfigPath, opts.Track, agentBinary)
+	step.Session = session
+	if err == nil {
+		step.NewEngine, err = waitEngineRegistered(opts.DB, opts.Clock, opts.Track, known, opts.StartTimeout)
+	}
+	if err != nil {
+		step.Error = err.Error()
+		if session != "" {
+			_ = opts.Tmux.KillSession(session)
+		}
+	}
+	return step
+}
+
+// waitEngineIdle waits for an engine to have no car in hand. It reports
+// false when the timeout runs out first or the engine is gone.
+func waitEngineIdle(db *gorm.DB, clk clock.Clock, engineID string, timeout time.Duration) bool {
+	deadline := clk.Now().Add(timeout)
+	for {
+		var eng models.Engine
+		if err := db.Where("id = ?", engineID).First(&eng).Error; err == nil {
+			if eng.Status == "dead" {
+				return false
+			}
+			if eng.Status == "idle" && eng.CurrentCar == "" {
+				return true
+			}
+		}
+		if !clk.Now().Before(deadline) {
+			return false
+		}
+		clk.Sleep(rolloutPollInterval)
+	}
+}
+
+// waitEngineRegistered waits for an engine not in known to register on
+// track and returns its ID.
+func waitEngineRegistered(db *gorm.DB, clk clock.Clock, track string, known map[string]bool, timeout time.Duration) (string, error) {
+	deadline := clk.Now().Add(timeout)
+	for {
+		var engines []models.Engine
+		if err := db.Where("track = ? AND status != ?", track, "dead").Find(&engines).Error; err == nil {
+			for _, e := range engines {
+				if !known[e.ID] {
+					return e.ID, nil
+				}
+			}
+		}
+		if !clk.Now().Before(deadline) {
+			return "", fmt.Errorf("orchestration: replacement engine on %s did not register within %s", track, timeout)
+		}
+		clk.Sleep(rolloutPollInterval)
+	}
+}
+
+// trackEngineIDs returns the IDs of every engine row on track, dead or not.
+func trackEngineIDs(db *gorm.DB, track string) (map[string]bool, error) {
+	var ids []string
+	if err := db.Model(&models.Engine{}).Where("track = ?", track).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: list engines for track %q: %w", track, err)
+	}
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	return known, nil
+}
+
+func engineByID(engines []models.Engine, id string) models.Engine {
+	for _, e := range engines {
+		if e.ID == id {
+			return e
+		}
+	}
+	return models.Engine{ID: id}
+}
@@ -0,0 +1,180 @@
+package orchestration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// rolloutTmux registers an engine row for every `ry engine start` typed
+// into a session, unless the command uses a binary listed in broken.
+type rolloutTmux struct {
+	*mockTmux
+	db     *gorm.DB
+	broken map[string]bool
+	n      int
+}
+
+func newRolloutTmux(t *testing.T, db *gorm.DB) *rolloutTmux {
+	t.Helper()
+	saved := rolloutPollInterval
+	rolloutPollInterval = time.Millisecond
+	t.Cleanup(func() { rolloutPollInterval = saved })
+
+	rt := &rolloutTmux{mockTmux: &mockTmux{sessionExists: true}, db: db, broken: map[string]bool{}}
+	rt.sendKeysFunc = func(session, keys string) error {
+		rt.sentKeys = append(rt.sentKeys, keys)
+		binary := ""
+		if i := strings.Index(keys, "--agent-binary "); i >= 0 {
+			binary = keys[i+len("--agent-binary "):]
+		}
+		if rt.broken[binary] {
+			return nil
+		}
+		rt.n++
+		return db.Create(&models.Engine{
+			ID: fmt.Sprintf("eng-new%d", rt.n), Track: "backend", Status: "idle",
+			AgentBinary: binary, StartedAt: time.Now(),
+		}).Error
+	}
+	return rt
+}
+
+func seedRolloutEngines(t *testing.T, db *gorm.DB, ids ...string) {
+	t.Helper()
+	base := time.Now().Add(-time.Hour)
+	for i, id := range ids {
+		if err := db.Create(&models.Engine{ID: id, Track: "backend", Status: "idle", StartedAt: base.Add(time.Duration(i) * time.Minute)}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func engineStatus(t *testing.T, db *gorm.DB, id string) string {
+	t.Helper()
+	var eng models.Engine
+	if err := db.Where("id = ?", id).First(&eng).Error; err != nil {
+		t.Fatalf("load %s: %v", id, err)
+	}
+	return eng.Status
+}
+
+func rolloutOpts(db *gorm.DB, tmux Tmux) RolloutOpts {
+	return RolloutOpts{
+		DB:           db,
+		Config:       testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 3}),
+		ConfigPath:   "/tmp/test.yaml",
+		Track:        "backend",
+		Tmux:         tmux,
+		AgentBinary:  "/opt/claude-2/bin/claude",
+		IdleTimeout:  50 * time.Millisecond,
+		StartTimeout: 50 * time.Millisecond,
+	}
+}
+
+func TestRollout_ReplacesEnginesOneAtATime(t *testing.T) {
+	db := testDB(t)
+	seedRolloutEngines(t, db, "eng-a", "eng-b")
+	rt := newRolloutTmux(t, db)
+
+	result, err := Rollout(rolloutOpts(db, rt))
+	if err != nil {
+		t.Fatalf("Rollout: %v", err)
+	}
+	if len(result.Upgraded) != 2 || result.Upgraded[0].OldEngine != "eng-a" || result.Upgraded[0].NewEngine != "eng-new1" {
+		t.Fatalf("upgraded = %+v", result.Upgraded)
+	}
+	for _, id := range []string{"eng-a", "eng-b"} {
+		if got := engineStatus(t, db, id); got != "dead" {
+			t.Errorf("%s status = %q, want dead", id, got)
+		}
+	}
+	want := "ry engine start --config /tmp/test.yaml --track backend --agent-binary /opt/claude-2/bin/claude"
+	if len(rt.sentKeys) != 2 || rt.sentKeys[0] != want {
+		t.Errorf("sent keys = %q, want %q", rt.sentKeys, want)
+	}
+}
+
+func TestRollout_SkipsBusyEngines(t *testing.T) {
+	db := testDB(t)
+	seedRolloutEngines(t, db, "eng-a", "eng-b")
+	db.Model(&models.Engine{}).Where("id = ?", "eng-a").Updates(map[string]interface{}{"status": "working", "current_car": "car-1"})
+	rt := newRolloutTmux(t, db)
+
+	result, err := Rollout(rolloutOpts(db, rt))
+	if err != nil {
+		t.Fatalf("Rollout: %v", err)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "eng-a" || len(result.Upgraded) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if got := engineStatus(t, db, "eng-a"); got != "working" {
+		t.Errorf("busy engine status = %q, want untouched", got)
+	}
+}
+
+func TestRollout_RollsBackPastMaxFailures(t *testing.T) {
+	db := testDB(t)
+	seedRolloutEngines(t, db, "eng-a", "eng-b", "eng-c")
+	rt := newRolloutTmux(t, db)
+	opts := rolloutOpts(db, rt)
+
+	// The first replacement succeeds; then the new binary breaks.
+	rt.sendKeysFunc = func(orig func(string, string) error) func(string, string) error {
+		return func(session, keys string) error {
+			if rt.n == 1 {
+				rt.broken[opts.AgentBinary] = true
+			}
+			return orig(session, keys)
+		}
+	}(rt.sendKeysFunc)
+
+	result, err := Rollout(opts)
+	if err == nil || !strings.Contains(err.Error(), "stopped after 1 failed replacement(s)") {
+		t.Fatalf("err = %v, want rollout stopped", err)
+	}
+	if len(result.Upgraded) != 1 || len(result.Failed) != 1 || result.Failed[0].OldEngine != "eng-b" {
+		t.Fatalf("result = %+v", result)
+	}
+	// eng-b is relaunched on its previous binary, and eng-a's upgrade is
+	// rolled back; eng-c is never touched.
+	if len(result.RolledBack) != 2 || result.RolledBack[1].OldEngine != "eng-new1" || result.RolledBack[1].Error != "" {
+		t.Fatalf("rolled back = %+v", result.RolledBack)
+	}
+	if got := engineStatus(t, db, "eng-c"); got != "idle" {
+		t.Errorf("eng-c status = %q, want untouched", got)
+	}
+	if got := engineStatus(t, db, "eng-new1"); got != "dead" {
+		t.Errorf("upgraded engine status = %q, want dead after rollback", got)
+	}
+	var live []models.Engine
+	db.Where("track = ? AND status != ? AND agent_binary != ?", "backend", "dead", "").Find(&live)
+	if len(live) != 0 {
+		t.Errorf("engines still on the new binary: %+v", live)
+	}
+	if len(rt.killedSessions) != 1 {
+		t.Errorf("killed sessions = %v, want the failed replacement", rt.killedSessions)
+	}
+}
+
+func TestRollout_Validation(t *testing.T) {
+	db := testDB(t)
+	opts := rolloutOpts(db, &mockTmux{sessionExists: true})
+	opts.Track = "nope"
+	if _, err := Rollout(opts); err == nil || !strings.Contains(err.Error(), `track "nope" not found`) {
+		t.Errorf("unknown track err = %v", err)
+	}
+	opts = rolloutOpts(db, &mockTmux{})
+	if _, err := Rollout(opts); err == nil || !strings.Contains(err.Error(), "no railyard session running") {
+		t.Errorf("no session err = %v", err)
+	}
+	opts.MaxFailures = -1
+	if _, err := Rollout(opts); err == nil || !strings.Contains(err.Error(), "max failures") {
+		t.Errorf("negative max failures err = %v", err)
+	}
+}
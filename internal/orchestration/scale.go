@@ -1,13 +1,15 @@
 package orchestration
 
 import (
+	"errors"
 	"fmt"
 	"sort"
-	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/progress"
 	"gorm.io/gorm"
 )
 
@@ -118,7 +120,12 @@ type ScaleOpts struct {
 	ConfigPath string
 	Track      string
 	Count      int
-	Tmux       Tmux // defaults to DefaultTmux if nil
+	Tmux       Tmux        // defaults to TmuxFor(Config) if nil
+	Clock      clock.Clock // time source for launch checks; nil means clock.Real
+
+	// Progress, when non-nil, receives an "engine" step as each engine
+	// session is launched (scale up) or each engine is drained (scale down).
+	Progress progress.Func
 }
 
 // ScaleResult holds the outcome of a scale operation.
@@ -147,7 +154,7 @@ func Scale(opts ScaleOpts) (*ScaleResult, error) {
 		return nil, fmt.Errorf("orchestration: count must be non-negative")
 	}
 	if opts.Tmux == nil {
-		opts.Tmux = DefaultTmux
+		opts.Tmux = TmuxFor(opts.Config)
 	}
 
 	owner := opts.Config.Owner
@@ -163,7 +170,7 @@ func Scale(opts ScaleOpts) (*ScaleResult, error) {
 		}
 	}
 	if !found {
-		return nil, fmt.Errorf("orchestration: track %q not found in config", opts.Track)
+		return nil, fmt.Errorf("orchestration: track %q %w in config", opts.Track, ErrNotFound)
 	}
 	if opts.Count > maxSlots {
 		return nil, fmt.Errorf("orchestration: count %d exceeds max engine_slots %d for track %q", opts.Count, maxSlots, opts.Track)
@@ -172,7 +179,7 @@ func Scale(opts ScaleOpts) (*ScaleResult, error) {
 	// Check that at least the yardmaster session is running.
 	ymSession := YardmasterSession(owner)
 	if !opts.Tmux.SessionExists(ymSession) {
-		return nil, fmt.Errorf("orchestration: no railyard session running")
+		return nil, fmt.Errorf("orchestration: %w", ErrNoSession)
 	}
 
 	// Count current live engines for this track.
@@ -196,6 +203,7 @@ func Scale(opts ScaleOpts) (*ScaleResult, error) {
 	if delta > 0 {
 		// Scale up: find next available engine index and create new sessions.
 		nextIdx := nextEngineIndex(opts.Tmux, owner)
+		var launches []paneLaunch
 		for i := 0; i < delta; i++ {
 			engSession := EngineSession(owner, nextIdx)
 			nextIdx++
@@ -203,11 +211,16 @@ func Scale(opts ScaleOpts) (*ScaleResult, error) {
 			if err := opts.Tmux.CreateSession(engSession); err != nil {
 				return result, fmt.Errorf("orchestration: create engine session: %w", err)
 			}
-			engineCmd := fmt.Sprintf("ry engine start --config %s --track %s", opts.ConfigPath, opts.Track)
-			if err := opts.Tmux.SendKeys(engSession, engineCmd); err != nil {
+			engineArgs := engineArgs(opts.Config, opts.ConfigPath, opts.Track, "")
+			if err := opts.Tmux.SendKeys(engSession, shellCommand(engineArgs...)); err != nil {
 				return result, fmt.Errorf("orchestration: start engine on %s: %w", opts.Track, err)
 			}
 			result.SessionsCreated = append(result.SessionsCreated, engSession)
+			launches = append(launches, paneLaunch{engSession, engineArgs})
+			opts.Progress.Report(progress.Event{Step: "engine", Detail: engSession, Current: i + 1, Total: delta})
+		}
+		if err := verifyLaunched(clock.OrReal(opts.Clock), opts.Tmux, launches); err != nil {
+			return result, err
 		}
 	} else {
 		// Scale down: drain newest engines first (LIFO by StartedAt).
@@ -232,6 +245,7 @@ func Scale(opts ScaleOpts) (*ScaleResult, error) {
 				return result, fmt.Errorf("orchestration: mark engine %s dead: %w", eng.ID, err)
 			}
 			result.EnginesDrained = append(result.EnginesDrained, eng.ID)
+			opts.Progress.Report(progress.Event{Step: "engine", Detail: eng.ID, Current: i + 1, Total: toRemove})
 		}
 	}
 
@@ -265,6 +279,7 @@ type EngineListOpts struct {
 	DB     *gorm.DB
 	Track  string
 	Status string
+	Clock  clock.Clock // time source for uptimes; nil means clock.Real
 }
 
 // ListEngines returns filtered engine information.
@@ -288,7 +303,7 @@ func ListEngines(opts EngineListOpts) ([]EngineInfo, error) {
 		return nil, fmt.Errorf("orchestration: list engines: %w", err)
 	}
 
-	now := time.Now()
+	now := clock.OrReal(opts.Clock).Now()
 	var infos []EngineInfo
 	for _, e := range engines {
 		infos = append(infos, EngineInfo{
@@ -320,42 +335,59 @@ func RestartEngine(db *gorm.DB, cfg *config.Config, configPath, engineID string,
 		return fmt.Errorf("orchestration: config is required")
 	}
 	if tmux == nil {
-		tmux = DefaultTmux
+		tmux = TmuxFor(cfg)
 	}
 
 	owner := cfg.Owner
 	ymSession := YardmasterSession(owner)
 	if !tmux.SessionExists(ymSession) {
-		return fmt.Errorf("orchestration: no railyard session running")
+		return fmt.Errorf("orchestration: %w", ErrNoSession)
 	}
 
 	// Get engine info.
 	var eng models.Engine
 	if err := db.Where("id = ?", engineID).First(&eng).Error; err != nil {
-		return fmt.Errorf("orchestration: engine %q not found", engineID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("orchestration: engine %q %w", engineID, ErrNotFound)
+		}
+		return fmt.Errorf("orchestration: get engine %q: %w", engineID, err)
 	}
 
-	// Drain the old engine: targeted drain instruction, then mark dead.
-	if _, err := messaging.Send(db, "orchestrator", engineID, "drain",
-		"Engine restarting. Complete current work and exit gracefully.",
-		messaging.SendOpts{}); err != nil {
+	if err := drainEngine(db, engineID, restartDrainReason); err != nil {
+		return err
+	}
+
+	// Create new session with same track and agent binary.
+	_, err := launchEngine(clock.Real, tmux, cfg, configPath, eng.Track, eng.AgentBinary)
+	return err
+}
+
+// drainEngine sends the engine a targeted drain instruction, then marks it
+// dead.
+func drainEngine(db *gorm.DB, engineID, reason string) error {
+	if _, err := messaging.Send(db, "orchestrator", engineID, "drain", reason, messaging.SendOpts{}); err != nil {
 		return fmt.Errorf("orchestration: send drain to engine %s: %w", engineID, err)
 	}
 	if err := db.Model(&models.Engine{}).Where("id = ?", engineID).
 		Update("status", "dead").Error; err != nil {
 		return fmt.Errorf("orchestration: mark engine %s dead: %w", engineID, err)
 	}
+	return nil
+}
 
-	// Create new session with same track.
-	nextIdx := nextEngineIndex(tmux, owner)
-	engSession := EngineSession(owner, nextIdx)
+// launchEngine starts an engine daemon on track in a new session and
+// returns the session name.
+func launchEngine(clk clock.Clock, tmux Tmux, cfg *config.Config, configPath, track, agentBinary string) (string, error) {
+	engSession := EngineSession(cfg.Owner, nextEngineIndex(tmux, cfg.Owner))
 	if err := tmux.CreateSession(engSession); err != nil {
-		return fmt.Errorf("orchestration: create replacement session: %w", err)
+		return "", fmt.Errorf("orchestration: create replacement session: %w", err)
 	}
-	engineCmd := fmt.Sprintf("ry engine start --config %s --track %s", configPath, eng.Track)
-	if err := tmux.SendKeys(engSession, engineCmd); err != nil {
-		return fmt.Errorf("orchestration: start replacement engine on %s: %w", eng.Track, err)
+	args := engineArgs(cfg, configPath, track, agentBinary)
+	if err := tmux.SendKeys(engSession, shellCommand(args...)); err != nil {
+		return engSession, fmt.Errorf("orchestration: start replacement engine on %s: %w", track, err)
 	}
-
-	return nil
+	if err := verifyLaunched(clk, tmux, []paneLaunch{{engSession, args}}); err != nil {
+		return engSession, err
+	}
+	return engSession, nil
 }
@@ -0,0 +1,193 @@
+package orchestration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// ANSI colors used to highlight changes in status watch mode.
+const (
+	ansiReset  = "\033[0m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiRed    = "\033[31m"
+)
+
+// ChangeKind classifies a difference between two status snapshots.
+type ChangeKind string
+
+const (
+	ChangeCarNew       ChangeKind = "car-new"
+	ChangeCarStatus    ChangeKind = "car-status"
+	ChangeEngineNew    ChangeKind = "engine-new"
+	ChangeEngineGone   ChangeKind = "engine-gone"
+	ChangeEngineStatus ChangeKind = "engine-status"
+	ChangeEngineCar    ChangeKind = "engine-car"
+)
+
+// CarState is the per-car state tracked between watch refreshes.
+type CarState struct {
+	ID     string
+	Title  string
+	Track  string
+	Status string
+}
+
+// StatusSnapshot captures what status watch mode compares between refreshes:
+// live engines and every car not in a terminal status (plus cars
+// seen in the previous snapshot, so a flip to a terminal status is caught).
+type StatusSnapshot struct {
+	At      time.Time
+	Engines map[string]EngineInfo
+	Cars    map[string]CarState
+}
+
+// StatusChange is one difference between two snapshots.
+type StatusChange struct {
+	Kind   ChangeKind
+	ID     string // car or engine ID
+	Detail string // e.g. "open → claimed" or the car title
+}
+
+// Snapshot builds a StatusSnapshot from info and the cars table, stamped with
+// clk's current time. prev may be nil on the first refresh.
+func Snapshot(db *gorm.DB, clk clock.Clock, info *StatusInfo, prev *StatusSnapshot) (*StatusSnapshot, error) {
+	if db == nil {
+		return nil, fmt.Errorf("orchestration: database connection is required")
+	}
+	snap := &StatusSnapshot{
+		At:      clk.Now(),
+		Engines: make(map[string]EngineInfo),
+		Cars:    make(map[string]CarState),
+	}
+	if info != nil {
+		for _, e := range info.Engines {
+			snap.Engines[e.ID] = e
+		}
+	}
+
+	q := db.Model(&models.Car{}).Select("id", "title", "track", "status").
+		Where("status NOT IN ?", models.TerminalStatuses)
+	if prev != nil && len(prev.Cars) > 0 {
+		ids := make([]string, 0, len(prev.Cars))
+		for id := range prev.Cars {
+			ids = append(ids, id)
+		}
+		q = q.Or("id IN ?", ids)
+	}
+	var cars []models.Car
+	if err := q.Find(&cars).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: snapshot cars: %w", err)
+	}
+	for _, c := range cars {
+		snap.Cars[c.ID] = CarState{ID: c.ID, Title: c.Title, Track: c.Track, Status: c.Status}
+	}
+	return snap, nil
+}
+
+// DiffStatus lists what changed from prev to cur: new cars, car status flips,
+// and engines appearing, disappearing, changing status, or switching cars.
+// Returns nil when prev is nil (nothing to compare against yet).
+func DiffStatus(prev, cur *StatusSnapshot) []StatusChange {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	var changes []StatusChange
+
+	for id, c := range cur.Cars {
+		old, ok := prev.Cars[id]
+		switch {
+		case !ok:
+			changes = append(changes, StatusChange{Kind: ChangeCarNew, ID: id, Detail: fmt.Sprintf("%s [%s] %s", c.Status, c.Track, c.Title)})
+		case old.Status != c.Status:
+			changes = append(changes, StatusChange{Kind: ChangeCarStatus, ID: id, Detail: old.Status + " → " + c.Status})
+		}
+	}
+
+	for id, e := range cur.Engines {
+		old, ok := prev.Engines[id]
+		switch {
+		case !ok:
+			changes = append(changes, StatusChange{Kind: ChangeEngineNew, ID: id, Detail: e.Track})
+		case old.Status != e.Status:
+			changes = append(changes, StatusChange{Kind: ChangeEngineStatus, ID: id, Detail: old.Status + " → " + e.Status})
+		case old.CurrentCar != e.CurrentCar:
+			changes = append(changes, StatusChange{Kind: ChangeEngineCar, ID: id, Detail: carLabel(old.CurrentCar) + " → " + carLabel(e.CurrentCar)})
+		}
+	}
+	for id, e := range prev.Engines {
+		if _, ok := cur.Engines[id]; !ok {
+			changes = append(changes, StatusChange{Kind: ChangeEngineGone, ID: id, Detail: e.Track})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// FormatStatusWithChanges renders the dashboard like FormatStatus, with engine
+// rows that appeared or changed highlighted, followed by a CHANGES section
+// listing everything that differs since the previous refresh at since.
+func FormatStatusWithChanges(info *StatusInfo, changes []StatusChange, since time.Time) string {
+	highlight := make(map[string]string)
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangeEngineNew:
+			highlight[c.ID] = ansiGreen
+		case ChangeEngineStatus, ChangeEngineCar:
+			highlight[c.ID] = ansiYellow
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(formatStatus(info, highlight))
+	if since.IsZero() {
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("\nCHANGES since %s\n", since.Format("15:04:05")))
+	if len(changes) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, c := range changes {
+		color, label := changeStyle(c.Kind)
+		b.WriteString(fmt.Sprintf("  %s%-12s %-14s %s%s\n", color, label, c.ID, c.Detail, ansiReset))
+	}
+	return b.String()
+}
+
+// changeStyle returns the highlight color and display label for a change.
+func changeStyle(k ChangeKind) (color, label string) {
+	switch k {
+	case ChangeCarNew:
+		return ansiGreen, "+ car"
+	case ChangeCarStatus:
+		return ansiYellow, "~ car"
+	case ChangeEngineNew:
+		return ansiGreen, "+ engine"
+	case ChangeEngineGone:
+		return ansiRed, "- engine"
+	case ChangeEngineStatus:
+		return ansiYellow, "~ engine"
+	case ChangeEngineCar:
+		return ansiYellow, "~ engine car"
+	}
+	return "", string(k)
+}
+
+func carLabel(id string) string {
+	if id == "" {
+		return "-"
+	}
+	return id
+}
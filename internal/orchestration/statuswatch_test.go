@@ -0,0 +1,133 @@
+package orchestration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestDiffStatus_NoPrevious(t *testing.T) {
+	cur := &StatusSnapshot{Cars: map[string]CarState{"car-1": {ID: "car-1", Status: "open"}}}
+	if changes := DiffStatus(nil, cur); changes != nil {
+		t.Errorf("DiffStatus(nil, cur) = %v, want nil", changes)
+	}
+}
+
+func TestDiffStatus_DetectsChanges(t *testing.T) {
+	prev := &StatusSnapshot{
+		Engines: map[string]EngineInfo{
+			"eng-stay":  {ID: "eng-stay", Status: "working", CurrentCar: "car-1"},
+			"eng-swap":  {ID: "eng-swap", Status: "working", CurrentCar: "car-2"},
+			"eng-gone":  {ID: "eng-gone", Track: "backend", Status: "idle"},
+			"eng-flip":  {ID: "eng-flip", Status: "idle"},
+			"eng-quiet": {ID: "eng-quiet", Status: "idle"},
+		},
+		Cars: map[string]CarState{
+			"car-1": {ID: "car-1", Status: "open"},
+			"car-2": {ID: "car-2", Status: "in_progress"},
+		},
+	}
+	cur := &StatusSnapshot{
+		Engines: map[string]EngineInfo{
+			"eng-stay":  {ID: "eng-stay", Status: "working", CurrentCar: "car-1"},
+			"eng-swap":  {ID: "eng-swap", Status: "working", CurrentCar: "car-3"},
+			"eng-flip":  {ID: "eng-flip", Status: "working"},
+			"eng-quiet": {ID: "eng-quiet", Status: "idle"},
+			"eng-new":   {ID: "eng-new", Track: "frontend", Status: "idle"},
+		},
+		Cars: map[string]CarState{
+			"car-1": {ID: "car-1", Status: "claimed"},
+			"car-2": {ID: "car-2", Status: "in_progress"},
+			"car-3": {ID: "car-3", Track: "backend", Status: "open", Title: "Fresh"},
+		},
+	}
+
+	got := make(map[string]StatusChange)
+	for _, c := range DiffStatus(prev, cur) {
+		got[string(c.Kind)+":"+c.ID] = c
+	}
+	want := map[string]string{
+		"car-status:car-1":       "open → claimed",
+		"car-new:car-3":          "open [backend] Fresh",
+		"engine-new:eng-new":     "frontend",
+		"engine-gone:eng-gone":   "backend",
+		"engine-status:eng-flip": "idle → working",
+		"engine-car:eng-swap":    "car-2 → car-3",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d changes, want %d: %v", len(got), len(want), got)
+	}
+	for key, detail := range want {
+		c, ok := got[key]
+		if !ok {
+			t.Errorf("missing change %s", key)
+			continue
+		}
+		if c.Detail != detail {
+			t.Errorf("%s detail = %q, want %q", key, c.Detail, detail)
+		}
+	}
+}
+
+func TestSnapshot_TracksFlipToTerminal(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-1", Title: "one", Track: "backend", Status: "done"})
+	db.Create(&models.Car{ID: "car-old", Title: "old", Track: "backend", Status: "merged"})
+
+	info := &StatusInfo{Engines: []EngineInfo{{ID: "eng-1", Status: "idle"}}}
+	first, err := Snapshot(db, clock.Real, info, nil)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if _, ok := first.Cars["car-old"]; ok {
+		t.Error("merged car should not be in the first snapshot")
+	}
+	if _, ok := first.Engines["eng-1"]; !ok {
+		t.Error("engine missing from snapshot")
+	}
+
+	db.Model(&models.Car{}).Where("id = ?", "car-1").Update("status", "merged")
+	second, err := Snapshot(db, clock.Real, info, first)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	changes := DiffStatus(first, second)
+	if len(changes) != 1 || changes[0].Kind != ChangeCarStatus || changes[0].Detail != "done → merged" {
+		t.Errorf("changes = %+v, want car-1 done → merged", changes)
+	}
+}
+
+func TestFormatStatusWithChanges(t *testing.T) {
+	info := &StatusInfo{
+		SessionRunning: true,
+		Engines: []EngineInfo{
+			{ID: "eng-new", Track: "backend", Status: "idle", LastActivity: time.Now()},
+			{ID: "eng-old", Track: "backend", Status: "idle", LastActivity: time.Now()},
+		},
+	}
+	changes := []StatusChange{
+		{Kind: ChangeEngineNew, ID: "eng-new", Detail: "backend"},
+		{Kind: ChangeCarStatus, ID: "car-1", Detail: "open → claimed"},
+	}
+	out := FormatStatusWithChanges(info, changes, time.Date(2026, 1, 1, 9, 30, 0, 0, time.Local))
+
+	if !strings.Contains(out, ansiGreen+"eng-new") {
+		t.Errorf("expected new engine row highlighted, got:\n%s", out)
+	}
+	if strings.Contains(out, ansiYellow+"eng-old") || strings.Contains(out, ansiGreen+"eng-old") {
+		t.Errorf("unchanged engine row should not be highlighted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "CHANGES since 09:30:00") || !strings.Contains(out, "open → claimed") {
+		t.Errorf("expected CHANGES section, got:\n%s", out)
+	}
+}
+
+func TestFormatStatusWithChanges_FirstRefresh(t *testing.T) {
+	out := FormatStatusWithChanges(&StatusInfo{}, nil, time.Time{})
+	if strings.Contains(out, "CHANGES") {
+		t.Errorf("first refresh should not show a CHANGES section, got:\n%s", out)
+	}
+}
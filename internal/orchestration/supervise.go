@@ -0,0 +1,158 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultStaleEngineThreshold is how long an engine may go without a
+// heartbeat before the Supervisor restarts it, when
+// stall.stale_engine_threshold_sec is unset.
+const DefaultStaleEngineThreshold = 60 * time.Second
+
+// yardmasterEngineID is the engine row the yardmaster heartbeats under; its
+// health is not the Supervisor's to judge.
+const yardmasterEngineID = "yardmaster"
+
+// SuperviseAction is what the Supervisor did about one stale engine.
+type SuperviseAction struct {
+	Engine       string
+	Track        string
+	LastActivity time.Time
+	Car          string // car re-opened for another engine; empty when it held none or the car had moved on
+	Restarted    bool   // a replacement engine was launched
+	RestartErr   error  // why the replacement was not launched
+}
+
+// Supervisor watches engine heartbeats (LastActivity). An engine silent for
+// longer than Threshold is marked dead, the car it held is re-opened, and a
+// replacement is launched on its track with RestartEngine. Call Tick
+// periodically; the yardmaster does so every cycle and `ry engine supervise`
+// runs it standalone.
+type Supervisor struct {
+	DB         *gorm.DB
+	Config     *config.Config
+	ConfigPath string
+	Tmux       Tmux          // defaults to TmuxFor(Config) if nil
+	Threshold  time.Duration // defaults to stall.stale_engine_threshold_sec, then DefaultStaleEngineThreshold
+}
+
+// NewSupervisor returns a Supervisor for cfg.
+func NewSupervisor(db *gorm.DB, cfg *config.Config, configPath string) *Supervisor {
+	return &Supervisor{DB: db, Config: cfg, ConfigPath: configPath}
+}
+
+// threshold returns the heartbeat staleness the Supervisor acts on.
+func (s *Supervisor) threshold() time.Duration {
+	if s.Threshold > 0 {
+		return s.Threshold
+	}
+	if s.Config != nil && s.Config.Stall.StaleEngineThresholdSec > 0 {
+		return time.Duration(s.Config.Stall.StaleEngineThresholdSec) * time.Second
+	}
+	return DefaultStaleEngineThreshold
+}
+
+// Tick handles every engine whose last heartbeat is older than the
+// threshold at now. A failed restart is reported on its action and does not
+// stop the others; the engine stays dead and its car open either way. In
+// Kubernetes mode engines are not restarted: the cluster replaces their
+// pods.
+func (s *Supervisor) Tick(now time.Time) ([]SuperviseAction, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("orchestration: database connection is required")
+	}
+	if s.Config == nil {
+		return nil, fmt.Errorf("orchestration: config is required")
+	}
+	threshold := s.threshold()
+
+	var stale []models.Engine
+	if err := s.DB.Where("last_activity < ? AND status != ? AND id != ?", now.Add(-threshold), "dead", yardmasterEngineID).
+		Order("last_activity").Find(&stale).Error; err != nil {
+		return nil, fmt.Errorf("orchestration: find stale engines: %w", err)
+	}
+
+	var actions []SuperviseAction
+	var errs []error
+	for _, eng := range stale {
+		reason := fmt.Sprintf("stale heartbeat (last seen %s ago)", now.Sub(eng.LastActivity).Round(time.Second))
+		reopened, err := reopenEngineCar(s.DB, eng, reason, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("engine %s: %w", eng.ID, err))
+			continue
+		}
+		act := SuperviseAction{Engine: eng.ID, Track: eng.Track, LastActivity: eng.LastActivity, Car: reopened}
+		if !s.Config.IsKubernetesMode() {
+			if s.Tmux == nil {
+				s.Tmux = TmuxFor(s.Config)
+			}
+			if err := RestartEngine(s.DB, s.Config, s.ConfigPath, eng.ID, s.Tmux); err != nil {
+				act.RestartErr = err
+			} else {
+				act.Restarted = true
+			}
+		}
+		actions = append(actions, act)
+	}
+	return actions, errors.Join(errs...)
+}
+
+// reopenEngineCar marks a stale engine dead and, when it still actively
+// holds its current car, re-opens the car for another engine with a
+// progress note and a broadcast. It returns the re-opened car's ID, or ""
+// when there was none.
+func reopenEngineCar(db *gorm.DB, eng models.Engine, reason string, now time.Time) (string, error) {
+	reopened := ""
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Engine{}).Where("id = ?", eng.ID).Updates(map[string]interface{}{
+			"status":      "dead",
+			"current_car": "",
+		}).Error; err != nil {
+			return fmt.Errorf("mark dead: %w", err)
+		}
+		if eng.CurrentCar == "" {
+			return nil
+		}
+
+		// Only a car this engine still holds: it may have completed or
+		// been reassigned since the heartbeat stopped.
+		res := tx.Model(&models.Car{}).
+			Where("id = ? AND assignee = ? AND status IN ?", eng.CurrentCar, eng.ID, []string{"claimed", "in_progress"}).
+			Updates(map[string]interface{}{"status": "open", "assignee": ""})
+		if res.Error != nil {
+			return fmt.Errorf("re-open car %s: %w", eng.CurrentCar, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		reopened = eng.CurrentCar
+
+		if err := tx.Create(&models.CarProgress{
+			CarID:        eng.CurrentCar,
+			EngineID:     eng.ID,
+			Note:         fmt.Sprintf("Reassigned from engine %s: %s", eng.ID, reason),
+			FilesChanged: "[]",
+			CreatedAt:    now,
+		}).Error; err != nil {
+			return fmt.Errorf("progress note for car %s: %w", eng.CurrentCar, err)
+		}
+		if _, err := messaging.Send(tx, "orchestrator", "broadcast", "reassignment",
+			fmt.Sprintf("Car %s reassigned from stalled engine %s", eng.CurrentCar, eng.ID),
+			messaging.SendOpts{CarID: eng.CurrentCar, Priority: "urgent"},
+		); err != nil {
+			return fmt.Errorf("broadcast reassignment for car %s: %w", eng.CurrentCar, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return reopened, nil
+}
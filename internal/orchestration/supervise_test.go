@@ -0,0 +1,102 @@
+package orchestration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestSupervisor_RestartsStaleEngines(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.CarProgress{}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	old := now.Add(-5 * time.Minute)
+	db.Create(&models.Engine{ID: "eng-stale", Track: "backend", Status: "working", CurrentCar: "car-1", StartedAt: old, LastActivity: old})
+	db.Create(&models.Engine{ID: "eng-moved", Track: "backend", Status: "working", CurrentCar: "car-2", StartedAt: old, LastActivity: old})
+	db.Create(&models.Engine{ID: "eng-live", Track: "backend", Status: "working", CurrentCar: "car-3", StartedAt: old, LastActivity: now})
+	db.Create(&models.Engine{ID: "yardmaster", Track: "*", Status: "idle", StartedAt: old, LastActivity: old})
+	db.Create(&models.Car{ID: "car-1", Track: "backend", Status: "in_progress", Assignee: "eng-stale"})
+	db.Create(&models.Car{ID: "car-2", Track: "backend", Status: "done", Assignee: "eng-moved"})
+
+	m := &mockTmux{sessionExistsFunc: func(name string) bool { return name == YardmasterSession("test") }}
+	sup := NewSupervisor(db, testConfig("test"), "/tmp/test.yaml")
+	sup.Tmux = m
+	actions, err := sup.Tick(now)
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("actions = %+v, want eng-stale and eng-moved", actions)
+	}
+	for _, a := range actions {
+		if !a.Restarted || a.RestartErr != nil {
+			t.Errorf("%s not restarted: %v", a.Engine, a.RestartErr)
+		}
+		if wantCar := map[string]string{"eng-stale": "car-1", "eng-moved": ""}[a.Engine]; a.Car != wantCar {
+			t.Errorf("%s re-opened %q, want %q", a.Engine, a.Car, wantCar)
+		}
+	}
+	if len(m.createdSessions) != 2 {
+		t.Errorf("replacement sessions = %d, want 2", len(m.createdSessions))
+	}
+
+	var c1, c2 models.Car
+	db.First(&c1, "id = ?", "car-1")
+	db.First(&c2, "id = ?", "car-2")
+	if c1.Status != "open" || c1.Assignee != "" {
+		t.Errorf("car-1 = %s/%q, want open and unassigned", c1.Status, c1.Assignee)
+	}
+	if c2.Status != "done" {
+		t.Errorf("car-2 = %s, want untouched", c2.Status)
+	}
+	var notes int64
+	db.Model(&models.CarProgress{}).Where("car_id = ?", "car-1").Count(&notes)
+	if notes != 1 {
+		t.Errorf("progress notes for car-1 = %d, want 1", notes)
+	}
+	for id, want := range map[string]string{"eng-stale": "dead", "eng-moved": "dead", "eng-live": "working", "yardmaster": "idle"} {
+		var e models.Engine
+		db.First(&e, "id = ?", id)
+		if e.Status != want {
+			t.Errorf("%s status = %s, want %s", id, e.Status, want)
+		}
+	}
+
+	// Dead engines are not handled again.
+	if actions, _ := sup.Tick(now); len(actions) != 0 {
+		t.Errorf("second tick = %+v", actions)
+	}
+}
+
+func TestSupervisor_ThresholdAndRestartFailure(t *testing.T) {
+	db := testDB(t)
+	db.AutoMigrate(&models.CarProgress{})
+	now := time.Now()
+	db.Create(&models.Engine{ID: "eng-1", Track: "backend", Status: "idle", StartedAt: now, LastActivity: now.Add(-90 * time.Second)})
+
+	cfg := testConfig("test")
+	cfg.Stall.StaleEngineThresholdSec = 120
+	sup := NewSupervisor(db, cfg, "/tmp/test.yaml")
+	sup.Tmux = &mockTmux{sessionExists: false}
+	if actions, err := sup.Tick(now); err != nil || len(actions) != 0 {
+		t.Fatalf("within the configured threshold: actions = %+v, err = %v", actions, err)
+	}
+
+	sup.Threshold = time.Minute
+	actions, err := sup.Tick(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Restarted || !errors.Is(actions[0].RestartErr, ErrNoSession) {
+		t.Fatalf("actions = %+v, want a restart failing without a session", actions)
+	}
+	var e models.Engine
+	db.First(&e, "id = ?", "eng-1")
+	if e.Status != "dead" {
+		t.Errorf("status = %s, want dead even though the restart failed", e.Status)
+	}
+}
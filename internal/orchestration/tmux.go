@@ -1,6 +1,10 @@
 package orchestration
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/zulandar/railyard/internal/config"
+)
 
 // Legacy session names kept for Stop() backwards compatibility.
 const legacySessionName = "railyard"
@@ -49,7 +53,8 @@ func DispatchSession(owner string) string {
 	return fmt.Sprintf("railyard_%s_dispatch", owner)
 }
 
-// Tmux abstracts tmux operations for testability.
+// Tmux abstracts terminal-multiplexer operations for testability. RealTmux
+// and Zellij implement it against the respective CLIs.
 type Tmux interface {
 	SessionExists(name string) bool
 	CreateSession(name string) error
@@ -62,3 +67,29 @@ type Tmux interface {
 // DefaultTmux is the default tmux implementation used by the package.
 // Set to RealTmux{} in tmux_real.go (excluded from test builds via build tag).
 var DefaultTmux Tmux = RealTmux{}
+
+// TmuxFor returns the multiplexer to use for cfg: Zellij when cfg selects
+// it, a RealTmux built from cfg.Tmux when that section sets anything, and
+// otherwise DefaultTmux.
+func TmuxFor(cfg *config.Config) Tmux {
+	if cfg == nil {
+		return DefaultTmux
+	}
+	if cfg.Multiplexer == config.MultiplexerZellij {
+		return Zellij{Binary: cfg.Zellij.Binary}
+	}
+	if cfg.Tmux.Binary == "" && cfg.Tmux.Socket == "" && cfg.Tmux.BaseIndex == nil {
+		return DefaultTmux
+	}
+	return RealTmux{Binary: cfg.Tmux.Binary, Socket: cfg.Tmux.Socket, BaseIndex: cfg.Tmux.BaseIndex}
+}
+
+// validateTmux runs tmux's Validate method when it has one. Backends without
+// configuration to check (test mocks, the unittest stub) need not implement it.
+func validateTmux(tmux Tmux) error {
+	v, ok := tmux.(interface{ Validate() error })
+	if !ok {
+		return nil
+	}
+	return v.Validate()
+}
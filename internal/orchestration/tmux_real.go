@@ -10,15 +10,54 @@ import (
 )
 
 // RealTmux is the production implementation that calls the real tmux binary.
-type RealTmux struct{}
+// The zero value runs "tmux" from PATH against the default server; see
+// TmuxFor for one configured from railyard.yaml.
+type RealTmux struct {
+	Binary    string // executable name or path; "" means "tmux"
+	Socket    string // server socket name (-L); "" means the default server
+	BaseIndex *int   // first window index; nil targets the session's active window
+}
+
+// command builds a tmux invocation against the configured binary and server.
+func (t RealTmux) command(args ...string) *exec.Cmd {
+	if t.Socket != "" {
+		args = append([]string{"-L", t.Socket}, args...)
+	}
+	return exec.Command(t.binary(), args...)
+}
+
+func (t RealTmux) binary() string {
+	if t.Binary == "" {
+		return "tmux"
+	}
+	return t.Binary
+}
+
+// paneTarget is the -t target for commands that act on a pane: the session
+// itself, or its first window when BaseIndex is configured.
+func (t RealTmux) paneTarget(session string) string {
+	if t.BaseIndex == nil {
+		return session
+	}
+	return fmt.Sprintf("%s:%d", session, *t.BaseIndex)
+}
+
+// Validate checks that the tmux binary can be found, so a bad binary setting
+// fails Start up front rather than on the first session it creates.
+func (t RealTmux) Validate() error {
+	if _, err := exec.LookPath(t.binary()); err != nil {
+		return fmt.Errorf("orchestration: tmux binary %q not found: %w", t.binary(), err)
+	}
+	return nil
+}
 
-func (RealTmux) SessionExists(name string) bool {
-	cmd := exec.Command("tmux", "has-session", "-t", name)
+func (t RealTmux) SessionExists(name string) bool {
+	cmd := t.command("has-session", "-t", name)
 	return cmd.Run() == nil
 }
 
-func (RealTmux) CreateSession(name string) error {
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", name, "-x", "200", "-y", "50")
+func (t RealTmux) CreateSession(name string) error {
+	cmd := t.command("new-session", "-d", "-s", name, "-x", "200", "-y", "50")
 	// Unset TMUX so this works when invoked from inside an existing tmux session.
 	cmd.Env = envWithoutTMUX()
 	if out, err := cmd.CombinedOutput(); err != nil {
@@ -39,24 +78,24 @@ func envWithoutTMUX() []string {
 	return env
 }
 
-func (RealTmux) SendKeys(session, keys string) error {
-	cmd := exec.Command("tmux", "send-keys", "-t", session, keys, "Enter")
+func (t RealTmux) SendKeys(session, keys string) error {
+	cmd := t.command("send-keys", "-t", t.paneTarget(session), keys, "Enter")
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("send keys to %q: %s: %w", session, strings.TrimSpace(string(out)), err)
 	}
 	return nil
 }
 
-func (RealTmux) SendSignal(session, signal string) error {
-	cmd := exec.Command("tmux", "send-keys", "-t", session, signal)
+func (t RealTmux) SendSignal(session, signal string) error {
+	cmd := t.command("send-keys", "-t", t.paneTarget(session), signal)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("send signal to %q: %s: %w", session, strings.TrimSpace(string(out)), err)
 	}
 	return nil
 }
 
-func (RealTmux) KillSession(name string) error {
-	cmd := exec.Command("tmux", "kill-session", "-t", name)
+func (t RealTmux) KillSession(name string) error {
+	cmd := t.command("kill-session", "-t", name)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("kill tmux session %q: %s: %w", name, strings.TrimSpace(string(out)), err)
 	}
@@ -64,8 +103,8 @@ func (RealTmux) KillSession(name string) error {
 }
 
 // ListSessions returns all tmux session names matching the given prefix.
-func (RealTmux) ListSessions(prefix string) ([]string, error) {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
+func (t RealTmux) ListSessions(prefix string) ([]string, error) {
+	cmd := t.command("list-sessions", "-F", "#{session_name}")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		// tmux returns error when server is not running (no sessions).
@@ -84,3 +123,24 @@ func (RealTmux) ListSessions(prefix string) ([]string, error) {
 	}
 	return sessions, nil
 }
+
+// PaneCommand returns the name of the foreground process in the session's
+// pane, e.g. "ry" once a launched daemon is running or "bash" at a prompt.
+func (t RealTmux) PaneCommand(session string) (string, error) {
+	cmd := t.command("display-message", "-p", "-t", t.paneTarget(session), "#{pane_current_command}")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("inspect tmux pane %q: %s: %w", session, strings.TrimSpace(string(out)), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CapturePane returns the visible contents of the session's pane.
+func (t RealTmux) CapturePane(session string) (string, error) {
+	cmd := t.command("capture-pane", "-p", "-t", t.paneTarget(session))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("capture tmux pane %q: %s: %w", session, strings.TrimSpace(string(out)), err)
+	}
+	return string(out), nil
+}
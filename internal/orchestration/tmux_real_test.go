@@ -0,0 +1,57 @@
+//go:build !unittest
+
+package orchestration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func TestRealTmux_CommandUsesBinaryAndSocket(t *testing.T) {
+	base := 1
+	tm := RealTmux{Binary: "/opt/tmux/bin/tmux", Socket: "railyard", BaseIndex: &base}
+
+	cmd := tm.command("send-keys", "-t", tm.paneTarget("railyard_me_yardmaster"), "ry yardmaster", "Enter")
+	want := []string{"/opt/tmux/bin/tmux", "-L", "railyard", "send-keys", "-t", "railyard_me_yardmaster:1", "ry yardmaster", "Enter"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+
+	var zero RealTmux
+	if got := zero.command("list-sessions").Args; !slices.Equal(got, []string{"tmux", "list-sessions"}) {
+		t.Errorf("zero-value args = %q", got)
+	}
+	if got := zero.paneTarget("s"); got != "s" {
+		t.Errorf("zero-value paneTarget = %q, want s", got)
+	}
+}
+
+func TestTmuxFor(t *testing.T) {
+	if got := TmuxFor(nil); got != DefaultTmux {
+		t.Errorf("TmuxFor(nil) = %#v, want DefaultTmux", got)
+	}
+	if got := TmuxFor(&config.Config{}); got != DefaultTmux {
+		t.Errorf("TmuxFor(empty) = %#v, want DefaultTmux", got)
+	}
+	cfg := &config.Config{Tmux: config.TmuxConfig{Socket: "railyard"}}
+	rt, ok := TmuxFor(cfg).(RealTmux)
+	if !ok || rt.Socket != "railyard" {
+		t.Errorf("TmuxFor(socket) = %#v, want RealTmux on socket railyard", TmuxFor(cfg))
+	}
+}
+
+func TestStart_MissingTmuxBinary(t *testing.T) {
+	cfg := testConfig("test", config.TrackConfig{Name: "backend", EngineSlots: 1})
+	cfg.Tmux.Binary = "/nonexistent/bin/tmux"
+	_, err := Start(StartOpts{
+		Config:     cfg,
+		ConfigPath: "/tmp/test.yaml",
+		DB:         testDB(t),
+	})
+	if err == nil || !strings.Contains(err.Error(), `tmux binary "/nonexistent/bin/tmux" not found`) {
+		t.Errorf("err = %v, want missing tmux binary", err)
+	}
+}
@@ -4,8 +4,13 @@ package orchestration
 
 // RealTmux is a no-op stub used during unit testing (build tag: unittest).
 // The real implementation is in tmux_real.go.
-type RealTmux struct{}
+type RealTmux struct {
+	Binary    string
+	Socket    string
+	BaseIndex *int
+}
 
+func (RealTmux) Validate() error                              { return nil }
 func (RealTmux) SessionExists(name string) bool               { return false }
 func (RealTmux) CreateSession(name string) error              { return nil }
 func (RealTmux) SendKeys(session, keys string) error          { return nil }
@@ -0,0 +1,58 @@
+package orchestration
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseZellijSessions extracts live session names from
+// `zellij list-sessions --no-formatting`, one session per line as
+// "NAME [Created 3m ago] (current)". Exited sessions, which zellij keeps
+// around for resurrection, are skipped.
+func parseZellijSessions(out string) []string {
+	var sessions []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.Contains(line, "(EXITED") {
+			continue
+		}
+		sessions = append(sessions, fields[0])
+	}
+	return sessions
+}
+
+// parseZellijVersion parses `zellij --version` output ("zellij 0.40.1").
+func parseZellijVersion(out string) (major, minor int, ok bool) {
+	fields := strings.Fields(out)
+	if len(fields) < 2 {
+		return 0, 0, false
+	}
+	parts := strings.Split(strings.TrimPrefix(fields[1], "v"), ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// zellijKeyByte maps a tmux key name, as passed to Tmux.SendSignal, to the
+// byte zellij's `action write` sends: "C-c" is 3, "Enter" is 13.
+func zellijKeyByte(key string) (byte, bool) {
+	switch key {
+	case "Enter":
+		return 13, true
+	case "Escape":
+		return 27, true
+	}
+	if len(key) == 3 && strings.HasPrefix(key, "C-") {
+		c := key[2] | 0x20 // lower-case
+		if c >= 'a' && c <= 'z' {
+			return c - 'a' + 1, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,145 @@
+//go:build !unittest
+
+package orchestration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// minZellijVersion is the first release with `attach --create-background`,
+// which Start needs to create sessions without a terminal.
+var minZellijVersion = [2]int{0, 39}
+
+// Zellij implements Tmux on top of the zellij CLI, for hosts where zellij is
+// the multiplexer of choice (config: multiplexer: zellij). Sessions map to
+// zellij sessions of the same name; keys are typed into the session's
+// focused pane.
+type Zellij struct {
+	Binary string // executable name or path; "" means "zellij"
+}
+
+func (z Zellij) binary() string {
+	if z.Binary == "" {
+		return "zellij"
+	}
+	return z.Binary
+}
+
+// command builds a zellij invocation. Nested zellij variables are dropped so
+// it works when run from inside an existing zellij session.
+func (z Zellij) command(args ...string) *exec.Cmd {
+	cmd := exec.Command(z.binary(), args...)
+	var env []string
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, "ZELLIJ=") && !strings.HasPrefix(e, "ZELLIJ_SESSION_NAME=") {
+			env = append(env, e)
+		}
+	}
+	cmd.Env = env
+	return cmd
+}
+
+// Validate checks that zellij is installed and new enough to create
+// background sessions.
+func (z Zellij) Validate() error {
+	path, err := exec.LookPath(z.binary())
+	if err != nil {
+		return fmt.Errorf("orchestration: zellij binary %q not found: %w", z.binary(), err)
+	}
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("orchestration: zellij --version: %w", err)
+	}
+	major, minor, ok := parseZellijVersion(string(out))
+	if !ok {
+		return nil // unrecognized format: let the first command report problems
+	}
+	if major < minZellijVersion[0] || (major == minZellijVersion[0] && minor < minZellijVersion[1]) {
+		return fmt.Errorf("orchestration: zellij %d.%d is too old; %d.%d or newer is required",
+			major, minor, minZellijVersion[0], minZellijVersion[1])
+	}
+	return nil
+}
+
+func (z Zellij) SessionExists(name string) bool {
+	sessions, err := z.liveSessions()
+	if err != nil {
+		return false
+	}
+	for _, s := range sessions {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (z Zellij) CreateSession(name string) error {
+	cmd := z.command("attach", "--create-background", name)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("create zellij session %q: %s: %w", name, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+func (z Zellij) SendKeys(session, keys string) error {
+	if out, err := z.command("--session", session, "action", "write-chars", keys).CombinedOutput(); err != nil {
+		return fmt.Errorf("send keys to %q: %s: %w", session, strings.TrimSpace(string(out)), err)
+	}
+	if out, err := z.command("--session", session, "action", "write", "13").CombinedOutput(); err != nil {
+		return fmt.Errorf("send keys to %q: %s: %w", session, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// SendSignal types a tmux-style key name (e.g. "C-c") into the session.
+func (z Zellij) SendSignal(session, signal string) error {
+	b, ok := zellijKeyByte(signal)
+	if !ok {
+		return fmt.Errorf("send signal to %q: unsupported key %q", session, signal)
+	}
+	cmd := z.command("--session", session, "action", "write", strconv.Itoa(int(b)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("send signal to %q: %s: %w", session, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+func (z Zellij) KillSession(name string) error {
+	cmd := z.command("kill-session", name)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("kill zellij session %q: %s: %w", name, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// ListSessions returns running zellij session names matching the given prefix.
+func (z Zellij) ListSessions(prefix string) ([]string, error) {
+	sessions, err := z.liveSessions()
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, s := range sessions {
+		if strings.HasPrefix(s, prefix) {
+			matched = append(matched, s)
+		}
+	}
+	return matched, nil
+}
+
+func (z Zellij) liveSessions() ([]string, error) {
+	out, err := z.command("list-sessions", "--no-formatting").CombinedOutput()
+	if err != nil {
+		// zellij exits non-zero when there are no sessions at all.
+		if strings.Contains(string(out), "No active zellij sessions") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list zellij sessions: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return parseZellijSessions(string(out)), nil
+}
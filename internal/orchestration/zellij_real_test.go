@@ -0,0 +1,33 @@
+//go:build !unittest
+
+package orchestration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestZellij_CommandsAndValidate(t *testing.T) {
+	t.Setenv("ZELLIJ", "0")
+	t.Setenv("ZELLIJ_SESSION_NAME", "outer")
+	z := Zellij{}
+	cmd := z.command("--session", "railyard_me_eng000", "action", "write-chars", "ry engine start")
+	want := []string{"zellij", "--session", "railyard_me_eng000", "action", "write-chars", "ry engine start"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+	for _, e := range cmd.Env {
+		if strings.HasPrefix(e, "ZELLIJ=") || strings.HasPrefix(e, "ZELLIJ_SESSION_NAME=") {
+			t.Errorf("nested zellij variable leaked into env: %s", e)
+		}
+	}
+
+	err := Zellij{Binary: "/nonexistent/bin/zellij"}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "zellij binary") {
+		t.Errorf("Validate = %v, want missing binary", err)
+	}
+	if err := (Zellij{}).SendSignal("s", "F1"); err == nil {
+		t.Error("SendSignal(F1) should report an unsupported key")
+	}
+}
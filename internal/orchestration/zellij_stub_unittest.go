@@ -0,0 +1,17 @@
+//go:build unittest
+
+package orchestration
+
+// Zellij is a no-op stub used during unit testing (build tag: unittest).
+// The real implementation is in zellij_real.go.
+type Zellij struct {
+	Binary string
+}
+
+func (Zellij) Validate() error                              { return nil }
+func (Zellij) SessionExists(name string) bool               { return false }
+func (Zellij) CreateSession(name string) error              { return nil }
+func (Zellij) SendKeys(session, keys string) error          { return nil }
+func (Zellij) SendSignal(session, signal string) error      { return nil }
+func (Zellij) KillSession(name string) error                { return nil }
+func (Zellij) ListSessions(prefix string) ([]string, error) { return nil, nil }
@@ -0,0 +1,68 @@
+package orchestration
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func TestParseZellijSessions(t *testing.T) {
+	out := "railyard_me_yardmaster [Created 5m ago] \n" +
+		"railyard_me_eng000 [Created 5m ago] (current)\n" +
+		"railyard_me_eng001 [Created 2h ago] (EXITED - attach to resurrect)\n" +
+		"\n"
+	got := parseZellijSessions(out)
+	want := []string{"railyard_me_yardmaster", "railyard_me_eng000"}
+	if !slices.Equal(got, want) {
+		t.Errorf("sessions = %q, want %q", got, want)
+	}
+}
+
+func TestParseZellijVersion(t *testing.T) {
+	tests := []struct {
+		in           string
+		major, minor int
+		ok           bool
+	}{
+		{"zellij 0.40.1\n", 0, 40, true},
+		{"zellij v0.39.0", 0, 39, true},
+		{"zellij", 0, 0, false},
+		{"zellij nightly", 0, 0, false},
+	}
+	for _, tt := range tests {
+		major, minor, ok := parseZellijVersion(tt.in)
+		if major != tt.major || minor != tt.minor || ok != tt.ok {
+			t.Errorf("parseZellijVersion(%q) = %d, %d, %v", tt.in, major, minor, ok)
+		}
+	}
+}
+
+func TestZellijKeyByte(t *testing.T) {
+	tests := []struct {
+		key  string
+		want byte
+		ok   bool
+	}{
+		{"C-c", 3, true},
+		{"C-D", 4, true},
+		{"Enter", 13, true},
+		{"Escape", 27, true},
+		{"C-1", 0, false},
+		{"F1", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := zellijKeyByte(tt.key)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("zellijKeyByte(%q) = %d, %v; want %d, %v", tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTmuxFor_Zellij(t *testing.T) {
+	cfg := &config.Config{Multiplexer: config.MultiplexerZellij, Zellij: config.ZellijConfig{Binary: "/opt/zellij"}}
+	z, ok := TmuxFor(cfg).(Zellij)
+	if !ok || z.Binary != "/opt/zellij" {
+		t.Errorf("TmuxFor = %#v, want Zellij with configured binary", TmuxFor(cfg))
+	}
+}
@@ -0,0 +1,108 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// Target is an endpoint `ry net check` probes.
+type Target struct {
+	Name string
+	URL  string
+}
+
+// Targets returns the endpoints cfg talks to: each configured chat platform
+// (its REST API and websocket gateway) and GitHub.
+func Targets(cfg *config.Config) []Target {
+	var ts []Target
+	for _, p := range cfg.Telegraph.EnabledPlatforms() {
+		switch p {
+		case "slack":
+			ts = append(ts,
+				Target{"slack api", "https://slack.com/api/api.test"},
+				Target{"slack socket mode", "wss://wss-primary.slack.com/"},
+			)
+		case "discord":
+			ts = append(ts,
+				Target{"discord api", "https://discord.com/api/v10/gateway"},
+				Target{"discord gateway", "wss://gateway.discord.gg/"},
+			)
+		}
+	}
+	return append(ts, Target{"github api", "https://api.github.com/"})
+}
+
+// CheckResult is the outcome of probing one Target.
+type CheckResult struct {
+	Target  Target
+	Proxy   string // proxy used, or "" for a direct connection
+	Status  int    // HTTP status, 0 when the request failed
+	Elapsed time.Duration
+	Err     error
+}
+
+// OK reports whether the endpoint was reached. Any HTTP response counts:
+// the point is that the proxy and TLS chain work, not that the request was
+// authorized.
+func (r CheckResult) OK() bool { return r.Err == nil }
+
+// Check probes each target through a client built from n. Websocket targets
+// are probed over HTTPS, which exercises the same proxy CONNECT and TLS
+// handshake a websocket dial does.
+func Check(ctx context.Context, n config.NetworkConfig, targets []Target, timeout time.Duration) ([]CheckResult, error) {
+	client, err := Client(n, timeout)
+	if err != nil {
+		return nil, err
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	results := make([]CheckResult, 0, len(targets))
+	for _, t := range targets {
+		results = append(results, checkOne(ctx, client, n, t))
+	}
+	return results, nil
+}
+
+func checkOne(ctx context.Context, client *http.Client, n config.NetworkConfig, t Target) CheckResult {
+	res := CheckResult{Target: t}
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		res.Err = fmt.Errorf("bad url: %w", err)
+		return res
+	}
+	if proxy, err := ProxyFor(n, u); err != nil {
+		res.Err = fmt.Errorf("proxy: %w", err)
+		return res
+	} else if proxy != nil {
+		res.Proxy = proxy.Redacted()
+	}
+	switch u.Scheme {
+	case "wss":
+		u.Scheme = "https"
+	case "ws":
+		u.Scheme = "http"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	res.Elapsed = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	res.Status = resp.StatusCode
+	return res
+}
@@ -0,0 +1,152 @@
+// Package outbound builds the HTTP clients and websocket dialers Railyard
+// uses to reach the outside world (Slack, Discord, GitHub, agent APIs),
+// honoring the network block of the config: proxy URLs and an extra CA
+// bundle for environments that sit behind a corporate proxy or
+// TLS-inspecting gateway.
+//
+// Daemons call Apply once at startup so that everything built on
+// http.DefaultTransport — including third-party SDKs — and every spawned
+// subprocess picks up the same settings. Adapters that open websockets take
+// a Dialer explicitly, since gorilla's default dialer ignores
+// http.DefaultTransport.
+package outbound
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// ProxyFunc returns the proxy selector for n. Fields n leaves empty fall
+// back to the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+func ProxyFunc(n config.NetworkConfig) func(*http.Request) (*url.URL, error) {
+	pf := proxyConfig(n).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return pf(req.URL)
+	}
+}
+
+// ProxyFor returns the proxy that requests to target go through, or nil for
+// a direct connection.
+func ProxyFor(n config.NetworkConfig, target *url.URL) (*url.URL, error) {
+	u := *target
+	switch u.Scheme {
+	case "wss":
+		u.Scheme = "https"
+	case "ws":
+		u.Scheme = "http"
+	}
+	return proxyConfig(n).ProxyFunc()(&u)
+}
+
+func proxyConfig(n config.NetworkConfig) *httpproxy.Config {
+	pc := httpproxy.FromEnvironment()
+	if n.HTTPProxy != "" {
+		pc.HTTPProxy = n.HTTPProxy
+	}
+	if n.HTTPSProxy != "" {
+		pc.HTTPSProxy = n.HTTPSProxy
+	}
+	if n.NoProxy != "" {
+		pc.NoProxy = n.NoProxy
+	}
+	return pc
+}
+
+// TLSConfig returns a TLS config trusting the system roots plus n.CABundle,
+// or nil when no bundle is configured.
+func TLSConfig(n config.NetworkConfig) (*tls.Config, error) {
+	if n.CABundle == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(n.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("outbound: read ca_bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("outbound: ca_bundle %s contains no PEM certificates", n.CABundle)
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// Transport returns a clone of the standard library's default transport
+// with n's proxy and CA bundle applied.
+func Transport(n config.NetworkConfig) (*http.Transport, error) {
+	tlsCfg, err := TLSConfig(n)
+	if err != nil {
+		return nil, err
+	}
+	t := defaultTransport.Clone()
+	t.Proxy = ProxyFunc(n)
+	if tlsCfg != nil {
+		t.TLSClientConfig = tlsCfg
+	}
+	return t, nil
+}
+
+// Client returns an *http.Client over Transport(n).
+func Client(n config.NetworkConfig, timeout time.Duration) (*http.Client, error) {
+	t, err := Transport(n)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t, Timeout: timeout}, nil
+}
+
+// Dialer returns a websocket dialer with n's proxy and CA bundle applied.
+func Dialer(n config.NetworkConfig) (*websocket.Dialer, error) {
+	tlsCfg, err := TLSConfig(n)
+	if err != nil {
+		return nil, err
+	}
+	return &websocket.Dialer{
+		Proxy:            ProxyFunc(n),
+		TLSClientConfig:  tlsCfg,
+		HandshakeTimeout: 45 * time.Second,
+	}, nil
+}
+
+// defaultTransport is the standard library transport as it was before
+// Apply replaced http.DefaultTransport, so repeated Applies start clean.
+var defaultTransport = http.DefaultTransport.(*http.Transport)
+
+// Apply installs Transport(n) as http.DefaultTransport and exports the
+// configured proxies to the environment so subprocesses (agent CLIs, git,
+// gh) use them too. The CA bundle is exported as NODE_EXTRA_CA_CERTS, which
+// Node-based agent CLIs add to their roots; it is not exported to variables
+// like SSL_CERT_FILE that replace the system roots. Apply is a no-op when n
+// is empty.
+func Apply(n config.NetworkConfig) error {
+	if n == (config.NetworkConfig{}) {
+		return nil
+	}
+	t, err := Transport(n)
+	if err != nil {
+		return err
+	}
+	http.DefaultTransport = t
+	for _, kv := range [][2]string{
+		{"HTTP_PROXY", n.HTTPProxy},
+		{"HTTPS_PROXY", n.HTTPSProxy},
+		{"NO_PROXY", n.NoProxy},
+		{"NODE_EXTRA_CA_CERTS", n.CABundle},
+	} {
+		if kv[1] != "" {
+			os.Setenv(kv[0], kv[1])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package outbound
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func clearProxyEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy", "REQUEST_METHOD"} {
+		t.Setenv(k, "")
+	}
+}
+
+// writeServerCA writes srv's certificate as a PEM bundle and returns its path.
+func writeServerCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProxyFor(t *testing.T) {
+	clearProxyEnv(t)
+	t.Setenv("HTTP_PROXY", "http://env-proxy:8080")
+	n := config.NetworkConfig{HTTPSProxy: "http://corp-proxy:3128", NoProxy: ".internal"}
+
+	for _, tc := range []struct{ target, want string }{
+		{"https://api.github.com/", "http://corp-proxy:3128"},
+		{"wss://gateway.discord.gg/", "http://corp-proxy:3128"},
+		{"http://example.com/", "http://env-proxy:8080"},
+		{"https://git.internal/", ""},
+	} {
+		u, _ := url.Parse(tc.target)
+		got, err := ProxyFor(n, u)
+		if err != nil {
+			t.Fatalf("ProxyFor(%s): %v", tc.target, err)
+		}
+		gotStr := ""
+		if got != nil {
+			gotStr = got.String()
+		}
+		if gotStr != tc.want {
+			t.Errorf("ProxyFor(%s) = %q, want %q", tc.target, gotStr, tc.want)
+		}
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	if cfg, err := TLSConfig(config.NetworkConfig{}); cfg != nil || err != nil {
+		t.Errorf("no bundle: cfg = %v, err = %v; want nil, nil", cfg, err)
+	}
+	if _, err := TLSConfig(config.NetworkConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("missing bundle: want error")
+	}
+	junk := filepath.Join(t.TempDir(), "junk.pem")
+	os.WriteFile(junk, []byte("not a certificate"), 0o600)
+	if _, err := TLSConfig(config.NetworkConfig{CABundle: junk}); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("junk bundle: err = %v", err)
+	}
+}
+
+func TestClient_TrustsCABundle(t *testing.T) {
+	clearProxyEnv(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	plain, err := Client(config.NetworkConfig{}, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Get(srv.URL); err == nil {
+		t.Fatal("system roots trusted the test server's self-signed certificate")
+	}
+
+	trusting, err := Client(config.NetworkConfig{CABundle: writeServerCA(t, srv)}, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := trusting.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("with ca_bundle: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestDialer(t *testing.T) {
+	clearProxyEnv(t)
+	d, err := Dialer(config.NetworkConfig{HTTPSProxy: "http://corp-proxy:3128"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://gateway.discord.gg/", nil)
+	if proxy, err := d.Proxy(req); err != nil || proxy == nil || proxy.Host != "corp-proxy:3128" {
+		t.Errorf("dialer proxy = %v, %v", proxy, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	clearProxyEnv(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	wss := "wss" + strings.TrimPrefix(srv.URL, "https")
+	targets := []Target{{"api", srv.URL}, {"gateway", wss}}
+
+	results, err := Check(context.Background(), config.NetworkConfig{CABundle: writeServerCA(t, srv)}, targets, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if !r.OK() || r.Status != http.StatusUnauthorized || r.Proxy != "" {
+			t.Errorf("%s: ok=%v status=%d proxy=%q err=%v", r.Target.Name, r.OK(), r.Status, r.Proxy, r.Err)
+		}
+	}
+
+	results, _ = Check(context.Background(), config.NetworkConfig{}, targets[:1], 5*time.Second)
+	if results[0].OK() {
+		t.Error("untrusted certificate passed the check")
+	}
+}
+
+func TestTargets(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Telegraph.Platform = "discord"
+	var names []string
+	for _, tg := range Targets(cfg) {
+		names = append(names, tg.Name)
+	}
+	if got := strings.Join(names, ","); got != "discord api,discord gateway,github api" {
+		t.Errorf("targets = %s", got)
+	}
+
+	cfg.Telegraph.Platforms = []string{"slack", "discord"}
+	names = nil
+	for _, tg := range Targets(cfg) {
+		names = append(names, tg.Name)
+	}
+	if got := strings.Join(names, ","); got != "slack api,slack socket mode,discord api,discord gateway,github api" {
+		t.Errorf("targets with two platforms = %s", got)
+	}
+}
@@ -0,0 +1,110 @@
+package packaging
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ParseChecksums reads a sha256sum-format checksums.txt ("<hash>  <file>")
+// into a map from file name to hash.
+func ParseChecksums(data []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || len(fields[0]) != 64 {
+			return nil, fmt.Errorf("packaging: checksums line %d: want \"<sha256>  <file>\", got %q", n, line)
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("packaging: %w", err)
+	}
+	return sums, nil
+}
+
+// brewPlatforms are the release tarballs a formula can point at, in the
+// order the formula lists them.
+var brewPlatforms = []struct {
+	goos, goarch string
+	os, cpu      string // Homebrew on_<os> and on_<cpu> blocks
+}{
+	{"darwin", "arm64", "macos", "arm"},
+	{"darwin", "amd64", "macos", "intel"},
+	{"linux", "arm64", "linux", "arm"},
+	{"linux", "amd64", "linux", "intel"},
+}
+
+// TarballName is the release tarball for a platform, as the release
+// workflow names it.
+func TarballName(tag, goos, goarch string) string {
+	return fmt.Sprintf("ry-%s-%s-%s.tar.gz", tag, goos, goarch)
+}
+
+// BrewFormula renders a Homebrew formula for the release tag whose tarballs
+// are published under urlBase. Platforms missing from checksums are left
+// out; the formula generates man pages and completions from the installed
+// binary with ry gen docs.
+func BrewFormula(tag, urlBase string, checksums map[string]string) (string, error) {
+	version, err := PackageVersion(tag)
+	if err != nil {
+		return "", err
+	}
+	urlBase = strings.TrimSuffix(urlBase, "/")
+
+	var b strings.Builder
+	b.WriteString("class Railyard < Formula\n")
+	fmt.Fprintf(&b, "  desc %q\n", Summary)
+	fmt.Fprintf(&b, "  homepage %q\n", Homepage)
+	fmt.Fprintf(&b, "  version %q\n", strings.ReplaceAll(version, "~", "-"))
+	fmt.Fprintf(&b, "  license %q\n", License)
+
+	found := 0
+	for _, osName := range []string{"macos", "linux"} {
+		var blocks strings.Builder
+		for _, p := range brewPlatforms {
+			if p.os != osName {
+				continue
+			}
+			name := TarballName(tag, p.goos, p.goarch)
+			sum, ok := checksums[name]
+			if !ok {
+				continue
+			}
+			found++
+			fmt.Fprintf(&blocks, "    on_%s do\n", p.cpu)
+			fmt.Fprintf(&blocks, "      url %q\n", urlBase+"/"+name)
+			fmt.Fprintf(&blocks, "      sha256 %q\n", sum)
+			blocks.WriteString("    end\n")
+		}
+		if blocks.Len() > 0 {
+			fmt.Fprintf(&b, "\n  on_%s do\n%s  end\n", osName, blocks.String())
+		}
+	}
+	if found == 0 {
+		return "", fmt.Errorf("packaging: checksums list no release tarballs for %s", tag)
+	}
+
+	b.WriteString(`
+  def install
+    bin.install Dir["ry-*"].first => "ry"
+    system bin/"ry", "gen", "docs", "--out", buildpath/"gen"
+    bash_completion.install "gen/completions/ry.bash" => "ry"
+    zsh_completion.install "gen/completions/_ry"
+    fish_completion.install "gen/completions/ry.fish"
+    man1.install Dir["gen/man/*.1"]
+  end
+
+  test do
+    assert_match version.to_s, shell_output("#{bin}/ry version")
+  end
+end
+`)
+	return b.String(), nil
+}
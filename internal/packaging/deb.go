@@ -0,0 +1,161 @@
+package packaging
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// debArch maps Go architectures to Debian ones.
+var debArch = map[string]string{"amd64": "amd64", "arm64": "arm64"}
+
+// DebFileName is the conventional file name of a .deb.
+func DebFileName(info Info) (string, error) {
+	version, err := PackageVersion(info.Version)
+	if err != nil {
+		return "", err
+	}
+	arch, ok := debArch[info.Arch]
+	if !ok {
+		return "", fmt.Errorf("packaging: unsupported deb architecture %q", info.Arch)
+	}
+	return fmt.Sprintf("%s_%s_%s.deb", PackageName, version, arch), nil
+}
+
+// WriteDeb writes a Debian binary package installing files to w. A .deb is
+// an ar archive of debian-binary, control.tar.gz, and data.tar.gz; it is
+// built here directly so releases need no dpkg tooling.
+func WriteDeb(w io.Writer, info Info, files []File) error {
+	version, err := PackageVersion(info.Version)
+	if err != nil {
+		return err
+	}
+	arch, ok := debArch[info.Arch]
+	if !ok {
+		return fmt.Errorf("packaging: unsupported deb architecture %q", info.Arch)
+	}
+
+	data, err := debTarball(info.Date, func(tw *tar.Writer) error {
+		for _, d := range dirsOf(files) {
+			if err := writeTarEntry(tw, "."+d+"/", 0o755, nil, info.Date); err != nil {
+				return err
+			}
+		}
+		for _, f := range files {
+			if err := writeTarEntry(tw, "."+f.Path, f.Mode, f.Data, info.Date); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	var sums strings.Builder
+	for _, f := range files {
+		size += int64(len(f.Data))
+		fmt.Fprintf(&sums, "%x  %s\n", md5.Sum(f.Data), strings.TrimPrefix(f.Path, "/"))
+	}
+	var control strings.Builder
+	fmt.Fprintf(&control, "Package: %s\n", PackageName)
+	fmt.Fprintf(&control, "Version: %s\n", version)
+	fmt.Fprintf(&control, "Architecture: %s\n", arch)
+	fmt.Fprintf(&control, "Maintainer: %s\n", info.maintainer())
+	fmt.Fprintf(&control, "Installed-Size: %d\n", (size+1023)/1024)
+	control.WriteString("Section: devel\nPriority: optional\n")
+	fmt.Fprintf(&control, "Homepage: %s\n", Homepage)
+	fmt.Fprintf(&control, "Description: %s\n", Summary)
+	for _, line := range strings.Split(Description, "\n") {
+		fmt.Fprintf(&control, " %s\n", line)
+	}
+
+	ctrl, err := debTarball(info.Date, func(tw *tar.Writer) error {
+		if err := writeTarEntry(tw, "./control", 0o644, []byte(control.String()), info.Date); err != nil {
+			return err
+		}
+		return writeTarEntry(tw, "./md5sums", 0o644, []byte(sums.String()), info.Date)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+	for _, m := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", ctrl},
+		{"data.tar.gz", data},
+	} {
+		if err := writeArMember(w, m.name, m.data, info.Date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debTarball builds a gzipped tar from the entries fill writes.
+func debTarball(mtime time.Time, fill func(*tar.Writer) error) ([]byte, error) {
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	if err := fill(tw); err != nil {
+		return nil, fmt.Errorf("packaging: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("packaging: %w", err)
+	}
+	return gzipBytes(b.Bytes(), mtime)
+}
+
+// writeTarEntry adds a root-owned file, or a directory when name ends in a
+// slash.
+func writeTarEntry(tw *tar.Writer, name string, mode int64, data []byte, mtime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    mode,
+		Size:    int64(len(data)),
+		ModTime: mtime,
+		Uname:   "root",
+		Gname:   "root",
+		Format:  tar.FormatGNU,
+	}
+	if strings.HasSuffix(name, "/") {
+		hdr.Typeflag = tar.TypeDir
+	} else {
+		hdr.Typeflag = tar.TypeReg
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeArMember writes one member of a common-format ar archive: a 60-byte
+// header, the data, and a newline pad to an even offset.
+func writeArMember(w io.Writer, name string, data []byte, mtime time.Time) error {
+	hdr := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8o%-10d`\n", name, mtime.Unix(), 0, 0, 0o100644, len(data))
+	if len(hdr) != 60 {
+		return fmt.Errorf("packaging: ar header for %s is %d bytes", name, len(hdr))
+	}
+	if _, err := io.WriteString(w, hdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data)%2 == 1 {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
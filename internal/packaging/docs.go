@@ -0,0 +1,234 @@
+// Package packaging builds the artifacts operators install Railyard from:
+// man pages and shell completions generated from the ry command tree, and
+// deb, rpm, and Homebrew packages that carry them alongside the binary.
+package packaging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ManOptions fills in the header of every generated man page.
+type ManOptions struct {
+	Version string    // e.g. "v1.2.3"; shown as "Railyard v1.2.3"
+	Date    time.Time // page date; use SOURCE_DATE_EPOCH for reproducible builds
+}
+
+// ManPages renders a section 1 page for root and every command below it,
+// keyed by file name (e.g. "ry-car-create.1"). Hidden commands and help
+// topics are skipped.
+func ManPages(root *cobra.Command, opts ManOptions) map[string][]byte {
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+	pages := make(map[string][]byte)
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if !c.IsAvailableCommand() && c != root || c.IsAdditionalHelpTopicCommand() {
+			return
+		}
+		pages[manName(c)+".1"] = manPage(c, opts)
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	return pages
+}
+
+// manName is a command's page name: its path joined with dashes.
+func manName(c *cobra.Command) string {
+	return strings.ReplaceAll(c.CommandPath(), " ", "-")
+}
+
+func manPage(c *cobra.Command, opts ManOptions) []byte {
+	var b bytes.Buffer
+	source := "Railyard"
+	if opts.Version != "" {
+		source += " " + opts.Version
+	}
+	fmt.Fprintf(&b, ".TH \"%s\" \"1\" \"%s\" \"%s\" \"Railyard Manual\"\n",
+		strings.ToUpper(manName(c)), opts.Date.UTC().Format("Jan 2006"), source)
+	b.WriteString(".nh\n.ad l\n")
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(manName(c)), roffEscape(c.Short))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, "\\fB%s\\fP", roffEscape(c.CommandPath()))
+	if c.Runnable() {
+		if c.HasAvailableFlags() {
+			b.WriteString(" [flags]")
+		}
+		if _, args, ok := strings.Cut(c.Use, " "); ok {
+			if args = strings.TrimSpace(strings.ReplaceAll(args, "[flags]", "")); args != "" {
+				fmt.Fprintf(&b, " %s", roffEscape(args))
+			}
+		}
+	} else {
+		b.WriteString(" <command>")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(".SH DESCRIPTION\n")
+	desc := c.Long
+	if desc == "" {
+		desc = c.Short
+	}
+	writeRoffText(&b, desc)
+
+	writeManFlags(&b, "OPTIONS", c.NonInheritedFlags())
+	writeManFlags(&b, "OPTIONS INHERITED FROM PARENT COMMANDS", c.InheritedFlags())
+
+	if c.Example != "" {
+		b.WriteString(".SH EXAMPLE\n.nf\n")
+		for _, line := range strings.Split(strings.TrimRight(c.Example, "\n"), "\n") {
+			b.WriteString(roffLine(line) + "\n")
+		}
+		b.WriteString(".fi\n")
+	}
+
+	var related []string
+	if c.HasParent() {
+		related = append(related, manName(c.Parent()))
+	}
+	for _, sub := range c.Commands() {
+		if sub.IsAvailableCommand() && !sub.IsAdditionalHelpTopicCommand() {
+			related = append(related, manName(sub))
+		}
+	}
+	if len(related) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		for i, name := range related {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+			fmt.Fprintf(&b, "\\fB%s\\fP(1)", roffEscape(name))
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// writeManFlags lists the visible flags of fs under heading.
+func writeManFlags(b *bytes.Buffer, heading string, fs *pflag.FlagSet) {
+	var flags []*pflag.Flag
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden && f.Deprecated == "" {
+			flags = append(flags, f)
+		}
+	})
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(b, ".SH %s\n", heading)
+	for _, f := range flags {
+		b.WriteString(".TP\n")
+		if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+			fmt.Fprintf(b, "\\fB\\-%s\\fP, ", roffEscape(f.Shorthand))
+		}
+		fmt.Fprintf(b, "\\fB\\-\\-%s\\fP", roffEscape(f.Name))
+		if f.Value.Type() != "bool" {
+			def := f.DefValue
+			if def == "" || def == "[]" {
+				fmt.Fprintf(b, " \\fI%s\\fP", roffEscape(f.Value.Type()))
+			} else {
+				fmt.Fprintf(b, "=\\fI%s\\fP", roffEscape(def))
+			}
+		}
+		b.WriteString("\n")
+		b.WriteString(roffLine(f.Usage) + "\n")
+	}
+}
+
+// writeRoffText writes help text unfilled, so the line breaks and
+// indentation of lists and command examples survive as written.
+func writeRoffText(b *bytes.Buffer, text string) {
+	b.WriteString(".nf\n")
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString(roffLine(line) + "\n")
+	}
+	b.WriteString(".fi\n")
+}
+
+// roffLine escapes a line of text, guarding a leading control character.
+func roffLine(s string) string {
+	s = roffEscape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
+
+// roffEscape escapes backslashes and hyphens for troff.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
+
+// Completion script file names, by shell. They follow each shell's
+// convention for its completion directories.
+var completionFiles = map[string]string{
+	"bash": "ry.bash",
+	"zsh":  "_ry",
+	"fish": "ry.fish",
+}
+
+// Completions renders the bash, zsh, and fish completion scripts for root,
+// keyed by shell.
+func Completions(root *cobra.Command) (map[string][]byte, error) {
+	out := make(map[string][]byte)
+	for shell := range completionFiles {
+		var b bytes.Buffer
+		var err error
+		switch shell {
+		case "bash":
+			err = root.GenBashCompletionV2(&b, true)
+		case "zsh":
+			err = root.GenZshCompletion(&b)
+		case "fish":
+			err = root.GenFishCompletion(&b, true)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("packaging: %s completion: %w", shell, err)
+		}
+		out[shell] = b.Bytes()
+	}
+	return out, nil
+}
+
+// WriteDocs writes the man pages to dir/man and the completion scripts to
+// dir/completions, returning the files written.
+func WriteDocs(root *cobra.Command, dir string, opts ManOptions) ([]string, error) {
+	completions, err := Completions(root)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for name, data := range ManPages(root, opts) {
+		files[filepath.Join(dir, "man", name)] = data
+	}
+	for shell, data := range completions {
+		files[filepath.Join(dir, "completions", completionFiles[shell])] = data
+	}
+
+	var written []string
+	for path, data := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("packaging: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, fmt.Errorf("packaging: %w", err)
+		}
+		written = append(written, path)
+	}
+	sort.Strings(written)
+	return written, nil
+}
@@ -0,0 +1,134 @@
+package packaging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Package metadata shared by every format.
+const (
+	PackageName = "railyard"
+	Homepage    = "https://github.com/zulandar/railyard"
+	License     = "MIT"
+	Summary     = "Multi-agent AI orchestration for coding agents"
+	Description = "Railyard coordinates coding agents across local machines and cloud VMs:\n" +
+		"dispatch plans work into cars, engines implement them on their own\n" +
+		"branches, and the yardmaster tests and merges the results."
+	DefaultMaintainer = "Railyard maintainers <railyard@noreply>"
+)
+
+// Info describes one package build.
+type Info struct {
+	Version string    // release tag, e.g. "v1.2.3" or "v1.3.0-rc.1"
+	Arch    string    // Go architecture: amd64 or arm64
+	Date    time.Time // file modification times and man page dates
+
+	Maintainer string // "Name <email>"; DefaultMaintainer when empty
+}
+
+func (i Info) maintainer() string {
+	if i.Maintainer != "" {
+		return i.Maintainer
+	}
+	return DefaultMaintainer
+}
+
+// File is one file installed by a package.
+type File struct {
+	Path string // absolute install path, e.g. /usr/bin/ry
+	Mode int64
+	Data []byte
+}
+
+// Layout holds the install directories that differ between distributions.
+type Layout struct {
+	ZshCompletionDir string
+}
+
+// Distribution layouts. Debian's zsh reads vendor-completions; Fedora and
+// friends read site-functions.
+var (
+	DebLayout = Layout{ZshCompletionDir: "/usr/share/zsh/vendor-completions"}
+	RPMLayout = Layout{ZshCompletionDir: "/usr/share/zsh/site-functions"}
+)
+
+// Stage returns the files a system package installs: the binary, gzipped
+// man pages, and completion scripts generated from root, sorted by path.
+func Stage(root *cobra.Command, binary []byte, info Info, layout Layout) ([]File, error) {
+	files := []File{{Path: "/usr/bin/ry", Mode: 0o755, Data: binary}}
+	for name, page := range ManPages(root, ManOptions{Version: info.Version, Date: info.Date}) {
+		gz, err := gzipBytes(page, info.Date)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Path: "/usr/share/man/man1/" + name + ".gz", Mode: 0o644, Data: gz})
+	}
+	completions, err := Completions(root)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files,
+		File{Path: "/usr/share/bash-completion/completions/ry", Mode: 0o644, Data: completions["bash"]},
+		File{Path: path.Join(layout.ZshCompletionDir, "_ry"), Mode: 0o644, Data: completions["zsh"]},
+		File{Path: "/usr/share/fish/vendor_completions.d/ry.fish", Mode: 0o644, Data: completions["fish"]},
+	)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// gzipBytes compresses data with a fixed header, so the same input always
+// produces the same bytes.
+func gzipBytes(data []byte, mtime time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&b, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	zw.ModTime = mtime
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// PackageVersion converts a release tag to a deb/rpm version: the leading
+// "v" is dropped and a pre-release suffix is joined with "~" so it sorts
+// before the final release (1.3.0~rc.1 < 1.3.0).
+func PackageVersion(tag string) (string, error) {
+	v := strings.TrimPrefix(tag, "v")
+	if v == "" || v[0] < '0' || v[0] > '9' {
+		return "", fmt.Errorf("packaging: version %q must start with a digit (e.g. v1.2.3)", tag)
+	}
+	v = strings.Replace(v, "-", "~", 1)
+	if strings.ContainsAny(v, " _/-") {
+		return "", fmt.Errorf("packaging: version %q has characters packages do not allow", tag)
+	}
+	return v, nil
+}
+
+// dirsOf returns every parent directory of files, shortest first, excluding
+// the root.
+func dirsOf(files []File) []string {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for d := path.Dir(f.Path); d != "/" && !seen[d]; d = path.Dir(d) {
+			seen[d] = true
+		}
+	}
+	dirs := make([]string, 0, len(seen))
+	for d := range seen {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -0,0 +1,247 @@
+package packaging
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var testDate = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func testTree() *cobra.Command {
+	root := &cobra.Command{Use: "ry", Short: "Railyard — multi-agent AI orchestration"}
+	root.PersistentFlags().StringP("config", "c", "railyard.yaml", "path to Railyard config file")
+	car := &cobra.Command{Use: "car", Short: "Car management"}
+	create := &cobra.Command{
+		Use:     "create [flags]",
+		Short:   "Create a new car",
+		Long:    "Creates a car.\n\n.dot-leading line stays text",
+		Example: "  ry car create --title fix-login",
+		RunE:    func(*cobra.Command, []string) error { return nil },
+	}
+	create.Flags().String("title", "", "car title")
+	create.Flags().Bool("draft", false, "create as draft")
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: func(*cobra.Command, []string) {}}
+	car.AddCommand(create, hidden)
+	root.AddCommand(car)
+	return root
+}
+
+func TestManPages(t *testing.T) {
+	pages := ManPages(testTree(), ManOptions{Version: "v1.2.3", Date: testDate})
+	for _, name := range []string{"ry.1", "ry-car.1", "ry-car-create.1", "ry-completion.1"} {
+		if _, ok := pages[name]; !ok {
+			t.Errorf("missing page %s", name)
+		}
+	}
+	if _, ok := pages["ry-car-secret.1"]; ok {
+		t.Error("hidden command should have no page")
+	}
+	if _, ok := pages["ry-help.1"]; ok {
+		t.Error("help command should have no page")
+	}
+
+	page := string(pages["ry-car-create.1"])
+	for _, want := range []string{
+		`.TH "RY-CAR-CREATE" "1" "Mar 2026" "Railyard v1.2.3" "Railyard Manual"`,
+		`ry\-car\-create \- Create a new car`,
+		`\fBry car create\fP [flags]` + "\n",
+		`\&.dot\-leading line stays text`,
+		`\fB\-\-title\fP \fIstring\fP`,
+		`\fB\-c\fP, \fB\-\-config\fP=\fIrailyard.yaml\fP`,
+		".SH OPTIONS INHERITED FROM PARENT COMMANDS",
+		`ry car create \-\-title fix\-login`,
+		`\fBry\-car\fP(1)`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, `\fB\-\-draft\fP `) {
+		t.Errorf("bool flag should take no argument:\n%s", page)
+	}
+}
+
+func TestWriteDocs(t *testing.T) {
+	dir := t.TempDir()
+	files, err := WriteDocs(testTree(), dir, ManOptions{Date: testDate})
+	if err != nil {
+		t.Fatalf("WriteDocs: %v", err)
+	}
+	for _, rel := range []string{"man/ry-car-create.1", "completions/ry.bash", "completions/_ry", "completions/ry.fish"} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("missing %s: %v", rel, err)
+		}
+	}
+	if len(files) == 0 {
+		t.Error("WriteDocs returned no files")
+	}
+}
+
+func TestPackageVersion(t *testing.T) {
+	for tag, want := range map[string]string{
+		"v1.2.3":       "1.2.3",
+		"1.2.3":        "1.2.3",
+		"v1.3.0-rc.1":  "1.3.0~rc.1",
+		"v0.9.0-beta1": "0.9.0~beta1",
+	} {
+		got, err := PackageVersion(tag)
+		if err != nil || got != want {
+			t.Errorf("PackageVersion(%q) = %q, %v; want %q", tag, got, err, want)
+		}
+	}
+	for _, tag := range []string{"", "dev", "v", "v1.2.3 x", "v1.0-a-b"} {
+		if _, err := PackageVersion(tag); err == nil {
+			t.Errorf("PackageVersion(%q) should fail", tag)
+		}
+	}
+}
+
+func TestStage(t *testing.T) {
+	info := Info{Version: "v1.2.3", Arch: "amd64", Date: testDate}
+	files, err := Stage(testTree(), []byte("binary"), info, RPMLayout)
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	paths := make(map[string]File)
+	for _, f := range files {
+		paths[f.Path] = f
+	}
+	for _, p := range []string{
+		"/usr/bin/ry",
+		"/usr/share/man/man1/ry-car-create.1.gz",
+		"/usr/share/bash-completion/completions/ry",
+		"/usr/share/zsh/site-functions/_ry",
+		"/usr/share/fish/vendor_completions.d/ry.fish",
+	} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("missing %s", p)
+		}
+	}
+	if paths["/usr/bin/ry"].Mode != 0o755 {
+		t.Errorf("binary mode = %o, want 755", paths["/usr/bin/ry"].Mode)
+	}
+}
+
+func TestWriteDeb(t *testing.T) {
+	info := Info{Version: "v1.3.0-rc.1", Arch: "arm64", Date: testDate}
+	files, err := Stage(testTree(), []byte("#!/bin/sh\necho ry\n"), info, DebLayout)
+	if err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	var a, b bytes.Buffer
+	if err := WriteDeb(&a, info, files); err != nil {
+		t.Fatalf("WriteDeb: %v", err)
+	}
+	if err := WriteDeb(&b, info, files); err != nil {
+		t.Fatalf("WriteDeb: %v", err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("WriteDeb is not reproducible")
+	}
+	if !bytes.HasPrefix(a.Bytes(), []byte("!<arch>\ndebian-binary   ")) {
+		t.Fatalf("not an ar archive: %q", a.Bytes()[:24])
+	}
+	name, err := DebFileName(info)
+	if err != nil || name != "railyard_1.3.0~rc.1_arm64.deb" {
+		t.Errorf("DebFileName = %q, %v", name, err)
+	}
+
+	if _, err := exec.LookPath("dpkg-deb"); err != nil {
+		t.Skip("dpkg-deb not installed")
+	}
+	deb := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(deb, a.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("dpkg-deb", "--info", deb).CombinedOutput()
+	if err != nil {
+		t.Fatalf("dpkg-deb --info: %v\n%s", err, out)
+	}
+	for _, want := range []string{"Package: railyard", "Version: 1.3.0~rc.1", "Architecture: arm64"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("control missing %q:\n%s", want, out)
+		}
+	}
+	out, err = exec.Command("dpkg-deb", "--contents", deb).CombinedOutput()
+	if err != nil {
+		t.Fatalf("dpkg-deb --contents: %v\n%s", err, out)
+	}
+	for _, want := range []string{"./usr/bin/ry", "./usr/share/zsh/vendor-completions/_ry", "./usr/share/man/man1/ry-car.1.gz"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("contents missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRPMSpec(t *testing.T) {
+	info := Info{Version: "v1.2.3", Arch: "arm64", Date: testDate}
+	files := []File{
+		{Path: "/usr/bin/ry", Mode: 0o755},
+		{Path: "/usr/share/man/man1/ry.1.gz", Mode: 0o644},
+	}
+	spec, err := RPMSpec(info, files)
+	if err != nil {
+		t.Fatalf("RPMSpec: %v", err)
+	}
+	for _, want := range []string{
+		"Name: railyard\n",
+		"Version: 1.2.3\n",
+		"License: MIT\n",
+		"mkdir -p %{buildroot}/usr/share/man/man1\n",
+		"install -m 755 %{staging}/usr/bin/ry %{buildroot}/usr/bin/ry\n",
+		"%files\n/usr/bin/ry\n/usr/share/man/man1/ry.1.gz\n",
+	} {
+		if !strings.Contains(spec, want) {
+			t.Errorf("spec missing %q:\n%s", want, spec)
+		}
+	}
+	name, err := RPMFileName(info)
+	if err != nil || name != "railyard-1.2.3-1.aarch64.rpm" {
+		t.Errorf("RPMFileName = %q, %v", name, err)
+	}
+	if _, err := RPMSpec(Info{Version: "v1.2.3", Arch: "386"}, files); err == nil {
+		t.Error("RPMSpec should reject unsupported architectures")
+	}
+}
+
+func TestBrewFormula(t *testing.T) {
+	sums, err := ParseChecksums([]byte(
+		strings.Repeat("a", 64) + "  ry-v1.2.3-darwin-arm64.tar.gz\n" +
+			strings.Repeat("b", 64) + "  ry-v1.2.3-linux-amd64.tar.gz\n"))
+	if err != nil {
+		t.Fatalf("ParseChecksums: %v", err)
+	}
+	formula, err := BrewFormula("v1.2.3", "https://example.com/dl/", sums)
+	if err != nil {
+		t.Fatalf("BrewFormula: %v", err)
+	}
+	for _, want := range []string{
+		"class Railyard < Formula\n",
+		`version "1.2.3"`,
+		"  on_macos do\n    on_arm do\n      url \"https://example.com/dl/ry-v1.2.3-darwin-arm64.tar.gz\"\n      sha256 \"" + strings.Repeat("a", 64) + "\"\n",
+		"  on_linux do\n    on_intel do\n",
+		`system bin/"ry", "gen", "docs", "--out", buildpath/"gen"`,
+		`man1.install Dir["gen/man/*.1"]`,
+	} {
+		if !strings.Contains(formula, want) {
+			t.Errorf("formula missing %q:\n%s", want, formula)
+		}
+	}
+	if strings.Contains(formula, "darwin-amd64") {
+		t.Errorf("formula lists a platform without a checksum:\n%s", formula)
+	}
+
+	if _, err := BrewFormula("v9.9.9", "https://example.com", sums); err == nil {
+		t.Error("BrewFormula should fail when no tarball matches the tag")
+	}
+	if _, err := ParseChecksums([]byte("nothex  file\n")); err == nil {
+		t.Error("ParseChecksums should reject malformed lines")
+	}
+}
@@ -0,0 +1,126 @@
+package packaging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// rpmArch maps Go architectures to RPM ones.
+var rpmArch = map[string]string{"amd64": "x86_64", "arm64": "aarch64"}
+
+// RPMSpec returns a spec file that installs files from a staging directory
+// (passed to rpmbuild as the "staging" macro) without compiling anything.
+func RPMSpec(info Info, files []File) (string, error) {
+	version, err := PackageVersion(info.Version)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := rpmArch[info.Arch]; !ok {
+		return "", fmt.Errorf("packaging: unsupported rpm architecture %q", info.Arch)
+	}
+
+	var b strings.Builder
+	// The binary is already stripped and built by go; skip debuginfo
+	// extraction and the brp post-install scripts that would rewrite it.
+	b.WriteString("%global debug_package %{nil}\n")
+	b.WriteString("%global __os_install_post %{nil}\n\n")
+	fmt.Fprintf(&b, "Name: %s\n", PackageName)
+	fmt.Fprintf(&b, "Version: %s\n", version)
+	b.WriteString("Release: 1\n")
+	fmt.Fprintf(&b, "Summary: %s\n", Summary)
+	fmt.Fprintf(&b, "License: %s\n", License)
+	fmt.Fprintf(&b, "URL: %s\n", Homepage)
+	fmt.Fprintf(&b, "Packager: %s\n", info.maintainer())
+	b.WriteString("\n%description\n")
+	b.WriteString(Description + "\n")
+	b.WriteString("\n%install\n")
+	for _, d := range dirsOf(files) {
+		fmt.Fprintf(&b, "mkdir -p %%{buildroot}%s\n", d)
+	}
+	for _, f := range files {
+		fmt.Fprintf(&b, "install -m %o %%{staging}%s %%{buildroot}%s\n", f.Mode, f.Path, f.Path)
+	}
+	b.WriteString("\n%files\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "%s\n", f.Path)
+	}
+	return b.String(), nil
+}
+
+// RPMFileName is the file name rpmbuild gives the package.
+func RPMFileName(info Info) (string, error) {
+	version, err := PackageVersion(info.Version)
+	if err != nil {
+		return "", err
+	}
+	arch, ok := rpmArch[info.Arch]
+	if !ok {
+		return "", fmt.Errorf("packaging: unsupported rpm architecture %q", info.Arch)
+	}
+	return fmt.Sprintf("%s-%s-1.%s.rpm", PackageName, version, arch), nil
+}
+
+// BuildRPM stages files, runs rpmbuild, and copies the package to outDir,
+// returning its path. rpmbuild must be on PATH (the rpm package on Debian
+// and Ubuntu provides it).
+func BuildRPM(info Info, files []File, outDir string) (string, error) {
+	rpmbuild, err := exec.LookPath("rpmbuild")
+	if err != nil {
+		return "", fmt.Errorf("packaging: rpmbuild not found; install the rpm package to build rpms")
+	}
+	spec, err := RPMSpec(info, files)
+	if err != nil {
+		return "", err
+	}
+	name, err := RPMFileName(info)
+	if err != nil {
+		return "", err
+	}
+
+	work, err := os.MkdirTemp("", "railyard-rpm-")
+	if err != nil {
+		return "", fmt.Errorf("packaging: %w", err)
+	}
+	defer os.RemoveAll(work)
+	staging := filepath.Join(work, "staging")
+	for _, f := range files {
+		p := filepath.Join(staging, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return "", fmt.Errorf("packaging: %w", err)
+		}
+		if err := os.WriteFile(p, f.Data, os.FileMode(f.Mode)); err != nil {
+			return "", fmt.Errorf("packaging: %w", err)
+		}
+	}
+	specPath := filepath.Join(work, PackageName+".spec")
+	if err := os.WriteFile(specPath, []byte(spec), 0o644); err != nil {
+		return "", fmt.Errorf("packaging: %w", err)
+	}
+
+	cmd := exec.Command(rpmbuild, "-bb",
+		"--target", rpmArch[info.Arch],
+		"--define", "_topdir "+filepath.Join(work, "rpmbuild"),
+		"--define", "staging "+staging,
+		specPath)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("packaging: rpmbuild: %w\n%s", err, output.String())
+	}
+
+	built := filepath.Join(work, "rpmbuild", "RPMS", rpmArch[info.Arch], name)
+	data, err := os.ReadFile(built)
+	if err != nil {
+		return "", fmt.Errorf("packaging: rpmbuild did not produce %s: %w", name, err)
+	}
+	dest := filepath.Join(outDir, name)
+	if err := os.WriteFile(dest, data, 0o644); err != nil {
+		return "", fmt.Errorf("packaging: %w", err)
+	}
+	return dest, nil
+}
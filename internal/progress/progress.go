@@ -0,0 +1,105 @@
+// Package progress reports the steps of long-running operations (start,
+// scale, switch) to whoever is driving them. Operations accept an optional
+// Func and call it as they go; the CLI either ignores the steps or, with
+// --porcelain, writes them to stdout as line-delimited JSON via Writer.
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// Event is one step of an operation. Current and Total are set when the
+// step is one of a known number of like steps (e.g. engine 2 of 5), so a
+// wrapper can draw a progress bar; both are zero otherwise.
+type Event struct {
+	Step    string
+	Detail  string
+	Current int
+	Total   int
+}
+
+// Func receives progress events. A nil Func discards them.
+type Func func(Event)
+
+// Report calls f with e if f is non-nil.
+func (f Func) Report(e Event) {
+	if f != nil {
+		f(e)
+	}
+}
+
+// Porcelain event kinds. The JSON schema is a stable contract for wrappers:
+// fields may be added, never renamed or removed.
+const (
+	KindStep  = "step"  // an intermediate step
+	KindDone  = "done"  // the operation succeeded; Result carries its outcome
+	KindError = "error" // the operation failed; Error carries the message
+)
+
+// Line is the JSON object written per event in porcelain mode.
+type Line struct {
+	Op      string      `json:"op"`
+	Kind    string      `json:"event"`
+	Time    time.Time   `json:"time"`
+	Step    string      `json:"step,omitempty"`
+	Detail  string      `json:"detail,omitempty"`
+	Current int         `json:"current,omitempty"`
+	Total   int         `json:"total,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Writer encodes an operation's events as line-delimited JSON. It is safe
+// for concurrent use, since some operations report from worker goroutines.
+// A nil *Writer discards everything, so commands can hold one whether or not
+// --porcelain was given.
+type Writer struct {
+	op    string
+	clock clock.Clock
+	mu    sync.Mutex
+	enc   *json.Encoder
+}
+
+// NewWriter returns a Writer that tags every line with op.
+func NewWriter(w io.Writer, op string, c clock.Clock) *Writer {
+	return &Writer{op: op, clock: clock.OrReal(c), enc: json.NewEncoder(w)}
+}
+
+// Func returns w.Step as a Func, or nil for a nil Writer.
+func (w *Writer) Func() Func {
+	if w == nil {
+		return nil
+	}
+	return w.Step
+}
+
+// Step writes e as a step event.
+func (w *Writer) Step(e Event) {
+	w.write(Line{Kind: KindStep, Step: e.Step, Detail: e.Detail, Current: e.Current, Total: e.Total})
+}
+
+// Done writes the terminal success event carrying result.
+func (w *Writer) Done(result interface{}) {
+	w.write(Line{Kind: KindDone, Result: result})
+}
+
+// Fail writes the terminal failure event for err.
+func (w *Writer) Fail(err error) {
+	w.write(Line{Kind: KindError, Error: err.Error()})
+}
+
+func (w *Writer) write(l Line) {
+	if w == nil {
+		return
+	}
+	l.Op = w.op
+	l.Time = w.clock.Now().UTC()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.enc.Encode(l)
+}
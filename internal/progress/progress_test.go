@@ -0,0 +1,67 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+func TestFuncReport_NilIsNoop(t *testing.T) {
+	var f Func
+	f.Report(Event{Step: "anything"}) // must not panic
+
+	var got []Event
+	f = func(e Event) { got = append(got, e) }
+	f.Report(Event{Step: "session", Current: 1, Total: 2})
+	if len(got) != 1 || got[0].Step != "session" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestWriter_LineDelimitedJSON(t *testing.T) {
+	var buf bytes.Buffer
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	w := NewWriter(&buf, "scale", clock.NewFake(at))
+
+	w.Step(Event{Step: "engine", Detail: "railyard_me_eng003", Current: 1, Total: 2})
+	w.Done(map[string]int{"current": 3})
+	w.Fail(errors.New("tmux: no server"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+
+	var step Line
+	if err := json.Unmarshal([]byte(lines[0]), &step); err != nil {
+		t.Fatalf("unmarshal step: %v", err)
+	}
+	if step.Op != "scale" || step.Kind != KindStep || step.Step != "engine" ||
+		step.Detail != "railyard_me_eng003" || step.Current != 1 || step.Total != 2 || !step.Time.Equal(at) {
+		t.Errorf("step line = %+v", step)
+	}
+	if !strings.Contains(lines[1], `"event":"done"`) || !strings.Contains(lines[1], `"result":{"current":3}`) {
+		t.Errorf("done line = %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"event":"error"`) || !strings.Contains(lines[2], `"error":"tmux: no server"`) {
+		t.Errorf("error line = %s", lines[2])
+	}
+	if strings.Contains(lines[1], `"step"`) {
+		t.Errorf("done line should omit empty step: %s", lines[1])
+	}
+}
+
+func TestWriter_NilDiscards(t *testing.T) {
+	var w *Writer
+	if w.Func() != nil {
+		t.Error("nil Writer Func() should be nil")
+	}
+	w.Step(Event{Step: "x"})
+	w.Done(nil)
+	w.Fail(errors.New("boom"))
+}
@@ -0,0 +1,176 @@
+// Package retention removes operational data older than the windows in the
+// config's retention section — events, dispatch conversation turns, agent
+// pane output, and merge-gate attempt notes — so a busy yard's database
+// does not grow without bound.
+package retention
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// Interval is how often the Janitor applies the policy.
+const Interval = 24 * time.Hour
+
+// batchSize caps the rows one statement touches, so a first run on a large
+// table does not hold locks for long.
+const batchSize = 1000
+
+// Kind names a class of data with its own retention window.
+type Kind string
+
+const (
+	KindEvents        Kind = "events"
+	KindConversations Kind = "conversations"
+	KindAgentLogs     Kind = "agent_logs"
+	KindMergeAttempts Kind = "merge_attempts"
+)
+
+// Result reports one kind's pass: the rows older than Cutoff that were
+// removed, or on a dry run would have been.
+type Result struct {
+	Kind   Kind
+	Cutoff time.Time
+	Rows   int64
+}
+
+// String renders the result for logs and CLI output, e.g.
+// "events: 1200 rows older than 2026-01-01".
+func (r Result) String() string {
+	return fmt.Sprintf("%s: %d rows older than %s", r.Kind, r.Rows, r.Cutoff.Local().Format("2006-01-02"))
+}
+
+// kind describes how to find and remove one kind's expired rows.
+type kind struct {
+	kind   Kind
+	days   func(config.RetentionConfig) int
+	model  any
+	expire func(db *gorm.DB, cutoff time.Time) *gorm.DB // scope of expired rows
+	// clear, when set, empties the bulky columns of expired rows instead of
+	// deleting them.
+	clear map[string]any
+}
+
+var kinds = []kind{
+	{
+		kind:  KindEvents,
+		days:  func(r config.RetentionConfig) int { return r.EventsDays },
+		model: &models.Event{},
+		expire: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+			return db.Model(&models.Event{}).Where("created_at < ?", cutoff)
+		},
+	},
+	{
+		// Only sessions that have finished lose their turns; an active
+		// session still needs them to resume.
+		kind:  KindConversations,
+		days:  func(r config.RetentionConfig) int { return r.ConversationsDays },
+		model: &models.TelegraphConversation{},
+		expire: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+			finished := db.Model(&models.DispatchSession{}).Select("id").
+				Where("status <> ? AND COALESCE(completed_at, created_at) < ?", "active", cutoff)
+			return db.Model(&models.TelegraphConversation{}).Where("created_at < ? AND session_id IN (?)", cutoff, finished)
+		},
+	},
+	{
+		// Agent log rows also carry token counts that cost and usage
+		// reports read, so only their content is dropped.
+		kind:  KindAgentLogs,
+		days:  func(r config.RetentionConfig) int { return r.AgentLogsDays },
+		model: &models.AgentLog{},
+		expire: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+			return db.Model(&models.AgentLog{}).Where("created_at < ? AND content <> ?", cutoff, "")
+		},
+		clear: map[string]any{"content": ""},
+	},
+	{
+		kind:  KindMergeAttempts,
+		days:  func(r config.RetentionConfig) int { return r.MergeAttemptsDays },
+		model: &models.CarProgress{},
+		expire: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+			return db.Model(&models.CarProgress{}).
+				Where("created_at < ? AND engine_id = ? AND note LIKE ?", cutoff, "yardmaster", "switch:%")
+		},
+	},
+}
+
+// Apply removes every kind's rows older than its window and returns one
+// Result per kind with a positive window, in a fixed order. With dryRun it
+// only counts them. It stops at the first error, returning the results so
+// far.
+func Apply(db *gorm.DB, cfg config.RetentionConfig, now time.Time, dryRun bool) ([]Result, error) {
+	if db == nil {
+		return nil, fmt.Errorf("retention: db is required")
+	}
+	var results []Result
+	for _, k := range kinds {
+		days := k.days(cfg)
+		if days <= 0 {
+			continue
+		}
+		res := Result{Kind: k.kind, Cutoff: now.AddDate(0, 0, -days)}
+		var err error
+		if dryRun {
+			err = k.expire(db, res.Cutoff).Count(&res.Rows).Error
+		} else {
+			res.Rows, err = k.remove(db, res.Cutoff)
+		}
+		if err != nil {
+			return results, fmt.Errorf("retention: %s: %w", k.kind, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// remove deletes (or clears) the expired rows in batches of batchSize.
+func (k kind) remove(db *gorm.DB, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []uint
+		if err := k.expire(db, cutoff).Order("id").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		var res *gorm.DB
+		if k.clear != nil {
+			res = db.Model(k.model).Where("id IN ?", ids).Updates(k.clear)
+		} else {
+			res = db.Where("id IN ?", ids).Delete(k.model)
+		}
+		if res.Error != nil {
+			return total, res.Error
+		}
+		total += res.RowsAffected
+		if len(ids) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// Janitor applies the retention policy once per Interval. Call Tick on each
+// yardmaster cycle.
+type Janitor struct {
+	DB     *gorm.DB
+	Config config.RetentionConfig
+
+	last time.Time
+}
+
+// Tick applies the policy when retention is enabled and Interval has passed
+// since the last run; the first call runs at once. ran reports whether it
+// did anything.
+func (j *Janitor) Tick(now time.Time) (results []Result, ran bool, err error) {
+	if !j.Config.Enabled || (!j.last.IsZero() && now.Sub(j.last) < Interval) {
+		return nil, false, nil
+	}
+	j.last = now
+	results, err = Apply(j.DB, j.Config, now, j.Config.DryRun)
+	return results, true, err
+}
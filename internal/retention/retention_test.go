@@ -0,0 +1,131 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Event{}, &models.DispatchSession{}, &models.TelegraphConversation{},
+		&models.AgentLog{}, &models.CarProgress{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+var policy = config.RetentionConfig{EventsDays: 90, ConversationsDays: 30, AgentLogsDays: 7, MergeAttemptsDays: 180}
+
+func seed(t *testing.T, db *gorm.DB, now time.Time) {
+	t.Helper()
+	daysAgo := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	db.Create(&models.Event{Kind: "CarMerged", CreatedAt: daysAgo(100)})
+	db.Create(&models.Event{Kind: "CarMerged", CreatedAt: daysAgo(10)})
+
+	done := daysAgo(40)
+	db.Create(&models.DispatchSession{ID: 1, Source: "telegraph", UserName: "a", Status: "completed", CreatedAt: daysAgo(41), CompletedAt: &done})
+	db.Create(&models.DispatchSession{ID: 2, Source: "telegraph", UserName: "b", Status: "active", CreatedAt: daysAgo(60)})
+	db.Create(&models.TelegraphConversation{SessionID: 1, Sequence: 1, Role: "user", Content: "old", CreatedAt: daysAgo(41)})
+	db.Create(&models.TelegraphConversation{SessionID: 2, Sequence: 1, Role: "user", Content: "still active", CreatedAt: daysAgo(60)})
+
+	db.Create(&models.AgentLog{EngineID: "eng-1", Content: "pane output", InputTokens: 500, CreatedAt: daysAgo(8)})
+	db.Create(&models.AgentLog{EngineID: "eng-1", Content: "recent output", CreatedAt: daysAgo(1)})
+
+	db.Create(&models.CarProgress{CarID: "car-1", EngineID: "yardmaster", Note: "switch:test: failed", CreatedAt: daysAgo(200)})
+	db.Create(&models.CarProgress{CarID: "car-1", EngineID: "yardmaster", Note: "Auto-rebased branch onto main", CreatedAt: daysAgo(200)})
+	db.Create(&models.CarProgress{CarID: "car-1", EngineID: "eng-1", Note: "switch: my own words", CreatedAt: daysAgo(200)})
+}
+
+func TestApply(t *testing.T) {
+	db := testDB(t)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	seed(t, db, now)
+
+	dry, err := Apply(db, policy, now, true)
+	if err != nil {
+		t.Fatalf("Apply dry run: %v", err)
+	}
+	results, err := Apply(db, policy, now, false)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	for _, rs := range [][]Result{dry, results} {
+		if len(rs) != 4 {
+			t.Fatalf("results = %v, want 4 kinds", rs)
+		}
+		for _, r := range rs {
+			if r.Rows != 1 {
+				t.Errorf("%s", r)
+			}
+		}
+	}
+
+	var events, turns, progress int64
+	db.Model(&models.Event{}).Count(&events)
+	db.Model(&models.TelegraphConversation{}).Count(&turns)
+	db.Model(&models.CarProgress{}).Count(&progress)
+	if events != 1 || turns != 1 || progress != 2 {
+		t.Errorf("left events=%d turns=%d progress=%d, want 1, 1, 2", events, turns, progress)
+	}
+	var old models.AgentLog
+	db.Order("id").First(&old)
+	if old.Content != "" || old.InputTokens != 500 {
+		t.Errorf("expired agent log = content %q tokens %d, want content cleared and tokens kept", old.Content, old.InputTokens)
+	}
+
+	again, err := Apply(db, policy, now, false)
+	if err != nil {
+		t.Fatalf("second Apply: %v", err)
+	}
+	for _, r := range again {
+		if r.Rows != 0 {
+			t.Errorf("second pass %s, want nothing left", r)
+		}
+	}
+}
+
+func TestApply_DryRunKeepsRows(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	seed(t, db, now)
+	if _, err := Apply(db, policy, now, true); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	var events int64
+	db.Model(&models.Event{}).Count(&events)
+	if events != 2 {
+		t.Errorf("dry run deleted events: %d left", events)
+	}
+}
+
+func TestJanitorTick(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	j := &Janitor{DB: db, Config: policy}
+	if _, ran, _ := j.Tick(now); ran {
+		t.Fatal("disabled janitor ran")
+	}
+	j.Config.Enabled = true
+	if _, ran, err := j.Tick(now); !ran || err != nil {
+		t.Fatalf("first tick ran=%v err=%v, want a run", ran, err)
+	}
+	if _, ran, _ := j.Tick(now.Add(time.Hour)); ran {
+		t.Error("janitor ran again within the interval")
+	}
+	if _, ran, _ := j.Tick(now.Add(Interval)); !ran {
+		t.Error("janitor did not run after the interval")
+	}
+}
@@ -3,6 +3,7 @@ package telegraph
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -38,6 +39,16 @@ type InboundMessage struct {
 	UserName  string    // human-readable username
 	Text      string    // raw message text
 	Timestamp time.Time // when the message was sent
+	Files     []InboundFile
+}
+
+// InboundFile is a file uploaded with an inbound message.
+type InboundFile struct {
+	ID       string // platform-specific file identifier
+	Name     string
+	MimeType string
+	Size     int64  // bytes as reported by the platform; 0 if unknown
+	URL      string // download URL; may require the bot's credentials
 }
 
 // OutboundMessage represents a message to be sent to the chat platform.
@@ -92,8 +103,43 @@ type ThreadStarter interface {
 	StartThread(ctx context.Context, channelID, messageID, replyText, threadName string) (threadID string, err error)
 }
 
+// DirectMessenger is an optional interface that adapters can implement to
+// send a private message to a single user. The daemon uses it to deliver
+// per-user `!ry notify` subscriptions.
+type DirectMessenger interface {
+	// SendDirect delivers msg to userID's direct-message conversation with
+	// the bot. msg.ChannelID and msg.ThreadID are ignored.
+	SendDirect(ctx context.Context, userID string, msg OutboundMessage) error
+}
+
+// MessageEditor is an optional interface that adapters can implement to
+// replace the text of a message they posted earlier (IDs as returned by
+// MessagePoster.Post). The dispatch relay uses it to grow one message as
+// agent output streams in instead of posting a new message every flush.
+type MessageEditor interface {
+	EditMessage(ctx context.Context, channelID, threadID, messageID, text string) error
+}
+
+// FileDownloader is an optional interface that adapters can implement to
+// fetch files users upload in a dispatch thread. The router stores them as
+// attachments that Dispatch can link to the cars it creates.
+type FileDownloader interface {
+	DownloadFile(ctx context.Context, f InboundFile, w io.Writer) error
+}
+
+// ThreadHistorySincer is an optional interface that adapters can implement
+// to fetch only the part of a thread posted after a known message. The
+// thread history cache uses it to refresh incrementally instead of reading
+// the whole thread again.
+type ThreadHistorySincer interface {
+	// ThreadHistorySince returns up to limit messages posted after the
+	// message with ID afterMessageID, oldest first.
+	ThreadHistorySince(ctx context.Context, channelID, threadID, afterMessageID string, limit int) ([]ThreadMessage, error)
+}
+
 // ThreadMessage represents a single message within a thread history.
 type ThreadMessage struct {
+	MessageID string // platform-specific message ID (Slack: ts, Discord: snowflake)
 	UserID    string
 	UserName  string
 	Text      string
@@ -0,0 +1,180 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"gorm.io/gorm"
+)
+
+// AskSystemPrompt is the system prompt for `!ry ask` agent sessions.
+const AskSystemPrompt = `You answer questions about a Railyard yard from chat.
+The user's message contains a question followed by a snapshot of the yard:
+overall status and every open car with its dependencies.
+
+Rules:
+- You are read-only. Never create, update, claim, or cancel cars, and never
+  modify files or run commands that change state.
+- Answer from the snapshot first; read files in the repository only when the
+  question is about code.
+- Be concise: a short paragraph or a few bullets, suitable for a chat thread.
+- Cite car IDs (e.g. car-a1b2c) for every car you mention.
+- If the snapshot does not answer the question, say so plainly.`
+
+const (
+	// defaultAskTimeout bounds one `!ry ask` agent session.
+	defaultAskTimeout = 3 * time.Minute
+	// askSnapshotLimit caps the cars listed in the yard snapshot.
+	askSnapshotLimit = 200
+)
+
+// Asker answers ad-hoc questions about the yard with a one-shot, read-only
+// agent session. Unlike dispatch it never takes the dispatch lock and keeps
+// no session history: each question is answered from a fresh yard snapshot.
+type Asker struct {
+	db      *gorm.DB
+	spawner ProcessSpawner
+	status  StatusProvider
+	timeout time.Duration
+}
+
+// AskerOpts holds parameters for creating an Asker.
+type AskerOpts struct {
+	DB             *gorm.DB
+	Spawner        ProcessSpawner // should be configured read-only (see ClaudeSpawner.ReadOnly)
+	StatusProvider StatusProvider // defaults to orchestration.Status()
+	Timeout        time.Duration  // defaults to 3 minutes
+}
+
+// NewAsker creates an Asker.
+func NewAsker(opts AskerOpts) (*Asker, error) {
+	if opts.DB == nil {
+		return nil, fmt.Errorf("telegraph: asker: db is required")
+	}
+	if opts.Spawner == nil {
+		return nil, fmt.Errorf("telegraph: asker: spawner is required")
+	}
+	sp := opts.StatusProvider
+	if sp == nil {
+		sp = &defaultStatusProvider{db: opts.DB, tmux: nil}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultAskTimeout
+	}
+	return &Asker{
+		db:      opts.DB,
+		spawner: opts.Spawner,
+		status:  sp,
+		timeout: timeout,
+	}, nil
+}
+
+// Ask runs one agent session for question and returns its answer.
+func (a *Asker) Ask(ctx context.Context, question string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	proc, err := a.spawner.Spawn(ctx, a.prompt(question))
+	if err != nil {
+		return "", fmt.Errorf("telegraph: ask: spawn: %w", err)
+	}
+	defer proc.Close()
+
+	var lines []string
+	recv := proc.Recv()
+	for recv != nil {
+		select {
+		case line, ok := <-recv:
+			if !ok {
+				recv = nil
+				break
+			}
+			lines = append(lines, line)
+		case <-ctx.Done():
+			return "", fmt.Errorf("telegraph: ask: %w", ctx.Err())
+		}
+	}
+
+	answer := strings.TrimSpace(strings.Join(lines, "\n"))
+	if answer == "" {
+		if exitErr := waitProcessExit(proc); exitErr != nil {
+			return "", fmt.Errorf("telegraph: ask: agent exited without an answer: %w", exitErr)
+		}
+		return "", fmt.Errorf("telegraph: ask: agent returned no answer")
+	}
+	return answer, nil
+}
+
+// prompt combines question with a snapshot of the yard.
+func (a *Asker) prompt(question string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\n", question)
+	b.WriteString("## Yard status\n")
+	if info, err := a.status.Status(); err != nil {
+		fmt.Fprintf(&b, "(status unavailable: %v)\n", err)
+	} else {
+		b.WriteString(orchestration.FormatStatus(info))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n## Open cars\n")
+	b.WriteString(a.carSnapshot())
+	return b.String()
+}
+
+// carSnapshot lists every car that is not in a terminal status, one per line,
+// with the cars it waits on.
+func (a *Asker) carSnapshot() string {
+	var cars []models.Car
+	if err := a.db.Where("status NOT IN ?", models.TerminalStatuses).
+		Order("priority, id").Limit(askSnapshotLimit + 1).Find(&cars).Error; err != nil {
+		return fmt.Sprintf("(cars unavailable: %v)\n", err)
+	}
+	if len(cars) == 0 {
+		return "(none)\n"
+	}
+	truncated := len(cars) > askSnapshotLimit
+	if truncated {
+		cars = cars[:askSnapshotLimit]
+	}
+
+	ids := make([]string, len(cars))
+	for i, c := range cars {
+		ids[i] = c.ID
+	}
+	var deps []models.CarDep
+	a.db.Preload("Blocker").Where("car_id IN ?", ids).Order("car_id, blocked_by").Find(&deps)
+	waitsOn := make(map[string][]string)
+	for _, d := range deps {
+		waitsOn[d.CarID] = append(waitsOn[d.CarID], fmt.Sprintf("%s (%s)", d.BlockedBy, d.Blocker.Status))
+	}
+
+	var b strings.Builder
+	for _, c := range cars {
+		fmt.Fprintf(&b, "- %s [%s, %s, P%d, track %s] %s", c.ID, c.Type, c.Status, c.Priority, c.Track, c.Title)
+		if c.ParentID != nil {
+			fmt.Fprintf(&b, "; parent %s", *c.ParentID)
+		}
+		if c.Owner != "" {
+			fmt.Fprintf(&b, "; owner %s", c.Owner)
+		}
+		if c.Assignee != "" {
+			fmt.Fprintf(&b, "; engine %s", c.Assignee)
+		}
+		if c.BlockedReason != "" {
+			fmt.Fprintf(&b, "; blocked: %s", c.BlockedReason)
+		}
+		if w := waitsOn[c.ID]; len(w) > 0 {
+			fmt.Fprintf(&b, "; waits on %s", strings.Join(w, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if truncated {
+		fmt.Fprintf(&b, "(list truncated at %d cars)\n", askSnapshotLimit)
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func answerWith(t *testing.T, spawner *mockSpawner, lines ...string) *mockProcess {
+	t.Helper()
+	waitFor(t, func() bool { return spawner.lastProcess() != nil }, 2*time.Second)
+	p := spawner.lastProcess()
+	for _, l := range lines {
+		p.recvCh <- l
+	}
+	close(p.recvCh)
+	p.exitWith(nil)
+	return p
+}
+
+func TestAsker_Ask(t *testing.T) {
+	db := openRouterTestDB(t)
+	epic := "car-epic"
+	db.Create(&models.Car{ID: epic, Title: "Payments", Type: "epic", Status: "open", Track: "backend"})
+	db.Create(&models.Car{ID: "car-b", Title: "Charge cards", Status: "blocked", Track: "backend", ParentID: &epic, Owner: "alice"})
+	db.Create(&models.Car{ID: "car-c", Title: "Stripe keys", Status: "in_progress", Track: "backend", Assignee: "eng-1"})
+	db.Create(&models.Car{ID: "car-old", Title: "Shipped", Status: "merged"})
+	db.Create(&models.CarDep{CarID: "car-b", BlockedBy: "car-c"})
+
+	spawner := &mockSpawner{}
+	asker, err := NewAsker(AskerOpts{DB: db, Spawner: spawner})
+	if err != nil {
+		t.Fatalf("NewAsker: %v", err)
+	}
+
+	type result struct {
+		answer string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		answer, err := asker.Ask(context.Background(), "what's blocking the payments epic?")
+		done <- result{answer, err}
+	}()
+	p := answerWith(t, spawner, "car-b waits on car-c,", "which eng-1 is working on.")
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("Ask: %v", res.err)
+	}
+	if res.answer != "car-b waits on car-c,\nwhich eng-1 is working on." {
+		t.Errorf("answer = %q", res.answer)
+	}
+	for _, want := range []string{
+		"Question: what's blocking the payments epic?",
+		"- car-b [task, blocked, P2, track backend] Charge cards; parent car-epic; owner alice; waits on car-c (in_progress)",
+		"- car-c [task, in_progress, P2, track backend] Stripe keys; engine eng-1",
+	} {
+		if !strings.Contains(p.prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, p.prompt)
+		}
+	}
+	if strings.Contains(p.prompt, "car-old") {
+		t.Error("prompt lists a merged car")
+	}
+}
+
+func TestAsker_NoAnswer(t *testing.T) {
+	db := openRouterTestDB(t)
+	spawner := &mockSpawner{}
+	asker, _ := NewAsker(AskerOpts{DB: db, Spawner: spawner})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := asker.Ask(context.Background(), "anything?")
+		done <- err
+	}()
+	waitFor(t, func() bool { return spawner.lastProcess() != nil }, 2*time.Second)
+	p := spawner.lastProcess()
+	close(p.recvCh)
+	p.exitWith(errors.New("exit status 1"))
+
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "exit status 1") {
+		t.Errorf("err = %v, want exit status in error", err)
+	}
+}
+
+func TestHandle_AskRepliesInThread(t *testing.T) {
+	db := openRouterTestDB(t)
+	router, adapter, dispatchSpawner := setupRouter(t, db, "9900112233", nil)
+	askSpawner := &mockSpawner{}
+	router.asker, _ = NewAsker(AskerOpts{DB: db, Spawner: askSpawner})
+
+	router.Handle(context.Background(), InboundMessage{
+		UserID:    "user-1",
+		UserName:  "alice",
+		ChannelID: "C1",
+		MessageID: "M1",
+		Text:      `!ry ask "what's blocking the payments epic?"`,
+	})
+	p := answerWith(t, askSpawner, "Nothing is blocked.")
+	if !strings.HasPrefix(p.prompt, "Question: what's blocking the payments epic?\n") {
+		t.Errorf("prompt = %q", p.prompt)
+	}
+
+	waitFor(t, func() bool { return adapter.SentCount() == 2 }, 2*time.Second)
+	msg, _ := adapter.LastSent()
+	if msg.Text != "Nothing is blocked." || msg.ThreadID != "thread-1" {
+		t.Errorf("answer = %+v, want it in thread-1", msg)
+	}
+	if dispatchSpawner.lastProcess() != nil {
+		t.Error("ask spawned a dispatch process")
+	}
+	var sessions int64
+	db.Model(&models.DispatchSession{}).Count(&sessions)
+	if sessions != 0 {
+		t.Errorf("dispatch sessions = %d, want 0", sessions)
+	}
+}
+
+func TestHandle_AskNotConfigured(t *testing.T) {
+	db := openRouterTestDB(t)
+	router, adapter, _ := setupRouter(t, db, "9900112233", nil)
+
+	router.Handle(context.Background(), InboundMessage{
+		ChannelID: "C1",
+		ThreadID:  "T1",
+		Text:      "!ry ask how are we doing?",
+	})
+	msg, ok := adapter.LastSent()
+	if !ok || !strings.Contains(msg.Text, "not configured") || msg.ThreadID != "T1" {
+		t.Errorf("reply = %+v, want not-configured notice in T1", msg)
+	}
+}
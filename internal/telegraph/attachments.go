@@ -0,0 +1,98 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// maxAttachmentSize caps a single uploaded file the router will store.
+const maxAttachmentSize = 10 << 20
+
+var errAttachmentTooLarge = errors.New("file too large")
+
+// cappedBuffer is a bytes.Buffer that refuses to grow past limit.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errAttachmentTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// withAttachments downloads the files uploaded with msg, stores them as
+// attachments not yet bound to a car, and returns text with a note that
+// tells Dispatch their IDs so it can pass them to `ry car create --attach`.
+// Files that cannot be fetched are listed as skipped so Dispatch can tell
+// the user. Returns text unchanged when msg carries no files.
+func (r *Router) withAttachments(ctx context.Context, msg InboundMessage, channelID, threadID, text string) string {
+	if len(msg.Files) == 0 {
+		return text
+	}
+	dl, _ := r.adapter.(FileDownloader)
+
+	var lines []string
+	for _, f := range msg.Files {
+		a, err := r.storeAttachment(ctx, dl, msg, f, channelID, threadID)
+		if err != nil {
+			log.Printf("telegraph: router: attachment %q: %v", f.Name, err)
+			lines = append(lines, fmt.Sprintf("- %s: skipped (%v)", f.Name, err))
+			continue
+		}
+		fmt.Fprintf(r.out, "telegraph: router: stored attachment %d (%s, %d bytes)\n", a.ID, a.Name, a.Size)
+		detail := car.FormatBytes(a.Size)
+		if a.ContentType != "" {
+			detail = a.ContentType + ", " + detail
+		}
+		lines = append(lines, fmt.Sprintf("- attachment %d: %s (%s)", a.ID, a.Name, detail))
+	}
+
+	note := "[attachments] The user uploaded files with this message. Pass `--attach <id>` to `ry car create` for each file a car needs:\n" +
+		strings.Join(lines, "\n")
+	if text == "" {
+		return note
+	}
+	return text + "\n\n" + note
+}
+
+// storeAttachment downloads f through dl and saves it.
+func (r *Router) storeAttachment(ctx context.Context, dl FileDownloader, msg InboundMessage, f InboundFile, channelID, threadID string) (*models.Attachment, error) {
+	if dl == nil {
+		return nil, fmt.Errorf("this chat adapter cannot download files")
+	}
+	if f.Size > maxAttachmentSize {
+		return nil, fmt.Errorf("%s exceeds the %s limit", car.FormatBytes(f.Size), car.FormatBytes(maxAttachmentSize))
+	}
+	buf := &cappedBuffer{limit: maxAttachmentSize}
+	if err := dl.DownloadFile(ctx, f, buf); err != nil {
+		if errors.Is(err, errAttachmentTooLarge) {
+			return nil, fmt.Errorf("file exceeds the %s limit", car.FormatBytes(maxAttachmentSize))
+		}
+		return nil, fmt.Errorf("download: %w", err)
+	}
+
+	a := &models.Attachment{
+		Platform:    msg.Platform,
+		ChannelID:   channelID,
+		ThreadID:    threadID,
+		UploadedBy:  msg.UserName,
+		Name:        f.Name,
+		ContentType: f.MimeType,
+		Size:        int64(buf.Len()),
+		Data:        buf.Bytes(),
+	}
+	if err := r.sessionMgr.db.WithContext(ctx).Create(a).Error; err != nil {
+		return nil, fmt.Errorf("save: %w", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,64 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestHandle_StoresAttachments(t *testing.T) {
+	db := openRouterTestDB(t)
+	router, adapter, spawner := setupRouter(t, db, "U1", nil)
+	adapter.SetFile("https://files/spec", []byte("# spec"))
+	adapter.SetFile("https://files/huge", bytes.Repeat([]byte("x"), maxAttachmentSize+1))
+
+	router.Handle(context.Background(), InboundMessage{
+		Platform:  "slack",
+		UserID:    "user-1",
+		UserName:  "bob",
+		ChannelID: "C1",
+		Text:      "<@U1> build this",
+		Files: []InboundFile{
+			{Name: "spec.md", MimeType: "text/markdown", URL: "https://files/spec"},
+			{Name: "huge.bin", URL: "https://files/huge"},
+			{Name: "video.mp4", Size: 25 << 20, URL: "https://files/video"},
+		},
+	})
+
+	var atts []models.Attachment
+	db.Find(&atts)
+	if len(atts) != 1 {
+		t.Fatalf("stored %d attachments, want 1", len(atts))
+	}
+	a := atts[0]
+	if a.CarID != "" || a.Name != "spec.md" || string(a.Data) != "# spec" || a.Size != 6 || a.UploadedBy != "bob" || a.ThreadID != "thread-1" {
+		t.Errorf("attachment = %+v", a)
+	}
+
+	if len(spawner.processes) != 1 {
+		t.Fatalf("spawned %d processes, want 1", len(spawner.processes))
+	}
+	sent := strings.Join(spawner.processes[0].sentMessages(), "\n")
+	for _, want := range []string{
+		"build this",
+		"--attach <id>",
+		"- attachment 1: spec.md (text/markdown, 6 B)",
+		"- huge.bin: skipped (file exceeds the 10.0 MB limit)",
+		"- video.mp4: skipped (25.0 MB exceeds the 10.0 MB limit)",
+	} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("routed text missing %q:\n%s", want, sent)
+		}
+	}
+}
+
+func TestWithAttachments_NoFiles(t *testing.T) {
+	db := openRouterTestDB(t)
+	router, _, _ := setupRouter(t, db, "U1", nil)
+	if got := router.withAttachments(context.Background(), InboundMessage{}, "C1", "T1", "hello"); got != "hello" {
+		t.Errorf("withAttachments = %q, want text unchanged", got)
+	}
+}
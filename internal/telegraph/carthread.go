@@ -0,0 +1,87 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// sendCarThreads posts a car lifecycle event on every platform behind m,
+// threaded under the car's earlier events there: the first event is posted
+// top-level, the second starts a thread from it, and later ones reply in
+// that thread. The car_threads table maps the car to its thread on each
+// platform, so the lifecycle is mirrored everywhere. A failure on one
+// platform does not stop delivery on the others.
+func (d *Daemon) sendCarThreads(ctx context.Context, m *MultiAdapter, event DetectedEvent, formatted FormattedEvent) error {
+	var errs []error
+	for _, p := range m.Platforms() {
+		if err := d.sendCarThread(ctx, p, event, formatted); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Platform, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendCarThread posts one car event on platform p.
+func (d *Daemon) sendCarThread(ctx context.Context, p PlatformAdapter, event DetectedEvent, formatted FormattedEvent) error {
+	msg := OutboundMessage{ChannelID: p.Channel, Events: []FormattedEvent{formatted}}
+
+	var ct models.CarThread
+	err := d.db.Where("car_id = ? AND platform = ?", event.CarID, p.Platform).First(&ct).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		poster, ok := p.Adapter.(MessagePoster)
+		if !ok {
+			return p.Adapter.Send(ctx, msg)
+		}
+		rootID, err := poster.Post(ctx, msg)
+		if err != nil {
+			return err
+		}
+		return d.db.Create(&models.CarThread{
+			CarID:         event.CarID,
+			Platform:      p.Platform,
+			ChannelID:     p.Channel,
+			RootMessageID: rootID,
+		}).Error
+	}
+	if err != nil {
+		return fmt.Errorf("car thread for %s: %w", event.CarID, err)
+	}
+
+	if ct.ThreadID == "" {
+		starter, ok := p.Adapter.(ThreadStarter)
+		if !ok || ct.RootMessageID == "" {
+			return p.Adapter.Send(ctx, msg)
+		}
+		threadID, err := starter.StartThread(ctx, ct.ChannelID, ct.RootMessageID, carThreadReply(formatted), carThreadName(event))
+		if threadID != "" {
+			if dbErr := d.db.Model(&ct).Update("thread_id", threadID).Error; dbErr != nil {
+				return fmt.Errorf("record car thread for %s: %w", event.CarID, dbErr)
+			}
+		}
+		return err
+	}
+
+	msg.ChannelID, msg.ThreadID = ct.ChannelID, ct.ThreadID
+	return p.Adapter.Send(ctx, msg)
+}
+
+// carThreadReply renders formatted as the plain-text first reply that
+// starts a car's thread.
+func carThreadReply(formatted FormattedEvent) string {
+	if formatted.Body == "" {
+		return formatted.Title
+	}
+	return formatted.Title + "\n" + formatted.Body
+}
+
+// carThreadName names a car's thread after the car.
+func carThreadName(event DetectedEvent) string {
+	if event.Title == "" {
+		return event.CarID
+	}
+	return fallbackTitle(event.CarID + ": " + event.Title)
+}
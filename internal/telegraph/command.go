@@ -5,13 +5,19 @@ import (
 	"strings"
 
 	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
-// CommandHandler processes read-only "!ry" commands from chat.
-// It does NOT acquire dispatch locks — all operations are read-only.
+// CommandHandler processes "!ry" commands from chat. It does NOT acquire
+// dispatch locks — all operations are read-only apart from `!ry notify`,
+// which only touches the sending user's own subscriptions, and
+// `!ry car revert` and `!ry merge abort`, which hand the request to the
+// yardmaster's inbox.
+// `!ry ask` is answered by the Router's Asker, not here.
 type CommandHandler struct {
 	db             *gorm.DB
 	statusProvider StatusProvider
@@ -39,8 +45,15 @@ func NewCommandHandler(opts CommandHandlerOpts) (*CommandHandler, error) {
 }
 
 // Execute parses and executes a "!ry" command string. Returns the
-// response text to send back to the chat channel.
+// response text to send back to the chat channel. Commands that act on
+// behalf of the sender (notify) need ExecuteFrom.
 func (ch *CommandHandler) Execute(text string) string {
+	return ch.ExecuteFrom(InboundMessage{}, text)
+}
+
+// ExecuteFrom is Execute with the inbound message that carried the command,
+// identifying the sending user.
+func (ch *CommandHandler) ExecuteFrom(msg InboundMessage, text string) string {
 	args := parseCommand(text)
 	if len(args) == 0 {
 		return ch.helpText()
@@ -50,9 +63,17 @@ func (ch *CommandHandler) Execute(text string) string {
 	case "status":
 		return ch.cmdStatus()
 	case "car":
-		return ch.cmdCar(args[1:])
+		return ch.cmdCar(msg, args[1:])
 	case "engine":
 		return ch.cmdEngine(args[1:])
+	case "merge":
+		return ch.cmdMerge(msg, args[1:])
+	case "infra":
+		return ch.cmdInfra(msg, args[1:])
+	case "notify":
+		return ch.cmdNotify(msg, args[1:])
+	case "ask":
+		return "`!ry ask` answers are posted by the telegraph router."
 	case "help":
 		return ch.helpText()
 	default:
@@ -84,9 +105,9 @@ func (ch *CommandHandler) cmdStatus() string {
 }
 
 // cmdCar handles "!ry car" subcommands.
-func (ch *CommandHandler) cmdCar(args []string) string {
+func (ch *CommandHandler) cmdCar(msg InboundMessage, args []string) string {
 	if len(args) == 0 {
-		return "Usage: `!ry car list [--track <track>] [--status <status>]` or `!ry car show <id>`"
+		return "Usage: `!ry car list [--track <track>] [--status <status>]`, `!ry car show <id>`, or `!ry car revert <id>`"
 	}
 
 	switch args[0] {
@@ -94,9 +115,87 @@ func (ch *CommandHandler) cmdCar(args []string) string {
 		return ch.cmdCarList(args[1:])
 	case "show":
 		return ch.cmdCarShow(args[1:])
+	case "revert":
+		return ch.cmdCarRevert(msg, args[1:])
 	default:
-		return fmt.Sprintf("Unknown car subcommand: `%s`\nUsage: `!ry car list` or `!ry car show <id>`", args[0])
+		return fmt.Sprintf("Unknown car subcommand: `%s`\nUsage: `!ry car list`, `!ry car show <id>`, or `!ry car revert <id>`", args[0])
+	}
+}
+
+// cmdCarRevert asks the yardmaster to revert a merged car. The revert itself
+// (revert branch, merge gate, merge or PR) runs in the yardmaster daemon.
+func (ch *CommandHandler) cmdCarRevert(msg InboundMessage, args []string) string {
+	if len(args) == 0 {
+		return "Usage: `!ry car revert <car-id>`"
+	}
+	c, err := car.Get(ch.db, args[0])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if c.Status != "merged" {
+		return fmt.Sprintf("Car %s is %s; only merged cars can be reverted.", c.ID, c.Status)
+	}
+
+	body := "Revert requested from chat"
+	if msg.UserName != "" {
+		body += " by @" + msg.UserName
+	}
+	if _, err := messaging.Send(ch.db, "telegraph", "yardmaster", "revert-car", body,
+		messaging.SendOpts{CarID: c.ID}); err != nil {
+		return fmt.Sprintf("Error requesting revert: %v", err)
 	}
+	return fmt.Sprintf("Revert of %s (%s) requested. The yardmaster will open a revert car and run it through the merge gate.", c.ID, c.Title)
+}
+
+// cmdMerge handles "!ry merge abort <car-id>": the yardmaster stops the
+// car's running merge-gate tests and retries the merge later.
+func (ch *CommandHandler) cmdMerge(msg InboundMessage, args []string) string {
+	if len(args) < 2 || args[0] != "abort" {
+		return "Usage: `!ry merge abort <car-id>`"
+	}
+	c, err := car.Get(ch.db, args[1])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if c.Status != "done" {
+		return fmt.Sprintf("Car %s is %s; only done cars are in the merge gate.", c.ID, c.Status)
+	}
+
+	body := "Merge abort requested from chat"
+	if msg.UserName != "" {
+		body += " by @" + msg.UserName
+	}
+	if _, err := messaging.Send(ch.db, "telegraph", "yardmaster", "abort-merge", body,
+		messaging.SendOpts{CarID: c.ID}); err != nil {
+		return fmt.Sprintf("Error requesting abort: %v", err)
+	}
+	return fmt.Sprintf("Abort of %s (%s) requested. The yardmaster will stop its test run and retry the merge later.", c.ID, c.Title)
+}
+
+// cmdInfra handles "!ry infra resolved": the operator reports the test
+// environment fixed, and the yardmaster releases the merge hold an infra
+// failure placed and retries the held cars.
+func (ch *CommandHandler) cmdInfra(msg InboundMessage, args []string) string {
+	if len(args) == 0 || args[0] != "resolved" {
+		return "Usage: `!ry infra resolved`"
+	}
+	hold, err := yard.GetMergeHold(ch.db)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if !hold.Held {
+		return "The merge queue is not held."
+	}
+
+	body := "Infra resolved from chat"
+	if msg.UserName != "" {
+		body += " by @" + msg.UserName
+	}
+	if _, err := messaging.Send(ch.db, "telegraph", "yardmaster", "infra-resolved", body,
+		messaging.SendOpts{}); err != nil {
+		return fmt.Sprintf("Error resuming merges: %v", err)
+	}
+	return fmt.Sprintf("Resuming the merge queue (%s). The yardmaster will retry the held cars.", hold)
 }
 
 // cmdCarList lists cars with optional filters.
@@ -159,7 +258,13 @@ func (ch *CommandHandler) helpText() string {
 		"`!ry status` — Railyard dashboard\n" +
 		"`!ry car list [--track X] [--status X]` — List cars\n" +
 		"`!ry car show <id>` — Car details\n" +
+		"`!ry car revert <id>` — Revert a merged car\n" +
+		"`!ry merge abort <id>` — Stop a car's merge-gate tests and retry later\n" +
+		"`!ry infra resolved` — Resume merges held after an infra failure and retry the held cars\n" +
 		"`!ry engine list` — List engines\n" +
+		"`!ry notify me on|off <car|engine|event>` — DM me about a car, engine, or event (`cars`, `engine-stalls`, `escalations`)\n" +
+		"`!ry notify me list` — My notifications\n" +
+		"`!ry ask <question>` — Ask about the yard (read-only agent)\n" +
 		"`!ry help` — This message"
 }
 
@@ -191,6 +296,9 @@ func formatCarDetail(c *models.Car) string {
 	if c.Assignee != "" {
 		b.WriteString(fmt.Sprintf("Assignee: %s\n", c.Assignee))
 	}
+	if c.Owner != "" {
+		b.WriteString(fmt.Sprintf("Owner: @%s\n", c.Owner))
+	}
 	if c.Branch != "" {
 		b.WriteString(fmt.Sprintf("Branch: %s\n", c.Branch))
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -293,6 +294,58 @@ func TestExecute_CarShowNotFound(t *testing.T) {
 	}
 }
 
+func TestExecute_CarRevert(t *testing.T) {
+	db := openCommandTestDB(t)
+	db.Create(&models.Car{ID: "car-1", Title: "Landed", Status: "merged", Track: "backend"})
+	db.Create(&models.Car{ID: "car-2", Title: "Working", Status: "in_progress", Track: "backend"})
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	result := ch.ExecuteFrom(InboundMessage{UserName: "alice"}, "!ry car revert car-1")
+	if !strings.Contains(result, "Revert of car-1") {
+		t.Errorf("unexpected response: %q", result)
+	}
+	var msg models.Message
+	if err := db.Where("to_agent = ? AND subject = ?", "yardmaster", "revert-car").First(&msg).Error; err != nil {
+		t.Fatalf("revert-car message not sent: %v", err)
+	}
+	if msg.CarID != "car-1" || !strings.Contains(msg.Body, "@alice") {
+		t.Errorf("message = %+v", msg)
+	}
+
+	if result := ch.Execute("!ry car revert car-2"); !strings.Contains(result, "only merged cars") {
+		t.Errorf("unmerged car: %q", result)
+	}
+	if result := ch.Execute("!ry car revert"); !strings.Contains(result, "Usage") {
+		t.Errorf("missing id: %q", result)
+	}
+}
+
+func TestExecute_MergeAbort(t *testing.T) {
+	db := openCommandTestDB(t)
+	db.Create(&models.Car{ID: "car-1", Title: "Slow suite", Status: "done", Track: "backend"})
+	db.Create(&models.Car{ID: "car-2", Title: "Working", Status: "in_progress", Track: "backend"})
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	result := ch.ExecuteFrom(InboundMessage{UserName: "alice"}, "!ry merge abort car-1")
+	if !strings.Contains(result, "Abort of car-1") {
+		t.Errorf("unexpected response: %q", result)
+	}
+	var msg models.Message
+	if err := db.Where("to_agent = ? AND subject = ?", "yardmaster", "abort-merge").First(&msg).Error; err != nil {
+		t.Fatalf("abort-merge message not sent: %v", err)
+	}
+	if msg.CarID != "car-1" || !strings.Contains(msg.Body, "@alice") {
+		t.Errorf("message = %+v", msg)
+	}
+
+	if result := ch.Execute("!ry merge abort car-2"); !strings.Contains(result, "only done cars") {
+		t.Errorf("car not in the merge gate: %q", result)
+	}
+	if result := ch.Execute("!ry merge car-1"); !strings.Contains(result, "Usage") {
+		t.Errorf("missing subcommand: %q", result)
+	}
+}
+
 func TestExecute_CarShowNoID(t *testing.T) {
 	db := openCommandTestDB(t)
 	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
@@ -463,3 +516,31 @@ func TestFormatEngineTable(t *testing.T) {
 		t.Error("empty current car should show '-'")
 	}
 }
+
+func TestExecute_InfraResolved(t *testing.T) {
+	db := openCommandTestDB(t)
+	if err := db.AutoMigrate(&models.RailyardConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	if result := ch.Execute("!ry infra resolved"); !strings.Contains(result, "not held") {
+		t.Errorf("nothing held: %q", result)
+	}
+	if result := ch.Execute("!ry infra"); !strings.Contains(result, "Usage") {
+		t.Errorf("missing subcommand: %q", result)
+	}
+
+	yard.HoldMerges(db, "car-1", "docker daemon unreachable")
+	result := ch.ExecuteFrom(InboundMessage{UserName: "alice"}, "!ry infra resolved")
+	if !strings.Contains(result, "Resuming the merge queue") || !strings.Contains(result, "docker daemon unreachable") {
+		t.Errorf("unexpected response: %q", result)
+	}
+	var msg models.Message
+	if err := db.Where("to_agent = ? AND subject = ?", "yardmaster", "infra-resolved").First(&msg).Error; err != nil {
+		t.Fatalf("infra-resolved message not sent: %v", err)
+	}
+	if !strings.Contains(msg.Body, "@alice") {
+		t.Errorf("message = %+v", msg)
+	}
+}
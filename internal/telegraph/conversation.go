@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
 )
@@ -24,14 +24,18 @@ type ConversationStore struct {
 	adapter              Adapter
 	maxTurnsPerSession   int
 	recoveryLookbackDays int
+	history              *HistoryStore
+	clock                clock.Clock
 }
 
 // ConversationStoreOpts holds parameters for creating a ConversationStore.
 type ConversationStoreOpts struct {
 	DB                   *gorm.DB
-	Adapter              Adapter // optional; enables dual-write to chat platform
-	MaxTurnsPerSession   int     // defaults to DefaultMaxTurnsPerSession
-	RecoveryLookbackDays int     // defaults to DefaultRecoveryLookbackDays
+	Adapter              Adapter       // optional; enables dual-write to chat platform
+	MaxTurnsPerSession   int           // defaults to DefaultMaxTurnsPerSession
+	RecoveryLookbackDays int           // defaults to DefaultRecoveryLookbackDays
+	History              *HistoryStore // reads history, including archived turns; defaults to the database only
+	Clock                clock.Clock   // defaults to clock.Real
 }
 
 // NewConversationStore creates a ConversationStore.
@@ -47,10 +51,16 @@ func NewConversationStore(opts ConversationStoreOpts) (*ConversationStore, error
 	if lookback <= 0 {
 		lookback = DefaultRecoveryLookbackDays
 	}
+	history := opts.History
+	if history == nil {
+		history = dbHistoryStore(opts.DB)
+	}
 	return &ConversationStore{
 		db:                   opts.DB,
 		adapter:              opts.Adapter,
 		maxTurnsPerSession:   maxTurns,
+		history:              history,
+		clock:                clock.OrReal(opts.Clock),
 		recoveryLookbackDays: lookback,
 	}, nil
 }
@@ -60,23 +70,14 @@ func NewConversationStore(opts ConversationStoreOpts) (*ConversationStore, error
 // platform thread. Returns ErrMaxTurnsExceeded if the session has reached
 // the maximum number of turns.
 func (cs *ConversationStore) WriteUserMessage(ctx context.Context, sessionID uint, userName, text, platformMsgID string) error {
-	seq, err := cs.nextSequence(sessionID)
-	if err != nil {
-		return err
-	}
-	if seq > cs.maxTurnsPerSession {
-		return fmt.Errorf("telegraph: max turns exceeded (%d) for session %d", cs.maxTurnsPerSession, sessionID)
-	}
-
 	conv := models.TelegraphConversation{
 		SessionID:     sessionID,
-		Sequence:      seq,
 		Role:          "user",
 		UserName:      userName,
 		Content:       text,
 		PlatformMsgID: platformMsgID,
 	}
-	if err := cs.db.Create(&conv).Error; err != nil {
+	if err := appendConversation(cs.db, &conv, cs.maxTurnsPerSession); err != nil {
 		return fmt.Errorf("telegraph: write user message: %w", err)
 	}
 
@@ -100,14 +101,6 @@ func (cs *ConversationStore) WriteUserMessage(ctx context.Context, sessionID uin
 // It writes to the database and, if an adapter is configured, sends the response to
 // the chat platform thread.
 func (cs *ConversationStore) WriteAssistantMessage(ctx context.Context, sessionID uint, text, platformMsgID string, carsReferenced []string) error {
-	seq, err := cs.nextSequence(sessionID)
-	if err != nil {
-		return err
-	}
-	if seq > cs.maxTurnsPerSession {
-		return fmt.Errorf("telegraph: max turns exceeded (%d) for session %d", cs.maxTurnsPerSession, sessionID)
-	}
-
 	carsJSON := "[]"
 	if len(carsReferenced) > 0 {
 		carsJSON = `["` + strings.Join(carsReferenced, `","`) + `"]`
@@ -115,13 +108,12 @@ func (cs *ConversationStore) WriteAssistantMessage(ctx context.Context, sessionI
 
 	conv := models.TelegraphConversation{
 		SessionID:      sessionID,
-		Sequence:       seq,
 		Role:           "assistant",
 		Content:        text,
 		PlatformMsgID:  platformMsgID,
 		CarsReferenced: carsJSON,
 	}
-	if err := cs.db.Create(&conv).Error; err != nil {
+	if err := appendConversation(cs.db, &conv, cs.maxTurnsPerSession); err != nil {
 		return fmt.Errorf("telegraph: write assistant message: %w", err)
 	}
 
@@ -141,13 +133,11 @@ func (cs *ConversationStore) WriteAssistantMessage(ctx context.Context, sessionI
 }
 
 // LoadHistory returns the full conversation history for a session, ordered
-// by sequence number.
+// by sequence number, including turns moved to the history archive.
 func (cs *ConversationStore) LoadHistory(sessionID uint) ([]models.TelegraphConversation, error) {
-	var convos []models.TelegraphConversation
-	result := cs.db.Where("session_id = ?", sessionID).
-		Order("sequence").Find(&convos)
-	if result.Error != nil {
-		return nil, fmt.Errorf("telegraph: load history: %w", result.Error)
+	convos, err := cs.history.SessionHistory(context.Background(), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: load history: %w", err)
 	}
 	return convos, nil
 }
@@ -156,18 +146,11 @@ func (cs *ConversationStore) LoadHistory(sessionID uint) ([]models.TelegraphConv
 // falling back to the adapter's ThreadHistory when no database records exist.
 // Only sessions within the lookback window are included.
 func (cs *ConversationStore) RecoverFromThread(ctx context.Context, channelID, threadID string) ([]models.TelegraphConversation, error) {
-	cutoff := time.Now().AddDate(0, 0, -cs.recoveryLookbackDays)
-
-	var convos []models.TelegraphConversation
-	result := cs.db.Where("session_id IN (?)",
-		cs.db.Model(&models.DispatchSession{}).
-			Select("id").
-			Where("platform_thread_id = ? AND channel_id = ? AND created_at >= ?",
-				threadID, channelID, cutoff),
-	).Order("session_id, sequence").Find(&convos)
+	cutoff := cs.clock.Now().AddDate(0, 0, -cs.recoveryLookbackDays)
 
-	if result.Error != nil {
-		return nil, fmt.Errorf("telegraph: recover from thread: %w", result.Error)
+	convos, err := cs.history.ThreadHistory(ctx, channelID, threadID, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: recover from thread: %w", err)
 	}
 
 	if len(convos) > 0 {
@@ -205,16 +188,46 @@ func (cs *ConversationStore) TurnCount(sessionID uint) (int, error) {
 	return int(count), nil
 }
 
-// nextSequence returns the next sequence number for a session.
-func (cs *ConversationStore) nextSequence(sessionID uint) (int, error) {
-	var maxSeq int
-	result := cs.db.Model(&models.TelegraphConversation{}).
-		Where("session_id = ?", sessionID).
-		Select("COALESCE(MAX(sequence), 0)").Scan(&maxSeq)
-	if result.Error != nil {
-		return 0, fmt.Errorf("telegraph: next sequence: %w", result.Error)
-	}
-	return maxSeq + 1, nil
+// appendConversation assigns conv the next Sequence in its session and
+// inserts it, in one transaction. Bumping the session's LastSequence counter
+// first locks the session row, so concurrent writers (Route, relayOutput, the
+// conversation store) are serialized per session rather than all reading the
+// same MAX(sequence). Sessions with rows written before the counter existed
+// continue from their highest sequence. maxTurns > 0 rejects sequences past it.
+func appendConversation(db *gorm.DB, conv *models.TelegraphConversation, maxTurns int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		session := tx.Model(&models.DispatchSession{}).Where("id = ?", conv.SessionID).Session(&gorm.Session{})
+		res := session.UpdateColumn("last_sequence", gorm.Expr("last_sequence + 1"))
+		if res.Error != nil {
+			return fmt.Errorf("next sequence: %w", res.Error)
+		}
+		counted := res.RowsAffected > 0 // false for conversations without a session row
+
+		var seq, maxSeq int
+		if counted {
+			if err := session.Select("last_sequence").Scan(&seq).Error; err != nil {
+				return fmt.Errorf("next sequence: %w", err)
+			}
+		}
+		if err := tx.Model(&models.TelegraphConversation{}).Where("session_id = ?", conv.SessionID).
+			Select("COALESCE(MAX(sequence), 0)").Scan(&maxSeq).Error; err != nil {
+			return fmt.Errorf("next sequence: %w", err)
+		}
+		if maxSeq >= seq {
+			seq = maxSeq + 1
+			if counted {
+				if err := session.UpdateColumn("last_sequence", seq).Error; err != nil {
+					return fmt.Errorf("next sequence: %w", err)
+				}
+			}
+		}
+		if maxTurns > 0 && seq > maxTurns {
+			return fmt.Errorf("max turns exceeded (%d) for session %d", maxTurns, conv.SessionID)
+		}
+
+		conv.Sequence = seq
+		return tx.Create(conv).Error
+	})
 }
 
 // lookupSession fetches a DispatchSession by ID (cached-friendly query).
@@ -2,7 +2,9 @@ package telegraph
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -554,3 +556,88 @@ func TestRecoverFromThread_MultipleSessions(t *testing.T) {
 		t.Fatalf("count = %d, want 2", len(convos))
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Sequence allocation
+// ---------------------------------------------------------------------------
+
+func TestAppendConversation_ConcurrentWriters(t *testing.T) {
+	// A file database with a connection pool, so writers really run
+	// concurrently (a pinned ":memory:" connection would serialize them).
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "conv.db")+"?_busy_timeout=10000&_journal_mode=WAL"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.DispatchSession{}, &models.TelegraphConversation{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	cs, _ := NewConversationStore(ConversationStoreOpts{DB: db, MaxTurnsPerSession: 1000})
+	session := createTestSession(t, db, "C01", "thread-1")
+
+	const writers, perWriter = 32, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				var err error
+				switch w % 3 {
+				case 0: // SessionManager.Route / Resume
+					err = appendConversation(db, &models.TelegraphConversation{SessionID: session.ID, Role: "user", Content: "route"}, 0)
+				case 1:
+					err = cs.WriteUserMessage(context.Background(), session.ID, "alice", "store", "")
+				case 2:
+					err = cs.WriteAssistantMessage(context.Background(), session.ID, "reply", "", nil)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("write: %v", err)
+	}
+
+	history, err := cs.LoadHistory(session.ID)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if len(history) != writers*perWriter {
+		t.Fatalf("history has %d rows, want %d", len(history), writers*perWriter)
+	}
+	for i, c := range history {
+		if c.Sequence != i+1 {
+			t.Fatalf("row %d has sequence %d, want %d (duplicate or gap)", i, c.Sequence, i+1)
+		}
+	}
+}
+
+func TestAppendConversation_ContinuesLegacySequence(t *testing.T) {
+	db := openConvTestDB(t)
+	session := createTestSession(t, db, "C01", "thread-1")
+	// Rows written before the per-session counter existed.
+	db.Create(&models.TelegraphConversation{SessionID: session.ID, Sequence: 1, Role: "user", Content: "a"})
+	db.Create(&models.TelegraphConversation{SessionID: session.ID, Sequence: 2, Role: "assistant", Content: "b"})
+
+	for want := 3; want <= 4; want++ {
+		conv := &models.TelegraphConversation{SessionID: session.ID, Role: "user", Content: "next"}
+		if err := appendConversation(db, conv, 0); err != nil {
+			t.Fatalf("appendConversation: %v", err)
+		}
+		if conv.Sequence != want {
+			t.Errorf("sequence = %d, want %d", conv.Sequence, want)
+		}
+	}
+	var stored models.DispatchSession
+	db.First(&stored, session.ID)
+	if stored.LastSequence != 4 {
+		t.Errorf("LastSequence = %d, want 4", stored.LastSequence)
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 func TestNextCronDuration_ValidExpression(t *testing.T) {
 	// "0 9 * * *" = daily at 09:00. Duration should be positive and < 24h.
-	d := nextCronDuration("0 9 * * *")
+	d := nextCronDuration(time.Now(), "0 9 * * *")
 	if d <= 0 {
 		t.Fatalf("expected positive duration, got %v", d)
 	}
@@ -17,7 +17,7 @@ func TestNextCronDuration_ValidExpression(t *testing.T) {
 }
 
 func TestNextCronDuration_InvalidExpression(t *testing.T) {
-	d := nextCronDuration("not a cron expr")
+	d := nextCronDuration(time.Now(), "not a cron expr")
 	if d != 0 {
 		t.Fatalf("expected 0 for invalid expression, got %v", d)
 	}
@@ -25,7 +25,7 @@ func TestNextCronDuration_InvalidExpression(t *testing.T) {
 
 func TestNextCronDuration_EveryMinute(t *testing.T) {
 	// "* * * * *" = every minute. Duration should be < 61s.
-	d := nextCronDuration("* * * * *")
+	d := nextCronDuration(time.Now(), "* * * * *")
 	if d <= 0 {
 		t.Fatalf("expected positive duration, got %v", d)
 	}
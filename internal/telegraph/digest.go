@@ -2,10 +2,13 @@ package telegraph
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
 	"gorm.io/gorm"
 )
 
@@ -25,7 +28,9 @@ type DailyReport struct {
 	StallCount     int
 	TotalTokens    int64
 	EngineCount    int
+	FlaggedEngines []orchestration.EngineStats // underperforming engines over the engine stats window
 	TrackBreakdown []TrackDigest
+	OwnerBreakdown []OwnerDigest // set when digests are grouped by owner
 
 	// Previous-period metrics (prior 24h window).
 	PrevCarsCreated   int
@@ -45,7 +50,18 @@ type WeeklyReport struct {
 	MergeSuccessRate float64
 	TotalTokens      int64
 	StallCount       int
+	CarsCompleted    int           // throughput: done or merged in the period
+	CarsFailed       int           // merge-failed or blocked, last updated in the period
+	FailureRate      float64       // CarsFailed / (CarsCompleted + CarsFailed), in percent
+	CycleTime        time.Duration // median claim-to-completion of CarsCompleted
 	TrackBreakdown   []TrackDigest
+	OwnerBreakdown   []OwnerDigest // set when digests are grouped by owner
+	CoverageTrends   []CoverageTrend
+	TopEngines       []Contributor               // engines that completed the most cars
+	FlaggedEngines   []orchestration.EngineStats // underperforming engines over the period
+	TopHumans        []Contributor               // owners (or requesters) of the most completed cars
+	OldestBlocked    []RiskItem                  // blocked cars, longest blocked first
+	LongestEpics     []RiskItem                  // open epics, oldest first
 
 	// Previous-period metrics (prior 7-day window).
 	PrevCarsClosed       int
@@ -53,8 +69,29 @@ type WeeklyReport struct {
 	PrevStallCount       int
 	PrevMergeSuccessRate float64
 	PrevTotalTokens      int64
+	PrevCarsCompleted    int
+	PrevFailureRate      float64
+	PrevCycleTime        time.Duration
 }
 
+// Contributor is an engine or person credited with completed cars.
+type Contributor struct {
+	Name      string
+	Completed int
+}
+
+// RiskItem is a car the weekly digest flags as a risk.
+type RiskItem struct {
+	CarID  string
+	Title  string
+	Track  string
+	Age    time.Duration // how long blocked (blocked cars) or open (epics)
+	Detail string        // blocked reason, or child progress for epics
+}
+
+// weeklyTopN caps the contributor and risk lists in the weekly digest.
+const weeklyTopN = 3
+
 // TrackDigest holds per-track metrics for digest reports.
 type TrackDigest struct {
 	Track         string
@@ -63,16 +100,37 @@ type TrackDigest struct {
 	AvgCompletion time.Duration
 }
 
+// CoverageTrend is a track's merged test coverage at the end of a digest
+// period and at the end of the period before it.
+type CoverageTrend struct {
+	Track       string
+	Coverage    float64
+	Previous    float64
+	HasPrevious bool
+}
+
+// OwnerDigest holds per-owner metrics for digest reports.
+type OwnerDigest struct {
+	Owner     string
+	Completed int // done or merged in the period
+	Failed    int // merge-failed or blocked, last updated in the period
+	Open      int // not yet done, merged, or cancelled
+}
+
 // BuildDailyDigest queries the DB for the last 24 hours and returns a
 // DetectedEvent with the daily report. Returns nil when no activity.
 func (w *Watcher) BuildDailyDigest() (*DetectedEvent, error) {
-	now := time.Now()
+	now := w.clock.Now()
 	since := now.Add(-24 * time.Hour)
 
 	report, err := buildDailyReport(w.db, since, now)
 	if err != nil {
 		return nil, fmt.Errorf("telegraph: daily digest: %w", err)
 	}
+	report.TrackBreakdown = groupTrackBreakdown(report.TrackBreakdown, w.trackTypes)
+	if w.digestByOwner {
+		report.OwnerBreakdown = buildOwnerBreakdown(w.db, since, now)
+	}
 
 	// Suppress when no activity.
 	if report.CarsCreated == 0 && report.CarsCompleted == 0 &&
@@ -86,22 +144,27 @@ func (w *Watcher) BuildDailyDigest() (*DetectedEvent, error) {
 		Timestamp: now,
 		Title:     formatted.Title,
 		Body:      formatted.Body,
+		Fields:    formatted.Fields,
 	}, nil
 }
 
 // BuildWeeklyDigest queries the DB for the last 7 days and returns a
 // DetectedEvent with the weekly report. Returns nil when no activity.
 func (w *Watcher) BuildWeeklyDigest() (*DetectedEvent, error) {
-	now := time.Now()
+	now := w.clock.Now()
 	since := now.Add(-7 * 24 * time.Hour)
 
 	report, err := buildWeeklyReport(w.db, since, now)
 	if err != nil {
 		return nil, fmt.Errorf("telegraph: weekly digest: %w", err)
 	}
+	report.TrackBreakdown = groupTrackBreakdown(report.TrackBreakdown, w.trackTypes)
+	if w.digestByOwner {
+		report.OwnerBreakdown = buildOwnerBreakdown(w.db, since, now)
+	}
 
 	// Suppress when no activity.
-	if report.CarsClosed == 0 && report.CarsMerged == 0 &&
+	if report.CarsClosed == 0 && report.CarsMerged == 0 && report.CarsFailed == 0 &&
 		report.StallCount == 0 && report.TotalTokens == 0 {
 		return nil, nil
 	}
@@ -112,6 +175,7 @@ func (w *Watcher) BuildWeeklyDigest() (*DetectedEvent, error) {
 		Timestamp: now,
 		Title:     formatted.Title,
 		Body:      formatted.Body,
+		Fields:    formatted.Fields,
 	}, nil
 }
 
@@ -167,6 +231,7 @@ func buildDailyReport(db *gorm.DB, since, until time.Time) (*DailyReport, error)
 	var engineCount int64
 	db.Model(&models.Engine{}).Count(&engineCount)
 	report.EngineCount = int(engineCount)
+	report.FlaggedEngines = buildFlaggedEngines(db, until.Add(-orchestration.DefaultEngineStatsWindow), until)
 
 	// Per-track breakdown.
 	report.TrackBreakdown = buildTrackBreakdown(db, since, until)
@@ -216,11 +281,11 @@ func buildWeeklyReport(db *gorm.DB, since, until time.Time) (*WeeklyReport, erro
 		PeriodEnd:   until,
 	}
 
-	// Cars closed (done+merged+cancelled, completed_at in range).
+	// Cars closed (models.ClosedStatuses, completed_at in range).
 	var closedCount int64
 	db.Model(&models.Car{}).
 		Where("status IN ? AND completed_at >= ? AND completed_at < ?",
-			[]string{"done", "merged", "cancelled"}, since, until).
+			models.ClosedStatuses, since, until).
 		Count(&closedCount)
 	report.CarsClosed = int(closedCount)
 
@@ -266,7 +331,7 @@ func buildWeeklyReport(db *gorm.DB, since, until time.Time) (*WeeklyReport, erro
 	var prevClosedCount int64
 	db.Model(&models.Car{}).
 		Where("status IN ? AND completed_at >= ? AND completed_at < ?",
-			[]string{"done", "merged", "cancelled"}, prevSince, prevUntil).
+			models.ClosedStatuses, prevSince, prevUntil).
 		Count(&prevClosedCount)
 	report.PrevCarsClosed = int(prevClosedCount)
 
@@ -298,9 +363,295 @@ func buildWeeklyReport(db *gorm.DB, since, until time.Time) (*WeeklyReport, erro
 		Scan(&prevTokenSum)
 	report.PrevTotalTokens = prevTokenSum.Total
 
+	report.CoverageTrends = buildCoverageTrends(db, since, until)
+
+	report.CarsCompleted, report.CarsFailed, report.CycleTime = buildFlowMetrics(db, since, until)
+	report.FailureRate = failureRate(report.CarsCompleted, report.CarsFailed)
+	var prevFailed int
+	report.PrevCarsCompleted, prevFailed, report.PrevCycleTime = buildFlowMetrics(db, prevSince, prevUntil)
+	report.PrevFailureRate = failureRate(report.PrevCarsCompleted, prevFailed)
+
+	report.TopEngines, report.TopHumans = buildTopContributors(db, since, until)
+	report.FlaggedEngines = buildFlaggedEngines(db, since, until)
+	report.OldestBlocked = buildOldestBlocked(db, until)
+	report.LongestEpics = buildLongestEpics(db, until)
+
 	return report, nil
 }
 
+// buildFlowMetrics returns the cars completed (done or merged) and failed
+// (merge-failed or blocked, last updated) in the window, and the median
+// claim-to-completion time of the completed cars. Computed in Go for
+// portability across SQLite (tests) and MySQL (production).
+func buildFlowMetrics(db *gorm.DB, since, until time.Time) (completed, failed int, cycle time.Duration) {
+	var rows []struct {
+		ClaimedAt   *time.Time
+		CompletedAt time.Time
+	}
+	db.Model(&models.Car{}).
+		Where("status IN ? AND completed_at >= ? AND completed_at < ?", []string{"done", "merged"}, since, until).
+		Select("claimed_at, completed_at").
+		Find(&rows)
+	completed = len(rows)
+
+	var cycles []time.Duration
+	for _, r := range rows {
+		if r.ClaimedAt != nil && r.CompletedAt.After(*r.ClaimedAt) {
+			cycles = append(cycles, r.CompletedAt.Sub(*r.ClaimedAt))
+		}
+	}
+	if len(cycles) > 0 {
+		sort.Slice(cycles, func(i, j int) bool { return cycles[i] < cycles[j] })
+		mid := len(cycles) / 2
+		cycle = cycles[mid]
+		if len(cycles)%2 == 0 {
+			cycle = (cycles[mid-1] + cycles[mid]) / 2
+		}
+	}
+
+	var failedCount int64
+	db.Model(&models.Car{}).
+		Where("status IN ? AND updated_at >= ? AND updated_at < ?", []string{"merge-failed", "blocked"}, since, until).
+		Count(&failedCount)
+	return completed, int(failedCount), cycle
+}
+
+// failureRate returns failed as a percentage of all finished cars.
+func failureRate(completed, failed int) float64 {
+	if completed+failed == 0 {
+		return 0
+	}
+	return float64(failed) / float64(completed+failed) * 100
+}
+
+// buildTopContributors ranks the engines and people behind the cars
+// completed in the window. A car is credited to its owner, or to whoever
+// requested it when it has no owner.
+func buildTopContributors(db *gorm.DB, since, until time.Time) (engines, humans []Contributor) {
+	var cars []models.Car
+	db.Model(&models.Car{}).
+		Select("assignee, owner, requested_by").
+		Where("status IN ? AND completed_at >= ? AND completed_at < ?", []string{"done", "merged"}, since, until).
+		Find(&cars)
+
+	engineCounts := make(map[string]int)
+	humanCounts := make(map[string]int)
+	for _, c := range cars {
+		if c.Assignee != "" {
+			engineCounts[c.Assignee]++
+		}
+		human := c.Owner
+		if human == "" {
+			human = c.RequestedBy
+		}
+		if human != "" {
+			humanCounts[human]++
+		}
+	}
+	return topContributors(engineCounts), topContributors(humanCounts)
+}
+
+// buildFlaggedEngines returns the engines ry engine stats flags over the
+// window, or nil when the stats cannot be computed.
+func buildFlaggedEngines(db *gorm.DB, since, until time.Time) []orchestration.EngineStats {
+	stats, err := orchestration.ComputeEngineStats(db, since, until)
+	if err != nil {
+		return nil
+	}
+	return orchestration.FlaggedEngines(stats)
+}
+
+// topContributors returns the weeklyTopN highest counts, ties broken by name.
+func topContributors(counts map[string]int) []Contributor {
+	var out []Contributor
+	for name, n := range counts {
+		out = append(out, Contributor{Name: name, Completed: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Completed != out[j].Completed {
+			return out[i].Completed > out[j].Completed
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > weeklyTopN {
+		out = out[:weeklyTopN]
+	}
+	return out
+}
+
+// buildOldestBlocked returns the blocked cars that have sat longest as of
+// until. Cars carry no blocked-at timestamp; updated_at, last set when the
+// car blocked, stands in for it.
+func buildOldestBlocked(db *gorm.DB, until time.Time) []RiskItem {
+	var cars []models.Car
+	db.Model(&models.Car{}).
+		Select("id, title, track, blocked_reason, updated_at").
+		Where("status = ?", "blocked").
+		Order("updated_at ASC, id ASC").
+		Limit(weeklyTopN).
+		Find(&cars)
+
+	var items []RiskItem
+	for _, c := range cars {
+		items = append(items, RiskItem{
+			CarID:  c.ID,
+			Title:  c.Title,
+			Track:  c.Track,
+			Age:    until.Sub(c.UpdatedAt),
+			Detail: c.BlockedReason,
+		})
+	}
+	return items
+}
+
+// buildLongestEpics returns the epics that have been open longest as of
+// until, with their children's progress.
+func buildLongestEpics(db *gorm.DB, until time.Time) []RiskItem {
+	var epics []models.Car
+	db.Model(&models.Car{}).
+		Select("id, title, track, created_at").
+		Where("type = ? AND status NOT IN ?", "epic", models.ClosedStatuses).
+		Order("created_at ASC, id ASC").
+		Limit(weeklyTopN).
+		Find(&epics)
+
+	var items []RiskItem
+	for _, e := range epics {
+		var total, finished int64
+		db.Model(&models.Car{}).Where("parent_id = ?", e.ID).Count(&total)
+		db.Model(&models.Car{}).
+			Where("parent_id = ? AND status IN ?", e.ID, models.ClosedStatuses).
+			Count(&finished)
+		item := RiskItem{CarID: e.ID, Title: e.Title, Track: e.Track, Age: until.Sub(e.CreatedAt)}
+		if total > 0 {
+			item.Detail = fmt.Sprintf("%d/%d children done", finished, total)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// formatContributors renders a contributor section as body lines.
+func formatContributors(heading, prefix string, contributors []Contributor) []string {
+	if len(contributors) == 0 {
+		return nil
+	}
+	lines := []string{heading}
+	for _, c := range contributors {
+		lines = append(lines, fmt.Sprintf("• %s%s: %d completed", prefix, c.Name, c.Completed))
+	}
+	return lines
+}
+
+// formatFlaggedEngines renders the flagged-engines section as body lines.
+func formatFlaggedEngines(stats []orchestration.EngineStats) []string {
+	if len(stats) == 0 {
+		return nil
+	}
+	lines := []string{"**Engines Flagged**:"}
+	for _, s := range stats {
+		for _, f := range s.Flags {
+			lines = append(lines, fmt.Sprintf("• %s (%s) — %s: %s", s.EngineID, s.Track, f.Kind, f.Reason))
+		}
+	}
+	return append(lines, "Run `ry engine stats --flagged` for suggested actions.")
+}
+
+// formatRisks renders the biggest-risks section as body lines.
+func formatRisks(blocked, epics []RiskItem) []string {
+	if len(blocked) == 0 && len(epics) == 0 {
+		return nil
+	}
+	lines := []string{"**Biggest Risks**:"}
+	for _, r := range blocked {
+		line := fmt.Sprintf("• 🚧 %s blocked %s — %s", r.CarID, formatDuration(r.Age), r.Title)
+		if r.Detail != "" {
+			line += fmt.Sprintf(" (%s)", r.Detail)
+		}
+		lines = append(lines, line)
+	}
+	for _, r := range epics {
+		line := fmt.Sprintf("• 🐢 epic %s open %s — %s", r.CarID, formatDuration(r.Age), r.Title)
+		if r.Detail != "" {
+			line += fmt.Sprintf(" (%s)", r.Detail)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatDurationWithDelta formats a duration with a delta indicator showing
+// change from a previous period (e.g. "4h 10m (▼30m)"). Without a previous
+// value there is nothing to compare, so no indicator is shown.
+func formatDurationWithDelta(current, previous time.Duration) string {
+	if previous == 0 {
+		return formatDuration(current)
+	}
+	delta := current - previous
+	switch {
+	case delta >= time.Minute:
+		return fmt.Sprintf("%s (▲%s)", formatDuration(current), formatDuration(delta))
+	case delta <= -time.Minute:
+		return fmt.Sprintf("%s (▼%s)", formatDuration(current), formatDuration(-delta))
+	default:
+		return fmt.Sprintf("%s (=)", formatDuration(current))
+	}
+}
+
+// buildCoverageTrends returns, per track with coverage tracking, the coverage
+// of the last car merged before until and before since, sorted by track.
+func buildCoverageTrends(db *gorm.DB, since, until time.Time) []CoverageTrend {
+	var tracks []string
+	db.Model(&models.CoverageRecord{}).Distinct("track").Order("track").Pluck("track", &tracks)
+
+	latest := func(track string, before time.Time) (float64, bool) {
+		var rec models.CoverageRecord
+		err := db.Model(&models.CoverageRecord{}).
+			Select("coverage_records.*").
+			Joins("JOIN cars ON cars.id = coverage_records.car_id").
+			Where("coverage_records.track = ? AND cars.status = ? AND cars.completed_at < ?", track, "merged", before).
+			Order("cars.completed_at DESC, coverage_records.id DESC").
+			Take(&rec).Error
+		return rec.Coverage, err == nil
+	}
+
+	var trends []CoverageTrend
+	for _, track := range tracks {
+		cov, ok := latest(track, until)
+		if !ok {
+			continue
+		}
+		trend := CoverageTrend{Track: track, Coverage: cov}
+		trend.Previous, trend.HasPrevious = latest(track, since)
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// formatCoverageTrends renders the coverage section as body lines, with the
+// change since the previous period in percentage points.
+func formatCoverageTrends(trends []CoverageTrend) []string {
+	if len(trends) == 0 {
+		return nil
+	}
+	lines := []string{"**Coverage**:"}
+	for _, ct := range trends {
+		line := fmt.Sprintf("• %s: %.1f%%", ct.Track, ct.Coverage)
+		if ct.HasPrevious {
+			switch delta := ct.Coverage - ct.Previous; {
+			case delta >= 0.05:
+				line += fmt.Sprintf(" (▲%.1f)", delta)
+			case delta <= -0.05:
+				line += fmt.Sprintf(" (▼%.1f)", -delta)
+			default:
+				line += " (=)"
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // buildTrackBreakdown computes per-track metrics.
 func buildTrackBreakdown(db *gorm.DB, since, until time.Time) []TrackDigest {
 	var tracks []struct {
@@ -358,6 +709,122 @@ func buildTrackBreakdown(db *gorm.DB, since, until time.Time) []TrackDigest {
 	return breakdown
 }
 
+// digestTrackTypes maps each configured track with a non-code type to it.
+func digestTrackTypes(cfg *config.Config) map[string]string {
+	types := make(map[string]string)
+	for _, t := range cfg.Tracks {
+		if typ := cfg.TrackTypeFor(t.Name); typ != config.TrackTypeCode {
+			types[t.Name] = typ
+		}
+	}
+	return types
+}
+
+// groupTrackBreakdown folds the docs and config tracks of a breakdown into
+// one entry per type, named for the type and its tracks, after the code
+// tracks. Their average completion time is weighted by cars completed.
+func groupTrackBreakdown(breakdown []TrackDigest, types map[string]string) []TrackDigest {
+	if len(types) == 0 {
+		return breakdown
+	}
+	var code []TrackDigest
+	groups := make(map[string]*TrackDigest)
+	names := make(map[string][]string)
+	var order []string
+	totalSec := make(map[string]float64)
+	for _, td := range breakdown {
+		typ, ok := types[td.Track]
+		if !ok {
+			code = append(code, td)
+			continue
+		}
+		g, seen := groups[typ]
+		if !seen {
+			g = &TrackDigest{}
+			groups[typ] = g
+			order = append(order, typ)
+		}
+		g.Completed += td.Completed
+		g.Open += td.Open
+		totalSec[typ] += td.AvgCompletion.Seconds() * float64(td.Completed)
+		names[typ] = append(names[typ], td.Track)
+	}
+	sort.Strings(order)
+	for _, typ := range order {
+		g := groups[typ]
+		g.Track = fmt.Sprintf("%s (%s)", typ, strings.Join(names[typ], ", "))
+		if g.Completed > 0 {
+			g.AvgCompletion = time.Duration(totalSec[typ]/float64(g.Completed)) * time.Second
+		}
+		code = append(code, *g)
+	}
+	return code
+}
+
+// buildOwnerBreakdown computes per-owner metrics for cars that have an
+// owner, sorted by owner. Owners with nothing completed, failed, or open are
+// omitted.
+func buildOwnerBreakdown(db *gorm.DB, since, until time.Time) []OwnerDigest {
+	var cars []models.Car
+	db.Model(&models.Car{}).
+		Select("owner, status, completed_at, updated_at").
+		Where("owner != ''").
+		Find(&cars)
+
+	inPeriod := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+	byOwner := make(map[string]*OwnerDigest)
+	var owners []string
+	for _, c := range cars {
+		od, ok := byOwner[c.Owner]
+		if !ok {
+			od = &OwnerDigest{Owner: c.Owner}
+			byOwner[c.Owner] = od
+			owners = append(owners, c.Owner)
+		}
+		switch c.Status {
+		case "done", "merged":
+			if c.CompletedAt != nil && inPeriod(*c.CompletedAt) {
+				od.Completed++
+			}
+		case "merge-failed", "blocked":
+			if inPeriod(c.UpdatedAt) {
+				od.Failed++
+			}
+			od.Open++
+		case "cancelled":
+		default:
+			od.Open++
+		}
+	}
+
+	sort.Strings(owners)
+	var breakdown []OwnerDigest
+	for _, o := range owners {
+		od := byOwner[o]
+		if od.Completed == 0 && od.Failed == 0 && od.Open == 0 {
+			continue
+		}
+		breakdown = append(breakdown, *od)
+	}
+	return breakdown
+}
+
+// formatOwnerBreakdown renders the per-owner digest section as body lines.
+func formatOwnerBreakdown(breakdown []OwnerDigest) []string {
+	if len(breakdown) == 0 {
+		return nil
+	}
+	lines := []string{"**By owner**:"}
+	for _, od := range breakdown {
+		line := fmt.Sprintf("• @%s: %d completed, %d open", od.Owner, od.Completed, od.Open)
+		if od.Failed > 0 {
+			line += fmt.Sprintf(", %d failed", od.Failed)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // formatWithDelta formats an integer count with a delta indicator showing
 // change from a previous period (e.g. "12 (▲4)", "8 (▼4)", "5 (=)").
 func formatWithDelta(current, previous int) string {
@@ -404,6 +871,8 @@ func FormatDaily(report *DailyReport, dashboardURL string) FormattedEvent {
 		bodyLines = append(bodyLines, fmt.Sprintf("**Stalls**: %s", formatWithDelta(report.StallCount, report.PrevStallCount)))
 	}
 	bodyLines = append(bodyLines, fmt.Sprintf("**Engines**: %d registered", report.EngineCount))
+	bodyLines = append(bodyLines, formatFlaggedEngines(report.FlaggedEngines)...)
+	bodyLines = append(bodyLines, formatOwnerBreakdown(report.OwnerBreakdown)...)
 
 	fields := []Field{
 		{Name: "Created", Value: formatWithDelta(report.CarsCreated, report.PrevCarsCreated), Short: true},
@@ -445,25 +914,49 @@ func FormatWeekly(report *WeeklyReport, dashboardURL string) FormattedEvent {
 	bodyLines = append(bodyLines, fmt.Sprintf("**Cars Closed**: %s (%s merged)",
 		formatWithDelta(report.CarsClosed, report.PrevCarsClosed),
 		formatWithDelta(report.CarsMerged, report.PrevCarsMerged)))
+	if report.CarsCompleted > 0 || report.PrevCarsCompleted > 0 {
+		bodyLines = append(bodyLines, fmt.Sprintf("**Throughput**: %s cars completed",
+			formatWithDelta(report.CarsCompleted, report.PrevCarsCompleted)))
+	}
+	if report.CycleTime > 0 {
+		bodyLines = append(bodyLines, fmt.Sprintf("**Cycle Time**: %s median claim to completion",
+			formatDurationWithDelta(report.CycleTime, report.PrevCycleTime)))
+	}
 	if report.MergeAttempts > 0 {
 		bodyLines = append(bodyLines, fmt.Sprintf("**Merge Success Rate**: %s (%d/%d)",
 			formatRateWithDelta(report.MergeSuccessRate, report.PrevMergeSuccessRate),
 			report.CarsMerged, report.MergeAttempts))
 	}
+	if report.CarsFailed > 0 {
+		bodyLines = append(bodyLines, fmt.Sprintf("**Failure Rate**: %s (%d failed or blocked)",
+			formatRateWithDelta(report.FailureRate, report.PrevFailureRate), report.CarsFailed))
+	}
 	if report.TotalTokens > 0 {
 		bodyLines = append(bodyLines, fmt.Sprintf("**Tokens**: %s", formatTokenCount(report.TotalTokens)))
 	}
 	if report.StallCount > 0 {
 		bodyLines = append(bodyLines, fmt.Sprintf("**Stalls**: %s", formatWithDelta(report.StallCount, report.PrevStallCount)))
 	}
+	bodyLines = append(bodyLines, formatCoverageTrends(report.CoverageTrends)...)
+	bodyLines = append(bodyLines, formatContributors("**Top Engines**:", "", report.TopEngines)...)
+	bodyLines = append(bodyLines, formatFlaggedEngines(report.FlaggedEngines)...)
+	bodyLines = append(bodyLines, formatContributors("**Top Contributors**:", "@", report.TopHumans)...)
+	bodyLines = append(bodyLines, formatRisks(report.OldestBlocked, report.LongestEpics)...)
+	bodyLines = append(bodyLines, formatOwnerBreakdown(report.OwnerBreakdown)...)
 
 	fields := []Field{
 		{Name: "Closed", Value: formatWithDelta(report.CarsClosed, report.PrevCarsClosed), Short: true},
 		{Name: "Merged", Value: formatWithDelta(report.CarsMerged, report.PrevCarsMerged), Short: true},
 	}
+	if report.CycleTime > 0 {
+		fields = append(fields, Field{Name: "Cycle Time", Value: formatDurationWithDelta(report.CycleTime, report.PrevCycleTime), Short: true})
+	}
 	if report.MergeAttempts > 0 {
 		fields = append(fields, Field{Name: "Merge Rate", Value: formatRateWithDelta(report.MergeSuccessRate, report.PrevMergeSuccessRate), Short: true})
 	}
+	if report.CarsFailed > 0 {
+		fields = append(fields, Field{Name: "Failure Rate", Value: formatRateWithDelta(report.FailureRate, report.PrevFailureRate), Short: true})
+	}
 	if report.TotalTokens > 0 {
 		fields = append(fields, Field{Name: "Tokens", Value: formatTokenCount(report.TotalTokens), Short: true})
 	}
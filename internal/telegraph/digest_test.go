@@ -6,13 +6,14 @@ import (
 	"time"
 
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 // openDigestTestDB opens an in-memory SQLite DB with the tables needed for
-// digest queries (cars, engines, agent_logs).
+// digest queries (cars, engines, agent_logs, coverage_records).
 func openDigestTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
@@ -25,6 +26,7 @@ func openDigestTestDB(t *testing.T) *gorm.DB {
 		&models.Car{},
 		&models.Engine{},
 		&models.AgentLog{},
+		&models.CoverageRecord{},
 	); err != nil {
 		t.Fatalf("auto migrate: %v", err)
 	}
@@ -286,6 +288,47 @@ func TestBuildWeeklyReport_MergeSuccessRate(t *testing.T) {
 	}
 }
 
+func TestBuildWeeklyReport_CoverageTrends(t *testing.T) {
+	db := openDigestTestDB(t)
+	now := time.Now()
+	since := now.Add(-7 * 24 * time.Hour)
+	lastWeek := now.Add(-10 * 24 * time.Hour)
+	mid := now.Add(-3 * 24 * time.Hour)
+
+	db.Create(&models.Car{ID: "c-old", Title: "Old", Status: "merged", Track: "backend", CompletedAt: ptr(lastWeek)})
+	db.Create(&models.Car{ID: "c-new", Title: "New", Status: "merged", Track: "backend", CompletedAt: ptr(mid)})
+	db.Create(&models.Car{ID: "c-open", Title: "Open", Status: "blocked", Track: "backend"})
+	db.Create(&models.Car{ID: "c-fe", Title: "FE", Status: "merged", Track: "frontend", CompletedAt: ptr(mid)})
+	db.Create(&models.CoverageRecord{CarID: "c-old", Track: "backend", Coverage: 80})
+	db.Create(&models.CoverageRecord{CarID: "c-new", Track: "backend", Coverage: 82.5})
+	db.Create(&models.CoverageRecord{CarID: "c-open", Track: "backend", Coverage: 60}) // never merged
+	db.Create(&models.CoverageRecord{CarID: "c-fe", Track: "frontend", Coverage: 70})
+
+	report, err := buildWeeklyReport(db, since, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []CoverageTrend{
+		{Track: "backend", Coverage: 82.5, Previous: 80, HasPrevious: true},
+		{Track: "frontend", Coverage: 70},
+	}
+	if len(report.CoverageTrends) != len(want) {
+		t.Fatalf("CoverageTrends = %+v, want %+v", report.CoverageTrends, want)
+	}
+	for i := range want {
+		if report.CoverageTrends[i] != want[i] {
+			t.Errorf("CoverageTrends[%d] = %+v, want %+v", i, report.CoverageTrends[i], want[i])
+		}
+	}
+
+	f := FormatWeekly(report, "")
+	for _, want := range []string{"**Coverage**:", "• backend: 82.5% (▲2.5)", "• frontend: 70.0%\n"} {
+		if !strings.Contains(f.Body+"\n", want) {
+			t.Errorf("body missing %q:\n%s", want, f.Body)
+		}
+	}
+}
+
 func TestBuildWeeklyReport_CarsClosed(t *testing.T) {
 	db := openDigestTestDB(t)
 	now := time.Now()
@@ -309,6 +352,133 @@ func TestBuildWeeklyReport_CarsClosed(t *testing.T) {
 	}
 }
 
+func TestBuildWeeklyReport_FlowMetrics(t *testing.T) {
+	db := openDigestTestDB(t)
+	now := time.Now()
+	since := now.Add(-7 * 24 * time.Hour)
+	mid := now.Add(-2 * 24 * time.Hour)
+	lastWeek := now.Add(-9 * 24 * time.Hour)
+
+	// This week: 3 completed with cycle times 1h, 2h, 6h (median 2h); 1 failed.
+	for i, d := range []time.Duration{time.Hour, 2 * time.Hour, 6 * time.Hour} {
+		db.Create(&models.Car{ID: "w" + string(rune('0'+i)), Title: "W", Status: "merged", Track: "backend",
+			ClaimedAt: ptr(mid.Add(-d)), CompletedAt: ptr(mid)})
+	}
+	db.Create(&models.Car{ID: "wf", Title: "WF", Status: "merge-failed", Track: "backend", UpdatedAt: mid})
+	// Last week: 1 completed with a 4h cycle time.
+	db.Create(&models.Car{ID: "p0", Title: "P", Status: "done", Track: "backend",
+		ClaimedAt: ptr(lastWeek.Add(-4 * time.Hour)), CompletedAt: ptr(lastWeek)})
+
+	report, err := buildWeeklyReport(db, since, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.CarsCompleted != 3 || report.PrevCarsCompleted != 1 {
+		t.Errorf("CarsCompleted = %d (prev %d), want 3 (prev 1)", report.CarsCompleted, report.PrevCarsCompleted)
+	}
+	if report.CycleTime != 2*time.Hour || report.PrevCycleTime != 4*time.Hour {
+		t.Errorf("CycleTime = %s (prev %s), want 2h (prev 4h)", report.CycleTime, report.PrevCycleTime)
+	}
+	if report.CarsFailed != 1 || report.FailureRate != 25 || report.PrevFailureRate != 0 {
+		t.Errorf("failures = %d at %.1f%% (prev %.1f%%), want 1 at 25%% (prev 0%%)",
+			report.CarsFailed, report.FailureRate, report.PrevFailureRate)
+	}
+
+	f := FormatWeekly(report, "")
+	for _, want := range []string{
+		"**Throughput**: 3 (▲2) cars completed",
+		"**Cycle Time**: 2h 0m (▼2h 0m) median",
+		"**Failure Rate**: 25% (▲25%) (1 failed or blocked)",
+	} {
+		if !strings.Contains(f.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, f.Body)
+		}
+	}
+}
+
+func TestBuildWeeklyReport_ContributorsAndRisks(t *testing.T) {
+	db := openDigestTestDB(t)
+	now := time.Now()
+	since := now.Add(-7 * 24 * time.Hour)
+	mid := now.Add(-2 * 24 * time.Hour)
+
+	done := func(id, engine, owner, requester string) {
+		db.Create(&models.Car{ID: id, Title: id, Status: "merged", Track: "backend",
+			Assignee: engine, Owner: owner, RequestedBy: requester, CompletedAt: ptr(mid)})
+	}
+	done("c1", "eng-a", "alice", "")
+	done("c2", "eng-a", "", "bob")
+	done("c3", "eng-b", "alice", "bob")
+	done("c4", "eng-c", "", "")
+	done("c5", "eng-d", "", "")
+
+	db.Create(&models.Car{ID: "b-new", Title: "Fresh block", Status: "blocked", Track: "backend", UpdatedAt: now.Add(-time.Hour)})
+	db.Create(&models.Car{ID: "b-old", Title: "Stuck", Status: "blocked", Track: "backend",
+		BlockedReason: "test-failed", UpdatedAt: now.Add(-5 * 24 * time.Hour)})
+	db.Create(&models.Car{ID: "e-old", Title: "Big epic", Type: "epic", Status: "open", Track: "backend",
+		CreatedAt: now.Add(-30 * 24 * time.Hour)})
+	db.Create(&models.Car{ID: "e-done", Title: "Shipped epic", Type: "epic", Status: "done", Track: "backend",
+		CreatedAt: now.Add(-60 * 24 * time.Hour)})
+	parent := "e-old"
+	db.Create(&models.Car{ID: "e-c1", Title: "Child", Status: "done", Track: "backend", ParentID: &parent})
+	db.Create(&models.Car{ID: "e-c2", Title: "Child", Status: "open", Track: "backend", ParentID: &parent})
+
+	report, err := buildWeeklyReport(db, since, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantEngines := []Contributor{{"eng-a", 2}, {"eng-b", 1}, {"eng-c", 1}}
+	if len(report.TopEngines) != 3 || report.TopEngines[0] != wantEngines[0] ||
+		report.TopEngines[1] != wantEngines[1] || report.TopEngines[2] != wantEngines[2] {
+		t.Errorf("TopEngines = %+v, want %+v", report.TopEngines, wantEngines)
+	}
+	wantHumans := []Contributor{{"alice", 2}, {"bob", 1}}
+	if len(report.TopHumans) != 2 || report.TopHumans[0] != wantHumans[0] || report.TopHumans[1] != wantHumans[1] {
+		t.Errorf("TopHumans = %+v, want %+v", report.TopHumans, wantHumans)
+	}
+	if len(report.OldestBlocked) != 2 || report.OldestBlocked[0].CarID != "b-old" {
+		t.Errorf("OldestBlocked = %+v, want b-old first", report.OldestBlocked)
+	}
+	if len(report.LongestEpics) != 1 || report.LongestEpics[0].CarID != "e-old" ||
+		report.LongestEpics[0].Detail != "1/2 children done" {
+		t.Errorf("LongestEpics = %+v, want e-old with 1/2 children done", report.LongestEpics)
+	}
+
+	f := FormatWeekly(report, "")
+	for _, want := range []string{
+		"**Top Engines**:\n• eng-a: 2 completed",
+		"**Top Contributors**:\n• @alice: 2 completed",
+		"**Biggest Risks**:\n• 🚧 b-old blocked 5d 0h — Stuck (test-failed)",
+		"• 🐢 epic e-old open 30d 0h — Big epic (1/2 children done)",
+	} {
+		if !strings.Contains(f.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, f.Body)
+		}
+	}
+}
+
+func TestBuildWeeklyDigest_CarriesFields(t *testing.T) {
+	db := openDigestTestDB(t)
+	mid := time.Now().Add(-2 * 24 * time.Hour)
+	db.Create(&models.Car{ID: "m1", Title: "Merged", Status: "merged", Track: "backend",
+		ClaimedAt: ptr(mid.Add(-time.Hour)), CompletedAt: ptr(mid)})
+
+	w, _ := NewWatcher(WatcherOpts{DB: db})
+	evt, err := w.BuildWeeklyDigest()
+	if err != nil || evt == nil {
+		t.Fatalf("BuildWeeklyDigest = %v, %v", evt, err)
+	}
+	names := make(map[string]bool)
+	for _, f := range evt.Fields {
+		names[f.Name] = true
+	}
+	for _, want := range []string{"Closed", "Cycle Time", "backend"} {
+		if !names[want] {
+			t.Errorf("event fields missing %q: %+v", want, evt.Fields)
+		}
+	}
+}
+
 func TestBuildWeeklyReport_NoMergeAttempts(t *testing.T) {
 	db := openDigestTestDB(t)
 	now := time.Now()
@@ -369,6 +539,34 @@ func TestBuildTrackBreakdown_MultipleTracks(t *testing.T) {
 	}
 }
 
+func TestGroupTrackBreakdown(t *testing.T) {
+	breakdown := []TrackDigest{
+		{Track: "api-docs", Completed: 1, Open: 2, AvgCompletion: time.Hour},
+		{Track: "backend", Completed: 4, Open: 1, AvgCompletion: 3 * time.Hour},
+		{Track: "handbook", Completed: 3, Open: 0, AvgCompletion: 5 * time.Hour},
+		{Track: "infra", Completed: 0, Open: 1},
+	}
+	types := map[string]string{"api-docs": "docs", "handbook": "docs", "infra": "config"}
+
+	got := groupTrackBreakdown(breakdown, types)
+	want := []TrackDigest{
+		{Track: "backend", Completed: 4, Open: 1, AvgCompletion: 3 * time.Hour},
+		{Track: "config (infra)", Completed: 0, Open: 1},
+		{Track: "docs (api-docs, handbook)", Completed: 4, Open: 2, AvgCompletion: 4 * time.Hour},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("grouped = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("grouped[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got := groupTrackBreakdown(breakdown, nil); len(got) != len(breakdown) {
+		t.Errorf("no track types should leave the breakdown alone, got %+v", got)
+	}
+}
+
 func TestBuildTrackBreakdown_EmptyTrackSkipped(t *testing.T) {
 	db := openDigestTestDB(t)
 	now := time.Now()
@@ -542,6 +740,30 @@ func TestFormatWeekly_NoMergeAttempts(t *testing.T) {
 	}
 }
 
+func TestFormatWeekly_FlaggedEngines(t *testing.T) {
+	report := &WeeklyReport{
+		PeriodStart: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		CarsClosed:  4,
+		FlaggedEngines: []orchestration.EngineStats{{
+			EngineID: "eng-slow",
+			Track:    "backend",
+			Flags:    []orchestration.EngineFlag{{Kind: orchestration.FlagReview, Reason: "2 of 3 completed cars failed the merge gate (67%)"}},
+		}},
+	}
+
+	f := FormatWeekly(report, "")
+	if !strings.Contains(f.Body, "**Engines Flagged**:\n• eng-slow (backend) — review: 2 of 3 completed cars failed the merge gate (67%)") {
+		t.Errorf("body should list the flagged engine, got:\n%s", f.Body)
+	}
+	if !strings.Contains(f.Body, "ry engine stats --flagged") {
+		t.Errorf("body should point at ry engine stats, got:\n%s", f.Body)
+	}
+	if f := FormatWeekly(&WeeklyReport{CarsClosed: 1}, ""); strings.Contains(f.Body, "Engines Flagged") {
+		t.Error("body should omit the section without flagged engines")
+	}
+}
+
 func TestFormatWeekly_NoStallsOrTokens(t *testing.T) {
 	report := &WeeklyReport{
 		PeriodStart: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
@@ -874,3 +1096,60 @@ func TestFormatWeekly_FieldsAndEmoji(t *testing.T) {
 		t.Error("expected per-track fields in weekly digest")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Owner breakdown
+// ---------------------------------------------------------------------------
+
+func TestBuildOwnerBreakdown(t *testing.T) {
+	db := openDigestTestDB(t)
+	now := time.Now()
+	recent := now.Add(-2 * time.Hour)
+	since := now.Add(-24 * time.Hour)
+
+	db.Create(&models.Car{ID: "car-a1", Title: "A1", Status: "merged", Track: "backend", Owner: "alice", CompletedAt: ptr(recent)})
+	db.Create(&models.Car{ID: "car-a2", Title: "A2", Status: "open", Track: "backend", Owner: "alice"})
+	db.Create(&models.Car{ID: "car-a3", Title: "A3", Status: "merge-failed", Track: "backend", Owner: "alice"})
+	db.Create(&models.Car{ID: "car-b1", Title: "B1", Status: "in_progress", Track: "backend", Owner: "bob"})
+	db.Create(&models.Car{ID: "car-c1", Title: "C1", Status: "cancelled", Track: "backend", Owner: "carol"})
+	db.Create(&models.Car{ID: "car-x", Title: "Unowned", Status: "open", Track: "backend"})
+
+	got := buildOwnerBreakdown(db, since, now.Add(time.Minute))
+	want := []OwnerDigest{
+		{Owner: "alice", Completed: 1, Failed: 1, Open: 2},
+		{Owner: "bob", Open: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("breakdown = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("breakdown[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBuildDailyDigest_GroupByOwner(t *testing.T) {
+	db := openDigestTestDB(t)
+	recent := time.Now().Add(-2 * time.Hour)
+	db.Create(&models.Car{ID: "car-1", Title: "Done car", Status: "done", Track: "backend", Owner: "alice",
+		CompletedAt: ptr(recent), CreatedAt: recent})
+
+	plain, _ := NewWatcher(WatcherOpts{DB: db})
+	evt, err := plain.BuildDailyDigest()
+	if err != nil || evt == nil {
+		t.Fatalf("BuildDailyDigest = %v, %v", evt, err)
+	}
+	if strings.Contains(evt.Body, "By owner") {
+		t.Errorf("ungrouped digest has owner section:\n%s", evt.Body)
+	}
+
+	grouped, _ := NewWatcher(WatcherOpts{DB: db, DigestByOwner: true})
+	evt, err = grouped.BuildDailyDigest()
+	if err != nil || evt == nil {
+		t.Fatalf("BuildDailyDigest = %v, %v", evt, err)
+	}
+	if !strings.Contains(evt.Body, "**By owner**") || !strings.Contains(evt.Body, "@alice: 1 completed, 0 open") {
+		t.Errorf("grouped digest body missing owner section:\n%s", evt.Body)
+	}
+}
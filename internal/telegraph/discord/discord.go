@@ -4,12 +4,15 @@ package discord
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
 	"github.com/zulandar/railyard/internal/telegraph"
 )
 
@@ -38,9 +41,12 @@ type session interface {
 	ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart) (*discordgo.Channel, error)
 	ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
 	AddHandler(handler interface{}) func()
+	UserChannelPermissions(userID, channelID string) (int64, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
 }
 
 // realSession wraps *discordgo.Session to implement the session interface.
@@ -70,6 +76,9 @@ func (r *realSession) ChannelMessageSendEmbed(channelID string, embed *discordgo
 func (r *realSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
 	return r.s.ChannelMessageSendComplex(channelID, data, options...)
 }
+func (r *realSession) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return r.s.ChannelMessageEdit(channelID, messageID, content, options...)
+}
 func (r *realSession) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart) (*discordgo.Channel, error) {
 	return r.s.MessageThreadStartComplex(channelID, messageID, data)
 }
@@ -79,6 +88,18 @@ func (r *realSession) ChannelMessages(channelID string, limit int, beforeID, aft
 func (r *realSession) AddHandler(handler interface{}) func() {
 	return r.s.AddHandler(handler)
 }
+func (r *realSession) UserChannelPermissions(userID, channelID string) (int64, error) {
+	perms, err := r.s.State.UserChannelPermissions(userID, channelID)
+	if err == nil {
+		return perms, nil
+	}
+	// State cache miss (guild members aren't cached without the members
+	// intent) — fall back to the REST API.
+	return r.s.UserChannelPermissions(userID, channelID)
+}
+func (r *realSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return r.s.UserChannelCreate(recipientID, options...)
+}
 
 // Adapter implements telegraph.Adapter for Discord via the Gateway WebSocket.
 type Adapter struct {
@@ -86,6 +107,8 @@ type Adapter struct {
 	botToken        string
 	channelID       string          // default channel for messages
 	allowedChannels map[string]bool // channels the bot may respond in; nil/empty = all
+	httpClient      *http.Client
+	dialer          *websocket.Dialer
 	botUserID       string
 	mu              sync.Mutex
 	connected       bool
@@ -118,6 +141,10 @@ type AdapterOpts struct {
 	BotToken        string   // Discord bot token
 	ChannelID       string   // default channel to post to
 	AllowedChannels []string // channel IDs the bot may respond in; empty = all
+	// HTTPClient and Dialer, when set, replace discordgo's REST client and
+	// gateway dialer (e.g. to route through a proxy; see internal/outbound).
+	HTTPClient *http.Client
+	Dialer     *websocket.Dialer
 	// For testing: inject a mock session instead of real Discord API.
 	Session session
 }
@@ -140,6 +167,8 @@ func New(opts AdapterOpts) (*Adapter, error) {
 		botToken:        opts.BotToken,
 		channelID:       opts.ChannelID,
 		allowedChannels: allowed,
+		httpClient:      opts.HTTPClient,
+		dialer:          opts.Dialer,
 		inbound:         make(chan telegraph.InboundMessage, 100),
 		baseBackoff:     baseBackoff,
 		maxBackoff:      maxBackoff,
@@ -173,6 +202,12 @@ func (a *Adapter) Connect(ctx context.Context) error {
 			return fmt.Errorf("discord: create session: %w", err)
 		}
 		dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+		if a.httpClient != nil {
+			dg.Client = a.httpClient
+		}
+		if a.dialer != nil {
+			dg.Dialer = a.dialer
+		}
 		a.sess = &realSession{s: dg}
 	}
 
@@ -278,10 +313,17 @@ func (a *Adapter) teardown() {
 
 // Send delivers a message to Discord. Translates OutboundMessage to Discord Embeds.
 func (a *Adapter) Send(ctx context.Context, msg telegraph.OutboundMessage) error {
+	_, err := a.Post(ctx, msg)
+	return err
+}
+
+// Post delivers a message like Send and returns the created message's ID.
+// Implements telegraph.MessagePoster.
+func (a *Adapter) Post(ctx context.Context, msg telegraph.OutboundMessage) (string, error) {
 	a.mu.Lock()
 	if !a.connected {
 		a.mu.Unlock()
-		return fmt.Errorf("discord: not connected")
+		return "", fmt.Errorf("discord: not connected")
 	}
 	a.mu.Unlock()
 
@@ -294,22 +336,129 @@ func (a *Adapter) Send(ctx context.Context, msg telegraph.OutboundMessage) error
 		channelID = a.channelID
 	}
 	if channelID == "" {
-		return fmt.Errorf("discord: no channel specified")
+		return "", fmt.Errorf("discord: no channel specified")
 	}
 
 	// Build the message.
 	data := buildMessageSend(msg)
 
+	var sent *discordgo.Message
+	err := a.retryOnRateLimit(ctx, func() error {
+		var sendErr error
+		sent, sendErr = a.sess.ChannelMessageSendComplex(channelID, data)
+		return sendErr
+	})
+	if err != nil {
+		return "", fmt.Errorf("discord: send message: %w", err)
+	}
+	if sent == nil {
+		return "", nil
+	}
+	return sent.ID, nil
+}
+
+// EditMessage replaces the content of a message posted by Post. As with
+// Post, a thread ID takes precedence since threads are channels. Implements
+// telegraph.MessageEditor.
+func (a *Adapter) EditMessage(ctx context.Context, channelID, threadID, messageID, text string) error {
+	a.mu.Lock()
+	if !a.connected {
+		a.mu.Unlock()
+		return fmt.Errorf("discord: not connected")
+	}
+	a.mu.Unlock()
+
+	target := threadID
+	if target == "" {
+		target = channelID
+	}
+	if target == "" {
+		target = a.channelID
+	}
+	err := a.retryOnRateLimit(ctx, func() error {
+		_, editErr := a.sess.ChannelMessageEdit(target, messageID, text)
+		return editErr
+	})
+	if err != nil {
+		return fmt.Errorf("discord: edit message: %w", err)
+	}
+	return nil
+}
+
+// SendDirect opens (or reuses) the DM channel with userID and posts msg
+// there. Implements telegraph.DirectMessenger.
+func (a *Adapter) SendDirect(ctx context.Context, userID string, msg telegraph.OutboundMessage) error {
+	a.mu.Lock()
+	if !a.connected {
+		a.mu.Unlock()
+		return fmt.Errorf("discord: not connected")
+	}
+	a.mu.Unlock()
+
+	var dm *discordgo.Channel
 	err := a.retryOnRateLimit(ctx, func() error {
-		_, sendErr := a.sess.ChannelMessageSendComplex(channelID, data)
+		var openErr error
+		dm, openErr = a.sess.UserChannelCreate(userID)
+		return openErr
+	})
+	if err != nil {
+		return fmt.Errorf("discord: open DM with %s: %w", userID, err)
+	}
+
+	data := buildMessageSend(msg)
+	err = a.retryOnRateLimit(ctx, func() error {
+		_, sendErr := a.sess.ChannelMessageSendComplex(dm.ID, data)
 		return sendErr
 	})
 	if err != nil {
-		return fmt.Errorf("discord: send message: %w", err)
+		return fmt.Errorf("discord: send DM to %s: %w", userID, err)
 	}
 	return nil
 }
 
+// discordPermissionCaps maps telegraph capabilities to the channel permission
+// bits the bot needs for them.
+var discordPermissionCaps = []struct {
+	name string
+	bits int64
+	desc string
+}{
+	{telegraph.CapSendMessage, discordgo.PermissionViewChannel | discordgo.PermissionSendMessages, "View Channel, Send Messages"},
+	{telegraph.CapCreateThread, discordgo.PermissionCreatePublicThreads | discordgo.PermissionSendMessagesInThreads, "Create Public Threads, Send Messages in Threads"},
+	{telegraph.CapReadHistory, discordgo.PermissionReadMessageHistory, "Read Message History"},
+	{telegraph.CapUploadFiles, discordgo.PermissionAttachFiles, "Attach Files"},
+}
+
+// CheckPermissions resolves the bot's effective permissions in channelID and
+// maps them to telegraph capabilities. Implements telegraph.PermissionChecker.
+func (a *Adapter) CheckPermissions(ctx context.Context, channelID string) ([]telegraph.Capability, error) {
+	a.mu.Lock()
+	connected, botID := a.connected, a.botUserID
+	a.mu.Unlock()
+	if !connected {
+		return nil, fmt.Errorf("discord: not connected")
+	}
+	if botID == "" {
+		return nil, fmt.Errorf("discord: bot user ID unknown (gateway READY not received)")
+	}
+
+	perms, err := a.sess.UserChannelPermissions(botID, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("discord: channel permissions: %w", err)
+	}
+
+	caps := make([]telegraph.Capability, 0, len(discordPermissionCaps))
+	for _, pc := range discordPermissionCaps {
+		c := telegraph.Capability{Name: pc.name, Status: telegraph.CapabilityOK, Detail: pc.desc}
+		if perms&discordgo.PermissionAdministrator == 0 && perms&pc.bits != pc.bits {
+			c.Status = telegraph.CapabilityMissing
+			c.Detail = "missing permission: " + pc.desc
+		}
+		caps = append(caps, c)
+	}
+	return caps, nil
+}
+
 // ThreadHistory retrieves messages from a Discord thread channel.
 // Discord threads are actual channel objects with their own IDs, so threadID
 // is the channel ID of the thread.
@@ -352,6 +501,7 @@ func (a *Adapter) ThreadHistory(ctx context.Context, channelID, threadID string,
 
 		for _, m := range msgs {
 			allMsgs = append(allMsgs, telegraph.ThreadMessage{
+				MessageID: m.ID,
 				UserID:    m.Author.ID,
 				UserName:  m.Author.Username,
 				Text:      m.Content,
@@ -375,6 +525,59 @@ func (a *Adapter) ThreadHistory(ctx context.Context, channelID, threadID string,
 	return allMsgs, nil
 }
 
+// ThreadHistorySince returns the messages posted in the thread after the
+// message afterMessageID, oldest first. Implements
+// telegraph.ThreadHistorySincer.
+func (a *Adapter) ThreadHistorySince(ctx context.Context, channelID, threadID, afterMessageID string, limit int) ([]telegraph.ThreadMessage, error) {
+	a.mu.Lock()
+	if !a.connected {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("discord: not connected")
+	}
+	a.mu.Unlock()
+
+	targetChannel := threadID
+	if targetChannel == "" {
+		targetChannel = channelID
+	}
+
+	var allMsgs []telegraph.ThreadMessage
+	afterID := afterMessageID
+	for {
+		var msgs []*discordgo.Message
+		err := a.retryOnRateLimit(ctx, func() error {
+			var apiErr error
+			msgs, apiErr = a.sess.ChannelMessages(targetChannel, defaultPageSize, "", afterID, "")
+			return apiErr
+		})
+		if err != nil {
+			return nil, fmt.Errorf("discord: channel messages: %w", err)
+		}
+
+		// Each page comes back newest first; walk it oldest first.
+		for i := len(msgs) - 1; i >= 0; i-- {
+			m := msgs[i]
+			allMsgs = append(allMsgs, telegraph.ThreadMessage{
+				MessageID: m.ID,
+				UserID:    m.Author.ID,
+				UserName:  m.Author.Username,
+				Text:      m.Content,
+				Timestamp: m.Timestamp,
+			})
+		}
+		if len(msgs) < defaultPageSize || (limit > 0 && len(allMsgs) >= limit) {
+			break
+		}
+		// Page forwards from the newest message of this page.
+		afterID = msgs[0].ID
+	}
+
+	if limit > 0 && len(allMsgs) > limit {
+		allMsgs = allMsgs[len(allMsgs)-limit:]
+	}
+	return allMsgs, nil
+}
+
 // Close gracefully shuts down the adapter connection.
 func (a *Adapter) Close() error {
 	a.mu.Lock()
@@ -481,9 +684,50 @@ func (a *Adapter) handleMessage(m *discordgo.MessageCreate) {
 		UserName:  m.Author.Username,
 		Text:      m.Content,
 		Timestamp: ts,
+		Files:     inboundFiles(m.Attachments),
 	})
 }
 
+// inboundFiles maps the files attached to a message.
+func inboundFiles(atts []*discordgo.MessageAttachment) []telegraph.InboundFile {
+	var out []telegraph.InboundFile
+	for _, att := range atts {
+		out = append(out, telegraph.InboundFile{
+			ID:       att.ID,
+			Name:     att.Filename,
+			MimeType: att.ContentType,
+			Size:     int64(att.Size),
+			URL:      att.URL,
+		})
+	}
+	return out
+}
+
+// DownloadFile implements telegraph.FileDownloader. Discord attachment URLs
+// are signed CDN links that need no bot token.
+func (a *Adapter) DownloadFile(ctx context.Context, f telegraph.InboundFile, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return fmt.Errorf("discord: download %s: %w", f.Name, err)
+	}
+	client := a.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("discord: download %s: %w", f.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("discord: download %s: HTTP %d", f.Name, resp.StatusCode)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("discord: download %s: %w", f.Name, err)
+	}
+	return nil
+}
+
 // buildMessageSend translates an OutboundMessage into a Discord MessageSend.
 func buildMessageSend(msg telegraph.OutboundMessage) *discordgo.MessageSend {
 	data := &discordgo.MessageSend{
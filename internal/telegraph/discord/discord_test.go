@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
@@ -28,10 +29,22 @@ type mockSession struct {
 	threadResponse *discordgo.Channel
 	messages       []*discordgo.Message
 	messagesErr    error
+	lastAfterID    string
 	handler        interface{}
 	readyHandler   func(*discordgo.Session, *discordgo.Ready)
 	removeCount    int
 	channels       map[string]*discordgo.Channel // for Channel() lookups
+	perms          int64                         // returned by UserChannelPermissions
+	permsErr       error
+	dmRecipients   []string // UserChannelCreate calls
+	dmErr          error
+	edits          []editedMessage
+}
+
+type editedMessage struct {
+	channelID string
+	messageID string
+	content   string
 }
 
 type sentMessage struct {
@@ -96,6 +109,16 @@ func (m *mockSession) ChannelMessageSendComplex(channelID string, data *discordg
 	return &discordgo.Message{ID: "msg-123"}, nil
 }
 
+func (m *mockSession) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sendErr != nil {
+		return nil, m.sendErr
+	}
+	m.edits = append(m.edits, editedMessage{channelID: channelID, messageID: messageID, content: content})
+	return &discordgo.Message{ID: messageID}, nil
+}
+
 func (m *mockSession) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart) (*discordgo.Channel, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -109,6 +132,7 @@ func (m *mockSession) MessageThreadStartComplex(channelID, messageID string, dat
 func (m *mockSession) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.lastAfterID = afterID
 	if m.messagesErr != nil {
 		return nil, m.messagesErr
 	}
@@ -129,6 +153,22 @@ func (m *mockSession) AddHandler(handler interface{}) func() {
 	}
 }
 
+func (m *mockSession) UserChannelPermissions(userID, channelID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.perms, m.permsErr
+}
+
+func (m *mockSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dmErr != nil {
+		return nil, m.dmErr
+	}
+	m.dmRecipients = append(m.dmRecipients, recipientID)
+	return &discordgo.Channel{ID: "dm-" + recipientID, Type: discordgo.ChannelTypeDM}, nil
+}
+
 // fireReady simulates the gateway delivering the READY event to the registered
 // handler, as discordgo does asynchronously after Open() in production.
 func (m *mockSession) fireReady(userID string) {
@@ -525,6 +565,53 @@ func TestHandleMessage_NonThreadChannel(t *testing.T) {
 	}
 }
 
+func TestHandleMessage_Attachments(t *testing.T) {
+	a, _ := newTestAdapter(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/spec.md" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("# spec"))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := a.Listen(ctx)
+
+	a.handleMessage(&discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        "402",
+			ChannelID: "C1",
+			Content:   "here is the spec",
+			Author:    &discordgo.User{ID: "U1", Username: "Alice"},
+			Attachments: []*discordgo.MessageAttachment{
+				{ID: "A1", Filename: "spec.md", ContentType: "text/markdown", Size: 6, URL: srv.URL + "/spec.md"},
+			},
+		},
+	})
+
+	var msg telegraph.InboundMessage
+	select {
+	case msg = <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	if len(msg.Files) != 1 || msg.Files[0].Name != "spec.md" || msg.Files[0].MimeType != "text/markdown" {
+		t.Fatalf("files = %+v", msg.Files)
+	}
+
+	var buf strings.Builder
+	if err := a.DownloadFile(ctx, msg.Files[0], &buf); err != nil || buf.String() != "# spec" {
+		t.Errorf("DownloadFile = %q, %v", buf.String(), err)
+	}
+	if err := a.DownloadFile(ctx, telegraph.InboundFile{Name: "gone", URL: srv.URL + "/gone"}, &buf); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("missing file: err = %v, want HTTP 404", err)
+	}
+}
+
 func TestHandleMessage_UnknownChannel(t *testing.T) {
 	a, _ := newTestAdapter(t)
 
@@ -689,6 +776,78 @@ func TestSend_PostError(t *testing.T) {
 	}
 }
 
+// --- EditMessage tests ---
+
+func TestEditMessage_TargetsThread(t *testing.T) {
+	a, sess := newTestAdapter(t)
+
+	if err := a.EditMessage(context.Background(), "C1", "thread-456", "msg-123", "more output"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.edits) != 1 {
+		t.Fatalf("edits = %d, want 1", len(sess.edits))
+	}
+	got := sess.edits[0]
+	if got.channelID != "thread-456" || got.messageID != "msg-123" || got.content != "more output" {
+		t.Errorf("edit = %+v", got)
+	}
+}
+
+func TestEditMessage_NotConnected(t *testing.T) {
+	sess := newMockSession()
+	a, _ := New(AdapterOpts{Session: sess})
+
+	if err := a.EditMessage(context.Background(), "C1", "", "msg-123", "x"); err == nil {
+		t.Fatal("expected error for not connected")
+	}
+}
+
+// --- SendDirect tests ---
+
+func TestSendDirect_OpensDMChannel(t *testing.T) {
+	a, sess := newTestAdapter(t)
+
+	err := a.SendDirect(context.Background(), "U42", telegraph.OutboundMessage{
+		ChannelID: "C1",
+		Text:      "your car merged",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sess.dmRecipients) != 1 || sess.dmRecipients[0] != "U42" {
+		t.Fatalf("dmRecipients = %v, want [U42]", sess.dmRecipients)
+	}
+	last := sess.lastSent()
+	if last.channelID != "dm-U42" {
+		t.Errorf("channel = %q, want dm-U42", last.channelID)
+	}
+	if last.data.Content != "your car merged" {
+		t.Errorf("content = %q, want 'your car merged'", last.data.Content)
+	}
+}
+
+func TestSendDirect_OpenError(t *testing.T) {
+	a, sess := newTestAdapter(t)
+	sess.dmErr = fmt.Errorf("cannot send messages to this user")
+
+	err := a.SendDirect(context.Background(), "U42", telegraph.OutboundMessage{Text: "hi"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if sess.sentCount() != 0 {
+		t.Errorf("sent %d messages, want 0", sess.sentCount())
+	}
+}
+
+func TestSendDirect_NotConnected(t *testing.T) {
+	sess := newMockSession()
+	a, _ := New(AdapterOpts{Session: sess, ChannelID: "C1"})
+
+	if err := a.SendDirect(context.Background(), "U42", telegraph.OutboundMessage{Text: "hi"}); err == nil {
+		t.Fatal("expected error for not connected")
+	}
+}
+
 // --- ThreadHistory tests ---
 
 func TestThreadHistory_Success(t *testing.T) {
@@ -727,6 +886,27 @@ func TestThreadHistory_Success(t *testing.T) {
 	}
 }
 
+func TestThreadHistorySince_OldestFirst(t *testing.T) {
+	a, sess := newTestAdapter(t)
+	now := time.Now()
+	// Discord returns messages after the cursor newest first.
+	sess.messages = []*discordgo.Message{
+		{ID: "msg3", Content: "third", Author: &discordgo.User{ID: "U2", Username: "Bob"}, Timestamp: now.Add(2 * time.Second)},
+		{ID: "msg2", Content: "second", Author: &discordgo.User{ID: "U1", Username: "Alice"}, Timestamp: now.Add(time.Second)},
+	}
+
+	msgs, err := a.ThreadHistorySince(context.Background(), "C1", "thread-1", "msg1", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].MessageID != "msg2" || msgs[1].MessageID != "msg3" {
+		t.Fatalf("msgs = %+v, want msg2 then msg3", msgs)
+	}
+	if sess.lastAfterID != "msg1" {
+		t.Errorf("after = %q, want msg1", sess.lastAfterID)
+	}
+}
+
 func TestThreadHistory_NotConnected(t *testing.T) {
 	sess := newMockSession()
 	a, _ := New(AdapterOpts{Session: sess})
@@ -1882,3 +2062,70 @@ func TestClose_NoRaceWithInflightHandler(t *testing.T) {
 		t.Fatal("consumer did not terminate after Close")
 	}
 }
+
+// --- Post / CheckPermissions tests ---
+
+func TestPost_ReturnsMessageID(t *testing.T) {
+	a, sess := newTestAdapter(t)
+
+	id, err := a.Post(context.Background(), telegraph.OutboundMessage{ChannelID: "C1", Text: "hello"})
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if id != "msg-123" {
+		t.Errorf("id = %q, want msg-123", id)
+	}
+	if sess.lastSent().channelID != "C1" {
+		t.Errorf("channel = %q, want C1", sess.lastSent().channelID)
+	}
+}
+
+func TestCheckPermissions_MapsBits(t *testing.T) {
+	a, sess := newTestAdapter(t)
+	sess.perms = discordgo.PermissionViewChannel | discordgo.PermissionSendMessages |
+		discordgo.PermissionReadMessageHistory
+
+	caps, err := a.CheckPermissions(context.Background(), "C1")
+	if err != nil {
+		t.Fatalf("check permissions: %v", err)
+	}
+	got := make(map[string]string)
+	for _, c := range caps {
+		got[c.Name] = c.Status
+	}
+	want := map[string]string{
+		telegraph.CapSendMessage:  telegraph.CapabilityOK,
+		telegraph.CapCreateThread: telegraph.CapabilityMissing,
+		telegraph.CapReadHistory:  telegraph.CapabilityOK,
+		telegraph.CapUploadFiles:  telegraph.CapabilityMissing,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %q, want %q", name, got[name], status)
+		}
+	}
+}
+
+func TestCheckPermissions_AdministratorGrantsAll(t *testing.T) {
+	a, sess := newTestAdapter(t)
+	sess.perms = discordgo.PermissionAdministrator
+
+	caps, err := a.CheckPermissions(context.Background(), "C1")
+	if err != nil {
+		t.Fatalf("check permissions: %v", err)
+	}
+	for _, c := range caps {
+		if c.Status != telegraph.CapabilityOK {
+			t.Errorf("%s = %q, want ok for administrator", c.Name, c.Status)
+		}
+	}
+}
+
+func TestCheckPermissions_Error(t *testing.T) {
+	a, sess := newTestAdapter(t)
+	sess.permsErr = fmt.Errorf("unknown channel")
+
+	if _, err := a.CheckPermissions(context.Background(), "C1"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,110 @@
+package telegraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// BuildEpicRollups returns a rollup event for each epic sent out by ry epic
+// dispatch whose children progressed since its last rollup. Rollups carry
+// the epic's car ID, so with thread-capable platforms they land in the
+// epic's car thread: one thread follows the epic from dispatch to close.
+// An epic's last rollup reports it closed; epics that closed before the
+// watcher first saw them get none.
+func (w *Watcher) BuildEpicRollups() ([]DetectedEvent, error) {
+	epics, err := car.DispatchedEpics(w.db)
+	if err != nil {
+		return nil, err
+	}
+	var reservations []models.EpicReservation
+	if len(epics) > 0 {
+		if err := w.db.Where("engines > 0").Order("track ASC").Find(&reservations).Error; err != nil {
+			return nil, fmt.Errorf("telegraph: epic reservations: %w", err)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []DetectedEvent
+	for _, e := range epics {
+		closed := slices.Contains(models.EpicClosedStatuses, e.Status)
+		last, seen := w.epicRollups[e.ID]
+		if closed && (!seen || last == "") {
+			// Closed before its first rollup, or its closing rollup
+			// was already sent.
+			w.epicRollups[e.ID] = ""
+			continue
+		}
+		summary, err := car.ChildrenSummary(w.db, e.ID)
+		if err != nil {
+			return events, fmt.Errorf("telegraph: epic %s: %w", e.ID, err)
+		}
+		body, fields := epicProgress(summary, reservations, e.ID, closed)
+		if body == last && !closed {
+			continue
+		}
+		if closed {
+			w.epicRollups[e.ID] = ""
+		} else {
+			w.epicRollups[e.ID] = body
+		}
+		events = append(events, DetectedEvent{
+			Type:      EventEpicRollup,
+			Timestamp: w.clock.Now(),
+			CarID:     e.ID,
+			NewStatus: e.Status,
+			Track:     e.Track,
+			Title:     e.Title,
+			Owner:     e.Owner,
+			Body:      body,
+			Fields:    fields,
+		})
+	}
+	return events, nil
+}
+
+// epicProgress renders an epic's rollup body and fields from its children's
+// status counts and the engines reserved for it.
+func epicProgress(summary []car.StatusCount, reservations []models.EpicReservation, epicID string, closed bool) (string, []Field) {
+	var total, resolved, active int
+	var parts []string
+	for _, sc := range summary {
+		total += sc.Count
+		switch {
+		case slices.Contains(models.TerminalStatuses, sc.Status):
+			resolved += sc.Count
+		case sc.Status == "claimed" || sc.Status == "in_progress":
+			active += sc.Count
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", sc.Count, sc.Status))
+	}
+	pct := 0
+	if total > 0 {
+		pct = resolved * 100 / total
+	}
+
+	lines := []string{fmt.Sprintf("**Progress**: %d/%d children merged, cancelled, or reverted (%d%%)", resolved, total, pct)}
+	if len(parts) > 0 {
+		lines = append(lines, "**Children**: "+strings.Join(parts, ", "))
+	}
+	var reserved []string
+	for _, r := range reservations {
+		if r.EpicID == epicID {
+			reserved = append(reserved, fmt.Sprintf("%s %d", r.Track, r.Engines))
+		}
+	}
+	if len(reserved) > 0 && !closed {
+		lines = append(lines, fmt.Sprintf("**Engines**: %d working; reserved %s", active, strings.Join(reserved, ", ")))
+	}
+
+	fields := []Field{
+		{Name: "Merged", Value: fmt.Sprintf("%d/%d", resolved, total), Short: true},
+		{Name: "Working", Value: fmt.Sprintf("%d", active), Short: true},
+	}
+	return strings.Join(lines, "\n"), fields
+}
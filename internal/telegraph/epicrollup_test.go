@@ -0,0 +1,54 @@
+package telegraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestBuildEpicRollups(t *testing.T) {
+	db := openWatcherTestDB(t)
+	if err := db.AutoMigrate(&models.EpicReservation{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	epicID := "car-epic"
+	db.Create(&models.Car{ID: epicID, Title: "Checkout", Type: "epic", Track: "backend", Status: "open", Owner: "alice"})
+	db.Create(&models.Car{ID: "car-a", Title: "A", Track: "backend", Status: "in_progress", ParentID: &epicID})
+	db.Create(&models.Car{ID: "car-b", Title: "B", Track: "backend", Status: "open", ParentID: &epicID})
+	db.Create(&models.EpicReservation{EpicID: epicID, Track: "backend", Engines: 2})
+	// An epic that closed before the watcher saw it gets no rollup.
+	db.Create(&models.Car{ID: "car-old", Title: "Old", Type: "epic", Status: "merged"})
+	db.Create(&models.EpicReservation{EpicID: "car-old", Track: "backend", Engines: 1})
+
+	w, _ := NewWatcher(WatcherOpts{DB: db})
+	events, err := w.BuildEpicRollups()
+	if err != nil {
+		t.Fatalf("BuildEpicRollups: %v", err)
+	}
+	if len(events) != 1 || events[0].CarID != epicID || events[0].Type != EventEpicRollup {
+		t.Fatalf("events = %+v, want one rollup for %s", events, epicID)
+	}
+	if body := events[0].Body; !strings.Contains(body, "0/2 children merged") || !strings.Contains(body, "1 working; reserved backend 2") {
+		t.Errorf("body = %q", body)
+	}
+
+	if events, _ := w.BuildEpicRollups(); len(events) != 0 {
+		t.Errorf("unchanged epic rolled up again: %+v", events)
+	}
+
+	db.Model(&models.Car{}).Where("id = ?", "car-a").Update("status", "merged")
+	db.Model(&models.Car{}).Where("id = ?", "car-b").Update("status", "reverted")
+	db.Model(&models.Car{}).Where("id = ?", epicID).Update("status", "merged")
+	events, _ = w.BuildEpicRollups()
+	if len(events) != 1 || !strings.Contains(events[0].Body, "2/2 children merged") {
+		t.Fatalf("closing rollup = %+v", events)
+	}
+	f := FormatEpicRollup(events[0], "")
+	if !strings.Contains(f.Title, "Epic car-epic merged: Checkout") || f.Severity != "success" {
+		t.Errorf("formatted = %q (%s)", f.Title, f.Severity)
+	}
+	if events, _ := w.BuildEpicRollups(); len(events) != 0 {
+		t.Errorf("closed epic rolled up twice: %+v", events)
+	}
+}
@@ -2,8 +2,10 @@ package telegraph
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
 )
 
@@ -48,6 +50,8 @@ func statusEmoji(status string) string {
 		return "⚠️"
 	case "merge-failed":
 		return "❌"
+	case "needs-attention":
+		return "⚠️"
 	case "cancelled":
 		return "🚫"
 	default:
@@ -89,6 +93,8 @@ func carStatusVerb(newStatus string) string {
 		return "blocked"
 	case "merge-failed":
 		return "merge failed"
+	case "needs-attention":
+		return "needs attention"
 	case "cancelled":
 		return "cancelled"
 	case "draft":
@@ -103,7 +109,7 @@ func carStatusSeverity(newStatus string) string {
 	switch newStatus {
 	case "done", "merged":
 		return "success"
-	case "blocked", "merge-failed":
+	case "blocked", "merge-failed", "needs-attention":
 		return "warning"
 	case "cancelled":
 		return "info"
@@ -141,6 +147,9 @@ func FormatCarEvent(event DetectedEvent, dashboardURL string) FormattedEvent {
 	if event.Track != "" {
 		fields = append(fields, Field{Name: "Track", Value: event.Track, Short: true})
 	}
+	if event.Owner != "" {
+		fields = append(fields, Field{Name: "Owner", Value: "@" + event.Owner, Short: true})
+	}
 
 	return FormattedEvent{
 		Title:    title,
@@ -209,6 +218,67 @@ func FormatEscalation(event DetectedEvent, dashboardURL string) FormattedEvent {
 		fields = append(fields, Field{Name: "Priority", Value: event.Priority, Short: true})
 	}
 
+	body := event.Body
+	if event.CarStatus == "merged" {
+		// The car already landed; offer the one-shot revert.
+		body += fmt.Sprintf("\n\nOne-shot revert: `!ry car revert %s`", event.CarID)
+	}
+
+	return FormattedEvent{
+		Title:    title,
+		Body:     body,
+		Severity: severity,
+		Color:    severityColor(severity),
+		Fields:   fields,
+	}
+}
+
+// FormatYardPauseEvent formats a yard pause or resume.
+func FormatYardPauseEvent(event DetectedEvent) FormattedEvent {
+	if !event.Paused {
+		return FormattedEvent{
+			Title:    "▶️ Yard resumed",
+			Body:     "Engines are claiming cars and the yardmaster is merging again.",
+			Severity: "success",
+			Color:    ColorSuccess,
+		}
+	}
+	title := "⏸️ Yard paused"
+	if event.PausedBy != "" {
+		title += " by " + event.PausedBy
+	}
+	var bodyParts []string
+	if event.Body != "" {
+		bodyParts = append(bodyParts, "Reason: "+event.Body)
+	}
+	bodyParts = append(bodyParts, "Engines finish their current cars; no new claims, merges, or car creation until `ry resume`.")
+	return FormattedEvent{
+		Title:    title,
+		Body:     strings.Join(bodyParts, "\n"),
+		Severity: "warning",
+		Color:    ColorWarning,
+	}
+}
+
+// FormatEpicRollup formats a dispatched epic's progress rollup. The body
+// and fields come from Watcher.BuildEpicRollups.
+func FormatEpicRollup(event DetectedEvent, dashboardURL string) FormattedEvent {
+	title := fmt.Sprintf("🚂 Epic %s", event.CarID)
+	severity := "info"
+	if slices.Contains(models.EpicClosedStatuses, event.NewStatus) {
+		title = fmt.Sprintf("%s Epic %s %s", statusEmoji(event.NewStatus), event.CarID, carStatusVerb(event.NewStatus))
+		severity = carStatusSeverity(event.NewStatus)
+	}
+	if event.Title != "" {
+		title += ": " + event.Title
+	}
+
+	fields := []Field{{Name: "Epic", Value: carLink(event.CarID, dashboardURL), Short: true}}
+	fields = append(fields, event.Fields...)
+	if event.Owner != "" {
+		fields = append(fields, Field{Name: "Owner", Value: "@" + event.Owner, Short: true})
+	}
+
 	return FormattedEvent{
 		Title:    title,
 		Body:     event.Body,
@@ -237,6 +307,9 @@ func FormatPulse(info *orchestration.StatusInfo, dashboardURL string) FormattedE
 	}
 
 	var bodyLines []string
+	if info.Pause.Paused {
+		bodyLines = append(bodyLines, fmt.Sprintf("**Yard %s**", info.Pause))
+	}
 	bodyLines = append(bodyLines, fmt.Sprintf("**Engines**: %d total, %d working", engineCount, workingEngines))
 	bodyLines = append(bodyLines, fmt.Sprintf("**Cars**: %d active, %d ready, %d done, %d blocked",
 		totalActive, totalReady, totalDone, totalBlocked))
@@ -148,6 +148,36 @@ func TestFormatCarEvent_NoTrackField(t *testing.T) {
 	}
 }
 
+func TestFormatCarEvent_OwnerField(t *testing.T) {
+	e := FormatCarEvent(DetectedEvent{CarID: "car-1", NewStatus: "merged", Owner: "alice"}, "")
+	found := false
+	for _, f := range e.Fields {
+		if f.Name == "Owner" && f.Value == "@alice" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("fields = %+v, want Owner @alice", e.Fields)
+	}
+}
+
+// --- FormatYardPauseEvent tests ---
+
+func TestFormatYardPauseEvent(t *testing.T) {
+	e := FormatYardPauseEvent(DetectedEvent{Paused: true, PausedBy: "alice", Body: "bad deploy"})
+	if e.Title != "⏸️ Yard paused by alice" {
+		t.Errorf("title = %q", e.Title)
+	}
+	if !strings.Contains(e.Body, "Reason: bad deploy") || e.Color != ColorWarning {
+		t.Errorf("paused event = %+v", e)
+	}
+
+	e = FormatYardPauseEvent(DetectedEvent{PausedBy: "alice"})
+	if e.Title != "▶️ Yard resumed" || e.Severity != "success" {
+		t.Errorf("resumed event = %+v", e)
+	}
+}
+
 // --- FormatStallEvent tests ---
 
 func TestFormatStallEvent_WithCarAndTrack(t *testing.T) {
@@ -291,6 +321,18 @@ func TestFormatEscalation_NoCarField(t *testing.T) {
 	}
 }
 
+func TestFormatEscalation_RevertHint(t *testing.T) {
+	e := FormatEscalation(DetectedEvent{FromAgent: "yardmaster", CarID: "car-1", CarStatus: "merged", Body: "main is red"}, "")
+	if !strings.Contains(e.Body, "`!ry car revert car-1`") {
+		t.Errorf("merged car escalation should offer a revert, got %q", e.Body)
+	}
+
+	e = FormatEscalation(DetectedEvent{FromAgent: "yardmaster", CarID: "car-1", CarStatus: "blocked", Body: "stuck"}, "")
+	if e.Body != "stuck" {
+		t.Errorf("unmerged car escalation body = %q, want unchanged", e.Body)
+	}
+}
+
 // --- FormatPulse tests ---
 
 func TestFormatPulse_BasicStatus(t *testing.T) {
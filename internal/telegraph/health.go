@@ -6,12 +6,15 @@ import (
 	"net/http"
 	"sync/atomic"
 	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
 )
 
 // HealthChecker provides HTTP health check endpoints for k8s probes.
 // It uses atomic operations for lock-free concurrent access.
 type HealthChecker struct {
 	pollInterval time.Duration
+	clock        clock.Clock
 	connected    atomic.Bool  // set true after adapter.Connect(), false on disconnect
 	lastPollNano atomic.Int64 // unix nanoseconds of last poll cycle
 }
@@ -22,10 +25,16 @@ type HealthChecker struct {
 func NewHealthChecker(pollInterval time.Duration) *HealthChecker {
 	hc := &HealthChecker{
 		pollInterval: pollInterval,
+		clock:        clock.Real,
 	}
 	return hc
 }
 
+// SetClock replaces the clock used for readiness checks. Call before serving.
+func (h *HealthChecker) SetClock(c clock.Clock) {
+	h.clock = clock.OrReal(c)
+}
+
 // SetConnected sets the adapter connected state.
 func (h *HealthChecker) SetConnected(v bool) {
 	h.connected.Store(v)
@@ -43,7 +52,7 @@ func (h *HealthChecker) IsReady() bool {
 		return false
 	}
 	lastPoll := time.Unix(0, h.lastPollNano.Load())
-	return time.Since(lastPoll) < 3*h.pollInterval
+	return h.clock.Since(lastPoll) < 3*h.pollInterval
 }
 
 // LivenessHandler always returns HTTP 200 ok.
@@ -60,7 +69,7 @@ func (h *HealthChecker) ReadinessHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	lastPoll := time.Unix(0, h.lastPollNano.Load())
-	if time.Since(lastPoll) >= 3*h.pollInterval {
+	if h.clock.Since(lastPoll) >= 3*h.pollInterval {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		w.Write([]byte("not ready: last poll too old"))
 		return
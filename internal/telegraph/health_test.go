@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
 )
 
 // freePort returns an available TCP port.
@@ -227,9 +229,12 @@ func TestIsReady_ConnectedAndFresh(t *testing.T) {
 }
 
 func TestIsReady_ConnectedButStale(t *testing.T) {
-	hc := NewHealthChecker(1 * time.Millisecond)
+	fc := clock.NewFake(time.Now())
+	hc := NewHealthChecker(time.Second)
+	hc.SetClock(fc)
 	hc.SetConnected(true)
-	time.Sleep(10 * time.Millisecond)
+	hc.SetLastPoll(fc.Now())
+	fc.Advance(3 * time.Second)
 	if hc.IsReady() {
 		t.Error("expected not ready when poll is stale")
 	}
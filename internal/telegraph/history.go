@@ -0,0 +1,470 @@
+package telegraph
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrArchiveNotFound is returned by HistoryArchive.Get for a missing key.
+var ErrArchiveNotFound = errors.New("telegraph: archived history not found")
+
+// HistoryArchive stores dispatch conversation turns moved out of the
+// database. Each archived session is one object holding gzipped JSON lines.
+type HistoryArchive interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns ErrArchiveNotFound when key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewHistoryArchive builds the archive cfg selects, or nil when archiving
+// is disabled (keep_days is 0).
+func NewHistoryArchive(cfg config.ConversationArchiveConfig) (HistoryArchive, error) {
+	if cfg.KeepDays <= 0 {
+		return nil, nil
+	}
+	switch cfg.Store {
+	case "", "file":
+		return &FileArchive{Dir: cfg.Dir}, nil
+	case "http":
+		return &HTTPArchive{BaseURL: cfg.URL, Token: cfg.Token}, nil
+	default:
+		return nil, fmt.Errorf("telegraph: unknown history archive store %q", cfg.Store)
+	}
+}
+
+// FileArchive keeps archived history as files in Dir.
+type FileArchive struct {
+	Dir string
+}
+
+func (a *FileArchive) Put(_ context.Context, key string, data []byte) error {
+	if err := os.MkdirAll(a.Dir, 0o700); err != nil {
+		return fmt.Errorf("telegraph: history archive: %w", err)
+	}
+	// Write then rename so a crash never leaves a truncated object behind.
+	path := filepath.Join(a.Dir, key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("telegraph: history archive: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("telegraph: history archive: %w", err)
+	}
+	return nil
+}
+
+func (a *FileArchive) Get(_ context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(a.Dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArchiveNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: history archive: %w", err)
+	}
+	return data, nil
+}
+
+func (a *FileArchive) Delete(_ context.Context, key string) error {
+	err := os.Remove(filepath.Join(a.Dir, key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("telegraph: history archive: %w", err)
+	}
+	return nil
+}
+
+// HTTPArchive keeps archived history in object storage reached over plain
+// HTTP: each key is PUT, fetched, and deleted at BaseURL/key. This covers
+// S3-compatible gateways, GCS and Azure behind a signing proxy, and WebDAV.
+type HTTPArchive struct {
+	BaseURL string
+	Token   string       // sent as "Authorization: Bearer <token>" when set
+	Client  *http.Client // defaults to http.DefaultClient
+}
+
+func (a *HTTPArchive) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := a.do(ctx, http.MethodPut, key, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telegraph: history archive: PUT %s: HTTP %d", key, resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *HTTPArchive) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := a.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrArchiveNotFound
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("telegraph: history archive: GET %s: HTTP %d", key, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: history archive: GET %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (a *HTTPArchive) Delete(ctx context.Context, key string) error {
+	resp, err := a.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("telegraph: history archive: DELETE %s: HTTP %d", key, resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *HTTPArchive) do(ctx context.Context, method, key string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(a.BaseURL, "/")+"/"+key, body)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: history archive: %w", err)
+	}
+	if a.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: history archive: %s %s: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// HistoryStore reads dispatch conversation history from the database and,
+// for sessions whose turns were archived, from a HistoryArchive. Readers
+// (resume, recovery, transcript export) see one ordered history either way.
+type HistoryStore struct {
+	db      *gorm.DB
+	archive HistoryArchive
+	keep    time.Duration
+	clock   clock.Clock
+}
+
+// HistoryStoreOpts holds parameters for creating a HistoryStore.
+type HistoryStoreOpts struct {
+	DB       *gorm.DB
+	Archive  HistoryArchive // optional; nil keeps all history in the database
+	KeepDays int            // finished sessions older than this are archived; 0 disables archiving
+	Clock    clock.Clock    // defaults to clock.Real
+}
+
+// NewHistoryStore creates a HistoryStore.
+func NewHistoryStore(opts HistoryStoreOpts) (*HistoryStore, error) {
+	if opts.DB == nil {
+		return nil, fmt.Errorf("telegraph: history store: db is required")
+	}
+	return &HistoryStore{
+		db:      opts.DB,
+		archive: opts.Archive,
+		keep:    time.Duration(opts.KeepDays) * 24 * time.Hour,
+		clock:   clock.OrReal(opts.Clock),
+	}, nil
+}
+
+// dbHistoryStore is the database-only store used when none is configured.
+func dbHistoryStore(db *gorm.DB) *HistoryStore {
+	return &HistoryStore{db: db, clock: clock.OrReal(nil)}
+}
+
+// historyKey names the archive object holding sessionID's turns.
+func historyKey(sessionID uint) string {
+	return fmt.Sprintf("session-%d.jsonl.gz", sessionID)
+}
+
+// SessionHistory returns sessionID's conversation ordered by sequence.
+func (hs *HistoryStore) SessionHistory(ctx context.Context, sessionID uint) ([]models.TelegraphConversation, error) {
+	var s models.DispatchSession
+	if err := hs.db.Select("id", "archived_at").Where("id = ?", sessionID).Limit(1).Find(&s).Error; err != nil {
+		return nil, fmt.Errorf("telegraph: session history: %w", err)
+	}
+	if s.ID == 0 {
+		s.ID = sessionID // conversations without a session row live only in the DB
+	}
+	return hs.load(ctx, []models.DispatchSession{s})
+}
+
+// ThreadHistory returns the conversation of every session in the thread
+// created at or after since, ordered by session then sequence.
+func (hs *HistoryStore) ThreadHistory(ctx context.Context, channelID, threadID string, since time.Time) ([]models.TelegraphConversation, error) {
+	var sessions []models.DispatchSession
+	q := hs.db.Select("id", "archived_at").Where("platform_thread_id = ? AND channel_id = ?", threadID, channelID)
+	if !since.IsZero() {
+		q = q.Where("created_at >= ?", since)
+	}
+	if err := q.Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("telegraph: thread history: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return hs.load(ctx, sessions)
+}
+
+// load merges the database rows and archived turns of sessions.
+func (hs *HistoryStore) load(ctx context.Context, sessions []models.DispatchSession) ([]models.TelegraphConversation, error) {
+	ids := make([]uint, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+	var convos []models.TelegraphConversation
+	if err := hs.db.Where("session_id IN ?", ids).Order("session_id, sequence").Find(&convos).Error; err != nil {
+		return nil, fmt.Errorf("telegraph: load history: %w", err)
+	}
+
+	archived := false
+	for _, s := range sessions {
+		if s.ArchivedAt == nil {
+			continue
+		}
+		if hs.archive == nil {
+			return nil, fmt.Errorf("telegraph: session %d history is archived but no archive is configured", s.ID)
+		}
+		turns, err := hs.readArchived(ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		convos = append(convos, turns...)
+		archived = true
+	}
+	if archived {
+		sort.SliceStable(convos, func(i, j int) bool {
+			if convos[i].SessionID != convos[j].SessionID {
+				return convos[i].SessionID < convos[j].SessionID
+			}
+			return convos[i].Sequence < convos[j].Sequence
+		})
+	}
+	return convos, nil
+}
+
+func (hs *HistoryStore) readArchived(ctx context.Context, sessionID uint) ([]models.TelegraphConversation, error) {
+	data, err := hs.archive.Get(ctx, historyKey(sessionID))
+	if errors.Is(err, ErrArchiveNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	turns, err := decodeHistory(data)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: session %d archived history: %w", sessionID, err)
+	}
+	return turns, nil
+}
+
+// ArchiveResult summarizes one Archive pass.
+type ArchiveResult struct {
+	Sessions int // sessions whose turns moved to the archive
+	Turns    int // conversation rows deleted from the database
+}
+
+// Archive moves the turns of finished sessions older than the keep window
+// out of the database. Each session's turns are written to the archive
+// before its rows are deleted, so an archive failure loses nothing.
+func (hs *HistoryStore) Archive(ctx context.Context) (ArchiveResult, error) {
+	var res ArchiveResult
+	if hs.archive == nil || hs.keep <= 0 {
+		return res, nil
+	}
+	cutoff := hs.clock.Now().Add(-hs.keep)
+
+	var sessions []models.DispatchSession
+	if err := hs.db.Where("status <> ? AND archived_at IS NULL AND COALESCE(completed_at, created_at) < ?", "active", cutoff).
+		Order("id").Find(&sessions).Error; err != nil {
+		return res, fmt.Errorf("telegraph: archive history: %w", err)
+	}
+	for _, s := range sessions {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		n, err := hs.archiveSession(ctx, s.ID)
+		if err != nil {
+			return res, fmt.Errorf("telegraph: archive session %d: %w", s.ID, err)
+		}
+		res.Sessions++
+		res.Turns += n
+	}
+	return res, nil
+}
+
+func (hs *HistoryStore) archiveSession(ctx context.Context, sessionID uint) (int, error) {
+	var convos []models.TelegraphConversation
+	if err := hs.db.Where("session_id = ?", sessionID).Order("sequence").Find(&convos).Error; err != nil {
+		return 0, err
+	}
+	if len(convos) > 0 {
+		// A session archived by an earlier, interrupted pass may already
+		// have an object; keep its turns.
+		prior, err := hs.readArchived(ctx, sessionID)
+		if err != nil {
+			return 0, err
+		}
+		data, err := encodeHistory(append(prior, convos...))
+		if err != nil {
+			return 0, err
+		}
+		if err := hs.archive.Put(ctx, historyKey(sessionID), data); err != nil {
+			return 0, err
+		}
+	}
+	now := hs.clock.Now()
+	err := hs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&models.TelegraphConversation{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.DispatchSession{}).Where("id = ?", sessionID).Update("archived_at", now).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(convos), nil
+}
+
+// PurgeArchived deletes the archive objects of every archived telegraph
+// session. ClearSessionHistory only touches the database, so callers that
+// clear history run this first.
+func (hs *HistoryStore) PurgeArchived(ctx context.Context) (int, error) {
+	if hs.archive == nil {
+		return 0, nil
+	}
+	var ids []uint
+	if err := hs.db.Model(&models.DispatchSession{}).
+		Where("source = ? AND archived_at IS NOT NULL", "telegraph").Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("telegraph: purge archived history: %w", err)
+	}
+	for i, id := range ids {
+		if err := hs.archive.Delete(ctx, historyKey(id)); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// encodeHistory renders turns as gzipped JSON lines.
+func encodeHistory(turns []models.TelegraphConversation) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := WriteTranscriptJSONL(zw, turns); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeHistory(data []byte) ([]models.TelegraphConversation, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var turns []models.TelegraphConversation
+	sc := bufio.NewScanner(zr)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var t turnRecord
+		if err := json.Unmarshal(sc.Bytes(), &t); err != nil {
+			return nil, err
+		}
+		turns = append(turns, t.conversation())
+	}
+	return turns, sc.Err()
+}
+
+// turnRecord is the archived form of a conversation row.
+type turnRecord struct {
+	SessionID      uint      `json:"session_id"`
+	Sequence       int       `json:"sequence"`
+	Role           string    `json:"role"`
+	UserName       string    `json:"user_name,omitempty"`
+	Content        string    `json:"content"`
+	PlatformMsgID  string    `json:"platform_msg_id,omitempty"`
+	CarsReferenced string    `json:"cars_referenced,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func archivedTurn(c models.TelegraphConversation) turnRecord {
+	return turnRecord{
+		SessionID:      c.SessionID,
+		Sequence:       c.Sequence,
+		Role:           c.Role,
+		UserName:       c.UserName,
+		Content:        c.Content,
+		PlatformMsgID:  c.PlatformMsgID,
+		CarsReferenced: c.CarsReferenced,
+		CreatedAt:      c.CreatedAt,
+	}
+}
+
+func (t turnRecord) conversation() models.TelegraphConversation {
+	return models.TelegraphConversation{
+		SessionID:      t.SessionID,
+		Sequence:       t.Sequence,
+		Role:           t.Role,
+		UserName:       t.UserName,
+		Content:        t.Content,
+		PlatformMsgID:  t.PlatformMsgID,
+		CarsReferenced: t.CarsReferenced,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+// WriteTranscriptJSONL writes a conversation as JSON lines, one turn per
+// line, in the same shape the archive stores.
+func WriteTranscriptJSONL(w io.Writer, convos []models.TelegraphConversation) error {
+	enc := json.NewEncoder(w)
+	for _, c := range convos {
+		if err := enc.Encode(archivedTurn(c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatTranscript renders a conversation as a plain-text transcript.
+func FormatTranscript(convos []models.TelegraphConversation) string {
+	var b strings.Builder
+	for _, c := range convos {
+		who := c.Role
+		if c.UserName != "" {
+			who += " " + c.UserName
+		}
+		fmt.Fprintf(&b, "[%s] %s:\n%s\n\n", c.CreatedAt.Format("2006-01-02 15:04:05"), who, strings.TrimRight(c.Content, "\n"))
+	}
+	return b.String()
+}
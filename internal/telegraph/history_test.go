@@ -0,0 +1,196 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// seedFinishedSession creates a completed session in thread-1 that finished
+// at completed, with one user and one assistant turn.
+func seedFinishedSession(t *testing.T, db *gorm.DB, completed time.Time) models.DispatchSession {
+	t.Helper()
+	s := models.DispatchSession{
+		Source:           "telegraph",
+		UserName:         "alice",
+		PlatformThreadID: "thread-1",
+		ChannelID:        "C01",
+		Status:           "completed",
+		CarsCreated:      "[]",
+		LastHeartbeat:    completed,
+		CreatedAt:        completed.Add(-time.Hour),
+		CompletedAt:      &completed,
+	}
+	db.Create(&s)
+	db.Create(&models.TelegraphConversation{SessionID: s.ID, Sequence: 1, Role: "user", UserName: "alice", Content: "create a task for auth", CreatedAt: completed})
+	db.Create(&models.TelegraphConversation{SessionID: s.ID, Sequence: 2, Role: "assistant", Content: "Created car-001 for auth", CarsReferenced: `["car-001"]`, CreatedAt: completed})
+	return s
+}
+
+func TestHistoryStore_ArchiveAndLoad(t *testing.T) {
+	db := openSessionTestDB(t)
+	now := time.Now()
+	old := seedFinishedSession(t, db, now.AddDate(0, 0, -40))
+	recent := seedFinishedSession(t, db, now.AddDate(0, 0, -1))
+	active := createTestSession(t, db, "C01", "thread-2")
+	db.Model(&active).Update("created_at", now.AddDate(0, 0, -60))
+
+	archive := &FileArchive{Dir: t.TempDir()}
+	hs, _ := NewHistoryStore(HistoryStoreOpts{DB: db, Archive: archive, KeepDays: 30})
+
+	res, err := hs.Archive(context.Background())
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if res.Sessions != 1 || res.Turns != 2 {
+		t.Errorf("Archive = %+v, want 1 session, 2 turns", res)
+	}
+	var left int64
+	db.Model(&models.TelegraphConversation{}).Where("session_id = ?", old.ID).Count(&left)
+	if left != 0 {
+		t.Errorf("%d turns left in the database for the archived session", left)
+	}
+	var s models.DispatchSession
+	db.First(&s, old.ID)
+	if s.ArchivedAt == nil {
+		t.Error("archived session has no archived_at")
+	}
+
+	// A second pass has nothing left to move.
+	if res, _ := hs.Archive(context.Background()); res.Sessions != 0 {
+		t.Errorf("second pass archived %d sessions", res.Sessions)
+	}
+
+	convos, err := hs.SessionHistory(context.Background(), old.ID)
+	if err != nil {
+		t.Fatalf("SessionHistory: %v", err)
+	}
+	if len(convos) != 2 || convos[0].Content != "create a task for auth" || convos[1].CarsReferenced != `["car-001"]` {
+		t.Errorf("archived history = %+v", convos)
+	}
+
+	thread, err := hs.ThreadHistory(context.Background(), "C01", "thread-1", time.Time{})
+	if err != nil {
+		t.Fatalf("ThreadHistory: %v", err)
+	}
+	if len(thread) != 4 || thread[0].SessionID != old.ID || thread[3].SessionID != recent.ID {
+		t.Errorf("thread history = %d turns, want archived then live session", len(thread))
+	}
+}
+
+func TestHistoryStore_ArchiveFailureKeepsRows(t *testing.T) {
+	db := openSessionTestDB(t)
+	old := seedFinishedSession(t, db, time.Now().AddDate(0, 0, -40))
+	hs, _ := NewHistoryStore(HistoryStoreOpts{DB: db, Archive: failingArchive{}, KeepDays: 30})
+
+	if _, err := hs.Archive(context.Background()); err == nil {
+		t.Fatal("Archive: want error")
+	}
+	var left int64
+	db.Model(&models.TelegraphConversation{}).Where("session_id = ?", old.ID).Count(&left)
+	if left != 2 {
+		t.Errorf("turns in database = %d, want 2 after a failed archive", left)
+	}
+}
+
+type failingArchive struct{}
+
+func (failingArchive) Put(context.Context, string, []byte) error { return errors.New("bucket gone") }
+func (failingArchive) Get(context.Context, string) ([]byte, error) {
+	return nil, ErrArchiveNotFound
+}
+func (failingArchive) Delete(context.Context, string) error { return nil }
+
+func TestResume_WithArchivedHistory(t *testing.T) {
+	db := openSessionTestDB(t)
+	seedFinishedSession(t, db, time.Now().AddDate(0, 0, -40))
+	hs, _ := NewHistoryStore(HistoryStoreOpts{DB: db, Archive: &FileArchive{Dir: t.TempDir()}, KeepDays: 30})
+	if _, err := hs.Archive(context.Background()); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+
+	spawner := &mockSpawner{}
+	sm, _ := NewSessionManager(SessionManagerOpts{DB: db, Spawner: spawner, History: hs})
+	if _, err := sm.Resume(context.Background(), "C01", "thread-1", "alice", "and the tests?"); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if p := spawner.lastProcess().prompt; !strings.Contains(p, "create a task for auth") {
+		t.Errorf("recovery prompt is missing the archived turns:\n%s", p)
+	}
+}
+
+func TestHTTPArchive(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	a := &HTTPArchive{BaseURL: srv.URL + "/history/", Token: "s3cret"}
+	if err := a.Put(ctx, "session-1.jsonl.gz", []byte("gz")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	mu.Lock()
+	_, stored := objects["/history/session-1.jsonl.gz"]
+	mu.Unlock()
+	if !stored {
+		t.Error("PUT did not store /history/session-1.jsonl.gz")
+	}
+	if got, err := a.Get(ctx, "session-1.jsonl.gz"); err != nil || string(got) != "gz" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if err := a.Delete(ctx, "session-1.jsonl.gz"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := a.Get(ctx, "session-1.jsonl.gz"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("Get after delete: %v, want ErrArchiveNotFound", err)
+	}
+	if err := (&HTTPArchive{BaseURL: srv.URL}).Put(ctx, "k", nil); err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Errorf("Put without token: %v, want HTTP 403", err)
+	}
+}
+
+func TestNewHistoryArchive(t *testing.T) {
+	if a, err := NewHistoryArchive(config.ConversationArchiveConfig{Store: "http"}); a != nil || err != nil {
+		t.Errorf("keep_days 0: %v, %v; want nil, nil", a, err)
+	}
+	if a, _ := NewHistoryArchive(config.ConversationArchiveConfig{KeepDays: 30, Dir: "h"}); a.(*FileArchive).Dir != "h" {
+		t.Errorf("default store = %#v, want FileArchive in h", a)
+	}
+	if a, _ := NewHistoryArchive(config.ConversationArchiveConfig{KeepDays: 30, Store: "http", URL: "https://x"}); a.(*HTTPArchive).BaseURL != "https://x" {
+		t.Errorf("http store = %#v", a)
+	}
+	if _, err := NewHistoryArchive(config.ConversationArchiveConfig{KeepDays: 30, Store: "tape"}); err == nil {
+		t.Error("unknown store: want error")
+	}
+}
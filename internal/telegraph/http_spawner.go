@@ -0,0 +1,281 @@
+package telegraph
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HTTPSpawner implements ProcessSpawner against a hosted agent API, so
+// dispatch can run without any local agent CLI installed. Each Spawn is one
+// turn: the prompt is POSTed as JSON to Endpoint and the response is streamed
+// back onto Recv — server-sent events when the server answers with
+// text/event-stream, otherwise plain text lines.
+//
+// Request body:
+//
+//	{"system_prompt": "...", "prompt": "...", "model": "..."}
+//
+// SSE events without a type (or of type "message") carry output text; an
+// "error" event fails the turn with its data as the error; a "done" event ends
+// the stream early.
+type HTTPSpawner struct {
+	Endpoint     string // full URL of the dispatch endpoint
+	Token        string // bearer token; empty sends no Authorization header
+	SystemPrompt string
+	Model        string
+	// Client performs the request; nil uses a client with no timeout (the
+	// session's process timeout bounds the turn via ctx).
+	Client *http.Client
+}
+
+// httpAgentRequest is the JSON body POSTed to the agent endpoint.
+type httpAgentRequest struct {
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	Prompt       string `json:"prompt"`
+	Model        string `json:"model,omitempty"`
+}
+
+// Spawn starts an HTTP-backed process. If prompt is non-empty the request is
+// sent immediately; if empty, the caller supplies the input via a single
+// Send() — mirroring ClaudeSpawner's piped-stdin semantics.
+func (s *HTTPSpawner) Spawn(ctx context.Context, prompt string) (Process, error) {
+	if s.Endpoint == "" {
+		return nil, fmt.Errorf("telegraph: http spawn: endpoint not configured")
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	p := &httpProcess{
+		ctx:     reqCtx,
+		cancel:  cancel,
+		spawner: s,
+		client:  client,
+		oneShot: prompt != "",
+		recvCh:  make(chan string, 64),
+		doneCh:  make(chan struct{}),
+	}
+	if p.oneShot {
+		p.start(prompt)
+	}
+	return p, nil
+}
+
+// httpProcess adapts one streamed agent API response to the Process interface.
+type httpProcess struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	spawner *HTTPSpawner
+	client  *http.Client
+
+	oneShot bool
+	recvCh  chan string
+	doneCh  chan struct{}
+
+	startOnce sync.Once
+
+	mu      sync.Mutex
+	sent    bool
+	closed  bool
+	exitErr error
+	stderr  string
+}
+
+// start launches the request exactly once.
+func (p *httpProcess) start(input string) {
+	p.startOnce.Do(func() { go p.run(input) })
+}
+
+// run performs the request and streams its output, closing recv and done when
+// the response ends.
+func (p *httpProcess) run(input string) {
+	err := p.stream(input)
+	p.mu.Lock()
+	p.exitErr = err
+	if err != nil {
+		p.stderr = err.Error()
+	}
+	p.mu.Unlock()
+	close(p.recvCh)
+	close(p.doneCh)
+}
+
+func (p *httpProcess) stream(input string) error {
+	s := p.spawner
+	body, err := json.Marshal(httpAgentRequest{SystemPrompt: s.SystemPrompt, Prompt: input, Model: s.Model})
+	if err != nil {
+		return fmt.Errorf("telegraph: http spawn: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegraph: http spawn: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegraph: http spawn: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telegraph: http spawn: %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // 1MB lines, like the claude stdout reader
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		err = p.readEvents(scanner)
+	} else {
+		for scanner.Scan() {
+			if !p.emit(scanner.Text()) {
+				return p.ctx.Err()
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("telegraph: http spawn: read response: %w", err)
+	}
+	return nil
+}
+
+// readEvents parses a server-sent event stream, emitting the data of each
+// output event one line at a time.
+func (p *httpProcess) readEvents(scanner *bufio.Scanner) error {
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// Blank line dispatches the accumulated event.
+			done, err := p.dispatchEvent(event, data)
+			if done || err != nil {
+				return err
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment / keepalive.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			v := strings.TrimPrefix(line, "data:")
+			data = append(data, strings.TrimPrefix(v, " "))
+		}
+	}
+	// A stream may end without a trailing blank line.
+	_, err := p.dispatchEvent(event, data)
+	return err
+}
+
+// dispatchEvent handles one parsed SSE event. done reports that the stream
+// has ended and no further events should be read.
+func (p *httpProcess) dispatchEvent(event string, data []string) (done bool, err error) {
+	switch event {
+	case "done":
+		return true, nil
+	case "error":
+		msg := strings.Join(data, "\n")
+		if msg == "" {
+			msg = "agent reported an error"
+		}
+		return true, fmt.Errorf("telegraph: http spawn: %s", msg)
+	case "", "message":
+		for _, line := range data {
+			if !p.emit(line) {
+				return true, p.ctx.Err()
+			}
+		}
+	}
+	return false, nil
+}
+
+// emit delivers one output line, returning false once the process is
+// cancelled.
+func (p *httpProcess) emit(line string) bool {
+	select {
+	case p.recvCh <- line:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// Send supplies the one-shot input when the process was spawned with an empty
+// prompt, starting the request. It may be called at most once.
+func (p *httpProcess) Send(msg string) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return fmt.Errorf("telegraph: process closed")
+	}
+	if p.oneShot {
+		p.mu.Unlock()
+		return fmt.Errorf("telegraph: no input channel (process spawned with prompt)")
+	}
+	if p.sent {
+		p.mu.Unlock()
+		return fmt.Errorf("telegraph: message already sent")
+	}
+	p.sent = true
+	p.mu.Unlock()
+
+	p.start(msg)
+	return nil
+}
+
+// Recv returns the channel delivering streamed output lines.
+func (p *httpProcess) Recv() <-chan string { return p.recvCh }
+
+// Done returns a channel that closes when the response has been consumed.
+func (p *httpProcess) Done() <-chan struct{} { return p.doneCh }
+
+// ExitErr returns the request or agent error (nil on success). Valid after Done().
+func (p *httpProcess) ExitErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exitErr
+}
+
+// Stderr returns the error text, if any. Valid after Done().
+func (p *httpProcess) Stderr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stderr
+}
+
+// Close cancels the request. If it never started (empty-prompt process that
+// was never Send()-ed), it closes recv and done so relay/monitor unblock.
+func (p *httpProcess) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	p.cancel()
+	p.startOnce.Do(func() {
+		close(p.recvCh)
+		close(p.doneCh)
+	})
+	return nil
+}
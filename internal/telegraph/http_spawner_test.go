@@ -0,0 +1,184 @@
+package telegraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collectHTTPProcess drains a process's output and waits for it to exit.
+func collectHTTPProcess(t *testing.T, proc Process) []string {
+	t.Helper()
+	var lines []string
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-proc.Recv():
+			if !ok {
+				<-proc.Done()
+				return lines
+			}
+			lines = append(lines, line)
+		case <-timeout:
+			t.Fatal("timed out waiting for http process output")
+		}
+	}
+}
+
+func TestHTTPSpawner_StreamsSSE(t *testing.T) {
+	var got httpAgentRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keepalive\n\n")
+		fmt.Fprint(w, "data: Looking at the repo\n\n")
+		fmt.Fprint(w, "event: message\ndata: line one\ndata: line two\n\n")
+		fmt.Fprint(w, "event: done\ndata: \n\n")
+		fmt.Fprint(w, "data: after done\n\n")
+	}))
+	defer srv.Close()
+
+	s := &HTTPSpawner{Endpoint: srv.URL, Token: "tok", SystemPrompt: "sys", Model: "m1"}
+	proc, err := s.Spawn(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	lines := collectHTTPProcess(t, proc)
+
+	want := []string{"Looking at the repo", "line one", "line two"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	if proc.ExitErr() != nil {
+		t.Errorf("ExitErr = %v", proc.ExitErr())
+	}
+	if auth != "Bearer tok" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Prompt != "hello" || got.SystemPrompt != "sys" || got.Model != "m1" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestHTTPSpawner_PlainTextAndSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req httpAgentRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "you said\n%s\n", req.Prompt)
+	}))
+	defer srv.Close()
+
+	proc, err := (&HTTPSpawner{Endpoint: srv.URL}).Spawn(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if err := proc.Send("ping"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := proc.Send("again"); err == nil {
+		t.Error("second Send should fail")
+	}
+	lines := collectHTTPProcess(t, proc)
+	if strings.Join(lines, "|") != "you said|ping" {
+		t.Errorf("lines = %q", lines)
+	}
+}
+
+func TestHTTPSpawner_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad token", http.StatusUnauthorized)
+			},
+			want: "401 Unauthorized: bad token",
+		},
+		{
+			name: "error event",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "data: partial\n\nevent: error\ndata: model overloaded\n\n")
+			},
+			want: "model overloaded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			proc, err := (&HTTPSpawner{Endpoint: srv.URL}).Spawn(context.Background(), "hi")
+			if err != nil {
+				t.Fatalf("Spawn: %v", err)
+			}
+			collectHTTPProcess(t, proc)
+			if proc.ExitErr() == nil || !strings.Contains(proc.ExitErr().Error(), tt.want) {
+				t.Errorf("ExitErr = %v, want containing %q", proc.ExitErr(), tt.want)
+			}
+			if !strings.Contains(proc.Stderr(), tt.want) {
+				t.Errorf("Stderr = %q", proc.Stderr())
+			}
+		})
+	}
+}
+
+func TestHTTPSpawner_CloseBeforeSend(t *testing.T) {
+	proc, err := (&HTTPSpawner{Endpoint: "http://127.0.0.1:1"}).Spawn(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	proc.Close()
+	select {
+	case <-proc.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done not closed after Close")
+	}
+	if err := proc.Send("late"); err == nil {
+		t.Error("Send after Close should fail")
+	}
+}
+
+func TestHTTPSpawner_NoEndpoint(t *testing.T) {
+	if _, err := (&HTTPSpawner{}).Spawn(context.Background(), "hi"); err == nil {
+		t.Error("expected error without endpoint")
+	}
+}
+
+func TestLazySpawner_HTTPSkipsWorktree(t *testing.T) {
+	var got httpAgentRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprintln(w, "ok")
+	}))
+	defer srv.Close()
+
+	spawner := &LazySpawner{
+		RenderPrompt: func() (string, error) { return "dispatch prompt", nil },
+		EnsureWorktree: func() (string, error) {
+			t.Error("EnsureWorktree called for HTTP backend")
+			return "", nil
+		},
+		Model: "agent-model",
+		HTTP:  &HTTPSpawner{Endpoint: srv.URL},
+	}
+	proc, err := spawner.Spawn(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	collectHTTPProcess(t, proc)
+	if got.SystemPrompt != "dispatch prompt" || got.Model != "agent-model" {
+		t.Errorf("request = %+v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// InboundLimits bounds what one chat user can send the router. Zero fields
+// disable the corresponding limit.
+type InboundLimits struct {
+	MaxChars     int  // longer messages are trimmed, or rejected when Reject is set
+	Reject       bool // reject oversized messages instead of trimming them
+	MaxPerMinute int  // messages per user in any rolling minute
+}
+
+// floodWindow is the rolling window MaxPerMinute is counted over.
+const floodWindow = time.Minute
+
+// floodGuard counts each user's recent messages to the bot.
+type floodGuard struct {
+	mu     sync.Mutex
+	clock  clock.Clock
+	max    int
+	recent map[string][]time.Time // user → message times within floodWindow
+	warned map[string]time.Time   // user → when they were last told to slow down
+}
+
+func newFloodGuard(max int, clk clock.Clock) *floodGuard {
+	return &floodGuard{
+		clock:  clock.OrReal(clk),
+		max:    max,
+		recent: make(map[string][]time.Time),
+		warned: make(map[string]time.Time),
+	}
+}
+
+// allow records a message from user and reports whether it is within the
+// limit. warn is true for the first dropped message in a window, so a
+// copy-paste loop gets one reply rather than one per message.
+func (g *floodGuard) allow(user string) (ok, warn bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	cutoff := now.Add(-floodWindow)
+	recent := g.recent[user]
+	for len(recent) > 0 && !recent[0].After(cutoff) {
+		recent = recent[1:]
+	}
+	if len(recent) >= g.max {
+		g.recent[user] = recent
+		if last, ok := g.warned[user]; ok && last.After(cutoff) {
+			return false, false
+		}
+		g.warned[user] = now
+		return false, true
+	}
+	g.recent[user] = append(recent, now)
+	return true, false
+}
+
+// floodKey identifies the sender of msg across platforms.
+func floodKey(msg InboundMessage) string {
+	user := msg.UserID
+	if user == "" {
+		user = msg.UserName
+	}
+	return msg.Platform + ":" + user
+}
+
+// applyLimits enforces the inbound limits on a message addressed to the
+// bot. It returns the text to handle, trimmed if it was too long, and false
+// when the message must be dropped. Each drop or trim is explained to the
+// user in the message's thread.
+func (r *Router) applyLimits(ctx context.Context, msg InboundMessage, text string) (string, bool) {
+	if r.flood != nil {
+		ok, warn := r.flood.allow(floodKey(msg))
+		if !ok {
+			fmt.Fprintf(r.out, "telegraph: router: → drop (over %d messages/minute) user=%s\n", r.limits.MaxPerMinute, msg.UserName)
+			if warn {
+				r.replyTo(ctx, msg, fmt.Sprintf("Slow down — that's more than %d messages in a minute. I'll ignore your messages for a bit; try again shortly.", r.limits.MaxPerMinute))
+			}
+			return "", false
+		}
+	}
+
+	max := r.limits.MaxChars
+	n := utf8.RuneCountInString(text)
+	if max <= 0 || n <= max {
+		return text, true
+	}
+	if r.limits.Reject {
+		fmt.Fprintf(r.out, "telegraph: router: → reject (%d chars) user=%s\n", n, msg.UserName)
+		r.replyTo(ctx, msg, fmt.Sprintf("That message is %d characters; the limit is %d. Please shorten it or upload the text as a file.", n, max))
+		return "", false
+	}
+	fmt.Fprintf(r.out, "telegraph: router: → trim (%d to %d chars) user=%s\n", n, max, msg.UserName)
+	r.replyTo(ctx, msg, fmt.Sprintf("That message is %d characters, so I'll only read the first %d. Upload long text as a file to send all of it.", n, max))
+	return string([]rune(text)[:max]) + fmt.Sprintf("\n\n[message trimmed from %d to %d characters]", n, max), true
+}
+
+// replyTo posts text in msg's thread, or in its channel for a top-level
+// message.
+func (r *Router) replyTo(ctx context.Context, msg InboundMessage, text string) {
+	if err := r.adapter.Send(ctx, OutboundMessage{
+		ChannelID: msg.ChannelID,
+		ThreadID:  msg.ThreadID,
+		Text:      text,
+	}); err != nil {
+		log.Printf("telegraph: router: send limit notice: %v", err)
+	}
+}
+
+// addressedToBot reports whether msg is for the bot: a command, a mention,
+// the dispatch prefix, or a reply in a thread that has a dispatch session.
+// Only these messages count toward the inbound limits, so ordinary channel
+// chatter is never throttled.
+func (r *Router) addressedToBot(msg InboundMessage, text string) bool {
+	if isCommand(text) || isDispatchPrefix(text) || r.isBotMention(text) {
+		return true
+	}
+	if msg.ThreadID != "" {
+		return r.sessionMgr.HasSession(msg.ChannelID, msg.ThreadID) ||
+			r.sessionMgr.HasHistoricSession(msg.ChannelID, msg.ThreadID)
+	}
+	channelID, found := r.sessionMgr.LookupThreadChannel(msg.ChannelID)
+	return found && channelID != msg.ChannelID
+}
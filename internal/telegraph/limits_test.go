@@ -0,0 +1,99 @@
+package telegraph
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// setupLimitedRouter returns a router with an active session in C1/T1 and
+// the given inbound limits on a fake clock.
+func setupLimitedRouter(t *testing.T, limits InboundLimits) (*Router, *MockAdapter, *mockSpawner, *clock.Fake) {
+	t.Helper()
+	router, adapter, spawner := setupRouter(t, openRouterTestDB(t), "9900112233", nil)
+	clk := clock.NewFake(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	router.limits = limits
+	if limits.MaxPerMinute > 0 {
+		router.flood = newFloodGuard(limits.MaxPerMinute, clk)
+	}
+	if _, err := router.sessionMgr.NewSession(context.Background(), "telegraph", "alice", "T1", "C1"); err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	return router, adapter, spawner, clk
+}
+
+func threadReply(text string) InboundMessage {
+	return InboundMessage{UserID: "user-1", UserName: "alice", ChannelID: "C1", ThreadID: "T1", Text: text}
+}
+
+func TestHandle_TrimsOversizedMessage(t *testing.T) {
+	router, adapter, spawner, _ := setupLimitedRouter(t, InboundLimits{MaxChars: 10})
+
+	router.Handle(context.Background(), threadReply("héllo wörld, and then a very long paste"))
+
+	sent := spawner.lastProcess().sentMessages()
+	if len(sent) != 1 || !strings.HasPrefix(sent[0], "héllo wörl\n\n[message trimmed from 39 to 10 characters]") {
+		t.Errorf("session got %q, want the first 10 characters and a trim note", sent)
+	}
+	if notice := adapter.AllSent()[0]; notice.ThreadID != "T1" || !strings.Contains(notice.Text, "only read the first 10") {
+		t.Errorf("notice = %+v", notice)
+	}
+}
+
+func TestHandle_RejectsOversizedMessage(t *testing.T) {
+	router, adapter, spawner, _ := setupLimitedRouter(t, InboundLimits{MaxChars: 10, Reject: true})
+
+	router.Handle(context.Background(), threadReply("a very long paste indeed"))
+
+	if sent := spawner.lastProcess().sentMessages(); len(sent) != 0 {
+		t.Errorf("session got %q, want nothing", sent)
+	}
+	if msg, _ := adapter.LastSent(); !strings.Contains(msg.Text, "24 characters; the limit is 10") {
+		t.Errorf("reply = %q", msg.Text)
+	}
+}
+
+func TestHandle_RateLimitsFloodingUser(t *testing.T) {
+	router, adapter, spawner, clk := setupLimitedRouter(t, InboundLimits{MaxPerMinute: 3})
+	ctx := context.Background()
+
+	for range 6 {
+		router.Handle(ctx, threadReply("again"))
+	}
+	if sent := spawner.lastProcess().sentMessages(); len(sent) != 3 {
+		t.Errorf("session got %d messages, want 3", len(sent))
+	}
+	var warnings int
+	for _, msg := range adapter.AllSent() {
+		if strings.HasPrefix(msg.Text, "Slow down") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("sent %d slow-down replies, want 1", warnings)
+	}
+
+	// Another user is unaffected, and the first is let back in a minute later.
+	bob := threadReply("hi")
+	bob.UserID, bob.UserName = "user-2", "bob"
+	router.Handle(ctx, bob)
+	clk.Advance(time.Minute)
+	router.Handle(ctx, threadReply("sorry"))
+	if sent := spawner.lastProcess().sentMessages(); len(sent) != 5 {
+		t.Errorf("session got %d messages, want 5", len(sent))
+	}
+}
+
+func TestHandle_LimitsIgnoreChannelChatter(t *testing.T) {
+	router, adapter, _, _ := setupLimitedRouter(t, InboundLimits{MaxChars: 5, MaxPerMinute: 1})
+
+	for range 3 {
+		router.Handle(context.Background(), InboundMessage{UserID: "user-1", ChannelID: "C1", Text: "just chatting with the team"})
+	}
+	if n := adapter.SentCount(); n != 0 {
+		t.Errorf("sent %d replies to chatter not addressed to the bot", n)
+	}
+}
@@ -1,10 +1,12 @@
 package telegraph
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -19,6 +21,10 @@ const DefaultHeartbeatTimeout = 90 * time.Second
 // session to end can treat this as success via errors.Is.
 var ErrSessionNotActive = errors.New("session not found or not active")
 
+// ErrLockHeld is wrapped by the error AcquireLock returns when another
+// active session already holds the thread's dispatch lock.
+var ErrLockHeld = errors.New("dispatch lock held")
+
 // AcquireLock attempts to acquire a dispatch lock for the given source,
 // user, thread, and channel. It first expires any stale sessions (heartbeat
 // older than timeout), then checks for an existing active session on the
@@ -27,6 +33,12 @@ var ErrSessionNotActive = errors.New("session not found or not active")
 // Returns the new DispatchSession on success, or an error if an active
 // session already holds the lock.
 func AcquireLock(db *gorm.DB, source, userName, threadID, channelID string, timeout time.Duration) (*models.DispatchSession, error) {
+	return acquireLock(db, clock.Real, source, userName, threadID, channelID, timeout)
+}
+
+// acquireLock is AcquireLock with an explicit clock for staleness checks and
+// timestamps.
+func acquireLock(db *gorm.DB, clk clock.Clock, source, userName, threadID, channelID string, timeout time.Duration) (*models.DispatchSession, error) {
 	if timeout <= 0 {
 		timeout = DefaultHeartbeatTimeout
 	}
@@ -34,7 +46,7 @@ func AcquireLock(db *gorm.DB, source, userName, threadID, channelID string, time
 	var session *models.DispatchSession
 
 	err := db.Transaction(func(tx *gorm.DB) error {
-		cutoff := time.Now().Add(-timeout)
+		cutoff := clk.Now().Add(-timeout)
 
 		// Expire all stale active sessions globally (regardless of
 		// thread/channel). This handles cross-source staleness — e.g. a
@@ -45,7 +57,7 @@ func AcquireLock(db *gorm.DB, source, userName, threadID, channelID string, time
 				"active", cutoff).
 			Updates(map[string]interface{}{
 				"status":       "expired",
-				"completed_at": time.Now(),
+				"completed_at": clk.Now(),
 			}).Error; err != nil {
 			return fmt.Errorf("expire stale sessions: %w", err)
 		}
@@ -59,14 +71,14 @@ func AcquireLock(db *gorm.DB, source, userName, threadID, channelID string, time
 			Where("status = ? AND platform_thread_id = ? AND channel_id = ?",
 				"active", threadID, channelID).First(&existing)
 		if result.Error == nil {
-			return fmt.Errorf("dispatch lock held by %q (session %d)", existing.UserName, existing.ID)
+			return fmt.Errorf("%w by %q (session %d)", ErrLockHeld, existing.UserName, existing.ID)
 		}
 		if result.Error != gorm.ErrRecordNotFound {
 			return fmt.Errorf("check existing session: %w", result.Error)
 		}
 
 		// No active session — create a new one.
-		now := time.Now()
+		now := clk.Now()
 		session = &models.DispatchSession{
 			Source:           source,
 			UserName:         userName,
@@ -89,7 +101,11 @@ func AcquireLock(db *gorm.DB, source, userName, threadID, channelID string, time
 
 // ReleaseLock marks the session as completed and sets CompletedAt.
 func ReleaseLock(db *gorm.DB, sessionID uint) error {
-	now := time.Now()
+	return releaseLock(db, clock.Real, sessionID)
+}
+
+func releaseLock(db *gorm.DB, clk clock.Clock, sessionID uint) error {
+	now := clk.Now()
 	result := db.Model(&models.DispatchSession{}).
 		Where("id = ? AND status = ?", sessionID, "active").
 		Updates(map[string]interface{}{
@@ -107,14 +123,75 @@ func ReleaseLock(db *gorm.DB, sessionID uint) error {
 
 // Heartbeat refreshes the LastHeartbeat timestamp for an active session.
 func Heartbeat(db *gorm.DB, sessionID uint) error {
+	return heartbeat(db, clock.Real, sessionID)
+}
+
+func heartbeat(db *gorm.DB, clk clock.Clock, sessionID uint) error {
 	result := db.Model(&models.DispatchSession{}).
 		Where("id = ? AND status = ?", sessionID, "active").
-		Update("last_heartbeat", time.Now())
+		Update("last_heartbeat", clk.Now())
 	if result.Error != nil {
 		return fmt.Errorf("telegraph: heartbeat: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("telegraph: heartbeat: session %d not found or not active", sessionID)
+		return fmt.Errorf("telegraph: heartbeat: session %d: %w", sessionID, ErrSessionNotActive)
 	}
 	return nil
 }
+
+// RecordCarCreated appends carID to the session's CarsCreated list. Cars
+// created by a dispatch agent carry their session ID (see
+// dispatch.SessionEnv), so the session can report what it produced.
+func RecordCarCreated(db *gorm.DB, sessionID uint, carID string) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var s models.DispatchSession
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "cars_created").First(&s, sessionID).Error; err != nil {
+			return err
+		}
+		cars, err := decodeCarsCreated(s.CarsCreated)
+		if err != nil {
+			return err
+		}
+		for _, id := range cars {
+			if id == carID {
+				return nil
+			}
+		}
+		data, err := json.Marshal(append(cars, carID))
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.DispatchSession{}).Where("id = ?", sessionID).
+			Update("cars_created", string(data)).Error
+	})
+	if err != nil {
+		return fmt.Errorf("telegraph: record car %s on session %d: %w", carID, sessionID, err)
+	}
+	return nil
+}
+
+// SessionCars returns the IDs of the cars created in a dispatch session, in
+// creation order.
+func SessionCars(db *gorm.DB, sessionID uint) ([]string, error) {
+	var s models.DispatchSession
+	if err := db.Select("id", "cars_created").First(&s, sessionID).Error; err != nil {
+		return nil, fmt.Errorf("telegraph: session %d cars: %w", sessionID, err)
+	}
+	cars, err := decodeCarsCreated(s.CarsCreated)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: session %d cars: %w", sessionID, err)
+	}
+	return cars, nil
+}
+
+func decodeCarsCreated(raw string) ([]string, error) {
+	cars := []string{}
+	if raw == "" {
+		return cars, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &cars); err != nil {
+		return nil, fmt.Errorf("decode cars_created: %w", err)
+	}
+	return cars, nil
+}
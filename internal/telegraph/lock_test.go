@@ -1,6 +1,7 @@
 package telegraph
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -75,6 +76,9 @@ func TestAcquireLock_Blocked(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for second lock")
 	}
+	if !errors.Is(err, ErrLockHeld) {
+		t.Errorf("errors.Is(%v, ErrLockHeld) = false", err)
+	}
 	if !strings.Contains(err.Error(), "lock held by") {
 		t.Errorf("error = %q, want to contain %q", err.Error(), "lock held by")
 	}
@@ -260,6 +264,9 @@ func TestReleaseLock_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent session")
 	}
+	if !errors.Is(err, ErrSessionNotActive) {
+		t.Errorf("errors.Is(%v, ErrSessionNotActive) = false", err)
+	}
 	if !strings.Contains(err.Error(), "not found or not active") {
 		t.Errorf("error = %q, want to contain %q", err.Error(), "not found or not active")
 	}
@@ -321,6 +328,9 @@ func TestHeartbeat_NotFound(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-existent session")
 	}
+	if !errors.Is(err, ErrSessionNotActive) {
+		t.Errorf("errors.Is(%v, ErrSessionNotActive) = false", err)
+	}
 	if !strings.Contains(err.Error(), "not found or not active") {
 		t.Errorf("error = %q, want to contain %q", err.Error(), "not found or not active")
 	}
@@ -418,3 +428,28 @@ func TestConcurrent_HeartbeatDuringAcquire(t *testing.T) {
 		t.Fatal("expected acquire to fail while lock is held and heartbeating")
 	}
 }
+
+func TestRecordCarCreated(t *testing.T) {
+	db := openLockTestDB(t)
+	session, err := AcquireLock(db, "local", "alice", "local", "local", DefaultHeartbeatTimeout)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+
+	for _, id := range []string{"car-a", "car-b", "car-a"} {
+		if err := RecordCarCreated(db, session.ID, id); err != nil {
+			t.Fatalf("RecordCarCreated(%s): %v", id, err)
+		}
+	}
+	cars, err := SessionCars(db, session.ID)
+	if err != nil {
+		t.Fatalf("SessionCars: %v", err)
+	}
+	if strings.Join(cars, ",") != "car-a,car-b" {
+		t.Errorf("cars = %v, want [car-a car-b]", cars)
+	}
+
+	if err := RecordCarCreated(db, session.ID+100, "car-c"); err == nil {
+		t.Error("RecordCarCreated on a missing session succeeded, want error")
+	}
+}
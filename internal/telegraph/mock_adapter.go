@@ -3,22 +3,29 @@ package telegraph
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 )
 
-// MockAdapter implements Adapter and ThreadStarter for testing. It records
-// sent messages and allows simulating inbound messages via SimulateInbound.
+// MockAdapter implements Adapter, ThreadStarter, and FileDownloader for
+// testing. It records sent messages and allows simulating inbound messages via
+// SimulateInbound.
+// Tests outside this package should use it through pkg/telegraph/telegraphtest,
+// which adds inbound builders, assertions, a mock spawner, and a fake clock.
 type MockAdapter struct {
-	mu             sync.Mutex
-	connected      bool
-	closed         bool
-	inbound        chan InboundMessage
-	sent           []OutboundMessage
-	history        map[string][]ThreadMessage // key: "channelID:threadID"
-	botUserID      string
-	threadCounter  int    // incremented for each StartThread call
-	lastThreadName string // thread name from the most recent StartThread call
+	mu                sync.Mutex
+	connected         bool
+	closed            bool
+	inbound           chan InboundMessage
+	sent              []OutboundMessage
+	history           map[string][]ThreadMessage // key: "channelID:threadID"
+	historyCalls      int
+	historySinceCalls int
+	files             map[string][]byte // key: InboundFile.URL
+	botUserID         string
+	threadCounter     int    // incremented for each StartThread call
+	lastThreadName    string // thread name from the most recent StartThread call
 }
 
 // BotUserID returns the configured bot user ID (implements BotUserIDer).
@@ -40,6 +47,7 @@ func NewMockAdapter() *MockAdapter {
 	return &MockAdapter{
 		inbound: make(chan InboundMessage, 100),
 		history: make(map[string][]ThreadMessage),
+		files:   make(map[string][]byte),
 	}
 }
 
@@ -75,11 +83,24 @@ func (m *MockAdapter) Send(ctx context.Context, msg OutboundMessage) error {
 	return nil
 }
 
+// Post records the outbound message like Send and returns a synthetic
+// message ID (implements MessagePoster).
+func (m *MockAdapter) Post(ctx context.Context, msg OutboundMessage) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.connected {
+		return "", fmt.Errorf("mock adapter: not connected")
+	}
+	m.sent = append(m.sent, msg)
+	return fmt.Sprintf("msg-%d", len(m.sent)), nil
+}
+
 // ThreadHistory returns pre-configured history for a channel/thread pair.
 func (m *MockAdapter) ThreadHistory(ctx context.Context, channelID, threadID string, limit int) ([]ThreadMessage, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	key := channelID + ":" + threadID
+	m.historyCalls++
 	msgs := m.history[key]
 	if limit > 0 && limit < len(msgs) {
 		msgs = msgs[len(msgs)-limit:]
@@ -87,6 +108,27 @@ func (m *MockAdapter) ThreadHistory(ctx context.Context, channelID, threadID str
 	return msgs, nil
 }
 
+// ThreadHistorySince returns the pre-configured messages after
+// afterMessageID. Implements ThreadHistorySincer.
+func (m *MockAdapter) ThreadHistorySince(ctx context.Context, channelID, threadID, afterMessageID string, limit int) ([]ThreadMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.historySinceCalls++
+	msgs := messagesAfter(m.history[channelID+":"+threadID], afterMessageID)
+	if limit > 0 && limit < len(msgs) {
+		msgs = msgs[len(msgs)-limit:]
+	}
+	return msgs, nil
+}
+
+// HistoryCalls returns how many full and incremental thread history reads
+// were made.
+func (m *MockAdapter) HistoryCalls() (full, since int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.historyCalls, m.historySinceCalls
+}
+
 // Close shuts down the mock adapter and closes the inbound channel.
 func (m *MockAdapter) Close() error {
 	m.mu.Lock()
@@ -121,6 +163,19 @@ func (m *MockAdapter) StartThread(ctx context.Context, channelID, messageID, rep
 	return threadID, nil
 }
 
+// DownloadFile implements FileDownloader, serving contents registered with
+// SetFile.
+func (m *MockAdapter) DownloadFile(ctx context.Context, f InboundFile, w io.Writer) error {
+	m.mu.Lock()
+	data, ok := m.files[f.URL]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("mock adapter: no file at %s", f.URL)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
 // LastThreadName returns the threadName argument from the most recent
 // StartThread call, or empty string if StartThread has never been called.
 func (m *MockAdapter) LastThreadName() string {
@@ -174,6 +229,13 @@ func (m *MockAdapter) SetThreadHistory(channelID, threadID string, msgs []Thread
 	m.history[channelID+":"+threadID] = msgs
 }
 
+// SetFile registers the contents DownloadFile returns for url.
+func (m *MockAdapter) SetFile(url string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[url] = data
+}
+
 // AllSent returns a copy of all sent outbound messages.
 func (m *MockAdapter) AllSent() []OutboundMessage {
 	m.mu.Lock()
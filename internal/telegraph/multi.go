@@ -0,0 +1,288 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// PlatformAdapter is one platform behind a MultiAdapter.
+type PlatformAdapter struct {
+	Platform string // "slack", "discord", ...
+	Channel  string // default channel ID on this platform
+	Adapter  Adapter
+}
+
+// MultiAdapter runs several chat platforms as one Adapter. Inbound messages
+// from every platform are merged into one stream; replies go back to the
+// platform that owns the channel; top-level messages without a channel (the
+// online notice, events, digests) fan out to every platform's default
+// channel.
+type MultiAdapter struct {
+	platforms []PlatformAdapter // primary first
+
+	mu       sync.RWMutex
+	channels map[string]int // channel or thread ID → index into platforms
+	files    map[string]int // inbound file URL → index into platforms
+}
+
+// NewMultiAdapter creates a MultiAdapter. The first platform is the primary:
+// it receives Post calls that name no channel.
+func NewMultiAdapter(platforms ...PlatformAdapter) (*MultiAdapter, error) {
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("telegraph: multi adapter: no platforms")
+	}
+	m := &MultiAdapter{
+		platforms: platforms,
+		channels:  make(map[string]int),
+		files:     make(map[string]int),
+	}
+	for i, p := range platforms {
+		if p.Adapter == nil {
+			return nil, fmt.Errorf("telegraph: multi adapter: %s: adapter is required", p.Platform)
+		}
+		if p.Channel != "" {
+			m.channels[p.Channel] = i
+		}
+	}
+	return m, nil
+}
+
+// Platforms returns the platforms in configuration order, primary first.
+func (m *MultiAdapter) Platforms() []PlatformAdapter {
+	return m.platforms
+}
+
+// Connect connects every platform. If one fails, the ones already connected
+// are closed again.
+func (m *MultiAdapter) Connect(ctx context.Context) error {
+	for i, p := range m.platforms {
+		if err := p.Adapter.Connect(ctx); err != nil {
+			for _, c := range m.platforms[:i] {
+				c.Adapter.Close()
+			}
+			return fmt.Errorf("%s: %w", p.Platform, err)
+		}
+	}
+	return nil
+}
+
+// WaitReady waits for every platform that can report readiness. Implements
+// ReadyWaiter.
+func (m *MultiAdapter) WaitReady(ctx context.Context) error {
+	for _, p := range m.platforms {
+		if rw, ok := p.Adapter.(ReadyWaiter); ok {
+			if err := rw.WaitReady(ctx); err != nil {
+				return fmt.Errorf("%s: %w", p.Platform, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Listen merges the inbound streams of every platform. Each message is
+// stamped with its platform, and its channel is remembered so replies go
+// back to the same platform. The merged channel closes once every platform
+// has closed its own.
+func (m *MultiAdapter) Listen(ctx context.Context) (<-chan InboundMessage, error) {
+	streams := make([]<-chan InboundMessage, len(m.platforms))
+	for i, p := range m.platforms {
+		ch, err := p.Adapter.Listen(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Platform, err)
+		}
+		streams[i] = ch
+	}
+
+	out := make(chan InboundMessage, 100)
+	var wg sync.WaitGroup
+	for i, ch := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range ch {
+				if msg.Platform == "" {
+					msg.Platform = m.platforms[i].Platform
+				}
+				m.remember(i, msg)
+				out <- msg
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, nil
+}
+
+// remember records that msg's channel, thread, and files belong to the
+// platform at index i.
+func (m *MultiAdapter) remember(i int, msg InboundMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if msg.ChannelID != "" {
+		m.channels[msg.ChannelID] = i
+	}
+	if msg.ThreadID != "" {
+		m.channels[msg.ThreadID] = i
+	}
+	for _, f := range msg.Files {
+		m.files[f.URL] = i
+	}
+}
+
+// route returns the platform that owns channelID (or threadID).
+func (m *MultiAdapter) route(channelID, threadID string) (PlatformAdapter, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, id := range []string{channelID, threadID} {
+		if i, ok := m.channels[id]; ok && id != "" {
+			return m.platforms[i], nil
+		}
+	}
+	return PlatformAdapter{}, fmt.Errorf("telegraph: no platform owns channel %q", channelID)
+}
+
+// Send delivers msg on the platform that owns its channel. A message with
+// no channel goes to every platform's default channel; failures on one
+// platform do not stop delivery on the others.
+func (m *MultiAdapter) Send(ctx context.Context, msg OutboundMessage) error {
+	if msg.ChannelID == "" {
+		var errs []error
+		for _, p := range m.platforms {
+			if err := p.Adapter.Send(ctx, msg); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.Platform, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	p, err := m.route(msg.ChannelID, msg.ThreadID)
+	if err != nil {
+		return err
+	}
+	return p.Adapter.Send(ctx, msg)
+}
+
+// Post delivers msg like Send and returns the message ID. A message with no
+// channel goes to the primary platform only, since one ID cannot name
+// messages on several platforms. Implements MessagePoster.
+func (m *MultiAdapter) Post(ctx context.Context, msg OutboundMessage) (string, error) {
+	p := m.platforms[0]
+	if msg.ChannelID != "" {
+		var err error
+		if p, err = m.route(msg.ChannelID, msg.ThreadID); err != nil {
+			return "", err
+		}
+	}
+	poster, ok := p.Adapter.(MessagePoster)
+	if !ok {
+		return "", p.Adapter.Send(ctx, msg)
+	}
+	return poster.Post(ctx, msg)
+}
+
+// EditMessage implements MessageEditor.
+func (m *MultiAdapter) EditMessage(ctx context.Context, channelID, threadID, messageID, text string) error {
+	p, err := m.route(channelID, threadID)
+	if err != nil {
+		return err
+	}
+	editor, ok := p.Adapter.(MessageEditor)
+	if !ok {
+		return fmt.Errorf("telegraph: %s cannot edit messages", p.Platform)
+	}
+	return editor.EditMessage(ctx, channelID, threadID, messageID, text)
+}
+
+// StartThread implements ThreadStarter.
+func (m *MultiAdapter) StartThread(ctx context.Context, channelID, messageID, replyText, threadName string) (string, error) {
+	p, err := m.route(channelID, "")
+	if err != nil {
+		return "", err
+	}
+	starter, ok := p.Adapter.(ThreadStarter)
+	if !ok {
+		return "", fmt.Errorf("telegraph: %s cannot start threads", p.Platform)
+	}
+	threadID, err := starter.StartThread(ctx, channelID, messageID, replyText, threadName)
+	if threadID != "" {
+		m.mu.Lock()
+		m.channels[threadID] = m.indexOf(p.Platform)
+		m.mu.Unlock()
+	}
+	return threadID, err
+}
+
+// indexOf returns the index of platform in m.platforms.
+func (m *MultiAdapter) indexOf(platform string) int {
+	for i, p := range m.platforms {
+		if p.Platform == platform {
+			return i
+		}
+	}
+	return 0
+}
+
+// ThreadHistory reads the thread from the platform that owns the channel.
+func (m *MultiAdapter) ThreadHistory(ctx context.Context, channelID, threadID string, limit int) ([]ThreadMessage, error) {
+	p, err := m.route(channelID, threadID)
+	if err != nil {
+		return nil, err
+	}
+	return p.Adapter.ThreadHistory(ctx, channelID, threadID, limit)
+}
+
+// ThreadHistorySince reads the newer part of the thread from the platform
+// that owns the channel. Implements ThreadHistorySincer.
+func (m *MultiAdapter) ThreadHistorySince(ctx context.Context, channelID, threadID, afterMessageID string, limit int) ([]ThreadMessage, error) {
+	p, err := m.route(channelID, threadID)
+	if err != nil {
+		return nil, err
+	}
+	return threadHistorySince(ctx, p.Adapter, channelID, threadID, afterMessageID, limit)
+}
+
+// DownloadFile fetches f from the platform it was uploaded to. Implements
+// FileDownloader.
+func (m *MultiAdapter) DownloadFile(ctx context.Context, f InboundFile, w io.Writer) error {
+	m.mu.RLock()
+	i, ok := m.files[f.URL]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("telegraph: no platform owns file %q", f.Name)
+	}
+	p := m.platforms[i]
+	dl, ok := p.Adapter.(FileDownloader)
+	if !ok {
+		return fmt.Errorf("telegraph: %s cannot download files", p.Platform)
+	}
+	return dl.DownloadFile(ctx, f, w)
+}
+
+// BotUserIDs returns the bot's user ID on each platform that exposes one.
+// The router treats a mention of any of them as a mention of the bot.
+func (m *MultiAdapter) BotUserIDs() []string {
+	var ids []string
+	for _, p := range m.platforms {
+		if b, ok := p.Adapter.(BotUserIDer); ok {
+			if id := b.BotUserID(); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// Close closes every platform.
+func (m *MultiAdapter) Close() error {
+	var errs []error
+	for _, p := range m.platforms {
+		if err := p.Adapter.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Platform, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,142 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// newTestMulti connects a Slack and a Discord mock behind a MultiAdapter.
+func newTestMulti(t *testing.T) (*MultiAdapter, *MockAdapter, *MockAdapter) {
+	t.Helper()
+	slack, discord := NewMockAdapter(), NewMockAdapter()
+	m, err := NewMultiAdapter(
+		PlatformAdapter{Platform: "slack", Channel: "C01", Adapter: slack},
+		PlatformAdapter{Platform: "discord", Channel: "111", Adapter: discord},
+	)
+	if err != nil {
+		t.Fatalf("NewMultiAdapter: %v", err)
+	}
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return m, slack, discord
+}
+
+func TestNewMultiAdapter_NoPlatforms(t *testing.T) {
+	if _, err := NewMultiAdapter(); err == nil {
+		t.Error("expected error without platforms")
+	}
+}
+
+func TestMultiAdapter_ListenAndRoute(t *testing.T) {
+	m, slack, discord := newTestMulti(t)
+	ctx := context.Background()
+	inbound, err := m.Listen(ctx)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+
+	discord.SimulateInbound(InboundMessage{ChannelID: "222", ThreadID: "333", Text: "!ry status"})
+	select {
+	case msg := <-inbound:
+		if msg.Platform != "discord" {
+			t.Errorf("Platform = %q, want discord", msg.Platform)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no merged inbound message")
+	}
+
+	// Replies go back to the platform the channel was seen on.
+	if err := m.Send(ctx, OutboundMessage{ChannelID: "222", ThreadID: "333", Text: "all good"}); err != nil {
+		t.Fatalf("Send reply: %v", err)
+	}
+	if slack.SentCount() != 0 || discord.SentCount() != 1 {
+		t.Errorf("sent slack=%d discord=%d, want the reply on discord only", slack.SentCount(), discord.SentCount())
+	}
+
+	// Top-level messages fan out to every platform.
+	if err := m.Send(ctx, OutboundMessage{Text: "Telegraph online"}); err != nil {
+		t.Fatalf("Send broadcast: %v", err)
+	}
+	if slack.SentCount() != 1 || discord.SentCount() != 2 {
+		t.Errorf("sent slack=%d discord=%d after broadcast", slack.SentCount(), discord.SentCount())
+	}
+
+	if err := m.Send(ctx, OutboundMessage{ChannelID: "C99", Text: "?"}); err == nil {
+		t.Error("expected error for a channel no platform owns")
+	}
+
+	m.Close()
+	select {
+	case _, ok := <-inbound:
+		if ok {
+			t.Error("unexpected message after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("merged inbound channel not closed after Close")
+	}
+}
+
+func TestRouter_MentionOfAnyPlatformBot(t *testing.T) {
+	m, slack, discord := newTestMulti(t)
+	slack.SetBotUserID("UBOT")
+	discord.SetBotUserID("9900112233")
+	r := &Router{adapter: m}
+
+	for _, text := range []string{"<@UBOT> deploy", "<@9900112233> deploy"} {
+		if !r.isBotMention(text) {
+			t.Errorf("isBotMention(%q) = false", text)
+		}
+	}
+	if r.isBotMention("<@U0OTHER> deploy") {
+		t.Error("mention of another user counted as a bot mention")
+	}
+	if !r.isSelfMessage(InboundMessage{UserID: "9900112233"}) {
+		t.Error("message from the Discord bot not filtered as self")
+	}
+}
+
+func TestHandleDetectedEvent_CarThreadMirroredOnEveryPlatform(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&models.CarThread{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	m, slack, discord := newTestMulti(t)
+	ctx := context.Background()
+	d := &Daemon{db: db, cfg: testCfg(), adapter: m, out: &bytes.Buffer{}}
+
+	for _, status := range []string{"open", "in_progress", "done"} {
+		d.handleDetectedEvent(ctx, DetectedEvent{
+			Type:      EventCarStatusChange,
+			CarID:     "backend-42",
+			NewStatus: status,
+			Title:     "Add login flow",
+		}, d.cfg.Telegraph.Events)
+	}
+
+	for name, mock := range map[string]*MockAdapter{"slack": slack, "discord": discord} {
+		sent := mock.AllSent()
+		if len(sent) != 3 {
+			t.Fatalf("%s: sent %d messages, want 3", name, len(sent))
+		}
+		if sent[0].ThreadID != "" || len(sent[0].Events) != 1 {
+			t.Errorf("%s: first event = %+v, want a top-level event", name, sent[0])
+		}
+		if sent[1].ThreadID != "thread-1" || sent[2].ThreadID != "thread-1" {
+			t.Errorf("%s: later events in threads %q, %q; want thread-1", name, sent[1].ThreadID, sent[2].ThreadID)
+		}
+		if mock.LastThreadName() != "backend-42: Add login flow" {
+			t.Errorf("%s: thread name = %q", name, mock.LastThreadName())
+		}
+	}
+
+	var threads []models.CarThread
+	db.Order("platform").Find(&threads)
+	if len(threads) != 2 || threads[0].Platform != "discord" || threads[0].ChannelID != "111" || threads[1].RootMessageID != "msg-1" {
+		t.Errorf("car threads = %+v", threads)
+	}
+}
@@ -0,0 +1,224 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event-kind notification targets. Any other target is a car or engine ID.
+const (
+	NotifyCars         = "cars"          // every car lifecycle change
+	NotifyEngineStalls = "engine-stalls" // every engine stall
+	NotifyEscalations  = "escalations"   // every escalation to a human
+)
+
+// notifyKinds lists the event-kind targets accepted by `!ry notify me on`.
+var notifyKinds = []string{NotifyCars, NotifyEngineStalls, NotifyEscalations}
+
+const notifyUsage = "Usage: `!ry notify me on <car-id|engine-id|event>`, `!ry notify me off <target>`, or `!ry notify me list`\n" +
+	"Events: `cars`, `engine-stalls`, `escalations`"
+
+// cmdNotify handles "!ry notify me on|off|list" for the sending user.
+func (ch *CommandHandler) cmdNotify(msg InboundMessage, args []string) string {
+	if len(args) < 2 || args[0] != "me" {
+		return notifyUsage
+	}
+	if msg.UserID == "" {
+		return "Notifications need a chat user; this message has none."
+	}
+
+	switch args[1] {
+	case "list":
+		return ch.notifyList(msg)
+	case "on", "off":
+		if len(args) < 3 {
+			return notifyUsage
+		}
+		if args[1] == "on" {
+			return ch.notifyOn(msg, args[2])
+		}
+		return ch.notifyOff(msg, args[2])
+	default:
+		return fmt.Sprintf("Unknown notify action: `%s`\n%s", args[1], notifyUsage)
+	}
+}
+
+// notifyOn subscribes the user to target after checking it names an event
+// kind, a car, or an engine.
+func (ch *CommandHandler) notifyOn(msg InboundMessage, target string) string {
+	if err := ch.validateNotifyTarget(target); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	sub := models.NotifySubscription{
+		Platform: msg.Platform,
+		UserID:   msg.UserID,
+		UserName: msg.UserName,
+		Target:   target,
+	}
+	if err := ch.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&sub).Error; err != nil {
+		return fmt.Sprintf("Error saving subscription: %v", err)
+	}
+	return fmt.Sprintf("You will get a DM for `%s`.", target)
+}
+
+// notifyOff removes the user's subscription to target.
+func (ch *CommandHandler) notifyOff(msg InboundMessage, target string) string {
+	res := ch.db.Where("platform = ? AND user_id = ? AND target = ?", msg.Platform, msg.UserID, target).
+		Delete(&models.NotifySubscription{})
+	if res.Error != nil {
+		return fmt.Sprintf("Error removing subscription: %v", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Sprintf("You are not subscribed to `%s`.", target)
+	}
+	return fmt.Sprintf("No more DMs for `%s`.", target)
+}
+
+// notifyList shows the user's subscriptions.
+func (ch *CommandHandler) notifyList(msg InboundMessage) string {
+	var subs []models.NotifySubscription
+	if err := ch.db.Where("platform = ? AND user_id = ?", msg.Platform, msg.UserID).
+		Order("target").Find(&subs).Error; err != nil {
+		return fmt.Sprintf("Error listing subscriptions: %v", err)
+	}
+	if len(subs) == 0 {
+		return "You have no notification subscriptions."
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**Your notifications** (%d)\n", len(subs)))
+	for _, s := range subs {
+		b.WriteString(fmt.Sprintf("- `%s`\n", s.Target))
+	}
+	return b.String()
+}
+
+// validateNotifyTarget accepts an event kind or the ID of an existing car
+// or engine.
+func (ch *CommandHandler) validateNotifyTarget(target string) error {
+	for _, k := range notifyKinds {
+		if target == k {
+			return nil
+		}
+	}
+	var count int64
+	if err := ch.db.Model(&models.Car{}).Where("id = ?", target).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := ch.db.Model(&models.Engine{}).Where("id = ?", target).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return fmt.Errorf("`%s` is not a car, engine, or event (%s)", target, strings.Join(notifyKinds, ", "))
+}
+
+// notifyTargets returns the subscription targets that match an event. Pulse
+// and digest events have none.
+func notifyTargets(event DetectedEvent) []string {
+	var targets []string
+	switch event.Type {
+	case EventCarStatusChange:
+		targets = append(targets, NotifyCars)
+	case EventEngineStalled:
+		targets = append(targets, NotifyEngineStalls)
+		if event.EngineID != "" {
+			targets = append(targets, event.EngineID)
+		}
+		if event.CurrentCar != "" {
+			targets = append(targets, event.CurrentCar)
+		}
+	case EventEscalation:
+		targets = append(targets, NotifyEscalations)
+	default:
+		return nil
+	}
+	if event.CarID != "" {
+		targets = append(targets, event.CarID)
+	}
+	return targets
+}
+
+// NotifyRecipients returns the IDs of users on platform subscribed to any
+// target matching event, deduplicated and sorted.
+func NotifyRecipients(db *gorm.DB, platform string, event DetectedEvent) ([]string, error) {
+	targets := notifyTargets(event)
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	var userIDs []string
+	if err := db.Model(&models.NotifySubscription{}).
+		Where("platform = ? AND target IN ?", platform, targets).
+		Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("telegraph: notify recipients: %w", err)
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// ownerPingStatuses are the car statuses that ping the car's owner: merged,
+// failed to merge or complete, or held because the branch changed.
+var ownerPingStatuses = map[string]bool{
+	"merged":          true,
+	"merge-failed":    true,
+	"needs-attention": true,
+	"blocked":         true,
+}
+
+// OwnerRecipient returns the platform user ID of the car owner to ping for
+// event, resolved through users (owner handle → user ID). Owners are pinged
+// when their car merges, fails, or escalates.
+func OwnerRecipient(users map[string]string, event DetectedEvent) (string, bool) {
+	if event.Owner == "" {
+		return "", false
+	}
+	switch event.Type {
+	case EventCarStatusChange:
+		if !ownerPingStatuses[event.NewStatus] {
+			return "", false
+		}
+	case EventEscalation:
+	default:
+		return "", false
+	}
+	uid, ok := users[event.Owner]
+	return uid, ok && uid != ""
+}
+
+// notifySubscribers DMs every user subscribed to the event, plus the car's
+// owner where the event warrants it, on each platform. Failures are logged
+// per user and never block channel delivery.
+func (d *Daemon) notifySubscribers(ctx context.Context, event DetectedEvent, formatted FormattedEvent) {
+	for _, p := range d.platforms() {
+		dm, ok := p.Adapter.(DirectMessenger)
+		if !ok {
+			continue
+		}
+		userIDs, err := NotifyRecipients(d.db, p.Platform, event)
+		if err != nil {
+			log.Printf("%v", err)
+			continue
+		}
+		if owner, ok := OwnerRecipient(d.cfg.Telegraph.PlatformUsers(p.Platform), event); ok && !slices.Contains(userIDs, owner) {
+			userIDs = append(userIDs, owner)
+		}
+		for _, uid := range userIDs {
+			if err := dm.SendDirect(ctx, uid, OutboundMessage{
+				Events: []FormattedEvent{formatted},
+			}); err != nil {
+				log.Printf("telegraph: notify %s of %s: %v", uid, event.Type, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+func openNotifyTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openCommandTestDB(t)
+	if err := db.AutoMigrate(&models.NotifySubscription{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	return db
+}
+
+func notifyMsg(userID string) InboundMessage {
+	return InboundMessage{Platform: "slack", ChannelID: "C1", UserID: userID, UserName: "alice"}
+}
+
+// dmAdapter adds DirectMessenger to MockAdapter.
+type dmAdapter struct {
+	*MockAdapter
+	mu   sync.Mutex
+	sent map[string][]OutboundMessage // userID -> DMs
+}
+
+func newDMAdapter() *dmAdapter {
+	return &dmAdapter{MockAdapter: NewMockAdapter(), sent: make(map[string][]OutboundMessage)}
+}
+
+func (a *dmAdapter) SendDirect(ctx context.Context, userID string, msg OutboundMessage) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sent[userID] = append(a.sent[userID], msg)
+	return nil
+}
+
+func (a *dmAdapter) directCount(userID string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.sent[userID])
+}
+
+func TestExecuteFrom_NotifyOnListOff(t *testing.T) {
+	db := openNotifyTestDB(t)
+	db.Create(&models.Car{ID: "car-123", Title: "Login", Track: "backend", Status: "open"})
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+	msg := notifyMsg("U1")
+
+	if got := ch.ExecuteFrom(msg, "!ry notify me on car-123"); !strings.Contains(got, "DM for `car-123`") {
+		t.Fatalf("on car = %q", got)
+	}
+	if got := ch.ExecuteFrom(msg, "!ry notify me on engine-stalls"); !strings.Contains(got, "engine-stalls") {
+		t.Fatalf("on event = %q", got)
+	}
+	// Re-subscribing is idempotent.
+	ch.ExecuteFrom(msg, "!ry notify me on car-123")
+
+	list := ch.ExecuteFrom(msg, "!ry notify me list")
+	if !strings.Contains(list, "(2)") || !strings.Contains(list, "car-123") || !strings.Contains(list, "engine-stalls") {
+		t.Errorf("list = %q", list)
+	}
+	if other := ch.ExecuteFrom(notifyMsg("U2"), "!ry notify me list"); !strings.Contains(other, "no notification subscriptions") {
+		t.Errorf("other user list = %q", other)
+	}
+
+	if got := ch.ExecuteFrom(msg, "!ry notify me off car-123"); !strings.Contains(got, "No more DMs") {
+		t.Errorf("off = %q", got)
+	}
+	if got := ch.ExecuteFrom(msg, "!ry notify me off car-123"); !strings.Contains(got, "not subscribed") {
+		t.Errorf("second off = %q", got)
+	}
+
+	var count int64
+	db.Model(&models.NotifySubscription{}).Count(&count)
+	if count != 1 {
+		t.Errorf("subscriptions = %d, want 1", count)
+	}
+}
+
+func TestExecuteFrom_NotifyUnknownTarget(t *testing.T) {
+	db := openNotifyTestDB(t)
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	got := ch.ExecuteFrom(notifyMsg("U1"), "!ry notify me on car-nope")
+	if !strings.Contains(got, "not a car, engine, or event") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestExecuteFrom_NotifyEngineTarget(t *testing.T) {
+	db := openNotifyTestDB(t)
+	db.Create(&models.Engine{ID: "eng-a1", Track: "backend", Status: "working"})
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	if got := ch.ExecuteFrom(notifyMsg("U1"), "!ry notify me on eng-a1"); !strings.Contains(got, "DM for `eng-a1`") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestExecute_NotifyNeedsUser(t *testing.T) {
+	db := openNotifyTestDB(t)
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	if got := ch.Execute("!ry notify me list"); !strings.Contains(got, "need a chat user") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestExecuteFrom_NotifyUsage(t *testing.T) {
+	db := openNotifyTestDB(t)
+	ch, _ := NewCommandHandler(CommandHandlerOpts{DB: db})
+
+	for _, text := range []string{"!ry notify", "!ry notify you on cars", "!ry notify me on", "!ry notify me maybe cars"} {
+		if got := ch.ExecuteFrom(notifyMsg("U1"), text); !strings.Contains(got, "Usage:") {
+			t.Errorf("%q → %q, want usage", text, got)
+		}
+	}
+}
+
+func TestNotifyRecipients(t *testing.T) {
+	db := openNotifyTestDB(t)
+	subs := []models.NotifySubscription{
+		{Platform: "slack", UserID: "U1", Target: "car-123"},
+		{Platform: "slack", UserID: "U2", Target: NotifyCars},
+		{Platform: "slack", UserID: "U3", Target: NotifyEngineStalls},
+		{Platform: "slack", UserID: "U4", Target: "eng-a1"},
+		{Platform: "slack", UserID: "U1", Target: NotifyEscalations},
+		{Platform: "discord", UserID: "D1", Target: "car-123"},
+	}
+	for i := range subs {
+		db.Create(&subs[i])
+	}
+
+	tests := []struct {
+		name  string
+		event DetectedEvent
+		want  string
+	}{
+		{"car change", DetectedEvent{Type: EventCarStatusChange, CarID: "car-123"}, "U1,U2"},
+		{"other car", DetectedEvent{Type: EventCarStatusChange, CarID: "car-9"}, "U2"},
+		{"stall on car", DetectedEvent{Type: EventEngineStalled, EngineID: "eng-a1", CurrentCar: "car-123"}, "U1,U3,U4"},
+		{"escalation", DetectedEvent{Type: EventEscalation, CarID: "car-9"}, "U1"},
+		{"pulse", DetectedEvent{Type: EventPulse}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NotifyRecipients(db, "slack", tt.event)
+			if err != nil {
+				t.Fatalf("NotifyRecipients: %v", err)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("recipients = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleDetectedEvent_DMsSubscribers(t *testing.T) {
+	db := openNotifyTestDB(t)
+	db.Create(&models.NotifySubscription{Platform: "slack", UserID: "U1", Target: "backend-42"})
+	db.Create(&models.NotifySubscription{Platform: "slack", UserID: "U2", Target: NotifyEngineStalls})
+
+	adapter := newDMAdapter()
+	ctx := context.Background()
+	adapter.Connect(ctx)
+
+	cfg := testCfg()
+	cfg.Telegraph.Platform = "slack"
+	cfg.Telegraph.Events.CarLifecycle = false // channel muted; DMs still go out
+	d := &Daemon{db: db, cfg: cfg, adapter: adapter, out: &bytes.Buffer{}}
+
+	d.handleDetectedEvent(ctx, DetectedEvent{
+		Type:      EventCarStatusChange,
+		CarID:     "backend-42",
+		OldStatus: "in_progress",
+		NewStatus: "done",
+		Track:     "backend",
+	}, cfg.Telegraph.Events)
+
+	if adapter.SentCount() != 0 {
+		t.Errorf("channel messages = %d, want 0", adapter.SentCount())
+	}
+	if adapter.directCount("U1") != 1 {
+		t.Errorf("DMs to U1 = %d, want 1", adapter.directCount("U1"))
+	}
+	if adapter.directCount("U2") != 0 {
+		t.Errorf("DMs to U2 = %d, want 0", adapter.directCount("U2"))
+	}
+}
+
+func TestOwnerRecipient(t *testing.T) {
+	users := map[string]string{"alice": "U0ALICE"}
+	tests := []struct {
+		name  string
+		event DetectedEvent
+		want  string
+	}{
+		{"merged", DetectedEvent{Type: EventCarStatusChange, NewStatus: "merged", Owner: "alice"}, "U0ALICE"},
+		{"merge failed", DetectedEvent{Type: EventCarStatusChange, NewStatus: "merge-failed", Owner: "alice"}, "U0ALICE"},
+		{"blocked", DetectedEvent{Type: EventCarStatusChange, NewStatus: "blocked", Owner: "alice"}, "U0ALICE"},
+		{"escalation", DetectedEvent{Type: EventEscalation, CarID: "car-1", Owner: "alice"}, "U0ALICE"},
+		{"claimed is quiet", DetectedEvent{Type: EventCarStatusChange, NewStatus: "claimed", Owner: "alice"}, ""},
+		{"no owner", DetectedEvent{Type: EventCarStatusChange, NewStatus: "merged"}, ""},
+		{"unmapped owner", DetectedEvent{Type: EventCarStatusChange, NewStatus: "merged", Owner: "bob"}, ""},
+		{"stall", DetectedEvent{Type: EventEngineStalled, Owner: "alice"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := OwnerRecipient(users, tt.event)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("OwnerRecipient = %q, %v; want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleDetectedEvent_PingsOwnerOnce(t *testing.T) {
+	db := openNotifyTestDB(t)
+	// The owner also subscribed to the car; they should get one DM, not two.
+	db.Create(&models.NotifySubscription{Platform: "slack", UserID: "U0ALICE", Target: "car-1"})
+
+	adapter := newDMAdapter()
+	ctx := context.Background()
+	adapter.Connect(ctx)
+
+	cfg := testCfg()
+	cfg.Telegraph.Platform = "slack"
+	cfg.Telegraph.Users = map[string]string{"alice": "U0ALICE", "bob": "U0BOB"}
+	d := &Daemon{db: db, cfg: cfg, adapter: adapter, out: &bytes.Buffer{}}
+
+	d.handleDetectedEvent(ctx, DetectedEvent{
+		Type:      EventCarStatusChange,
+		CarID:     "car-1",
+		OldStatus: "done",
+		NewStatus: "merged",
+		Owner:     "alice",
+	}, cfg.Telegraph.Events)
+	d.handleDetectedEvent(ctx, DetectedEvent{
+		Type:      EventCarStatusChange,
+		CarID:     "car-2",
+		OldStatus: "done",
+		NewStatus: "merge-failed",
+		Owner:     "bob",
+	}, cfg.Telegraph.Events)
+
+	if n := adapter.directCount("U0ALICE"); n != 1 {
+		t.Errorf("DMs to alice = %d, want 1", n)
+	}
+	if n := adapter.directCount("U0BOB"); n != 1 {
+		t.Errorf("DMs to bob = %d, want 1", n)
+	}
+	if adapter.SentCount() != 2 {
+		t.Errorf("channel messages = %d, want 2", adapter.SentCount())
+	}
+}
@@ -35,6 +35,9 @@ type OpenRouterSpawner struct {
 	CodeSearch *agentloop.CodeSearchParams
 	// MaxIterations bounds the loop; 0 uses the agentloop default.
 	MaxIterations int
+	// ReadOnly swaps the dispatch profile for agentloop.ReadOnlyTools
+	// (read_file, plus codesearch), dropping bash. Used for `!ry ask`.
+	ReadOnly bool
 	// RateLimitMaxRetries bounds 429 pause-and-retry attempts for one dispatch
 	// turn; 0 uses agentloop.DefaultRateLimitMaxRetries.
 	RateLimitMaxRetries int
@@ -51,12 +54,17 @@ func (s *OpenRouterSpawner) Spawn(ctx context.Context, prompt string) (Process,
 		return nil, fmt.Errorf("telegraph: openrouter spawn: client not configured")
 	}
 
+	tools := agentloop.DispatchTools(s.WorkDir, s.CodeSearch)
+	if s.ReadOnly {
+		tools = agentloop.ReadOnlyTools(s.WorkDir, s.CodeSearch)
+	}
+
 	loopCtx, cancel := context.WithCancel(ctx)
 	events := make(chan agentloop.Event, 64)
 	loop := agentloop.NewLoop(s.Client, agentloop.LoopConfig{
 		Model:         s.Model,
 		SystemPrompt:  s.SystemPrompt,
-		Tools:         agentloop.DispatchTools(s.WorkDir, s.CodeSearch),
+		Tools:         tools,
 		MaxIterations: s.MaxIterations,
 		Events:        events,
 		Role:          "telegraph",
@@ -0,0 +1,220 @@
+package telegraph
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// pidProcess is implemented by Process values backed by an OS subprocess.
+// The PID is also the process group ID (spawned with Setpgid).
+type pidProcess interface {
+	PID() int
+}
+
+// pidStartTolerance is how far a live process's start time may differ from
+// the recorded ProcessStartedAt before the PID is assumed reused.
+const pidStartTolerance = 5 * time.Second
+
+// reapGrace is how long ReapOrphans waits after SIGTERM before SIGKILL.
+var reapGrace = 5 * time.Second
+
+// ReapOpts configures ReapOrphans.
+type ReapOpts struct {
+	// Host limits reaping to processes recorded on this host; defaults to
+	// os.Hostname. PIDs from other hosts are meaningless here.
+	Host string
+	// StaleBefore, when set, skips active sessions whose heartbeat is newer,
+	// so a running daemon's live sessions are left alone. Zero reaps every
+	// recorded process, which is only safe at daemon startup.
+	StaleBefore time.Time
+	Clock       clock.Clock // defaults to clock.Real
+}
+
+// ReapedSession is one session ReapOrphans cleaned up.
+type ReapedSession struct {
+	SessionID uint
+	PID       int
+	Killed    bool // the process was still running and was signalled
+	Expired   bool // the session's dispatch lock was released
+}
+
+// ReapOrphans cleans up dispatch subprocesses left behind by a telegraph
+// process that crashed: it kills each recorded process group still running
+// on this host, clears the recorded PID, and expires the session's dispatch
+// lock if it is still held. A PID whose process started at a different time
+// than recorded belongs to an unrelated process and is not signalled.
+func ReapOrphans(db *gorm.DB, opts ReapOpts) ([]ReapedSession, error) {
+	host := opts.Host
+	if host == "" {
+		host = processHost()
+	}
+	clk := clock.OrReal(opts.Clock)
+
+	q := db.Where("source = ? AND process_pid > 0 AND process_host = ?", "telegraph", host)
+	if !opts.StaleBefore.IsZero() {
+		q = q.Where("status <> ? OR last_heartbeat < ?", "active", opts.StaleBefore)
+	}
+	var sessions []models.DispatchSession
+	if err := q.Order("id").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("telegraph: reap: list sessions: %w", err)
+	}
+
+	var reaped []ReapedSession
+	for _, s := range sessions {
+		r := ReapedSession{SessionID: s.ID, PID: s.ProcessPID}
+		if processRunning(s.ProcessPID, s.ProcessStartedAt) {
+			if err := killProcessGroup(s.ProcessPID); err != nil {
+				log.Printf("telegraph: reap session %d: kill pid %d: %v", s.ID, s.ProcessPID, err)
+			} else {
+				r.Killed = true
+			}
+		}
+
+		updates := map[string]interface{}{"process_pid": 0}
+		if s.Status == "active" {
+			updates["status"] = "expired"
+			updates["completed_at"] = clk.Now()
+			r.Expired = true
+		}
+		if err := db.Model(&models.DispatchSession{}).Where("id = ?", s.ID).Updates(updates).Error; err != nil {
+			return reaped, fmt.Errorf("telegraph: reap session %d: %w", s.ID, err)
+		}
+		log.Printf("telegraph: reaped session %d [pid=%d killed=%v expired=%v]", s.ID, s.ProcessPID, r.Killed, r.Expired)
+		reaped = append(reaped, r)
+	}
+	return reaped, nil
+}
+
+// recordProcess stores the subprocess PID on the session row so a later
+// telegraph can reap it if this one dies. Processes without a PID (the
+// in-process native loop) are not recorded.
+func (sm *SessionManager) recordProcess(sessionID uint, proc Process) {
+	pp, ok := proc.(pidProcess)
+	if !ok || pp.PID() <= 0 {
+		return
+	}
+	// Wall time, not sm.clock: it is compared against the OS process start.
+	now := time.Now()
+	if err := sm.db.Model(&models.DispatchSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"process_pid":        pp.PID(),
+		"process_host":       processHost(),
+		"process_started_at": now,
+	}).Error; err != nil {
+		log.Printf("telegraph: session %d: record pid: %v", sessionID, err)
+	}
+}
+
+// clearProcess forgets the session's PID once the subprocess has exited.
+func (sm *SessionManager) clearProcess(sessionID uint) {
+	if err := sm.db.Model(&models.DispatchSession{}).Where("id = ? AND process_pid > 0", sessionID).
+		Update("process_pid", 0).Error; err != nil {
+		log.Printf("telegraph: session %d: clear pid: %v", sessionID, err)
+	}
+}
+
+func processHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "localhost"
+	}
+	return host
+}
+
+// processRunning reports whether pid is a live (non-zombie) process that
+// started at about startedAt. Without /proc the start time cannot be checked
+// and only liveness is.
+func processRunning(pid int, startedAt *time.Time) bool {
+	if err := syscall.Kill(pid, 0); err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+	state, start, ok := procStat(pid)
+	if state == "Z" || state == "X" {
+		return false
+	}
+	if !ok {
+		return true
+	}
+	if startedAt != nil {
+		if d := start.Sub(*startedAt); d > pidStartTolerance || d < -pidStartTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// killProcessGroup sends SIGTERM to the group led by pid, then SIGKILL if it
+// is still running after reapGrace.
+func killProcessGroup(pid int) error {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		return err
+	}
+	deadline := time.Now().Add(reapGrace)
+	for time.Now().Before(deadline) {
+		if !processRunning(pid, nil) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
+
+// procStat reads a process's state and start time from /proc (Linux). ok is
+// false where /proc is unavailable.
+func procStat(pid int) (state string, start time.Time, ok bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	// The command name (field 2) may contain spaces; fields resume after ')'.
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return "", time.Time{}, false
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 20 {
+		return "", time.Time{}, false
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64) // field 22: starttime
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	boot, ok := bootTime()
+	if !ok {
+		return fields[0], time.Time{}, false
+	}
+	const clockTicks = 100 // USER_HZ; 100 on every mainstream Linux platform
+	return fields[0], boot.Add(time.Duration(ticks) * time.Second / clockTicks), true
+}
+
+func bootTime() (time.Time, bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "btime "); ok {
+			secs, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			return time.Unix(secs, 0), true
+		}
+	}
+	return time.Time{}, false
+}
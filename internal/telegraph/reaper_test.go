@@ -0,0 +1,174 @@
+package telegraph
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// startOrphan starts a long-running process in its own process group, like a
+// dispatch subprocess, and returns it with a channel closed when it exits.
+func startOrphan(t *testing.T) (*exec.Cmd, <-chan struct{}) {
+	t.Helper()
+	cmd := exec.Command("sleep", "60")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+	})
+	return cmd, done
+}
+
+func createProcessSession(t *testing.T, db *gorm.DB, status, host string, pid int, started time.Time, heartbeat time.Time) *models.DispatchSession {
+	t.Helper()
+	s := &models.DispatchSession{
+		Source:           "telegraph",
+		UserName:         "alice",
+		PlatformThreadID: "thread",
+		ChannelID:        "C01",
+		Status:           status,
+		CarsCreated:      "[]",
+		LastHeartbeat:    heartbeat,
+		ProcessPID:       pid,
+		ProcessHost:      host,
+		ProcessStartedAt: &started,
+	}
+	if err := db.Create(s).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return s
+}
+
+func TestReapOrphans_KillsAndExpires(t *testing.T) {
+	db := openSessionTestDB(t)
+	cmd, done := startOrphan(t)
+	s := createProcessSession(t, db, "active", "host-a", cmd.Process.Pid, time.Now(), time.Now())
+
+	reaped, err := ReapOrphans(db, ReapOpts{Host: "host-a"})
+	if err != nil {
+		t.Fatalf("ReapOrphans: %v", err)
+	}
+	if len(reaped) != 1 || !reaped[0].Killed || !reaped[0].Expired {
+		t.Fatalf("reaped = %+v, want one killed and expired", reaped)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("orphan still running after reap")
+	}
+
+	var got models.DispatchSession
+	db.First(&got, s.ID)
+	if got.Status != "expired" || got.ProcessPID != 0 || got.CompletedAt == nil {
+		t.Errorf("session = status %q pid %d completed_at %v", got.Status, got.ProcessPID, got.CompletedAt)
+	}
+}
+
+func TestReapOrphans_SkipsReusedPIDAndOtherHosts(t *testing.T) {
+	db := openSessionTestDB(t)
+	cmd, done := startOrphan(t)
+	// Same PID, but recorded as started an hour ago: an unrelated process.
+	reused := createProcessSession(t, db, "expired", "host-a", cmd.Process.Pid, time.Now().Add(-time.Hour), time.Now())
+	other := createProcessSession(t, db, "active", "host-b", cmd.Process.Pid, time.Now(), time.Now())
+
+	reaped, err := ReapOrphans(db, ReapOpts{Host: "host-a"})
+	if err != nil {
+		t.Fatalf("ReapOrphans: %v", err)
+	}
+	if len(reaped) != 1 || reaped[0].SessionID != reused.ID || reaped[0].Killed || reaped[0].Expired {
+		t.Fatalf("reaped = %+v, want only the reused-PID session, cleared without a kill", reaped)
+	}
+	select {
+	case <-done:
+		t.Fatal("process with a reused PID was killed")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	var got models.DispatchSession
+	db.First(&got, other.ID)
+	if got.ProcessPID == 0 || got.Status != "active" {
+		t.Errorf("other host's session touched: %+v", got)
+	}
+}
+
+func TestReapOrphans_StaleBeforeSparesLiveSessions(t *testing.T) {
+	db := openSessionTestDB(t)
+	cmd, done := startOrphan(t)
+	live := createProcessSession(t, db, "active", "host-a", cmd.Process.Pid, time.Now(), time.Now())
+
+	reaped, err := ReapOrphans(db, ReapOpts{Host: "host-a", StaleBefore: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("ReapOrphans: %v", err)
+	}
+	if len(reaped) != 0 {
+		t.Fatalf("reaped = %+v, want the live session spared", reaped)
+	}
+
+	db.Model(live).Update("last_heartbeat", time.Now().Add(-time.Hour))
+	reaped, err = ReapOrphans(db, ReapOpts{Host: "host-a", StaleBefore: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("ReapOrphans: %v", err)
+	}
+	if len(reaped) != 1 || !reaped[0].Killed {
+		t.Fatalf("reaped = %+v, want the stale session killed", reaped)
+	}
+	<-done
+}
+
+// pidMockProcess is a mockProcess that reports an OS PID.
+type pidMockProcess struct {
+	*mockProcess
+	pid int
+}
+
+func (p *pidMockProcess) PID() int { return p.pid }
+
+type pidSpawner struct {
+	proc *pidMockProcess
+}
+
+func (s *pidSpawner) Spawn(_ context.Context, prompt string) (Process, error) {
+	s.proc = &pidMockProcess{mockProcess: newMockProcess(prompt), pid: 4242}
+	return s.proc, nil
+}
+
+func TestSessionManager_RecordsAndClearsPID(t *testing.T) {
+	db := openSessionTestDB(t)
+	spawner := &pidSpawner{}
+	sm, _ := NewSessionManager(SessionManagerOpts{DB: db, Spawner: spawner})
+
+	session, err := sm.NewSession(context.Background(), "telegraph", "alice", "thread-1", "C01")
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	var got models.DispatchSession
+	db.First(&got, session.ID)
+	if got.ProcessPID != 4242 || got.ProcessHost != processHost() || got.ProcessStartedAt == nil {
+		t.Fatalf("recorded = pid %d host %q started %v", got.ProcessPID, got.ProcessHost, got.ProcessStartedAt)
+	}
+
+	close(spawner.proc.recvCh)
+	spawner.proc.exitWith(nil)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		db.First(&got, session.ID)
+		if got.ProcessPID == 0 && got.Status == "completed" {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Errorf("after exit: pid %d status %q, want pid cleared and session completed", got.ProcessPID, got.Status)
+}
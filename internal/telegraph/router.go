@@ -8,8 +8,11 @@ import (
 	"math/rand"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
+
+	"github.com/zulandar/railyard/internal/clock"
 )
 
 // commandPrefix is the prefix that triggers read-only command handling.
@@ -25,6 +28,10 @@ type Router struct {
 	botUserID  string // construction-time bot user ID; fallback when the adapter exposes no live id (see resolveBotUserID)
 	out        io.Writer
 	titleGen   TitleGenerator // generates descriptive thread titles; nil → fallback
+	asker      *Asker         // answers `!ry ask`; nil → ask disabled
+	asks       sync.WaitGroup // in-flight `!ry ask` answers, waited on by drain
+	limits     InboundLimits
+	flood      *floodGuard // nil when MaxPerMinute is 0
 
 	ackMu   sync.Mutex
 	ackDeck []string // shuffled phrases, popped from end
@@ -38,6 +45,9 @@ type RouterOpts struct {
 	BotUserID  string         // bot's user ID for self-message filtering
 	Out        io.Writer      // defaults to os.Stdout
 	TitleGen   TitleGenerator // optional; generates thread titles from message body
+	Asker      *Asker         // optional; enables `!ry ask`
+	Limits     InboundLimits  // optional; zero fields disable each limit
+	Clock      clock.Clock    // drives the per-user rate limit; defaults to clock.Real
 }
 
 // NewRouter creates a Router.
@@ -55,6 +65,10 @@ func NewRouter(opts RouterOpts) (*Router, error) {
 	if out == nil {
 		out = os.Stdout
 	}
+	var flood *floodGuard
+	if opts.Limits.MaxPerMinute > 0 {
+		flood = newFloodGuard(opts.Limits.MaxPerMinute, opts.Clock)
+	}
 	return &Router{
 		sessionMgr: opts.SessionMgr,
 		cmdHandler: opts.CmdHandler,
@@ -62,12 +76,16 @@ func NewRouter(opts RouterOpts) (*Router, error) {
 		botUserID:  opts.BotUserID,
 		out:        out,
 		titleGen:   opts.TitleGen,
+		asker:      opts.Asker,
+		limits:     opts.Limits,
+		flood:      flood,
 	}, nil
 }
 
 // Handle classifies and routes a single inbound message. Routing paths:
 //
-//  1. Bot self-message → ignore
+//  1. Bot self-message → ignore; messages to the bot over the inbound
+//     limits → trimmed or dropped with a reply (see applyLimits)
 //  2. Known command ("!ry status") or @mention with command ("@bot status") → command handler
 //  3. Thread reply:
 //     a. Active session in thread → Route()
@@ -86,6 +104,13 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 	fmt.Fprintf(r.out, "telegraph: router: recv [ch=%s thread=%s user=%s] %q\n",
 		msg.ChannelID, msg.ThreadID, msg.UserName, truncate(text, 80))
 
+	if r.limits != (InboundLimits{}) && r.addressedToBot(msg, text) {
+		var ok bool
+		if text, ok = r.applyLimits(ctx, msg, text); !ok {
+			return
+		}
+	}
+
 	// 2. Known command ("!ry status") or @mention with command ("@bot status").
 	if isCommand(text) {
 		fmt.Fprintf(r.out, "telegraph: router: → command\n")
@@ -105,6 +130,7 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 		if r.sessionMgr.HasSession(msg.ChannelID, msg.ThreadID) {
 			fmt.Fprintf(r.out, "telegraph: router: → active session [ch=%s thread=%s]\n", msg.ChannelID, msg.ThreadID)
 			r.sendAck(ctx, msg.ChannelID, msg.ThreadID)
+			text = r.withAttachments(ctx, msg, msg.ChannelID, msg.ThreadID, text)
 			if err := r.sessionMgr.Route(ctx, msg.ChannelID, msg.ThreadID, msg.UserName, text); err != nil {
 				log.Printf("telegraph: router: route to session: %v", err)
 			}
@@ -115,6 +141,7 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 		if r.sessionMgr.HasHistoricSession(msg.ChannelID, msg.ThreadID) {
 			fmt.Fprintf(r.out, "telegraph: router: → resume session [ch=%s thread=%s]\n", msg.ChannelID, msg.ThreadID)
 			r.sendAck(ctx, msg.ChannelID, msg.ThreadID)
+			text = r.withAttachments(ctx, msg, msg.ChannelID, msg.ThreadID, text)
 			_, err := r.sessionMgr.Resume(ctx, msg.ChannelID, msg.ThreadID, msg.UserName, text)
 			if err != nil {
 				log.Printf("telegraph: router: resume session: %v", err)
@@ -133,6 +160,7 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 				r.sendUnavailable(ctx, msg.ChannelID, msg.ThreadID)
 				return
 			}
+			text = r.withAttachments(ctx, msg, msg.ChannelID, msg.ThreadID, text)
 			if err := r.sessionMgr.Route(ctx, msg.ChannelID, msg.ThreadID, msg.UserName, text); err != nil {
 				log.Printf("telegraph: router: route initial message: %v", err)
 			}
@@ -155,6 +183,7 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 
 		if r.sessionMgr.HasSession(channelID, threadID) {
 			r.sendAck(ctx, channelID, threadID)
+			text = r.withAttachments(ctx, msg, channelID, threadID, text)
 			if err := r.sessionMgr.Route(ctx, channelID, threadID, msg.UserName, text); err != nil {
 				log.Printf("telegraph: router: route to session (recovered): %v", err)
 			}
@@ -163,6 +192,7 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 
 		if r.sessionMgr.HasHistoricSession(channelID, threadID) {
 			r.sendAck(ctx, channelID, threadID)
+			text = r.withAttachments(ctx, msg, channelID, threadID, text)
 			_, err := r.sessionMgr.Resume(ctx, channelID, threadID, msg.UserName, text)
 			if err != nil {
 				log.Printf("telegraph: router: resume session (recovered): %v", err)
@@ -200,6 +230,7 @@ func (r *Router) Handle(ctx context.Context, msg InboundMessage) {
 			r.sendUnavailable(ctx, msg.ChannelID, sessionThreadID)
 			return
 		}
+		text = r.withAttachments(ctx, msg, msg.ChannelID, sessionThreadID, text)
 		if err := r.sessionMgr.Route(ctx, msg.ChannelID, sessionThreadID, msg.UserName, text); err != nil {
 			log.Printf("telegraph: router: route initial message: %v", err)
 		}
@@ -222,7 +253,11 @@ func truncate(s string, maxLen int) string {
 // Long responses are chunked to stay within platform message limits
 // (e.g. Discord's 2000-character cap).
 func (r *Router) handleCommand(ctx context.Context, msg InboundMessage, text string) {
-	response := r.cmdHandler.Execute(text)
+	if question, ok := askQuestion(text); ok {
+		r.handleAsk(ctx, msg, question)
+		return
+	}
+	response := r.cmdHandler.ExecuteFrom(msg, text)
 	chunks := chunkMessage(response, 2000)
 	for _, chunk := range chunks {
 		if err := r.adapter.Send(ctx, OutboundMessage{
@@ -236,6 +271,84 @@ func (r *Router) handleCommand(ctx context.Context, msg InboundMessage, text str
 	}
 }
 
+// askQuestion returns the question in a "!ry ask <question>" command.
+func askQuestion(text string) (string, bool) {
+	args := parseCommand(text)
+	if len(args) == 0 || args[0] != "ask" {
+		return "", false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(text, commandPrefix))
+	question := strings.TrimSpace(strings.TrimPrefix(rest, "ask"))
+	return strings.Trim(question, `"“”`), true
+}
+
+// handleAsk answers a `!ry ask` question with a read-only agent session and
+// replies in the message's thread, starting one for a top-level question.
+// It never takes the dispatch lock, so questions run alongside dispatch. The
+// agent runs in the background; the router goes back to handling messages.
+func (r *Router) handleAsk(ctx context.Context, msg InboundMessage, question string) {
+	reply := func(threadID, text string) {
+		for _, chunk := range chunkMessage(text, 2000) {
+			if err := r.adapter.Send(ctx, OutboundMessage{
+				ChannelID: msg.ChannelID,
+				ThreadID:  threadID,
+				Text:      chunk,
+			}); err != nil {
+				log.Printf("telegraph: router: send ask response: %v", err)
+				return
+			}
+		}
+	}
+
+	if r.asker == nil {
+		reply(msg.ThreadID, "`!ry ask` is not configured on this telegraph.")
+		return
+	}
+	if question == "" {
+		reply(msg.ThreadID, "Usage: `!ry ask <question>` — e.g. `!ry ask \"what's blocking the payments epic?\"`")
+		return
+	}
+
+	threadID := msg.ThreadID
+	if threadID == "" {
+		if ts, ok := r.adapter.(ThreadStarter); ok {
+			newThreadID, err := ts.StartThread(ctx, msg.ChannelID, msg.MessageID, "Looking into it...", truncate(question, 80))
+			if err != nil {
+				log.Printf("telegraph: router: create ask thread: %v", err)
+			} else {
+				threadID = newThreadID
+			}
+		}
+	}
+
+	fmt.Fprintf(r.out, "telegraph: router: → ask [ch=%s thread=%s] %q\n", msg.ChannelID, threadID, truncate(question, 80))
+	r.asks.Go(func() {
+		answer, err := r.asker.Ask(ctx, question)
+		if err != nil {
+			log.Printf("telegraph: router: ask: %v", err)
+			reply(threadID, fmt.Sprintf("Couldn't answer that: %v", err))
+			return
+		}
+		reply(threadID, answer)
+	})
+}
+
+// drain waits for in-flight `!ry ask` answers to be posted, or for ctx to
+// end.
+func (r *Router) drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.asks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // ackPhrases are the random acknowledgment messages the bot sends when it
 // starts working on a dispatch request.
 var ackPhrases = []string{
@@ -393,10 +506,21 @@ func (r *Router) resolveBotUserID() string {
 	return r.botUserID
 }
 
+// isBotUserID reports whether id is the bot's own user id. Behind a
+// MultiAdapter the bot has one id per platform and any of them matches.
+func (r *Router) isBotUserID(id string) bool {
+	if id == "" {
+		return false
+	}
+	if m, ok := r.adapter.(*MultiAdapter); ok {
+		return slices.Contains(m.BotUserIDs(), id)
+	}
+	return id == r.resolveBotUserID()
+}
+
 // isSelfMessage returns true if the message is from the bot itself.
 func (r *Router) isSelfMessage(msg InboundMessage) bool {
-	botID := r.resolveBotUserID()
-	return botID != "" && msg.UserID == botID
+	return r.isBotUserID(msg.UserID)
 }
 
 // isCommand returns true if the text is a known "!ry" command (e.g. "!ry status",
@@ -435,6 +559,8 @@ var knownCommands = map[string]bool{
 	"status": true,
 	"car":    true,
 	"engine": true,
+	"notify": true,
+	"ask":    true,
 	"help":   true,
 }
 
@@ -473,12 +599,8 @@ func (r *Router) extractMentionCommand(text string) string {
 // but once the gateway READY event populates it, mentions start matching
 // without rebuilding the router (railyard-1q9).
 func (r *Router) isBotMention(text string) bool {
-	botID := r.resolveBotUserID()
-	if botID == "" {
-		return false
-	}
 	for _, m := range mentionRe.FindAllStringSubmatch(text, -1) {
-		if m[1] == botID {
+		if r.isBotUserID(m[1]) {
 			return true
 		}
 	}
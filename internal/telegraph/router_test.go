@@ -32,6 +32,7 @@ func openRouterTestDB(t *testing.T) *gorm.DB {
 		&models.DispatchSession{},
 		&models.TelegraphConversation{},
 		&models.AgentLog{},
+		&models.Attachment{},
 	); err != nil {
 		t.Fatalf("auto migrate: %v", err)
 	}
@@ -684,6 +685,7 @@ func TestIsCommand(t *testing.T) {
 		{"!ry car list", true},
 		{"!ry help", true},
 		{"!ry engine list", true},
+		{"!ry notify me on car-1", true},
 		{"!ryExtra", false},
 		{"ry status", false},
 		{"hello !ry", false},
@@ -0,0 +1,230 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Capability status values reported by SelfTest and PermissionChecker.
+const (
+	CapabilityOK      = "ok"
+	CapabilityMissing = "missing"
+	CapabilityUnknown = "unknown"
+)
+
+// Capability names checked by SelfTest. Adapters implementing
+// PermissionChecker should report against these names so the matrix lines up
+// across platforms.
+const (
+	CapConnect      = "connect"
+	CapIdentity     = "identity"
+	CapSendMessage  = "send_message"
+	CapCreateThread = "create_thread"
+	CapReadHistory  = "read_history"
+	CapUploadFiles  = "upload_files"
+)
+
+const (
+	selfTestText         = "Railyard telegraph test: configuration check. You can ignore or delete this message."
+	selfTestReply        = "Railyard telegraph test: thread reply."
+	selfTestThread       = "railyard-telegraph-test"
+	selfTestTimeout      = 30 * time.Second
+	selfTestReadyTimeout = 10 * time.Second
+)
+
+// Capability is one row in the self-test capability matrix.
+type Capability struct {
+	Name   string // one of the Cap* constants
+	Status string // CapabilityOK, CapabilityMissing, or CapabilityUnknown
+	Detail string // human-readable explanation (error text, missing scope)
+}
+
+// MessagePoster is an optional interface that adapters can implement to post
+// a top-level message and learn its platform message ID. SelfTest uses it to
+// start a thread from its own test message.
+type MessagePoster interface {
+	Post(ctx context.Context, msg OutboundMessage) (messageID string, err error)
+}
+
+// PermissionChecker is an optional interface that adapters can implement to
+// report granted bot permissions (scopes, channel permission bits) without
+// exercising them. SelfTest uses these only to fill in capabilities its own
+// probes could not decide (e.g. file uploads, which it never attempts).
+type PermissionChecker interface {
+	CheckPermissions(ctx context.Context, channelID string) ([]Capability, error)
+}
+
+// SelfTestOpts configures a SelfTest run.
+type SelfTestOpts struct {
+	Adapter   Adapter
+	Platform  string
+	ChannelID string
+	Timeout   time.Duration // overall deadline; default 30s
+}
+
+// SelfTestReport is the outcome of a SelfTest run.
+type SelfTestReport struct {
+	Platform     string
+	ChannelID    string
+	BotUserID    string
+	Capabilities []Capability
+}
+
+// OK reports whether every capability in the report is CapabilityOK.
+// Unknown capabilities count as failures so misconfiguration is never
+// silently reported as healthy.
+func (r *SelfTestReport) OK() bool {
+	for _, c := range r.Capabilities {
+		if c.Status != CapabilityOK {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the capability with the given name.
+func (r *SelfTestReport) Get(name string) (Capability, bool) {
+	for _, c := range r.Capabilities {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capability{}, false
+}
+
+func (r *SelfTestReport) set(name, status, detail string) {
+	for i := range r.Capabilities {
+		if r.Capabilities[i].Name == name {
+			r.Capabilities[i].Status = status
+			r.Capabilities[i].Detail = detail
+			return
+		}
+	}
+	r.Capabilities = append(r.Capabilities, Capability{Name: name, Status: status, Detail: detail})
+}
+
+// SelfTest connects with the adapter, posts a test message and thread reply,
+// reads the thread back, and merges in any permissions the adapter reports.
+// It never returns an error for a failed check — failures are rows in the
+// report — so callers can always print the full matrix.
+func SelfTest(ctx context.Context, opts SelfTestOpts) *SelfTestReport {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = selfTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := &SelfTestReport{Platform: opts.Platform, ChannelID: opts.ChannelID}
+	for _, name := range []string{CapConnect, CapIdentity, CapSendMessage, CapCreateThread, CapReadHistory, CapUploadFiles} {
+		report.set(name, CapabilityUnknown, "not checked")
+	}
+
+	a := opts.Adapter
+	if err := a.Connect(ctx); err != nil {
+		report.set(CapConnect, CapabilityMissing, err.Error())
+		return report
+	}
+	defer a.Close()
+	report.set(CapConnect, CapabilityOK, "")
+
+	if rw, ok := a.(ReadyWaiter); ok {
+		readyCtx, readyCancel := context.WithTimeout(ctx, selfTestReadyTimeout)
+		_ = rw.WaitReady(readyCtx)
+		readyCancel()
+	}
+	if bu, ok := a.(BotUserIDer); ok {
+		report.BotUserID = bu.BotUserID()
+	}
+	if report.BotUserID != "" {
+		report.set(CapIdentity, CapabilityOK, report.BotUserID)
+	} else {
+		report.set(CapIdentity, CapabilityMissing, "bot user ID not available; self-message filtering and @mentions will not work")
+	}
+
+	if opts.ChannelID == "" {
+		report.set(CapSendMessage, CapabilityMissing, "no channel configured (set telegraph.channel)")
+		return report
+	}
+
+	msg := OutboundMessage{ChannelID: opts.ChannelID, Text: selfTestText}
+	poster, canPost := a.(MessagePoster)
+	var messageID string
+	if canPost {
+		id, err := poster.Post(ctx, msg)
+		if err != nil {
+			report.set(CapSendMessage, CapabilityMissing, err.Error())
+		} else {
+			messageID = id
+			report.set(CapSendMessage, CapabilityOK, "")
+		}
+	} else if err := a.Send(ctx, msg); err != nil {
+		report.set(CapSendMessage, CapabilityMissing, err.Error())
+	} else {
+		report.set(CapSendMessage, CapabilityOK, "")
+	}
+
+	starter, canThread := a.(ThreadStarter)
+	var threadID string
+	switch {
+	case !canThread:
+		report.set(CapCreateThread, CapabilityMissing, "adapter does not support threads")
+	case messageID == "":
+		report.set(CapCreateThread, CapabilityUnknown, "test message ID unavailable; thread not attempted")
+	default:
+		id, err := starter.StartThread(ctx, opts.ChannelID, messageID, selfTestReply, selfTestThread)
+		if err != nil {
+			report.set(CapCreateThread, CapabilityMissing, err.Error())
+		} else {
+			threadID = id
+			report.set(CapCreateThread, CapabilityOK, "")
+		}
+	}
+
+	if threadID != "" {
+		msgs, err := a.ThreadHistory(ctx, opts.ChannelID, threadID, 5)
+		switch {
+		case err != nil:
+			report.set(CapReadHistory, CapabilityMissing, err.Error())
+		case len(msgs) == 0:
+			report.set(CapReadHistory, CapabilityMissing, "thread history returned no messages")
+		default:
+			report.set(CapReadHistory, CapabilityOK, "")
+		}
+	}
+
+	if pc, ok := a.(PermissionChecker); ok {
+		caps, err := pc.CheckPermissions(ctx, opts.ChannelID)
+		if err != nil {
+			for _, name := range []string{CapReadHistory, CapUploadFiles} {
+				if c, _ := report.Get(name); c.Status == CapabilityUnknown {
+					report.set(name, CapabilityUnknown, fmt.Sprintf("permission check failed: %v", err))
+				}
+			}
+		}
+		for _, c := range caps {
+			// Empirical results win; reported permissions only fill gaps.
+			if cur, ok := report.Get(c.Name); ok && cur.Status != CapabilityUnknown {
+				continue
+			}
+			report.set(c.Name, c.Status, c.Detail)
+		}
+	}
+
+	return report
+}
+
+// WriteSelfTestReport renders the capability matrix as a fixed-width table.
+func WriteSelfTestReport(w io.Writer, r *SelfTestReport) {
+	fmt.Fprintf(w, "Telegraph test: platform=%s channel=%s", r.Platform, r.ChannelID)
+	if r.BotUserID != "" {
+		fmt.Fprintf(w, " bot=%s", r.BotUserID)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-16s %-8s %s\n", "CAPABILITY", "STATUS", "DETAIL")
+	for _, c := range r.Capabilities {
+		fmt.Fprintf(w, "%-16s %-8s %s\n", c.Name, c.Status, c.Detail)
+	}
+}
@@ -0,0 +1,155 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var _ MessagePoster = (*MockAdapter)(nil)
+
+// permAdapter wraps MockAdapter with a canned PermissionChecker result.
+type permAdapter struct {
+	*MockAdapter
+	caps []Capability
+	err  error
+}
+
+func (p *permAdapter) CheckPermissions(ctx context.Context, channelID string) ([]Capability, error) {
+	return p.caps, p.err
+}
+
+func statusOf(t *testing.T, r *SelfTestReport, name string) string {
+	t.Helper()
+	c, ok := r.Get(name)
+	if !ok {
+		t.Fatalf("capability %q missing from report", name)
+	}
+	return c.Status
+}
+
+func TestSelfTest_AllProbesPass(t *testing.T) {
+	m := NewMockAdapter()
+	m.SetBotUserID("BOT1")
+	m.SetThreadHistory("C1", "thread-1", []ThreadMessage{{Text: "reply"}})
+
+	r := SelfTest(context.Background(), SelfTestOpts{Adapter: m, Platform: "mock", ChannelID: "C1"})
+
+	for _, name := range []string{CapConnect, CapIdentity, CapSendMessage, CapCreateThread, CapReadHistory} {
+		if got := statusOf(t, r, name); got != CapabilityOK {
+			t.Errorf("%s = %q, want ok", name, got)
+		}
+	}
+	// Uploads are never attempted and MockAdapter reports no permissions.
+	if got := statusOf(t, r, CapUploadFiles); got != CapabilityUnknown {
+		t.Errorf("upload_files = %q, want unknown", got)
+	}
+	if r.OK() {
+		t.Error("report with unknown capability should not be OK")
+	}
+	if r.BotUserID != "BOT1" {
+		t.Errorf("BotUserID = %q, want BOT1", r.BotUserID)
+	}
+	// Test message + thread reply.
+	if m.SentCount() != 2 {
+		t.Errorf("sent %d messages, want 2", m.SentCount())
+	}
+}
+
+func TestSelfTest_PermissionsFillGaps(t *testing.T) {
+	m := NewMockAdapter()
+	m.SetBotUserID("BOT1")
+	m.SetThreadHistory("C1", "thread-1", []ThreadMessage{{Text: "reply"}})
+	a := &permAdapter{MockAdapter: m, caps: []Capability{
+		{Name: CapReadHistory, Status: CapabilityMissing, Detail: "scope"},
+		{Name: CapUploadFiles, Status: CapabilityOK},
+	}}
+
+	r := SelfTest(context.Background(), SelfTestOpts{Adapter: a, Platform: "mock", ChannelID: "C1"})
+
+	// Empirical read succeeded, so the reported permission does not override it.
+	if got := statusOf(t, r, CapReadHistory); got != CapabilityOK {
+		t.Errorf("read_history = %q, want ok", got)
+	}
+	if got := statusOf(t, r, CapUploadFiles); got != CapabilityOK {
+		t.Errorf("upload_files = %q, want ok", got)
+	}
+	if !r.OK() {
+		t.Errorf("expected OK report, got %+v", r.Capabilities)
+	}
+}
+
+func TestSelfTest_PermissionCheckError(t *testing.T) {
+	m := NewMockAdapter()
+	m.SetBotUserID("BOT1")
+	a := &permAdapter{MockAdapter: m, err: fmt.Errorf("boom")}
+
+	r := SelfTest(context.Background(), SelfTestOpts{Adapter: a, Platform: "mock", ChannelID: "C1"})
+
+	c, _ := r.Get(CapUploadFiles)
+	if c.Status != CapabilityUnknown || !strings.Contains(c.Detail, "boom") {
+		t.Errorf("upload_files = %+v, want unknown with error detail", c)
+	}
+}
+
+func TestSelfTest_ConnectFailure(t *testing.T) {
+	m := NewMockAdapter()
+	m.Close() // Connect after Close fails
+
+	r := SelfTest(context.Background(), SelfTestOpts{Adapter: m, Platform: "mock", ChannelID: "C1"})
+
+	if got := statusOf(t, r, CapConnect); got != CapabilityMissing {
+		t.Errorf("connect = %q, want missing", got)
+	}
+	if got := statusOf(t, r, CapSendMessage); got != CapabilityUnknown {
+		t.Errorf("send_message = %q, want unknown after failed connect", got)
+	}
+}
+
+func TestSelfTest_NoChannel(t *testing.T) {
+	m := NewMockAdapter()
+	m.SetBotUserID("BOT1")
+
+	r := SelfTest(context.Background(), SelfTestOpts{Adapter: m, Platform: "mock"})
+
+	c, _ := r.Get(CapSendMessage)
+	if c.Status != CapabilityMissing || !strings.Contains(c.Detail, "telegraph.channel") {
+		t.Errorf("send_message = %+v, want missing with config hint", c)
+	}
+	if m.SentCount() != 0 {
+		t.Errorf("sent %d messages with no channel, want 0", m.SentCount())
+	}
+}
+
+func TestSelfTest_MissingIdentity(t *testing.T) {
+	m := NewMockAdapter()
+
+	r := SelfTest(context.Background(), SelfTestOpts{Adapter: m, Platform: "mock", ChannelID: "C1"})
+
+	if got := statusOf(t, r, CapIdentity); got != CapabilityMissing {
+		t.Errorf("identity = %q, want missing", got)
+	}
+}
+
+func TestWriteSelfTestReport(t *testing.T) {
+	r := &SelfTestReport{
+		Platform:  "slack",
+		ChannelID: "C1",
+		BotUserID: "U1",
+		Capabilities: []Capability{
+			{Name: CapConnect, Status: CapabilityOK},
+			{Name: CapUploadFiles, Status: CapabilityMissing, Detail: "missing scope files:write"},
+		},
+	}
+	var buf bytes.Buffer
+	WriteSelfTestReport(&buf, r)
+	out := buf.String()
+
+	for _, want := range []string{"platform=slack", "bot=U1", "CAPABILITY", "upload_files", "missing scope files:write"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
 )
@@ -40,6 +41,10 @@ type Process interface {
 	Close() error
 }
 
+// ErrNoSession is wrapped by the errors SessionManager returns for a thread
+// that has no active dispatch session.
+var ErrNoSession = errors.New("no active session")
+
 // SessionManager manages dispatch sessions for Telegraph. It tracks active
 // sessions by thread/channel, spawns subprocesses, routes messages, and
 // resumes dead sessions from conversation history.
@@ -51,9 +56,18 @@ type SessionManager struct {
 	processTimeout     time.Duration
 	relayFlushInterval time.Duration
 	redact             func(string) string // strips secrets before agent_logs storage
+	history            *HistoryStore       // reads conversation history, including archived turns
+	threadCache        *ThreadHistoryCache // reads chat thread history on resume; nil reads the adapter directly
+	summarizeAfter     int                 // turns kept verbatim on resume; older ones are summarized; 0 = never
+	summarizer         Summarizer
+	clock              clock.Clock
 
 	mu       sync.RWMutex
 	sessions map[string]*activeSession // key: "channelID:threadID"
+
+	// inflight counts relay and monitor goroutines, so Drain can wait for
+	// every in-flight turn to be relayed and its lock released.
+	inflight sync.WaitGroup
 }
 
 // activeSession pairs a DB session with a running process.
@@ -74,7 +88,15 @@ type SessionManagerOpts struct {
 	// Redact strips secrets from subprocess I/O before it is written to
 	// agent_logs. Defaults to a no-op. Wired to engine.RedactSecrets in the
 	// cmd layer (telegraph stays decoupled from internal/engine).
-	Redact func(string) string
+	Redact      func(string) string
+	History     *HistoryStore       // reads history on resume; defaults to the database only
+	ThreadCache *ThreadHistoryCache // caches chat thread history read on resume; nil reads the adapter each time
+	// SummarizeAfterTurns keeps the latest N turns of a resumed conversation
+	// verbatim and passes the older ones through Summarizer. 0 never
+	// summarizes.
+	SummarizeAfterTurns int
+	Summarizer          Summarizer  // defaults to TruncateSummarizer
+	Clock               clock.Clock // defaults to clock.Real
 }
 
 // NewSessionManager creates a SessionManager.
@@ -101,6 +123,14 @@ func NewSessionManager(opts SessionManagerOpts) (*SessionManager, error) {
 	if redact == nil {
 		redact = func(s string) string { return s }
 	}
+	history := opts.History
+	if history == nil {
+		history = dbHistoryStore(opts.DB)
+	}
+	summarizer := opts.Summarizer
+	if summarizer == nil {
+		summarizer = TruncateSummarizer{}
+	}
 	return &SessionManager{
 		db:                 opts.DB,
 		adapter:            opts.Adapter,
@@ -109,6 +139,11 @@ func NewSessionManager(opts SessionManagerOpts) (*SessionManager, error) {
 		processTimeout:     procTimeout,
 		relayFlushInterval: flushInterval,
 		redact:             redact,
+		history:            history,
+		threadCache:        opts.ThreadCache,
+		summarizeAfter:     opts.SummarizeAfterTurns,
+		summarizer:         summarizer,
+		clock:              clock.OrReal(opts.Clock),
 		sessions:           make(map[string]*activeSession),
 	}, nil
 }
@@ -121,7 +156,7 @@ func sessionKey(channelID, threadID string) string {
 // NewSession acquires the dispatch lock and spawns a new subprocess.
 // Returns the DispatchSession on success.
 func (sm *SessionManager) NewSession(ctx context.Context, source, userName, threadID, channelID string) (*models.DispatchSession, error) {
-	dbSession, err := AcquireLock(sm.db, source, userName, threadID, channelID, sm.timeout)
+	dbSession, err := acquireLock(sm.db, sm.clock, source, userName, threadID, channelID, sm.timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +165,7 @@ func (sm *SessionManager) NewSession(ctx context.Context, source, userName, thre
 	proc, err := sm.spawner.Spawn(procCtx, "")
 	if err != nil {
 		cancel()
-		ReleaseLock(sm.db, dbSession.ID)
+		releaseLock(sm.db, sm.clock, dbSession.ID)
 		return nil, fmt.Errorf("telegraph: spawn dispatch: %w", err)
 	}
 
@@ -143,14 +178,16 @@ func (sm *SessionManager) NewSession(ctx context.Context, source, userName, thre
 	}
 	sm.mu.Unlock()
 
+	sm.recordProcess(dbSession.ID, proc)
+
 	log.Printf("telegraph: session %d spawned [ch=%s thread=%s user=%s]",
 		dbSession.ID, channelID, threadID, userName)
 
 	// Relay subprocess output back to the chat platform.
-	go sm.relayOutput(ctx, channelID, threadID, dbSession.ID, proc)
+	sm.inflight.Go(func() { sm.relayOutput(ctx, channelID, threadID, dbSession.ID, proc) })
 
 	// Monitor process exit and clean up.
-	go sm.monitorProcess(key, dbSession.ID, proc)
+	sm.inflight.Go(func() { sm.monitorProcess(key, dbSession.ID, proc) })
 
 	return dbSession, nil
 }
@@ -164,23 +201,18 @@ func (sm *SessionManager) Route(ctx context.Context, channelID, threadID, userNa
 	sm.mu.RUnlock()
 
 	if !ok {
-		return fmt.Errorf("telegraph: no active session for %s", key)
+		return fmt.Errorf("telegraph: %w for %s", ErrNoSession, key)
 	}
 
 	// Record conversation in DB.
-	var maxSeq int
-	sm.db.Model(&models.TelegraphConversation{}).
-		Where("session_id = ?", as.dbSession.ID).
-		Select("COALESCE(MAX(sequence), 0)").Scan(&maxSeq)
-
-	conv := models.TelegraphConversation{
+	if err := appendConversation(sm.db, &models.TelegraphConversation{
 		SessionID: as.dbSession.ID,
-		Sequence:  maxSeq + 1,
 		Role:      "user",
 		UserName:  userName,
 		Content:   text,
+	}, 0); err != nil {
+		log.Printf("telegraph: session %d: record user message: %v", as.dbSession.ID, err)
 	}
-	sm.db.Create(&conv)
 
 	// Send to subprocess.
 	if err := as.process.Send(text); err != nil {
@@ -188,7 +220,7 @@ func (sm *SessionManager) Route(ctx context.Context, channelID, threadID, userNa
 	}
 
 	// Refresh heartbeat.
-	Heartbeat(sm.db, as.dbSession.ID)
+	heartbeat(sm.db, sm.clock, as.dbSession.ID)
 
 	return nil
 }
@@ -198,8 +230,8 @@ func (sm *SessionManager) Route(ctx context.Context, channelID, threadID, userNa
 // the resume — it is appended to the recovery context and included in the
 // one-shot prompt so the subprocess can respond to it immediately.
 func (sm *SessionManager) Resume(ctx context.Context, channelID, threadID, userName, newMessage string) (*models.DispatchSession, error) {
-	// Build recovery context from database conversation history.
-	recoveryPrompt, err := sm.buildRecoveryContext(channelID, threadID)
+	// Build recovery context from stored conversation history.
+	recoveryPrompt, err := sm.buildRecoveryContext(ctx, channelID, threadID)
 	if err != nil {
 		return nil, fmt.Errorf("telegraph: build recovery context: %w", err)
 	}
@@ -216,7 +248,7 @@ func (sm *SessionManager) Resume(ctx context.Context, channelID, threadID, userN
 	// (We do this before spawning so it's included if a subsequent resume occurs.)
 
 	// Acquire a new lock for the resumed session.
-	dbSession, err := AcquireLock(sm.db, "telegraph", userName, threadID, channelID, sm.timeout)
+	dbSession, err := acquireLock(sm.db, sm.clock, "telegraph", userName, threadID, channelID, sm.timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +257,7 @@ func (sm *SessionManager) Resume(ctx context.Context, channelID, threadID, userN
 	proc, err := sm.spawner.Spawn(procCtx, recoveryPrompt)
 	if err != nil {
 		cancel()
-		ReleaseLock(sm.db, dbSession.ID)
+		releaseLock(sm.db, sm.clock, dbSession.ID)
 		return nil, fmt.Errorf("telegraph: spawn resumed dispatch: %w", err)
 	}
 
@@ -238,29 +270,27 @@ func (sm *SessionManager) Resume(ctx context.Context, channelID, threadID, userN
 	}
 	sm.mu.Unlock()
 
+	sm.recordProcess(dbSession.ID, proc)
+
 	log.Printf("telegraph: session %d resumed [ch=%s thread=%s user=%s] recovery_len=%d",
 		dbSession.ID, channelID, threadID, userName, len(recoveryPrompt))
 
 	// Record the new message in conversation history for future resumes.
 	if newMessage != "" {
-		var maxSeq int
-		sm.db.Model(&models.TelegraphConversation{}).
-			Where("session_id = ?", dbSession.ID).
-			Select("COALESCE(MAX(sequence), 0)").Scan(&maxSeq)
-
-		sm.db.Create(&models.TelegraphConversation{
+		if err := appendConversation(sm.db, &models.TelegraphConversation{
 			SessionID: dbSession.ID,
-			Sequence:  maxSeq + 1,
 			Role:      "user",
 			UserName:  userName,
 			Content:   newMessage,
-		})
+		}, 0); err != nil {
+			log.Printf("telegraph: session %d: record user message: %v", dbSession.ID, err)
+		}
 	}
 
 	// Relay subprocess output back to the chat platform.
-	go sm.relayOutput(ctx, channelID, threadID, dbSession.ID, proc)
+	sm.inflight.Go(func() { sm.relayOutput(ctx, channelID, threadID, dbSession.ID, proc) })
 
-	go sm.monitorProcess(key, dbSession.ID, proc)
+	sm.inflight.Go(func() { sm.monitorProcess(key, dbSession.ID, proc) })
 
 	return dbSession, nil
 }
@@ -280,7 +310,7 @@ func (sm *SessionManager) HasSession(channelID, threadID string) bool {
 // heartbeat, meaning the process exited without ReleaseLock succeeding).
 func (sm *SessionManager) HasHistoricSession(channelID, threadID string) bool {
 	var count int64
-	cutoff := time.Now().Add(-sm.timeout)
+	cutoff := sm.clock.Now().Add(-sm.timeout)
 	sm.db.Model(&models.DispatchSession{}).
 		Where("platform_thread_id = ? AND channel_id = ? AND (status IN ? OR (status = ? AND last_heartbeat < ?))",
 			threadID, channelID, []string{"completed", "expired"}, "active", cutoff).
@@ -336,7 +366,7 @@ func (sm *SessionManager) CloseSession(channelID, threadID string) error {
 	as, ok := sm.sessions[key]
 	if !ok {
 		sm.mu.Unlock()
-		return fmt.Errorf("telegraph: no active session for %s", key)
+		return fmt.Errorf("telegraph: %w for %s", ErrNoSession, key)
 	}
 	delete(sm.sessions, key)
 	sm.mu.Unlock()
@@ -347,12 +377,65 @@ func (sm *SessionManager) CloseSession(channelID, threadID string) error {
 	// The process-exit cleanup goroutine (monitorProcess) may release the lock
 	// first when Close above makes the process exit. An already-released session
 	// means the close succeeded, so don't surface that as an error.
-	if err := ReleaseLock(sm.db, as.dbSession.ID); err != nil && !errors.Is(err, ErrSessionNotActive) {
+	if err := releaseLock(sm.db, sm.clock, as.dbSession.ID); err != nil && !errors.Is(err, ErrSessionNotActive) {
 		return err
 	}
 	return nil
 }
 
+// ActiveCount returns the number of sessions with a running process.
+func (sm *SessionManager) ActiveCount() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return len(sm.sessions)
+}
+
+// Drain waits for every in-flight dispatch turn to finish relaying its
+// output and release its lock. It returns ctx's error if ctx ends first.
+// Callers must stop starting new sessions before draining.
+func (sm *SessionManager) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		sm.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops every session still running (after Drain timed out),
+// releases its dispatch lock, and waits up to wait for the relays to post
+// what the processes printed before they were stopped. It returns the
+// number of sessions stopped.
+func (sm *SessionManager) Shutdown(wait time.Duration) int {
+	sm.mu.Lock()
+	active := make([]*activeSession, 0, len(sm.sessions))
+	for key, as := range sm.sessions {
+		active = append(active, as)
+		delete(sm.sessions, key)
+	}
+	sm.mu.Unlock()
+
+	for _, as := range active {
+		as.process.Close()
+		as.cancel()
+		if err := releaseLock(sm.db, sm.clock, as.dbSession.ID); err != nil && !errors.Is(err, ErrSessionNotActive) {
+			log.Printf("telegraph: session %d: release lock on shutdown: %v", as.dbSession.ID, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	if err := sm.Drain(ctx); err != nil {
+		log.Printf("telegraph: shutdown: relays still running after %v", wait)
+	}
+	return len(active)
+}
+
 // monitorProcess watches for process exit and cleans up the session.
 func (sm *SessionManager) monitorProcess(key string, sessionID uint, proc Process) {
 	<-proc.Done()
@@ -363,35 +446,37 @@ func (sm *SessionManager) monitorProcess(key string, sessionID uint, proc Proces
 	delete(sm.sessions, key)
 	sm.mu.Unlock()
 
-	if err := ReleaseLock(sm.db, sessionID); err != nil {
+	sm.clearProcess(sessionID)
+	if err := releaseLock(sm.db, sm.clock, sessionID); err != nil {
 		log.Printf("telegraph: session %d: release lock failed: %v", sessionID, err)
 	}
 }
 
 // buildRecoveryContext constructs a recovery prompt from conversation history.
-// Primary source: database TelegraphConversation rows. Fallback: adapter.ThreadHistory().
-func (sm *SessionManager) buildRecoveryContext(channelID, threadID string) (string, error) {
-	// Try database conversation history first.
-	var convos []models.TelegraphConversation
-	result := sm.db.Where("session_id IN (?)",
-		sm.db.Model(&models.DispatchSession{}).
-			Select("id").
-			Where("platform_thread_id = ? AND channel_id = ?", threadID, channelID),
-	).Order("session_id, sequence").Find(&convos)
-
-	if result.Error != nil {
-		return "", fmt.Errorf("query conversations: %w", result.Error)
+// Primary source: stored conversation history (database rows plus archived
+// turns). Fallback: the chat thread, through the thread history cache when
+// one is configured.
+func (sm *SessionManager) buildRecoveryContext(ctx context.Context, channelID, threadID string) (string, error) {
+	// Try stored conversation history first.
+	convos, err := sm.history.ThreadHistory(ctx, channelID, threadID, time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("query conversations: %w", err)
 	}
 
 	if len(convos) > 0 {
-		return formatConversationHistory(convos), nil
+		return sm.formatConversation(ctx, convos), nil
 	}
 
 	// Fallback: adapter thread history.
 	if sm.adapter != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
-		msgs, err := sm.adapter.ThreadHistory(ctx, channelID, threadID, 50)
+		var msgs []ThreadMessage
+		if sm.threadCache != nil {
+			msgs, err = sm.threadCache.ThreadHistory(ctx, channelID, threadID, 50)
+		} else {
+			msgs, err = sm.adapter.ThreadHistory(ctx, channelID, threadID, 50)
+		}
 		if err == nil && len(msgs) > 0 {
 			return formatThreadHistory(msgs), nil
 		}
@@ -400,14 +485,40 @@ func (sm *SessionManager) buildRecoveryContext(channelID, threadID string) (stri
 	return "", nil
 }
 
+// formatConversation builds the recovery prompt from conversation history.
+// Past summarizeAfter turns, the older turns are replaced by a summary so a
+// long thread does not overflow the agent's context.
+func (sm *SessionManager) formatConversation(ctx context.Context, convos []models.TelegraphConversation) string {
+	if sm.summarizeAfter <= 0 || len(convos) <= sm.summarizeAfter {
+		return formatConversationHistory(convos)
+	}
+	older, recent := convos[:len(convos)-sm.summarizeAfter], convos[len(convos)-sm.summarizeAfter:]
+	summary, err := sm.summarizer.Summarize(ctx, older)
+	if err != nil {
+		log.Printf("telegraph: summarize %d turns: %v; truncating instead", len(older), err)
+		summary, _ = TruncateSummarizer{}.Summarize(ctx, older)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary of the earlier conversation (%d turns):\n\n%s\n\n", len(older), strings.TrimSpace(summary))
+	b.WriteString("Most recent turns:\n\n")
+	writeConversationTurns(&b, recent)
+	return b.String()
+}
+
 // formatConversationHistory builds a prompt from database conversation rows.
 func formatConversationHistory(convos []models.TelegraphConversation) string {
 	var b strings.Builder
 	b.WriteString("Previous conversation context:\n\n")
+	writeConversationTurns(&b, convos)
+	return b.String()
+}
+
+// writeConversationTurns writes one "[role] user: content" line per turn.
+func writeConversationTurns(b *strings.Builder, convos []models.TelegraphConversation) {
 	for _, c := range convos {
-		fmt.Fprintf(&b, "[%s] %s: %s\n", c.Role, c.UserName, c.Content)
+		fmt.Fprintf(b, "[%s] %s: %s\n", c.Role, c.UserName, c.Content)
 	}
-	return b.String()
 }
 
 // formatThreadHistory builds a prompt from adapter thread messages.
@@ -420,6 +531,10 @@ func formatThreadHistory(msgs []ThreadMessage) string {
 	return b.String()
 }
 
+// relayMessageLimit is the largest message the relay posts; Discord's
+// content limit, and comfortably under Slack's.
+const relayMessageLimit = 2000
+
 // defaultRelayFlushInterval is how often accumulated output is flushed to
 // the chat platform.
 const defaultRelayFlushInterval = 3 * time.Second
@@ -427,14 +542,38 @@ const defaultRelayFlushInterval = 3 * time.Second
 // relayOutput reads lines from a process's Recv channel and forwards them
 // to the chat platform incrementally. Lines are accumulated for up to
 // relayFlushInterval before being flushed, so users see progress without
-// spamming the channel. The full response is recorded in conversation
-// history after the process finishes.
+// spamming the channel. When the adapter can edit its own messages, each
+// flush extends the last relayed message in place until it reaches the
+// platform limit; the flush interval doubles as the edit rate limit. The full
+// response is recorded in conversation history after the process finishes.
 func (sm *SessionManager) relayOutput(ctx context.Context, channelID, threadID string, sessionID uint, proc Process) {
 	var fullBuf strings.Builder // complete response for DB persistence
 	var pending strings.Builder // lines waiting to be flushed to chat
 	fullLines := 0              // lines written to fullBuf
 	pendingLines := 0           // lines written to pending since last flush
 
+	poster, canPost := sm.adapter.(MessagePoster)
+	editor, canEdit := sm.adapter.(MessageEditor)
+	streaming := canPost && canEdit
+	var liveID, liveText string // message being extended in streaming mode
+
+	send := func(chunk string) {
+		msg := OutboundMessage{ChannelID: channelID, ThreadID: threadID, Text: chunk}
+		if !streaming {
+			if err := sm.adapter.Send(ctx, msg); err != nil {
+				log.Printf("telegraph: relay session %d: send error: %v", sessionID, err)
+			}
+			return
+		}
+		id, err := poster.Post(ctx, msg)
+		if err != nil {
+			log.Printf("telegraph: relay session %d: send error: %v", sessionID, err)
+			liveID, liveText = "", ""
+			return
+		}
+		liveID, liveText = id, chunk
+	}
+
 	flush := func() {
 		defer func() {
 			pending.Reset()
@@ -450,21 +589,25 @@ func (sm *SessionManager) relayOutput(ctx context.Context, channelID, threadID s
 		if strings.TrimSpace(text) == "" {
 			return
 		}
-		for _, chunk := range chunkMessage(text, 2000) {
+		if streaming && liveID != "" {
+			if grown := liveText + "\n" + text; len(grown) <= relayMessageLimit {
+				err := editor.EditMessage(ctx, channelID, threadID, liveID, grown)
+				if err == nil {
+					liveText = grown
+					return
+				}
+				log.Printf("telegraph: relay session %d: edit error: %v (posting instead)", sessionID, err)
+			}
+		}
+		for _, chunk := range chunkMessage(text, relayMessageLimit) {
 			if strings.TrimSpace(chunk) == "" {
 				continue
 			}
-			if err := sm.adapter.Send(ctx, OutboundMessage{
-				ChannelID: channelID,
-				ThreadID:  threadID,
-				Text:      chunk,
-			}); err != nil {
-				log.Printf("telegraph: relay session %d: send error: %v", sessionID, err)
-			}
+			send(chunk)
 		}
 	}
 
-	ticker := time.NewTicker(sm.relayFlushInterval)
+	ticker := sm.clock.NewTicker(sm.relayFlushInterval)
 	defer ticker.Stop()
 
 	recv := proc.Recv()
@@ -485,7 +628,7 @@ func (sm *SessionManager) relayOutput(ctx context.Context, channelID, threadID s
 			}
 			pending.WriteString(line)
 			pendingLines++
-		case <-ticker.C:
+		case <-ticker.C():
 			flush()
 		case <-ctx.Done():
 			recv = nil
@@ -501,16 +644,13 @@ func (sm *SessionManager) relayOutput(ctx context.Context, channelID, threadID s
 	// needed for session resumption and does not depend on the exit status.
 	if text != "" {
 		log.Printf("telegraph: relay session %d: %d chars output", sessionID, len(text))
-		var maxSeq int
-		sm.db.Model(&models.TelegraphConversation{}).
-			Where("session_id = ?", sessionID).
-			Select("COALESCE(MAX(sequence), 0)").Scan(&maxSeq)
-		sm.db.Create(&models.TelegraphConversation{
+		if err := appendConversation(sm.db, &models.TelegraphConversation{
 			SessionID: sessionID,
-			Sequence:  maxSeq + 1,
 			Role:      "assistant",
 			Content:   text,
-		})
+		}, 0); err != nil {
+			log.Printf("telegraph: relay session %d: record assistant message: %v", sessionID, err)
+		}
 	}
 
 	// The scanner closes recv before cmd.Wait() returns, so wait (bounded) for
@@ -552,7 +692,7 @@ func (sm *SessionManager) persistAgentIO(sessionID uint, stdout, stderr string,
 			SessionID: sid,
 			Direction: "out",
 			Content:   sm.redact(stdout),
-			CreatedAt: time.Now(),
+			CreatedAt: sm.clock.Now(),
 		})
 	}
 
@@ -573,7 +713,7 @@ func (sm *SessionManager) persistAgentIO(sessionID uint, stdout, stderr string,
 		SessionID: sid,
 		Direction: "err",
 		Content:   sm.redact(content),
-		CreatedAt: time.Now(),
+		CreatedAt: sm.clock.Now(),
 	})
 }
 
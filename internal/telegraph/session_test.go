@@ -2,6 +2,7 @@ package telegraph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -330,6 +331,9 @@ func TestRoute_NoActiveSession(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for no active session")
 	}
+	if !errors.Is(err, ErrNoSession) {
+		t.Errorf("errors.Is(%v, ErrNoSession) = false", err)
+	}
 	if !strings.Contains(err.Error(), "no active session") {
 		t.Errorf("error = %q, want to contain %q", err.Error(), "no active session")
 	}
@@ -662,6 +666,67 @@ func TestCloseSession_ToleratesAlreadyReleasedLock(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Drain / Shutdown tests
+// ---------------------------------------------------------------------------
+
+func TestDrain_WaitsForInFlightTurn(t *testing.T) {
+	db := openSessionTestDB(t)
+	adapter := NewMockAdapter()
+	adapter.Connect(context.Background())
+	spawner := &mockSpawner{}
+	sm, _ := NewSessionManager(SessionManagerOpts{DB: db, Adapter: adapter, Spawner: spawner})
+	session, err := sm.NewSession(context.Background(), "telegraph", "alice", "thread-1", "C01")
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sm.Drain(ctx); err == nil {
+		t.Fatal("Drain returned before the turn finished")
+	}
+
+	p := spawner.lastProcess()
+	p.recvCh <- "Created car-001"
+	close(p.recvCh)
+	p.exitWith(nil)
+	if err := sm.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	if msg, _ := adapter.LastSent(); msg.Text != "Created car-001" {
+		t.Errorf("last sent = %q, want the relayed output", msg.Text)
+	}
+	var s models.DispatchSession
+	db.First(&s, session.ID)
+	if s.Status != "completed" {
+		t.Errorf("status = %q, want completed", s.Status)
+	}
+	if n := sm.ActiveCount(); n != 0 {
+		t.Errorf("ActiveCount = %d, want 0", n)
+	}
+}
+
+func TestShutdown_StopsRunningSessions(t *testing.T) {
+	db := openSessionTestDB(t)
+	spawner := &mockSpawner{}
+	sm, _ := NewSessionManager(SessionManagerOpts{DB: db, Spawner: spawner})
+	session, _ := sm.NewSession(context.Background(), "telegraph", "alice", "thread-1", "C01")
+
+	if n := sm.Shutdown(100 * time.Millisecond); n != 1 {
+		t.Errorf("Shutdown = %d, want 1", n)
+	}
+	if sm.HasSession("C01", "thread-1") {
+		t.Error("session should be removed after shutdown")
+	}
+	var s models.DispatchSession
+	db.First(&s, session.ID)
+	if s.Status != "completed" {
+		t.Errorf("status = %q, want completed (lock released)", s.Status)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Process exit cleanup test
 // ---------------------------------------------------------------------------
@@ -820,6 +885,63 @@ func TestRelayOutput_ChunksLongMessages(t *testing.T) {
 	}
 }
 
+// editingAdapter is a MockAdapter that also implements MessageEditor,
+// recording each edit.
+type editingAdapter struct {
+	*MockAdapter
+	mu    sync.Mutex
+	edits map[string]string // message ID -> latest text
+}
+
+func (a *editingAdapter) EditMessage(_ context.Context, _, _, messageID, text string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.edits[messageID] = text
+	return nil
+}
+
+func TestRelayOutput_StreamsByEditing(t *testing.T) {
+	db := openSessionTestDB(t)
+	adapter := &editingAdapter{MockAdapter: NewMockAdapter(), edits: map[string]string{}}
+	adapter.Connect(context.Background())
+
+	sm, _ := NewSessionManager(SessionManagerOpts{
+		DB:                 db,
+		Spawner:            &mockSpawner{},
+		Adapter:            adapter,
+		RelayFlushInterval: 20 * time.Millisecond,
+	})
+
+	proc := newMockProcess("")
+	done := make(chan struct{})
+	go func() {
+		sm.relayOutput(context.Background(), "C01", "thread-1", 1, proc)
+		close(done)
+	}()
+
+	proc.recvCh <- "step one"
+	time.Sleep(100 * time.Millisecond) // let a flush post the first message
+	proc.recvCh <- "step two"
+	time.Sleep(100 * time.Millisecond)
+	proc.recvCh <- strings.Repeat("x", 1990) // overflows the live message
+	close(proc.recvCh)
+	proc.exitWith(nil)
+	<-done
+
+	sent := adapter.AllSent()
+	if len(sent) != 2 {
+		t.Fatalf("sent count = %d, want 2 (first post, then overflow)", len(sent))
+	}
+	if sent[0].Text != "step one" {
+		t.Errorf("first post = %q", sent[0].Text)
+	}
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if got := adapter.edits["msg-1"]; got != "step one\nstep two" {
+		t.Errorf("edited text = %q, want the first message extended", got)
+	}
+}
+
 // TestRelayOutput_EmptyOutputSendsWarning asserts that when the agent
 // finishes cleanly but produces no text, the user gets a warning in the
 // thread instead of silence (and no empty message is POSTed).
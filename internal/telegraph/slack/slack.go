@@ -2,17 +2,21 @@
 package slack
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
 	slackapi "github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
@@ -34,8 +38,10 @@ const (
 type slackClient interface {
 	AuthTest() (*slackapi.AuthTestResponse, error)
 	PostMessage(channelID string, options ...slackapi.MsgOption) (string, string, error)
+	UpdateMessage(channelID, timestamp string, options ...slackapi.MsgOption) (string, string, string, error)
 	GetConversationReplies(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error)
 	GetUserInfo(userID string) (*slackapi.User, error)
+	GetFileContext(ctx context.Context, downloadURL string, w io.Writer) error
 }
 
 // socketClient abstracts the Socket Mode client methods we use.
@@ -65,6 +71,8 @@ type Adapter struct {
 	botToken        string
 	channelID       string          // default channel for messages without explicit channel
 	allowedChannels map[string]bool // channels the bot may respond in; nil/empty = all
+	httpClient      *http.Client
+	dialer          *websocket.Dialer
 	mu              sync.Mutex
 	connected       bool
 	closed          bool
@@ -74,6 +82,12 @@ type Adapter struct {
 	maxBackoff      time.Duration // reconnection max backoff (default: maxBackoff const)
 	maxReconnect    int           // max reconnection attempts (default: maxReconnectAttempts)
 
+	// scopes holds the OAuth scopes Slack reported in the X-OAuth-Scopes
+	// header of the auth.test call made by Connect. Guarded by scopesMu, not
+	// mu, because the header callback fires while Connect holds mu.
+	scopesMu sync.Mutex
+	scopes   []string
+
 	// Inbound lifecycle, guarded by sendMu so a send can never race the close
 	// in teardown (mirrors the Discord adapter — railyard-hpy).
 	sendMu        sync.Mutex
@@ -87,6 +101,11 @@ type AdapterOpts struct {
 	BotToken        string   // xoxb-... Slack bot token
 	ChannelID       string   // default channel to post to
 	AllowedChannels []string // channel IDs the bot may respond in; empty = all
+	// HTTPClient and Dialer, when set, replace the Web API client and the
+	// Socket Mode dialer (e.g. to route through a proxy; see
+	// internal/outbound).
+	HTTPClient *http.Client
+	Dialer     *websocket.Dialer
 	// For testing: inject mock clients instead of real Slack API.
 	Client slackClient
 	Socket socketClient
@@ -114,6 +133,8 @@ func New(opts AdapterOpts) (*Adapter, error) {
 		botToken:        opts.BotToken,
 		channelID:       opts.ChannelID,
 		allowedChannels: allowed,
+		httpClient:      opts.HTTPClient,
+		dialer:          opts.Dialer,
 		inbound:         make(chan telegraph.InboundMessage, 100),
 		baseBackoff:     baseBackoff,
 		maxBackoff:      maxBackoff,
@@ -143,9 +164,20 @@ func (a *Adapter) Connect(ctx context.Context) error {
 
 	// Create real clients if not injected (production path).
 	if a.client == nil {
-		api := slackapi.New(a.botToken, slackapi.OptionAppLevelToken(a.appToken))
+		apiOpts := []slackapi.Option{
+			slackapi.OptionAppLevelToken(a.appToken),
+			slackapi.OptionOnResponseHeaders(a.recordScopes),
+		}
+		if a.httpClient != nil {
+			apiOpts = append(apiOpts, slackapi.OptionHTTPClient(a.httpClient))
+		}
+		api := slackapi.New(a.botToken, apiOpts...)
+		var socketOpts []socketmode.Option
+		if a.dialer != nil {
+			socketOpts = append(socketOpts, socketmode.OptionDialer(a.dialer))
+		}
 		a.client = api
-		a.socket = &realSocketClient{client: socketmode.New(api)}
+		a.socket = &realSocketClient{client: socketmode.New(api, socketOpts...)}
 	}
 
 	// Get bot user ID for self-message filtering.
@@ -194,10 +226,17 @@ func (a *Adapter) Listen(ctx context.Context) (<-chan telegraph.InboundMessage,
 
 // Send delivers a message to Slack. Translates OutboundMessage to Block Kit.
 func (a *Adapter) Send(ctx context.Context, msg telegraph.OutboundMessage) error {
+	_, err := a.Post(ctx, msg)
+	return err
+}
+
+// Post delivers a message like Send and returns its timestamp, which is the
+// message ID StartThread expects. Implements telegraph.MessagePoster.
+func (a *Adapter) Post(ctx context.Context, msg telegraph.OutboundMessage) (string, error) {
 	a.mu.Lock()
 	if !a.connected {
 		a.mu.Unlock()
-		return fmt.Errorf("slack: not connected")
+		return "", fmt.Errorf("slack: not connected")
 	}
 	a.mu.Unlock()
 
@@ -206,21 +245,122 @@ func (a *Adapter) Send(ctx context.Context, msg telegraph.OutboundMessage) error
 		channelID = a.channelID
 	}
 	if channelID == "" {
-		return fmt.Errorf("slack: no channel specified")
+		return "", fmt.Errorf("slack: no channel specified")
 	}
 
 	options := buildMessageOptions(msg)
 
+	var ts string
 	err := retryOnRateLimit(ctx, func() error {
-		_, _, postErr := a.client.PostMessage(channelID, options...)
+		var postErr error
+		_, ts, postErr = a.client.PostMessage(channelID, options...)
 		return postErr
 	})
 	if err != nil {
-		return fmt.Errorf("slack: post message: %w", err)
+		return "", fmt.Errorf("slack: post message: %w", err)
+	}
+	return ts, nil
+}
+
+// EditMessage replaces the text of a message posted by Post; messageID is
+// its timestamp. Implements telegraph.MessageEditor.
+func (a *Adapter) EditMessage(ctx context.Context, channelID, threadID, messageID, text string) error {
+	a.mu.Lock()
+	if !a.connected {
+		a.mu.Unlock()
+		return fmt.Errorf("slack: not connected")
+	}
+	a.mu.Unlock()
+
+	if channelID == "" {
+		channelID = a.channelID
+	}
+	err := retryOnRateLimit(ctx, func() error {
+		_, _, _, updateErr := a.client.UpdateMessage(channelID, messageID, slackapi.MsgOptionText(text, false))
+		return updateErr
+	})
+	if err != nil {
+		return fmt.Errorf("slack: update message: %w", err)
 	}
 	return nil
 }
 
+// SendDirect posts msg to the bot's DM conversation with userID; Slack
+// accepts a user ID as the channel for chat.postMessage. Requires the
+// im:write scope. Implements telegraph.DirectMessenger.
+func (a *Adapter) SendDirect(ctx context.Context, userID string, msg telegraph.OutboundMessage) error {
+	a.mu.Lock()
+	if !a.connected {
+		a.mu.Unlock()
+		return fmt.Errorf("slack: not connected")
+	}
+	a.mu.Unlock()
+
+	msg.ChannelID = ""
+	msg.ThreadID = ""
+	options := buildMessageOptions(msg)
+
+	err := retryOnRateLimit(ctx, func() error {
+		_, _, postErr := a.client.PostMessage(userID, options...)
+		return postErr
+	})
+	if err != nil {
+		return fmt.Errorf("slack: send DM to %s: %w", userID, err)
+	}
+	return nil
+}
+
+// recordScopes captures the granted OAuth scopes from Slack response headers.
+func (a *Adapter) recordScopes(path string, headers http.Header) {
+	raw := headers.Get("X-OAuth-Scopes")
+	if raw == "" {
+		return
+	}
+	var scopes []string
+	for _, sc := range strings.Split(raw, ",") {
+		if sc = strings.TrimSpace(sc); sc != "" {
+			scopes = append(scopes, sc)
+		}
+	}
+	a.scopesMu.Lock()
+	a.scopes = scopes
+	a.scopesMu.Unlock()
+}
+
+// CheckPermissions maps the bot token's OAuth scopes to telegraph
+// capabilities. Slack threads are plain replies, so thread creation only
+// needs chat:write. Implements telegraph.PermissionChecker.
+func (a *Adapter) CheckPermissions(ctx context.Context, channelID string) ([]telegraph.Capability, error) {
+	a.scopesMu.Lock()
+	granted := make(map[string]bool, len(a.scopes))
+	for _, sc := range a.scopes {
+		granted[sc] = true
+	}
+	a.scopesMu.Unlock()
+	if len(granted) == 0 {
+		return nil, fmt.Errorf("slack: token scopes not reported by auth.test")
+	}
+
+	check := func(name string, anyOf ...string) telegraph.Capability {
+		for _, sc := range anyOf {
+			if granted[sc] {
+				return telegraph.Capability{Name: name, Status: telegraph.CapabilityOK, Detail: sc}
+			}
+		}
+		return telegraph.Capability{
+			Name:   name,
+			Status: telegraph.CapabilityMissing,
+			Detail: "missing scope " + strings.Join(anyOf, " or "),
+		}
+	}
+	return []telegraph.Capability{
+		check(telegraph.CapSendMessage, "chat:write"),
+		check(telegraph.CapCreateThread, "chat:write"),
+		check(telegraph.CapReadHistory, "channels:history", "groups:history"),
+		check(telegraph.CapUploadFiles, "files:write"),
+	}, nil
+}
+
 // StartThread creates a thread from an existing message by replying to it.
 // In Slack, threads are simply reply chains — the original message's timestamp
 // (thread_ts) is the only identifier. Slack has no API to set a display name
@@ -254,6 +394,29 @@ func (a *Adapter) StartThread(ctx context.Context, channelID, messageID, replyTe
 // It paginates through all replies using cursor-based pagination and handles
 // Slack rate limits with exponential backoff.
 func (a *Adapter) ThreadHistory(ctx context.Context, channelID, threadID string, limit int) ([]telegraph.ThreadMessage, error) {
+	return a.replies(ctx, channelID, threadID, "", limit)
+}
+
+// ThreadHistorySince returns the replies posted after the message with
+// timestamp afterMessageID. Implements telegraph.ThreadHistorySincer.
+func (a *Adapter) ThreadHistorySince(ctx context.Context, channelID, threadID, afterMessageID string, limit int) ([]telegraph.ThreadMessage, error) {
+	msgs, err := a.replies(ctx, channelID, threadID, afterMessageID, limit)
+	if err != nil {
+		return nil, err
+	}
+	// conversations.replies always includes the thread's parent message.
+	kept := msgs[:0]
+	for _, m := range msgs {
+		if compareSlackTS(m.MessageID, afterMessageID) > 0 {
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
+
+// replies pages through conversations.replies, starting after the message
+// with timestamp oldest when it is set.
+func (a *Adapter) replies(ctx context.Context, channelID, threadID, oldest string, limit int) ([]telegraph.ThreadMessage, error) {
 	a.mu.Lock()
 	if !a.connected {
 		a.mu.Unlock()
@@ -274,6 +437,7 @@ func (a *Adapter) ThreadHistory(ctx context.Context, channelID, threadID string,
 		params := &slackapi.GetConversationRepliesParameters{
 			ChannelID: channelID,
 			Timestamp: threadID,
+			Oldest:    oldest,
 			Limit:     pageSize,
 			Cursor:    cursor,
 		}
@@ -293,6 +457,7 @@ func (a *Adapter) ThreadHistory(ctx context.Context, channelID, threadID string,
 
 		for _, m := range msgs {
 			allMsgs = append(allMsgs, telegraph.ThreadMessage{
+				MessageID: m.Timestamp,
 				UserID:    m.User,
 				UserName:  a.resolveUserName(m.User),
 				Text:      m.Text,
@@ -466,8 +631,9 @@ func (a *Adapter) handleMessage(ev *slackevents.MessageEvent) {
 	if ev.User == a.botUserID {
 		return
 	}
-	// Filter bot messages and message subtypes (edits, deletes, etc.).
-	if ev.BotID != "" || ev.SubType != "" {
+	// Filter bot messages and message subtypes (edits, deletes, etc.), but
+	// keep file uploads so the router can store them as attachments.
+	if ev.BotID != "" || (ev.SubType != "" && ev.SubType != "file_share") {
 		return
 	}
 	// Filter messages from channels not in the allowlist.
@@ -476,6 +642,8 @@ func (a *Adapter) handleMessage(ev *slackevents.MessageEvent) {
 	}
 	// Skip @mentions of the bot — they fire as both message.channels and
 	// app_mention events. Let handleAppMention handle them to avoid duplicates.
+	// Files on the mention itself are dropped with it; users upload specs as
+	// thread replies.
 	if a.botUserID != "" && strings.Contains(ev.Text, "<@"+a.botUserID+">") {
 		return
 	}
@@ -489,9 +657,42 @@ func (a *Adapter) handleMessage(ev *slackevents.MessageEvent) {
 		UserName:  a.resolveUserName(ev.User),
 		Text:      ev.Text,
 		Timestamp: parseSlackTimestamp(ev.TimeStamp),
+		Files:     inboundFiles(ev.Message),
 	})
 }
 
+// inboundFiles maps the files shared with a message. slackevents decodes
+// the file_share payload into ev.Message, which carries the file list.
+func inboundFiles(msg *slackapi.Msg) []telegraph.InboundFile {
+	if msg == nil {
+		return nil
+	}
+	var out []telegraph.InboundFile
+	for _, f := range msg.Files {
+		out = append(out, telegraph.InboundFile{
+			ID:       f.ID,
+			Name:     f.Name,
+			MimeType: f.Mimetype,
+			Size:     int64(f.Size),
+			URL:      f.URLPrivateDownload,
+		})
+	}
+	return out
+}
+
+// DownloadFile implements telegraph.FileDownloader. Slack file URLs are
+// private; the client authenticates with the bot token, which needs the
+// files:read scope.
+func (a *Adapter) DownloadFile(ctx context.Context, f telegraph.InboundFile, w io.Writer) error {
+	if f.URL == "" {
+		return fmt.Errorf("slack: file %s has no download URL", f.ID)
+	}
+	if err := a.client.GetFileContext(ctx, f.URL, w); err != nil {
+		return fmt.Errorf("slack: download %s: %w", f.Name, err)
+	}
+	return nil
+}
+
 // handleAppMention converts a Slack @mention event to an InboundMessage.
 func (a *Adapter) handleAppMention(ev *slackevents.AppMentionEvent) {
 	// Filter self-mentions (shouldn't happen but be safe).
@@ -666,3 +867,19 @@ func parseSlackTimestamp(ts string) time.Time {
 	}
 	return time.Unix(sec, 0)
 }
+
+// compareSlackTS orders two message timestamps ("1712345678.000200"),
+// returning -1, 0, or 1. Unlike parseSlackTimestamp it keeps the
+// sub-second part, which tells apart messages posted in the same second.
+func compareSlackTS(a, b string) int {
+	as, af, _ := strings.Cut(a, ".")
+	bs, bf, _ := strings.Cut(b, ".")
+	if c := cmp.Compare(len(as), len(bs)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(as, bs); c != 0 {
+		return c
+	}
+	width := max(len(af), len(bf))
+	return strings.Compare(af+strings.Repeat("0", width-len(af)), bf+strings.Repeat("0", width-len(bf)))
+}
@@ -2,7 +2,10 @@ package slack
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"sync"
 	"testing"
@@ -17,16 +20,24 @@ import (
 // --- Mock Slack client ---
 
 type mockSlackClient struct {
-	mu       sync.Mutex
-	authResp *slackapi.AuthTestResponse
-	authErr  error
-	posted   []postedMessage
-	postErr  error
-	replies  []slackapi.Message
-	hasMore  bool
-	cursor   string
-	replyErr error
-	users    map[string]*slackapi.User
+	mu              sync.Mutex
+	authResp        *slackapi.AuthTestResponse
+	authErr         error
+	posted          []postedMessage
+	postErr         error
+	replies         []slackapi.Message
+	hasMore         bool
+	cursor          string
+	replyErr        error
+	lastReplyParams *slackapi.GetConversationRepliesParameters
+	users           map[string]*slackapi.User
+	updated         []updatedMessage
+	files           map[string]string // download URL → contents
+}
+
+type updatedMessage struct {
+	channelID string
+	timestamp string
 }
 
 type postedMessage struct {
@@ -55,7 +66,20 @@ func (m *mockSlackClient) PostMessage(channelID string, options ...slackapi.MsgO
 	return channelID, "1234567890.123456", nil
 }
 
+func (m *mockSlackClient) UpdateMessage(channelID, timestamp string, options ...slackapi.MsgOption) (string, string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.postErr != nil {
+		return "", "", "", m.postErr
+	}
+	m.updated = append(m.updated, updatedMessage{channelID: channelID, timestamp: timestamp})
+	return channelID, timestamp, "", nil
+}
+
 func (m *mockSlackClient) GetConversationReplies(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+	m.mu.Lock()
+	m.lastReplyParams = params
+	m.mu.Unlock()
 	if m.replyErr != nil {
 		return nil, false, "", m.replyErr
 	}
@@ -71,6 +95,17 @@ func (m *mockSlackClient) GetUserInfo(userID string) (*slackapi.User, error) {
 	return nil, fmt.Errorf("user not found: %s", userID)
 }
 
+func (m *mockSlackClient) GetFileContext(ctx context.Context, downloadURL string, w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[downloadURL]
+	if !ok {
+		return fmt.Errorf("file not found: %s", downloadURL)
+	}
+	_, err := io.WriteString(w, data)
+	return err
+}
+
 func (m *mockSlackClient) postedCount() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -458,6 +493,22 @@ func TestListen_AcksEventsAPIEvents(t *testing.T) {
 	}
 }
 
+// --- EditMessage tests ---
+
+func TestEditMessage_UpdatesByTimestamp(t *testing.T) {
+	a, client, _ := newTestAdapter(t)
+
+	if err := a.EditMessage(context.Background(), "", "1111.2222", "1234567890.123456", "more output"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.updated) != 1 {
+		t.Fatalf("updates = %d, want 1", len(client.updated))
+	}
+	if got := client.updated[0]; got.channelID != "C_DEFAULT" || got.timestamp != "1234567890.123456" {
+		t.Errorf("update = %+v, want default channel and message ts", got)
+	}
+}
+
 // --- Send tests ---
 
 func TestSend_SimpleText(t *testing.T) {
@@ -561,6 +612,35 @@ func TestSend_PostError(t *testing.T) {
 	}
 }
 
+func TestSendDirect_PostsToUser(t *testing.T) {
+	a, client, _ := newTestAdapter(t)
+
+	err := a.SendDirect(context.Background(), "U42", telegraph.OutboundMessage{
+		ChannelID: "C1",
+		ThreadID:  "1234.5678",
+		Text:      "your car merged",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.postedCount() != 1 {
+		t.Fatalf("expected 1 posted message, got %d", client.postedCount())
+	}
+	if last := client.lastPosted(); last.channelID != "U42" {
+		t.Errorf("channel = %q, want U42", last.channelID)
+	}
+}
+
+func TestSendDirect_NotConnected(t *testing.T) {
+	client := newMockSlackClient()
+	socket := newMockSocketClient()
+	a, _ := New(AdapterOpts{Client: client, Socket: socket})
+
+	if err := a.SendDirect(context.Background(), "U42", telegraph.OutboundMessage{Text: "hi"}); err == nil {
+		t.Fatal("expected error for not connected")
+	}
+}
+
 // --- ThreadHistory tests ---
 
 func TestThreadHistory_Success(t *testing.T) {
@@ -585,6 +665,45 @@ func TestThreadHistory_Success(t *testing.T) {
 	}
 }
 
+func TestThreadHistorySince_SkipsParentAndOlder(t *testing.T) {
+	a, client, _ := newTestAdapter(t)
+	// Slack returns the parent message even when oldest is set.
+	client.replies = []slackapi.Message{
+		{Msg: slackapi.Msg{User: "U_ALICE", Text: "parent", Timestamp: "1700000000.000001"}},
+		{Msg: slackapi.Msg{User: "U_BOB", Text: "seen", Timestamp: "1700000005.000100"}},
+		{Msg: slackapi.Msg{User: "U_BOB", Text: "same second", Timestamp: "1700000005.000200"}},
+	}
+
+	msgs, err := a.ThreadHistorySince(context.Background(), "C1", "1700000000.000001", "1700000005.000100", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Text != "same second" || msgs[0].MessageID != "1700000005.000200" {
+		t.Fatalf("msgs = %+v, want only the newer reply", msgs)
+	}
+	if got := client.lastReplyParams.Oldest; got != "1700000005.000100" {
+		t.Errorf("oldest = %q, want the last seen timestamp", got)
+	}
+}
+
+func TestCompareSlackTS(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1700000000.000001", "1700000000.000001", 0},
+		{"1700000000.000002", "1700000000.000001", 1},
+		{"1700000000.5", "1700000000.400000", 1},
+		{"999999999.999999", "1000000000.000000", -1},
+		{"1700000000.000001", "", 1},
+	}
+	for _, tt := range tests {
+		if got := compareSlackTS(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareSlackTS(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
 func TestThreadHistory_NotConnected(t *testing.T) {
 	client := newMockSlackClient()
 	socket := newMockSocketClient()
@@ -822,6 +941,58 @@ func TestHandleMessage_FiltersSubtypes(t *testing.T) {
 	}
 }
 
+func TestHandleMessage_FileShare(t *testing.T) {
+	a, client, socket := newTestAdapter(t)
+	client.files = map[string]string{"https://files.slack.com/spec.md": "# spec"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, _ := a.Listen(ctx)
+
+	// Decode the event as Slack sends it: the files sit at the top level of
+	// the payload and slackevents moves them into ev.Message.
+	ev := &slackevents.MessageEvent{}
+	payload := `{
+		"type": "message", "subtype": "file_share", "user": "U_ALICE", "channel": "C1",
+		"text": "here is the spec", "thread_ts": "1700000000.000001", "ts": "1700000002.000001",
+		"files": [{"id": "F1", "name": "spec.md", "mimetype": "text/markdown", "size": 6,
+			"url_private_download": "https://files.slack.com/spec.md"}]
+	}`
+	if err := json.Unmarshal([]byte(payload), ev); err != nil {
+		t.Fatal(err)
+	}
+
+	socket.events <- socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type: slackevents.CallbackEvent,
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Data: ev,
+			},
+		},
+		Request: &socketmode.Request{EnvelopeID: "env-10"},
+	}
+
+	var msg telegraph.InboundMessage
+	select {
+	case msg = <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("timeout: file_share message was filtered")
+	}
+	if len(msg.Files) != 1 || msg.Files[0].Name != "spec.md" || msg.Files[0].Size != 6 {
+		t.Fatalf("files = %+v", msg.Files)
+	}
+
+	var buf strings.Builder
+	if err := a.DownloadFile(ctx, msg.Files[0], &buf); err != nil || buf.String() != "# spec" {
+		t.Errorf("DownloadFile = %q, %v", buf.String(), err)
+	}
+	if err := a.DownloadFile(ctx, telegraph.InboundFile{ID: "F2"}, &buf); err == nil {
+		t.Error("file without URL: want error")
+	}
+}
+
 // --- handleAppMention self-mention filtering ---
 
 func TestHandleAppMention_FiltersSelfMention(t *testing.T) {
@@ -1030,6 +1201,10 @@ func (r *rateLimitMockClient) PostMessage(channelID string, options ...slackapi.
 	return r.inner.PostMessage(channelID, options...)
 }
 
+func (r *rateLimitMockClient) UpdateMessage(channelID, timestamp string, options ...slackapi.MsgOption) (string, string, string, error) {
+	return r.inner.UpdateMessage(channelID, timestamp, options...)
+}
+
 func (r *rateLimitMockClient) GetConversationReplies(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
 	r.mu.Lock()
 	r.calls++
@@ -1045,6 +1220,10 @@ func (r *rateLimitMockClient) GetUserInfo(userID string) (*slackapi.User, error)
 	return r.inner.GetUserInfo(userID)
 }
 
+func (r *rateLimitMockClient) GetFileContext(ctx context.Context, downloadURL string, w io.Writer) error {
+	return r.inner.GetFileContext(ctx, downloadURL, w)
+}
+
 // --- ThreadHistory rate limiting tests ---
 
 func TestThreadHistory_RetriesOnRateLimit(t *testing.T) {
@@ -1150,6 +1329,10 @@ func (p *paginatingMockClient) PostMessage(channelID string, options ...slackapi
 	return channelID, "ts", nil
 }
 
+func (p *paginatingMockClient) UpdateMessage(channelID, timestamp string, options ...slackapi.MsgOption) (string, string, string, error) {
+	return channelID, timestamp, "", nil
+}
+
 func (p *paginatingMockClient) GetConversationReplies(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -1176,6 +1359,10 @@ func (p *paginatingMockClient) GetUserInfo(userID string) (*slackapi.User, error
 	return nil, fmt.Errorf("user not found: %s", userID)
 }
 
+func (p *paginatingMockClient) GetFileContext(ctx context.Context, downloadURL string, w io.Writer) error {
+	return fmt.Errorf("file not found: %s", downloadURL)
+}
+
 // --- runWithReconnect tests ---
 
 func TestRunWithReconnect_CleanShutdown(t *testing.T) {
@@ -1830,3 +2017,56 @@ func TestListen_CtxCancelClosesInbound(t *testing.T) {
 		t.Fatal("inbound channel not closed within 2s of ctx cancel")
 	}
 }
+
+// --- Post / CheckPermissions tests ---
+
+func TestPost_ReturnsTimestamp(t *testing.T) {
+	a, client, _ := newTestAdapter(t)
+
+	id, err := a.Post(context.Background(), telegraph.OutboundMessage{Text: "hello"})
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if id != "1234567890.123456" {
+		t.Errorf("id = %q, want slack ts", id)
+	}
+	if client.lastPosted().channelID != "C_DEFAULT" {
+		t.Errorf("channel = %q, want default channel", client.lastPosted().channelID)
+	}
+}
+
+func TestRecordScopes_ParsesHeader(t *testing.T) {
+	a, _, _ := newTestAdapter(t)
+
+	h := http.Header{}
+	h.Set("X-OAuth-Scopes", "chat:write, channels:history,app_mentions:read")
+	a.recordScopes("auth.test", h)
+
+	caps, err := a.CheckPermissions(context.Background(), "C1")
+	if err != nil {
+		t.Fatalf("check permissions: %v", err)
+	}
+	got := make(map[string]string)
+	for _, c := range caps {
+		got[c.Name] = c.Status
+	}
+	want := map[string]string{
+		telegraph.CapSendMessage:  telegraph.CapabilityOK,
+		telegraph.CapCreateThread: telegraph.CapabilityOK,
+		telegraph.CapReadHistory:  telegraph.CapabilityOK,
+		telegraph.CapUploadFiles:  telegraph.CapabilityMissing,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %q, want %q", name, got[name], status)
+		}
+	}
+}
+
+func TestCheckPermissions_NoScopesReported(t *testing.T) {
+	a, _, _ := newTestAdapter(t)
+
+	if _, err := a.CheckPermissions(context.Background(), "C1"); err == nil {
+		t.Fatal("expected error when no scopes were recorded")
+	}
+}
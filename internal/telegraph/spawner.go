@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -29,6 +30,10 @@ type ClaudeSpawner struct {
 	// ClaudeProvider uses. Empty leaves the env unchanged, preserving the
 	// CLI's default (and any operator-set ANTHROPIC_MODEL in the shell).
 	Model string
+	// ReadOnly drops --dangerously-skip-permissions, so in print mode claude
+	// may read and search files but is denied any tool that needs approval
+	// (edits, shell commands). Used for `!ry ask`.
+	ReadOnly bool
 }
 
 // Spawn starts a claude subprocess. If prompt is non-empty, it is passed via
@@ -40,9 +45,15 @@ func (s *ClaudeSpawner) Spawn(ctx context.Context, prompt string) (Process, erro
 		binary = "claude"
 	}
 
+	// stream-json (which requires --verbose with -p) emits each assistant
+	// message and tool call as it happens, so the relay can show progress
+	// during long turns instead of the whole answer at exit.
 	args := []string{
-		"--dangerously-skip-permissions",
-		"--output-format", "text",
+		"--output-format", "stream-json",
+		"--verbose",
+	}
+	if !s.ReadOnly {
+		args = append([]string{"--dangerously-skip-permissions"}, args...)
 	}
 	if s.SystemPrompt != "" {
 		args = append(args, "--append-system-prompt", s.SystemPrompt)
@@ -120,8 +131,12 @@ func (s *ClaudeSpawner) Spawn(ctx context.Context, prompt string) (Process, erro
 		scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024) // 1MB buffer
 	scanLoop:
 		for scanner.Scan() {
-			line := scanner.Text()
-			stdoutBytes += len(line) + 1 // +1 for the newline the scanner strips
+			raw := scanner.Text()
+			stdoutBytes += len(raw) + 1 // +1 for the newline the scanner strips
+			line, ok := renderClaudeStreamLine(raw)
+			if !ok {
+				continue
+			}
 			select {
 			case recvCh <- line:
 			case <-ctx.Done():
@@ -156,6 +171,53 @@ func (s *ClaudeSpawner) Spawn(ctx context.Context, prompt string) (Process, erro
 	return proc, nil
 }
 
+// renderClaudeStreamLine maps one line of claude stream-json output to a
+// relay line, mirroring renderLoopEvent: assistant text is relayed as-is and
+// tool calls surface as a progress line. System, tool-result, and successful
+// result events (the result repeats the final assistant text) are dropped.
+// Lines that are not stream-json events pass through unchanged.
+func renderClaudeStreamLine(line string) (string, bool) {
+	var ev struct {
+		Type    string `json:"type"`
+		IsError bool   `json:"is_error"`
+		Result  string `json:"result"`
+		Message struct {
+			Content []struct {
+				Type  string          `json:"type"`
+				Text  string          `json:"text"`
+				Name  string          `json:"name"`
+				Input json.RawMessage `json:"input"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &ev) != nil || ev.Type == "" {
+		return line, true
+	}
+	switch ev.Type {
+	case "assistant":
+		var parts []string
+		for _, block := range ev.Message.Content {
+			switch block.Type {
+			case "text":
+				if block.Text != "" {
+					parts = append(parts, block.Text)
+				}
+			case "tool_use":
+				parts = append(parts, agentloop.FormatToolProgress(block.Name, string(block.Input)))
+			}
+		}
+		if len(parts) == 0 {
+			return "", false
+		}
+		return strings.Join(parts, "\n"), true
+	case "result":
+		if ev.IsError && ev.Result != "" {
+			return "⚠️ " + ev.Result, true
+		}
+	}
+	return "", false
+}
+
 // claudeProcess implements the Process interface for a running claude subprocess.
 type claudeProcess struct {
 	cmd       *exec.Cmd
@@ -222,6 +284,11 @@ func (p *claudeProcess) Stderr() string {
 	return p.stderr
 }
 
+// PID returns the subprocess PID, which is also its process group ID.
+func (p *claudeProcess) PID() int {
+	return p.cmd.Process.Pid
+}
+
 // Close terminates the subprocess via context cancellation (SIGTERM).
 func (p *claudeProcess) Close() error {
 	p.mu.Lock()
@@ -269,6 +336,11 @@ type LazySpawner struct {
 	CodeSearch *agentloop.CodeSearchParams
 	// MaxIterations bounds the native loop; 0 uses the agentloop default.
 	MaxIterations int
+
+	// HTTP, when non-nil, routes dispatch to a hosted agent API. Its
+	// SystemPrompt is filled from RenderPrompt on each Spawn; no local
+	// worktree is prepared since the agent does not run on this host.
+	HTTP *HTTPSpawner
 }
 
 // Spawn performs full dispatch setup then delegates to ClaudeSpawner.
@@ -285,6 +357,15 @@ func (ls *LazySpawner) Spawn(ctx context.Context, prompt string) (Process, error
 		return nil, fmt.Errorf("telegraph: lazy spawn: render prompt: %w", err)
 	}
 
+	if ls.HTTP != nil {
+		remote := *ls.HTTP
+		remote.SystemPrompt = systemPrompt
+		if remote.Model == "" {
+			remote.Model = ls.Model
+		}
+		return remote.Spawn(ctx, prompt)
+	}
+
 	worktreeDir, err := ls.EnsureWorktree()
 	if err != nil {
 		return nil, fmt.Errorf("telegraph: lazy spawn: ensure worktree: %w", err)
@@ -58,6 +58,45 @@ echo "line three"`)
 	}
 }
 
+func TestClaudeSpawner_RendersStreamJSON(t *testing.T) {
+	dir := t.TempDir()
+	binary := writeMockBinary(t, dir, "claude", `cat <<'EOF'
+{"type":"system","subtype":"init"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Checking the board"},{"type":"tool_use","name":"Bash","input":{"command":"ry car list"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Created car-001"}]}}
+{"type":"result","subtype":"success","result":"Created car-001"}
+EOF`)
+
+	spawner := &ClaudeSpawner{ClaudeBinary: binary, WorkDir: dir}
+	proc, err := spawner.Spawn(context.Background(), "test prompt")
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	defer proc.Close()
+
+	var lines []string
+	for line := range proc.Recv() {
+		lines = append(lines, line)
+	}
+	<-proc.Done()
+
+	want := []string{"Checking the board\n🔧 Bash: ry car list", "Created car-001"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestRenderClaudeStreamLine_ErrorResult(t *testing.T) {
+	got, ok := renderClaudeStreamLine(`{"type":"result","is_error":true,"result":"credit balance too low"}`)
+	if !ok || got != "⚠️ credit balance too low" {
+		t.Errorf("got (%q, %v)", got, ok)
+	}
+	if got, ok := renderClaudeStreamLine("{not json"); !ok || got != "{not json" {
+		t.Errorf("non-JSON line = (%q, %v), want passed through", got, ok)
+	}
+}
+
 func TestClaudeSpawner_SendClosesStdin(t *testing.T) {
 	dir := t.TempDir()
 	// Script reads from stdin and echoes it back.
@@ -0,0 +1,110 @@
+package telegraph
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/orchestration"
+)
+
+// statusStaleFactor bounds how old a cached status may be, in multiples of
+// the TTL, and still be served while a refresh runs in the background.
+const statusStaleFactor = 6
+
+// StatusCache is a StatusProvider that keeps the last status read in memory,
+// so `!ry status`, `!ry ask`, and pulse digests share one database read per
+// TTL instead of each running the engine and track queries.
+//
+// Within the TTL the cached status is returned as is. Past the TTL but
+// within statusStaleFactor TTLs it is still returned, and a single refresh
+// starts in the background, so a command answers immediately even while the
+// database is slow under merge load. Older than that, or after Invalidate,
+// callers wait for a fresh read. Concurrent misses share one read.
+//
+// The returned StatusInfo is shared between callers and must not be
+// modified.
+type StatusCache struct {
+	sp    StatusProvider
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	info    *orchestration.StatusInfo
+	fetched time.Time
+	gen     uint64 // bumped by Invalidate; reads started before it are not cached
+	loading *statusLoad
+}
+
+// statusLoad is one in-flight read of the underlying provider.
+type statusLoad struct {
+	done chan struct{}
+	info *orchestration.StatusInfo
+	err  error
+}
+
+// NewStatusCache wraps sp with a cache holding each read for ttl. A
+// non-positive ttl returns sp unwrapped.
+func NewStatusCache(sp StatusProvider, ttl time.Duration, clk clock.Clock) StatusProvider {
+	if ttl <= 0 {
+		return sp
+	}
+	return &StatusCache{sp: sp, ttl: ttl, clock: clock.OrReal(clk)}
+}
+
+// Status returns the cached status, reading through to the provider when
+// it is missing or too old.
+func (c *StatusCache) Status() (*orchestration.StatusInfo, error) {
+	c.mu.Lock()
+	if c.info != nil {
+		age := c.clock.Since(c.fetched)
+		if age < c.ttl*statusStaleFactor {
+			if age >= c.ttl {
+				c.startLoad()
+			}
+			info := c.info
+			c.mu.Unlock()
+			return info, nil
+		}
+	}
+	l := c.startLoad()
+	c.mu.Unlock()
+
+	<-l.done
+	return l.info, l.err
+}
+
+// Invalidate drops the cached status, so the next call reads fresh data.
+// Reads already in flight finish for their callers but are not cached.
+func (c *StatusCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.info = nil
+	c.loading = nil
+}
+
+// startLoad starts a read unless one is already running and returns it.
+// The caller holds c.mu.
+func (c *StatusCache) startLoad() *statusLoad {
+	if c.loading != nil {
+		return c.loading
+	}
+	l := &statusLoad{done: make(chan struct{})}
+	c.loading = l
+	gen := c.gen
+	go func() {
+		info, err := c.sp.Status()
+		c.mu.Lock()
+		l.info, l.err = info, err
+		if c.loading == l {
+			c.loading = nil
+		}
+		if err == nil && gen == c.gen {
+			c.info, c.fetched = info, c.clock.Now()
+		}
+		c.mu.Unlock()
+		close(l.done)
+	}()
+	return l
+}
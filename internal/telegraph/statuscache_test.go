@@ -0,0 +1,165 @@
+package telegraph
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/orchestration"
+)
+
+// countingStatusProvider returns a new StatusInfo per call, numbered by
+// MessageDepth, and can be held mid-read with gate.
+type countingStatusProvider struct {
+	calls atomic.Int32
+	gate  chan struct{} // when non-nil, each read waits for a receive
+	err   error
+}
+
+func (p *countingStatusProvider) Status() (*orchestration.StatusInfo, error) {
+	n := p.calls.Add(1)
+	if p.gate != nil {
+		<-p.gate
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &orchestration.StatusInfo{MessageDepth: int64(n)}, nil
+}
+
+func cachedRead(t *testing.T, sp StatusProvider) int {
+	t.Helper()
+	info, err := sp.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	return int(info.MessageDepth)
+}
+
+// waitForCalls waits for background refreshes to reach the provider.
+func waitForCalls(t *testing.T, p *countingStatusProvider, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.calls.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("provider calls = %d, want %d", p.calls.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStatusCache_ServesWithinTTL(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	p := &countingStatusProvider{}
+	sc := NewStatusCache(p, 5*time.Second, clk)
+
+	if got := cachedRead(t, sc); got != 1 {
+		t.Fatalf("first read = %d, want 1", got)
+	}
+	clk.Advance(4 * time.Second)
+	if got := cachedRead(t, sc); got != 1 {
+		t.Errorf("read within TTL = %d, want cached 1", got)
+	}
+	if p.calls.Load() != 1 {
+		t.Errorf("provider calls = %d, want 1", p.calls.Load())
+	}
+}
+
+func TestStatusCache_StaleWhileRefreshing(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	p := &countingStatusProvider{}
+	sc := NewStatusCache(p, 5*time.Second, clk)
+	cachedRead(t, sc)
+
+	// Past the TTL the stale status is served at once and one refresh
+	// starts in the background.
+	p.gate = make(chan struct{})
+	clk.Advance(6 * time.Second)
+	if got := cachedRead(t, sc); got != 1 {
+		t.Errorf("stale read = %d, want 1", got)
+	}
+	if got := cachedRead(t, sc); got != 1 {
+		t.Errorf("second stale read = %d, want 1", got)
+	}
+	waitForCalls(t, p, 2)
+	p.gate <- struct{}{}
+	p.gate = nil
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cachedRead(t, sc) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("refreshed status never served")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if p.calls.Load() != 2 {
+		t.Errorf("provider calls = %d, want 2 (one shared refresh)", p.calls.Load())
+	}
+
+	// Too old to serve: the caller waits for a fresh read.
+	clk.Advance(time.Minute)
+	if got := cachedRead(t, sc); got != 3 {
+		t.Errorf("read past max staleness = %d, want 3", got)
+	}
+}
+
+func TestStatusCache_Invalidate(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	p := &countingStatusProvider{}
+	sp := NewStatusCache(p, time.Minute, clk)
+	cachedRead(t, sp)
+
+	sp.(*StatusCache).Invalidate()
+	if got := cachedRead(t, sp); got != 2 {
+		t.Errorf("read after Invalidate = %d, want 2", got)
+	}
+}
+
+func TestStatusCache_ConcurrentMissesShareOneRead(t *testing.T) {
+	p := &countingStatusProvider{gate: make(chan struct{})}
+	sc := NewStatusCache(p, time.Minute, nil)
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			info, _ := sc.Status()
+			results[i] = int(info.MessageDepth)
+		}(i)
+	}
+	waitForCalls(t, p, 1)
+	close(p.gate)
+	wg.Wait()
+	for i, r := range results {
+		if r != 1 {
+			t.Errorf("caller %d got %d, want 1", i, r)
+		}
+	}
+	if p.calls.Load() != 1 {
+		t.Errorf("provider calls = %d, want 1", p.calls.Load())
+	}
+}
+
+func TestStatusCache_ErrorsAreNotCached(t *testing.T) {
+	p := &countingStatusProvider{err: errors.New("db down")}
+	sc := NewStatusCache(p, time.Minute, nil)
+	if _, err := sc.Status(); err == nil {
+		t.Fatal("expected error")
+	}
+	p.err = nil
+	if got := cachedRead(t, sc); got != 2 {
+		t.Errorf("read after error = %d, want 2", got)
+	}
+}
+
+func TestNewStatusCache_DisabledReturnsProvider(t *testing.T) {
+	p := &countingStatusProvider{}
+	if sp := NewStatusCache(p, 0, nil); sp != StatusProvider(p) {
+		t.Errorf("NewStatusCache with ttl 0 = %T, want the provider itself", sp)
+	}
+}
@@ -0,0 +1,166 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// Summarizer condenses the older turns of a long dispatch conversation, so
+// the recovery prompt of a resumed session stays within the agent's context.
+type Summarizer interface {
+	Summarize(ctx context.Context, turns []models.TelegraphConversation) (string, error)
+}
+
+// Defaults for TruncateSummarizer.
+const (
+	defaultSummaryTurnChars = 200
+	defaultSummaryChars     = 4000
+)
+
+// TruncateSummarizer is the default Summarizer. It makes no model call: each
+// turn becomes one line cut to MaxTurnChars, and when the lines exceed
+// MaxChars the middle of the conversation is dropped, keeping the opening
+// request and the turns closest to the recent ones.
+type TruncateSummarizer struct {
+	MaxTurnChars int // defaults to 200
+	MaxChars     int // defaults to 4000
+}
+
+// Summarize implements Summarizer.
+func (s TruncateSummarizer) Summarize(_ context.Context, turns []models.TelegraphConversation) (string, error) {
+	turnChars, maxChars := s.MaxTurnChars, s.MaxChars
+	if turnChars <= 0 {
+		turnChars = defaultSummaryTurnChars
+	}
+	if maxChars <= 0 {
+		maxChars = defaultSummaryChars
+	}
+	if len(turns) == 0 {
+		return "", nil
+	}
+
+	lines := make([]string, len(turns))
+	for i, t := range turns {
+		lines[i] = fmt.Sprintf("[%s] %s: %s", t.Role, t.UserName, clipTurn(t.Content, turnChars))
+	}
+
+	// Keep the opening turn, then as many of the latest lines as fit.
+	size := len(lines[0]) + 1
+	keepFrom := len(lines)
+	for keepFrom > 1 && size+len(lines[keepFrom-1])+1 <= maxChars {
+		keepFrom--
+		size += len(lines[keepFrom]) + 1
+	}
+	var b strings.Builder
+	b.WriteString(lines[0])
+	b.WriteString("\n")
+	if omitted := keepFrom - 1; omitted > 0 {
+		fmt.Fprintf(&b, "(%d turns omitted)\n", omitted)
+	}
+	for _, l := range lines[keepFrom:] {
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// clipTurn flattens content to one line of at most n characters.
+func clipTurn(content string, n int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if r := []rune(content); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return content
+}
+
+// SummarizeSystemPrompt is the system prompt for AgentSummarizer sessions.
+const SummarizeSystemPrompt = `You summarize a chat conversation between users and the Railyard dispatch
+agent, so the dispatcher can pick the conversation up again later.
+
+Rules:
+- You are read-only. Never create, update, or cancel cars, and never modify
+  files or run commands.
+- Keep what the users asked for, decisions made, car IDs created or
+  discussed, and questions still open. Drop greetings and repetition.
+- Answer with the summary only: at most 20 short bullet points.`
+
+const (
+	// defaultSummarizeTimeout bounds one AgentSummarizer session.
+	defaultSummarizeTimeout = 2 * time.Minute
+	// summarizeInputTurnChars caps each turn in the transcript sent to the
+	// agent, so the summarization call itself fits in context.
+	summarizeInputTurnChars = 2000
+)
+
+// AgentSummarizer asks a one-shot agent session to write the summary. When
+// the session fails or returns nothing, Fallback (default
+// TruncateSummarizer) summarizes instead, so a resume never fails because
+// summarization did.
+type AgentSummarizer struct {
+	Spawner  ProcessSpawner // should be configured read-only (see ClaudeSpawner.ReadOnly)
+	Timeout  time.Duration  // defaults to 2 minutes
+	Fallback Summarizer
+}
+
+// Summarize implements Summarizer.
+func (s *AgentSummarizer) Summarize(ctx context.Context, turns []models.TelegraphConversation) (string, error) {
+	summary, err := s.ask(ctx, turns)
+	if err == nil {
+		return summary, nil
+	}
+	log.Printf("telegraph: %v; truncating the conversation instead", err)
+	fallback := s.Fallback
+	if fallback == nil {
+		fallback = TruncateSummarizer{}
+	}
+	return fallback.Summarize(ctx, turns)
+}
+
+// ask runs the summarization session and returns its output.
+func (s *AgentSummarizer) ask(ctx context.Context, turns []models.TelegraphConversation) (string, error) {
+	if s.Spawner == nil {
+		return "", fmt.Errorf("telegraph: summarize: no spawner")
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultSummarizeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var prompt strings.Builder
+	prompt.WriteString("Summarize this conversation:\n\n")
+	for _, t := range turns {
+		fmt.Fprintf(&prompt, "[%s] %s: %s\n", t.Role, t.UserName, clipTurn(t.Content, summarizeInputTurnChars))
+	}
+	proc, err := s.Spawner.Spawn(ctx, prompt.String())
+	if err != nil {
+		return "", fmt.Errorf("telegraph: summarize: spawn: %w", err)
+	}
+	defer proc.Close()
+
+	var lines []string
+	recv := proc.Recv()
+	for recv != nil {
+		select {
+		case line, ok := <-recv:
+			if !ok {
+				recv = nil
+				break
+			}
+			lines = append(lines, line)
+		case <-ctx.Done():
+			return "", fmt.Errorf("telegraph: summarize: %w", ctx.Err())
+		}
+	}
+	summary := strings.TrimSpace(strings.Join(lines, "\n"))
+	if summary == "" {
+		return "", fmt.Errorf("telegraph: summarize: agent returned no summary")
+	}
+	return summary, nil
+}
@@ -0,0 +1,111 @@
+package telegraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func conversationTurns(n int, content func(i int) string) []models.TelegraphConversation {
+	turns := make([]models.TelegraphConversation, n)
+	for i := range turns {
+		role, user := "assistant", ""
+		if i%2 == 0 {
+			role, user = "user", "alice"
+		}
+		turns[i] = models.TelegraphConversation{Sequence: i + 1, Role: role, UserName: user, Content: content(i)}
+	}
+	return turns
+}
+
+func TestTruncateSummarizer(t *testing.T) {
+	turns := conversationTurns(30, func(i int) string {
+		return fmt.Sprintf("turn %d\n%s", i, strings.Repeat("x", 300))
+	})
+	summary, err := TruncateSummarizer{MaxChars: 1000}.Summarize(context.Background(), turns)
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if len(summary) > 1100 {
+		t.Errorf("summary is %d bytes, want about 1000", len(summary))
+	}
+	lines := strings.Split(strings.TrimSpace(summary), "\n")
+	if !strings.HasPrefix(lines[0], "[user] alice: turn 0 xxx") || !strings.HasSuffix(lines[0], "…") {
+		t.Errorf("first line = %q, want the opening turn flattened and clipped", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "(") || !strings.Contains(lines[1], "turns omitted)") {
+		t.Errorf("second line = %q, want an omission marker", lines[1])
+	}
+	if !strings.Contains(lines[len(lines)-1], "turn 29") {
+		t.Errorf("last line = %q, want the latest turn", lines[len(lines)-1])
+	}
+
+	short, _ := TruncateSummarizer{}.Summarize(context.Background(), conversationTurns(3, func(i int) string { return "hi" }))
+	if strings.Contains(short, "omitted") || strings.Count(short, "\n") != 3 {
+		t.Errorf("short conversation summary = %q, want every turn", short)
+	}
+}
+
+func TestAgentSummarizer(t *testing.T) {
+	turns := conversationTurns(4, func(i int) string { return fmt.Sprintf("message %d", i) })
+	spawner := &mockSpawner{}
+	s := &AgentSummarizer{Spawner: spawner}
+
+	done := make(chan string, 1)
+	go func() {
+		summary, _ := s.Summarize(context.Background(), turns)
+		done <- summary
+	}()
+	p := answerWith(t, spawner, "- alice asked for an auth task")
+	if summary := <-done; summary != "- alice asked for an auth task" {
+		t.Errorf("summary = %q", summary)
+	}
+	if !strings.Contains(p.prompt, "[user] alice: message 2") {
+		t.Errorf("prompt = %q, want the transcript", p.prompt)
+	}
+}
+
+func TestAgentSummarizer_FallsBackToTruncation(t *testing.T) {
+	turns := conversationTurns(2, func(i int) string { return fmt.Sprintf("message %d", i) })
+	s := &AgentSummarizer{Spawner: &mockSpawner{err: errors.New("no agent")}, Timeout: time.Second}
+	summary, err := s.Summarize(context.Background(), turns)
+	if err != nil || !strings.Contains(summary, "message 1") {
+		t.Errorf("summary = %q, %v; want the truncated conversation", summary, err)
+	}
+}
+
+func TestResume_SummarizesLongConversation(t *testing.T) {
+	db := openSessionTestDB(t)
+	spawner := &mockSpawner{}
+	sm, _ := NewSessionManager(SessionManagerOpts{DB: db, Spawner: spawner, SummarizeAfterTurns: 2})
+
+	now := time.Now()
+	old := models.DispatchSession{Source: "telegraph", UserName: "alice", PlatformThreadID: "thread-1", ChannelID: "C01",
+		Status: "completed", CarsCreated: "[]", LastHeartbeat: now, CompletedAt: &now}
+	db.Create(&old)
+	for _, turn := range conversationTurns(5, func(i int) string { return fmt.Sprintf("message %d", i) }) {
+		turn.SessionID = old.ID
+		db.Create(&turn)
+	}
+
+	if _, err := sm.Resume(context.Background(), "C01", "thread-1", "alice", "and now?"); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	prompt := spawner.lastProcess().prompt
+	summaryAt := strings.Index(prompt, "Summary of the earlier conversation (3 turns)")
+	recentAt := strings.Index(prompt, "Most recent turns:")
+	if summaryAt < 0 || recentAt < summaryAt {
+		t.Fatalf("prompt = %q, want a summary of 3 turns before the recent ones", prompt)
+	}
+	if !strings.Contains(prompt[summaryAt:recentAt], "message 0") || strings.Contains(prompt[summaryAt:recentAt], "message 3") {
+		t.Errorf("summary section = %q, want only the older turns", prompt[summaryAt:recentAt])
+	}
+	if !strings.Contains(prompt[recentAt:], "[user] alice: message 4") {
+		t.Errorf("recent section = %q, want the latest turns verbatim", prompt[recentAt:])
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/logutil"
 	"gorm.io/gorm"
@@ -21,24 +22,34 @@ type Daemon struct {
 	cfg            *config.Config
 	adapter        Adapter
 	spawner        ProcessSpawner
+	askSpawner     ProcessSpawner
+	summarySpawner ProcessSpawner
 	statusProvider StatusProvider
+	statusCache    *StatusCache // set by Run when telegraph.status_cache_ttl_sec > 0
 	redact         func(string) string
 	out            io.Writer
+	clock          clock.Clock
 }
 
 // DaemonOpts holds parameters for creating a new Daemon.
 type DaemonOpts struct {
-	DB             *gorm.DB
-	Config         *config.Config
-	Adapter        Adapter
-	Spawner        ProcessSpawner // optional; enables dispatch sessions
+	DB         *gorm.DB
+	Config     *config.Config
+	Adapter    Adapter
+	Spawner    ProcessSpawner // optional; enables dispatch sessions
+	AskSpawner ProcessSpawner // optional; enables `!ry ask` (should be read-only)
+	// SummarySpawner runs the read-only agent that summarizes long
+	// conversations on resume when telegraph.conversations.summarizer is
+	// "agent". Optional; without it conversations are truncated.
+	SummarySpawner ProcessSpawner
 	StatusProvider StatusProvider // optional; defaults to orchestration-based
 	// Redact strips secrets from dispatch subprocess I/O before it is written
 	// to agent_logs. Optional; defaults to a no-op. Wired to
 	// engine.RedactSecrets in the cmd layer to keep telegraph decoupled from
 	// internal/engine.
 	Redact func(string) string
-	Out    io.Writer // defaults to os.Stdout
+	Out    io.Writer   // defaults to os.Stdout
+	Clock  clock.Clock // drives polls, digests, and heartbeats; defaults to clock.Real
 }
 
 // NewDaemon creates a Daemon with the given options.
@@ -65,9 +76,12 @@ func NewDaemon(opts DaemonOpts) (*Daemon, error) {
 		cfg:            opts.Config,
 		adapter:        opts.Adapter,
 		spawner:        opts.Spawner,
+		askSpawner:     opts.AskSpawner,
+		summarySpawner: opts.SummarySpawner,
 		statusProvider: opts.StatusProvider,
 		redact:         opts.Redact,
 		out:            out,
+		clock:          clock.OrReal(opts.Clock),
 	}, nil
 }
 
@@ -87,6 +101,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 		return fmt.Errorf("telegraph: connect: %w", err)
 	}
 	hc := NewHealthChecker(time.Duration(d.cfg.Telegraph.Events.PollIntervalSec) * time.Second)
+	hc.SetClock(d.clock)
 	go func() {
 		if err := StartHealthServer(ctx, d.cfg.Telegraph.HealthPort, hc); err != nil {
 			log.Printf("telegraph: health server: %v", err)
@@ -121,11 +136,15 @@ func (d *Daemon) Run(ctx context.Context) error {
 		spawner = noopSpawner{}
 	}
 
-	// Resolve status provider.
+	// Resolve status provider. Commands, asks, and pulses share one cached
+	// read; dispatchEvents drops it when the watcher sees cars or engines
+	// change.
 	sp := d.statusProvider
 	if sp == nil {
 		sp = &defaultStatusProvider{db: d.db, tmux: nil}
 	}
+	sp = NewStatusCache(sp, time.Duration(d.cfg.Telegraph.StatusCacheTTLSec)*time.Second, d.clock)
+	d.statusCache, _ = sp.(*StatusCache)
 
 	// Build CommandHandler.
 	cmdHandler, err := NewCommandHandler(CommandHandlerOpts{
@@ -137,22 +156,91 @@ func (d *Daemon) Run(ctx context.Context) error {
 		return fmt.Errorf("telegraph: build command handler: %w", err)
 	}
 
+	// Build HistoryStore; with an archive configured, old conversation turns
+	// move out of the database and are read back on resume.
+	archive, err := NewHistoryArchive(d.cfg.Telegraph.Conversations.Archive)
+	if err != nil {
+		d.adapter.Close()
+		return fmt.Errorf("telegraph: build history archive: %w", err)
+	}
+	history, err := NewHistoryStore(HistoryStoreOpts{
+		DB:       d.db,
+		Archive:  archive,
+		KeepDays: d.cfg.Telegraph.Conversations.Archive.KeepDays,
+		Clock:    d.clock,
+	})
+	if err != nil {
+		d.adapter.Close()
+		return fmt.Errorf("telegraph: build history store: %w", err)
+	}
+
+	// Chat thread history read on resume is cached in the database and
+	// refreshed with only the messages posted since.
+	threadCache, err := NewThreadHistoryCache(ThreadHistoryCacheOpts{
+		DB:      d.db,
+		Adapter: d.adapter,
+		Clock:   d.clock,
+	})
+	if err != nil {
+		d.adapter.Close()
+		return fmt.Errorf("telegraph: build thread history cache: %w", err)
+	}
+
+	// Long conversations are summarized on resume; the agent summarizer
+	// needs its own read-only spawner.
+	conv := d.cfg.Telegraph.Conversations
+	summarizeAfter := conv.SummarizeAfterTurns
+	if summarizeAfter < 0 {
+		summarizeAfter = 0
+	}
+	var summarizer Summarizer
+	if conv.Summarizer == "agent" && d.summarySpawner != nil {
+		summarizer = &AgentSummarizer{Spawner: d.summarySpawner}
+	}
+
 	// Build SessionManager.
 	hbTimeout := time.Duration(d.cfg.Telegraph.DispatchLock.HeartbeatTimeoutSec) * time.Second
 	procTimeout := time.Duration(d.cfg.Telegraph.ProcessTimeoutSec) * time.Second
 	sessionMgr, err := NewSessionManager(SessionManagerOpts{
-		DB:               d.db,
-		Adapter:          d.adapter,
-		Spawner:          spawner,
-		HeartbeatTimeout: hbTimeout,
-		ProcessTimeout:   procTimeout,
-		Redact:           d.redact,
+		DB:                  d.db,
+		Adapter:             d.adapter,
+		Spawner:             spawner,
+		HeartbeatTimeout:    hbTimeout,
+		ProcessTimeout:      procTimeout,
+		Redact:              d.redact,
+		History:             history,
+		ThreadCache:         threadCache,
+		SummarizeAfterTurns: summarizeAfter,
+		Summarizer:          summarizer,
+		Clock:               d.clock,
 	})
 	if err != nil {
 		d.adapter.Close()
 		return fmt.Errorf("telegraph: build session manager: %w", err)
 	}
 
+	// Dispatch subprocesses recorded on this host belong to a previous
+	// telegraph that exited without cleaning up; none are ours yet.
+	if reaped, err := ReapOrphans(d.db, ReapOpts{Clock: d.clock}); err != nil {
+		log.Printf("telegraph: reap orphaned dispatch sessions: %v", err)
+	} else if len(reaped) > 0 {
+		fmt.Fprintf(d.out, "Telegraph reaped %d orphaned dispatch session(s)\n", len(reaped))
+	}
+
+	// Build Asker for `!ry ask` — read-only, so it needs no dispatch lock.
+	var asker *Asker
+	if d.askSpawner != nil {
+		asker, err = NewAsker(AskerOpts{
+			DB:             d.db,
+			Spawner:        d.askSpawner,
+			StatusProvider: sp,
+		})
+		if err != nil {
+			d.adapter.Close()
+			return fmt.Errorf("telegraph: build asker: %w", err)
+		}
+	}
+
 	// Build Router.
 	router, err := NewRouter(RouterOpts{
 		SessionMgr: sessionMgr,
@@ -160,12 +248,27 @@ func (d *Daemon) Run(ctx context.Context) error {
 		Adapter:    d.adapter,
 		BotUserID:  botUserID,
 		Out:        d.out,
+		Asker:      asker,
+		Limits: InboundLimits{
+			MaxChars:     d.cfg.Telegraph.Inbound.MaxMessageChars,
+			Reject:       d.cfg.Telegraph.Inbound.Oversize == "reject",
+			MaxPerMinute: d.cfg.Telegraph.Inbound.MaxPerMinute,
+		},
+		Clock: d.clock,
 	})
 	if err != nil {
 		d.adapter.Close()
 		return fmt.Errorf("telegraph: build router: %w", err)
 	}
 
+	// Background loops stop on runCtx at the start of shutdown. Dispatch turns
+	// and event sends use work, which outlives ctx so in-flight output can be
+	// relayed while draining.
+	runCtx, stopRun := context.WithCancel(ctx)
+	defer stopRun()
+	work, stopWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWork()
+
 	// Start listening for inbound messages.
 	inbound, err := d.adapter.Listen(ctx)
 	if err != nil {
@@ -179,19 +282,31 @@ func (d *Daemon) Run(ctx context.Context) error {
 		DB:             d.db,
 		StatusProvider: sp,
 		PollInterval:   pollInterval,
-		OnPoll:         func() { hc.SetLastPoll(time.Now()) },
+		DigestByOwner:  d.cfg.Telegraph.Digest.GroupByOwner,
+		TrackTypes:     digestTrackTypes(d.cfg),
+		OnPoll:         func() { hc.SetLastPoll(d.clock.Now()) },
+		Clock:          d.clock,
 	})
 	if err != nil {
 		d.adapter.Close()
 		return fmt.Errorf("telegraph: build watcher: %w", err)
 	}
-	eventsCh := watcher.Run(ctx)
+	eventsCh := watcher.Run(runCtx)
 
 	// Start event dispatcher goroutine.
-	go d.dispatchEvents(ctx, eventsCh)
+	eventsDone := make(chan struct{})
+	go func() {
+		defer close(eventsDone)
+		d.dispatchEvents(work, eventsCh)
+	}()
 
 	// Start digest scheduler goroutine.
-	go d.runDigestScheduler(ctx, watcher)
+	go d.runDigestScheduler(runCtx, watcher)
+
+	// Start history archiver goroutine.
+	if archive != nil {
+		go d.runHistoryArchiver(runCtx, history)
+	}
 
 	fmt.Fprintf(d.out, "Telegraph online\n")
 
@@ -208,11 +323,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			hc.SetConnected(false)
 			fmt.Fprintf(d.out, "Telegraph shutting down...\n")
-			d.sendShutdown()
-			if err := d.adapter.Close(); err != nil {
-				log.Printf("telegraph: close adapter: %v", err)
-			}
-			fmt.Fprintf(d.out, "Telegraph stopped\n")
+			d.shutdown(work, inbound, stopRun, router, sessionMgr, eventsDone)
 			return nil
 
 		case msg, ok := <-inbound:
@@ -221,20 +332,106 @@ func (d *Daemon) Run(ctx context.Context) error {
 				hc.SetConnected(false)
 				fmt.Fprintf(d.out, "Telegraph inbound channel closed\n")
 				fmt.Fprintf(d.out, "Telegraph shutting down...\n")
-				d.sendShutdown() // best-effort; may fail if adapter already disconnected
-				if err := d.adapter.Close(); err != nil {
-					log.Printf("telegraph: close adapter: %v", err)
-				}
-				fmt.Fprintf(d.out, "Telegraph stopped\n")
+				d.shutdown(work, nil, stopRun, router, sessionMgr, eventsDone)
 				return nil
 			}
-			router.Handle(ctx, msg)
+			router.Handle(work, msg)
+		}
+	}
+}
+
+// defaultShutdownTimeout bounds the shutdown drain when
+// telegraph.shutdown_timeout_sec is unset.
+const defaultShutdownTimeout = 60 * time.Second
+
+// shutdownKillWait bounds how long shutdown waits for relays to post the
+// last output of sessions it had to stop.
+const shutdownKillWait = 10 * time.Second
+
+// shutdown stops the daemon without dropping a dispatch turn mid-reply: it
+// stops accepting inbound messages, waits up to the configured shutdown
+// timeout for in-flight turns and `!ry ask` answers to finish relaying,
+// stops whatever is still running and releases its dispatch lock, flushes
+// queued events, and posts a going-offline notice before closing the
+// adapter. A nil inbound means the adapter already closed it.
+func (d *Daemon) shutdown(ctx context.Context, inbound <-chan InboundMessage, stopRun context.CancelFunc, router *Router, sessionMgr *SessionManager, eventsDone <-chan struct{}) {
+	// Stop accepting new messages. Keep reading so the adapter never blocks
+	// on a full channel while we drain.
+	if inbound != nil {
+		go func() {
+			for msg := range inbound {
+				log.Printf("telegraph: shutting down, dropped message from %s in %s", msg.UserName, msg.ChannelID)
+			}
+		}()
+	}
+	// Stop the watcher, digest scheduler, and archiver. The watcher closes
+	// its events channel, which lets dispatchEvents flush what is queued.
+	stopRun()
+
+	timeout := time.Duration(d.cfg.Telegraph.ShutdownTimeoutSec) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	if n := sessionMgr.ActiveCount(); n > 0 {
+		fmt.Fprintf(d.out, "Telegraph draining %d dispatch session(s) (up to %v)...\n", n, timeout)
+	}
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := router.drain(drainCtx); err != nil {
+		log.Printf("telegraph: shutdown: ask answers still running after %v", timeout)
+	}
+	if err := sessionMgr.Drain(drainCtx); err != nil {
+		if n := sessionMgr.Shutdown(shutdownKillWait); n > 0 {
+			fmt.Fprintf(d.out, "Telegraph stopped %d dispatch session(s) still running after %v\n", n, timeout)
+		}
+	}
+
+	select {
+	case <-eventsDone:
+	case <-drainCtx.Done():
+		log.Printf("telegraph: shutdown: events still queued after %v", timeout)
+	}
+
+	d.sendShutdown(ctx) // best-effort; may fail if adapter already disconnected
+	if err := d.adapter.Close(); err != nil {
+		log.Printf("telegraph: close adapter: %v", err)
+	}
+	fmt.Fprintf(d.out, "Telegraph stopped\n")
+}
+
+// historyArchiveInterval is how often the daemon moves old conversation
+// turns to the history archive.
+const historyArchiveInterval = 6 * time.Hour
+
+// runHistoryArchiver archives old conversation turns at startup and then
+// every historyArchiveInterval until ctx is cancelled.
+func (d *Daemon) runHistoryArchiver(ctx context.Context, history *HistoryStore) {
+	archive := func() {
+		res, err := history.Archive(ctx)
+		if err != nil {
+			log.Printf("telegraph: archive history: %v", err)
+		}
+		if res.Sessions > 0 {
+			fmt.Fprintf(d.out, "Telegraph archived %d turn(s) from %d session(s)\n", res.Turns, res.Sessions)
+		}
+	}
+	archive()
+	ticker := d.clock.NewTicker(historyArchiveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			archive()
 		}
 	}
 }
 
 // dispatchEvents reads detected events from the watcher channel, filters
-// them by config toggles, formats them, and sends to the chat platform.
+// them by config toggles, formats them, and sends to the chat platform. It
+// returns once the watcher closes the channel, after sending everything it
+// had queued, or when ctx is cancelled.
 func (d *Daemon) dispatchEvents(ctx context.Context, eventsCh <-chan DetectedEvent) {
 	evtCfg := d.cfg.Telegraph.Events
 	for {
@@ -245,38 +442,39 @@ func (d *Daemon) dispatchEvents(ctx context.Context, eventsCh <-chan DetectedEve
 			if !ok {
 				return
 			}
+			if d.statusCache != nil && (event.Type == EventCarStatusChange || event.Type == EventEngineStalled || event.Type == EventYardPause) {
+				d.statusCache.Invalidate()
+			}
 			d.handleDetectedEvent(ctx, event, evtCfg)
 		}
 	}
 }
 
 // handleDetectedEvent processes a single detected event: applies config
-// filters, formats, and sends via the adapter.
+// filters, formats, DMs subscribed users, and sends via the adapter.
 func (d *Daemon) handleDetectedEvent(ctx context.Context, event DetectedEvent, evtCfg config.EventsConfig) {
 	var formatted FormattedEvent
 	dashURL := d.cfg.DashboardURL
+	enabled := true
 
 	switch event.Type {
 	case EventCarStatusChange:
-		if !evtCfg.CarLifecycle {
-			return
-		}
+		enabled = evtCfg.CarLifecycle
 		formatted = FormatCarEvent(event, dashURL)
 	case EventEngineStalled:
-		if !evtCfg.EngineStalls {
-			return
-		}
+		enabled = evtCfg.EngineStalls
 		formatted = FormatStallEvent(event, dashURL)
 	case EventEscalation:
-		if !evtCfg.Escalations {
-			// Suppressed by config — mark consumed so the watcher does not
-			// re-detect it on every poll.
-			if err := MarkEscalationDelivered(d.db, event); err != nil {
-				log.Printf("telegraph: mark suppressed escalation %d: %v", event.MessageID, err)
-			}
-			return
-		}
+		enabled = evtCfg.Escalations
 		formatted = FormatEscalation(event, dashURL)
+	case EventYardPause:
+		// Pause changes are not gated by event toggles: they matter most
+		// during an incident.
+		formatted = FormatYardPauseEvent(event)
+	case EventEpicRollup:
+		// Like pulses, rollups are only sent for epics an operator
+		// dispatched, so they are not gated by event toggles.
+		formatted = FormatEpicRollup(event, dashURL)
 	case EventPulse, EventDailyDigest, EventWeeklyDigest:
 		// Pulse and digest events are not gated by event toggles.
 		formatted = FormattedEvent{
@@ -284,14 +482,36 @@ func (d *Daemon) handleDetectedEvent(ctx context.Context, event DetectedEvent, e
 			Body:     event.Body,
 			Severity: "info",
 			Color:    ColorInfo,
+			Fields:   event.Fields,
 		}
 	default:
 		return
 	}
 
-	if err := d.adapter.Send(ctx, OutboundMessage{
-		Events: []FormattedEvent{formatted},
-	}); err != nil {
+	// Personal subscriptions are honored even when the channel toggle is
+	// off. A redelivered escalation may DM its subscribers again.
+	d.notifySubscribers(ctx, event, formatted)
+
+	if !enabled {
+		if event.Type == EventEscalation {
+			// Suppressed by config — mark consumed so the watcher does not
+			// re-detect it on every poll.
+			if err := MarkEscalationDelivered(d.db, event); err != nil {
+				log.Printf("telegraph: mark suppressed escalation %d: %v", event.MessageID, err)
+			}
+		}
+		return
+	}
+
+	var err error
+	if m, ok := d.adapter.(*MultiAdapter); ok && (event.Type == EventCarStatusChange || event.Type == EventEpicRollup) {
+		err = d.sendCarThreads(ctx, m, event, formatted)
+	} else {
+		err = d.adapter.Send(ctx, OutboundMessage{
+			Events: []FormattedEvent{formatted},
+		})
+	}
+	if err != nil {
 		// Escalations are intentionally NOT marked delivered on failure: the
 		// watcher re-detects them next poll (at-least-once, railyard-05m).
 		log.Printf("telegraph: send event %s: %v", event.Type, err)
@@ -315,45 +535,39 @@ func (d *Daemon) runDigestScheduler(ctx context.Context, watcher *Watcher) {
 		return
 	}
 
-	var dailyTimer, weeklyTimer *time.Timer
+	// A nil channel blocks forever, which disables that digest.
+	var dailyC, weeklyC <-chan time.Time
 	if dailyCfg.Enabled && dailyCfg.Cron != "" {
-		if d := nextCronDuration(dailyCfg.Cron); d > 0 {
-			dailyTimer = time.NewTimer(d)
-		}
+		dailyC = d.nextCronTick(dailyCfg.Cron)
 	}
 	if weeklyCfg.Enabled && weeklyCfg.Cron != "" {
-		if d := nextCronDuration(weeklyCfg.Cron); d > 0 {
-			weeklyTimer = time.NewTimer(d)
-		}
+		weeklyC = d.nextCronTick(weeklyCfg.Cron)
 	}
 
-	defer func() {
-		if dailyTimer != nil {
-			dailyTimer.Stop()
-		}
-		if weeklyTimer != nil {
-			weeklyTimer.Stop()
-		}
-	}()
-
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-timerChan(dailyTimer):
+		case <-dailyC:
 			d.fireDigest(ctx, watcher, "daily")
-			if d := nextCronDuration(dailyCfg.Cron); d > 0 {
-				dailyTimer.Reset(d)
-			}
-		case <-timerChan(weeklyTimer):
+			dailyC = d.nextCronTick(dailyCfg.Cron)
+		case <-weeklyC:
 			d.fireDigest(ctx, watcher, "weekly")
-			if d := nextCronDuration(weeklyCfg.Cron); d > 0 {
-				weeklyTimer.Reset(d)
-			}
+			weeklyC = d.nextCronTick(weeklyCfg.Cron)
 		}
 	}
 }
 
+// nextCronTick returns a channel that fires at the next time matching expr,
+// or nil (never fires) when expr is invalid.
+func (d *Daemon) nextCronTick(expr string) <-chan time.Time {
+	dur := nextCronDuration(d.clock.Now(), expr)
+	if dur <= 0 {
+		return nil
+	}
+	return d.clock.After(dur)
+}
+
 // fireDigest builds and sends a single digest (daily or weekly).
 func (d *Daemon) fireDigest(ctx context.Context, watcher *Watcher, kind string) {
 	var event *DetectedEvent
@@ -379,6 +593,7 @@ func (d *Daemon) fireDigest(ctx context.Context, watcher *Watcher, kind string)
 		Body:     event.Body,
 		Severity: "info",
 		Color:    ColorInfo,
+		Fields:   event.Fields,
 	}
 	if err := d.adapter.Send(ctx, OutboundMessage{
 		Events: []FormattedEvent{formatted},
@@ -387,21 +602,25 @@ func (d *Daemon) fireDigest(ctx context.Context, watcher *Watcher, kind string)
 	}
 }
 
-// timerChan returns the timer's channel, or nil if the timer is nil.
-// A nil channel blocks forever in select, which is the desired behavior
-// when a digest type is not enabled.
-func timerChan(t *time.Timer) <-chan time.Time {
-	if t == nil {
-		return nil
-	}
-	return t.C
+// platforms returns the chat platforms the daemon posts to: every platform
+// behind a MultiAdapter, or the configured one.
+func (d *Daemon) platforms() []PlatformAdapter {
+	if m, ok := d.adapter.(*MultiAdapter); ok {
+		return m.Platforms()
+	}
+	return []PlatformAdapter{{
+		Platform: d.cfg.Telegraph.Platform,
+		Channel:  d.cfg.Telegraph.Channel,
+		Adapter:  d.adapter,
+	}}
 }
 
-// sendShutdown posts a shutdown message to the adapter (best-effort).
-func (d *Daemon) sendShutdown() {
-	ctx := context.Background()
+// sendShutdown posts the going-offline notice to the adapter (best-effort).
+func (d *Daemon) sendShutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 	if err := d.adapter.Send(ctx, OutboundMessage{
-		Text: "Telegraph shutting down",
+		Text: "Telegraph going offline",
 	}); err != nil {
 		log.Printf("telegraph: send shutdown message: %v", err)
 	}
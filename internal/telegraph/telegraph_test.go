@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
@@ -200,8 +201,8 @@ func TestRun_ConnectsAndShutdown(t *testing.T) {
 	if !ok {
 		t.Fatal("expected shutdown message")
 	}
-	if last.Text != "Telegraph shutting down" {
-		t.Errorf("last message = %q, want %q", last.Text, "Telegraph shutting down")
+	if last.Text != "Telegraph going offline" {
+		t.Errorf("last message = %q, want %q", last.Text, "Telegraph going offline")
 	}
 }
 
@@ -301,8 +302,8 @@ func TestRun_InboundClosedSendsShutdown(t *testing.T) {
 	if !ok {
 		t.Fatal("expected shutdown message")
 	}
-	if last.Text != "Telegraph shutting down" {
-		t.Errorf("last message = %q, want %q", last.Text, "Telegraph shutting down")
+	if last.Text != "Telegraph going offline" {
+		t.Errorf("last message = %q, want %q", last.Text, "Telegraph going offline")
 	}
 
 	output := buf.String()
@@ -321,6 +322,71 @@ func TestRun_InboundClosedSendsShutdown(t *testing.T) {
 // Inbound routing tests
 // ---------------------------------------------------------------------------
 
+func TestRun_ShutdownDrainsInFlightDispatch(t *testing.T) {
+	mock := NewMockAdapter()
+	mock.SetBotUserID("9900112233")
+	buf := &syncBuffer{}
+	db := openTestDB(t)
+	spawner := &mockSpawner{}
+
+	d, err := NewDaemon(DaemonOpts{
+		DB:             db,
+		Config:         testCfg(),
+		Adapter:        mock,
+		Spawner:        spawner,
+		StatusProvider: &nullStatusProvider{},
+		Out:            buf,
+	})
+	if err != nil {
+		t.Fatalf("NewDaemon: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+	waitFor(t, func() bool {
+		return strings.Contains(buf.String(), "Telegraph online")
+	}, 2*time.Second)
+
+	mock.SimulateInbound(InboundMessage{
+		Platform:  "test",
+		ChannelID: "C123",
+		MessageID: "M1",
+		UserName:  "bob",
+		Text:      "<@9900112233> create a bug ticket",
+	})
+	waitFor(t, func() bool { return spawner.lastProcess() != nil }, 2*time.Second)
+
+	// SIGTERM arrives mid-turn: the daemon must wait for the reply.
+	cancel()
+	waitFor(t, func() bool {
+		return strings.Contains(buf.String(), "Telegraph draining 1 dispatch session(s)")
+	}, 2*time.Second)
+	p := spawner.lastProcess()
+	p.recvCh <- "Created car-001"
+	close(p.recvCh)
+	p.exitWith(nil)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+
+	all := mock.AllSent()
+	if len(all) < 2 || all[len(all)-2].Text != "Created car-001" || all[len(all)-1].Text != "Telegraph going offline" {
+		t.Errorf("sent = %+v, want the relayed reply followed by the offline notice", all)
+	}
+	var s models.DispatchSession
+	db.First(&s)
+	if s.Status != "completed" {
+		t.Errorf("dispatch session status = %q, want completed", s.Status)
+	}
+}
+
 func TestRun_InboundRoutedToRouter(t *testing.T) {
 	mock := NewMockAdapter()
 	buf := &syncBuffer{}
@@ -658,6 +724,48 @@ func TestRunDigestScheduler_NeitherEnabled(t *testing.T) {
 	}
 }
 
+func TestRunDigestScheduler_FiresOnCronWithFakeClock(t *testing.T) {
+	start := time.Date(2026, 3, 2, 8, 59, 0, 0, time.Local)
+	fc := clock.NewFake(start)
+
+	cfg := testCfg()
+	cfg.Telegraph.Digest.Daily.Enabled = true
+	cfg.Telegraph.Digest.Daily.Cron = "0 9 * * *"
+	cfg.Telegraph.Digest.Weekly.Enabled = false
+
+	db := openDigestTestDB(t)
+	recent := start.Add(-time.Hour)
+	db.Create(&models.Car{ID: "car-1", Title: "Done car", Status: "done", Track: "backend",
+		CompletedAt: ptr(recent), CreatedAt: recent})
+
+	watcher, err := NewWatcher(WatcherOpts{DB: db, StatusProvider: &nullStatusProvider{}, Clock: fc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := NewMockAdapter()
+	mock.Connect(context.Background())
+	d := &Daemon{cfg: cfg, adapter: mock, out: &bytes.Buffer{}, clock: fc}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.runDigestScheduler(ctx, watcher)
+
+	if !fc.BlockUntil(1, time.Second) {
+		t.Fatal("scheduler did not arm the daily timer")
+	}
+	if mock.SentCount() != 0 {
+		t.Fatalf("digest sent before cron time: %d messages", mock.SentCount())
+	}
+
+	fc.Advance(time.Minute)
+	waitFor(t, func() bool { return mock.SentCount() == 1 }, time.Second)
+
+	// The scheduler re-arms for the next day after firing.
+	if !fc.BlockUntil(1, time.Second) {
+		t.Fatal("scheduler did not re-arm after firing")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // helpers
 // ---------------------------------------------------------------------------
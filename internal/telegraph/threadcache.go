@@ -0,0 +1,189 @@
+package telegraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultThreadCacheMaxAge is how long a cached thread is refreshed
+// incrementally before it is fetched again in full, picking up messages
+// edited or deleted since.
+const DefaultThreadCacheMaxAge = 24 * time.Hour
+
+// threadCacheMaxMessages caps the messages kept per thread; older ones are
+// dropped from the cache as new ones arrive.
+const threadCacheMaxMessages = 500
+
+// ThreadHistoryCache reads chat thread history through a database cache, so
+// resuming a dispatch session does not fetch the whole thread from Slack or
+// Discord every time. The first read fetches the thread and stores it keyed
+// by channel and thread along with the newest message ID; later reads ask
+// the platform only for messages after that ID (through
+// ThreadHistorySincer when the adapter implements it) and append them.
+//
+// When the platform cannot be reached the cached copy is returned as is.
+// When the cache table cannot be read the adapter is used directly.
+type ThreadHistoryCache struct {
+	db      *gorm.DB
+	adapter Adapter
+	maxAge  time.Duration
+	clock   clock.Clock
+}
+
+// ThreadHistoryCacheOpts holds parameters for creating a ThreadHistoryCache.
+type ThreadHistoryCacheOpts struct {
+	DB      *gorm.DB
+	Adapter Adapter
+	MaxAge  time.Duration // defaults to DefaultThreadCacheMaxAge
+	Clock   clock.Clock   // defaults to clock.Real
+}
+
+// NewThreadHistoryCache creates a ThreadHistoryCache.
+func NewThreadHistoryCache(opts ThreadHistoryCacheOpts) (*ThreadHistoryCache, error) {
+	if opts.DB == nil {
+		return nil, fmt.Errorf("telegraph: thread history cache: db is required")
+	}
+	if opts.Adapter == nil {
+		return nil, fmt.Errorf("telegraph: thread history cache: adapter is required")
+	}
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultThreadCacheMaxAge
+	}
+	return &ThreadHistoryCache{db: opts.DB, adapter: opts.Adapter, maxAge: maxAge, clock: clock.OrReal(opts.Clock)}, nil
+}
+
+// ThreadHistory returns up to the newest limit messages of the thread,
+// oldest first.
+func (c *ThreadHistoryCache) ThreadHistory(ctx context.Context, channelID, threadID string, limit int) ([]ThreadMessage, error) {
+	var row models.ThreadHistoryCache
+	err := c.db.Where("channel_id = ? AND thread_id = ?", channelID, threadID).First(&row).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("telegraph: thread history cache %s/%s: %v", channelID, threadID, err)
+		return c.adapter.ThreadHistory(ctx, channelID, threadID, limit)
+	}
+
+	if err == nil && row.LastMessageID != "" && c.clock.Since(row.FetchedAt) < c.maxAge {
+		var cached []ThreadMessage
+		if err := json.Unmarshal([]byte(row.Messages), &cached); err == nil {
+			newer, err := threadHistorySince(ctx, c.adapter, channelID, threadID, row.LastMessageID, threadCacheMaxMessages)
+			if err != nil {
+				log.Printf("telegraph: thread history %s/%s: refresh failed, using cache: %v", channelID, threadID, err)
+				return lastMessages(cached, limit), nil
+			}
+			if len(newer) == 0 {
+				return lastMessages(cached, limit), nil
+			}
+			msgs := mergeThreadMessages(cached, newer)
+			c.store(channelID, threadID, msgs, row.FetchedAt)
+			return lastMessages(msgs, limit), nil
+		}
+	}
+
+	msgs, err := c.adapter.ThreadHistory(ctx, channelID, threadID, limit)
+	if err != nil {
+		return nil, err
+	}
+	msgs = mergeThreadMessages(nil, msgs)
+	c.store(channelID, threadID, msgs, c.clock.Now())
+	return lastMessages(msgs, limit), nil
+}
+
+// Invalidate drops the cached copy of a thread, so the next read fetches
+// it in full.
+func (c *ThreadHistoryCache) Invalidate(channelID, threadID string) error {
+	err := c.db.Where("channel_id = ? AND thread_id = ?", channelID, threadID).
+		Delete(&models.ThreadHistoryCache{}).Error
+	if err != nil {
+		return fmt.Errorf("telegraph: invalidate thread history %s/%s: %w", channelID, threadID, err)
+	}
+	return nil
+}
+
+// store writes msgs as the cached copy of the thread. Failures are logged;
+// the next read fetches the thread again.
+func (c *ThreadHistoryCache) store(channelID, threadID string, msgs []ThreadMessage, fetchedAt time.Time) {
+	msgs = lastMessages(msgs, threadCacheMaxMessages)
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		log.Printf("telegraph: thread history cache %s/%s: %v", channelID, threadID, err)
+		return
+	}
+	row := models.ThreadHistoryCache{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		Messages:  string(data),
+		FetchedAt: fetchedAt,
+	}
+	if len(msgs) > 0 {
+		row.LastMessageID = msgs[len(msgs)-1].MessageID
+	}
+	err = c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}, {Name: "thread_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_message_id", "messages", "fetched_at", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		log.Printf("telegraph: thread history cache %s/%s: store: %v", channelID, threadID, err)
+	}
+}
+
+// threadHistorySince returns the messages after afterMessageID, through
+// ThreadHistorySincer when a implements it and otherwise by reading the
+// whole thread and keeping what follows that message.
+func threadHistorySince(ctx context.Context, a Adapter, channelID, threadID, afterMessageID string, limit int) ([]ThreadMessage, error) {
+	if s, ok := a.(ThreadHistorySincer); ok {
+		return s.ThreadHistorySince(ctx, channelID, threadID, afterMessageID, limit)
+	}
+	msgs, err := a.ThreadHistory(ctx, channelID, threadID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return lastMessages(messagesAfter(mergeThreadMessages(nil, msgs), afterMessageID), limit), nil
+}
+
+// messagesAfter returns the messages following the one with ID
+// afterMessageID, or all of them when it is not among msgs.
+func messagesAfter(msgs []ThreadMessage, afterMessageID string) []ThreadMessage {
+	for i, m := range msgs {
+		if m.MessageID == afterMessageID {
+			return msgs[i+1:]
+		}
+	}
+	return msgs
+}
+
+// mergeThreadMessages appends newer to cached, skipping messages already
+// present, and orders the result oldest first.
+func mergeThreadMessages(cached, newer []ThreadMessage) []ThreadMessage {
+	seen := make(map[string]bool, len(cached))
+	out := make([]ThreadMessage, 0, len(cached)+len(newer))
+	for _, m := range append(cached[:len(cached):len(cached)], newer...) {
+		if m.MessageID != "" {
+			if seen[m.MessageID] {
+				continue
+			}
+			seen[m.MessageID] = true
+		}
+		out = append(out, m)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out
+}
+
+// lastMessages returns the newest limit messages; limit <= 0 means all.
+func lastMessages(msgs []ThreadMessage, limit int) []ThreadMessage {
+	if limit > 0 && len(msgs) > limit {
+		return msgs[len(msgs)-limit:]
+	}
+	return msgs
+}
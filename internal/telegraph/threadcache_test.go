@@ -0,0 +1,159 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func threadMsgs(base time.Time, ids ...int) []ThreadMessage {
+	var msgs []ThreadMessage
+	for _, id := range ids {
+		msgs = append(msgs, ThreadMessage{
+			MessageID: fmt.Sprintf("m%d", id),
+			UserName:  "alice",
+			Text:      fmt.Sprintf("message %d", id),
+			Timestamp: base.Add(time.Duration(id) * time.Second),
+		})
+	}
+	return msgs
+}
+
+func newTestThreadCache(t *testing.T) (*ThreadHistoryCache, *MockAdapter, *clock.Fake) {
+	t.Helper()
+	db := openLockTestDB(t)
+	if err := db.AutoMigrate(&models.ThreadHistoryCache{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	adapter := NewMockAdapter()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	c, err := NewThreadHistoryCache(ThreadHistoryCacheOpts{DB: db, Adapter: adapter, Clock: clk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, adapter, clk
+}
+
+func messageIDs(msgs []ThreadMessage) string {
+	var s string
+	for _, m := range msgs {
+		s += m.MessageID + " "
+	}
+	return s
+}
+
+func TestThreadHistoryCache_RefreshesIncrementally(t *testing.T) {
+	c, adapter, clk := newTestThreadCache(t)
+	ctx := context.Background()
+	base := clk.Now()
+
+	adapter.SetThreadHistory("C1", "T1", threadMsgs(base, 1, 2))
+	msgs, err := c.ThreadHistory(ctx, "C1", "T1", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := messageIDs(msgs); got != "m1 m2 " {
+		t.Fatalf("first read = %q", got)
+	}
+
+	// Nothing new: served from the cache after one incremental check.
+	if msgs, _ = c.ThreadHistory(ctx, "C1", "T1", 50); messageIDs(msgs) != "m1 m2 " {
+		t.Fatalf("second read = %q", messageIDs(msgs))
+	}
+
+	adapter.SetThreadHistory("C1", "T1", threadMsgs(base, 1, 2, 3, 4))
+	msgs, _ = c.ThreadHistory(ctx, "C1", "T1", 3)
+	if got := messageIDs(msgs); got != "m2 m3 m4 " {
+		t.Fatalf("after new messages = %q, want the newest 3", got)
+	}
+
+	full, since := adapter.HistoryCalls()
+	if full != 1 || since != 2 {
+		t.Errorf("adapter calls: full=%d since=%d, want 1 full fetch then incremental", full, since)
+	}
+
+	var row models.ThreadHistoryCache
+	c.db.Where("channel_id = ? AND thread_id = ?", "C1", "T1").First(&row)
+	if row.LastMessageID != "m4" {
+		t.Errorf("LastMessageID = %q, want m4", row.LastMessageID)
+	}
+}
+
+func TestThreadHistoryCache_RefetchesWhenOldOrInvalidated(t *testing.T) {
+	c, adapter, clk := newTestThreadCache(t)
+	ctx := context.Background()
+	base := clk.Now()
+
+	adapter.SetThreadHistory("C1", "T1", threadMsgs(base, 1, 2))
+	c.ThreadHistory(ctx, "C1", "T1", 50)
+
+	// An edit the incremental refresh cannot see.
+	edited := threadMsgs(base, 1, 2)
+	edited[0].Text = "edited"
+	adapter.SetThreadHistory("C1", "T1", edited)
+
+	clk.Advance(DefaultThreadCacheMaxAge)
+	msgs, _ := c.ThreadHistory(ctx, "C1", "T1", 50)
+	if msgs[0].Text != "edited" {
+		t.Errorf("after max age text = %q, want the full refetch", msgs[0].Text)
+	}
+
+	if err := c.Invalidate("C1", "T1"); err != nil {
+		t.Fatal(err)
+	}
+	c.ThreadHistory(ctx, "C1", "T1", 50)
+	if full, _ := adapter.HistoryCalls(); full != 3 {
+		t.Errorf("full fetches = %d, want 3", full)
+	}
+}
+
+func TestThreadHistoryCache_ServesCacheWhenPlatformFails(t *testing.T) {
+	c, adapter, clk := newTestThreadCache(t)
+	ctx := context.Background()
+	adapter.SetThreadHistory("C1", "T1", threadMsgs(clk.Now(), 1))
+	c.ThreadHistory(ctx, "C1", "T1", 50)
+
+	c.adapter = failingHistoryAdapter{adapter}
+	msgs, err := c.ThreadHistory(ctx, "C1", "T1", 50)
+	if err != nil || messageIDs(msgs) != "m1 " {
+		t.Errorf("msgs = %q, err = %v, want the cached copy", messageIDs(msgs), err)
+	}
+}
+
+// failingHistoryAdapter fails every incremental history read.
+type failingHistoryAdapter struct{ *MockAdapter }
+
+func (failingHistoryAdapter) ThreadHistorySince(context.Context, string, string, string, int) ([]ThreadMessage, error) {
+	return nil, fmt.Errorf("rate limited")
+}
+
+func TestSessionManager_ResumeUsesThreadCache(t *testing.T) {
+	c, adapter, clk := newTestThreadCache(t)
+	adapter.SetThreadHistory("C1", "T1", threadMsgs(clk.Now(), 1, 2))
+	sm, err := NewSessionManager(SessionManagerOpts{
+		DB:          c.db,
+		Adapter:     adapter,
+		Spawner:     &mockSpawner{},
+		ThreadCache: c,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		prompt, err := sm.buildRecoveryContext(context.Background(), "C1", "T1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prompt == "" {
+			t.Fatal("empty recovery prompt")
+		}
+	}
+	if full, _ := adapter.HistoryCalls(); full != 1 {
+		t.Errorf("full thread fetches = %d, want 1", full)
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
@@ -26,7 +28,7 @@ type defaultStatusProvider struct {
 }
 
 func (p *defaultStatusProvider) Status() (*orchestration.StatusInfo, error) {
-	return orchestration.Status(p.db, p.tmux, p.cfg)
+	return orchestration.Status(orchestration.StatusOpts{DB: p.db, Tmux: p.tmux, Config: p.cfg})
 }
 
 // Default watcher intervals.
@@ -43,6 +45,8 @@ const (
 	EventEngineStalled   EventType = "engine_stalled"
 	EventEscalation      EventType = "escalation"
 	EventPulse           EventType = "pulse"
+	EventYardPause       EventType = "yard_pause"
+	EventEpicRollup      EventType = "epic_rollup"
 )
 
 // DetectedEvent is a raw event detected by the watcher before formatting.
@@ -56,6 +60,11 @@ type DetectedEvent struct {
 	NewStatus string
 	Track     string
 	Title     string // car title
+	Owner     string // car's human owner, if any (car and escalation events)
+	CarStatus string // car's current status (escalation events)
+	// BlockedReason is why the car is blocked when NewStatus is "blocked"
+	// (models.BlockedReason*; "" for a dependency block).
+	BlockedReason string
 
 	// Stall events
 	EngineID   string
@@ -68,6 +77,13 @@ type DetectedEvent struct {
 	Subject   string
 	Body      string
 	Priority  string
+
+	// Digest events
+	Fields []Field // structured metrics rendered alongside Body
+
+	// Yard pause events: Paused is the new state, Body the pause reason.
+	Paused   bool
+	PausedBy string
 }
 
 // carSnapshot holds the last-known status of each car for change detection.
@@ -75,6 +91,7 @@ type carSnapshot struct {
 	Status string
 	Track  string
 	Title  string
+	Owner  string
 }
 
 // pulseDigest holds a snapshot of orchestration status for comparison.
@@ -96,7 +113,11 @@ type Watcher struct {
 	pollInterval   time.Duration
 	pulseInterval  time.Duration
 	dashboardURL   string
+	digestByOwner  bool
+	trackTypes     map[string]string
+	skipEscalation bool
 	onPoll         func() // optional; called after each successful poll
+	clock          clock.Clock
 
 	mu            sync.Mutex
 	snapshot      map[string]carSnapshot // carID -> last-known state
@@ -104,6 +125,9 @@ type Watcher struct {
 	seeded        bool                   // true after first poll (baseline established)
 	lastDigest    *pulseDigest           // last emitted pulse for comparison
 	lastPulseAt   time.Time              // when the last pulse was emitted
+	pauseSeeded   bool                   // true once the pause state baseline is read
+	lastPause     yard.PauseState        // pause state at the last poll
+	epicRollups   map[string]string      // dispatched epic ID -> body of its last rollup
 }
 
 // WatcherOpts holds parameters for creating a Watcher.
@@ -113,7 +137,15 @@ type WatcherOpts struct {
 	PollInterval   time.Duration  // defaults to DefaultPollInterval
 	PulseInterval  time.Duration  // defaults to DefaultPulseInterval
 	DashboardURL   string         // optional; used for links in formatted events
-	OnPoll         func()         // optional; called after each successful poll
+	DigestByOwner  bool           // add a per-owner section to daily/weekly digests
+	// TrackTypes maps track names to tracks[].type; digests group docs and
+	// config tracks into one entry per type.
+	TrackTypes map[string]string
+	// SkipEscalations leaves escalation messages out of Poll, for consumers
+	// other than telegraph, which tracks their delivery.
+	SkipEscalations bool
+	OnPoll          func()      // optional; called after each successful poll
+	Clock           clock.Clock // defaults to clock.Real
 }
 
 // NewWatcher creates a Watcher.
@@ -139,9 +171,14 @@ func NewWatcher(opts WatcherOpts) (*Watcher, error) {
 		pollInterval:   poll,
 		pulseInterval:  pulse,
 		dashboardURL:   opts.DashboardURL,
+		digestByOwner:  opts.DigestByOwner,
+		trackTypes:     opts.TrackTypes,
+		skipEscalation: opts.SkipEscalations,
 		onPoll:         opts.OnPoll,
+		clock:          clock.OrReal(opts.Clock),
 		snapshot:       make(map[string]carSnapshot),
 		stallSnapshot:  make(map[string]bool),
+		epicRollups:    make(map[string]string),
 	}, nil
 }
 
@@ -162,25 +199,57 @@ func (w *Watcher) Poll(ctx context.Context) ([]DetectedEvent, error) {
 	}
 	allEvents = append(allEvents, stallEvents...)
 
-	escalations, err := w.detectEscalations()
-	if err != nil {
-		return nil, fmt.Errorf("telegraph: watcher: escalation events: %w", err)
+	if !w.skipEscalation {
+		escalations, err := w.detectEscalations()
+		if err != nil {
+			return nil, fmt.Errorf("telegraph: watcher: escalation events: %w", err)
+		}
+		allEvents = append(allEvents, escalations...)
+	}
+
+	if ev := w.detectPauseChange(); ev != nil {
+		allEvents = append(allEvents, *ev)
 	}
-	allEvents = append(allEvents, escalations...)
 
 	return allEvents, nil
 }
 
+// detectPauseChange emits an event when the yard is paused or resumed. The
+// first call records the baseline without emitting, like car events. A
+// failed read is skipped; the next poll catches the change.
+func (w *Watcher) detectPauseChange() *DetectedEvent {
+	st, err := yard.GetPause(w.db)
+	if err != nil {
+		log.Printf("telegraph: watcher: pause state: %v", err)
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev, seeded := w.lastPause, w.pauseSeeded
+	w.lastPause, w.pauseSeeded = st, true
+	if !seeded || prev.Paused == st.Paused {
+		return nil
+	}
+	ev := &DetectedEvent{Type: EventYardPause, Timestamp: w.clock.Now(), Paused: st.Paused}
+	if st.Paused {
+		ev.PausedBy, ev.Body = st.By, st.Reason
+	} else {
+		ev.PausedBy = prev.By
+	}
+	return ev
+}
+
 // Run starts the watcher loop. It polls on the configured interval and
 // sends detected events to the returned channel. The channel is closed
-// when the context is cancelled. Pulse digests fire on a separate interval.
+// when the context is cancelled. Pulse digests and epic rollups fire on a
+// separate interval.
 func (w *Watcher) Run(ctx context.Context) <-chan DetectedEvent {
 	ch := make(chan DetectedEvent, 64)
 	go func() {
 		defer close(ch)
-		pollTicker := time.NewTicker(w.pollInterval)
+		pollTicker := w.clock.NewTicker(w.pollInterval)
 		defer pollTicker.Stop()
-		pulseTicker := time.NewTicker(w.pulseInterval)
+		pulseTicker := w.clock.NewTicker(w.pulseInterval)
 		defer pulseTicker.Stop()
 
 		emit := func(events []DetectedEvent) {
@@ -197,7 +266,7 @@ func (w *Watcher) Run(ctx context.Context) <-chan DetectedEvent {
 			select {
 			case <-ctx.Done():
 				return
-			case <-pollTicker.C:
+			case <-pollTicker.C():
 				events, err := w.Poll(ctx)
 				if err != nil {
 					log.Printf("telegraph: watcher: poll: %v", err)
@@ -207,7 +276,7 @@ func (w *Watcher) Run(ctx context.Context) <-chan DetectedEvent {
 				if w.onPoll != nil {
 					w.onPoll()
 				}
-			case <-pulseTicker.C:
+			case <-pulseTicker.C():
 				if pulse, err := w.BuildPulse(); err == nil && pulse != nil {
 					select {
 					case ch <- *pulse:
@@ -215,6 +284,11 @@ func (w *Watcher) Run(ctx context.Context) <-chan DetectedEvent {
 						return
 					}
 				}
+				rollups, err := w.BuildEpicRollups()
+				if err != nil {
+					log.Printf("telegraph: watcher: epic rollups: %v", err)
+				}
+				emit(rollups)
 			}
 		}
 	}()
@@ -227,7 +301,7 @@ func (w *Watcher) Run(ctx context.Context) <-chan DetectedEvent {
 // positives on startup).
 func (w *Watcher) detectCarEvents() ([]DetectedEvent, error) {
 	var cars []models.Car
-	if err := w.db.Select("id, status, track, title").Find(&cars).Error; err != nil {
+	if err := w.db.Select("id, status, track, title, owner, blocked_reason").Find(&cars).Error; err != nil {
 		return nil, err
 	}
 
@@ -242,31 +316,35 @@ func (w *Watcher) detectCarEvents() ([]DetectedEvent, error) {
 		old, exists := w.snapshot[c.ID]
 		if !exists {
 			// New car — record it. Only emit if we've already seeded.
-			w.snapshot[c.ID] = carSnapshot{Status: c.Status, Track: c.Track, Title: c.Title}
+			w.snapshot[c.ID] = carSnapshot{Status: c.Status, Track: c.Track, Title: c.Title, Owner: c.Owner}
 			if w.seeded {
 				events = append(events, DetectedEvent{
-					Type:      EventCarStatusChange,
-					Timestamp: time.Now(),
-					CarID:     c.ID,
-					OldStatus: "",
-					NewStatus: c.Status,
-					Track:     c.Track,
-					Title:     c.Title,
+					Type:          EventCarStatusChange,
+					Timestamp:     w.clock.Now(),
+					CarID:         c.ID,
+					OldStatus:     "",
+					NewStatus:     c.Status,
+					Track:         c.Track,
+					Title:         c.Title,
+					Owner:         c.Owner,
+					BlockedReason: c.BlockedReason,
 				})
 			}
 			continue
 		}
 		if old.Status != c.Status {
 			events = append(events, DetectedEvent{
-				Type:      EventCarStatusChange,
-				Timestamp: time.Now(),
-				CarID:     c.ID,
-				OldStatus: old.Status,
-				NewStatus: c.Status,
-				Track:     c.Track,
-				Title:     c.Title,
+				Type:          EventCarStatusChange,
+				Timestamp:     w.clock.Now(),
+				CarID:         c.ID,
+				OldStatus:     old.Status,
+				NewStatus:     c.Status,
+				Track:         c.Track,
+				Title:         c.Title,
+				Owner:         c.Owner,
+				BlockedReason: c.BlockedReason,
 			})
-			w.snapshot[c.ID] = carSnapshot{Status: c.Status, Track: c.Track, Title: c.Title}
+			w.snapshot[c.ID] = carSnapshot{Status: c.Status, Track: c.Track, Title: c.Title, Owner: c.Owner}
 		}
 	}
 
@@ -308,7 +386,7 @@ func (w *Watcher) detectStalls() ([]DetectedEvent, error) {
 				// Newly stalled — emit event.
 				events = append(events, DetectedEvent{
 					Type:       EventEngineStalled,
-					Timestamp:  time.Now(),
+					Timestamp:  w.clock.Now(),
 					EngineID:   e.ID,
 					Track:      e.Track,
 					CurrentCar: e.CurrentCar,
@@ -359,6 +437,7 @@ func (w *Watcher) detectEscalations() ([]DetectedEvent, error) {
 		return nil, nil
 	}
 
+	cars := w.escalationCars(msgs)
 	events := make([]DetectedEvent, 0, len(msgs))
 	for _, m := range msgs {
 		events = append(events, DetectedEvent{
@@ -371,12 +450,39 @@ func (w *Watcher) detectEscalations() ([]DetectedEvent, error) {
 			Subject:   m.Subject,
 			Body:      m.Body,
 			Priority:  m.Priority,
+			Owner:     cars[m.CarID].Owner,
+			CarStatus: cars[m.CarID].Status,
 		})
 	}
 
 	return events, nil
 }
 
+// escalationCars returns the owner and status of each car referenced by msgs,
+// keyed by car ID. Lookup failures are logged and yield no cars; escalations
+// are still delivered.
+func (w *Watcher) escalationCars(msgs []models.Message) map[string]models.Car {
+	var ids []string
+	for _, m := range msgs {
+		if m.CarID != "" {
+			ids = append(ids, m.CarID)
+		}
+	}
+	byID := make(map[string]models.Car)
+	if len(ids) == 0 {
+		return byID
+	}
+	var cars []models.Car
+	if err := w.db.Select("id, owner, status").Where("id IN ?", ids).Find(&cars).Error; err != nil {
+		log.Printf("telegraph: watcher: escalation cars: %v", err)
+		return byID
+	}
+	for _, c := range cars {
+		byID[c.ID] = c
+	}
+	return byID
+}
+
 // MarkEscalationDelivered records that an escalation event reached the chat
 // platform. It writes telegraph's per-consumer delivery marker (idempotent),
 // and for messages addressed to telegraph itself — where telegraph is the
@@ -433,12 +539,12 @@ func (w *Watcher) BuildPulse() (*DetectedEvent, error) {
 	}
 
 	w.lastDigest = &current
-	w.lastPulseAt = time.Now()
+	w.lastPulseAt = w.clock.Now()
 
 	formatted := FormatPulse(info, w.dashboardURL)
 	return &DetectedEvent{
 		Type:      EventPulse,
-		Timestamp: time.Now(),
+		Timestamp: w.clock.Now(),
 		Title:     formatted.Title,
 		Body:      formatted.Body,
 	}, nil
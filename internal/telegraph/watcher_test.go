@@ -9,6 +9,7 @@ import (
 
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -43,6 +44,7 @@ func openWatcherTestDB(t *testing.T) *gorm.DB {
 		&models.Track{},
 		&models.DispatchSession{},
 		&models.TelegraphConversation{},
+		&models.RailyardConfig{},
 	); err != nil {
 		t.Fatalf("auto migrate: %v", err)
 	}
@@ -436,6 +438,44 @@ func TestDetectEscalations_HumanMessage(t *testing.T) {
 	}
 }
 
+func TestDetectEscalations_CarOwner(t *testing.T) {
+	db := openWatcherTestDB(t)
+	db.Create(&models.Car{ID: "car-1", Title: "Owned", Status: "blocked", Track: "backend", Owner: "alice"})
+	db.Create(&models.Message{FromAgent: "yardmaster", ToAgent: "human", CarID: "car-1", Subject: "Stuck"})
+	db.Create(&models.Message{FromAgent: "yardmaster", ToAgent: "human", Subject: "No car"})
+
+	w, _ := NewWatcher(WatcherOpts{DB: db})
+	events, err := w.detectEscalations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 escalation events, got %d", len(events))
+	}
+	owners := map[string]string{}
+	for _, e := range events {
+		owners[e.Subject] = e.Owner
+	}
+	if owners["Stuck"] != "alice" || owners["No car"] != "" {
+		t.Errorf("owners = %v, want Stuck→alice and No car→empty", owners)
+	}
+}
+
+func TestDetectEscalations_CarStatus(t *testing.T) {
+	db := openWatcherTestDB(t)
+	db.Create(&models.Car{ID: "car-1", Title: "Landed", Status: "merged", Track: "backend"})
+	db.Create(&models.Message{FromAgent: "yardmaster", ToAgent: "human", CarID: "car-1", Subject: "Main broken"})
+
+	w, _ := NewWatcher(WatcherOpts{DB: db})
+	events, err := w.detectEscalations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].CarStatus != "merged" {
+		t.Fatalf("events = %+v, want one event with CarStatus merged (unowned cars included)", events)
+	}
+}
+
 func TestDetectEscalations_TelegraphMessage(t *testing.T) {
 	db := openWatcherTestDB(t)
 	db.Create(&models.Message{
@@ -638,6 +678,37 @@ func TestDetectEscalations_IgnoresAcknowledged(t *testing.T) {
 	}
 }
 
+// --- detectPauseChange tests ---
+
+func TestDetectPauseChange(t *testing.T) {
+	db := openWatcherTestDB(t)
+	w, _ := NewWatcher(WatcherOpts{DB: db})
+
+	// First call seeds the baseline silently.
+	if ev := w.detectPauseChange(); ev != nil {
+		t.Fatalf("seed emitted %+v", ev)
+	}
+
+	if _, _, err := yard.Pause(db, "bad deploy", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	ev := w.detectPauseChange()
+	if ev == nil || ev.Type != EventYardPause || !ev.Paused || ev.PausedBy != "alice" || ev.Body != "bad deploy" {
+		t.Fatalf("pause event = %+v", ev)
+	}
+	if ev := w.detectPauseChange(); ev != nil {
+		t.Errorf("unchanged pause emitted %+v", ev)
+	}
+
+	if _, _, err := yard.Resume(db); err != nil {
+		t.Fatal(err)
+	}
+	ev = w.detectPauseChange()
+	if ev == nil || ev.Paused || ev.PausedBy != "alice" {
+		t.Errorf("resume event = %+v", ev)
+	}
+}
+
 // --- Poll integration test ---
 
 func TestPoll_CombinesAllEventTypes(t *testing.T) {
@@ -0,0 +1,214 @@
+// Package undo records reversible CLI mutations and restores the state they
+// replaced. Each actor has a bounded stack of entries, newest on top.
+package undo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// MaxEntries bounds each actor's undo stack; older entries are dropped.
+const MaxEntries = 20
+
+// Entry kinds.
+const (
+	KindCar   = "car"          // car field changes (ry car update, ry car own)
+	KindScale = "engine-scale" // ry engine scale
+)
+
+// ErrEmpty is returned by Last when there is nothing to undo.
+var ErrEmpty = errors.New("undo: nothing to undo")
+
+// CarChange is the Data of a KindCar entry: the car before and after the
+// change, and the columns that changed.
+type CarChange struct {
+	Fields []string   `json:"fields"`
+	Before models.Car `json:"before"`
+	After  models.Car `json:"after"`
+}
+
+// ScaleChange is the Data of a KindScale entry.
+type ScaleChange struct {
+	Track    string `json:"track"`
+	Previous int    `json:"previous"`
+	Current  int    `json:"current"`
+}
+
+// Record pushes an entry onto actor's stack and drops entries beyond
+// MaxEntries.
+func Record(db *gorm.DB, actor, kind, target, summary string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("undo: encode %s entry: %w", kind, err)
+	}
+	if len(summary) > 255 {
+		summary = summary[:252] + "..."
+	}
+	e := models.UndoEntry{Actor: actor, Kind: kind, Target: target, Summary: summary, Data: string(b)}
+	if err := db.Create(&e).Error; err != nil {
+		return fmt.Errorf("undo: record %s: %w", kind, err)
+	}
+
+	// Find the oldest entry that stays and delete everything below it. MySQL
+	// rejects OFFSET without LIMIT, so the cutoff is selected on its own.
+	var cutoff []uint
+	if err := db.Model(&models.UndoEntry{}).Where("actor = ?", actor).
+		Order("id DESC").Limit(1).Offset(MaxEntries-1).Pluck("id", &cutoff).Error; err != nil {
+		return fmt.Errorf("undo: trim stack: %w", err)
+	}
+	if len(cutoff) > 0 {
+		if err := db.Where("actor = ? AND id < ?", actor, cutoff[0]).Delete(&models.UndoEntry{}).Error; err != nil {
+			return fmt.Errorf("undo: trim stack: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecordCarChange records a change to fields of a car, given the car before
+// and after it. Fields that did not actually change are left out; nothing is
+// recorded when none did.
+func RecordCarChange(db *gorm.DB, actor, summary string, before, after *models.Car, fields []string) error {
+	var changed []string
+	for _, f := range fields {
+		bv, ok := CarField(before, f)
+		if !ok {
+			continue
+		}
+		av, _ := CarField(after, f)
+		if !sameValue(bv, av) {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return Record(db, actor, KindCar, before.ID, summary, CarChange{Fields: changed, Before: *before, After: *after})
+}
+
+// List returns actor's undo stack, newest first.
+func List(db *gorm.DB, actor string) ([]models.UndoEntry, error) {
+	var entries []models.UndoEntry
+	if err := db.Where("actor = ?", actor).Order("id DESC").Limit(MaxEntries).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("undo: list: %w", err)
+	}
+	return entries, nil
+}
+
+// Last returns the top of actor's stack, or ErrEmpty.
+func Last(db *gorm.DB, actor string) (*models.UndoEntry, error) {
+	entries, err := List(db, actor)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+	return &entries[0], nil
+}
+
+// Remove deletes an entry once it has been undone.
+func Remove(db *gorm.DB, id uint) error {
+	if err := db.Delete(&models.UndoEntry{}, id).Error; err != nil {
+		return fmt.Errorf("undo: remove entry %d: %w", id, err)
+	}
+	return nil
+}
+
+// RestoreCar reverses a KindCar entry. It refuses when any recorded field has
+// changed again since, so a later edit is never silently overwritten. Status
+// is restored directly, without transition validation: going back to the
+// previous state is the point.
+func RestoreCar(db *gorm.DB, e *models.UndoEntry) error {
+	var change CarChange
+	if err := json.Unmarshal([]byte(e.Data), &change); err != nil {
+		return fmt.Errorf("undo: decode entry %d: %w", e.ID, err)
+	}
+
+	var current models.Car
+	if err := db.Where("id = ?", e.Target).First(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("undo: car not found: %s", e.Target)
+		}
+		return fmt.Errorf("undo: get car %s: %w", e.Target, err)
+	}
+
+	updates := make(map[string]interface{}, len(change.Fields))
+	var moved []string
+	for _, f := range change.Fields {
+		cur, ok := CarField(&current, f)
+		if !ok {
+			return fmt.Errorf("undo: car %s: unsupported field %q", e.Target, f)
+		}
+		after, _ := CarField(&change.After, f)
+		if !sameValue(cur, after) {
+			moved = append(moved, f)
+		}
+		updates[f], _ = CarField(&change.Before, f)
+	}
+	if len(moved) > 0 {
+		return fmt.Errorf("undo: car %s changed since (%s); not undoing", e.Target, strings.Join(moved, ", "))
+	}
+
+	q := db.Model(&models.Car{}).Where("id = ?", e.Target)
+	if _, ok := updates["status"]; ok {
+		q = q.Where("status = ?", current.Status)
+	}
+	res := q.Updates(updates)
+	if res.Error != nil {
+		return fmt.Errorf("undo: restore car %s: %w", e.Target, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("undo: car %s changed since; not undoing", e.Target)
+	}
+	return nil
+}
+
+// CarField returns the value of the car column named col, for the columns a
+// CarChange may record.
+func CarField(c *models.Car, col string) (interface{}, bool) {
+	switch col {
+	case "status":
+		return c.Status, true
+	case "assignee":
+		return c.Assignee, true
+	case "owner":
+		return c.Owner, true
+	case "priority":
+		return c.Priority, true
+	case "estimate":
+		return c.Estimate, true
+	case "description":
+		return c.Description, true
+	case "acceptance":
+		return c.Acceptance, true
+	case "design_notes":
+		return c.DesignNotes, true
+	case "skip_tests":
+		return c.SkipTests, true
+	case "claimed_at":
+		return c.ClaimedAt, true
+	case "completed_at":
+		return c.CompletedAt, true
+	}
+	return nil, false
+}
+
+// sameValue compares two CarField values. Times are compared as instants, as
+// a JSON round trip keeps the instant but not the location.
+func sameValue(a, b interface{}) bool {
+	at, aok := a.(*time.Time)
+	bt, bok := b.(*time.Time)
+	if aok && bok {
+		if at == nil || bt == nil {
+			return at == bt
+		}
+		return at.Equal(*bt)
+	}
+	return a == b
+}
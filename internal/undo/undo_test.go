@@ -0,0 +1,155 @@
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.UndoEntry{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestRecord_BoundedPerActor(t *testing.T) {
+	db := testDB(t)
+	for i := 0; i < MaxEntries+5; i++ {
+		if err := Record(db, "alice", KindScale, "backend", fmt.Sprintf("scale %d", i), ScaleChange{Track: "backend"}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	Record(db, "bob", KindScale, "backend", "bob's", ScaleChange{Track: "backend"})
+
+	entries, err := List(db, "alice")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("alice has %d entries, want %d", len(entries), MaxEntries)
+	}
+	if entries[0].Summary != fmt.Sprintf("scale %d", MaxEntries+4) {
+		t.Errorf("newest = %q", entries[0].Summary)
+	}
+	var total int64
+	db.Model(&models.UndoEntry{}).Count(&total)
+	if total != MaxEntries+1 {
+		t.Errorf("stored %d entries, want %d (trimmed alice + bob)", total, MaxEntries+1)
+	}
+
+	if _, err := Last(db, "carol"); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Last on empty stack: err = %v", err)
+	}
+}
+
+// TestRecord_TrimQueriesOnMySQL checks the trim statements in the MySQL
+// dialect production runs: sqlite accepts OFFSET without LIMIT, MySQL does
+// not.
+func TestRecord_TrimQueriesOnMySQL(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer sqlDB.Close()
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:                 logger.Default.LogMode(logger.Silent),
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open(mysql): %v", err)
+	}
+
+	mock.ExpectExec("INSERT INTO `undo_entries`").WillReturnResult(sqlmock.NewResult(30, 1))
+	mock.ExpectQuery("SELECT `id` FROM `undo_entries` WHERE actor = \\? ORDER BY id DESC LIMIT \\? OFFSET \\?$").
+		WithArgs("alice", 1, MaxEntries-1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectExec("DELETE FROM `undo_entries` WHERE actor = \\? AND id < \\?").
+		WithArgs("alice", 11).WillReturnResult(sqlmock.NewResult(0, 10))
+
+	if err := Record(db, "alice", KindScale, "backend", "scale", ScaleChange{Track: "backend"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRestoreCar(t *testing.T) {
+	db := testDB(t)
+	before := models.Car{ID: "car-u1", Title: "Undo me", Status: "open", Priority: 2, Track: "backend"}
+	db.Create(&before)
+
+	done := time.Now().UTC().Truncate(time.Second)
+	db.Model(&models.Car{}).Where("id = ?", "car-u1").Updates(map[string]interface{}{"status": "done", "priority": 0, "completed_at": done})
+	var after models.Car
+	db.First(&after, "id = ?", "car-u1")
+
+	fields := []string{"status", "priority", "completed_at", "description"}
+	if err := RecordCarChange(db, "alice", "car update car-u1", &before, &after, fields); err != nil {
+		t.Fatalf("RecordCarChange: %v", err)
+	}
+	e, err := Last(db, "alice")
+	if err != nil {
+		t.Fatalf("Last: %v", err)
+	}
+	if !strings.Contains(e.Data, `"fields":["status","priority","completed_at"]`) {
+		t.Errorf("recorded fields: %s", e.Data)
+	}
+
+	if err := RestoreCar(db, e); err != nil {
+		t.Fatalf("RestoreCar: %v", err)
+	}
+	var got models.Car
+	db.First(&got, "id = ?", "car-u1")
+	if got.Status != "open" || got.Priority != 2 || got.CompletedAt != nil {
+		t.Errorf("restored car = status %q priority %d completed_at %v", got.Status, got.Priority, got.CompletedAt)
+	}
+}
+
+func TestRestoreCar_RefusesWhenChangedSince(t *testing.T) {
+	db := testDB(t)
+	before := models.Car{ID: "car-u2", Title: "Moved on", Status: "open", Priority: 2, Track: "backend"}
+	db.Create(&before)
+	after := before
+	after.Priority = 0
+	db.Model(&models.Car{}).Where("id = ?", "car-u2").Update("priority", 0)
+	RecordCarChange(db, "alice", "car update car-u2", &before, &after, []string{"priority"})
+
+	db.Model(&models.Car{}).Where("id = ?", "car-u2").Update("priority", 1)
+	e, _ := Last(db, "alice")
+	if err := RestoreCar(db, e); err == nil || !strings.Contains(err.Error(), "changed since (priority)") {
+		t.Errorf("err = %v, want changed since", err)
+	}
+	var got models.Car
+	db.First(&got, "id = ?", "car-u2")
+	if got.Priority != 1 {
+		t.Errorf("priority = %d, want the later edit kept", got.Priority)
+	}
+}
+
+func TestRecordCarChange_NoChange(t *testing.T) {
+	db := testDB(t)
+	c := models.Car{ID: "car-u3", Owner: "alice"}
+	if err := RecordCarChange(db, "alice", "car own car-u3", &c, &c, []string{"owner"}); err != nil {
+		t.Fatalf("RecordCarChange: %v", err)
+	}
+	if _, err := Last(db, "alice"); !errors.Is(err, ErrEmpty) {
+		t.Errorf("no-op change recorded: err = %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+// Package webhook POSTs signed JSON payloads to the URLs configured under
+// webhooks: in railyard.yaml when cars change status, engines stall,
+// switches fail, or the yard is paused or resumed, and to the webhooks
+// registered on a single car (ry car webhook add) when that car changes
+// status or its merge fails. It runs inside the yardmaster and detects
+// changes with the same database watcher Telegraph uses, so it works
+// whether or not the Telegraph bot is running.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/telegraph"
+	"gorm.io/gorm"
+)
+
+// Request headers set on every delivery.
+const (
+	HeaderEvent     = "X-Railyard-Event"
+	HeaderDelivery  = "X-Railyard-Delivery"
+	HeaderSignature = "X-Railyard-Signature" // "sha256=" + hex HMAC of the body; only when a secret is set
+)
+
+// DefaultPollInterval is how often the dispatcher checks for changes.
+const DefaultPollInterval = 15 * time.Second
+
+// switchFailReasons are the blocked reasons that mean the yardmaster tried
+// to merge a car and could not.
+var switchFailReasons = []string{
+	models.BlockedReasonTestFailed,
+	models.BlockedReasonMergeConflict,
+	models.BlockedReasonCoverageDropped,
+	models.BlockedReasonDiffTooLarge,
+}
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	Event     string    `json:"event"`
+	Delivery  string    `json:"delivery"`
+	Timestamp time.Time `json:"timestamp"`
+	Project   string    `json:"project,omitempty"`
+
+	Car    *CarInfo    `json:"car,omitempty"`
+	Engine *EngineInfo `json:"engine,omitempty"`
+	Yard   *YardInfo   `json:"yard,omitempty"`
+}
+
+// CarInfo describes the car in car.* and switch.* events.
+type CarInfo struct {
+	ID            string `json:"id"`
+	Title         string `json:"title,omitempty"`
+	Track         string `json:"track,omitempty"`
+	Owner         string `json:"owner,omitempty"`
+	OldStatus     string `json:"old_status,omitempty"`
+	Status        string `json:"status"`
+	BlockedReason string `json:"blocked_reason,omitempty"`
+}
+
+// EngineInfo describes the engine in engine.* events.
+type EngineInfo struct {
+	ID         string `json:"id"`
+	Track      string `json:"track,omitempty"`
+	CurrentCar string `json:"current_car,omitempty"`
+}
+
+// YardInfo describes a pause or resume.
+type YardInfo struct {
+	Paused bool   `json:"paused"`
+	By     string `json:"by,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Dispatcher watches the yard and delivers events to the configured hooks
+// and to the hooks registered on each car.
+type Dispatcher struct {
+	db      *gorm.DB
+	hooks   []config.WebhookConfig
+	project string
+	watcher *telegraph.Watcher
+	client  *http.Client
+	logger  *slog.Logger
+
+	// retryWaits are the pauses between delivery attempts; one attempt
+	// more than its length is made.
+	retryWaits []time.Duration
+}
+
+// New returns a Dispatcher for cfg.Webhooks. The first poll records the
+// current state without sending anything.
+func New(db *gorm.DB, cfg *config.Config, logger *slog.Logger) (*Dispatcher, error) {
+	w, err := telegraph.NewWatcher(telegraph.WatcherOpts{DB: db, SkipEscalations: true})
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Dispatcher{
+		db:         db,
+		hooks:      cfg.Webhooks,
+		project:    cfg.Project,
+		watcher:    w,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+		retryWaits: []time.Duration{time.Second, 5 * time.Second},
+	}, nil
+}
+
+// Run polls every interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Tick(ctx); err != nil {
+			d.logger.Warn("Webhook poll failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick detects what changed since the last call and delivers it. Failed
+// deliveries are logged and dropped after the retries.
+func (d *Dispatcher) Tick(ctx context.Context) error {
+	detected, err := d.watcher.Poll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ev := range detected {
+		for _, p := range d.payloads(ev) {
+			d.deliver(ctx, p)
+		}
+	}
+	return nil
+}
+
+// payloads maps one watcher event to the webhook events it produces.
+func (d *Dispatcher) payloads(ev telegraph.DetectedEvent) []Payload {
+	base := Payload{Timestamp: ev.Timestamp.UTC(), Project: d.project}
+	var out []Payload
+	switch ev.Type {
+	case telegraph.EventCarStatusChange:
+		car := &CarInfo{ID: ev.CarID, Title: ev.Title, Track: ev.Track, Owner: ev.Owner,
+			OldStatus: ev.OldStatus, Status: ev.NewStatus, BlockedReason: ev.BlockedReason}
+		p := base
+		p.Event, p.Car = config.WebhookCarStatusChanged, car
+		out = append(out, p)
+		if ev.NewStatus == "merge-failed" || (ev.NewStatus == "blocked" && slices.Contains(switchFailReasons, ev.BlockedReason)) {
+			p.Event = config.WebhookSwitchFailed
+			out = append(out, p)
+		}
+	case telegraph.EventEngineStalled:
+		p := base
+		p.Event = config.WebhookEngineStalled
+		p.Engine = &EngineInfo{ID: ev.EngineID, Track: ev.Track, CurrentCar: ev.CurrentCar}
+		out = append(out, p)
+	case telegraph.EventYardPause:
+		p := base
+		p.Event = config.WebhookYardResumed
+		if ev.Paused {
+			p.Event = config.WebhookYardPaused
+		}
+		p.Yard = &YardInfo{Paused: ev.Paused, By: ev.PausedBy, Reason: ev.Body}
+		out = append(out, p)
+	}
+	return out
+}
+
+// deliver sends p to every hook subscribed to its event, and a car event to
+// the hooks registered on that car.
+func (d *Dispatcher) deliver(ctx context.Context, p Payload) {
+	hooks := slices.Clip(d.hooks)
+	if p.Car != nil {
+		var carHooks []models.CarWebhook
+		if err := d.db.Where("car_id = ?", p.Car.ID).Order("id ASC").Find(&carHooks).Error; err != nil {
+			d.logger.Warn("Webhook lookup failed", "car", p.Car.ID, "error", err)
+		}
+		for _, h := range carHooks {
+			hooks = append(hooks, config.WebhookConfig{URL: h.URL, Secret: h.Secret})
+		}
+	}
+	for _, h := range hooks {
+		if !h.Wants(p.Event) {
+			continue
+		}
+		p.Delivery = newDeliveryID()
+		if err := d.post(ctx, h, p); err != nil {
+			d.logger.Warn("Webhook delivery failed", "url", h.URL, "event", p.Event, "delivery", p.Delivery, "error", err)
+		}
+	}
+}
+
+// post sends p to h, retrying network errors and 5xx/429 responses.
+func (d *Dispatcher) post(ctx context.Context, h config.WebhookConfig, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		retry, err := d.send(ctx, h, p, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt >= len(d.retryWaits) {
+			return lastErr
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(lastErr, ctx.Err())
+		case <-time.After(d.retryWaits[attempt]):
+		}
+	}
+}
+
+// send makes one delivery attempt and reports whether a failure is worth
+// retrying.
+func (d *Dispatcher) send(ctx context.Context, h config.WebhookConfig, p Payload, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "railyard-webhook")
+	req.Header.Set(HeaderEvent, p.Event)
+	req.Header.Set(HeaderDelivery, p.Delivery)
+	if h.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(h.Secret, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook: %s returned %s", h.URL, resp.Status)
+}
+
+// Sign returns the X-Railyard-Signature value for body: "sha256=" followed
+// by the hex HMAC-SHA256 of body keyed with secret. Receivers recompute it
+// over the raw request body and compare with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID returns a random ID receivers can use to drop duplicates.
+func newDeliveryID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
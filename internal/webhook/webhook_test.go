@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/models/testfactory"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Car{}, &models.CarWebhook{}, &models.Engine{}, &models.Message{}, &models.BroadcastAck{}, &models.RailyardConfig{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+type received struct {
+	headers http.Header
+	body    []byte
+	payload Payload
+}
+
+// receiver records deliveries, answering with the given statuses in turn
+// (then 200).
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, func() []received) {
+	t.Helper()
+	var mu sync.Mutex
+	var got []received
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var p Payload
+		json.Unmarshal(body, &p)
+		mu.Lock()
+		got = append(got, received{headers: r.Header.Clone(), body: body, payload: p})
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), got...)
+	}
+}
+
+func newTestDispatcher(t *testing.T, db *gorm.DB, hooks ...config.WebhookConfig) *Dispatcher {
+	t.Helper()
+	d, err := New(db, &config.Config{Project: "shop", Webhooks: hooks}, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.retryWaits = []time.Duration{time.Millisecond, time.Millisecond}
+	if err := d.Tick(context.Background()); err != nil { // baseline
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDispatcher_CarStatusAndSwitchFailure(t *testing.T) {
+	db := testDB(t)
+	c := testfactory.Car().WithID("car-1").WithStatus("done").Create(t, db)
+	srv, got := receiver(t)
+	d := newTestDispatcher(t, db,
+		config.WebhookConfig{URL: srv.URL, Secret: "s3cret"},
+		config.WebhookConfig{URL: srv.URL + "/stalls", Events: []string{config.WebhookEngineStalled}},
+	)
+	if n := len(got()); n != 0 {
+		t.Fatalf("baseline poll delivered %d events", n)
+	}
+
+	db.Model(&c).Updates(map[string]interface{}{"status": "blocked", "blocked_reason": models.BlockedReasonTestFailed})
+	if err := d.Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := got()
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %d, want status change + switch failure to the first hook only", len(deliveries))
+	}
+	status, failed := deliveries[0], deliveries[1]
+	if status.payload.Event != config.WebhookCarStatusChanged || failed.payload.Event != config.WebhookSwitchFailed {
+		t.Errorf("events = %q, %q", status.payload.Event, failed.payload.Event)
+	}
+	if car := status.payload.Car; car == nil || car.ID != "car-1" || car.OldStatus != "done" || car.Status != "blocked" || car.BlockedReason != models.BlockedReasonTestFailed {
+		t.Errorf("car = %+v", status.payload.Car)
+	}
+	if status.payload.Project != "shop" || status.headers.Get(HeaderEvent) != config.WebhookCarStatusChanged || status.headers.Get(HeaderDelivery) == "" {
+		t.Errorf("payload/headers = %+v %v", status.payload, status.headers)
+	}
+	if sig := status.headers.Get(HeaderSignature); sig != Sign("s3cret", status.body) {
+		t.Errorf("signature = %q, want HMAC of the body", sig)
+	}
+}
+
+func TestDispatcher_EngineStallRetries(t *testing.T) {
+	db := testDB(t)
+	e := testfactory.Engine().WithID("eng-1").Working("car-9").Create(t, db)
+	srv, got := receiver(t, http.StatusBadGateway, http.StatusServiceUnavailable)
+	d := newTestDispatcher(t, db, config.WebhookConfig{URL: srv.URL, Events: []string{config.WebhookEngineStalled}})
+
+	db.Model(&e).Update("status", "stalled")
+	if err := d.Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deliveries := got()
+	if len(deliveries) != 3 {
+		t.Fatalf("attempts = %d, want 2 failures then success", len(deliveries))
+	}
+	last := deliveries[2]
+	if eng := last.payload.Engine; eng == nil || eng.ID != "eng-1" || eng.CurrentCar != "car-9" {
+		t.Errorf("engine = %+v", last.payload.Engine)
+	}
+	if last.headers.Get(HeaderDelivery) != deliveries[0].headers.Get(HeaderDelivery) {
+		t.Error("retries should reuse the delivery ID")
+	}
+	if last.headers.Get(HeaderSignature) != "" {
+		t.Error("signature set without a secret")
+	}
+}
+
+func TestDispatcher_ClientErrorNotRetried(t *testing.T) {
+	db := testDB(t)
+	c := testfactory.Car().Create(t, db)
+	srv, got := receiver(t, http.StatusBadRequest)
+	d := newTestDispatcher(t, db, config.WebhookConfig{URL: srv.URL, Events: []string{config.WebhookCarStatusChanged}})
+
+	db.Model(&c).Update("status", "claimed")
+	d.Tick(context.Background())
+	if n := len(got()); n != 1 {
+		t.Errorf("attempts = %d, want 1 (4xx is not retried)", n)
+	}
+}
+
+func TestDispatcher_CarWebhooks(t *testing.T) {
+	db := testDB(t)
+	watched := testfactory.Car().WithID("car-1").WithStatus("done").Create(t, db)
+	other := testfactory.Car().WithID("car-2").WithStatus("done").Create(t, db)
+	srv, got := receiver(t)
+	db.Create(&models.CarWebhook{CarID: "car-1", URL: srv.URL + "/car-1", Secret: "k"})
+	d := newTestDispatcher(t, db)
+
+	db.Model(&other).Update("status", "merged")
+	db.Model(&watched).Update("status", "merge-failed")
+	if err := d.Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := got()
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %d, want car-1's status change and switch failure only", len(deliveries))
+	}
+	for _, r := range deliveries {
+		if r.payload.Car == nil || r.payload.Car.ID != "car-1" {
+			t.Errorf("delivered %+v to car-1's hook", r.payload.Car)
+		}
+		if r.headers.Get(HeaderSignature) != Sign("k", r.body) {
+			t.Error("car webhook delivery not signed with its secret")
+		}
+	}
+	if deliveries[1].payload.Event != config.WebhookSwitchFailed {
+		t.Errorf("second event = %q, want the merge failure", deliveries[1].payload.Event)
+	}
+}
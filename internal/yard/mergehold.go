@@ -0,0 +1,139 @@
+package yard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// MergeHold describes a hold on the merge queue. The yardmaster places one
+// when a car's merge gate fails for infrastructure reasons (a broken test
+// environment rather than broken code): merging other cars would fail the
+// same way, so the queue waits until an operator reports the environment
+// fixed.
+type MergeHold struct {
+	Held   bool
+	Reason string    // the infra failure that placed the hold
+	Since  time.Time // when the hold was placed
+	Cars   []string  // cars that failed the merge gate while the hold was in place
+}
+
+// String renders the hold for status output, e.g.
+// "merges held since 2026-05-01 09:00 (2 cars): docker daemon unreachable".
+func (h MergeHold) String() string {
+	if !h.Held {
+		return "merging"
+	}
+	out := "merges held"
+	if !h.Since.IsZero() {
+		out += " since " + h.Since.Local().Format("2006-01-02 15:04")
+	}
+	if n := len(h.Cars); n == 1 {
+		out += " (1 car)"
+	} else if n > 1 {
+		out += fmt.Sprintf(" (%d cars)", n)
+	}
+	if h.Reason != "" {
+		out += ": " + h.Reason
+	}
+	return out
+}
+
+// The merge hold lives in the same RailyardConfig.Settings blob as the
+// pause state.
+const (
+	keyHold       = "merge_hold"
+	keyHoldReason = "merge_hold_reason"
+	keyHoldSince  = "merge_hold_at"
+	keyHoldCars   = "merge_hold_cars"
+)
+
+// GetMergeHold reads the merge hold. A missing config row or unreadable
+// settings count as not held.
+func GetMergeHold(db *gorm.DB) (MergeHold, error) {
+	if db == nil {
+		return MergeHold{}, fmt.Errorf("yard: db is required")
+	}
+	var rc models.RailyardConfig
+	if err := db.First(&rc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return MergeHold{}, nil
+		}
+		return MergeHold{}, fmt.Errorf("yard: read merge hold: %w", err)
+	}
+	settings := map[string]any{}
+	if rc.Settings == "" || json.Unmarshal([]byte(rc.Settings), &settings) != nil {
+		return MergeHold{}, nil
+	}
+	return holdFromSettings(settings), nil
+}
+
+// HoldMerges places a merge hold for carID's infra failure, or adds carID to
+// the hold already in place (keeping its reason and time). started reports
+// whether this call placed the hold.
+func HoldMerges(db *gorm.DB, carID, reason string) (h MergeHold, started bool, err error) {
+	err = updateSettings(db, func(settings map[string]any) bool {
+		h = holdFromSettings(settings)
+		if !h.Held {
+			h = MergeHold{Held: true, Reason: reason, Since: time.Now().UTC().Truncate(time.Second)}
+			settings[keyHold] = true
+			settings[keyHoldReason] = reason
+			settings[keyHoldSince] = h.Since.Format(time.RFC3339)
+			started = true
+		} else if slices.Contains(h.Cars, carID) {
+			return false
+		}
+		if carID != "" {
+			h.Cars = append(h.Cars, carID)
+		}
+		settings[keyHoldCars] = h.Cars
+		return true
+	})
+	return h, started, err
+}
+
+// ReleaseMerges clears the merge hold and returns the hold it cleared, whose
+// Cars are the ones to retry; changed is false when merges were not held.
+func ReleaseMerges(db *gorm.DB) (prev MergeHold, changed bool, err error) {
+	err = updateSettings(db, func(settings map[string]any) bool {
+		prev = holdFromSettings(settings)
+		if !prev.Held {
+			return false
+		}
+		delete(settings, keyHold)
+		delete(settings, keyHoldReason)
+		delete(settings, keyHoldSince)
+		delete(settings, keyHoldCars)
+		changed = true
+		return true
+	})
+	return prev, changed, err
+}
+
+func holdFromSettings(settings map[string]any) MergeHold {
+	held, _ := settings[keyHold].(bool)
+	if !held {
+		return MergeHold{}
+	}
+	h := MergeHold{Held: true}
+	h.Reason, _ = settings[keyHoldReason].(string)
+	if s, ok := settings[keyHoldSince].(string); ok {
+		h.Since, _ = time.Parse(time.RFC3339, s)
+	}
+	switch cars := settings[keyHoldCars].(type) {
+	case []any:
+		for _, c := range cars {
+			if id, ok := c.(string); ok && id != "" {
+				h.Cars = append(h.Cars, id)
+			}
+		}
+	case []string:
+		h.Cars = cars
+	}
+	return h
+}
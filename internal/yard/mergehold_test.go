@@ -0,0 +1,53 @@
+package yard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestMergeHold(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.RailyardConfig{Owner: "alice", Settings: `{"paused":false}`})
+
+	if h, err := GetMergeHold(db); err != nil || h.Held {
+		t.Fatalf("GetMergeHold before hold = %+v, %v", h, err)
+	}
+
+	h, started, err := HoldMerges(db, "car-1", "docker daemon unreachable")
+	if err != nil || !started || !h.Held || h.Since.IsZero() {
+		t.Fatalf("HoldMerges = %+v, %v, %v", h, started, err)
+	}
+
+	// Later failures join the hold without replacing its reason; repeats
+	// are recorded once.
+	h, started, err = HoldMerges(db, "car-2", "other")
+	if err != nil || started || h.Reason != "docker daemon unreachable" {
+		t.Errorf("second HoldMerges = %+v, %v, %v", h, started, err)
+	}
+	HoldMerges(db, "car-1", "again")
+
+	h, err = GetMergeHold(db)
+	if err != nil || !h.Held || strings.Join(h.Cars, ",") != "car-1,car-2" {
+		t.Fatalf("GetMergeHold = %+v, %v", h, err)
+	}
+	if s := h.String(); !strings.HasPrefix(s, "merges held since ") || !strings.HasSuffix(s, "(2 cars): docker daemon unreachable") {
+		t.Errorf("String() = %q", s)
+	}
+	// The pause state is untouched.
+	if st, _ := GetPause(db); st.Paused {
+		t.Error("merge hold paused the yard")
+	}
+
+	prev, changed, err := ReleaseMerges(db)
+	if err != nil || !changed || len(prev.Cars) != 2 {
+		t.Fatalf("ReleaseMerges = %+v, %v, %v", prev, changed, err)
+	}
+	if _, changed, _ := ReleaseMerges(db); changed {
+		t.Error("second ReleaseMerges reported a change")
+	}
+	if h, _ := GetMergeHold(db); h.Held || h.String() != "merging" {
+		t.Errorf("after release = %+v", h)
+	}
+}
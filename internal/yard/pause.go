@@ -0,0 +1,206 @@
+// Package yard holds yard-wide state that every daemon consults, such as
+// the pause switch operators flip during an incident.
+package yard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrPaused is matched (via errors.Is) by the error CheckRunning returns
+// while the yard is paused.
+var ErrPaused = errors.New("yard is paused")
+
+// PauseState describes whether the yard is paused, and if so by whom, why,
+// and since when.
+type PauseState struct {
+	Paused bool
+	Reason string
+	By     string
+	Since  time.Time // zero for pauses recorded before the time was tracked
+}
+
+// String renders the state for status output, e.g.
+// "paused by alice since 2026-05-01 09:00: bad deploy".
+func (s PauseState) String() string {
+	if !s.Paused {
+		return "running"
+	}
+	out := "paused"
+	if s.By != "" {
+		out += " by " + s.By
+	}
+	if !s.Since.IsZero() {
+		out += " since " + s.Since.Local().Format("2006-01-02 15:04")
+	}
+	if s.Reason != "" {
+		out += ": " + s.Reason
+	}
+	return out
+}
+
+// PausedError is returned by CheckRunning while the yard is paused.
+type PausedError struct {
+	State PauseState
+}
+
+func (e *PausedError) Error() string {
+	return "yard is " + e.State.String() + " (ry resume to continue)"
+}
+
+// Is makes errors.Is(err, ErrPaused) match.
+func (e *PausedError) Is(target error) bool { return target == ErrPaused }
+
+// The pause state lives in the RailyardConfig.Settings JSON blob. These keys
+// are written alongside whatever other keys the blob holds.
+const (
+	keyPaused = "paused"
+	keyReason = "paused_reason"
+	keyBy     = "paused_by"
+	keySince  = "paused_at"
+)
+
+// GetPause reads the pause state. A missing config row or unreadable
+// settings count as running.
+func GetPause(db *gorm.DB) (PauseState, error) {
+	if db == nil {
+		return PauseState{}, fmt.Errorf("yard: db is required")
+	}
+	var rc models.RailyardConfig
+	if err := db.First(&rc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return PauseState{}, nil
+		}
+		return PauseState{}, fmt.Errorf("yard: read pause state: %w", err)
+	}
+	return parsePause(rc.Settings), nil
+}
+
+// CheckRunning returns a *PausedError when the yard is paused. It fails
+// open: if the state cannot be read the error is logged and the yard is
+// treated as running, since a database that cannot serve the config row
+// will fail the caller's next query anyway.
+func CheckRunning(db *gorm.DB) error {
+	st, err := GetPause(db)
+	if err != nil {
+		slog.Default().Warn("yard: pause check failed; treating yard as running", "error", err)
+		return nil
+	}
+	if st.Paused {
+		return &PausedError{State: st}
+	}
+	return nil
+}
+
+// Pause pauses the yard, recording reason and who paused it. If the yard is
+// already paused the existing pause is kept; changed reports whether this
+// call paused it. The read and write happen in one transaction.
+func Pause(db *gorm.DB, reason, by string) (st PauseState, changed bool, err error) {
+	err = updateSettings(db, func(settings map[string]any) bool {
+		if cur := pauseFromSettings(settings); cur.Paused {
+			st = cur
+			return false
+		}
+		st = PauseState{Paused: true, Reason: reason, By: by, Since: time.Now().UTC().Truncate(time.Second)}
+		settings[keyPaused] = true
+		settings[keyReason] = reason
+		settings[keyBy] = by
+		settings[keySince] = st.Since.Format(time.RFC3339)
+		changed = true
+		return true
+	})
+	return st, changed, err
+}
+
+// Resume clears a pause and returns the pause it cleared; changed is false
+// when the yard was not paused.
+func Resume(db *gorm.DB) (prev PauseState, changed bool, err error) {
+	err = updateSettings(db, func(settings map[string]any) bool {
+		prev = pauseFromSettings(settings)
+		if !prev.Paused {
+			return false
+		}
+		settings[keyPaused] = false
+		delete(settings, keyReason)
+		delete(settings, keyBy)
+		delete(settings, keySince)
+		changed = true
+		return true
+	})
+	return prev, changed, err
+}
+
+// updateSettings applies fn to the Settings JSON of the config row inside a
+// transaction and writes the result back when fn returns true. If no row
+// exists yet, one is created so the pause works before `ry db init` seeds
+// the config.
+func updateSettings(db *gorm.DB, fn func(map[string]any) bool) error {
+	if db == nil {
+		return fmt.Errorf("yard: db is required")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rc models.RailyardConfig
+		if err := tx.First(&rc).Error; err != nil {
+			// Only a genuine "no row yet" creates one; any other error must
+			// propagate rather than insert a spurious row.
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("yard: read config row: %w", err)
+			}
+			rc = models.RailyardConfig{Owner: "yard", Mode: "local", Settings: "{}"}
+			if err := tx.Create(&rc).Error; err != nil {
+				return fmt.Errorf("yard: create config row: %w", err)
+			}
+		}
+		settings := map[string]any{}
+		if rc.Settings != "" {
+			if err := json.Unmarshal([]byte(rc.Settings), &settings); err != nil {
+				// Corrupted JSON: start over rather than refuse to change
+				// yard state during an incident.
+				settings = map[string]any{}
+			}
+		}
+		if !fn(settings) {
+			return nil
+		}
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("yard: marshal settings: %w", err)
+		}
+		if err := tx.Model(&models.RailyardConfig{}).Where("id = ?", rc.ID).
+			Update("settings", string(data)).Error; err != nil {
+			return fmt.Errorf("yard: update settings: %w", err)
+		}
+		return nil
+	})
+}
+
+func parsePause(raw string) PauseState {
+	if raw == "" {
+		return PauseState{}
+	}
+	settings := map[string]any{}
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return PauseState{}
+	}
+	return pauseFromSettings(settings)
+}
+
+func pauseFromSettings(settings map[string]any) PauseState {
+	paused, _ := settings[keyPaused].(bool)
+	if !paused {
+		return PauseState{}
+	}
+	st := PauseState{Paused: true}
+	st.Reason, _ = settings[keyReason].(string)
+	st.By, _ = settings[keyBy].(string)
+	if s, ok := settings[keySince].(string); ok {
+		st.Since, _ = time.Parse(time.RFC3339, s)
+	}
+	return st
+}
@@ -0,0 +1,114 @@
+package yard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.RailyardConfig{}); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestPauseResume(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.RailyardConfig{Owner: "alice", RepoURL: "git@x", Settings: `{"theme":"dark"}`})
+
+	if err := CheckRunning(db); err != nil {
+		t.Fatalf("CheckRunning before pause: %v", err)
+	}
+	st, changed, err := Pause(db, "bad deploy", "alice")
+	if err != nil || !changed {
+		t.Fatalf("Pause = %+v, %v, %v", st, changed, err)
+	}
+	if st.Since.IsZero() || st.By != "alice" || st.Reason != "bad deploy" {
+		t.Errorf("state = %+v", st)
+	}
+
+	// A second pause keeps the first one.
+	again, changed, err := Pause(db, "other", "bob")
+	if err != nil || changed || again.By != "alice" || again.Reason != "bad deploy" {
+		t.Errorf("second Pause = %+v, %v, %v", again, changed, err)
+	}
+
+	err = CheckRunning(db)
+	if !errors.Is(err, ErrPaused) {
+		t.Fatalf("CheckRunning = %v, want ErrPaused", err)
+	}
+	if !strings.Contains(err.Error(), "paused by alice since ") || !strings.Contains(err.Error(), ": bad deploy") {
+		t.Errorf("error = %q", err)
+	}
+
+	prev, changed, err := Resume(db)
+	if err != nil || !changed || prev.By != "alice" {
+		t.Fatalf("Resume = %+v, %v, %v", prev, changed, err)
+	}
+	if _, changed, _ := Resume(db); changed {
+		t.Error("second Resume reported a change")
+	}
+	if st, _ := GetPause(db); st.Paused {
+		t.Errorf("state after resume = %+v", st)
+	}
+
+	var rc models.RailyardConfig
+	db.First(&rc)
+	if !strings.Contains(rc.Settings, `"theme":"dark"`) || strings.Contains(rc.Settings, "paused_by") {
+		t.Errorf("settings = %s", rc.Settings)
+	}
+}
+
+func TestPause_CreatesConfigRow(t *testing.T) {
+	db := testDB(t)
+	if st, err := GetPause(db); err != nil || st.Paused {
+		t.Fatalf("GetPause without a row = %+v, %v", st, err)
+	}
+	if _, _, err := Pause(db, "", "dashboard"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if st, _ := GetPause(db); !st.Paused || st.String() == "running" {
+		t.Errorf("state = %+v", st)
+	}
+}
+
+func TestPause_PropagatesReadErrors(t *testing.T) {
+	db := testDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := Pause(db.WithContext(ctx), "x", "alice"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Pause err = %v, want context.Canceled", err)
+	}
+	var count int64
+	db.Model(&models.RailyardConfig{}).Count(&count)
+	if count != 0 {
+		t.Errorf("rows = %d, want no row inserted", count)
+	}
+	// CheckRunning fails open.
+	if err := CheckRunning(db.WithContext(ctx)); err != nil {
+		t.Errorf("CheckRunning = %v, want nil on read failure", err)
+	}
+}
+
+func TestPauseState_String(t *testing.T) {
+	if got := (PauseState{}).String(); got != "running" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (PauseState{Paused: true}).String(); got != "paused" {
+		t.Errorf("String() = %q", got)
+	}
+}
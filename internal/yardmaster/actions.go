@@ -4,8 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"time"
 
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/messaging"
@@ -19,7 +20,7 @@ import (
 // that passes a nil bus. Existing tests call this form; the daemon loop uses
 // the WithBus variant so it can publish [plugin.YardmasterAction].
 func handleRestartEngine(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath string, msg models.Message, logger *slog.Logger) {
-	handleRestartEngineWithBus(ctx, db, cfg, configPath, msg, logger, nil)
+	handleRestartEngineWithBus(ctx, db, clock.Real, cfg, configPath, msg, logger, nil)
 }
 
 // handleRestartEngineWithBus restarts the engine assigned to the car in msg.CarID.
@@ -28,7 +29,7 @@ func handleRestartEngine(ctx context.Context, db *gorm.DB, cfg *config.Config, c
 //
 // When bus is non-nil and the restart succeeds, publishes a
 // [plugin.YardmasterAction] event with ActionType="restart-engine".
-func handleRestartEngineWithBus(_ context.Context, db *gorm.DB, cfg *config.Config, configPath string, msg models.Message, logger *slog.Logger, bus events.Bus) {
+func handleRestartEngineWithBus(_ context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath string, msg models.Message, logger *slog.Logger, bus events.Bus) {
 	if msg.CarID == "" {
 		logger.Info("Action restart-engine: no car-id provided, skipping")
 		return
@@ -44,7 +45,7 @@ func handleRestartEngineWithBus(_ context.Context, db *gorm.DB, cfg *config.Conf
 	logger.Info("Action restart-engine: restarting engine", "engine", eng.ID, "car", msg.CarID, "reason", msg.Body)
 
 	// Reassign the car back to the pool so another engine can claim it.
-	reassigned, err := ReassignCar(db, msg.CarID, eng.ID, "dispatch: "+msg.Body)
+	reassigned, err := ReassignCar(db, clk, msg.CarID, eng.ID, "dispatch: "+msg.Body)
 	if err != nil {
 		logger.Error("Action restart-engine: reassign car failed", "car", msg.CarID, "error", err)
 		return
@@ -69,7 +70,7 @@ func handleRestartEngineWithBus(_ context.Context, db *gorm.DB, cfg *config.Conf
 // handleRetryMerge is a thin wrapper around [handleRetryMergeWithBus] that
 // passes a nil bus. Existing tests call this form.
 func handleRetryMerge(db *gorm.DB, msg models.Message, logger *slog.Logger) {
-	handleRetryMergeWithBus(db, msg, logger, nil)
+	handleRetryMergeWithBus(db, clock.Real, msg, logger, nil)
 }
 
 // handleRetryMergeWithBus sets a blocked car's status back to "done" so the
@@ -78,7 +79,7 @@ func handleRetryMerge(db *gorm.DB, msg models.Message, logger *slog.Logger) {
 //
 // When bus is non-nil, publishes a [plugin.YardmasterAction] event with
 // ActionType="retry-merge".
-func handleRetryMergeWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger, bus events.Bus) {
+func handleRetryMergeWithBus(db *gorm.DB, clk clock.Clock, msg models.Message, logger *slog.Logger, bus events.Bus) {
 	if msg.CarID == "" {
 		logger.Info("Action retry-merge: no car-id provided, skipping")
 		return
@@ -93,8 +94,8 @@ func handleRetryMergeWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 	// Epics don't have branches — they close when all children are done.
 	if car.Type == "epic" {
 		logger.Info("Action retry-merge: car is an epic, attempting auto-close", "car", msg.CarID, "reason", msg.Body)
-		TryCloseEpic(db, msg.CarID)
-		if err := writeProgressNote(db, msg.CarID, "dispatch", fmt.Sprintf("Retry merge (epic auto-close attempted): %s", msg.Body)); err != nil {
+		TryCloseEpic(db, clk, msg.CarID)
+		if err := writeProgressNote(db, clk, msg.CarID, "dispatch", fmt.Sprintf("Retry merge (epic auto-close attempted): %s", msg.Body)); err != nil {
 			logger.Error("Action retry-merge: progress note failed", "error", err)
 		}
 		publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
@@ -118,7 +119,7 @@ func handleRetryMergeWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 	if retries := countMergeRetries(db, msg.CarID); retries >= maxMergeRetries {
 		logger.Warn("Action retry-merge: retry limit reached, escalating to human instead of re-arming",
 			"car", msg.CarID, "retries", retries, "reason", msg.Body)
-		if err := writeProgressNote(db, msg.CarID, "dispatch",
+		if err := writeProgressNote(db, clk, msg.CarID, "dispatch",
 			fmt.Sprintf("Retry merge refused after %d attempts — escalating to human: %s", retries, msg.Body)); err != nil {
 			logger.Error("Action retry-merge: progress note failed", "error", err)
 		}
@@ -144,7 +145,7 @@ func handleRetryMergeWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 		return
 	}
 
-	if err := writeProgressNote(db, msg.CarID, "dispatch", fmt.Sprintf("Retry merge requested: %s", msg.Body)); err != nil {
+	if err := writeProgressNote(db, clk, msg.CarID, "dispatch", fmt.Sprintf("Retry merge requested: %s", msg.Body)); err != nil {
 		logger.Error("Action retry-merge: progress note failed", "error", err)
 	}
 
@@ -174,7 +175,7 @@ func countMergeRetries(db *gorm.DB, carID string) int {
 // handleRequeueCar is a thin wrapper around [handleRequeueCarWithBus] that
 // passes a nil bus. Existing tests call this form.
 func handleRequeueCar(db *gorm.DB, msg models.Message, logger *slog.Logger) {
-	handleRequeueCarWithBus(db, msg, logger, nil)
+	handleRequeueCarWithBus(db, clock.Real, msg, logger, nil)
 }
 
 // handleRequeueCarWithBus sets a car's status to "open" and clears the assignee,
@@ -182,7 +183,7 @@ func handleRequeueCar(db *gorm.DB, msg models.Message, logger *slog.Logger) {
 //
 // When bus is non-nil, publishes a [plugin.YardmasterAction] event with
 // ActionType="requeue-car".
-func handleRequeueCarWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger, bus events.Bus) {
+func handleRequeueCarWithBus(db *gorm.DB, clk clock.Clock, msg models.Message, logger *slog.Logger, bus events.Bus) {
 	if msg.CarID == "" {
 		logger.Info("Action requeue-car: no car-id provided, skipping")
 		return
@@ -191,14 +192,15 @@ func handleRequeueCarWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 	logger.Info("Action requeue-car: requeuing car", "car", msg.CarID, "reason", msg.Body)
 
 	if err := db.Model(&models.Car{}).Where("id = ?", msg.CarID).Updates(map[string]interface{}{
-		"status":   "open",
-		"assignee": "",
+		"status":         "open",
+		"assignee":       "",
+		"blocked_reason": "",
 	}).Error; err != nil {
 		logger.Error("Action requeue-car: update car failed", "car", msg.CarID, "error", err)
 		return
 	}
 
-	if err := writeProgressNote(db, msg.CarID, "dispatch", fmt.Sprintf("Requeued: %s", msg.Body)); err != nil {
+	if err := writeProgressNote(db, clk, msg.CarID, "dispatch", fmt.Sprintf("Requeued: %s", msg.Body)); err != nil {
 		logger.Error("Action requeue-car: progress note failed", "error", err)
 	}
 
@@ -211,7 +213,7 @@ func handleRequeueCarWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 // handleNudgeEngine is a thin wrapper around [handleNudgeEngineWithBus] that
 // passes a nil bus. Existing tests call this form.
 func handleNudgeEngine(db *gorm.DB, msg models.Message, logger *slog.Logger) {
-	handleNudgeEngineWithBus(db, msg, logger, nil)
+	handleNudgeEngineWithBus(db, clock.Real, msg, logger, nil)
 }
 
 // handleNudgeEngineWithBus forwards guidance from dispatch to the engine currently
@@ -219,7 +221,7 @@ func handleNudgeEngine(db *gorm.DB, msg models.Message, logger *slog.Logger) {
 //
 // When bus is non-nil, publishes a [plugin.YardmasterAction] event with
 // ActionType="nudge-engine".
-func handleNudgeEngineWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger, bus events.Bus) {
+func handleNudgeEngineWithBus(db *gorm.DB, clk clock.Clock, msg models.Message, logger *slog.Logger, bus events.Bus) {
 	if msg.CarID == "" {
 		logger.Info("Action nudge-engine: no car-id provided, skipping")
 		return
@@ -249,14 +251,14 @@ func handleNudgeEngineWithBus(db *gorm.DB, msg models.Message, logger *slog.Logg
 // handleUnblockCar is a thin wrapper around [handleUnblockCarWithBus] that
 // passes a nil bus. Existing tests call this form.
 func handleUnblockCar(db *gorm.DB, msg models.Message, logger *slog.Logger) {
-	handleUnblockCarWithBus(db, msg, logger, nil)
+	handleUnblockCarWithBus(db, clock.Real, msg, logger, nil)
 }
 
 // handleUnblockCarWithBus transitions a blocked car back to "open" status.
 //
 // When bus is non-nil, publishes a [plugin.YardmasterAction] event with
 // ActionType="unblock-car".
-func handleUnblockCarWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger, bus events.Bus) {
+func handleUnblockCarWithBus(db *gorm.DB, clk clock.Clock, msg models.Message, logger *slog.Logger, bus events.Bus) {
 	if msg.CarID == "" {
 		logger.Info("Action unblock-car: no car-id provided, skipping")
 		return
@@ -282,7 +284,7 @@ func handleUnblockCarWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 		return
 	}
 
-	if err := writeProgressNote(db, msg.CarID, "dispatch", fmt.Sprintf("Manually unblocked: %s", msg.Body)); err != nil {
+	if err := writeProgressNote(db, clk, msg.CarID, "dispatch", fmt.Sprintf("Manually unblocked: %s", msg.Body)); err != nil {
 		logger.Error("Action unblock-car: progress note failed", "error", err)
 	}
 
@@ -295,7 +297,7 @@ func handleUnblockCarWithBus(db *gorm.DB, msg models.Message, logger *slog.Logge
 // handleCloseEpic is a thin wrapper around [handleCloseEpicWithBus] that
 // passes a nil bus. Existing tests call this form.
 func handleCloseEpic(db *gorm.DB, msg models.Message, logger *slog.Logger) {
-	handleCloseEpicWithBus(db, msg, logger, nil)
+	handleCloseEpicWithBus(db, clock.Real, msg, logger, nil)
 }
 
 // handleCloseEpicWithBus attempts to auto-close an epic whose children are all
@@ -303,7 +305,7 @@ func handleCloseEpic(db *gorm.DB, msg models.Message, logger *slog.Logger) {
 //
 // When bus is non-nil, publishes a [plugin.YardmasterAction] event with
 // ActionType="close-epic".
-func handleCloseEpicWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger, bus events.Bus) {
+func handleCloseEpicWithBus(db *gorm.DB, clk clock.Clock, msg models.Message, logger *slog.Logger, bus events.Bus) {
 	if msg.CarID == "" {
 		logger.Info("Action close-epic: no car-id provided, skipping")
 		return
@@ -320,8 +322,8 @@ func handleCloseEpicWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger
 	}
 
 	logger.Info("Action close-epic: attempting auto-close", "epic", msg.CarID, "reason", msg.Body)
-	TryCloseEpic(db, msg.CarID)
-	if err := writeProgressNote(db, msg.CarID, "dispatch", fmt.Sprintf("Close epic requested: %s", msg.Body)); err != nil {
+	TryCloseEpic(db, clk, msg.CarID)
+	if err := writeProgressNote(db, clk, msg.CarID, "dispatch", fmt.Sprintf("Close epic requested: %s", msg.Body)); err != nil {
 		logger.Error("Action close-epic: progress note failed", "error", err)
 	}
 
@@ -331,14 +333,51 @@ func handleCloseEpicWithBus(db *gorm.DB, msg models.Message, logger *slog.Logger
 	})
 }
 
+// handleRevertCar reverts the merged car in msg.CarID via [RevertCar]. It is
+// the target of the one-shot revert offered from telegraph escalations; the
+// revert car then goes through the normal merge gate on the next cycle.
+//
+// When bus is non-nil and the revert car is created, publishes a
+// [plugin.YardmasterAction] event with ActionType="revert-car".
+func handleRevertCar(db *gorm.DB, clk clock.Clock, cfg *config.Config, repoDir string, msg models.Message, logger *slog.Logger, bus events.Bus) {
+	if msg.CarID == "" {
+		logger.Info("Action revert-car: no car-id provided, skipping")
+		return
+	}
+
+	result, err := RevertCar(db, msg.CarID, RevertOpts{
+		RepoDir:      repoDir,
+		Config:       cfg,
+		BranchPrefix: cfg.BranchPrefix,
+		IDFormat:     car.IDFormatFromConfig(cfg),
+		RequestedBy:  msg.FromAgent,
+		Reason:       msg.Body,
+		Clock:        clk,
+	})
+	if err != nil {
+		logger.Error("Action revert-car: revert failed", "car", msg.CarID, "error", err)
+		return
+	}
+	if result.Conflict {
+		logger.Warn("Action revert-car: revert conflicts, left open for an engine", "car", msg.CarID, "revert_car", result.RevertCar.ID)
+	} else {
+		logger.Info("Action revert-car: revert queued for merge", "car", msg.CarID, "revert_car", result.RevertCar.ID, "commit", result.MergeCommit)
+	}
+
+	publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+		TargetID:   msg.CarID,
+		ActionType: "revert-car",
+	})
+}
+
 // writeProgressNote creates a CarProgress record documenting an action.
-func writeProgressNote(db *gorm.DB, carID, engineID, note string) error {
+func writeProgressNote(db *gorm.DB, clk clock.Clock, carID, engineID, note string) error {
 	if err := db.Create(&models.CarProgress{
 		CarID:        carID,
 		EngineID:     engineID,
 		Note:         note,
 		FilesChanged: "[]",
-		CreatedAt:    time.Now(),
+		CreatedAt:    clk.Now(),
 	}).Error; err != nil {
 		return fmt.Errorf("yardmaster: progress note for car %s: %w", carID, err)
 	}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/logutil"
 	"github.com/zulandar/railyard/internal/models"
 )
@@ -146,9 +147,9 @@ func TestHandleRetryMerge_RejectsOpenStatus(t *testing.T) {
 func TestCountMergeRetries(t *testing.T) {
 	db := testDB(t)
 	db.Create(&models.Car{ID: "car-cmr1", Type: "task", Status: "merge-failed", Track: "backend"})
-	writeProgressNote(db, "car-cmr1", "dispatch", "Retry merge requested: a")
-	writeProgressNote(db, "car-cmr1", "dispatch", "Retry merge requested: b")
-	writeProgressNote(db, "car-cmr1", "yardmaster", "switch:test-failed: boom") // not a retry
+	writeProgressNote(db, clock.Real, "car-cmr1", "dispatch", "Retry merge requested: a")
+	writeProgressNote(db, clock.Real, "car-cmr1", "dispatch", "Retry merge requested: b")
+	writeProgressNote(db, clock.Real, "car-cmr1", "yardmaster", "switch:test-failed: boom") // not a retry
 
 	if got := countMergeRetries(db, "car-cmr1"); got != 2 {
 		t.Errorf("countMergeRetries = %d, want 2", got)
@@ -161,8 +162,8 @@ func TestHandleRetryMerge_AllowsRetriesBelowLimit(t *testing.T) {
 
 	// Two prior retries — still under the limit. A config/env fix is a
 	// legitimate retry and does not change the branch HEAD.
-	writeProgressNote(db, "car-loop2", "dispatch", "Retry merge requested: fixed env")
-	writeProgressNote(db, "car-loop2", "dispatch", "Retry merge requested: fixed env again")
+	writeProgressNote(db, clock.Real, "car-loop2", "dispatch", "Retry merge requested: fixed env")
+	writeProgressNote(db, clock.Real, "car-loop2", "dispatch", "Retry merge requested: fixed env again")
 
 	var buf bytes.Buffer
 	logger := actTestLogger(&buf)
@@ -183,7 +184,7 @@ func TestHandleRetryMerge_EscalatesAfterMaxRetries(t *testing.T) {
 	// maxMergeRetries prior retry-merge requests that each re-failed: the loop
 	// is futile, so the next retry must alert a human instead of re-arming.
 	for i := 0; i < maxMergeRetries; i++ {
-		writeProgressNote(db, "car-loop1", "dispatch", "Retry merge requested: transient?")
+		writeProgressNote(db, clock.Real, "car-loop1", "dispatch", "Retry merge requested: transient?")
 	}
 
 	var buf bytes.Buffer
@@ -0,0 +1,223 @@
+package yardmaster
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// AdoptOpts configures AdoptBranch.
+type AdoptOpts struct {
+	RepoDir     string         // main repository; the branch lives there or, for a track with its own repo, in the track's clone (locally or on origin)
+	Config      *config.Config // tracks to infer the car's track from, and each track's repo and default_branch
+	BaseBranch  string         // branch the car merges into (default: the track's default_branch, else "main")
+	Track       string         // explicit track; skips inference
+	Title       string         // car title; default is the branch's first commit subject
+	IDFormat    car.IDFormat   // ID format for the new car
+	RequestedBy string         // who adopted the branch
+	Clock       clock.Clock    // time source for timestamps; nil uses clock.Real
+}
+
+// AdoptResult is the outcome of AdoptBranch.
+type AdoptResult struct {
+	Car          *models.Car
+	ChangedFiles []string // files the branch changes relative to its base
+	Commits      int      // commits on the branch not on the base
+	Inferred     bool     // the track was inferred from ChangedFiles
+}
+
+// AdoptBranch creates a car for a branch that was started outside Railyard.
+// The car points at the branch as-is (no engine ever works on it) and is
+// marked done, so the yardmaster runs it through the normal merge gate like
+// any agent-produced car. Unless opts.Track is set, the track is the one whose
+// file_patterns match the most changed files; only tracks in the main repo
+// are candidates, so a branch in a track's own repo needs opts.Track.
+func AdoptBranch(db *gorm.DB, branch string, opts AdoptOpts) (*AdoptResult, error) {
+	if db == nil {
+		return nil, fmt.Errorf("yardmaster: db is required")
+	}
+	if opts.RepoDir == "" {
+		return nil, fmt.Errorf("yardmaster: repoDir is required")
+	}
+	branch = strings.TrimPrefix(strings.TrimSpace(branch), "origin/")
+	if branch == "" {
+		return nil, fmt.Errorf("adopt: branch is required")
+	}
+	clk := clock.OrReal(opts.Clock)
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = carBaseBranch(opts.Config, &models.Car{Track: opts.Track})
+	}
+	if branch == baseBranch {
+		return nil, fmt.Errorf("adopt: %s is the base branch", branch)
+	}
+
+	var existing models.Car
+	if err := db.Where("branch = ? AND status NOT IN ?", branch, []string{"cancelled"}).First(&existing).Error; err == nil {
+		return nil, fmt.Errorf("adopt: branch %s already belongs to car %s (%s)", branch, existing.ID, existing.Status)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("adopt: check existing cars: %w", err)
+	}
+
+	repoDir, err := trackRepoDir(opts.Config, opts.RepoDir, opts.Track)
+	if err != nil {
+		return nil, fmt.Errorf("adopt: %w", err)
+	}
+	if err := gitFetch(repoDir); err != nil {
+		return nil, fmt.Errorf("adopt: %w", err)
+	}
+	if err := ensureLocalBranch(repoDir, branch); err != nil {
+		return nil, err
+	}
+	base := "origin/" + baseBranch
+	if _, err := gitOutput(repoDir, "rev-parse", "--verify", "--quiet", base); err != nil {
+		base = baseBranch
+	}
+
+	out, err := gitOutput(repoDir, "log", "--reverse", "--format=%s", base+".."+branch)
+	if err != nil {
+		return nil, fmt.Errorf("adopt: list commits on %s: %w", branch, err)
+	}
+	if out == "" {
+		return nil, fmt.Errorf("adopt: %s has no commits that are not already on %s", branch, baseBranch)
+	}
+	subjects := strings.Split(out, "\n")
+	files, err := gitOutput(repoDir, "diff", "--name-only", base+"..."+branch)
+	if err != nil {
+		return nil, fmt.Errorf("adopt: diff %s against %s: %w", branch, baseBranch, err)
+	}
+	result := &AdoptResult{Commits: len(subjects)}
+	if files != "" {
+		result.ChangedFiles = strings.Split(files, "\n")
+	}
+
+	track := opts.Track
+	if track == "" {
+		if track, err = inferTrack(mainRepoTracks(opts.Config), result.ChangedFiles); err != nil {
+			return nil, err
+		}
+		result.Inferred = true
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = subjects[0]
+	}
+	desc := fmt.Sprintf("Adopted from branch %s (%d commits, %d files changed).\n\nCommits:\n- %s",
+		branch, len(subjects), len(result.ChangedFiles), strings.Join(subjects, "\n- "))
+
+	note := fmt.Sprintf("Adopted branch %s; queued for the merge gate", branch)
+	if opts.RequestedBy != "" {
+		note = fmt.Sprintf("Adopted branch %s for %s; queued for the merge gate", branch, opts.RequestedBy)
+	}
+
+	// The work is already on the branch: the car is created pointing at it
+	// and done, in one transaction, so no engine ever sees it claimable and
+	// it goes straight to the merge gate.
+	var c *models.Car
+	now := clk.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if c, err = car.Create(tx, car.CreateOpts{
+			Title:       title,
+			Description: desc,
+			Track:       track,
+			BaseBranch:  baseBranch,
+			IDFormat:    opts.IDFormat,
+			RequestedBy: opts.RequestedBy,
+		}); err != nil {
+			return fmt.Errorf("adopt: create car: %w", err)
+		}
+		if err := tx.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+			"branch":       branch,
+			"status":       "done",
+			"completed_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("adopt: link car %s to %s: %w", c.ID, branch, err)
+		}
+		return writeProgressNote(tx, clk, c.ID, YardmasterID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.Branch = branch
+	c.Status = "done"
+	c.CompletedAt = &now
+	result.Car = c
+	return result, nil
+}
+
+// ensureLocalBranch makes sure branch exists locally, creating a tracking
+// branch when it exists only on origin, so Switch can check it out.
+func ensureLocalBranch(repoDir, branch string) error {
+	if _, err := gitOutput(repoDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return nil
+	}
+	remote := "origin/" + branch
+	if _, err := gitOutput(repoDir, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remote); err != nil {
+		return fmt.Errorf("adopt: branch %s not found locally or on origin", branch)
+	}
+	if out, err := gitCombined(repoDir, "branch", "--track", branch, remote); err != nil {
+		return fmt.Errorf("adopt: track %s: %s: %w", remote, out, err)
+	}
+	return nil
+}
+
+// inferTrack picks the track whose file_patterns match the most files. With a
+// single configured track there is nothing to infer.
+func inferTrack(tracks []config.TrackConfig, files []string) (string, error) {
+	if len(tracks) == 1 {
+		return tracks[0].Name, nil
+	}
+	scores := make(map[string]int)
+	for _, t := range tracks {
+		for _, f := range files {
+			for _, p := range t.FilePatterns {
+				if matchFilePattern(p, f) {
+					scores[t.Name]++
+					break
+				}
+			}
+		}
+	}
+	if len(scores) == 0 {
+		return "", fmt.Errorf("adopt: no track's file_patterns match the branch's changes; pass --track")
+	}
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if scores[names[i]] != scores[names[j]] {
+			return scores[names[i]] > scores[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > 1 && scores[names[0]] == scores[names[1]] {
+		return "", fmt.Errorf("adopt: changes match tracks %s and %s equally; pass --track", names[0], names[1])
+	}
+	return names[0], nil
+}
+
+// matchFilePattern reports whether file matches a track file_patterns glob.
+// "dir/**" matches everything under dir; a pattern without a slash matches
+// the base name at any depth ("*.go"); anything else is a path.Match glob.
+func matchFilePattern(pattern, file string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(file, dir+"/")
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
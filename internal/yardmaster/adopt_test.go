@@ -0,0 +1,161 @@
+package yardmaster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+var adoptTestConfig = &config.Config{Tracks: []config.TrackConfig{
+	{Name: "backend", FilePatterns: []string{"internal/**", "*.go"}},
+	{Name: "frontend", FilePatterns: []string{"web/**", "*.ts"}},
+}}
+
+func TestAdoptBranch_RemoteBranchThroughMergeGate(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+
+	// A hand-made branch that exists only on origin.
+	run(repoDir, "git", "checkout", "-b", "alice/fix-login")
+	writeFile(t, repoDir, "internal/auth.go", "package auth")
+	run(repoDir, "git", "add", ".")
+	run(repoDir, "git", "commit", "-m", "Fix login timeout")
+	writeFile(t, repoDir, "web/login.ts", "export {}")
+	run(repoDir, "git", "add", ".")
+	run(repoDir, "git", "commit", "-m", "Tweak login form")
+	writeFile(t, repoDir, "internal/session.go", "package auth")
+	run(repoDir, "git", "add", ".")
+	run(repoDir, "git", "commit", "-m", "Extend session")
+	run(repoDir, "git", "push", "origin", "alice/fix-login")
+	run(repoDir, "git", "checkout", "main")
+	run(repoDir, "git", "branch", "-D", "alice/fix-login")
+
+	db := testDB(t)
+	result, err := AdoptBranch(db, "origin/alice/fix-login", AdoptOpts{
+		RepoDir:     repoDir,
+		Config:      adoptTestConfig,
+		RequestedBy: "alice",
+	})
+	if err != nil {
+		t.Fatalf("AdoptBranch: %v", err)
+	}
+	c := result.Car
+	if c.Track != "backend" || !result.Inferred {
+		t.Errorf("track = %q (inferred %v), want inferred backend", c.Track, result.Inferred)
+	}
+	if c.Title != "Fix login timeout" || c.Branch != "alice/fix-login" || c.Status != "done" {
+		t.Errorf("car = %+v", c)
+	}
+	if result.Commits != 3 || len(result.ChangedFiles) != 3 {
+		t.Errorf("commits = %d, files = %v", result.Commits, result.ChangedFiles)
+	}
+
+	var stored models.Car
+	db.First(&stored, "id = ?", c.ID)
+	if stored.Branch != "alice/fix-login" || stored.Status != "done" {
+		t.Errorf("stored car = branch %q status %q", stored.Branch, stored.Status)
+	}
+
+	// The yardmaster merges it like any other done car.
+	sw, err := Switch(db, c.ID, SwitchOpts{RepoDir: repoDir, TestCommand: "true"})
+	if err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+	if !sw.Merged {
+		t.Errorf("expected adopted branch to merge; result = %+v", sw)
+	}
+
+	if _, err := AdoptBranch(db, "alice/fix-login", AdoptOpts{RepoDir: repoDir, Config: adoptTestConfig}); err == nil ||
+		!strings.Contains(err.Error(), "already belongs to car "+c.ID) {
+		t.Errorf("second adopt error = %v, want already belongs", err)
+	}
+}
+
+func TestAdoptBranch_FailureLeavesNoCar(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "alice/fix")
+	writeFile(t, repoDir, "internal/auth.go", "package auth")
+	run(repoDir, "git", "add", ".")
+	run(repoDir, "git", "commit", "-m", "Fix auth")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	// Without a progress table the last write of the adoption fails.
+	if err := db.Migrator().DropTable(&models.CarProgress{}); err != nil {
+		t.Fatalf("drop car_progress: %v", err)
+	}
+	if _, err := AdoptBranch(db, "alice/fix", AdoptOpts{RepoDir: repoDir, Config: adoptTestConfig}); err == nil {
+		t.Fatal("AdoptBranch succeeded without a progress table")
+	}
+	var n int64
+	db.Model(&models.Car{}).Count(&n)
+	if n != 0 {
+		t.Errorf("cars = %d, want the adoption rolled back", n)
+	}
+}
+
+func TestAdoptBranch_Errors(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+	db := testDB(t)
+
+	if _, err := AdoptBranch(db, "nope", AdoptOpts{RepoDir: repoDir}); err == nil || !strings.Contains(err.Error(), "not found locally or on origin") {
+		t.Errorf("missing branch error = %v", err)
+	}
+	if _, err := AdoptBranch(db, "main", AdoptOpts{RepoDir: repoDir}); err == nil || !strings.Contains(err.Error(), "is the base branch") {
+		t.Errorf("base branch error = %v", err)
+	}
+
+	run(repoDir, "git", "branch", "empty")
+	if _, err := AdoptBranch(db, "empty", AdoptOpts{RepoDir: repoDir}); err == nil || !strings.Contains(err.Error(), "no commits") {
+		t.Errorf("empty branch error = %v", err)
+	}
+}
+
+func TestInferTrack(t *testing.T) {
+	tests := []struct {
+		name    string
+		tracks  []config.TrackConfig
+		files   []string
+		want    string
+		wantErr string
+	}{
+		{"single track", adoptTestConfig.Tracks[:1], []string{"README.md"}, "backend", ""},
+		{"majority", adoptTestConfig.Tracks, []string{"web/a.ts", "web/b.ts", "main.go"}, "frontend", ""},
+		{"tie", adoptTestConfig.Tracks, []string{"web/a.ts", "main.go"}, "", "equally"},
+		{"no match", adoptTestConfig.Tracks, []string{"README.md"}, "", "pass --track"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inferTrack(tt.tracks, tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("inferTrack = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFilePattern(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"internal/**", "internal/car/car.go", true},
+		{"internal/**", "internalx/a.go", false},
+		{"*.go", "cmd/ry/main.go", true},
+		{"*.go", "README.md", false},
+		{"web/*.ts", "web/app.ts", true},
+		{"web/*.ts", "web/sub/app.ts", false},
+	}
+	for _, tt := range tests {
+		if got := matchFilePattern(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("matchFilePattern(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package yardmaster
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultBisectMerges is how many recent car merges Bisect searches when no
+// known-good commit is given.
+const DefaultBisectMerges = 20
+
+// BisectOpts configures a regression bisect over the base branch's merges.
+type BisectOpts struct {
+	RepoDir     string // repository to bisect; left untouched (bisect runs in a temporary worktree)
+	BaseBranch  string // branch whose merge history is searched (default "main")
+	TestCommand string // shell command; exit 0 = good, 125 = skip, anything else = bad
+	Good        string // known-good commit; default: before the oldest of the last MaxMerges car merges
+	MaxMerges   int    // car merges to search when Good is unset (default DefaultBisectMerges)
+}
+
+// BisectMerge is a first-parent merge commit on the base branch.
+type BisectMerge struct {
+	Commit  string
+	Subject string
+	CarID   string // from the Car-ID trailer or the merged branch name; empty for non-car commits
+}
+
+// BisectResult is the outcome of a bisect.
+type BisectResult struct {
+	Bad        BisectMerge // the first bad first-parent commit
+	Car        *models.Car // the car that merged Bad; nil when unknown
+	Good       string      // the known-good commit the search started from
+	Candidates int         // first-parent commits between Good and the branch head
+	Log        string      // git bisect output
+}
+
+var (
+	carIDTrailerRe = regexp.MustCompile(`(?m)^Car-ID:\s*(\S+)\s*$`)
+	mergeSubjectRe = regexp.MustCompile(`^Switch: merge (\S+) to \S+`)
+	firstBadRe     = regexp.MustCompile(`(?m)^([0-9a-f]{40}) is the first bad commit`)
+)
+
+// Bisect finds the merge that broke the base branch. It walks the branch's
+// first-parent history (one step per merge), confirms the test command fails
+// at the head and passes at the good commit, then runs `git bisect run` in a
+// temporary detached worktree and maps the first bad merge back to its car.
+// db may be nil, in which case only the car ID is reported.
+func Bisect(db *gorm.DB, opts BisectOpts) (*BisectResult, error) {
+	if opts.RepoDir == "" {
+		return nil, fmt.Errorf("yardmaster: repoDir is required")
+	}
+	if opts.TestCommand == "" {
+		return nil, fmt.Errorf("yardmaster: bisect test command is required")
+	}
+	if opts.BaseBranch == "" {
+		opts.BaseBranch = "main"
+	}
+	if opts.MaxMerges <= 0 {
+		opts.MaxMerges = DefaultBisectMerges
+	}
+
+	bad, err := gitOutput(opts.RepoDir, "rev-parse", "--verify", opts.BaseBranch+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("bisect: resolve %s: %w", opts.BaseBranch, err)
+	}
+	good := opts.Good
+	if good == "" {
+		good, err = defaultBisectGood(opts.RepoDir, bad, opts.MaxMerges)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if good, err = gitOutput(opts.RepoDir, "rev-parse", "--verify", good+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("bisect: resolve good commit: %w", err)
+	}
+
+	candidates, err := gitOutput(opts.RepoDir, "rev-list", "--first-parent", "--count", good+".."+bad)
+	if err != nil {
+		return nil, fmt.Errorf("bisect: count commits: %w", err)
+	}
+	result := &BisectResult{Good: good}
+	fmt.Sscanf(candidates, "%d", &result.Candidates)
+	if result.Candidates == 0 {
+		return nil, fmt.Errorf("bisect: %s has no commits after %s", opts.BaseBranch, shortSHA(good))
+	}
+
+	wt, err := os.MkdirTemp("", "railyard-bisect-")
+	if err != nil {
+		return nil, fmt.Errorf("bisect: temp dir: %w", err)
+	}
+	os.Remove(wt) // git worktree add creates it
+	if out, err := gitCombined(opts.RepoDir, "worktree", "add", "--detach", wt, bad); err != nil {
+		return nil, fmt.Errorf("bisect: add worktree: %s: %w", out, err)
+	}
+	defer func() {
+		if out, err := gitCombined(opts.RepoDir, "worktree", "remove", "--force", wt); err != nil {
+			slog.Warn("bisect: remove worktree", "dir", wt, "output", out, "error", err)
+		}
+	}()
+
+	// git bisect trusts both endpoints; check them so a flaky or pre-existing
+	// failure is reported instead of blamed on an arbitrary merge.
+	if out, err := runBisectTest(wt, opts.TestCommand); err == nil {
+		return nil, fmt.Errorf("bisect: tests pass at %s head %s; nothing to bisect", opts.BaseBranch, shortSHA(bad))
+	} else if isBisectSkip(err) {
+		return nil, fmt.Errorf("bisect: test command skipped (exit 125) at %s head:\n%s", opts.BaseBranch, truncateOutput(out, 500))
+	}
+	if out, err := gitCombined(wt, "checkout", "--quiet", "--detach", good); err != nil {
+		return nil, fmt.Errorf("bisect: checkout good commit: %s: %w", out, err)
+	}
+	if out, err := runBisectTest(wt, opts.TestCommand); err != nil {
+		return nil, fmt.Errorf("bisect: tests also fail at good commit %s; pass an older --good:\n%s", shortSHA(good), truncateOutput(out, 500))
+	}
+
+	if out, err := gitCombined(wt, "bisect", "start", "--first-parent", bad, good); err != nil {
+		return nil, fmt.Errorf("bisect: start: %s: %w", out, err)
+	}
+	defer gitCombined(wt, "bisect", "reset")
+
+	out, err := gitCombined(wt, "bisect", "run", "sh", "-c", opts.TestCommand)
+	result.Log = out
+	m := firstBadRe.FindStringSubmatch(out)
+	if m == nil {
+		if err != nil {
+			return result, fmt.Errorf("bisect: run: %w", err)
+		}
+		return result, fmt.Errorf("bisect: no first bad commit found (too many skipped commits?)")
+	}
+
+	result.Bad, err = describeMerge(opts.RepoDir, m[1])
+	if err != nil {
+		return result, err
+	}
+	if db != nil && result.Bad.CarID != "" {
+		var c models.Car
+		if err := db.First(&c, "id = ?", result.Bad.CarID).Error; err == nil {
+			result.Car = &c
+		}
+	}
+	return result, nil
+}
+
+// defaultBisectGood returns the first parent of the oldest of the last n car
+// merges on bad's first-parent history.
+func defaultBisectGood(repoDir, bad string, n int) (string, error) {
+	out, err := gitOutput(repoDir, "log", "--first-parent", "--format=%H%x00%B%x1e", bad)
+	if err != nil {
+		return "", fmt.Errorf("bisect: list history: %w", err)
+	}
+	var oldest string
+	found := 0
+	for _, entry := range strings.Split(out, "\x1e") {
+		sha, msg, ok := strings.Cut(strings.TrimSpace(entry), "\x00")
+		if !ok || parseMergeMessage(sha, msg).CarID == "" {
+			continue
+		}
+		oldest = sha
+		if found++; found == n {
+			break
+		}
+	}
+	if oldest == "" {
+		return "", fmt.Errorf("bisect: no car merges found; pass --good")
+	}
+	parent, err := gitOutput(repoDir, "rev-parse", "--verify", oldest+"^1")
+	if err != nil {
+		return "", fmt.Errorf("bisect: oldest car merge %s has no parent; pass --good", shortSHA(oldest))
+	}
+	return parent, nil
+}
+
+// describeMerge reads a commit's subject and the car it merged: the Car-ID
+// trailer, or for merges made before trailers the car ID at the end of the
+// "Switch: merge <branch>" subject.
+func describeMerge(repoDir, sha string) (BisectMerge, error) {
+	msg, err := gitOutput(repoDir, "log", "-1", "--format=%B", sha)
+	if err != nil {
+		return BisectMerge{}, fmt.Errorf("bisect: read commit %s: %w", shortSHA(sha), err)
+	}
+	return parseMergeMessage(sha, msg), nil
+}
+
+func parseMergeMessage(sha, msg string) BisectMerge {
+	subject, _, _ := strings.Cut(msg, "\n")
+	merge := BisectMerge{Commit: sha, Subject: subject}
+	if m := carIDTrailerRe.FindStringSubmatch(msg); m != nil {
+		merge.CarID = m[1]
+	} else if m := mergeSubjectRe.FindStringSubmatch(subject); m != nil {
+		branch := m[1]
+		merge.CarID = branch[strings.LastIndex(branch, "/")+1:]
+	}
+	return merge
+}
+
+// runBisectTest runs the test command in dir.
+func runBisectTest(dir, command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// isBisectSkip reports whether a test exited 125, git bisect's "skip" code.
+func isBisectSkip(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 125
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func gitCombined(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
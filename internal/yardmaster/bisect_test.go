@@ -0,0 +1,107 @@
+package yardmaster
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// mergeCars merges one car branch per ID into main the way Switch does. The
+// car listed in breaking adds the file the bisect test rejects.
+func mergeCars(t *testing.T, repoDir string, run func(args ...string), carIDs []string, breaking string, trailers bool) {
+	t.Helper()
+	for _, id := range carIDs {
+		branch := "ry/alice/backend/" + id
+		run("git", "checkout", "-b", branch)
+		name := "feature-" + id + ".txt"
+		if id == breaking {
+			name = "broken"
+		}
+		writeFile(t, repoDir, name, id)
+		run("git", "add", name)
+		run("git", "commit", "-m", "work on "+id)
+		run("git", "checkout", "main")
+
+		msg := fmt.Sprintf("Switch: merge %s to main", branch)
+		if trailers {
+			msg += fmt.Sprintf("\n\nCar %s\n\nCar-ID: %s\nTrack: backend", id, id)
+		}
+		run("git", "merge", "--no-ff", "-m", msg, branch)
+	}
+}
+
+func TestBisect_FindsOffendingCar(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	mergeCars(t, repoDir, run, []string{"car-bs1", "car-bs2", "car-bs3", "car-bs4", "car-bs5"}, "car-bs3", true)
+
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-bs3", Title: "Refactor config", Track: "backend", Status: "merged"})
+
+	result, err := Bisect(db, BisectOpts{RepoDir: repoDir, TestCommand: "test ! -f broken"})
+	if err != nil {
+		t.Fatalf("Bisect: %v", err)
+	}
+	if result.Bad.CarID != "car-bs3" {
+		t.Errorf("Bad = %+v, want car-bs3", result.Bad)
+	}
+	if result.Car == nil || result.Car.Title != "Refactor config" {
+		t.Errorf("Car = %+v, want car-bs3 from the db", result.Car)
+	}
+	if result.Candidates != 5 {
+		t.Errorf("Candidates = %d, want 5 (one per merge)", result.Candidates)
+	}
+
+	// The caller's checkout is untouched and no worktree is left behind.
+	if head, _ := gitOutput(repoDir, "rev-parse", "--abbrev-ref", "HEAD"); head != "main" {
+		t.Errorf("HEAD = %q, want main", head)
+	}
+	if wts, _ := gitOutput(repoDir, "worktree", "list"); strings.Count(wts, "\n") != 0 {
+		t.Errorf("worktrees left behind:\n%s", wts)
+	}
+}
+
+func TestBisect_LegacySubjectAndMaxMerges(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	mergeCars(t, repoDir, run, []string{"car-lg1", "car-lg2", "car-lg3", "car-lg4"}, "car-lg4", false)
+
+	result, err := Bisect(nil, BisectOpts{RepoDir: repoDir, TestCommand: "test ! -f broken", MaxMerges: 2})
+	if err != nil {
+		t.Fatalf("Bisect: %v", err)
+	}
+	if result.Bad.CarID != "car-lg4" || result.Car != nil {
+		t.Errorf("result = %+v, want car-lg4 without a db lookup", result.Bad)
+	}
+	if result.Candidates != 2 {
+		t.Errorf("Candidates = %d, want 2", result.Candidates)
+	}
+}
+
+func TestBisect_EndpointChecks(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	mergeCars(t, repoDir, run, []string{"car-ep1", "car-ep2"}, "", true)
+
+	if _, err := Bisect(nil, BisectOpts{RepoDir: repoDir, TestCommand: "true"}); err == nil || !strings.Contains(err.Error(), "nothing to bisect") {
+		t.Errorf("passing head: err = %v", err)
+	}
+	if _, err := Bisect(nil, BisectOpts{RepoDir: repoDir, TestCommand: "false"}); err == nil || !strings.Contains(err.Error(), "also fail at good commit") {
+		t.Errorf("failing good: err = %v", err)
+	}
+}
+
+func TestParseMergeMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"Switch: merge ry/alice/backend/car-a to main\n\nTitle\n\nCar-ID: car-b\nTrack: backend", "car-b"},
+		{"Switch: merge ry/alice/backend/car-c to main", "car-c"},
+		{"Fix typo in README", ""},
+	}
+	for _, tt := range tests {
+		if got := parseMergeMessage("abc", tt.msg).CarID; got != tt.want {
+			t.Errorf("parseMergeMessage(%q).CarID = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
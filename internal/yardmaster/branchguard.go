@@ -0,0 +1,74 @@
+package yardmaster
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// checkCarBranch reports why a car's branch on origin is no longer the work
+// the engine pushed, or "" when it is intact: the branch was deleted, or it
+// was rewritten so it no longer contains car.PushedHead. Cars with no
+// recorded push (created before it was tracked, or never pushed through
+// ry complete) are not checked.
+func checkCarBranch(repoDir string, car *models.Car) string {
+	if car.PushedHead == "" {
+		return ""
+	}
+	// Ask origin directly: a remote-tracking ref survives the branch's
+	// deletion until a pruning fetch.
+	cmd := exec.Command("git", "ls-remote", "--heads", "origin", "refs/heads/"+car.Branch)
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		// Origin unreachable: the fetch would have failed first, and the
+		// merge will surface anything else.
+		return ""
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return fmt.Sprintf("branch %s was deleted from origin outside Railyard", car.Branch)
+	}
+	remoteHead := fields[0]
+	if remoteHead == car.PushedHead {
+		return ""
+	}
+	ancestor := exec.Command("git", "merge-base", "--is-ancestor", car.PushedHead, remoteHead)
+	ancestor.Dir = repoDir
+	if err := ancestor.Run(); err != nil {
+		return fmt.Sprintf("branch %s was rewritten on origin outside Railyard: it no longer contains %.12s, the commit the engine pushed", car.Branch, car.PushedHead)
+	}
+	return ""
+}
+
+// flagBranchChanged moves a done car to needs-attention because its branch
+// changed outside Railyard, and tells a human. The recorded push is cleared
+// so that once someone restores the branch and moves the car back to done,
+// the branch as it now stands is what gets merged.
+func flagBranchChanged(db *gorm.DB, car *models.Car, reason string, bus events.Bus, result *SwitchResult) {
+	result.FailureCategory = SwitchFailBranch
+	result.Error = fmt.Errorf("%s", reason)
+	slog.Warn("Switch: car branch changed outside Railyard", "car", car.ID, "branch", car.Branch, "reason", reason)
+
+	if err := db.Model(&models.Car{}).Where("id = ? AND status = ?", car.ID, "done").Updates(map[string]interface{}{
+		"status":      "needs-attention",
+		"pushed_head": "",
+	}).Error; err != nil {
+		slog.Error("update car to needs-attention", "car", car.ID, "error", err)
+	}
+	publish(bus, plugin.MergeFailed, plugin.MergeFailedEvent{
+		CarID:  car.ID,
+		Reason: reason,
+	})
+	messaging.Send(db, "yardmaster", "human", "needs-attention",
+		fmt.Sprintf("Car %s needs attention: %s. Restore the branch and run `ry car update %s --status done` to retry the merge, or `--status open` to have an engine redo the work.", car.ID, reason, car.ID),
+		messaging.SendOpts{CarID: car.ID, Priority: "urgent"},
+	)
+}
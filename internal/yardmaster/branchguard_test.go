@@ -0,0 +1,83 @@
+package yardmaster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestSwitch_BranchChangedOutsideRailyard(t *testing.T) {
+	const branch = "ry/alice/backend/car-bg1"
+	tests := []struct {
+		name   string
+		change func(run func(dir string, args ...string), repoDir string)
+		want   string // reason substring; "" means the merge goes ahead
+	}{
+		{"intact", func(func(string, ...string), string) {}, ""},
+		{"extended", func(run func(string, ...string), repoDir string) {
+			run(repoDir, "git", "checkout", branch)
+			writeFile(t, repoDir, "more.txt", "more")
+			run(repoDir, "git", "add", "more.txt")
+			run(repoDir, "git", "commit", "-m", "more work")
+			run(repoDir, "git", "push", "origin", branch)
+			run(repoDir, "git", "checkout", "main")
+		}, ""},
+		{"deleted", func(run func(string, ...string), repoDir string) {
+			run(repoDir, "git", "push", "origin", "--delete", branch)
+		}, "deleted from origin"},
+		{"rewritten", func(run func(string, ...string), repoDir string) {
+			run(repoDir, "git", "checkout", branch)
+			run(repoDir, "git", "reset", "--hard", "main")
+			writeFile(t, repoDir, "other.txt", "other")
+			run(repoDir, "git", "add", "other.txt")
+			run(repoDir, "git", "commit", "-m", "someone else's work")
+			run(repoDir, "git", "push", "--force", "origin", branch)
+			run(repoDir, "git", "checkout", "main")
+		}, "rewritten on origin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir, _, run := initTestRepoWithRemote(t)
+			run(repoDir, "git", "checkout", "-b", branch)
+			writeFile(t, repoDir, "feature.txt", "feature")
+			run(repoDir, "git", "add", "feature.txt")
+			run(repoDir, "git", "commit", "-m", "feature work")
+			run(repoDir, "git", "push", "origin", branch)
+			pushed, err := gitOutput(repoDir, "rev-parse", "HEAD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			run(repoDir, "git", "checkout", "main")
+			tt.change(run, repoDir)
+
+			db := testDB(t)
+			db.Create(&models.Car{ID: "car-bg1", Title: "Guard", Track: "backend", Branch: branch, Status: "done", PushedHead: pushed})
+
+			result, err := Switch(db, "car-bg1", SwitchOpts{RepoDir: repoDir, TestCommand: "true"})
+			if err != nil {
+				t.Fatalf("Switch: %v", err)
+			}
+			var c models.Car
+			db.First(&c, "id = ?", "car-bg1")
+
+			if tt.want == "" {
+				if !result.Merged || c.Status != "merged" {
+					t.Errorf("Merged = %v, status = %q; want merged", result.Merged, c.Status)
+				}
+				return
+			}
+			if result.FailureCategory != SwitchFailBranch || !strings.Contains(result.Error.Error(), tt.want) {
+				t.Errorf("category = %q, error = %v; want %s containing %q", result.FailureCategory, result.Error, SwitchFailBranch, tt.want)
+			}
+			if c.Status != "needs-attention" || c.PushedHead != "" {
+				t.Errorf("status = %q, pushed_head = %q; want needs-attention with the head cleared", c.Status, c.PushedHead)
+			}
+			var msgs []models.Message
+			db.Where("to_agent = ? AND subject = ?", "human", "needs-attention").Find(&msgs)
+			if len(msgs) != 1 {
+				t.Errorf("human messages = %d, want 1", len(msgs))
+			}
+		})
+	}
+}
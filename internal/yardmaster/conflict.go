@@ -0,0 +1,251 @@
+package yardmaster
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// ConflictOpts configures SpawnConflictCar.
+type ConflictOpts struct {
+	RepoDir       string       // the car's track repository, with an origin remote; left untouched (the merge is built in a temporary worktree)
+	DefaultBranch string       // base branch when the car has none, i.e. its track's default_branch; default "main"
+	BranchPrefix  string       // branch prefix for the conflict car, e.g. "ry/alice"
+	IDFormat      car.IDFormat // ID format for the conflict car
+	Details       string       // conflict context from the failed switch, recorded on the conflict car
+	Clock         clock.Clock  // time source for timestamps; nil uses clock.Real
+}
+
+// ConflictResult is the outcome of SpawnConflictCar.
+type ConflictResult struct {
+	Original    *models.Car // the car whose merge conflicted
+	ConflictCar *models.Car // the new car carrying the conflicted merge
+	Files       []string    // files with conflict markers on ConflictCar's branch
+	Existing    bool        // ConflictCar already existed; nothing was created
+}
+
+// SpawnConflictCar hands a car whose merge conflicted with its base branch to
+// a conflict-resolution engine. It creates a "conflict" car linked to the
+// original (ConflictOf) whose branch starts at the original's branch and
+// carries a commit merging the base branch into it, conflict markers
+// included, so the engine sees both sides in its worktree. The original is
+// blocked until the conflict car merges, at which point it is marked merged
+// too. If the base branch has moved and the merge is now clean, the conflict
+// car goes straight to the merge gate.
+//
+// A car that already has a live conflict car gets that car back (Existing).
+// Conflict cars do not spawn conflict cars of their own. The conflict car
+// stays a draft until its branch is pushed, and is cancelled if the hand-off
+// fails after it was created, so a failed attempt never shadows the next.
+func SpawnConflictCar(db *gorm.DB, carID string, opts ConflictOpts) (result *ConflictResult, err error) {
+	if db == nil {
+		return nil, fmt.Errorf("yardmaster: db is required")
+	}
+	if opts.RepoDir == "" {
+		return nil, fmt.Errorf("yardmaster: repoDir is required")
+	}
+	clk := clock.OrReal(opts.Clock)
+
+	var orig models.Car
+	if err := db.Where("id = ?", carID).First(&orig).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("conflict: car %w: %s", car.ErrNotFound, carID)
+		}
+		return nil, fmt.Errorf("conflict: get car %s: %w", carID, err)
+	}
+	if orig.Type == "conflict" {
+		return nil, fmt.Errorf("conflict: car %s is itself a conflict car", carID)
+	}
+	if orig.Branch == "" {
+		return nil, fmt.Errorf("conflict: car %s has no branch", carID)
+	}
+	var existing models.Car
+	if err := db.Where("conflict_of = ? AND status NOT IN ?", carID, models.TerminalStatuses).First(&existing).Error; err == nil {
+		// The original went back to the merge gate (e.g. retried by hand)
+		// while its conflict car is still live: park it again.
+		if err := blockForConflict(db, &orig); err != nil {
+			return nil, err
+		}
+		return &ConflictResult{Original: &orig, ConflictCar: &existing, Existing: true}, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("conflict: check existing conflict cars: %w", err)
+	}
+
+	baseBranch := orig.BaseBranch
+	if baseBranch == "" {
+		baseBranch = opts.DefaultBranch
+	}
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+	if err := gitFetch(opts.RepoDir); err != nil {
+		return nil, fmt.Errorf("conflict: %w", err)
+	}
+	start := "origin/" + orig.Branch
+	if _, err := gitOutput(opts.RepoDir, "rev-parse", "--verify", "--quiet", start); err != nil {
+		start = orig.Branch
+	}
+
+	desc := fmt.Sprintf("Car %s (%s) conflicts with %s. This branch starts at %s and its last commit merges %s into it with the conflict markers left in.",
+		orig.ID, orig.Title, baseBranch, orig.Branch, baseBranch)
+	if opts.Details != "" {
+		desc += "\n\n" + opts.Details
+	}
+	cc, err := car.Create(db, car.CreateOpts{
+		Title:        fmt.Sprintf("Resolve merge conflict: %s", orig.Title),
+		Description:  desc,
+		Type:         "conflict",
+		Priority:     orig.Priority,
+		Track:        orig.Track,
+		BaseBranch:   orig.BaseBranch,
+		BranchPrefix: opts.BranchPrefix,
+		IDFormat:     opts.IDFormat,
+		RequestedBy:  YardmasterID,
+		Owner:        orig.Owner,
+		ConflictOf:   orig.ID,
+		Acceptance:   fmt.Sprintf("No conflict markers remain, the changes from both %s and %s are kept, and the merge gate passes.", orig.Branch, baseBranch),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("conflict: create conflict car: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			cancelConflictCar(db, clk, cc, err)
+		}
+	}()
+	result = &ConflictResult{Original: &orig, ConflictCar: cc}
+
+	files, err := commitConflictMerge(opts.RepoDir, cc.Branch, start, "origin/"+baseBranch)
+	if err != nil {
+		return result, err
+	}
+	result.Files = files
+	if _, err := car.Publish(db, cc.ID, false); err != nil {
+		return result, fmt.Errorf("conflict: publish conflict car %s: %w", cc.ID, err)
+	}
+	cc.Status = "open"
+
+	if err := blockForConflict(db, &orig); err != nil {
+		return result, err
+	}
+	writeProgressNote(db, clk, orig.ID, YardmasterID, fmt.Sprintf("Merge conflict with %s handed to conflict car %s", baseBranch, cc.ID))
+
+	if len(files) > 0 {
+		writeProgressNote(db, clk, cc.ID, YardmasterID, fmt.Sprintf("Merged %s into %s with conflicts in: %s", baseBranch, orig.Branch, strings.Join(files, ", ")))
+		return result, nil
+	}
+
+	// The base moved and the merge is clean now: skip the engine.
+	now := clk.Now()
+	if err := db.Model(&models.Car{}).Where("id = ?", cc.ID).Updates(map[string]interface{}{
+		"status":       "done",
+		"completed_at": now,
+	}).Error; err != nil {
+		return result, fmt.Errorf("conflict: mark conflict car %s done: %w", cc.ID, err)
+	}
+	cc.Status = "done"
+	cc.CompletedAt = &now
+	writeProgressNote(db, clk, cc.ID, YardmasterID, fmt.Sprintf("Merged %s into %s cleanly; queued for the merge gate", baseBranch, orig.Branch))
+	return result, nil
+}
+
+// cancelConflictCar retires a conflict car whose hand-off failed. Left live,
+// it would be handed back as Existing on the next attempt with no branch or
+// a half-built one.
+func cancelConflictCar(db *gorm.DB, clk clock.Clock, cc *models.Car, cause error) {
+	if err := db.Model(&models.Car{}).Where("id = ?", cc.ID).Update("status", "cancelled").Error; err != nil {
+		slog.Error("conflict: cancel conflict car", "car", cc.ID, "error", err)
+		return
+	}
+	cc.Status = "cancelled"
+	writeProgressNote(db, clk, cc.ID, YardmasterID, fmt.Sprintf("Cancelled: conflict hand-off failed: %v", cause))
+}
+
+// blockForConflict parks c until its conflict car merges.
+func blockForConflict(db *gorm.DB, c *models.Car) error {
+	if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+		"status":         "blocked",
+		"blocked_reason": models.BlockedReasonMergeConflict,
+	}).Error; err != nil {
+		return fmt.Errorf("conflict: block car %s: %w", c.ID, err)
+	}
+	c.Status = "blocked"
+	c.BlockedReason = models.BlockedReasonMergeConflict
+	return nil
+}
+
+// commitConflictMerge creates branch at start in a temporary worktree, merges
+// base into it, commits the result with any conflict markers staged as-is,
+// and pushes the branch. It returns the files that conflicted.
+func commitConflictMerge(repoDir, branch, start, base string) ([]string, error) {
+	wt, err := os.MkdirTemp("", "railyard-conflict-")
+	if err != nil {
+		return nil, fmt.Errorf("conflict: temp dir: %w", err)
+	}
+	os.Remove(wt) // git worktree add creates it
+	if out, err := gitCombined(repoDir, "worktree", "add", "-b", branch, wt, start); err != nil {
+		return nil, fmt.Errorf("conflict: add worktree: %s: %w", out, err)
+	}
+	defer func() {
+		if out, err := gitCombined(repoDir, "worktree", "remove", "--force", wt); err != nil {
+			slog.Warn("conflict: remove worktree", "dir", wt, "output", out, "error", err)
+		}
+	}()
+
+	var files []string
+	if _, err := gitCombined(wt, "merge", "--no-edit", base); err != nil {
+		files = getConflictFiles(wt)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("conflict: merge %s: %w", base, err)
+		}
+		if out, err := gitCombined(wt, "add", "-A"); err != nil {
+			return nil, fmt.Errorf("conflict: stage conflicts: %s: %w", out, err)
+		}
+		msg := fmt.Sprintf("Merge %s (conflicts to resolve)\n\nConflicts:\n\t%s", base, strings.Join(files, "\n\t"))
+		if out, err := gitCombined(wt, "commit", "--no-verify", "-m", msg); err != nil {
+			return nil, fmt.Errorf("conflict: commit conflicts: %s: %w", out, err)
+		}
+	}
+
+	if err := gitPushBranch(wt, branch); err != nil {
+		return files, fmt.Errorf("conflict: %w", err)
+	}
+	return files, nil
+}
+
+// markConflictResolvedOriginal marks the car a just-merged conflict car
+// resolved as merged: the conflict car's branch carried the original's
+// commits into the base branch. It is a no-op for ordinary cars.
+func markConflictResolvedOriginal(db *gorm.DB, clk clock.Clock, c *models.Car) {
+	if c.ConflictOf == "" {
+		return
+	}
+	now := clk.Now()
+	res := db.Model(&models.Car{}).Where("id = ? AND status = ?", c.ConflictOf, "blocked").Updates(map[string]interface{}{
+		"status":         "merged",
+		"blocked_reason": "",
+		"completed_at":   now,
+	})
+	if res.Error != nil {
+		slog.Error("mark conflict original merged", "car", c.ConflictOf, "conflict_car", c.ID, "error", res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		return
+	}
+	slog.Info("Car merged via conflict car", "car", c.ConflictOf, "conflict_car", c.ID)
+	if err := writeProgressNote(db, clk, c.ConflictOf, YardmasterID, fmt.Sprintf("Merged via conflict car %s", c.ID)); err != nil {
+		slog.Error("mark conflict original merged: progress note", "car", c.ConflictOf, "error", err)
+	}
+	var orig models.Car
+	if err := db.Where("id = ?", c.ConflictOf).First(&orig).Error; err == nil {
+		runPostMerge(db, clk, orig, slog.Default())
+	}
+}
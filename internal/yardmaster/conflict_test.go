@@ -0,0 +1,181 @@
+package yardmaster
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// conflictingCar sets up car-cf1 whose branch edits shared.txt one way while
+// main edits it another, so its switch fails with a merge conflict.
+func conflictingCar(t *testing.T) (string, func(dir string, args ...string), *gorm.DB) {
+	t.Helper()
+	repoDir, _, run := initTestRepoWithRemote(t)
+	writeFile(t, repoDir, "shared.txt", "base\n")
+	run(repoDir, "git", "add", "shared.txt")
+	run(repoDir, "git", "commit", "-m", "add shared")
+	run(repoDir, "git", "push", "origin", "main")
+
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-cf1")
+	writeFile(t, repoDir, "shared.txt", "car change\n")
+	run(repoDir, "git", "commit", "-am", "car work")
+	run(repoDir, "git", "push", "origin", "ry/alice/backend/car-cf1")
+	run(repoDir, "git", "checkout", "main")
+	writeFile(t, repoDir, "shared.txt", "main change\n")
+	run(repoDir, "git", "commit", "-am", "main work")
+	run(repoDir, "git", "push", "origin", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{
+		ID:       "car-cf1",
+		Title:    "Shared edit",
+		Track:    "backend",
+		Branch:   "ry/alice/backend/car-cf1",
+		Status:   "done",
+		Owner:    "alice",
+		Priority: 1,
+	})
+	return repoDir, run, db
+}
+
+func TestSpawnConflictCar_ResolvedBranchMergesOriginal(t *testing.T) {
+	repoDir, run, db := conflictingCar(t)
+
+	result, err := Switch(db, "car-cf1", SwitchOpts{RepoDir: repoDir, TestCommand: "true"})
+	if err == nil || result.FailureCategory != SwitchFailMerge {
+		t.Fatalf("Switch car-cf1: category=%v err=%v, want merge conflict", result.FailureCategory, err)
+	}
+
+	spawned, err := SpawnConflictCar(db, "car-cf1", ConflictOpts{RepoDir: repoDir, BranchPrefix: "ry/alice", Details: result.ConflictDetails})
+	if err != nil {
+		t.Fatalf("SpawnConflictCar: %v", err)
+	}
+	cc := spawned.ConflictCar
+	if cc.Type != "conflict" || cc.ConflictOf != "car-cf1" || cc.Track != "backend" || cc.Owner != "alice" || cc.Priority != 1 {
+		t.Errorf("conflict car = %+v", cc)
+	}
+	if len(spawned.Files) != 1 || spawned.Files[0] != "shared.txt" {
+		t.Errorf("files = %v, want [shared.txt]", spawned.Files)
+	}
+	var stored models.Car
+	db.First(&stored, "id = ?", cc.ID)
+	if stored.Status != "open" {
+		t.Errorf("conflict car status = %q, want open for an engine", stored.Status)
+	}
+	var orig models.Car
+	db.First(&orig, "id = ?", "car-cf1")
+	if orig.Status != "blocked" || orig.BlockedReason != models.BlockedReasonMergeConflict {
+		t.Errorf("original = %s/%s, want blocked/merge-conflict", orig.Status, orig.BlockedReason)
+	}
+	content, err := gitOutput(repoDir, "show", "origin/"+cc.Branch+":shared.txt")
+	if err != nil || !strings.Contains(content, "<<<<<<<") || !strings.Contains(content, "car change") || !strings.Contains(content, "main change") {
+		t.Errorf("pushed shared.txt = %q (err=%v), want both sides with markers", content, err)
+	}
+	if wts, _ := gitOutput(repoDir, "worktree", "list"); strings.Count(wts, "\n") != 0 {
+		t.Errorf("worktrees left behind:\n%s", wts)
+	}
+
+	// Asking again returns the live conflict car.
+	again, err := SpawnConflictCar(db, "car-cf1", ConflictOpts{RepoDir: repoDir})
+	if err != nil || !again.Existing || again.ConflictCar.ID != cc.ID {
+		t.Errorf("second spawn = %+v, err = %v; want existing %s", again, err, cc.ID)
+	}
+
+	// An engine resolves the conflict and completes the car.
+	run(repoDir, "git", "checkout", cc.Branch)
+	writeFile(t, repoDir, "shared.txt", "car change\nmain change\n")
+	run(repoDir, "git", "commit", "-am", "resolve conflict")
+	run(repoDir, "git", "push", "origin", cc.Branch)
+	run(repoDir, "git", "checkout", "main")
+	db.Model(&models.Car{}).Where("id = ?", cc.ID).Update("status", "done")
+
+	merged, err := Switch(db, cc.ID, SwitchOpts{RepoDir: repoDir, TestCommand: "true"})
+	if err != nil || !merged.Merged {
+		t.Fatalf("Switch conflict car: merged=%v err=%v", merged != nil && merged.Merged, err)
+	}
+	db.First(&orig, "id = ?", "car-cf1")
+	if orig.Status != "merged" || orig.BlockedReason != "" {
+		t.Errorf("original = %s/%s, want merged", orig.Status, orig.BlockedReason)
+	}
+}
+
+func TestSpawnConflictCar_FailedPushCancelsConflictCar(t *testing.T) {
+	repoDir, _, db := conflictingCar(t)
+	bareDir, err := gitOutput(repoDir, "remote", "get-url", "origin")
+	if err != nil {
+		t.Fatalf("remote: %v", err)
+	}
+	hook := filepath.Join(bareDir, "hooks", "pre-receive")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+
+	if _, err := SpawnConflictCar(db, "car-cf1", ConflictOpts{RepoDir: repoDir, BranchPrefix: "ry/alice"}); err == nil {
+		t.Fatal("SpawnConflictCar succeeded with a rejecting remote")
+	}
+	var ccs []models.Car
+	db.Where("conflict_of = ?", "car-cf1").Find(&ccs)
+	if len(ccs) != 1 || ccs[0].Status != "cancelled" {
+		t.Fatalf("conflict cars = %+v, want one cancelled", ccs)
+	}
+	var orig models.Car
+	db.First(&orig, "id = ?", "car-cf1")
+	if orig.Status != "done" {
+		t.Errorf("original status = %q, want done (left for the next attempt)", orig.Status)
+	}
+
+	// The next attempt builds a fresh conflict car rather than finding the
+	// failed one.
+	os.Remove(hook)
+	spawned, err := SpawnConflictCar(db, "car-cf1", ConflictOpts{RepoDir: repoDir, BranchPrefix: "ry/alice"})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if spawned.Existing || spawned.ConflictCar.ID == ccs[0].ID {
+		t.Errorf("retry reused the failed conflict car %s", ccs[0].ID)
+	}
+}
+
+func TestSpawnConflictCar_Refusals(t *testing.T) {
+	repoDir, _, _ := initTestRepoWithRemote(t)
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-cf2", Title: "Resolver", Track: "backend", Type: "conflict", Branch: "b", Status: "done"})
+	db.Create(&models.Car{ID: "car-cf3", Title: "No branch", Track: "backend", Status: "done"})
+
+	for id, want := range map[string]string{
+		"car-cf2":     "itself a conflict car",
+		"car-cf3":     "has no branch",
+		"car-missing": "not found",
+	} {
+		if _, err := SpawnConflictCar(db, id, ConflictOpts{RepoDir: repoDir}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("SpawnConflictCar(%s) err = %v, want %q", id, err, want)
+		}
+	}
+}
+
+func TestMaybeSpawnConflictCar_ConfigGate(t *testing.T) {
+	repoDir, _, db := conflictingCar(t)
+	var buf bytes.Buffer
+	cfg := testConfig(config.TrackConfig{Name: "backend"})
+	c := models.Car{ID: "car-cf1", Type: "task"}
+
+	if maybeSpawnConflictCar(db, clock.Real, cfg, &c, repoDir, "", testLogger(&buf)) {
+		t.Error("spawned with conflict_assist off")
+	}
+	cfg.Yardmaster.ConflictAssist = true
+	if !maybeSpawnConflictCar(db, clock.Real, cfg, &c, repoDir, "", testLogger(&buf)) {
+		t.Fatalf("did not spawn with conflict_assist on:\n%s", buf.String())
+	}
+	var n int64
+	db.Model(&models.Car{}).Where("conflict_of = ?", "car-cf1").Count(&n)
+	if n != 1 {
+		t.Errorf("conflict cars = %d, want 1", n)
+	}
+}
@@ -0,0 +1,217 @@
+package yardmaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// maxContentProblems caps the problems listed in a content check report.
+const maxContentProblems = 50
+
+// reMarkdownLink matches inline Markdown links and images, capturing the
+// target: [text](target "title").
+var reMarkdownLink = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// checkTrackContent runs the built-in checks of a docs or config track on
+// the files the car's branch changes, and blocks the car for its engine
+// like a test failure when any fail. It reports whether the car was
+// blocked. Checks that cannot run are logged and do not block the merge.
+func checkTrackContent(db *gorm.DB, car *models.Car, opts SwitchOpts, baseBranch string, result *SwitchResult) bool {
+	report, checked, err := contentCheck(opts.RepoDir, car.Branch, baseBranch, opts.TrackType)
+	if err != nil {
+		slog.Warn("Switch: content checks not run", "car", car.ID, "track_type", opts.TrackType, "error", err)
+		return false
+	}
+	slog.Info("Switch: content checked", "car", car.ID, "track_type", opts.TrackType, "files", checked)
+	if report == "" {
+		return false
+	}
+
+	result.TestsPassed = false
+	result.TestOutput = report
+	result.FailureCategory = SwitchFailTest
+	result.Error = fmt.Errorf("%s checks failed", opts.TrackType)
+	slog.Warn("Switch: content checks failed", "car", car.ID, "track_type", opts.TrackType)
+	if opts.DryRun {
+		return true
+	}
+
+	if dbErr := db.Model(&models.Car{}).Where("id = ?", car.ID).Updates(map[string]interface{}{
+		"status":         "blocked",
+		"blocked_reason": models.BlockedReasonTestFailed,
+	}).Error; dbErr != nil {
+		slog.Error("update car to blocked", "car", car.ID, "error", dbErr)
+	}
+	if car.Assignee != "" {
+		messaging.Send(db, "yardmaster", car.Assignee, "test-failure",
+			fmt.Sprintf("%s checks failed for car %s on branch %s:\n%s", opts.TrackType, car.ID, car.Branch, report),
+			messaging.SendOpts{CarID: car.ID, Priority: "urgent"},
+		)
+	}
+	return true
+}
+
+// contentCheck checks the files branch changes against baseBranch, reading
+// them from git rather than a checkout: for docs tracks, relative links in
+// Markdown must point at files or directories on the branch; for config
+// tracks, YAML and JSON files must parse. It returns one line per problem
+// (empty when there are none) and how many files were checked.
+func contentCheck(repoDir, branch, baseBranch, trackType string) (string, int, error) {
+	base := firstRef(repoDir, "origin/"+baseBranch, baseBranch)
+	head := firstRef(repoDir, "origin/"+branch, branch)
+	if base == "" || head == "" {
+		return "", 0, fmt.Errorf("resolve %s or %s", baseBranch, branch)
+	}
+	changed, err := gitLines(repoDir, "diff", "--name-only", "--diff-filter=d", base+"..."+head)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var problems []string
+	checked := 0
+	switch trackType {
+	case config.TrackTypeDocs:
+		tree, err := gitLines(repoDir, "ls-tree", "-r", "-t", "--name-only", head)
+		if err != nil {
+			return "", 0, err
+		}
+		exists := make(map[string]bool, len(tree))
+		for _, p := range tree {
+			exists[p] = true
+		}
+		for _, file := range changed {
+			if !isMarkdown(file) {
+				continue
+			}
+			content, err := gitShow(repoDir, head, file)
+			if err != nil {
+				return "", 0, err
+			}
+			checked++
+			problems = append(problems, brokenLinks(file, content, exists)...)
+		}
+	case config.TrackTypeConfig:
+		for _, file := range changed {
+			ext := strings.ToLower(path.Ext(file))
+			if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+				continue
+			}
+			content, err := gitShow(repoDir, head, file)
+			if err != nil {
+				return "", 0, err
+			}
+			checked++
+			if err := parseConfigFile(ext, content); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", file, err))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return "", checked, nil
+	}
+	if len(problems) > maxContentProblems {
+		problems = append(problems[:maxContentProblems], fmt.Sprintf("... and %d more", len(problems)-maxContentProblems))
+	}
+	return strings.Join(problems, "\n") + "\n", checked, nil
+}
+
+func isMarkdown(file string) bool {
+	ext := strings.ToLower(path.Ext(file))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// brokenLinks returns the relative links in a Markdown file that point at
+// paths missing from exists. External links and in-page anchors are not
+// checked; a leading / is taken from the repository root.
+func brokenLinks(file string, content []byte, exists map[string]bool) []string {
+	var broken []string
+	for i, line := range strings.Split(string(content), "\n") {
+		for _, m := range reMarkdownLink.FindAllStringSubmatch(line, -1) {
+			target := m[1]
+			if strings.HasPrefix(target, "#") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+				continue
+			}
+			target, _, _ = strings.Cut(target, "#")
+			target, _, _ = strings.Cut(target, "?")
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			var resolved string
+			if strings.HasPrefix(target, "/") {
+				resolved = path.Clean(strings.TrimPrefix(target, "/"))
+			} else {
+				resolved = path.Join(path.Dir(file), target)
+			}
+			if resolved == "." || exists[resolved] {
+				continue
+			}
+			broken = append(broken, fmt.Sprintf("%s:%d: broken link %s", file, i+1, m[1]))
+		}
+	}
+	return broken
+}
+
+// parseConfigFile reports whether content parses as ext's format. Every
+// document of a multi-document YAML file is parsed.
+func parseConfigFile(ext string, content []byte) error {
+	if ext == ".json" {
+		var v any
+		return json.Unmarshal(content, &v)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// gitLines runs git in repoDir and returns its non-empty output lines.
+func gitLines(repoDir string, args ...string) ([]string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	var lines []string
+	for _, l := range strings.Split(string(out), "\n") {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, nil
+}
+
+// gitShow returns file's content at rev.
+func gitShow(repoDir, rev, file string) ([]byte, error) {
+	cmd := exec.Command("git", "show", rev+":"+file)
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show %s:%s: %w", rev, file, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package yardmaster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestContentCheck_Docs(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	writeFile(t, repoDir, "docs/setup.md", "# Setup\n")
+	writeFile(t, repoDir, "README.md", "See [setup](docs/setup.md).\n")
+	run("git", "add", "-A")
+	run("git", "commit", "-m", "docs")
+
+	run("git", "checkout", "-b", "ry/docs")
+	writeFile(t, repoDir, "docs/guide.md", strings.Join([]string{
+		"# Guide",
+		"Start with [setup](setup.md#install) and the [site](https://example.com/x.md).",
+		"Jump [down](#usage), see ![diagram](img/flow.png) and [the root](/README.md).",
+		"Old link: [api](api.md).",
+		"[dir](../docs/)",
+	}, "\n"))
+	writeFile(t, repoDir, "notes.txt", "[not markdown](missing.md)\n")
+	run("git", "add", "-A")
+	run("git", "commit", "-m", "guide")
+	run("git", "checkout", "main")
+
+	report, checked, err := contentCheck(repoDir, "ry/docs", "main", config.TrackTypeDocs)
+	if err != nil {
+		t.Fatalf("contentCheck: %v", err)
+	}
+	if checked != 1 {
+		t.Errorf("checked %d files, want only the changed Markdown file", checked)
+	}
+	want := "docs/guide.md:3: broken link img/flow.png\ndocs/guide.md:4: broken link api.md\n"
+	if report != want {
+		t.Errorf("report:\n%s\nwant:\n%s", report, want)
+	}
+}
+
+func TestContentCheck_Config(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	run("git", "checkout", "-b", "ry/config")
+	writeFile(t, repoDir, "deploy/app.yaml", "replicas: 2\n---\nkind: Service\n")
+	writeFile(t, repoDir, "deploy/bad.yml", "replicas: [2\n")
+	writeFile(t, repoDir, "deploy/flags.json", `{"beta": true,}`)
+	writeFile(t, repoDir, "deploy/notes.md", "anything")
+	run("git", "add", "-A")
+	run("git", "commit", "-m", "config")
+	run("git", "checkout", "main")
+
+	report, checked, err := contentCheck(repoDir, "ry/config", "main", config.TrackTypeConfig)
+	if err != nil {
+		t.Fatalf("contentCheck: %v", err)
+	}
+	if checked != 3 {
+		t.Errorf("checked %d files, want 3", checked)
+	}
+	if !strings.Contains(report, "deploy/bad.yml:") || !strings.Contains(report, "deploy/flags.json:") || strings.Contains(report, "app.yaml") {
+		t.Errorf("report:\n%s", report)
+	}
+}
+
+func TestCheckTrackContent_BlocksCar(t *testing.T) {
+	db := testDB(t)
+	repoDir, run := initTestRepo(t)
+	run("git", "checkout", "-b", "ry/docs")
+	writeFile(t, repoDir, "guide.md", "[gone](gone.md)\n")
+	run("git", "add", "-A")
+	run("git", "commit", "-m", "guide")
+	run("git", "checkout", "main")
+
+	car := models.Car{ID: "car-docs", Title: "Guide", Track: "handbook", Status: "done", Branch: "ry/docs", Assignee: "eng-1"}
+	db.Create(&car)
+	result := &SwitchResult{}
+	opts := SwitchOpts{RepoDir: repoDir, TrackType: config.TrackTypeDocs}
+	if !checkTrackContent(db, &car, opts, "main", result) {
+		t.Fatal("checkTrackContent = false, want the car blocked")
+	}
+	if result.FailureCategory != SwitchFailTest || !strings.Contains(result.TestOutput, "broken link gone.md") {
+		t.Errorf("result = %+v", result)
+	}
+	var got models.Car
+	db.First(&got, "id = ?", car.ID)
+	if got.Status != "blocked" || got.BlockedReason != models.BlockedReasonTestFailed {
+		t.Errorf("car = %s/%s, want blocked/test-failed", got.Status, got.BlockedReason)
+	}
+	var msg models.Message
+	if err := db.Where("to_agent = ?", "eng-1").First(&msg).Error; err != nil || !strings.Contains(msg.Body, "docs checks failed") {
+		t.Errorf("engine message = %+v, %v", msg, err)
+	}
+}
@@ -0,0 +1,133 @@
+package yardmaster
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// measureCoverage reads the coverage percentage for a test run: from the
+// cover profile in repoDir when configured, otherwise from the last match of
+// the coverage regex in the test output.
+func measureCoverage(cfg *config.CoverageConfig, repoDir, output string) (float64, error) {
+	if cfg.Profile != "" {
+		data, err := os.ReadFile(filepath.Join(repoDir, cfg.Profile))
+		if err != nil {
+			return 0, fmt.Errorf("read coverage profile: %w", err)
+		}
+		return parseCoverProfile(data)
+	}
+
+	re, err := regexp.Compile(cfg.Regex)
+	if err != nil {
+		return 0, fmt.Errorf("coverage regex: %w", err)
+	}
+	matches := re.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 || len(matches[len(matches)-1]) < 2 {
+		return 0, fmt.Errorf("no coverage found in test output")
+	}
+	pct, err := strconv.ParseFloat(matches[len(matches)-1][1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse coverage %q: %w", matches[len(matches)-1][1], err)
+	}
+	return pct, nil
+}
+
+// parseCoverProfile returns the statement coverage of a Go cover profile.
+// Blocks repeated across packages (e.g. with -coverpkg) count once, covered
+// if any occurrence was hit.
+func parseCoverProfile(data []byte) (float64, error) {
+	type block struct {
+		stmts   int
+		covered bool
+	}
+	blocks := make(map[string]*block)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// file.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("malformed cover profile line %q", line)
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("malformed cover profile line %q", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, fmt.Errorf("malformed cover profile line %q", line)
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{stmts: stmts}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+
+	var total, covered int
+	for _, b := range blocks {
+		total += b.stmts
+		if b.covered {
+			covered += b.stmts
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("cover profile has no statements")
+	}
+	return float64(covered) / float64(total) * 100, nil
+}
+
+// coverageBaseline returns the track's coverage at its most recent merge —
+// the latest record of the most recently merged car — or nil when no merged
+// car on the track has coverage yet.
+func coverageBaseline(db *gorm.DB, track string) *float64 {
+	var rec models.CoverageRecord
+	err := db.Model(&models.CoverageRecord{}).
+		Select("coverage_records.*").
+		Joins("JOIN cars ON cars.id = coverage_records.car_id").
+		Where("coverage_records.track = ? AND cars.status = ?", track, "merged").
+		Order("cars.completed_at DESC, coverage_records.id DESC").
+		Take(&rec).Error
+	if err != nil {
+		return nil
+	}
+	return &rec.Coverage
+}
+
+// newCoverageRecord builds the coverage record for a car against its track's
+// baseline.
+func newCoverageRecord(db *gorm.DB, car *models.Car, coverage float64) *models.CoverageRecord {
+	rec := &models.CoverageRecord{CarID: car.ID, Track: car.Track, Coverage: coverage}
+	if base := coverageBaseline(db, car.Track); base != nil {
+		rec.Baseline = base
+		rec.Delta = coverage - *base
+	}
+	return rec
+}
+
+// coverageDropError returns an error when the record drops coverage by more
+// than maxDrop percentage points. A maxDrop of 0 disables the gate.
+func coverageDropError(rec *models.CoverageRecord, maxDrop float64) error {
+	if maxDrop <= 0 || rec.Baseline == nil || -rec.Delta <= maxDrop {
+		return nil
+	}
+	return fmt.Errorf("coverage dropped %.1f points (%.1f%% -> %.1f%%), more than max_drop %.1f",
+		-rec.Delta, *rec.Baseline, rec.Coverage, maxDrop)
+}
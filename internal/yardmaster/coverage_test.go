@@ -0,0 +1,180 @@
+package yardmaster
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+func coverageDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.CoverageRecord{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/app/a.go:3.14,5.2 2 1
+example.com/app/a.go:7.14,9.2 3 0
+example.com/app/b.go:3.14,5.2 5 0
+example.com/app/b.go:3.14,5.2 5 1
+`
+	got, err := parseCoverProfile([]byte(profile))
+	if err != nil {
+		t.Fatalf("parseCoverProfile: %v", err)
+	}
+	// 7 of 10 statements: b.go's repeated block counts once, covered.
+	if math.Abs(got-70) > 1e-9 {
+		t.Errorf("coverage = %v, want 70", got)
+	}
+
+	if _, err := parseCoverProfile([]byte("mode: set\n")); err == nil {
+		t.Error("expected error for empty profile")
+	}
+	if _, err := parseCoverProfile([]byte("mode: set\na.go:1.1,2.2 x 1\n")); err == nil {
+		t.Error("expected error for malformed line")
+	}
+}
+
+func TestMeasureCoverage_RegexUsesLastMatch(t *testing.T) {
+	cfg := &config.CoverageConfig{Regex: `coverage: ([\d.]+)% of statements`}
+	output := "ok  app/a  coverage: 50.0% of statements\nok  app/b  coverage: 81.3% of statements\n"
+	got, err := measureCoverage(cfg, t.TempDir(), output)
+	if err != nil {
+		t.Fatalf("measureCoverage: %v", err)
+	}
+	if got != 81.3 {
+		t.Errorf("coverage = %v, want 81.3", got)
+	}
+
+	if _, err := measureCoverage(cfg, t.TempDir(), "PASS\n"); err == nil {
+		t.Error("expected error when output has no coverage")
+	}
+}
+
+func TestCoverageDropError(t *testing.T) {
+	base := 80.0
+	tests := []struct {
+		name    string
+		rec     models.CoverageRecord
+		maxDrop float64
+		wantErr bool
+	}{
+		{"no baseline", models.CoverageRecord{Coverage: 10}, 1, false},
+		{"gate off", models.CoverageRecord{Coverage: 10, Baseline: &base, Delta: -70}, 0, false},
+		{"within threshold", models.CoverageRecord{Coverage: 79, Baseline: &base, Delta: -1}, 1, false},
+		{"over threshold", models.CoverageRecord{Coverage: 78.5, Baseline: &base, Delta: -1.5}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := coverageDropError(&tt.rec, tt.maxDrop); (err != nil) != tt.wantErr {
+				t.Errorf("coverageDropError = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCoverageBaseline_LatestMergedCar(t *testing.T) {
+	db := coverageDB(t)
+	older := time.Now().Add(-48 * time.Hour)
+	newer := time.Now().Add(-time.Hour)
+	db.Create(&models.Car{ID: "car-b1", Title: "Old", Track: "backend", Status: "merged", CompletedAt: &newer})
+	db.Create(&models.Car{ID: "car-b2", Title: "Older", Track: "backend", Status: "merged", CompletedAt: &older})
+	db.Create(&models.Car{ID: "car-b3", Title: "Blocked", Track: "backend", Status: "blocked"})
+	db.Create(&models.CoverageRecord{CarID: "car-b1", Track: "backend", Coverage: 75})
+	db.Create(&models.CoverageRecord{CarID: "car-b2", Track: "backend", Coverage: 90})
+	db.Create(&models.CoverageRecord{CarID: "car-b3", Track: "backend", Coverage: 10})
+
+	base := coverageBaseline(db, "backend")
+	if base == nil || *base != 75 {
+		t.Errorf("baseline = %v, want 75", base)
+	}
+	if base := coverageBaseline(db, "frontend"); base != nil {
+		t.Errorf("frontend baseline = %v, want nil", *base)
+	}
+}
+
+func TestSwitch_CoverageGate(t *testing.T) {
+	tests := []struct {
+		name       string
+		coverage   string
+		wantMerged bool
+	}{
+		{"drop blocks merge", "70.0", false},
+		{"small drop merges", "79.5", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir, _, run := initTestRepoWithRemote(t)
+			run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-cv1")
+			writeFile(t, repoDir, "feature-cv1.txt", "coverage feature")
+			run(repoDir, "git", "add", "feature-cv1.txt")
+			run(repoDir, "git", "commit", "-m", "feature work")
+			run(repoDir, "git", "checkout", "main")
+
+			db := coverageDB(t)
+			merged := time.Now().Add(-time.Hour)
+			db.Create(&models.Car{ID: "car-cv0", Title: "Earlier", Track: "backend", Status: "merged", CompletedAt: &merged})
+			db.Create(&models.CoverageRecord{CarID: "car-cv0", Track: "backend", Coverage: 80})
+			db.Create(&models.Car{
+				ID:       "car-cv1",
+				Title:    "Coverage test",
+				Track:    "backend",
+				Branch:   "ry/alice/backend/car-cv1",
+				Status:   "done",
+				Assignee: "eng-1",
+			})
+
+			result, err := Switch(db, "car-cv1", SwitchOpts{
+				RepoDir:     repoDir,
+				TestCommand: "echo 'total: (statements) " + tt.coverage + "%'",
+				Coverage:    &config.CoverageConfig{Regex: `total:\s+\(statements\)\s+([\d.]+)%`, MaxDrop: 1},
+			})
+			if err != nil {
+				t.Fatalf("Switch returned error: %v", err)
+			}
+			if result.Merged != tt.wantMerged {
+				t.Fatalf("Merged = %v, want %v (error: %v)", result.Merged, tt.wantMerged, result.Error)
+			}
+			if result.Coverage == nil || result.Coverage.Baseline == nil || *result.Coverage.Baseline != 80 {
+				t.Fatalf("Coverage = %+v, want baseline 80", result.Coverage)
+			}
+
+			var rec models.CoverageRecord
+			if err := db.Where("car_id = ?", "car-cv1").First(&rec).Error; err != nil {
+				t.Fatalf("coverage record not stored: %v", err)
+			}
+
+			var c models.Car
+			db.First(&c, "id = ?", "car-cv1")
+			if tt.wantMerged {
+				if c.Status != "merged" {
+					t.Errorf("status = %q, want merged", c.Status)
+				}
+				if base := coverageBaseline(db, "backend"); base == nil || *base != rec.Coverage {
+					t.Errorf("baseline after merge = %v, want %v", base, rec.Coverage)
+				}
+				return
+			}
+			if result.FailureCategory != SwitchFailCoverage {
+				t.Errorf("FailureCategory = %q, want %q", result.FailureCategory, SwitchFailCoverage)
+			}
+			if c.Status != "blocked" || c.BlockedReason != models.BlockedReasonCoverageDropped {
+				t.Errorf("car = %s/%s, want blocked/%s", c.Status, c.BlockedReason, models.BlockedReasonCoverageDropped)
+			}
+			var msg models.Message
+			db.Where("to_agent = ? AND car_id = ?", "eng-1", "car-cv1").First(&msg)
+			if !strings.Contains(msg.Body, "coverage dropped 10.0 points") {
+				t.Errorf("engine message = %q", msg.Body)
+			}
+		})
+	}
+}
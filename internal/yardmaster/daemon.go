@@ -13,12 +13,16 @@ import (
 	"time"
 
 	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/retention"
+	"github.com/zulandar/railyard/internal/webhook"
+	"github.com/zulandar/railyard/internal/yard"
 	"github.com/zulandar/railyard/pkg/plugin"
 	"gorm.io/gorm"
 )
@@ -44,6 +48,12 @@ func RunDaemon(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath,
 // CarMerged, MergeFailed) — see spec §6.3. Passing nil disables publishing and
 // matches the behavior of the OSS binary that does not configure plugins.
 func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath, repoDir string, pollInterval time.Duration, logger *slog.Logger, bus events.Bus, statusProvider StatusProvider) error {
+	return runDaemon(ctx, db, clock.Real, cfg, configPath, repoDir, pollInterval, logger, bus, statusProvider)
+}
+
+// runDaemon is [RunDaemonWithBus] with clk as the time source for polling,
+// staleness, and cooldown logic.
+func runDaemon(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath, repoDir string, pollInterval time.Duration, logger *slog.Logger, bus events.Bus, statusProvider StatusProvider) error {
 	if db == nil {
 		return fmt.Errorf("yardmaster: db is required")
 	}
@@ -60,8 +70,8 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 		logger = slog.Default()
 	}
 
-	startedAt := time.Now()
-	if err := registerYardmaster(db, cfg.AgentProvider); err != nil {
+	startedAt := clk.Now()
+	if err := registerYardmaster(db, clk, cfg.AgentProvider); err != nil {
 		return fmt.Errorf("yardmaster: register: %w", err)
 	}
 	logger.Info("Yardmaster registered", "id", YardmasterID)
@@ -79,12 +89,24 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 	hbErrCh := engine.StartHeartbeat(ctx, db, YardmasterID, engine.DefaultHeartbeatInterval)
 
 	hs := NewHealthServer(pollInterval)
+	hs.SetClock(clk)
 	go func() {
 		if err := StartHealthServer(ctx, cfg.Yardmaster.HealthPort, hs, statusProvider); err != nil {
 			logger.Error("Health server error", "error", err)
 		}
 	}()
 
+	// The dispatcher runs even with no webhooks configured, since any car
+	// can have its own (ry car webhook add).
+	wh, err := webhook.New(db, cfg, logger)
+	if err != nil {
+		return fmt.Errorf("yardmaster: %w", err)
+	}
+	go wh.Run(ctx, webhook.DefaultPollInterval)
+	if len(cfg.Webhooks) > 0 {
+		logger.Info("Webhooks enabled", "count", len(cfg.Webhooks))
+	}
+
 	logger.Info("Yardmaster daemon starting", "poll", pollInterval)
 
 	defer func() {
@@ -96,6 +118,12 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 	}()
 
 	rbState := &rebalanceState{lastTrackMoveAt: make(map[string]time.Time)}
+	autoscaler := orchestration.NewAutoscaler(db, cfg, configPath)
+	janitor := &retention.Janitor{DB: db, Config: cfg.Retention}
+	draftState := newDraftPRState()
+	gh := newGitHubClient(logger)
+	draftOps := draftPROpsFor(cfg, gh)
+	forge := apiForge(cfg, gh)
 
 	// Track background escalation goroutines so shutdown waits for them.
 	var escWg sync.WaitGroup
@@ -103,10 +131,14 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 
 	// Per-car escalation cooldown tracker.
 	escTracker := NewEscalationTracker(time.Duration(cfg.Stall.EscalationCooldownSec) * time.Second)
+	escTracker.SetClock(clk)
 
 	// Semaphore to limit concurrent escalation goroutines.
 	escSem := make(chan struct{}, cfg.Stall.MaxConcurrentEscalations)
 
+	// Whether the previous iteration saw the yard paused, to log transitions.
+	wasPaused := false
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -129,9 +161,9 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 
 			// timePhase uses tiered logging based on phase duration.
 			timePhase := func(name string, fn func()) {
-				start := time.Now()
+				start := clk.Now()
 				fn()
-				elapsed := time.Since(start)
+				elapsed := clk.Since(start)
 				if elapsed > 5*time.Second {
 					logger.Warn("Phase slow", "phase", name, "elapsed", elapsed)
 				} else if elapsed > time.Second {
@@ -145,7 +177,7 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 			var shouldDrain bool
 			timePhase("inbox", func() {
 				var pErr error
-				shouldDrain, pErr = processInboxWithBus(ctx, db, cfg, configPath, repoDir, startedAt, &escWg, escTracker, escSem, logger, bus)
+				shouldDrain, pErr = processInboxWithBus(ctx, db, clk, cfg, configPath, repoDir, startedAt, &escWg, escTracker, escSem, logger, bus)
 				if pErr != nil {
 					logger.Error("Inbox error", "error", pErr)
 				}
@@ -156,28 +188,54 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 
 			// Phase 2: Handle stale engines.
 			timePhase("stale-engines", func() {
-				if err := handleStaleEnginesWithBus(db, cfg, configPath, logger, bus); err != nil {
+				if err := handleStaleEnginesWithBus(db, clk, cfg, configPath, logger, bus); err != nil {
 					logger.Error("Stale engines error", "error", err)
 				}
 			})
 
+			// A paused yard merges nothing and moves no work around; the
+			// bookkeeping phases below still run.
+			paused := false
+			if err := yard.CheckRunning(db); err != nil {
+				paused = true
+				if !wasPaused {
+					logger.Warn("Yard paused: holding merges, preemption, and rebalancing", "state", err)
+				}
+			} else if wasPaused {
+				logger.Info("Yard resumed")
+			}
+			wasPaused = paused
+
+			// Phase 2b: Release claims an engine never started on.
+			timePhase("stale-claims", func() {
+				if paused {
+					return
+				}
+				if err := releaseStaleClaims(db, clk, cfg, ymDir, logger, bus); err != nil {
+					logger.Error("Stale claims error", "error", err)
+				}
+			})
+
 			// Phase 3: Handle completed cars.
 			timePhase("completed-cars", func() {
-				if err := handleCompletedCarsWithBus(ctx, db, cfg, configPath, repoDir, ymDir, &escWg, escTracker, escSem, logger, bus); err != nil {
+				if paused {
+					return
+				}
+				if err := handleCompletedCarsWithBus(ctx, db, clk, cfg, configPath, repoDir, ymDir, &escWg, escTracker, escSem, logger, bus, forge); err != nil {
 					logger.Error("Completed cars error", "error", err)
 				}
 			})
 
 			// Phase 4: Handle blocked cars (safety-net sweep).
 			timePhase("blocked-cars", func() {
-				if err := handleBlockedCars(db, logger); err != nil {
+				if err := handleBlockedCars(db, clk, logger); err != nil {
 					logger.Error("Blocked cars error", "error", err)
 				}
 			})
 
 			// Phase 4b: Sweep open epics whose children may all be complete.
 			timePhase("sweep-epics", func() {
-				if err := sweepOpenEpics(db, logger); err != nil {
+				if err := sweepOpenEpics(db, clk, logger); err != nil {
 					logger.Error("Sweep open epics error", "error", err)
 				}
 			})
@@ -185,37 +243,100 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 			// Phase 5: Reconcile stale cars whose branches are already merged.
 			timePhase("reconcile", func() {
 				var reconcileViewer PRViewer
-				if cfg.RequirePR {
+				if cfg.UsesPRs() {
 					reconcileViewer = &ghPRViewer{repoDir: repoDir}
 				}
-				if err := reconcileStaleCars(db, repoDir, cfg.RequirePR, reconcileViewer, logger); err != nil {
+				if err := reconcileStaleCars(db, clk, repoDir, cfg.UsesPRs(), reconcileViewer, logger); err != nil {
 					logger.Error("Reconcile error", "error", err)
 				}
 			})
 
 			// Phase 5b: Poll pr_open cars for GitHub review feedback.
 			timePhase("pr-review", func() {
-				if cfg.RequirePR {
+				if cfg.UsesPRs() && !paused {
 					prViewer := &ghPRViewer{repoDir: repoDir}
-					if err := handlePrOpenCars(db, prViewer, cfg.Yardmaster.AutoMergeOnApproval, repoDir, ymDir, cfg, logger); err != nil {
+					if err := handlePrOpenCars(db, clk, prViewer, cfg.Yardmaster.AutoMergeOnApproval, repoDir, ymDir, cfg, logger); err != nil {
 						logger.Error("PR review error", "error", err)
 					}
 				}
 			})
 
+			// Phase 5b2: Open and refresh draft PRs for in-progress cars.
+			timePhase("draft-prs", func() {
+				if cfg.UsesPRs() && cfg.Yardmaster.DraftPROnPush {
+					if err := syncDraftPRs(db, clk, cfg, repoDir, draftOps, draftState, logger); err != nil {
+						logger.Error("Draft PR error", "error", err)
+					}
+					if gh != nil {
+						m := gh.Metrics()
+						logger.Debug("GitHub API usage", "requests", m.Requests, "cache_hits", m.CacheHits,
+							"primary_waits", m.PrimaryWaits, "secondary_limits", m.SecondaryLimits, "errors", m.Errors, "remaining", m.Remaining)
+					}
+				}
+			})
+
 			// Phase 5c: Clean up stale pr_review cars.
 			timePhase("stale-review-cleanup", func() {
 				prViewer := &ghPRViewer{repoDir: repoDir}
-				handlePrReviewCars(db, prViewer, cfg, logger)
+				handlePrReviewCars(db, clk, prViewer, cfg, logger)
+			})
+
+			// Phase 5d: Preempt low-priority work for waiting urgent cars.
+			timePhase("preempt", func() {
+				if paused {
+					return
+				}
+				if err := preemptForUrgentCars(db, cfg, logger, bus); err != nil {
+					logger.Error("Preemption error", "error", err)
+				}
 			})
 
 			// Phase 6: Rebalance idle engines to busy tracks.
 			timePhase("rebalance", func() {
-				if err := rebalanceEnginesWithBus(db, cfg, configPath, rbState, logger, bus); err != nil {
+				if paused {
+					return
+				}
+				if err := rebalanceEnginesWithBus(db, clk, cfg, configPath, rbState, logger, bus); err != nil {
 					logger.Error("Rebalance error", "error", err)
 				}
 			})
 
+			// Phase 6b: Autoscale engine pools to the ready-car backlog.
+			timePhase("autoscale", func() {
+				if paused {
+					return
+				}
+				actions, err := autoscaler.Tick(clk.Now())
+				for _, a := range actions {
+					logger.Info("Autoscaled track", "track", a.Track, "from", a.Previous, "to", a.Current, "engine", a.Engine, "reason", a.Reason)
+					publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+						TargetID:   a.Track,
+						ActionType: "autoscale",
+					})
+				}
+				if err != nil {
+					logger.Error("Autoscale error", "error", err)
+				}
+			})
+
+			// Phase 7: Remove operational data past its retention window.
+			timePhase("retention", func() {
+				results, ran, err := janitor.Tick(clk.Now())
+				if !ran {
+					return
+				}
+				for _, r := range results {
+					if cfg.Retention.DryRun {
+						logger.Info("Retention dry run: would remove", "kind", r.Kind, "rows", r.Rows, "older_than", r.Cutoff)
+					} else if r.Rows > 0 {
+						logger.Info("Retention removed old data", "kind", r.Kind, "rows", r.Rows, "older_than", r.Cutoff)
+					}
+				}
+				if err != nil {
+					logger.Error("Retention error", "error", err)
+				}
+			})
+
 			return false
 		}()
 
@@ -224,13 +345,13 @@ func RunDaemonWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, conf
 			return nil
 		}
 
-		sleepWithContext(ctx, pollInterval)
+		sleepWithContext(ctx, clk, pollInterval)
 	}
 }
 
 // registerYardmaster creates or updates the yardmaster engine record.
-func registerYardmaster(db *gorm.DB, providerName string) error {
-	now := time.Now()
+func registerYardmaster(db *gorm.DB, clk clock.Clock, providerName string) error {
+	now := clk.Now()
 	if providerName == "" {
 		providerName = "claude"
 	}
@@ -265,13 +386,13 @@ func registerYardmaster(db *gorm.DB, providerName string) error {
 // startedAt is when this yardmaster instance started; drain messages older than
 // this are stale leftovers from a previous shutdown and are silently acked.
 func processInbox(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath, repoDir string, startedAt time.Time, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger) (draining bool, err error) {
-	return processInboxWithBus(ctx, db, cfg, configPath, repoDir, startedAt, escWg, escTracker, escSem, logger, nil)
+	return processInboxWithBus(ctx, db, clock.Real, cfg, configPath, repoDir, startedAt, escWg, escTracker, escSem, logger, nil)
 }
 
 // processInboxWithBus is the bus-aware variant of [processInbox]. Existing
 // tests call processInbox (which forwards a nil bus); the daemon loop uses
 // this form so action sites can publish [plugin.YardmasterAction].
-func processInboxWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath, repoDir string, startedAt time.Time, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) (draining bool, err error) {
+func processInboxWithBus(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath, repoDir string, startedAt time.Time, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) (draining bool, err error) {
 	msgs, err := messaging.Inbox(db, YardmasterID)
 	if err != nil {
 		return false, err
@@ -309,7 +430,7 @@ func processInboxWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, c
 		case subject == "engine-stalled":
 			logger.Info("Inbox: engine-stalled", "from", msg.FromAgent, "body", msg.Body)
 			if msg.CarID != "" {
-				writeProgressNote(db, msg.CarID, msg.FromAgent, fmt.Sprintf("Engine stalled: %s", msg.Body))
+				writeProgressNote(db, clk, msg.CarID, msg.FromAgent, fmt.Sprintf("Engine stalled: %s", msg.Body))
 			}
 			// Restart the stalled engine to spawn a replacement.
 			if msg.FromAgent != "" && msg.FromAgent != YardmasterID {
@@ -355,7 +476,7 @@ func processInboxWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, c
 					logger.Error("Escalation error", "error", escErr)
 					return
 				}
-				handleEscalateResult(db, m.FromAgent, m.CarID, result, logger)
+				handleEscalateResult(db, clk, m.FromAgent, m.CarID, result, logger)
 			}(msg)
 			ackMsg(db, msg, logger)
 
@@ -364,27 +485,44 @@ func processInboxWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, c
 			ackMsg(db, msg, logger)
 
 		case subject == "restart-engine":
-			handleRestartEngineWithBus(ctx, db, cfg, configPath, msg, logger, bus)
+			handleRestartEngineWithBus(ctx, db, clk, cfg, configPath, msg, logger, bus)
 			ackMsg(db, msg, logger)
 
 		case subject == "retry-merge":
-			handleRetryMergeWithBus(db, msg, logger, bus)
+			handleRetryMergeWithBus(db, clk, msg, logger, bus)
 			ackMsg(db, msg, logger)
 
 		case subject == "requeue-car":
-			handleRequeueCarWithBus(db, msg, logger, bus)
+			handleRequeueCarWithBus(db, clk, msg, logger, bus)
 			ackMsg(db, msg, logger)
 
 		case subject == "nudge-engine":
-			handleNudgeEngineWithBus(db, msg, logger, bus)
+			handleNudgeEngineWithBus(db, clk, msg, logger, bus)
 			ackMsg(db, msg, logger)
 
 		case subject == "unblock-car":
-			handleUnblockCarWithBus(db, msg, logger, bus)
+			handleUnblockCarWithBus(db, clk, msg, logger, bus)
 			ackMsg(db, msg, logger)
 
 		case subject == "close-epic":
-			handleCloseEpicWithBus(db, msg, logger, bus)
+			handleCloseEpicWithBus(db, clk, msg, logger, bus)
+			ackMsg(db, msg, logger)
+
+		case subject == "revert-car":
+			handleRevertCar(db, clk, cfg, repoDir, msg, logger, bus)
+			ackMsg(db, msg, logger)
+
+		case subject == "infra-resolved":
+			handleInfraResolvedWithBus(db, clk, msg, logger, bus)
+			ackMsg(db, msg, logger)
+
+		case subject == "abort-merge":
+			// A running test run consumes its own abort requests; one that
+			// reaches the inbox found nothing to abort.
+			logger.Info("Inbox: abort-merge with no test run in progress", "car", msg.CarID)
+			messaging.Send(db, YardmasterID, "telegraph", "abort-merge",
+				fmt.Sprintf("No merge-gate test run is in progress for car %s; nothing to abort.", msg.CarID),
+				messaging.SendOpts{CarID: msg.CarID})
 			ackMsg(db, msg, logger)
 
 		case subject == "reassignment" || subject == "deps-unblocked" || subject == "epic-closed":
@@ -419,75 +557,49 @@ func ackMsg(db *gorm.DB, msg models.Message, logger *slog.Logger) {
 // handleStaleEngines is a thin wrapper around [handleStaleEnginesWithBus] that
 // passes a nil bus. Existing tests call this form.
 func handleStaleEngines(db *gorm.DB, cfg *config.Config, configPath string, logger *slog.Logger) error {
-	return handleStaleEnginesWithBus(db, cfg, configPath, logger, nil)
+	return handleStaleEnginesWithBus(db, clock.Real, cfg, configPath, logger, nil)
 }
 
-// handleStaleEnginesWithBus detects engines with stale heartbeats, reassigns
-// their cars, and restarts the engines.
+// handleStaleEnginesWithBus runs the engine [orchestration.Supervisor]: engines
+// with stale heartbeats are marked dead, their cars re-opened, and
+// replacements launched on their tracks.
 //
 // When bus is non-nil and a reassign or restart succeeds, publishes a
 // [plugin.YardmasterAction] event (ActionType="reassign" or "restart-engine").
-func handleStaleEnginesWithBus(db *gorm.DB, cfg *config.Config, configPath string, logger *slog.Logger, bus events.Bus) error {
-	threshold := DefaultStaleThreshold
-	if cfg.Stall.StaleEngineThresholdSec > 0 {
-		threshold = time.Duration(cfg.Stall.StaleEngineThresholdSec) * time.Second
-	}
-	stale, err := CheckEngineHealth(db, threshold)
-	if err != nil {
-		return err
-	}
-
-	for _, eng := range stale {
-		if eng.ID == YardmasterID {
-			continue
-		}
-
-		// Clean up dead engine's overlay before restart (non-fatal).
-		if err := engine.CleanupOverlay(eng.ID, cfg); err != nil {
-			logger.Warn("Overlay cleanup for stale engine", "engine", eng.ID, "error", err)
+func handleStaleEnginesWithBus(db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath string, logger *slog.Logger, bus events.Bus) error {
+	actions, err := orchestration.NewSupervisor(db, cfg, configPath).Tick(clk.Now())
+	for _, a := range actions {
+		// Clean up the dead engine's overlay (non-fatal).
+		if err := engine.CleanupOverlay(a.Engine, cfg); err != nil {
+			logger.Warn("Overlay cleanup for stale engine", "engine", a.Engine, "error", err)
 		}
 
-		if eng.CurrentCar != "" {
-			logger.Warn("Engine deregistered as stale", "engine", eng.ID, "car", eng.CurrentCar)
-			reassigned, err := ReassignCar(db, eng.CurrentCar, eng.ID, "stale heartbeat")
-			switch {
-			case err != nil:
-				logger.Error("Reassign car from stale engine", "car", eng.CurrentCar, "engine", eng.ID, "error", err)
-			case !reassigned:
-				// Car completed/merged or moved to another engine between the
-				// staleness check and the reassign — nothing to broadcast.
-				logger.Info("Car moved on before reassign, leaving untouched", "car", eng.CurrentCar, "engine", eng.ID)
-			default:
-				publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
-					TargetID:   eng.CurrentCar,
-					ActionType: "reassign",
-				})
-			}
+		if a.Car != "" {
+			logger.Warn("Engine deregistered as stale", "engine", a.Engine, "car", a.Car, "last_activity", a.LastActivity)
+			publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+				TargetID:   a.Car,
+				ActionType: "reassign",
+			})
 		} else {
-			logger.Warn("Engine deregistered as stale", "engine", eng.ID, "status", "idle")
-			if err := db.Model(&models.Engine{}).Where("id = ?", eng.ID).Update("status", engine.StatusDead).Error; err != nil {
-				logger.Error("Update stale engine to dead", "engine", eng.ID, "error", err)
-			}
+			logger.Warn("Engine deregistered as stale", "engine", a.Engine, "last_activity", a.LastActivity)
 		}
 
-		// Restart the engine to spawn a replacement on the same track.
-		if err := orchestration.RestartEngine(db, cfg, configPath, eng.ID, nil); err != nil {
-			logger.Error("Failed to restart engine", "engine", eng.ID, "error", err)
-		} else {
+		if a.RestartErr != nil {
+			logger.Error("Failed to restart engine", "engine", a.Engine, "error", a.RestartErr)
+		} else if a.Restarted {
 			publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
-				TargetID:   eng.ID,
+				TargetID:   a.Engine,
 				ActionType: "restart-engine",
 			})
 		}
 	}
-
-	return nil
+	return err
 }
 
 // handleCompletedCars is a thin wrapper around [handleCompletedCarsWithBus]
-// that passes a nil bus. Existing tests call this form.
+// that passes a nil bus and forge. Existing tests call this form.
 func handleCompletedCars(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath, repoDir, ymDir string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger) error {
-	return handleCompletedCarsWithBus(ctx, db, cfg, configPath, repoDir, ymDir, escWg, escTracker, escSem, logger, nil)
+	return handleCompletedCarsWithBus(ctx, db, clock.Real, cfg, configPath, repoDir, ymDir, escWg, escTracker, escSem, logger, nil, nil)
 }
 
 // handleCompletedCarsWithBus finds cars with status "done" and runs the switch flow.
@@ -498,7 +610,17 @@ func handleCompletedCars(ctx context.Context, db *gorm.DB, cfg *config.Config, c
 // When bus is non-nil, [plugin.YardmasterAction] (ActionType="merge") fires
 // per car prior to the switch call, and [plugin.CarMerged] / [plugin.MergeFailed]
 // fire from inside [Switch] / [maybeSwitchEscalate].
-func handleCompletedCarsWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, configPath, repoDir, ymDir string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) error {
+//
+// forge posts merge-gate results to PRs when require_pr is set; nil uses
+// the gh CLI.
+func handleCompletedCarsWithBus(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath, repoDir, ymDir string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus, forge ForgeFunc) error {
+	// An infra failure holds the merge queue: every merge would fail the
+	// same way until an operator fixes the environment.
+	if hold, err := yard.GetMergeHold(db); err == nil && hold.Held {
+		logger.Debug("Merge queue held after infra failure, skipping merges", "hold", hold.String())
+		return nil
+	}
+
 	cars, err := car.List(db, car.ListFilters{Status: "done"})
 	if err != nil {
 		return err
@@ -514,6 +636,7 @@ func handleCompletedCarsWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 		return cars[i].CreatedAt.Before(cars[j].CreatedAt)
 	})
 
+	var queue []models.Car
 	for _, c := range cars {
 		// Epics are container cars — no engine ever commits to their branch.
 		// Skip the merge and transition directly to merged when all children
@@ -521,7 +644,7 @@ func handleCompletedCarsWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 		if c.Type == "epic" {
 			var remaining int64
 			if err := db.Model(&models.Car{}).
-				Where("parent_id = ? AND status NOT IN ?", c.ID, []string{"done", "merged", "cancelled"}).
+				Where("parent_id = ? AND status NOT IN ?", c.ID, models.ClosedStatuses).
 				Count(&remaining).Error; err != nil {
 				logger.Error("Count remaining children for epic", "epic", c.ID, "error", err)
 				continue
@@ -530,7 +653,7 @@ func handleCompletedCarsWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 				logger.Info("Epic has pending children, skipping", "epic", c.ID, "title", c.Title, "remaining", remaining)
 				continue
 			}
-			now := time.Now()
+			now := clk.Now()
 			if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
 				"status":       "merged",
 				"completed_at": now,
@@ -547,141 +670,222 @@ func handleCompletedCarsWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 			}
 			for _, u := range unblocked {
 				if u.Type == "epic" {
-					TryCloseEpic(db, u.ID)
+					TryCloseEpic(db, clk, u.ID)
 				}
 			}
 			if c.ParentID != nil && *c.ParentID != "" {
-				TryCloseEpic(db, *c.ParentID)
+				TryCloseEpic(db, clk, *c.ParentID)
 			}
 			continue
 		}
 
-		// Reset the yardmaster worktree to the car's base branch before each
-		// switch so we start from a clean state.
-		baseBranch := c.BaseBranch
-		if baseBranch == "" {
-			baseBranch = "main"
+		if deferredMerges.deferred(c.ID, clk.Now()) {
+			logger.Debug("Merge deferred after abort, skipping", "car", c.ID)
+			continue
 		}
-
-		logger.Info("Car completed, switching",
-			"car", c.ID,
-			"title", c.Title,
-			"branch", c.Branch,
-			"base_branch", baseBranch,
-			"track", c.Track,
-			"assignee", c.Assignee,
-		)
-		if ymDir != repoDir {
-			if err := engine.SyncWorktreeToBranch(ymDir, baseBranch, repoDir); err != nil {
-				logger.Warn("Reset yardmaster worktree", "car", c.ID, "error", err)
-			}
+		if c.SwitchRetryAt != nil && clk.Now().Before(*c.SwitchRetryAt) {
+			logger.Debug("Merge retry backing off, skipping", "car", c.ID, "retry_at", *c.SwitchRetryAt)
+			continue
 		}
+		queue = append(queue, c)
+	}
 
-		var testCommand, preTestCommand string
-		for _, t := range cfg.Tracks {
-			if t.Name == c.Track {
-				preTestCommand = t.PreTestCommand
-				testCommand = t.TestCommand
-				break
-			}
-		}
+	runSwitchQueue(cfg, repoDir, queue, func(c models.Car, testDir string) {
+		switchCompletedCar(ctx, db, clk, cfg, configPath, repoDir, ymDir, testDir, c, escWg, escTracker, escSem, logger, bus, forge)
+	})
+	return nil
+}
 
-		// Build a CommentCounter if PR mode is active — nil is safe otherwise.
-		var commentCounter func(string) (int, error)
-		if cfg.RequirePR {
-			commentCounter = (&ghPRViewer{repoDir: repoDir}).CountComments
+// switchCompletedCar runs the switch flow for one done car and handles the
+// outcome: progress notes, conflict cars, escalation and overlay cleanup.
+// testDir is the worktree its tests run in; empty runs them in ymDir.
+func switchCompletedCar(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath, repoDir, ymDir, testDir string, c models.Car, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus, forge ForgeFunc) {
+	// A track with its own repo is switched in its clone. The car stays
+	// done and is retried next poll if the clone is unavailable.
+	if cfg.HasOwnRepo(c.Track) {
+		var err error
+		if repoDir, ymDir, err = trackDirs(cfg, repoDir, ymDir, c.Track); err != nil {
+			logger.Error("Prepare track repo for switch", "car", c.ID, "track", c.Track, "error", err)
+			return
 		}
+		// The shared forge is bound to the top-level repo; the gh CLI in
+		// the track's worktree targets the track's own.
+		forge = nil
+	}
+
+	// Reset the yardmaster worktree to the car's base branch before each
+	// switch so we start from a clean state.
+	baseBranch := carBaseBranch(cfg, &c)
+
+	logger.Info("Car completed, switching",
+		"car", c.ID,
+		"title", c.Title,
+		"branch", c.Branch,
+		"base_branch", baseBranch,
+		"track", c.Track,
+		"assignee", c.Assignee,
+	)
+	if ymDir != repoDir {
+		gitMu.Lock()
+		if err := engine.SyncWorktreeToBranch(ymDir, baseBranch, repoDir); err != nil {
+			logger.Warn("Reset yardmaster worktree", "car", c.ID, "error", err)
+		}
+		gitMu.Unlock()
+	}
+
+	var testCommand, preTestCommand string
+	var testMatrix []config.TestMatrixCell
+	var testMatrixParallel bool
+	var testRunner TestRunner
+	var coverage *config.CoverageConfig
+	var diffLimit *config.DiffLimitConfig
+	for _, t := range cfg.Tracks {
+		if t.Name == c.Track {
+			if c.Canary {
+				t = t.WithCanary()
+			}
+			preTestCommand = t.PreTestCommand
+			testCommand = t.TestCommand
+			testMatrix = t.TestMatrix
+			testMatrixParallel = t.TestMatrixParallel
+			testRunner = NewTestRunner(t.TestRunner)
+			coverage = t.Coverage
+			diffLimit = t.DiffLimit
+			break
+		}
+	}
 
-		// Announce the merge action site BEFORE the switch runs so subscribers
-		// see the intent even when the operation fails. The Switch call itself
-		// then publishes CarMerged / MergeFailed per outcome.
-		publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
-			TargetID:   c.ID,
-			ActionType: "merge",
-		})
-
-		result, err := Switch(db, c.ID, SwitchOpts{
-			RepoDir:          ymDir,
-			PrimaryRepoDir:   repoDir,
-			BaseBranch:       baseBranch,
-			PreTestCommand:   preTestCommand,
-			TestCommand:      testCommand,
-			RequirePR:        cfg.RequirePR,
-			SwitchTimeoutSec: cfg.Stall.SwitchTimeoutSec,
-			CommentCounter:   commentCounter,
-			RevisedLabel:     cfg.Yardmaster.RevisedLabel,
-			ReReviewLabel:    cfg.Inspect.Labels.ReReview,
-			ConfigPath:       configPath,
-			Bus:              bus,
-		})
+	// Build a CommentCounter if PR mode is active — nil is safe otherwise.
+	requirePR := cfg.RequirePRFor(c.Track)
+	var commentCounter func(string) (int, error)
+	if requirePR {
+		commentCounter = (&ghPRViewer{repoDir: repoDir}).CountComments
+	}
 
-		// Handle any failure — write a categorized progress note and check
-		// whether we've hit the escalation threshold.
-		failCategory := SwitchFailNone
-		if result != nil {
-			failCategory = result.FailureCategory
-		}
+	// Announce the merge action site BEFORE the switch runs so subscribers
+	// see the intent even when the operation fails. The Switch call itself
+	// then publishes CarMerged / MergeFailed per outcome.
+	publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+		TargetID:   c.ID,
+		ActionType: "merge",
+	})
 
-		if err != nil {
-			logger.Error("Switch car failed", "car", c.ID, "error", err)
+	result, err := Switch(db, c.ID, SwitchOpts{
+		RepoDir:             ymDir,
+		PrimaryRepoDir:      repoDir,
+		BaseBranch:          baseBranch,
+		PreTestCommand:      preTestCommand,
+		TestCommand:         testCommand,
+		TestMatrix:          testMatrix,
+		TestMatrixParallel:  testMatrixParallel,
+		TestRunner:          testRunner,
+		TestDir:             testDir,
+		Coverage:            coverage,
+		DiffLimit:           diffLimit,
+		RequirePR:           requirePR,
+		TrackType:           cfg.TrackTypeFor(c.Track),
+		SwitchTimeoutSec:    cfg.SwitchTimeoutFor(c.Track),
+		TestNoticeSec:       max(cfg.Stall.SwitchNoticeSec, 0),
+		CommentCounter:      commentCounter,
+		RevisedLabel:        cfg.Yardmaster.RevisedLabel,
+		ReReviewLabel:       cfg.Inspect.Labels.ReReview,
+		FailurePatternsFile: cfg.Yardmaster.FailurePatternsFile,
+		RetryInfra:          cfg.Stall.SwitchRetryMax > 0,
+		Config:              cfg,
+		Forge:               forge,
+		Bus:                 bus,
+		Clock:               clk,
+	})
 
-			if failCategory != SwitchFailNone {
-				note := fmt.Sprintf("switch:%s: %v", failCategory, err)
-				if result != nil && result.ConflictDetails != "" {
-					note += "\n" + result.ConflictDetails
-				}
-				writeProgressNote(db, c.ID, YardmasterID, note)
-			}
+	// Handle any failure — write a categorized progress note and check
+	// whether we've hit the escalation threshold.
+	failCategory := SwitchFailNone
+	if result != nil {
+		failCategory = result.FailureCategory
+	}
+	if !transientSwitchFailure(failCategory) && failCategory != SwitchFailAborted {
+		clearSwitchRetry(db, &c, logger)
+	}
 
-			conflictDetails := ""
-			if result != nil {
-				conflictDetails = result.ConflictDetails
-			}
-			maybeSwitchEscalateWithBus(ctx, db, cfg, c.ID, failCategory, err, conflictDetails, escWg, escTracker, escSem, logger, bus)
-			continue
-		}
+	if err != nil {
+		logger.Error("Switch car failed", "car", c.ID, "error", err)
 
-		// Test failures return result with nil error but FailureCategory set.
 		if failCategory != SwitchFailNone {
-			note := fmt.Sprintf("switch:%s: %v", failCategory, result.Error)
-			if result.ConflictDetails != "" {
+			note := fmt.Sprintf("switch:%s: %v", failCategory, err)
+			if result != nil && result.ConflictDetails != "" {
 				note += "\n" + result.ConflictDetails
 			}
-			writeProgressNote(db, c.ID, YardmasterID, note)
-			maybeSwitchEscalateWithBus(ctx, db, cfg, c.ID, failCategory, result.Error, result.ConflictDetails, escWg, escTracker, escSem, logger, bus)
+			writeProgressNote(db, clk, c.ID, YardmasterID, note)
 		}
 
-		if result.PRCreated {
-			logger.Info("Car state transition", "car", c.ID, "transition", "done->pr_open", "pr_url", result.PRUrl)
-		} else if result.Merged {
-			if result.AlreadyMerged {
-				logger.Info("Car state transition", "car", c.ID, "transition", "done->merged", "branch", result.Branch, "already_merged", true)
-			} else {
-				logger.Info("Car state transition", "car", c.ID, "transition", "done->merged", "branch", result.Branch)
+		conflictDetails := ""
+		if result != nil {
+			conflictDetails = result.ConflictDetails
+		}
+		if failCategory == SwitchFailMerge {
+			gitMu.Lock()
+			spawned := maybeSpawnConflictCar(db, clk, cfg, &c, ymDir, conflictDetails, logger)
+			gitMu.Unlock()
+			if spawned {
+				return
 			}
+		}
+		if retryTransientSwitchFailure(ctx, db, clk, cfg, &c, failCategory, err, "", escWg, escTracker, escSem, logger, bus) {
+			return
+		}
+		maybeSwitchEscalateWithBus(ctx, db, clk, cfg, c.ID, failCategory, err, conflictDetails, escWg, escTracker, escSem, logger, bus)
+		return
+	}
 
-			// Clean up the completing engine's overlay (non-fatal).
-			if c.Assignee != "" {
-				if err := engine.CleanupOverlay(c.Assignee, cfg); err != nil {
-					logger.Warn("Overlay cleanup", "assignee", c.Assignee, "error", err)
-				}
-			}
+	if failCategory == SwitchFailAborted {
+		requeueAfter := time.Duration(cfg.Stall.AbortRequeueSec) * time.Second
+		deferredMerges.deferMerge(c.ID, clk.Now().Add(requeueAfter))
+		// Not a "switch:" note: an abort does not count toward escalation.
+		writeProgressNote(db, clk, c.ID, YardmasterID, fmt.Sprintf("%v; retrying in %s", result.Error, requeueAfter))
+		messaging.Send(db, YardmasterID, "telegraph", "tests-aborted",
+			fmt.Sprintf("Merge-gate tests for car %s (%s) stopped. The car re-enters the merge gate in %s.", c.ID, c.Title, requeueAfter),
+			messaging.SendOpts{CarID: c.ID})
+		logger.Info("Car merge aborted, requeued", "car", c.ID, "retry_in", requeueAfter)
+		return
+	}
 
-		} else if !result.TestsPassed {
-			logger.Warn("Car tests failed, blocked",
-				"car", c.ID,
-				"failure_category", failCategory,
-				"test_output_tail", engine.RedactSecrets(truncateSwitchLog(result.TestOutput, 200)),
-			)
+	// Test failures return result with nil error but FailureCategory set.
+	if failCategory != SwitchFailNone {
+		writeProgressNote(db, clk, c.ID, YardmasterID, switchFailureNote(failCategory, result.Error, result.ConflictDetails, result.TestOutput))
+		if retryTransientSwitchFailure(ctx, db, clk, cfg, &c, failCategory, result.Error, infraHint(result.TestOutput, preTestCommand), escWg, escTracker, escSem, logger, bus) {
+			return
 		}
+		maybeSwitchEscalateWithBus(ctx, db, clk, cfg, c.ID, failCategory, result.Error, result.ConflictDetails, escWg, escTracker, escSem, logger, bus)
 	}
 
-	return nil
+	if result.PRCreated {
+		logger.Info("Car state transition", "car", c.ID, "transition", "done->pr_open", "pr_url", result.PRUrl)
+	} else if result.Merged {
+		if result.AlreadyMerged {
+			logger.Info("Car state transition", "car", c.ID, "transition", "done->merged", "branch", result.Branch, "already_merged", true)
+		} else {
+			logger.Info("Car state transition", "car", c.ID, "transition", "done->merged", "branch", result.Branch)
+		}
+
+		// Clean up the completing engine's overlay (non-fatal).
+		if c.Assignee != "" {
+			if err := engine.CleanupOverlay(c.Assignee, cfg); err != nil {
+				logger.Warn("Overlay cleanup", "assignee", c.Assignee, "error", err)
+			}
+		}
+
+	} else if !result.TestsPassed {
+		logger.Warn("Car tests failed, blocked",
+			"car", c.ID,
+			"failure_category", failCategory,
+			"test_output_tail", engine.RedactSecrets(truncateSwitchLog(result.TestOutput, 200)),
+		)
+	}
 }
 
 // handleBlockedCars is a safety-net sweep that tries to unblock cars whose
 // dependencies may have resolved outside the normal switch flow.
-func handleBlockedCars(db *gorm.DB, logger *slog.Logger) error {
+func handleBlockedCars(db *gorm.DB, clk clock.Clock, logger *slog.Logger) error {
 	for _, status := range []string{"merged"} {
 		completedCars, err := car.List(db, car.ListFilters{Status: status})
 		if err != nil {
@@ -699,7 +903,7 @@ func handleBlockedCars(db *gorm.DB, logger *slog.Logger) error {
 				logger.Info("Car unblocked", "car", u.ID, "dependency", c.ID)
 				// Auto-close epics whose children are all complete.
 				if u.Type == "epic" {
-					TryCloseEpic(db, u.ID)
+					TryCloseEpic(db, clk, u.ID)
 				}
 			}
 		}
@@ -711,7 +915,7 @@ func handleBlockedCars(db *gorm.DB, logger *slog.Logger) error {
 // sweepOpenEpics checks open epics whose children may all be complete and
 // auto-closes them. This is a safety net for epics that missed the reactive
 // TryCloseEpic call (e.g., timing issues, last child merged before check).
-func sweepOpenEpics(db *gorm.DB, logger *slog.Logger) error {
+func sweepOpenEpics(db *gorm.DB, clk clock.Clock, logger *slog.Logger) error {
 	openEpics, err := car.List(db, car.ListFilters{Status: "open", Type: "epic"})
 	if err != nil {
 		return err
@@ -720,7 +924,7 @@ func sweepOpenEpics(db *gorm.DB, logger *slog.Logger) error {
 	for _, e := range openEpics {
 		var remaining int64
 		if err := db.Model(&models.Car{}).
-			Where("parent_id = ? AND status NOT IN ?", e.ID, []string{"done", "merged", "cancelled"}).
+			Where("parent_id = ? AND status NOT IN ?", e.ID, models.ClosedStatuses).
 			Count(&remaining).Error; err != nil {
 			logger.Error("Sweep: count remaining for epic", "epic", e.ID, "error", err)
 			continue
@@ -735,7 +939,7 @@ func sweepOpenEpics(db *gorm.DB, logger *slog.Logger) error {
 			}
 			if total > 0 {
 				logger.Info("Epic progress: auto-closing, all children complete", "epic", e.ID, "title", e.Title)
-				TryCloseEpic(db, e.ID)
+				TryCloseEpic(db, clk, e.ID)
 			}
 		}
 	}
@@ -750,7 +954,7 @@ func sweepOpenEpics(db *gorm.DB, logger *slog.Logger) error {
 // remote truth) to avoid false positives from local-only merges that were
 // never pushed. Cars are grouped by base branch so each group is checked
 // against the correct target.
-func reconcileStaleCars(db *gorm.DB, repoDir string, requirePR bool, viewer PRViewer, logger *slog.Logger) error {
+func reconcileStaleCars(db *gorm.DB, clk clock.Clock, repoDir string, requirePR bool, viewer PRViewer, logger *slog.Logger) error {
 	// Fetch first to get current remote state.
 	if err := gitFetch(repoDir); err != nil {
 		return fmt.Errorf("reconcile fetch: %w", err)
@@ -781,7 +985,7 @@ func reconcileStaleCars(db *gorm.DB, repoDir string, requirePR bool, viewer PRVi
 		carsByBase[base] = append(carsByBase[base], c)
 	}
 
-	now := time.Now()
+	now := clk.Now()
 	for base, cars := range carsByBase {
 		// Get branches merged into origin/{base}.
 		mergedBranches, err := getMergedBranches(repoDir, "origin/"+base)
@@ -833,7 +1037,7 @@ func reconcileStaleCars(db *gorm.DB, repoDir string, requirePR bool, viewer PRVi
 					logger.Error("Reconcile car to merged", "car", c.ID, "error", err)
 					continue
 				}
-				runPostMerge(db, c, logger)
+				runPostMerge(db, clk, c, logger)
 			}
 		}
 	}
@@ -924,7 +1128,7 @@ func branchHasUniqueCommits(repoDir, branch, baseBranch string) bool {
 }
 
 // handleEscalateResult acts on the decision returned by Claude escalation.
-func handleEscalateResult(db *gorm.DB, engineID, carID string, result *EscalateResult, logger *slog.Logger) {
+func handleEscalateResult(db *gorm.DB, clk clock.Clock, engineID, carID string, result *EscalateResult, logger *slog.Logger) {
 	if result == nil {
 		return
 	}
@@ -940,7 +1144,7 @@ func handleEscalateResult(db *gorm.DB, engineID, carID string, result *EscalateR
 	switch result.Action {
 	case EscalateReassign:
 		logger.Info("Escalation: reassigning car", "car", carID)
-		if reassigned, err := ReassignCar(db, carID, engineID, "escalation: "+result.Message); err != nil {
+		if reassigned, err := ReassignCar(db, clk, carID, engineID, "escalation: "+result.Message); err != nil {
 			logger.Error("Escalation: reassign failed", "car", carID, "error", err)
 		} else if !reassigned {
 			logger.Info("Escalation: car moved on before reassign", "car", carID)
@@ -960,6 +1164,39 @@ func handleEscalateResult(db *gorm.DB, engineID, carID string, result *EscalateR
 	}
 }
 
+// maybeSpawnConflictCar hands a merge-conflicted car to a conflict car when
+// yardmaster.conflict_assist is on, reporting whether it did. When it did not
+// (disabled, c is itself a conflict car, or spawning failed), the caller
+// falls back to the usual escalation path. repoDir is the car's track
+// repository (see trackDirs).
+func maybeSpawnConflictCar(db *gorm.DB, clk clock.Clock, cfg *config.Config, c *models.Car, repoDir, details string, logger *slog.Logger) bool {
+	if !cfg.Yardmaster.ConflictAssist || c.Type == "conflict" {
+		return false
+	}
+	res, err := SpawnConflictCar(db, c.ID, ConflictOpts{
+		RepoDir:       repoDir,
+		DefaultBranch: cfg.DefaultBranchFor(c.Track),
+		BranchPrefix:  cfg.BranchPrefix,
+		IDFormat:      car.IDFormatFromConfig(cfg),
+		Details:       details,
+		Clock:         clk,
+	})
+	if err != nil {
+		logger.Error("Spawn conflict car", "car", c.ID, "error", err)
+		return false
+	}
+	if res.Existing {
+		logger.Info("Car already has a conflict car", "car", c.ID, "conflict_car", res.ConflictCar.ID)
+		return true
+	}
+	logger.Info("Car state transition", "car", c.ID, "transition", "done->blocked", "conflict_car", res.ConflictCar.ID, "files", len(res.Files))
+	messaging.Send(db, YardmasterID, "broadcast", "conflict-car",
+		fmt.Sprintf("Merge of %s conflicts; conflict car %s will resolve it", c.ID, res.ConflictCar.ID),
+		messaging.SendOpts{CarID: c.ID},
+	)
+	return true
+}
+
 // countRecentSwitchFailures counts all switch-categorized failure progress
 // notes for a car. Each note has the form "switch:<category>: <details>".
 func countRecentSwitchFailures(db *gorm.DB, carID string) int {
@@ -991,6 +1228,10 @@ func switchFailureReason(cat SwitchFailureCategory) string {
 		return "repeated-push-failure"
 	case SwitchFailPR:
 		return "repeated-pr-failure"
+	case SwitchFailCoverage:
+		return "repeated-coverage-drop"
+	case SwitchFailDiffSize:
+		return "repeated-diff-too-large"
 	default:
 		return "repeated-switch-failure"
 	}
@@ -999,7 +1240,7 @@ func switchFailureReason(cat SwitchFailureCategory) string {
 // maybeSwitchEscalate is a thin wrapper around [maybeSwitchEscalateWithBus]
 // that passes a nil bus. Existing tests call this form.
 func maybeSwitchEscalate(ctx context.Context, db *gorm.DB, cfg *config.Config, carID string, cat SwitchFailureCategory, switchErr error, conflictDetails string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger) {
-	maybeSwitchEscalateWithBus(ctx, db, cfg, carID, cat, switchErr, conflictDetails, escWg, escTracker, escSem, logger, nil)
+	maybeSwitchEscalateWithBus(ctx, db, clock.Real, cfg, carID, cat, switchErr, conflictDetails, escWg, escTracker, escSem, logger, nil)
 }
 
 // maybeSwitchEscalateWithBus checks whether a car has exceeded the switch failure
@@ -1009,7 +1250,13 @@ func maybeSwitchEscalate(ctx context.Context, db *gorm.DB, cfg *config.Config, c
 // infrastructure-failure shortcut or by exceeding MaxSwitchFailures),
 // publishes [plugin.MergeFailed] plus a [plugin.YardmasterAction] escalate
 // event.
-func maybeSwitchEscalateWithBus(ctx context.Context, db *gorm.DB, cfg *config.Config, carID string, cat SwitchFailureCategory, switchErr error, conflictDetails string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) {
+func maybeSwitchEscalateWithBus(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, carID string, cat SwitchFailureCategory, switchErr error, conflictDetails string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) {
+	// The car already waits for a human in needs-attention; moving it to
+	// merge-failed would hide why.
+	if cat == SwitchFailBranch {
+		return
+	}
+
 	// Infrastructure failures escalate immediately — no threshold needed.
 	// The human message was already sent by Switch(); here we also escalate
 	// to Claude for a suggested action.
@@ -1023,6 +1270,7 @@ func maybeSwitchEscalateWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 			logger.Error("Update car to merge-failed (infra)", "car", carID, "error", err)
 		}
 		logger.Info("Car state transition", "car", carID, "transition", "done->merge-failed")
+		holdMergesForInfra(db, carID, switchErr, logger)
 
 		// The transition above moves the car into merge-failed. Surface this
 		// to subscribers as MergeFailed (the merge attempt is abandoned) plus
@@ -1052,10 +1300,10 @@ func maybeSwitchEscalateWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 			})
 			if escErr != nil {
 				logger.Error("Escalation error", "car", carID, "error", escErr)
-				handleEscalateResult(db, "", carID, escalationFailureResult(carID, reason, escErr), logger)
+				handleEscalateResult(db, clk, "", carID, escalationFailureResult(carID, reason, escErr), logger)
 				return
 			}
-			handleEscalateResult(db, "", carID, res, logger)
+			handleEscalateResult(db, clk, "", carID, res, logger)
 		}(carID, reason)
 		return
 	}
@@ -1075,7 +1323,13 @@ func maybeSwitchEscalateWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 		)
 		return
 	}
+	escalateSwitchFailure(ctx, db, clk, cfg, carID, cat, switchErr, conflictDetails, failures, escWg, escTracker, escSem, logger, bus)
+}
 
+// escalateSwitchFailure moves a car that keeps failing the merge gate to
+// merge-failed and escalates it to the agent, unless the car's escalation
+// cooldown is active.
+func escalateSwitchFailure(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, carID string, cat SwitchFailureCategory, switchErr error, conflictDetails string, failures int, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) {
 	if escTracker != nil && !escTracker.ShouldEscalate(carID) {
 		logger.Info("Car escalation skipped, cooldown active", "car", carID)
 		return
@@ -1118,10 +1372,10 @@ func maybeSwitchEscalateWithBus(ctx context.Context, db *gorm.DB, cfg *config.Co
 		})
 		if escErr != nil {
 			logger.Error("Escalation error", "car", carID, "error", escErr)
-			handleEscalateResult(db, "", carID, escalationFailureResult(carID, reason, escErr), logger)
+			handleEscalateResult(db, clk, "", carID, escalationFailureResult(carID, reason, escErr), logger)
 			return
 		}
-		handleEscalateResult(db, "", carID, res, logger)
+		handleEscalateResult(db, clk, "", carID, res, logger)
 	}(carID, failures, reason)
 }
 
@@ -1214,7 +1468,7 @@ type prStatus struct {
 type PRViewer interface {
 	ViewPR(branch string) (*prStatus, error)
 	FetchComments(branch string) ([]prInlineComment, []prConversationComment, error)
-	MergePR(branch string) error
+	MergePR(branch, body string) error
 	CountComments(branch string) (int, error)
 	RemoveLabel(branch, label string) error
 }
@@ -1404,8 +1658,9 @@ func parseInlineComments(data []byte) ([]prInlineComment, error) {
 	return comments, nil
 }
 
-func (g *ghPRViewer) MergePR(branch string) error {
-	cmd := exec.Command("gh", "pr", "merge", branch, "--merge", "--delete-branch")
+// MergePR merges the PR for branch with a merge commit whose body is body.
+func (g *ghPRViewer) MergePR(branch, body string) error {
+	cmd := exec.Command("gh", "pr", "merge", branch, "--merge", "--delete-branch", "--body", body)
 	cmd.Dir = g.repoDir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
@@ -1417,7 +1672,7 @@ func (g *ghPRViewer) MergePR(branch string) error {
 // handlePrOpenCars polls pr_open cars for GitHub review status and transitions
 // them based on the PR state: changes_requested → open, merged → merged, closed → cancelled.
 // When autoMerge is true, APPROVED PRs are automatically merged via the viewer.
-func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymDir string, cfg *config.Config, logger *slog.Logger) error {
+func handlePrOpenCars(db *gorm.DB, clk clock.Clock, viewer PRViewer, autoMerge bool, repoDir, ymDir string, cfg *config.Config, logger *slog.Logger) error {
 	prCars, err := car.List(db, car.ListFilters{Status: "pr_open"})
 	if err != nil {
 		return err
@@ -1433,17 +1688,22 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 			continue
 		}
 
-		// Resolve base branch for this car.
-		baseBranch := c.BaseBranch
-		if baseBranch == "" {
-			if cfg != nil && cfg.DefaultBranch != "" {
-				baseBranch = cfg.DefaultBranch
-			} else {
-				baseBranch = "main"
+		// A track with its own repo has its PRs, and its conflicts, there.
+		carRepoDir, carYmDir, carViewer := repoDir, ymDir, viewer
+		if cfg != nil && cfg.HasOwnRepo(c.Track) {
+			if carRepoDir, carYmDir, err = trackDirs(cfg, repoDir, ymDir, c.Track); err != nil {
+				logger.Error("Prepare track repo for PR check", "car", c.ID, "track", c.Track, "error", err)
+				continue
+			}
+			if _, ok := viewer.(*ghPRViewer); ok {
+				carViewer = &ghPRViewer{repoDir: carRepoDir}
 			}
 		}
 
-		status, err := viewer.ViewPR(c.Branch)
+		// Resolve base branch for this car.
+		baseBranch := carBaseBranch(cfg, &c)
+
+		status, err := carViewer.ViewPR(c.Branch)
 		if err != nil {
 			logger.Error("PR status error", "car", c.ID, "error", err)
 			continue
@@ -1462,7 +1722,7 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 		switch {
 		case status.Mergeable == "CONFLICTING" && status.State == "OPEN":
 			// Check if base branch has advanced since last rebase attempt.
-			currentBaseHead := getRemoteHeadCommit(repoDir, baseBranch)
+			currentBaseHead := getRemoteHeadCommit(carRepoDir, baseBranch)
 			if currentBaseHead == "" {
 				continue // unable to determine remote HEAD, skip
 			}
@@ -1471,23 +1731,28 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 			}
 
 			// Attempt rebase using existing conflict resolution pipeline.
-			// Use ymDir (yardmaster worktree) to avoid mutating primary repo.
-			resolved, resolveErr := tryResolveConflict(ymDir, c.Branch, baseBranch)
+			// Use the yardmaster worktree to avoid mutating the primary repo.
+			resolved, resolveErr := tryResolveConflict(carYmDir, c.Branch, baseBranch)
 			if resolved {
-				if pushErr := gitForcePushBranch(ymDir, c.Branch); pushErr != nil {
+				if pushErr := gitForcePushBranch(carYmDir, c.Branch); pushErr != nil {
 					logger.Error("Force push after rebase failed", "car", c.ID, "error", pushErr)
-					writeProgressNote(db, c.ID, "yardmaster", fmt.Sprintf("Rebase succeeded but force push failed: %v", pushErr))
+					writeProgressNote(db, clk, c.ID, "yardmaster", fmt.Sprintf("Rebase succeeded but force push failed: %v", pushErr))
 					// Don't record base HEAD so the next cycle retries.
 					continue
 				}
-				writeProgressNote(db, c.ID, "yardmaster", "Auto-rebased branch onto updated "+baseBranch)
+				// Record the rebased head so Switch does not mistake this
+				// rewrite for one made outside Railyard.
+				if head, err := gitOutput(carYmDir, "rev-parse", c.Branch); err == nil {
+					db.Model(&models.Car{}).Where("id = ?", c.ID).Update("pushed_head", head)
+				}
+				writeProgressNote(db, clk, c.ID, "yardmaster", "Auto-rebased branch onto updated "+baseBranch)
 				logger.Info("Auto-rebased PR branch", "car", c.ID)
 			} else {
 				detail := ""
 				if resolveErr != nil {
 					detail = resolveErr.Error()
 				}
-				writeProgressNote(db, c.ID, "yardmaster",
+				writeProgressNote(db, clk, c.ID, "yardmaster",
 					fmt.Sprintf("PR has merge conflict that cannot be auto-resolved:\n%s", detail))
 				messaging.Send(db, YardmasterID, "human", "escalate",
 					fmt.Sprintf("Car %s PR has unresolvable merge conflict", c.ID),
@@ -1502,7 +1767,7 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 			}
 
 		case status.State == "MERGED":
-			now := time.Now()
+			now := clk.Now()
 			if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
 				"status":       "merged",
 				"completed_at": now,
@@ -1511,7 +1776,7 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 				continue
 			}
 			logger.Info("PR merged", "car", c.ID, "transition", "pr_open->merged")
-			runPostMerge(db, c, logger)
+			runPostMerge(db, clk, c, logger)
 
 		case status.State == "CLOSED":
 			if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Update("status", "cancelled").Error; err != nil {
@@ -1521,12 +1786,12 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 			logger.Info("PR closed", "car", c.ID, "transition", "pr_open->cancelled")
 
 		case autoMerge && decision == "APPROVED" && status.State == "OPEN":
-			if err := viewer.MergePR(c.Branch); err != nil {
+			if err := carViewer.MergePR(c.Branch, mergeCommitBody(db, &c)); err != nil {
 				logger.Error("Auto-merge PR failed", "car", c.ID, "error", err)
-				writeProgressNote(db, c.ID, "yardmaster", fmt.Sprintf("Auto-merge failed: %v", err))
+				writeProgressNote(db, clk, c.ID, "yardmaster", fmt.Sprintf("Auto-merge failed: %v", err))
 				continue
 			}
-			now := time.Now()
+			now := clk.Now()
 			if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
 				"status":       "merged",
 				"completed_at": now,
@@ -1535,7 +1800,7 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 				continue
 			}
 			logger.Info("PR approved and auto-merged", "car", c.ID)
-			runPostMerge(db, c, logger)
+			runPostMerge(db, clk, c, logger)
 
 		case decision == "CHANGES_REQUESTED":
 			// If a revision is already pending review (the "revised" label is
@@ -1549,17 +1814,17 @@ func handlePrOpenCars(db *gorm.DB, viewer PRViewer, autoMerge bool, repoDir, ymD
 					"car", c.ID)
 				continue
 			}
-			reopenCarWithFeedback(db, viewer, c, status.Reviews, revisedLabel, logger)
+			reopenCarWithFeedback(db, clk, carViewer, c, status.Reviews, revisedLabel, logger)
 			logger.Info("PR changes requested", "car", c.ID, "transition", "pr_open->open")
 
 		case status.State == "OPEN" && cfg != nil && hasReworkLabel(status.Labels, cfg.Yardmaster.ReworkLabel):
 			// Remove the label BEFORE reopening the car to prevent a reopen loop
 			// if the label removal fails (stale label + rework cycle = infinite loop).
-			if err := viewer.RemoveLabel(c.Branch, cfg.Yardmaster.ReworkLabel); err != nil {
+			if err := carViewer.RemoveLabel(c.Branch, cfg.Yardmaster.ReworkLabel); err != nil {
 				logger.Error("Remove rework label failed, skipping reopen to avoid loop", "car", c.ID, "error", err)
 				continue
 			}
-			reopenCarWithFeedback(db, viewer, c, nil, revisedLabel, logger)
+			reopenCarWithFeedback(db, clk, carViewer, c, nil, revisedLabel, logger)
 			logger.Info("PR rework label detected", "car", c.ID, "transition", "pr_open->open")
 		}
 	}
@@ -1581,7 +1846,7 @@ func hasReworkLabel(labels []string, reworkLabel string) bool {
 // as a progress note. CompletedAt is preserved so engines detect isRevision=true.
 // If revisedLabel is non-empty, it is removed from the PR (best-effort) so it can
 // be re-applied on the next revision cycle.
-func reopenCarWithFeedback(db *gorm.DB, viewer PRViewer, c models.Car, reviews []prReview, revisedLabel string, logger *slog.Logger) {
+func reopenCarWithFeedback(db *gorm.DB, clk clock.Clock, viewer PRViewer, c models.Car, reviews []prReview, revisedLabel string, logger *slog.Logger) {
 	if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
 		"status":   "open",
 		"assignee": "",
@@ -1603,7 +1868,7 @@ func reopenCarWithFeedback(db *gorm.DB, viewer PRViewer, c models.Car, reviews [
 	}
 
 	note := formatReviewNote(changesRequestedReviews(reviews), inline, conversation)
-	writeProgressNote(db, c.ID, "yardmaster", note)
+	writeProgressNote(db, clk, c.ID, "yardmaster", note)
 }
 
 // changesRequestedReviews returns only the reviews whose bodies belong in a
@@ -1667,7 +1932,7 @@ func formatReviewNote(reviews []prReview, inline []prInlineComment, conversation
 // car is marked as merged. All merge paths (normal switch, auto-merge,
 // externally merged PR, reconciliation) should call this to ensure consistent
 // post-merge behavior.
-func runPostMerge(db *gorm.DB, c models.Car, logger *slog.Logger) {
+func runPostMerge(db *gorm.DB, clk clock.Clock, c models.Car, logger *slog.Logger) {
 	unblocked, ubErr := UnblockDeps(db, c.ID)
 	if ubErr != nil {
 		logger.Error("Unblock deps", "car", c.ID, "error", ubErr)
@@ -1678,7 +1943,7 @@ func runPostMerge(db *gorm.DB, c models.Car, logger *slog.Logger) {
 			titles[i] = u.ID
 			logger.Info("Car unblocked", "car", u.ID, "dependency", c.ID)
 			if u.Type == "epic" {
-				TryCloseEpic(db, u.ID)
+				TryCloseEpic(db, clk, u.ID)
 			}
 		}
 		messaging.Send(db, "yardmaster", "broadcast", "deps-unblocked",
@@ -1687,15 +1952,17 @@ func runPostMerge(db *gorm.DB, c models.Car, logger *slog.Logger) {
 		)
 	}
 	if c.ParentID != nil && *c.ParentID != "" {
-		TryCloseEpic(db, *c.ParentID)
+		TryCloseEpic(db, clk, *c.ParentID)
 	}
+	markRevertedOriginal(db, clk, &c)
+	markConflictResolvedOriginal(db, clk, &c)
 }
 
 // sleepWithContext sleeps for duration d, returning early if ctx is cancelled.
-func sleepWithContext(ctx context.Context, d time.Duration) {
+func sleepWithContext(ctx context.Context, clk clock.Clock, d time.Duration) {
 	select {
 	case <-ctx.Done():
-	case <-time.After(d):
+	case <-clk.After(d):
 	}
 }
 
@@ -1710,7 +1977,7 @@ func truncateSwitchLog(s string, n int) string {
 // handlePrReviewCars detects cars stuck in pr_review status longer than the
 // configured timeout and resets them to pr_open. This cleans up after Inspection
 // Pit replicas that crash mid-review.
-func handlePrReviewCars(db *gorm.DB, viewer PRViewer, cfg *config.Config, logger *slog.Logger) {
+func handlePrReviewCars(db *gorm.DB, clk clock.Clock, viewer PRViewer, cfg *config.Config, logger *slog.Logger) {
 	if cfg == nil || !cfg.Inspect.Enabled {
 		return
 	}
@@ -1721,7 +1988,7 @@ func handlePrReviewCars(db *gorm.DB, viewer PRViewer, cfg *config.Config, logger
 	}
 
 	var staleCars []models.Car
-	cutoff := time.Now().Add(-timeout)
+	cutoff := clk.Now().Add(-timeout)
 	if err := db.Where("status = ? AND updated_at < ?", "pr_review", cutoff).
 		Find(&staleCars).Error; err != nil {
 		logger.Error("List stale pr_review cars", "error", err)
@@ -1733,7 +2000,7 @@ func handlePrReviewCars(db *gorm.DB, viewer PRViewer, cfg *config.Config, logger
 			"car", c.ID,
 			"branch", c.Branch,
 			"assignee", c.Assignee,
-			"stuck_seconds", int(time.Since(c.UpdatedAt).Seconds()),
+			"stuck_seconds", int(clk.Since(c.UpdatedAt).Seconds()),
 		)
 
 		if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/logutil"
 	"github.com/zulandar/railyard/internal/models"
@@ -83,7 +84,7 @@ func TestSleepWithContext_Cancelled(t *testing.T) {
 	cancel() // Cancel immediately.
 
 	start := time.Now()
-	sleepWithContext(ctx, 10*time.Second)
+	sleepWithContext(ctx, clock.Real, 10*time.Second)
 	elapsed := time.Since(start)
 
 	if elapsed > time.Second {
@@ -91,16 +92,28 @@ func TestSleepWithContext_Cancelled(t *testing.T) {
 	}
 }
 
-func TestSleepWithContext_ShortDuration(t *testing.T) {
-	start := time.Now()
-	sleepWithContext(context.Background(), 50*time.Millisecond)
-	elapsed := time.Since(start)
+func TestSleepWithContext_WaitsForClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	done := make(chan struct{})
+	go func() {
+		sleepWithContext(context.Background(), fake, time.Minute)
+		close(done)
+	}()
 
-	if elapsed < 40*time.Millisecond {
-		t.Errorf("sleepWithContext returned too early: %v", elapsed)
+	if !fake.BlockUntil(1, time.Second) {
+		t.Fatal("sleepWithContext never waited on the clock")
 	}
-	if elapsed > time.Second {
-		t.Errorf("sleepWithContext took too long: %v", elapsed)
+	fake.Advance(59 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("sleepWithContext returned before the duration elapsed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	fake.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sleepWithContext did not return once the duration elapsed")
 	}
 }
 
@@ -124,7 +137,7 @@ func TestSweepOpenEpics_ClosesCompletedEpic(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := sweepOpenEpics(db, logger); err != nil {
+	if err := sweepOpenEpics(db, clock.Real, logger); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -148,7 +161,7 @@ func TestSweepOpenEpics_SkipsEpicWithPendingChildren(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := sweepOpenEpics(db, logger); err != nil {
+	if err := sweepOpenEpics(db, clock.Real, logger); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -166,7 +179,7 @@ func TestSweepOpenEpics_SkipsEmptyEpic(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := sweepOpenEpics(db, logger); err != nil {
+	if err := sweepOpenEpics(db, clock.Real, logger); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -340,7 +353,7 @@ func TestReconcileStaleCars_PerBaseBranch(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := reconcileStaleCars(db, repoDir, false, nil, logger); err != nil {
+	if err := reconcileStaleCars(db, clock.Real, repoDir, false, nil, logger); err != nil {
 		t.Fatalf("reconcileStaleCars: %v", err)
 	}
 
@@ -417,7 +430,7 @@ func TestReconcileStaleCars_PrOpenMergedBranch(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := reconcileStaleCars(db, repoDir, false, nil, logger); err != nil {
+	if err := reconcileStaleCars(db, clock.Real, repoDir, false, nil, logger); err != nil {
 		t.Fatalf("reconcileStaleCars: %v", err)
 	}
 
@@ -478,7 +491,7 @@ func TestReconcileStaleCars_ZeroCommitBranch(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := reconcileStaleCars(db, repoDir, false, nil, logger); err != nil {
+	if err := reconcileStaleCars(db, clock.Real, repoDir, false, nil, logger); err != nil {
 		t.Fatalf("reconcileStaleCars: %v", err)
 	}
 
@@ -544,7 +557,7 @@ func TestReconcileStaleCars_RequirePR_NoMergedPR(t *testing.T) {
 
 	// Mock viewer returns OPEN (not MERGED) — car should NOT transition.
 	viewer := &mockPRViewer{state: "OPEN"}
-	if err := reconcileStaleCars(db, repoDir, true, viewer, logger); err != nil {
+	if err := reconcileStaleCars(db, clock.Real, repoDir, true, viewer, logger); err != nil {
 		t.Fatalf("reconcileStaleCars: %v", err)
 	}
 
@@ -599,7 +612,7 @@ func TestReconcileStaleCars_RequirePR_MergedPR(t *testing.T) {
 
 	// Mock viewer returns MERGED — car should transition.
 	viewer := &mockPRViewer{state: "MERGED"}
-	if err := reconcileStaleCars(db, repoDir, true, viewer, logger); err != nil {
+	if err := reconcileStaleCars(db, clock.Real, repoDir, true, viewer, logger); err != nil {
 		t.Fatalf("reconcileStaleCars: %v", err)
 	}
 
@@ -650,7 +663,7 @@ func TestReconcileStaleCars_RequirePR_APIError(t *testing.T) {
 
 	// Mock viewer returns an error (simulates API outage).
 	viewer := &mockPRViewer{err: fmt.Errorf("gh auth token expired")}
-	if err := reconcileStaleCars(db, repoDir, true, viewer, logger); err != nil {
+	if err := reconcileStaleCars(db, clock.Real, repoDir, true, viewer, logger); err != nil {
 		t.Fatalf("reconcileStaleCars: %v", err)
 	}
 
@@ -729,9 +742,9 @@ func TestCountRecentSwitchFailures_CountsCategorized(t *testing.T) {
 	db.Create(&models.Car{ID: "car-csf2", Track: "backend"})
 
 	// Write various categorized progress notes.
-	writeProgressNote(db, "car-csf2", "yardmaster", "switch:merge-conflict: git merge failed")
-	writeProgressNote(db, "car-csf2", "yardmaster", "switch:fetch-failed: network error")
-	writeProgressNote(db, "car-csf2", "yardmaster", "switch:test-failed: FAIL TestFoo")
+	writeProgressNote(db, clock.Real, "car-csf2", "yardmaster", "switch:merge-conflict: git merge failed")
+	writeProgressNote(db, clock.Real, "car-csf2", "yardmaster", "switch:fetch-failed: network error")
+	writeProgressNote(db, clock.Real, "car-csf2", "yardmaster", "switch:test-failed: FAIL TestFoo")
 
 	count := countRecentSwitchFailures(db, "car-csf2")
 	if count != 3 {
@@ -744,10 +757,10 @@ func TestCountRecentSwitchFailures_IgnoresNonSwitch(t *testing.T) {
 	db.Create(&models.Car{ID: "car-csf3", Track: "backend"})
 
 	// Non-switch progress notes should be ignored.
-	writeProgressNote(db, "car-csf3", "eng-001", "Implemented feature X")
-	writeProgressNote(db, "car-csf3", "eng-001", "Engine stalled: timeout")
+	writeProgressNote(db, clock.Real, "car-csf3", "eng-001", "Implemented feature X")
+	writeProgressNote(db, clock.Real, "car-csf3", "eng-001", "Engine stalled: timeout")
 	// One switch note.
-	writeProgressNote(db, "car-csf3", "yardmaster", "switch:push-failed: auth error")
+	writeProgressNote(db, clock.Real, "car-csf3", "yardmaster", "switch:push-failed: auth error")
 
 	count := countRecentSwitchFailures(db, "car-csf3")
 	if count != 1 {
@@ -771,6 +784,7 @@ func TestSwitchFailureReason_AllCategories(t *testing.T) {
 		{SwitchFailMerge, "repeated-merge-conflict"},
 		{SwitchFailPush, "repeated-push-failure"},
 		{SwitchFailPR, "repeated-pr-failure"},
+		{SwitchFailDiffSize, "repeated-diff-too-large"},
 		{SwitchFailNone, "repeated-switch-failure"},
 	}
 
@@ -791,8 +805,8 @@ func TestMaybeSwitchEscalate_BelowThreshold(t *testing.T) {
 	db.Create(&models.Car{ID: "car-esc1", Track: "backend"})
 
 	// Only 2 failures, threshold is 3.
-	writeProgressNote(db, "car-esc1", "yardmaster", "switch:merge-conflict: conflict 1")
-	writeProgressNote(db, "car-esc1", "yardmaster", "switch:merge-conflict: conflict 2")
+	writeProgressNote(db, clock.Real, "car-esc1", "yardmaster", "switch:merge-conflict: conflict 1")
+	writeProgressNote(db, clock.Real, "car-esc1", "yardmaster", "switch:merge-conflict: conflict 2")
 
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	cfg.Stall.MaxSwitchFailures = 3
@@ -812,9 +826,9 @@ func TestMaybeSwitchEscalate_AtThreshold(t *testing.T) {
 	db.Create(&models.Car{ID: "car-esc2", Track: "backend"})
 
 	// 3 failures, threshold is 3.
-	writeProgressNote(db, "car-esc2", "yardmaster", "switch:fetch-failed: err 1")
-	writeProgressNote(db, "car-esc2", "yardmaster", "switch:fetch-failed: err 2")
-	writeProgressNote(db, "car-esc2", "yardmaster", "switch:fetch-failed: err 3")
+	writeProgressNote(db, clock.Real, "car-esc2", "yardmaster", "switch:fetch-failed: err 1")
+	writeProgressNote(db, clock.Real, "car-esc2", "yardmaster", "switch:fetch-failed: err 2")
+	writeProgressNote(db, clock.Real, "car-esc2", "yardmaster", "switch:fetch-failed: err 3")
 
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	cfg.Stall.MaxSwitchFailures = 3
@@ -862,9 +876,9 @@ func TestMaybeSwitchEscalate_SetsCarToMergeFailed(t *testing.T) {
 	db.Create(&models.Car{ID: "car-esc3", Status: "done", Track: "backend"})
 
 	// 3 failures at threshold.
-	writeProgressNote(db, "car-esc3", "yardmaster", "switch:merge-conflict: conflict 1")
-	writeProgressNote(db, "car-esc3", "yardmaster", "switch:merge-conflict: conflict 2")
-	writeProgressNote(db, "car-esc3", "yardmaster", "switch:merge-conflict: conflict 3")
+	writeProgressNote(db, clock.Real, "car-esc3", "yardmaster", "switch:merge-conflict: conflict 1")
+	writeProgressNote(db, clock.Real, "car-esc3", "yardmaster", "switch:merge-conflict: conflict 2")
+	writeProgressNote(db, clock.Real, "car-esc3", "yardmaster", "switch:merge-conflict: conflict 3")
 
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	cfg.Stall.MaxSwitchFailures = 3
@@ -915,7 +929,7 @@ func TestHandleEscalateResult_GuidanceWithoutEngine_FallsBackToHuman(t *testing.
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	handleEscalateResult(db, "", "car-001", &EscalateResult{
+	handleEscalateResult(db, clock.Real, "", "car-001", &EscalateResult{
 		Action:  EscalateGuidance,
 		Message: "Try rebasing onto main",
 	}, logger)
@@ -948,7 +962,7 @@ func TestHandleEscalateResult_ReassignWithoutEngine_FallsBackToHuman(t *testing.
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	handleEscalateResult(db, "", "car-002", &EscalateResult{
+	handleEscalateResult(db, clock.Real, "", "car-002", &EscalateResult{
 		Action:  EscalateReassign,
 		Message: "Reassign to a different engine",
 	}, logger)
@@ -967,7 +981,7 @@ func TestHandleEscalateResult_GuidanceWithEngine_SendsGuidance(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	handleEscalateResult(db, "eng-001", "car-003", &EscalateResult{
+	handleEscalateResult(db, clock.Real, "eng-001", "car-003", &EscalateResult{
 		Action:  EscalateGuidance,
 		Message: "Try a different approach",
 	}, logger)
@@ -993,7 +1007,7 @@ func TestHandleEscalateResult_HumanAlwaysWorks(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	handleEscalateResult(db, "", "car-004", &EscalateResult{
+	handleEscalateResult(db, clock.Real, "", "car-004", &EscalateResult{
 		Action:  EscalateHuman,
 		Message: "Needs manual merge resolution",
 	}, logger)
@@ -1018,7 +1032,7 @@ func TestHandleEscalateResult_NilResult(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	handleEscalateResult(db, "eng-001", "car-005", nil, logger)
+	handleEscalateResult(db, clock.Real, "eng-001", "car-005", nil, logger)
 
 	if buf.Len() != 0 {
 		t.Errorf("expected no output for nil result, got: %s", buf.String())
@@ -1051,7 +1065,7 @@ func TestHandlePrOpenCars_ChangesRequested(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1094,7 +1108,7 @@ func TestHandlePrOpenCars_ChangesRequestedWithInlineComments(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1138,7 +1152,7 @@ func TestHandlePrOpenCars_ChangesRequestedWithConversationComments(t *testing.T)
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1182,7 +1196,7 @@ func TestHandlePrOpenCars_ChangesRequestedAllCommentTypes(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1227,7 +1241,7 @@ func TestHandlePrOpenCars_ChangesRequestedEmptyComments(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1275,7 +1289,7 @@ func TestHandlePrOpenCars_FetchCommentsError(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1409,7 +1423,7 @@ func TestHandlePrOpenCars_Merged(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1435,7 +1449,7 @@ func TestHandlePrOpenCars_Closed(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1454,7 +1468,7 @@ func TestHandlePrOpenCars_NoPrOpenCars(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1477,7 +1491,7 @@ func TestHandlePrOpenCars_ApprovedNoAction(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1504,7 +1518,7 @@ func TestHandlePrOpenCars_NoBranch(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1541,7 +1555,7 @@ func TestHandlePrOpenCars_ApprovedAutoMerge(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, true, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, true, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1549,6 +1563,9 @@ func TestHandlePrOpenCars_ApprovedAutoMerge(t *testing.T) {
 	if !viewer.mergeCalled {
 		t.Error("expected MergePR to be called")
 	}
+	if !strings.Contains(viewer.mergeBody, "Car-ID: car-am1") || !strings.Contains(viewer.mergeBody, "Epic: epic-am1") {
+		t.Errorf("merge body missing trailers:\n%s", viewer.mergeBody)
+	}
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-am1")
@@ -1582,7 +1599,7 @@ func TestHandlePrOpenCars_ApprovedNoAutoMergeWhenDisabled(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1616,7 +1633,7 @@ func TestHandlePrOpenCars_ApprovedMergeFailure(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, true, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, true, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1654,7 +1671,7 @@ func TestHandlePrOpenCars_ApprovedButNotOpen_NoMerge(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, true, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, true, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1699,7 +1716,7 @@ func TestHandlePrOpenCars_ExternalMergeUnblocksDeps(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1747,7 +1764,7 @@ func TestHandlePrOpenCars_AutoMergeUnblocksDeps(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, true, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, true, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -1781,7 +1798,7 @@ func TestRunPostMerge_UnblocksAndClosesEpic(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	runPostMerge(db, mergedCar, logger)
+	runPostMerge(db, clock.Real, mergedCar, logger)
 
 	// Car B should be unblocked.
 	var carB models.Car
@@ -1810,7 +1827,7 @@ func TestRunPostMerge_EmitsDepsUnblockedBroadcast(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	runPostMerge(db, mergedCar, logger)
+	runPostMerge(db, clock.Real, mergedCar, logger)
 
 	// Verify the deps-unblocked broadcast message was sent.
 	var msg models.Message
@@ -1831,7 +1848,7 @@ func TestRunPostMerge_NoBroadcastWhenNoDeps(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	runPostMerge(db, mergedCar, logger)
+	runPostMerge(db, clock.Real, mergedCar, logger)
 
 	// No deps to unblock — no broadcast should be sent.
 	var count int64
@@ -1955,7 +1972,7 @@ func TestSweepOpenEpics_CountError_LogsAndContinues(t *testing.T) {
 	// so this test verifies the function doesn't panic.
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := sweepOpenEpics(db, logger)
+	err := sweepOpenEpics(db, clock.Real, logger)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2010,6 +2027,7 @@ type mockPRViewer struct {
 	err               error
 	mergeErr          error
 	mergeCalled       bool
+	mergeBody         string
 	commentCount      int
 	countErr          error
 	removeLabelCalled bool
@@ -2034,8 +2052,9 @@ func (m *mockPRViewer) FetchComments(branch string) ([]prInlineComment, []prConv
 	return m.inlineComments, m.convComments, m.fetchErr
 }
 
-func (m *mockPRViewer) MergePR(branch string) error {
+func (m *mockPRViewer) MergePR(branch, body string) error {
 	m.mergeCalled = true
+	m.mergeBody = body
 	return m.mergeErr
 }
 
@@ -2210,9 +2229,9 @@ func TestMaybeSwitchEscalate_WithCooldown(t *testing.T) {
 	db.Create(&models.Car{ID: "car-cool1", Track: "backend"})
 
 	// Write enough failures to trigger escalation.
-	writeProgressNote(db, "car-cool1", "yardmaster", "switch:merge-conflict: conflict 1")
-	writeProgressNote(db, "car-cool1", "yardmaster", "switch:merge-conflict: conflict 2")
-	writeProgressNote(db, "car-cool1", "yardmaster", "switch:merge-conflict: conflict 3")
+	writeProgressNote(db, clock.Real, "car-cool1", "yardmaster", "switch:merge-conflict: conflict 1")
+	writeProgressNote(db, clock.Real, "car-cool1", "yardmaster", "switch:merge-conflict: conflict 2")
+	writeProgressNote(db, clock.Real, "car-cool1", "yardmaster", "switch:merge-conflict: conflict 3")
 
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	cfg.Stall.MaxSwitchFailures = 3
@@ -2559,7 +2578,7 @@ func TestHandlePrOpenCars_ConflictingAutoRebase(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, repoDir, repoDir, nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, repoDir, repoDir, nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -2612,7 +2631,7 @@ func TestHandlePrOpenCars_ConflictingSkipWhenMainUnchanged(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, repoDir, repoDir, nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, repoDir, repoDir, nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -2665,7 +2684,7 @@ func TestHandlePrOpenCars_UnresolvableConflict(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, repoDir, repoDir, nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, repoDir, repoDir, nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -2718,7 +2737,7 @@ func TestHandlePrOpenCars_MergeableUnknownSkipped(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -2766,7 +2785,7 @@ func TestHandlePrOpenCars_ConflictingAndApproved(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, true, repoDir, repoDir, nil, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, true, repoDir, repoDir, nil, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -2801,7 +2820,7 @@ func TestReopenCarWithFeedback_SetsStatusOpen(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen1", Branch: "ry/backend/car-reopen1"}, nil, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen1", Branch: "ry/backend/car-reopen1"}, nil, "", logger)
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-reopen1")
@@ -2826,7 +2845,7 @@ func TestReopenCarWithFeedback_PreservesCompletedAt(t *testing.T) {
 	viewer := &mockPRViewer{}
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen2", Branch: "ry/backend/car-reopen2"}, nil, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen2", Branch: "ry/backend/car-reopen2"}, nil, "", logger)
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-reopen2")
@@ -2846,7 +2865,7 @@ func TestReopenCarWithFeedback_FetchCommentsError_StillReopens(t *testing.T) {
 	viewer := &mockPRViewer{fetchErr: fmt.Errorf("gh api failed")}
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen3", Branch: "ry/backend/car-reopen3"}, nil, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen3", Branch: "ry/backend/car-reopen3"}, nil, "", logger)
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-reopen3")
@@ -2875,7 +2894,7 @@ func TestReopenCarWithFeedback_ProgressNoteFormat(t *testing.T) {
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
 	reviews := []prReview{{Body: "Needs work", Author: "alice"}}
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen4", Branch: "ry/backend/car-reopen4"}, reviews, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen4", Branch: "ry/backend/car-reopen4"}, reviews, "", logger)
 
 	var notes []models.CarProgress
 	db.Where("car_id = ?", "car-reopen4").Find(&notes)
@@ -2919,7 +2938,7 @@ func TestHandlePrOpenCars_ChangesRequestedSkippedWhenRevisionPending(t *testing.
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger); err != nil {
+	if err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger); err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
 
@@ -2952,7 +2971,7 @@ func TestHandlePrOpenCars_ChangesRequestedReopensWhenNoRevisionPending(t *testin
 
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	if err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger); err != nil {
+	if err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger); err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
 
@@ -2983,7 +3002,7 @@ func TestReopenCarWithFeedback_ExcludesNonChangesRequestedReviewBodies(t *testin
 		{State: "COMMENTED", Body: "just a thought", Author: "inspect[bot]"},
 		{State: "CHANGES_REQUESTED", Body: "fix the nil deref", Author: "inspect[bot]"},
 	}
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-filter1", Branch: "ry/backend/car-filter1"}, reviews, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-filter1", Branch: "ry/backend/car-filter1"}, reviews, "", logger)
 
 	var notes []models.CarProgress
 	db.Where("car_id = ?", "car-filter1").Find(&notes)
@@ -3013,7 +3032,7 @@ func TestReopenCarWithFeedback_EmptyComments(t *testing.T) {
 	viewer := &mockPRViewer{}
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen5", Branch: "ry/backend/car-reopen5"}, nil, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen5", Branch: "ry/backend/car-reopen5"}, nil, "", logger)
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-reopen5")
@@ -3039,7 +3058,7 @@ func TestReopenCarWithFeedback_RemovesRevisedLabel(t *testing.T) {
 	viewer := &mockPRViewer{}
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen6", Branch: "ry/backend/car-reopen6"}, nil, "railyard: revised", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen6", Branch: "ry/backend/car-reopen6"}, nil, "railyard: revised", logger)
 
 	if !viewer.removeLabelCalled {
 		t.Error("expected RemoveLabel to be called for revised label")
@@ -3060,7 +3079,7 @@ func TestReopenCarWithFeedback_SkipsRemoveWhenNoRevisedLabel(t *testing.T) {
 	viewer := &mockPRViewer{}
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	reopenCarWithFeedback(db, viewer, models.Car{ID: "car-reopen7", Branch: "ry/backend/car-reopen7"}, nil, "", logger)
+	reopenCarWithFeedback(db, clock.Real, viewer, models.Car{ID: "car-reopen7", Branch: "ry/backend/car-reopen7"}, nil, "", logger)
 
 	if viewer.removeLabelCalled {
 		t.Error("RemoveLabel should not be called when revisedLabel is empty")
@@ -3083,7 +3102,7 @@ func TestHandlePrOpenCars_ChangesRequested_RemovesRevisedLabel(t *testing.T) {
 	cfg.Yardmaster.RevisedLabel = "railyard: revised"
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -3116,7 +3135,7 @@ func TestHandlePrOpenCars_ReworkLabel(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3152,7 +3171,7 @@ func TestHandlePrOpenCars_ReworkLabelCustom(t *testing.T) {
 	cfg.Yardmaster.ReworkLabel = "needs-rework"
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3181,7 +3200,7 @@ func TestHandlePrOpenCars_NoReworkLabel(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3210,7 +3229,7 @@ func TestHandlePrOpenCars_ReworkLabelCaseExact(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3240,7 +3259,7 @@ func TestHandlePrOpenCars_RemoveLabelFailureSkipsReopen(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3269,7 +3288,7 @@ func TestHandlePrOpenCars_ReworkLabelOnClosedPR(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3327,7 +3346,7 @@ func TestHandlePrOpenCars_CommentsIgnoredWhenApproved(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3356,7 +3375,7 @@ func TestHandlePrOpenCars_MultipleCarsNoVerdictNoReopen(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3387,7 +3406,7 @@ func TestHandlePrOpenCars_ReworkLabelReopens(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3432,7 +3451,7 @@ func TestHandlePrOpenCars_NewCommentsDoNotReopen(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3465,7 +3484,7 @@ func TestHandlePrOpenCars_DerivedApproveAutoMerges(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, true /* autoMerge */, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, true /* autoMerge */, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3500,7 +3519,7 @@ func TestHandlePrOpenCars_DerivedApproveAwaitsHumanWhenAutoMergeOff(t *testing.T
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false /* autoMerge off */, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false /* autoMerge off */, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3535,7 +3554,7 @@ func TestHandlePrOpenCars_DerivedChangesRequestedReopens(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3568,7 +3587,7 @@ func TestHandlePrOpenCars_ChangesRequestedStillWorks(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3606,7 +3625,7 @@ func TestHandlePrOpenCars_AllTriggersInOneBatch(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
-	err := handlePrOpenCars(db, viewer, false, "", "", cfg, logger)
+	err := handlePrOpenCars(db, clock.Real, viewer, false, "", "", cfg, logger)
 	if err != nil {
 		t.Fatalf("handlePrOpenCars: %v", err)
 	}
@@ -3648,7 +3667,7 @@ func TestHandlePrReviewCars_StaleReview(t *testing.T) {
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
 
-	handlePrReviewCars(db, viewer, cfg, logger)
+	handlePrReviewCars(db, clock.Real, viewer, cfg, logger)
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-stale1")
@@ -3688,7 +3707,7 @@ func TestHandlePrReviewCars_NotStaleYet(t *testing.T) {
 	var buf bytes.Buffer
 	logger := testLogger(&buf)
 
-	handlePrReviewCars(db, viewer, cfg, logger)
+	handlePrReviewCars(db, clock.Real, viewer, cfg, logger)
 
 	var c models.Car
 	db.First(&c, "id = ?", "car-fresh1")
@@ -3713,5 +3732,5 @@ func TestHandlePrReviewCars_DisabledInspect(t *testing.T) {
 	logger := testLogger(&buf)
 
 	// Should return immediately without panic or DB queries.
-	handlePrReviewCars(db, nil, cfg, logger)
+	handlePrReviewCars(db, clock.Real, nil, cfg, logger)
 }
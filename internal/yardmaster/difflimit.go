@@ -0,0 +1,217 @@
+package yardmaster
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// splitReportFiles is how many of the largest files a split-car request
+// lists.
+const splitReportFiles = 10
+
+// diffFileStat is one file changed on a car's branch. Binary files count as
+// a changed file with no lines.
+type diffFileStat struct {
+	Path   string
+	Lines  int // added plus deleted
+	Binary bool
+}
+
+// diffStat is what a car's branch changes against its base, after the
+// track's exclude globs.
+type diffStat struct {
+	Files []diffFileStat
+	Lines int
+}
+
+// measureDiff returns what branch changes since it forked from baseBranch.
+// Remote-tracking refs are preferred, as the yardmaster worktree's local
+// branches may be stale after a fetch.
+func measureDiff(repoDir, branch, baseBranch string, exclude []string) (*diffStat, error) {
+	base := firstRef(repoDir, "origin/"+baseBranch, baseBranch)
+	head := firstRef(repoDir, "origin/"+branch, branch)
+	if base == "" || head == "" {
+		return nil, fmt.Errorf("resolve %s or %s", baseBranch, branch)
+	}
+	cmd := exec.Command("git", "diff", "--numstat", "-z", "-M", base+"..."+head)
+	cmd.Dir = repoDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	files, err := parseNumstat(out)
+	if err != nil {
+		return nil, err
+	}
+	stat := &diffStat{}
+	for _, f := range files {
+		if diffExcluded(f.Path, exclude) {
+			continue
+		}
+		stat.Files = append(stat.Files, f)
+		stat.Lines += f.Lines
+	}
+	return stat, nil
+}
+
+// firstRef returns the first of refs that names a commit in repoDir, or ""
+// when none does.
+func firstRef(repoDir string, refs ...string) string {
+	for _, ref := range refs {
+		cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		cmd.Dir = repoDir
+		if cmd.Run() == nil {
+			return ref
+		}
+	}
+	return ""
+}
+
+// parseNumstat parses `git diff --numstat -z` output. A rename's entry has
+// an empty path followed by the old and new paths as separate fields; the
+// new path is the one reported.
+func parseNumstat(out []byte) ([]diffFileStat, error) {
+	fields := strings.Split(string(out), "\x00")
+	var files []diffFileStat
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" {
+			continue
+		}
+		entry := fields[i]
+		parts := strings.SplitN(entry, "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed numstat entry %q", entry)
+		}
+		f := diffFileStat{Path: parts[2]}
+		if f.Path == "" {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("truncated numstat rename entry %q", entry)
+			}
+			f.Path = fields[i+2]
+			i += 2
+		}
+		if parts[0] == "-" && parts[1] == "-" {
+			f.Binary = true
+		} else {
+			added, aErr := strconv.Atoi(parts[0])
+			deleted, dErr := strconv.Atoi(parts[1])
+			if aErr != nil || dErr != nil {
+				return nil, fmt.Errorf("malformed numstat entry %q", entry)
+			}
+			f.Lines = added + deleted
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// diffExcluded reports whether p matches one of the exclude globs. A glob
+// without a slash matches the base name, so "go.sum" covers every go.sum.
+func diffExcluded(p string, exclude []string) bool {
+	for _, g := range exclude {
+		target := p
+		if !strings.Contains(g, "/") {
+			target = path.Base(p)
+		}
+		if ok, _ := path.Match(g, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// diffLimitError returns an error naming each limit the diff exceeds, or nil
+// when it is within the track's limits. A zero limit is not enforced.
+func diffLimitError(stat *diffStat, limit *config.DiffLimitConfig) error {
+	var over []string
+	if limit.MaxFiles > 0 && len(stat.Files) > limit.MaxFiles {
+		over = append(over, fmt.Sprintf("%d files (limit %d)", len(stat.Files), limit.MaxFiles))
+	}
+	if limit.MaxLines > 0 && stat.Lines > limit.MaxLines {
+		over = append(over, fmt.Sprintf("%d lines (limit %d)", stat.Lines, limit.MaxLines))
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	return fmt.Errorf("diff too large: changes %s", strings.Join(over, " and "))
+}
+
+// checkDiffLimit measures the car's branch against the track's diff_limit
+// before any tests run. It reports whether the merge was deferred; an
+// oversized car is blocked and dispatch is asked to split it. A diff that
+// cannot be measured is logged and does not defer the merge. Dry runs report
+// the result without blocking the car.
+func checkDiffLimit(db *gorm.DB, car *models.Car, opts SwitchOpts, baseBranch string, result *SwitchResult) bool {
+	stat, err := measureDiff(opts.RepoDir, car.Branch, baseBranch, opts.DiffLimit.Exclude)
+	if err != nil {
+		slog.Warn("Switch: diff size not measured", "car", car.ID, "error", err)
+		return false
+	}
+	slog.Info("Switch: diff measured", "car", car.ID, "files", len(stat.Files), "lines", stat.Lines)
+
+	limitErr := diffLimitError(stat, opts.DiffLimit)
+	if limitErr == nil {
+		return false
+	}
+	result.FailureCategory = SwitchFailDiffSize
+	result.Error = limitErr
+	slog.Warn("Switch: diff limit exceeded, merge deferred", "car", car.ID, "error", limitErr)
+	if opts.DryRun {
+		return true
+	}
+
+	if dbErr := db.Model(&models.Car{}).Where("id = ?", car.ID).Updates(map[string]interface{}{
+		"status":         "blocked",
+		"blocked_reason": models.BlockedReasonDiffTooLarge,
+	}).Error; dbErr != nil {
+		slog.Error("update car to blocked", "car", car.ID, "error", dbErr)
+	}
+	publish(opts.Bus, plugin.MergeFailed, plugin.MergeFailedEvent{
+		CarID:  car.ID,
+		Reason: limitErr.Error(),
+	})
+	messaging.Send(db, "yardmaster", "dispatch", "split-car",
+		splitCarRequest(car, baseBranch, stat, limitErr),
+		messaging.SendOpts{CarID: car.ID, Priority: "urgent"},
+	)
+	return true
+}
+
+// splitCarRequest is the message asking dispatch to split an oversized car,
+// listing the files that contribute most to its diff.
+func splitCarRequest(car *models.Car, baseBranch string, stat *diffStat, limitErr error) string {
+	files := append([]diffFileStat(nil), stat.Files...)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Lines > files[j].Lines })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Merge deferred for car %s (%s) on branch %s: %v.\n\n", car.ID, car.Track, car.Branch, limitErr)
+	fmt.Fprintf(&b, "Largest changes against %s:\n", baseBranch)
+	for i, f := range files {
+		if i == splitReportFiles {
+			fmt.Fprintf(&b, "  ... and %d more files\n", len(files)-i)
+			break
+		}
+		if f.Binary {
+			fmt.Fprintf(&b, "  %s (binary)\n", f.Path)
+		} else {
+			fmt.Fprintf(&b, "  %s (%d lines)\n", f.Path, f.Lines)
+		}
+	}
+	b.WriteString("\nSplit the remaining scope into follow-up cars, narrow this car's description to a reviewable slice, then send requeue-car so an engine trims the branch to that slice.")
+	return b.String()
+}
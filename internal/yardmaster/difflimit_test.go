@@ -0,0 +1,124 @@
+package yardmaster
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestParseNumstat(t *testing.T) {
+	out := "3\t1\tmain.go\x00-\t-\tlogo.png\x002\t0\t\x00old/name.go\x00new/name.go\x00"
+	got, err := parseNumstat([]byte(out))
+	if err != nil {
+		t.Fatalf("parseNumstat: %v", err)
+	}
+	want := []diffFileStat{
+		{Path: "main.go", Lines: 4},
+		{Path: "logo.png", Binary: true},
+		{Path: "new/name.go", Lines: 2},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseNumstat([]byte("x\ty\tmain.go\x00")); err == nil {
+		t.Error("expected error for malformed counts")
+	}
+}
+
+func TestDiffExcluded(t *testing.T) {
+	exclude := []string{"go.sum", "*.lock", "gen/*.pb.go"}
+	for p, want := range map[string]bool{
+		"go.sum":            true,
+		"tools/go.sum":      true,
+		"web/yarn.lock":     true,
+		"gen/api.pb.go":     true,
+		"sub/gen/api.pb.go": false,
+		"main.go":           false,
+	} {
+		if got := diffExcluded(p, exclude); got != want {
+			t.Errorf("diffExcluded(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestDiffLimitError(t *testing.T) {
+	stat := &diffStat{Files: make([]diffFileStat, 12), Lines: 900}
+	if err := diffLimitError(stat, &config.DiffLimitConfig{MaxFiles: 20, MaxLines: 1000}); err != nil {
+		t.Errorf("within limits: %v", err)
+	}
+	err := diffLimitError(stat, &config.DiffLimitConfig{MaxFiles: 10, MaxLines: 500})
+	if err == nil || err.Error() != "diff too large: changes 12 files (limit 10) and 900 lines (limit 500)" {
+		t.Errorf("err = %v", err)
+	}
+	if err := diffLimitError(stat, &config.DiffLimitConfig{MaxLines: 800}); err == nil || strings.Contains(err.Error(), "files") {
+		t.Errorf("lines-only limit: %v", err)
+	}
+}
+
+func TestSwitch_DiffLimitDefersMerge(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-dl1")
+	writeFile(t, repoDir, "a.go", "package a\n\nfunc A() {}\n")
+	writeFile(t, repoDir, "b.go", "package a\n\nfunc B() {}\n")
+	writeFile(t, repoDir, "go.sum", strings.Repeat("dep v1.0.0 h1:x\n", 50))
+	run(repoDir, "git", "add", "-A")
+	run(repoDir, "git", "commit", "-m", "car work")
+	run(repoDir, "git", "push", "origin", "ry/alice/backend/car-dl1")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{
+		ID:     "car-dl1",
+		Title:  "Big change",
+		Track:  "backend",
+		Branch: "ry/alice/backend/car-dl1",
+		Status: "done",
+	})
+
+	// go.sum is excluded, so only a.go and b.go count.
+	result, err := Switch(db, "car-dl1", SwitchOpts{
+		RepoDir:     repoDir,
+		TestCommand: "false", // must not run
+		DiffLimit:   &config.DiffLimitConfig{MaxFiles: 1, MaxLines: 100, Exclude: []string{"go.sum"}},
+	})
+	if err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+	if result.FailureCategory != SwitchFailDiffSize || result.Merged || result.TestOutput != "" {
+		t.Fatalf("result = %+v", result)
+	}
+	if !strings.Contains(result.Error.Error(), "2 files (limit 1)") || strings.Contains(result.Error.Error(), "lines") {
+		t.Errorf("error = %v", result.Error)
+	}
+
+	var car models.Car
+	db.First(&car, "id = ?", "car-dl1")
+	if car.Status != "blocked" || car.BlockedReason != models.BlockedReasonDiffTooLarge {
+		t.Errorf("car = %s/%s, want blocked/%s", car.Status, car.BlockedReason, models.BlockedReasonDiffTooLarge)
+	}
+	var msg models.Message
+	if err := db.Where("to_agent = ? AND subject = ?", "dispatch", "split-car").First(&msg).Error; err != nil {
+		t.Fatalf("no split-car message: %v", err)
+	}
+	if msg.CarID != "car-dl1" || !strings.Contains(msg.Body, "a.go (3 lines)") || strings.Contains(msg.Body, "go.sum") {
+		t.Errorf("message = %+v", msg)
+	}
+
+	// Within the limit the car merges as usual.
+	db.Model(&models.Car{}).Where("id = ?", "car-dl1").Updates(map[string]interface{}{"status": "done", "blocked_reason": ""})
+	result, err = Switch(db, "car-dl1", SwitchOpts{
+		RepoDir:     repoDir,
+		TestCommand: "true",
+		DiffLimit:   &config.DiffLimitConfig{MaxFiles: 2, Exclude: []string{"go.sum"}},
+	})
+	if err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+	if !result.Merged {
+		t.Errorf("result = %+v, want merged", result)
+	}
+}
@@ -0,0 +1,285 @@
+package yardmaster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	ghapi "github.com/zulandar/railyard/internal/github"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// draftPRStatuses are the car statuses whose pushed work gets an early draft PR.
+var draftPRStatuses = []string{"claimed", "in_progress"}
+
+// prChecks summarizes the CI checks on a PR.
+type prChecks struct {
+	Passing int
+	Failing int
+	Pending int
+}
+
+func (c prChecks) String() string {
+	if c.Passing+c.Failing+c.Pending == 0 {
+		return "none reported yet"
+	}
+	return fmt.Sprintf("%d passing, %d failing, %d pending", c.Passing, c.Failing, c.Pending)
+}
+
+// draftPROps are the git and gh operations used by syncDraftPRs, injectable
+// for testing.
+type draftPROps struct {
+	Fetch       func(repoDir string) error
+	HasCommits  func(repoDir, branch, baseBranch string) bool
+	GetExisting func(repoDir, branch string) (string, error)
+	CreateDraft func(repoDir, title, body, branch string) (string, error)
+	UpdateBody  func(repoDir, branch, body string) error
+	Checks      func(repoDir, branch string) (prChecks, error)
+}
+
+func defaultDraftPROps() draftPROps {
+	return draftPROps{
+		Fetch:       gitFetch,
+		HasCommits:  branchHasUniqueCommits,
+		GetExisting: getExistingPR,
+		CreateDraft: createDraftPR,
+		UpdateBody:  updatePRBody,
+		Checks:      getPRChecks,
+	}
+}
+
+// newGitHubClient returns the shared GitHub API client when a token is in the
+// environment (GITHUB_TOKEN or GH_TOKEN), or nil to keep using the gh CLI.
+func newGitHubClient(logger *slog.Logger) *ghapi.Client {
+	token := ghapi.TokenFromEnv()
+	if token == "" {
+		return nil
+	}
+	return ghapi.New(ghapi.Options{Token: token, Logger: logger})
+}
+
+// draftPROpsFor returns the draft PR operations, polling checks through gh
+// (the shared API client) when it is non-nil: repeated polls of unchanged
+// checks are then answered from the ETag cache and do not count against the
+// rate limit.
+func draftPROpsFor(cfg *config.Config, gh *ghapi.Client) draftPROps {
+	ops := defaultDraftPROps()
+	if gh == nil {
+		return ops
+	}
+	owner, name, err := config.ParseGitHubRepo(cfg.Repo)
+	if err != nil {
+		return ops
+	}
+	ops.Checks = func(_, branch string) (prChecks, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		return apiPRChecks(ctx, gh, owner, name, branch)
+	}
+	return ops
+}
+
+// apiPRChecks summarizes the check runs and commit statuses on the head of
+// branch through the GitHub REST API.
+func apiPRChecks(ctx context.Context, gh *ghapi.Client, owner, name, branch string) (prChecks, error) {
+	ref := fmt.Sprintf("repos/%s/%s/commits/%s", url.PathEscape(owner), url.PathEscape(name), url.PathEscape(branch))
+	var runs struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := gh.GetJSON(ctx, ref+"/check-runs?per_page=100", &runs); err != nil {
+		return prChecks{}, err
+	}
+	var status struct {
+		Statuses []struct {
+			State string `json:"state"`
+		} `json:"statuses"`
+	}
+	if err := gh.GetJSON(ctx, ref+"/status", &status); err != nil {
+		return prChecks{}, err
+	}
+
+	var c prChecks
+	for _, r := range runs.CheckRuns {
+		switch {
+		case r.Status != "completed":
+			c.Pending++
+		case r.Conclusion == "success" || r.Conclusion == "neutral" || r.Conclusion == "skipped":
+			c.Passing++
+		default:
+			c.Failing++
+		}
+	}
+	for _, s := range status.Statuses {
+		switch s.State {
+		case "success":
+			c.Passing++
+		case "pending":
+			c.Pending++
+		default:
+			c.Failing++
+		}
+	}
+	return c, nil
+}
+
+// draftPRState remembers the last body written to each car's draft PR so
+// unchanged drafts are not re-edited every poll.
+type draftPRState struct {
+	bodies map[string]string // car ID -> last body
+}
+
+func newDraftPRState() *draftPRState {
+	return &draftPRState{bodies: make(map[string]string)}
+}
+
+// syncDraftPRs opens a draft PR for each in-progress car whose branch has
+// pushed commits, and refreshes the body of drafts already open with the
+// latest progress notes and check status. Switch marks the PR ready for
+// review when the car is done. repoDir is the main repository; cars on a
+// track with its own repo are synced in that track's clone.
+func syncDraftPRs(db *gorm.DB, clk clock.Clock, cfg *config.Config, repoDir string, ops draftPROps, state *draftPRState, logger *slog.Logger) error {
+	var cars []models.Car
+	if err := db.Where("status IN ? AND branch != '' AND type != ?", draftPRStatuses, "epic").
+		Order("created_at ASC").Find(&cars).Error; err != nil {
+		return fmt.Errorf("list in-progress cars: %w", err)
+	}
+
+	fetched := make(map[string]bool)
+	for i := range cars {
+		c := &cars[i]
+		if !cfg.RequirePRFor(c.Track) {
+			continue
+		}
+		// A track with its own repo has its branches and PRs there.
+		carRepoDir, err := trackRepoDir(cfg, repoDir, c.Track)
+		if err != nil {
+			logger.Warn("Prepare track repo for draft PR", "car", c.ID, "track", c.Track, "error", err)
+			continue
+		}
+		if !fetched[carRepoDir] {
+			fetched[carRepoDir] = true
+			if err := ops.Fetch(carRepoDir); err != nil {
+				logger.Warn("Draft PR fetch failed", "repo", carRepoDir, "error", err)
+			}
+		}
+		baseBranch := carBaseBranch(cfg, c)
+
+		if c.DraftPRAt == nil {
+			if !ops.HasCommits(carRepoDir, c.Branch, baseBranch) {
+				continue
+			}
+			if url, err := ops.GetExisting(carRepoDir, c.Branch); err == nil && url != "" {
+				// A PR is already open (e.g. from an earlier run); adopt it.
+				markDraftPROpened(db, clk, c, logger)
+				logger.Info("Draft PR adopted", "car", c.ID, "pr_url", url)
+				continue
+			}
+			pr := renderPR(db, c, carRepoDir, baseBranch, cfg)
+			body := pr.Body + draftStatusSection(c, prChecks{})
+			url, err := ops.CreateDraft(carRepoDir, pr.Title, body, c.Branch)
+			if err != nil {
+				logger.Warn("Open draft PR failed", "car", c.ID, "branch", c.Branch, "error", err)
+				continue
+			}
+			markDraftPROpened(db, clk, c, logger)
+			state.bodies[c.ID] = body
+			logger.Info("Draft PR opened for in-progress car", "car", c.ID, "branch", c.Branch, "pr_url", url)
+			continue
+		}
+
+		checks, err := ops.Checks(carRepoDir, c.Branch)
+		if err != nil {
+			logger.Debug("Draft PR checks unavailable", "car", c.ID, "error", err)
+		}
+		pr := renderPR(db, c, carRepoDir, baseBranch, cfg)
+		body := pr.Body + draftStatusSection(c, checks)
+		if state.bodies[c.ID] == body {
+			continue
+		}
+		if err := ops.UpdateBody(carRepoDir, c.Branch, body); err != nil {
+			logger.Warn("Update draft PR body failed", "car", c.ID, "error", err)
+			continue
+		}
+		state.bodies[c.ID] = body
+		logger.Debug("Draft PR body refreshed", "car", c.ID)
+	}
+	return nil
+}
+
+func markDraftPROpened(db *gorm.DB, clk clock.Clock, c *models.Car, logger *slog.Logger) {
+	now := clk.Now()
+	if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Update("draft_pr_at", now).Error; err != nil {
+		logger.Error("Record draft PR", "car", c.ID, "error", err)
+		return
+	}
+	c.DraftPRAt = &now
+}
+
+// draftStatusSection is appended to the body of a draft PR while the car is
+// still being worked. It is dropped when Switch rewrites the body at ready.
+func draftStatusSection(c *models.Car, checks prChecks) string {
+	var b strings.Builder
+	b.WriteString("\n## Status\n")
+	b.WriteString("**Work in progress** — this draft is updated as the engine pushes and is marked ready for review when the car is done.\n\n")
+	fmt.Fprintf(&b, "- Car status: %s\n", c.Status)
+	if c.Assignee != "" {
+		fmt.Fprintf(&b, "- Engine: %s\n", c.Assignee)
+	}
+	fmt.Fprintf(&b, "- Checks: %s\n", checks)
+	return b.String()
+}
+
+// getPRChecks summarizes the status check rollup of the PR for branch.
+func getPRChecks(repoDir, branch string) (prChecks, error) {
+	cmd := exec.Command("gh", "pr", "view", branch, "--json", "statusCheckRollup")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return prChecks{}, fmt.Errorf("gh pr view %s: %w", branch, err)
+	}
+	return parsePRChecks(out)
+}
+
+// parsePRChecks counts check runs (status/conclusion) and commit statuses
+// (state) from gh's statusCheckRollup JSON.
+func parsePRChecks(data []byte) (prChecks, error) {
+	var result struct {
+		StatusCheckRollup []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			State      string `json:"state"`
+		} `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return prChecks{}, fmt.Errorf("parse status checks: %w", err)
+	}
+	var c prChecks
+	for _, r := range result.StatusCheckRollup {
+		verdict := r.Conclusion
+		if r.State != "" {
+			verdict = r.State
+		} else if r.Status != "" && r.Status != "COMPLETED" {
+			verdict = "PENDING"
+		}
+		switch verdict {
+		case "SUCCESS", "NEUTRAL", "SKIPPED":
+			c.Passing++
+		case "PENDING", "EXPECTED", "":
+			c.Pending++
+		default: // FAILURE, ERROR, CANCELLED, TIMED_OUT, ACTION_REQUIRED, ...
+			c.Failing++
+		}
+	}
+	return c, nil
+}
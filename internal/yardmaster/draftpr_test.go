@@ -0,0 +1,249 @@
+package yardmaster
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	ghapi "github.com/zulandar/railyard/internal/github"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// fakeDraftPROps records draft PR operations against an in-memory set of PRs.
+type fakeDraftPROps struct {
+	withCommits map[string]bool   // branch -> has pushed commits
+	prs         map[string]string // branch -> PR URL
+	created     []string          // branches with a PR created
+	bases       map[string]string // branch -> base HasCommits compared against
+	updates     map[string][]string
+	checks      prChecks
+}
+
+func newFakeDraftPROps() *fakeDraftPROps {
+	return &fakeDraftPROps{
+		withCommits: make(map[string]bool),
+		prs:         make(map[string]string),
+		updates:     make(map[string][]string),
+		bases:       make(map[string]string),
+	}
+}
+
+func (f *fakeDraftPROps) ops() draftPROps {
+	return draftPROps{
+		Fetch: func(string) error { return nil },
+		HasCommits: func(_, branch, base string) bool {
+			f.bases[branch] = base
+			return f.withCommits[branch]
+		},
+		GetExisting: func(_, branch string) (string, error) {
+			if url, ok := f.prs[branch]; ok {
+				return url, nil
+			}
+			return "", fmt.Errorf("no PR for %s", branch)
+		},
+		CreateDraft: func(_, title, body, branch string) (string, error) {
+			url := "https://github.com/org/repo/pull/" + branch
+			f.prs[branch] = url
+			f.created = append(f.created, branch)
+			f.updates[branch] = append(f.updates[branch], body)
+			return url, nil
+		},
+		UpdateBody: func(_, branch, body string) error {
+			f.updates[branch] = append(f.updates[branch], body)
+			return nil
+		},
+		Checks: func(string, string) (prChecks, error) {
+			return f.checks, nil
+		},
+	}
+}
+
+func TestSyncDraftPRs_OpensDraftOnFirstPush(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-d1", Title: "Pushed", Track: "backend", Status: "in_progress", Branch: "ry/car-d1", Assignee: "eng-1"})
+	db.Create(&models.Car{ID: "car-d2", Title: "Not pushed", Track: "backend", Status: "in_progress", Branch: "ry/car-d2"})
+	db.Create(&models.Car{ID: "car-d3", Title: "Open", Track: "backend", Status: "open", Branch: "ry/car-d3"})
+
+	fake := newFakeDraftPROps()
+	fake.withCommits["ry/car-d1"] = true
+	fake.withCommits["ry/car-d3"] = true
+
+	var buf bytes.Buffer
+	if err := syncDraftPRs(db, clock.Real, &config.Config{RequirePR: true}, "/repo", fake.ops(), newDraftPRState(), testLogger(&buf)); err != nil {
+		t.Fatalf("syncDraftPRs: %v", err)
+	}
+
+	if len(fake.created) != 1 || fake.created[0] != "ry/car-d1" {
+		t.Fatalf("created = %v, want only ry/car-d1", fake.created)
+	}
+	body := fake.updates["ry/car-d1"][0]
+	if !strings.Contains(body, "## Status") || !strings.Contains(body, "Engine: eng-1") {
+		t.Errorf("draft body missing status section:\n%s", body)
+	}
+
+	var c models.Car
+	db.First(&c, "id = ?", "car-d1")
+	if c.DraftPRAt == nil {
+		t.Error("DraftPRAt not recorded")
+	}
+	var unpushed models.Car
+	db.First(&unpushed, "id = ?", "car-d2")
+	if unpushed.DraftPRAt != nil {
+		t.Error("DraftPRAt set for car with no pushed commits")
+	}
+}
+
+func TestSyncDraftPRs_SkipsDocsTracks(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-doc", Title: "Docs", Track: "handbook", Status: "in_progress", Branch: "ry/car-doc"})
+
+	fake := newFakeDraftPROps()
+	fake.withCommits["ry/car-doc"] = true
+	cfg := &config.Config{RequirePR: true, Tracks: []config.TrackConfig{{Name: "handbook", Type: config.TrackTypeDocs}}}
+
+	var buf bytes.Buffer
+	if err := syncDraftPRs(db, clock.Real, cfg, "/repo", fake.ops(), newDraftPRState(), testLogger(&buf)); err != nil {
+		t.Fatalf("syncDraftPRs: %v", err)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("created = %v, want no draft PR for a docs track", fake.created)
+	}
+}
+
+func TestSyncDraftPRs_UsesTrackDefaultBranch(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-d6", Title: "Pushed", Track: "backend", Status: "in_progress", Branch: "ry/car-d6"})
+	db.Create(&models.Car{ID: "car-d7", Title: "Pinned", Track: "backend", Status: "in_progress", Branch: "ry/car-d7", BaseBranch: "release"})
+
+	fake := newFakeDraftPROps()
+	cfg := &config.Config{RequirePR: true, Tracks: []config.TrackConfig{{Name: "backend", DefaultBranch: "develop"}}}
+
+	var buf bytes.Buffer
+	if err := syncDraftPRs(db, clock.Real, cfg, "/repo", fake.ops(), newDraftPRState(), testLogger(&buf)); err != nil {
+		t.Fatalf("syncDraftPRs: %v", err)
+	}
+	if got := fake.bases["ry/car-d6"]; got != "develop" {
+		t.Errorf("base for car without one = %q, want the track's %q", got, "develop")
+	}
+	if got := fake.bases["ry/car-d7"]; got != "release" {
+		t.Errorf("base for car with one = %q, want %q", got, "release")
+	}
+}
+
+func TestSyncDraftPRs_RefreshesOnlyWhenChanged(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+	db.Create(&models.Car{ID: "car-d4", Title: "Drafted", Track: "backend", Status: "in_progress", Branch: "ry/car-d4", DraftPRAt: &now})
+
+	fake := newFakeDraftPROps()
+	fake.prs["ry/car-d4"] = "https://github.com/org/repo/pull/4"
+	state := newDraftPRState()
+	var buf bytes.Buffer
+	sync := func() {
+		t.Helper()
+		if err := syncDraftPRs(db, clock.Real, &config.Config{RequirePR: true}, "/repo", fake.ops(), state, testLogger(&buf)); err != nil {
+			t.Fatalf("syncDraftPRs: %v", err)
+		}
+	}
+
+	sync()
+	sync() // nothing changed
+	if n := len(fake.updates["ry/car-d4"]); n != 1 {
+		t.Fatalf("updates = %d, want 1", n)
+	}
+
+	db.Create(&models.CarProgress{CarID: "car-d4", EngineID: "eng-1", Note: "added handler"})
+	fake.checks = prChecks{Passing: 2, Failing: 1}
+	sync()
+
+	bodies := fake.updates["ry/car-d4"]
+	if len(bodies) != 2 {
+		t.Fatalf("updates = %d, want 2", len(bodies))
+	}
+	last := bodies[1]
+	if !strings.Contains(last, "added handler") || !strings.Contains(last, "Checks: 2 passing, 1 failing, 0 pending") {
+		t.Errorf("refreshed body:\n%s", last)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("created = %v, want none for an already-drafted car", fake.created)
+	}
+}
+
+func TestSyncDraftPRs_AdoptsExistingPR(t *testing.T) {
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-d5", Title: "Has PR", Track: "backend", Status: "claimed", Branch: "ry/car-d5"})
+
+	fake := newFakeDraftPROps()
+	fake.withCommits["ry/car-d5"] = true
+	fake.prs["ry/car-d5"] = "https://github.com/org/repo/pull/5"
+
+	var buf bytes.Buffer
+	if err := syncDraftPRs(db, clock.Real, &config.Config{RequirePR: true}, "/repo", fake.ops(), newDraftPRState(), testLogger(&buf)); err != nil {
+		t.Fatalf("syncDraftPRs: %v", err)
+	}
+	if len(fake.created) != 0 {
+		t.Errorf("created = %v, want existing PR adopted", fake.created)
+	}
+	var c models.Car
+	db.First(&c, "id = ?", "car-d5")
+	if c.DraftPRAt == nil {
+		t.Error("DraftPRAt not recorded for adopted PR")
+	}
+}
+
+func TestParsePRChecks(t *testing.T) {
+	data := []byte(`{"statusCheckRollup":[
+		{"__typename":"CheckRun","status":"COMPLETED","conclusion":"SUCCESS"},
+		{"__typename":"CheckRun","status":"COMPLETED","conclusion":"FAILURE"},
+		{"__typename":"CheckRun","status":"IN_PROGRESS","conclusion":""},
+		{"__typename":"StatusContext","state":"SUCCESS"},
+		{"__typename":"StatusContext","state":"PENDING"}
+	]}`)
+	got, err := parsePRChecks(data)
+	if err != nil {
+		t.Fatalf("parsePRChecks: %v", err)
+	}
+	want := prChecks{Passing: 2, Failing: 1, Pending: 2}
+	if got != want {
+		t.Errorf("checks = %+v, want %+v", got, want)
+	}
+	if s := (prChecks{}).String(); s != "none reported yet" {
+		t.Errorf("empty checks = %q", s)
+	}
+}
+
+func TestAPIPRChecks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/repos/org/app/commits/ry%2Falice%2Fbackend%2Fcar-1/check-runs":
+			fmt.Fprint(w, `{"check_runs":[
+				{"status":"completed","conclusion":"success"},
+				{"status":"completed","conclusion":"failure"},
+				{"status":"in_progress","conclusion":null}
+			]}`)
+		case "/repos/org/app/commits/ry%2Falice%2Fbackend%2Fcar-1/status":
+			fmt.Fprint(w, `{"statuses":[{"state":"success"},{"state":"pending"}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.Repo = "git@github.com:org/app.git"
+	ops := draftPROpsFor(cfg, ghapi.New(ghapi.Options{BaseURL: srv.URL}))
+	got, err := ops.Checks("", "ry/alice/backend/car-1")
+	if err != nil {
+		t.Fatalf("Checks: %v", err)
+	}
+	if want := (prChecks{Passing: 2, Failing: 1, Pending: 2}); got != want {
+		t.Errorf("checks = %+v, want %+v", got, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/zulandar/railyard/internal/agentbackend"
 	"github.com/zulandar/railyard/internal/agentloop"
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/models"
@@ -26,6 +27,7 @@ type EscalationTracker struct {
 	mu       sync.Mutex
 	lastEsc  map[string]time.Time // car_id -> last escalation time
 	cooldown time.Duration
+	clock    clock.Clock
 }
 
 // NewEscalationTracker creates a tracker with the given cooldown duration.
@@ -33,9 +35,17 @@ func NewEscalationTracker(cooldown time.Duration) *EscalationTracker {
 	return &EscalationTracker{
 		lastEsc:  make(map[string]time.Time),
 		cooldown: cooldown,
+		clock:    clock.Real,
 	}
 }
 
+// SetClock replaces the clock used for cooldown checks.
+func (et *EscalationTracker) SetClock(c clock.Clock) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	et.clock = clock.OrReal(c)
+}
+
 // ShouldEscalate returns true if the car has not been escalated within the cooldown period.
 // If it returns true, it also records the current time as the last escalation time.
 func (et *EscalationTracker) ShouldEscalate(carID string) bool {
@@ -43,11 +53,11 @@ func (et *EscalationTracker) ShouldEscalate(carID string) bool {
 	defer et.mu.Unlock()
 
 	if last, ok := et.lastEsc[carID]; ok {
-		if time.Since(last) < et.cooldown {
+		if et.clock.Since(last) < et.cooldown {
 			return false
 		}
 	}
-	et.lastEsc[carID] = time.Now()
+	et.lastEsc[carID] = et.clock.Now()
 	return true
 }
 
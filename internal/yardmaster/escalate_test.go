@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/zulandar/railyard/internal/agentloop"
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 )
 
@@ -293,11 +294,13 @@ func TestEscalationTracker_CooldownBlocks(t *testing.T) {
 }
 
 func TestEscalationTracker_CooldownExpires(t *testing.T) {
-	et := NewEscalationTracker(10 * time.Millisecond)
+	fc := clock.NewFake(time.Now())
+	et := NewEscalationTracker(10 * time.Minute)
+	et.SetClock(fc)
 	if !et.ShouldEscalate("car-1") {
 		t.Fatal("first call should return true")
 	}
-	time.Sleep(20 * time.Millisecond)
+	fc.Advance(10 * time.Minute)
 	if !et.ShouldEscalate("car-1") {
 		t.Error("call after cooldown expiry should return true")
 	}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/events"
@@ -76,7 +77,7 @@ func TestHandleRetryMergeWithBus_PublishesAction(t *testing.T) {
 	logger := actTestLogger(&buf)
 
 	msg := models.Message{Subject: "retry-merge", CarID: "car-rm1", Body: "fixed"}
-	handleRetryMergeWithBus(db, msg, logger, bus)
+	handleRetryMergeWithBus(db, clock.Real, msg, logger, bus)
 
 	if !bus.hasYardmasterAction("car-rm1", "retry-merge") {
 		t.Fatalf("expected YardmasterAction with target=car-rm1 type=retry-merge; got %+v", bus.snapshot())
@@ -93,7 +94,7 @@ func TestHandleRetryMergeWithBus_EpicPublishesAction(t *testing.T) {
 	logger := actTestLogger(&buf)
 
 	msg := models.Message{Subject: "retry-merge", CarID: epicID, Body: "all children merged"}
-	handleRetryMergeWithBus(db, msg, logger, bus)
+	handleRetryMergeWithBus(db, clock.Real, msg, logger, bus)
 
 	if !bus.hasYardmasterAction(epicID, "retry-merge") {
 		t.Fatalf("expected YardmasterAction for epic; got %+v", bus.snapshot())
@@ -105,7 +106,7 @@ func TestHandleRetryMergeWithBus_NoCarID_NoPublish(t *testing.T) {
 	var buf bytes.Buffer
 	logger := actTestLogger(&buf)
 
-	handleRetryMergeWithBus(nil, models.Message{Subject: "retry-merge"}, logger, bus)
+	handleRetryMergeWithBus(nil, clock.Real, models.Message{Subject: "retry-merge"}, logger, bus)
 
 	if len(bus.snapshot()) != 0 {
 		t.Fatalf("expected no events for empty car-id; got %+v", bus.snapshot())
@@ -121,7 +122,7 @@ func TestHandleRequeueCarWithBus_PublishesAction(t *testing.T) {
 	logger := actTestLogger(&buf)
 
 	msg := models.Message{Subject: "requeue-car", CarID: "car-rq1", Body: "from scratch"}
-	handleRequeueCarWithBus(db, msg, logger, bus)
+	handleRequeueCarWithBus(db, clock.Real, msg, logger, bus)
 
 	if !bus.hasYardmasterAction("car-rq1", "requeue-car") {
 		t.Fatalf("expected requeue-car YardmasterAction; got %+v", bus.snapshot())
@@ -137,7 +138,7 @@ func TestHandleUnblockCarWithBus_PublishesAction(t *testing.T) {
 	logger := actTestLogger(&buf)
 
 	msg := models.Message{Subject: "unblock-car", CarID: "car-ub1", Body: "dep ready"}
-	handleUnblockCarWithBus(db, msg, logger, bus)
+	handleUnblockCarWithBus(db, clock.Real, msg, logger, bus)
 
 	if !bus.hasYardmasterAction("car-ub1", "unblock-car") {
 		t.Fatalf("expected unblock-car YardmasterAction; got %+v", bus.snapshot())
@@ -154,7 +155,7 @@ func TestHandleUnblockCarWithBus_NotBlocked_NoPublish(t *testing.T) {
 	var buf bytes.Buffer
 	logger := actTestLogger(&buf)
 
-	handleUnblockCarWithBus(db, models.Message{Subject: "unblock-car", CarID: "car-ub2"}, logger, bus)
+	handleUnblockCarWithBus(db, clock.Real, models.Message{Subject: "unblock-car", CarID: "car-ub2"}, logger, bus)
 
 	if len(bus.snapshot()) != 0 {
 		t.Fatalf("expected no publish when car is not blocked; got %+v", bus.snapshot())
@@ -170,7 +171,7 @@ func TestHandleCloseEpicWithBus_PublishesAction(t *testing.T) {
 	var buf bytes.Buffer
 	logger := actTestLogger(&buf)
 
-	handleCloseEpicWithBus(db, models.Message{Subject: "close-epic", CarID: epicID}, logger, bus)
+	handleCloseEpicWithBus(db, clock.Real, models.Message{Subject: "close-epic", CarID: epicID}, logger, bus)
 
 	if !bus.hasYardmasterAction(epicID, "close-epic") {
 		t.Fatalf("expected close-epic YardmasterAction; got %+v", bus.snapshot())
@@ -186,7 +187,7 @@ func TestHandleNudgeEngineWithBus_NoEngine_NoPublish(t *testing.T) {
 	var buf bytes.Buffer
 	logger := actTestLogger(&buf)
 
-	handleNudgeEngineWithBus(db, models.Message{Subject: "nudge-engine", CarID: "car-nu1", Body: "try X"}, logger, bus)
+	handleNudgeEngineWithBus(db, clock.Real, models.Message{Subject: "nudge-engine", CarID: "car-nu1", Body: "try X"}, logger, bus)
 
 	if len(bus.snapshot()) != 0 {
 		t.Fatalf("expected no publish when no engine assigned; got %+v", bus.snapshot())
@@ -202,7 +203,7 @@ func TestHandleNudgeEngineWithBus_PublishesAction(t *testing.T) {
 	var buf bytes.Buffer
 	logger := actTestLogger(&buf)
 
-	handleNudgeEngineWithBus(db, models.Message{Subject: "nudge-engine", CarID: "car-nu2", Body: "try X"}, logger, bus)
+	handleNudgeEngineWithBus(db, clock.Real, models.Message{Subject: "nudge-engine", CarID: "car-nu2", Body: "try X"}, logger, bus)
 
 	if !bus.hasYardmasterAction("car-nu2", "nudge-engine") {
 		t.Fatalf("expected nudge-engine YardmasterAction; got %+v", bus.snapshot())
@@ -333,9 +334,9 @@ func TestHandleCompletedCarsWithBus_PublishesMergeAction(t *testing.T) {
 	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
 
 	err := handleCompletedCarsWithBus(
-		context.Background(), db, cfg, "", "/nonexistent", "/nonexistent",
+		context.Background(), db, clock.Real, cfg, "", "/nonexistent", "/nonexistent",
 		&sync.WaitGroup{}, nil, make(chan struct{}, 1),
-		logger, bus,
+		logger, bus, nil,
 	)
 	// Switch will error on the fake repo path; that's expected.
 	_ = err
@@ -0,0 +1,302 @@
+package yardmaster
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/models"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// DefaultFailurePatternsFile is where ry merge relabel keeps learned
+// failure patterns when yardmaster.failure_patterns_file is unset.
+const DefaultFailurePatternsFile = ".railyard/failure-patterns.yaml"
+
+// switchOutputMarker separates a switch note's error from the tail of the
+// test output, which ry merge relabel learns patterns from.
+const switchOutputMarker = "\n--- test output ---\n"
+
+// switchNoteOutputBytes is how much of the end of the test output a
+// test-failed or infra-failed switch note keeps.
+const switchNoteOutputBytes = 4000
+
+// FailurePatterns are lowercase output substrings learned from operators
+// relabeling merge-gate failures. Infra patterns extend the built-in
+// infrastructure signatures; Test patterns mark output that the built-in
+// signatures wrongly call infrastructure. Runner and exit-code failures
+// (126, 127, 128) stay infrastructure regardless.
+type FailurePatterns struct {
+	Infra []string `yaml:"infra,omitempty"`
+	Test  []string `yaml:"test,omitempty"`
+}
+
+// LoadFailurePatterns reads a patterns file. A missing file has no
+// patterns.
+func LoadFailurePatterns(path string) (*FailurePatterns, error) {
+	p := &FailurePatterns{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failure patterns: %w", err)
+	}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failure patterns: parse %s: %w", path, err)
+	}
+	for i, pat := range p.Infra {
+		p.Infra[i] = strings.ToLower(pat)
+	}
+	for i, pat := range p.Test {
+		p.Test[i] = strings.ToLower(pat)
+	}
+	return p, nil
+}
+
+// Save writes the patterns to path, creating its directory.
+func (p *FailurePatterns) Save(path string) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failure patterns: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failure patterns: %w", err)
+	}
+	header := "# Learned by ry merge relabel. Lowercase substrings of test output.\n"
+	if err := os.WriteFile(path, append([]byte(header), data...), 0o644); err != nil {
+		return fmt.Errorf("failure patterns: %w", err)
+	}
+	return nil
+}
+
+// Add records pattern for category and drops it from the other list. It
+// reports whether anything changed.
+func (p *FailurePatterns) Add(category SwitchFailureCategory, pattern string) bool {
+	pattern = strings.ToLower(pattern)
+	list, other := &p.Test, &p.Infra
+	if category == SwitchFailInfra {
+		list, other = &p.Infra, &p.Test
+	}
+	changed := false
+	if i := slices.Index(*other, pattern); i >= 0 {
+		*other = slices.Delete(*other, i, i+1)
+		changed = true
+	}
+	if !slices.Contains(*list, pattern) {
+		*list = append(*list, pattern)
+		changed = true
+	}
+	return changed
+}
+
+// classify is classifyTestFailure with the learned patterns applied. A nil
+// receiver classifies with the built-in rules only.
+func (p *FailurePatterns) classify(err error, output string) SwitchFailureCategory {
+	category := classifyTestFailure(err, output)
+	if p == nil || isRunnerInfraError(err) {
+		return category
+	}
+	lower := strings.ToLower(output)
+	if category == SwitchFailInfra && containsAny(lower, p.Test) {
+		return SwitchFailTest
+	}
+	if category == SwitchFailTest && containsAny(lower, p.Infra) {
+		return SwitchFailInfra
+	}
+	return category
+}
+
+func containsAny(s string, patterns []string) bool {
+	for _, pat := range patterns {
+		if pat != "" && strings.Contains(s, pat) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSwitchFailurePatterns loads the patterns for a switch run. A file
+// that cannot be read is ignored so a bad edit never blocks the merge gate.
+func loadSwitchFailurePatterns(path string) *FailurePatterns {
+	if path == "" {
+		return nil
+	}
+	p, err := LoadFailurePatterns(path)
+	if err != nil {
+		slog.Warn("Switch: ignoring failure patterns", "path", path, "error", err)
+		return nil
+	}
+	return p
+}
+
+// switchFailureNote is the progress note for a failed switch. Test and
+// infrastructure failures carry the redacted end of the test output.
+func switchFailureNote(category SwitchFailureCategory, err error, conflictDetails, testOutput string) string {
+	note := fmt.Sprintf("switch:%s: %v", category, err)
+	if conflictDetails != "" {
+		note += "\n" + conflictDetails
+	}
+	if (category == SwitchFailTest || category == SwitchFailInfra) && strings.TrimSpace(testOutput) != "" {
+		note += switchOutputMarker + engine.RedactSecrets(truncateSwitchLog(testOutput, switchNoteOutputBytes))
+	}
+	return note
+}
+
+// RelabelOpts configures RelabelFailure.
+type RelabelOpts struct {
+	AttemptID    uint                  // ID of the attempt's "switch:" progress note
+	Label        SwitchFailureCategory // SwitchFailTest or SwitchFailInfra
+	Pattern      string                // output substring to learn; derived from the output when empty
+	PatternsFile string                // defaults to DefaultFailurePatternsFile
+	LabeledBy    string
+}
+
+// RelabelFailure records an operator's correction of a merge attempt's
+// failure category and adds a pattern from the attempt's output to the
+// patterns file, so later attempts with the same output are classified
+// the way the operator said. It does not change the car's status.
+func RelabelFailure(db *gorm.DB, opts RelabelOpts) (*models.FailureLabel, error) {
+	if opts.Label != SwitchFailTest && opts.Label != SwitchFailInfra {
+		return nil, fmt.Errorf("relabel: label must be %s or %s", SwitchFailTest, SwitchFailInfra)
+	}
+	var attempt models.CarProgress
+	if err := db.Where("id = ? AND engine_id = ? AND note LIKE ?", opts.AttemptID, YardmasterID, "switch:%").
+		First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("relabel: merge attempt %d not found", opts.AttemptID)
+		}
+		return nil, fmt.Errorf("relabel: %w", err)
+	}
+	original, _ := ParseSwitchNote(attempt.Note)
+	if original != SwitchFailTest && original != SwitchFailInfra {
+		return nil, fmt.Errorf("relabel: attempt %d failed with %s; only %s and %s failures can be relabeled",
+			opts.AttemptID, original, SwitchFailTest, SwitchFailInfra)
+	}
+	if original == opts.Label {
+		return nil, fmt.Errorf("relabel: attempt %d is already classified %s", opts.AttemptID, original)
+	}
+
+	output := switchNoteOutput(attempt.Note)
+	lower := strings.ToLower(output)
+	pattern := strings.ToLower(strings.TrimSpace(opts.Pattern))
+	if pattern == "" {
+		pattern = derivePattern(output, opts.Label)
+		if pattern == "" {
+			return nil, fmt.Errorf("relabel: no pattern found in attempt %d's output; pass one with --pattern", opts.AttemptID)
+		}
+	} else if !strings.Contains(lower, pattern) {
+		return nil, fmt.Errorf("relabel: pattern %q does not occur in attempt %d's output", pattern, opts.AttemptID)
+	}
+
+	path := opts.PatternsFile
+	if path == "" {
+		path = DefaultFailurePatternsFile
+	}
+	patterns, err := LoadFailurePatterns(path)
+	if err != nil {
+		return nil, fmt.Errorf("relabel: %w", err)
+	}
+	if patterns.Add(opts.Label, pattern) {
+		if err := patterns.Save(path); err != nil {
+			return nil, fmt.Errorf("relabel: %w", err)
+		}
+	}
+
+	label := models.FailureLabel{
+		ProgressID:       attempt.ID,
+		CarID:            attempt.CarID,
+		OriginalCategory: string(original),
+		Label:            string(opts.Label),
+		Pattern:          pattern,
+		Output:           output,
+		LabeledBy:        opts.LabeledBy,
+	}
+	var existing models.FailureLabel
+	if err := db.Where("progress_id = ?", attempt.ID).First(&existing).Error; err == nil {
+		label.ID = existing.ID
+		label.CreatedAt = existing.CreatedAt
+	}
+	if err := db.Save(&label).Error; err != nil {
+		return nil, fmt.Errorf("relabel: record label: %w", err)
+	}
+	return &label, nil
+}
+
+// ParseSwitchNote splits a "switch:<category>: <error>" note into its
+// category and first line of error text.
+func ParseSwitchNote(note string) (SwitchFailureCategory, string) {
+	rest, ok := strings.CutPrefix(note, "switch:")
+	if !ok {
+		return SwitchFailNone, ""
+	}
+	category, msg, _ := strings.Cut(rest, ": ")
+	return SwitchFailureCategory(category), firstLine(msg)
+}
+
+// switchNoteOutput returns the test output stored in a switch note, or the
+// note's error text for notes written before output was kept.
+func switchNoteOutput(note string) string {
+	if _, out, ok := strings.Cut(note, switchOutputMarker); ok {
+		return out
+	}
+	_, rest, _ := strings.Cut(note, ": ")
+	return rest
+}
+
+// errorWords pick the lines of test output worth learning an
+// infrastructure pattern from.
+var errorWords = []string{"error", "fatal", "cannot", "unable", "refused", "timed out", "timeout", "denied", "not found", "no such", "failed to", "missing"}
+
+// minDerivedPattern and maxDerivedPattern bound a derived pattern's length:
+// shorter ones match unrelated output, longer ones rarely recur verbatim.
+const (
+	minDerivedPattern = 12
+	maxDerivedPattern = 120
+)
+
+// derivePattern picks a pattern for output relabeled as label. For infra,
+// it is the first error-looking line; for test, the line holding the
+// built-in infrastructure signature that misfired. Either way the line is
+// reduced to its longest run without digits, so line numbers, ports, and
+// durations don't stop it matching the next time.
+func derivePattern(output string, label SwitchFailureCategory) string {
+	for _, line := range strings.Split(strings.ToLower(output), "\n") {
+		var hit bool
+		if label == SwitchFailInfra {
+			hit = containsAny(line, errorWords)
+		} else {
+			hit = containsAny(line, infraPatterns)
+		}
+		if !hit {
+			continue
+		}
+		if pat := stableSegment(line); len(pat) >= minDerivedPattern {
+			return pat
+		}
+	}
+	return ""
+}
+
+// stableSegment returns the longest digit-free run of line, trimmed of
+// spaces and punctuation and cut to maxDerivedPattern bytes.
+func stableSegment(line string) string {
+	best := ""
+	for _, seg := range strings.FieldsFunc(line, unicode.IsDigit) {
+		seg = strings.TrimFunc(seg, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsPunct(r) })
+		if len(seg) > len(best) {
+			best = seg
+		}
+	}
+	if len(best) > maxDerivedPattern {
+		best = strings.TrimSpace(best[:maxDerivedPattern])
+	}
+	return best
+}
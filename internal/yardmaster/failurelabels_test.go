@@ -0,0 +1,147 @@
+package yardmaster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestFailurePatterns_Classify(t *testing.T) {
+	learned := &FailurePatterns{
+		Infra: []string{"fixture server went away"},
+		Test:  []string{"sqlstate[23000]"},
+	}
+	tests := []struct {
+		name   string
+		err    error
+		output string
+		want   SwitchFailureCategory
+	}{
+		{"learned infra", errors.New("exit status 1"), "FAIL: fixture server went away after 3s", SwitchFailInfra},
+		{"learned test overrides built-in", errors.New("exit status 1"), "SQLSTATE[23000]: integrity constraint violation", SwitchFailTest},
+		{"unlearned output unchanged", errors.New("exit status 1"), "--- FAIL: TestRefund", SwitchFailTest},
+		{"runner errors stay infra", ErrTestRunnerUnavailable, "sqlstate[23000]", SwitchFailInfra},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := learned.classify(tt.err, tt.output); got != tt.want {
+				t.Errorf("classify = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	var none *FailurePatterns
+	if got := none.classify(errors.New("exit status 1"), "fixture server went away"); got != SwitchFailTest {
+		t.Errorf("nil patterns classify = %q, want built-in %q", got, SwitchFailTest)
+	}
+}
+
+func TestFailurePatterns_LoadAddSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "patterns.yaml")
+	p, err := LoadFailurePatterns(path)
+	if err != nil || len(p.Infra)+len(p.Test) != 0 {
+		t.Fatalf("missing file = %+v, %v; want no patterns", p, err)
+	}
+	if !p.Add(SwitchFailInfra, "Fixture Server Went Away") || p.Add(SwitchFailInfra, "fixture server went away") {
+		t.Error("Add should report only the first addition as a change")
+	}
+	if !p.Add(SwitchFailTest, "fixture server went away") || len(p.Infra) != 0 {
+		t.Errorf("patterns = %+v, want the pattern moved to test", p)
+	}
+	if err := p.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadFailurePatterns(path)
+	if err != nil || len(loaded.Test) != 1 || loaded.Test[0] != "fixture server went away" {
+		t.Errorf("reloaded = %+v, %v", loaded, err)
+	}
+
+	os.WriteFile(path, []byte("infra: [unclosed"), 0o644)
+	if loadSwitchFailurePatterns(path) != nil {
+		t.Error("a broken patterns file should be ignored")
+	}
+}
+
+func TestDerivePattern(t *testing.T) {
+	output := "ok  \tpkg/a\t0.2s\n" +
+		"--- FAIL: TestUpload (0.31s)\n" +
+		"    upload_test.go:42: dial tcp 10.0.3.7:9000: i/o timeout talking to minio\n"
+	if got := derivePattern(output, SwitchFailInfra); got != "i/o timeout talking to minio" {
+		t.Errorf("infra pattern = %q", got)
+	}
+	laravel := "PDOException: SQLSTATE[23000]: Integrity constraint violation: 1062 Duplicate entry\n"
+	if got := derivePattern(laravel, SwitchFailTest); got != "integrity constraint violation" {
+		t.Errorf("test pattern = %q", got)
+	}
+	if got := derivePattern("--- FAIL: TestX\n", SwitchFailTest); got != "" {
+		t.Errorf("pattern = %q, want none without a built-in signature", got)
+	}
+}
+
+func TestRelabelFailure(t *testing.T) {
+	db := testDB(t)
+	db.AutoMigrate(&models.FailureLabel{})
+	t.Chdir(t.TempDir())
+
+	output := "--- FAIL: TestUpload\n    upload_test.go:42: dial tcp 10.0.3.7:9000: i/o timeout talking to minio\n"
+	attempt := models.CarProgress{CarID: "car-1", EngineID: YardmasterID,
+		Note: switchFailureNote(SwitchFailTest, errors.New("tests failed"), "", output)}
+	db.Create(&attempt)
+	merge := models.CarProgress{CarID: "car-1", EngineID: YardmasterID, Note: "switch:merge-conflict: conflict"}
+	db.Create(&merge)
+
+	if _, err := RelabelFailure(db, RelabelOpts{AttemptID: merge.ID, Label: SwitchFailInfra}); err == nil {
+		t.Error("relabeling a merge conflict should fail")
+	}
+	if _, err := RelabelFailure(db, RelabelOpts{AttemptID: attempt.ID, Label: SwitchFailTest}); err == nil {
+		t.Error("relabeling to the same category should fail")
+	}
+	if _, err := RelabelFailure(db, RelabelOpts{AttemptID: attempt.ID, Label: SwitchFailInfra, Pattern: "not in output"}); err == nil {
+		t.Error("a pattern missing from the output should be rejected")
+	}
+
+	label, err := RelabelFailure(db, RelabelOpts{AttemptID: attempt.ID, Label: SwitchFailInfra, LabeledBy: "alice"})
+	if err != nil {
+		t.Fatalf("RelabelFailure: %v", err)
+	}
+	if label.OriginalCategory != string(SwitchFailTest) || label.Pattern != "i/o timeout talking to minio" ||
+		!strings.Contains(label.Output, "upload_test.go:42") {
+		t.Errorf("label = %+v", label)
+	}
+
+	patterns, err := LoadFailurePatterns(DefaultFailurePatternsFile)
+	if err != nil {
+		t.Fatalf("LoadFailurePatterns: %v", err)
+	}
+	if got := patterns.classify(errors.New("exit status 1"), "dial tcp 10.0.9.1:9000: i/o timeout talking to minio"); got != SwitchFailInfra {
+		t.Errorf("next attempt classified %q, want %q", got, SwitchFailInfra)
+	}
+
+	if _, err := RelabelFailure(db, RelabelOpts{AttemptID: attempt.ID, Label: SwitchFailInfra, Pattern: "talking to minio"}); err != nil {
+		t.Fatalf("second relabel: %v", err)
+	}
+	var count int64
+	db.Model(&models.FailureLabel{}).Count(&count)
+	if count != 1 {
+		t.Errorf("labels = %d, want the attempt's label updated in place", count)
+	}
+}
+
+func TestSwitchFailureNote(t *testing.T) {
+	note := switchFailureNote(SwitchFailTest, errors.New("tests failed"), "", strings.Repeat("x", 5000)+"tail")
+	if !strings.HasPrefix(note, "switch:test-failed: tests failed"+switchOutputMarker) || !strings.HasSuffix(note, "tail") {
+		t.Errorf("note = %.80q..., want the error then the output tail", note)
+	}
+	if len(note) > switchNoteOutputBytes+100 {
+		t.Errorf("note is %d bytes, want the output cut to %d", len(note), switchNoteOutputBytes)
+	}
+	if got := switchFailureNote(SwitchFailMerge, errors.New("conflict"), "a.go", "out"); got != "switch:merge-conflict: conflict\na.go" {
+		t.Errorf("merge note = %q, want no output", got)
+	}
+	if cat, msg := ParseSwitchNote(note); cat != SwitchFailTest || msg != "tests failed" {
+		t.Errorf("ParseSwitchNote = %q, %q", cat, msg)
+	}
+}
@@ -0,0 +1,255 @@
+package yardmaster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	ghapi "github.com/zulandar/railyard/internal/github"
+)
+
+// ForgeFunc performs one GitHub REST call: it sends body (if non-nil) as
+// JSON to path with method and decodes the response into v (if non-nil).
+// Paths use gh's {owner}/{repo} placeholders for the yard's repository.
+type ForgeFunc func(method, path string, body, v any) error
+
+const (
+	// gateCommentMarker starts the merge-gate comment so later runs update
+	// it instead of adding another.
+	gateCommentMarker = "<!-- railyard:merge-gate -->"
+
+	// gateStatusContext names the commit status the merge gate sets.
+	gateStatusContext = "railyard/merge-gate"
+
+	// gateSummaryLines is how much of the end of the test output the
+	// comment shows.
+	gateSummaryLines = 20
+)
+
+// gateReport is the merge-gate verdict on a car's branch.
+type gateReport struct {
+	CarID    string
+	Passed   bool
+	Category SwitchFailureCategory // empty when Passed
+	Reason   string                // why the gate failed
+	Skipped  bool                  // tests skipped (skip_tests on the car)
+	Checked  time.Time             // when the gate ran
+	Result   *SwitchResult
+}
+
+// newGateReport returns the gate verdict in result, or false when the gate
+// reached no verdict (e.g. the fetch failed before anything ran).
+func newGateReport(result *SwitchResult, checked time.Time) (gateReport, bool) {
+	r := gateReport{CarID: result.CarID, Result: result, Category: result.FailureCategory, Checked: checked}
+	switch result.FailureCategory {
+	case SwitchFailTest, SwitchFailPreTest, SwitchFailInfra, SwitchFailCoverage, SwitchFailDiffSize:
+		if result.Error != nil {
+			r.Reason = result.Error.Error()
+		}
+		return r, true
+	}
+	if !result.TestsPassed {
+		return r, false
+	}
+	r.Passed, r.Category = true, SwitchFailNone
+	r.Skipped = strings.HasPrefix(result.TestOutput, "tests skipped")
+	return r, true
+}
+
+// description is the one-line commit status description (GitHub allows 140
+// characters).
+func (r gateReport) description() string {
+	var d string
+	switch {
+	case r.Passed && r.Skipped:
+		d = "Tests skipped for this car"
+	case r.Passed:
+		d = "Tests passed"
+	default:
+		d = "Failed: " + string(r.Category)
+	}
+	if c := r.Result.Coverage; c != nil {
+		d += fmt.Sprintf("; coverage %.1f%%", c.Coverage)
+		if c.Baseline != nil {
+			d += fmt.Sprintf(" (%+.1f)", c.Delta)
+		}
+	}
+	if len(d) > 140 {
+		d = d[:137] + "..."
+	}
+	return d
+}
+
+// comment renders the PR comment body.
+func (r gateReport) comment() string {
+	var b strings.Builder
+	b.WriteString(gateCommentMarker + "\n")
+	if r.Passed {
+		b.WriteString("### ✅ Railyard merge gate passed\n\n")
+	} else {
+		fmt.Fprintf(&b, "### ❌ Railyard merge gate failed: %s\n\n", r.Category)
+	}
+	fmt.Fprintf(&b, "- Car: `%s`\n", r.CarID)
+	if r.Reason != "" {
+		fmt.Fprintf(&b, "- Reason: %s\n", firstLine(r.Reason))
+	}
+	if len(r.Result.FailedCells) > 0 {
+		fmt.Fprintf(&b, "- Failed matrix cells: %s\n", strings.Join(r.Result.FailedCells, ", "))
+	}
+	if c := r.Result.Coverage; c != nil {
+		if c.Baseline != nil {
+			fmt.Fprintf(&b, "- Coverage: %.1f%% (%+.1f points vs %.1f%% at the last merge)\n", c.Coverage, c.Delta, *c.Baseline)
+		} else {
+			fmt.Fprintf(&b, "- Coverage: %.1f%% (first measurement on this track)\n", c.Coverage)
+		}
+	}
+	fmt.Fprintf(&b, "- Checked: %s\n", r.Checked.UTC().Format("2006-01-02 15:04 UTC"))
+	if summary := tailLines(r.Result.TestOutput, gateSummaryLines); summary != "" {
+		b.WriteString("\n<details><summary>Test output (last lines)</summary>\n\n```\n")
+		b.WriteString(summary)
+		b.WriteString("\n```\n</details>\n")
+	}
+	return b.String()
+}
+
+// reportGate posts the merge-gate verdict for branch as a commit status on
+// the tested commit and as a comment on the branch's open PR, updating the
+// comment from an earlier run if there is one. It is best-effort: failures
+// are logged and never change the Switch outcome.
+func reportGate(opts SwitchOpts, branch string, result *SwitchResult) {
+	report, ok := newGateReport(result, clock.OrReal(opts.Clock).Now())
+	if !ok {
+		return
+	}
+	forge := opts.Forge
+	if forge == nil {
+		forge = ghCLIForge(opts.RepoDir)
+	}
+
+	if sha, err := gitOutput(opts.RepoDir, "rev-parse", "origin/"+branch); err == nil && sha != "" {
+		state := "success"
+		if !report.Passed {
+			state = "failure"
+		}
+		if err := forge(http.MethodPost, "repos/{owner}/{repo}/statuses/"+sha, map[string]string{
+			"state":       state,
+			"context":     gateStatusContext,
+			"description": report.description(),
+		}, nil); err != nil {
+			slog.Warn("Merge gate status failed", "car", result.CarID, "error", err)
+		}
+	}
+
+	if err := upsertGateComment(forge, branch, report.comment()); err != nil {
+		slog.Warn("Merge gate comment failed", "car", result.CarID, "error", err)
+	}
+}
+
+// upsertGateComment writes body to the gate comment on branch's open PR. A
+// branch without an open PR (tests failed before one was opened) gets no
+// comment.
+func upsertGateComment(forge ForgeFunc, branch, body string) error {
+	var prs []struct {
+		Number int `json:"number"`
+	}
+	if err := forge(http.MethodGet, "repos/{owner}/{repo}/pulls?state=open&head={owner}:"+url.QueryEscape(branch), nil, &prs); err != nil {
+		return fmt.Errorf("find PR: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil
+	}
+	number := prs[0].Number
+
+	var comments []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	if err := forge(http.MethodGet, fmt.Sprintf("repos/{owner}/{repo}/issues/%d/comments?per_page=100", number), nil, &comments); err != nil {
+		return fmt.Errorf("list PR #%d comments: %w", number, err)
+	}
+	payload := map[string]string{"body": body}
+	for _, c := range comments {
+		if strings.HasPrefix(c.Body, gateCommentMarker) {
+			return forge(http.MethodPatch, fmt.Sprintf("repos/{owner}/{repo}/issues/comments/%d", c.ID), payload, nil)
+		}
+	}
+	return forge(http.MethodPost, fmt.Sprintf("repos/{owner}/{repo}/issues/%d/comments", number), payload, nil)
+}
+
+// ghCLIForge runs REST calls through `gh api` in repoDir, which fills in the
+// {owner}/{repo} placeholders from the repository's remote.
+func ghCLIForge(repoDir string) ForgeFunc {
+	return func(method, path string, body, v any) error {
+		args := []string{"api", "-X", method, path}
+		var stdin []byte
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			stdin = data
+			args = append(args, "--input", "-")
+		}
+		cmd := exec.Command("gh", args...)
+		cmd.Dir = repoDir
+		cmd.Stdin = bytes.NewReader(stdin)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("gh api %s %s: %s: %w", method, path, strings.TrimSpace(stderr.String()), err)
+		}
+		if v == nil {
+			return nil
+		}
+		return json.Unmarshal(out, v)
+	}
+}
+
+// apiForge runs REST calls through the shared GitHub client for cfg.Repo,
+// or returns nil (use the gh CLI) when there is no client or the repo is
+// not on GitHub.
+func apiForge(cfg *config.Config, gh *ghapi.Client) ForgeFunc {
+	if gh == nil {
+		return nil
+	}
+	owner, name, err := config.ParseGitHubRepo(cfg.Repo)
+	if err != nil {
+		return nil
+	}
+	r := strings.NewReplacer("{owner}", url.PathEscape(owner), "{repo}", url.PathEscape(name))
+	return func(method, path string, body, v any) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		path = r.Replace(path)
+		if method == http.MethodGet {
+			return gh.GetJSON(ctx, path, v)
+		}
+		return gh.SendJSON(ctx, method, path, body, v)
+	}
+}
+
+// tailLines returns the last n lines of s, ignoring trailing newlines.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
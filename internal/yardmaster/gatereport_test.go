@@ -0,0 +1,134 @@
+package yardmaster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// fakeForge records REST calls and answers the PR and comment lookups.
+type fakeForge struct {
+	prs      string // JSON for the pulls lookup
+	comments string // JSON for the comments lookup
+	calls    []string
+	bodies   []map[string]string
+}
+
+func (f *fakeForge) do(method, path string, body, v any) error {
+	f.calls = append(f.calls, method+" "+path)
+	if b, ok := body.(map[string]string); ok {
+		f.bodies = append(f.bodies, b)
+	}
+	if v == nil {
+		return nil
+	}
+	switch {
+	case strings.Contains(path, "/pulls?"):
+		return json.Unmarshal([]byte(f.prs), v)
+	case strings.HasSuffix(path, "/comments?per_page=100"):
+		return json.Unmarshal([]byte(f.comments), v)
+	}
+	return fmt.Errorf("unexpected %s %s", method, path)
+}
+
+func TestNewGateReport(t *testing.T) {
+	cov := 0.0
+	tests := []struct {
+		name   string
+		result SwitchResult
+		ok     bool
+		passed bool
+		desc   string
+	}{
+		{"passed", SwitchResult{TestsPassed: true}, true, true, "Tests passed"},
+		{"skipped", SwitchResult{TestsPassed: true, TestOutput: "tests skipped (skip_tests=true on car)"}, true, true, "Tests skipped for this car"},
+		{"test failure", SwitchResult{FailureCategory: SwitchFailTest, Error: errors.New("tests failed: exit 1")}, true, false, "Failed: test-failed"},
+		{"coverage", SwitchResult{TestsPassed: true, FailureCategory: SwitchFailCoverage, Coverage: &models.CoverageRecord{Coverage: 70, Baseline: &cov, Delta: -2.5}}, true, false, "Failed: coverage-dropped; coverage 70.0% (-2.5)"},
+		{"fetch failure", SwitchResult{FailureCategory: SwitchFailFetch}, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := newGateReport(&tt.result, time.Now())
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if r.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v", r.Passed, tt.passed)
+			}
+			if got := r.description(); got != tt.desc {
+				t.Errorf("description = %q, want %q", got, tt.desc)
+			}
+		})
+	}
+}
+
+func TestReportGate(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-1")
+	run(repoDir, "git", "commit", "--allow-empty", "-m", "work")
+	run(repoDir, "git", "push", "origin", "ry/alice/backend/car-1")
+	sha, err := gitOutput(repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &SwitchResult{
+		CarID:           "car-1",
+		FailureCategory: SwitchFailTest,
+		FailedCells:     []string{"linux"},
+		TestOutput:      "--- FAIL: TestX\nFAIL\n",
+		Error:           errors.New("tests failed: exit status 1"),
+	}
+
+	t.Run("creates comment", func(t *testing.T) {
+		f := &fakeForge{prs: `[{"number":12}]`, comments: `[{"id":1,"body":"looks good"}]`}
+		clk := clock.NewFake(time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC))
+		reportGate(SwitchOpts{RepoDir: repoDir, Forge: f.do, Clock: clk}, "ry/alice/backend/car-1", result)
+
+		want := []string{
+			"POST repos/{owner}/{repo}/statuses/" + sha,
+			"GET repos/{owner}/{repo}/pulls?state=open&head={owner}:ry%2Falice%2Fbackend%2Fcar-1",
+			"GET repos/{owner}/{repo}/issues/12/comments?per_page=100",
+			"POST repos/{owner}/{repo}/issues/12/comments",
+		}
+		if strings.Join(f.calls, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("calls:\n%s\nwant:\n%s", strings.Join(f.calls, "\n"), strings.Join(want, "\n"))
+		}
+		if st := f.bodies[0]; st["state"] != "failure" || st["context"] != gateStatusContext {
+			t.Errorf("status = %v", st)
+		}
+		comment := f.bodies[1]["body"]
+		for _, s := range []string{gateCommentMarker, "merge gate failed: test-failed", "Failed matrix cells: linux", "Checked: 2026-03-04 15:30 UTC", "--- FAIL: TestX"} {
+			if !strings.Contains(comment, s) {
+				t.Errorf("comment missing %q:\n%s", s, comment)
+			}
+		}
+	})
+
+	t.Run("updates existing comment", func(t *testing.T) {
+		f := &fakeForge{prs: `[{"number":12}]`, comments: `[{"id":7,"body":"` + gateCommentMarker + `\nold"}]`}
+		reportGate(SwitchOpts{RepoDir: repoDir, Forge: f.do}, "ry/alice/backend/car-1", &SwitchResult{CarID: "car-1", TestsPassed: true})
+		last := f.calls[len(f.calls)-1]
+		if last != http.MethodPatch+" repos/{owner}/{repo}/issues/comments/7" {
+			t.Errorf("last call = %q, want PATCH of comment 7", last)
+		}
+	})
+
+	t.Run("no open PR", func(t *testing.T) {
+		f := &fakeForge{prs: `[]`}
+		reportGate(SwitchOpts{RepoDir: repoDir, Forge: f.do}, "ry/alice/backend/car-1", result)
+		if len(f.calls) != 2 {
+			t.Errorf("calls = %v, want status and PR lookup only", f.calls)
+		}
+	})
+}
@@ -52,8 +52,8 @@ func TestBuildPRBody_Golden_NoPlaywright(t *testing.T) {
 	c := goldenCar()
 	db.Create(&c)
 
-	// Empty configPath => playwright section is silently omitted.
-	body := buildPRBody(db, &c, "/nonexistent", "main", "")
+	// Nil config => playwright section is silently omitted.
+	body := buildPRBody(db, &c, "/nonexistent", "main", nil)
 	compareGolden(t, "pr_body_no_playwright.golden", body)
 }
 
@@ -72,8 +72,8 @@ tracks:
       spec_path: tests/pr-demos
       filename: "{car_id}.spec.ts"
 `
-	configPath := writeYAMLConfig(t, yaml)
-	body := buildPRBody(db, &c, "/nonexistent", "main", configPath)
+	cfg := loadYAMLConfig(t, yaml)
+	body := buildPRBody(db, &c, "/nonexistent", "main", cfg)
 	compareGolden(t, "pr_body_playwright_enabled.golden", body)
 }
 
@@ -93,7 +93,7 @@ tracks:
       enabled: false
       spec_path: tests/pr-demos
 `
-	configPath := writeYAMLConfig(t, yaml)
-	body := buildPRBody(db, &c, "/nonexistent", "main", configPath)
+	cfg := loadYAMLConfig(t, yaml)
+	body := buildPRBody(db, &c, "/nonexistent", "main", cfg)
 	compareGolden(t, "pr_body_no_playwright.golden", body)
 }
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/pluginhost"
@@ -41,6 +42,7 @@ var _ StatusProvider = (*pluginhost.Host)(nil)
 // HealthServer provides HTTP health check endpoints for k8s probes.
 type HealthServer struct {
 	pollInterval time.Duration
+	clock        clock.Clock
 	mu           sync.RWMutex
 	lastPoll     time.Time
 }
@@ -49,22 +51,32 @@ type HealthServer struct {
 func NewHealthServer(pollInterval time.Duration) *HealthServer {
 	return &HealthServer{
 		pollInterval: pollInterval,
-		lastPoll:     time.Now(),
+		clock:        clock.Real,
+		lastPoll:     clock.Real.Now(),
 	}
 }
 
+// SetClock replaces the clock used for readiness checks and resets the last
+// poll time to the new clock's current time.
+func (h *HealthServer) SetClock(c clock.Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock.OrReal(c)
+	h.lastPoll = h.clock.Now()
+}
+
 // RecordPoll records the time of the latest daemon poll.
 func (h *HealthServer) RecordPoll() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.lastPoll = time.Now()
+	h.lastPoll = h.clock.Now()
 }
 
 // IsReady returns true if the last poll was within 2x the poll interval.
 func (h *HealthServer) IsReady() bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	return time.Since(h.lastPoll) < 2*h.pollInterval
+	return h.clock.Since(h.lastPoll) < 2*h.pollInterval
 }
 
 // StartHealthServer starts an HTTP server with /healthz, /readyz, and
@@ -263,7 +275,7 @@ const DefaultStaleThreshold = 60 * time.Second
 
 // CheckEngineHealth returns engines where last_activity is older than threshold
 // and status is not "dead".
-func CheckEngineHealth(db *gorm.DB, threshold time.Duration) ([]models.Engine, error) {
+func CheckEngineHealth(db *gorm.DB, clk clock.Clock, threshold time.Duration) ([]models.Engine, error) {
 	if db == nil {
 		return nil, fmt.Errorf("yardmaster: db is required")
 	}
@@ -271,7 +283,7 @@ func CheckEngineHealth(db *gorm.DB, threshold time.Duration) ([]models.Engine, e
 		return nil, fmt.Errorf("yardmaster: threshold must be positive")
 	}
 
-	cutoff := time.Now().Add(-threshold)
+	cutoff := clk.Now().Add(-threshold)
 	var engines []models.Engine
 	if err := db.Where("last_activity < ? AND status != ?", cutoff, "dead").
 		Find(&engines).Error; err != nil {
@@ -282,7 +294,7 @@ func CheckEngineHealth(db *gorm.DB, threshold time.Duration) ([]models.Engine, e
 
 // StaleEngines is a convenience wrapper using the default 60s threshold.
 func StaleEngines(db *gorm.DB) ([]models.Engine, error) {
-	return CheckEngineHealth(db, DefaultStaleThreshold)
+	return CheckEngineHealth(db, clock.Real, DefaultStaleThreshold)
 }
 
 // ReassignCar releases a car from a stalled/dead engine so it can be reclaimed.
@@ -295,7 +307,7 @@ func StaleEngines(db *gorm.DB) ([]models.Engine, error) {
 // no note or broadcast is written, and ReassignCar returns (false, nil); the
 // engine is still marked dead either way, since the caller established its
 // staleness (railyard-h2v).
-func ReassignCar(db *gorm.DB, carID, fromEngineID, reason string) (bool, error) {
+func ReassignCar(db *gorm.DB, clk clock.Clock, carID, fromEngineID, reason string) (bool, error) {
 	if db == nil {
 		return false, fmt.Errorf("yardmaster: db is required")
 	}
@@ -340,7 +352,7 @@ func ReassignCar(db *gorm.DB, carID, fromEngineID, reason string) (bool, error)
 			EngineID:     fromEngineID,
 			Note:         note,
 			FilesChanged: "[]",
-			CreatedAt:    time.Now(),
+			CreatedAt:    clk.Now(),
 		}).Error; err != nil {
 			return fmt.Errorf("yardmaster: progress note for car %s: %w", carID, err)
 		}
@@ -10,12 +10,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
 )
 
 func TestCheckEngineHealth_NilDB(t *testing.T) {
-	_, err := CheckEngineHealth(nil, 60*time.Second)
+	_, err := CheckEngineHealth(nil, clock.Real, 60*time.Second)
 	if err == nil {
 		t.Fatal("expected error for nil db")
 	}
@@ -26,14 +27,14 @@ func TestCheckEngineHealth_NilDB(t *testing.T) {
 
 func TestCheckEngineHealth_ZeroThreshold(t *testing.T) {
 	// With nil db, db check happens first.
-	_, err := CheckEngineHealth(nil, 0)
+	_, err := CheckEngineHealth(nil, clock.Real, 0)
 	if err == nil {
 		t.Fatal("expected error")
 	}
 }
 
 func TestCheckEngineHealth_NegativeThreshold(t *testing.T) {
-	_, err := CheckEngineHealth(nil, -1*time.Second)
+	_, err := CheckEngineHealth(nil, clock.Real, -1*time.Second)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -54,7 +55,7 @@ func TestDefaultStaleThreshold(t *testing.T) {
 }
 
 func TestReassignCar_NilDB(t *testing.T) {
-	_, err := ReassignCar(nil, "car-001", "eng-001", "stalled")
+	_, err := ReassignCar(nil, clock.Real, "car-001", "eng-001", "stalled")
 	if err == nil {
 		t.Fatal("expected error for nil db")
 	}
@@ -65,7 +66,7 @@ func TestReassignCar_NilDB(t *testing.T) {
 
 func TestReassignCar_EmptyCarID(t *testing.T) {
 	// nil db check comes first, then carID check.
-	_, err := ReassignCar(nil, "", "eng-001", "stalled")
+	_, err := ReassignCar(nil, clock.Real, "", "eng-001", "stalled")
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -73,7 +74,7 @@ func TestReassignCar_EmptyCarID(t *testing.T) {
 
 func TestReassignCar_EmptyEngineID(t *testing.T) {
 	// nil db check comes first, then field checks.
-	_, err := ReassignCar(nil, "car-001", "", "stalled")
+	_, err := ReassignCar(nil, clock.Real, "car-001", "", "stalled")
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -102,7 +103,7 @@ func TestReassignCar_StillClaimed_Reopens(t *testing.T) {
 	db := testDB(t)
 	reassignFixture(t, db, "claimed", "eng-rg")
 
-	reassigned, err := ReassignCar(db, "car-rg", "eng-rg", "stale heartbeat")
+	reassigned, err := ReassignCar(db, clock.Real, "car-rg", "eng-rg", "stale heartbeat")
 	if err != nil {
 		t.Fatalf("ReassignCar: %v", err)
 	}
@@ -144,7 +145,7 @@ func TestReassignCar_CarAlreadyDone_NoOp(t *testing.T) {
 	// reassign: the completion must not be lost.
 	reassignFixture(t, db, "done", "eng-rg")
 
-	reassigned, err := ReassignCar(db, "car-rg", "eng-rg", "stale heartbeat")
+	reassigned, err := ReassignCar(db, clock.Real, "car-rg", "eng-rg", "stale heartbeat")
 	if err != nil {
 		t.Fatalf("ReassignCar: %v", err)
 	}
@@ -187,7 +188,7 @@ func TestReassignCar_ClaimedByOtherEngine_NoOp(t *testing.T) {
 	// Car was already reassigned to and claimed by a different engine.
 	reassignFixture(t, db, "claimed", "eng-other")
 
-	reassigned, err := ReassignCar(db, "car-rg", "eng-rg", "stale heartbeat")
+	reassigned, err := ReassignCar(db, clock.Real, "car-rg", "eng-rg", "stale heartbeat")
 	if err != nil {
 		t.Fatalf("ReassignCar: %v", err)
 	}
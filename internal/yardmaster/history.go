@@ -0,0 +1,245 @@
+package yardmaster
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// HistoryImportOpts configures ImportGitHistory.
+type HistoryImportOpts struct {
+	RepoDir      string         // main repository; a DefaultTrack with its own repo is mined in its clone instead
+	Config       *config.Config // tracks for inferring each car's track, and each track's repo and default_branch
+	BaseBranch   string         // branch the merges landed on (default: DefaultTrack's default_branch, else "main")
+	Since        time.Time      // oldest merge to import
+	DefaultTrack string         // track when inference fails; such merges are skipped when empty
+	IDFormat     car.IDFormat   // ID format for the new cars
+	DryRun       bool           // report what would be imported without creating cars
+}
+
+// HistoryMerge is one merge found on the base branch.
+type HistoryMerge struct {
+	Commit    string
+	PR        int // pull request number; 0 when the merge names none
+	Title     string
+	Branch    string // merged branch, when the merge message names it
+	Author    string
+	Track     string
+	Files     int
+	StartedAt *time.Time // first commit on the merged branch; nil for squash merges
+	MergedAt  time.Time
+	CarID     string // archived car created for it; empty on a dry run or skip
+	Reason    string // why it was skipped
+}
+
+// HistoryImportResult is the outcome of ImportGitHistory, oldest merge first.
+type HistoryImportResult struct {
+	Imported []HistoryMerge
+	Skipped  []HistoryMerge
+}
+
+var (
+	prMergeRe     = regexp.MustCompile(`^Merge pull request #(\d+) from (\S+)`)
+	branchMergeRe = regexp.MustCompile(`^Merge (?:remote-tracking )?branch '([^']+)'`)
+	squashPRRe    = regexp.MustCompile(`^(.*\S)\s+\(#(\d+)\)$`)
+)
+
+// ImportGitHistory turns merges made on the base branch before Railyard ran
+// into archived cars, so throughput and cycle-time reports have a baseline
+// to compare against. A merge commit (pull request or branch merge) or a
+// squash-merged pull request ("Title (#12)") becomes a merged car whose
+// claim time is the first commit on the merged branch and whose completion
+// time is the merge; squash merges have no claim time. Merges Railyard made
+// (with a Car-ID trailer) and merges imported before are skipped, so running
+// it again only adds newer history.
+func ImportGitHistory(db *gorm.DB, opts HistoryImportOpts) (*HistoryImportResult, error) {
+	if db == nil {
+		return nil, fmt.Errorf("yardmaster: db is required")
+	}
+	if opts.RepoDir == "" {
+		return nil, fmt.Errorf("yardmaster: repoDir is required")
+	}
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = carBaseBranch(opts.Config, &models.Car{Track: opts.DefaultTrack})
+	}
+	repoDir, err := trackRepoDir(opts.Config, opts.RepoDir, opts.DefaultTrack)
+	if err != nil {
+		return nil, fmt.Errorf("import history: %w", err)
+	}
+	tracks := mainRepoTracks(opts.Config)
+	if repoDir != opts.RepoDir {
+		// Everything in a track's own repo belongs to that track.
+		tracks = nil
+	}
+	ref := "origin/" + baseBranch
+	if _, err := gitOutput(repoDir, "rev-parse", "--verify", "--quiet", ref); err != nil {
+		ref = baseBranch
+	}
+
+	args := []string{"log", "--first-parent", "--reverse", "--format=%H%x1f%P%x1f%an%x1f%ct%x1f%s%x1f%b%x1e"}
+	if !opts.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%d", opts.Since.Unix()))
+	}
+	out, err := gitOutput(repoDir, append(args, ref)...)
+	if err != nil {
+		return nil, fmt.Errorf("import history: read %s log: %w", ref, err)
+	}
+
+	res := &HistoryImportResult{}
+	for _, record := range strings.Split(out, "\x1e") {
+		m, files, ok := parseHistoryCommit(repoDir, strings.TrimLeft(record, "\n"))
+		if !ok {
+			continue
+		}
+		if m.Reason == "" {
+			m.Reason = alreadyImported(db, m.Commit)
+		}
+		if m.Reason == "" {
+			m.Track, m.Reason = historyTrack(tracks, opts.DefaultTrack, files)
+		}
+		if m.Reason != "" {
+			res.Skipped = append(res.Skipped, m)
+			continue
+		}
+		if !opts.DryRun {
+			if m.CarID, err = archiveMerge(db, opts, baseBranch, m); err != nil {
+				return res, err
+			}
+		}
+		res.Imported = append(res.Imported, m)
+	}
+	return res, nil
+}
+
+// parseHistoryCommit reads one "git log" record and the files the commit
+// changed. ok is false for commits that are not merges at all (direct
+// pushes to the base branch).
+func parseHistoryCommit(repoDir, record string) (m HistoryMerge, files []string, ok bool) {
+	fields := strings.SplitN(record, "\x1f", 6)
+	if len(fields) != 6 || fields[0] == "" {
+		return m, nil, false
+	}
+	parents := strings.Fields(fields[1])
+	committed, _ := strconv.ParseInt(fields[3], 10, 64)
+	subject, body := fields[4], strings.TrimSpace(fields[5])
+	m = HistoryMerge{Commit: fields[0], Author: fields[2], Title: subject, MergedAt: time.Unix(committed, 0).UTC()}
+
+	switch {
+	case len(parents) >= 2:
+		if g := prMergeRe.FindStringSubmatch(subject); g != nil {
+			m.PR, _ = strconv.Atoi(g[1])
+			m.Branch = g[2]
+			if _, b, found := strings.Cut(g[2], "/"); found {
+				m.Branch = b
+			}
+		} else if g := branchMergeRe.FindStringSubmatch(subject); g != nil {
+			m.Branch = g[1]
+		}
+		if line, _, _ := strings.Cut(body, "\n"); line != "" && m.PR != 0 {
+			// GitHub puts the pull request title on the first body line.
+			m.Title = strings.TrimSpace(line)
+		}
+		if first, err := gitOutput(repoDir, "log", "--format=%at", parents[0]+".."+parents[1]); err == nil && first != "" {
+			lines := strings.Split(first, "\n")
+			if sec, err := strconv.ParseInt(lines[len(lines)-1], 10, 64); err == nil {
+				t := time.Unix(sec, 0).UTC()
+				m.StartedAt = &t
+			}
+		}
+	case len(parents) == 1:
+		// A squash merge keeps no trace of when the work started.
+		g := squashPRRe.FindStringSubmatch(subject)
+		if g == nil {
+			return m, nil, false
+		}
+		m.Title = g[1]
+		m.PR, _ = strconv.Atoi(g[2])
+	default:
+		return m, nil, false
+	}
+
+	if carIDTrailerRe.MatchString(body) {
+		m.Reason = "merged by Railyard"
+	}
+	if out, err := gitOutput(repoDir, "diff", "--name-only", parents[0], m.Commit); err == nil && out != "" {
+		files = strings.Split(out, "\n")
+		m.Files = len(files)
+	}
+	return m, files, true
+}
+
+// alreadyImported returns a skip reason when commit already has a car.
+func alreadyImported(db *gorm.DB, commit string) string {
+	var existing models.Car
+	err := db.Select("id").Where("imported_from = ?", commit).First(&existing).Error
+	switch {
+	case err == nil:
+		return "already imported as " + existing.ID
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ""
+	default:
+		return "check existing cars: " + err.Error()
+	}
+}
+
+// historyTrack infers a merge's track from the files it changed, falling
+// back to defaultTrack. It returns a skip reason when neither works.
+func historyTrack(tracks []config.TrackConfig, defaultTrack string, files []string) (track, reason string) {
+	track, err := inferTrack(tracks, files)
+	if err == nil {
+		return track, ""
+	}
+	if defaultTrack != "" {
+		return defaultTrack, ""
+	}
+	return "", strings.TrimPrefix(err.Error(), "adopt: ")
+}
+
+// archiveMerge creates the merged car for m and returns its ID.
+func archiveMerge(db *gorm.DB, opts HistoryImportOpts, baseBranch string, m HistoryMerge) (string, error) {
+	desc := fmt.Sprintf("Imported from merge %s on %s (%d files changed).", shortSHA(m.Commit), baseBranch, m.Files)
+	if m.PR != 0 {
+		desc = fmt.Sprintf("Imported from pull request #%d, merge %s on %s (%d files changed).", m.PR, shortSHA(m.Commit), baseBranch, m.Files)
+	}
+	c, err := car.Create(db, car.CreateOpts{
+		Title:       m.Title,
+		Description: desc,
+		Track:       m.Track,
+		BaseBranch:  baseBranch,
+		IDFormat:    opts.IDFormat,
+		RequestedBy: m.Author,
+		IgnorePause: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("import history: create car for %s: %w", shortSHA(m.Commit), err)
+	}
+
+	created := m.MergedAt
+	if m.StartedAt != nil {
+		created = *m.StartedAt
+	}
+	branch := m.Branch
+	if len(branch) > 128 {
+		branch = branch[:128]
+	}
+	if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+		"status":        "merged",
+		"branch":        branch,
+		"imported_from": m.Commit,
+		"created_at":    created,
+		"claimed_at":    m.StartedAt,
+		"completed_at":  m.MergedAt,
+	}).Error; err != nil {
+		return "", fmt.Errorf("import history: archive car %s: %w", c.ID, err)
+	}
+	return c.ID, nil
+}
@@ -0,0 +1,163 @@
+package yardmaster
+
+import (
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestImportGitHistory(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	at := func(date string) {
+		t.Setenv("GIT_AUTHOR_DATE", date)
+		t.Setenv("GIT_COMMITTER_DATE", date)
+	}
+
+	// A pull request merge: the branch started on the 1st, merged on the 3rd.
+	run("git", "checkout", "-b", "feature-login")
+	at("2026-03-01T10:00:00Z")
+	writeFile(t, repoDir, "internal/auth/login.go", "package auth")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "start login")
+	at("2026-03-02T10:00:00Z")
+	writeFile(t, repoDir, "internal/auth/timeout.go", "package auth")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "finish login")
+	run("git", "checkout", "main")
+	at("2026-03-03T10:00:00Z")
+	run("git", "merge", "--no-ff", "feature-login", "-m", "Merge pull request #12 from org/feature-login", "-m", "Add login timeout")
+
+	// A squash-merged pull request on the frontend track.
+	at("2026-03-04T10:00:00Z")
+	writeFile(t, repoDir, "web/app.ts", "export {}")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "Dark mode toggle (#13)")
+
+	// A direct push, which is not a merge.
+	writeFile(t, repoDir, "README.md", "hi")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "fix typo")
+
+	// A merge Railyard made, carrying its Car-ID trailer.
+	run("git", "checkout", "-b", "ry/backend/car-old")
+	writeFile(t, repoDir, "internal/auth/ry.go", "package auth")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "railyard work")
+	run("git", "checkout", "main")
+	at("2026-03-05T10:00:00Z")
+	run("git", "merge", "--no-ff", "ry/backend/car-old", "-m", "Merge ry/backend/car-old", "-m", "Car-ID: car-old")
+
+	db := testDB(t)
+	opts := HistoryImportOpts{
+		RepoDir:    repoDir,
+		BaseBranch: "main",
+		Config: &config.Config{Tracks: []config.TrackConfig{
+			{Name: "backend", FilePatterns: []string{"internal/**"}},
+			{Name: "frontend", FilePatterns: []string{"web/**"}},
+		}},
+	}
+
+	res, err := ImportGitHistory(db, opts)
+	if err != nil {
+		t.Fatalf("ImportGitHistory: %v", err)
+	}
+	if len(res.Imported) != 2 {
+		t.Fatalf("imported %d merges, want 2: %+v", len(res.Imported), res.Imported)
+	}
+	if len(res.Skipped) != 1 || res.Skipped[0].Reason != "merged by Railyard" {
+		t.Errorf("skipped = %+v, want the Railyard merge", res.Skipped)
+	}
+
+	pr := res.Imported[0]
+	if pr.PR != 12 || pr.Title != "Add login timeout" || pr.Branch != "feature-login" || pr.Track != "backend" || pr.Files != 2 {
+		t.Errorf("PR merge = %+v", pr)
+	}
+	var c models.Car
+	if err := db.First(&c, "id = ?", pr.CarID).Error; err != nil {
+		t.Fatalf("load car: %v", err)
+	}
+	if c.Status != "merged" || c.ImportedFrom != pr.Commit || c.Branch != "feature-login" {
+		t.Errorf("car = status %q imported_from %q branch %q", c.Status, c.ImportedFrom, c.Branch)
+	}
+	if c.ClaimedAt == nil || c.CompletedAt == nil || c.CompletedAt.Sub(*c.ClaimedAt).Hours() != 48 {
+		t.Errorf("cycle = claimed %v completed %v, want 48h", c.ClaimedAt, c.CompletedAt)
+	}
+
+	squash := res.Imported[1]
+	if squash.PR != 13 || squash.Title != "Dark mode toggle" || squash.Track != "frontend" || squash.StartedAt != nil {
+		t.Errorf("squash merge = %+v", squash)
+	}
+
+	// Running again imports nothing new.
+	again, err := ImportGitHistory(db, opts)
+	if err != nil {
+		t.Fatalf("second ImportGitHistory: %v", err)
+	}
+	if len(again.Imported) != 0 || len(again.Skipped) != 3 {
+		t.Errorf("second run imported %d, skipped %d; want 0 and 3", len(again.Imported), len(again.Skipped))
+	}
+	var n int64
+	db.Model(&models.Car{}).Count(&n)
+	if n != 2 {
+		t.Errorf("cars = %d, want 2", n)
+	}
+}
+
+func TestImportGitHistory_UnmatchedTrack(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	writeFile(t, repoDir, "docs/guide.md", "guide")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "Write guide (#4)")
+
+	db := testDB(t)
+	opts := HistoryImportOpts{
+		RepoDir: repoDir,
+		Config: &config.Config{Tracks: []config.TrackConfig{
+			{Name: "backend", FilePatterns: []string{"internal/**"}},
+			{Name: "frontend", FilePatterns: []string{"web/**"}},
+		}},
+		DryRun: true,
+	}
+	res, err := ImportGitHistory(db, opts)
+	if err != nil {
+		t.Fatalf("ImportGitHistory: %v", err)
+	}
+	if len(res.Imported) != 0 || len(res.Skipped) != 1 {
+		t.Fatalf("imported %d, skipped %d; want 0 and 1", len(res.Imported), len(res.Skipped))
+	}
+
+	opts.DefaultTrack = "backend"
+	res, err = ImportGitHistory(db, opts)
+	if err != nil {
+		t.Fatalf("ImportGitHistory: %v", err)
+	}
+	if len(res.Imported) != 1 || res.Imported[0].Track != "backend" || res.Imported[0].CarID != "" {
+		t.Errorf("imported = %+v, want one dry-run merge on backend", res.Imported)
+	}
+}
+
+func TestImportGitHistory_TrackDefaultBranch(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	run("git", "checkout", "-b", "develop")
+	writeFile(t, repoDir, "internal/api.go", "package api")
+	run("git", "add", ".")
+	run("git", "commit", "-m", "Add API (#7)")
+	run("git", "checkout", "main")
+
+	db := testDB(t)
+	res, err := ImportGitHistory(db, HistoryImportOpts{
+		RepoDir: repoDir,
+		Config: &config.Config{Tracks: []config.TrackConfig{
+			{Name: "backend", DefaultBranch: "develop", FilePatterns: []string{"internal/**"}},
+		}},
+		DefaultTrack: "backend",
+		DryRun:       true,
+	})
+	if err != nil {
+		t.Fatalf("ImportGitHistory: %v", err)
+	}
+	if len(res.Imported) != 1 || res.Imported[0].PR != 7 {
+		t.Errorf("imported = %+v, want the merge on the track's develop branch", res.Imported)
+	}
+}
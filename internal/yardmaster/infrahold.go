@@ -0,0 +1,93 @@
+package yardmaster
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// holdMergesForInfra holds the merge queue after carID's merge gate failed
+// for infrastructure reasons, recording the car for retry once the hold is
+// released. The first failure of a hold pages the operator channel; later
+// ones only join it.
+func holdMergesForInfra(db *gorm.DB, carID string, switchErr error, logger *slog.Logger) {
+	reason := "infrastructure test failure"
+	if switchErr != nil {
+		reason = truncateOutput(switchErr.Error(), 200)
+	}
+	hold, started, err := yard.HoldMerges(db, carID, reason)
+	if err != nil {
+		logger.Error("Hold merge queue after infra failure", "car", carID, "error", err)
+		return
+	}
+	if !started {
+		logger.Info("Car joined merge hold", "car", carID, "held_cars", len(hold.Cars))
+		return
+	}
+	logger.Warn("Merge queue held after infra failure", "car", carID, "reason", reason)
+	if _, err := messaging.Send(db, YardmasterID, "telegraph", "merge-queue-held",
+		fmt.Sprintf("Merge queue held: car %s failed the merge gate for infrastructure reasons, not code (%s). No cars will merge until the environment is fixed. Fix it, then run `!ry infra resolved` to resume merging and retry the affected cars.", carID, reason),
+		messaging.SendOpts{CarID: carID, Priority: "urgent"}); err != nil {
+		logger.Error("Page merge hold", "car", carID, "error", err)
+	}
+}
+
+// handleInfraResolvedWithBus handles the "infra-resolved" inbox message an
+// operator sends once the test environment is fixed: it releases the merge
+// hold and sends the cars held for infra failures back to done so their
+// merges are retried. These retries do not count toward maxMergeRetries —
+// the failures were not the cars' fault.
+func handleInfraResolvedWithBus(db *gorm.DB, clk clock.Clock, msg models.Message, logger *slog.Logger, bus events.Bus) {
+	hold, changed, err := yard.ReleaseMerges(db)
+	if err != nil {
+		logger.Error("Action infra-resolved: release merge hold", "error", err)
+		return
+	}
+	if !changed {
+		logger.Info("Action infra-resolved: merge queue was not held")
+		messaging.Send(db, YardmasterID, "telegraph", "infra-resolved",
+			"The merge queue was not held; nothing to resume.", messaging.SendOpts{})
+		return
+	}
+
+	var retried, skipped []string
+	for _, carID := range hold.Cars {
+		res := db.Model(&models.Car{}).Where("id = ? AND status = ?", carID, "merge-failed").
+			Updates(map[string]interface{}{"status": "done", "blocked_reason": ""})
+		if res.Error != nil {
+			logger.Error("Action infra-resolved: update car failed", "car", carID, "error", res.Error)
+			continue
+		}
+		if res.RowsAffected == 0 {
+			// Already retried or handled by hand while the queue was held.
+			skipped = append(skipped, carID)
+			continue
+		}
+		retried = append(retried, carID)
+		if err := writeProgressNote(db, clk, carID, "dispatch", fmt.Sprintf("Retry merge after infra resolved: %s", msg.Body)); err != nil {
+			logger.Error("Action infra-resolved: progress note failed", "error", err)
+		}
+		publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+			TargetID:   carID,
+			ActionType: "retry-merge",
+		})
+	}
+	logger.Info("Action infra-resolved: merge queue resumed", "retried", retried, "skipped", skipped, "reason", msg.Body)
+
+	body := "Merge queue resumed."
+	if len(retried) > 0 {
+		body += " Retrying " + strings.Join(retried, ", ") + "."
+	}
+	if len(skipped) > 0 {
+		body += " No longer merge-failed, left alone: " + strings.Join(skipped, ", ") + "."
+	}
+	messaging.Send(db, YardmasterID, "telegraph", "infra-resolved", body, messaging.SendOpts{})
+}
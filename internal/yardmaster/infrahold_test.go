@@ -0,0 +1,82 @@
+package yardmaster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yard"
+)
+
+func TestInfraFailureHoldsMergeQueue(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.RailyardConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&models.Car{ID: "car-a", Status: "done", Track: "backend"})
+	db.Create(&models.Car{ID: "car-b", Status: "done", Track: "backend"})
+	db.Create(&models.Car{ID: "car-c", Status: "done", Track: "backend"})
+	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
+
+	var buf bytes.Buffer
+	logger := testLogger(&buf)
+	var wg sync.WaitGroup
+	for _, id := range []string{"car-a", "car-b"} {
+		maybeSwitchEscalate(context.Background(), db, cfg, id, SwitchFailInfra, errors.New("docker: daemon not running"), "", &wg, nil, make(chan struct{}, 3), logger)
+	}
+	wg.Wait()
+
+	hold, err := yard.GetMergeHold(db)
+	if err != nil || !hold.Held || strings.Join(hold.Cars, ",") != "car-a,car-b" || !strings.Contains(hold.Reason, "daemon not running") {
+		t.Fatalf("hold = %+v, %v", hold, err)
+	}
+	// Only the first failure pages the operator channel.
+	var pages []models.Message
+	db.Where("to_agent = ? AND subject = ?", "telegraph", "merge-queue-held").Find(&pages)
+	if len(pages) != 1 || pages[0].CarID != "car-a" || pages[0].Priority != "urgent" || !strings.Contains(pages[0].Body, "!ry infra resolved") {
+		t.Fatalf("pages = %+v", pages)
+	}
+
+	// Held: the done car is not switched.
+	if err := handleCompletedCars(context.Background(), db, cfg, "", t.TempDir(), t.TempDir(), &wg, nil, make(chan struct{}, 3), logger); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Merge queue held") || strings.Contains(buf.String(), "Car completed, switching") {
+		t.Errorf("merge queue should be skipped while held, log:\n%s", buf.String())
+	}
+
+	// car-b was retried by hand while the queue was held.
+	db.Model(&models.Car{}).Where("id = ?", "car-b").Update("status", "done")
+	handleInfraResolvedWithBus(db, clock.Real, models.Message{Body: "Infra resolved from chat by @alice"}, logger, nil)
+
+	if hold, _ := yard.GetMergeHold(db); hold.Held {
+		t.Errorf("hold not released: %+v", hold)
+	}
+	var a models.Car
+	db.First(&a, "id = ?", "car-a")
+	if a.Status != "done" {
+		t.Errorf("car-a status = %q, want done", a.Status)
+	}
+	if n := countMergeRetries(db, "car-a"); n != 0 {
+		t.Errorf("infra retry counted toward the retry limit: %d", n)
+	}
+	var reply models.Message
+	db.Where("to_agent = ? AND subject = ?", "telegraph", "infra-resolved").First(&reply)
+	if !strings.Contains(reply.Body, "Retrying car-a.") || !strings.Contains(reply.Body, "left alone: car-b.") {
+		t.Errorf("reply = %q", reply.Body)
+	}
+
+	// A second report finds nothing held.
+	handleInfraResolvedWithBus(db, clock.Real, models.Message{}, logger, nil)
+	var replies int64
+	db.Model(&models.Message{}).Where("subject = ? AND body LIKE ?", "infra-resolved", "%not held%").Count(&replies)
+	if replies != 1 {
+		t.Errorf("not-held replies = %d, want 1", replies)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/db"
 	"github.com/zulandar/railyard/internal/models"
 	"gorm.io/gorm"
@@ -95,7 +96,7 @@ func setupTestDB(t *testing.T, dbName string) *gorm.DB {
 
 func TestIntegration_CheckEngineHealth_ZeroThreshold(t *testing.T) {
 	gormDB := setupTestDB(t, "railyard_ym_hval1")
-	_, err := CheckEngineHealth(gormDB, 0)
+	_, err := CheckEngineHealth(gormDB, clock.Real, 0)
 	if err == nil {
 		t.Fatal("expected error for zero threshold")
 	}
@@ -103,7 +104,7 @@ func TestIntegration_CheckEngineHealth_ZeroThreshold(t *testing.T) {
 
 func TestIntegration_ReassignCar_EmptyCarID(t *testing.T) {
 	gormDB := setupTestDB(t, "railyard_ym_hval2")
-	_, err := ReassignCar(gormDB, "", "eng-001", "stalled")
+	_, err := ReassignCar(gormDB, clock.Real, "", "eng-001", "stalled")
 	if err == nil {
 		t.Fatal("expected error for empty carID")
 	}
@@ -111,7 +112,7 @@ func TestIntegration_ReassignCar_EmptyCarID(t *testing.T) {
 
 func TestIntegration_ReassignCar_EmptyEngineID(t *testing.T) {
 	gormDB := setupTestDB(t, "railyard_ym_hval3")
-	_, err := ReassignCar(gormDB, "car-001", "", "stalled")
+	_, err := ReassignCar(gormDB, clock.Real, "car-001", "", "stalled")
 	if err == nil {
 		t.Fatal("expected error for empty engineID")
 	}
@@ -131,7 +132,7 @@ func TestIntegration_CheckEngineHealth_NoStaleEngines(t *testing.T) {
 		StartedAt:    time.Now(),
 	})
 
-	stale, err := CheckEngineHealth(gormDB, 60*time.Second)
+	stale, err := CheckEngineHealth(gormDB, clock.Real, 60*time.Second)
 	if err != nil {
 		t.Fatalf("CheckEngineHealth: %v", err)
 	}
@@ -152,7 +153,7 @@ func TestIntegration_CheckEngineHealth_StaleEngine(t *testing.T) {
 		StartedAt:    time.Now().Add(-5 * time.Minute),
 	})
 
-	stale, err := CheckEngineHealth(gormDB, 60*time.Second)
+	stale, err := CheckEngineHealth(gormDB, clock.Real, 60*time.Second)
 	if err != nil {
 		t.Fatalf("CheckEngineHealth: %v", err)
 	}
@@ -176,7 +177,7 @@ func TestIntegration_CheckEngineHealth_ExcludesDead(t *testing.T) {
 		StartedAt:    time.Now().Add(-10 * time.Minute),
 	})
 
-	stale, err := CheckEngineHealth(gormDB, 60*time.Second)
+	stale, err := CheckEngineHealth(gormDB, clock.Real, 60*time.Second)
 	if err != nil {
 		t.Fatalf("CheckEngineHealth: %v", err)
 	}
@@ -205,7 +206,7 @@ func TestIntegration_CheckEngineHealth_MixedEngines(t *testing.T) {
 		LastActivity: now.Add(-10 * time.Minute), StartedAt: now.Add(-20 * time.Minute),
 	})
 
-	stale, err := CheckEngineHealth(gormDB, 60*time.Second)
+	stale, err := CheckEngineHealth(gormDB, clock.Real, 60*time.Second)
 	if err != nil {
 		t.Fatalf("CheckEngineHealth: %v", err)
 	}
@@ -222,7 +223,7 @@ func TestIntegration_CheckEngineHealth_DBError(t *testing.T) {
 	sqlDB, _ := gormDB.DB()
 	sqlDB.Close()
 
-	_, err := CheckEngineHealth(gormDB, 60*time.Second)
+	_, err := CheckEngineHealth(gormDB, clock.Real, 60*time.Second)
 	if err == nil {
 		t.Fatal("expected error for closed DB")
 	}
@@ -243,7 +244,7 @@ func TestIntegration_ReassignCar(t *testing.T) {
 		Status: "in_progress", Assignee: "eng-001",
 	})
 
-	reassigned, err := ReassignCar(gormDB, "car-001", "eng-001", "heartbeat stale >60s")
+	reassigned, err := ReassignCar(gormDB, clock.Real, "car-001", "eng-001", "heartbeat stale >60s")
 	if err != nil {
 		t.Fatalf("ReassignCar: %v", err)
 	}
@@ -294,7 +295,7 @@ func TestIntegration_ReassignCar_NotFound(t *testing.T) {
 
 	// A car that does not exist (or is not actively held by the engine) is a
 	// guarded no-op, not an error (railyard-h2v).
-	reassigned, err := ReassignCar(gormDB, "car-nonexistent", "eng-001", "stalled")
+	reassigned, err := ReassignCar(gormDB, clock.Real, "car-nonexistent", "eng-001", "stalled")
 	if err != nil {
 		t.Fatalf("ReassignCar: %v", err)
 	}
@@ -308,7 +309,7 @@ func TestIntegration_ReassignCar_DBError(t *testing.T) {
 	sqlDB, _ := gormDB.DB()
 	sqlDB.Close()
 
-	_, err := ReassignCar(gormDB, "car-001", "eng-001", "stalled")
+	_, err := ReassignCar(gormDB, clock.Real, "car-001", "eng-001", "stalled")
 	if err == nil {
 		t.Fatal("expected error for closed DB")
 	}
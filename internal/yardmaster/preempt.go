@@ -0,0 +1,188 @@
+package yardmaster
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// preemptForUrgentCars makes room for urgent (P0) cars on tracks where every
+// engine is busy. For each waiting urgent car not already covered by an idle
+// engine or an outstanding preemption, the engine working the lowest-priority
+// car is sent a preempt instruction; the engine checkpoints and parks that
+// car back to ready and claims the urgent one. Disabled by
+// yardmaster.disable_preemption.
+func preemptForUrgentCars(db *gorm.DB, cfg *config.Config, logger *slog.Logger, bus events.Bus) error {
+	if cfg.Yardmaster.DisablePreemption {
+		return nil
+	}
+	for _, t := range cfg.Tracks {
+		if err := preemptTrack(db, t.Name, logger, bus); err != nil {
+			return fmt.Errorf("preempt track %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// preemptTrack applies preemptForUrgentCars to a single track.
+func preemptTrack(db *gorm.DB, track string, logger *slog.Logger, bus events.Bus) error {
+	urgent, err := readyUrgentCars(db, track)
+	if err != nil {
+		return fmt.Errorf("find urgent cars: %w", err)
+	}
+	if len(urgent) == 0 {
+		return nil
+	}
+
+	var live []models.Engine
+	if err := db.Where("track = ? AND status != ? AND id != ?", track, engine.StatusDead, YardmasterID).
+		Find(&live).Error; err != nil {
+		return fmt.Errorf("list engines: %w", err)
+	}
+	if len(live) == 0 {
+		return nil
+	}
+
+	// Outstanding preemptions: engines already told to make way, and the
+	// urgent cars they will claim.
+	liveIDs := make([]string, 0, len(live))
+	for _, e := range live {
+		liveIDs = append(liveIDs, e.ID)
+	}
+	var pending []models.Message
+	if err := db.Where("from_agent = ? AND subject = ? AND acknowledged = ? AND to_agent IN ?",
+		YardmasterID, "preempt", false, liveIDs).Find(&pending).Error; err != nil {
+		return fmt.Errorf("list pending preemptions: %w", err)
+	}
+	pendingEngines := make(map[string]bool, len(pending))
+	coveredCars := make(map[string]bool, len(pending))
+	for _, m := range pending {
+		pendingEngines[m.ToAgent] = true
+		coveredCars[m.Body] = true
+	}
+
+	// Idle engines will claim urgent cars on their own; only the remainder
+	// needs a preemption.
+	idle := 0
+	for _, e := range live {
+		if e.CurrentCar == "" {
+			idle++
+		}
+	}
+	var needed []models.Car
+	for _, c := range urgent {
+		if coveredCars[c.ID] {
+			continue
+		}
+		if idle > 0 {
+			idle--
+			continue
+		}
+		needed = append(needed, c)
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+
+	victims, err := preemptionCandidates(db, live, pendingEngines)
+	if err != nil {
+		return fmt.Errorf("find preemption candidates: %w", err)
+	}
+
+	for i, u := range needed {
+		if i >= len(victims) {
+			break
+		}
+		v := victims[i]
+		if _, err := messaging.Send(db, YardmasterID, v.engineID, "preempt", u.ID, messaging.SendOpts{
+			CarID:    v.car.ID,
+			Priority: "urgent",
+		}); err != nil {
+			return fmt.Errorf("send preempt to %s: %w", v.engineID, err)
+		}
+		logger.Info("Preempting low-priority work for urgent car",
+			"track", track,
+			"engine", v.engineID,
+			"car", v.car.ID,
+			"car_priority", v.car.Priority,
+			"urgent_car", u.ID,
+		)
+		publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+			TargetID:   v.car.ID,
+			ActionType: "preempt",
+		})
+	}
+	return nil
+}
+
+// preemptionCandidate is a busy engine and the car it is working.
+type preemptionCandidate struct {
+	engineID string
+	car      models.Car
+}
+
+// preemptionCandidates returns busy engines working non-urgent cars, ordered
+// by preference: lowest priority (highest number) first, then the most
+// recently claimed car so the least work is interrupted. Engines with an
+// outstanding preemption are skipped.
+func preemptionCandidates(db *gorm.DB, live []models.Engine, skip map[string]bool) ([]preemptionCandidate, error) {
+	byCar := make(map[string]string)
+	var carIDs []string
+	for _, e := range live {
+		if e.CurrentCar == "" || skip[e.ID] {
+			continue
+		}
+		byCar[e.CurrentCar] = e.ID
+		carIDs = append(carIDs, e.CurrentCar)
+	}
+	if len(carIDs) == 0 {
+		return nil, nil
+	}
+
+	var cars []models.Car
+	if err := db.Where("id IN ? AND priority > ? AND status IN ?",
+		carIDs, engine.UrgentPriority, []string{"claimed", "in_progress"}).Find(&cars).Error; err != nil {
+		return nil, err
+	}
+
+	candidates := make([]preemptionCandidate, 0, len(cars))
+	for _, c := range cars {
+		candidates = append(candidates, preemptionCandidate{engineID: byCar[c.ID], car: c})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].car, candidates[j].car
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.ClaimedAt == nil || b.ClaimedAt == nil {
+			return a.ClaimedAt != nil
+		}
+		return a.ClaimedAt.After(*b.ClaimedAt)
+	})
+	return candidates, nil
+}
+
+// readyUrgentCars returns ready urgent-priority cars on a track, oldest
+// first. Readiness mirrors countReadyWork.
+func readyUrgentCars(db *gorm.DB, track string) ([]models.Car, error) {
+	blockedSub := db.Table("car_deps").
+		Select("car_deps.car_id").
+		Joins("JOIN cars blocker ON car_deps.blocked_by = blocker.id").
+		Where("blocker.status NOT IN ?", models.ResolvedBlockerStatuses)
+
+	var cars []models.Car
+	err := db.Where("status = ? AND (assignee = ? OR assignee IS NULL) AND track = ? AND type != ? AND priority = ?",
+		"open", "", track, "epic", engine.UrgentPriority).
+		Where("id NOT IN (?)", blockedSub).
+		Order("created_at ASC, id ASC").
+		Find(&cars).Error
+	return cars, err
+}
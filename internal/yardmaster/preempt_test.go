@@ -0,0 +1,117 @@
+package yardmaster
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// seedPreemptTrack creates two busy backend engines working a P1 and a P3
+// car, plus a ready P0 car.
+func seedPreemptTrack(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	now := time.Now()
+	claimed := now.Add(-time.Hour)
+	for _, c := range []models.Car{
+		{ID: "car-p1", Title: "p1", Track: "backend", Status: "in_progress", Priority: 1, Assignee: "eng-a", ClaimedAt: &claimed},
+		{ID: "car-p3", Title: "p3", Track: "backend", Status: "in_progress", Priority: 3, Assignee: "eng-b", ClaimedAt: &claimed},
+		{ID: "car-urgent", Title: "urgent", Track: "backend", Status: "open", Priority: 1},
+	} {
+		if err := db.Create(&c).Error; err != nil {
+			t.Fatalf("create car: %v", err)
+		}
+	}
+	// Priority carries a column default; set P0 explicitly.
+	db.Model(&models.Car{}).Where("id = ?", "car-urgent").Update("priority", engine.UrgentPriority)
+	for _, e := range []models.Engine{
+		{ID: "eng-a", Track: "backend", Status: engine.StatusWorking, CurrentCar: "car-p1", LastActivity: now},
+		{ID: "eng-b", Track: "backend", Status: engine.StatusWorking, CurrentCar: "car-p3", LastActivity: now},
+	} {
+		if err := db.Create(&e).Error; err != nil {
+			t.Fatalf("create engine: %v", err)
+		}
+	}
+}
+
+func preemptMessages(t *testing.T, db *gorm.DB) []models.Message {
+	t.Helper()
+	var msgs []models.Message
+	if err := db.Where("subject = ?", "preempt").Find(&msgs).Error; err != nil {
+		t.Fatalf("list messages: %v", err)
+	}
+	return msgs
+}
+
+func TestPreemptForUrgentCars_PreemptsLowestPriority(t *testing.T) {
+	db := testDB(t)
+	seedPreemptTrack(t, db)
+
+	var buf bytes.Buffer
+	if err := preemptForUrgentCars(db, twoTrackConfig(), rbTestLogger(&buf), nil); err != nil {
+		t.Fatalf("preemptForUrgentCars: %v", err)
+	}
+
+	msgs := preemptMessages(t, db)
+	if len(msgs) != 1 {
+		t.Fatalf("got %d preempt messages, want 1", len(msgs))
+	}
+	m := msgs[0]
+	if m.ToAgent != "eng-b" || m.CarID != "car-p3" || m.Body != "car-urgent" {
+		t.Errorf("message = to %s car %s body %q, want eng-b/car-p3/car-urgent", m.ToAgent, m.CarID, m.Body)
+	}
+
+	// A second pass must not preempt again while the first is outstanding.
+	if err := preemptForUrgentCars(db, twoTrackConfig(), rbTestLogger(&buf), nil); err != nil {
+		t.Fatalf("second pass: %v", err)
+	}
+	if n := len(preemptMessages(t, db)); n != 1 {
+		t.Errorf("got %d preempt messages after second pass, want 1", n)
+	}
+}
+
+func TestPreemptForUrgentCars_IdleEngineClaimsInstead(t *testing.T) {
+	db := testDB(t)
+	seedPreemptTrack(t, db)
+	db.Create(&models.Engine{ID: "eng-c", Track: "backend", Status: engine.StatusIdle, LastActivity: time.Now()})
+
+	var buf bytes.Buffer
+	if err := preemptForUrgentCars(db, twoTrackConfig(), rbTestLogger(&buf), nil); err != nil {
+		t.Fatalf("preemptForUrgentCars: %v", err)
+	}
+	if n := len(preemptMessages(t, db)); n != 0 {
+		t.Errorf("got %d preempt messages, want 0 with an idle engine", n)
+	}
+}
+
+func TestPreemptForUrgentCars_NeverPreemptsUrgentWork(t *testing.T) {
+	db := testDB(t)
+	seedPreemptTrack(t, db)
+	db.Model(&models.Car{}).Where("id IN ?", []string{"car-p1", "car-p3"}).Update("priority", engine.UrgentPriority)
+
+	var buf bytes.Buffer
+	if err := preemptForUrgentCars(db, twoTrackConfig(), rbTestLogger(&buf), nil); err != nil {
+		t.Fatalf("preemptForUrgentCars: %v", err)
+	}
+	if n := len(preemptMessages(t, db)); n != 0 {
+		t.Errorf("got %d preempt messages, want 0 when all work is urgent", n)
+	}
+}
+
+func TestPreemptForUrgentCars_Disabled(t *testing.T) {
+	db := testDB(t)
+	seedPreemptTrack(t, db)
+	cfg := twoTrackConfig()
+	cfg.Yardmaster.DisablePreemption = true
+
+	var buf bytes.Buffer
+	if err := preemptForUrgentCars(db, cfg, rbTestLogger(&buf), nil); err != nil {
+		t.Fatalf("preemptForUrgentCars: %v", err)
+	}
+	if n := len(preemptMessages(t, db)); n != 0 {
+		t.Errorf("got %d preempt messages, want 0 when disabled", n)
+	}
+}
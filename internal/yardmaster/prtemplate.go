@@ -0,0 +1,191 @@
+package yardmaster
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// PRTemplateData is the data available to pr_template title and body
+// templates.
+type PRTemplateData struct {
+	Car          *models.Car
+	BaseBranch   string
+	Progress     []PRProgressNote
+	ChangedFiles []string // paths changed on the car branch relative to the base
+	DiffStat     string   // git diff --stat output, empty when unavailable
+	Acceptance   []string // acceptance criteria, one item per line
+	Checklist    []string // checklist items from the resolved pr_template
+	Playwright   string   // rendered Playwright Demo section, empty when disabled
+	Default      string   // the built-in PR body, for templates that extend it
+}
+
+// PRProgressNote is one progress note as seen by PR templates.
+type PRProgressNote struct {
+	Engine string // engine ID, or session ID when the engine is unknown
+	Note   string
+	At     time.Time
+}
+
+// PRContent is a rendered pull request title and body.
+type PRContent struct {
+	Title string
+	Body  string
+}
+
+// prTemplateFuncs are the helpers available to pr_template templates.
+var prTemplateFuncs = template.FuncMap{
+	"checklist": func(items []string) string {
+		var b strings.Builder
+		for _, item := range items {
+			fmt.Fprintf(&b, "- [ ] %s\n", item)
+		}
+		return b.String()
+	},
+	"bullets": func(items []string) string {
+		var b strings.Builder
+		for _, item := range items {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+		return b.String()
+	},
+	"join":  strings.Join,
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// RenderPR renders the PR title and body for a car using the pr_template
+// resolved for its track in cfg. Without a template (or with a nil cfg) the
+// title is the car title and the body is the built-in format. On a template
+// error the built-in content is returned along with the error, so callers can
+// still open the PR.
+func RenderPR(db *gorm.DB, car *models.Car, repoDir, baseBranch string, cfg *config.Config) (PRContent, error) {
+	content := PRContent{
+		Title: car.Title,
+		Body:  buildPRBody(db, car, repoDir, baseBranch, cfg),
+	}
+	if cfg == nil {
+		return content, nil
+	}
+	tmpl := cfg.ResolvePRTemplate(car.Track)
+	if tmpl.Title == "" && tmpl.Body == "" {
+		return content, nil
+	}
+
+	data := prTemplateData(db, car, repoDir, baseBranch, cfg, tmpl.Checklist)
+	data.Default = content.Body
+
+	rendered := content
+	if tmpl.Title != "" {
+		title, err := renderPRTemplate("title", tmpl.Title, data)
+		if err != nil {
+			return content, err
+		}
+		// PR titles are a single line; keep the first.
+		title, _, _ = strings.Cut(strings.TrimSpace(title), "\n")
+		if title != "" {
+			rendered.Title = title
+		}
+	}
+	if tmpl.Body != "" {
+		body, err := renderPRTemplate("body", tmpl.Body, data)
+		if err != nil {
+			return content, err
+		}
+		rendered.Body = body
+	}
+	return rendered, nil
+}
+
+// renderPR is RenderPR for the switch flow: template errors are logged and
+// the built-in content is used so a bad template never blocks a PR.
+func renderPR(db *gorm.DB, car *models.Car, repoDir, baseBranch string, cfg *config.Config) PRContent {
+	content, err := RenderPR(db, car, repoDir, baseBranch, cfg)
+	if err != nil {
+		slog.Warn("PR template failed; using built-in PR format", "car", car.ID, "error", err)
+	}
+	return content
+}
+
+func renderPRTemplate(name, text string, data PRTemplateData) (string, error) {
+	t, err := template.New(name).Funcs(prTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("pr_template.%s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("pr_template.%s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// prTemplateData gathers the car's progress notes and branch changes for
+// template rendering.
+func prTemplateData(db *gorm.DB, car *models.Car, repoDir, baseBranch string, cfg *config.Config, checklist []string) PRTemplateData {
+	data := PRTemplateData{
+		Car:          car,
+		BaseBranch:   baseBranch,
+		ChangedFiles: gitChangedFiles(repoDir, car.Branch, baseBranch),
+		DiffStat:     gitDiffStat(repoDir, car.Branch, baseBranch),
+		Acceptance:   splitChecklist(car.Acceptance),
+		Checklist:    checklist,
+		Playwright:   buildPlaywrightSection(car, cfg),
+	}
+	var progress []models.CarProgress
+	if db != nil {
+		db.Where("car_id = ?", car.ID).Order("created_at ASC").Find(&progress)
+	}
+	for _, p := range progress {
+		eng := p.EngineID
+		if eng == "" {
+			eng = p.SessionID
+		}
+		data.Progress = append(data.Progress, PRProgressNote{Engine: eng, Note: p.Note, At: p.CreatedAt})
+	}
+	return data
+}
+
+// splitChecklist splits free-form text into items, one per non-blank line,
+// dropping any leading list or checkbox marker.
+func splitChecklist(text string) []string {
+	var items []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		for _, marker := range []string{"- [ ]", "- [x]", "* [ ]", "* [x]", "-", "*"} {
+			if strings.HasPrefix(line, marker) {
+				line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+				break
+			}
+		}
+		if line != "" {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// gitChangedFiles lists the files changed between the base branch and the
+// given branch. Returns nil when git fails.
+func gitChangedFiles(repoDir, branch, baseBranch string) []string {
+	cmd := exec.Command("git", "diff", "--name-only", baseBranch+"..."+branch)
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
@@ -0,0 +1,155 @@
+package yardmaster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+const prTemplateYAML = `owner: alice
+repo: git@github.com:org/app.git
+pr_template:
+  title: "[{{.Car.Track}}] {{.Car.Title}} ({{.Car.ID}})"
+  body: |
+    {{.Car.Description}}
+
+    ## Checks
+    {{checklist .Acceptance}}{{checklist .Checklist}}
+    ## Notes
+    {{range .Progress}}- {{.Engine}}: {{.Note}}
+    {{end}}
+  checklist:
+    - Docs updated
+tracks:
+  - name: backend
+    language: go
+  - name: frontend
+    language: typescript
+    pr_template:
+      title: "feat: {{lower .Car.Title}}"
+`
+
+func TestRenderPR_NoConfigUsesBuiltIn(t *testing.T) {
+	db := testDB(t)
+	c := goldenCar()
+	db.Create(&c)
+
+	got, err := RenderPR(db, &c, "/nonexistent", "main", nil)
+	if err != nil {
+		t.Fatalf("RenderPR: %v", err)
+	}
+	if got.Title != c.Title {
+		t.Errorf("Title = %q, want car title", got.Title)
+	}
+	if got.Body != buildPRBody(db, &c, "/nonexistent", "main", nil) {
+		t.Error("Body differs from built-in PR body")
+	}
+}
+
+func TestRenderPR_Template(t *testing.T) {
+	db := testDB(t)
+	c := models.Car{
+		ID:          "car-tmpl1",
+		Title:       "Add login",
+		Track:       "backend",
+		Branch:      "ry/alice/backend/car-tmpl1",
+		Description: "Login flow.",
+		Acceptance:  "- Valid users log in\n- [ ] Bad passwords rejected",
+	}
+	db.Create(&c)
+	db.Create(&models.CarProgress{CarID: c.ID, EngineID: "eng-1", Note: "wired handler"})
+
+	got, err := RenderPR(db, &c, "/nonexistent", "main", loadYAMLConfig(t, prTemplateYAML))
+	if err != nil {
+		t.Fatalf("RenderPR: %v", err)
+	}
+	if got.Title != "[backend] Add login (car-tmpl1)" {
+		t.Errorf("Title = %q", got.Title)
+	}
+	for _, want := range []string{
+		"Login flow.",
+		"- [ ] Valid users log in\n- [ ] Bad passwords rejected\n- [ ] Docs updated\n",
+		"- eng-1: wired handler",
+	} {
+		if !strings.Contains(got.Body, want) {
+			t.Errorf("Body missing %q:\n%s", want, got.Body)
+		}
+	}
+}
+
+func TestRenderPR_TrackOverride(t *testing.T) {
+	db := testDB(t)
+	c := models.Car{ID: "car-tmpl2", Title: "Add Login Page", Track: "frontend", Description: "Page."}
+	db.Create(&c)
+
+	got, err := RenderPR(db, &c, "/nonexistent", "main", loadYAMLConfig(t, prTemplateYAML))
+	if err != nil {
+		t.Fatalf("RenderPR: %v", err)
+	}
+	if got.Title != "feat: add login page" {
+		t.Errorf("Title = %q, want track override", got.Title)
+	}
+	// The body template is inherited from the top level.
+	if !strings.HasPrefix(got.Body, "Page.\n") {
+		t.Errorf("Body = %q, want inherited template", got.Body)
+	}
+}
+
+func TestRenderPR_BadTemplateFallsBack(t *testing.T) {
+	db := testDB(t)
+	c := goldenCar()
+	db.Create(&c)
+
+	yaml := `owner: alice
+repo: git@github.com:org/app.git
+pr_template:
+  title: "{{.Car.Nope}}"
+tracks:
+  - name: frontend
+    language: typescript
+`
+	cfg := loadYAMLConfig(t, yaml)
+	got, err := RenderPR(db, &c, "/nonexistent", "main", cfg)
+	if err == nil || !strings.Contains(err.Error(), "pr_template.title") {
+		t.Fatalf("err = %v, want pr_template.title error", err)
+	}
+	if got.Title != c.Title || got.Body != buildPRBody(db, &c, "/nonexistent", "main", cfg) {
+		t.Errorf("got %+v, want built-in content on error", got)
+	}
+	if pr := renderPR(db, &c, "/nonexistent", "main", cfg); pr.Title != c.Title {
+		t.Errorf("renderPR title = %q, want fallback", pr.Title)
+	}
+}
+
+func TestRenderPR_DefaultBodyAvailable(t *testing.T) {
+	db := testDB(t)
+	c := goldenCar()
+	db.Create(&c)
+
+	yaml := `owner: alice
+repo: git@github.com:org/app.git
+pr_template:
+  body: "Reviewer: @bob\n\n{{.Default}}"
+tracks:
+  - name: frontend
+    language: typescript
+`
+	cfg := loadYAMLConfig(t, yaml)
+	got, err := RenderPR(db, &c, "/nonexistent", "main", cfg)
+	if err != nil {
+		t.Fatalf("RenderPR: %v", err)
+	}
+	want := "Reviewer: @bob\n\n" + buildPRBody(db, &c, "/nonexistent", "main", cfg)
+	if got.Body != want {
+		t.Errorf("Body = %q, want %q", got.Body, want)
+	}
+}
+
+func TestSplitChecklist(t *testing.T) {
+	got := splitChecklist("- one\n\n* two\n- [x] three\nfour  ")
+	want := []string{"one", "two", "three", "four"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitChecklist = %q, want %q", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/events"
@@ -25,7 +26,7 @@ const (
 type rebalanceState struct {
 	lastRebalanceAt time.Time
 	lastTrackMoveAt map[string]time.Time
-	tmux            orchestration.Tmux // nil → DefaultTmux
+	tmux            orchestration.Tmux // nil → orchestration.TmuxFor(cfg)
 }
 
 // trackMetrics holds per-track engine and work counts.
@@ -42,14 +43,14 @@ type trackMetrics struct {
 // them to tracks with a backlog. At most 1 engine is moved per deficit track
 // per cycle.
 func rebalanceEngines(db *gorm.DB, cfg *config.Config, configPath string, state *rebalanceState, logger *slog.Logger) error {
-	return rebalanceEnginesWithBus(db, cfg, configPath, state, logger, nil)
+	return rebalanceEnginesWithBus(db, clock.Real, cfg, configPath, state, logger, nil)
 }
 
 // rebalanceEnginesWithBus is the bus-aware variant of [rebalanceEngines]. When
 // bus is non-nil and an engine move succeeds, publishes a
 // [plugin.YardmasterAction] event with ActionType="rebalance".
-func rebalanceEnginesWithBus(db *gorm.DB, cfg *config.Config, configPath string, state *rebalanceState, logger *slog.Logger, bus events.Bus) error {
-	now := time.Now()
+func rebalanceEnginesWithBus(db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath string, state *rebalanceState, logger *slog.Logger, bus events.Bus) error {
+	now := clk.Now()
 
 	// Cooldown guard.
 	if now.Sub(state.lastRebalanceAt) < rebalanceCooldown {
@@ -140,7 +141,7 @@ func rebalanceEnginesWithBus(db *gorm.DB, cfg *config.Config, configPath string,
 			continue
 		}
 
-		err := rebalanceMove(db, cfg, configPath, donor, dt.name, state, logger)
+		err := rebalanceMove(db, clk, cfg, configPath, donor, dt.name, state, logger)
 		if err != nil {
 			logger.Error("Rebalance failed", "from", donor, "to", dt.name, "error", err)
 			continue
@@ -185,8 +186,8 @@ func rebalanceEnginesWithBus(db *gorm.DB, cfg *config.Config, configPath string,
 
 // rebalanceMove kills one idle engine on the donor track and scales up the
 // receiver track by 1.
-func rebalanceMove(db *gorm.DB, cfg *config.Config, configPath, donorTrack, receiverTrack string, state *rebalanceState, logger *slog.Logger) error {
-	dm := findIdleEngine(db, donorTrack)
+func rebalanceMove(db *gorm.DB, clk clock.Clock, cfg *config.Config, configPath, donorTrack, receiverTrack string, state *rebalanceState, logger *slog.Logger) error {
+	dm := findIdleEngine(db, clk, donorTrack)
 	if dm == nil {
 		return fmt.Errorf("no idle engine on donor track %s", donorTrack)
 	}
@@ -205,7 +206,7 @@ func rebalanceMove(db *gorm.DB, cfg *config.Config, configPath, donorTrack, rece
 
 	tmux := state.tmux
 	if tmux == nil {
-		tmux = orchestration.DefaultTmux
+		tmux = orchestration.TmuxFor(cfg)
 	}
 
 	_, err := orchestration.Scale(orchestration.ScaleOpts{
@@ -225,9 +226,9 @@ func rebalanceMove(db *gorm.DB, cfg *config.Config, configPath, donorTrack, rece
 
 // findIdleEngine returns the first engine on a track that is idle with no
 // current car and has been idle for at least idleThreshold.
-func findIdleEngine(db *gorm.DB, track string) *models.Engine {
+func findIdleEngine(db *gorm.DB, clk clock.Clock, track string) *models.Engine {
 	var eng models.Engine
-	cutoff := time.Now().Add(-idleThreshold)
+	cutoff := clk.Now().Add(-idleThreshold)
 	result := db.Where("track = ? AND status = ? AND current_car = ? AND last_activity <= ? AND id != ?",
 		track, engine.StatusIdle, "", cutoff, YardmasterID).
 		Order("last_activity ASC").
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/logutil"
@@ -325,7 +326,7 @@ func TestRebalanceMove_NoIdleEngine(t *testing.T) {
 	// No engines at all on backend.
 	var buf bytes.Buffer
 	logger := rbTestLogger(&buf)
-	err := rebalanceMove(db, cfg, "test.yaml", "backend", "frontend", state, logger)
+	err := rebalanceMove(db, clock.Real, cfg, "test.yaml", "backend", "frontend", state, logger)
 	if err == nil {
 		t.Fatal("expected error for no idle engine on donor")
 	}
@@ -349,7 +350,7 @@ func TestRebalanceMove_Success(t *testing.T) {
 
 	var buf bytes.Buffer
 	logger := rbTestLogger(&buf)
-	err := rebalanceMove(db, cfg, "test.yaml", "backend", "frontend", state, logger)
+	err := rebalanceMove(db, clock.Real, cfg, "test.yaml", "backend", "frontend", state, logger)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,320 @@
+package yardmaster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/db"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yard"
+	"gorm.io/gorm"
+)
+
+// RecoverOpts configures Recover.
+type RecoverOpts struct {
+	Config  *config.Config
+	RepoDir string             // repository holding .railyard/engines worktrees
+	Tmux    orchestration.Tmux // defaults to orchestration.TmuxFor(Config) if nil
+
+	// StaleAfter is how long an engine may go without a heartbeat before
+	// it counts as dead. Zero uses stall.stale_engine_threshold_sec, then
+	// DefaultStaleThreshold.
+	StaleAfter time.Duration
+
+	// DryRun reports what would be done without changing anything.
+	DryRun bool
+
+	// Clock is the time source for staleness checks; nil uses clock.Real.
+	Clock clock.Clock
+}
+
+// SavedWorktree is a dead engine's worktree whose uncommitted work was
+// committed to the branch it had checked out.
+type SavedWorktree struct {
+	Engine string
+	Branch string
+}
+
+// RecoverResult records what each recovery step did (or, for a dry run,
+// would do).
+type RecoverResult struct {
+	DryRun bool
+	Tables int // tables present out of db.AllModels()
+
+	KilledSessions []string // sessions whose pane had fallen back to a shell
+	LiveSessions   []string
+
+	DeadEngines []string // engine rows marked dead
+	LiveEngines []string // engines still heartbeating; left alone
+
+	RepairedWorktrees []string // `git worktree repair` output lines
+	SavedWorktrees    []SavedWorktree
+	RemovedWorktrees  []string // dead engines whose worktree was removed
+
+	RequeuedCars []string
+
+	// FollowUps are things Recover could not or would not fix itself.
+	FollowUps []string
+}
+
+// recoverShells are pane commands that mean the daemon in a session has
+// exited and left the session at a prompt.
+var recoverShells = map[string]bool{"bash": true, "zsh": true, "sh": true, "dash": true, "fish": true, "ksh": true, "tcsh": true}
+
+// paneCommander is implemented by multiplexers that can report a pane's
+// foreground process (orchestration.RealTmux).
+type paneCommander interface {
+	PaneCommand(session string) (string, error)
+}
+
+// Recover brings the yard back to a consistent state after a host crash.
+// In order, it:
+//
+//  1. verifies the database is reachable and fully migrated;
+//  2. kills Railyard sessions whose daemon has exited;
+//  3. marks engines without a recent heartbeat dead;
+//  4. repairs git worktree bookkeeping, commits uncommitted work in dead
+//     engines' worktrees to the branch they had checked out, and removes
+//     those worktrees so the next engine starts clean;
+//  5. requeues cars still claimed by engines that are no longer alive.
+//
+// Anything it cannot fix safely is listed in RecoverResult.FollowUps. An
+// unreachable database is returned as an error, since no other step can run.
+func Recover(gormDB *gorm.DB, opts RecoverOpts) (*RecoverResult, error) {
+	if gormDB == nil {
+		return nil, fmt.Errorf("yardmaster: db is required")
+	}
+	clk := clock.OrReal(opts.Clock)
+	res := &RecoverResult{DryRun: opts.DryRun}
+
+	// Step 1: database.
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("recover: database: %w", err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("recover: database unreachable: %w", err)
+	}
+	var missing []string
+	for _, m := range db.AllModels() {
+		if gormDB.Migrator().HasTable(m) {
+			res.Tables++
+			continue
+		}
+		stmt := &gorm.Statement{DB: gormDB}
+		if err := stmt.Parse(m); err == nil {
+			missing = append(missing, stmt.Schema.Table)
+		} else {
+			missing = append(missing, fmt.Sprintf("%T", m))
+		}
+	}
+	if len(missing) > 0 {
+		// The remaining steps query these tables; stop before they fail.
+		res.FollowUps = append(res.FollowUps, fmt.Sprintf("Database is missing tables (%s): run `ry db init` to migrate, then `ry recover` again", strings.Join(missing, ", ")))
+		return res, nil
+	}
+
+	// Step 2: sessions.
+	if opts.Config != nil {
+		if opts.Tmux == nil {
+			opts.Tmux = orchestration.TmuxFor(opts.Config)
+		}
+		recoverSessions(opts, res)
+	}
+
+	// Step 3: engine rows.
+	threshold := opts.StaleAfter
+	if threshold <= 0 && opts.Config != nil && opts.Config.Stall.StaleEngineThresholdSec > 0 {
+		threshold = time.Duration(opts.Config.Stall.StaleEngineThresholdSec) * time.Second
+	}
+	if threshold <= 0 {
+		threshold = DefaultStaleThreshold
+	}
+	live, err := recoverEngines(gormDB, clk, threshold, opts.DryRun, res)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: worktrees.
+	if opts.RepoDir != "" {
+		recoverWorktrees(opts.RepoDir, live, opts.DryRun, res)
+	}
+
+	// Step 5: cars held by engines that are gone.
+	if err := requeueOrphanedCars(gormDB, clk, live, opts.DryRun, res); err != nil {
+		return nil, err
+	}
+
+	if st, err := yard.GetPause(gormDB); err == nil && st.Paused {
+		res.FollowUps = append(res.FollowUps, fmt.Sprintf("Yard is %s: run `ry resume` once it is healthy", st))
+	}
+	if opts.Config != nil && len(res.LiveSessions) == 0 {
+		res.FollowUps = append(res.FollowUps, "No Railyard sessions are running: run `ry start` to relaunch the yard")
+	}
+	return res, nil
+}
+
+// recoverSessions kills the owner's sessions whose pane is back at a shell
+// prompt. Multiplexers that cannot report pane commands (zellij) are only
+// listed.
+func recoverSessions(opts RecoverOpts, res *RecoverResult) {
+	sessions, err := opts.Tmux.ListSessions(orchestration.SessionPrefix(opts.Config.Owner))
+	if err != nil {
+		res.FollowUps = append(res.FollowUps, fmt.Sprintf("Could not list sessions (%v): check for leftover railyard_%s_* sessions by hand", err, opts.Config.Owner))
+		return
+	}
+	pc, canInspect := opts.Tmux.(paneCommander)
+	for _, s := range sessions {
+		if canInspect {
+			if cmd, err := pc.PaneCommand(s); err == nil && recoverShells[cmd] {
+				if !opts.DryRun {
+					if err := opts.Tmux.KillSession(s); err != nil {
+						res.FollowUps = append(res.FollowUps, fmt.Sprintf("Kill dead session %s: %v", s, err))
+						res.LiveSessions = append(res.LiveSessions, s)
+						continue
+					}
+				}
+				res.KilledSessions = append(res.KilledSessions, s)
+				continue
+			}
+		}
+		res.LiveSessions = append(res.LiveSessions, s)
+	}
+}
+
+// recoverEngines marks engines with stale heartbeats dead and returns the
+// set of engines still alive.
+func recoverEngines(gormDB *gorm.DB, clk clock.Clock, threshold time.Duration, dryRun bool, res *RecoverResult) (map[string]bool, error) {
+	stale, err := CheckEngineHealth(gormDB, clk, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("recover: %w", err)
+	}
+	dead := make(map[string]bool, len(stale))
+	for _, eng := range stale {
+		dead[eng.ID] = true
+		res.DeadEngines = append(res.DeadEngines, eng.ID)
+	}
+	if !dryRun && len(stale) > 0 {
+		// Cars they held are released in requeueOrphanedCars, which needs
+		// the engine as the car's assignee, so only the engine row changes.
+		if err := gormDB.Model(&models.Engine{}).Where("id IN ?", res.DeadEngines).
+			Update("status", engine.StatusDead).Error; err != nil {
+			return nil, fmt.Errorf("recover: mark engines dead: %w", err)
+		}
+	}
+
+	var engines []models.Engine
+	if err := gormDB.Where("status != ?", engine.StatusDead).Find(&engines).Error; err != nil {
+		return nil, fmt.Errorf("recover: list engines: %w", err)
+	}
+	live := make(map[string]bool)
+	for _, eng := range engines {
+		if !dead[eng.ID] {
+			live[eng.ID] = true
+			res.LiveEngines = append(res.LiveEngines, eng.ID)
+		}
+	}
+	return live, nil
+}
+
+// recoverWorktrees repairs worktree bookkeeping and clears the worktrees of
+// engines that are not alive, committing any uncommitted work first.
+func recoverWorktrees(repoDir string, live map[string]bool, dryRun bool, res *RecoverResult) {
+	if !dryRun {
+		// repair re-links worktrees whose admin files and directories
+		// disagree; prune drops entries whose directory is gone.
+		if out, err := gitCombined(repoDir, "worktree", "repair"); err != nil {
+			res.FollowUps = append(res.FollowUps, fmt.Sprintf("git worktree repair failed: %s", out))
+		} else if out != "" {
+			res.RepairedWorktrees = strings.Split(out, "\n")
+		}
+		gitCombined(repoDir, "worktree", "prune") //nolint:errcheck
+	}
+
+	entries, err := os.ReadDir(filepath.Join(repoDir, ".railyard", "engines"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			res.FollowUps = append(res.FollowUps, fmt.Sprintf("Read engine worktrees: %v", err))
+		}
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), "eng-") || live[e.Name()] {
+			continue
+		}
+		wtDir := filepath.Join(repoDir, ".railyard", "engines", e.Name())
+		rel := filepath.Join(".railyard", "engines", e.Name())
+
+		status, err := gitOutput(wtDir, "status", "--porcelain")
+		if err != nil {
+			res.FollowUps = append(res.FollowUps, fmt.Sprintf("%s is not a usable git worktree: copy out anything worth keeping, then delete it", rel))
+			continue
+		}
+		if status != "" {
+			branch, _ := gitOutput(wtDir, "symbolic-ref", "--short", "-q", "HEAD")
+			if branch == "" {
+				res.FollowUps = append(res.FollowUps, fmt.Sprintf("%s has uncommitted changes on a detached HEAD: copy out anything worth keeping, then delete it", rel))
+				continue
+			}
+			if !dryRun {
+				if _, err := engine.AutoCommitIfDirty(wtDir, "railyard: save uncommitted work recovered after crash"); err != nil {
+					res.FollowUps = append(res.FollowUps, fmt.Sprintf("%s: commit uncommitted work on %s: %v", rel, branch, err))
+					continue
+				}
+			}
+			res.SavedWorktrees = append(res.SavedWorktrees, SavedWorktree{Engine: e.Name(), Branch: branch})
+		}
+		if !dryRun {
+			if err := engine.RemoveWorktree(repoDir, e.Name()); err != nil {
+				res.FollowUps = append(res.FollowUps, fmt.Sprintf("Remove %s: %v", rel, err))
+				continue
+			}
+		}
+		res.RemovedWorktrees = append(res.RemovedWorktrees, e.Name())
+	}
+}
+
+// requeueOrphanedCars reopens claimed and in-progress cars whose assignee is
+// not a live engine, so the next engine on the track picks them up.
+func requeueOrphanedCars(gormDB *gorm.DB, clk clock.Clock, live map[string]bool, dryRun bool, res *RecoverResult) error {
+	var cars []models.Car
+	if err := gormDB.Where("status IN ?", []string{"claimed", "in_progress"}).Order("id").Find(&cars).Error; err != nil {
+		return fmt.Errorf("recover: list claimed cars: %w", err)
+	}
+	for _, c := range cars {
+		if c.Assignee != "" && live[c.Assignee] {
+			continue
+		}
+		if dryRun {
+			res.RequeuedCars = append(res.RequeuedCars, c.ID)
+			continue
+		}
+		if c.Assignee == "" {
+			result := gormDB.Model(&models.Car{}).
+				Where("id = ? AND assignee = '' AND status IN ?", c.ID, []string{"claimed", "in_progress"}).
+				Update("status", "open")
+			if result.Error != nil {
+				return fmt.Errorf("recover: requeue car %s: %w", c.ID, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				res.RequeuedCars = append(res.RequeuedCars, c.ID)
+			}
+			continue
+		}
+		requeued, err := ReassignCar(gormDB, clk, c.ID, c.Assignee, "engine lost in host crash (ry recover)")
+		if err != nil {
+			return fmt.Errorf("recover: %w", err)
+		}
+		if requeued {
+			res.RequeuedCars = append(res.RequeuedCars, c.ID)
+		}
+	}
+	return nil
+}
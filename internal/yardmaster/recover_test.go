@@ -0,0 +1,149 @@
+package yardmaster
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/db"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recoverTmux reports a fixed pane command per session.
+type recoverTmux struct {
+	mockTmux
+	panes  map[string]string
+	killed []string
+}
+
+func (m *recoverTmux) ListSessions(prefix string) ([]string, error) {
+	var out []string
+	for s := range m.panes {
+		if strings.HasPrefix(s, prefix) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+func (m *recoverTmux) PaneCommand(session string) (string, error) { return m.panes[session], nil }
+func (m *recoverTmux) KillSession(name string) error {
+	m.killed = append(m.killed, name)
+	return nil
+}
+
+func recoverTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	sqlDB, _ := gormDB.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(gormDB); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return gormDB
+}
+
+func TestRecover(t *testing.T) {
+	gormDB := recoverTestDB(t)
+	repoDir, run := initTestRepo(t)
+
+	old := time.Now().Add(-10 * time.Minute)
+	gormDB.Create(&models.Engine{ID: "eng-dead1", Track: "backend", Status: "working", CurrentCar: "car-1", LastActivity: old})
+	gormDB.Create(&models.Engine{ID: "eng-dead2", Track: "backend", Status: "idle", LastActivity: old})
+	gormDB.Create(&models.Engine{ID: "eng-live", Track: "backend", Status: "working", CurrentCar: "car-2", LastActivity: time.Now()})
+	gormDB.Create(&models.Car{ID: "car-1", Track: "backend", Status: "in_progress", Assignee: "eng-dead1", Branch: "ry/car-1"})
+	gormDB.Create(&models.Car{ID: "car-2", Track: "backend", Status: "in_progress", Assignee: "eng-live", Branch: "ry/car-2"})
+	gormDB.Create(&models.Car{ID: "car-3", Track: "backend", Status: "claimed", Assignee: "eng-gone"})
+
+	// eng-dead1 crashed mid-car with uncommitted work; eng-dead2 was idle.
+	run("git", "worktree", "add", "-b", "ry/car-1", ".railyard/engines/eng-dead1")
+	run("git", "worktree", "add", "--detach", ".railyard/engines/eng-dead2")
+	run("git", "worktree", "add", "-b", "ry/car-2", ".railyard/engines/eng-live")
+	writeFile(t, repoDir, ".railyard/engines/eng-dead1/work.go", "package work")
+
+	tmux := &recoverTmux{panes: map[string]string{
+		"railyard_alice_yardmaster": "ry",
+		"railyard_alice_eng000":     "bash",
+		"railyard_bob_eng000":       "bash",
+	}}
+	opts := RecoverOpts{Config: &config.Config{Owner: "alice"}, RepoDir: repoDir, Tmux: tmux}
+
+	dry, err := Recover(gormDB, RecoverOpts{Config: opts.Config, RepoDir: repoDir, Tmux: tmux, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(tmux.killed) != 0 || !reflect.DeepEqual(dry.RequeuedCars, []string{"car-1", "car-3"}) {
+		t.Errorf("dry run killed %v, requeued %v", tmux.killed, dry.RequeuedCars)
+	}
+	var c models.Car
+	gormDB.First(&c, "id = ?", "car-1")
+	if c.Status != "in_progress" {
+		t.Fatalf("dry run changed car-1 to %s", c.Status)
+	}
+
+	res, err := Recover(gormDB, opts)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if !reflect.DeepEqual(tmux.killed, []string{"railyard_alice_eng000"}) || !reflect.DeepEqual(res.LiveSessions, []string{"railyard_alice_yardmaster"}) {
+		t.Errorf("killed %v, live %v", tmux.killed, res.LiveSessions)
+	}
+	if !reflect.DeepEqual(res.DeadEngines, []string{"eng-dead1", "eng-dead2"}) || !reflect.DeepEqual(res.LiveEngines, []string{"eng-live"}) {
+		t.Errorf("dead %v, live %v", res.DeadEngines, res.LiveEngines)
+	}
+	if !reflect.DeepEqual(res.SavedWorktrees, []SavedWorktree{{Engine: "eng-dead1", Branch: "ry/car-1"}}) ||
+		!reflect.DeepEqual(res.RemovedWorktrees, []string{"eng-dead1", "eng-dead2"}) {
+		t.Errorf("saved %v, removed %v", res.SavedWorktrees, res.RemovedWorktrees)
+	}
+	if !reflect.DeepEqual(res.RequeuedCars, []string{"car-1", "car-3"}) {
+		t.Errorf("requeued %v", res.RequeuedCars)
+	}
+	if len(res.FollowUps) != 0 {
+		t.Errorf("follow-ups = %v", res.FollowUps)
+	}
+
+	// The uncommitted work is on the car's branch for the next engine.
+	if out, err := gitOutput(repoDir, "show", "--stat", "--format=%s", "ry/car-1"); err != nil || !strings.Contains(out, "work.go") {
+		t.Errorf("ry/car-1 head = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".railyard/engines/eng-dead1")); !os.IsNotExist(err) {
+		t.Errorf("eng-dead1 worktree still present: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".railyard/engines/eng-live")); err != nil {
+		t.Errorf("live engine worktree removed: %v", err)
+	}
+	for id, want := range map[string]string{"car-1": "open", "car-2": "in_progress", "car-3": "open"} {
+		var got models.Car
+		gormDB.First(&got, "id = ?", id)
+		if got.Status != want || (want == "open" && got.Assignee != "") {
+			t.Errorf("%s = %s/%q, want %s", id, got.Status, got.Assignee, want)
+		}
+	}
+	var eng models.Engine
+	gormDB.First(&eng, "id = ?", "eng-dead1")
+	if eng.Status != "dead" {
+		t.Errorf("eng-dead1 status = %s", eng.Status)
+	}
+}
+
+func TestRecover_MissingTables(t *testing.T) {
+	gormDB := testDB(t)
+	res, err := Recover(gormDB, RecoverOpts{})
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if len(res.FollowUps) != 1 || !strings.Contains(res.FollowUps[0], "ry db init") {
+		t.Errorf("follow-ups = %v", res.FollowUps)
+	}
+}
@@ -0,0 +1,213 @@
+package yardmaster
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// RevertOpts configures RevertCar.
+type RevertOpts struct {
+	RepoDir      string         // main repository, with an origin remote; left untouched (the revert is built in a temporary worktree)
+	Config       *config.Config // optional; a car on a track with its own repo is reverted in that track's clone, and a car without a base branch uses its track's default_branch
+	BranchPrefix string         // branch prefix for the revert car, e.g. "ry/alice"
+	IDFormat     car.IDFormat   // ID format for the revert car
+	RequestedBy  string         // who asked for the revert
+	Reason       string         // optional; recorded on both cars
+	Clock        clock.Clock    // time source for timestamps; nil uses clock.Real
+}
+
+// RevertResult is the outcome of RevertCar.
+type RevertResult struct {
+	Original    *models.Car // the merged car being reverted
+	RevertCar   *models.Car // the new car carrying the revert
+	MergeCommit string      // the commit on the base branch that merged Original
+	Conflict    bool        // the revert did not apply cleanly; RevertCar is left open for an engine
+}
+
+// RevertCar reverts a merged car. It finds the commit that merged the car on
+// origin/<base>, creates a revert car linked to the original (RevertOf),
+// commits `git revert` of that merge on the revert car's branch, and pushes
+// it. The revert car is then marked done so the yardmaster runs it through
+// the normal merge gate (tests, then merge or PR); once it merges, the
+// original is marked "reverted". If the revert conflicts, the revert car is
+// left open with instructions for an engine to resolve it.
+func RevertCar(db *gorm.DB, carID string, opts RevertOpts) (*RevertResult, error) {
+	if db == nil {
+		return nil, fmt.Errorf("yardmaster: db is required")
+	}
+	if opts.RepoDir == "" {
+		return nil, fmt.Errorf("yardmaster: repoDir is required")
+	}
+	clk := clock.OrReal(opts.Clock)
+
+	var orig models.Car
+	if err := db.Where("id = ?", carID).First(&orig).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("revert: car %w: %s", car.ErrNotFound, carID)
+		}
+		return nil, fmt.Errorf("revert: get car %s: %w", carID, err)
+	}
+	if orig.Status != "merged" {
+		return nil, fmt.Errorf("revert: car %s is %s; only merged cars can be reverted: %w", carID, orig.Status, car.ErrInvalidTransition)
+	}
+	var existing models.Car
+	if err := db.Where("revert_of = ? AND status NOT IN ?", carID, []string{"cancelled"}).First(&existing).Error; err == nil {
+		return nil, fmt.Errorf("revert: car %s is already being reverted by %s (%s)", carID, existing.ID, existing.Status)
+	}
+
+	baseBranch := carBaseBranch(opts.Config, &orig)
+	repoDir, err := trackRepoDir(opts.Config, opts.RepoDir, orig.Track)
+	if err != nil {
+		return nil, fmt.Errorf("revert: %w", err)
+	}
+	if err := gitFetch(repoDir); err != nil {
+		return nil, fmt.Errorf("revert: %w", err)
+	}
+	merge, err := findCarMerge(repoDir, "origin/"+baseBranch, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedBy := opts.RequestedBy
+	if requestedBy == "" {
+		requestedBy = orig.Owner
+	}
+	desc := fmt.Sprintf("Reverts car %s (%s), merged to %s in %s.", orig.ID, orig.Title, baseBranch, merge.Commit)
+	if opts.Reason != "" {
+		desc += "\n\nReason: " + opts.Reason
+	}
+	rc, err := car.Create(db, car.CreateOpts{
+		Title:        fmt.Sprintf("Revert %s: %s", orig.ID, orig.Title),
+		Description:  desc,
+		Type:         "bug",
+		Priority:     0,
+		Track:        orig.Track,
+		BaseBranch:   orig.BaseBranch,
+		BranchPrefix: opts.BranchPrefix,
+		IDFormat:     opts.IDFormat,
+		RequestedBy:  requestedBy,
+		Owner:        orig.Owner,
+		RevertOf:     orig.ID,
+		Acceptance:   fmt.Sprintf("%s is reverted on %s and the merge gate passes.", shortSHA(merge.Commit), baseBranch),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("revert: create revert car: %w", err)
+	}
+	if _, err := car.Publish(db, rc.ID, false); err != nil {
+		return nil, fmt.Errorf("revert: publish revert car %s: %w", rc.ID, err)
+	}
+	result := &RevertResult{Original: &orig, RevertCar: rc, MergeCommit: merge.Commit}
+
+	conflict, err := commitRevert(repoDir, rc.Branch, "origin/"+baseBranch, merge.Commit)
+	if err != nil {
+		return result, err
+	}
+
+	if conflict {
+		result.Conflict = true
+		note := fmt.Sprintf("git revert of %s conflicts with %s. Resolve it on this branch: `git revert -m 1 %s` (drop `-m 1` if it is not a merge commit).",
+			shortSHA(merge.Commit), baseBranch, merge.Commit)
+		if err := db.Model(&models.Car{}).Where("id = ?", rc.ID).
+			Update("description", desc+"\n\n"+note).Error; err != nil {
+			return result, fmt.Errorf("revert: update revert car %s: %w", rc.ID, err)
+		}
+		writeProgressNote(db, clk, orig.ID, YardmasterID, fmt.Sprintf("Revert requested by %s: %s conflicts; left open for an engine", requestedBy, rc.ID))
+		return result, nil
+	}
+
+	// The revert commit is already on the branch, so skip the engine and hand
+	// the car straight to the merge gate.
+	now := clk.Now()
+	if err := db.Model(&models.Car{}).Where("id = ?", rc.ID).Updates(map[string]interface{}{
+		"status":       "done",
+		"completed_at": now,
+	}).Error; err != nil {
+		return result, fmt.Errorf("revert: mark revert car %s done: %w", rc.ID, err)
+	}
+	rc.Status = "done"
+	rc.CompletedAt = &now
+	writeProgressNote(db, clk, rc.ID, YardmasterID, fmt.Sprintf("Reverted %s on branch %s; queued for the merge gate", shortSHA(merge.Commit), rc.Branch))
+	writeProgressNote(db, clk, orig.ID, YardmasterID, fmt.Sprintf("Revert requested by %s: %s", requestedBy, rc.ID))
+	return result, nil
+}
+
+// findCarMerge returns the most recent first-parent commit on ref that merged
+// carID.
+func findCarMerge(repoDir, ref, carID string) (BisectMerge, error) {
+	out, err := gitOutput(repoDir, "log", "--first-parent", "--format=%H%x00%B%x1e", ref)
+	if err != nil {
+		return BisectMerge{}, fmt.Errorf("revert: list %s history: %w", ref, err)
+	}
+	for _, entry := range strings.Split(out, "\x1e") {
+		sha, msg, ok := strings.Cut(strings.TrimSpace(entry), "\x00")
+		if !ok {
+			continue
+		}
+		if m := parseMergeMessage(sha, msg); m.CarID == carID {
+			return m, nil
+		}
+	}
+	return BisectMerge{}, fmt.Errorf("revert: no merge of car %s found on %s", carID, ref)
+}
+
+// commitRevert creates branch at base in a temporary worktree, commits a
+// revert of sha, and pushes the branch. A conflicting revert is aborted and
+// the branch pushed at base, so an engine can redo it; conflict reports that.
+func commitRevert(repoDir, branch, base, sha string) (conflict bool, err error) {
+	wt, err := os.MkdirTemp("", "railyard-revert-")
+	if err != nil {
+		return false, fmt.Errorf("revert: temp dir: %w", err)
+	}
+	os.Remove(wt) // git worktree add creates it
+	if out, err := gitCombined(repoDir, "worktree", "add", "-b", branch, wt, base); err != nil {
+		return false, fmt.Errorf("revert: add worktree: %s: %w", out, err)
+	}
+	defer func() {
+		if out, err := gitCombined(repoDir, "worktree", "remove", "--force", wt); err != nil {
+			slog.Warn("revert: remove worktree", "dir", wt, "output", out, "error", err)
+		}
+	}()
+
+	args := []string{"revert", "--no-edit"}
+	if parents, _ := gitOutput(repoDir, "rev-list", "--parents", "-n", "1", sha); len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	if _, err := gitCombined(wt, append(args, sha)...); err != nil {
+		gitCombined(wt, "revert", "--abort")
+		conflict = true
+	}
+
+	if err := gitPushBranch(wt, branch); err != nil {
+		return conflict, fmt.Errorf("revert: %w", err)
+	}
+	return conflict, nil
+}
+
+// markRevertedOriginal marks the car a just-merged revert car reverted. It is
+// a no-op for ordinary cars.
+func markRevertedOriginal(db *gorm.DB, clk clock.Clock, c *models.Car) {
+	if c.RevertOf == "" {
+		return
+	}
+	res := db.Model(&models.Car{}).Where("id = ? AND status = ?", c.RevertOf, "merged").Update("status", "reverted")
+	if res.Error != nil {
+		slog.Error("mark car reverted", "car", c.RevertOf, "revert_car", c.ID, "error", res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		return
+	}
+	slog.Info("Car reverted", "car", c.RevertOf, "revert_car", c.ID)
+	if err := writeProgressNote(db, clk, c.RevertOf, YardmasterID, fmt.Sprintf("Reverted by %s", c.ID)); err != nil {
+		slog.Error("mark car reverted: progress note", "car", c.RevertOf, "error", err)
+	}
+}
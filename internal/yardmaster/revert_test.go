@@ -0,0 +1,162 @@
+package yardmaster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// mergeCarForRevert merges car-rv1 (adding feature-rv1.txt) into main through
+// Switch and returns the repo and db.
+func mergeCarForRevert(t *testing.T) (string, func(dir string, args ...string), *gorm.DB) {
+	t.Helper()
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-rv1")
+	writeFile(t, repoDir, "feature-rv1.txt", "revert me")
+	run(repoDir, "git", "add", "feature-rv1.txt")
+	run(repoDir, "git", "commit", "-m", "feature work")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{
+		ID:     "car-rv1",
+		Title:  "Risky feature",
+		Track:  "backend",
+		Branch: "ry/alice/backend/car-rv1",
+		Status: "done",
+		Owner:  "alice",
+	})
+	result, err := Switch(db, "car-rv1", SwitchOpts{RepoDir: repoDir, TestCommand: "true"})
+	if err != nil || !result.Merged {
+		t.Fatalf("Switch car-rv1: merged=%v err=%v", result != nil && result.Merged, err)
+	}
+	return repoDir, run, db
+}
+
+func TestRevertCar_RevertsThroughMergeGate(t *testing.T) {
+	repoDir, _, db := mergeCarForRevert(t)
+
+	result, err := RevertCar(db, "car-rv1", RevertOpts{RepoDir: repoDir, BranchPrefix: "ry/bob", RequestedBy: "bob", Reason: "breaks login"})
+	if err != nil {
+		t.Fatalf("RevertCar: %v", err)
+	}
+	if result.Conflict {
+		t.Fatal("unexpected conflict")
+	}
+	rc := result.RevertCar
+	if rc.RevertOf != "car-rv1" || rc.Owner != "alice" || rc.Track != "backend" || rc.Priority != 0 {
+		t.Errorf("revert car = %+v", rc)
+	}
+	var stored models.Car
+	db.First(&stored, "id = ?", rc.ID)
+	if stored.Status != "done" {
+		t.Fatalf("revert car status = %q, want done (queued for the merge gate)", stored.Status)
+	}
+	if !strings.Contains(stored.Description, "breaks login") {
+		t.Errorf("description = %q, want the reason", stored.Description)
+	}
+	if _, err := gitOutput(repoDir, "rev-parse", "--verify", "origin/"+rc.Branch); err != nil {
+		t.Errorf("revert branch not pushed: %v", err)
+	}
+
+	// A second revert of the same car is refused while the first is live.
+	if _, err := RevertCar(db, "car-rv1", RevertOpts{RepoDir: repoDir}); err == nil || !strings.Contains(err.Error(), "already being reverted") {
+		t.Errorf("second revert: err = %v", err)
+	}
+
+	switchResult, err := Switch(db, rc.ID, SwitchOpts{RepoDir: repoDir, TestCommand: "true"})
+	if err != nil || !switchResult.Merged {
+		t.Fatalf("Switch revert car: merged=%v err=%v", switchResult != nil && switchResult.Merged, err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "feature-rv1.txt")); !os.IsNotExist(err) {
+		t.Errorf("feature-rv1.txt still on main after revert merged (err=%v)", err)
+	}
+
+	var orig models.Car
+	db.First(&orig, "id = ?", "car-rv1")
+	if orig.Status != "reverted" {
+		t.Errorf("original status = %q, want reverted", orig.Status)
+	}
+	var notes []models.CarProgress
+	db.Where("car_id = ?", "car-rv1").Find(&notes)
+	if len(notes) == 0 || !strings.Contains(notes[len(notes)-1].Note, "Reverted by "+rc.ID) {
+		t.Errorf("original progress notes = %+v", notes)
+	}
+}
+
+func TestRevertCar_TrackWithOwnRepo(t *testing.T) {
+	trackRepo, _, db := mergeCarForRevert(t)
+	trackRemote, err := gitOutput(trackRepo, "remote", "get-url", "origin")
+	if err != nil {
+		t.Fatalf("track remote: %v", err)
+	}
+	mainRepo, _ := initTestRepo(t)
+	cfg := &config.Config{
+		Repo:   "org/app",
+		Tracks: []config.TrackConfig{{Name: "backend", Repo: trackRemote}},
+	}
+
+	// The clone has no user config of its own.
+	for _, kv := range [][2]string{{"GIT_AUTHOR_NAME", "Test"}, {"GIT_AUTHOR_EMAIL", "test@test.com"}, {"GIT_COMMITTER_NAME", "Test"}, {"GIT_COMMITTER_EMAIL", "test@test.com"}} {
+		t.Setenv(kv[0], kv[1])
+	}
+
+	result, err := RevertCar(db, "car-rv1", RevertOpts{RepoDir: mainRepo, Config: cfg, BranchPrefix: "ry/bob"})
+	if err != nil {
+		t.Fatalf("RevertCar: %v", err)
+	}
+	if result.Conflict {
+		t.Fatal("unexpected conflict")
+	}
+	clone := filepath.Join(mainRepo, ".railyard", "repos", "backend")
+	if _, err := gitOutput(clone, "rev-parse", "--verify", "origin/"+result.RevertCar.Branch); err != nil {
+		t.Errorf("revert branch not pushed from the track's clone: %v", err)
+	}
+}
+
+func TestRevertCar_ConflictLeavesCarOpen(t *testing.T) {
+	repoDir, run, db := mergeCarForRevert(t)
+	writeFile(t, repoDir, "feature-rv1.txt", "built on top")
+	run(repoDir, "git", "commit", "-am", "follow-up")
+	run(repoDir, "git", "push", "origin", "main")
+
+	result, err := RevertCar(db, "car-rv1", RevertOpts{RepoDir: repoDir, BranchPrefix: "ry/bob"})
+	if err != nil {
+		t.Fatalf("RevertCar: %v", err)
+	}
+	if !result.Conflict {
+		t.Fatal("expected conflict")
+	}
+	var stored models.Car
+	db.First(&stored, "id = ?", result.RevertCar.ID)
+	if stored.Status != "open" || !strings.Contains(stored.Description, "conflicts") {
+		t.Errorf("revert car = %s: %q, want open with conflict instructions", stored.Status, stored.Description)
+	}
+	if wts, _ := gitOutput(repoDir, "worktree", "list"); strings.Count(wts, "\n") != 0 {
+		t.Errorf("worktrees left behind:\n%s", wts)
+	}
+}
+
+func TestRevertCar_RequiresMergedCar(t *testing.T) {
+	repoDir, _, _ := initTestRepoWithRemote(t)
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-rv2", Title: "Open", Track: "backend", Status: "open"})
+	db.Create(&models.Car{ID: "car-rv3", Title: "Merged elsewhere", Track: "backend", Status: "merged"})
+
+	if _, err := RevertCar(db, "car-rv2", RevertOpts{RepoDir: repoDir}); !errors.Is(err, car.ErrInvalidTransition) || !strings.Contains(err.Error(), "only merged cars") {
+		t.Errorf("open car: err = %v", err)
+	}
+	if _, err := RevertCar(db, "car-rv3", RevertOpts{RepoDir: repoDir}); err == nil || !strings.Contains(err.Error(), "no merge of car car-rv3") {
+		t.Errorf("no merge commit: err = %v", err)
+	}
+	if _, err := RevertCar(db, "car-missing", RevertOpts{RepoDir: repoDir}); !errors.Is(err, car.ErrNotFound) {
+		t.Errorf("missing car: err = %v", err)
+	}
+}
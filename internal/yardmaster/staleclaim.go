@@ -0,0 +1,150 @@
+package yardmaster
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// releaseStaleClaims takes back cars that have sat in claimed status past
+// stall.stale_claim_sec with no sign of work since the claim: no progress
+// note and no commit on the car's branch on origin. Their engine is still
+// heartbeating (dead engines are handled by the stale-engine phase) but
+// never started the car, e.g. because its worktree reset failed. Each car
+// goes back to open for any engine, the engine's stale-release count and
+// reason are recorded for the health score, and Telegraph is told.
+func releaseStaleClaims(db *gorm.DB, clk clock.Clock, cfg *config.Config, repoDir string, logger *slog.Logger, bus events.Bus) error {
+	if cfg.Stall.StaleClaimSec < 0 {
+		return nil
+	}
+	threshold := time.Duration(cfg.Stall.StaleClaimSec) * time.Second
+	now := clk.Now()
+
+	var cars []models.Car
+	if err := db.Where("status = ? AND claimed_at IS NOT NULL AND claimed_at < ?", "claimed", now.Add(-threshold)).
+		Find(&cars).Error; err != nil {
+		return fmt.Errorf("yardmaster: list claimed cars: %w", err)
+	}
+
+	for _, c := range cars {
+		last := lastClaimActivity(db, repoDir, &c)
+		if now.Sub(last) < threshold {
+			continue
+		}
+		reason := fmt.Sprintf("claimed %s ago with no progress since %s", now.Sub(*c.ClaimedAt).Round(time.Minute), last.Format("15:04"))
+		released, err := releaseClaim(db, clk, c.ID, c.Assignee, reason)
+		if err != nil {
+			logger.Error("Release stale claim", "car", c.ID, "engine", c.Assignee, "error", err)
+			continue
+		}
+		if !released {
+			continue
+		}
+		logger.Warn("Released stale claim", "car", c.ID, "engine", c.Assignee, "reason", reason)
+		publish(bus, plugin.YardmasterAction, plugin.YardmasterActionEvent{
+			TargetID:   c.ID,
+			ActionType: "release-stale-claim",
+		})
+	}
+	return nil
+}
+
+// lastClaimActivity returns the latest sign of work on a claimed car: its
+// claim time, its newest progress note, or the newest commit on its branch
+// on origin, whichever is later.
+func lastClaimActivity(db *gorm.DB, repoDir string, c *models.Car) time.Time {
+	last := *c.ClaimedAt
+	var note models.CarProgress
+	if err := db.Where("car_id = ?", c.ID).Order("created_at DESC").Limit(1).Find(&note).Error; err == nil && note.CreatedAt.After(last) {
+		last = note.CreatedAt
+	}
+	if c.Branch != "" && repoDir != "" {
+		if t, ok := remoteBranchCommitTime(repoDir, c.Branch); ok && t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// remoteBranchCommitTime fetches branch from origin and returns its head
+// commit's committer time, or false if the branch is not on origin.
+func remoteBranchCommitTime(repoDir, branch string) (time.Time, bool) {
+	fetch := exec.Command("git", "fetch", "origin", "refs/heads/"+branch)
+	fetch.Dir = repoDir
+	if err := fetch.Run(); err != nil {
+		return time.Time{}, false
+	}
+	out, err := gitOutput(repoDir, "show", "-s", "--format=%ct", "FETCH_HEAD")
+	if err != nil {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+// releaseClaim returns a car still claimed by engineID to open, clears the
+// engine's current car, and records the release against the engine. Unlike
+// ReassignCar the engine is not marked dead: it is alive, it just never
+// got going on this car. Returns false if the car moved on first.
+func releaseClaim(db *gorm.DB, clk clock.Clock, carID, engineID, reason string) (bool, error) {
+	released := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Car{}).
+			Where("id = ? AND assignee = ? AND status = ?", carID, engineID, "claimed").
+			Updates(map[string]interface{}{
+				"status":     "open",
+				"assignee":   "",
+				"claimed_at": nil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("yardmaster: release car %s: %w", carID, result.Error)
+		}
+		if released = result.RowsAffected > 0; !released {
+			return nil
+		}
+
+		if err := tx.Model(&models.Engine{}).Where("id = ?", engineID).Updates(map[string]interface{}{
+			"stale_releases":      gorm.Expr("stale_releases + 1"),
+			"last_release_reason": fmt.Sprintf("%s: %s", carID, reason),
+		}).Error; err != nil {
+			return fmt.Errorf("yardmaster: record release on engine %s: %w", engineID, err)
+		}
+		if err := tx.Model(&models.Engine{}).Where("id = ? AND current_car = ?", engineID, carID).
+			Update("current_car", "").Error; err != nil {
+			return fmt.Errorf("yardmaster: clear engine %s car: %w", engineID, err)
+		}
+
+		if err := tx.Create(&models.CarProgress{
+			CarID:        carID,
+			EngineID:     engineID,
+			Note:         fmt.Sprintf("Released from engine %s: %s", engineID, reason),
+			FilesChanged: "[]",
+			CreatedAt:    clk.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("yardmaster: progress note for car %s: %w", carID, err)
+		}
+
+		if _, err := messaging.Send(tx, "yardmaster", "telegraph", "stale-claim",
+			fmt.Sprintf("Car %s released back to ready: engine %s %s.", carID, engineID, reason),
+			messaging.SendOpts{CarID: carID},
+		); err != nil {
+			return fmt.Errorf("yardmaster: notify release of car %s: %w", carID, err)
+		}
+		return nil
+	})
+	return released, err
+}
@@ -0,0 +1,70 @@
+package yardmaster
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/models/testfactory"
+)
+
+func TestReleaseStaleClaims(t *testing.T) {
+	db := testDB(t)
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-push")
+	run(repoDir, "git", "commit", "--allow-empty", "-m", "started")
+	run(repoDir, "git", "push", "origin", "ry/alice/backend/car-push")
+	run(repoDir, "git", "checkout", "main")
+
+	now := time.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	testfactory.Engine().WithID("eng-1").Working("car-stale").Create(t, db)
+	testfactory.Car().WithID("car-stale").ClaimedBy("eng-1", ago(30*time.Minute)).Create(t, db)
+	testfactory.Car().WithID("car-fresh").ClaimedBy("eng-1", ago(5*time.Minute)).Create(t, db)
+	testfactory.Car().WithID("car-noted").ClaimedBy("eng-1", ago(30*time.Minute)).Create(t, db)
+	testfactory.Car().WithID("car-push").ClaimedBy("eng-1", ago(30*time.Minute)).WithBranch("ry/alice/backend/car-push").Create(t, db)
+	testfactory.Car().WithID("car-working").ClaimedBy("eng-1", ago(time.Hour)).WithStatus("in_progress").Create(t, db)
+	db.Create(&models.CarProgress{CarID: "car-noted", EngineID: "eng-1", Note: "halfway", CreatedAt: now.Add(-2 * time.Minute)})
+
+	cfg := &config.Config{Stall: config.StallConfig{StaleClaimSec: 900}}
+	if err := releaseStaleClaims(db, clock.Real, cfg, repoDir, slog.Default(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]string{}
+	var cars []models.Car
+	db.Find(&cars)
+	for _, c := range cars {
+		statuses[c.ID] = c.Status
+	}
+	want := map[string]string{"car-stale": "open", "car-fresh": "claimed", "car-noted": "claimed", "car-push": "claimed", "car-working": "in_progress"}
+	for id, st := range want {
+		if statuses[id] != st {
+			t.Errorf("%s status = %q, want %q", id, statuses[id], st)
+		}
+	}
+
+	var eng models.Engine
+	db.First(&eng, "id = ?", "eng-1")
+	if eng.Status != "working" || eng.CurrentCar != "" || eng.StaleReleases != 1 {
+		t.Errorf("engine = status %q, car %q, releases %d; want still working, car cleared, 1 release", eng.Status, eng.CurrentCar, eng.StaleReleases)
+	}
+	var msgs []models.Message
+	db.Where("to_agent = ? AND subject = ? AND car_id = ?", "telegraph", "stale-claim", "car-stale").Find(&msgs)
+	if len(msgs) != 1 {
+		t.Errorf("telegraph messages = %d, want 1", len(msgs))
+	}
+
+	// Disabled with a negative threshold.
+	db.Model(&models.Car{}).Where("id = ?", "car-stale").Updates(map[string]interface{}{"status": "claimed", "assignee": "eng-1", "claimed_at": now.Add(-time.Hour)})
+	cfg.Stall.StaleClaimSec = -1
+	releaseStaleClaims(db, clock.Real, cfg, repoDir, slog.Default(), nil)
+	var c models.Car
+	db.First(&c, "id = ?", "car-stale")
+	if c.Status != "claimed" {
+		t.Errorf("status with stale_claim_sec=-1 = %q, want claimed", c.Status)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
@@ -15,10 +16,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/progress"
 	"github.com/zulandar/railyard/pkg/plugin"
 	"gorm.io/gorm"
 )
@@ -30,18 +33,28 @@ var gitMu sync.Mutex
 
 // SwitchOpts holds parameters for the switch (merge) operation.
 type SwitchOpts struct {
-	RepoDir          string                           // working directory (yardmaster worktree when running via daemon)
-	PrimaryRepoDir   string                           // primary repo directory (for engine worktree detachment; empty = use RepoDir)
-	BaseBranch       string                           // target branch for merge (default "main"); used for worktree-safe operations
-	DryRun           bool                             // run tests but don't merge
-	PreTestCommand   string                           // command to run before tests (e.g. "go mod vendor", "npm install")
-	TestCommand      string                           // per-track test command (e.g. "go test ./...", "phpunit", "npm test")
-	RequirePR        bool                             // create a draft PR instead of direct merge
-	SwitchTimeoutSec int                              // max seconds for runTests (default 600 if 0)
-	CommentCounter   func(branch string) (int, error) // nil-safe; returns non-author comment count (inline + conversation) for pr_open snapshot
-	RevisedLabel     string                           // label to apply after a revision pushes to an existing PR (e.g. "railyard: revised")
-	ReReviewLabel    string                           // inspect re-review label applied alongside RevisedLabel so the inspect daemon re-reviews the pushed revision (e.g. "inspect: re-review")
-	ConfigPath       string                           // path to railyard.yaml; re-read at PR-open time so current track config (e.g. Playwright) wins over dispatch-time config
+	RepoDir             string                           // working directory (yardmaster worktree when running via daemon)
+	PrimaryRepoDir      string                           // primary repo directory (for engine worktree detachment; empty = use RepoDir)
+	BaseBranch          string                           // target branch for merge (default "main"); used for worktree-safe operations
+	DryRun              bool                             // run tests but don't merge
+	PreTestCommand      string                           // command to run before tests (e.g. "go mod vendor", "npm install")
+	TestCommand         string                           // per-track test command (e.g. "go test ./...", "phpunit", "npm test")
+	TrackType           string                           // tracks[].type; docs and config tracks run content checks (see contentCheck)
+	TestMatrix          []config.TestMatrixCell          // per-track test matrix; when set, run instead of TestCommand
+	TestMatrixParallel  bool                             // run TestMatrix cells concurrently
+	TestRunner          TestRunner                       // remote runner for the track's tests; nil runs them in TestDir
+	TestDir             string                           // worktree local tests run in (empty = RepoDir); a separate one lets other cars merge meanwhile
+	Coverage            *config.CoverageConfig           // per-track coverage tracking; nil disables it
+	DiffLimit           *config.DiffLimitConfig          // per-track cap on files/lines changed per car; nil disables it
+	RequirePR           bool                             // create a draft PR instead of direct merge
+	SwitchTimeoutSec    int                              // max seconds for runTests (default 600 if 0)
+	TestNoticeSec       int                              // post a progress notice to telegraph each time tests run another N seconds; 0 disables
+	CommentCounter      func(branch string) (int, error) // nil-safe; returns non-author comment count (inline + conversation) for pr_open snapshot
+	RevisedLabel        string                           // label to apply after a revision pushes to an existing PR (e.g. "railyard: revised")
+	ReReviewLabel       string                           // inspect re-review label applied alongside RevisedLabel so the inspect daemon re-reviews the pushed revision (e.g. "inspect: re-review")
+	FailurePatternsFile string                           // patterns learned by ry merge relabel; empty uses the built-in classification only
+	RetryInfra          bool                             // the caller retries infra failures: leave the car done and page no one
+	Config              *config.Config                   // loaded railyard.yaml for PR rendering (pr_template, Playwright); nil uses the built-in PR format
 
 	// PR operation hooks — nil defaults to the gh-CLI implementations.
 	// Injectable for testing the RequirePR logic without a real GitHub remote.
@@ -52,12 +65,32 @@ type SwitchOpts struct {
 	MarkPRReadyFn   func(repoDir, branch string) error
 	AddPRLabelFn    func(repoDir, branch, label string) error
 
+	// Forge posts the merge-gate result (commit status and PR comment) when
+	// RequirePR is set. Nil uses the gh CLI.
+	Forge ForgeFunc
+
+	// Progress, when non-nil, receives a step as each pipeline stage begins:
+	// "fetch", "test", then "pr" or "merge" and "push".
+	Progress progress.Func
+
 	// Bus is the optional plugin event bus. When non-nil, [Switch] publishes
 	// [plugin.CarMerged] on success and [plugin.MergeFailed] on failure paths
 	// (test failure, merge conflict, push failure, etc.) after the relevant
 	// DB transition commits. A nil bus disables publishing — existing callers
 	// (e.g. cmd/ry/ switch) that omit this field are unchanged.
 	Bus events.Bus
+
+	// Clock is the time source for timestamps and retry scheduling; nil
+	// uses clock.Real.
+	Clock clock.Clock
+}
+
+// testDir returns the directory local merge-gate tests run in.
+func (o SwitchOpts) testDir() string {
+	if o.TestDir != "" {
+		return o.TestDir
+	}
+	return o.RepoDir
 }
 
 // SwitchFailureCategory categorizes Switch errors so the daemon can track and
@@ -65,14 +98,18 @@ type SwitchOpts struct {
 type SwitchFailureCategory string
 
 const (
-	SwitchFailNone    SwitchFailureCategory = ""
-	SwitchFailFetch   SwitchFailureCategory = "fetch-failed"
-	SwitchFailPreTest SwitchFailureCategory = "pre-test-failed"
-	SwitchFailTest    SwitchFailureCategory = "test-failed"
-	SwitchFailInfra   SwitchFailureCategory = "infra-failed"
-	SwitchFailMerge   SwitchFailureCategory = "merge-conflict"
-	SwitchFailPush    SwitchFailureCategory = "push-failed"
-	SwitchFailPR      SwitchFailureCategory = "pr-failed"
+	SwitchFailNone     SwitchFailureCategory = ""
+	SwitchFailFetch    SwitchFailureCategory = "fetch-failed"
+	SwitchFailPreTest  SwitchFailureCategory = "pre-test-failed"
+	SwitchFailTest     SwitchFailureCategory = "test-failed"
+	SwitchFailInfra    SwitchFailureCategory = "infra-failed"
+	SwitchFailMerge    SwitchFailureCategory = "merge-conflict"
+	SwitchFailPush     SwitchFailureCategory = "push-failed"
+	SwitchFailPR       SwitchFailureCategory = "pr-failed"
+	SwitchFailCoverage SwitchFailureCategory = "coverage-dropped"
+	SwitchFailDiffSize SwitchFailureCategory = "diff-too-large"
+	SwitchFailBranch   SwitchFailureCategory = "branch-changed" // deleted or rewritten on origin outside Railyard
+	SwitchFailAborted  SwitchFailureCategory = "aborted"        // test run cancelled from chat; the car stays done for a later retry
 )
 
 // SwitchResult contains the outcome of a switch operation.
@@ -85,16 +122,23 @@ type SwitchResult struct {
 	AlreadyMerged   bool // true when the branch was already an ancestor of main
 	PRCreated       bool
 	PRUrl           string
-	FailureCategory SwitchFailureCategory // set on error for categorized escalation
-	ConflictDetails string                // conflict file list + diff context for escalation
+	FailureCategory SwitchFailureCategory  // set on error for categorized escalation
+	ConflictDetails string                 // conflict file list + diff context for escalation
+	FailedCells     []string               // test matrix cells that failed, in matrix order
+	Coverage        *models.CoverageRecord // coverage measured at the gate; nil when not tracked
 	Error           error
 }
 
 // Switch performs the branch merge flow for a completed car:
 // 1. Fetch the branch
-// 2. Run the track's test suite
-// 3. If tests pass and not dry-run: merge to main
-// 4. If tests fail: set car status to blocked, notify engine
+// 2. If the branch exceeds the track's diff limit: block the car, ask dispatch to split it
+// 3. Run the track's test suite
+// 4. If tests pass and not dry-run: merge to main
+// 5. If tests fail: set car status to blocked, notify engine
+//
+// Every step holds gitMu. When opts.TestDir is a worktree other than RepoDir
+// the lock is released while the tests run there, so one car's tests can
+// overlap other cars' tests and merges; only the git steps are serialized.
 func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 	if db == nil {
 		return nil, fmt.Errorf("yardmaster: db is required")
@@ -105,10 +149,16 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 	if opts.RepoDir == "" {
 		return nil, fmt.Errorf("yardmaster: repoDir is required")
 	}
+	clk := clock.OrReal(opts.Clock)
 
 	// Serialize git operations to prevent worktree corruption.
 	gitMu.Lock()
-	defer gitMu.Unlock()
+	locked := true
+	defer func() {
+		if locked {
+			gitMu.Unlock()
+		}
+	}()
 
 	// Load the car.
 	var car models.Car
@@ -129,6 +179,10 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		CarID:  carID,
 		Branch: car.Branch,
 	}
+	if opts.RequirePR && !opts.DryRun {
+		// Runs once the outcome is final, so a PR created below is found.
+		defer reportGate(opts, car.Branch, result)
+	}
 
 	slog.Info("Switch: starting merge pipeline",
 		"car", carID,
@@ -139,6 +193,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 	)
 
 	// Fetch the branch.
+	opts.Progress.Report(progress.Event{Step: "fetch", Detail: car.Branch})
 	if err := gitFetch(opts.RepoDir); err != nil {
 		result.FailureCategory = SwitchFailFetch
 		result.Error = fmt.Errorf("fetch: %w", err)
@@ -151,6 +206,14 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 
 	slog.Debug("Switch: fetch complete", "car", carID)
 
+	// A branch deleted or rewritten on origin would otherwise fail
+	// opaquely further down (or merge someone else's history); hold the
+	// car for a human instead.
+	if reason := checkCarBranch(opts.RepoDir, &car); reason != "" {
+		flagBranchChanged(db, &car, reason, opts.Bus, result)
+		return result, nil
+	}
+
 	// Detach the engine worktree so the branch can be checked out.
 	// Engine worktrees live under the primary repo, not the yardmaster worktree.
 	if car.Assignee != "" {
@@ -162,6 +225,21 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		slog.Debug("Switch: engine worktree detached", "car", carID, "assignee", car.Assignee)
 	}
 
+	// Defer oversized cars before spending a test run on them.
+	if opts.DiffLimit != nil {
+		if deferred := checkDiffLimit(db, &car, opts, baseBranch, result); deferred {
+			return result, nil
+		}
+	}
+
+	// Docs and config tracks check the files the branch changes before any
+	// test_command (e.g. a spell checker) runs.
+	if !car.SkipTests && (opts.TrackType == config.TrackTypeDocs || opts.TrackType == config.TrackTypeConfig) {
+		if blocked := checkTrackContent(db, &car, opts, baseBranch, result); blocked {
+			return result, nil
+		}
+	}
+
 	// Run tests on the branch (unless skip_tests is set on the car).
 	if car.SkipTests {
 		result.TestsPassed = true
@@ -178,20 +256,79 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 			"car", carID,
 			"branch", car.Branch,
 			"test_command", opts.TestCommand,
+			"test_matrix_cells", len(opts.TestMatrix),
 			"pre_test_command", opts.PreTestCommand,
 			"timeout_sec", timeoutSec,
 		)
 
-		testOutput, testErr := runTests(ctx, opts.RepoDir, car.Branch, baseBranch, opts.PreTestCommand, opts.TestCommand)
+		testDetail := opts.TestCommand
+		if len(opts.TestMatrix) > 0 {
+			testDetail = fmt.Sprintf("%d matrix cells", len(opts.TestMatrix))
+		}
+		opts.Progress.Report(progress.Event{Step: "test", Detail: testDetail})
+
+		// A stale profile from an earlier run must not pass for this one.
+		if opts.Coverage != nil && opts.Coverage.Profile != "" && opts.TestRunner == nil {
+			os.Remove(filepath.Join(opts.testDir(), opts.Coverage.Profile))
+		}
+
+		if opts.TestDir != "" && opts.TestDir != opts.RepoDir {
+			gitMu.Unlock()
+			locked = false
+		}
+		tail := newOutputTail(noticeTailLen)
+		watch := startTestWatch(db, clk, &car, time.Duration(opts.TestNoticeSec)*time.Second, tail, cancel)
+		var testOutput string
+		var testErr error
+		var cells []TestCellResult
+		if opts.TestRunner != nil && (len(opts.TestMatrix) > 0 || opts.TestCommand != "") {
+			jobCells := opts.TestMatrix
+			if len(jobCells) == 0 {
+				jobCells = []config.TestMatrixCell{{Command: opts.TestCommand}}
+			}
+			testOutput, cells, testErr = opts.TestRunner.Run(ctx, TestJob{
+				CarID:          carID,
+				Branch:         car.Branch,
+				BaseBranch:     baseBranch,
+				PreTestCommand: opts.PreTestCommand,
+				Cells:          jobCells,
+				Parallel:       opts.TestMatrixParallel,
+			})
+		} else if len(opts.TestMatrix) > 0 {
+			testOutput, cells, testErr = runTestMatrix(ctx, opts.testDir(), car.Branch, baseBranch, opts.PreTestCommand, opts.TestMatrix, opts.TestMatrixParallel, tail)
+		} else {
+			testOutput, _, testErr = runTestMatrix(ctx, opts.testDir(), car.Branch, baseBranch, opts.PreTestCommand, testCommandCells(opts.TestCommand), false, tail)
+		}
+		abortRequest := watch.stop()
+		if !locked {
+			gitMu.Lock()
+			locked = true
+			// Other cars may have merged while the tests ran.
+			if err := gitFetch(opts.RepoDir); err != nil {
+				slog.Warn("Switch: refetch after tests failed", "car", carID, "error", err)
+			}
+		}
 		result.TestOutput = testOutput
 
+		if abortRequest != "" {
+			// Aborted from chat: not a failure of the car. It stays done and
+			// the daemon requeues it later.
+			result.FailureCategory = SwitchFailAborted
+			result.Error = fmt.Errorf("tests aborted: %s", abortRequest)
+			slog.Info("Switch: tests aborted", "car", carID, "request", abortRequest)
+			return result, nil
+		}
+
 		if testErr != nil {
 			result.TestsPassed = false
+			result.FailedCells = failedCellNames(cells)
 
 			if strings.Contains(testErr.Error(), "pre-test command failed") {
 				result.FailureCategory = SwitchFailPreTest
+			} else if patterns := loadSwitchFailurePatterns(opts.FailurePatternsFile); len(opts.TestMatrix) > 0 {
+				result.FailureCategory = classifyMatrixFailure(patterns, cells, testErr, testOutput)
 			} else {
-				result.FailureCategory = classifyTestFailure(testErr, testOutput)
+				result.FailureCategory = patterns.classify(testErr, testOutput)
 			}
 
 			slog.Warn("Switch: tests failed",
@@ -200,7 +337,11 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 				"error", testErr,
 			)
 
-			if result.FailureCategory == SwitchFailInfra {
+			switch {
+			case result.FailureCategory == SwitchFailInfra && opts.RetryInfra:
+				// Infrastructure failure the daemon retries with backoff; the
+				// car stays done and a human is paged once retries run out.
+			case result.FailureCategory == SwitchFailInfra:
 				// Infrastructure failure — set merge-failed, escalate to human.
 				if dbErr := db.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
 					"status":         "merge-failed",
@@ -222,7 +363,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 					msg,
 					messaging.SendOpts{CarID: carID, Priority: "urgent"},
 				)
-			} else {
+			default:
 				// Code test failure — set blocked, notify engine.
 				if dbErr := db.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
 					"status":         "blocked",
@@ -244,6 +385,12 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 
 		result.TestsPassed = true
 		slog.Info("Switch: tests passed", "car", carID)
+
+		if opts.Coverage != nil {
+			if blocked := checkCoverage(db, &car, opts, testOutput, result); blocked {
+				return result, nil
+			}
+		}
 	}
 
 	if opts.DryRun {
@@ -262,7 +409,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		result.Merged = true
 		result.AlreadyMerged = true
 
-		now := time.Now()
+		now := clk.Now()
 		if dbErr := db.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
 			"status":       "merged",
 			"completed_at": now,
@@ -293,14 +440,16 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 			)
 			for _, u := range unblocked {
 				if u.Type == "epic" {
-					TryCloseEpic(db, u.ID)
+					TryCloseEpic(db, clk, u.ID)
 				}
 			}
 		}
 
 		if car.ParentID != nil && *car.ParentID != "" {
-			TryCloseEpic(db, *car.ParentID)
+			TryCloseEpic(db, clk, *car.ParentID)
 		}
+		markRevertedOriginal(db, clk, &car)
+		markConflictResolvedOriginal(db, clk, &car)
 
 		return result, nil
 	}
@@ -335,6 +484,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		}
 
 		// Push the branch to origin so a PR can reference it.
+		opts.Progress.Report(progress.Event{Step: "pr", Detail: car.Branch})
 		if err := pushBranch(opts.RepoDir, car.Branch); err != nil {
 			result.FailureCategory = SwitchFailPush
 			result.Error = fmt.Errorf("push branch: %w", err)
@@ -351,8 +501,8 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		var prURL string
 		if existsErr == nil && existingURL != "" {
 			// PR exists — update body with latest progress notes.
-			prBody := buildPRBody(db, &car, opts.RepoDir, baseBranch, opts.ConfigPath)
-			if updErr := updateBody(opts.RepoDir, car.Branch, prBody); updErr != nil {
+			pr := renderPR(db, &car, opts.RepoDir, baseBranch, opts.Config)
+			if updErr := updateBody(opts.RepoDir, car.Branch, pr.Body); updErr != nil {
 				slog.Warn("Update PR body failed", "car", carID, "error", updErr)
 			}
 
@@ -361,8 +511,13 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 				slog.Warn("Mark PR ready failed", "car", carID, "error", err)
 			}
 
+			// A draft opened for in-progress work (draft_pr_on_push) that is
+			// now going ready for the first time is not a revision: skip the
+			// revised and re-review labels.
+			firstReady := car.DraftPRAt != nil && car.CompletedAt == nil
+
 			// Apply revised label to signal reviewers that changes have been pushed.
-			if opts.RevisedLabel != "" {
+			if opts.RevisedLabel != "" && !firstReady {
 				if err := addLabel(opts.RepoDir, car.Branch, opts.RevisedLabel); err != nil {
 					slog.Warn("Add revised label failed", "car", carID, "error", err)
 				}
@@ -374,7 +529,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 			// never re-reviewed, the revised label is never cleared, and the
 			// yardmaster stale-CHANGES_REQUESTED guard blocks reopen forever
 			// (railyard-1d0.5).
-			if opts.ReReviewLabel != "" {
+			if opts.ReReviewLabel != "" && !firstReady {
 				if err := addLabel(opts.RepoDir, car.Branch, opts.ReReviewLabel); err != nil {
 					slog.Warn("Add re-review label failed", "car", carID, "error", err)
 				}
@@ -385,9 +540,9 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 				"car", carID, "branch", car.Branch, "pr_url", prURL)
 		} else {
 			// No existing PR — create a new draft.
-			prBody := buildPRBody(db, &car, opts.RepoDir, baseBranch, opts.ConfigPath)
+			pr := renderPR(db, &car, opts.RepoDir, baseBranch, opts.Config)
 			var createErr error
-			prURL, createErr = createDraft(opts.RepoDir, car.Title, prBody, car.Branch)
+			prURL, createErr = createDraft(opts.RepoDir, pr.Title, pr.Body, car.Branch)
 			if createErr != nil {
 				result.FailureCategory = SwitchFailPR
 				result.Error = fmt.Errorf("create PR: %w", createErr)
@@ -419,7 +574,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		}
 
 		// Mark car as pr_open — not merged yet, waiting for human review.
-		now := time.Now()
+		now := clk.Now()
 		if dbErr := db.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
 			"status":                "pr_open",
 			"completed_at":          now,
@@ -435,8 +590,10 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 	preMergeHead := getHeadCommit(opts.RepoDir)
 
 	// Merge to the base branch.
+	opts.Progress.Report(progress.Event{Step: "merge", Detail: baseBranch})
 	slog.Debug("Switch: attempting merge", "car", carID, "branch", car.Branch, "base_branch", baseBranch)
-	if err := gitMerge(opts.RepoDir, car.Branch, baseBranch); err != nil {
+	mergeMsg := mergeCommitMessage(db, &car, baseBranch)
+	if err := gitMerge(opts.RepoDir, car.Branch, baseBranch, mergeMsg); err != nil {
 		// Attempt conflict resolution: abort failed merge, rebase branch, retry.
 		resolved, resolveErr := tryResolveConflict(opts.RepoDir, car.Branch, baseBranch)
 		slog.Debug("Switch: conflict resolution attempted", "car", carID, "resolved", resolved)
@@ -453,7 +610,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 			return result, result.Error
 		}
 		// Rebase succeeded — retry the merge (should be clean now).
-		if retryErr := gitMerge(opts.RepoDir, car.Branch, baseBranch); retryErr != nil {
+		if retryErr := gitMerge(opts.RepoDir, car.Branch, baseBranch, mergeMsg); retryErr != nil {
 			result.FailureCategory = SwitchFailMerge
 			// Capture conflict details from the failed retry merge.
 			conflictFiles := getConflictFiles(opts.RepoDir)
@@ -473,6 +630,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 	// Push to remote before marking merged — the car should only be
 	// considered merged once the code is confirmed on the remote.
 	slog.Debug("Switch: pushing merge to remote", "car", carID, "base_branch", baseBranch)
+	opts.Progress.Report(progress.Event{Step: "push", Detail: baseBranch})
 	if err := gitPush(opts.RepoDir, baseBranch); err != nil {
 		// Undo the local merge so the car will be retried next cycle.
 		gitResetToCommit(opts.RepoDir, preMergeHead)
@@ -501,7 +659,7 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 	)
 
 	// Mark car as merged — push succeeded, safe to update status.
-	now := time.Now()
+	now := clk.Now()
 	if dbErr := db.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
 		"status":       "merged",
 		"completed_at": now,
@@ -533,16 +691,21 @@ func Switch(db *gorm.DB, carID string, opts SwitchOpts) (*SwitchResult, error) {
 		// Auto-close any unblocked epics whose children are all complete.
 		for _, u := range unblocked {
 			if u.Type == "epic" {
-				TryCloseEpic(db, u.ID)
+				TryCloseEpic(db, clk, u.ID)
 			}
 		}
 	}
 
 	// Auto-close parent epic if all children are done.
 	if car.ParentID != nil && *car.ParentID != "" {
-		TryCloseEpic(db, *car.ParentID)
+		TryCloseEpic(db, clk, *car.ParentID)
 	}
 
+	// A merged revert car retires the car it reverted; a merged conflict
+	// car carries the car whose conflict it resolved.
+	markRevertedOriginal(db, clk, &car)
+	markConflictResolvedOriginal(db, clk, &car)
+
 	return result, nil
 }
 
@@ -589,6 +752,16 @@ func UnblockDeps(db *gorm.DB, carID string) ([]models.Car, error) {
 			if b.Status != "blocked" {
 				continue
 			}
+			// A command policy block waits for a human to approve the
+			// command, whatever happens to the car's dependencies.
+			if b.BlockedReason == models.BlockedReasonCommandPolicy {
+				continue
+			}
+			// An oversized car waits for dispatch to split it; merging a
+			// dependency does not make its diff any smaller.
+			if b.BlockedReason == models.BlockedReasonDiffTooLarge {
+				continue
+			}
 
 			// Test-failure blocks transition to "done" so the merge pipeline
 			// retries (the dependency that caused the failure is now merged).
@@ -670,18 +843,61 @@ var infraPatterns = []string{
 	"database file at path",
 }
 
+// checkCoverage measures and records the car's coverage and applies the
+// track's max_drop gate. It reports whether the car was blocked; a coverage
+// drop blocks the car for its engine like a test failure. Coverage that
+// cannot be measured is logged and does not block the merge. Dry runs
+// report coverage without recording it or blocking the car.
+func checkCoverage(db *gorm.DB, car *models.Car, opts SwitchOpts, testOutput string, result *SwitchResult) bool {
+	pct, err := measureCoverage(opts.Coverage, opts.testDir(), testOutput)
+	if err != nil {
+		slog.Warn("Switch: coverage not measured", "car", car.ID, "error", err)
+		return false
+	}
+	rec := newCoverageRecord(db, car, pct)
+	result.Coverage = rec
+	if !opts.DryRun {
+		if dbErr := db.Create(rec).Error; dbErr != nil {
+			slog.Error("record coverage", "car", car.ID, "error", dbErr)
+		}
+	}
+	slog.Info("Switch: coverage measured", "car", car.ID, "coverage", pct, "delta", rec.Delta)
+
+	dropErr := coverageDropError(rec, opts.Coverage.MaxDrop)
+	if dropErr == nil {
+		return false
+	}
+	result.FailureCategory = SwitchFailCoverage
+	result.Error = dropErr
+	slog.Warn("Switch: coverage gate failed", "car", car.ID, "error", dropErr)
+	if opts.DryRun {
+		return true
+	}
+
+	if dbErr := db.Model(&models.Car{}).Where("id = ?", car.ID).Updates(map[string]interface{}{
+		"status":         "blocked",
+		"blocked_reason": models.BlockedReasonCoverageDropped,
+	}).Error; dbErr != nil {
+		slog.Error("update car to blocked", "car", car.ID, "error", dbErr)
+	}
+	if car.Assignee != "" {
+		messaging.Send(db, "yardmaster", car.Assignee, "test-failure",
+			fmt.Sprintf("Tests passed for car %s on branch %s but %v. Add tests for the new code before the merge is retried.", car.ID, car.Branch, dropErr),
+			messaging.SendOpts{CarID: car.ID, Priority: "urgent"},
+		)
+	}
+	return true
+}
+
 // classifyTestFailure distinguishes infrastructure failures from code test
 // failures by inspecting the error and output. Exit codes 126 (permission
 // denied), 127 (command not found), and 128 (git fatal error) are always
-// infrastructure. Otherwise the output is pattern-matched against known
-// infrastructure signatures.
+// infrastructure, as is ErrTestRunnerUnavailable from a remote runner.
+// Otherwise the output is pattern-matched against known infrastructure
+// signatures.
 func classifyTestFailure(err error, output string) SwitchFailureCategory {
-	var exitErr *exec.ExitError
-	if errors.As(err, &exitErr) {
-		code := exitErr.ExitCode()
-		if code == 127 || code == 126 || code == 128 {
-			return SwitchFailInfra
-		}
+	if isRunnerInfraError(err) {
+		return SwitchFailInfra
 	}
 
 	lower := strings.ToLower(output)
@@ -701,6 +917,21 @@ func classifyTestFailure(err error, output string) SwitchFailureCategory {
 	return SwitchFailTest
 }
 
+// isRunnerInfraError reports whether err alone marks a test run as an
+// infrastructure failure: an unavailable remote runner, or exit code 126,
+// 127, or 128.
+func isRunnerInfraError(err error) bool {
+	if errors.Is(err, ErrTestRunnerUnavailable) {
+		return true
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		return code == 127 || code == 126 || code == 128
+	}
+	return false
+}
+
 // Test-summary parsers for the env-error heuristic. They match xUnit-style
 // summary lines such as PHPUnit's "Tests: 47, Assertions: 17, Errors: 38".
 var (
@@ -783,6 +1014,26 @@ func truncateOutput(output string, maxLen int) string {
 // baseBranch is the branch to return to after tests (e.g. "main").
 // The provided ctx controls the overall timeout for pre-test and test commands.
 func runTests(ctx context.Context, repoDir, branch, baseBranch, preTestCommand, testCommand string) (string, error) {
+	output, _, err := runTestMatrix(ctx, repoDir, branch, baseBranch, preTestCommand, testCommandCells(testCommand), false, nil)
+	return output, err
+}
+
+// testCommandCells is the single unnamed cell running a plain test_command,
+// or none when it is empty.
+func testCommandCells(testCommand string) []config.TestMatrixCell {
+	if testCommand == "" {
+		return nil
+	}
+	return []config.TestMatrixCell{{Command: testCommand}}
+}
+
+// runTestMatrix checks out the branch, runs the pre-test command, then runs
+// each test cell (concurrently when parallel is set) and aggregates the
+// results. A single unnamed cell behaves exactly like a plain test_command;
+// named cells get a per-cell header in the output and failing cells are
+// named in the returned error. When live is non-nil, pre-test and test
+// output is also copied to it as it is produced.
+func runTestMatrix(ctx context.Context, repoDir, branch, baseBranch, preTestCommand string, cells []config.TestMatrixCell, parallel bool, live io.Writer) (string, []TestCellResult, error) {
 	// Discard any uncommitted changes before switching branches.
 	gitCleanWorkingTree(repoDir)
 	slog.Debug("runTests: cleaned working tree", "branch", branch)
@@ -802,7 +1053,7 @@ func runTests(ctx context.Context, repoDir, branch, baseBranch, preTestCommand,
 			last := exec.Command("git", "checkout", "--detach", branch)
 			last.Dir = repoDir
 			if lOut, lErr := last.CombinedOutput(); lErr != nil {
-				return string(out) + "\n" + string(dOut) + "\n" + string(lOut),
+				return string(out) + "\n" + string(dOut) + "\n" + string(lOut), nil,
 					fmt.Errorf("git checkout %s: %w", branch, err)
 			}
 		}
@@ -814,51 +1065,67 @@ func runTests(ctx context.Context, repoDir, branch, baseBranch, preTestCommand,
 		slog.Debug("runTests: running pre-test command", "command", preTestCommand)
 		preCmd := exec.CommandContext(ctx, "sh", "-c", preTestCommand)
 		preCmd.Dir = repoDir
-		if out, err := preCmd.CombinedOutput(); err != nil {
+		if out, err := combinedOutput(preCmd, live); err != nil {
 			checkoutBase(repoDir, baseBranch)
 			if ctx.Err() == context.DeadlineExceeded {
-				dl, _ := ctx.Deadline()
-				timeout := time.Until(dl) + time.Since(dl) // reconstruct original timeout
-				_ = timeout
-				return string(out), fmt.Errorf("switch timeout exceeded during pre-test command")
+				return string(out), nil, fmt.Errorf("switch timeout exceeded during pre-test command")
 			}
-			return string(out), fmt.Errorf("pre-test command failed: %w", err)
+			return string(out), nil, fmt.Errorf("pre-test command failed: %w", err)
 		}
 		slog.Debug("runTests: pre-test command succeeded")
 	}
 
-	// Run the track's configured test command.
-	if testCommand == "" {
+	// Run the track's configured test command(s).
+	if len(cells) == 0 {
 		slog.Warn("no test_command configured for track; skipping tests")
 		checkoutBase(repoDir, baseBranch)
-		return "", nil
+		return "", nil, nil
+	}
+	results := make([]TestCellResult, len(cells))
+	if parallel && len(cells) > 1 {
+		var wg sync.WaitGroup
+		for i := range cells {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = runTestCell(ctx, repoDir, cells[i], live)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range cells {
+			results[i] = runTestCell(ctx, repoDir, cells[i], live)
+		}
 	}
-	slog.Debug("runTests: executing test command", "command", testCommand)
-	testCmd := exec.CommandContext(ctx, "sh", "-c", testCommand)
-	testCmd.Dir = repoDir
-
-	out, err := testCmd.CombinedOutput()
-	output := string(out)
 
 	// Return to base branch regardless.
 	checkoutBase(repoDir, baseBranch)
 	slog.Debug("runTests: returned to base branch", "base_branch", baseBranch)
 
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return output, fmt.Errorf("switch timeout exceeded")
-		}
-		// Check for "no tests" patterns — treat as pass.
-		for _, pat := range noTestPatterns {
-			if strings.Contains(output, pat) {
-				slog.Debug("runTests: no-test pattern matched, treating as pass", "pattern", pat)
-				return output, nil
-			}
-		}
-		return output, fmt.Errorf("tests failed: %w", err)
+	matrix := len(cells) > 1 || cells[0].Name != ""
+	output := results[0].Output
+	if matrix {
+		output = formatMatrixOutput(results)
 	}
 
-	return output, nil
+	failed := failedCellNames(results)
+	if len(failed) == 0 {
+		return output, results, nil
+	}
+	var firstErr error
+	for _, r := range results {
+		if !r.Passed {
+			firstErr = r.Err
+			break
+		}
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, results, fmt.Errorf("switch timeout exceeded")
+	}
+	if matrix {
+		return output, results, fmt.Errorf("tests failed in matrix cell(s) %s: %w", strings.Join(failed, ", "), firstErr)
+	}
+	return output, results, fmt.Errorf("tests failed: %w", firstErr)
 }
 
 // deleteRemoteBranch deletes a branch from the remote. Non-fatal — logs warning on failure.
@@ -943,7 +1210,7 @@ func resolveOriginRef(repoDir, ref string) string {
 
 // gitMerge merges the branch into the base branch.
 // Uses checkoutBase which handles worktree mode (detached HEAD fallback).
-func gitMerge(repoDir, branch, baseBranch string) error {
+func gitMerge(repoDir, branch, baseBranch, msg string) error {
 	// Discard any uncommitted changes left by tests or prior operations.
 	// The yardmaster repo should always have a clean working tree before merge.
 	gitCleanWorkingTree(repoDir)
@@ -952,8 +1219,7 @@ func gitMerge(repoDir, branch, baseBranch string) error {
 	checkoutBase(repoDir, baseBranch)
 
 	// Verify we're at the right commit (either on baseBranch or detached at it).
-	// Merge the branch with co-author trailer for Railyard attribution.
-	msg := fmt.Sprintf("Switch: merge %s to %s\n\nCo-Authored-By: Railyard Yardmaster <railyard-yardmaster@noreply>", branch, baseBranch)
+	// msg carries the car trailers and co-author attribution (mergeCommitMessage).
 	merge := exec.Command("git", "merge", "--no-ff", branch, "-m", msg)
 	merge.Dir = repoDir
 	if out, err := merge.CombinedOutput(); err != nil {
@@ -1216,7 +1482,7 @@ func detachEngineWorktree(repoDir, engineID string) {
 // TryCloseEpic checks if all children of an epic are done/cancelled and, if so,
 // marks the epic as done. This is called after each successful merge to handle
 // automatic epic completion.
-func TryCloseEpic(db *gorm.DB, epicID string) {
+func TryCloseEpic(db *gorm.DB, clk clock.Clock, epicID string) {
 	if db == nil || epicID == "" {
 		return
 	}
@@ -1232,7 +1498,7 @@ func TryCloseEpic(db *gorm.DB, epicID string) {
 	// Count children that are NOT done, merged, or cancelled.
 	var remaining int64
 	if err := db.Model(&models.Car{}).
-		Where("parent_id = ? AND status NOT IN ?", epicID, []string{"done", "merged", "cancelled"}).
+		Where("parent_id = ? AND status NOT IN ?", epicID, models.ClosedStatuses).
 		Count(&remaining).Error; err != nil {
 		slog.Error("TryCloseEpic: count remaining children", "epic", epicID, "error", err)
 		return
@@ -1243,7 +1509,7 @@ func TryCloseEpic(db *gorm.DB, epicID string) {
 	}
 
 	// All children are done/cancelled — close the epic.
-	now := time.Now()
+	now := clk.Now()
 	if err := db.Model(&models.Car{}).Where("id = ?", epicID).Updates(map[string]interface{}{
 		"status":       "done",
 		"completed_at": now,
@@ -1259,7 +1525,7 @@ func TryCloseEpic(db *gorm.DB, epicID string) {
 
 	// Recurse: if the epic itself has a parent epic, check that too.
 	if epic.ParentID != nil && *epic.ParentID != "" {
-		TryCloseEpic(db, *epic.ParentID)
+		TryCloseEpic(db, clk, *epic.ParentID)
 	}
 }
 
@@ -1274,10 +1540,8 @@ func gitPushBranch(repoDir, branch string) error {
 }
 
 // buildPRBody assembles a rich PR description from the car record and progress notes.
-// configPath, when non-empty, is re-read at PR-open time so the current track config
-// (e.g. Playwright) wins over the dispatch-time config. A missing or invalid file is
-// treated as "no playwright section" (silent, non-fatal).
-func buildPRBody(db *gorm.DB, car *models.Car, repoDir, baseBranch, configPath string) string {
+// cfg supplies the track's Playwright settings; nil means "no playwright section".
+func buildPRBody(db *gorm.DB, car *models.Car, repoDir, baseBranch string, cfg *config.Config) string {
 	var b strings.Builder
 
 	// Summary.
@@ -1329,10 +1593,8 @@ func buildPRBody(db *gorm.DB, car *models.Car, repoDir, baseBranch, configPath s
 	}
 
 	// Playwright Demo section — appended only when the resolved track has
-	// playwright.enabled=true at PR-open time. We re-read railyard.yaml here
-	// so config changes between dispatch and PR-open take effect (current
-	// config wins). Missing/invalid config => silent skip.
-	if section := buildPlaywrightSection(car, configPath); section != "" {
+	// playwright.enabled=true.
+	if section := buildPlaywrightSection(car, cfg); section != "" {
 		b.WriteString(section)
 	}
 
@@ -1349,19 +1611,10 @@ func buildPRBody(db *gorm.DB, car *models.Car, repoDir, baseBranch, configPath s
 
 // buildPlaywrightSection returns the rendered "Playwright Demo" markdown
 // section for the car's track, or an empty string when the section should be
-// omitted (no configPath, config load fails, track missing, no playwright
-// block, or playwright.enabled=false).
-//
-// The config is re-read from disk at PR-open time so changes between dispatch
-// and PR-open take effect — current config wins.
-func buildPlaywrightSection(car *models.Car, configPath string) string {
-	if car == nil || configPath == "" {
-		return ""
-	}
-	cfg, err := config.Load(configPath)
-	if err != nil {
-		slog.Debug("buildPlaywrightSection: config load failed; omitting section",
-			"car", car.ID, "config_path", configPath, "error", err)
+// omitted (nil cfg, track missing, no playwright block, or
+// playwright.enabled=false).
+func buildPlaywrightSection(car *models.Car, cfg *config.Config) string {
+	if car == nil || cfg == nil {
 		return ""
 	}
 	var pw *models.PlaywrightConfig
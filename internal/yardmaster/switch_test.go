@@ -11,7 +11,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/progress"
 )
 
 // --- Switch validation tests ---
@@ -470,7 +473,7 @@ func TestBuildPRBody_FullCar(t *testing.T) {
 		Note:     "Added test coverage for all token states",
 	})
 
-	body := buildPRBody(db, &c, "/nonexistent", "main", "") // repoDir doesn't matter — git diff will fail gracefully
+	body := buildPRBody(db, &c, "/nonexistent", "main", nil) // repoDir doesn't matter — git diff will fail gracefully
 
 	// Summary section.
 	if !strings.Contains(body, "## Summary") {
@@ -533,7 +536,7 @@ func TestBuildPRBody_MinimalCar(t *testing.T) {
 	}
 	db.Create(&c)
 
-	body := buildPRBody(db, &c, "/nonexistent", "main", "")
+	body := buildPRBody(db, &c, "/nonexistent", "main", nil)
 
 	// Should use title as summary when description is empty.
 	if !strings.Contains(body, "Fix typo") {
@@ -576,7 +579,7 @@ func TestBuildPRBody_NilDB(t *testing.T) {
 	}
 
 	// Should not panic with nil DB — just no progress section.
-	body := buildPRBody(nil, &c, "/nonexistent", "main", "")
+	body := buildPRBody(nil, &c, "/nonexistent", "main", nil)
 	if !strings.Contains(body, "## Summary") {
 		t.Error("missing Summary section")
 	}
@@ -598,6 +601,16 @@ func writeYAMLConfig(t *testing.T, body string) string {
 	return p
 }
 
+// loadYAMLConfig parses body as a railyard.yaml.
+func loadYAMLConfig(t *testing.T, body string) *config.Config {
+	t.Helper()
+	cfg, err := config.Load(writeYAMLConfig(t, body))
+	if err != nil {
+		t.Fatalf("load yaml: %v", err)
+	}
+	return cfg
+}
+
 func TestBuildPRBody_Playwright_EnabledTrackIncludesSection(t *testing.T) {
 	db := testDB(t)
 	c := models.Car{
@@ -619,9 +632,9 @@ tracks:
       spec_path: tests/pr-demos
       filename: "{car_id}.spec.ts"
 `
-	configPath := writeYAMLConfig(t, yaml)
+	cfg := loadYAMLConfig(t, yaml)
 
-	body := buildPRBody(db, &c, "/nonexistent", "main", configPath)
+	body := buildPRBody(db, &c, "/nonexistent", "main", cfg)
 
 	if !strings.Contains(body, "## 📹 Playwright Demo") {
 		t.Errorf("missing Playwright Demo heading; body=\n%s", body)
@@ -654,9 +667,9 @@ tracks:
       enabled: false
       spec_path: tests/pr-demos
 `
-	configPath := writeYAMLConfig(t, yaml)
+	cfg := loadYAMLConfig(t, yaml)
 
-	body := buildPRBody(db, &c, "/nonexistent", "main", configPath)
+	body := buildPRBody(db, &c, "/nonexistent", "main", cfg)
 
 	if strings.Contains(body, "Playwright Demo") {
 		t.Errorf("disabled track should not include Playwright section; body=\n%s", body)
@@ -679,18 +692,18 @@ tracks:
   - name: backend
     language: go
 `
-	configPath := writeYAMLConfig(t, yaml)
+	cfg := loadYAMLConfig(t, yaml)
 
-	bodyWithConfig := buildPRBody(db, &c, "/nonexistent", "main", configPath)
-	bodyNoConfig := buildPRBody(db, &c, "/nonexistent", "main", "")
+	bodyWithConfig := buildPRBody(db, &c, "/nonexistent", "main", cfg)
+	bodyNoConfig := buildPRBody(db, &c, "/nonexistent", "main", nil)
 
 	if strings.Contains(bodyWithConfig, "Playwright Demo") {
 		t.Errorf("track without playwright block should not include section; body=\n%s", bodyWithConfig)
 	}
 	// Body should otherwise be identical to the no-config baseline — the
-	// playwright section is the only thing configPath should influence.
+	// playwright section is the only thing the config should influence.
 	if bodyWithConfig != bodyNoConfig {
-		t.Errorf("body differs between configPath=\"\" and configPath=<track without playwright>:\n--- with config ---\n%s\n--- no config ---\n%s",
+		t.Errorf("body differs between nil config and a config whose track has no playwright:\n--- with config ---\n%s\n--- no config ---\n%s",
 			bodyWithConfig, bodyNoConfig)
 	}
 }
@@ -698,8 +711,8 @@ tracks:
 func TestBuildPRBody_Playwright_ConfigChangedBetweenDispatchAndPROpen(t *testing.T) {
 	// Simulates the design-spec case: at dispatch time, playwright was OFF
 	// (or absent). Between dispatch and PR-open, ops enables playwright in
-	// railyard.yaml. PR-open re-reads the file, so the current config wins
-	// and the section IS rendered.
+	// railyard.yaml. PR-open renders with the config loaded at that point,
+	// so the current config wins and the section IS rendered.
 	db := testDB(t)
 	c := models.Car{
 		ID:     "car-pw4",
@@ -716,16 +729,14 @@ tracks:
   - name: frontend
     language: typescript
 `
-	configPath := writeYAMLConfig(t, dispatchYAML)
-
 	// First render — dispatch-time config: no section.
-	bodyDispatch := buildPRBody(db, &c, "/nonexistent", "main", configPath)
+	bodyDispatch := buildPRBody(db, &c, "/nonexistent", "main", loadYAMLConfig(t, dispatchYAML))
 	if strings.Contains(bodyDispatch, "Playwright Demo") {
 		t.Fatalf("dispatch-time render should not have section; body=\n%s", bodyDispatch)
 	}
 
-	// Replace the file in-place between dispatch and PR-open. PR-open-time
-	// config wins.
+	// The config changes between dispatch and PR-open. PR-open-time config
+	// wins.
 	prOpenYAML := `owner: alice
 repo: git@github.com:org/app.git
 tracks:
@@ -736,11 +747,7 @@ tracks:
       spec_path: tests/pr-demos
       filename: "{car_id}.spec.ts"
 `
-	if err := os.WriteFile(configPath, []byte(prOpenYAML), 0o600); err != nil {
-		t.Fatalf("rewrite yaml: %v", err)
-	}
-
-	bodyPROpen := buildPRBody(db, &c, "/nonexistent", "main", configPath)
+	bodyPROpen := buildPRBody(db, &c, "/nonexistent", "main", loadYAMLConfig(t, prOpenYAML))
 	if !strings.Contains(bodyPROpen, "## 📹 Playwright Demo") {
 		t.Errorf("PR-open-time render should include section after config update; body=\n%s", bodyPROpen)
 	}
@@ -754,12 +761,12 @@ tracks:
 
 func TestTryCloseEpic_NilDB(t *testing.T) {
 	// Should not panic with nil DB.
-	TryCloseEpic(nil, "epic-001")
+	TryCloseEpic(nil, clock.Real, "epic-001")
 }
 
 func TestTryCloseEpic_EmptyID(t *testing.T) {
 	// Should not panic with empty ID.
-	TryCloseEpic(nil, "")
+	TryCloseEpic(nil, clock.Real, "")
 }
 
 func TestTryCloseEpic_ClosesWhenAllChildrenMerged(t *testing.T) {
@@ -770,7 +777,7 @@ func TestTryCloseEpic_ClosesWhenAllChildrenMerged(t *testing.T) {
 	db.Create(&models.Car{ID: "child-1", Type: "task", Status: "merged", Track: "backend", ParentID: &epicID})
 	db.Create(&models.Car{ID: "child-2", Type: "task", Status: "merged", Track: "backend", ParentID: &epicID})
 
-	TryCloseEpic(db, epicID)
+	TryCloseEpic(db, clock.Real, epicID)
 
 	var epic models.Car
 	db.First(&epic, "id = ?", epicID)
@@ -787,7 +794,7 @@ func TestTryCloseEpic_DoesNotCloseWithPendingChildren(t *testing.T) {
 	db.Create(&models.Car{ID: "child-3", Type: "task", Status: "merged", Track: "backend", ParentID: &epicID})
 	db.Create(&models.Car{ID: "child-4", Type: "task", Status: "open", Track: "backend", ParentID: &epicID})
 
-	TryCloseEpic(db, epicID)
+	TryCloseEpic(db, clock.Real, epicID)
 
 	var epic models.Car
 	db.First(&epic, "id = ?", epicID)
@@ -796,6 +803,23 @@ func TestTryCloseEpic_DoesNotCloseWithPendingChildren(t *testing.T) {
 	}
 }
 
+func TestTryCloseEpic_ClosesWhenChildReverted(t *testing.T) {
+	db := testDB(t)
+
+	epicID := "epic-revert1"
+	db.Create(&models.Car{ID: epicID, Type: "epic", Status: "open", Track: "backend"})
+	db.Create(&models.Car{ID: "child-r1", Type: "task", Status: "merged", Track: "backend", ParentID: &epicID})
+	db.Create(&models.Car{ID: "child-r2", Type: "task", Status: "reverted", Track: "backend", ParentID: &epicID})
+
+	TryCloseEpic(db, clock.Real, epicID)
+
+	var epic models.Car
+	db.First(&epic, "id = ?", epicID)
+	if epic.Status != "done" {
+		t.Errorf("epic status = %q, want %q (reverted child is closed)", epic.Status, "done")
+	}
+}
+
 func TestTryCloseEpic_ClosesBlockedEpicWhenAllChildrenDone(t *testing.T) {
 	// Simulates the bug scenario: epic was blocked, gets unblocked,
 	// but all children are already merged. TryCloseEpic should close it
@@ -808,7 +832,7 @@ func TestTryCloseEpic_ClosesBlockedEpicWhenAllChildrenDone(t *testing.T) {
 	db.Create(&models.Car{ID: "child-6", Type: "task", Status: "merged", Track: "frontend", ParentID: &epicID})
 	db.Create(&models.Car{ID: "child-7", Type: "task", Status: "cancelled", Track: "backend", ParentID: &epicID})
 
-	TryCloseEpic(db, epicID)
+	TryCloseEpic(db, clock.Real, epicID)
 
 	var epic models.Car
 	db.First(&epic, "id = ?", epicID)
@@ -2414,6 +2438,35 @@ func TestUnblockDeps_StalledCar_TransitionsToOpen(t *testing.T) {
 	}
 }
 
+func TestUnblockDeps_CommandPolicyCar_StaysBlocked(t *testing.T) {
+	db := testDB(t)
+
+	db.Create(&models.Car{
+		ID:            "car-policy-a",
+		Status:        "blocked",
+		BlockedReason: models.BlockedReasonCommandPolicy,
+		Track:         "backend",
+	})
+	db.Create(&models.Car{ID: "car-policy-b", Status: "merged", Track: "backend"})
+	db.Create(&models.CarDep{CarID: "car-policy-a", BlockedBy: "car-policy-b"})
+
+	unblocked, err := UnblockDeps(db, "car-policy-b")
+	if err != nil {
+		t.Fatalf("UnblockDeps error: %v", err)
+	}
+	if len(unblocked) != 0 {
+		t.Fatalf("expected no unblocked cars, got %d", len(unblocked))
+	}
+
+	var c models.Car
+	if err := db.First(&c, "id = ?", "car-policy-a").Error; err != nil {
+		t.Fatalf("load car: %v", err)
+	}
+	if c.Status != "blocked" || c.BlockedReason != models.BlockedReasonCommandPolicy {
+		t.Errorf("car = %s/%s, want blocked/%s", c.Status, c.BlockedReason, models.BlockedReasonCommandPolicy)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // RequirePR path tests (using injectable PR hooks)
 // ---------------------------------------------------------------------------
@@ -2687,6 +2740,54 @@ func TestSwitch_RequirePR_ExistingPR_NoLabelWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestSwitch_RequirePR_EarlyDraftFirstReady_NoRevisionLabels(t *testing.T) {
+	// A draft opened while the car was in progress (draft_pr_on_push) goes
+	// ready for the first time at switch: it is not a revision, so the
+	// revised and re-review labels must not be applied.
+	repoDir, _, run := initTestRepoWithRemote(t)
+	db := testDB(t)
+
+	run(repoDir, "git", "checkout", "-b", "ry/backend/car-pr-early")
+	writeFile(t, repoDir, "feature.go", "package main\n// early draft\n")
+	run(repoDir, "git", "add", ".")
+	run(repoDir, "git", "commit", "-m", "feature")
+	run(repoDir, "git", "checkout", "main")
+
+	draftAt := time.Now()
+	db.Create(&models.Car{
+		ID: "car-pr-early", Title: "Early Draft", Track: "backend",
+		Status: "done", Branch: "ry/backend/car-pr-early", DraftPRAt: &draftAt,
+	})
+
+	tracker := &prCallTracker{
+		getExistingURL: "https://github.com/org/repo/pull/12",
+	}
+	push, getEx, createDr, updateBd, markRd, addLb := tracker.hooks()
+
+	_, err := Switch(db, "car-pr-early", SwitchOpts{
+		RepoDir:         repoDir,
+		RequirePR:       true,
+		RevisedLabel:    "railyard: revised",
+		ReReviewLabel:   "inspect: re-review",
+		PushBranchFn:    push,
+		GetExistingPRFn: getEx,
+		CreateDraftPRFn: createDr,
+		UpdatePRBodyFn:  updateBd,
+		MarkPRReadyFn:   markRd,
+		AddPRLabelFn:    addLb,
+	})
+	if err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+
+	if !tracker.updateBodyCalled || !tracker.markReadyCalled {
+		t.Error("expected the draft body to be updated and marked ready")
+	}
+	if tracker.addLabelCalled {
+		t.Errorf("addedLabels = %v, want none on first ready", tracker.addedLabels)
+	}
+}
+
 func TestSwitch_RequirePR_CreateDraftFails(t *testing.T) {
 	repoDir, _, run := initTestRepoWithRemote(t)
 	db := testDB(t)
@@ -2881,3 +2982,38 @@ func TestSwitch_RequirePR_MergedPR_CreatesNewDraft(t *testing.T) {
 		t.Errorf("status = %q, want %q", car.Status, "pr_open")
 	}
 }
+
+func TestSwitch_ReportsProgress(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-pg1")
+	writeFile(t, repoDir, "feature-pg1.txt", "progress")
+	run(repoDir, "git", "add", "feature-pg1.txt")
+	run(repoDir, "git", "commit", "-m", "feature work")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{
+		ID:     "car-pg1",
+		Title:  "Progress test",
+		Track:  "backend",
+		Branch: "ry/alice/backend/car-pg1",
+		Status: "done",
+	})
+
+	var steps []string
+	result, err := Switch(db, "car-pg1", SwitchOpts{
+		RepoDir:     repoDir,
+		TestCommand: "true",
+		Progress:    func(e progress.Event) { steps = append(steps, e.Step) },
+	})
+	if err != nil {
+		t.Fatalf("Switch error: %v", err)
+	}
+	if !result.Merged {
+		t.Fatalf("expected Merged=true; result=%+v", result)
+	}
+	if got := strings.Join(steps, ","); got != "fetch,test,merge,push" {
+		t.Errorf("steps = %s, want fetch,test,merge,push", got)
+	}
+}
@@ -0,0 +1,99 @@
+package yardmaster
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// ensureSwitchWorktree creates a switch worktree; tests replace it.
+var ensureSwitchWorktree = engine.EnsureSwitchWorktree
+
+// switchConcurrency returns how many of a track's done cars may be tested at
+// once (tracks[].switch_concurrency, at least 1).
+func switchConcurrency(cfg *config.Config, track string) int {
+	for _, t := range cfg.Tracks {
+		if t.Name == track && t.SwitchConcurrency > 1 {
+			return t.SwitchConcurrency
+		}
+	}
+	return 1
+}
+
+// runSwitchQueue runs the switch flow for each of a poll's done cars, in
+// queue order within each track. When every track has switch_concurrency 1
+// the cars go through one at a time, exactly as before. Otherwise each
+// track gets its own queue with up to switch_concurrency workers, and
+// tracks run side by side. A worker on a track with a limit above 1 tests
+// its cars in its own switch worktree (.railyard/switch/<track>-<n>), so
+// Switch holds gitMu only for the fetch, merge and push steps; a worker on
+// a track with a limit of 1 tests in the yardmaster worktree. Switch
+// worktrees of a track with its own repo are made from its clone.
+//
+// run is called once per car with the test directory to use, "" for the
+// yardmaster worktree. runSwitchQueue returns when every car is done.
+func runSwitchQueue(cfg *config.Config, repoDir string, cars []models.Car, run func(c models.Car, testDir string)) {
+	queues := make(map[string][]models.Car)
+	var tracks []string
+	parallel := false
+	for _, c := range cars {
+		if _, ok := queues[c.Track]; !ok {
+			tracks = append(tracks, c.Track)
+		}
+		queues[c.Track] = append(queues[c.Track], c)
+		if switchConcurrency(cfg, c.Track) > 1 {
+			parallel = true
+		}
+	}
+	if !parallel {
+		for _, c := range cars {
+			run(c, "")
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, track := range tracks {
+		queue := queues[track]
+		workers := min(switchConcurrency(cfg, track), len(queue))
+		next := make(chan models.Car, len(queue))
+		for _, c := range queue {
+			next <- c
+		}
+		close(next)
+
+		for i := 1; i <= workers; i++ {
+			testDir := ""
+			if switchConcurrency(cfg, track) > 1 {
+				if trackRepoDir, _, err := trackDirs(cfg, repoDir, "", track); err == nil {
+					testDir = switchTestDir(trackRepoDir, fmt.Sprintf("%s-%d", track, i))
+				}
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range next {
+					run(c, testDir)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// switchTestDir returns the switch worktree for slot, or "" (test in the
+// yardmaster worktree) when it cannot be created.
+func switchTestDir(repoDir, slot string) string {
+	gitMu.Lock()
+	defer gitMu.Unlock()
+	dir, err := ensureSwitchWorktree(repoDir, slot)
+	if err != nil {
+		slog.Warn("Switch worktree unavailable, testing in the yardmaster worktree", "slot", slot, "error", err)
+		return ""
+	}
+	return dir
+}
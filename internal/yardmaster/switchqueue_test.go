@@ -0,0 +1,152 @@
+package yardmaster
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func queueCars(track string, n int) []models.Car {
+	var cars []models.Car
+	for i := 1; i <= n; i++ {
+		cars = append(cars, models.Car{ID: fmt.Sprintf("%s-%d", track, i), Track: track})
+	}
+	return cars
+}
+
+func TestRunSwitchQueue_SequentialByDefault(t *testing.T) {
+	cfg := &config.Config{Tracks: []config.TrackConfig{{Name: "backend"}, {Name: "frontend", SwitchConcurrency: 1}}}
+	cars := append(queueCars("backend", 2), queueCars("frontend", 2)...)
+	cars[1], cars[2] = cars[2], cars[1]
+
+	var got []string
+	runSwitchQueue(cfg, t.TempDir(), cars, func(c models.Car, testDir string) {
+		if testDir != "" {
+			t.Errorf("car %s tested in %q, want the yardmaster worktree", c.ID, testDir)
+		}
+		got = append(got, c.ID)
+	})
+	want := []string{"backend-1", "frontend-1", "backend-2", "frontend-2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestRunSwitchQueue_PerTrackConcurrency(t *testing.T) {
+	orig := ensureSwitchWorktree
+	ensureSwitchWorktree = func(repoDir, slot string) (string, error) { return "/switch/" + slot, nil }
+	t.Cleanup(func() { ensureSwitchWorktree = orig })
+
+	cfg := &config.Config{Tracks: []config.TrackConfig{{Name: "backend", SwitchConcurrency: 2}, {Name: "frontend"}}}
+	cars := append(queueCars("backend", 4), queueCars("frontend", 2)...)
+
+	var mu sync.Mutex
+	inFlight := map[string]int{}
+	peak := map[string]int{}
+	dirs := map[string]string{}
+	runSwitchQueue(cfg, t.TempDir(), cars, func(c models.Car, testDir string) {
+		mu.Lock()
+		inFlight[c.Track]++
+		peak[c.Track] = max(peak[c.Track], inFlight[c.Track])
+		dirs[c.ID] = testDir
+		mu.Unlock()
+
+		// Hold the first backend car until a second one is running.
+		deadline := time.Now().Add(2 * time.Second)
+		for c.ID == "backend-1" && time.Now().Before(deadline) {
+			mu.Lock()
+			n := peak["backend"]
+			mu.Unlock()
+			if n >= 2 {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		mu.Lock()
+		inFlight[c.Track]--
+		mu.Unlock()
+	})
+
+	if peak["backend"] != 2 || peak["frontend"] != 1 {
+		t.Errorf("peak concurrency = %v, want backend 2 and frontend 1", peak)
+	}
+	if len(dirs) != 6 {
+		t.Fatalf("ran %d cars, want 6", len(dirs))
+	}
+	for id, dir := range dirs {
+		switch {
+		case strings.HasPrefix(id, "frontend") && dir != "":
+			t.Errorf("%s tested in %q, want the yardmaster worktree", id, dir)
+		case strings.HasPrefix(id, "backend") && dir != "/switch/backend-1" && dir != "/switch/backend-2":
+			t.Errorf("%s tested in %q, want a backend switch worktree", id, dir)
+		}
+	}
+}
+
+func TestSwitch_TestDirReleasesGitMuDuringTests(t *testing.T) {
+	repoDir, bareDir, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-pq1")
+	writeFile(t, repoDir, "feature-pq1.txt", "queued feature")
+	run(repoDir, "git", "add", "feature-pq1.txt")
+	run(repoDir, "git", "commit", "-m", "queued feature")
+	run(repoDir, "git", "checkout", "main")
+
+	testDir, err := engine.EnsureSwitchWorktree(repoDir, "backend-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-pq1", Track: "backend", Branch: "ry/alice/backend/car-pq1", Status: "done"})
+
+	started := filepath.Join(t.TempDir(), "started")
+	release := filepath.Join(t.TempDir(), "release")
+	type outcome struct {
+		result *SwitchResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := Switch(db, "car-pq1", SwitchOpts{
+			RepoDir:     repoDir,
+			TestDir:     testDir,
+			TestCommand: fmt.Sprintf("touch %s; while [ ! -f %s ]; do sleep 0.05; done; test -f feature-pq1.txt", started, release),
+		})
+		done <- outcome{result, err}
+	}()
+
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("tests never started")
+		}
+	}
+	if !gitMu.TryLock() {
+		t.Error("gitMu held while tests run in a separate worktree")
+	} else {
+		gitMu.Unlock()
+	}
+	os.WriteFile(release, nil, 0o644)
+
+	out := <-done
+	if out.err != nil || !out.result.TestsPassed || !out.result.Merged {
+		t.Fatalf("result = %+v, err = %v", out.result, out.err)
+	}
+	cmd := exec.Command("git", "log", "--oneline", "main")
+	cmd.Dir = bareDir
+	if log, _ := cmd.CombinedOutput(); !strings.Contains(string(log), "Switch: merge") {
+		t.Errorf("remote missing merge commit, got: %s", log)
+	}
+}
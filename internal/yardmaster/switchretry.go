@@ -0,0 +1,111 @@
+package yardmaster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// transientSwitchFailure reports whether a merge-gate failure is worth
+// retrying unchanged: the test environment or the remote failed, not the
+// car's code.
+func transientSwitchFailure(cat SwitchFailureCategory) bool {
+	return cat == SwitchFailInfra || cat == SwitchFailPush
+}
+
+// switchRetryDelay is the wait before automatic retry number attempt
+// (1-based): stall.switch_retry_base_sec, doubled for each earlier attempt
+// and capped at stall.switch_retry_max_sec.
+func switchRetryDelay(stall config.StallConfig, attempt int) time.Duration {
+	delay := time.Duration(stall.SwitchRetryBaseSec) * time.Second
+	limit := time.Duration(stall.SwitchRetryMaxSec) * time.Second
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// retryTransientSwitchFailure handles an infra or push failure of the merge
+// gate. While the car has automatic retries left, it stays done and
+// re-enters the merge gate after a backoff. Once they run out, telegraph is
+// paged: a push failure then escalates at once, and an infra failure takes
+// the usual path (merge-failed and a merge hold), for which the function
+// reports false. It also reports false for other categories and when
+// stall.switch_retry_max disables retries.
+func retryTransientSwitchFailure(ctx context.Context, db *gorm.DB, clk clock.Clock, cfg *config.Config, c *models.Car, cat SwitchFailureCategory, switchErr error, hint string, escWg *sync.WaitGroup, escTracker *EscalationTracker, escSem chan struct{}, logger *slog.Logger, bus events.Bus) bool {
+	maxRetries := cfg.Stall.SwitchRetryMax
+	if !transientSwitchFailure(cat) || maxRetries <= 0 {
+		return false
+	}
+
+	attempt := c.SwitchAttempts + 1
+	if attempt <= maxRetries {
+		delay := switchRetryDelay(cfg.Stall, attempt)
+		retryAt := clk.Now().Add(delay)
+		if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+			"status":          "done",
+			"blocked_reason":  "",
+			"switch_failure":  string(cat),
+			"switch_attempts": attempt,
+			"switch_retry_at": retryAt,
+		}).Error; err != nil {
+			logger.Error("Schedule merge retry", "car", c.ID, "error", err)
+		}
+		// Not a "switch:" note: the failure itself was recorded by the caller.
+		writeProgressNote(db, clk, c.ID, YardmasterID, fmt.Sprintf("%s; retrying the merge in %s (retry %d of %d)", cat, delay, attempt, maxRetries))
+		logger.Info("Car merge failed, retry scheduled", "car", c.ID, "category", cat, "attempt", attempt, "max_retries", maxRetries, "retry_in", delay)
+		return true
+	}
+
+	// Retries are used up. Reset the count so a manual retry of the car
+	// starts with a fresh budget; the category stays for ry car show.
+	if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+		"switch_failure":  string(cat),
+		"switch_attempts": 0,
+		"switch_retry_at": nil,
+	}).Error; err != nil {
+		logger.Error("Reset merge retries", "car", c.ID, "error", err)
+	}
+	logger.Warn("Car merge retries exhausted", "car", c.ID, "category", cat, "failures", attempt)
+	body := fmt.Sprintf("Car %s (%s) failed the merge gate %d times in a row (%s): %v. Automatic retries have stopped and the car needs a human.",
+		c.ID, c.Title, attempt, cat, switchErr)
+	if hint != "" {
+		body += "\n\n" + hint
+	}
+	if _, err := messaging.Send(db, YardmasterID, "telegraph", "merge-retries-exhausted", body,
+		messaging.SendOpts{CarID: c.ID, Priority: "urgent"}); err != nil {
+		logger.Error("Page merge retries exhausted", "car", c.ID, "error", err)
+	}
+	if cat == SwitchFailInfra {
+		return false
+	}
+	escalateSwitchFailure(ctx, db, clk, cfg, c.ID, cat, switchErr, "", attempt, escWg, escTracker, escSem, logger, bus)
+	return true
+}
+
+// clearSwitchRetry forgets a car's transient merge-gate failures once the
+// merge gate gets past them.
+func clearSwitchRetry(db *gorm.DB, c *models.Car, logger *slog.Logger) {
+	if c.SwitchFailure == "" && c.SwitchAttempts == 0 && c.SwitchRetryAt == nil {
+		return
+	}
+	if err := db.Model(&models.Car{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+		"switch_failure":  "",
+		"switch_attempts": 0,
+		"switch_retry_at": nil,
+	}).Error; err != nil {
+		logger.Error("Clear merge retries", "car", c.ID, "error", err)
+	}
+}
@@ -0,0 +1,148 @@
+package yardmaster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestSwitchRetryDelay(t *testing.T) {
+	stall := config.StallConfig{SwitchRetryBaseSec: 60, SwitchRetryMaxSec: 300}
+	for attempt, want := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		3: 4 * time.Minute,
+		4: 5 * time.Minute,
+		9: 5 * time.Minute,
+	} {
+		if got := switchRetryDelay(stall, attempt); got != want {
+			t.Errorf("switchRetryDelay(attempt %d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func retryTestConfig() *config.Config {
+	cfg := testConfig(config.TrackConfig{Name: "backend", Language: "go"})
+	cfg.Stall.SwitchRetryMax = 2
+	cfg.Stall.SwitchRetryBaseSec = 60
+	cfg.Stall.SwitchRetryMaxSec = 1800
+	return cfg
+}
+
+func TestRetryTransientSwitchFailure_SchedulesBackoff(t *testing.T) {
+	db := testDB(t)
+	c := models.Car{ID: "car-rt1", Title: "Push it", Track: "backend", Status: "done", SwitchAttempts: 1}
+	db.Create(&c)
+
+	var buf bytes.Buffer
+	start := time.Now()
+	if !retryTransientSwitchFailure(context.Background(), db, clock.Real, retryTestConfig(), &c, SwitchFailPush, errors.New("push after merge: rejected"), "",
+		&sync.WaitGroup{}, nil, make(chan struct{}, 3), testLogger(&buf), nil) {
+		t.Fatal("push failure with retries left was not retried")
+	}
+
+	var got models.Car
+	db.First(&got, "id = ?", "car-rt1")
+	if got.Status != "done" || got.SwitchAttempts != 2 || got.SwitchFailure != string(SwitchFailPush) {
+		t.Errorf("car = status %q, attempts %d, failure %q; want done, 2, push-failed", got.Status, got.SwitchAttempts, got.SwitchFailure)
+	}
+	if got.SwitchRetryAt == nil || got.SwitchRetryAt.Before(start.Add(2*time.Minute)) || got.SwitchRetryAt.After(time.Now().Add(2*time.Minute)) {
+		t.Errorf("retry at = %v, want two minutes from now", got.SwitchRetryAt)
+	}
+	var note models.CarProgress
+	db.Where("car_id = ?", "car-rt1").Last(&note)
+	if !strings.Contains(note.Note, "retry 2 of 2") || strings.HasPrefix(note.Note, "switch:") {
+		t.Errorf("progress note = %q, want a retry note that is not a switch failure", note.Note)
+	}
+}
+
+func TestRetryTransientSwitchFailure_InfraExhaustedPagesTelegraph(t *testing.T) {
+	db := testDB(t)
+	retryAt := time.Now()
+	c := models.Car{ID: "car-rt2", Title: "Infra", Track: "backend", Status: "done", SwitchAttempts: 2, SwitchRetryAt: &retryAt}
+	db.Create(&c)
+
+	var buf bytes.Buffer
+	if retryTransientSwitchFailure(context.Background(), db, clock.Real, retryTestConfig(), &c, SwitchFailInfra, errors.New("tests failed: exit status 127"), "Install the missing tool.",
+		&sync.WaitGroup{}, nil, make(chan struct{}, 3), testLogger(&buf), nil) {
+		t.Fatal("infra failure with retries used up should fall through to the merge hold")
+	}
+
+	var got models.Car
+	db.First(&got, "id = ?", "car-rt2")
+	if got.SwitchAttempts != 0 || got.SwitchRetryAt != nil || got.SwitchFailure != string(SwitchFailInfra) {
+		t.Errorf("car = attempts %d, retry at %v, failure %q; want the count reset and the category kept", got.SwitchAttempts, got.SwitchRetryAt, got.SwitchFailure)
+	}
+	var page models.Message
+	if err := db.Where("to_agent = ? AND subject = ?", "telegraph", "merge-retries-exhausted").First(&page).Error; err != nil {
+		t.Fatalf("no telegraph page: %v", err)
+	}
+	if !strings.Contains(page.Body, "3 times") || !strings.Contains(page.Body, "Install the missing tool.") {
+		t.Errorf("page = %q, want the failure count and hint", page.Body)
+	}
+}
+
+func TestRetryTransientSwitchFailure_PushExhaustedEscalates(t *testing.T) {
+	db := testDB(t)
+	c := models.Car{ID: "car-rt3", Title: "Push", Track: "backend", Status: "done", SwitchAttempts: 2}
+	db.Create(&c)
+
+	var buf bytes.Buffer
+	var wg sync.WaitGroup
+	if !retryTransientSwitchFailure(context.Background(), db, clock.Real, retryTestConfig(), &c, SwitchFailPush, errors.New("push after merge: rejected"), "",
+		&wg, nil, make(chan struct{}, 3), testLogger(&buf), nil) {
+		t.Fatal("exhausted push failure should be handled")
+	}
+	wg.Wait()
+
+	var got models.Car
+	db.First(&got, "id = ?", "car-rt3")
+	if got.Status != "merge-failed" {
+		t.Errorf("status = %q, want merge-failed", got.Status)
+	}
+	if !strings.Contains(buf.String(), "repeated-push-failure") {
+		t.Errorf("log should show the push escalation, got: %s", buf.String())
+	}
+}
+
+func TestRetryTransientSwitchFailure_NotRetried(t *testing.T) {
+	db := testDB(t)
+	c := models.Car{ID: "car-rt4", Track: "backend", Status: "done"}
+	db.Create(&c)
+	var buf bytes.Buffer
+
+	if retryTransientSwitchFailure(context.Background(), db, clock.Real, retryTestConfig(), &c, SwitchFailMerge, errors.New("conflict"), "",
+		&sync.WaitGroup{}, nil, make(chan struct{}, 3), testLogger(&buf), nil) {
+		t.Error("merge conflicts are not transient")
+	}
+	cfg := retryTestConfig()
+	cfg.Stall.SwitchRetryMax = -1
+	if retryTransientSwitchFailure(context.Background(), db, clock.Real, cfg, &c, SwitchFailPush, errors.New("rejected"), "",
+		&sync.WaitGroup{}, nil, make(chan struct{}, 3), testLogger(&buf), nil) {
+		t.Error("switch_retry_max -1 should disable retries")
+	}
+}
+
+func TestClearSwitchRetry(t *testing.T) {
+	db := testDB(t)
+	retryAt := time.Now()
+	c := models.Car{ID: "car-rt5", Track: "backend", SwitchFailure: string(SwitchFailPush), SwitchAttempts: 3, SwitchRetryAt: &retryAt}
+	db.Create(&c)
+
+	var buf bytes.Buffer
+	clearSwitchRetry(db, &c, testLogger(&buf))
+
+	var got models.Car
+	db.First(&got, "id = ?", "car-rt5")
+	if got.SwitchFailure != "" || got.SwitchAttempts != 0 || got.SwitchRetryAt != nil {
+		t.Errorf("car = failure %q, attempts %d, retry at %v; want cleared", got.SwitchFailure, got.SwitchAttempts, got.SwitchRetryAt)
+	}
+}
@@ -0,0 +1,119 @@
+package yardmaster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// TestCellResult is the outcome of one test matrix cell at the merge gate.
+type TestCellResult struct {
+	Name   string // cell name; empty for a plain test_command
+	Passed bool
+	Output string
+	Err    error // nil when Passed
+}
+
+// runTestCell runs one cell's command in repoDir with the cell's extra
+// environment. Output matching a no-test pattern counts as a pass. Output is
+// also copied to live as it is produced when live is non-nil.
+func runTestCell(ctx context.Context, repoDir string, cell config.TestMatrixCell, live io.Writer) TestCellResult {
+	slog.Debug("runTests: executing test command", "cell", cell.Name, "command", cell.Command)
+	cmd := exec.CommandContext(ctx, "sh", "-c", cell.Command)
+	cmd.Dir = repoDir
+	if len(cell.Env) > 0 {
+		keys := make([]string, 0, len(cell.Env))
+		for k := range cell.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		cmd.Env = os.Environ()
+		for _, k := range keys {
+			cmd.Env = append(cmd.Env, k+"="+cell.Env[k])
+		}
+	}
+
+	out, err := combinedOutput(cmd, live)
+	res := TestCellResult{Name: cell.Name, Output: string(out), Passed: err == nil, Err: err}
+	if err != nil {
+		// Check for "no tests" patterns — treat as pass.
+		for _, pat := range noTestPatterns {
+			if strings.Contains(res.Output, pat) {
+				slog.Debug("runTests: no-test pattern matched, treating as pass", "cell", cell.Name, "pattern", pat)
+				res.Passed, res.Err = true, nil
+				break
+			}
+		}
+	}
+	return res
+}
+
+// combinedOutput is cmd.CombinedOutput that also copies the output to live,
+// when non-nil, as the command runs.
+func combinedOutput(cmd *exec.Cmd, live io.Writer) ([]byte, error) {
+	if live == nil {
+		return cmd.CombinedOutput()
+	}
+	var buf bytes.Buffer
+	w := io.MultiWriter(&buf, live)
+	cmd.Stdout, cmd.Stderr = w, w
+	err := cmd.Run()
+	return buf.Bytes(), err
+}
+
+// formatMatrixOutput joins cell outputs under a PASS/FAIL header per cell.
+func formatMatrixOutput(results []TestCellResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		verdict := "PASS"
+		if !r.Passed {
+			verdict = "FAIL"
+		}
+		fmt.Fprintf(&b, "=== matrix cell %s: %s ===\n", r.Name, verdict)
+		b.WriteString(r.Output)
+		if r.Output != "" && !strings.HasSuffix(r.Output, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// classifyMatrixFailure categorizes a failed matrix run: a code failure in
+// any cell wins over infrastructure failures in others. With no failed cell
+// (e.g. checkout failed or the run timed out) it falls back to classifying
+// the overall error and output. Learned patterns apply to each cell.
+func classifyMatrixFailure(learned *FailurePatterns, results []TestCellResult, err error, output string) SwitchFailureCategory {
+	category := SwitchFailNone
+	for _, r := range results {
+		if r.Passed {
+			continue
+		}
+		if learned.classify(r.Err, r.Output) == SwitchFailTest {
+			return SwitchFailTest
+		}
+		category = SwitchFailInfra
+	}
+	if category == SwitchFailNone {
+		return learned.classify(err, output)
+	}
+	return category
+}
+
+// failedCellNames returns the names of the failed matrix cells.
+func failedCellNames(results []TestCellResult) []string {
+	var names []string
+	for _, r := range results {
+		if !r.Passed {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,136 @@
+package yardmaster
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestRunTestMatrix_AggregatesCells(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("parallel=%v", parallel), func(t *testing.T) {
+			repoDir, run := initTestRepo(t)
+			run("git", "checkout", "-b", "feature")
+			run("git", "checkout", "main")
+
+			cells := []config.TestMatrixCell{
+				{Name: "go1.22", Command: `echo "running $GO_VERSION"`, Env: map[string]string{"GO_VERSION": "1.22"}},
+				{Name: "go1.23", Command: `echo "broken on $GO_VERSION"; exit 1`, Env: map[string]string{"GO_VERSION": "1.23"}},
+				{Name: "lint", Command: "echo lint ok"},
+			}
+			output, results, err := runTestMatrix(context.Background(), repoDir, "feature", "main", "", cells, parallel, nil)
+			if err == nil {
+				t.Fatal("expected matrix failure")
+			}
+			if !strings.Contains(err.Error(), "matrix cell(s) go1.23:") {
+				t.Errorf("err = %v, want failed cell named", err)
+			}
+			if got := failedCellNames(results); !slices.Equal(got, []string{"go1.23"}) {
+				t.Errorf("failed cells = %v", got)
+			}
+			for _, want := range []string{
+				"=== matrix cell go1.22: PASS ===\nrunning 1.22\n",
+				"=== matrix cell go1.23: FAIL ===\nbroken on 1.23\n",
+				"=== matrix cell lint: PASS ===\nlint ok\n",
+			} {
+				if !strings.Contains(output, want) {
+					t.Errorf("output missing %q:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTestMatrix_AllPass(t *testing.T) {
+	repoDir, run := initTestRepo(t)
+	run("git", "checkout", "-b", "feature")
+	run("git", "checkout", "main")
+
+	cells := []config.TestMatrixCell{{Name: "a", Command: "true"}, {Name: "b", Command: "true"}}
+	if _, _, err := runTestMatrix(context.Background(), repoDir, "feature", "main", "", cells, true, nil); err != nil {
+		t.Fatalf("runTestMatrix: %v", err)
+	}
+}
+
+func TestClassifyMatrixFailure(t *testing.T) {
+	codeErr := exec.Command("sh", "-c", "exit 1").Run()
+	missingErr := exec.Command("sh", "-c", "exit 127").Run()
+
+	tests := []struct {
+		name    string
+		results []TestCellResult
+		want    SwitchFailureCategory
+	}{
+		{"code failure wins", []TestCellResult{
+			{Name: "a", Err: missingErr},
+			{Name: "b", Err: codeErr, Output: "--- FAIL: TestX"},
+		}, SwitchFailTest},
+		{"all infra", []TestCellResult{
+			{Name: "a", Err: missingErr},
+			{Name: "b", Passed: true},
+		}, SwitchFailInfra},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyMatrixFailure(nil, tt.results, fmt.Errorf("tests failed"), ""); got != tt.want {
+				t.Errorf("classifyMatrixFailure = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitch_TestMatrixFailureReportsCell(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-mx1")
+	writeFile(t, repoDir, "feature-mx1.txt", "matrix feature")
+	run(repoDir, "git", "add", "feature-mx1.txt")
+	run(repoDir, "git", "commit", "-m", "feature work")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{
+		ID:     "car-mx1",
+		Title:  "Matrix test",
+		Track:  "backend",
+		Branch: "ry/alice/backend/car-mx1",
+		Status: "done",
+	})
+
+	result, err := Switch(db, "car-mx1", SwitchOpts{
+		RepoDir:     repoDir,
+		TestCommand: "true", // ignored when a matrix is configured
+		TestMatrix: []config.TestMatrixCell{
+			{Name: "node18", Command: "true"},
+			{Name: "node20", Command: "echo '--- FAIL: TestWidget'; exit 1"},
+		},
+		TestMatrixParallel: true,
+	})
+	if err != nil {
+		t.Fatalf("Switch returned error: %v", err)
+	}
+	if result.TestsPassed || result.Merged {
+		t.Fatalf("result = %+v, want failed tests and no merge", result)
+	}
+	if result.FailureCategory != SwitchFailTest {
+		t.Errorf("FailureCategory = %q, want %q", result.FailureCategory, SwitchFailTest)
+	}
+	if !slices.Equal(result.FailedCells, []string{"node20"}) {
+		t.Errorf("FailedCells = %v, want [node20]", result.FailedCells)
+	}
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "node20") {
+		t.Errorf("Error = %v, want failed cell in details", result.Error)
+	}
+
+	var c models.Car
+	db.First(&c, "id = ?", "car-mx1")
+	if c.Status != "blocked" {
+		t.Errorf("status = %q, want blocked", c.Status)
+	}
+}
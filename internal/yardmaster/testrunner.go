@@ -0,0 +1,379 @@
+package yardmaster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// ErrTestRunnerUnavailable marks a remote test run that failed for reasons
+// outside the code under test (unreachable host, rejected job, CI error).
+// classifyTestFailure treats it as an infrastructure failure.
+var ErrTestRunnerUnavailable = errors.New("test runner unavailable")
+
+// TestJob describes one merge-gate test run for a remote runner.
+type TestJob struct {
+	CarID          string
+	Branch         string
+	BaseBranch     string
+	PreTestCommand string
+	Cells          []config.TestMatrixCell
+	Parallel       bool
+}
+
+// TestRunner runs merge-gate tests away from the yardmaster worktree. Run
+// returns the same results as runTestMatrix: the aggregated output, per-cell
+// results, and an error naming what failed.
+type TestRunner interface {
+	Run(ctx context.Context, job TestJob) (string, []TestCellResult, error)
+}
+
+// NewTestRunner returns the runner for a track's test_runner block, or nil
+// when tests run locally.
+func NewTestRunner(cfg *config.TestRunnerConfig) TestRunner {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.Type {
+	case config.TestRunnerSSH:
+		return &sshTestRunner{cfg: *cfg, sshBin: "ssh"}
+	case config.TestRunnerCI:
+		interval := time.Duration(cfg.PollIntervalSec) * time.Second
+		if interval <= 0 {
+			interval = config.DefaultTestRunnerPollSec * time.Second
+		}
+		return &ciTestRunner{cfg: *cfg, client: http.DefaultClient, interval: interval}
+	default:
+		return nil
+	}
+}
+
+// jobOutput aggregates cell results the way runTestMatrix does, and builds
+// the error for a failed run.
+func jobOutput(job TestJob, results []TestCellResult) (string, error) {
+	matrix := len(results) > 1 || (len(results) == 1 && results[0].Name != "")
+	output := ""
+	if len(results) > 0 {
+		output = results[0].Output
+	}
+	if matrix {
+		output = formatMatrixOutput(results)
+	}
+	failed := failedCellNames(results)
+	if len(failed) == 0 {
+		return output, nil
+	}
+	var firstErr error
+	for _, r := range results {
+		if !r.Passed {
+			firstErr = r.Err
+			break
+		}
+	}
+	if matrix {
+		return output, fmt.Errorf("tests failed in matrix cell(s) %s: %w", strings.Join(failed, ", "), firstErr)
+	}
+	return output, fmt.Errorf("tests failed: %w", firstErr)
+}
+
+// --- ssh ---
+
+// sshTestRunner runs tests in a dedicated clone on a remote host. Each step
+// is one ssh invocation: checkout, pre-test, then one per cell.
+type sshTestRunner struct {
+	cfg    config.TestRunnerConfig
+	sshBin string // "ssh"; overridden in tests
+}
+
+// sshUnreachable is ssh's own exit status for connection and auth failures.
+const sshUnreachable = 255
+
+func (r *sshTestRunner) Run(ctx context.Context, job TestJob) (string, []TestCellResult, error) {
+	checkout := fmt.Sprintf("cd %s && git fetch --quiet origin && git checkout --quiet --force --detach origin/%s",
+		shellQuote(r.cfg.WorkDir), shellQuote(job.Branch))
+	if out, err := r.ssh(ctx, checkout); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return out, nil, fmt.Errorf("switch timeout exceeded")
+		}
+		return out, nil, fmt.Errorf("%w: checkout %s on %s: %v", ErrTestRunnerUnavailable, job.Branch, r.cfg.Host, err)
+	}
+	slog.Debug("runTests: remote checkout done", "host", r.cfg.Host, "branch", job.Branch)
+
+	if job.PreTestCommand != "" {
+		if out, err := r.ssh(ctx, "cd "+shellQuote(r.cfg.WorkDir)+" && "+job.PreTestCommand); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return out, nil, fmt.Errorf("switch timeout exceeded during pre-test command")
+			}
+			if isSSHUnreachable(err) {
+				return out, nil, fmt.Errorf("%w: %s: %v", ErrTestRunnerUnavailable, r.cfg.Host, err)
+			}
+			return out, nil, fmt.Errorf("pre-test command failed: %w", err)
+		}
+	}
+
+	results := make([]TestCellResult, len(job.Cells))
+	runCell := func(i int) {
+		cell := job.Cells[i]
+		command := cell.Command
+		if len(cell.Env) > 0 {
+			command = envPrefix(cell.Env) + "sh -c " + shellQuote(cell.Command)
+		}
+		out, err := r.ssh(ctx, "cd "+shellQuote(r.cfg.WorkDir)+" && "+command)
+		res := TestCellResult{Name: cell.Name, Output: out, Passed: err == nil, Err: err}
+		if err != nil {
+			if isSSHUnreachable(err) {
+				res.Err = fmt.Errorf("%w: %s: %v", ErrTestRunnerUnavailable, r.cfg.Host, err)
+			} else {
+				for _, pat := range noTestPatterns {
+					if strings.Contains(out, pat) {
+						res.Passed, res.Err = true, nil
+						break
+					}
+				}
+			}
+		}
+		results[i] = res
+	}
+	if job.Parallel && len(job.Cells) > 1 {
+		var wg sync.WaitGroup
+		for i := range job.Cells {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				runCell(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range job.Cells {
+			runCell(i)
+		}
+	}
+
+	output, err := jobOutput(job, results)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return output, results, fmt.Errorf("switch timeout exceeded")
+	}
+	return output, results, err
+}
+
+func (r *sshTestRunner) ssh(ctx context.Context, script string) (string, error) {
+	args := []string{"-o", "BatchMode=yes"}
+	if r.cfg.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(r.cfg.Port))
+	}
+	if r.cfg.IdentityFile != "" {
+		args = append(args, "-i", r.cfg.IdentityFile)
+	}
+	args = append(args, r.cfg.Host, script)
+	out, err := exec.CommandContext(ctx, r.sshBin, args...).CombinedOutput()
+	return string(out), err
+}
+
+func isSSHUnreachable(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == sshUnreachable
+}
+
+// envPrefix renders env as an "env K=V ... " prefix for a remote shell,
+// sorted for stable commands.
+func envPrefix(env map[string]string) string {
+	if len(env) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("env ")
+	for _, k := range keys {
+		b.WriteString(shellQuote(k + "=" + env[k]))
+		b.WriteString(" ")
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// --- ci ---
+
+// ciTestRunner triggers a test job over HTTP and polls it to completion.
+//
+// The trigger is a POST of ciJobRequest to trigger_url, answered with
+// {"id": "...", "status_url": "..."} (status_url defaults to
+// trigger_url/<id>). The status URL answers GET with ciJobStatus; status is
+// one of queued, running, passed, failed, or error. log is the job log so
+// far and cells optionally reports per-cell results.
+type ciTestRunner struct {
+	cfg      config.TestRunnerConfig
+	client   *http.Client
+	interval time.Duration // between status polls
+}
+
+type ciJobRequest struct {
+	CarID          string                  `json:"car_id"`
+	Branch         string                  `json:"branch"`
+	BaseBranch     string                  `json:"base_branch"`
+	PreTestCommand string                  `json:"pre_test_command,omitempty"`
+	Cells          []config.TestMatrixCell `json:"cells"`
+	Parallel       bool                    `json:"parallel"`
+}
+
+type ciJobStatus struct {
+	Status string `json:"status"`
+	Log    string `json:"log"`
+	Cells  []struct {
+		Name   string `json:"name"`
+		Passed bool   `json:"passed"`
+		Log    string `json:"log"`
+	} `json:"cells"`
+}
+
+func (r *ciTestRunner) Run(ctx context.Context, job TestJob) (string, []TestCellResult, error) {
+	statusURL, jobID, err := r.trigger(ctx, job)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrTestRunnerUnavailable, err)
+	}
+	slog.Info("Switch: remote test job started", "car", job.CarID, "job", jobID)
+
+	logged := 0
+	for {
+		st, err := r.status(ctx, statusURL)
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return "", nil, fmt.Errorf("switch timeout exceeded")
+			}
+			return "", nil, fmt.Errorf("%w: job %s: %v", ErrTestRunnerUnavailable, jobID, err)
+		}
+		// Stream new log lines as they arrive.
+		if len(st.Log) > logged {
+			slog.Debug("runTests: remote log", "job", jobID, "log", st.Log[logged:])
+			logged = len(st.Log)
+		}
+
+		switch st.Status {
+		case "passed", "failed":
+			return ciResults(job, jobID, st)
+		case "error":
+			return st.Log, nil, fmt.Errorf("%w: job %s errored", ErrTestRunnerUnavailable, jobID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return st.Log, nil, fmt.Errorf("switch timeout exceeded")
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// ciResults converts a finished job status into cell results.
+func ciResults(job TestJob, jobID string, st *ciJobStatus) (string, []TestCellResult, error) {
+	if len(st.Cells) == 0 {
+		res := TestCellResult{Passed: st.Status == "passed", Output: st.Log}
+		if len(job.Cells) == 1 {
+			res.Name = job.Cells[0].Name
+		}
+		if !res.Passed {
+			res.Err = fmt.Errorf("remote job %s failed", jobID)
+		}
+		output, err := jobOutput(job, []TestCellResult{res})
+		return output, []TestCellResult{res}, err
+	}
+	results := make([]TestCellResult, len(st.Cells))
+	for i, c := range st.Cells {
+		results[i] = TestCellResult{Name: c.Name, Passed: c.Passed, Output: c.Log}
+		if !c.Passed {
+			results[i].Err = fmt.Errorf("remote job %s: cell %s failed", jobID, c.Name)
+		}
+	}
+	output, err := jobOutput(job, results)
+	return output, results, err
+}
+
+func (r *ciTestRunner) trigger(ctx context.Context, job TestJob) (statusURL, jobID string, err error) {
+	body, err := json.Marshal(ciJobRequest{
+		CarID:          job.CarID,
+		Branch:         job.Branch,
+		BaseBranch:     job.BaseBranch,
+		PreTestCommand: job.PreTestCommand,
+		Cells:          job.Cells,
+		Parallel:       job.Parallel,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.TriggerURL, bytes.NewReader(body))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	r.authorize(req)
+
+	var resp struct {
+		ID        string `json:"id"`
+		StatusURL string `json:"status_url"`
+	}
+	if err := r.do(req, &resp); err != nil {
+		return "", "", fmt.Errorf("trigger: %w", err)
+	}
+	if resp.StatusURL == "" {
+		if resp.ID == "" {
+			return "", "", fmt.Errorf("trigger: response has neither id nor status_url")
+		}
+		resp.StatusURL = strings.TrimSuffix(r.cfg.TriggerURL, "/") + "/" + resp.ID
+	}
+	return resp.StatusURL, resp.ID, nil
+}
+
+func (r *ciTestRunner) status(ctx context.Context, statusURL string) (*ciJobStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.authorize(req)
+	var st ciJobStatus
+	if err := r.do(req, &st); err != nil {
+		return nil, fmt.Errorf("status: %w", err)
+	}
+	return &st, nil
+}
+
+func (r *ciTestRunner) authorize(req *http.Request) {
+	if r.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.Token)
+	}
+}
+
+func (r *ciTestRunner) do(req *http.Request, v any) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: HTTP %d: %s", req.Method, req.URL, resp.StatusCode, truncateOutput(string(data), 200))
+	}
+	return json.Unmarshal(data, v)
+}
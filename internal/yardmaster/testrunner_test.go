@@ -0,0 +1,239 @@
+package yardmaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// fakeSSH writes an ssh stand-in that ignores options and host and runs the
+// remote command locally, or exits with exitCode when non-zero.
+func fakeSSH(t *testing.T, exitCode int) string {
+	t.Helper()
+	script := "#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\"\n"
+	if exitCode != 0 {
+		script = "#!/bin/sh\necho 'ssh: connect to host build-01 port 22: Connection timed out' >&2\nexit 255\n"
+	}
+	path := filepath.Join(t.TempDir(), "ssh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ssh: %v", err)
+	}
+	return path
+}
+
+func TestSSHTestRunner_RunsCellsInRemoteClone(t *testing.T) {
+	repoDir, bareDir, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "feature")
+	writeFile(t, repoDir, "marker.txt", "from feature")
+	run(repoDir, "git", "add", "marker.txt")
+	run(repoDir, "git", "commit", "-m", "feature work")
+	run(repoDir, "git", "push", "origin", "feature")
+
+	workDir := filepath.Join(t.TempDir(), "remote")
+	run(t.TempDir(), "git", "clone", bareDir, workDir)
+
+	r := &sshTestRunner{
+		cfg:    config.TestRunnerConfig{Type: config.TestRunnerSSH, Host: "build-01", WorkDir: workDir},
+		sshBin: fakeSSH(t, 0),
+	}
+	output, results, err := r.Run(context.Background(), TestJob{
+		Branch:         "feature",
+		PreTestCommand: "touch prepared",
+		Cells: []config.TestMatrixCell{
+			{Name: "marker", Command: "test -f prepared && cat marker.txt"},
+			{Name: "env", Command: `echo "mode=$MODE"; exit 1`, Env: map[string]string{"MODE": "it's race"}},
+		},
+		Parallel: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "matrix cell(s) env:") {
+		t.Fatalf("err = %v, want env cell failure", err)
+	}
+	if got := failedCellNames(results); !slices.Equal(got, []string{"env"}) {
+		t.Errorf("failed cells = %v", got)
+	}
+	for _, want := range []string{
+		"=== matrix cell marker: PASS ===\nfrom feature",
+		"=== matrix cell env: FAIL ===\nmode=it's race\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if got := classifyMatrixFailure(nil, results, err, output); got != SwitchFailTest {
+		t.Errorf("category = %q, want %q", got, SwitchFailTest)
+	}
+}
+
+func TestSSHTestRunner_UnreachableIsInfra(t *testing.T) {
+	r := &sshTestRunner{
+		cfg:    config.TestRunnerConfig{Type: config.TestRunnerSSH, Host: "build-01", WorkDir: "/srv/app"},
+		sshBin: fakeSSH(t, 255),
+	}
+	output, _, err := r.Run(context.Background(), TestJob{
+		Branch: "feature",
+		Cells:  []config.TestMatrixCell{{Command: "go test ./..."}},
+	})
+	if !errors.Is(err, ErrTestRunnerUnavailable) {
+		t.Fatalf("err = %v, want ErrTestRunnerUnavailable", err)
+	}
+	if got := classifyTestFailure(err, output); got != SwitchFailInfra {
+		t.Errorf("category = %q, want %q", got, SwitchFailInfra)
+	}
+}
+
+// fakeCI serves the trigger endpoint and a status endpoint that reports
+// running for the first poll and final afterwards.
+func fakeCI(t *testing.T, final map[string]any) (*httptest.Server, *ciJobRequest) {
+	t.Helper()
+	var job ciJobRequest
+	var polls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&job)
+		json.NewEncoder(w).Encode(map[string]string{"id": "j1"})
+	})
+	mux.HandleFunc("GET /jobs/j1", func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) == 1 {
+			json.NewEncoder(w).Encode(map[string]any{"status": "running", "log": "building\n"})
+			return
+		}
+		json.NewEncoder(w).Encode(final)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &job
+}
+
+func newFakeCIRunner(url, token string) *ciTestRunner {
+	return &ciTestRunner{
+		cfg:      config.TestRunnerConfig{Type: config.TestRunnerCI, TriggerURL: url + "/jobs", Token: token},
+		client:   http.DefaultClient,
+		interval: 10 * time.Millisecond,
+	}
+}
+
+func TestCITestRunner_PollsUntilDone(t *testing.T) {
+	srv, job := fakeCI(t, map[string]any{
+		"status": "failed",
+		"log":    "building\ndone\n",
+		"cells": []map[string]any{
+			{"name": "node18", "passed": true, "log": "ok\n"},
+			{"name": "node20", "passed": false, "log": "--- FAIL: TestWidget\n"},
+		},
+	})
+	r := newFakeCIRunner(srv.URL, "tok")
+
+	cells := []config.TestMatrixCell{{Name: "node18", Command: "npm test"}, {Name: "node20", Command: "npm test"}}
+	output, results, err := r.Run(context.Background(), TestJob{CarID: "car-ci1", Branch: "feature", BaseBranch: "main", Cells: cells})
+	if err == nil || !strings.Contains(err.Error(), "matrix cell(s) node20:") {
+		t.Fatalf("err = %v, want node20 failure", err)
+	}
+	if job.CarID != "car-ci1" || job.Branch != "feature" || len(job.Cells) != 2 {
+		t.Errorf("triggered job = %+v", job)
+	}
+	if !strings.Contains(output, "=== matrix cell node20: FAIL ===\n--- FAIL: TestWidget") {
+		t.Errorf("output:\n%s", output)
+	}
+	if got := classifyMatrixFailure(nil, results, err, output); got != SwitchFailTest {
+		t.Errorf("category = %q, want %q", got, SwitchFailTest)
+	}
+}
+
+func TestCITestRunner_SingleCommandPass(t *testing.T) {
+	srv, _ := fakeCI(t, map[string]any{"status": "passed", "log": "PASS\n"})
+	r := newFakeCIRunner(srv.URL, "tok")
+
+	output, _, err := r.Run(context.Background(), TestJob{Branch: "feature", Cells: []config.TestMatrixCell{{Command: "go test ./..."}}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "PASS\n" {
+		t.Errorf("output = %q, want job log", output)
+	}
+}
+
+func TestCITestRunner_InfraErrors(t *testing.T) {
+	t.Run("job error", func(t *testing.T) {
+		srv, _ := fakeCI(t, map[string]any{"status": "error", "log": "runner lost\n"})
+		_, _, err := newFakeCIRunner(srv.URL, "tok").Run(context.Background(), TestJob{Branch: "feature"})
+		if !errors.Is(err, ErrTestRunnerUnavailable) {
+			t.Errorf("err = %v, want ErrTestRunnerUnavailable", err)
+		}
+	})
+	t.Run("trigger rejected", func(t *testing.T) {
+		srv, _ := fakeCI(t, nil)
+		_, _, err := newFakeCIRunner(srv.URL, "wrong").Run(context.Background(), TestJob{Branch: "feature"})
+		if !errors.Is(err, ErrTestRunnerUnavailable) || !strings.Contains(err.Error(), "HTTP 401") {
+			t.Errorf("err = %v, want ErrTestRunnerUnavailable with HTTP 401", err)
+		}
+	})
+}
+
+type stubTestRunner struct {
+	job    TestJob
+	output string
+	err    error
+}
+
+func (s *stubTestRunner) Run(_ context.Context, job TestJob) (string, []TestCellResult, error) {
+	s.job = job
+	return s.output, nil, s.err
+}
+
+func TestSwitch_RemoteRunnerInfraFailure(t *testing.T) {
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-rr1")
+	writeFile(t, repoDir, "feature-rr1.txt", "remote feature")
+	run(repoDir, "git", "add", "feature-rr1.txt")
+	run(repoDir, "git", "commit", "-m", "feature work")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{
+		ID:     "car-rr1",
+		Title:  "Remote test",
+		Track:  "backend",
+		Branch: "ry/alice/backend/car-rr1",
+		Status: "done",
+	})
+
+	runner := &stubTestRunner{err: errors.Join(ErrTestRunnerUnavailable, errors.New("build-01 unreachable"))}
+	result, err := Switch(db, "car-rr1", SwitchOpts{
+		RepoDir:        repoDir,
+		PreTestCommand: "go mod download",
+		TestCommand:    "go test ./...",
+		TestRunner:     runner,
+	})
+	if err != nil {
+		t.Fatalf("Switch returned error: %v", err)
+	}
+	if runner.job.Branch != "ry/alice/backend/car-rr1" || runner.job.PreTestCommand != "go mod download" ||
+		len(runner.job.Cells) != 1 || runner.job.Cells[0].Command != "go test ./..." {
+		t.Errorf("job = %+v", runner.job)
+	}
+	if result.Merged || result.FailureCategory != SwitchFailInfra {
+		t.Errorf("result = %+v, want unmerged infra failure", result)
+	}
+
+	var c models.Car
+	db.First(&c, "id = ?", "car-rr1")
+	if c.Status != "merge-failed" {
+		t.Errorf("status = %q, want merge-failed", c.Status)
+	}
+}
@@ -0,0 +1,201 @@
+package yardmaster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// testWatchInterval is how often a running merge-gate test run checks for
+// abort requests and whether a progress notice is due.
+var testWatchInterval = 10 * time.Second
+
+// noticeTailLen is how much of the test output a progress notice quotes.
+const noticeTailLen = 1500
+
+// outputTail keeps the last bytes written to it. Parallel matrix cells write
+// to it concurrently.
+type outputTail struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+	cut bool // earlier output was dropped
+}
+
+func newOutputTail(max int) *outputTail {
+	return &outputTail{max: max}
+}
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+		t.cut = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, starting at a line boundary when the
+// start was cut off.
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := string(t.buf)
+	if t.cut {
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	return strings.TrimRight(s, "\n")
+}
+
+// testWatch runs beside a merge-gate test run. Every noticeAfter it posts a
+// progress notice with the elapsed time and output tail to telegraph, and
+// it cancels the run when an abort-merge message for the car arrives
+// (`!ry merge abort <car>`).
+type testWatch struct {
+	db          *gorm.DB
+	clk         clock.Clock
+	car         *models.Car
+	noticeAfter time.Duration // 0 disables notices
+	tail        *outputTail
+	cancel      context.CancelFunc
+	start       time.Time
+	stopCh      chan struct{}
+	done        chan struct{}
+
+	mu      sync.Mutex
+	aborted string // body of the abort request, once one arrived
+}
+
+// startTestWatch starts watching a test run; cancel stops the run.
+func startTestWatch(db *gorm.DB, clk clock.Clock, car *models.Car, noticeAfter time.Duration, tail *outputTail, cancel context.CancelFunc) *testWatch {
+	w := &testWatch{
+		db:          db,
+		clk:         clk,
+		car:         car,
+		noticeAfter: noticeAfter,
+		tail:        tail,
+		cancel:      cancel,
+		start:       clk.Now(),
+		stopCh:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *testWatch) run() {
+	defer close(w.done)
+	ticker := w.clk.NewTicker(testWatchInterval)
+	defer ticker.Stop()
+	notices := 0
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C():
+		}
+		if w.checkAbort() {
+			return
+		}
+		if w.noticeAfter > 0 {
+			if elapsed := w.clk.Since(w.start); elapsed >= time.Duration(notices+1)*w.noticeAfter {
+				notices++
+				w.postNotice(elapsed)
+			}
+		}
+	}
+}
+
+// checkAbort acknowledges pending abort requests for the car and cancels
+// the run if there was one.
+func (w *testWatch) checkAbort() bool {
+	var msgs []models.Message
+	if err := w.db.Where("to_agent = ? AND subject = ? AND car_id = ? AND acknowledged = ?",
+		YardmasterID, "abort-merge", w.car.ID, false).Order("id").Find(&msgs).Error; err != nil {
+		slog.Warn("Switch: check abort requests", "car", w.car.ID, "error", err)
+		return false
+	}
+	if len(msgs) == 0 {
+		return false
+	}
+	for _, m := range msgs {
+		if err := messaging.Acknowledge(w.db, m.ID); err != nil {
+			slog.Warn("Switch: ack abort request", "car", w.car.ID, "msg", m.ID, "error", err)
+		}
+	}
+	w.mu.Lock()
+	w.aborted = msgs[0].Body
+	w.mu.Unlock()
+	slog.Info("Switch: test run aborted", "car", w.car.ID, "request", msgs[0].Body)
+	w.cancel()
+	return true
+}
+
+// postNotice tells telegraph the run is still going.
+func (w *testWatch) postNotice(elapsed time.Duration) {
+	body := fmt.Sprintf("Merge-gate tests for car %s (%s) on branch %s have been running for %s. Abort with `!ry merge abort %s`; the car re-enters the merge gate later.",
+		w.car.ID, w.car.Title, w.car.Branch, elapsed.Round(time.Second), w.car.ID)
+	if tail := w.tail.String(); tail != "" {
+		body += "\n\nOutput tail:\n" + tail
+	}
+	if _, err := messaging.Send(w.db, YardmasterID, "telegraph", "tests-running-long", body,
+		messaging.SendOpts{CarID: w.car.ID}); err != nil {
+		slog.Warn("Switch: post test progress notice", "car", w.car.ID, "error", err)
+	}
+}
+
+// stop ends the watch and returns the abort request, or "" when the run
+// was not aborted.
+func (w *testWatch) stop() string {
+	close(w.stopCh)
+	<-w.done
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.aborted
+}
+
+// mergeDeferrals holds cars whose merge-gate run was aborted until they may
+// re-enter the merge gate. It lives in the daemon's memory: a restart lets
+// them back in at once.
+type mergeDeferrals struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// deferredMerges is the daemon's set of aborted merges awaiting requeue.
+var deferredMerges = &mergeDeferrals{until: make(map[string]time.Time)}
+
+// deferMerge keeps carID out of the merge gate until the given time.
+func (d *mergeDeferrals) deferMerge(carID string, until time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.until[carID] = until
+}
+
+// deferred reports whether carID must still wait at now, forgetting
+// deferrals that have passed.
+func (d *mergeDeferrals) deferred(carID string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	until, ok := d.until[carID]
+	if !ok {
+		return false
+	}
+	if !now.Before(until) {
+		delete(d.until, carID)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package yardmaster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+	"github.com/zulandar/railyard/internal/messaging"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestOutputTail(t *testing.T) {
+	tail := newOutputTail(16)
+	tail.Write([]byte("ok  pkg/a\n"))
+	if got := tail.String(); got != "ok  pkg/a" {
+		t.Errorf("tail = %q", got)
+	}
+	tail.Write([]byte("ok  pkg/b\nok  pkg/c\n"))
+	if got := tail.String(); got != "ok  pkg/c" {
+		t.Errorf("tail = %q, want the last whole line", got)
+	}
+}
+
+func TestTestWatch_PostsNoticeAndAborts(t *testing.T) {
+	orig := testWatchInterval
+	testWatchInterval = 10 * time.Millisecond
+	defer func() { testWatchInterval = orig }()
+
+	db := testDB(t)
+	c := &models.Car{ID: "car-slow", Title: "Slow suite", Branch: "ry/alice/backend/car-slow"}
+	tail := newOutputTail(noticeTailLen)
+	tail.Write([]byte("=== RUN   TestSlow\n"))
+	canceled := make(chan struct{})
+	w := startTestWatch(db, clock.Real, c, 20*time.Millisecond, tail, func() { close(canceled) })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var n int64
+		db.Model(&models.Message{}).Where("to_agent = ? AND subject = ?", "telegraph", "tests-running-long").Count(&n)
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no progress notice posted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var notice models.Message
+	db.Where("subject = ?", "tests-running-long").First(&notice)
+	if notice.CarID != "car-slow" || !strings.Contains(notice.Body, "`!ry merge abort car-slow`") || !strings.Contains(notice.Body, "=== RUN   TestSlow") {
+		t.Errorf("notice = %q", notice.Body)
+	}
+
+	msg, _ := messaging.Send(db, "telegraph", YardmasterID, "abort-merge", "Merge abort requested from chat by @alice", messaging.SendOpts{CarID: "car-slow"})
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("abort request did not cancel the run")
+	}
+	if got := w.stop(); got != "Merge abort requested from chat by @alice" {
+		t.Errorf("stop = %q", got)
+	}
+	var acked models.Message
+	db.First(&acked, msg.ID)
+	if !acked.Acknowledged {
+		t.Error("abort request not acknowledged")
+	}
+}
+
+func TestSwitch_AbortedTestsKeepCarDone(t *testing.T) {
+	orig := testWatchInterval
+	testWatchInterval = 10 * time.Millisecond
+	defer func() { testWatchInterval = orig }()
+
+	repoDir, _, run := initTestRepoWithRemote(t)
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-ab")
+	run(repoDir, "git", "commit", "--allow-empty", "-m", "feature work")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	db.Create(&models.Car{ID: "car-ab", Title: "Aborted", Track: "backend", Branch: "ry/alice/backend/car-ab", Status: "done"})
+	messaging.Send(db, "telegraph", YardmasterID, "abort-merge", "Merge abort requested from chat by @alice", messaging.SendOpts{CarID: "car-ab"})
+
+	start := time.Now()
+	result, err := Switch(db, "car-ab", SwitchOpts{RepoDir: repoDir, TestCommand: "exec sleep 30"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 20*time.Second {
+		t.Error("tests were not cancelled")
+	}
+	if result.FailureCategory != SwitchFailAborted || result.Merged || !strings.Contains(result.Error.Error(), "@alice") {
+		t.Errorf("result = %+v", result)
+	}
+	var c models.Car
+	db.First(&c, "id = ?", "car-ab")
+	if c.Status != "done" {
+		t.Errorf("status = %q, want done (requeued)", c.Status)
+	}
+}
+
+func TestMergeDeferrals(t *testing.T) {
+	d := &mergeDeferrals{until: make(map[string]time.Time)}
+	now := time.Now()
+	d.deferMerge("car-1", now.Add(time.Minute))
+	if !d.deferred("car-1", now) {
+		t.Error("car-1 should wait")
+	}
+	if d.deferred("car-2", now) {
+		t.Error("car-2 was never deferred")
+	}
+	if d.deferred("car-1", now.Add(time.Minute)) {
+		t.Error("car-1 should be back after its deferral")
+	}
+}
@@ -0,0 +1,68 @@
+package yardmaster
+
+import (
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+// trackDirs returns the repository and yardmaster worktree a track's cars
+// are switched in. Tracks without their own repo use repoDir and ymDir; a
+// track with one uses its clone under .railyard/repos/<track> (cloned on
+// first use) and a yardmaster worktree of that clone.
+func trackDirs(cfg *config.Config, repoDir, ymDir, track string) (trackRepoDir, trackYmDir string, err error) {
+	if !cfg.HasOwnRepo(track) {
+		return repoDir, ymDir, nil
+	}
+	gitMu.Lock()
+	defer gitMu.Unlock()
+	if trackRepoDir, err = engine.EnsureTrackRepo(cfg, repoDir, track); err != nil {
+		return "", "", err
+	}
+	if trackYmDir, err = engine.EnsureYardmasterWorktree(trackRepoDir); err != nil {
+		return "", "", err
+	}
+	return trackRepoDir, trackYmDir, nil
+}
+
+// trackRepoDir returns the repository a track's git work happens in: its
+// clone when it has its own repo (cloned on first use), otherwise repoDir.
+// A nil cfg means a single-repo yard.
+func trackRepoDir(cfg *config.Config, repoDir, track string) (string, error) {
+	if cfg == nil || !cfg.HasOwnRepo(track) {
+		return repoDir, nil
+	}
+	gitMu.Lock()
+	defer gitMu.Unlock()
+	return engine.EnsureTrackRepo(cfg, repoDir, track)
+}
+
+// carBaseBranch returns the branch a car merges into: its own base branch,
+// else its track's configured default branch, else "main". cfg may be nil.
+func carBaseBranch(cfg *config.Config, c *models.Car) string {
+	if c.BaseBranch != "" {
+		return c.BaseBranch
+	}
+	if cfg != nil {
+		if b := cfg.DefaultBranchFor(c.Track); b != "" {
+			return b
+		}
+	}
+	return "main"
+}
+
+// mainRepoTracks returns the tracks whose files live in the top-level repo,
+// the only ones a branch or merge there can be attributed to. A nil cfg has
+// no tracks.
+func mainRepoTracks(cfg *config.Config) []config.TrackConfig {
+	if cfg == nil {
+		return nil
+	}
+	var tracks []config.TrackConfig
+	for _, t := range cfg.Tracks {
+		if !cfg.HasOwnRepo(t.Name) {
+			tracks = append(tracks, t)
+		}
+	}
+	return tracks
+}
@@ -0,0 +1,84 @@
+package yardmaster
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestTrackDirs(t *testing.T) {
+	mainRepo, _ := initTestRepo(t)
+	trackRemote, _ := initTestRepo(t)
+	cfg := &config.Config{
+		Repo: "org/app",
+		Tracks: []config.TrackConfig{
+			{Name: "backend"},
+			{Name: "ml", Repo: trackRemote},
+		},
+	}
+
+	repoDir, ymDir, err := trackDirs(cfg, mainRepo, "/ym", "backend")
+	if err != nil || repoDir != mainRepo || ymDir != "/ym" {
+		t.Errorf("trackDirs(backend) = %q, %q, %v; want the main repo and yardmaster worktree", repoDir, ymDir, err)
+	}
+
+	repoDir, ymDir, err = trackDirs(cfg, mainRepo, "/ym", "ml")
+	if err != nil {
+		t.Fatalf("trackDirs(ml): %v", err)
+	}
+	clone := filepath.Join(mainRepo, ".railyard", "repos", "ml")
+	if repoDir != clone {
+		t.Errorf("repoDir = %q, want %q", repoDir, clone)
+	}
+	if want := filepath.Join(clone, ".railyard", "yardmaster"); ymDir != want {
+		t.Errorf("ymDir = %q, want %q", ymDir, want)
+	}
+	if out, err := gitOutput(ymDir, "rev-parse", "--show-toplevel"); err != nil || out != ymDir {
+		t.Errorf("yardmaster worktree not a checkout of the clone: %q, %v", out, err)
+	}
+}
+
+func TestCarBaseBranch(t *testing.T) {
+	cfg := &config.Config{
+		DefaultBranch: "trunk",
+		Tracks:        []config.TrackConfig{{Name: "backend", DefaultBranch: "develop"}, {Name: "frontend"}},
+	}
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		car  models.Car
+		want string
+	}{
+		{"car's own", cfg, models.Car{Track: "backend", BaseBranch: "release"}, "release"},
+		{"track default", cfg, models.Car{Track: "backend"}, "develop"},
+		{"repo default", cfg, models.Car{Track: "frontend"}, "trunk"},
+		{"no config", nil, models.Car{Track: "backend"}, "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := carBaseBranch(tt.cfg, &tt.car); got != tt.want {
+				t.Errorf("carBaseBranch = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMainRepoTracks(t *testing.T) {
+	cfg := &config.Config{
+		Repo:   "org/app",
+		Tracks: []config.TrackConfig{{Name: "backend"}, {Name: "ml", Repo: "org/ml"}, {Name: "web", Repo: "org/app"}},
+	}
+	var names []string
+	for _, tr := range mainRepoTracks(cfg) {
+		names = append(names, tr.Name)
+	}
+	if strings.Join(names, ",") != "backend,web" {
+		t.Errorf("mainRepoTracks = %v, want backend and web", names)
+	}
+	if got := mainRepoTracks(nil); got != nil {
+		t.Errorf("mainRepoTracks(nil) = %v, want nil", got)
+	}
+}
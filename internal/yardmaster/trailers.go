@@ -0,0 +1,71 @@
+package yardmaster
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+// yardmasterCoAuthor is the attribution trailer on every yardmaster merge.
+const yardmasterCoAuthor = "Co-Authored-By: Railyard Yardmaster <railyard-yardmaster@noreply>"
+
+// mergeCommitMessage builds the message for the merge commit of a car's
+// branch: the "Switch: merge" subject followed by mergeCommitBody.
+func mergeCommitMessage(db *gorm.DB, car *models.Car, baseBranch string) string {
+	return fmt.Sprintf("Switch: merge %s to %s\n\n%s", car.Branch, baseBranch, mergeCommitBody(db, car))
+}
+
+// mergeCommitBody returns the car title, a "Closes #N" line per linked GitHub
+// issue, and a trailer block (Car-ID, Car-Type, Track, Epic) so release notes
+// and issue auto-close can work from git history alone. The trailers parse
+// with `git interpret-trailers --parse`.
+func mergeCommitBody(db *gorm.DB, car *models.Car) string {
+	var b strings.Builder
+	if car.Title != "" {
+		b.WriteString(car.Title)
+		b.WriteString("\n\n")
+	}
+
+	if issues := linkedIssues(db, car); len(issues) > 0 {
+		for _, n := range issues {
+			fmt.Fprintf(&b, "Closes #%d\n", n)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "Car-ID: %s\n", car.ID)
+	if car.Type != "" {
+		fmt.Fprintf(&b, "Car-Type: %s\n", car.Type)
+	}
+	if car.Track != "" {
+		fmt.Fprintf(&b, "Track: %s\n", car.Track)
+	}
+	if car.ParentID != nil && *car.ParentID != "" {
+		fmt.Fprintf(&b, "Epic: %s\n", *car.ParentID)
+	}
+	b.WriteString(yardmasterCoAuthor)
+	return b.String()
+}
+
+// linkedIssues returns the GitHub issue numbers linked to a car — its source
+// issue and any issue Bull tracks for it — deduplicated and sorted.
+func linkedIssues(db *gorm.DB, car *models.Car) []int {
+	var issues []int
+	if car.SourceIssue > 0 {
+		issues = append(issues, car.SourceIssue)
+	}
+	if db != nil {
+		var tracked []int
+		db.Model(&models.BullIssue{}).Where("car_id = ?", car.ID).Pluck("issue_number", &tracked)
+		for _, n := range tracked {
+			if n > 0 {
+				issues = append(issues, n)
+			}
+		}
+	}
+	slices.Sort(issues)
+	return slices.Compact(issues)
+}
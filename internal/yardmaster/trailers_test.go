@@ -0,0 +1,89 @@
+package yardmaster
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestMergeCommitBody_Trailers(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&models.BullIssue{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	epic := "car-epic1"
+	c := models.Car{ID: "car-tr1", Title: "Fix login", Type: "bug", Track: "backend", ParentID: &epic, SourceIssue: 42}
+	db.Create(&models.BullIssue{IssueNumber: 7, CarID: c.ID})
+	db.Create(&models.BullIssue{IssueNumber: 42, CarID: c.ID}) // same as SourceIssue
+
+	want := "Fix login\n\n" +
+		"Closes #7\nCloses #42\n\n" +
+		"Car-ID: car-tr1\nCar-Type: bug\nTrack: backend\nEpic: car-epic1\n" +
+		yardmasterCoAuthor
+	if got := mergeCommitBody(db, &c); got != want {
+		t.Errorf("mergeCommitBody =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMergeCommitBody_Minimal(t *testing.T) {
+	c := models.Car{ID: "car-tr2", Title: "Tidy", Track: "backend"}
+	got := mergeCommitBody(nil, &c)
+	if strings.Contains(got, "Closes") || strings.Contains(got, "Epic:") || strings.Contains(got, "Car-Type:") {
+		t.Errorf("unexpected optional lines:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "Car-ID: car-tr2\nTrack: backend\n"+yardmasterCoAuthor) {
+		t.Errorf("mergeCommitBody =\n%s", got)
+	}
+}
+
+func TestSwitch_MergeCommitHasTrailers(t *testing.T) {
+	repoDir, bareDir, run := initTestRepoWithRemote(t)
+
+	run(repoDir, "git", "checkout", "-b", "ry/alice/backend/car-tr3")
+	writeFile(t, repoDir, "feature-tr3.txt", "trailer feature")
+	run(repoDir, "git", "add", "feature-tr3.txt")
+	run(repoDir, "git", "commit", "-m", "feature work")
+	run(repoDir, "git", "checkout", "main")
+
+	db := testDB(t)
+	epic := "car-epic3"
+	db.Create(&models.Car{ID: epic, Title: "Epic", Type: "epic", Track: "backend", Status: "open"})
+	db.Create(&models.Car{
+		ID:          "car-tr3",
+		Title:       "Trailer test",
+		Type:        "task",
+		Track:       "backend",
+		Branch:      "ry/alice/backend/car-tr3",
+		Status:      "done",
+		ParentID:    &epic,
+		SourceIssue: 15,
+	})
+
+	if _, err := Switch(db, "car-tr3", SwitchOpts{RepoDir: repoDir, TestCommand: "true"}); err != nil {
+		t.Fatalf("Switch returned error: %v", err)
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%B", "main")
+	cmd.Dir = bareDir
+	msg, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %s: %v", msg, err)
+	}
+	if !strings.HasPrefix(string(msg), "Switch: merge ry/alice/backend/car-tr3 to main") || !strings.Contains(string(msg), "Closes #15") {
+		t.Errorf("merge message:\n%s", msg)
+	}
+
+	parse := exec.Command("git", "interpret-trailers", "--parse")
+	parse.Stdin = strings.NewReader(string(msg))
+	trailers, err := parse.CombinedOutput()
+	if err != nil {
+		t.Fatalf("interpret-trailers: %s: %v", trailers, err)
+	}
+	for _, want := range []string{"Car-ID: car-tr3", "Car-Type: task", "Track: backend", "Epic: car-epic3"} {
+		if !strings.Contains(string(trailers), want) {
+			t.Errorf("parsed trailers missing %q:\n%s", want, trailers)
+		}
+	}
+}
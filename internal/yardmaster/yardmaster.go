@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/events"
 	"gorm.io/gorm"
@@ -29,6 +30,9 @@ type StartOpts struct {
 	// served by the embedded HealthServer. *pluginhost.Host satisfies it.
 	// nil disables the route (handler returns an empty Snapshot).
 	PluginStatus StatusProvider
+	// Clock is the time source for polling, staleness, and cooldown logic.
+	// nil uses clock.Real; tests pass a clock.Fake.
+	Clock clock.Clock
 }
 
 // Start launches the yardmaster daemon loop. It validates options, then
@@ -53,5 +57,5 @@ func Start(ctx context.Context, opts StartOpts) error {
 		logger = slog.Default()
 	}
 
-	return RunDaemonWithBus(ctx, opts.DB, opts.Config, opts.ConfigPath, opts.RepoDir, opts.PollInterval, logger, opts.Bus, opts.PluginStatus)
+	return runDaemon(ctx, opts.DB, clock.OrReal(opts.Clock), opts.Config, opts.ConfigPath, opts.RepoDir, opts.PollInterval, logger, opts.Bus, opts.PluginStatus)
 }
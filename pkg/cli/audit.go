@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/audit"
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Export and verify the audit trail",
+	}
+	cmd.AddCommand(newAuditExportCmd())
+	cmd.AddCommand(newAuditVerifyCmd())
+	return cmd
+}
+
+func newAuditExportCmd() *cobra.Command {
+	var (
+		configPath string
+		since      string
+		until      string
+		outPath    string
+		sign       bool
+		keyID      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write a tamper-evident export of the audit and engine journal logs",
+		Long: `Exports every audit event and engine journal entry (the commands, file
+edits, and decisions engines recorded) in a time window as JSON lines: a
+header, the records in time order, and a trailer.
+
+Each record carries the SHA-256 of the previous record's hash and its own
+content, so editing, dropping, or reordering any record breaks the chain
+from that point on; ry audit verify checks it. The trailer holds the record
+count and final hash.
+
+With --sign the file is also signed with gpg (a detached, ASCII-armored
+signature written next to it as <file>.asc), so a reviewer can prove who
+produced the export, not just that it is internally consistent.`,
+		Example: `  ry audit export --since 720h --out audit-march.jsonl
+  ry audit export --since 2026-03-01 --until 2026-04-01 --out q1.jsonl --sign --key compliance@example.com
+  gpg --verify q1.jsonl.asc q1.jsonl && ry audit verify q1.jsonl`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			from, err := parseAuditTime(since, now)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			to := now
+			if until != "" {
+				if to, err = parseAuditTime(until, now); err != nil {
+					return fmt.Errorf("--until: %w", err)
+				}
+			}
+			if !from.Before(to) {
+				return fmt.Errorf("--since must be before --until")
+			}
+			if sign && outPath == "" {
+				return fmt.Errorf("--sign requires --out")
+			}
+			return runAuditExport(cmd.OutOrStdout(), cmd.ErrOrStderr(), configPath, from, to, outPath, sign, keyID)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&since, "since", "", "start of the window: a duration ago (e.g. 24h, 30d) or a date (2006-01-02 or RFC 3339)")
+	cmd.Flags().StringVar(&until, "until", "", "end of the window, same formats as --since (default: now)")
+	cmd.Flags().StringVar(&outPath, "out", "", "file to write (default: stdout)")
+	cmd.Flags().BoolVar(&sign, "sign", false, "sign the export with gpg, writing <out>.asc")
+	cmd.Flags().StringVar(&keyID, "key", "", "gpg key to sign with (default: gpg's default key)")
+	cmd.MarkFlagRequired("since")
+	return cmd
+}
+
+func runAuditExport(out, errOut io.Writer, configPath string, since, until time.Time, outPath string, sign bool, keyID string) error {
+	_, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	header := audit.ExportHeader{Since: since.UTC(), Until: until.UTC(), GeneratedAt: time.Now().UTC()}
+	if u, err := user.Current(); err == nil {
+		header.GeneratedBy = u.Username
+	}
+
+	w := out
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", outPath, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	trailer, err := audit.Export(gormDB, w, header)
+	if err != nil {
+		return err
+	}
+	if outPath == "" {
+		return nil
+	}
+	if f, ok := w.(*os.File); ok {
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("write %s: %w", outPath, err)
+		}
+	}
+	fmt.Fprintf(errOut, "Exported %d records to %s (final hash %s)\n", trailer.Records, outPath, trailer.FinalHash)
+
+	if sign {
+		sigPath, err := gpgDetachSign(outPath, keyID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(errOut, "Signed: %s\n", sigPath)
+	}
+	return nil
+}
+
+// gpgDetachSign writes an ASCII-armored detached signature for path to
+// path.asc and returns its name.
+func gpgDetachSign(path, keyID string) (string, error) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		return "", fmt.Errorf("--sign needs gpg on PATH: %w", err)
+	}
+	sigPath := path + ".asc"
+	args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", sigPath}
+	if keyID != "" {
+		args = append(args, "--local-user", keyID)
+	}
+	args = append(args, path)
+	if out, err := exec.Command("gpg", args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("gpg sign %s: %w\n%s", path, err, strings.TrimSpace(string(out)))
+	}
+	return sigPath, nil
+}
+
+func newAuditVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify <file>",
+		Short: "Check an audit export's hash chain",
+		Long: `Recomputes every hash in an export written by ry audit export and fails
+on the first record that was edited, dropped, or moved, or if the file was
+truncated. Check a --sign signature separately with gpg --verify.`,
+		Example: `  ry audit verify audit-march.jsonl`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			trailer, err := audit.VerifyExport(f)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "OK: %d records, final hash %s\n", trailer.Records, trailer.FinalHash)
+			return nil
+		},
+	}
+}
+
+// parseAuditTime reads a --since/--until value: a duration before now
+// ("24h", or "30d" for days) or a date ("2006-01-02" or RFC 3339).
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration (24h, 30d) or date (2006-01-02, RFC 3339)", s)
+}
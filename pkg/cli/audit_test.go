@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"24h":                  now.Add(-24 * time.Hour),
+		"30d":                  now.AddDate(0, 0, -30),
+		"2026-03-01T00:00:00Z": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2026-03-01":           time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+	} {
+		got, err := parseAuditTime(in, now)
+		if err != nil {
+			t.Errorf("parseAuditTime(%q): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseAuditTime(%q) = %v, want %v", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "yesterday", "-3d5"} {
+		if _, err := parseAuditTime(in, now); err == nil {
+			t.Errorf("parseAuditTime(%q) succeeded, want error", in)
+		}
+	}
+}
+
+// TestAuditExportCmd_ThroughRoot parses audit export's flags together with
+// the root's persistent ones, which a shorthand clash makes cobra panic on.
+func TestAuditExportCmd_ThroughRoot(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"audit", "export", "--since", "24h", "--sign"}, "--sign requires --out"},
+		{[]string{"audit", "export", "--since", "24h", "--out", filepath.Join(t.TempDir(), "a.jsonl"), "-o", "json"},
+			"does not support --output json"},
+	} {
+		cmd := newRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(tc.args)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: err = %v, want it to mention %q", tc.args, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+func newBisectCmd() *cobra.Command {
+	var (
+		configPath string
+		testCmd    string
+		base       string
+		good       string
+		merges     int
+		revert     bool
+		track      string
+		repoDir    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Find the car whose merge broke the base branch",
+		Long: `Bisects the base branch's merge history to find the car that introduced a
+regression. Each step tests one merge (git bisect --first-parent) in a
+temporary worktree, so the current checkout is left alone. The test command
+exits 0 when good, 125 to skip a commit, and anything else when bad.
+
+By default the search covers the last 20 car merges; use --merges or --good
+to widen or pin it. With --revert, a revert car is created for the
+offending merge.`,
+		Example: `  ry bisect --test "make test"
+  ry bisect --test "go test ./pkg/api/..." --good v1.4.0 --revert`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBisect(cmd, configPath, yardmaster.BisectOpts{
+				TestCommand: testCmd,
+				BaseBranch:  base,
+				Good:        good,
+				MaxMerges:   merges,
+				RepoDir:     repoDir,
+			}, revert, track)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&testCmd, "test", "", "test command that fails on the regression (required)")
+	cmd.Flags().StringVar(&base, "base", "", "branch to bisect (default: default_branch, or main)")
+	cmd.Flags().StringVar(&good, "good", "", "known-good commit (default: before the oldest of --merges car merges)")
+	cmd.Flags().IntVar(&merges, "merges", yardmaster.DefaultBisectMerges, "recent car merges to search when --good is not set")
+	cmd.Flags().BoolVar(&revert, "revert", false, "create a revert car for the offending merge")
+	cmd.Flags().StringVar(&track, "track", "", "track for the revert car when the offending commit has no car")
+	cmd.Flags().StringVar(&repoDir, "repo", "", "repository to bisect (default: current directory)")
+	cmd.MarkFlagRequired("test")
+	return cmd
+}
+
+func runBisect(cmd *cobra.Command, configPath string, opts yardmaster.BisectOpts, revert bool, track string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if opts.RepoDir == "" {
+		if opts.RepoDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+	}
+	if opts.BaseBranch == "" {
+		opts.BaseBranch = cfg.DefaultBranch
+	}
+
+	out := cmd.OutOrStdout()
+	result, err := yardmaster.Bisect(gormDB, opts)
+	if err != nil {
+		if result != nil && result.Log != "" {
+			fmt.Fprintln(out, result.Log)
+		}
+		return err
+	}
+
+	bad := result.Bad
+	fmt.Fprintf(out, "Searched %d merges after %s\n", result.Candidates, shortCommit(result.Good))
+	fmt.Fprintf(out, "First bad commit: %s %s\n", shortCommit(bad.Commit), bad.Subject)
+	switch {
+	case result.Car != nil:
+		fmt.Fprintf(out, "Car: %s — %s (track %s)\n", result.Car.ID, result.Car.Title, result.Car.Track)
+		if result.Car.Owner != "" {
+			fmt.Fprintf(out, "Owner: @%s\n", result.Car.Owner)
+		}
+	case bad.CarID != "":
+		fmt.Fprintf(out, "Car: %s (not found in the database)\n", bad.CarID)
+	default:
+		fmt.Fprintln(out, "Car: none (commit was not a car merge)")
+	}
+
+	if !revert {
+		return nil
+	}
+
+	revertOpts := car.CreateOpts{
+		Type:         "bug",
+		Priority:     0,
+		Track:        track,
+		BaseBranch:   opts.BaseBranch,
+		BranchPrefix: cfg.BranchPrefix,
+		IDFormat:     car.IDFormatFromConfig(cfg),
+		RequestedBy:  cfg.Owner,
+		Title:        fmt.Sprintf("Revert %s", bad.Subject),
+	}
+	desc := fmt.Sprintf("`ry bisect --test %q` found %s as the first bad commit on %s.\n\n"+
+		"Revert it with `git revert -m 1 %s` (drop `-m 1` if it is not a merge commit), "+
+		"confirm the test command passes, and note what the original change needs before it can land again.",
+		opts.TestCommand, bad.Commit, opts.BaseBranch, bad.Commit)
+	if c := result.Car; c != nil {
+		revertOpts.Title = fmt.Sprintf("Revert %s: %s", c.ID, c.Title)
+		revertOpts.Owner = c.Owner
+		revertOpts.RevertOf = c.ID
+		if revertOpts.Track == "" {
+			revertOpts.Track = c.Track
+		}
+		desc += fmt.Sprintf("\n\nOriginal car: %s (%s).", c.ID, c.Title)
+	}
+	if revertOpts.Track == "" {
+		return fmt.Errorf("offending commit has no car; pass --track for the revert car")
+	}
+	revertOpts.Description = desc
+	revertOpts.Acceptance = fmt.Sprintf("%s is reverted on %s and `%s` passes.", shortCommit(bad.Commit), opts.BaseBranch, opts.TestCommand)
+
+	rc, err := car.Create(gormDB, revertOpts)
+	if err != nil {
+		return fmt.Errorf("create revert car: %w", err)
+	}
+	if _, err := car.Publish(gormDB, rc.ID, false); err != nil {
+		return fmt.Errorf("publish revert car %s: %w", rc.ID, err)
+	}
+	fmt.Fprintf(out, "Created revert car %s\n", rc.ID)
+	return nil
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zulandar/railyard/internal/audit"
@@ -12,7 +17,10 @@ import (
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/db"
 	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/outbound"
+	"github.com/zulandar/railyard/internal/yardmaster"
 	"gorm.io/gorm"
 )
 
@@ -27,6 +35,12 @@ func newCarCmd() *cobra.Command {
 	cmd.AddCommand(newCarSearchCmd())
 	cmd.AddCommand(newCarShowCmd())
 	cmd.AddCommand(newCarUpdateCmd())
+	cmd.AddCommand(newCarOwnCmd())
+	cmd.AddCommand(newCarRevertCmd())
+	cmd.AddCommand(newCarCloneCmd())
+	cmd.AddCommand(newCarRerunCmd())
+	cmd.AddCommand(newCarSplitCmd())
+	cmd.AddCommand(newCarAdoptCmd())
 	cmd.AddCommand(newCarDepCmd())
 	cmd.AddCommand(newCarReadyCmd())
 	cmd.AddCommand(newCarChildrenCmd())
@@ -34,6 +48,11 @@ func newCarCmd() *cobra.Command {
 	cmd.AddCommand(newCarRememberCmd())
 	cmd.AddCommand(newCarMemoriesCmd())
 	cmd.AddCommand(newCarForgetCmd())
+	cmd.AddCommand(newCarJournalCmd())
+	cmd.AddCommand(newCarWatchCmd())
+	cmd.AddCommand(newCarWebhookCmd())
+	cmd.AddCommand(newCarPRPreviewCmd())
+	cmd.AddCommand(newCarImportCmd())
 	return cmd
 }
 
@@ -44,29 +63,44 @@ func newCarCreateCmd() *cobra.Command {
 		track       string
 		carType     string
 		priority    int
+		estimate    int
 		description string
 		acceptance  string
 		design      string
 		parentID    string
 		skipTests   bool
+		owner       string
+		attach      []uint
+		ignorePause bool
+		template    string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new car",
-		Long:  "Creates a new car (work item) in the Railyard database with an auto-generated ID.",
+		Long: `Creates a new car (work item) in the Railyard database with an auto-generated ID.
+
+--template pre-fills the type, track, priority, description, acceptance
+criteria, and design notes from a template in railyard.yaml's templates
+section or .railyard/templates/<name>.yaml; flags given explicitly win.
+The template's deps become blockers of the new car.`,
+		Example: `  ry car create --title "Fix login timeout" --track backend --type bug
+  ry car create --template bugfix --title "Fix login timeout"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCarCreate(cmd, configPath, car.CreateOpts{
+			return runCarCreate(cmd, configPath, template, car.CreateOpts{
 				Title:       title,
 				Track:       track,
 				Type:        carType,
 				Priority:    priority,
+				Estimate:    estimate,
 				Description: description,
 				Acceptance:  acceptance,
 				DesignNotes: design,
 				ParentID:    parentID,
 				SkipTests:   skipTests,
-			})
+				Owner:       owner,
+				IgnorePause: ignorePause,
+			}, attach)
 		},
 	}
 
@@ -75,54 +109,62 @@ func newCarCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&track, "track", "", "track name (required if no parent with track)")
 	cmd.Flags().StringVar(&carType, "type", "task", "car type (task, epic, bug, spike)")
 	cmd.Flags().IntVar(&priority, "priority", 2, "priority (0=critical → 4=backlog)")
+	cmd.Flags().IntVar(&estimate, "estimate", 0, "relative size, used by the smallest_first claim strategy (0 = unestimated)")
 	cmd.Flags().StringVar(&description, "description", "", "detailed description")
 	cmd.Flags().StringVar(&acceptance, "acceptance", "", "acceptance criteria")
 	cmd.Flags().StringVar(&design, "design", "", "design notes")
 	cmd.Flags().StringVar(&parentID, "parent", "", "parent epic car ID")
 	cmd.Flags().BoolVar(&skipTests, "skip-tests", false, "skip test gate during merge")
+	cmd.Flags().StringVar(&owner, "owner", "", "human owner/reviewer (e.g. @alice)")
+	cmd.Flags().UintSliceVar(&attach, "attach", nil, "chat attachment ID to link to the car (repeatable)")
+	cmd.Flags().BoolVar(&ignorePause, "ignore-pause", false, "create the car even while the yard is paused")
+	cmd.Flags().StringVar(&template, "template", "", "pre-fill fields from a car template")
 	cmd.MarkFlagRequired("title")
 	return cmd
 }
 
-func runCarCreate(cmd *cobra.Command, configPath string, opts car.CreateOpts) error {
+func runCarCreate(cmd *cobra.Command, configPath, template string, opts car.CreateOpts, attach []uint) error {
 	cfg, gormDB, err := connectFromConfig(configPath)
 	if err != nil {
 		return err
 	}
 
-	// Validate the track against the config: engines claim strictly by
-	// track equality, so a typo'd track produces a car that sits open
-	// forever with nothing sweeping or reporting it (railyard-d5f). An
-	// empty track is allowed through — it either inherits from the parent
-	// epic or is rejected by car.Create.
-	if opts.Track != "" {
-		known := make([]string, 0, len(cfg.Tracks))
-		found := false
-		for _, t := range cfg.Tracks {
-			known = append(known, t.Name)
-			if t.Name == opts.Track {
-				found = true
-			}
+	repoDir, _ := os.Getwd()
+	var deps []string
+	if template != "" {
+		tmpl, err := cfg.CarTemplate(repoDir, template)
+		if err != nil {
+			return err
 		}
-		if !found {
-			return fmt.Errorf("unknown track %q — no engine would ever claim this car; configured tracks: %s",
-				opts.Track, strings.Join(known, ", "))
+		applyCarTemplate(cmd, tmpl, &opts)
+		deps = tmpl.Deps
+	}
+
+	// An empty track is allowed through — it either inherits from the
+	// parent epic or is rejected by car.Create.
+	if opts.Track != "" {
+		if err := checkCarTrack(cfg, opts.Track); err != nil {
+			return err
 		}
 	}
 
+	if opts.ParentID, err = resolveCarID(cmd, gormDB, opts.ParentID); err != nil {
+		return err
+	}
 	opts.BranchPrefix = cfg.BranchPrefix
+	opts.IDFormat = car.IDFormatFromConfig(cfg)
 	if opts.RequestedBy == "" {
 		opts.RequestedBy = cfg.Owner
 	}
 
 	// Snapshot the current base branch at car creation time.
-	repoDir, _ := os.Getwd()
-	opts.BaseBranch = engine.DetectBaseBranch(repoDir, cfg.DefaultBranch)
+	opts.BaseBranch = engine.TrackBaseBranch(cfg, repoDir, opts.Track)
 
 	b, err := car.Create(gormDB, opts)
 	if err != nil {
 		return err
 	}
+	recordDispatchCar(cmd.ErrOrStderr(), gormDB, b.ID)
 
 	out := cmd.OutOrStdout()
 	fmt.Fprintf(out, "Created car %s\n", b.ID)
@@ -130,9 +172,60 @@ func runCarCreate(cmd *cobra.Command, configPath string, opts car.CreateOpts) er
 	if b.ParentID != nil {
 		fmt.Fprintf(out, "Parent: %s\n", *b.ParentID)
 	}
+	if b.Owner != "" {
+		fmt.Fprintf(out, "Owner: @%s\n", b.Owner)
+	}
+
+	for _, dep := range deps {
+		if err := car.AddDep(gormDB, b.ID, dep, ""); err != nil {
+			return fmt.Errorf("car %s created without template dependency %s: %w", b.ID, dep, err)
+		}
+		fmt.Fprintf(out, "Blocked by: %s\n", dep)
+	}
+
+	atts, err := car.Attach(gormDB, b.ID, attach)
+	if err != nil {
+		return fmt.Errorf("car %s created without attachments: %w", b.ID, err)
+	}
+	for _, a := range atts {
+		fmt.Fprintf(out, "Attached: %s\n", car.AttachmentPath(a))
+	}
 	return nil
 }
 
+// applyCarTemplate fills the fields of opts that were not set by a flag
+// from the template.
+func applyCarTemplate(cmd *cobra.Command, t config.CarTemplate, opts *car.CreateOpts) {
+	fill := func(flag string, dst *string, v string) {
+		if v != "" && !cmd.Flags().Changed(flag) {
+			*dst = v
+		}
+	}
+	fill("type", &opts.Type, t.Type)
+	fill("track", &opts.Track, t.Track)
+	fill("description", &opts.Description, t.Description)
+	fill("acceptance", &opts.Acceptance, t.Acceptance)
+	fill("design", &opts.DesignNotes, t.DesignNotes)
+	if t.Priority != nil && !cmd.Flags().Changed("priority") {
+		opts.Priority = *t.Priority
+	}
+}
+
+// checkCarTrack validates track against the config: engines claim strictly
+// by track equality, so a typo'd track produces a car that sits open
+// forever with nothing sweeping or reporting it (railyard-d5f).
+func checkCarTrack(cfg *config.Config, track string) error {
+	known := make([]string, 0, len(cfg.Tracks))
+	for _, t := range cfg.Tracks {
+		if t.Name == track {
+			return nil
+		}
+		known = append(known, t.Name)
+	}
+	return fmt.Errorf("unknown track %q — no engine would ever claim this car; configured tracks: %s",
+		track, strings.Join(known, ", "))
+}
+
 func newCarListCmd() *cobra.Command {
 	var (
 		configPath string
@@ -140,6 +233,7 @@ func newCarListCmd() *cobra.Command {
 		status     string
 		carType    string
 		assignee   string
+		owner      string
 	)
 
 	cmd := &cobra.Command{
@@ -152,6 +246,7 @@ func newCarListCmd() *cobra.Command {
 				Status:   status,
 				Type:     carType,
 				Assignee: assignee,
+				Owner:    owner,
 			})
 		},
 	}
@@ -161,7 +256,8 @@ func newCarListCmd() *cobra.Command {
 	cmd.Flags().StringVar(&status, "status", "", "filter by status")
 	cmd.Flags().StringVar(&carType, "type", "", "filter by type")
 	cmd.Flags().StringVar(&assignee, "assignee", "", "filter by assignee")
-	return cmd
+	cmd.Flags().StringVar(&owner, "owner", "", "filter by owner")
+	return supportsJSON(cmd)
 }
 
 func runCarList(cmd *cobra.Command, configPath string, filters car.ListFilters) error {
@@ -176,11 +272,19 @@ func runCarList(cmd *cobra.Command, configPath string, filters car.ListFilters)
 	}
 
 	out := cmd.OutOrStdout()
+	if wantJSON(cmd) {
+		return writeCarsJSON(out, gormDB, cars)
+	}
 	if len(cars) == 0 {
 		fmt.Fprintln(out, "No cars found.")
 		return nil
 	}
+	return printCarList(out, gormDB, cars)
+}
 
+// printCarList prints cars as the ry car list table, with token and cycle
+// totals read from gormDB.
+func printCarList(out io.Writer, gormDB *gorm.DB, cars []models.Car) error {
 	// Build token and cycle maps.
 	ids := make([]string, len(cars))
 	for i, b := range cars {
@@ -271,7 +375,7 @@ Use --limit to cap the result set.`,
 	cmd.Flags().StringVar(&assignee, "assignee", "", "filter by assignee")
 	cmd.Flags().StringVar(&parentID, "parent", "", "filter by parent epic ID")
 	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of results (0 = unlimited)")
-	return cmd
+	return supportsJSON(cmd)
 }
 
 func runCarSearch(cmd *cobra.Command, configPath, query string, filters car.ListFilters, limit int) error {
@@ -279,6 +383,9 @@ func runCarSearch(cmd *cobra.Command, configPath, query string, filters car.List
 	if err != nil {
 		return err
 	}
+	if filters.ParentID, err = lookupCarID(cmd, gormDB, filters.ParentID); err != nil {
+		return err
+	}
 
 	cars, err := car.Search(gormDB, query, filters, limit)
 	if err != nil {
@@ -286,6 +393,9 @@ func runCarSearch(cmd *cobra.Command, configPath, query string, filters car.List
 	}
 
 	out := cmd.OutOrStdout()
+	if wantJSON(cmd) {
+		return writeCarsJSON(out, gormDB, cars)
+	}
 	if len(cars) == 0 {
 		fmt.Fprintln(out, "No cars found.")
 		return nil
@@ -357,7 +467,7 @@ func newCarShowCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
-	return cmd
+	return supportsJSON(cmd)
 }
 
 func runCarShow(cmd *cobra.Command, configPath, id string) error {
@@ -365,6 +475,9 @@ func runCarShow(cmd *cobra.Command, configPath, id string) error {
 	if err != nil {
 		return err
 	}
+	if id, err = lookupCarID(cmd, gormDB, id); err != nil {
+		return err
+	}
 
 	b, err := car.Get(gormDB, id)
 	if err != nil {
@@ -372,6 +485,9 @@ func runCarShow(cmd *cobra.Command, configPath, id string) error {
 	}
 
 	out := cmd.OutOrStdout()
+	if wantJSON(cmd) {
+		return writeCarDetailJSON(out, gormDB, b)
+	}
 	fmt.Fprintf(out, "ID:          %s\n", b.ID)
 	fmt.Fprintf(out, "Title:       %s\n", b.Title)
 	fmt.Fprintf(out, "Status:      %s\n", b.Status)
@@ -384,12 +500,24 @@ func runCarShow(cmd *cobra.Command, configPath, id string) error {
 		base = "main"
 	}
 	fmt.Fprintf(out, "Base Branch: %s\n", base)
+	if b.Estimate > 0 {
+		fmt.Fprintf(out, "Estimate:    %d\n", b.Estimate)
+	}
 	if b.Assignee != "" {
 		fmt.Fprintf(out, "Assignee:    %s\n", b.Assignee)
 	}
+	if b.Owner != "" {
+		fmt.Fprintf(out, "Owner:       @%s\n", b.Owner)
+	}
 	if b.ParentID != nil {
 		fmt.Fprintf(out, "Parent:      %s\n", *b.ParentID)
 	}
+	if b.ClonedFrom != "" {
+		fmt.Fprintf(out, "Cloned From: %s\n", b.ClonedFrom)
+	}
+	if b.ImportedFrom != "" {
+		fmt.Fprintf(out, "Imported From: merge %s (ry import git-history)\n", b.ImportedFrom)
+	}
 	if b.Type == "epic" {
 		summary, err := car.ChildrenSummary(gormDB, b.ID)
 		if err == nil {
@@ -412,6 +540,9 @@ func runCarShow(cmd *cobra.Command, configPath, id string) error {
 	if b.CompletedAt != nil {
 		fmt.Fprintf(out, "Completed:   %s\n", b.CompletedAt.Format("2006-01-02 15:04:05"))
 	}
+	if b.SwitchRetryAt != nil && b.SwitchAttempts > 0 {
+		fmt.Fprintf(out, "Merge Retry: %d after %s, next at %s\n", b.SwitchAttempts, b.SwitchFailure, b.SwitchRetryAt.Format("2006-01-02 15:04:05"))
+	}
 
 	if b.Description != "" {
 		fmt.Fprintf(out, "\nDescription:\n%s\n", b.Description)
@@ -520,6 +651,7 @@ func newCarUpdateCmd() *cobra.Command {
 		status      string
 		assignee    string
 		priority    int
+		estimate    int
 		description string
 		acceptance  string
 		design      string
@@ -543,6 +675,12 @@ func newCarUpdateCmd() *cobra.Command {
 			if cmd.Flags().Changed("priority") {
 				updates["priority"] = priority
 			}
+			if cmd.Flags().Changed("estimate") {
+				if estimate < 0 {
+					return fmt.Errorf("--estimate must not be negative")
+				}
+				updates["estimate"] = estimate
+			}
 			if cmd.Flags().Changed("description") {
 				updates["description"] = description
 			}
@@ -557,7 +695,7 @@ func newCarUpdateCmd() *cobra.Command {
 			}
 
 			if len(updates) == 0 {
-				return fmt.Errorf("no fields to update; use --status, --assignee, --priority, --description, --acceptance, --design, or --skip-tests")
+				return fmt.Errorf("no fields to update; use --status, --assignee, --priority, --estimate, --description, --acceptance, --design, or --skip-tests")
 			}
 
 			return runCarUpdate(cmd, configPath, args[0], updates)
@@ -568,6 +706,7 @@ func newCarUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&status, "status", "", "new status")
 	cmd.Flags().StringVar(&assignee, "assignee", "", "assign to engine")
 	cmd.Flags().IntVar(&priority, "priority", 0, "new priority")
+	cmd.Flags().IntVar(&estimate, "estimate", 0, "new estimate (relative size; 0 = unestimated)")
 	cmd.Flags().StringVar(&description, "description", "", "new description")
 	cmd.Flags().StringVar(&acceptance, "acceptance", "", "new acceptance criteria")
 	cmd.Flags().StringVar(&design, "design", "", "new design notes")
@@ -580,10 +719,22 @@ func runCarUpdate(cmd *cobra.Command, configPath, id string, updates map[string]
 	if err != nil {
 		return err
 	}
+	if id, err = resolveCarID(cmd, gormDB, id); err != nil {
+		return err
+	}
 
+	before, err := car.Get(gormDB, id)
+	if err != nil {
+		return err
+	}
 	if err := car.Update(gormDB, id, updates); err != nil {
 		return err
 	}
+	fields := make([]string, 0, len(updates))
+	for f := range updates {
+		fields = append(fields, f)
+	}
+	recordCarUndo(cmd, gormDB, undoActor(), "car update", before, fields)
 
 	fmt.Fprintf(cmd.OutOrStdout(), "Updated car %s\n", id)
 	return nil
@@ -593,15 +744,344 @@ func runCarUpdate(cmd *cobra.Command, configPath, id string, updates map[string]
 // It is a var so tests can override it with a SQLite-backed implementation.
 var connectFromConfig = defaultConnectFromConfig
 
+func newCarOwnCmd() *cobra.Command {
+	var (
+		configPath string
+		clearOwner bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "own <id> [@owner]",
+		Short: "Set the human owner of a car",
+		Long:  "Sets the human owner (reviewer) of a car, separate from the engine assignee. Owners are pinged by telegraph when their car merges, fails, or escalates. Use --clear to remove the owner.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearOwner == (len(args) == 2) {
+				return fmt.Errorf("specify an owner or --clear")
+			}
+			owner := ""
+			if len(args) == 2 {
+				owner = car.NormalizeOwner(args[1])
+				if owner == "" {
+					return fmt.Errorf("owner must not be empty")
+				}
+			}
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			before, err := car.Get(gormDB, id)
+			if err != nil {
+				return err
+			}
+			if err := car.SetOwner(gormDB, id, owner); err != nil {
+				return err
+			}
+			recordCarUndo(cmd, gormDB, undoActor(), "car own", before, []string{"owner"})
+			if owner == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Cleared owner of car %s\n", id)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Car %s is owned by @%s\n", id, owner)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().BoolVar(&clearOwner, "clear", false, "remove the car's owner")
+	return cmd
+}
+
+func newCarRevertCmd() *cobra.Command {
+	var (
+		configPath string
+		reason     string
+		runGate    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "revert <id>",
+		Short: "Revert a merged car",
+		Long: `Reverts a merged car's merge commit. A revert car linked to the original is
+created, the revert is committed on its branch and pushed, and the car is
+queued for the yardmaster's merge gate (tests, then merge or PR). When the
+revert car merges, the original is marked "reverted".
+
+If the revert conflicts, the revert car is left open for an engine. With
+--switch, the merge gate runs immediately instead of on the next yardmaster
+cycle.`,
+		Example: `  ry car revert car-a1b2c3d4
+  ry car revert car-a1b2c3d4 --reason "breaks login" --switch`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCarRevert(cmd, configPath, args[0], reason, runGate)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&reason, "reason", "", "why the car is being reverted")
+	cmd.Flags().BoolVar(&runGate, "switch", false, "run the merge gate on the revert car now")
+	return cmd
+}
+
+func runCarRevert(cmd *cobra.Command, configPath, carID, reason string, runGate bool) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
+	repoDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	result, err := yardmaster.RevertCar(gormDB, carID, yardmaster.RevertOpts{
+		RepoDir:      repoDir,
+		Config:       cfg,
+		BranchPrefix: cfg.BranchPrefix,
+		IDFormat:     car.IDFormatFromConfig(cfg),
+		RequestedBy:  cfg.Owner,
+		Reason:       reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	rc := result.RevertCar
+	fmt.Fprintf(out, "Created revert car %s for %s (merge %s)\n", rc.ID, carID, shortCommit(result.MergeCommit))
+	fmt.Fprintf(out, "  Branch: %s\n", rc.Branch)
+	if result.Conflict {
+		fmt.Fprintln(out, "Revert conflicts; the car is open for an engine to resolve.")
+		return nil
+	}
+	if !runGate {
+		fmt.Fprintln(out, "Queued for the merge gate; the yardmaster will test and merge it.")
+		return nil
+	}
+	return runSwitch(cmd, configPath, rc.ID, false, false)
+}
+
+func newCarCloneCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+		link       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clone <id>",
+		Short: "Create a new car from an existing car's description",
+		Long: `Creates a draft car with the same title, description, acceptance criteria,
+design notes, type, priority, and estimate as an existing car, for re-running
+similar work. The clone gets its own ID and branch from the current base
+branch; status, progress, and dependencies are not copied. It stays under the
+original's epic unless --track moves it to another track.
+
+With --link, the clone records the original, shown as "Cloned From" by
+ry car show.`,
+		Example: `  ry car clone car-a1b2c3d4
+  ry car clone car-a1b2c3d4 --track frontend --link`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCarClone(cmd, configPath, args[0], track, link)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track for the clone (default: the original's)")
+	cmd.Flags().BoolVar(&link, "link", false, "record the original car on the clone")
+	return cmd
+}
+
+func runCarClone(cmd *cobra.Command, configPath, carID, track string, link bool) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
+	if track != "" {
+		if err := checkCarTrack(cfg, track); err != nil {
+			return err
+		}
+	}
+	repoDir, _ := os.Getwd()
+	baseTrack := track
+	if baseTrack == "" {
+		if orig, err := car.Get(gormDB, carID); err == nil {
+			baseTrack = orig.Track
+		}
+	}
+
+	c, err := car.Clone(gormDB, carID, car.CloneOpts{
+		Track:        track,
+		Link:         link,
+		BranchPrefix: cfg.BranchPrefix,
+		BaseBranch:   engine.TrackBaseBranch(cfg, repoDir, baseTrack),
+		RequestedBy:  cfg.Owner,
+		IDFormat:     car.IDFormatFromConfig(cfg),
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Created car %s (clone of %s)\n", c.ID, carID)
+	fmt.Fprintf(out, "Branch: %s\n", c.Branch)
+	if c.ParentID != nil {
+		fmt.Fprintf(out, "Parent: %s\n", *c.ParentID)
+	}
+	fmt.Fprintf(out, "Publish it with: ry car publish %s\n", c.ID)
+	return nil
+}
+
+func newCarRerunCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "rerun <id>",
+		Short: "Reset a merge-failed or cancelled car to open on a fresh branch",
+		Long: `Sends a merge-failed or cancelled car back to open so an engine redoes the
+work from the current base branch. The car keeps its ID, progress notes, and
+dependencies; it gets a fresh branch (the old name with a -rerunN suffix) and
+a progress note recording the previous status and branch. The old branch is
+left in place for reference.
+
+To merge a merge-failed car's existing branch instead, move it back to done
+with ry car update --status done.`,
+		Example: `  ry car rerun car-a1b2c3d4`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCarRerun(cmd, configPath, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	return cmd
+}
+
+func runCarRerun(cmd *cobra.Command, configPath, carID string) error {
+	_, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
+
+	// Record the reopen in the event log; closing the bus waits for the write.
+	bus := events.NewBus()
+	persistEvents(bus, gormDB, currentUserName(), nil)
+	oldBranch, err := car.RerunWithBus(gormDB, bus, carID)
+	bus.(interface{ Close() }).Close()
+	if err != nil {
+		return err
+	}
+	c, err := car.Get(gormDB, carID)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Car %s reset to open\n", c.ID)
+	fmt.Fprintf(out, "Branch: %s (was %s)\n", c.Branch, oldBranch)
+	return nil
+}
+
+func newCarAdoptCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+		title      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "adopt <branch>",
+		Short: "Create a car from a hand-made branch and queue it for merge",
+		Long: `Creates a car for a branch that was started outside Railyard (locally or on
+origin) and queues it for the yardmaster's merge gate, so it is tested and
+merged or PR'd like any engine-produced car.
+
+The car's title defaults to the branch's first commit subject. Its track is
+inferred from the tracks' file_patterns matched against the branch's changed
+files; pass --track when the match is ambiguous.`,
+		Example: `  ry car adopt fix/login-timeout
+  ry car adopt origin/alice/hotfix --track backend --title "Hotfix session expiry"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCarAdopt(cmd, configPath, args[0], track, title)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track for the car (default: inferred from changed files)")
+	cmd.Flags().StringVar(&title, "title", "", "car title (default: the branch's first commit subject)")
+	return cmd
+}
+
+func runCarAdopt(cmd *cobra.Command, configPath, branch, track, title string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if track != "" {
+		if err := checkCarTrack(cfg, track); err != nil {
+			return err
+		}
+	}
+	repoDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	result, err := yardmaster.AdoptBranch(gormDB, branch, yardmaster.AdoptOpts{
+		RepoDir:     repoDir,
+		Config:      cfg,
+		BaseBranch:  engine.TrackBaseBranch(cfg, repoDir, track),
+		Track:       track,
+		Title:       title,
+		IDFormat:    car.IDFormatFromConfig(cfg),
+		RequestedBy: cfg.Owner,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	c := result.Car
+	fmt.Fprintf(out, "Created car %s from %s (%d commits)\n", c.ID, c.Branch, result.Commits)
+	if result.Inferred {
+		fmt.Fprintf(out, "  Track: %s (inferred from %d changed files)\n", c.Track, len(result.ChangedFiles))
+	} else {
+		fmt.Fprintf(out, "  Track: %s\n", c.Track)
+	}
+	fmt.Fprintln(out, "Queued for the merge gate; the yardmaster will test and merge it.")
+	return nil
+}
+
 func defaultConnectFromConfig(configPath string) (*config.Config, *gorm.DB, error) {
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("load config: %w", err)
 	}
+	if err := outbound.Apply(cfg.Network); err != nil {
+		return nil, nil, fmt.Errorf("network: %w", err)
+	}
 
 	gormDB, err := db.Connect(cfg.Database.Host, cfg.Database.Port, cfg.Database.Database, cfg.Database.Username, cfg.Database.Password)
 	if err != nil {
-		return nil, nil, fmt.Errorf("connect to %s: %w", cfg.Database.Database, err)
+		return nil, nil, withExitCode(ExitInfra, fmt.Errorf("connect to %s: %w", cfg.Database.Database, err))
+	}
+	if err := db.InstrumentQueries(gormDB, cfg.Database.QueryLog); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: query log: %v\n", err)
 	}
 
 	// Best-effort audit; do not fail startup if audit logging fails.
@@ -642,10 +1122,17 @@ func newCarDepAddCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if err := car.AddDep(gormDB, args[0], blockedBy, depType); err != nil {
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
 				return err
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Added dependency: %s blocked by %s\n", args[0], blockedBy)
+			if blockedBy, err = resolveCarID(cmd, gormDB, blockedBy); err != nil {
+				return err
+			}
+			if err := car.AddDep(gormDB, id, blockedBy, depType); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added dependency: %s blocked by %s\n", id, blockedBy)
 			return nil
 		},
 	}
@@ -670,14 +1157,18 @@ func newCarDepListCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			blockers, dependents, err := car.ListDeps(gormDB, args[0])
+			id, err := lookupCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			blockers, dependents, err := car.ListDeps(gormDB, id)
 			if err != nil {
 				return err
 			}
 
 			out := cmd.OutOrStdout()
 			if len(blockers) == 0 && len(dependents) == 0 {
-				fmt.Fprintf(out, "No dependencies for %s\n", args[0])
+				fmt.Fprintf(out, "No dependencies for %s\n", id)
 				return nil
 			}
 
@@ -725,10 +1216,17 @@ func newCarDepRemoveCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if err := car.RemoveDep(gormDB, args[0], blockedBy); err != nil {
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			if blockedBy, err = resolveCarID(cmd, gormDB, blockedBy); err != nil {
 				return err
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Removed dependency: %s blocked by %s\n", args[0], blockedBy)
+			if err := car.RemoveDep(gormDB, id, blockedBy); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed dependency: %s blocked by %s\n", id, blockedBy)
 			return nil
 		},
 	}
@@ -761,6 +1259,9 @@ func newCarReadyCmd() *cobra.Command {
 			}
 
 			out := cmd.OutOrStdout()
+			if wantJSON(cmd) {
+				return writeCarsJSON(out, gormDB, cars)
+			}
 			if len(cars) == 0 {
 				fmt.Fprintln(out, "No ready cars.")
 				return nil
@@ -779,7 +1280,7 @@ func newCarReadyCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().StringVar(&track, "track", "", "filter by track")
-	return cmd
+	return supportsJSON(cmd)
 }
 
 func newCarChildrenCmd() *cobra.Command {
@@ -804,6 +1305,9 @@ func runCarChildren(cmd *cobra.Command, configPath, parentID string) error {
 	if err != nil {
 		return err
 	}
+	if parentID, err = lookupCarID(cmd, gormDB, parentID); err != nil {
+		return err
+	}
 
 	children, err := car.GetChildren(gormDB, parentID)
 	if err != nil {
@@ -855,16 +1359,20 @@ With --recursive, also publishes all draft children (useful for epics).`,
 				return err
 			}
 
-			count, err := car.Publish(gormDB, args[0], recursive)
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			count, err := car.Publish(gormDB, id, recursive)
 			if err != nil {
 				return err
 			}
 
 			out := cmd.OutOrStdout()
 			if count == 0 {
-				fmt.Fprintf(out, "No draft cars to publish for %s\n", args[0])
+				fmt.Fprintf(out, "No draft cars to publish for %s\n", id)
 			} else {
-				fmt.Fprintf(out, "Published %d car(s) starting from %s\n", count, args[0])
+				fmt.Fprintf(out, "Published %d car(s) starting from %s\n", count, id)
 			}
 			return nil
 		},
@@ -899,6 +1407,10 @@ func newCarRememberCmd() *cobra.Command {
 }
 
 func runCarRemember(cmd *cobra.Command, gormDB *gorm.DB, carID, keyword, content string) error {
+	carID, err := resolveCarID(cmd, gormDB, carID)
+	if err != nil {
+		return err
+	}
 	if err := car.Remember(gormDB, carID, keyword, content); err != nil {
 		return err
 	}
@@ -938,6 +1450,10 @@ func newCarMemoriesCmd() *cobra.Command {
 }
 
 func runCarMemories(cmd *cobra.Command, gormDB *gorm.DB, carID, keyword string) error {
+	carID, err := lookupCarID(cmd, gormDB, carID)
+	if err != nil {
+		return err
+	}
 	memories, err := car.Memories(gormDB, carID, keyword)
 	if err != nil {
 		return err
@@ -984,6 +1500,10 @@ func newCarForgetCmd() *cobra.Command {
 }
 
 func runCarForget(cmd *cobra.Command, gormDB *gorm.DB, carID, keyword string) error {
+	carID, err := resolveCarID(cmd, gormDB, carID)
+	if err != nil {
+		return err
+	}
 	if err := car.Forget(gormDB, carID, keyword); err != nil {
 		return err
 	}
@@ -991,6 +1511,195 @@ func runCarForget(cmd *cobra.Command, gormDB *gorm.DB, carID, keyword string) er
 	return nil
 }
 
+// --- journal subcommand ---
+
+func newCarJournalCmd() *cobra.Command {
+	var (
+		configPath string
+		sessionID  string
+		sandbox    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "journal <car-id>",
+		Short: "Show the engine work journal for a car",
+		Long:  "Shows what engines actually did on a car, session by session: shell commands run, test runs and their outcomes, files touched, sandbox violations, commands refused by the command policy, and when each session started and ended. Use for postmortems on a bad change.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runCarJournal(cmd, gormDB, args[0], sessionID, sandbox)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&sessionID, "session", "", "only show entries from this session")
+	cmd.Flags().BoolVar(&sandbox, "sandbox", false, "only show sandbox violations")
+	return cmd
+}
+
+func runCarJournal(cmd *cobra.Command, gormDB *gorm.DB, carID, sessionID string, sandbox bool) error {
+	carID, err := lookupCarID(cmd, gormDB, carID)
+	if err != nil {
+		return err
+	}
+	entries, err := car.Journal(gormDB, carID)
+	if err != nil {
+		return err
+	}
+	if sessionID, err = matchSessionPrefix(cmd, entries, sessionID); err != nil {
+		return err
+	}
+	if sessionID != "" || sandbox {
+		filtered := entries[:0]
+		for _, e := range entries {
+			if (sessionID == "" || e.SessionID == sessionID) && (!sandbox || e.Kind == engine.JournalSandbox) {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+
+	out := cmd.OutOrStdout()
+	if len(entries) == 0 {
+		if sandbox {
+			fmt.Fprintf(out, "No sandbox violations for car %s.\n", carID)
+			return nil
+		}
+		fmt.Fprintf(out, "No journal entries for car %s.\n", carID)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSESSION\tENGINE\tKIND\tOUTCOME\tDETAIL")
+	for _, e := range entries {
+		outcome := e.Outcome
+		if outcome == "" {
+			outcome = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.CreatedAt.Format("2006-01-02 15:04:05"), e.SessionID, e.EngineID, e.Kind, outcome, journalDetail(e))
+	}
+	w.Flush()
+	return nil
+}
+
+// journalDetail renders an entry's detail on one line. File snapshots are
+// stored as a JSON array and shown comma-separated.
+func journalDetail(e models.JournalEntry) string {
+	if e.Kind == engine.JournalFiles {
+		var files []string
+		if err := json.Unmarshal([]byte(e.Detail), &files); err == nil {
+			return strings.Join(files, ", ")
+		}
+	}
+	return truncate(strings.ReplaceAll(e.Detail, "\n", " "), 120)
+}
+
+func newCarWatchCmd() *cobra.Command {
+	var (
+		configPath string
+		until      string
+		interval   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch <car-id>",
+		Short: "Follow a car's status changes, progress notes, and messages live",
+		Long: `Prints a car's status changes, engine progress notes, and messages about it
+(merge attempts, escalations) as they happen. Runs until Ctrl+C, or with
+--until until the car reaches that state, so scripts can block on completion:
+
+  ry car watch car-1a2b --until merged && deploy.sh
+
+--until merged waits for the merge; --until done also accepts done,
+merge-failed, and PR states. A car cancelled before reaching the target
+exits with an error.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if until != "" && until != "merged" && until != "done" {
+				return fmt.Errorf("invalid --until %q (use merged or done)", until)
+			}
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return runCarWatch(ctx, cmd, gormDB, args[0], until, interval)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&until, "until", "", "exit once the car is merged or done (merged|done)")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "poll interval")
+	return cmd
+}
+
+func runCarWatch(ctx context.Context, cmd *cobra.Command, gormDB *gorm.DB, carID, until string, interval time.Duration) error {
+	carID, err := lookupCarID(cmd, gormDB, carID)
+	if err != nil {
+		return err
+	}
+	w, err := car.NewWatcher(gormDB, carID)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	out := cmd.OutOrStdout()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.Poll()
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			printCarWatchEvent(out, e)
+		}
+		if until != "" {
+			reached, unreachable := car.UntilReached(until, w.Status())
+			if reached {
+				return nil
+			}
+			if unreachable {
+				return fmt.Errorf("car %s is %s and will not become %s", carID, w.Status(), until)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if until != "" {
+				return fmt.Errorf("interrupted before car %s became %s", carID, until)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func printCarWatchEvent(out io.Writer, e car.WatchEvent) {
+	ts := e.At.Format("15:04:05")
+	switch e.Kind {
+	case car.WatchStatus:
+		if e.OldStatus == "" {
+			fmt.Fprintf(out, "[%s] status: %s\n", ts, e.Status)
+		} else {
+			fmt.Fprintf(out, "[%s] status: %s → %s\n", ts, e.OldStatus, e.Status)
+		}
+	case car.WatchProgress:
+		fmt.Fprintf(out, "[%s] progress (%s): %s\n", ts, e.From, truncate(strings.ReplaceAll(e.Detail, "\n", " "), 200))
+	case car.WatchMessage:
+		fmt.Fprintf(out, "[%s] message from %s: %s\n", ts, e.From, truncate(strings.ReplaceAll(e.Detail, "\n", " "), 200))
+	}
+}
+
 // hasMultipleBaseBranches returns true when not all cars share the same base branch.
 func hasMultipleBaseBranches(cars []models.Car) bool {
 	if len(cars) == 0 {
@@ -1019,3 +1728,61 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// --- pr-preview subcommand ---
+
+func newCarPRPreviewCmd() *cobra.Command {
+	var (
+		configPath string
+		repoDir    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pr-preview <id>",
+		Short: "Render the pull request a car would open",
+		Long:  "Renders the PR title and body the yardmaster would use for a car, applying the pr_template (and any per-track override) from the config. Use it to check a template before a PR is opened; template errors are reported instead of falling back to the built-in format.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCarPRPreview(cmd, configPath, repoDir, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&repoDir, "repo", "", "repository used for the changed-files diff (default: current directory)")
+	return cmd
+}
+
+func runCarPRPreview(cmd *cobra.Command, configPath, repoDir, id string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if id, err = lookupCarID(cmd, gormDB, id); err != nil {
+		return err
+	}
+
+	c, err := car.Get(gormDB, id)
+	if err != nil {
+		return err
+	}
+	if repoDir == "" {
+		repoDir, _ = os.Getwd()
+	}
+	base := c.BaseBranch
+	if base == "" {
+		base = "main"
+	}
+
+	pr, err := yardmaster.RenderPR(gormDB, c, repoDir, base, cfg)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Title: %s\n\n", pr.Title)
+	fmt.Fprint(out, pr.Body)
+	if !strings.HasSuffix(pr.Body, "\n") {
+		fmt.Fprintln(out)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/engine"
+	ghapi "github.com/zulandar/railyard/internal/github"
+	"github.com/zulandar/railyard/internal/issueimport"
+	"gorm.io/gorm"
+)
+
+// newIssueImportClient returns the GitHub client for repo; tests replace it.
+var newIssueImportClient = func(repo string) (issueimport.Client, error) {
+	return issueimport.NewGitHub(ghapi.New(ghapi.Options{Token: ghapi.TokenFromEnv()}), repo)
+}
+
+func newCarImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create cars from an external tracker",
+	}
+	cmd.AddCommand(newCarImportGitHubCmd())
+	return cmd
+}
+
+func newCarImportGitHubCmd() *cobra.Command {
+	var (
+		configPath string
+		repo       string
+		label      string
+		track      string
+		publish    bool
+		dryRun     bool
+		update     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "github",
+		Short: "Create cars from open GitHub issues",
+		Long: `Creates a draft car for each open issue in the repository (only those
+carrying --label, if given) that has no car yet. Run it again to pick up new
+issues; issues already imported, or triaged by Bull, are skipped.
+
+Labels map to car fields: a label naming a configured track ("backend" or
+"track:backend") sets the track, falling back to --track; "bug" and "spike"
+set the type; "P0"–"P4" or "priority:N" set the priority. Issues in a
+milestone become children of an epic car titled after the milestone.
+
+With --update, each imported issue whose car changed status since the last
+run also gets a comment saying where the car is (claimed, merged, blocked,
+...). Cars link back to their issue, so merge commits reference it.
+
+The GitHub token is read from GITHUB_TOKEN or GH_TOKEN; --update needs one
+that can comment on issues.`,
+		Example: `  ry car import github --repo org/app --label railyard
+  ry car import github --label railyard --track backend --publish
+  ry car import github --label railyard --update     # from cron: import new issues, comment on progress
+  ry car import github --label railyard --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			if repo == "" {
+				owner, name, err := config.ParseGitHubRepo(cfg.RepoFor(track))
+				if err != nil {
+					return fmt.Errorf("--repo not set and config repo is not a GitHub repository: %w", err)
+				}
+				repo = owner + "/" + name
+			}
+			if track != "" {
+				if err := checkCarTrack(cfg, track); err != nil {
+					return err
+				}
+			}
+			client, err := newIssueImportClient(repo)
+			if err != nil {
+				return err
+			}
+
+			repoDir, _ := os.Getwd()
+			opts := issueimport.Options{
+				Label:        label,
+				DefaultTrack: track,
+				Publish:      publish,
+				DryRun:       dryRun,
+				BranchPrefix: cfg.BranchPrefix,
+				IDFormat:     car.IDFormatFromConfig(cfg),
+				RequestedBy:  cfg.Owner,
+				BaseBranch:   engine.TrackBaseBranch(cfg, repoDir, track),
+			}
+			for _, t := range cfg.Tracks {
+				opts.Tracks = append(opts.Tracks, t.Name)
+			}
+			return runCarImportGitHub(cmd.Context(), cmd.OutOrStdout(), gormDB, client, repo, opts, update && !dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&repo, "repo", "", "GitHub repository as owner/name (default: the config's repo)")
+	cmd.Flags().StringVar(&label, "label", "", "only import issues with this label")
+	cmd.Flags().StringVar(&track, "track", "", "track for issues without a track label (default: skip them)")
+	cmd.Flags().BoolVar(&publish, "publish", false, "open the new cars for engines instead of leaving them as drafts")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be imported without creating cars")
+	cmd.Flags().BoolVar(&update, "update", false, "also comment on imported issues whose car changed status")
+	return cmd
+}
+
+func runCarImportGitHub(ctx context.Context, out io.Writer, gormDB *gorm.DB, client issueimport.Client, repo string, opts issueimport.Options, update bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	res, err := issueimport.Import(ctx, gormDB, client, opts)
+	if res != nil {
+		printImportResult(out, repo, res, opts.DryRun)
+	}
+	if err != nil {
+		return err
+	}
+	if !update {
+		return nil
+	}
+
+	synced, err := issueimport.SyncStatus(ctx, gormDB, client)
+	for _, s := range synced {
+		fmt.Fprintf(out, "Commented on #%d: %s %s → %s\n", s.Issue, s.CarID, s.OldStatus, s.Status)
+	}
+	if err != nil {
+		return err
+	}
+	if len(synced) == 0 {
+		fmt.Fprintln(out, "No status changes to report.")
+	}
+	return nil
+}
+
+func printImportResult(out io.Writer, repo string, res *issueimport.Result, dryRun bool) {
+	for _, id := range res.Epics {
+		fmt.Fprintf(out, "Created epic %s\n", id)
+	}
+	if len(res.Imported) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ISSUE\tCAR\tTRACK\tEPIC\tTITLE")
+		for _, imp := range res.Imported {
+			carID := imp.CarID
+			if dryRun {
+				carID = "(dry run)"
+			}
+			fmt.Fprintf(w, "#%d\t%s\t%s\t%s\t%s\n", imp.Issue, carID, imp.Track, dashIfEmpty(imp.Epic), truncate(imp.Title, 60))
+		}
+		w.Flush()
+	}
+	for _, s := range res.Skipped {
+		fmt.Fprintf(out, "Skipped #%d: %s\n", s.Issue, s.Reason)
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(out, "%s %d issue(s) from %s, skipped %d\n", verb, len(res.Imported), repo, len(res.Skipped))
+}
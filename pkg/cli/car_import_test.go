@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/issueimport"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+type stubIssueClient struct {
+	issues   []issueimport.Issue
+	comments []string
+}
+
+func (s *stubIssueClient) ListIssues(context.Context, string) ([]issueimport.Issue, error) {
+	return s.issues, nil
+}
+
+func (s *stubIssueClient) Comment(_ context.Context, _ int, body string) error {
+	s.comments = append(s.comments, body)
+	return nil
+}
+
+func withIssueClient(t *testing.T, c issueimport.Client) {
+	t.Helper()
+	orig := newIssueImportClient
+	newIssueImportClient = func(string) (issueimport.Client, error) { return c, nil }
+	t.Cleanup(func() { newIssueImportClient = orig })
+}
+
+func TestCarImportGitHub(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+	client := &stubIssueClient{issues: []issueimport.Issue{
+		{Number: 12, Title: "Fix login", Labels: []issueimport.Label{{Name: "backend"}}},
+		{Number: 13, Title: "Unlabelled"},
+	}}
+	withIssueClient(t, client)
+
+	out, err := execCmd(t, []string{"car", "import", "github", "--repo", "org/app", "--dry-run", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(out, "Would import 1 issue(s) from org/app, skipped 1") {
+		t.Errorf("dry run output:\n%s", out)
+	}
+
+	out, err = execCmd(t, []string{"car", "import", "github", "--repo", "org/app", "--publish", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	var c models.Car
+	if err := gormDB.Where("source_issue = ?", 12).First(&c).Error; err != nil {
+		t.Fatalf("no car for #12: %v\n%s", err, out)
+	}
+	if c.Status != "open" || !strings.Contains(out, c.ID) {
+		t.Errorf("car = %+v, output:\n%s", c, out)
+	}
+
+	gormDB.Model(&models.Car{}).Where("id = ?", c.ID).Update("status", "claimed")
+	out, err = execCmd(t, []string{"car", "import", "github", "--repo", "org/app", "--update", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !strings.Contains(out, "Commented on #12") || len(client.comments) != 1 || !strings.Contains(client.comments[0], "is being worked on") {
+		t.Errorf("update output:\n%s\ncomments: %q", out, client.comments)
+	}
+}
+
+func TestCarImportGitHub_UnknownDefaultTrack(t *testing.T) {
+	cleanup := withMockDB(t, mockTestDB(t))
+	defer cleanup()
+	withIssueClient(t, &stubIssueClient{})
+
+	_, err := execCmd(t, []string{"car", "import", "github", "--repo", "org/app", "--track", "nope", "--config", "test.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "unknown track") {
+		t.Errorf("err = %v, want unknown track", err)
+	}
+}
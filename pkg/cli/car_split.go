@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"gopkg.in/yaml.v3"
+)
+
+// splitChildSpec is one child in the YAML list ry car split reads from stdin.
+type splitChildSpec struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Acceptance  string `yaml:"acceptance"`
+	Type        string `yaml:"type"`
+	Priority    *int   `yaml:"priority"`
+	Estimate    int    `yaml:"estimate"`
+}
+
+func newCarSplitCmd() *cobra.Command {
+	var (
+		configPath string
+		titles     []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "split <id>",
+		Short: "Turn a car that is too big into an epic with child cars",
+		Long: `Turns a task into an epic and creates its children, for when work turns
+out to be bigger than one car. Children keep the original's track, branch
+prefix, base branch, owner, design notes, and blockers; they are drafts when
+the original was a draft and open otherwise. Cars that were waiting on the
+original wait on every child instead.
+
+The original is released from its engine and closes on its own once the
+children are done. Commits already on its branch are not moved to the
+children; mention them in a child's description if they should be kept.
+
+Name children with repeated --child flags, or pipe a YAML list with title and
+optional description, acceptance, type, priority, and estimate fields.`,
+		Example: `  ry car split car-a1b2c3d4 --child "Add refund model" --child "Add refund API"
+  ry car split car-a1b2c3d4 <<'EOF'
+  - title: Add refund model
+    acceptance: migration runs on an empty database
+  - title: Add refund API
+    priority: 1
+  EOF`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCarSplit(cmd, configPath, args[0], titles)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringArrayVar(&titles, "child", nil, "title of a child car (repeatable; default: read a YAML list from stdin)")
+	return cmd
+}
+
+func runCarSplit(cmd *cobra.Command, configPath, carID string, titles []string) error {
+	var children []car.SplitChild
+	for _, t := range titles {
+		children = append(children, car.SplitChild{Title: t})
+	}
+	if len(children) == 0 {
+		in := cmd.InOrStdin()
+		if isInteractive(in) {
+			return fmt.Errorf("name the children with --child, or pipe a YAML list of them on stdin")
+		}
+		var err error
+		if children, err = readSplitChildren(in); err != nil {
+			return err
+		}
+	}
+
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
+
+	created, err := car.Split(gormDB, carID, car.SplitOpts{
+		Children:     children,
+		BranchPrefix: cfg.BranchPrefix,
+		IDFormat:     car.IDFormatFromConfig(cfg),
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Split %s into %d car(s); it is now their epic\n", carID, len(created))
+	for _, c := range created {
+		fmt.Fprintf(out, "  %s  %s  [%s]\n", c.ID, c.Title, c.Status)
+	}
+	if len(created) > 0 && created[0].Status == "draft" {
+		fmt.Fprintf(out, "Publish them with: ry car publish %s --recursive\n", carID)
+	}
+	return nil
+}
+
+// readSplitChildren parses the YAML list of children piped to ry car split.
+func readSplitChildren(in io.Reader) ([]car.SplitChild, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("read children from stdin: %w", err)
+	}
+	var specs []splitChildSpec
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("parse children from stdin: %w", err)
+	}
+	children := make([]car.SplitChild, len(specs))
+	for i, s := range specs {
+		children[i] = car.SplitChild{
+			Title:       s.Title,
+			Description: s.Description,
+			Acceptance:  s.Acceptance,
+			Type:        s.Type,
+			Priority:    s.Priority,
+			Estimate:    s.Estimate,
+		}
+	}
+	return children, nil
+}
@@ -2,11 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
 )
 
 func TestCarCmd_Help(t *testing.T) {
@@ -582,6 +585,74 @@ func TestRunCarCreate_KnownTrack(t *testing.T) {
 	}
 }
 
+func TestRunCarCreate_Attach(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	spec := models.Attachment{Name: "spec.md", Size: 6, Data: []byte("# spec")}
+	gormDB.Create(&spec)
+
+	out, err := execCmd(t, []string{"car", "create", "--title", "ok", "--track", "backend", "--config", "test.yaml",
+		"--description", "Build it.", "--attach", "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Attached: .railyard-attachments/1-spec.md") {
+		t.Errorf("output missing attachment path:\n%s", out)
+	}
+	var c models.Car
+	gormDB.First(&c)
+	if !strings.Contains(c.Description, "Build it.") || !strings.Contains(c.Description, "`.railyard-attachments/1-spec.md`") {
+		t.Errorf("description = %q, want the attachment listed", c.Description)
+	}
+
+	if _, err := execCmd(t, []string{"car", "create", "--title", "bad", "--track", "backend", "--config", "test.yaml", "--attach", "99"}); err == nil {
+		t.Error("unknown attachment: want error")
+	}
+}
+
+func TestRunCarCreate_Template(t *testing.T) {
+	gormDB := mockTestDB(t)
+	gormDB.Create(&models.Car{ID: "car-infra", Title: "infra", Track: "backend", Status: "open"})
+	orig := connectFromConfig
+	defer func() { connectFromConfig = orig }()
+	one := 1
+	connectFromConfig = func(string) (*config.Config, *gorm.DB, error) {
+		return &config.Config{
+			Owner:  "test-user",
+			Tracks: []config.TrackConfig{{Name: "backend", Language: "go"}},
+			Templates: map[string]config.CarTemplate{"bugfix": {
+				Type: "bug", Track: "backend", Priority: &one,
+				Description: "Steps to reproduce:", Acceptance: "Regression test added.",
+				Deps: []string{"car-infra"},
+			}},
+		}, gormDB, nil
+	}
+
+	out, err := execCmd(t, []string{"car", "create", "--template", "bugfix", "--title", "Fix login", "--acceptance", "Login works.", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Blocked by: car-infra") {
+		t.Errorf("output missing dependency:\n%s", out)
+	}
+	var c models.Car
+	gormDB.Where("title = ?", "Fix login").First(&c)
+	if c.Type != "bug" || c.Track != "backend" || c.Priority != 1 || c.Description != "Steps to reproduce:" || c.Acceptance != "Login works." {
+		t.Errorf("car = %+v, want template fields with the --acceptance override", c)
+	}
+	var deps int64
+	gormDB.Model(&models.CarDep{}).Where("car_id = ? AND blocked_by = ?", c.ID, "car-infra").Count(&deps)
+	if deps != 1 {
+		t.Errorf("deps = %d, want car-infra as blocker", deps)
+	}
+
+	if _, err := execCmd(t, []string{"car", "create", "--template", "feature", "--title", "x", "--config", "test.yaml"}); !errors.Is(err, config.ErrTemplateNotFound) {
+		t.Errorf("unknown template: err = %v", err)
+	}
+}
+
 // --- remember / memories / forget command tests ---
 
 func TestCarRememberCmd_Help(t *testing.T) {
@@ -997,3 +1068,153 @@ func TestRunCarForget_CarNotFound(t *testing.T) {
 		t.Fatal("expected error for nonexistent car")
 	}
 }
+
+func TestRunCarWatch_UntilMerged(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	gormDB.Create(&models.Car{ID: "car-w1", Title: "Watched", Track: "backend", Status: "done"})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		gormDB.Model(&models.Car{}).Where("id = ?", "car-w1").Update("status", "merged")
+	}()
+
+	out, err := execCmd(t, []string{"car", "watch", "car-w1", "--until", "merged", "--interval", "10ms", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if !strings.Contains(out, "status: done") || !strings.Contains(out, "status: done → merged") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRunCarWatch_UntilUnreachable(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	gormDB.Create(&models.Car{ID: "car-w2", Title: "Cancelled", Track: "backend", Status: "cancelled"})
+	_, err := execCmd(t, []string{"car", "watch", "car-w2", "--until", "merged", "--config", "test.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "will not become merged") {
+		t.Errorf("err = %v, want unreachable error", err)
+	}
+}
+
+func TestRunCarWatch_InvalidUntil(t *testing.T) {
+	_, err := execCmd(t, []string{"car", "watch", "car-w3", "--until", "shipped"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --until") {
+		t.Errorf("err = %v, want invalid --until", err)
+	}
+}
+
+func TestRunCarCloneAndRerun(t *testing.T) {
+	gormDB := mockTestDB(t)
+	gormDB.Create(&models.Car{ID: "car-orig", Title: "Add refunds", Description: "desc", Track: "backend", Status: "merge-failed", Branch: "ry/backend/car-orig"})
+	orig := connectFromConfig
+	defer func() { connectFromConfig = orig }()
+	connectFromConfig = func(string) (*config.Config, *gorm.DB, error) {
+		return &config.Config{
+			Owner:  "test-user",
+			Tracks: []config.TrackConfig{{Name: "backend", Language: "go"}, {Name: "frontend", Language: "typescript"}},
+		}, gormDB, nil
+	}
+
+	out, err := execCmd(t, []string{"car", "clone", "car-orig", "--track", "frontend", "--link", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("clone: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "(clone of car-orig)") {
+		t.Errorf("clone output:\n%s", out)
+	}
+	var clone models.Car
+	gormDB.Where("cloned_from = ?", "car-orig").First(&clone)
+	if clone.Track != "frontend" || clone.Description != "desc" || clone.Status != "draft" {
+		t.Errorf("clone = %+v", clone)
+	}
+	if _, err := execCmd(t, []string{"car", "clone", "car-orig", "--track", "mobile", "--config", "test.yaml"}); err == nil || !strings.Contains(err.Error(), "unknown track") {
+		t.Errorf("unknown track: err = %v", err)
+	}
+
+	out, err = execCmd(t, []string{"car", "rerun", "car-orig", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("rerun: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Branch: ry/backend/car-orig-rerun1 (was ry/backend/car-orig)") {
+		t.Errorf("rerun output:\n%s", out)
+	}
+	var reopened int64
+	gormDB.Model(&models.Event{}).Where("car_id = ? AND kind = ?", "car-orig", "CarStatusChanged").Count(&reopened)
+	if reopened != 1 {
+		t.Errorf("recorded %d CarStatusChanged events for the rerun, want 1", reopened)
+	}
+	if _, err := execCmd(t, []string{"car", "rerun", "car-orig", "--config", "test.yaml"}); err == nil {
+		t.Error("re-ran an open car")
+	}
+}
+
+func TestRunCarSplit(t *testing.T) {
+	gormDB := mockTestDB(t)
+	gormDB.Create(&models.Car{ID: "car-big", Title: "Payments rewrite", Track: "backend", Type: "task", Status: "in_progress", Assignee: "eng-1", Branch: "ry/backend/car-big"})
+	orig := connectFromConfig
+	defer func() { connectFromConfig = orig }()
+	connectFromConfig = func(string) (*config.Config, *gorm.DB, error) {
+		return &config.Config{Tracks: []config.TrackConfig{{Name: "backend", Language: "go"}}}, gormDB, nil
+	}
+
+	out, err := execCmd(t, []string{"car", "split", "car-big", "--child", "Refund model", "--child", "Refund API", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("split: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Split car-big into 2 car(s)") || !strings.Contains(out, "Refund API") {
+		t.Errorf("split output:\n%s", out)
+	}
+	var children []models.Car
+	gormDB.Where("parent_id = ?", "car-big").Find(&children)
+	if len(children) != 2 || children[0].Status != "open" || !strings.HasPrefix(children[0].Branch, "ry/backend/") {
+		t.Errorf("children = %+v", children)
+	}
+
+	if _, err := execCmd(t, []string{"car", "split", "car-big", "--child", "More", "--config", "test.yaml"}); err == nil || !strings.Contains(err.Error(), "already an epic") {
+		t.Errorf("second split: err = %v", err)
+	}
+}
+
+func TestReadSplitChildren(t *testing.T) {
+	children, err := readSplitChildren(strings.NewReader("- title: Refund model\n  acceptance: migrates\n- title: Refund API\n  priority: 0\n"))
+	if err != nil {
+		t.Fatalf("readSplitChildren: %v", err)
+	}
+	if len(children) != 2 || children[0].Acceptance != "migrates" || children[0].Priority != nil ||
+		children[1].Priority == nil || *children[1].Priority != 0 {
+		t.Errorf("children = %+v", children)
+	}
+	if _, err := readSplitChildren(strings.NewReader("title: not a list")); err == nil {
+		t.Error("accepted a mapping instead of a list")
+	}
+}
+
+func TestRunCarWebhook(t *testing.T) {
+	gormDB := mockTestDB(t)
+	gormDB.Create(&models.Car{ID: "car-hook", Title: "Deployable", Track: "backend", Status: "open"})
+	defer withMockDB(t, gormDB)()
+
+	out, err := execCmd(t, []string{"car", "webhook", "add", "car-hook", "https://deploy.example.com/hook", "--secret", "k", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("add: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Added webhook 1 on car-hook") {
+		t.Errorf("add output:\n%s", out)
+	}
+	out, err = execCmd(t, []string{"car", "webhook", "list", "car-hook", "--config", "test.yaml"})
+	if err != nil || !strings.Contains(out, "https://deploy.example.com/hook") || !strings.Contains(out, "yes") {
+		t.Errorf("list: %v\n%s", err, out)
+	}
+	if _, err := execCmd(t, []string{"car", "webhook", "remove", "car-hook", "1", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	out, _ = execCmd(t, []string{"car", "webhook", "list", "car-hook", "--config", "test.yaml"})
+	if !strings.Contains(out, "No webhooks for car-hook") {
+		t.Errorf("list after remove:\n%s", out)
+	}
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+)
+
+func newCarWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manage webhooks for a single car",
+		Long: `A car's webhooks receive that car's status changes (car.status_changed)
+and merge failures (switch.failed) as they happen, so a deployment pipeline
+or ticket system can follow one piece of work without taking every event
+from the webhooks: section of railyard.yaml. Payloads, headers, signing,
+and retries are the same; the yardmaster delivers them.`,
+	}
+
+	cmd.AddCommand(newCarWebhookAddCmd())
+	cmd.AddCommand(newCarWebhookListCmd())
+	cmd.AddCommand(newCarWebhookRemoveCmd())
+	return cmd
+}
+
+func newCarWebhookAddCmd() *cobra.Command {
+	var (
+		configPath string
+		secret     string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "add <car-id> <url>",
+		Short:   "Send a car's status changes and merge result to a URL",
+		Example: `  ry car webhook add car-a1b2c3d4 https://deploy.example.com/hooks/railyard --secret "$HOOK_SECRET"`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			hook, err := car.AddWebhook(gormDB, id, args[1], secret, cfg.Owner)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added webhook %d on %s: %s\n", hook.ID, id, hook.URL)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&secret, "secret", "", "HMAC-SHA256 key for the X-Railyard-Signature header")
+	return cmd
+}
+
+func newCarWebhookListCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "list <car-id>",
+		Short: "List a car's webhooks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			id, err := lookupCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			hooks, err := car.Webhooks(gormDB, id)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if len(hooks) == 0 {
+				fmt.Fprintf(out, "No webhooks for %s\n", id)
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tURL\tSIGNED\tADDED BY\tADDED")
+			for _, h := range hooks {
+				signed := "no"
+				if h.Secret != "" {
+					signed = "yes"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", h.ID, h.URL, signed, h.CreatedBy, h.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	return cmd
+}
+
+func newCarWebhookRemoveCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "remove <car-id> <webhook-id>",
+		Short: "Remove a car's webhook",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hookID, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid webhook ID %q", args[1])
+			}
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			if err := car.RemoveWebhook(gormDB, id, uint(hookID)); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed webhook %d from %s\n", hookID, id)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	return cmd
+}
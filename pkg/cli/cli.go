@@ -26,43 +26,70 @@ func newRootCmd() *cobra.Command {
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newDBCmd())
 	cmd.AddCommand(newCarCmd())
+	cmd.AddCommand(newEpicCmd())
 	cmd.AddCommand(newEngineCmd())
 	cmd.AddCommand(newCompleteCmd())
 	cmd.AddCommand(newProgressCmd())
 	cmd.AddCommand(newMessageCmd())
 	cmd.AddCommand(newInboxCmd())
+	cmd.AddCommand(newNoteCmd())
 	cmd.AddCommand(newDispatchCmd())
 	cmd.AddCommand(newYardmasterCmd())
 	cmd.AddCommand(newSwitchCmd())
+	cmd.AddCommand(newMergeCmd())
+	cmd.AddCommand(newBisectCmd())
+	cmd.AddCommand(newUndoCmd())
 	cmd.AddCommand(newStartCmd())
 	cmd.AddCommand(newStopCmd())
+	cmd.AddCommand(newContainerCmd())
+	cmd.AddCommand(newPauseCmd())
+	cmd.AddCommand(newResumeCmd())
+	cmd.AddCommand(newRecoverCmd())
 	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newReplayCmd())
+	cmd.AddCommand(newFindCmd())
+	cmd.AddCommand(newImportCmd())
 	cmd.AddCommand(newLogsCmd())
+	cmd.AddCommand(newEventsCmd())
 	cmd.AddCommand(newWatchCmd())
 	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newDebugCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newNetCmd())
 	cmd.AddCommand(newDashboardCmd())
 	cmd.AddCommand(newCocoIndexCmd())
 	cmd.AddCommand(newOverlayCmd())
 	cmd.AddCommand(newGitIgnoreCmd())
 	cmd.AddCommand(newMigrateCmd())
+	cmd.AddCommand(newUpgradeConfigCmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newGenCmd())
+	cmd.AddCommand(newReleaseCmd())
 	cmd.AddCommand(newTelegraphCmd())
 	cmd.AddCommand(newBullCmd())
 	cmd.AddCommand(newInspectCmd())
 	cmd.AddCommand(newInitCmd())
 	cmd.AddCommand(newPluginsCmd())
+	cmd.AddCommand(newExitCodesHelpCmd())
+	addOutputFlag(cmd)
+	addConfigOverrideFlags(cmd)
 	return cmd
 }
 
 func newVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	return supportsJSON(&cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
 		Run: func(cmd *cobra.Command, args []string) {
 			info, ok := debug.ReadBuildInfo()
 			v, c, d := resolveVersion(Version, Commit, Date, info, ok)
+			if wantJSON(cmd) {
+				writeJSON(cmd.OutOrStdout(), map[string]string{"version": v, "commit": c, "date": d})
+				return
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "ry %s (commit: %s, built: %s)\n", v, c, d)
 		},
-	}
+	})
 }
 
 // resolveVersion fills in version/commit/date from Go's build info when the
@@ -109,11 +136,10 @@ func resolveVersion(version, commit, date string, info *debug.BuildInfo, infoOK
 	return version, commit, date
 }
 
+// execute runs cmd and maps its error to an exit code (see exitcode.go).
 func execute(cmd *cobra.Command) int {
-	if err := cmd.Execute(); err != nil {
-		return 1
-	}
-	return 0
+	markUsageErrors(cmd)
+	return exitCodeFor(cmd.Execute())
 }
 
 // Run executes the railyard CLI and exits the process with the
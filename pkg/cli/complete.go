@@ -46,6 +46,9 @@ func runComplete(cmd *cobra.Command, configPath, carID, summary string) error {
 	if err != nil {
 		return err
 	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
 
 	// Verify the car exists and is in a completable state.
 	b, err := car.Get(gormDB, carID)
@@ -95,11 +98,20 @@ func runComplete(cmd *cobra.Command, configPath, carID, summary string) error {
 
 	// Push branch to remote BEFORE setting status to "done". This ensures the
 	// yardmaster never sees a "done" car whose branch hasn't been pushed yet.
+	// The pushed commit is recorded so the yardmaster can tell if the branch
+	// is later deleted or rewritten on origin.
+	updates := map[string]interface{}{
+		"status":       "done",
+		"completed_at": time.Now(),
+	}
 	if b.Branch != "" {
 		if pushErr := engine.PushBranch(cwd, b.Branch); pushErr != nil {
 			return fmt.Errorf("complete rejected: push branch %s failed: %w", b.Branch, pushErr)
 		}
 		slog.Info("ry complete: branch pushed", "car", carID, "branch", b.Branch)
+		if head, err := engine.HeadCommit(cwd); err == nil {
+			updates["pushed_head"] = head
+		}
 	}
 
 	// Transition to done. Conditional UPDATE + RowsAffected (not read-then-
@@ -108,10 +120,7 @@ func runComplete(cmd *cobra.Command, configPath, carID, summary string) error {
 	// from an active status (railyard-41w).
 	result := gormDB.Model(&models.Car{}).
 		Where("id = ? AND status IN ?", carID, []string{"claimed", "in_progress"}).
-		Updates(map[string]interface{}{
-			"status":       "done",
-			"completed_at": time.Now(),
-		})
+		Updates(updates)
 	if result.Error != nil {
 		return fmt.Errorf("complete car %s: %w", carID, result.Error)
 	}
@@ -165,6 +174,9 @@ func runProgress(cmd *cobra.Command, configPath, carID, note string) error {
 	if err != nil {
 		return err
 	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
 
 	// Verify the car exists.
 	b, err := car.Get(gormDB, carID)
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Trial and promote track configuration changes",
+	}
+	cmd.AddCommand(newConfigCanaryCmd())
+	cmd.AddCommand(newConfigPromoteCmd())
+	return cmd
+}
+
+func newConfigCanaryCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "canary",
+		Short: "Compare a track's canary cars with the rest of the track",
+		Long: `Compares the cars in a track's canary group (see the canary block in
+railyard.yaml) with the track's other cars claimed since the first canary
+car was: how many merged, how many passed the merge gate on the first
+attempt, and the median time from claim to completion.
+
+Promote the canary settings with "ry config promote" once the canary
+holds up.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			var tc *config.TrackConfig
+			for i := range cfg.Tracks {
+				if cfg.Tracks[i].Name == track {
+					tc = &cfg.Tracks[i]
+				}
+			}
+			if tc == nil {
+				return fmt.Errorf("config canary: track %q not found in config", track)
+			}
+			cmp, err := car.CompareCanary(gormDB, track)
+			if err != nil {
+				return err
+			}
+			printCanaryComparison(cmd.OutOrStdout(), cmp, tc.Canary)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track to report on")
+	cmd.MarkFlagRequired("track")
+	return cmd
+}
+
+func printCanaryComparison(out io.Writer, cmp *car.CanaryComparison, cn *config.CanaryConfig) {
+	if cn == nil {
+		fmt.Fprintf(out, "Track %s has no canary configured.\n", cmp.Track)
+	} else {
+		fmt.Fprintf(out, "Track %s: canary on %d%% of newly claimed cars\n", cmp.Track, cn.Percent)
+	}
+	if cmp.Since.IsZero() {
+		fmt.Fprintln(out, "No canary cars claimed yet.")
+		return
+	}
+	fmt.Fprintf(out, "Since %s\n\n", cmp.Since.Local().Format("2006-01-02 15:04"))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tCARS\tMERGED\tGATE FAILURES\tFIRST-PASS RATE\tMEDIAN CYCLE")
+	for _, row := range []struct {
+		name string
+		g    car.CanaryGroup
+	}{{"canary", cmp.Canary}, {"control", cmp.Control}} {
+		rate := "-"
+		if r := row.g.MergeRate(); r >= 0 {
+			rate = fmt.Sprintf("%.0f%%", r*100)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n", row.name, row.g.Cars, row.g.Merged,
+			row.g.GateFailures, rate, formatDuration(row.g.MedianCycle.Seconds()))
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%s\n", cmp.Verdict())
+}
+
+func newConfigPromoteCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Apply a track's canary settings to the whole track",
+		Long: `Moves the settings in a track's canary block into the track itself and
+removes the canary block, so every car on the track uses them. Comments
+elsewhere in the file are kept.
+
+The original file is copied to <config>.<timestamp>.bak before it is
+rewritten. Use --dry-run to print the promoted config instead. Running
+engines and the yardmaster pick the change up on their next config load.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigPromote(cmd.OutOrStdout(), configPath, track, dryRun, time.Now())
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track whose canary to promote")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the promoted config without writing it")
+	cmd.MarkFlagRequired("track")
+	return cmd
+}
+
+func runConfigPromote(out io.Writer, configPath, track string, dryRun bool, now time.Time) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return &config.LoadError{Err: fmt.Errorf("config: read %s: %w", configPath, err)}
+	}
+	promoted, err := config.PromoteCanary(data, track)
+	if err != nil {
+		return err
+	}
+	if _, err := config.Parse(promoted); err != nil {
+		return &config.LoadError{Err: fmt.Errorf("config promote: promoted config does not validate: %w", err)}
+	}
+	if dryRun {
+		out.Write(promoted)
+		return nil
+	}
+
+	backup, err := replaceConfigFile("config promote", configPath, data, promoted, now)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Promoted the %s canary in %s (original saved as %s)\n", track, configPath, backup)
+	return nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+const canaryConfig = `owner: alice
+repo: git@github.com:org/app.git
+tracks:
+  - name: backend
+    language: go
+    test_command: go test ./...
+    canary:
+      percent: 10
+      test_command: go test -race ./...
+`
+
+func TestRunConfigPromote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "railyard.yaml")
+	if err := os.WriteFile(path, []byte(canaryConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := runConfigPromote(&out, path, "backend", true, now); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(out.String(), "test_command: go test -race ./...") || strings.Contains(out.String(), "canary:") {
+		t.Errorf("dry-run output = %s", out.String())
+	}
+	if data, _ := os.ReadFile(path); string(data) != canaryConfig {
+		t.Errorf("dry run wrote the config: %s", data)
+	}
+
+	out.Reset()
+	if err := runConfigPromote(&out, path, "backend", false, now); err != nil {
+		t.Fatalf("runConfigPromote: %v", err)
+	}
+	if backup, err := os.ReadFile(path + ".20260304-050607.bak"); err != nil || string(backup) != canaryConfig {
+		t.Errorf("backup = %q, %v", backup, err)
+	}
+	data, _ := os.ReadFile(path)
+	cfg, err := config.Parse(data)
+	if err != nil {
+		t.Fatalf("promoted config: %v", err)
+	}
+	if tc := cfg.Tracks[0]; tc.Canary != nil || tc.TestCommand != "go test -race ./..." {
+		t.Errorf("promoted track = %+v", tc)
+	}
+
+	if err := runConfigPromote(&out, path, "backend", false, now); err == nil || !strings.Contains(err.Error(), "no canary to promote") {
+		t.Errorf("second promote err = %v", err)
+	}
+}
+
+func TestPrintCanaryComparison(t *testing.T) {
+	cmp := &car.CanaryComparison{
+		Track:   "backend",
+		Since:   time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+		Canary:  car.CanaryGroup{Cars: 2, Merged: 2, FirstPass: 2, MedianCycle: time.Hour, Completed: 2},
+		Control: car.CanaryGroup{Cars: 3, Merged: 1, FirstPass: 1, GateFailures: 1, MedianCycle: 3 * time.Hour, Completed: 2},
+	}
+	var out bytes.Buffer
+	printCanaryComparison(&out, cmp, &config.CanaryConfig{Percent: 20})
+	for _, want := range []string{
+		"Track backend: canary on 20% of newly claimed cars",
+		"canary   2     2       0              100%             1h 0m",
+		"control  3     1       1              50%              3h 0m",
+		"canary is on par with or ahead of control",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	printCanaryComparison(&out, &car.CanaryComparison{Track: "backend"}, nil)
+	if !strings.Contains(out.String(), "has no canary configured") || !strings.Contains(out.String(), "No canary cars claimed yet.") {
+		t.Errorf("output = %q", out.String())
+	}
+}
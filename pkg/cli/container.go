@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/db"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/orchestration"
+)
+
+func newContainerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "container",
+		Short: "Run the yard inside a container",
+	}
+	cmd.AddCommand(newContainerRunCmd())
+	return cmd
+}
+
+func newContainerRunCmd() *cobra.Command {
+	var (
+		configPath string
+		engines    int
+		interval   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Bootstrap and supervise the whole yard (container entrypoint)",
+		Long: `Entrypoint for the yard image (docker/Dockerfile.yard). It brings a yard up
+from an empty container and keeps it running:
+
+  1. Writes the config from the environment when it does not exist:
+     RAILYARD_CONFIG holds a complete railyard.yaml, or RAILYARD_OWNER,
+     RAILYARD_REPO, and RAILYARD_TRACKS (name:language[:engine_slots],...)
+     with optional RAILYARD_DB_HOST, RAILYARD_DB_PORT, RAILYARD_DB_NAME,
+     RAILYARD_DB_USER, and RAILYARD_DB_PASSWORD build a minimal one.
+  2. Clones the repo into the working directory when it is not a checkout.
+  3. Creates and migrates the database, as ry db init does.
+  4. Starts the yardmaster, engines, and telegraph (when configured), as
+     ry start does, plus Dispatch in its own tmux session.
+  5. Relaunches any of those daemons that exits, until SIGTERM or SIGINT,
+     then stops the yard gracefully. Engines are restarted by the
+     yardmaster as usual.
+
+Attach to Dispatch with docker exec -it <container> tmux attach -t
+railyard_<owner>_dispatch.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runContainer(cmd, configPath, engines, interval)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file (written from the environment when missing)")
+	cmd.Flags().IntVar(&engines, "engines", 0, "number of engines (default: sum of track engine_slots)")
+	cmd.Flags().DurationVar(&interval, "interval", 15*time.Second, "how often exited daemons are relaunched")
+	return cmd
+}
+
+func runContainer(cmd *cobra.Command, configPath string, engines int, interval time.Duration) error {
+	out := cmd.OutOrStdout()
+	if err := bootstrapContainerConfig(out, configPath, os.Getenv); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := ensureRepoCheckout(out, ".", engine.CloneURL(cfg.Repo)); err != nil {
+		return err
+	}
+	if err := runDBInit(cmd, configPath); err != nil {
+		return err
+	}
+	gormDB, err := db.Connect(cfg.Database.Host, cfg.Database.Port, cfg.Database.Database, cfg.Database.Username, cfg.Database.Password)
+	if err != nil {
+		return withExitCode(ExitInfra, fmt.Errorf("connect to %s: %w", cfg.Database.Database, err))
+	}
+
+	lock, err := orchestration.AcquireLock(cfg.Owner, "container run")
+	if err != nil {
+		return err
+	}
+	tmux := orchestration.TmuxFor(cfg)
+	started, err := orchestration.Start(orchestration.StartOpts{
+		Config:     cfg,
+		ConfigPath: configPath,
+		DB:         gormDB,
+		Engines:    engines,
+		Telegraph:  cfg.Telegraph.Platform != "",
+		Tmux:       tmux,
+	})
+	lock.Release()
+	if err != nil {
+		return err
+	}
+	dispatchSession, err := orchestration.LaunchDispatch(tmux, cfg, configPath)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: dispatch: %v\n", err)
+	}
+	fmt.Fprintf(out, "\nYard running: yardmaster, %d engine(s)", len(started.EngineSessions))
+	if started.TelegraphSession != "" {
+		fmt.Fprint(out, ", telegraph")
+	}
+	fmt.Fprintln(out)
+	if dispatchSession != "" {
+		fmt.Fprintf(out, "Attach to Dispatch with: tmux attach -t %s\n", dispatchSession)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigCh)
+
+	keeper := orchestration.NewKeeper(tmux, cfg, configPath, started, dispatchSession)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case sig := <-sigCh:
+			fmt.Fprintf(out, "Received %s, stopping the yard\n", sig)
+			return orchestration.Stop(orchestration.StopOpts{DB: gormDB, Config: cfg, Tmux: tmux})
+		case <-ticker.C:
+			relaunched, err := keeper.Tick()
+			for _, s := range relaunched {
+				fmt.Fprintf(out, "Relaunched %s\n", s)
+			}
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+			}
+		}
+	}
+}
+
+// bootstrapContainerConfig writes configPath from the RAILYARD_* variables
+// read through getenv unless it already exists.
+func bootstrapContainerConfig(out io.Writer, configPath string, getenv func(string) string) error {
+	if _, err := os.Stat(configPath); err == nil {
+		fmt.Fprintf(out, "Using config %s\n", configPath)
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("config: %w", err)
+	}
+	data, err := config.BootstrapYAML(getenv)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(configPath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	if err := os.WriteFile(configPath, data, 0o600); err != nil {
+		return fmt.Errorf("config: write %s: %w", configPath, err)
+	}
+	fmt.Fprintf(out, "Wrote config %s from the environment\n", configPath)
+	return nil
+}
+
+// ensureRepoCheckout clones repo into dir unless dir is already a git
+// checkout. Files already in dir, such as a mounted railyard.yaml, are kept:
+// the clone is made without a checkout and its .git moved into place.
+func ensureRepoCheckout(out io.Writer, dir, repo string) error {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return nil
+	}
+	tmp, err := os.MkdirTemp(dir, ".clone-")
+	if err != nil {
+		return fmt.Errorf("clone %s: %w", repo, err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if b, err := exec.Command("git", "clone", "--quiet", "--no-checkout", repo, tmp).CombinedOutput(); err != nil {
+		return fmt.Errorf("clone %s: %s", repo, strings.TrimSpace(string(b)))
+	}
+	if err := os.Rename(filepath.Join(tmp, ".git"), filepath.Join(dir, ".git")); err != nil {
+		return fmt.Errorf("clone %s: %w", repo, err)
+	}
+	reset := exec.Command("git", "reset", "--hard", "--quiet")
+	reset.Dir = dir
+	if b, err := reset.CombinedOutput(); err != nil {
+		return fmt.Errorf("check out %s: %s", repo, strings.TrimSpace(string(b)))
+	}
+	fmt.Fprintf(out, "Cloned %s\n", repo)
+	return nil
+}
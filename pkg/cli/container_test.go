@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func TestBootstrapContainerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etc", "railyard.yaml")
+	env := map[string]string{
+		config.EnvOwner:  "alice",
+		config.EnvRepo:   "org/app",
+		config.EnvTracks: "backend:go:2",
+	}
+	var out bytes.Buffer
+	if err := bootstrapContainerConfig(&out, path, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load written config: %v", err)
+	}
+	if cfg.Owner != "alice" || cfg.Tracks[0].EngineSlots != 2 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("config mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// An existing config is left alone.
+	env[config.EnvOwner] = "bob"
+	out.Reset()
+	if err := bootstrapContainerConfig(&out, path, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("bootstrap existing: %v", err)
+	}
+	if !strings.Contains(out.String(), "Using config") {
+		t.Errorf("output = %q", out.String())
+	}
+	if cfg, _ := config.Load(path); cfg.Owner != "alice" {
+		t.Errorf("existing config overwritten: owner %s", cfg.Owner)
+	}
+}
+
+func TestEnsureRepoCheckout(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.email=a@b", "-c", "user.name=a", "commit", "-q", "-m", "main"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = src
+		if b, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, b)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "railyard.yaml"), []byte("owner: alice\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := ensureRepoCheckout(&out, dir, src); err != nil {
+		t.Fatalf("ensureRepoCheckout: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("repo not checked out: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "railyard.yaml")); string(data) != "owner: alice\n" {
+		t.Errorf("mounted config changed: %q", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".clone-") {
+			t.Errorf("temporary clone left behind: %s", e.Name())
+		}
+	}
+
+	// A checkout is left as is.
+	out.Reset()
+	if err := ensureRepoCheckout(&out, dir, "/no/such/repo"); err != nil || out.Len() != 0 {
+		t.Errorf("existing checkout: err = %v, output %q", err, out.String())
+	}
+}
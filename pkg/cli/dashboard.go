@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/dashboard"
 	"github.com/zulandar/railyard/internal/events"
 	"gorm.io/gorm"
@@ -47,13 +48,13 @@ func runDashboard(cmd *cobra.Command, configPath string, port int, tlsCert, tlsK
 	// Retry DB connection to tolerate the database starting up (e.g. in K8s
 	// where the dashboard pod may start before the database is ready).
 	var gormDB *gorm.DB
-	var projectName string
+	var cfg *config.Config
 	const maxRetries = 30
 	for i := range maxRetries {
-		cfg, db, err := connectFromConfig(configPath)
+		c, db, err := connectFromConfig(configPath)
 		if err == nil {
 			gormDB = db
-			projectName = cfg.Project
+			cfg = c
 			break
 		}
 		// Config load errors are permanent — don't retry.
@@ -86,18 +87,41 @@ func runDashboard(cmd *cobra.Command, configPath string, port int, tlsCert, tlsK
 	// in-process subscribers could consume; today there are none in pod
 	// mode, so publishes are no-ops.
 	bus := events.NewBus()
+	defer persistEvents(bus, gormDB, "dashboard", nil)()
 
-	return dashboard.Start(ctx, dashboard.StartOpts{
-		DB:          gormDB,
-		Port:        port,
-		Out:         cmd.OutOrStdout(),
-		TLSCert:     tlsCert,
-		TLSKey:      tlsKey,
-		ProjectName: projectName,
-		Bus:         bus,
-		RateLimit: dashboard.RateLimitConfig{
-			Enabled:           rateLimitEnabled,
-			RequestsPerMinute: rateLimitRPM,
-		},
-	})
+	opts := dashboardSecurityOpts(cfg.Dashboard, tlsCert, tlsKey)
+	opts.DB = gormDB
+	opts.Port = port
+	opts.Out = cmd.OutOrStdout()
+	opts.ProjectName = cfg.Project
+	opts.Bus = bus
+	opts.RateLimit = dashboard.RateLimitConfig{
+		Enabled:           rateLimitEnabled,
+		RequestsPerMinute: rateLimitRPM,
+	}
+	return dashboard.Start(ctx, opts)
+}
+
+// dashboardSecurityOpts maps the dashboard config block onto StartOpts.
+// --tls-cert/--tls-key, when given, take precedence over dashboard.tls.
+func dashboardSecurityOpts(dc config.DashboardConfig, tlsCert, tlsKey string) dashboard.StartOpts {
+	opts := dashboard.StartOpts{
+		TLSCert:  dc.TLS.Cert,
+		TLSKey:   dc.TLS.Key,
+		ClientCA: dc.TLS.ClientCA,
+	}
+	if tlsCert != "" || tlsKey != "" {
+		opts.TLSCert, opts.TLSKey = tlsCert, tlsKey
+	} else if acme := dc.TLS.ACME; len(acme.Domains) > 0 {
+		opts.ACME = &dashboard.ACMEOpts{
+			Domains:      acme.Domains,
+			Email:        acme.Email,
+			CacheDir:     acme.CacheDir,
+			DirectoryURL: acme.DirectoryURL,
+		}
+	}
+	for _, t := range dc.Tokens {
+		opts.Tokens = append(opts.Tokens, dashboard.Token{Name: t.Name, Value: t.Token, Scope: t.Scope})
+	}
+	return opts
 }
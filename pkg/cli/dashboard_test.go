@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/dashboard"
 )
 
 func TestDashboardCmd_Help(t *testing.T) {
@@ -55,3 +58,26 @@ func TestDashboardCmd_DefaultPort(t *testing.T) {
 		t.Errorf("default port = %q, want %q", flag.DefValue, "8080")
 	}
 }
+
+func TestDashboardSecurityOpts(t *testing.T) {
+	dc := config.DashboardConfig{
+		TLS: config.DashboardTLSConfig{
+			ClientCA: "clients.pem",
+			ACME:     config.ACMEConfig{Domains: []string{"yard.example.com"}, CacheDir: ".railyard/acme"},
+		},
+		Tokens: []config.APITokenConfig{{Name: "ops", Token: "t1", Scope: config.TokenScopeAdmin}},
+	}
+
+	opts := dashboardSecurityOpts(dc, "", "")
+	if opts.ACME == nil || opts.ACME.Domains[0] != "yard.example.com" || opts.ClientCA != "clients.pem" {
+		t.Errorf("opts = %+v, want ACME and client CA from config", opts)
+	}
+	if len(opts.Tokens) != 1 || opts.Tokens[0].Value != "t1" || opts.Tokens[0].Scope != dashboard.ScopeAdmin {
+		t.Errorf("tokens = %+v", opts.Tokens)
+	}
+
+	opts = dashboardSecurityOpts(dc, "cert.pem", "key.pem")
+	if opts.ACME != nil || opts.TLSCert != "cert.pem" || opts.TLSKey != "key.pem" {
+		t.Errorf("flags did not override config: %+v", opts)
+	}
+}
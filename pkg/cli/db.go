@@ -23,6 +23,7 @@ func newDBCmd() *cobra.Command {
 	cmd.AddCommand(newDBInitCmd())
 	cmd.AddCommand(newDBResetCmd())
 	cmd.AddCommand(newDBStartCmd())
+	cmd.AddCommand(newDBPruneCmd())
 	return cmd
 }
 
@@ -46,7 +47,7 @@ func runDBInit(cmd *cobra.Command, configPath string) error {
 	out := cmd.OutOrStdout()
 
 	// Load config
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
@@ -150,7 +151,7 @@ func runDBReset(cmd *cobra.Command, configPath, dbName string, skipConfirm bool)
 	} else {
 		// Load config to get database name.
 		var err error
-		cfg, err = config.Load(configPath)
+		cfg, err = loadConfig(configPath)
 		if err != nil {
 			return fmt.Errorf("load config: %w", err)
 		}
@@ -268,7 +269,7 @@ func isLocalHost(host string) bool {
 func runDBStart(cmd *cobra.Command, configPath string) error {
 	out := cmd.OutOrStdout()
 
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
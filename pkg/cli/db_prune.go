@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/retention"
+)
+
+func newDBPruneCmd() *cobra.Command {
+	var (
+		configPath string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove operational data past its retention window",
+		Long: `Applies the retention section of the config now: events, turns of
+finished dispatch sessions, agent pane output (token counts are kept), and
+the yardmaster's failed merge-gate notes older than their windows are
+removed. With retention.enabled the yardmaster does this once a day; prune
+runs it on demand whether or not that is set.
+
+--dry-run reports how many rows each window would remove.`,
+		Example: `  ry db prune --dry-run
+  ry db prune`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			results, err := retention.Apply(gormDB, cfg.Retention, time.Now(), dryRun)
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			for _, r := range results {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %d %s row(s) older than %s\n", verb, r.Rows, r.Kind, r.Cutoff.Local().Format("2006-01-02"))
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be removed without removing it")
+	return cmd
+}
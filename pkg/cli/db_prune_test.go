@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestDBPrune(t *testing.T) {
+	gormDB := mockTestDB(t)
+	orig := connectFromConfig
+	connectFromConfig = func(configPath string) (*config.Config, *gorm.DB, error) {
+		return &config.Config{Retention: config.RetentionConfig{EventsDays: 90}}, gormDB, nil
+	}
+	defer func() { connectFromConfig = orig }()
+
+	gormDB.Create(&models.Event{Kind: "CarMerged", CreatedAt: time.Now().AddDate(0, 0, -100)})
+	gormDB.Create(&models.Event{Kind: "CarMerged", CreatedAt: time.Now()})
+
+	out, err := execCmd(t, []string{"db", "prune", "--dry-run", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("db prune --dry-run: %v", err)
+	}
+	if !strings.Contains(out, "Would remove 1 events row(s)") {
+		t.Errorf("dry run output = %q", out)
+	}
+
+	out, err = execCmd(t, []string{"db", "prune", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("db prune: %v", err)
+	}
+	if !strings.Contains(out, "Removed 1 events row(s)") {
+		t.Errorf("output = %q", out)
+	}
+	var left int64
+	gormDB.Model(&models.Event{}).Count(&left)
+	if left != 1 {
+		t.Errorf("events left = %d, want 1", left)
+	}
+}
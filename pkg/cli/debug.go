@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/db"
+)
+
+func newDebugCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Diagnostics for slow or misbehaving yards",
+	}
+	cmd.AddCommand(newDebugQueriesCmd())
+	return cmd
+}
+
+func newDebugQueriesCmd() *cobra.Command {
+	var (
+		configPath string
+		logPath    string
+		top        int
+		since      time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "queries",
+		Short: "Summarize the slow-query log by query and subsystem",
+		Long: `Reads the slow-query log and ranks queries by the total time spent in
+them, with the subsystems and call sites that issued them. Enable the log
+in railyard.yaml first:
+
+  database:
+    query_log:
+      enabled: true
+      slow_ms: 200   # log queries taking at least this long
+
+Every ry process that connects to the database (status, engines, the
+yardmaster, dispatch, telegraph) appends to the same file.`,
+		Example: `  ry debug queries --top 20
+  ry debug queries --since 1h`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := logPath
+			if path == "" {
+				cfg, err := loadConfig(configPath)
+				if err != nil {
+					return fmt.Errorf("load config: %w", err)
+				}
+				path = cfg.Database.QueryLog.Path
+				if !cfg.Database.QueryLog.Enabled {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Note: database.query_log.enabled is false in %s; no new queries are being logged.\n", configPath)
+				}
+			}
+			var cutoff time.Time
+			if since > 0 {
+				cutoff = time.Now().Add(-since)
+			}
+			return runDebugQueries(cmd.OutOrStdout(), path, top, cutoff)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&logPath, "log", "", "slow-query log to read (default: database.query_log.path)")
+	cmd.Flags().IntVar(&top, "top", 20, "number of queries to show")
+	cmd.Flags().DurationVar(&since, "since", 0, "only count queries from this long ago onward (e.g. 1h)")
+	return cmd
+}
+
+func runDebugQueries(out io.Writer, path string, top int, since time.Time) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(out, "No slow queries logged (%s does not exist).\n", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("debug queries: %w", err)
+	}
+	defer f.Close()
+
+	records, err := db.ReadQueryLog(f, since)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(out, "No slow queries logged in %s.\n", path)
+		return nil
+	}
+	all := db.SummarizeQueries(records, 0)
+	sums := all
+	if top > 0 && len(sums) > top {
+		sums = sums[:top]
+	}
+
+	fmt.Fprintf(out, "%d slow queries, %d distinct (showing top %d by total time)\n\n", len(records), len(all), len(sums))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tCOUNT\tTOTAL\tAVG\tMAX\tSUBSYSTEMS\tQUERY")
+	for i, s := range sums {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			i+1, s.Count, formatMs(s.TotalMs), formatMs(s.AvgMs()), formatMs(s.MaxMs), formatSubsystems(s.Subsystems), truncate(s.SQL, 70))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nTop call site per query:")
+	for i, s := range sums {
+		caller := s.TopCaller
+		if caller == "" {
+			caller = "-"
+		}
+		fmt.Fprintf(out, "  %d  %s\n", i+1, caller)
+	}
+	return nil
+}
+
+// formatMs renders a millisecond duration compactly: 240ms, 1.9s.
+func formatMs(ms float64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%.0fms", ms)
+	}
+	return fmt.Sprintf("%.1fs", ms/1000)
+}
+
+// formatSubsystems lists subsystems by record count, busiest first:
+// "cli:400,engine:12".
+func formatSubsystems(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s:%d", name, counts[name])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunDebugQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.jsonl")
+	log := `{"time":"2026-05-01T11:00:00Z","duration_ms":300,"sql":"SELECT * FROM cars WHERE status = ?","subsystem":"cli","caller":"cli.runStatus (status.go:10)"}
+{"time":"2026-05-01T11:01:00Z","duration_ms":1900,"sql":"SELECT * FROM cars WHERE status = ?","subsystem":"yardmaster","caller":"yardmaster.poll (daemon.go:5)"}
+{"time":"2026-05-01T11:02:00Z","duration_ms":250,"sql":"SELECT * FROM engines","subsystem":"cli","caller":"cli.runStatus (status.go:12)"}
+`
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runDebugQueries(&out, path, 1, time.Time{}); err != nil {
+		t.Fatalf("runDebugQueries: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"3 slow queries, 2 distinct (showing top 1 by total time)",
+		"2.2s", "1.1s", "1.9s",
+		"cli:1,yardmaster:1",
+		"SELECT * FROM cars WHERE status = ?",
+		"  1  cli.runStatus (status.go:10)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "FROM engines") {
+		t.Errorf("--top 1 should hide the second query:\n%s", got)
+	}
+}
+
+func TestRunDebugQueries_NoLog(t *testing.T) {
+	var out bytes.Buffer
+	if err := runDebugQueries(&out, filepath.Join(t.TempDir(), "missing.jsonl"), 20, time.Time{}); err != nil {
+		t.Fatalf("runDebugQueries: %v", err)
+	}
+	if !strings.Contains(out.String(), "No slow queries logged") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestFormatMs(t *testing.T) {
+	for ms, want := range map[float64]string{0.4: "0ms", 240: "240ms", 999.4: "999ms", 1900: "1.9s", 98200: "98.2s"} {
+		if got := formatMs(ms); got != want {
+			t.Errorf("formatMs(%v) = %q, want %q", ms, got, want)
+		}
+	}
+}
@@ -1,18 +1,29 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"os/user"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/agentbackend"
+	"github.com/zulandar/railyard/internal/agentloop"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/db"
 	"github.com/zulandar/railyard/internal/dispatch"
+	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/outbound"
 	"github.com/zulandar/railyard/internal/telegraph"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
@@ -20,11 +31,26 @@ func newDispatchCmd() *cobra.Command {
 	var configPath string
 
 	cmd := &cobra.Command{
-		Use:   "dispatch",
+		Use:   "dispatch [request]",
 		Short: "Start the Dispatch planner agent",
-		Long:  "Starts an interactive Claude Code session with the Dispatch planner prompt. Acquires a dispatch lock to prevent concurrent sessions.",
+		Long: `Starts an interactive Claude Code session with the Dispatch planner prompt.
+Acquires a dispatch lock to prevent concurrent sessions.
+
+Given a request, dispatch runs it without an interactive session instead:
+the call blocks while the planner works, streams its conversation to
+stderr, and when it finishes prints the cars it created as JSON on stdout:
+
+  {"session":12,"cars":["car-a1b2c","car-d3e4f"]}
+
+The planner cannot ask follow-up questions in this mode, so put everything
+it needs in the request. The exit status is non-zero if the agent failed;
+cars created before the failure are still listed.`,
+		Example: `  ry dispatch
+  ry dispatch "Add rate limiting to the public API, per API key"
+  ry dispatch "Fix the flaky login test" | jq -r '.cars[]'`,
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDispatch(cmd, configPath)
+			return runDispatch(cmd, configPath, strings.TrimSpace(strings.Join(args, " ")))
 		},
 	}
 
@@ -32,11 +58,14 @@ func newDispatchCmd() *cobra.Command {
 	return cmd
 }
 
-func runDispatch(cmd *cobra.Command, configPath string) error {
-	cfg, err := config.Load(configPath)
+func runDispatch(cmd *cobra.Command, configPath, request string) error {
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	if err := outbound.Apply(cfg.Network); err != nil {
+		return fmt.Errorf("network: %w", err)
+	}
 
 	// Sync embedded CocoIndex scripts for dispatch MCP config.
 	if err := ensureCocoIndexScripts(cfg.CocoIndex.ScriptsPath); err != nil {
@@ -47,6 +76,9 @@ func runDispatch(cmd *cobra.Command, configPath string) error {
 	if err != nil {
 		return fmt.Errorf("connect to %s: %w", cfg.Database.Database, err)
 	}
+	if err := db.InstrumentQueries(gormDB, cfg.Database.QueryLog); err != nil {
+		log.Printf("query log warning: %v", err)
+	}
 
 	// Auto-migrate dispatch session table.
 	if err := gormDB.AutoMigrate(&models.DispatchSession{}); err != nil {
@@ -65,19 +97,24 @@ func runDispatch(cmd *cobra.Command, configPath string) error {
 		heartbeatInterval = time.Duration(cfg.Telegraph.DispatchLock.HeartbeatIntervalSec) * time.Second
 	}
 
-	// Resolve current user name.
-	userName := "local"
-	if u, err := user.Current(); err == nil && u.Username != "" {
-		userName = u.Username
+	// A paused yard creates no cars, so there is nothing to dispatch.
+	if err := yard.CheckRunning(gormDB); err != nil {
+		return fmt.Errorf("dispatch: %w", err)
 	}
 
+	userName := currentUserName()
+
 	// Acquire dispatch lock.
 	session, err := telegraph.AcquireLock(gormDB, "local", userName, "local", "local", timeout)
 	if err != nil {
 		return fmt.Errorf("dispatch: %w (another dispatch session is active — use Telegraph or wait)", err)
 	}
 
+	// With a request, stdout carries only the JSON result.
 	out := cmd.OutOrStdout()
+	if request != "" {
+		out = cmd.ErrOrStderr()
+	}
 	fmt.Fprintf(out, "Dispatch lock acquired (session %d, user %s)\n", session.ID, userName)
 
 	// Start heartbeat in background.
@@ -95,6 +132,20 @@ func runDispatch(cmd *cobra.Command, configPath string) error {
 
 	repoDir, _ := os.Getwd()
 
+	if request != "" {
+		if len(cfg.Tracks) == 0 {
+			return fmt.Errorf("dispatch: at least one track must be configured")
+		}
+		client, useNativeLoop, err := agentbackend.Resolve(cfg)
+		if err != nil {
+			return fmt.Errorf("dispatch: native loop: %w", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		spawner := newDispatchSpawner(cfg, repoDir, client, useNativeLoop, nil)
+		return runDispatchRequest(ctx, cmd.OutOrStdout(), out, gormDB, spawner, session.ID, request)
+	}
+
 	return dispatch.Start(dispatch.StartOpts{
 		ConfigPath: configPath,
 		Config:     cfg,
@@ -122,3 +173,104 @@ func startHeartbeat(gormDB *gorm.DB, sessionID uint, interval time.Duration) fun
 	}()
 	return func() { close(done) }
 }
+
+// dispatchResult is what `ry dispatch <request>` prints on stdout.
+type dispatchResult struct {
+	Session uint     `json:"session"`
+	Cars    []string `json:"cars"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// runDispatchRequest runs one dispatch request to completion without an
+// interactive session: it relays the planner's conversation to errOut as it
+// happens, then writes the cars the session created to out as JSON. Cars
+// are attributed through dispatch.SessionEnv, which the agent's `ry car
+// create` calls inherit.
+func runDispatchRequest(ctx context.Context, out, errOut io.Writer, gormDB *gorm.DB, spawner telegraph.ProcessSpawner, sessionID uint, request string) error {
+	prev, had := os.LookupEnv(dispatch.SessionEnv)
+	os.Setenv(dispatch.SessionEnv, strconv.FormatUint(uint64(sessionID), 10))
+	defer func() {
+		if had {
+			os.Setenv(dispatch.SessionEnv, prev)
+		} else {
+			os.Unsetenv(dispatch.SessionEnv)
+		}
+	}()
+
+	proc, err := spawner.Spawn(ctx, request)
+	if err != nil {
+		return fmt.Errorf("dispatch: %w", err)
+	}
+	defer proc.Close()
+
+	for line := range proc.Recv() {
+		fmt.Fprintln(errOut, line)
+	}
+	<-proc.Done()
+	runErr := proc.ExitErr()
+	if runErr == nil && ctx.Err() != nil {
+		runErr = ctx.Err()
+	}
+
+	cars, err := telegraph.SessionCars(gormDB, sessionID)
+	if err != nil {
+		return err
+	}
+	res := dispatchResult{Session: sessionID, Cars: cars}
+	if runErr != nil {
+		res.Error = runErr.Error()
+	}
+	if err := json.NewEncoder(out).Encode(res); err != nil {
+		return fmt.Errorf("dispatch: write result: %w", err)
+	}
+	if runErr != nil {
+		return fmt.Errorf("dispatch: agent: %w", runErr)
+	}
+	return nil
+}
+
+// recordDispatchCar adds carID to the dispatch session named by
+// dispatch.SessionEnv, when `ry car create` runs under a dispatch agent.
+func recordDispatchCar(errOut io.Writer, gormDB *gorm.DB, carID string) {
+	raw := os.Getenv(dispatch.SessionEnv)
+	if raw == "" {
+		return
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		fmt.Fprintf(errOut, "Warning: ignoring %s=%q: not a session ID\n", dispatch.SessionEnv, raw)
+		return
+	}
+	if err := telegraph.RecordCarCreated(gormDB, uint(id), carID); err != nil {
+		fmt.Fprintf(errOut, "Warning: %v\n", err)
+	}
+}
+
+// newDispatchSpawner builds the spawner that runs dispatch agents for
+// Telegraph and `ry dispatch <request>`. Worktree, prompt, and MCP setup
+// happen on each Spawn so a transient failure does not stick.
+func newDispatchSpawner(cfg *config.Config, repoDir string, loopClient agentloop.Completer, useNativeLoop bool, httpAgent *telegraph.HTTPSpawner) *telegraph.LazySpawner {
+	return &telegraph.LazySpawner{
+		RenderPrompt: func() (string, error) {
+			return dispatch.RenderPrompt(cfg)
+		},
+		EnsureWorktree: func() (string, error) {
+			return engine.EnsureDispatchWorktree(repoDir)
+		},
+		SyncWorktree: func(worktreeDir string) error {
+			baseBranch := engine.DetectBaseBranch(repoDir, cfg.DefaultBranch)
+			return engine.SyncWorktreeToBranch(worktreeDir, baseBranch, repoDir)
+		},
+		WriteMCPConfig: func(worktreeDir string) error {
+			return dispatch.WriteDispatchMCPConfig(worktreeDir, cfg)
+		},
+		Model:         cfg.AgentModel,
+		UseNativeLoop: useNativeLoop,
+		Client:        loopClient,
+		// Native loop has no MCP client; give it the codesearch tool directly
+		// (main-index profile: all track main tables). nil when CocoIndex is
+		// unconfigured, so the tool is simply absent.
+		CodeSearch: engine.MainIndexCodeSearchParams(cfg),
+		HTTP:       httpAgent,
+	}
+}
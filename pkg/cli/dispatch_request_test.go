@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/dispatch"
+	"github.com/zulandar/railyard/internal/telegraph"
+	"gorm.io/gorm"
+)
+
+// scriptedProcess is a finished dispatch agent: it has already emitted its
+// lines and exited with err.
+type scriptedProcess struct {
+	recv chan string
+	done chan struct{}
+	err  error
+}
+
+func (p *scriptedProcess) Send(string) error     { return errors.New("one-shot") }
+func (p *scriptedProcess) Recv() <-chan string   { return p.recv }
+func (p *scriptedProcess) Done() <-chan struct{} { return p.done }
+func (p *scriptedProcess) ExitErr() error        { return p.err }
+func (p *scriptedProcess) Stderr() string        { return "" }
+func (p *scriptedProcess) Close() error          { return nil }
+
+// scriptedSpawner "creates" cars the way an agent's `ry car create` would,
+// by reading the session from the environment, then replays lines.
+type scriptedSpawner struct {
+	t      *testing.T
+	db     *gorm.DB
+	cars   []string
+	lines  []string
+	err    error
+	prompt string
+}
+
+func (s *scriptedSpawner) Spawn(ctx context.Context, prompt string) (telegraph.Process, error) {
+	s.prompt = prompt
+	var errOut bytes.Buffer
+	for _, id := range s.cars {
+		recordDispatchCar(&errOut, s.db, id)
+	}
+	if errOut.Len() > 0 {
+		s.t.Errorf("recordDispatchCar: %s", errOut.String())
+	}
+	p := &scriptedProcess{recv: make(chan string, len(s.lines)), done: make(chan struct{}), err: s.err}
+	for _, l := range s.lines {
+		p.recv <- l
+	}
+	close(p.recv)
+	close(p.done)
+	return p, nil
+}
+
+func TestRunDispatchRequest(t *testing.T) {
+	gormDB := mockTestDB(t)
+	session, err := telegraph.AcquireLock(gormDB, "local", "alice", "local", "local", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := &scriptedSpawner{t: t, db: gormDB, cars: []string{"car-a", "car-b"}, lines: []string{"Planning…", "Created 2 cars."}}
+
+	var out, errOut bytes.Buffer
+	if err := runDispatchRequest(context.Background(), &out, &errOut, gormDB, sp, session.ID, "build X"); err != nil {
+		t.Fatalf("runDispatchRequest: %v", err)
+	}
+	if sp.prompt != "build X" {
+		t.Errorf("prompt = %q", sp.prompt)
+	}
+	if !strings.Contains(errOut.String(), "Created 2 cars.") {
+		t.Errorf("conversation not streamed to stderr: %q", errOut.String())
+	}
+	var res dispatchResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out.String())
+	}
+	if res.Session != session.ID || strings.Join(res.Cars, ",") != "car-a,car-b" || res.Error != "" {
+		t.Errorf("result = %+v", res)
+	}
+	if _, set := os.LookupEnv(dispatch.SessionEnv); set {
+		t.Errorf("%s left set after the request", dispatch.SessionEnv)
+	}
+}
+
+func TestRunDispatchRequest_AgentFails(t *testing.T) {
+	gormDB := mockTestDB(t)
+	session, err := telegraph.AcquireLock(gormDB, "local", "alice", "local", "local", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := &scriptedSpawner{t: t, db: gormDB, cars: []string{"car-a"}, err: errors.New("exit status 1")}
+
+	var out, errOut bytes.Buffer
+	if err := runDispatchRequest(context.Background(), &out, &errOut, gormDB, sp, session.ID, "build X"); err == nil {
+		t.Fatal("runDispatchRequest succeeded, want the agent's error")
+	}
+	var res dispatchResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out.String())
+	}
+	if len(res.Cars) != 1 || res.Error != "exit status 1" {
+		t.Errorf("result = %+v, want car-a and the error", res)
+	}
+}
+
+func TestRecordDispatchCar_NoSession(t *testing.T) {
+	gormDB := mockTestDB(t)
+	t.Setenv(dispatch.SessionEnv, "")
+	var errOut bytes.Buffer
+	recordDispatchCar(&errOut, gormDB, "car-a")
+	if errOut.Len() != 0 {
+		t.Errorf("output without a session = %q", errOut.String())
+	}
+	t.Setenv(dispatch.SessionEnv, strconv.Itoa(999))
+	recordDispatchCar(&errOut, gormDB, "car-a")
+	if !strings.Contains(errOut.String(), "Warning") {
+		t.Errorf("missing session should warn, got %q", errOut.String())
+	}
+}
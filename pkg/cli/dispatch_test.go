@@ -25,8 +25,8 @@ func TestDispatchCmd_Help(t *testing.T) {
 
 func TestDispatchCmd_Flags(t *testing.T) {
 	cmd := newDispatchCmd()
-	if cmd.Use != "dispatch" {
-		t.Errorf("Use = %q, want %q", cmd.Use, "dispatch")
+	if cmd.Use != "dispatch [request]" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "dispatch [request]")
 	}
 
 	cfgFlag := cmd.Flags().Lookup("config")
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zulandar/railyard/internal/audit"
@@ -22,7 +25,9 @@ func newDoctorCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "Check system prerequisites and configuration",
-		Long:  "Runs diagnostic checks on Railyard prerequisites: config, binaries, database, schema, tracks, tmux session, and git repo.",
+		Long: `Runs diagnostic checks on Railyard prerequisites: config, binaries, database, schema,
+tracks, tmux session, git repo and remote, and the CocoIndex venv (when configured).
+Failed checks name a fix; the command exits nonzero if any critical check fails.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDoctor(cmd, configPath)
 		},
@@ -63,6 +68,14 @@ func runDoctor(cmd *cobra.Command, configPath string) error {
 		binaries = []string{"go", "tmux", "claude"}
 	}
 	for _, bin := range binaries {
+		if bin == "tmux" && cfg != nil && cfg.Multiplexer == config.MultiplexerZellij {
+			results = append(results, checkZellij(cfg.Zellij.Binary))
+			continue
+		}
+		if bin == "tmux" && cfg != nil && cfg.Tmux.Binary != "" {
+			results = append(results, checkConfiguredTmux(cfg.Tmux.Binary))
+			continue
+		}
 		results = append(results, checkBinary(bin))
 	}
 
@@ -71,8 +84,8 @@ func runDoctor(cmd *cobra.Command, configPath string) error {
 		results = append(results, checkProviderBinaries(cfg)...)
 	}
 
-	// 3b. GitHub CLI (only when require_pr is enabled)
-	if cfg != nil && cfg.RequirePR {
+	// 3b. GitHub CLI (only when some track merges through PRs)
+	if cfg != nil && cfg.UsesPRs() {
 		ghResult := checkBinary("gh")
 		results = append(results, ghResult)
 		if ghResult.status == "PASS" {
@@ -116,8 +129,14 @@ func runDoctor(cmd *cobra.Command, configPath string) error {
 	// 7. tmux sessions
 	results = append(results, checkTmuxSession(cfg)...)
 
-	// 8. Git repo
+	// 8. Git repo and remote
 	results = append(results, checkGitRepo())
+	results = append(results, checkGitRemote("", cfg))
+
+	// 9. CocoIndex venv (only when cocoindex is configured)
+	if cfg != nil && cfg.CocoIndex.DatabaseURL != "" {
+		results = append(results, checkCocoIndexVenv(cfg.CocoIndex.VenvPath))
+	}
 
 	// Print results.
 	passed, failed, warned := 0, 0, 0
@@ -146,7 +165,7 @@ func printCheckResult(out io.Writer, r checkResult) {
 }
 
 func checkConfig(path string) (*config.Config, checkResult) {
-	cfg, err := config.Load(path)
+	cfg, err := loadConfig(path)
 	if err != nil {
 		return nil, checkResult{"Config file", "FAIL", fmt.Sprintf("%s: %v", path, err)}
 	}
@@ -159,10 +178,13 @@ func checkBinary(name string) checkResult {
 		label := name
 		switch name {
 		case "claude":
-			return checkResult{"Claude CLI", "WARN", "not found (engines need this to spawn agents)"}
+			return checkResult{"Claude CLI", "WARN", "not found (engines need this to spawn agents) — " + providerInstallHint("claude")}
 		case "gh":
 			return checkResult{"GitHub CLI", "WARN", "not found — install: https://cli.github.com"}
 		}
+		if hint := binaryInstallHint(name); hint != "" {
+			return checkResult{binaryLabel(name), "FAIL", "not found in PATH — " + hint}
+		}
 		return checkResult{label, "FAIL", "not found in PATH"}
 	}
 
@@ -191,6 +213,48 @@ func checkBinary(name string) checkResult {
 	return checkResult{binaryLabel(name), "PASS", version}
 }
 
+// checkConfiguredTmux checks the tmux.binary set in railyard.yaml, which may
+// be a path or a name other than "tmux".
+func checkConfiguredTmux(binary string) checkResult {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return checkResult{"tmux", "FAIL", fmt.Sprintf("tmux.binary %q not found", binary)}
+	}
+	out, err := exec.Command(path, "-V").Output()
+	if err != nil {
+		return checkResult{"tmux", "PASS", fmt.Sprintf("%s (version unknown)", path)}
+	}
+	return checkResult{"tmux", "PASS", fmt.Sprintf("%s (%s)", strings.TrimSpace(string(out)), path)}
+}
+
+// checkZellij checks the zellij binary and version when multiplexer is
+// zellij, reusing the backend's own validation.
+func checkZellij(binary string) checkResult {
+	z := orchestration.Zellij{Binary: binary}
+	if err := z.Validate(); err != nil {
+		return checkResult{"zellij", "FAIL", err.Error()}
+	}
+	if binary == "" {
+		binary = "zellij"
+	}
+	return checkResult{"zellij", "PASS", binary}
+}
+
+// binaryInstallHint returns installation instructions for a prerequisite
+// binary, or "" if there is none.
+func binaryInstallHint(name string) string {
+	switch name {
+	case "tmux":
+		return "install: apt install tmux / brew install tmux"
+	case "go":
+		return "install: https://go.dev/dl"
+	case "docker":
+		return "install: https://docs.docker.com/get-docker (needed by ry db start)"
+	default:
+		return ""
+	}
+}
+
 func binaryLabel(name string) string {
 	switch name {
 	case "go":
@@ -211,14 +275,14 @@ func binaryLabel(name string) string {
 func checkDBServer(host string, port int, username, password string) checkResult {
 	adminDB, err := db.ConnectAdmin(host, port, username, password)
 	if err != nil {
-		return checkResult{"Database server", "FAIL", fmt.Sprintf("%s:%d unreachable: %v", host, port, err)}
+		return checkResult{"Database server", "FAIL", fmt.Sprintf("%s:%d unreachable: %v — start it with ry db start, and check database.host/port/username/password", host, port, err)}
 	}
 	sqlDB, err := adminDB.DB()
 	if err != nil {
 		return checkResult{"Database server", "FAIL", fmt.Sprintf("get sql.DB: %v", err)}
 	}
 	if err := sqlDB.Ping(); err != nil {
-		return checkResult{"Database server", "FAIL", fmt.Sprintf("%s:%d ping failed: %v", host, port, err)}
+		return checkResult{"Database server", "FAIL", fmt.Sprintf("%s:%d ping failed: %v — start it with ry db start, and check database.host/port/username/password", host, port, err)}
 	}
 	return checkResult{"Database server", "PASS", fmt.Sprintf("%s:%d reachable", host, port)}
 }
@@ -226,7 +290,7 @@ func checkDBServer(host string, port int, username, password string) checkResult
 func checkDatabase(host string, port int, dbName, username, password string) checkResult {
 	gormDB, err := db.Connect(host, port, dbName, username, password)
 	if err != nil {
-		return checkResult{"Database", "FAIL", fmt.Sprintf("%s: %v", dbName, err)}
+		return checkResult{"Database", "FAIL", fmt.Sprintf("%s: %v — create it with ry db init", dbName, err)}
 	}
 	sqlDB, err := gormDB.DB()
 	if err != nil {
@@ -254,7 +318,7 @@ func checkSchema(cfg *config.Config) checkResult {
 	if actual >= expected {
 		return checkResult{"Schema", "PASS", fmt.Sprintf("%d/%d tables migrated", actual, expected)}
 	}
-	return checkResult{"Schema", "WARN", fmt.Sprintf("%d/%d tables migrated", actual, expected)}
+	return checkResult{"Schema", "WARN", fmt.Sprintf("%d/%d tables migrated — run ry db init", actual, expected)}
 }
 
 func checkTracks(cfg *config.Config) checkResult {
@@ -281,7 +345,7 @@ func checkTmuxSession(cfg *config.Config) []checkResult {
 	}
 	var results []checkResult
 	prefix := orchestration.SessionPrefix(cfg.Owner)
-	sessions, err := orchestration.DefaultTmux.ListSessions(prefix)
+	sessions, err := orchestration.TmuxFor(cfg).ListSessions(prefix)
 	if err != nil {
 		results = append(results, checkResult{"tmux sessions", "WARN", fmt.Sprintf("could not list sessions: %v", err)})
 		return results
@@ -321,6 +385,48 @@ func checkGitRepo() checkResult {
 	return checkResult{"Git repo", "PASS", "valid"}
 }
 
+// checkGitRemote checks that dir's origin remote (dir "" is the current
+// directory) is reachable with the user's git credentials, since engines
+// push to it and the yardmaster fetches from it.
+func checkGitRemote(dir string, cfg *config.Config) checkResult {
+	urlCmd := exec.Command("git", "remote", "get-url", "origin")
+	urlCmd.Dir = dir
+	out, err := urlCmd.Output()
+	if err != nil {
+		hint := "git remote add origin <url>"
+		if cfg != nil && cfg.Repo != "" {
+			hint = "git remote add origin " + cfg.Repo
+		}
+		return checkResult{"Git remote", "FAIL", "no origin remote — " + hint}
+	}
+	remote := strings.TrimSpace(string(out))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ls := exec.CommandContext(ctx, "git", "ls-remote", "origin", "HEAD")
+	ls.Dir = dir
+	// Fail instead of prompting for a password or host key.
+	ls.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	if out, err := ls.CombinedOutput(); err != nil {
+		msg, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		if msg == "" {
+			msg = err.Error()
+		}
+		return checkResult{"Git remote", "FAIL", fmt.Sprintf("%s unreachable: %s — check network access and git credentials (ssh key or gh auth setup-git)", remote, msg)}
+	}
+	return checkResult{"Git remote", "PASS", remote + " reachable"}
+}
+
+// checkCocoIndexVenv checks for the Python venv semantic search runs from.
+// Engines work without it, so a missing venv is a warning.
+func checkCocoIndexVenv(venvPath string) checkResult {
+	python := filepath.Join(venvPath, "bin", "python")
+	if _, err := os.Stat(python); err != nil {
+		return checkResult{"CocoIndex venv", "WARN", fmt.Sprintf("%s not found — run ry cocoindex init", python)}
+	}
+	return checkResult{"CocoIndex venv", "PASS", venvPath}
+}
+
 // checkProviderBinaries validates that each configured agent provider's binary is available.
 func checkProviderBinaries(cfg *config.Config) []checkResult {
 	// Collect unique provider names from config.
@@ -3,8 +3,13 @@ package cli
 import (
 	"bytes"
 	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
 )
 
 // --- doctor command tests ---
@@ -67,6 +72,13 @@ func TestCheckBinary_Missing(t *testing.T) {
 	}
 }
 
+func TestCheckConfiguredTmux_Missing(t *testing.T) {
+	result := checkConfiguredTmux("/nonexistent/bin/tmux")
+	if result.status != "FAIL" || !strings.Contains(result.detail, "tmux.binary") {
+		t.Errorf("got %s: %s, want FAIL naming tmux.binary", result.status, result.detail)
+	}
+}
+
 func TestCheckBinary_Claude_Warn(t *testing.T) {
 	// Claude CLI may or may not be installed; if missing, it should be WARN not FAIL.
 	result := checkBinary("claude")
@@ -83,6 +95,51 @@ func TestCheckGitRepo(t *testing.T) {
 	}
 }
 
+func TestCheckGitRemote(t *testing.T) {
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s\n%s", args, err, out)
+		}
+	}
+	bare := t.TempDir()
+	git(bare, "init", "--bare", "-b", "main")
+	dir := t.TempDir()
+	git(dir, "init", "-b", "main")
+
+	cfg := &config.Config{Repo: "git@github.com:org/app.git"}
+	r := checkGitRemote(dir, cfg)
+	if r.status != "FAIL" || !strings.Contains(r.detail, "git remote add origin git@github.com:org/app.git") {
+		t.Errorf("no origin: got %s: %s", r.status, r.detail)
+	}
+
+	git(dir, "remote", "add", "origin", filepath.Join(t.TempDir(), "missing.git"))
+	if r := checkGitRemote(dir, cfg); r.status != "FAIL" || !strings.Contains(r.detail, "unreachable") {
+		t.Errorf("bad origin: got %s: %s", r.status, r.detail)
+	}
+
+	git(dir, "remote", "set-url", "origin", bare)
+	git(dir, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "--allow-empty", "-m", "init")
+	git(dir, "push", "origin", "main")
+	if r := checkGitRemote(dir, cfg); r.status != "PASS" {
+		t.Errorf("reachable origin: got %s: %s", r.status, r.detail)
+	}
+}
+
+func TestCheckCocoIndexVenv(t *testing.T) {
+	venv := t.TempDir()
+	if r := checkCocoIndexVenv(venv); r.status != "WARN" || !strings.Contains(r.detail, "ry cocoindex init") {
+		t.Errorf("missing venv: got %s: %s", r.status, r.detail)
+	}
+	os.MkdirAll(filepath.Join(venv, "bin"), 0755)
+	os.WriteFile(filepath.Join(venv, "bin", "python"), nil, 0755)
+	if r := checkCocoIndexVenv(venv); r.status != "PASS" {
+		t.Errorf("venv present: got %s: %s", r.status, r.detail)
+	}
+}
+
 func TestCheckCredentials_DefaultPassword(t *testing.T) {
 	result := checkCredentials("root", "", io.Discard)
 	if result.status != "WARN" {
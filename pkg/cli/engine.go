@@ -2,13 +2,16 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/rand"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -27,6 +30,9 @@ import (
 	"github.com/zulandar/railyard/internal/messaging"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/outbound"
+	"github.com/zulandar/railyard/internal/progress"
+	"github.com/zulandar/railyard/internal/yard"
 	"gorm.io/gorm"
 )
 
@@ -42,7 +48,11 @@ func newEngineCmd() *cobra.Command {
 	cmd.AddCommand(newEngineDrainCmd())
 	cmd.AddCommand(newEngineScaleCmd())
 	cmd.AddCommand(newEngineListCmd())
+	cmd.AddCommand(newEngineStatsCmd())
 	cmd.AddCommand(newEngineRestartCmd())
+	cmd.AddCommand(newEngineSuperviseCmd())
+	cmd.AddCommand(newEngineRolloutCmd())
+	cmd.AddCommand(newEngineCheckCommandCmd())
 	return cmd
 }
 
@@ -52,6 +62,7 @@ func newEngineStartCmd() *cobra.Command {
 		track        string
 		pollInterval time.Duration
 		logLevel     string
+		agentBinary  string
 	)
 
 	cmd := &cobra.Command{
@@ -59,7 +70,7 @@ func newEngineStartCmd() *cobra.Command {
 		Short: "Start the engine daemon",
 		Long:  "Starts the engine daemon loop: claims cars, spawns Claude Code, monitors subprocess, handles outcomes.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runEngineStart(cmd, configPath, track, pollInterval, logLevel)
+			return runEngineStart(cmd, configPath, track, pollInterval, logLevel, agentBinary)
 		},
 	}
 
@@ -67,19 +78,23 @@ func newEngineStartCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&track, "track", "t", "", "track to work on (required)")
 	cmd.Flags().DurationVar(&pollInterval, "poll-interval", defaultPollInterval, "interval between claim attempts")
 	cmd.Flags().StringVar(&logLevel, "log-level", "", "log level (debug, info, warn, error; env LOG_LEVEL)")
+	cmd.Flags().StringVar(&agentBinary, "agent-binary", "", "agent CLI to run instead of the provider default (set by ry engine rollout)")
 	_ = cmd.MarkFlagRequired("track")
 	return cmd
 }
 
-func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval time.Duration, logLevel string) error {
+func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval time.Duration, logLevel, agentBinary string) error {
 	level := logutil.ParseLevel(os.Getenv("LOG_LEVEL"), logLevel)
 	logger := logutil.NewLogger(cmd.OutOrStdout(), cmd.ErrOrStderr(), level)
 	slog.SetDefault(logger)
 
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	if err := outbound.Apply(cfg.Network); err != nil {
+		return fmt.Errorf("network: %w", err)
+	}
 
 	// Sync embedded CocoIndex scripts to disk so overlay/MCP operations
 	// find them without requiring a prior 'ry cocoindex init'.
@@ -103,6 +118,9 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 	if err != nil {
 		return fmt.Errorf("connect to %s: %w", cfg.Database.Database, err)
 	}
+	if err := db.InstrumentQueries(gormDB, cfg.Database.QueryLog); err != nil {
+		logger.Warn("Query log warning", "error", err)
+	}
 
 	// Ensure schema is up to date (adds any new columns from model changes).
 	if err := db.AutoMigrate(gormDB); err != nil {
@@ -143,6 +161,54 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 		logger.Info("Engine using native agent loop", "auth_method", cfg.AuthMethod, "model", trackCfg.AgentModel)
 	}
 
+	// An agent binary override that does not resolve would fail every
+	// spawn; refuse to register so a rollout sees the engine never came up.
+	if agentBinary != "" {
+		if useNativeLoop {
+			return fmt.Errorf("engine: --agent-binary is not supported with the native agent loop (auth_method %s)", cfg.AuthMethod)
+		}
+		if _, err := exec.LookPath(agentBinary); err != nil {
+			return fmt.Errorf("engine: agent binary: %w", err)
+		}
+	}
+
+	// Fail fast on a sandbox this host cannot run, rather than on every spawn.
+	if sb := trackCfg.Sandbox; sb != nil {
+		if useNativeLoop {
+			return fmt.Errorf("track %q: sandbox is not supported with the native agent loop (auth_method %s)", track, cfg.AuthMethod)
+		}
+		if err := engine.CheckSandbox(sb); err != nil {
+			return err
+		}
+		logger.Info("Engine agents sandboxed", "tool", sb.Tool, "allow_hosts", sb.AllowHosts)
+	}
+
+	// Resource limits wrap the agent subprocess, so check them up front too.
+	if rc := trackCfg.Resources; rc != nil {
+		if useNativeLoop {
+			return fmt.Errorf("track %q: resources are not supported with the native agent loop (auth_method %s)", track, cfg.AuthMethod)
+		}
+		if err := engine.CheckResources(rc); err != nil {
+			return err
+		}
+		logger.Info("Engine agents resource-limited", "nice", rc.Nice, "cpus", rc.CPUs, "memory_max", rc.MemoryMax, "cpu_quota", rc.CPUQuota)
+	}
+
+	// The command policy is enforced by a claude PreToolUse hook that runs
+	// this binary, so it needs the claude CLI and a resolvable executable.
+	var policyHook string
+	if trackCfg.CommandPolicy != nil {
+		if useNativeLoop || providerName != "claude" {
+			return fmt.Errorf("track %q: command_policy needs the claude agent provider (provider %s, auth_method %s)", track, providerName, cfg.AuthMethod)
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("track %q: command_policy: locate ry binary: %w", track, err)
+		}
+		policyHook = "'" + strings.ReplaceAll(exe, "'", `'\''`) + "' " + engine.CommandPolicyHookName
+		logger.Info("Engine agent commands restricted", "allow", trackCfg.CommandPolicy.Allow, "deny", trackCfg.CommandPolicy.Deny, "block_car", trackCfg.CommandPolicy.BlockCar)
+	}
+
 	// Construct an event bus for this engine pod. Plugin lifecycle is NOT
 	// started here — engine pods are per-track Kubernetes workloads, and
 	// plugin daemons that need yard-wide visibility live in the yardmaster
@@ -150,9 +216,10 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 	// in-process subscribers (today: none in pod mode) observe events;
 	// publishing to a bus with no subscribers is a no-op.
 	bus := events.NewBusWithLogger(logger)
+	defer persistEvents(bus, gormDB, "", logger)()
 
 	// Register the engine.
-	eng, err := engine.RegisterWithBus(gormDB, engine.RegisterOpts{Track: track, Provider: providerName}, bus)
+	eng, err := engine.RegisterWithBus(gormDB, engine.RegisterOpts{Track: track, Provider: providerName, AgentBinary: agentBinary}, bus)
 	if err != nil {
 		return fmt.Errorf("register engine: %w", err)
 	}
@@ -189,6 +256,12 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 		return fmt.Errorf("get working directory: %w", err)
 	}
 
+	// A track with its own repo works in a clone of that repo; its engine
+	// worktrees hang off the clone rather than the main repository.
+	if repoDir, err = engine.EnsureTrackRepo(cfg, repoDir, track); err != nil {
+		return fmt.Errorf("setup track repo: %w", err)
+	}
+
 	// Create a dedicated git worktree for this engine.
 	workDir, err := engine.EnsureWorktree(repoDir, eng.ID)
 	if err != nil {
@@ -207,6 +280,11 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 
 	cycle := 0
 	var lastIdleLog time.Time
+	// urgentNext is the urgent car to claim after this engine was preempted.
+	var urgentNext string
+	// guidance holds guidance messages (e.g. from ry message broadcast)
+	// already acknowledged by ProcessInbox, until the next prompt shows them.
+	var guidance []models.Message
 	var claimTime time.Time
 
 	type cycleStats struct {
@@ -260,6 +338,10 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 		if inboxErr != nil {
 			logger.Error("Inbox error", "error", inboxErr)
 		}
+		if g := engine.Guidance(instructions); len(g) > 0 {
+			logger.Info("Guidance received; included in the next prompt", "messages", len(g))
+			guidance = append(guidance, g...)
+		}
 
 		// Handle drain instruction — finish up and exit gracefully. Sent by
 		// orchestration Stop (broadcast), Scale down, and RestartEngine.
@@ -278,6 +360,7 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 					break
 				}
 				resumeInst, _ := engine.ProcessInbox(gormDB, eng.ID)
+				guidance = append(guidance, engine.Guidance(resumeInst)...)
 				if engine.ShouldDrain(resumeInst) {
 					logger.Info("Drain instruction received while paused, shutting down", "engine", eng.ID)
 					gracefulShutdown()
@@ -303,8 +386,31 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			continue
 		}
 
-		// Try to claim a car (or re-claim current if mid-cycle).
-		claimed, err := claimOrReclaim(gormDB, eng, track)
+		// Handle a preempt instruction that arrived between sessions: park
+		// the current car and go straight for the urgent one.
+		if eng.CurrentCar != "" {
+			if urgent, ok := engine.PreemptionFor(instructions, eng.CurrentCar); ok {
+				logger.Info("Preempt instruction received", "car", eng.CurrentCar, "urgent_car", urgent)
+				if current, getErr := car.Get(gormDB, eng.CurrentCar); getErr != nil {
+					logger.Error("Preemption handling error", "car", eng.CurrentCar, "error", getErr)
+				} else if err := engine.HandlePreemption(gormDB, current, eng, engine.PreemptionOpts{
+					RepoDir:     workDir,
+					UrgentCarID: urgent,
+				}); err != nil {
+					logger.Error("Preemption handling error", "car", eng.CurrentCar, "error", err)
+				}
+				eng.CurrentCar = ""
+				urgentNext = urgent
+				cycle = 0
+				continue
+			}
+		}
+
+		// Try to claim a car (or re-claim current if mid-cycle). After a
+		// preemption, only the urgent car is considered for this attempt.
+		claimOpts := engine.ClaimOptsForTrack(trackCfg)
+		claimOpts.CarID, urgentNext = urgentNext, ""
+		claimed, err := claimOrReclaim(gormDB, eng, track, claimOpts)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				// No ready cars — sleep and retry.
@@ -315,6 +421,14 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 				sleepWithContext(ctx, pollInterval)
 				continue
 			}
+			if errors.Is(err, yard.ErrPaused) {
+				if time.Since(lastIdleLog) >= 30*time.Second {
+					logger.Info("Yard paused, not claiming new cars", "reason", err)
+					lastIdleLog = time.Now()
+				}
+				sleepWithContext(ctx, pollInterval)
+				continue
+			}
 			logger.Error("Claim error", "error", err)
 			sleepWithContext(ctx, pollInterval)
 			continue
@@ -332,15 +446,44 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 		// Render context.
 		progress, _ := loadProgress(gormDB, claimed.ID)
 		messages, _ := loadMessages(gormDB, eng.ID)
+		messages = append(guidance, messages...)
+		notes, _ := car.TrackNotes(gormDB, track, car.MaxPromptNotes)
 		commits, _ := engine.RecentCommits(workDir, claimed.Branch, 10)
+		prior, err := engine.RelatedPriorCars(gormDB, workDir, claimed)
+		if err != nil {
+			cycleLog.Warn("Related prior cars error", "car", claimed.ID, "error", err)
+		}
+		trackContext, err := engine.LoadTrackContext(gormDB, &trackModel)
+		if err != nil {
+			cycleLog.Warn("Track context error", "track", track, "error", err)
+		}
+
+		// A car joins the track's canary group on its first claim and keeps
+		// its group for later cycles; canary cars run the canary settings.
+		if trackCfg.Canary != nil && len(progress) == 0 && !claimed.Canary && config.InCanary(claimed.ID, trackCfg.Canary.Percent) {
+			if err := gormDB.Model(&models.Car{}).Where("id = ?", claimed.ID).Update("canary", true).Error; err != nil {
+				cycleLog.Warn("Canary assignment error", "car", claimed.ID, "error", err)
+			} else {
+				claimed.Canary = true
+			}
+		}
+		carTrack, ctxTrack := *trackCfg, &trackModel
+		if claimed.Canary {
+			carTrack = trackCfg.WithCanary()
+			ctxTrack = canaryTrackModel(trackModel, carTrack)
+			cycleLog.Info("Car in canary group", "car", claimed.ID)
+		}
 
 		contextPayload, err := engine.RenderContext(engine.ContextInput{
 			Car:           claimed,
-			Track:         &trackModel,
+			Track:         ctxTrack,
 			Config:        cfg,
 			Progress:      progress,
 			Messages:      messages,
+			Notes:         notes,
 			RecentCommits: commits,
+			PriorCars:     prior,
+			TrackContext:  trackContext != nil,
 			EngineID:      eng.ID,
 			RepoDir:       workDir,
 		})
@@ -349,11 +492,13 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			sleepWithContext(ctx, pollInterval)
 			continue
 		}
+		guidance = nil
 
-		// Set up git branch — revision cars resume existing branch, new cars branch off base.
-		isRevision := claimed.CompletedAt != nil && claimed.Branch != "" && engine.RemoteBranchExists(workDir, claimed.Branch)
+		// Set up git branch — revision and preempted cars resume their existing
+		// branch, new cars branch off base.
+		isRevision := (claimed.CompletedAt != nil || claimed.PreemptedAt != nil) && claimed.Branch != "" && engine.RemoteBranchExists(workDir, claimed.Branch)
 		if isRevision {
-			logger.Info("Revision car, checking out existing branch", "car", claimed.ID, "branch", claimed.Branch)
+			logger.Info("Revision or preempted car, checking out existing branch", "car", claimed.ID, "branch", claimed.Branch)
 			if err := engine.CheckoutExistingBranch(workDir, claimed.Branch); err != nil {
 				logger.Warn("Checkout existing branch error, falling back to new branch", "error", err)
 				isRevision = false
@@ -361,7 +506,7 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 		}
 		if !isRevision {
 			// Reset worktree to clean state at the car's base branch before branching.
-			if err := engine.ResetWorktree(workDir, claimed.BaseBranch); err != nil {
+			if err := engine.ResetWorktreeWithOpts(workDir, claimed.BaseBranch, engine.ResetOptsForTrack(trackCfg)); err != nil {
 				logger.Error("Reset worktree error", "error", err)
 				sleepWithContext(ctx, pollInterval)
 				continue
@@ -375,6 +520,22 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			}
 		}
 
+		// Write chat attachments the car description points at (non-fatal).
+		if n, err := engine.WriteAttachments(gormDB, workDir, claimed.ID); err != nil {
+			logger.Warn("Attachments warning", "car", claimed.ID, "error", err)
+		} else if n > 0 {
+			logger.Info("Wrote car attachments", "car", claimed.ID, "count", n)
+		}
+
+		// Refresh the track's TRACK.md (non-fatal).
+		if trackContext != nil {
+			if written, err := trackContext.Write(workDir); err != nil {
+				logger.Warn("Track context warning", "track", track, "error", err)
+			} else if written {
+				logger.Info("Wrote track context", "track", track, "path", engine.TrackContextFile)
+			}
+		}
+
 		// Build overlay index (non-fatal).
 		if cfg.CocoIndex.Overlay.Enabled {
 			if overlayTable, err := engine.BuildOverlay(workDir, eng.ID, track, cfg); err != nil {
@@ -391,6 +552,15 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 		if err := engine.WriteMCPConfig(workDir, eng.ID, track, cfg); err != nil {
 			logger.Warn("MCP config warning", "error", err)
 		}
+		// Unlike MCP config, the command policy hook is not optional: without
+		// it the agent would run unrestricted.
+		if policyHook != "" {
+			if err := engine.WriteCommandPolicyHook(workDir, policyHook); err != nil {
+				logger.Error("Command policy hook error", "car", claimed.ID, "error", err)
+				sleepWithContext(ctx, pollInterval)
+				continue
+			}
+		}
 
 		// Mark the car in_progress now that an agent is about to work it
 		// (railyard-rsy). Conditional on status=claimed — re-claim cycles
@@ -414,10 +584,16 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			ContextPayload: contextPayload,
 			WorkDir:        workDir,
 			ProviderName:   providerName,
-			Model:          trackCfg.AgentModel,
+			AgentBinary:    agentBinary,
+			Model:          carTrack.AgentModel,
+			Sandbox:        trackCfg.Sandbox,
+			Resources:      trackCfg.Resources,
+			CommandPolicy:  engine.NewCommandPolicy(trackCfg.CommandPolicy),
 		}
 		// Native loop and CLI subprocess paths share the same pause-and-retry
-		// wrapper; only the runner differs.
+		// wrapper; only the runner differs. Both run under a preemption watcher
+		// so the yardmaster can park this car mid-session for urgent work.
+		pw := engine.WatchPreemption(ctx, gormDB, eng.ID, claimed.ID, engine.DefaultPreemptPollInterval)
 		var sess *engine.Session
 		var outcome sessionOutcome
 		var spawnErr error
@@ -435,9 +611,18 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			// when CocoIndex is unconfigured — same gate as WriteMCPConfig).
 			csParams := engine.EngineCodeSearchParams(workDir, eng.ID, trackCfg.Name, cfg)
 			runner := nativeSpawnRunner(gormDB, loopClient, cfg.AuthMethod, nativeEngineMaxIterations, csParams, cycleLog)
-			sess, outcome, spawnErr = spawnAndMonitorWithRetryRunner(ctx, spawnOpts, cfg.Stall.RateLimitMaxRetries, cfg.Stall.RateLimitMaxWaitSec, cycleLog, runner)
+			sess, outcome, spawnErr = spawnAndMonitorWithRetryRunner(pw.Context(), spawnOpts, cfg.Stall.RateLimitMaxRetries, cfg.Stall.RateLimitMaxWaitSec, cycleLog, runner)
 		} else {
-			sess, outcome, spawnErr = spawnAndMonitorWithRetry(ctx, gormDB, spawnOpts, stallCfg, cfg.Stall.RateLimitMaxRetries, cfg.Stall.RateLimitMaxWaitSec, cycle, cycleLog)
+			sess, outcome, spawnErr = spawnAndMonitorWithRetry(pw.Context(), gormDB, spawnOpts, stallCfg, cfg.Stall.RateLimitMaxRetries, cfg.Stall.RateLimitMaxWaitSec, cycle, cycleLog)
+		}
+		pw.Stop()
+		if urgent, preempted := pw.Preempted(); preempted {
+			// A session that finished on its own just as the preemption
+			// landed keeps its outcome; either way the urgent car is next.
+			urgentNext = urgent
+			if outcome.kind == outcomeCancelled && ctx.Err() == nil {
+				outcome = sessionOutcome{kind: outcomePreempted, urgentCar: urgent}
+			}
 		}
 		if spawnErr != nil {
 			// Transient spawn failure (binary missing, fork-limit, etc.) — log
@@ -447,6 +632,33 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			continue
 		}
 
+		// Report commands the policy refused, as events and in the journal.
+		var policyViolations []engine.PolicyViolation
+		if trackCfg.CommandPolicy != nil && sess != nil {
+			if policyViolations, err = engine.SessionPolicyViolations(gormDB, sess.ID); err != nil {
+				cycleLog.Warn("Command policy scan error", "car", claimed.ID, "session", sess.ID, "error", err)
+			}
+			engine.ReportPolicyViolations(bus, eng.ID, claimed.ID, policyViolations)
+		}
+
+		// Journal the session before completion/clear handling auto-commits,
+		// so the files snapshot still shows the agent's uncommitted changes.
+		if sess != nil {
+			if err := engine.RecordSessionJournal(gormDB, engine.JournalOpts{
+				CarID:            claimed.ID,
+				EngineID:         eng.ID,
+				SessionID:        sess.ID,
+				RepoDir:          workDir,
+				TestCommand:      carTrack.TestCommand,
+				Outcome:          outcome.journalOutcome(),
+				Sandboxed:        trackCfg.Sandbox != nil,
+				EgressDenials:    sess.EgressDenials(),
+				PolicyViolations: policyViolations,
+			}); err != nil {
+				cycleLog.Warn("Journal write error", "car", claimed.ID, "session", sess.ID, "error", err)
+			}
+		}
+
 		switch outcome.kind {
 		case outcomeCompleted:
 			stats := queryCarOutcomeStats(gormDB, claimed.ID, claimed.Branch, claimed.BaseBranch, workDir, claimTime)
@@ -542,6 +754,35 @@ func runEngineStart(cmd *cobra.Command, configPath, track string, pollInterval t
 			// Reset cycle — car is now blocked, engine should move on.
 			cycle = 0
 
+		case outcomePolicy:
+			cycleLog.Warn("Command policy violation, blocking car", "car", claimed.ID, "commands", len(policyViolations))
+			if err := engine.HandleCommandPolicyBlock(gormDB, eng.ID, claimed.ID, engine.CommandPolicyBlockOpts{
+				RepoDir:    workDir,
+				Branch:     claimed.Branch,
+				SessionID:  sess.ID,
+				Violations: policyViolations,
+			}); err != nil {
+				logger.Error("Command policy handling error", "car", claimed.ID, "error", err)
+			}
+			// Clear current_car so the engine doesn't re-claim the now-blocked car.
+			gormDB.Model(&models.Engine{}).Where("id = ?", eng.ID).Update("current_car", "")
+			eng.CurrentCar = ""
+			cycle = 0
+
+		case outcomePreempted:
+			cycleLog.Info("Preempted, parking car", "car", claimed.ID, "urgent_car", outcome.urgentCar)
+			preemptOpts := engine.PreemptionOpts{RepoDir: workDir, UrgentCarID: outcome.urgentCar}
+			if sess != nil {
+				preemptOpts.SessionID = sess.ID
+			}
+			if err := engine.HandlePreemption(gormDB, claimed, eng, preemptOpts); err != nil {
+				logger.Error("Preemption handling error", "car", claimed.ID, "error", err)
+			}
+			eng.CurrentCar = ""
+			cycle = 0
+			// Claim the urgent car right away rather than after a poll.
+			continue
+
 		case outcomeCancelled:
 			cycleLog.Info("Cancelled, shutting down")
 			pushInflightBranch(gormDB, eng, workDir)
@@ -648,12 +889,36 @@ const (
 	outcomeStall                          // stall detected
 	outcomeCancelled                      // context cancelled (shutdown)
 	outcomeRateLimited                    // upstream rate-limit signal observed; engine should pause and retry
+	outcomePreempted                      // yardmaster preempted the car for urgent work
+	outcomePolicy                         // agent tried a command the track's policy forbids; car is blocked
 )
 
 type sessionOutcome struct {
 	kind            outcomeKind
 	stallReason     engine.StallReason
 	rateLimitSignal engine.RateLimitSignal
+	urgentCar       string // outcomePreempted: the urgent car to claim next
+}
+
+// journalOutcome describes the outcome for the car's work journal.
+func (o sessionOutcome) journalOutcome() string {
+	switch o.kind {
+	case outcomeCompleted:
+		return "completed"
+	case outcomeClear:
+		return "clear"
+	case outcomeStall:
+		return "stall: " + o.stallReason.Detail
+	case outcomeCancelled:
+		return "cancelled"
+	case outcomeRateLimited:
+		return "rate limited"
+	case outcomePreempted:
+		return "preempted"
+	case outcomePolicy:
+		return "blocked by command policy"
+	}
+	return ""
 }
 
 // spawnRunner is the seam between spawnAndMonitorWithRetry's loop body and
@@ -691,16 +956,43 @@ func defaultSpawnRunner(db *gorm.DB, stallCfg engine.StallConfig, cycle int, cyc
 		rd := engine.NewRateLimitDetector()
 		rd.AttachToSession(sess)
 
+		// On tracks that block cars on a command policy violation, stop the
+		// agent at the first refused command; monitorSession then sees the
+		// exit and the outcome is replaced below.
+		var pd *engine.PolicyDetector
+		stopPolicy := make(chan struct{})
+		if opts.CommandPolicy != nil && opts.CommandPolicy.BlockCar {
+			pd = engine.NewPolicyDetector()
+			pd.AttachToSession(sess)
+			go func() {
+				select {
+				case <-pd.Signaled():
+					sess.Cancel()
+				case <-stopPolicy:
+				}
+			}()
+		}
+
 		outcome := monitorSession(ctx, sess, sd, rd, db, opts.CarID)
+		close(stopPolicy)
 		sd.Stop()
 		rd.Stop()
+		if pd != nil && pd.Fired() && outcome.kind != outcomeCompleted && outcome.kind != outcomeCancelled {
+			return sess, sessionOutcome{kind: outcomePolicy}, nil
+		}
 
-		if outcome.kind == outcomeRateLimited {
+		switch outcome.kind {
+		case outcomeRateLimited:
 			// Terminate the running subprocess so it doesn't keep burning
 			// tokens against the upstream that just rejected us, then drain
 			// its Done channel. Wait blocks until exit.
 			sess.Cancel()
 			_ = sess.Wait()
+		case outcomeCancelled:
+			// Shutdown or preemption already signalled the subprocess via
+			// ctx; wait (bounded by the command's WaitDelay) so the worktree
+			// is quiet before the caller checkpoints it.
+			_ = sess.Wait()
 		}
 		return sess, outcome, nil
 	}
@@ -888,13 +1180,15 @@ func monitorSessionWithDB(ctx context.Context, doneCh <-chan error, stallCh <-ch
 	}
 }
 
-// claimOrReclaim either claims a new car or re-claims the engine's current car.
-func claimOrReclaim(gormDB *gorm.DB, eng *models.Engine, track string) (*models.Car, error) {
+// claimOrReclaim either claims a new car (ordered by the track's claim
+// strategy) or re-claims the engine's current car.
+func claimOrReclaim(gormDB *gorm.DB, eng *models.Engine, track string, opts engine.ClaimOpts) (*models.Car, error) {
 	// Check if engine already has a car assigned (re-claim after clear cycle).
 	if eng.CurrentCar != "" {
 		b, err := car.Get(gormDB, eng.CurrentCar)
-		// Only re-claim if car is still actively workable (not done, cancelled, or blocked).
-		if err == nil && b.Status != "done" && b.Status != "cancelled" && b.Status != "blocked" {
+		// Only re-claim if car is still actively workable (not done, cancelled,
+		// or blocked, and not split into an epic by ry car split).
+		if err == nil && b.Status != "done" && b.Status != "cancelled" && b.Status != "blocked" && b.Type != "epic" {
 			slog.Debug("engine: re-claiming existing car", "engine", eng.ID, "car", b.ID, "status", b.Status)
 			return b, nil
 		}
@@ -915,7 +1209,7 @@ func claimOrReclaim(gormDB *gorm.DB, eng *models.Engine, track string) (*models.
 		eng.CurrentCar = ""
 	}
 
-	claimed, err := engine.ClaimCar(gormDB, eng.ID, track)
+	claimed, err := engine.ClaimCarWithOpts(gormDB, eng.ID, track, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -1007,6 +1301,7 @@ func newEngineScaleCmd() *cobra.Command {
 		configPath string
 		track      string
 		count      int
+		porcelain  bool
 	)
 
 	cmd := &cobra.Command{
@@ -1014,23 +1309,32 @@ func newEngineScaleCmd() *cobra.Command {
 		Short: "Scale engine count for a track",
 		Long:  "Adjusts the number of engines running on a specific track. Scale up creates new tmux panes; scale down drains newest engines first.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runEngineScale(cmd, configPath, track, count)
+			return runEngineScale(cmd, configPath, track, count, porcelain)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().StringVar(&track, "track", "", "track to scale (required)")
 	cmd.Flags().IntVar(&count, "count", 0, "desired engine count (required)")
+	cmd.Flags().BoolVar(&porcelain, "porcelain", false, porcelainFlagUsage)
 	_ = cmd.MarkFlagRequired("track")
 	_ = cmd.MarkFlagRequired("count")
 	return cmd
 }
 
-func runEngineScale(cmd *cobra.Command, configPath, track string, count int) error {
+func runEngineScale(cmd *cobra.Command, configPath, track string, count int, porcelain bool) (err error) {
+	pw := porcelainWriter(cmd, "scale", porcelain)
+	defer failPorcelain(pw, &err)
+
 	cfg, gormDB, err := connectFromConfig(configPath)
 	if err != nil {
 		return err
 	}
+	lock, err := orchestration.AcquireLock(cfg.Owner, "engine scale")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
 
 	result, err := orchestration.Scale(orchestration.ScaleOpts{
 		DB:         gormDB,
@@ -1038,10 +1342,18 @@ func runEngineScale(cmd *cobra.Command, configPath, track string, count int) err
 		ConfigPath: configPath,
 		Track:      track,
 		Count:      count,
+		Progress:   pw.Func(),
 	})
 	if err != nil {
 		return err
 	}
+	actor := undoActor()
+	recordScaleUndo(cmd, gormDB, actor, result)
+	_ = events.Record(gormDB, "EngineScaled", actor, result)
+	if porcelain {
+		pw.Done(newPorcelainScaleResult(result))
+		return nil
+	}
 
 	out := cmd.OutOrStdout()
 	fmt.Fprintf(out, "Track %s: %d → %d engines\n", result.Track, result.Previous, result.Current)
@@ -1073,7 +1385,7 @@ func newEngineListCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().StringVar(&track, "track", "", "filter by track")
 	cmd.Flags().StringVar(&statusFilter, "status", "", "filter by status")
-	return cmd
+	return supportsJSON(cmd)
 }
 
 func runEngineList(cmd *cobra.Command, configPath, track, statusFilter string) error {
@@ -1092,11 +1404,19 @@ func runEngineList(cmd *cobra.Command, configPath, track, statusFilter string) e
 	}
 
 	out := cmd.OutOrStdout()
+	if wantJSON(cmd) {
+		return writeJSON(out, newJSONEngines(engines))
+	}
 	if len(engines) == 0 {
 		fmt.Fprintln(out, "No engines found.")
 		return nil
 	}
+	printEngineList(out, engines)
+	return nil
+}
 
+// printEngineList prints engines as the ry engine list table.
+func printEngineList(out io.Writer, engines []orchestration.EngineInfo) {
 	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "ID\tTRACK\tSTATUS\tPROVIDER\tCURRENT CAR\tLAST ACTIVITY\tUPTIME")
 	for _, e := range engines {
@@ -1114,7 +1434,6 @@ func runEngineList(cmd *cobra.Command, configPath, track, statusFilter string) e
 			formatUptime(e.Uptime))
 	}
 	w.Flush()
-	return nil
 }
 
 func newEngineRestartCmd() *cobra.Command {
@@ -1139,6 +1458,14 @@ func runEngineRestart(cmd *cobra.Command, configPath, engineID string) error {
 	if err != nil {
 		return err
 	}
+	if engineID, err = resolveEngineID(cmd, gormDB, engineID); err != nil {
+		return err
+	}
+	lock, err := orchestration.AcquireLock(cfg.Owner, "engine restart")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
 
 	if err := orchestration.RestartEngine(gormDB, cfg, configPath, engineID, nil); err != nil {
 		return err
@@ -1148,6 +1475,202 @@ func runEngineRestart(cmd *cobra.Command, configPath, engineID string) error {
 	return nil
 }
 
+func newEngineSuperviseCmd() *cobra.Command {
+	var (
+		configPath string
+		interval   time.Duration
+		stale      time.Duration
+		once       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "supervise",
+		Short: "Restart engines whose heartbeat has gone stale",
+		Long: `Watches engine heartbeats. An engine that has not reported activity for
+longer than --stale (default: stall.stale_engine_threshold_sec, 60s) is
+marked dead, the car it held is re-opened for another engine, and a
+replacement engine is started on its track.
+
+The yardmaster does this every cycle; run supervise on its own when the
+yardmaster is not running. Restarts need the railyard session (ry start);
+in Kubernetes mode engines are only marked dead and their cars re-opened.`,
+		Example: `  ry engine supervise
+  ry engine supervise --stale 5m --interval 30s
+  ry engine supervise --once`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			sup := orchestration.NewSupervisor(gormDB, cfg, configPath)
+			sup.Threshold = stale
+			if once {
+				return superviseTick(cmd.OutOrStdout(), sup)
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runEngineSupervise(ctx, cmd.OutOrStdout(), cmd.ErrOrStderr(), sup, interval)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().DurationVar(&interval, "interval", 15*time.Second, "how often to check heartbeats")
+	cmd.Flags().DurationVar(&stale, "stale", 0, "heartbeat age after which an engine is restarted (default: stall.stale_engine_threshold_sec)")
+	cmd.Flags().BoolVar(&once, "once", false, "check once and exit")
+	return cmd
+}
+
+// runEngineSupervise ticks the supervisor every interval until ctx ends.
+// Errors are reported and the loop continues.
+func runEngineSupervise(ctx context.Context, out, errOut io.Writer, sup *orchestration.Supervisor, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := superviseTick(out, sup); err != nil {
+			fmt.Fprintf(errOut, "supervise: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// superviseTick runs one supervisor pass and prints what it did.
+func superviseTick(out io.Writer, sup *orchestration.Supervisor) error {
+	actions, err := sup.Tick(time.Now())
+	for _, a := range actions {
+		line := fmt.Sprintf("Engine %s (%s) silent since %s: marked dead", a.Engine, a.Track, a.LastActivity.Format(time.RFC3339))
+		if a.Car != "" {
+			line += ", re-opened " + a.Car
+		}
+		switch {
+		case a.Restarted:
+			line += ", replacement started"
+		case a.RestartErr != nil:
+			line += fmt.Sprintf(", restart failed: %v", a.RestartErr)
+		}
+		fmt.Fprintln(out, line)
+	}
+	return err
+}
+
+func newEngineRolloutCmd() *cobra.Command {
+	var (
+		configPath   string
+		track        string
+		agentBinary  string
+		maxFailures  int
+		idleTimeout  time.Duration
+		startTimeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:     "rollout",
+		Aliases: []string{"swap-agent"},
+		Short:   "Restart a track's engines one at a time onto a new agent CLI",
+		Long: `Replaces each engine on a track in turn so it runs a newly installed agent
+CLI. Each engine finishes its current car first; the next engine is not
+touched until the replacement has registered. A replacement that fails to
+start is relaunched on the previous binary, and once more than
+--max-failures replacements fail the rollout stops and rolls back the
+engines it already upgraded.
+
+Rollback needs the old CLI to stay installed: pass the new one with
+--agent-binary. Without it, replacements run the provider's default binary
+from PATH and a failed rollout can only stop.`,
+		Example: `  ry engine rollout --track backend
+  ry engine rollout --track backend --agent-binary ~/.local/claude-2.1/bin/claude --max-failures 1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEngineRollout(cmd, configPath, orchestration.RolloutOpts{
+				Track:        track,
+				AgentBinary:  agentBinary,
+				MaxFailures:  maxFailures,
+				IdleTimeout:  idleTimeout,
+				StartTimeout: startTimeout,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track to roll out (required)")
+	cmd.Flags().StringVar(&agentBinary, "agent-binary", "", "agent CLI for the replacements (default: the provider binary on PATH)")
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "failed replacements tolerated before rolling back")
+	cmd.Flags().DurationVar(&idleTimeout, "idle-timeout", orchestration.DefaultRolloutIdleTimeout, "max wait for each engine to finish its current car (busy engines are skipped)")
+	cmd.Flags().DurationVar(&startTimeout, "start-timeout", orchestration.DefaultRolloutStartTimeout, "max wait for each replacement to register")
+	_ = cmd.MarkFlagRequired("track")
+	return cmd
+}
+
+func runEngineRollout(cmd *cobra.Command, configPath string, opts orchestration.RolloutOpts) error {
+	if opts.AgentBinary != "" {
+		path, err := exec.LookPath(opts.AgentBinary)
+		if err != nil {
+			return fmt.Errorf("agent binary: %w", err)
+		}
+		// Engines start in their own shells; pin the resolved path.
+		if opts.AgentBinary, err = filepath.Abs(path); err != nil {
+			return fmt.Errorf("agent binary: %w", err)
+		}
+	}
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	lock, err := orchestration.AcquireLock(cfg.Owner, "engine rollout")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	out := cmd.OutOrStdout()
+	opts.DB, opts.Config, opts.ConfigPath = gormDB, cfg, configPath
+	opts.Progress = func(e progress.Event) {
+		fmt.Fprintf(out, "[%d/%d] Replacing %s (waits for its current car)...\n", e.Current, e.Total, e.Detail)
+	}
+
+	result, err := orchestration.Rollout(opts)
+	if result != nil {
+		printRolloutResult(out, result)
+	}
+	return err
+}
+
+func printRolloutResult(out io.Writer, r *orchestration.RolloutResult) {
+	for _, s := range r.Upgraded {
+		fmt.Fprintf(out, "  %s → %s\n", s.OldEngine, s.NewEngine)
+	}
+	for _, s := range r.Failed {
+		fmt.Fprintf(out, "  %s: replacement failed: %s\n", s.OldEngine, s.Error)
+	}
+	for _, s := range r.RolledBack {
+		if s.Error != "" {
+			fmt.Fprintf(out, "  %s: rollback failed: %s\n", s.OldEngine, s.Error)
+		} else {
+			fmt.Fprintf(out, "  %s rolled back → %s\n", s.OldEngine, s.NewEngine)
+		}
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(out, "  Skipped (still busy or stopped): %s\n", strings.Join(r.Skipped, ", "))
+	}
+	fmt.Fprintf(out, "Track %s: %d upgraded, %d failed, %d skipped\n", r.Track, len(r.Upgraded), len(r.Failed), len(r.Skipped))
+}
+
+// canaryTrackModel returns the track row a canary car's prompt is rendered
+// from: trackModel carrying tc's conventions.
+func canaryTrackModel(trackModel models.Track, tc config.TrackConfig) *models.Track {
+	if b, err := json.Marshal(tc.Conventions); err == nil && tc.Conventions != nil {
+		trackModel.Conventions = string(b)
+	}
+	return &trackModel
+}
+
 // formatUptime formats a duration as "Xh Ym" or "Ym Zs".
 func formatUptime(d time.Duration) string {
 	h := int(d.Hours())
@@ -211,7 +211,13 @@ func writeTranscriptEvent(b *strings.Builder, ev agentloop.Event) {
 		if ev.ToolError != "" {
 			fmt.Fprintf(b, "→ error: %s\n", agentloop.Truncate(ev.ToolError, 200))
 		} else {
-			fmt.Fprintf(b, "→ %s\n", agentloop.Truncate(ev.ToolResult, 200))
+			out := agentloop.Truncate(ev.ToolResult, 200)
+			fmt.Fprintf(b, "→ %s\n", out)
+			// Keep a non-zero bash exit visible (and journaled) when the
+			// trailing "[command failed: ...]" marker was truncated away.
+			if strings.Contains(ev.ToolResult, "[command failed") && !strings.Contains(out, "[command failed") {
+				b.WriteString("[command failed]\n")
+			}
 		}
 	}
 }
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/engine"
+)
+
+// hookBlockExit is the exit code that makes a claude PreToolUse hook refuse
+// the tool call; its stderr is shown to the agent as the reason. Any other
+// non-zero code lets the call through, so every failure here uses it.
+const hookBlockExit = 2
+
+// newEngineCheckCommandCmd is the PreToolUse hook engines install in each
+// worktree for tracks with a command_policy. It is not meant to be run by
+// hand, so it is hidden from help.
+func newEngineCheckCommandCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:           "check-command",
+		Short:         "Check an agent shell command against the track's command policy (agent hook)",
+		Hidden:        true,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEngineCheckCommand(cmd.InOrStdin(), cmd.ErrOrStderr())
+		},
+	}
+}
+
+// runEngineCheckCommand reads a PreToolUse hook payload from in and refuses
+// the command when the policy in engine.CommandPolicyEnv forbids it. Without
+// a policy every command is allowed.
+func runEngineCheckCommand(in io.Reader, errOut io.Writer) error {
+	policy, err := engine.CommandPolicyFromEnv()
+	if err != nil {
+		return refuseHook(errOut, err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	var payload struct {
+		ToolName  string `json:"tool_name"`
+		ToolInput struct {
+			Command string `json:"command"`
+		} `json:"tool_input"`
+	}
+	if err := json.NewDecoder(in).Decode(&payload); err != nil {
+		return refuseHook(errOut, fmt.Errorf("railyard command policy: read hook input: %w", err))
+	}
+	if payload.ToolInput.Command == "" {
+		return nil
+	}
+	if err := policy.Check(payload.ToolInput.Command); err != nil {
+		return refuseHook(errOut, err)
+	}
+	return nil
+}
+
+// refuseHook reports err to the agent and exits with hookBlockExit.
+func refuseHook(errOut io.Writer, err error) error {
+	fmt.Fprintln(errOut, err)
+	return withExitCode(hookBlockExit, err)
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/engine"
+)
+
+func hookInput(command string) *strings.Reader {
+	data, _ := json.Marshal(map[string]any{
+		"session_id": "s1",
+		"tool_name":  "Bash",
+		"tool_input": map[string]string{"command": command},
+	})
+	return strings.NewReader(string(data))
+}
+
+func TestRunEngineCheckCommand(t *testing.T) {
+	t.Setenv(engine.CommandPolicyEnv, `{"allow":["go","git"],"deny":["git push"]}`)
+
+	var errOut bytes.Buffer
+	if err := runEngineCheckCommand(hookInput("go test ./..."), &errOut); err != nil {
+		t.Errorf("allowed command refused: %v", err)
+	}
+
+	err := runEngineCheckCommand(hookInput(`git push origin "main"`), &errOut)
+	if exitCodeFor(err) != hookBlockExit {
+		t.Errorf("exit code = %d, want %d (err %v)", exitCodeFor(err), hookBlockExit, err)
+	}
+	if !strings.Contains(errOut.String(), `railyard command policy: blocked "git push origin \"main\"" (denied by "git push")`) {
+		t.Errorf("stderr = %q", errOut.String())
+	}
+
+	// Unreadable input fails closed.
+	if err := runEngineCheckCommand(strings.NewReader("not json"), &errOut); exitCodeFor(err) != hookBlockExit {
+		t.Errorf("bad input: exit code = %d, want %d", exitCodeFor(err), hookBlockExit)
+	}
+}
+
+func TestRunEngineCheckCommand_NoPolicy(t *testing.T) {
+	t.Setenv(engine.CommandPolicyEnv, "")
+	if err := runEngineCheckCommand(hookInput("kubectl delete ns prod"), &bytes.Buffer{}); err != nil {
+		t.Errorf("command refused without a policy: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/orchestration"
+)
+
+func newEngineStatsCmd() *cobra.Command {
+	var (
+		configPath  string
+		window      time.Duration
+		track       string
+		flaggedOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Rank engines by completed cars, cycle time, and merge failures",
+		Long: `Shows a leaderboard of engines over a rolling window (default 7 days):
+cars completed, average claim-to-completion time, the share of completed cars
+the merge gate rejected at least once, and restarts (stalls, ry engine
+restart, and the supervisor).
+
+Engines that underperform are flagged. RESTART marks a live engine that keeps
+releasing claims it never started on. REVIEW marks an engine with at least 3
+completed cars where half or more failed the merge gate, or whose average
+cycle time is over twice its track's: look at the track's system_prompt and
+conventions, or its agent_provider and agent_model. Flags also appear in the
+yard health of ry status and in the telegraph digests.`,
+		Example: `  ry engine stats
+  ry engine stats --window 24h --track backend
+  ry engine stats --flagged`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			if window <= 0 {
+				return fmt.Errorf("--window must be positive")
+			}
+			now := time.Now()
+			stats, err := orchestration.ComputeEngineStats(gormDB, now.Add(-window), now)
+			if err != nil {
+				return err
+			}
+			var shown []orchestration.EngineStats
+			for _, s := range stats {
+				if (track == "" || s.Track == track) && (!flaggedOnly || len(s.Flags) > 0) {
+					shown = append(shown, s)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if wantJSON(cmd) {
+				return writeJSON(out, newJSONEngineStats(shown))
+			}
+			if len(shown) == 0 {
+				fmt.Fprintln(out, "No engine activity in the window.")
+				return nil
+			}
+			printEngineStats(out, shown)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().DurationVar(&window, "window", orchestration.DefaultEngineStatsWindow, "rolling window the stats cover")
+	cmd.Flags().StringVar(&track, "track", "", "filter by track")
+	cmd.Flags().BoolVar(&flaggedOnly, "flagged", false, "show only flagged engines")
+	return supportsJSON(cmd)
+}
+
+// printEngineStats prints the leaderboard followed by each flag's reason and
+// suggested action.
+func printEngineStats(out io.Writer, stats []orchestration.EngineStats) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RANK\tENGINE\tTRACK\tSTATUS\tCOMPLETED\tAVG CYCLE\tMERGE FAIL\tRESTARTS\tFLAGS")
+	for i, s := range stats {
+		failRate := "-"
+		if rate := s.MergeFailureRate(); rate >= 0 {
+			failRate = fmt.Sprintf("%.0f%%", rate*100)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%d\t%s\n",
+			i+1, s.EngineID, s.Track, s.Status, s.Completed,
+			formatDuration(s.AvgCycle.Seconds()), failRate, s.Restarts, s.FlagKinds())
+	}
+	w.Flush()
+
+	for _, s := range orchestration.FlaggedEngines(stats) {
+		for _, f := range s.Flags {
+			fmt.Fprintf(out, "\n%s [%s]: %s\n  → %s\n", s.EngineID, f.Kind, f.Reason, f.Action)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/orchestration"
+)
+
+func TestPrintEngineStats(t *testing.T) {
+	var buf bytes.Buffer
+	printEngineStats(&buf, []orchestration.EngineStats{
+		{EngineID: "eng-fast", Track: "backend", Status: "idle", Completed: 4, GateFailures: 1, AvgCycle: 30 * time.Minute},
+		{EngineID: "eng-new", Track: "frontend", Status: "working"},
+		{
+			EngineID: "eng-slow", Track: "backend", Status: "working", Completed: 3, GateFailures: 2, AvgCycle: 10 * time.Hour, Restarts: 1,
+			Flags: []orchestration.EngineFlag{{Kind: orchestration.FlagReview, Reason: "2 of 3 completed cars failed the merge gate (67%)", Action: "review the backend track's system_prompt"}},
+		},
+	})
+	out := buf.String()
+
+	lines := strings.Split(out, "\n")
+	if !strings.HasPrefix(lines[0], "RANK") || !strings.Contains(lines[0], "MERGE FAIL") {
+		t.Errorf("header = %q", lines[0])
+	}
+	for _, want := range []string{"25%", "30m 0s", "10h 0m", "67%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if fields := strings.Fields(lines[2]); fields[1] != "eng-new" || fields[5] != "-" || fields[6] != "-" {
+		t.Errorf("engine without completions = %q, want - for cycle and failure rate", lines[2])
+	}
+	if !strings.Contains(out, "eng-slow [review]: 2 of 3 completed cars failed the merge gate (67%)\n  → review the backend track's system_prompt") {
+		t.Errorf("output should explain the flag:\n%s", out)
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func newEpicCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "epic",
+		Short: "Epic management commands",
+	}
+	cmd.AddCommand(newEpicDispatchCmd())
+	return cmd
+}
+
+func newEpicDispatchCmd() *cobra.Command {
+	var (
+		configPath string
+		engines    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dispatch <id>",
+		Short: "Send an epic's children to the engines as one batch",
+		Long: `Publishes an epic and all its draft children at once. Children whose
+blockers have merged are ready immediately; the rest become ready as their
+blockers merge. The children are listed in dependency waves.
+
+Engine capacity is reserved for the epic on each of its children's tracks:
+one engine per open child, at most --engines, and never more than the
+track's engine_slots leave after other dispatched epics' reservations. The
+reservation is soft: engines claim the epic's ready children first while
+fewer than the reserved engines work on it, and claim other cars when none
+are ready. Running dispatch again re-sizes the reservations.
+
+Telegraph posts a progress rollup for the epic every pulse interval while
+it changes, in the epic's thread on Slack and Discord.`,
+		Example: `  ry epic dispatch car-a1b2c3d4
+  ry epic dispatch car-a1b2c3d4 --engines 2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if engines < 0 {
+				return fmt.Errorf("--engines must not be negative")
+			}
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			id, err := resolveCarID(cmd, gormDB, args[0])
+			if err != nil {
+				return err
+			}
+			slots := trackSlots(cfg)
+			result, err := car.DispatchEpic(gormDB, id, car.DispatchEpicOpts{Engines: engines, TrackSlots: slots})
+			if err != nil {
+				return err
+			}
+			printEpicDispatch(cmd.OutOrStdout(), id, result, slots)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().IntVar(&engines, "engines", 0, "max engines to reserve per track (default: one per open child)")
+	return cmd
+}
+
+// trackSlots maps each configured track to its engine_slots.
+func trackSlots(cfg *config.Config) map[string]int {
+	slots := make(map[string]int, len(cfg.Tracks))
+	for _, t := range cfg.Tracks {
+		slots[t.Name] = t.EngineSlots
+	}
+	return slots
+}
+
+// printEpicDispatch reports the published cars, the dependency waves, and
+// the engines reserved on each track.
+func printEpicDispatch(out io.Writer, epicID string, d *car.EpicDispatch, slots map[string]int) {
+	fmt.Fprintf(out, "Dispatched epic %s (%d car(s) published)\n", epicID, d.Published)
+	if len(d.Waves) == 0 && len(d.External) == 0 {
+		fmt.Fprintln(out, "No open children to dispatch.")
+	}
+	for i, wave := range d.Waves {
+		label := fmt.Sprintf("Wave %d", i+1)
+		if i == 0 {
+			label += " (ready now)"
+		}
+		fmt.Fprintf(out, "%s: %s\n", label, epicCarIDs(wave))
+	}
+	if len(d.External) > 0 {
+		fmt.Fprintf(out, "Waiting on cars outside the epic: %s\n", epicCarIDs(d.External))
+	}
+	if len(d.Reservations) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, "\nReserved engines:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, r := range d.Reservations {
+		note := ""
+		if r.Engines == 0 {
+			note = "  (engine_slots already reserved by other epics)"
+		}
+		fmt.Fprintf(w, "  %s\t%d of %d engine_slots%s\n", r.Track, r.Engines, slots[r.Track], note)
+	}
+	w.Flush()
+}
+
+func epicCarIDs(cars []models.Car) string {
+	ids := make([]string, len(cars))
+	for i, c := range cars {
+		ids[i] = c.ID
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestPrintEpicDispatch(t *testing.T) {
+	var buf bytes.Buffer
+	printEpicDispatch(&buf, "car-epic", &car.EpicDispatch{
+		Published: 4,
+		Waves:     [][]models.Car{{{ID: "car-api"}, {ID: "car-db"}}, {{ID: "car-ui"}}},
+		External:  []models.Car{{ID: "car-docs"}},
+		Reservations: []models.EpicReservation{
+			{EpicID: "car-epic", Track: "backend", Engines: 2},
+			{EpicID: "car-epic", Track: "frontend", Engines: 0},
+		},
+	}, map[string]int{"backend": 3, "frontend": 2})
+	out := buf.String()
+
+	for _, want := range []string{
+		"Dispatched epic car-epic (4 car(s) published)",
+		"Wave 1 (ready now): car-api, car-db",
+		"Wave 2: car-ui",
+		"Waiting on cars outside the epic: car-docs",
+		"2 of 3 engine_slots",
+		"0 of 2 engine_slots  (engine_slots already reserved by other epics)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/events"
+	"github.com/zulandar/railyard/pkg/plugin"
+	"gorm.io/gorm"
+)
+
+// persistEvents records every core event published on bus to the events
+// table for `ry events list`.
+func persistEvents(bus events.Bus, gormDB *gorm.DB, actor string, logger *slog.Logger) events.Unsubscribe {
+	core := plugin.CoreEventTypes()
+	topics := make([]string, len(core))
+	for i, t := range core {
+		topics[i] = string(t)
+	}
+	return events.Persist(bus, gormDB, actor, logger, topics...)
+}
+
+func newEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Query the yard's event log",
+	}
+	cmd.AddCommand(newEventsListCmd())
+	return cmd
+}
+
+func newEventsListCmd() *cobra.Command {
+	var (
+		configPath string
+		since      string
+		carID      string
+		engineID   string
+		kind       string
+		limit      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show recorded state changes: car transitions, merges, engine starts and stalls, scaling",
+		Long: `Lists events recorded by the yardmaster, engines, the dashboard, and ry
+commands that change the yard — car creation, claims and status changes,
+merges and merge failures, engine starts, stops and stalls, yardmaster
+actions (reassignments, autoscaling, released claims), scaling, and
+pause/resume — oldest first.`,
+		Example: `  ry events list --since 1h
+  ry events list --since 12h --car car-123
+  ry events list --engine eng-a1b2c3d4 --kind EngineStalled`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := events.ListFilter{CarID: carID, EngineID: engineID, Kind: kind, Limit: limit}
+			if since != "" {
+				t, err := parseAuditTime(since, time.Now())
+				if err != nil {
+					return fmt.Errorf("--since: %w", err)
+				}
+				f.Since = t
+			}
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runEventsList(cmd.OutOrStdout(), gormDB, f)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&since, "since", "24h", "how far back to look: a duration (1h, 7d) or a date (2006-01-02)")
+	cmd.Flags().StringVar(&carID, "car", "", "only events for this car")
+	cmd.Flags().StringVar(&engineID, "engine", "", "only events for this engine")
+	cmd.Flags().StringVar(&kind, "kind", "", "only events of this kind (e.g. CarStatusChanged)")
+	cmd.Flags().IntVar(&limit, "limit", 200, "show at most the newest N events (0 for all)")
+	return cmd
+}
+
+func runEventsList(out io.Writer, gormDB *gorm.DB, f events.ListFilter) error {
+	evs, err := events.List(gormDB, f)
+	if err != nil {
+		return err
+	}
+	if len(evs) == 0 {
+		fmt.Fprintln(out, "No events.")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tKIND\tACTOR\tCAR\tENGINE\tPAYLOAD")
+	for _, e := range evs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.CreatedAt.Format("2006-01-02 15:04:05"), e.Kind, dashIfEmpty(e.Actor), dashIfEmpty(e.CarID), dashIfEmpty(e.EngineID), e.Payload)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEventsList_PauseResume(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	if err := runPause(&buf, gormDB, "bad deploy", "alice"); err != nil {
+		t.Fatalf("runPause: %v", err)
+	}
+	if err := runResume(&buf, gormDB, "bob"); err != nil {
+		t.Fatalf("runResume: %v", err)
+	}
+
+	out, err := execCmd(t, []string{"events", "list", "--since", "1h", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("events list: %v", err)
+	}
+	paused, resumed := strings.Index(out, "YardPaused"), strings.Index(out, "YardResumed")
+	if paused < 0 || resumed < paused || !strings.Contains(out, "alice") || !strings.Contains(out, "bad deploy") {
+		t.Errorf("events list should show the pause by alice then the resume, got:\n%s", out)
+	}
+
+	out, err = execCmd(t, []string{"events", "list", "--car", "car-none", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("events list --car: %v", err)
+	}
+	if !strings.Contains(out, "No events.") {
+		t.Errorf("events list --car car-none = %q, want no events", out)
+	}
+}
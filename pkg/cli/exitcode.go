@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/telegraph"
+	"github.com/zulandar/railyard/internal/undo"
+	"gorm.io/gorm"
+)
+
+// Exit codes returned by ry. They are a stable contract for scripts: a code's
+// meaning never changes, and new failure classes get new codes. Keep
+// exitCodesHelp in sync.
+const (
+	ExitOK       = 0
+	ExitFailure  = 1 // unclassified failure
+	ExitConfig   = 2 // config file missing, unparsable, or invalid
+	ExitNotFound = 3 // the named car, engine, session, etc. does not exist
+	ExitConflict = 4 // the request conflicts with current state (status transition, concurrent edit)
+	ExitInfra    = 5 // database, network, or other infrastructure unavailable
+	ExitUsage    = 6 // bad command line: unknown command or flag, wrong arguments
+)
+
+const exitCodesHelp = `ry exits with a code that identifies the class of failure, so scripts can
+branch on it instead of matching error text. Codes are stable across releases.
+
+  0  success
+  1  unclassified failure
+  2  config error: railyard.yaml missing, unparsable, or invalid
+  3  not found: the named car, engine, session, or other object does not exist
+  4  conflict: the request conflicts with current state (e.g. an invalid status
+     transition, the car changed underneath the command, or another ry
+     operation or dispatch session holds the lock)
+  5  infrastructure: the database or another service could not be reached
+  6  usage: unknown command or flag, or wrong number of arguments
+
+Example:
+
+  ry car update car-1a2b --status done
+  case $? in
+    3) echo "no such car" ;;
+    4) echo "car is not in a state that can be marked done" ;;
+  esac`
+
+// exitError attaches an explicit exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode marks err as belonging to the failure class code. A nil err
+// stays nil.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCodeFor classifies err into one of the Exit* codes. Explicit codes
+// (withExitCode) win; then known sentinel and typed errors; then, for the
+// internal packages that report lookups as "<thing> not found", the message.
+func exitCodeFor(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+
+	var cfgErr *config.LoadError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &cfgErr):
+		return ExitConfig
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, errIDNotFound), errors.Is(err, undo.ErrEmpty),
+		errors.Is(err, car.ErrNotFound), errors.Is(err, orchestration.ErrNotFound), errors.Is(err, orchestration.ErrNoSession),
+		errors.Is(err, telegraph.ErrNoSession), errors.Is(err, telegraph.ErrSessionNotActive):
+		return ExitNotFound
+	case errors.Is(err, car.ErrInvalidTransition), errors.Is(err, car.ErrConcurrentModification),
+		errors.Is(err, orchestration.ErrLocked), errors.Is(err, telegraph.ErrLockHeld):
+		return ExitConflict
+	case errors.Is(err, driver.ErrBadConn), errors.As(err, &netErr):
+		return ExitInfra
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return ExitNotFound
+	case isCobraUsageError(msg):
+		return ExitUsage
+	}
+	return ExitFailure
+}
+
+// isCobraUsageError recognizes the command-line errors cobra raises itself
+// before any RunE runs; flag and argument errors are tagged directly in
+// markUsageErrors.
+func isCobraUsageError(msg string) bool {
+	return strings.HasPrefix(msg, "unknown command ") ||
+		strings.HasPrefix(msg, "required flag(s) ") ||
+		strings.HasPrefix(msg, "if any flags in the group ")
+}
+
+// markUsageErrors tags flag-parsing and positional-argument errors from every
+// command in the tree with ExitUsage.
+func markUsageErrors(root *cobra.Command) {
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if args := c.Args; args != nil {
+			c.Args = func(cmd *cobra.Command, a []string) error {
+				return withExitCode(ExitUsage, args(cmd, a))
+			}
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+}
+
+// newExitCodesHelpCmd is a help topic: `ry help exit-codes`.
+func newExitCodesHelpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exit-codes",
+		Short: "Exit codes ry returns, by failure class",
+		Long:  exitCodesHelp,
+	}
+}
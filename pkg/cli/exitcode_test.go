@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/telegraph"
+	"gorm.io/gorm"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	_, cfgErr := config.Parse([]byte("owner: [unterminated"))
+	if cfgErr == nil {
+		t.Fatal("expected config parse error")
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"generic", fmt.Errorf("boom"), ExitFailure},
+		{"explicit", withExitCode(ExitConflict, fmt.Errorf("busy")), ExitConflict},
+		{"config", fmt.Errorf("load config: %w", cfgErr), ExitConfig},
+		{"record not found", fmt.Errorf("get: %w", gorm.ErrRecordNotFound), ExitNotFound},
+		{"resolver not found", fmt.Errorf("car %w: x", errIDNotFound), ExitNotFound},
+		{"message not found", fmt.Errorf("engine: not found: eng-1"), ExitNotFound},
+		{"car not found", fmt.Errorf("car: parent %w: car-1", car.ErrNotFound), ExitNotFound},
+		{"no railyard session", fmt.Errorf("orchestration: %w", orchestration.ErrNoSession), ExitNotFound},
+		{"no dispatch session", fmt.Errorf("telegraph: %w for C1:T1", telegraph.ErrNoSession), ExitNotFound},
+		{"transition", fmt.Errorf("update: %w", car.ErrInvalidTransition), ExitConflict},
+		{"concurrent", car.ErrConcurrentModification, ExitConflict},
+		{"orchestration lock", &orchestration.LockedError{Path: "/tmp/x.lock"}, ExitConflict},
+		{"dispatch lock", fmt.Errorf("telegraph: acquire lock: %w", telegraph.ErrLockHeld), ExitConflict},
+		{"network", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, ExitInfra},
+		{"unknown command", fmt.Errorf(`unknown command "frob" for "ry"`), ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeFor(tt.err); got != tt.want {
+				t.Errorf("exitCodeFor(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_ExitCodes(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+	gormDB.Create(&models.Car{ID: "car-x1", Title: "Merged", Track: "backend", Status: "merged"})
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"unknown flag", []string{"car", "list", "--bogus"}, ExitUsage},
+		{"wrong args", []string{"car", "show"}, ExitUsage},
+		{"unknown command", []string{"frobnicate"}, ExitUsage},
+		{"car not found", []string{"car", "show", "car-zzzzzz", "--config", "test.yaml"}, ExitNotFound},
+		{"invalid transition", []string{"car", "update", "car-x1", "--status", "open", "--config", "test.yaml"}, ExitConflict},
+		{"missing config", []string{"dispatch", "--config", "/nonexistent/railyard.yaml"}, ExitConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRootCmd()
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			cmd.SetArgs(tt.args)
+			if got := execute(cmd); got != tt.want {
+				t.Errorf("exit code = %d, want %d; output:\n%s", got, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestHelpExitCodes(t *testing.T) {
+	out, err := execCmd(t, []string{"help", "exit-codes"})
+	if err != nil {
+		t.Fatalf("help exit-codes: %v", err)
+	}
+	for _, want := range []string{"2  config error", "3  not found", "4  conflict", "5  infrastructure"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("help output missing %q:\n%s", want, out)
+		}
+	}
+}
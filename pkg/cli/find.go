@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/find"
+	"gorm.io/gorm"
+)
+
+func newFindCmd() *cobra.Command {
+	var (
+		configPath string
+		limit      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "find <query>",
+		Short: "Search cars, epics, notes, journals, and sessions at once",
+		Long: `Searches every record Railyard keeps for the query — epics, cars, progress
+notes, engine journals, dispatch conversations, and track notes — and prints
+the hits grouped by kind, each with the command that shows more.
+
+Matching is case-insensitive on any part of the text; cars also match on
+their ID. Each group shows up to --limit hits, newest (or, for cars, highest
+priority) first.`,
+		Example: `  ry find payments
+  ry find "STRIPE_KEY" --limit 3
+  ry find refund -o json | jq '.[] | select(.kind == "car")'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runFind(cmd, gormDB, strings.Join(args, " "), limit)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().IntVar(&limit, "limit", find.DefaultLimit, "maximum hits per group")
+	return supportsJSON(cmd)
+}
+
+func runFind(cmd *cobra.Command, gormDB *gorm.DB, query string, limit int) error {
+	groups, err := find.Search(gormDB, query, limit)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if wantJSON(cmd) {
+		return writeJSON(out, toJSONFindGroups(groups))
+	}
+	printFindGroups(out, query, groups)
+	return nil
+}
+
+func printFindGroups(out io.Writer, query string, groups []find.Group) {
+	if len(groups) == 0 {
+		fmt.Fprintf(out, "Nothing matches %q.\n", query)
+		return
+	}
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		more := ""
+		if g.More {
+			more = ", more not shown"
+		}
+		fmt.Fprintf(out, "%s (%d%s)\n", g.Label, len(g.Hits), more)
+		for _, h := range g.Hits {
+			label := h.ID
+			if h.CarID != "" && h.CarID != h.ID {
+				label = h.CarID
+			}
+			fmt.Fprintf(out, "  %s  %s  %s\n", label, h.At.Local().Format("2006-01-02"), truncate(h.Title, 60))
+			if h.Snippet != "" && h.Snippet != h.Title {
+				fmt.Fprintf(out, "      %s\n", h.Snippet)
+			}
+			fmt.Fprintf(out, "      → %s\n", h.Link)
+		}
+	}
+}
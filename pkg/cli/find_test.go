@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestFindCmd(t *testing.T) {
+	gormDB := mockTestDB(t)
+	defer withMockDB(t, gormDB)()
+	gormDB.Create(&models.Car{ID: "car-pay", Title: "Payments retry", Track: "backend", Status: "open"})
+	gormDB.Create(&models.CarProgress{CarID: "car-pay", EngineID: "eng-1", Note: "payments client wired", FilesChanged: "[]"})
+
+	out, err := execCmd(t, []string{"find", "payments", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("find: %v\n%s", err, out)
+	}
+	for _, want := range []string{"Cars (1)", "car-pay", "→ ry car show car-pay", "Progress notes (1)", "payments client wired"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = execCmd(t, []string{"find", "payments", "-o", "json", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("find -o json: %v\n%s", err, out)
+	}
+	var groups []jsonFindGroup
+	if err := json.Unmarshal([]byte(out), &groups); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if len(groups) != 2 || groups[0].Kind != "car" || groups[0].Hits[0].Link != "ry car show car-pay" {
+		t.Errorf("groups = %+v", groups)
+	}
+
+	out, err = execCmd(t, []string{"find", "nothing", "here", "--config", "test.yaml"})
+	if err != nil || !strings.Contains(out, `Nothing matches "nothing here".`) {
+		t.Errorf("no hits: %v\n%s", err, out)
+	}
+}
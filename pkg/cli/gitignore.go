@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/zulandar/railyard/internal/config"
 )
 
 func newGitIgnoreCmd() *cobra.Command {
@@ -47,7 +46,7 @@ func runGitIgnore(out io.Writer, configPath string, dryRun, detect bool) error {
 	if detect {
 		languages = detectLanguages(".")
 	} else {
-		cfg, err := config.Load(configPath)
+		cfg, err := loadConfig(configPath)
 		if err != nil {
 			// Fall back to detection if config is unavailable.
 			fmt.Fprintln(out, "Could not load config, falling back to language detection...")
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/engine"
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import records from before Railyard",
+	}
+	cmd.AddCommand(newImportGitHistoryCmd())
+	return cmd
+}
+
+func newImportGitHistoryCmd() *cobra.Command {
+	var (
+		configPath string
+		since      string
+		track      string
+		base       string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "git-history",
+		Short: "Create archived cars from past merges for analytics",
+		Long: `Mines the base branch's merge commits and squash-merged pull requests
+from before Railyard and records each as a merged car, so the digest's
+throughput and cycle-time numbers have a baseline to compare against.
+
+Titles come from the pull request title in the merge message. Each car's
+track is the one whose file_patterns match most of the merge's files,
+falling back to --track; merges matching no track are skipped. A merge's
+cycle time runs from the first commit on its branch to the merge; squash
+merges record no cycle time. Merges Railyard made, and merges imported
+before, are skipped, so the command can be run again safely.
+
+--since takes a duration (6m for six months, 2y, 4w, 30d, 72h) or a date.
+Imported cars are marked with the merge commit (imported_from) and never
+reach an engine. With --track naming a track that has its own repo, that
+repository's history is imported onto the track.`,
+		Example: `  ry import git-history --since 6m
+  ry import git-history --since 2026-01-01 --track backend --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportGitHistory(cmd, configPath, since, track, base, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&since, "since", "6m", "how far back to import: a duration (6m = six months, 2y, 4w, 30d) or a date (2006-01-02)")
+	cmd.Flags().StringVar(&track, "track", "", "track for merges whose files match no track (default: skip them)")
+	cmd.Flags().StringVar(&base, "base", "", "branch the merges landed on (default: the track's or repo's base branch)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be imported without creating cars")
+	return cmd
+}
+
+func runImportGitHistory(cmd *cobra.Command, configPath, since, track, base string, dryRun bool) error {
+	from, err := parseHistorySince(since, time.Now())
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if track != "" {
+		if err := checkCarTrack(cfg, track); err != nil {
+			return err
+		}
+	}
+	repoDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	if base == "" {
+		base = engine.TrackBaseBranch(cfg, repoDir, track)
+	}
+
+	res, err := yardmaster.ImportGitHistory(gormDB, yardmaster.HistoryImportOpts{
+		RepoDir:      repoDir,
+		Config:       cfg,
+		BaseBranch:   base,
+		Since:        from,
+		DefaultTrack: track,
+		IDFormat:     car.IDFormatFromConfig(cfg),
+		DryRun:       dryRun,
+	})
+	if res != nil {
+		printHistoryImport(cmd.OutOrStdout(), res, dryRun)
+	}
+	return err
+}
+
+// parseHistorySince reads --since. History is measured in months, so unlike
+// the other --since flags "m" means months here; minutes make no sense.
+func parseHistorySince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s != "" {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'm':
+				return now.AddDate(0, -n, 0), nil
+			case 'y':
+				return now.AddDate(-n, 0, 0), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	return parseAuditTime(s, now)
+}
+
+func printHistoryImport(out io.Writer, res *yardmaster.HistoryImportResult, dryRun bool) {
+	if len(res.Imported) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MERGED\tPR\tCAR\tTRACK\tCYCLE\tTITLE")
+		for _, m := range res.Imported {
+			carID := m.CarID
+			if dryRun {
+				carID = "(dry run)"
+			}
+			pr, cycle := "-", "-"
+			if m.PR != 0 {
+				pr = fmt.Sprintf("#%d", m.PR)
+			}
+			if m.StartedAt != nil {
+				cycle = formatDuration(m.MergedAt.Sub(*m.StartedAt).Seconds())
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", m.MergedAt.Local().Format("2006-01-02"), pr, carID, m.Track, cycle, truncate(m.Title, 60))
+		}
+		w.Flush()
+	}
+	for _, m := range res.Skipped {
+		fmt.Fprintf(out, "Skipped %.10s (%s): %s\n", m.Commit, truncate(m.Title, 40), m.Reason)
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(out, "%s %d merge(s) as archived cars, skipped %d\n", verb, len(res.Imported), len(res.Skipped))
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHistorySince(t *testing.T) {
+	now := time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"6m":         now.AddDate(0, -6, 0),
+		"1y":         now.AddDate(-1, 0, 0),
+		"4w":         now.AddDate(0, 0, -28),
+		"30d":        now.AddDate(0, 0, -30),
+		"72h":        now.Add(-72 * time.Hour),
+		"2026-01-01": time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local),
+	} {
+		got, err := parseHistorySince(in, now)
+		if err != nil {
+			t.Errorf("parseHistorySince(%q): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseHistorySince(%q) = %v, want %v", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "m", "soon"} {
+		if _, err := parseHistorySince(in, now); err == nil {
+			t.Errorf("parseHistorySince(%q) succeeded, want error", in)
+		}
+	}
+}
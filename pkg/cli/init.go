@@ -994,7 +994,7 @@ func runInit(cmd *cobra.Command, configPath string, yes, skipDB, skipCoco, skipT
 
 	// Step 7: Initialize the database.
 	fmt.Fprintln(out, "")
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load generated config: %w", err)
 	}
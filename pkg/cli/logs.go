@@ -66,6 +66,17 @@ func runLogs(cmd *cobra.Command, configPath string, opts logsOpts) error {
 	if err != nil {
 		return err
 	}
+	engineID, err := resolveEngineID(cmd, gormDB, opts.engineID)
+	if opts.engineID, err = filterID(engineID, opts.engineID, err); err != nil {
+		return err
+	}
+	carID, err := lookupCarID(cmd, gormDB, opts.carID)
+	if opts.carID, err = filterID(carID, opts.carID, err); err != nil {
+		return err
+	}
+	if opts.sessionID, err = resolveSessionID(cmd, gormDB, opts.sessionID); err != nil {
+		return err
+	}
 
 	out := cmd.OutOrStdout()
 
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+func newMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Inspect and correct merge-gate attempts",
+		Long: `Every failed merge-gate run is recorded as a merge attempt with its failure
+category. A test failure blocks the car for its engine; an infrastructure
+failure sends it to merge-failed for a human. When the merge gate gets that
+wrong, relabel the attempt: the output pattern behind it is saved to the
+yardmaster's failure patterns file so the next run with the same output is
+classified correctly.`,
+	}
+
+	cmd.AddCommand(newMergeAttemptsCmd())
+	cmd.AddCommand(newMergeRelabelCmd())
+	return cmd
+}
+
+func newMergeAttemptsCmd() *cobra.Command {
+	var (
+		configPath string
+		limit      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "attempts [car-id]",
+		Short: "List failed merge attempts and their labels",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			q := gormDB.Where("engine_id = ? AND note LIKE ?", yardmaster.YardmasterID, "switch:%")
+			if len(args) == 1 {
+				id, err := lookupCarID(cmd, gormDB, args[0])
+				if err != nil {
+					return err
+				}
+				q = q.Where("car_id = ?", id)
+			}
+			var attempts []models.CarProgress
+			if err := q.Order("id DESC").Limit(limit).Find(&attempts).Error; err != nil {
+				return fmt.Errorf("list merge attempts: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if len(attempts) == 0 {
+				fmt.Fprintln(out, "No failed merge attempts")
+				return nil
+			}
+			ids := make([]uint, len(attempts))
+			for i, a := range attempts {
+				ids[i] = a.ID
+			}
+			var labels []models.FailureLabel
+			if err := gormDB.Where("progress_id IN ?", ids).Find(&labels).Error; err != nil {
+				return fmt.Errorf("list merge attempts: %w", err)
+			}
+			labelOf := make(map[uint]string, len(labels))
+			for _, l := range labels {
+				labelOf[l.ProgressID] = l.Label
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ATTEMPT\tCAR\tCATEGORY\tRELABELED\tWHEN\tERROR")
+			for _, a := range attempts {
+				category, msg := yardmaster.ParseSwitchNote(a.Note)
+				relabeled := labelOf[a.ID]
+				if relabeled == "" {
+					relabeled = "-"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", a.ID, a.CarID, category, relabeled,
+					a.CreatedAt.Local().Format("2006-01-02 15:04"), truncate(msg, 60))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of attempts to show")
+	return cmd
+}
+
+func newMergeRelabelCmd() *cobra.Command {
+	var (
+		configPath string
+		as         string
+		pattern    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "relabel <attempt-id> --as infra|test",
+		Short: "Correct a merge attempt's failure category and learn from its output",
+		Long: `Records that a failed merge attempt was really an infrastructure failure
+(--as infra) or a code test failure (--as test), and adds a pattern from the
+attempt's test output to yardmaster.failure_patterns_file. The merge gate
+reads that file on every failed run: infra patterns extend its built-in
+infrastructure signatures, and test patterns stop a built-in signature from
+claiming output it should not.
+
+The pattern is taken from the output (the first error line for infra, the
+line with the misfiring signature for test, minus numbers); pass --pattern
+to choose a lowercase substring yourself. The car's status is not changed.`,
+		Example: `  ry merge attempts car-a1b2c3d4
+  ry merge relabel 812 --as infra
+  ry merge relabel 815 --as test --pattern "sqlstate[23000]: integrity constraint violation"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid attempt ID %q", args[0])
+			}
+			var label yardmaster.SwitchFailureCategory
+			switch as {
+			case "infra":
+				label = yardmaster.SwitchFailInfra
+			case "test":
+				label = yardmaster.SwitchFailTest
+			default:
+				return fmt.Errorf("--as must be infra or test")
+			}
+
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			patternsFile := cfg.Yardmaster.FailurePatternsFile
+			if patternsFile == "" {
+				patternsFile = yardmaster.DefaultFailurePatternsFile
+			}
+			l, err := yardmaster.RelabelFailure(gormDB, yardmaster.RelabelOpts{
+				AttemptID:    uint(id),
+				Label:        label,
+				Pattern:      pattern,
+				PatternsFile: patternsFile,
+				LabeledBy:    cfg.Owner,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Relabeled attempt %d on %s: %s -> %s\nLearned pattern %q in %s\n",
+				l.ProgressID, l.CarID, l.OriginalCategory, l.Label, l.Pattern, patternsFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&as, "as", "", "correct category: infra or test")
+	cmd.Flags().StringVar(&pattern, "pattern", "", "output substring to learn (default: derived from the attempt's output)")
+	cmd.MarkFlagRequired("as")
+	return cmd
+}
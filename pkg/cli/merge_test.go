@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+func TestRunMergeRelabel(t *testing.T) {
+	gormDB := mockTestDB(t)
+	defer withMockDB(t, gormDB)()
+	t.Chdir(t.TempDir())
+
+	attempt := models.CarProgress{CarID: "car-gate", EngineID: yardmaster.YardmasterID,
+		Note: "switch:test-failed: tests failed\n--- test output ---\nupload_test.go:42: i/o timeout talking to minio\n"}
+	gormDB.Create(&attempt)
+
+	out, err := execCmd(t, []string{"merge", "attempts", "--config", "test.yaml"})
+	if err != nil || !strings.Contains(out, "car-gate") || !strings.Contains(out, "test-failed") {
+		t.Fatalf("attempts: %v\n%s", err, out)
+	}
+	if _, err := execCmd(t, []string{"merge", "relabel", "1", "--as", "flaky", "--config", "test.yaml"}); err == nil {
+		t.Error("an unknown --as value should fail")
+	}
+	out, err = execCmd(t, []string{"merge", "relabel", "1", "--as", "infra", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("relabel: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "test-failed -> infra-failed") || !strings.Contains(out, `"i/o timeout talking to minio"`) {
+		t.Errorf("relabel output:\n%s", out)
+	}
+	out, _ = execCmd(t, []string{"merge", "attempts", "--config", "test.yaml"})
+	if !strings.Contains(out, "infra-failed") {
+		t.Errorf("attempts after relabel:\n%s", out)
+	}
+}
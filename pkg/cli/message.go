@@ -2,20 +2,26 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/messaging"
+	"gorm.io/gorm"
 )
 
 func newMessageCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "message",
-		Short: "Messaging commands",
+		Use:     "message",
+		Aliases: []string{"msg"},
+		Short:   "Messaging commands",
 	}
 
 	cmd.AddCommand(newMessageSendCmd())
+	cmd.AddCommand(newMessageBroadcastCmd())
 	cmd.AddCommand(newMessageAckCmd())
 	cmd.AddCommand(newMessageThreadCmd())
 	return cmd
@@ -76,6 +82,61 @@ func newMessageSendCmd() *cobra.Command {
 	return cmd
 }
 
+func newMessageBroadcastCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+		from       string
+		priority   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "broadcast <text>",
+		Short: "Send guidance to every active engine on a track",
+		Long: `Sends guidance to every active engine on a track, e.g. to steer agents
+while an incident is handled. Each engine picks the message up on its next
+inbox check and includes it in the prompt of its next agent session; an
+agent session already running finishes without it.
+
+Each engine acknowledges its copy when it picks it up. The copies share a
+thread, so ry message thread <id> shows which engines have it.`,
+		Example: `  ry msg broadcast --track backend "pause refactors, hotfix incoming"`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				from = currentUserName()
+			}
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runMessageBroadcast(cmd.OutOrStdout(), gormDB, track, from, priority, strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track whose engines receive the message (required)")
+	cmd.Flags().StringVar(&from, "from", "", "sender shown to the engines (default: current user)")
+	cmd.Flags().StringVar(&priority, "priority", "normal", "message priority (normal, urgent)")
+	cmd.MarkFlagRequired("track")
+	return cmd
+}
+
+func runMessageBroadcast(out io.Writer, gormDB *gorm.DB, track, from, priority, body string) error {
+	msgs, err := messaging.SendToTrack(gormDB, from, track, string(engine.InstructionGuidance), body, messaging.SendOpts{
+		Priority: priority,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Sent to %d engine(s) on track %s:\n", len(msgs), track)
+	for _, m := range msgs {
+		fmt.Fprintf(out, "  %s (message %d)\n", m.ToAgent, m.ID)
+	}
+	fmt.Fprintf(out, "Check acknowledgement with: ry message thread %d\n", *msgs[0].ThreadID)
+	return nil
+}
+
 func newInboxCmd() *cobra.Command {
 	var (
 		configPath string
@@ -98,6 +159,9 @@ func newInboxCmd() *cobra.Command {
 			}
 
 			out := cmd.OutOrStdout()
+			if wantJSON(cmd) {
+				return writeJSON(out, newJSONMessages(msgs))
+			}
 			if len(msgs) == 0 {
 				fmt.Fprintf(out, "No messages for %s\n", agent)
 				return nil
@@ -118,7 +182,7 @@ func newInboxCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().StringVar(&agent, "agent", "", "agent ID to check inbox (required)")
 	cmd.MarkFlagRequired("agent")
-	return cmd
+	return supportsJSON(cmd)
 }
 
 func newMessageAckCmd() *cobra.Command {
@@ -199,9 +263,13 @@ func newMessageThreadCmd() *cobra.Command {
 			}
 
 			for _, m := range msgs {
-				fmt.Fprintf(out, "[%s] %s → %s: %s\n%s\n\n",
+				ack := ""
+				if m.Acknowledged {
+					ack = " (acknowledged)"
+				}
+				fmt.Fprintf(out, "[%s] %s → %s: %s%s\n%s\n\n",
 					m.CreatedAt.Format("2006-01-02 15:04"),
-					m.FromAgent, m.ToAgent, m.Subject, m.Body)
+					m.FromAgent, m.ToAgent, m.Subject, ack, m.Body)
 			}
 			return nil
 		},
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestRunMessageBroadcast(t *testing.T) {
+	gormDB := mockTestDB(t)
+	for _, id := range []string{"eng-1", "eng-2"} {
+		if err := gormDB.Create(&models.Engine{ID: id, Track: "backend", Status: "idle"}).Error; err != nil {
+			t.Fatalf("create engine: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runMessageBroadcast(&out, gormDB, "backend", "alice", "normal", "pause refactors"); err != nil {
+		t.Fatalf("runMessageBroadcast: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Sent to 2 engine(s) on track backend", "eng-1 (message", "eng-2 (message", "ry message thread "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	var msgs []models.Message
+	gormDB.Where("to_agent = ?", "eng-1").Find(&msgs)
+	if len(msgs) != 1 || msgs[0].Subject != "guidance" || msgs[0].FromAgent != "alice" {
+		t.Errorf("eng-1 messages = %+v", msgs)
+	}
+
+	if err := runMessageBroadcast(&out, gormDB, "frontend", "alice", "normal", "x"); err == nil {
+		t.Error("expected error for a track with no engines")
+	}
+}
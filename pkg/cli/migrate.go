@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/db"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/models"
@@ -135,7 +134,7 @@ func runMigrate(out io.Writer, configPath string) error {
 
 // checkRunningEngines checks if any engines have a recent heartbeat and warns.
 func checkRunningEngines(out io.Writer, configPath string) error {
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		// No config = no DB = can't check engines. Proceed silently.
 		return nil
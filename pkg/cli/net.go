@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/outbound"
+)
+
+func newNetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "net",
+		Short: "Outbound network diagnostics",
+	}
+	cmd.AddCommand(newNetCheckCmd())
+	return cmd
+}
+
+func newNetCheckCmd() *cobra.Command {
+	var (
+		configPath string
+		urls       []string
+		timeout    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check that Slack/Discord/GitHub are reachable through the configured proxy and CA bundle",
+		Long: "Prints the effective proxy settings from the network block (falling back to HTTP_PROXY/HTTPS_PROXY/NO_PROXY), " +
+			"then connects to each endpoint Railyard uses — the configured telegraph platform's API and websocket gateway, and GitHub — " +
+			"reporting which proxy was used and whether the TLS chain verified. Any HTTP response counts as reachable.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNetCheck(cmd, configPath, urls, timeout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringArrayVar(&urls, "url", nil, "additional URL to check (repeatable)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "per-endpoint timeout")
+	return cmd
+}
+
+func runNetCheck(cmd *cobra.Command, configPath string, urls []string, timeout time.Duration) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	targets := outbound.Targets(cfg)
+	for _, u := range urls {
+		targets = append(targets, outbound.Target{Name: "custom", URL: u})
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return reportNetCheck(ctx, cmd.OutOrStdout(), cfg.Network, targets, timeout)
+}
+
+// reportNetCheck prints the effective network settings and probes targets.
+// Split from runNetCheck so tests can point it at local servers.
+func reportNetCheck(ctx context.Context, out io.Writer, n config.NetworkConfig, targets []outbound.Target, timeout time.Duration) error {
+	fmt.Fprintln(out, "Network")
+	printNetSetting(out, "http_proxy", n.HTTPProxy, "HTTP_PROXY")
+	printNetSetting(out, "https_proxy", n.HTTPSProxy, "HTTPS_PROXY")
+	printNetSetting(out, "no_proxy", n.NoProxy, "NO_PROXY")
+	if n.CABundle != "" {
+		fmt.Fprintf(out, "  %-12s %s\n", "ca_bundle:", n.CABundle)
+	} else {
+		fmt.Fprintf(out, "  %-12s (system roots only)\n", "ca_bundle:")
+	}
+
+	results, err := outbound.Check(ctx, n, targets, timeout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nEndpoints")
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	failed := 0
+	for _, r := range results {
+		via := "direct"
+		if r.Proxy != "" {
+			via = "via " + r.Proxy
+		}
+		if r.OK() {
+			fmt.Fprintf(tw, "  OK\t%s\t%s\t%s\tHTTP %d in %s\n", r.Target.Name, r.Target.URL, via, r.Status, r.Elapsed.Round(time.Millisecond))
+		} else {
+			failed++
+			fmt.Fprintf(tw, "  FAIL\t%s\t%s\t%s\t%v\n", r.Target.Name, r.Target.URL, via, r.Err)
+		}
+	}
+	tw.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("net check: %d of %d endpoints unreachable", failed, len(results))
+	}
+	fmt.Fprintln(out, "\nAll endpoints reachable.")
+	return nil
+}
+
+// printNetSetting prints a proxy setting and where it came from, with any
+// password in the proxy URL masked.
+func printNetSetting(out io.Writer, name, configured, envVar string) {
+	switch {
+	case configured != "":
+		fmt.Fprintf(out, "  %-12s %s (config)\n", name+":", redactProxy(configured))
+	case envValue(envVar) != "":
+		fmt.Fprintf(out, "  %-12s %s (%s)\n", name+":", redactProxy(envValue(envVar)), envVar)
+	default:
+		fmt.Fprintf(out, "  %-12s (none)\n", name+":")
+	}
+}
+
+func redactProxy(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return raw
+}
+
+// envValue reads a proxy variable the way net/http does: upper case first,
+// then lower case.
+func envValue(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return os.Getenv(strings.ToLower(name))
+}
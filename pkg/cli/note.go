@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"gorm.io/gorm"
+)
+
+func newNoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "note",
+		Short: "Track notes shared with every engine on a track",
+		Long: `Track notes are short, reusable facts (e.g. "tests require FOO_ENV=1")
+that engines or operators record for a track. The most recent notes are
+included in the prompt of every engine working on the track, so prune
+notes once they go stale.`,
+	}
+	cmd.AddCommand(newNoteAddCmd())
+	cmd.AddCommand(newNoteListCmd())
+	cmd.AddCommand(newNoteRmCmd())
+	cmd.AddCommand(newNotePruneCmd())
+	return cmd
+}
+
+func newNoteAddCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+		from       string
+		carID      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <text>",
+		Short: "Record a note for a track",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runNoteAdd(cmd.OutOrStdout(), gormDB, cfg, track, from, carID, strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track the note applies to")
+	cmd.Flags().StringVar(&from, "from", "human", "who is recording the note (engine ID)")
+	cmd.Flags().StringVar(&carID, "car", "", "car being worked on when the fact was learned")
+	cmd.MarkFlagRequired("track")
+	return cmd
+}
+
+func runNoteAdd(out io.Writer, gormDB *gorm.DB, cfg *config.Config, track, from, carID, content string) error {
+	if !hasTrack(cfg, track) {
+		return fmt.Errorf("note: track %q not found in config", track)
+	}
+	note, err := car.AddNote(gormDB, track, content, from, carID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added note %d to track %s\n", note.ID, track)
+	return nil
+}
+
+func newNoteListCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a track's notes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runNoteList(cmd.OutOrStdout(), gormDB, track)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "track to list notes for")
+	cmd.MarkFlagRequired("track")
+	return supportsJSON(cmd)
+}
+
+func runNoteList(out io.Writer, gormDB *gorm.DB, track string) error {
+	notes, err := car.TrackNotes(gormDB, track, 0)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		fmt.Fprintf(out, "No notes for track %s.\n", track)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tADDED\tFROM\tCAR\tNOTE")
+	for _, n := range notes {
+		carID := n.CarID
+		if carID == "" {
+			carID = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", n.ID, n.CreatedAt.Local().Format("2006-01-02"), n.Author, carID, n.Content)
+	}
+	w.Flush()
+	if len(notes) > car.MaxPromptNotes {
+		fmt.Fprintf(out, "\nOnly the newest %d notes are included in engine prompts.\n", car.MaxPromptNotes)
+	}
+	return nil
+}
+
+func newNoteRmCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "rm <note-id>...",
+		Short: "Remove notes by ID",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return runNoteRm(cmd.OutOrStdout(), gormDB, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	return cmd
+}
+
+func runNoteRm(out io.Writer, gormDB *gorm.DB, args []string) error {
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 0)
+		if err != nil {
+			return fmt.Errorf("note: invalid note ID %q", arg)
+		}
+		if err := car.RemoveNote(gormDB, uint(id)); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Removed note %d\n", id)
+	}
+	return nil
+}
+
+func newNotePruneCmd() *cobra.Command {
+	var (
+		configPath string
+		track      string
+		olderThan  time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove notes older than a given age",
+		Long:  "Removes notes added more than --older-than ago, on one track or, without --track, on every track.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if olderThan <= 0 {
+				return fmt.Errorf("note prune: --older-than must be positive")
+			}
+			_, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			n, err := car.PruneNotes(gormDB, track, time.Now().Add(-olderThan))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d note(s)\n", n)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&track, "track", "", "only prune this track's notes")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "age past which notes are removed (e.g. 720h)")
+	cmd.MarkFlagRequired("older-than")
+	return cmd
+}
+
+func hasTrack(cfg *config.Config, track string) bool {
+	for _, tc := range cfg.Tracks {
+		if tc.Name == track {
+			return true
+		}
+	}
+	return false
+}
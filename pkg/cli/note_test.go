@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func TestRunNoteAddListRm(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cfg := &config.Config{Tracks: []config.TrackConfig{{Name: "backend"}}}
+
+	var out bytes.Buffer
+	if err := runNoteAdd(&out, gormDB, cfg, "backend", "eng-abc", "car-1", "tests require FOO_ENV=1"); err != nil {
+		t.Fatalf("runNoteAdd: %v", err)
+	}
+	if got := out.String(); got != "Added note 1 to track backend\n" {
+		t.Errorf("add output = %q", got)
+	}
+	if err := runNoteAdd(&out, gormDB, cfg, "frontend", "human", "", "x"); err == nil || !strings.Contains(err.Error(), `track "frontend" not found`) {
+		t.Errorf("unknown track err = %v", err)
+	}
+
+	out.Reset()
+	if err := runNoteList(&out, gormDB, "backend"); err != nil {
+		t.Fatalf("runNoteList: %v", err)
+	}
+	for _, want := range []string{"ID", "eng-abc", "car-1", "tests require FOO_ENV=1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("list output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runNoteRm(&out, gormDB, []string{"1"}); err != nil {
+		t.Fatalf("runNoteRm: %v", err)
+	}
+	if err := runNoteRm(&out, gormDB, []string{"abc"}); err == nil || !strings.Contains(err.Error(), "invalid note ID") {
+		t.Errorf("bad ID err = %v", err)
+	}
+	out.Reset()
+	runNoteList(&out, gormDB, "backend")
+	if got := out.String(); got != "No notes for track backend.\n" {
+		t.Errorf("list after rm = %q", got)
+	}
+}
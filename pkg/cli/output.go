@@ -0,0 +1,410 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/find"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"gorm.io/gorm"
+)
+
+// Values of the global --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// jsonOutputAnnotation marks a command that honors --output json. The root
+// command rejects --output json for any other command, so a script gets an
+// error instead of text it cannot parse.
+const jsonOutputAnnotation = "railyard/output-json"
+
+// outputFormat is the pflag.Value behind --output.
+type outputFormat string
+
+func (f *outputFormat) String() string { return string(*f) }
+func (f *outputFormat) Type() string   { return "format" }
+
+func (f *outputFormat) Set(v string) error {
+	switch v {
+	case outputText, outputJSON:
+		*f = outputFormat(v)
+		return nil
+	}
+	return fmt.Errorf("must be %q or %q", outputText, outputJSON)
+}
+
+// addOutputFlag registers --output on root and the check that the command
+// being run supports the requested format.
+func addOutputFlag(root *cobra.Command) {
+	format := outputFormat(outputText)
+	root.PersistentFlags().VarP(&format, "output", "o", "output format: text or json (supported by status, list, and show commands)")
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if wantJSON(cmd) && cmd.Annotations[jsonOutputAnnotation] == "" {
+			return fmt.Errorf("%s does not support --output json", cmd.CommandPath())
+		}
+		return nil
+	}
+}
+
+// supportsJSON marks cmd as honoring --output json and returns it.
+func supportsJSON(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[jsonOutputAnnotation] = "true"
+	return cmd
+}
+
+// wantJSON reports whether --output json was given.
+func wantJSON(cmd *cobra.Command) bool {
+	f := cmd.Flag("output")
+	return f != nil && f.Value.String() == outputJSON
+}
+
+// writeJSON writes v as indented JSON followed by a newline.
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// The types below are the JSON shapes of command output. They are separate
+// from the models so that column renames in the database do not break
+// scripts, and use snake_case keys throughout.
+
+type jsonCar struct {
+	ID            string     `json:"id"`
+	Title         string     `json:"title"`
+	Status        string     `json:"status"`
+	Type          string     `json:"type"`
+	Track         string     `json:"track"`
+	BaseBranch    string     `json:"base_branch"`
+	Branch        string     `json:"branch,omitempty"`
+	Priority      int        `json:"priority"`
+	Estimate      int        `json:"estimate,omitempty"`
+	Assignee      string     `json:"assignee,omitempty"`
+	Owner         string     `json:"owner,omitempty"`
+	ParentID      string     `json:"parent_id,omitempty"`
+	BlockedReason string     `json:"blocked_reason,omitempty"`
+	Tokens        int64      `json:"tokens"`
+	Cycles        int        `json:"cycles"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	ClaimedAt     *time.Time `json:"claimed_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+func newJSONCar(c models.Car, tokens int64, cycles int) jsonCar {
+	base := c.BaseBranch
+	if base == "" {
+		base = "main"
+	}
+	jc := jsonCar{
+		ID: c.ID, Title: c.Title, Status: c.Status, Type: c.Type, Track: c.Track,
+		BaseBranch: base, Branch: c.Branch, Priority: c.Priority, Estimate: c.Estimate,
+		Assignee: c.Assignee, Owner: c.Owner, BlockedReason: c.BlockedReason,
+		Tokens: tokens, Cycles: cycles,
+		CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt, ClaimedAt: c.ClaimedAt, CompletedAt: c.CompletedAt,
+	}
+	if c.ParentID != nil {
+		jc.ParentID = *c.ParentID
+	}
+	return jc
+}
+
+// writeCarsJSON writes cars as a JSON array with their token and cycle
+// totals.
+func writeCarsJSON(out io.Writer, gormDB *gorm.DB, cars []models.Car) error {
+	ids := make([]string, len(cars))
+	for i, c := range cars {
+		ids[i] = c.ID
+	}
+	tokenMap, err := car.CarTokenMap(gormDB, ids)
+	if err != nil {
+		return err
+	}
+	cycleMap, err := car.CarCycleMap(gormDB, ids)
+	if err != nil {
+		return err
+	}
+	list := make([]jsonCar, 0, len(cars))
+	for _, c := range cars {
+		list = append(list, newJSONCar(c, tokenMap[c.ID].TotalTokens, cycleMap[c.ID].TotalCycles))
+	}
+	return writeJSON(out, list)
+}
+
+type jsonCarDep struct {
+	BlockedBy string `json:"blocked_by"`
+	DepType   string `json:"dep_type"`
+}
+
+type jsonCarProgress struct {
+	Cycle      int       `json:"cycle"`
+	EngineID   string    `json:"engine_id"`
+	Note       string    `json:"note"`
+	CommitHash string    `json:"commit_hash,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type jsonMemory struct {
+	Keyword string `json:"keyword"`
+	Content string `json:"content"`
+}
+
+type jsonCarDetail struct {
+	jsonCar
+	Description  string            `json:"description,omitempty"`
+	Acceptance   string            `json:"acceptance,omitempty"`
+	DesignNotes  string            `json:"design_notes,omitempty"`
+	Deps         []jsonCarDep      `json:"deps"`
+	Progress     []jsonCarProgress `json:"progress"`
+	Memories     []jsonMemory      `json:"memories"`
+	InputTokens  int64             `json:"input_tokens"`
+	OutputTokens int64             `json:"output_tokens"`
+	Model        string            `json:"model,omitempty"`
+}
+
+func writeCarDetailJSON(out io.Writer, gormDB *gorm.DB, c *models.Car) error {
+	tokens, _ := car.GetTokenUsage(gormDB, c.ID)
+	cycles, _, _ := car.GetCycleMetrics(gormDB, c.ID)
+	d := jsonCarDetail{
+		jsonCar:      newJSONCar(*c, tokens.TotalTokens, cycles.TotalCycles),
+		Description:  c.Description,
+		Acceptance:   c.Acceptance,
+		DesignNotes:  c.DesignNotes,
+		Deps:         []jsonCarDep{},
+		Progress:     []jsonCarProgress{},
+		Memories:     []jsonMemory{},
+		InputTokens:  tokens.InputTokens,
+		OutputTokens: tokens.OutputTokens,
+		Model:        tokens.Model,
+	}
+	for _, dep := range c.Deps {
+		d.Deps = append(d.Deps, jsonCarDep{BlockedBy: dep.BlockedBy, DepType: dep.DepType})
+	}
+	for _, p := range c.Progress {
+		d.Progress = append(d.Progress, jsonCarProgress{Cycle: p.Cycle, EngineID: p.EngineID, Note: p.Note, CommitHash: p.CommitHash, CreatedAt: p.CreatedAt})
+	}
+	memories, err := car.Memories(gormDB, c.ID, "")
+	if err != nil {
+		return err
+	}
+	for _, m := range memories {
+		d.Memories = append(d.Memories, jsonMemory{Keyword: m.Keyword, Content: m.Content})
+	}
+	return writeJSON(out, d)
+}
+
+type jsonEngine struct {
+	ID            string    `json:"id"`
+	Track         string    `json:"track"`
+	Status        string    `json:"status"`
+	Provider      string    `json:"provider"`
+	CurrentCar    string    `json:"current_car,omitempty"`
+	LastActivity  time.Time `json:"last_activity"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+func newJSONEngines(engines []orchestration.EngineInfo) []jsonEngine {
+	list := make([]jsonEngine, 0, len(engines))
+	for _, e := range engines {
+		provider := e.Provider
+		if provider == "" {
+			provider = "claude"
+		}
+		list = append(list, jsonEngine{
+			ID: e.ID, Track: e.Track, Status: e.Status, Provider: provider, CurrentCar: e.CurrentCar,
+			LastActivity: e.LastActivity, UptimeSeconds: int64(e.Uptime.Seconds()),
+		})
+	}
+	return list
+}
+
+type jsonEngineFlag struct {
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+	Action string `json:"action"`
+}
+
+type jsonEngineStats struct {
+	EngineID        string           `json:"engine_id"`
+	Track           string           `json:"track"`
+	Provider        string           `json:"provider"`
+	Status          string           `json:"status"`
+	Completed       int              `json:"completed"`
+	GateFailures    int              `json:"gate_failures"`
+	AvgCycleSeconds int64            `json:"avg_cycle_seconds"`
+	Restarts        int              `json:"restarts"`
+	StaleReleases   int              `json:"stale_releases"`
+	Flags           []jsonEngineFlag `json:"flags"`
+}
+
+func newJSONEngineStats(stats []orchestration.EngineStats) []jsonEngineStats {
+	list := make([]jsonEngineStats, 0, len(stats))
+	for _, s := range stats {
+		flags := make([]jsonEngineFlag, 0, len(s.Flags))
+		for _, f := range s.Flags {
+			flags = append(flags, jsonEngineFlag{Kind: f.Kind, Reason: f.Reason, Action: f.Action})
+		}
+		list = append(list, jsonEngineStats{
+			EngineID: s.EngineID, Track: s.Track, Provider: s.Provider, Status: s.Status,
+			Completed: s.Completed, GateFailures: s.GateFailures, AvgCycleSeconds: int64(s.AvgCycle.Seconds()),
+			Restarts: s.Restarts, StaleReleases: s.StaleReleases, Flags: flags,
+		})
+	}
+	return list
+}
+
+type jsonTrackSummary struct {
+	Track         string   `json:"track"`
+	Open          int64    `json:"open"`
+	Ready         int64    `json:"ready"`
+	InProgress    int64    `json:"in_progress"`
+	Done          int64    `json:"done"`
+	Blocked       int64    `json:"blocked"`
+	MergeFailed   int64    `json:"merge_failed"`
+	BaseBranches  []string `json:"base_branches"`
+	ClaimStrategy string   `json:"claim_strategy,omitempty"`
+}
+
+type jsonHealthSignal struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Subject  string `json:"subject"`
+	Message  string `json:"message"`
+	Action   string `json:"action,omitempty"`
+}
+
+type jsonHealth struct {
+	Score   int                `json:"score"`
+	Label   string             `json:"label"`
+	Signals []jsonHealthSignal `json:"signals"`
+}
+
+type jsonPause struct {
+	Paused bool       `json:"paused"`
+	By     string     `json:"by,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+}
+
+type jsonStatus struct {
+	SessionRunning bool               `json:"session_running"`
+	Sessions       []string           `json:"sessions"`
+	Pause          jsonPause          `json:"pause"`
+	Engines        []jsonEngine       `json:"engines"`
+	Tracks         []jsonTrackSummary `json:"tracks"`
+	MessageDepth   int64              `json:"message_depth"`
+	InputTokens    int64              `json:"input_tokens"`
+	OutputTokens   int64              `json:"output_tokens"`
+	TotalTokens    int64              `json:"total_tokens"`
+	Health         *jsonHealth        `json:"health,omitempty"`
+}
+
+func newJSONStatus(info *orchestration.StatusInfo) jsonStatus {
+	st := jsonStatus{
+		SessionRunning: info.SessionRunning,
+		Sessions:       append([]string{}, info.ComponentSessions...),
+		Pause:          jsonPause{Paused: info.Pause.Paused, By: info.Pause.By, Reason: info.Pause.Reason},
+		Engines:        newJSONEngines(info.Engines),
+		Tracks:         make([]jsonTrackSummary, 0, len(info.TrackSummary)),
+		MessageDepth:   info.MessageDepth,
+		InputTokens:    info.TotalInputTokens,
+		OutputTokens:   info.TotalOutputTokens,
+		TotalTokens:    info.TotalTokens,
+	}
+	if !info.Pause.Since.IsZero() {
+		since := info.Pause.Since
+		st.Pause.Since = &since
+	}
+	for _, t := range info.TrackSummary {
+		st.Tracks = append(st.Tracks, jsonTrackSummary{
+			Track: t.Track, Open: t.Open, Ready: t.Ready, InProgress: t.InProgress, Done: t.Done,
+			Blocked: t.Blocked, MergeFailed: t.MergeFailed,
+			BaseBranches: append([]string{}, t.BaseBranches...), ClaimStrategy: t.ClaimStrategy,
+		})
+	}
+	if h := info.Health; h != nil {
+		st.Health = &jsonHealth{Score: h.Score, Label: h.Label(), Signals: []jsonHealthSignal{}}
+		for _, s := range h.Signals {
+			st.Health.Signals = append(st.Health.Signals, jsonHealthSignal{
+				Kind: s.Kind, Severity: s.Severity, Subject: s.Subject, Message: s.Message, Action: s.Action,
+			})
+		}
+	}
+	return st
+}
+
+type jsonMessage struct {
+	ID        uint      `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	CarID     string    `json:"car_id,omitempty"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	Priority  string    `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newJSONMessages(msgs []models.Message) []jsonMessage {
+	list := make([]jsonMessage, 0, len(msgs))
+	for _, m := range msgs {
+		list = append(list, jsonMessage{
+			ID: m.ID, From: m.FromAgent, To: m.ToAgent, CarID: m.CarID, Subject: m.Subject,
+			Body: m.Body, Priority: m.Priority, CreatedAt: m.CreatedAt,
+		})
+	}
+	return list
+}
+
+type jsonTrackNote struct {
+	ID        uint      `json:"id"`
+	Track     string    `json:"track"`
+	Content   string    `json:"content"`
+	Author    string    `json:"author"`
+	CarID     string    `json:"car_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newJSONTrackNotes(notes []models.TrackNote) []jsonTrackNote {
+	list := make([]jsonTrackNote, 0, len(notes))
+	for _, n := range notes {
+		list = append(list, jsonTrackNote{ID: n.ID, Track: n.Track, Content: n.Content, Author: n.Author, CarID: n.CarID, CreatedAt: n.CreatedAt})
+	}
+	return list
+}
+
+type jsonFindGroup struct {
+	Kind string        `json:"kind"`
+	More bool          `json:"more"`
+	Hits []jsonFindHit `json:"hits"`
+}
+
+type jsonFindHit struct {
+	ID      string    `json:"id"`
+	CarID   string    `json:"car_id,omitempty"`
+	Title   string    `json:"title"`
+	Snippet string    `json:"snippet"`
+	At      time.Time `json:"at"`
+	Link    string    `json:"link"`
+}
+
+func toJSONFindGroups(groups []find.Group) []jsonFindGroup {
+	out := make([]jsonFindGroup, 0, len(groups))
+	for _, g := range groups {
+		jg := jsonFindGroup{Kind: string(g.Kind), More: g.More, Hits: make([]jsonFindHit, 0, len(g.Hits))}
+		for _, h := range g.Hits {
+			jg.Hits = append(jg.Hits, jsonFindHit{ID: h.ID, CarID: h.CarID, Title: h.Title, Snippet: h.Snippet, At: h.At, Link: h.Link})
+		}
+		out = append(out, jg)
+	}
+	return out
+}
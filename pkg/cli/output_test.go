@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yard"
+)
+
+func TestOutputJSON_CarList(t *testing.T) {
+	gormDB := mockTestDB(t)
+	defer withMockDB(t, gormDB)()
+
+	gormDB.Create(&models.Car{ID: "car-j1", Title: "First", Status: "open", Type: "task", Track: "backend", Priority: 1})
+	gormDB.Create(&models.Car{ID: "car-j2", Title: "Second", Status: "in_progress", Type: "task", Track: "backend", Assignee: "eng-1", BaseBranch: "release"})
+
+	out, err := execCmd(t, []string{"car", "list", "--output", "json", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("car list: %v\n%s", err, out)
+	}
+	var cars []jsonCar
+	if err := json.Unmarshal([]byte(out), &cars); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	if len(cars) != 2 {
+		t.Fatalf("got %d cars: %+v", len(cars), cars)
+	}
+	byID := map[string]jsonCar{cars[0].ID: cars[0], cars[1].ID: cars[1]}
+	if c := byID["car-j1"]; c.BaseBranch != "main" || c.Priority != 1 || c.Assignee != "" {
+		t.Errorf("car-j1 = %+v", c)
+	}
+	if c := byID["car-j2"]; c.BaseBranch != "release" || c.Assignee != "eng-1" || c.Status != "in_progress" {
+		t.Errorf("car-j2 = %+v", c)
+	}
+
+	out, err = execCmd(t, []string{"car", "list", "-o", "json", "--track", "frontend", "--config", "test.yaml"})
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Errorf("empty list = %q, %v", out, err)
+	}
+}
+
+func TestOutputJSON_CarShow(t *testing.T) {
+	gormDB := mockTestDB(t)
+	defer withMockDB(t, gormDB)()
+	gormDB.Create(&models.Car{ID: "car-s1", Title: "Show me", Status: "open", Type: "task", Track: "backend", Description: "details"})
+
+	out, err := execCmd(t, []string{"car", "show", "car-s1", "-o", "json", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("car show: %v\n%s", err, out)
+	}
+	var c jsonCarDetail
+	if err := json.Unmarshal([]byte(out), &c); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	if c.ID != "car-s1" || c.Description != "details" || c.Deps == nil || c.Progress == nil {
+		t.Errorf("car = %+v", c)
+	}
+}
+
+func TestOutputJSON_EngineListEmpty(t *testing.T) {
+	gormDB := mockTestDB(t)
+	defer withMockDB(t, gormDB)()
+
+	out, err := execCmd(t, []string{"engine", "list", "-o", "json", "--config", "test.yaml"})
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Errorf("engine list = %q, %v", out, err)
+	}
+}
+
+func TestOutputJSON_Rejected(t *testing.T) {
+	if _, err := execCmd(t, []string{"pause", "-o", "json"}); err == nil || !strings.Contains(err.Error(), "ry pause does not support --output json") {
+		t.Errorf("unsupported command err = %v", err)
+	}
+	if _, err := execCmd(t, []string{"version", "--output", "yaml"}); err == nil || !strings.Contains(err.Error(), `must be "text" or "json"`) {
+		t.Errorf("bad format err = %v", err)
+	}
+	if _, err := execCmd(t, []string{"status", "--watch", "-o", "json"}); err == nil || !strings.Contains(err.Error(), "--watch cannot be combined") {
+		t.Errorf("status --watch err = %v", err)
+	}
+}
+
+func TestOutputJSON_Version(t *testing.T) {
+	out, err := execCmd(t, []string{"version", "-o", "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]string
+	if err := json.Unmarshal([]byte(out), &v); err != nil || v["version"] == "" {
+		t.Errorf("version = %q, %v", out, err)
+	}
+}
+
+func TestNewJSONStatus(t *testing.T) {
+	since := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	st := newJSONStatus(&orchestration.StatusInfo{
+		SessionRunning: true,
+		Engines:        []orchestration.EngineInfo{{ID: "eng-1", Track: "backend", Status: "working", Uptime: 90 * time.Second}},
+		TrackSummary:   []orchestration.TrackSummary{{Track: "backend", Open: 2, MergeFailed: 1}},
+		MessageDepth:   3,
+		Health:         &orchestration.YardHealth{Score: 80, Signals: []orchestration.HealthSignal{{Kind: orchestration.HealthSlowCar, Severity: orchestration.SeverityWarn}}},
+		Pause:          yard.PauseState{Paused: true, By: "alice", Reason: "deploy", Since: since},
+	})
+	data, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"session_running":true`,
+		`"sessions":[]`,
+		`"provider":"claude"`,
+		`"uptime_seconds":90`,
+		`"merge_failed":1`,
+		`"base_branches":[]`,
+		`"message_depth":3`,
+		`"score":80`,
+		`"kind":"slow-car"`,
+		`"pause":{"paused":true,"by":"alice","reason":"deploy","since":"2026-05-01T09:00:00Z"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("status JSON missing %s:\n%s", want, data)
+		}
+	}
+}
@@ -41,7 +41,7 @@ func newOverlayBuildCmd() *cobra.Command {
 		Use:   "build",
 		Short: "Build overlay index for an engine's changed files",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(configPath)
+			cfg, err := loadConfig(configPath)
 			if err != nil {
 				return fmt.Errorf("load config: %w", err)
 			}
@@ -87,7 +87,7 @@ func newOverlayStatusCmd() *cobra.Command {
 		Use:   "status",
 		Short: "Show overlay status for an engine or all engines",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(configPath)
+			cfg, err := loadConfig(configPath)
 			if err != nil {
 				return fmt.Errorf("load config: %w", err)
 			}
@@ -223,7 +223,7 @@ func newOverlayCleanupCmd() *cobra.Command {
 		Use:   "cleanup",
 		Short: "Drop overlay table and metadata for an engine",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(configPath)
+			cfg, err := loadConfig(configPath)
 			if err != nil {
 				return fmt.Errorf("load config: %w", err)
 			}
@@ -263,7 +263,7 @@ func newOverlayGCCmd() *cobra.Command {
 		Long: `Cross-references overlay_meta in pgvector with the engines table in the database.
 Any overlay whose engine_id doesn't correspond to an active engine gets cleaned up.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load(configPath)
+			cfg, err := loadConfig(configPath)
 			if err != nil {
 				return fmt.Errorf("load config: %w", err)
 			}
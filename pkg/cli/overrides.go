@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+// configOverrides and verboseConfig hold the root --set and --verbose
+// flags. Commands load their config through loadConfig, which applies them.
+var (
+	configOverrides []string
+	verboseConfig   bool
+)
+
+// configOverrideStderr receives the --verbose echo of the effective values.
+// A var so tests can capture it.
+var configOverrideStderr io.Writer = os.Stderr
+
+// addConfigOverrideFlags adds the persistent --set and --verbose flags.
+func addConfigOverrideFlags(root *cobra.Command) {
+	root.PersistentFlags().StringArrayVar(&configOverrides, "set", nil,
+		"override a railyard.yaml value for this invocation, e.g. tracks.backend.engine_slots=8 (repeatable); daemons it starts inherit it")
+	root.PersistentFlags().BoolVar(&verboseConfig, "verbose", false, "echo the effective values of --set overrides")
+}
+
+// loadConfig loads the config at path with the --set overrides applied.
+// With --verbose it echoes each overridden value as the config holds it
+// after defaults and validation.
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.LoadWithOverrides(path, configOverrides)
+	if err != nil {
+		return nil, err
+	}
+	if verboseConfig {
+		echoOverrides(configOverrideStderr, cfg)
+	}
+	return cfg, nil
+}
+
+// echoOverrides prints the effective value of each override in cfg.
+func echoOverrides(w io.Writer, cfg *config.Config) {
+	for _, o := range cfg.Overrides {
+		path, _, err := config.ParseOverride(o)
+		if err != nil {
+			continue
+		}
+		value, err := config.OverrideValue(cfg, path)
+		if err != nil {
+			value = "(unset)"
+		}
+		fmt.Fprintf(w, "config: %s = %s (--set)\n", path, value)
+	}
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_SetOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "railyard.yaml")
+	if err := os.WriteFile(path, []byte(canaryConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	t.Cleanup(func() {
+		configOverrides, verboseConfig, configOverrideStderr = nil, false, os.Stderr
+	})
+	configOverrideStderr = &stderr
+
+	if _, err := execCmd(t, []string{"version", "--set", "tracks.backend.engine_slots=8", "--set", "tracks.backend.claim_strategy=", "--verbose"}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.Tracks[0].EngineSlots != 8 {
+		t.Errorf("engine_slots = %d, want 8", cfg.Tracks[0].EngineSlots)
+	}
+	for _, want := range []string{
+		"config: tracks.backend.engine_slots = 8 (--set)",
+		"config: tracks.backend.claim_strategy = priority (--set)",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("verbose output %q missing %q", stderr.String(), want)
+		}
+	}
+
+	configOverrides = []string{"tracks.backend.engine_slot=8"}
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), `unknown key "engine_slot"`) {
+		t.Errorf("typo override: err = %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunPauseResume(t *testing.T) {
+	gormDB := mockTestDB(t)
+
+	var out bytes.Buffer
+	if err := runResume(&out, gormDB, "alice"); err != nil {
+		t.Fatalf("runResume: %v", err)
+	}
+	if got := out.String(); got != "Yard is not paused\n" {
+		t.Errorf("resume while running = %q", got)
+	}
+
+	out.Reset()
+	if err := runPause(&out, gormDB, "bad deploy", "alice"); err != nil {
+		t.Fatalf("runPause: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Yard paused by alice since ") || !strings.Contains(out.String(), ": bad deploy\n") {
+		t.Errorf("pause output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runPause(&out, gormDB, "other", "bob"); err != nil {
+		t.Fatalf("second runPause: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Yard already paused by alice") {
+		t.Errorf("second pause output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runResume(&out, gormDB, "bob"); err != nil {
+		t.Fatalf("runResume: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Yard resumed (was paused by alice") {
+		t.Errorf("resume output = %q", out.String())
+	}
+}
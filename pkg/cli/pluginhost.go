@@ -9,12 +9,13 @@ import (
 	"time"
 
 	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/config"
-	"github.com/zulandar/railyard/internal/dashboard"
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
 	"github.com/zulandar/railyard/internal/pluginhost"
+	"github.com/zulandar/railyard/internal/yard"
 	"github.com/zulandar/railyard/internal/yardmaster"
 	"github.com/zulandar/railyard/pkg/plugin"
 	"gorm.io/gorm"
@@ -106,13 +107,13 @@ func parseBuildDate(s string) time.Time {
 	return time.Time{}
 }
 
-// pauseYardAdapter binds the pluginhost "pause_yard" command to the
-// existing dashboard.SetYardPaused write. The bus argument is captured so
+// pauseYardAdapter binds the pluginhost "pause_yard" command to yard.Pause,
+// attributing the pause to "plugin". The bus argument is captured so
 // the dashboard's pause/resume event semantics (currently emitted only by
 // the HTTP handler) can also fire when a plugin pauses the yard.
 func pauseYardAdapter(db *gorm.DB, bus events.Bus) func(ctx context.Context, reason string) error {
 	return func(ctx context.Context, reason string) error {
-		if err := dashboard.SetYardPaused(db, true, reason); err != nil {
+		if _, _, err := yard.Pause(db, reason, "plugin"); err != nil {
 			return err
 		}
 		if bus != nil {
@@ -122,10 +123,10 @@ func pauseYardAdapter(db *gorm.DB, bus events.Bus) func(ctx context.Context, rea
 	}
 }
 
-// resumeYardAdapter binds "resume_yard" to dashboard.SetYardPaused(false).
+// resumeYardAdapter binds "resume_yard" to yard.Resume.
 func resumeYardAdapter(db *gorm.DB, bus events.Bus) func(ctx context.Context, reason string) error {
 	return func(ctx context.Context, reason string) error {
-		if err := dashboard.SetYardPaused(db, false, ""); err != nil {
+		if _, _, err := yard.Resume(db); err != nil {
 			return err
 		}
 		if bus != nil {
@@ -141,7 +142,7 @@ func resumeYardAdapter(db *gorm.DB, bus events.Bus) func(ctx context.Context, re
 // "plugin-dispatched" reason so the progress note is still self-describing.
 func reassignCarAdapter(db *gorm.DB) func(ctx context.Context, carID, fromEngine string) error {
 	return func(ctx context.Context, carID, fromEngine string) error {
-		reassigned, err := yardmaster.ReassignCar(db, carID, fromEngine, "plugin-dispatched")
+		reassigned, err := yardmaster.ReassignCar(db, clock.Real, carID, fromEngine, "plugin-dispatched")
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/progress"
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+// porcelainFlagUsage is the shared help text for --porcelain.
+const porcelainFlagUsage = "emit line-delimited JSON progress events on stdout instead of human output"
+
+// porcelainWriter returns the event writer for op when --porcelain is set,
+// or nil (which discards events) when it is not. In porcelain mode cobra's
+// usage and error text are silenced: the error event already reports the
+// failure, and stdout must stay line-delimited JSON.
+func porcelainWriter(cmd *cobra.Command, op string, on bool) *progress.Writer {
+	if !on {
+		return nil
+	}
+	cmd.Root().SilenceUsage = true
+	cmd.Root().SilenceErrors = true
+	return progress.NewWriter(cmd.OutOrStdout(), op, nil)
+}
+
+// failPorcelain writes the terminal error event when *errp is set. Defer it
+// from a command that may fail before or after its operation starts, so a
+// wrapper always sees a final done or error line.
+func failPorcelain(pw *progress.Writer, errp *error) {
+	if *errp != nil {
+		pw.Fail(*errp)
+	}
+}
+
+type porcelainEngineSession struct {
+	Session string `json:"session"`
+	Track   string `json:"track"`
+}
+
+type porcelainStartResult struct {
+	YardmasterSession string                   `json:"yardmaster_session"`
+	TelegraphSession  string                   `json:"telegraph_session,omitempty"`
+	BullSession       string                   `json:"bull_session,omitempty"`
+	InspectSession    string                   `json:"inspect_session,omitempty"`
+	Engines           []porcelainEngineSession `json:"engines"`
+}
+
+func newPorcelainStartResult(r *orchestration.StartResult) porcelainStartResult {
+	out := porcelainStartResult{
+		YardmasterSession: r.YardmasterSession,
+		TelegraphSession:  r.TelegraphSession,
+		BullSession:       r.BullSession,
+		InspectSession:    r.InspectSession,
+		Engines:           []porcelainEngineSession{},
+	}
+	for _, es := range r.EngineSessions {
+		out.Engines = append(out.Engines, porcelainEngineSession{Session: es.Session, Track: es.Track})
+	}
+	return out
+}
+
+type porcelainScaleResult struct {
+	Track           string   `json:"track"`
+	Previous        int      `json:"previous"`
+	Current         int      `json:"current"`
+	SessionsCreated []string `json:"sessions_created,omitempty"`
+	EnginesDrained  []string `json:"engines_drained,omitempty"`
+}
+
+func newPorcelainScaleResult(r *orchestration.ScaleResult) porcelainScaleResult {
+	return porcelainScaleResult{
+		Track:           r.Track,
+		Previous:        r.Previous,
+		Current:         r.Current,
+		SessionsCreated: r.SessionsCreated,
+		EnginesDrained:  r.EnginesDrained,
+	}
+}
+
+type porcelainSwitchResult struct {
+	CarID           string   `json:"car_id"`
+	Branch          string   `json:"branch"`
+	DryRun          bool     `json:"dry_run,omitempty"`
+	TestsPassed     bool     `json:"tests_passed"`
+	Merged          bool     `json:"merged"`
+	AlreadyMerged   bool     `json:"already_merged,omitempty"`
+	PRURL           string   `json:"pr_url,omitempty"`
+	FailureCategory string   `json:"failure_category,omitempty"`
+	FailedCells     []string `json:"failed_cells,omitempty"`
+	Coverage        *float64 `json:"coverage,omitempty"`
+	Message         string   `json:"message,omitempty"`
+}
+
+func newPorcelainSwitchResult(r *yardmaster.SwitchResult, dryRun bool) porcelainSwitchResult {
+	out := porcelainSwitchResult{
+		CarID:           r.CarID,
+		Branch:          r.Branch,
+		DryRun:          dryRun,
+		TestsPassed:     r.TestsPassed,
+		Merged:          r.Merged,
+		AlreadyMerged:   r.AlreadyMerged,
+		PRURL:           r.PRUrl,
+		FailureCategory: string(r.FailureCategory),
+		FailedCells:     r.FailedCells,
+	}
+	if r.Coverage != nil {
+		out.Coverage = &r.Coverage.Coverage
+	}
+	if r.Error != nil {
+		out.Message = r.Error.Error()
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/progress"
+)
+
+// porcelainLines runs args and decodes stdout as line-delimited JSON events.
+// Stderr is kept separate so cobra's error text cannot break decoding.
+func porcelainLines(t *testing.T, args []string) ([]progress.Line, error) {
+	t.Helper()
+	cmd := newRootCmd()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+
+	var lines []progress.Line
+	for _, raw := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var l progress.Line
+		if jerr := json.Unmarshal([]byte(raw), &l); jerr != nil {
+			t.Fatalf("stdout line is not JSON: %q (%v)", raw, jerr)
+		}
+		lines = append(lines, l)
+	}
+	return lines, err
+}
+
+func TestEngineScale_Porcelain(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+	orig := orchestration.DefaultTmux
+	orchestration.DefaultTmux = &mockTelegraphTmux{sessionExists: true}
+	defer func() { orchestration.DefaultTmux = orig }()
+
+	lines, err := porcelainLines(t, []string{"engine", "scale", "--track", "backend", "--count", "2", "--porcelain", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 2 steps + done: %+v", len(lines), lines)
+	}
+	for i, l := range lines[:2] {
+		if l.Op != "scale" || l.Kind != progress.KindStep || l.Step != "engine" || l.Current != i+1 || l.Total != 2 {
+			t.Errorf("line %d = %+v", i, l)
+		}
+	}
+	done := lines[2]
+	if done.Kind != progress.KindDone {
+		t.Fatalf("last line = %+v, want done", done)
+	}
+	result, _ := done.Result.(map[string]interface{})
+	if result["track"] != "backend" || result["current"] != float64(2) {
+		t.Errorf("done result = %+v", done.Result)
+	}
+}
+
+func TestEngineScale_PorcelainError(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	lines, err := porcelainLines(t, []string{"engine", "scale", "--track", "frontend", "--count", "1", "--porcelain", "--config", "test.yaml"})
+	if err == nil {
+		t.Fatal("expected error for unknown track")
+	}
+	if len(lines) != 1 || lines[0].Kind != progress.KindError || !strings.Contains(lines[0].Error, "not found") {
+		t.Errorf("lines = %+v, want a single error event", lines)
+	}
+}
+
+func TestSwitch_PorcelainLoadError(t *testing.T) {
+	lines, err := porcelainLines(t, []string{"switch", "car-1", "--porcelain", "--config", "/nonexistent/railyard.yaml"})
+	if err == nil {
+		t.Fatal("expected config error")
+	}
+	if len(lines) != 1 || lines[0].Op != "switch" || lines[0].Kind != progress.KindError {
+		t.Errorf("lines = %+v, want a single switch error event", lines)
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+func newRecoverCmd() *cobra.Command {
+	var (
+		configPath string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recover",
+		Short: "Recover the yard after a host crash",
+		Long: `Walks through recovery after the host running Railyard crashed or
+rebooted. Run it from the repository root. In order, it:
+
+  1. verifies the database is reachable and fully migrated
+  2. kills Railyard sessions whose daemon has exited to a shell prompt
+  3. marks engines without a recent heartbeat dead
+  4. repairs git worktree bookkeeping, commits uncommitted work in dead
+     engines' worktrees to their car branch, and removes those worktrees
+  5. requeues cars still claimed by engines that are gone
+
+It ends with the manual follow-ups it could not handle itself. Engines
+that are still heartbeating are left alone, so it is safe to run against
+a yard that is partly up. Use --dry-run to see what it would do.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, gormDB, err := connectFromConfig(configPath)
+			if err != nil {
+				return err
+			}
+			repoDir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("recover: get working directory: %w", err)
+			}
+			if !dryRun {
+				lock, err := orchestration.AcquireLock(cfg.Owner, "recover")
+				if err != nil {
+					return err
+				}
+				defer lock.Release()
+			}
+			res, err := yardmaster.Recover(gormDB, yardmaster.RecoverOpts{
+				Config:  cfg,
+				RepoDir: repoDir,
+				DryRun:  dryRun,
+			})
+			if err != nil {
+				return err
+			}
+			printRecoverResult(cmd.OutOrStdout(), res)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be done without changing anything")
+	return cmd
+}
+
+func printRecoverResult(out io.Writer, res *yardmaster.RecoverResult) {
+	if res.DryRun {
+		fmt.Fprintln(out, "Dry run: nothing below was changed.")
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "Database:  reachable, %d tables\n", res.Tables)
+	fmt.Fprintf(out, "Sessions:  %s\n", recoverLine("killed", res.KilledSessions, "running", res.LiveSessions))
+	fmt.Fprintf(out, "Engines:   %s\n", recoverLine("marked dead", res.DeadEngines, "still heartbeating", res.LiveEngines))
+	saved := make([]string, len(res.SavedWorktrees))
+	for i, s := range res.SavedWorktrees {
+		saved[i] = s.Engine + " -> " + s.Branch
+	}
+	fmt.Fprintf(out, "Worktrees: %s", recoverLine("removed", res.RemovedWorktrees, "uncommitted work committed", saved))
+	if len(res.RepairedWorktrees) > 0 {
+		fmt.Fprintf(out, "; repaired %d", len(res.RepairedWorktrees))
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Cars:      %s\n", recoverLine("requeued", res.RequeuedCars, "", nil))
+
+	fmt.Fprintln(out)
+	if len(res.FollowUps) == 0 {
+		fmt.Fprintln(out, "No manual follow-ups.")
+		return
+	}
+	fmt.Fprintln(out, "Manual follow-ups:")
+	for _, f := range res.FollowUps {
+		fmt.Fprintf(out, "  - %s\n", f)
+	}
+}
+
+// recoverLine renders "killed 2 (a, b); running 1 (c)", skipping the second
+// part when its label is empty.
+func recoverLine(label string, items []string, otherLabel string, other []string) string {
+	part := func(l string, xs []string) string {
+		if len(xs) == 0 {
+			return l + " 0"
+		}
+		return fmt.Sprintf("%s %d (%s)", l, len(xs), strings.Join(xs, ", "))
+	}
+	line := part(label, items)
+	if otherLabel != "" {
+		line += "; " + part(otherLabel, other)
+	}
+	return line
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zulandar/railyard/internal/yardmaster"
+)
+
+func TestPrintRecoverResult(t *testing.T) {
+	var out bytes.Buffer
+	printRecoverResult(&out, &yardmaster.RecoverResult{
+		DryRun:           true,
+		Tables:           23,
+		KilledSessions:   []string{"railyard_alice_eng000"},
+		DeadEngines:      []string{"eng-a", "eng-b"},
+		LiveEngines:      []string{"eng-c"},
+		SavedWorktrees:   []yardmaster.SavedWorktree{{Engine: "eng-a", Branch: "ry/alice/backend/car-1"}},
+		RemovedWorktrees: []string{"eng-a", "eng-b"},
+		RequeuedCars:     []string{"car-1"},
+		FollowUps:        []string{"No Railyard sessions are running: run `ry start` to relaunch the yard"},
+	})
+	for _, want := range []string{
+		"Dry run: nothing below was changed.",
+		"Database:  reachable, 23 tables",
+		"Sessions:  killed 1 (railyard_alice_eng000); running 0",
+		"Engines:   marked dead 2 (eng-a, eng-b); still heartbeating 1 (eng-c)",
+		"Worktrees: removed 2 (eng-a, eng-b); uncommitted work committed 1 (eng-a -> ry/alice/backend/car-1)",
+		"Cars:      requeued 1 (car-1)\n",
+		"Manual follow-ups:\n  - No Railyard sessions are running",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
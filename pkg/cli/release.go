@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/packaging"
+)
+
+func newGenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate build artifacts from the ry command tree",
+	}
+	cmd.AddCommand(newGenDocsCmd())
+	return cmd
+}
+
+func newGenDocsCmd() *cobra.Command {
+	var outDir string
+
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate man pages and shell completions",
+		Long: `Writes a man page for every ry command to <out>/man and bash, zsh, and
+fish completion scripts to <out>/completions. Pages are dated from
+SOURCE_DATE_EPOCH when it is set, so release builds are reproducible.`,
+		Example: `  ry gen docs --out dist
+  man ./dist/man/ry-car-create.1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, err := sourceDate()
+			if err != nil {
+				return err
+			}
+			return runGenDocs(cmd.OutOrStdout(), cmd.Root(), outDir, date)
+		},
+	}
+
+	cmd.Flags().StringVar(&outDir, "out", "dist", "directory to write man/ and completions/ into")
+	return cmd
+}
+
+func runGenDocs(out io.Writer, root *cobra.Command, outDir string, date time.Time) error {
+	info, ok := debug.ReadBuildInfo()
+	version, _, _ := resolveVersion(Version, Commit, Date, info, ok)
+	files, err := packaging.WriteDocs(root, outDir, packaging.ManOptions{Version: version, Date: date})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %d files to %s\n", len(files), outDir)
+	return nil
+}
+
+// sourceDate honours SOURCE_DATE_EPOCH (reproducible-builds.org) and falls
+// back to the current time.
+func sourceDate() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now().UTC(), nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("SOURCE_DATE_EPOCH %q is not a unix timestamp", epoch)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+func newReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Release tooling for building distributable packages",
+	}
+	cmd.AddCommand(newReleasePackageCmd())
+	return cmd
+}
+
+// releasePackageOpts holds the flags of ry release package.
+type releasePackageOpts struct {
+	binary     string
+	version    string
+	arch       string
+	formats    []string
+	outDir     string
+	checksums  string
+	urlBase    string
+	maintainer string
+	date       time.Time
+}
+
+func newReleasePackageCmd() *cobra.Command {
+	var opts releasePackageOpts
+
+	cmd := &cobra.Command{
+		Use:   "package",
+		Short: "Build deb, rpm, and Homebrew packages for a release",
+		Long: `Builds system packages for a released ry binary. The deb and rpm install
+the binary with man pages and bash, zsh, and fish completions generated
+from this ry's command tree, so run the same version you are packaging.
+
+  deb   railyard_<version>_<arch>.deb, written directly
+  rpm   railyard-<version>-1.<arch>.rpm, built with rpmbuild
+  brew  railyard.rb, a Homebrew formula pointing at the release tarballs
+        listed in --checksums (the release's checksums.txt)
+
+The formula needs no --binary or --arch; it covers every macOS and Linux
+tarball the checksums list.`,
+		Example: `  ry release package --binary ry-v1.2.3-linux-amd64 --version v1.2.3 --arch amd64 --format deb,rpm
+  ry release package --version v1.2.3 --format brew --checksums checksums.txt`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, err := sourceDate()
+			if err != nil {
+				return err
+			}
+			opts.date = date
+			return runReleasePackage(cmd.OutOrStdout(), cmd.Root(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.binary, "binary", "", "ry binary to package (deb and rpm)")
+	cmd.Flags().StringVar(&opts.version, "version", "", "release tag, e.g. v1.2.3 (required)")
+	cmd.Flags().StringVar(&opts.arch, "arch", "amd64", "binary architecture: amd64 or arm64 (deb and rpm)")
+	cmd.Flags().StringSliceVar(&opts.formats, "format", []string{"deb", "rpm"}, "formats to build: deb, rpm, brew")
+	cmd.Flags().StringVar(&opts.outDir, "out", "dist", "directory to write packages into")
+	cmd.Flags().StringVar(&opts.checksums, "checksums", "", "release checksums.txt (brew)")
+	cmd.Flags().StringVar(&opts.urlBase, "url-base", "", "URL the release tarballs are published under (brew; default: the GitHub release)")
+	cmd.Flags().StringVar(&opts.maintainer, "maintainer", packaging.DefaultMaintainer, "package maintainer, \"Name <email>\"")
+	cmd.MarkFlagRequired("version")
+	return cmd
+}
+
+func runReleasePackage(out io.Writer, root *cobra.Command, opts releasePackageOpts) error {
+	var deb, rpm, brew bool
+	for _, f := range opts.formats {
+		switch strings.TrimSpace(f) {
+		case "deb":
+			deb = true
+		case "rpm":
+			rpm = true
+		case "brew":
+			brew = true
+		default:
+			return fmt.Errorf("release package: unknown format %q (want deb, rpm, or brew)", f)
+		}
+	}
+	if _, err := packaging.PackageVersion(opts.version); err != nil {
+		return err
+	}
+	if (deb || rpm) && opts.binary == "" {
+		return fmt.Errorf("release package: --binary is required for deb and rpm")
+	}
+	if brew && opts.checksums == "" {
+		return fmt.Errorf("release package: --checksums is required for brew")
+	}
+	if err := os.MkdirAll(opts.outDir, 0o755); err != nil {
+		return fmt.Errorf("release package: %w", err)
+	}
+	info := packaging.Info{Version: opts.version, Arch: opts.arch, Date: opts.date, Maintainer: opts.maintainer}
+
+	var binary []byte
+	if deb || rpm {
+		var err error
+		if binary, err = os.ReadFile(opts.binary); err != nil {
+			return fmt.Errorf("release package: %w", err)
+		}
+	}
+
+	if deb {
+		files, err := packaging.Stage(root, binary, info, packaging.DebLayout)
+		if err != nil {
+			return err
+		}
+		name, err := packaging.DebFileName(info)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		if err := packaging.WriteDeb(&b, info, files); err != nil {
+			return err
+		}
+		dest := filepath.Join(opts.outDir, name)
+		if err := os.WriteFile(dest, b.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("release package: %w", err)
+		}
+		fmt.Fprintf(out, "Wrote %s\n", dest)
+	}
+
+	if rpm {
+		files, err := packaging.Stage(root, binary, info, packaging.RPMLayout)
+		if err != nil {
+			return err
+		}
+		dest, err := packaging.BuildRPM(info, files, opts.outDir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Wrote %s\n", dest)
+	}
+
+	if brew {
+		data, err := os.ReadFile(opts.checksums)
+		if err != nil {
+			return fmt.Errorf("release package: %w", err)
+		}
+		sums, err := packaging.ParseChecksums(data)
+		if err != nil {
+			return err
+		}
+		urlBase := opts.urlBase
+		if urlBase == "" {
+			urlBase = packaging.Homepage + "/releases/download/" + opts.version
+		}
+		formula, err := packaging.BrewFormula(opts.version, urlBase, sums)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(opts.outDir, packaging.PackageName+".rb")
+		if err := os.WriteFile(dest, []byte(formula), 0o644); err != nil {
+			return fmt.Errorf("release package: %w", err)
+		}
+		fmt.Fprintf(out, "Wrote %s\n", dest)
+	}
+	return nil
+}
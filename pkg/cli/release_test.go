@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenDocsCmd_WritesManPagesAndCompletions(t *testing.T) {
+	dir := t.TempDir()
+	cmd := newRootCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{"gen", "docs", "--out", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("gen docs: %v\n%s", err, buf.String())
+	}
+	for _, rel := range []string{"man/ry.1", "man/ry-car-create.1", "man/ry-release-package.1", "completions/_ry"} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("missing %s: %v", rel, err)
+		}
+	}
+}
+
+func TestSourceDate(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1772323200")
+	got, err := sourceDate()
+	if err != nil || !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("sourceDate = %v, %v", got, err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := sourceDate(); err == nil {
+		t.Error("sourceDate should reject a non-numeric SOURCE_DATE_EPOCH")
+	}
+}
+
+func TestRunReleasePackage_Deb(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "ry")
+	if err := os.WriteFile(bin, []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out := new(bytes.Buffer)
+	opts := releasePackageOpts{binary: bin, version: "v1.2.3", arch: "amd64", formats: []string{"deb"}, outDir: dir}
+	if err := runReleasePackage(out, newRootCmd(), opts); err != nil {
+		t.Fatalf("runReleasePackage: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "railyard_1.2.3_amd64.deb")); err != nil {
+		t.Errorf("deb not written: %v (output %q)", err, out.String())
+	}
+}
+
+func TestRunReleasePackage_Validation(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		opts releasePackageOpts
+		want string
+	}{
+		"unknown format": {releasePackageOpts{version: "v1.2.3", formats: []string{"snap"}, outDir: dir}, "unknown format"},
+		"bad version":    {releasePackageOpts{version: "dev", formats: []string{"brew"}, outDir: dir}, "must start with a digit"},
+		"no binary":      {releasePackageOpts{version: "v1.2.3", formats: []string{"deb"}, outDir: dir}, "--binary is required"},
+		"no checksums":   {releasePackageOpts{version: "v1.2.3", formats: []string{"brew"}, outDir: dir}, "--checksums is required"},
+	} {
+		err := runReleasePackage(new(bytes.Buffer), newRootCmd(), tc.opts)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/db"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+)
+
+// replayHiddenStatuses are the car statuses ry replay leaves out unless
+// --all-cars is set: finished work says little about a stuck yard.
+var replayHiddenStatuses = models.TerminalStatuses
+
+func newReplayCmd() *cobra.Command {
+	var (
+		configPath string
+		asOf       string
+		allCars    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Show the yard as it was at an earlier time (Dolt databases)",
+		Long: `Renders the status dashboard, engines, and cars as they were at an earlier
+point, for post-mortems such as why the yard deadlocked overnight.
+
+This needs the database to be served by Dolt, which versions every commit;
+plain MySQL keeps no history. --as-of takes a timestamp ("2024-01-01 10:00",
+local time), a duration before now (8h), or a Dolt commit hash, branch, or
+tag. A timestamp resolves to the last Dolt commit at or before it, so the
+replay is as fine-grained as the server's commits: run Dolt with
+@@dolt_transaction_commit=1 to commit every transaction.
+
+The replay reads Dolt's read-only revision database for that commit, so
+nothing is written. Ages such as uptime and last activity are measured from
+now, not from the replayed time.`,
+		Example: `  ry replay --as-of "2024-01-01 10:00"
+  ry replay --as-of 8h --all-cars
+  ry replay --as-of 3v2q9k1mhtbg1p7ccm4gq2l8ufn5ie0d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(cmd, configPath, asOf, allCars)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&asOf, "as-of", "", "timestamp, duration before now, or Dolt revision to replay")
+	cmd.Flags().BoolVar(&allCars, "all-cars", false, "include merged, cancelled, and reverted cars")
+	cmd.MarkFlagRequired("as-of")
+	return cmd
+}
+
+func runReplay(cmd *cobra.Command, configPath, asOf string, allCars bool) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	commit, err := db.ResolveAsOf(gormDB, asOf, time.Now())
+	if errors.Is(err, db.ErrNotDolt) {
+		return fmt.Errorf("%w; ry replay needs the database served by Dolt", err)
+	}
+	if err != nil {
+		return err
+	}
+	pastDB, err := db.ConnectAsOf(cfg.Database, commit.Hash)
+	if err != nil {
+		return withExitCode(ExitInfra, fmt.Errorf("connect as of %s: %w", commit.Hash, err))
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Replaying %s as of commit %s (%s by %s)\n",
+		cfg.Database.Database, commit.Hash, commit.Date.Local().Format("2006-01-02 15:04:05"), commit.Committer)
+	if commit.Message != "" {
+		fmt.Fprintf(out, "  %s\n", commit.Message)
+	}
+	fmt.Fprintln(out)
+
+	info, err := orchestration.Status(orchestration.StatusOpts{DB: pastDB, Config: cfg})
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, orchestration.FormatStatus(info))
+
+	engines, err := orchestration.ListEngines(orchestration.EngineListOpts{DB: pastDB})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "\nEngines:")
+	if len(engines) == 0 {
+		fmt.Fprintln(out, "No engines found.")
+	} else {
+		printEngineList(out, engines)
+	}
+
+	cars, err := car.List(pastDB, car.ListFilters{})
+	if err != nil {
+		return err
+	}
+	if !allCars {
+		cars = slices.DeleteFunc(cars, func(c models.Car) bool {
+			return slices.Contains(replayHiddenStatuses, c.Status)
+		})
+	}
+	fmt.Fprintln(out, "\nCars:")
+	if len(cars) == 0 {
+		fmt.Fprintln(out, "No cars found.")
+		return nil
+	}
+	return printCarList(out, pastDB, cars)
+}
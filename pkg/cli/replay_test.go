@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplayCmd_RequiresAsOf(t *testing.T) {
+	_, err := execCmd(t, []string{"replay", "--config", "test.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "as-of") {
+		t.Errorf("err = %v, want --as-of required", err)
+	}
+}
+
+func TestReplayCmd_NotDolt(t *testing.T) {
+	gormDB := mockTestDB(t)
+	defer withMockDB(t, gormDB)()
+
+	_, err := execCmd(t, []string{"replay", "--as-of", "8h", "--config", "test.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "needs the database served by Dolt") {
+		t.Errorf("err = %v, want a Dolt requirement error", err)
+	}
+}
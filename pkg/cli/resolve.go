@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/models"
+	"golang.org/x/term"
+	"gorm.io/gorm"
+)
+
+// maxIDMatches caps how many candidates an ambiguous argument lists.
+const maxIDMatches = 20
+
+// errIDNotFound is wrapped by resolver errors when nothing matches.
+var errIDNotFound = errors.New("not found")
+
+// likeSafeRe matches arguments that can go into a LIKE pattern unescaped.
+// Every generated car, engine, and session ID fits it.
+var likeSafeRe = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// isInteractive reports whether in is a terminal the user can answer a
+// prompt on. Tests swap it out.
+var isInteractive = func(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// idMatch is one candidate for a short or fuzzy ID argument.
+type idMatch struct {
+	ID    string
+	Label string // shown next to the ID when choosing, e.g. the car title
+}
+
+// resolveCarID maps a car argument to a car ID for commands that change
+// cars: the full ID or a unique prefix of the ID or of its random suffix
+// ("48f" → car-48f2a1). Title words are not matched, so a typo or a stale
+// ID from an agent cannot complete or revert an unrelated car.
+func resolveCarID(cmd *cobra.Command, db *gorm.DB, arg string) (string, error) {
+	return matchCarID(cmd, db, arg, false)
+}
+
+// lookupCarID is resolveCarID for read-only commands (show, list, watch),
+// which also match words of the title ("jwt-middleware" → "Add JWT
+// middleware") when no ID prefix matches.
+func lookupCarID(cmd *cobra.Command, db *gorm.DB, arg string) (string, error) {
+	return matchCarID(cmd, db, arg, true)
+}
+
+// matchCarID resolves arg as resolveCarID does, falling back to title words
+// when titles is set. Exact IDs are returned without further lookups.
+func matchCarID(cmd *cobra.Command, db *gorm.DB, arg string, titles bool) (string, error) {
+	if arg == "" {
+		return "", nil
+	}
+	var exact int64
+	if err := db.Model(&models.Car{}).Where("id = ?", arg).Count(&exact).Error; err != nil {
+		return "", fmt.Errorf("resolve car %q: %w", arg, err)
+	}
+	if exact > 0 {
+		return arg, nil
+	}
+
+	var cars []models.Car
+	if likeSafeRe.MatchString(arg) {
+		prefix := strings.ToLower(arg)
+		var candidates []models.Car
+		if err := db.Select("id, title, status").
+			Where("id LIKE ? OR id LIKE ?", prefix+"%", "%-"+prefix+"%").
+			Order("updated_at DESC").Limit(maxIDMatches * 5).
+			Find(&candidates).Error; err != nil {
+			return "", fmt.Errorf("resolve car %q: %w", arg, err)
+		}
+		for _, c := range candidates {
+			if strings.HasPrefix(c.ID, prefix) || strings.HasPrefix(c.ID[strings.LastIndex(c.ID, "-")+1:], prefix) {
+				cars = append(cars, c)
+			}
+		}
+	}
+
+	if len(cars) == 0 && !titles {
+		return "", fmt.Errorf("car %w: %s (use the car ID or a prefix of it)", errIDNotFound, arg)
+	}
+	if len(cars) == 0 {
+		words := strings.FieldsFunc(strings.ToLower(arg), func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		})
+		if len(words) == 0 {
+			return "", fmt.Errorf("car %w: %s", errIDNotFound, arg)
+		}
+		q := db.Select("id, title, status")
+		for _, w := range words {
+			q = q.Where("LOWER(title) LIKE ?", "%"+w+"%")
+		}
+		if err := q.Order("updated_at DESC").Limit(maxIDMatches).Find(&cars).Error; err != nil {
+			return "", fmt.Errorf("resolve car %q: %w", arg, err)
+		}
+	}
+
+	matches := make([]idMatch, len(cars))
+	for i, c := range cars {
+		matches[i] = idMatch{ID: c.ID, Label: fmt.Sprintf("%s (%s)", c.Title, c.Status)}
+	}
+	return pickMatch(cmd, "car", arg, matches)
+}
+
+// resolveEngineID maps an engine argument to an engine ID: the full ID or a
+// unique prefix of the ID or of its random suffix ("a1b" → eng-a1b2c3d4).
+func resolveEngineID(cmd *cobra.Command, db *gorm.DB, arg string) (string, error) {
+	if arg == "" {
+		return "", nil
+	}
+	var engines []models.Engine
+	if err := db.Select("id, track, status").Where("id = ?", arg).Find(&engines).Error; err != nil {
+		return "", fmt.Errorf("resolve engine %q: %w", arg, err)
+	}
+	if len(engines) > 0 {
+		return arg, nil
+	}
+	if !likeSafeRe.MatchString(arg) {
+		return "", fmt.Errorf("engine %w: %s", errIDNotFound, arg)
+	}
+	prefix := strings.ToLower(arg)
+	if err := db.Select("id, track, status").
+		Where("id LIKE ? OR id LIKE ?", prefix+"%", "eng-"+prefix+"%").
+		Order("started_at DESC").Limit(maxIDMatches).
+		Find(&engines).Error; err != nil {
+		return "", fmt.Errorf("resolve engine %q: %w", arg, err)
+	}
+	matches := make([]idMatch, len(engines))
+	for i, e := range engines {
+		matches[i] = idMatch{ID: e.ID, Label: fmt.Sprintf("%s (%s)", e.Track, e.Status)}
+	}
+	return pickMatch(cmd, "engine", arg, matches)
+}
+
+// resolveSessionID maps a session argument to an agent session ID recorded in
+// the agent logs: the full ID or a unique prefix of it.
+func resolveSessionID(cmd *cobra.Command, db *gorm.DB, arg string) (string, error) {
+	if arg == "" || !likeSafeRe.MatchString(arg) {
+		return arg, nil
+	}
+	var rows []struct {
+		SessionID string
+		EngineID  string
+		CarID     string
+	}
+	if err := db.Model(&models.AgentLog{}).
+		Select("session_id, MAX(engine_id) AS engine_id, MAX(car_id) AS car_id").
+		Where("session_id LIKE ?", arg+"%").
+		Group("session_id").Limit(maxIDMatches).
+		Scan(&rows).Error; err != nil {
+		return "", fmt.Errorf("resolve session %q: %w", arg, err)
+	}
+	matches := make([]idMatch, 0, len(rows))
+	for _, r := range rows {
+		if r.SessionID == arg {
+			return arg, nil
+		}
+		matches = append(matches, idMatch{ID: r.SessionID, Label: fmt.Sprintf("%s on %s", r.EngineID, r.CarID)})
+	}
+	if len(matches) == 0 {
+		// Unknown sessions are a filter with no results, not an error.
+		return arg, nil
+	}
+	return pickMatch(cmd, "session", arg, matches)
+}
+
+// matchSessionPrefix resolves a session argument against the sessions in
+// entries, the way resolveSessionID does against the agent logs.
+func matchSessionPrefix(cmd *cobra.Command, entries []models.JournalEntry, arg string) (string, error) {
+	if arg == "" {
+		return "", nil
+	}
+	seen := make(map[string]bool)
+	var matches []idMatch
+	for _, e := range entries {
+		if e.SessionID == arg {
+			return arg, nil
+		}
+		if strings.HasPrefix(e.SessionID, arg) && !seen[e.SessionID] {
+			seen[e.SessionID] = true
+			matches = append(matches, idMatch{ID: e.SessionID, Label: e.EngineID})
+		}
+	}
+	if len(matches) == 0 {
+		return arg, nil
+	}
+	return pickMatch(cmd, "session", arg, matches)
+}
+
+// filterID returns the resolved ID, or arg unchanged when nothing matched.
+// Filters over history use it: logs outlive the cars and engines they name.
+func filterID(id, arg string, err error) (string, error) {
+	if errors.Is(err, errIDNotFound) {
+		return arg, nil
+	}
+	return id, err
+}
+
+// pickMatch returns the only match. With several, it asks the user to choose
+// when stdin is a terminal and otherwise fails listing the candidates.
+func pickMatch(cmd *cobra.Command, kind, arg string, matches []idMatch) (string, error) {
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s %w: %s", kind, errIDNotFound, arg)
+	case 1:
+		return matches[0].ID, nil
+	}
+
+	var list strings.Builder
+	for i, m := range matches {
+		fmt.Fprintf(&list, "  %d) %s  %s\n", i+1, m.ID, m.Label)
+	}
+	in := cmd.InOrStdin()
+	if !isInteractive(in) {
+		return "", fmt.Errorf("%q matches %d %ss; use a longer ID:\n%s", arg, len(matches), kind, strings.TrimRight(list.String(), "\n"))
+	}
+
+	out := cmd.ErrOrStderr()
+	fmt.Fprintf(out, "%q matches %d %ss:\n%s", arg, len(matches), kind, list.String())
+	fmt.Fprintf(out, "Choose 1-%d: ", len(matches))
+	scanner := bufio.NewScanner(in)
+	if scanner.Scan() {
+		if n, err := strconv.Atoi(strings.TrimSpace(scanner.Text())); err == nil && n >= 1 && n <= len(matches) {
+			return matches[n-1].ID, nil
+		}
+	}
+	return "", fmt.Errorf("no %s selected for %q", kind, arg)
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
+)
+
+func seedResolveCars(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	now := time.Now()
+	for _, c := range []models.Car{
+		{ID: "car-48f2a1", Title: "Add JWT middleware", Status: "open", Track: "backend"},
+		{ID: "car-48e9b0", Title: "Fix login timeout", Status: "open", Track: "backend"},
+		{ID: "be-fix-cache-7c01aa", Title: "Fix cache eviction", Status: "open", Track: "backend"},
+	} {
+		c.CreatedAt, c.UpdatedAt = now, now
+		if err := db.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolveCarID(t *testing.T) {
+	db := mockTestDB(t)
+	seedResolveCars(t, db)
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader(""))
+
+	tests := []struct {
+		arg, want string
+	}{
+		{"car-48f2a1", "car-48f2a1"},
+		{"48f", "car-48f2a1"},
+		{"car-48e", "car-48e9b0"},
+		{"7c0", "be-fix-cache-7c01aa"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := resolveCarID(cmd, db, tt.arg)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveCarID(%q) = %q, %v; want %q", tt.arg, got, err, tt.want)
+		}
+	}
+
+	// Commands that change cars never fall back to the title.
+	for _, arg := range []string{"jwt-middleware", "login timeout"} {
+		if got, err := resolveCarID(cmd, db, arg); err == nil || !strings.Contains(err.Error(), "car not found") {
+			t.Errorf("resolveCarID(%q) = %q, %v; want not found", arg, got, err)
+		}
+	}
+
+	if _, err := resolveCarID(cmd, db, "nothing-like-this"); err == nil || !strings.Contains(err.Error(), "car not found") {
+		t.Errorf("no match: err = %v", err)
+	}
+	_, err := resolveCarID(cmd, db, "48")
+	if err == nil || !strings.Contains(err.Error(), "matches 2 cars") ||
+		!strings.Contains(err.Error(), "car-48f2a1") || !strings.Contains(err.Error(), "car-48e9b0") {
+		t.Errorf("ambiguous: err = %v", err)
+	}
+}
+
+func TestLookupCarID_Titles(t *testing.T) {
+	db := mockTestDB(t)
+	seedResolveCars(t, db)
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader(""))
+
+	for arg, want := range map[string]string{
+		"48f":            "car-48f2a1",
+		"jwt-middleware": "car-48f2a1",
+		"login timeout":  "car-48e9b0",
+	} {
+		if got, err := lookupCarID(cmd, db, arg); err != nil || got != want {
+			t.Errorf("lookupCarID(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+}
+
+func TestResolveCarID_InteractiveChoice(t *testing.T) {
+	db := mockTestDB(t)
+	seedResolveCars(t, db)
+	orig := isInteractive
+	isInteractive = func(io.Reader) bool { return true }
+	defer func() { isInteractive = orig }()
+
+	cmd := &cobra.Command{}
+	var prompt strings.Builder
+	cmd.SetErr(&prompt)
+	cmd.SetIn(strings.NewReader("2\n"))
+	got, err := lookupCarID(cmd, db, "fix")
+	if err != nil {
+		t.Fatalf("lookupCarID: %v", err)
+	}
+	if !strings.Contains(prompt.String(), "Choose 1-2") {
+		t.Errorf("prompt = %q", prompt.String())
+	}
+	if got != "car-48e9b0" && got != "be-fix-cache-7c01aa" {
+		t.Errorf("chose %q", got)
+	}
+
+	cmd.SetIn(strings.NewReader("9\n"))
+	if _, err := lookupCarID(cmd, db, "fix"); err == nil || !strings.Contains(err.Error(), "no car selected") {
+		t.Errorf("out-of-range choice: err = %v", err)
+	}
+}
+
+func TestResolveEngineAndSessionID(t *testing.T) {
+	db := mockTestDB(t)
+	db.Create(&models.Engine{ID: "eng-a1b2c3d4", Track: "backend", Status: "working", StartedAt: time.Now()})
+	db.Create(&models.Engine{ID: "eng-a1ffffff", Track: "frontend", Status: "idle", StartedAt: time.Now()})
+	db.Create(&models.AgentLog{EngineID: "eng-a1b2c3d4", CarID: "car-1", SessionID: "sess-9f8e7d", Direction: "in", Content: "x"})
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader(""))
+
+	if got, err := resolveEngineID(cmd, db, "a1b"); err != nil || got != "eng-a1b2c3d4" {
+		t.Errorf("resolveEngineID(a1b) = %q, %v", got, err)
+	}
+	if _, err := resolveEngineID(cmd, db, "a1"); err == nil || !strings.Contains(err.Error(), "matches 2 engines") {
+		t.Errorf("ambiguous engine: err = %v", err)
+	}
+	if got, err := resolveSessionID(cmd, db, "sess-9f"); err != nil || got != "sess-9f8e7d" {
+		t.Errorf("resolveSessionID(sess-9f) = %q, %v", got, err)
+	}
+	if got, err := resolveSessionID(cmd, db, "sess-unknown"); err != nil || got != "sess-unknown" {
+		t.Errorf("unknown session = %q, %v; want it passed through", got, err)
+	}
+}
+
+func TestRunCarShow_ShortID(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+	seedResolveCars(t, gormDB)
+
+	out, err := execCmd(t, []string{"car", "show", "jwt-middleware", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "car-48f2a1") {
+		t.Errorf("expected car-48f2a1, got:\n%s", out)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/telegraph"
 )
@@ -170,7 +171,7 @@ func TestClaimOrReclaim_NewCar(t *testing.T) {
 		t.Fatalf("fetch engine: %v", err)
 	}
 
-	claimed, err := claimOrReclaim(gormDB, &eng, "backend")
+	claimed, err := claimOrReclaim(gormDB, &eng, "backend", engine.ClaimOpts{})
 	if err != nil {
 		// ClaimCar uses FOR UPDATE SKIP LOCKED which may not be supported in
 		// SQLite. If this fails, that is acceptable — the re-claim tests below
@@ -195,7 +196,7 @@ func TestClaimOrReclaim_ReclaimExisting(t *testing.T) {
 		t.Fatalf("fetch engine: %v", err)
 	}
 
-	claimed, err := claimOrReclaim(gormDB, &eng, "backend")
+	claimed, err := claimOrReclaim(gormDB, &eng, "backend", engine.ClaimOpts{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -223,7 +224,7 @@ func TestClaimOrReclaim_ReclaimAfterFreshClaim(t *testing.T) {
 	}
 
 	// First call: fresh claim via engine.ClaimCar.
-	claimed, err := claimOrReclaim(gormDB, &eng, "backend")
+	claimed, err := claimOrReclaim(gormDB, &eng, "backend", engine.ClaimOpts{})
 	if err != nil {
 		t.Skipf("ClaimCar failed with SQLite (expected): %v", err)
 	}
@@ -241,7 +242,7 @@ func TestClaimOrReclaim_ReclaimAfterFreshClaim(t *testing.T) {
 	claimed.Status = "in_progress" // keep the local copy consistent
 
 	// Second call: should re-claim the same car (clear-cycle path).
-	reclaimed, err := claimOrReclaim(gormDB, &eng, "backend")
+	reclaimed, err := claimOrReclaim(gormDB, &eng, "backend", engine.ClaimOpts{})
 	if err != nil {
 		t.Fatalf("re-claim: unexpected error: %v", err)
 	}
@@ -262,7 +263,7 @@ func TestClaimOrReclaim_SkipDoneCar(t *testing.T) {
 		t.Fatalf("fetch engine: %v", err)
 	}
 
-	_, err := claimOrReclaim(gormDB, &eng, "backend")
+	_, err := claimOrReclaim(gormDB, &eng, "backend", engine.ClaimOpts{})
 	// The done car should be skipped. claimOrReclaim will clear current_car
 	// and then try engine.ClaimCar, which will fail because there are no
 	// ready cars.
@@ -1,11 +1,18 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/models"
+	"gorm.io/gorm"
 )
 
 // ---------------------------------------------------------------------------
@@ -429,3 +436,374 @@ func TestRunCarShow_NoTrackMemories(t *testing.T) {
 		t.Errorf("expected output NOT to contain 'Track Memories:' when no track memories exist, got:\n%s", out)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// runCarJournal
+// ---------------------------------------------------------------------------
+
+func TestRunCarJournal_ShowsEntries(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-jr", Title: "Journal Car", Status: "done", Track: "backend", Priority: 2, CreatedAt: now, UpdatedAt: now})
+	gormDB.Create(&models.JournalEntry{CarID: "car-jr", EngineID: "eng-1", SessionID: "sess-a", Kind: "session", Detail: "started", CreatedAt: now})
+	gormDB.Create(&models.JournalEntry{CarID: "car-jr", EngineID: "eng-1", SessionID: "sess-a", Kind: "test", Detail: "go test ./...", Outcome: "failed", CreatedAt: now.Add(time.Second)})
+	gormDB.Create(&models.JournalEntry{CarID: "car-jr", EngineID: "eng-1", SessionID: "sess-a", Kind: "files", Detail: `["a.go","b.go"]`, CreatedAt: now.Add(2 * time.Second)})
+	gormDB.Create(&models.JournalEntry{CarID: "car-jr", EngineID: "eng-2", SessionID: "sess-b", Kind: "command", Detail: "ls", Outcome: "ok", CreatedAt: now.Add(3 * time.Second)})
+
+	out, err := execCmd(t, []string{"car", "journal", "car-jr", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"SESSION", "OUTCOME", "sess-a", "go test ./...", "failed", "a.go, b.go", "sess-b"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out, err = execCmd(t, []string{"car", "journal", "car-jr", "--session", "sess-b", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out, "sess-a") || !strings.Contains(out, "sess-b") {
+		t.Errorf("--session filter not applied, got:\n%s", out)
+	}
+
+	out, err = execCmd(t, []string{"car", "journal", "car-jr", "--sandbox", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "No sandbox violations for car car-jr") {
+		t.Errorf("--sandbox with no violations, got:\n%s", out)
+	}
+	gormDB.Create(&models.JournalEntry{CarID: "car-jr", EngineID: "eng-2", SessionID: "sess-b", Kind: "sandbox", Detail: "network: egress to evil.example blocked", Outcome: "blocked", CreatedAt: now.Add(4 * time.Second)})
+	out, err = execCmd(t, []string{"car", "journal", "car-jr", "--sandbox", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "egress to evil.example blocked") || strings.Contains(out, "go test") {
+		t.Errorf("--sandbox filter not applied, got:\n%s", out)
+	}
+}
+
+func TestRunCarJournal_Empty(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-jr2", Title: "Quiet Car", Status: "open", Track: "backend", Priority: 2, CreatedAt: now, UpdatedAt: now})
+
+	out, err := execCmd(t, []string{"car", "journal", "car-jr2", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "No journal entries") {
+		t.Errorf("expected empty message, got:\n%s", out)
+	}
+}
+
+func TestStatusCmd_IntervalFlag(t *testing.T) {
+	cmd := newStatusCmd()
+	f := cmd.Flags().Lookup("interval")
+	if f == nil {
+		t.Fatal("expected --interval flag")
+	}
+	if f.DefValue != "5s" {
+		t.Errorf("--interval default = %q, want 5s", f.DefValue)
+	}
+
+	_, err := execCmd(t, []string{"status", "--watch", "--interval", "0s", "--config", "test.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "--interval must be positive") {
+		t.Errorf("err = %v, want --interval must be positive", err)
+	}
+}
+
+func TestRunStatusWatch_StopsOnCancel(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-sw", Title: "Watched", Status: "open", Track: "backend", Priority: 2, CreatedAt: now, UpdatedAt: now})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cmd := newStatusCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	if err := runStatusWatch(ctx, cmd, "test.yaml", time.Second); err != nil {
+		t.Fatalf("runStatusWatch: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "ENGINES") || !strings.Contains(out, "Refreshing every 1s") {
+		t.Errorf("expected one dashboard render, got:\n%s", out)
+	}
+}
+
+func TestRunCarOwn_SetShowAndClear(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-own", Title: "Owned car", Status: "open", Track: "backend", CreatedAt: now, UpdatedAt: now})
+
+	out, err := execCmd(t, []string{"car", "own", "car-own", "@alice", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("own: %v", err)
+	}
+	if !strings.Contains(out, "owned by @alice") {
+		t.Errorf("own output = %q", out)
+	}
+
+	out, err = execCmd(t, []string{"car", "show", "car-own", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if !strings.Contains(out, "Owner:       @alice") {
+		t.Errorf("show output missing owner:\n%s", out)
+	}
+
+	out, err = execCmd(t, []string{"car", "list", "--owner", "alice", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out, "car-own") {
+		t.Errorf("list --owner missing car:\n%s", out)
+	}
+
+	if _, err := execCmd(t, []string{"car", "own", "car-own", "--clear", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("own --clear: %v", err)
+	}
+	var c models.Car
+	gormDB.First(&c, "id = ?", "car-own")
+	if c.Owner != "" {
+		t.Errorf("Owner = %q after --clear, want empty", c.Owner)
+	}
+}
+
+func TestRunCarOwn_NeedsOwnerOrClear(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"car", "own", "car-own", "--config", "test.yaml"},
+		{"car", "own", "car-own", "@alice", "--clear", "--config", "test.yaml"},
+	} {
+		if _, err := execCmd(t, args); err == nil || !strings.Contains(err.Error(), "owner or --clear") {
+			t.Errorf("%v: err = %v, want owner-or-clear error", args, err)
+		}
+	}
+}
+
+func TestRunCarPRPreview_Template(t *testing.T) {
+	gormDB := mockTestDB(t)
+	orig := connectFromConfig
+	defer func() { connectFromConfig = orig }()
+	connectFromConfig = func(string) (*config.Config, *gorm.DB, error) {
+		return &config.Config{
+			Owner: "alice",
+			PRTemplate: config.PRTemplateConfig{
+				Title: "[{{.Car.Track}}] {{.Car.Title}}",
+				Body:  "{{.Car.Description}}\n{{checklist .Acceptance}}",
+			},
+			Tracks: []config.TrackConfig{{Name: "backend", Language: "go"}},
+		}, gormDB, nil
+	}
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-prv", Title: "Add login", Status: "done", Track: "backend",
+		Description: "Login flow.", Acceptance: "- Users log in", CreatedAt: now, UpdatedAt: now})
+
+	out, err := execCmd(t, []string{"car", "pr-preview", "car-prv", "--repo", t.TempDir()})
+	if err != nil {
+		t.Fatalf("pr-preview: %v", err)
+	}
+	if !strings.Contains(out, "Title: [backend] Add login") || !strings.Contains(out, "- [ ] Users log in") {
+		t.Errorf("pr-preview output:\n%s", out)
+	}
+}
+
+func TestRunCarPRPreview_BuiltInWithoutConfig(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-prv2", Title: "Fix bug", Status: "done", Track: "backend", CreatedAt: now, UpdatedAt: now})
+
+	out, err := execCmd(t, []string{"car", "pr-preview", "car-prv2", "--config", "missing.yaml", "--repo", t.TempDir()})
+	if err != nil {
+		t.Fatalf("pr-preview: %v", err)
+	}
+	if !strings.Contains(out, "Title: Fix bug") || !strings.Contains(out, "## Summary") {
+		t.Errorf("pr-preview output:\n%s", out)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// runBisect
+// ---------------------------------------------------------------------------
+
+func TestRunBisect_ReportsCarAndCreatesRevert(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-bad", Title: "Break things", Status: "merged", Track: "backend", Owner: "bob", CreatedAt: now, UpdatedAt: now})
+
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	git("init", "-b", "main")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "test")
+	git("commit", "--allow-empty", "-m", "init")
+	for _, id := range []string{"car-ok", "car-bad"} {
+		branch := "ry/alice/backend/" + id
+		git("checkout", "-b", branch)
+		if id == "car-bad" {
+			if err := os.WriteFile(filepath.Join(repoDir, "broken"), []byte("x"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			git("add", "broken")
+		}
+		git("commit", "--allow-empty", "-m", "work")
+		git("checkout", "main")
+		git("merge", "--no-ff", "-m", "Switch: merge "+branch+" to main\n\nCar-ID: "+id, branch)
+	}
+
+	out, err := execCmd(t, []string{"bisect", "--test", "test ! -f broken", "--repo", repoDir, "--revert", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("bisect: %v\n%s", err, out)
+	}
+	for _, want := range []string{"Searched 2 merges", "Car: car-bad — Break things (track backend)", "Owner: @bob", "Created revert car"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	var revert models.Car
+	if err := gormDB.Where("title = ?", "Revert car-bad: Break things").First(&revert).Error; err != nil {
+		t.Fatalf("revert car not created: %v", err)
+	}
+	if revert.Status != "open" || revert.Type != "bug" || revert.Track != "backend" || revert.Owner != "bob" || revert.Priority != 0 || revert.RevertOf != "car-bad" {
+		t.Errorf("revert car = %+v", revert)
+	}
+}
+
+func TestRunCarRevert_QueuesRevertCar(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-rv", Title: "Risky", Status: "merged", Track: "backend", Owner: "bob", CreatedAt: now, UpdatedAt: now})
+
+	bareDir := t.TempDir()
+	repoDir := t.TempDir()
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	git(bareDir, "init", "--bare", "-b", "main")
+	git(repoDir, "init", "-b", "main")
+	git(repoDir, "config", "user.email", "test@test.com")
+	git(repoDir, "config", "user.name", "test")
+	git(repoDir, "remote", "add", "origin", bareDir)
+	git(repoDir, "commit", "--allow-empty", "-m", "init")
+	branch := "ry/alice/backend/car-rv"
+	git(repoDir, "checkout", "-b", branch)
+	if err := os.WriteFile(filepath.Join(repoDir, "risky"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(repoDir, "add", "risky")
+	git(repoDir, "commit", "-m", "work")
+	git(repoDir, "checkout", "main")
+	git(repoDir, "merge", "--no-ff", "-m", "Switch: merge "+branch+" to main\n\nCar-ID: car-rv", branch)
+	git(repoDir, "push", "origin", "main")
+	t.Chdir(repoDir)
+
+	out, err := execCmd(t, []string{"car", "revert", "car-rv", "--reason", "breaks login", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("car revert: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Created revert car") || !strings.Contains(out, "Queued for the merge gate") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	var revert models.Car
+	if err := gormDB.Where("revert_of = ?", "car-rv").First(&revert).Error; err != nil {
+		t.Fatalf("revert car not created: %v", err)
+	}
+	if revert.Status != "done" || revert.Owner != "bob" || revert.Track != "backend" {
+		t.Errorf("revert car = %+v", revert)
+	}
+}
+
+func TestRunCarAdopt_QueuesBranch(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	bareDir := t.TempDir()
+	repoDir := t.TempDir()
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	git(bareDir, "init", "--bare", "-b", "main")
+	git(repoDir, "init", "-b", "main")
+	git(repoDir, "config", "user.email", "test@test.com")
+	git(repoDir, "config", "user.name", "test")
+	git(repoDir, "remote", "add", "origin", bareDir)
+	git(repoDir, "commit", "--allow-empty", "-m", "init")
+	git(repoDir, "push", "origin", "main")
+	git(repoDir, "checkout", "-b", "fix/login")
+	if err := os.WriteFile(filepath.Join(repoDir, "login.go"), []byte("package x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(repoDir, "add", "login.go")
+	git(repoDir, "commit", "-m", "Fix login timeout")
+	git(repoDir, "checkout", "main")
+	t.Chdir(repoDir)
+
+	if _, err := execCmd(t, []string{"car", "adopt", "fix/login", "--track", "frontend", "--config", "test.yaml"}); err == nil || !strings.Contains(err.Error(), "unknown track") {
+		t.Fatalf("err = %v, want unknown track", err)
+	}
+
+	out, err := execCmd(t, []string{"car", "adopt", "fix/login", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("car adopt: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "from fix/login (1 commits)") || !strings.Contains(out, "backend (inferred") || !strings.Contains(out, "Queued for the merge gate") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	var c models.Car
+	if err := gormDB.Where("branch = ?", "fix/login").First(&c).Error; err != nil {
+		t.Fatalf("car not created: %v", err)
+	}
+	if c.Status != "done" || c.Title != "Fix login timeout" || c.Track != "backend" {
+		t.Errorf("car = %+v", c)
+	}
+}
@@ -17,6 +17,7 @@ func newStartCmd() *cobra.Command {
 		configPath    string
 		engines       int
 		withTelegraph bool
+		porcelain     bool
 	)
 
 	cmd := &cobra.Command{
@@ -24,21 +25,25 @@ func newStartCmd() *cobra.Command {
 		Short: "Start the Railyard orchestration",
 		Long:  "Creates a tmux session with Yardmaster and N engine agents. Use --telegraph to include Telegraph. Start Dispatch separately with 'ry dispatch'.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStart(cmd, configPath, engines, withTelegraph)
+			return runStart(cmd, configPath, engines, withTelegraph, porcelain)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().IntVar(&engines, "engines", 0, "number of engines (default: sum of track engine_slots)")
 	cmd.Flags().BoolVar(&withTelegraph, "telegraph", false, "include Telegraph chat bridge pane")
+	cmd.Flags().BoolVar(&porcelain, "porcelain", false, porcelainFlagUsage)
 	return cmd
 }
 
-func runStart(cmd *cobra.Command, configPath string, engines int, withTelegraph bool) error {
+func runStart(cmd *cobra.Command, configPath string, engines int, withTelegraph, porcelain bool) (err error) {
+	pw := porcelainWriter(cmd, "start", porcelain)
+	defer failPorcelain(pw, &err)
+
 	// Warn if old engines/ layout is present without .railyard/.
 	checkMigrationNeeded(cmd)
 
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
@@ -59,6 +64,12 @@ func runStart(cmd *cobra.Command, configPath string, engines int, withTelegraph
 		"tracks": len(cfg.Tracks),
 	})
 
+	lock, err := orchestration.AcquireLock(cfg.Owner, "start")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Enable telegraph if --telegraph flag set or config has telegraph section.
 	telegraph := withTelegraph || cfg.Telegraph.Platform != ""
 
@@ -68,10 +79,15 @@ func runStart(cmd *cobra.Command, configPath string, engines int, withTelegraph
 		DB:         gormDB,
 		Engines:    engines,
 		Telegraph:  telegraph,
+		Progress:   pw.Func(),
 	})
 	if err != nil {
 		return err
 	}
+	if porcelain {
+		pw.Done(newPorcelainStartResult(result))
+		return nil
+	}
 
 	out := cmd.OutOrStdout()
 	fmt.Fprintf(out, "Railyard started\n")
@@ -89,11 +105,31 @@ func runStart(cmd *cobra.Command, configPath string, engines int, withTelegraph
 	for _, es := range result.EngineSessions {
 		fmt.Fprintf(out, "    %s → %s\n", es.Session, es.Track)
 	}
-	fmt.Fprintf(out, "\nAttach with: tmux attach -t <session-name>\n")
+	fmt.Fprintf(out, "\nAttach with: %s\n", attachCommandLine(cfg))
 	fmt.Fprintf(out, "Start Dispatch separately: ry dispatch --config %s\n", configPath)
 	return nil
 }
 
+// attachCommandLine is what a user types to attach to one of Railyard's
+// sessions under the configured multiplexer.
+func attachCommandLine(cfg *config.Config) string {
+	if cfg.Multiplexer == config.MultiplexerZellij {
+		bin := cfg.Zellij.Binary
+		if bin == "" {
+			bin = "zellij"
+		}
+		return bin + " attach <session-name>"
+	}
+	bin := cfg.Tmux.Binary
+	if bin == "" {
+		bin = "tmux"
+	}
+	if cfg.Tmux.Socket != "" {
+		bin += " -L " + cfg.Tmux.Socket
+	}
+	return bin + " attach -t <session-name>"
+}
+
 // checkMigrationNeeded prints a warning if the repo uses the old engines/ layout
 // without a .railyard/ directory. Does not block startup.
 func checkMigrationNeeded(cmd *cobra.Command) {
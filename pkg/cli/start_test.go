@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/orchestration"
 )
 
 // --- start command tests ---
@@ -316,15 +319,69 @@ func TestEngineRestartCmd_NoArgs(t *testing.T) {
 	}
 }
 
+func TestEngineSuperviseCmd_Flags(t *testing.T) {
+	cmd := newEngineSuperviseCmd()
+	for _, name := range []string{"config", "interval", "stale", "once"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag", name)
+		}
+	}
+}
+
+func TestEngineRolloutCmd_Flags(t *testing.T) {
+	cmd := newEngineRolloutCmd()
+	if len(cmd.Aliases) == 0 || cmd.Aliases[0] != "swap-agent" {
+		t.Errorf("Aliases = %v, want swap-agent", cmd.Aliases)
+	}
+	for _, name := range []string{"config", "track", "agent-binary", "max-failures", "idle-timeout", "start-timeout"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag", name)
+		}
+	}
+}
+
+func TestPrintRolloutResult(t *testing.T) {
+	var buf bytes.Buffer
+	printRolloutResult(&buf, &orchestration.RolloutResult{
+		Track:      "backend",
+		Upgraded:   []orchestration.RolloutStep{{OldEngine: "eng-a", NewEngine: "eng-b"}},
+		Failed:     []orchestration.RolloutStep{{OldEngine: "eng-c", Error: "did not register"}},
+		RolledBack: []orchestration.RolloutStep{{OldEngine: "eng-c", NewEngine: "eng-d"}},
+		Skipped:    []string{"eng-e"},
+	})
+	out := buf.String()
+	for _, want := range []string{"eng-a → eng-b", "eng-c: replacement failed: did not register", "eng-c rolled back → eng-d", "Skipped (still busy or stopped): eng-e", "Track backend: 1 upgraded, 1 failed, 1 skipped"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
 func TestEngineCmd_HasSubcommands(t *testing.T) {
 	cmd := newEngineCmd()
 	subs := make(map[string]bool)
 	for _, c := range cmd.Commands() {
 		subs[c.Name()] = true
 	}
-	for _, expected := range []string{"start", "scale", "list", "restart"} {
+	for _, expected := range []string{"start", "scale", "list", "restart", "supervise", "rollout"} {
 		if !subs[expected] {
 			t.Errorf("expected subcommand %q", expected)
 		}
 	}
 }
+
+func TestAttachCommandLine(t *testing.T) {
+	tests := []struct {
+		cfg  config.Config
+		want string
+	}{
+		{config.Config{}, "tmux attach -t <session-name>"},
+		{config.Config{Tmux: config.TmuxConfig{Binary: "/opt/bin/tmux", Socket: "railyard"}}, "/opt/bin/tmux -L railyard attach -t <session-name>"},
+		{config.Config{Multiplexer: config.MultiplexerZellij}, "zellij attach <session-name>"},
+	}
+	for _, tt := range tests {
+		if got := attachCommandLine(&tt.cfg); got != tt.want {
+			t.Errorf("attachCommandLine(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
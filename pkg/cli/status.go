@@ -1,10 +1,14 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/clock"
 	"github.com/zulandar/railyard/internal/orchestration"
 )
 
@@ -12,46 +16,89 @@ func newStatusCmd() *cobra.Command {
 	var (
 		configPath string
 		watch      bool
+		interval   time.Duration
 	)
 
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show Railyard status dashboard",
-		Long:  "Displays the Railyard status dashboard: engine status, car counts per track, and message queue depth. Use --watch for auto-refresh.",
+		Long:  "Displays the Railyard status dashboard: engine status, car counts per track (READY is the queue engines claim from), message queue depth, and a yard health score with anomaly hints and suggested next actions. Use --watch to refresh in place, highlighting new cars, status flips, and engines appearing or disappearing since the previous refresh.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStatus(cmd, configPath, watch)
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if !watch {
+				return runStatus(cmd, configPath)
+			}
+			if wantJSON(cmd) {
+				return fmt.Errorf("--watch cannot be combined with --output json")
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return runStatusWatch(ctx, cmd, configPath, interval)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
-	cmd.Flags().BoolVar(&watch, "watch", false, "auto-refresh every 5 seconds")
-	return cmd
+	cmd.Flags().BoolVar(&watch, "watch", false, "refresh in place and highlight changes")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "refresh interval for --watch")
+	return supportsJSON(cmd)
 }
 
-func runStatus(cmd *cobra.Command, configPath string, watch bool) error {
+func runStatus(cmd *cobra.Command, configPath string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	info, err := orchestration.Status(orchestration.StatusOpts{DB: gormDB, Config: cfg})
+	if err != nil {
+		return err
+	}
+	if wantJSON(cmd) {
+		return writeJSON(cmd.OutOrStdout(), newJSONStatus(info))
+	}
+	fmt.Fprint(cmd.OutOrStdout(), orchestration.FormatStatus(info))
+	return nil
+}
+
+// runStatusWatch redraws the dashboard every interval until ctx is done,
+// diffing each refresh against the previous one.
+func runStatusWatch(ctx context.Context, cmd *cobra.Command, configPath string, interval time.Duration) error {
 	cfg, gormDB, err := connectFromConfig(configPath)
 	if err != nil {
 		return err
 	}
 
 	out := cmd.OutOrStdout()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
+	var prev *orchestration.StatusSnapshot
 	for {
-		info, err := orchestration.Status(gormDB, nil, cfg)
+		info, err := orchestration.Status(orchestration.StatusOpts{DB: gormDB, Config: cfg})
 		if err != nil {
 			return err
 		}
-
-		if watch {
-			// Clear screen.
-			fmt.Fprint(out, "\033[2J\033[H")
+		snap, err := orchestration.Snapshot(gormDB, clock.Real, info, prev)
+		if err != nil {
+			return err
 		}
 
-		fmt.Fprint(out, orchestration.FormatStatus(info))
+		var since time.Time
+		if prev != nil {
+			since = prev.At
+		}
+		// Cursor home + clear screen, then redraw.
+		fmt.Fprint(out, "\033[H\033[2J")
+		fmt.Fprint(out, orchestration.FormatStatusWithChanges(info, orchestration.DiffStatus(prev, snap), since))
+		fmt.Fprintf(out, "\nRefreshing every %s (Ctrl+C to stop)\n", interval)
+		prev = snap
 
-		if !watch {
+		select {
+		case <-ctx.Done():
 			return nil
+		case <-ticker.C:
 		}
-		time.Sleep(5 * time.Second)
 	}
 }
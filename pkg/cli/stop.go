@@ -35,6 +35,11 @@ func runStop(cmd *cobra.Command, configPath string, timeout time.Duration) error
 	if err != nil {
 		return err
 	}
+	lock, err := orchestration.AcquireLock(cfg.Owner, "stop")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
 
 	if err := orchestration.Stop(orchestration.StopOpts{
 		DB:      gormDB,
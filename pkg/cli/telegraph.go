@@ -7,16 +7,19 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zulandar/railyard/internal/agentbackend"
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/db"
-	"github.com/zulandar/railyard/internal/dispatch"
 	"github.com/zulandar/railyard/internal/engine"
 	"github.com/zulandar/railyard/internal/models"
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/outbound"
 	"github.com/zulandar/railyard/internal/telegraph"
 	discordadapter "github.com/zulandar/railyard/internal/telegraph/discord"
 	slackadapter "github.com/zulandar/railyard/internal/telegraph/slack"
@@ -37,6 +40,7 @@ func newTelegraphCmd() *cobra.Command {
 	cmd.AddCommand(newTelegraphStatusCmd())
 	cmd.AddCommand(newTelegraphStopCmd())
 	cmd.AddCommand(newTelegraphSessionsCmd())
+	cmd.AddCommand(newTelegraphTestCmd())
 	return cmd
 }
 
@@ -97,11 +101,259 @@ func newTelegraphSessionsCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().BoolVar(&clear, "clear", false, "delete all telegraph session history from the database")
+	cmd.AddCommand(newTelegraphSessionsCleanupCmd())
+	cmd.AddCommand(newTelegraphSessionsExportCmd())
+	cmd.AddCommand(newTelegraphSessionsArchiveCmd())
 	return cmd
 }
 
+func newTelegraphSessionsExportCmd() *cobra.Command {
+	var (
+		configPath string
+		format     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export <session-id>",
+		Short: "Print a dispatch session's conversation transcript",
+		Long: `Prints the full conversation of a telegraph dispatch session, including turns
+moved to the history archive (telegraph.conversations.archive).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTelegraphSessionsExport(cmd, configPath, args[0], format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text or jsonl")
+	return cmd
+}
+
+func runTelegraphSessionsExport(cmd *cobra.Command, configPath, id, format string) error {
+	if format != "text" && format != "jsonl" {
+		return fmt.Errorf("unknown format %q (use text or jsonl)", format)
+	}
+	var sessionID uint
+	if _, err := fmt.Sscanf(id, "%d", &sessionID); err != nil {
+		return fmt.Errorf("invalid session id %q", id)
+	}
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	history, err := telegraphHistory(cfg, gormDB)
+	if err != nil {
+		return err
+	}
+	convos, err := history.SessionHistory(cmd.Context(), sessionID)
+	if err != nil {
+		return err
+	}
+	if len(convos) == 0 {
+		return fmt.Errorf("session %d has no conversation history", sessionID)
+	}
+
+	out := cmd.OutOrStdout()
+	if format == "jsonl" {
+		return telegraph.WriteTranscriptJSONL(out, convos)
+	}
+	fmt.Fprint(out, telegraph.FormatTranscript(convos))
+	return nil
+}
+
+func newTelegraphSessionsArchiveCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move old conversation turns to the history archive",
+		Long: `Moves the conversation turns of finished dispatch sessions older than
+telegraph.conversations.archive.keep_days out of the database and into the
+configured archive. Telegraph does this every few hours while running; this
+runs one pass now.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTelegraphSessionsArchive(cmd, configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	return cmd
+}
+
+func runTelegraphSessionsArchive(cmd *cobra.Command, configPath string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Telegraph.Conversations.Archive.KeepDays <= 0 {
+		return fmt.Errorf("history archiving is disabled (set telegraph.conversations.archive.keep_days)")
+	}
+	history, err := telegraphHistory(cfg, gormDB)
+	if err != nil {
+		return err
+	}
+	res, err := history.Archive(cmd.Context())
+	fmt.Fprintf(cmd.OutOrStdout(), "Archived %d turn(s) from %d session(s).\n", res.Turns, res.Sessions)
+	return err
+}
+
+// telegraphHistory builds the conversation history store cfg describes.
+func telegraphHistory(cfg *config.Config, gormDB *gorm.DB) (*telegraph.HistoryStore, error) {
+	archive, err := telegraph.NewHistoryArchive(cfg.Telegraph.Conversations.Archive)
+	if err != nil {
+		return nil, err
+	}
+	return telegraph.NewHistoryStore(telegraph.HistoryStoreOpts{
+		DB:       gormDB,
+		Archive:  archive,
+		KeepDays: cfg.Telegraph.Conversations.Archive.KeepDays,
+	})
+}
+
+func newTelegraphSessionsCleanupCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Kill orphaned dispatch processes and release their locks",
+		Long: `Finds dispatch subprocesses recorded on this host whose session is no longer
+live (released, or heartbeat older than the dispatch lock timeout), kills any
+still running, and expires their dispatch locks. Telegraph does the same for
+every recorded process when it starts, so this is only needed if it is not
+being restarted after a crash.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTelegraphSessionsCleanup(cmd, configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	return cmd
+}
+
+func runTelegraphSessionsCleanup(cmd *cobra.Command, configPath string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(cfg.Telegraph.DispatchLock.HeartbeatTimeoutSec) * time.Second
+	if timeout <= 0 {
+		timeout = telegraph.DefaultHeartbeatTimeout
+	}
+	reaped, err := telegraph.ReapOrphans(gormDB, telegraph.ReapOpts{StaleBefore: time.Now().Add(-timeout)})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(reaped) == 0 {
+		fmt.Fprintf(out, "No orphaned dispatch sessions.\n")
+		return nil
+	}
+	for _, r := range reaped {
+		action := "already exited"
+		if r.Killed {
+			action = "killed"
+		}
+		lock := ""
+		if r.Expired {
+			lock = ", lock released"
+		}
+		fmt.Fprintf(out, "Session %d: pid %d %s%s\n", r.SessionID, r.PID, action, lock)
+	}
+	fmt.Fprintf(out, "Cleaned up %d session(s).\n", len(reaped))
+	return nil
+}
+
+func newTelegraphTestCmd() *cobra.Command {
+	var (
+		configPath string
+		channel    string
+		platform   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Verify the Telegraph chat configuration",
+		Long: `Connects with the configured adapter, posts a test message and thread reply,
+reads the thread back, and checks the bot's permissions (read history, create
+threads, upload files). Prints a capability matrix and exits non-zero when any
+capability is missing, so misconfigured tokens are caught before going live.
+With several platforms configured, each is tested in turn (or only --platform).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTelegraphTest(cmd, configPath, platform, channel)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().StringVar(&channel, "channel", "", "channel ID to test in (default: the platform's configured channel)")
+	cmd.Flags().StringVar(&platform, "platform", "", "test only this platform (default: every configured platform)")
+	return cmd
+}
+
+func runTelegraphTest(cmd *cobra.Command, configPath, platform, channel string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if cfg.Telegraph.Platform == "" {
+		return fmt.Errorf("telegraph: no platform configured in %s (add telegraph.platform)", configPath)
+	}
+	platforms := cfg.Telegraph.EnabledPlatforms()
+	if platform != "" {
+		if !slices.Contains(platforms, platform) {
+			return fmt.Errorf("telegraph: platform %q is not configured (have %s)", platform, strings.Join(platforms, ", "))
+		}
+		platforms = []string{platform}
+	}
+
+	out := cmd.OutOrStdout()
+	var failed []string
+	for _, p := range platforms {
+		ch := channel
+		if ch == "" {
+			ch = cfg.Telegraph.PlatformChannel(p)
+		}
+		adapter, err := createPlatformAdapter(cfg, p)
+		if err != nil {
+			return err
+		}
+		if len(platforms) > 1 {
+			fmt.Fprintf(out, "== %s ==\n", p)
+		}
+		if err := reportTelegraphTest(cmd.Context(), out, adapter, p, ch); err != nil {
+			failed = append(failed, p)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("telegraph: configuration check failed (%s)", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// reportTelegraphTest runs the adapter self-test and prints the capability
+// matrix. Split from runTelegraphTest so tests can inject a mock adapter.
+func reportTelegraphTest(ctx context.Context, out io.Writer, adapter telegraph.Adapter, platform, channel string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	report := telegraph.SelfTest(ctx, telegraph.SelfTestOpts{
+		Adapter:   adapter,
+		Platform:  platform,
+		ChannelID: channel,
+	})
+	telegraph.WriteSelfTestReport(out, report)
+	if !report.OK() {
+		return fmt.Errorf("telegraph: configuration check failed")
+	}
+	fmt.Fprintf(out, "All capabilities OK.\n")
+	return nil
+}
+
 func runTelegraphSessions(cmd *cobra.Command, configPath string, clear bool) error {
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
@@ -114,6 +366,13 @@ func runTelegraphSessions(cmd *cobra.Command, configPath string, clear bool) err
 	out := cmd.OutOrStdout()
 
 	if clear {
+		history, err := telegraphHistory(cfg, gormDB)
+		if err != nil {
+			return err
+		}
+		if _, err := history.PurgeArchived(cmd.Context()); err != nil {
+			return fmt.Errorf("clear archived history: %w", err)
+		}
 		sessions, convos, err := telegraph.ClearSessionHistory(gormDB)
 		if err != nil {
 			return fmt.Errorf("clear sessions: %w", err)
@@ -138,11 +397,15 @@ func runTelegraphSessionsList(gormDB *gorm.DB, out io.Writer) error {
 	}
 
 	fmt.Fprintf(out, "Telegraph Sessions (%d)\n", len(sessions))
-	fmt.Fprintf(out, "%-6s %-12s %-16s %-20s %-20s\n",
-		"ID", "STATUS", "USER", "CHANNEL", "CREATED")
+	fmt.Fprintf(out, "%-6s %-12s %-16s %-20s %-20s %s\n",
+		"ID", "STATUS", "USER", "CHANNEL", "CREATED", "PID")
 	for _, s := range sessions {
-		fmt.Fprintf(out, "%-6d %-12s %-16s %-20s %-20s\n",
-			s.ID, s.Status, s.UserName, s.ChannelID, s.CreatedAt.Format("2006-01-02 15:04:05"))
+		pid := "-"
+		if s.ProcessPID > 0 {
+			pid = fmt.Sprintf("%d@%s", s.ProcessPID, s.ProcessHost)
+		}
+		fmt.Fprintf(out, "%-6d %-12s %-16s %-20s %-20s %s\n",
+			s.ID, s.Status, s.UserName, s.ChannelID, s.CreatedAt.Format("2006-01-02 15:04:05"), pid)
 	}
 	return nil
 }
@@ -153,7 +416,7 @@ var tmuxForTelegraph func() orchestration.Tmux = func() orchestration.Tmux {
 }
 
 func runTelegraphStart(cmd *cobra.Command, configPath string) error {
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
@@ -171,6 +434,9 @@ func runTelegraphStart(cmd *cobra.Command, configPath string) error {
 	if err != nil {
 		return fmt.Errorf("connect to %s: %w", cfg.Database.Database, err)
 	}
+	if err := db.InstrumentQueries(gormDB, cfg.Database.QueryLog); err != nil {
+		log.Printf("query log warning: %v", err)
+	}
 
 	adapter, err := createAdapter(cfg)
 	if err != nil {
@@ -194,41 +460,65 @@ func runTelegraphStart(cmd *cobra.Command, configPath string) error {
 		return fmt.Errorf("telegraph: native loop: %w", err)
 	}
 
-	var spawner telegraph.ProcessSpawner = &telegraph.LazySpawner{
-		RenderPrompt: func() (string, error) {
-			return dispatch.RenderPrompt(cfg)
-		},
-		EnsureWorktree: func() (string, error) {
-			return engine.EnsureDispatchWorktree(repoDir)
-		},
-		SyncWorktree: func(worktreeDir string) error {
-			baseBranch := engine.DetectBaseBranch(repoDir, cfg.DefaultBranch)
-			return engine.SyncWorktreeToBranch(worktreeDir, baseBranch, repoDir)
-		},
-		WriteMCPConfig: func(worktreeDir string) error {
-			return dispatch.WriteDispatchMCPConfig(worktreeDir, cfg)
-		},
-		Model:         cfg.AgentModel,
-		UseNativeLoop: useNativeLoop,
-		Client:        loopClient,
-		// Native loop has no MCP client; give it the codesearch tool directly
-		// (main-index profile: all track main tables). nil when CocoIndex is
-		// unconfigured, so the tool is simply absent.
-		CodeSearch: engine.MainIndexCodeSearchParams(cfg),
-	}
-	if useNativeLoop {
+	// A configured hosted agent API takes precedence over both local paths.
+	var httpAgent *telegraph.HTTPSpawner
+	if agent := cfg.Telegraph.HTTPAgent; agent.Endpoint != "" {
+		httpAgent = &telegraph.HTTPSpawner{Endpoint: agent.Endpoint, Token: agent.Token}
+	}
+
+	var spawner telegraph.ProcessSpawner = newDispatchSpawner(cfg, repoDir, loopClient, useNativeLoop, httpAgent)
+	if httpAgent != nil {
+		fmt.Fprintf(out, "telegraph: dispatch enabled (http agent %s)\n", httpAgent.Endpoint)
+	} else if useNativeLoop {
 		fmt.Fprintf(out, "telegraph: dispatch enabled (native %s loop, model=%s)\n", cfg.AuthMethod, cfg.AgentModel)
 	} else {
 		fmt.Fprintf(out, "telegraph: dispatch enabled (lazy spawner)\n")
 	}
 
+	// `!ry ask` and the conversation summarizer run a read-only agent
+	// against the main checkout. A hosted agent enforces its own tool
+	// policy; it only gets the system prompt.
+	readOnlySpawner := func(systemPrompt string) telegraph.ProcessSpawner {
+		switch {
+		case httpAgent != nil:
+			remote := *httpAgent
+			remote.SystemPrompt = systemPrompt
+			if remote.Model == "" {
+				remote.Model = cfg.AgentModel
+			}
+			return &remote
+		case useNativeLoop:
+			return &telegraph.OpenRouterSpawner{
+				SystemPrompt: systemPrompt,
+				WorkDir:      repoDir,
+				Client:       loopClient,
+				Model:        cfg.AgentModel,
+				CodeSearch:   engine.MainIndexCodeSearchParams(cfg),
+				ReadOnly:     true,
+			}
+		default:
+			return &telegraph.ClaudeSpawner{
+				SystemPrompt: systemPrompt,
+				WorkDir:      repoDir,
+				Model:        cfg.AgentModel,
+				ReadOnly:     true,
+			}
+		}
+	}
+	var summarySpawner telegraph.ProcessSpawner
+	if cfg.Telegraph.Conversations.Summarizer == "agent" {
+		summarySpawner = readOnlySpawner(telegraph.SummarizeSystemPrompt)
+	}
+
 	daemon, err := telegraph.NewDaemon(telegraph.DaemonOpts{
-		DB:      gormDB,
-		Config:  cfg,
-		Adapter: adapter,
-		Spawner: spawner,
-		Redact:  engine.RedactSecrets,
-		Out:     out,
+		DB:             gormDB,
+		Config:         cfg,
+		Adapter:        adapter,
+		Spawner:        spawner,
+		AskSpawner:     readOnlySpawner(telegraph.AskSystemPrompt),
+		SummarySpawner: summarySpawner,
+		Redact:         engine.RedactSecrets,
+		Out:            out,
 	})
 	if err != nil {
 		return err
@@ -237,12 +527,16 @@ func runTelegraphStart(cmd *cobra.Command, configPath string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Handle OS signals for graceful shutdown.
+	// Handle OS signals for graceful shutdown: the first signal drains
+	// in-flight dispatch turns, a second one exits immediately.
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigCh
 		cancel()
+		<-sigCh
+		fmt.Fprintln(out, "Telegraph: second signal, exiting without draining")
+		os.Exit(1)
 	}()
 
 	return daemon.Run(ctx)
@@ -261,26 +555,61 @@ func resolveAllowedChannels(configured []string, defaultChannel string) []string
 	return configured
 }
 
-// createAdapter builds a platform adapter from the config.
+// createAdapter builds the chat adapter from the config: a single platform
+// adapter, or a MultiAdapter over every platform in telegraph.platforms.
 func createAdapter(cfg *config.Config) (telegraph.Adapter, error) {
-	allowed := resolveAllowedChannels(cfg.Telegraph.AllowedChannels, cfg.Telegraph.Channel)
+	platforms := cfg.Telegraph.EnabledPlatforms()
+	if len(platforms) <= 1 {
+		return createPlatformAdapter(cfg, cfg.Telegraph.Platform)
+	}
+	members := make([]telegraph.PlatformAdapter, 0, len(platforms))
+	for _, p := range platforms {
+		adapter, err := createPlatformAdapter(cfg, p)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, telegraph.PlatformAdapter{
+			Platform: p,
+			Channel:  cfg.Telegraph.PlatformChannel(p),
+			Adapter:  adapter,
+		})
+	}
+	return telegraph.NewMultiAdapter(members...)
+}
+
+// createPlatformAdapter builds the adapter for one platform.
+func createPlatformAdapter(cfg *config.Config, platform string) (telegraph.Adapter, error) {
+	channel := cfg.Telegraph.PlatformChannel(platform)
+	allowed := resolveAllowedChannels(cfg.Telegraph.AllowedChannels, channel)
+	httpClient, err := outbound.Client(cfg.Network, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: %w", err)
+	}
+	dialer, err := outbound.Dialer(cfg.Network)
+	if err != nil {
+		return nil, fmt.Errorf("telegraph: %w", err)
+	}
 
-	switch cfg.Telegraph.Platform {
+	switch platform {
 	case "slack":
 		return slackadapter.New(slackadapter.AdapterOpts{
 			AppToken:        cfg.Telegraph.Slack.AppToken,
 			BotToken:        cfg.Telegraph.Slack.BotToken,
-			ChannelID:       cfg.Telegraph.Channel,
+			ChannelID:       channel,
 			AllowedChannels: allowed,
+			HTTPClient:      httpClient,
+			Dialer:          dialer,
 		})
 	case "discord":
 		return discordadapter.New(discordadapter.AdapterOpts{
 			BotToken:        cfg.Telegraph.Discord.BotToken,
-			ChannelID:       cfg.Telegraph.Channel,
+			ChannelID:       channel,
 			AllowedChannels: allowed,
+			HTTPClient:      httpClient,
+			Dialer:          dialer,
 		})
 	default:
-		return nil, fmt.Errorf("telegraph: unsupported platform %q", cfg.Telegraph.Platform)
+		return nil, fmt.Errorf("telegraph: unsupported platform %q", platform)
 	}
 }
 
@@ -3,6 +3,8 @@ package cli
 import (
 	"bytes"
 	"database/sql"
+	"os"
+	"os/exec"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/db"
 	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/telegraph"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -221,5 +224,101 @@ func TestCreateAdapter_DiscordMissingToken(t *testing.T) {
 	}
 }
 
+func TestCreateAdapter_MultiPlatform(t *testing.T) {
+	cfg := &config.Config{
+		Telegraph: config.TelegraphConfig{
+			Platform:  "slack",
+			Platforms: []string{"slack", "discord"},
+			Channel:   "C0123456789",
+			Slack:     config.SlackConfig{AppToken: "xapp-test", BotToken: "xoxb-test"},
+			Discord:   config.DiscordConfig{BotToken: "discord-test", ChannelID: "123456789012345678"},
+		},
+	}
+
+	adapter, err := createAdapter(cfg)
+	if err != nil {
+		t.Fatalf("createAdapter: %v", err)
+	}
+	multi, ok := adapter.(*telegraph.MultiAdapter)
+	if !ok {
+		t.Fatalf("adapter = %T, want *telegraph.MultiAdapter", adapter)
+	}
+	ps := multi.Platforms()
+	if len(ps) != 2 || ps[0].Platform != "slack" || ps[1].Channel != "123456789012345678" {
+		t.Errorf("platforms = %+v", ps)
+	}
+}
+
 // Ensure the sql import is used (referenced by the closed-db test).
 var _ *sql.DB
+
+func TestRunTelegraphSessionsCleanup(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	out, err := execCmd(t, []string{"telegraph", "sessions", "cleanup", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if !strings.Contains(out, "No orphaned dispatch sessions.") {
+		t.Errorf("empty cleanup output = %q", out)
+	}
+
+	// A session left active by a crashed telegraph whose process has exited.
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("run true: %v", err)
+	}
+	host, _ := os.Hostname()
+	started := time.Now().Add(-time.Hour)
+	gormDB.Create(&models.DispatchSession{
+		Source: "telegraph", UserName: "alice", ChannelID: "C01", PlatformThreadID: "T1",
+		Status: "active", CarsCreated: "[]", LastHeartbeat: started,
+		ProcessPID: exited.Process.Pid, ProcessHost: host, ProcessStartedAt: &started,
+	})
+
+	out, err = execCmd(t, []string{"telegraph", "sessions", "cleanup", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if !strings.Contains(out, "already exited, lock released") || !strings.Contains(out, "Cleaned up 1 session(s).") {
+		t.Errorf("cleanup output = %q", out)
+	}
+	var s models.DispatchSession
+	gormDB.First(&s)
+	if s.Status != "expired" || s.ProcessPID != 0 {
+		t.Errorf("session = status %q pid %d, want expired with pid cleared", s.Status, s.ProcessPID)
+	}
+}
+
+func TestRunTelegraphSessionsExport(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	s := models.DispatchSession{Source: "telegraph", UserName: "alice", ChannelID: "C01", Status: "completed", CarsCreated: "[]", LastHeartbeat: time.Now()}
+	gormDB.Create(&s)
+	gormDB.Create(&models.TelegraphConversation{SessionID: s.ID, Sequence: 1, Role: "user", UserName: "alice", Content: "add auth"})
+	gormDB.Create(&models.TelegraphConversation{SessionID: s.ID, Sequence: 2, Role: "assistant", Content: "Created car-001"})
+
+	out, err := execCmd(t, []string{"telegraph", "sessions", "export", "1", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(out, "user alice:\nadd auth") || !strings.Contains(out, "assistant:\nCreated car-001") {
+		t.Errorf("text transcript = %q", out)
+	}
+
+	out, err = execCmd(t, []string{"telegraph", "sessions", "export", "1", "--format", "jsonl", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("export jsonl: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"content":"add auth"`) {
+		t.Errorf("jsonl transcript = %q", out)
+	}
+
+	if _, err := execCmd(t, []string{"telegraph", "sessions", "export", "99", "--config", "test.yaml"}); err == nil {
+		t.Error("export of a session without history: want error")
+	}
+}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/pkg/telegraph/telegraphtest"
 )
 
 // ---------------------------------------------------------------------------
@@ -18,7 +20,7 @@ func TestTelegraphCmd_HasSubcommands(t *testing.T) {
 	for _, c := range cmd.Commands() {
 		subs[c.Name()] = true
 	}
-	for _, expected := range []string{"start", "status", "stop"} {
+	for _, expected := range []string{"start", "status", "stop", "sessions", "test"} {
 		if !subs[expected] {
 			t.Errorf("expected subcommand %q", expected)
 		}
@@ -135,6 +137,49 @@ func TestTelegraphStartCmd_Help(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// telegraph test tests
+// ---------------------------------------------------------------------------
+
+func TestTelegraphTestCmd_Flags(t *testing.T) {
+	cmd := newTelegraphTestCmd()
+	for _, name := range []string{"config", "channel"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag", name)
+		}
+	}
+}
+
+func TestTelegraphTestCmd_MissingConfig(t *testing.T) {
+	cmd := newRootCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{"telegraph", "test", "--config", "/nonexistent/railyard.yaml"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "load config") {
+		t.Fatalf("err = %v, want load config error", err)
+	}
+}
+
+func TestReportTelegraphTest_PrintsMatrixAndFails(t *testing.T) {
+	m := telegraphtest.NewMockAdapter()
+	m.SetBotUserID("BOT1")
+	buf := new(bytes.Buffer)
+
+	err := reportTelegraphTest(context.Background(), buf, m, "mock", "C1")
+	if err == nil {
+		t.Fatal("expected failure: mock adapter cannot report upload permissions")
+	}
+	out := buf.String()
+	for _, want := range []string{"platform=mock", "send_message", "upload_files"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // telegraph status tests
 // ---------------------------------------------------------------------------
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/car"
+	"github.com/zulandar/railyard/internal/config"
+	"github.com/zulandar/railyard/internal/models"
+	"github.com/zulandar/railyard/internal/orchestration"
+	"github.com/zulandar/railyard/internal/undo"
+	"gorm.io/gorm"
+)
+
+func newUndoCmd() *cobra.Command {
+	var (
+		configPath string
+		list       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo your last car update, owner change, or engine scale",
+		Long: `Restores the state before your most recent reversible ry command:
+car update (status, priority, and other fields), car own, and engine scale.
+The last ` + fmt.Sprint(undo.MaxEntries) + ` operations per user are kept. A car that has changed
+again since is left alone. Use --list to see what can be undone.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return runUndoList(cmd, configPath)
+			}
+			return runUndo(cmd, configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().BoolVar(&list, "list", false, "list operations that can be undone, newest first")
+	return cmd
+}
+
+func runUndo(cmd *cobra.Command, configPath string) error {
+	cfg, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	e, err := undo.Last(gormDB, undoActor())
+	if errors.Is(err, undo.ErrEmpty) {
+		fmt.Fprintln(cmd.OutOrStdout(), "Nothing to undo.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch e.Kind {
+	case undo.KindCar:
+		err = undo.RestoreCar(gormDB, e)
+	case undo.KindScale:
+		err = undoScale(cmd, cfg, configPath, gormDB, e)
+	default:
+		err = fmt.Errorf("undo: unknown entry kind %q", e.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	if err := undo.Remove(gormDB, e.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Undid: %s\n", e.Summary)
+	return nil
+}
+
+// undoScale scales the entry's track back to its previous engine count.
+func undoScale(cmd *cobra.Command, cfg *config.Config, configPath string, gormDB *gorm.DB, e *models.UndoEntry) error {
+	var change undo.ScaleChange
+	if err := json.Unmarshal([]byte(e.Data), &change); err != nil {
+		return fmt.Errorf("undo: decode entry %d: %w", e.ID, err)
+	}
+	lock, err := orchestration.AcquireLock(cfg.Owner, "undo")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	result, err := orchestration.Scale(orchestration.ScaleOpts{
+		DB:         gormDB,
+		Config:     cfg,
+		ConfigPath: configPath,
+		Track:      change.Track,
+		Count:      change.Previous,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Track %s: %d → %d engines\n", result.Track, result.Previous, result.Current)
+	return nil
+}
+
+func runUndoList(cmd *cobra.Command, configPath string) error {
+	_, gormDB, err := connectFromConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := undo.List(gormDB, undoActor())
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "Nothing to undo.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tWHEN\tOPERATION")
+	for i, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, e.CreatedAt.Format("2006-01-02 15:04"), e.Summary)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\nry undo reverts #1.")
+	return nil
+}
+
+// undoActor is whose undo stack a command records to and ry undo pops: the
+// user running it, as in the events it records. It is a var so tests can act
+// as another user.
+var undoActor = currentUserName
+
+// recordCarUndo records a car change for ry undo. The change has already
+// been made, so a failure to record it is reported but not returned.
+func recordCarUndo(cmd *cobra.Command, db *gorm.DB, actor, verb string, before *models.Car, fields []string) {
+	after, err := car.Get(db, before.ID)
+	if err == nil {
+		err = undo.RecordCarChange(db, actor, carChangeSummary(verb, before, after, fields), before, after, fields)
+	}
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not record undo: %v\n", err)
+	}
+}
+
+// recordScaleUndo records an engine scale for ry undo.
+func recordScaleUndo(cmd *cobra.Command, db *gorm.DB, actor string, result *orchestration.ScaleResult) {
+	if result.Previous == result.Current {
+		return
+	}
+	summary := fmt.Sprintf("engine scale %s: %d → %d", result.Track, result.Previous, result.Current)
+	change := undo.ScaleChange{Track: result.Track, Previous: result.Previous, Current: result.Current}
+	if err := undo.Record(db, actor, undo.KindScale, result.Track, summary, change); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not record undo: %v\n", err)
+	}
+}
+
+// carChangeSummary describes a car change for ry undo --list, e.g.
+// "car update car-1: status open → cancelled, description".
+func carChangeSummary(verb string, before, after *models.Car, fields []string) string {
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+	var parts []string
+	for _, f := range sorted {
+		b, _ := undo.CarField(before, f)
+		a, _ := undo.CarField(after, f)
+		switch f {
+		case "claimed_at", "completed_at":
+			// Follow from the status change.
+		case "description", "acceptance", "design_notes":
+			if b != a {
+				parts = append(parts, f)
+			}
+		default:
+			if b != a {
+				parts = append(parts, fmt.Sprintf("%s %v → %v", f, displayValue(b), displayValue(a)))
+			}
+		}
+	}
+	return fmt.Sprintf("%s %s: %s", verb, before.ID, strings.Join(parts, ", "))
+}
+
+func displayValue(v interface{}) interface{} {
+	if s, ok := v.(string); ok && s == "" {
+		return `""`
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/models"
+)
+
+func TestUndo_CarUpdateAndOwn(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-un1", Title: "Undo", Status: "open", Priority: 2, Track: "backend", Owner: "alice", CreatedAt: now, UpdatedAt: now})
+
+	if _, err := execCmd(t, []string{"car", "update", "car-un1", "--status", "cancelled", "--priority", "0", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("car update: %v", err)
+	}
+	if _, err := execCmd(t, []string{"car", "own", "car-un1", "@bob", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("car own: %v", err)
+	}
+
+	out, err := execCmd(t, []string{"undo", "--list", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("undo --list: %v", err)
+	}
+	own := strings.Index(out, "car own car-un1: owner alice → bob")
+	update := strings.Index(out, "car update car-un1: priority 2 → 0, status open → cancelled")
+	if own < 0 || update < 0 || own > update {
+		t.Fatalf("undo --list should show the own change above the update, got:\n%s", out)
+	}
+
+	if _, err := execCmd(t, []string{"undo", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("undo own: %v", err)
+	}
+	var c models.Car
+	gormDB.First(&c, "id = ?", "car-un1")
+	if c.Owner != "alice" || c.Status != "cancelled" {
+		t.Fatalf("after first undo: owner %q status %q", c.Owner, c.Status)
+	}
+
+	out, err = execCmd(t, []string{"undo", "--config", "test.yaml"})
+	if err != nil {
+		t.Fatalf("undo update: %v", err)
+	}
+	if !strings.Contains(out, "Undid: car update car-un1") {
+		t.Errorf("output = %q", out)
+	}
+	gormDB.First(&c, "id = ?", "car-un1")
+	if c.Status != "open" || c.Priority != 2 {
+		t.Errorf("after second undo: status %q priority %d", c.Status, c.Priority)
+	}
+
+	out, err = execCmd(t, []string{"undo", "--config", "test.yaml"})
+	if err != nil || !strings.Contains(out, "Nothing to undo") {
+		t.Errorf("empty stack: out %q err %v", out, err)
+	}
+}
+
+func TestUndo_RefusesWhenCarChangedSince(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-un2", Title: "Undo", Status: "open", Priority: 2, Track: "backend", CreatedAt: now, UpdatedAt: now})
+	if _, err := execCmd(t, []string{"car", "update", "car-un2", "--priority", "0", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("car update: %v", err)
+	}
+	gormDB.Model(&models.Car{}).Where("id = ?", "car-un2").Update("priority", 3)
+
+	if _, err := execCmd(t, []string{"undo", "--config", "test.yaml"}); err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Errorf("err = %v, want changed since", err)
+	}
+	var c models.Car
+	gormDB.First(&c, "id = ?", "car-un2")
+	if c.Priority != 3 {
+		t.Errorf("priority = %d, want later edit kept", c.Priority)
+	}
+}
+
+func TestUndo_StackIsPerUser(t *testing.T) {
+	gormDB := mockTestDB(t)
+	cleanup := withMockDB(t, gormDB)
+	defer cleanup()
+	orig := undoActor
+	defer func() { undoActor = orig }()
+
+	now := time.Now()
+	gormDB.Create(&models.Car{ID: "car-un3", Title: "Undo", Status: "open", Priority: 2, Track: "backend", CreatedAt: now, UpdatedAt: now})
+
+	undoActor = func() string { return "alice" }
+	if _, err := execCmd(t, []string{"car", "update", "car-un3", "--priority", "0", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("car update: %v", err)
+	}
+
+	// Same config owner, different user: alice's change is not bob's to undo.
+	undoActor = func() string { return "bob" }
+	out, err := execCmd(t, []string{"undo", "--config", "test.yaml"})
+	if err != nil || !strings.Contains(out, "Nothing to undo") {
+		t.Fatalf("bob's undo: out %q err %v", out, err)
+	}
+	var c models.Car
+	gormDB.First(&c, "id = ?", "car-un3")
+	if c.Priority != 0 {
+		t.Fatalf("priority = %d, want alice's change kept", c.Priority)
+	}
+
+	undoActor = func() string { return "alice" }
+	if _, err := execCmd(t, []string{"undo", "--config", "test.yaml"}); err != nil {
+		t.Fatalf("alice's undo: %v", err)
+	}
+	gormDB.First(&c, "id = ?", "car-un3")
+	if c.Priority != 2 {
+		t.Errorf("priority = %d, want 2 after alice's undo", c.Priority)
+	}
+}
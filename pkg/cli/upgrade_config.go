@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/config"
+)
+
+func newUpgradeConfigCmd() *cobra.Command {
+	var (
+		configPath string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "upgrade-config",
+		Short: "Rewrite an older railyard.yaml to the current config schema",
+		Long: `Rewrites keys that older Railyard releases used (renamed keys, moved
+sections) to the current schema. Comments on moved keys travel with them;
+a config that needs no changes is left untouched.
+
+The original file is copied to <config>.<timestamp>.bak before it is
+rewritten. Use --dry-run to print the upgraded config instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgradeConfig(cmd.OutOrStdout(), configPath, dryRun, time.Now())
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the upgraded config without writing it")
+	return cmd
+}
+
+func runUpgradeConfig(out io.Writer, configPath string, dryRun bool, now time.Time) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return &config.LoadError{Err: fmt.Errorf("config: read %s: %w", configPath, err)}
+	}
+	res, err := config.UpgradeConfig(data)
+	if err != nil {
+		return &config.LoadError{Err: err}
+	}
+	if len(res.Applied) == 0 {
+		fmt.Fprintf(out, "%s already uses the current config schema\n", configPath)
+		return nil
+	}
+	for _, m := range res.Applied {
+		fmt.Fprintf(out, "  %s -> %s\n", m.From, m.To)
+	}
+	if dryRun {
+		fmt.Fprintln(out)
+		out.Write(res.Data)
+		return nil
+	}
+
+	backup, err := replaceConfigFile("upgrade-config", configPath, data, res.Data, now)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Upgraded %s (original saved as %s)\n", configPath, backup)
+
+	// The layout is current now; anything still wrong is for the user to fix.
+	if _, err := config.Parse(res.Data); err != nil {
+		fmt.Fprintf(out, "Warning: the upgraded config does not validate yet:\n%v\n", err)
+	}
+	return nil
+}
+
+// replaceConfigFile copies the original config to <config>.<timestamp>.bak
+// and replaces it with newData, returning the backup's path. op prefixes
+// errors.
+func replaceConfigFile(op, configPath string, data, newData []byte, now time.Time) (string, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	backup := fmt.Sprintf("%s.%s.bak", configPath, now.Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("%s: back up %s: %w", op, configPath, err)
+	}
+	// Write beside the original and rename, so a failed write never leaves
+	// a truncated config behind.
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), filepath.Base(configPath)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(newData); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("%s: write %s: %w", op, tmp.Name(), err)
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("%s: write %s: %w", op, tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), configPath); err != nil {
+		return "", fmt.Errorf("%s: replace %s: %w", op, configPath, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const oldLayoutConfig = `owner: alice
+repo: git@github.com:org/app.git
+# yard database
+dolt:
+  host: 10.0.0.5
+tracks:
+  - name: backend
+    language: go
+`
+
+func TestRunUpgradeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "railyard.yaml")
+	if err := os.WriteFile(path, []byte(oldLayoutConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := runUpgradeConfig(&out, path, false, now); err != nil {
+		t.Fatalf("runUpgradeConfig: %v", err)
+	}
+	if !strings.Contains(out.String(), "dolt -> database") || strings.Contains(out.String(), "Warning") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	backup, err := os.ReadFile(path + ".20260304-050607.bak")
+	if err != nil || string(backup) != oldLayoutConfig {
+		t.Errorf("backup = %q, %v", backup, err)
+	}
+	upgraded, _ := os.ReadFile(path)
+	if !strings.Contains(string(upgraded), "# yard database\ndatabase:\n") {
+		t.Errorf("upgraded config = %s", upgraded)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A current config is left alone and gets no backup.
+	out.Reset()
+	if err := runUpgradeConfig(&out, path, false, now.Add(time.Hour)); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if !strings.Contains(out.String(), "already uses the current config schema") {
+		t.Errorf("output = %q", out.String())
+	}
+	if _, err := os.Stat(path + ".20260304-060607.bak"); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup for a current config: %v", err)
+	}
+}
+
+func TestRunUpgradeConfig_DryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "railyard.yaml")
+	os.WriteFile(path, []byte(oldLayoutConfig), 0o600)
+
+	var out bytes.Buffer
+	if err := runUpgradeConfig(&out, path, true, time.Now()); err != nil {
+		t.Fatalf("runUpgradeConfig: %v", err)
+	}
+	if !strings.Contains(out.String(), "database:\n  host: 10.0.0.5") {
+		t.Errorf("dry run output = %q", out.String())
+	}
+	if data, _ := os.ReadFile(path); string(data) != oldLayoutConfig {
+		t.Error("dry run rewrote the config")
+	}
+	if matches, _ := filepath.Glob(path + ".*.bak"); len(matches) != 0 {
+		t.Errorf("dry run wrote backups %v", matches)
+	}
+}
+
+func TestRunUpgradeConfig_MissingFile(t *testing.T) {
+	err := runUpgradeConfig(&bytes.Buffer{}, filepath.Join(t.TempDir(), "nope.yaml"), false, time.Now())
+	if exitCodeFor(err) != ExitConfig {
+		t.Errorf("exit code = %d, want %d (err %v)", exitCodeFor(err), ExitConfig, err)
+	}
+}
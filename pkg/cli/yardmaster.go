@@ -6,10 +6,12 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zulandar/railyard/internal/config"
 	"github.com/zulandar/railyard/internal/events"
 	"github.com/zulandar/railyard/internal/logutil"
 	"github.com/zulandar/railyard/internal/yardmaster"
@@ -63,6 +65,7 @@ func runYardmaster(cmd *cobra.Command, configPath, logLevel string) error {
 	// binary registers zero plugins, so host.Init / host.Start / host.Stop
 	// are effective no-ops there.
 	bus := events.NewBusWithLogger(logger)
+	defer persistEvents(bus, gormDB, "yardmaster", logger)()
 	host := buildPluginHost(cfg, gormDB, bus)
 	host.Init(ctx)
 	host.Start(ctx)
@@ -101,6 +104,7 @@ func newSwitchCmd() *cobra.Command {
 	var (
 		configPath string
 		dryRun     bool
+		porcelain  bool
 	)
 
 	cmd := &cobra.Command{
@@ -109,20 +113,27 @@ func newSwitchCmd() *cobra.Command {
 		Long:  "Runs the switch flow: fetch branch, run tests, merge to main if tests pass. Use --dry-run to run tests without merging.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runSwitch(cmd, configPath, args[0], dryRun)
+			return runSwitch(cmd, configPath, args[0], dryRun, porcelain)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "railyard.yaml", "path to Railyard config file")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "run tests without merging")
+	cmd.Flags().BoolVar(&porcelain, "porcelain", false, porcelainFlagUsage)
 	return cmd
 }
 
-func runSwitch(cmd *cobra.Command, configPath, carID string, dryRun bool) error {
+func runSwitch(cmd *cobra.Command, configPath, carID string, dryRun, porcelain bool) (err error) {
+	pw := porcelainWriter(cmd, "switch", porcelain)
+	defer failPorcelain(pw, &err)
+
 	cfg, gormDB, err := connectFromConfig(configPath)
 	if err != nil {
 		return err
 	}
+	if carID, err = resolveCarID(cmd, gormDB, carID); err != nil {
+		return err
+	}
 
 	repoDir, err := os.Getwd()
 	if err != nil {
@@ -131,38 +142,83 @@ func runSwitch(cmd *cobra.Command, configPath, carID string, dryRun bool) error
 
 	// Look up the car's track and base branch.
 	var testCommand, preTestCommand, baseBranch string
+	var testMatrix []config.TestMatrixCell
+	var testMatrixParallel bool
+	var testRunner yardmaster.TestRunner
+	var coverage *config.CoverageConfig
+	var diffLimit *config.DiffLimitConfig
 	var car struct {
 		Track      string
 		BaseBranch string
+		Canary     bool
 	}
-	if err := gormDB.Table("cars").Select("track, base_branch").Where("id = ?", carID).Scan(&car).Error; err == nil {
+	if err := gormDB.Table("cars").Select("track, base_branch, canary").Where("id = ?", carID).Scan(&car).Error; err == nil {
 		baseBranch = car.BaseBranch
 		for _, t := range cfg.Tracks {
 			if t.Name == car.Track {
+				if car.Canary {
+					t = t.WithCanary()
+				}
 				preTestCommand = t.PreTestCommand
 				testCommand = t.TestCommand
+				testMatrix = t.TestMatrix
+				testMatrixParallel = t.TestMatrixParallel
+				testRunner = yardmaster.NewTestRunner(t.TestRunner)
+				coverage = t.Coverage
+				diffLimit = t.DiffLimit
 				break
 			}
 		}
 	}
 
 	result, err := yardmaster.Switch(gormDB, carID, yardmaster.SwitchOpts{
-		RepoDir:        repoDir,
-		BaseBranch:     baseBranch,
-		DryRun:         dryRun,
-		PreTestCommand: preTestCommand,
-		TestCommand:    testCommand,
-		ConfigPath:     configPath,
+		RepoDir:            repoDir,
+		BaseBranch:         baseBranch,
+		DryRun:             dryRun,
+		PreTestCommand:     preTestCommand,
+		TestCommand:        testCommand,
+		TestMatrix:         testMatrix,
+		TestMatrixParallel: testMatrixParallel,
+		TestRunner:         testRunner,
+		Coverage:           coverage,
+		DiffLimit:          diffLimit,
+		SwitchTimeoutSec:   cfg.SwitchTimeoutFor(car.Track),
+		Config:             cfg,
+		Progress:           pw.Func(),
 	})
 	if err != nil {
 		return err
 	}
+	if porcelain {
+		pw.Done(newPorcelainSwitchResult(result, dryRun))
+		return nil
+	}
 
 	out := cmd.OutOrStdout()
+	if result.FailureCategory == yardmaster.SwitchFailDiffSize {
+		fmt.Fprintf(out, "Merge deferred: %v\n", result.Error)
+		if !dryRun {
+			fmt.Fprintf(out, "Car %s blocked; dispatch was asked to split it\n", carID)
+		}
+		return nil
+	}
 	if result.TestsPassed {
 		fmt.Fprintf(out, "Tests passed for car %s\n", carID)
 	} else {
 		fmt.Fprintf(out, "Tests failed for car %s:\n%s\n", carID, result.TestOutput)
+		if len(result.FailedCells) > 0 {
+			fmt.Fprintf(out, "Failed matrix cells: %s\n", strings.Join(result.FailedCells, ", "))
+		}
+	}
+	if cov := result.Coverage; cov != nil {
+		if cov.Baseline != nil {
+			fmt.Fprintf(out, "Coverage: %.1f%% (%+.1f vs %.1f%% at last merge)\n", cov.Coverage, cov.Delta, *cov.Baseline)
+		} else {
+			fmt.Fprintf(out, "Coverage: %.1f%%\n", cov.Coverage)
+		}
+	}
+	if result.FailureCategory == yardmaster.SwitchFailCoverage {
+		fmt.Fprintf(out, "Merge blocked: %v\n", result.Error)
 	}
 
 	if result.Merged {
@@ -0,0 +1,104 @@
+package telegraphtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/telegraph"
+)
+
+// SentText returns the text of a sent message including its event titles and
+// bodies, so assertions match content regardless of whether it was sent as
+// plain text or as a formatted event.
+func SentText(msg telegraph.OutboundMessage) string {
+	var b strings.Builder
+	b.WriteString(msg.Text)
+	for _, ev := range msg.Events {
+		b.WriteString("\n")
+		b.WriteString(ev.Title)
+		b.WriteString("\n")
+		b.WriteString(ev.Body)
+		for _, f := range ev.Fields {
+			b.WriteString("\n")
+			b.WriteString(f.Name + ": " + f.Value)
+		}
+	}
+	return b.String()
+}
+
+// AssertSentCount fails the test unless exactly n messages were sent.
+func AssertSentCount(t testing.TB, m *MockAdapter, n int) {
+	t.Helper()
+	if got := m.SentCount(); got != n {
+		t.Errorf("sent %d messages, want %d", got, n)
+	}
+}
+
+// AssertSentContains fails the test unless some sent message contains substr
+// and returns the first match.
+func AssertSentContains(t testing.TB, m *MockAdapter, substr string) telegraph.OutboundMessage {
+	t.Helper()
+	for _, msg := range m.AllSent() {
+		if strings.Contains(SentText(msg), substr) {
+			return msg
+		}
+	}
+	t.Errorf("no sent message contains %q; sent:\n%s", substr, dumpSent(m))
+	return telegraph.OutboundMessage{}
+}
+
+// AssertNotSentContains fails the test if any sent message contains substr.
+func AssertNotSentContains(t testing.TB, m *MockAdapter, substr string) {
+	t.Helper()
+	for _, msg := range m.AllSent() {
+		if strings.Contains(SentText(msg), substr) {
+			t.Errorf("unexpected sent message containing %q: %q", substr, SentText(msg))
+			return
+		}
+	}
+}
+
+// AssertSentInThread fails the test unless some message was sent to threadID.
+func AssertSentInThread(t testing.TB, m *MockAdapter, threadID string) telegraph.OutboundMessage {
+	t.Helper()
+	for _, msg := range m.AllSent() {
+		if msg.ThreadID == threadID {
+			return msg
+		}
+	}
+	t.Errorf("no message sent in thread %q; sent:\n%s", threadID, dumpSent(m))
+	return telegraph.OutboundMessage{}
+}
+
+// WaitForSent polls until at least n messages have been sent or timeout
+// elapses, failing the test on timeout. Use it when the code under test sends
+// from a background goroutine.
+func WaitForSent(t testing.TB, m *MockAdapter, n int, timeout time.Duration) []telegraph.OutboundMessage {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		if m.SentCount() >= n {
+			return m.AllSent()
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s waiting for %d sent messages; got %d:\n%s", timeout, n, m.SentCount(), dumpSent(m))
+			return nil
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func dumpSent(m *MockAdapter) string {
+	var b strings.Builder
+	for i, msg := range m.AllSent() {
+		b.WriteString("  [")
+		b.WriteString(strings.TrimSpace(msg.ChannelID + " " + msg.ThreadID))
+		b.WriteString("] ")
+		b.WriteString(strings.ReplaceAll(SentText(msg), "\n", " | "))
+		if i < m.SentCount()-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
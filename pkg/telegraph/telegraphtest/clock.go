@@ -0,0 +1,18 @@
+package telegraphtest
+
+import (
+	"time"
+
+	"github.com/zulandar/railyard/internal/clock"
+)
+
+// FakeClock is a manually advanced clock.Clock for driving telegraph's
+// time-based logic (watcher polls, pulse digests, session timeouts) in tests.
+// Pass it as the Clock field of telegraph.DaemonOpts, WatcherOpts, or
+// SessionManagerOpts.
+type FakeClock = clock.Fake
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return clock.NewFake(start)
+}
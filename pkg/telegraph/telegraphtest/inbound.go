@@ -0,0 +1,88 @@
+package telegraphtest
+
+import (
+	"time"
+
+	"github.com/zulandar/railyard/internal/telegraph"
+)
+
+// InboundBuilder builds telegraph.InboundMessage values for
+// MockAdapter.SimulateInbound. Start one with Inbound; every setter returns
+// the builder so calls chain.
+type InboundBuilder struct {
+	msg telegraph.InboundMessage
+}
+
+// Inbound starts a builder with test defaults: platform "mock", channel
+// "C_TEST", user "U_TEST"/"tester", and a fixed timestamp so output that
+// embeds it is deterministic.
+func Inbound() *InboundBuilder {
+	return &InboundBuilder{msg: telegraph.InboundMessage{
+		Platform:  "mock",
+		ChannelID: "C_TEST",
+		UserID:    "U_TEST",
+		UserName:  "tester",
+		Timestamp: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}
+}
+
+// Platform sets the platform name.
+func (b *InboundBuilder) Platform(p string) *InboundBuilder {
+	b.msg.Platform = p
+	return b
+}
+
+// InChannel sets the channel ID.
+func (b *InboundBuilder) InChannel(channelID string) *InboundBuilder {
+	b.msg.ChannelID = channelID
+	return b
+}
+
+// InThread marks the message as a reply in threadID.
+func (b *InboundBuilder) InThread(threadID string) *InboundBuilder {
+	b.msg.ThreadID = threadID
+	return b
+}
+
+// WithID sets the platform message ID (needed for thread creation).
+func (b *InboundBuilder) WithID(messageID string) *InboundBuilder {
+	b.msg.MessageID = messageID
+	return b
+}
+
+// From sets the sender's user ID and display name.
+func (b *InboundBuilder) From(userID, userName string) *InboundBuilder {
+	b.msg.UserID = userID
+	b.msg.UserName = userName
+	return b
+}
+
+// Text sets the raw message text.
+func (b *InboundBuilder) Text(text string) *InboundBuilder {
+	b.msg.Text = text
+	return b
+}
+
+// Command sets the text to a "!ry" command, e.g. Command("status").
+func (b *InboundBuilder) Command(cmd string) *InboundBuilder {
+	b.msg.Text = "!ry " + cmd
+	return b
+}
+
+// Mention sets the text to an @mention of botUserID followed by text, in the
+// <@ID> form both Slack and Discord deliver.
+func (b *InboundBuilder) Mention(botUserID, text string) *InboundBuilder {
+	b.msg.Text = "<@" + botUserID + "> " + text
+	return b
+}
+
+// At sets the message timestamp.
+func (b *InboundBuilder) At(ts time.Time) *InboundBuilder {
+	b.msg.Timestamp = ts
+	return b
+}
+
+// Build returns the message.
+func (b *InboundBuilder) Build() telegraph.InboundMessage {
+	return b.msg
+}
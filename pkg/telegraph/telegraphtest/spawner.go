@@ -0,0 +1,156 @@
+package telegraphtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zulandar/railyard/internal/telegraph"
+)
+
+// MockProcess implements telegraph.Process. Tests drive its output with Emit
+// and end it with Exit; everything written via Send is recorded.
+type MockProcess struct {
+	// Prompt is the system prompt the process was spawned with.
+	Prompt string
+
+	mu      sync.Mutex
+	sent    []string
+	recvCh  chan string
+	doneCh  chan struct{}
+	closed  bool
+	recvEnd bool
+	exitErr error
+	stderr  string
+}
+
+// NewMockProcess creates a running MockProcess with a buffered output channel.
+func NewMockProcess(prompt string) *MockProcess {
+	return &MockProcess{
+		Prompt: prompt,
+		recvCh: make(chan string, 100),
+		doneCh: make(chan struct{}),
+	}
+}
+
+// Send records msg. It fails once the process has exited.
+func (p *MockProcess) Send(msg string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return fmt.Errorf("process closed")
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+// Recv returns the output channel fed by Emit.
+func (p *MockProcess) Recv() <-chan string { return p.recvCh }
+
+// Done returns a channel closed by Exit or Close.
+func (p *MockProcess) Done() <-chan struct{} { return p.doneCh }
+
+// ExitErr returns the error passed to Exit.
+func (p *MockProcess) ExitErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exitErr
+}
+
+// Stderr returns the stderr set via SetStderr.
+func (p *MockProcess) Stderr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stderr
+}
+
+// Close marks the process exited without an error.
+func (p *MockProcess) Close() error {
+	p.Exit(nil)
+	return nil
+}
+
+// Emit delivers one output line as if the subprocess had written it.
+// Lines emitted after Exit are dropped.
+func (p *MockProcess) Emit(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.recvEnd {
+		return
+	}
+	p.recvCh <- line
+}
+
+// SetStderr records simulated stderr; call before Exit.
+func (p *MockProcess) SetStderr(s string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stderr = s
+}
+
+// Exit ends the output stream and marks the process exited with err,
+// mimicking a real subprocess finishing. Safe to call more than once; only
+// the first call has an effect.
+func (p *MockProcess) Exit(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.recvEnd {
+		p.recvEnd = true
+		close(p.recvCh)
+	}
+	if !p.closed {
+		p.closed = true
+		p.exitErr = err
+		close(p.doneCh)
+	}
+}
+
+// Sent returns a copy of every message written via Send.
+func (p *MockProcess) Sent() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]string, len(p.sent))
+	copy(out, p.sent)
+	return out
+}
+
+// MockSpawner implements telegraph.ProcessSpawner, handing out MockProcesses.
+// The zero value is ready to use.
+type MockSpawner struct {
+	// Err, if non-nil, is returned from every Spawn call.
+	Err error
+
+	mu        sync.Mutex
+	processes []*MockProcess
+}
+
+// Spawn creates and records a new MockProcess.
+func (s *MockSpawner) Spawn(_ context.Context, prompt string) (telegraph.Process, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	p := NewMockProcess(prompt)
+	s.processes = append(s.processes, p)
+	return p, nil
+}
+
+// Processes returns every process spawned so far, oldest first.
+func (s *MockSpawner) Processes() []*MockProcess {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*MockProcess, len(s.processes))
+	copy(out, s.processes)
+	return out
+}
+
+// LastProcess returns the most recently spawned process, or nil.
+func (s *MockSpawner) LastProcess() *MockProcess {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.processes) == 0 {
+		return nil
+	}
+	return s.processes[len(s.processes)-1]
+}
@@ -0,0 +1,62 @@
+// Package telegraphtest provides fakes, builders, and assertions for testing
+// code built on the telegraph package without a real chat platform or agent
+// subprocess.
+//
+// # Typical usage
+//
+//	adapter := telegraphtest.NewMockAdapter()
+//	adapter.SetBotUserID("BOT")
+//	spawner := &telegraphtest.MockSpawner{}
+//
+//	// ... start a telegraph.Daemon or Router with adapter and spawner ...
+//
+//	adapter.SimulateInbound(telegraphtest.Inbound().
+//		From("U1", "alice").
+//		InChannel("C1").
+//		Mention("BOT", "add a retry to the importer").
+//		Build())
+//
+//	telegraphtest.WaitForSent(t, adapter, 1, time.Second)
+//	telegraphtest.AssertSentContains(t, adapter, "Dispatching")
+//
+// The package lives under pkg/ so bots and adapters built outside this
+// module can import it. [InboundMessage] and [OutboundMessage] alias the
+// telegraph message types so such callers can name them.
+//
+// # MockAdapter location
+//
+// [MockAdapter] is an alias for telegraph.MockAdapter. The implementation
+// stays in package telegraph because that package's own in-package tests
+// depend on it, and importing telegraphtest from them would be an import
+// cycle. New code should reference it through this package.
+package telegraphtest
+
+import "github.com/zulandar/railyard/internal/telegraph"
+
+// MockAdapter implements telegraph.Adapter, telegraph.ThreadStarter,
+// telegraph.MessagePoster, and telegraph.BotUserIDer. It records sent
+// messages and delivers inbound messages injected via SimulateInbound.
+type MockAdapter = telegraph.MockAdapter
+
+// InboundMessage is a message received from the chat platform, as built by
+// [Inbound] and delivered by [MockAdapter.SimulateInbound].
+type InboundMessage = telegraph.InboundMessage
+
+// OutboundMessage is a message the code under test sent, as recorded by
+// [MockAdapter].
+type OutboundMessage = telegraph.OutboundMessage
+
+// NewMockAdapter creates a MockAdapter with a buffered inbound channel.
+func NewMockAdapter() *MockAdapter {
+	return telegraph.NewMockAdapter()
+}
+
+// Compile-time interface compliance checks.
+var (
+	_ telegraph.Adapter        = (*MockAdapter)(nil)
+	_ telegraph.ThreadStarter  = (*MockAdapter)(nil)
+	_ telegraph.MessagePoster  = (*MockAdapter)(nil)
+	_ telegraph.BotUserIDer    = (*MockAdapter)(nil)
+	_ telegraph.ProcessSpawner = (*MockSpawner)(nil)
+	_ telegraph.Process        = (*MockProcess)(nil)
+)
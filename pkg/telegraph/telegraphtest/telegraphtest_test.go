@@ -0,0 +1,161 @@
+package telegraphtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zulandar/railyard/internal/telegraph"
+	"github.com/zulandar/railyard/pkg/telegraph/telegraphtest"
+)
+
+func TestInboundBuilder(t *testing.T) {
+	msg := telegraphtest.Inbound().
+		From("U1", "alice").
+		InChannel("C1").
+		InThread("T1").
+		WithID("M1").
+		Mention("BOT", "hello").
+		Build()
+
+	if msg.UserID != "U1" || msg.UserName != "alice" {
+		t.Errorf("user = %q/%q, want U1/alice", msg.UserID, msg.UserName)
+	}
+	if msg.ChannelID != "C1" || msg.ThreadID != "T1" || msg.MessageID != "M1" {
+		t.Errorf("ids = %q/%q/%q, want C1/T1/M1", msg.ChannelID, msg.ThreadID, msg.MessageID)
+	}
+	if msg.Text != "<@BOT> hello" {
+		t.Errorf("text = %q, want mention form", msg.Text)
+	}
+	if msg.Timestamp.IsZero() {
+		t.Error("expected default timestamp")
+	}
+
+	if got := telegraphtest.Inbound().Command("status").Build().Text; got != "!ry status" {
+		t.Errorf("command text = %q, want %q", got, "!ry status")
+	}
+}
+
+func TestMockAdapter_SimulateAndAssert(t *testing.T) {
+	m := telegraphtest.NewMockAdapter()
+	ctx := context.Background()
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	ch, err := m.Listen(ctx)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	m.SimulateInbound(telegraphtest.Inbound().Text("ping").Build())
+	if got := <-ch; got.Text != "ping" {
+		t.Errorf("inbound text = %q, want ping", got.Text)
+	}
+
+	go func() {
+		_ = m.Send(ctx, telegraph.OutboundMessage{
+			ChannelID: "C1",
+			ThreadID:  "T1",
+			Events:    []telegraph.FormattedEvent{{Title: "Car car-1 merged", Body: "done"}},
+		})
+	}()
+	telegraphtest.WaitForSent(t, m, 1, time.Second)
+	telegraphtest.AssertSentCount(t, m, 1)
+	telegraphtest.AssertSentContains(t, m, "car-1 merged")
+	telegraphtest.AssertNotSentContains(t, m, "failed")
+	telegraphtest.AssertSentInThread(t, m, "T1")
+}
+
+func TestMockSpawner(t *testing.T) {
+	s := &telegraphtest.MockSpawner{}
+	proc, err := s.Spawn(context.Background(), "system prompt")
+	if err != nil {
+		t.Fatalf("spawn: %v", err)
+	}
+	mp := s.LastProcess()
+	if mp == nil || mp.Prompt != "system prompt" {
+		t.Fatalf("LastProcess = %+v, want process with prompt", mp)
+	}
+
+	if err := proc.Send("hi"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	mp.Emit("line 1")
+	if got := <-proc.Recv(); got != "line 1" {
+		t.Errorf("recv = %q, want line 1", got)
+	}
+
+	exitErr := errors.New("exit status 1")
+	mp.SetStderr("boom")
+	mp.Exit(exitErr)
+	<-proc.Done()
+	if _, ok := <-proc.Recv(); ok {
+		t.Error("recv channel should be closed after Exit")
+	}
+	if !errors.Is(proc.ExitErr(), exitErr) || proc.Stderr() != "boom" {
+		t.Errorf("exit = %v/%q, want exit error and stderr", proc.ExitErr(), proc.Stderr())
+	}
+	if err := proc.Send("late"); err == nil {
+		t.Error("send after exit should fail")
+	}
+	if got := mp.Sent(); len(got) != 1 || got[0] != "hi" {
+		t.Errorf("sent = %v, want [hi]", got)
+	}
+	mp.Exit(nil) // idempotent
+
+	s.Err = errors.New("no capacity")
+	if _, err := s.Spawn(context.Background(), "p"); err == nil {
+		t.Error("expected spawn error")
+	}
+	if n := len(s.Processes()); n != 1 {
+		t.Errorf("processes = %d, want 1", n)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := telegraphtest.NewFakeClock(start)
+
+	ch := c.After(time.Minute)
+	select {
+	case <-ch:
+		t.Fatal("timer fired before Advance")
+	default:
+	}
+
+	c.Advance(30 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("timer fired before deadline")
+	default:
+	}
+
+	c.Advance(30 * time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(start.Add(time.Minute)) {
+			t.Errorf("fired at %v, want %v", got, start.Add(time.Minute))
+		}
+	default:
+		t.Fatal("timer did not fire at deadline")
+	}
+	if c.Since(start) != time.Minute {
+		t.Errorf("Since = %v, want 1m", c.Since(start))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Hour)
+		close(done)
+	}()
+	if !c.BlockUntil(1, time.Second) {
+		t.Fatal("sleeper never registered")
+	}
+	c.Advance(time.Hour)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep did not return after Advance")
+	}
+}